@@ -2,30 +2,47 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-redis/redis/v8"
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 
 	"github.com/alfanzaky/eraflazz/config"
+	chaosadapter "github.com/alfanzaky/eraflazz/internal/adapter/chaos"
 	digiflazzadapter "github.com/alfanzaky/eraflazz/internal/adapter/digiflazz"
+	emailadapter "github.com/alfanzaky/eraflazz/internal/adapter/email"
 	adapterfactory "github.com/alfanzaky/eraflazz/internal/adapter/factory"
+	normalizeadapter "github.com/alfanzaky/eraflazz/internal/adapter/normalize"
+	eventpublisher "github.com/alfanzaky/eraflazz/internal/adapter/publisher"
+	"github.com/alfanzaky/eraflazz/internal/adapter/sandbox"
 	"github.com/alfanzaky/eraflazz/internal/domain"
 	apihandler "github.com/alfanzaky/eraflazz/internal/handler/api"
+	"github.com/alfanzaky/eraflazz/internal/plugin"
 	"github.com/alfanzaky/eraflazz/internal/repository/postgres"
 	redisrepo "github.com/alfanzaky/eraflazz/internal/repository/redis"
 	"github.com/alfanzaky/eraflazz/internal/usecase"
 	"github.com/alfanzaky/eraflazz/internal/worker"
 	"github.com/alfanzaky/eraflazz/pkg/auth"
+	"github.com/alfanzaky/eraflazz/pkg/chaos"
+	"github.com/alfanzaky/eraflazz/pkg/httpclient"
+	"github.com/alfanzaky/eraflazz/pkg/lifecycle"
 	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/mailer"
 	"github.com/alfanzaky/eraflazz/pkg/observability"
+	"github.com/alfanzaky/eraflazz/pkg/secretbox"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
 )
 
 func main() {
@@ -44,35 +61,99 @@ func main() {
 	logger.Init(cfg.App.Environment)
 	defer logger.Close()
 
+	logComponents, err := logger.ParseComponents(cfg.Logging.Components)
+	if err == nil {
+		err = logger.Configure(cfg.Logging.Level, logComponents)
+	}
+	if err != nil {
+		logger.Fatal("Invalid logging configuration", logger.ErrorField(err))
+	}
+
+	// Components are stopped in reverse dependency order on shutdown; the
+	// logger is flushed last
+	app := lifecycle.New(lifecycle.Config{StopTimeout: cfg.App.StopTimeout})
+	app.Register(lifecycle.Component{
+		Name: "logger",
+		Stop: func(ctx context.Context) error {
+			logger.Sync()
+			return nil
+		},
+	})
+
 	// Print configuration in development mode
 	if cfg.App.IsDevelopment() {
 		cfg.Print()
 	}
 
+	// Initialize fault injection (non-production resilience testing only)
+	var chaosInjector *chaos.Injector
+	if cfg.Chaos.Enabled {
+		chaosInjector, err = chaos.NewInjector(
+			chaos.Fault{Target: chaos.TargetSupplier, Latency: cfg.Chaos.SupplierLatency, ErrorRate: cfg.Chaos.SupplierErrorRate, Scope: cfg.Chaos.SupplierScope},
+			chaos.Fault{Target: chaos.TargetRedis, Latency: cfg.Chaos.RedisLatency, ErrorRate: cfg.Chaos.RedisErrorRate},
+			chaos.Fault{Target: chaos.TargetDatabase, Latency: cfg.Chaos.DatabaseLatency, ErrorRate: cfg.Chaos.DatabaseErrorRate},
+		)
+		if err != nil {
+			logger.Fatal("Invalid chaos configuration", logger.ErrorField(err))
+		}
+		logger.Warn("Chaos fault injection enabled", logger.Any("faults", chaosInjector.List()))
+	}
+
 	// Initialize database connection
-	db, err := sqlx.Connect("postgres", cfg.Database.GetDSN())
+	db, err := connectDatabase(cfg.Database.GetDSN(), chaosInjector, cfg.Database.QueryMetrics)
 	if err != nil {
 		logger.Fatal("Failed to connect to database", logger.ErrorField(err))
 	}
-	defer db.Close()
+	app.Register(lifecycle.Component{
+		Name:      "database",
+		DependsOn: []string{"logger"},
+		Stop:      func(ctx context.Context) error { return db.Close() },
+	})
+	db.SetMaxOpenConns(cfg.Database.MaxOpen)
+	db.SetMaxIdleConns(cfg.Database.MaxIdle)
+	db.SetConnMaxLifetime(cfg.Database.MaxLife)
 
 	// Initialize Redis connection
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     cfg.Redis.GetRedisAddr(),
-		Password: cfg.Redis.Password,
-		DB:       cfg.Redis.DB,
-		PoolSize: cfg.Redis.PoolSize,
+	rdb, err := redisrepo.NewUniversalClient(redisrepo.ClientOptions{
+		Mode:             cfg.Redis.Mode,
+		Addrs:            cfg.Redis.GetRedisAddrs(),
+		MasterName:       cfg.Redis.MasterName,
+		Username:         cfg.Redis.Username,
+		Password:         cfg.Redis.Password,
+		SentinelPassword: cfg.Redis.SentinelPassword,
+		DB:               cfg.Redis.DB,
+		PoolSize:         cfg.Redis.PoolSize,
+		MaxRetries:       cfg.Redis.MaxRetries,
 	})
+	if err != nil {
+		logger.Fatal("Failed to configure Redis client", logger.ErrorField(err))
+	}
 
 	// Test Redis connection
 	_, err = rdb.Ping(context.Background()).Result()
 	if err != nil {
 		logger.Fatal("Failed to connect to Redis", logger.ErrorField(err))
 	}
-	defer rdb.Close()
+	app.Register(lifecycle.Component{
+		Name:      "redis",
+		DependsOn: []string{"logger"},
+		Stop:      func(ctx context.Context) error { return rdb.Close() },
+	})
+
+	// Added after the connection check so startup never fails on purpose
+	if chaosInjector != nil {
+		rdb.AddHook(chaos.NewRedisHook(chaosInjector))
+	}
 
 	logger.Info("Database and Redis connections established")
 
+	// Warn about hot queries running without index support; never blocks startup
+	if cfg.Database.IndexCheck {
+		go postgres.NewIndexAdvisor(db, postgres.IndexAdvisorConfig{
+			MinRows: int64(cfg.Database.IndexCheckMinRows),
+		}).Run()
+	}
+
 	// Initialize repositories
 	userRepo := postgres.NewUserRepository(db)
 	productRepo := postgres.NewProductRepository(db)
@@ -81,25 +162,249 @@ func main() {
 	mutationRepo := postgres.NewMutationRepository(db)
 	productMappingRepo := postgres.NewProductMappingRepository(db)
 	apiClientRepo := postgres.NewAPIClientRepository(db.DB)
+	eventRepo := postgres.NewEventRepository(db)
+	unitOfWork := postgres.NewUnitOfWork(db)
+	routingOverrideRepo := postgres.NewRoutingOverrideRepository(db)
+	notificationPrefRepo := postgres.NewNotificationPreferenceRepository(db)
+	messageTemplateRepo := postgres.NewMessageTemplateRepository(db)
+	outboxRepo := postgres.NewOutboxRepository(db)
+	priceHistoryRepo := postgres.NewPriceHistoryRepository(db)
+	mappingReviewRepo := postgres.NewMappingReviewRepository(db)
+	reconciliationRepo := postgres.NewReconciliationRepository(db)
+	downlineRepo := postgres.NewDownlineRepository(db)
+	mutationWebhookRepo := postgres.NewMutationWebhookRepository(db)
+	balanceHoldRepo := postgres.NewBalanceHoldRepository(db)
+	securityEventRepo := postgres.NewSecurityEventRepository(db)
+	reportRepo := postgres.NewReportRepository(db)
+	timelineRepo := postgres.NewTransactionTimelineRepository(db)
+	feeRuleRepo := postgres.NewFeeRuleRepository(db)
+	promotionRepo := postgres.NewPromotionRepository(db)
+	voucherCodeRepo := postgres.NewVoucherCodeRepository(db)
+	statementRepo := postgres.NewStatementRepository(db)
+	supplierProbeRepo := postgres.NewSupplierProbeRepository(db)
+	destinationRuleRepo := postgres.NewDestinationRuleRepository(db)
+	anomalyRepo := postgres.NewAnomalyRepository(db)
+	refundSLARepo := postgres.NewRefundSLARepository(db)
+	favoriteRepo := postgres.NewFavoriteRepository(db)
+	quotaPlanRepo := postgres.NewQuotaPlanRepository(db)
+	userPriceRepo := postgres.NewUserProductPriceRepository(db)
+	tierPriceRepo := postgres.NewProductTierPriceRepository(db)
+	transferRepo := postgres.NewBalanceTransferRepository(db)
+	referralRepo := postgres.NewReferralRepository(db)
+	statusIncidentRepo := postgres.NewStatusIncidentRepository(db)
+	passwordResetRepo := postgres.NewPasswordResetRepository(db)
+	routingDecisionRepo := postgres.NewRoutingDecisionRepository(db)
+	cutoffScheduleRepo := postgres.NewCutoffScheduleRepository(db)
+	retryPolicyRepo := postgres.NewRetryPolicyRepository(db)
+	productCategoryRepo := postgres.NewProductCategoryRepository(db)
+	productProviderRepo := postgres.NewProductProviderRepository(db)
+	adminSigningKeyRepo := postgres.NewAdminSigningKeyRepository(db)
+	impersonationRepo := postgres.NewImpersonationRepository(db)
+	supplierInvoiceRepo := postgres.NewSupplierInvoiceRepository(db)
+	featureFlagRepo := postgres.NewFeatureFlagRepository(db)
 
-	// Initialize smart routing
-	smartRoutingUC := usecase.NewSmartRoutingUsecase(productRepo, supplierRepo, productMappingRepo)
+	// Initialize product categories and providers
+	catalogUC := usecase.NewCatalogUsecase(productCategoryRepo, productProviderRepo, usecase.DefaultCatalogConfig())
 
-	// Initialize product use case
-	productUC := usecase.NewProductUsecase(productRepo, productMappingRepo, supplierRepo, smartRoutingUC)
+	// Initialize cutoff hours and ops calendar
+	cutoffUC := usecase.NewCutoffUsecase(cutoffScheduleRepo, supplierRepo, catalogUC, usecase.CutoffConfig{
+		Timezone: cfg.Report.Timezone,
+	})
 
-	// Initialize retry use case
-	retryUC := usecase.NewRetryUsecase(transactionRepo, supplierRepo, smartRoutingUC)
+	// Initialize feature flags of this environment, shared between replicas
+	// through Redis
+	featureFlagUC := usecase.NewFeatureFlagUsecase(featureFlagRepo, redisrepo.NewFeatureFlagCacheRepository(rdb), usecase.FeatureFlagConfig{
+		Environment: cfg.App.Environment,
+		Defaults: map[string]bool{
+			domain.FlagRoutingSpreadAccounts: cfg.Routing.SpreadAccounts,
+			domain.FlagH2HSyncFailover:       cfg.Flags.SyncFailoverDefault,
+		},
+		CacheTTL: cfg.Flags.CacheTTL,
+	})
+
+	// Initialize smart routing; mappings and suppliers of a product are
+	// cached in Redis and invalidated when either changes
+	routingCacheRepo := redisrepo.NewRoutingCacheRepository(rdb)
+	smartRoutingUC := usecase.NewSmartRoutingUsecase(productRepo, supplierRepo, productMappingRepo, routingOverrideRepo, cutoffUC, routingCacheRepo, featureFlagUC, usecase.SmartRoutingConfig{
+		PriorityBlendWeight: cfg.Routing.PriorityBlendWeight,
+		CacheTTL:            cfg.Routing.CacheTTL,
+		SpreadAccounts:      cfg.Routing.SpreadAccounts,
+	})
 
-	// Initialize supplier adapters
+	// Initialize supplier adapters. Digiflazz adapters are built per supplier
+	// record, so every Digiflazz account uses its own credentials; all of
+	// them share one pooled, retrying HTTP transport.
+	supplierHTTPConfig := httpclient.Config{
+		MaxRetries:          cfg.Suppliers.HTTP.MaxRetries,
+		AttemptTimeout:      cfg.Suppliers.HTTP.AttemptTimeout,
+		RetryBackoff:        cfg.Suppliers.HTTP.RetryBackoff,
+		MaxIdleConnsPerHost: cfg.Suppliers.HTTP.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     cfg.Suppliers.HTTP.MaxConnsPerHost,
+		IdleConnTimeout:     cfg.Suppliers.HTTP.IdleConnTimeout,
+	}
+	supplierTransport := httpclient.NewTransport(supplierHTTPConfig)
 	adapterFactory := adapterfactory.NewSupplierAdapterFactory()
-	digiflazzAdapter := digiflazzadapter.NewAdapter(cfg.Suppliers.Digiflazz, nil)
-	adapterFactory.RegisterAdapter(domain.SupplierCodeDigiflazz, digiflazzAdapter)
+	adapterFactory.RegisterBuilder(domain.SupplierCodeDigiflazz, func(supplier *domain.Supplier) (domain.SupplierAdapter, error) {
+		timeoutSeconds := supplier.TimeoutSeconds
+		if timeoutSeconds <= 0 {
+			timeoutSeconds = cfg.Suppliers.Digiflazz.TimeoutSeconds
+		}
+		var transport http.RoundTripper = supplierTransport
+		sandboxTransport, err := sandbox.NewTransport(sandbox.Config{
+			Mode: cfg.Suppliers.Sandbox.Mode,
+			Dir:  filepath.Join(cfg.Suppliers.Sandbox.Dir, strings.ToLower(supplier.Code)),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if sandboxTransport != nil {
+			logger.Warn("Supplier sandbox enabled",
+				logger.String("mode", cfg.Suppliers.Sandbox.Mode),
+				logger.String("supplier_code", supplier.Code),
+			)
+			transport = sandboxTransport
+		}
+		client := httpclient.New(supplierHTTPConfig, transport, time.Duration(timeoutSeconds)*time.Second)
+		adapter, err := digiflazzadapter.NewAdapter(cfg.Suppliers.Digiflazz, supplier, client)
+		if err != nil {
+			return nil, err
+		}
+		normalized, err := normalizeadapter.Wrap(adapter, supplier)
+		if err != nil {
+			return nil, err
+		}
+		return chaosadapter.Wrap(normalized, chaosInjector, supplier.Code), nil
+	})
+
+	// Build the adapters of the active supplier accounts now; accounts added
+	// or edited later through the admin API are (re)built on the spot
+	supplierRegistryUC := usecase.NewSupplierRegistryUsecase(supplierRepo, productMappingRepo, adapterFactory, routingCacheRepo)
+	if loaded, err := supplierRegistryUC.LoadAdapters(); err != nil {
+		logger.Warn("Failed to load supplier adapters", logger.ErrorField(err))
+	} else {
+		logger.Info("Supplier adapters loaded", logger.Int("count", loaded))
+	}
+
+	// Initialize pricing use case (price history and margin protection)
+	pricingUC := usecase.NewPricingUsecase(productRepo, productMappingRepo, supplierRepo, priceHistoryRepo, userRepo, adapterFactory, routingCacheRepo, eventRepo, usecase.PricingConfig{
+		MinMargin:    cfg.Pricing.MinMargin,
+		MarginAction: cfg.Pricing.MarginAction,
+	})
+
+	// Initialize mapping validation use case (stale supplier codes)
+	mappingValidationUC := usecase.NewMappingValidationUsecase(productRepo, productMappingRepo, supplierRepo, mappingReviewRepo, adapterFactory, routingCacheRepo)
+	reconciliationUC := usecase.NewReconciliationUsecase(reconciliationRepo, usecase.ReconciliationConfig{
+		BatchSize: cfg.Reconcile.BatchSize,
+	})
+
+	// Initialize product use case
+	productUC := usecase.NewProductUsecase(productRepo, productMappingRepo, supplierRepo, smartRoutingUC, pricingUC, catalogUC)
+
+	// Initialize routing override use case
+	routingOverrideUC := usecase.NewRoutingOverrideUsecase(routingOverrideRepo, productRepo, supplierRepo, catalogUC)
+
+	// Initialize admin fee use case
+	feeUC := usecase.NewFeeUsecase(feeRuleRepo, catalogUC)
+	promotionUC := usecase.NewPromotionUsecase(promotionRepo, transactionRepo, productRepo, userRepo, catalogUC, unitOfWork)
+
+	// Initialize notification use case
+	notificationUC := usecase.NewNotificationUsecase(notificationPrefRepo, messageTemplateRepo, outboxRepo, userRepo, reportRepo, priceHistoryRepo, usecase.NotificationConfig{
+		LowBalanceThreshold: cfg.Notify.LowBalanceThreshold,
+		Timezone:            cfg.Report.Timezone,
+	})
+
+	// Initialize security event use case (access denial audit and burst alerts)
+	var securityAlertPublisher domain.EventPublisher
+	if cfg.Security.AlertWebhookURL != "" {
+		securityAlertPublisher = eventpublisher.NewWebhookPublisher(cfg.Security.AlertWebhookURL, cfg.Security.AlertWebhookSecret, 0, nil)
+	}
+	securityEventUC := usecase.NewSecurityEventUsecase(securityEventRepo, securityAlertPublisher, usecase.SecurityAlertConfig{
+		Threshold: cfg.Security.AlertThreshold,
+		Window:    cfg.Security.AlertWindow,
+		Cooldown:  cfg.Security.AlertCooldown,
+	})
+	apihandler.SetSecurityEventRecorder(securityEventUC)
+
+	// Initialize phone numbering plans (default country, overrides per product and category)
+	phoneNormalizer, err := utils.NewPhoneNormalizer(cfg.Phone.DefaultCountry, cfg.Phone.ProductCountries, cfg.Phone.CategoryCountries)
+	if err != nil {
+		logger.Fatal("Invalid phone configuration", logger.ErrorField(err))
+	}
+
+	// Initialize destination rule use case (destination format per category and product)
+	destinationRuleUC := usecase.NewDestinationRuleUsecase(destinationRuleRepo, catalogUC, phoneNormalizer)
+
+	// Initialize retry use case (policies per supplier and error class)
+	retryPolicyUC := usecase.NewRetryPolicyUsecase(retryPolicyRepo, supplierRepo, usecase.RetryPolicyConfig{
+		Defaults: usecase.RetryConfig{
+			MaxAttempts:       cfg.Retry.MaxAttempts,
+			InitialDelay:      cfg.Retry.InitialDelay,
+			MaxDelay:          cfg.Retry.MaxDelay,
+			BackoffMultiplier: cfg.Retry.BackoffMultiplier,
+			EnableJitter:      cfg.Retry.Jitter,
+		},
+		CacheTTL: cfg.Retry.PolicyCacheTTL,
+	})
+	retryUC := usecase.NewRetryUsecase(transactionRepo, supplierRepo, smartRoutingUC, timelineRepo, retryPolicyUC)
 
 	// Initialize repositories that depend on Redis
 	queueRepo := redisrepo.NewCacheRepository(rdb)
+	loginAttemptRepo := redisrepo.NewLoginAttemptRepository(rdb)
+	reportCacheRepo := redisrepo.NewReportCacheRepository(rdb)
+	schedulerRepo := redisrepo.NewSchedulerRepository(rdb)
+	nonceRepo := redisrepo.NewNonceRepository(rdb)
+	quotaCounterRepo := redisrepo.NewQuotaCounterRepository(rdb)
+	tokenRevocationRepo := redisrepo.NewTokenRevocationRepository(rdb)
+	webhookEventRepo := redisrepo.NewWebhookEventRepository(rdb)
+	priceListCacheRepo := redisrepo.NewPriceListCacheRepository(rdb)
+	amountConfirmationRepo := redisrepo.NewAmountConfirmationRepository(rdb)
+	var transactionLockRepo domain.TransactionLockRepository
+	if cfg.TrxLock.Enabled {
+		transactionLockRepo = redisrepo.NewTransactionLockRepository(rdb)
+	}
 
 	// Initialize use cases
+	var voucherBox *secretbox.Box
+	if cfg.Voucher.CodeKey != "" {
+		voucherBox, err = secretbox.New(cfg.Voucher.CodeKey)
+		if err != nil {
+			logger.Fatal("Invalid voucher code key", logger.ErrorField(err))
+		}
+	}
+	voucherUC := usecase.NewVoucherUsecase(voucherCodeRepo, productRepo, unitOfWork, voucherBox, usecase.VoucherConfig{
+		LowStockThreshold: cfg.Voucher.LowStockThreshold,
+		MaxFileSize:       cfg.Voucher.MaxFileSize,
+		MaxCodes:          cfg.Voucher.MaxCodes,
+	})
+	userPriceUC := usecase.NewUserPriceUsecase(userPriceRepo, tierPriceRepo, userRepo, productRepo, usecase.DefaultUserPriceConfig())
+
+	// Initialize balance transfer use case (limits per sender level)
+	transferLimits := make(map[int]domain.TransferLimit)
+	for level, amount := range cfg.Transfer.MaxAmount {
+		limit := transferLimits[level]
+		limit.MaxAmount = amount
+		transferLimits[level] = limit
+	}
+	for level, amount := range cfg.Transfer.DailyLimit {
+		limit := transferLimits[level]
+		limit.DailyLimit = amount
+		transferLimits[level] = limit
+	}
+	transferUC := usecase.NewBalanceTransferUsecase(userRepo, transferRepo, loginAttemptRepo, unitOfWork, usecase.BalanceTransferConfig{
+		MinAmount:       cfg.Transfer.MinAmount,
+		Limits:          transferLimits,
+		Timezone:        cfg.Report.Timezone,
+		PINMaxAttempts:  cfg.Transfer.PINMaxAttempts,
+		PINLockDuration: cfg.Transfer.PINLockDuration,
+	})
+
+	// Initialize amount confirmation (large orders need a token or the PIN)
+	amountConfirmationUC := usecase.NewAmountConfirmationUsecase(userRepo, amountConfirmationRepo, transferUC, domain.AmountConfirmationPolicy{
+		DefaultThreshold: cfg.Confirm.DefaultThreshold,
+		TokenTTL:         cfg.Confirm.TokenTTL,
+		ExemptChannels:   cfg.Confirm.ExemptChannels,
+	})
+
 	transactionUC := usecase.NewTransactionUsecase(
 		userRepo,
 		productRepo,
@@ -110,13 +415,423 @@ func main() {
 		adapterFactory,
 		retryUC,
 		queueRepo,
+		queueRepo, // The Redis cache repository also caches products
+		unitOfWork,
+		pricingUC,
+		balanceHoldRepo,
+		timelineRepo,
+		routingDecisionRepo,
+		feeUC,
+		destinationRuleUC,
+		userPriceUC,
+		cutoffUC,
+		amountConfirmationUC,
+		transactionLockRepo,
+		voucherUC,
+		usecase.TransactionConfig{
+			AutoCancel: domain.AutoCancelPolicy{
+				Default:  cfg.Expiry.Default,
+				Channels: cfg.Expiry.ChannelDurations,
+				Products: cfg.Expiry.ProductDurations,
+			},
+			ExpiryBatchSize: cfg.Expiry.BatchSize,
+			ProcessingSLA: domain.ProcessingSLAPolicy{
+				Expected:         cfg.Expiry.ProcessingExpected,
+				Timeout:          cfg.Expiry.ProcessingTimeout,
+				CategoryExpected: cfg.Expiry.CategoryExpected,
+				CategoryTimeout:  cfg.Expiry.CategoryTimeout,
+				ProductExpected:  cfg.Expiry.ProductExpected,
+				ProductTimeout:   cfg.Expiry.ProductTimeout,
+			},
+			DuplicateGuard: domain.DuplicateGuardPolicy{
+				Mode:   cfg.Duplicate.Mode,
+				Window: cfg.Duplicate.Window,
+			},
+			LockTTL:           cfg.TrxLock.TTL,
+			MinSupplierBudget: cfg.API.MinSupplierBudget,
+			SlowProcessing:    cfg.Expiry.SlowProcessing,
+		},
 	)
 
+	// Initialize supplier webhook use case (signed results of pending transactions)
+	supplierWebhookUC := usecase.NewSupplierWebhookUsecase(supplierRepo, webhookEventRepo, adapterFactory, transactionUC, usecase.SupplierWebhookConfig{
+		ReplayWindow: cfg.Suppliers.WebhookReplayWindow,
+	})
+
+	// Initialize favorite use case (saved products and quick orders)
+	favoriteUC := usecase.NewFavoriteUsecase(favoriteRepo, productRepo, destinationRuleUC, transactionUC)
+
+	quotaUC := usecase.NewQuotaUsecase(quotaPlanRepo, quotaCounterRepo, usecase.QuotaConfig{
+		Timezone: cfg.Report.Timezone,
+	})
+	apiClientPortalUC := usecase.NewAPIClientPortalUsecase(apiClientRepo, transactionRepo, outboxRepo, transactionUC, quotaUC, usecase.APIClientPortalConfig{
+		SecretGracePeriod: cfg.H2H.SecretGracePeriod,
+	})
+
+	mutationUC := usecase.NewMutationUsecase(mutationRepo, unitOfWork, usecase.MutationConfig{
+		Timezone: cfg.Report.Timezone,
+	})
+	reportUC := usecase.NewReportUsecase(reportRepo, reportCacheRepo, usecase.ReportConfig{
+		Timezone: cfg.Report.Timezone,
+		CacheTTL: cfg.Report.CacheTTL,
+	})
+	refundSLAUC := usecase.NewRefundSLAUsecase(refundSLARepo, eventRepo, usecase.RefundSLAConfig{
+		Target:           cfg.Refund.Target,
+		StuckAfter:       cfg.Refund.StuckAfter,
+		Lookback:         cfg.Refund.Lookback,
+		OutstandingLimit: cfg.Refund.OutstandingLimit,
+		Cooldown:         cfg.Refund.AlertCooldown,
+	})
+	downlineUC := usecase.NewDownlineUsecase(downlineRepo, userRepo, usecase.DownlineConfig{
+		Timezone: cfg.Report.Timezone,
+	})
+	// Initialize mutation webhook use case (user mutations pushed to their accounting systems).
+	// The dispatcher only connects to public addresses unless private ones are allowed.
+	var mutationWebhookClient *http.Client
+	if cfg.MutHook.AllowPrivate {
+		mutationWebhookClient = &http.Client{Timeout: cfg.MutHook.Timeout}
+	}
+	mutationWebhookUC := usecase.NewMutationWebhookUsecase(mutationWebhookRepo, userRepo, eventpublisher.NewWebhookDispatcher(cfg.MutHook.Timeout, mutationWebhookClient), usecase.MutationWebhookConfig{
+		MaxDepth:             cfg.MutHook.MaxDepth,
+		BatchSize:            cfg.MutHook.BatchSize,
+		MaxAttempts:          cfg.MutHook.MaxAttempts,
+		Timeout:              cfg.MutHook.Timeout,
+		AllowHTTP:            cfg.MutHook.AllowHTTP,
+		AllowPrivateNetworks: cfg.MutHook.AllowPrivate,
+	})
+	// Initialize referral use case (downline limits per upline level)
+	maxDownlines := make(map[int]int)
+	for level, count := range cfg.Referral.MaxDownlines {
+		maxDownlines[level] = int(count)
+	}
+	referralUC := usecase.NewReferralUsecase(userRepo, referralRepo, unitOfWork, phoneNormalizer, usecase.ReferralConfig{
+		MaxDownlines: maxDownlines,
+		CodeLength:   cfg.Referral.CodeLength,
+	})
+	productHealthUC := usecase.NewProductHealthUsecase(productMappingRepo, usecase.ProductHealthConfig{
+		Window:         cfg.Health.Window,
+		MinSamples:     cfg.Health.MinSamples,
+		DisruptedBelow: cfg.Health.DisruptedBelow,
+		CacheTTL:       cfg.Health.CacheTTL,
+	})
+	priceListUC := usecase.NewPriceListUsecase(productRepo, userRepo, priceListCacheRepo, catalogUC, productHealthUC, usecase.PriceListConfig{
+		FreshTTL: cfg.Catalog.PriceListFreshTTL,
+		StaleTTL: cfg.Catalog.PriceListStaleTTL,
+	})
+
+	statementUC := usecase.NewStatementUsecase(statementRepo, userRepo, usecase.StatementConfig{
+		Timezone:    cfg.Report.Timezone,
+		SyncMaxRows: cfg.Statement.SyncMaxRows,
+		BatchSize:   cfg.Statement.BatchSize,
+	})
+
+	supplierProbeUC := usecase.NewSupplierProbeUsecase(supplierRepo, supplierProbeRepo, adapterFactory, routingCacheRepo, eventRepo, usecase.SupplierProbeConfig{
+		Retention:          cfg.Probe.Retention,
+		TargetP95Ms:        cfg.Probe.TargetP95Ms,
+		TargetAvailability: cfg.Probe.TargetAvailability,
+		UnreachableAfter:   cfg.Probe.UnreachableAfter,
+	})
+
+	anomalyUC := usecase.NewAnomalyUsecase(anomalyRepo, eventRepo, usecase.AnomalyConfig{
+		Window:               cfg.Anomaly.Window,
+		SettleDelay:          cfg.Anomaly.SettleDelay,
+		MinSamples:           cfg.Anomaly.MinSamples,
+		FailureRateThreshold: cfg.Anomaly.FailureRateThreshold,
+		PendingRateThreshold: cfg.Anomaly.PendingRateThreshold,
+		Cooldown:             cfg.Anomaly.AlertCooldown,
+	})
+
+	// Workers stop before the outbox components, so what they write on the
+	// way out is still delivered
+	workerDeps := []string{"database", "redis"}
+
+	// Start outbox message dispatch worker (email delivery)
+	if cfg.SMTP.Enabled {
+		smtpMailer, err := mailer.New(mailer.Config{
+			Host:       cfg.SMTP.Host,
+			Port:       cfg.SMTP.Port,
+			Username:   cfg.SMTP.Username,
+			Password:   cfg.SMTP.Password,
+			From:       cfg.SMTP.From,
+			FromName:   cfg.SMTP.FromName,
+			Encryption: cfg.SMTP.Encryption,
+			Timeout:    cfg.SMTP.Timeout,
+			MaxRetries: cfg.SMTP.MaxRetries,
+		})
+		if err != nil {
+			logger.Fatal("Failed to configure SMTP mailer", logger.ErrorField(err))
+		}
+
+		dispatchUC := usecase.NewOutboxDispatchUsecase(outboxRepo, []domain.MessageSender{
+			emailadapter.NewSender(smtpMailer),
+		}, usecase.OutboxDispatchConfig{
+			BatchSize: cfg.Notify.DispatchBatchSize,
+		})
+		outboxDispatchWorker := worker.NewOutboxDispatchWorker(dispatchUC, worker.OutboxDispatchWorkerConfig{
+			PollingInterval: cfg.Notify.DispatchInterval,
+		})
+		app.Register(lifecycle.Component{
+			Name:      "outbox-dispatch",
+			DependsOn: []string{"database"},
+			Run:       outboxDispatchWorker.Start,
+			// One last pass for messages written while the workers stopped
+			Stop: func(ctx context.Context) error {
+				_, err := dispatchUC.DispatchPending(ctx)
+				return err
+			},
+		})
+		workerDeps = append(workerDeps, "outbox-dispatch")
+	}
+
+	// Start outbox relay worker
+	if cfg.Events.RelayEnabled {
+		closePublishers := func() error { return nil }
+		publishers := make([]domain.EventPublisher, 0, len(cfg.Events.WebhookURLs)+4)
+		if cfg.Events.NotificationsEnabled {
+			publishers = append(publishers, eventpublisher.NewNotificationPublisher(notificationUC))
+		}
+		if cfg.Events.CashbackEnabled {
+			publishers = append(publishers, eventpublisher.NewCashbackPublisher(promotionUC))
+		}
+		if cfg.MutHook.Enabled {
+			publishers = append(publishers, eventpublisher.NewMutationWebhookPublisher(mutationWebhookUC))
+		}
+		for _, url := range cfg.Events.WebhookURLs {
+			publishers = append(publishers, eventpublisher.NewWebhookPublisher(url, cfg.Events.WebhookSecret, cfg.Events.WebhookTimeout, nil))
+		}
+		if cfg.Events.RedisStream != "" {
+			publishers = append(publishers, eventpublisher.NewRedisStreamPublisher(rdb, cfg.Events.RedisStream))
+		}
+		if cfg.Events.NATSURL != "" {
+			natsPublisher, err := eventpublisher.NewNATSPublisher(eventpublisher.NATSConfig{
+				URL:           cfg.Events.NATSURL,
+				Token:         cfg.Events.NATSToken,
+				SubjectPrefix: cfg.Events.NATSSubject,
+				JetStream:     cfg.Events.NATSJetStream,
+				Timeout:       cfg.Events.NATSTimeout,
+			})
+			if err != nil {
+				logger.Fatal("Failed to configure NATS event publisher", logger.ErrorField(err))
+			}
+			closePublishers = natsPublisher.Close
+			publishers = append(publishers, natsPublisher)
+		}
+		hookPublisher := eventpublisher.NewHookPublisher(cfg.Events.HookTimeout)
+		for _, hook := range plugin.Hooks() {
+			if err := hookPublisher.Register(hook); err != nil {
+				logger.Fatal("Failed to register event hook", logger.ErrorField(err))
+			}
+		}
+		if hookPublisher.Len() > 0 {
+			publishers = append(publishers, hookPublisher)
+		}
+
+		eventRelayUC := usecase.NewEventRelayUsecase(eventRepo, publishers, usecase.EventRelayConfig{
+			BatchSize:   cfg.Events.BatchSize,
+			MaxAttempts: cfg.Events.MaxAttempts,
+		})
+		eventRelayWorker := worker.NewEventRelayWorker(eventRelayUC, worker.EventRelayWorkerConfig{
+			PollingInterval: cfg.Events.RelayInterval,
+		})
+		app.Register(lifecycle.Component{
+			Name:      "event-relay",
+			DependsOn: []string{"database", "redis"},
+			Run:       eventRelayWorker.Start,
+			// One last pass for events written while the workers stopped
+			Stop: func(ctx context.Context) error {
+				_, err := eventRelayUC.RelayPendingEvents(ctx)
+				return errors.Join(err, closePublishers())
+			},
+		})
+		workerDeps = append(workerDeps, "event-relay")
+	} else {
+		if len(plugin.Hooks()) > 0 {
+			logger.Warn("Event hooks registered but the event relay is disabled, they will not run")
+		}
+		if cfg.MutHook.Enabled {
+			logger.Warn("Mutation webhooks enabled but the event relay is disabled, no mutation will be queued")
+		}
+	}
+
+	var workers []string
+	runWorker := func(name string, run func(ctx context.Context)) {
+		workers = append(workers, name)
+		app.Register(lifecycle.Component{Name: name, DependsOn: workerDeps, Run: run})
+	}
+
 	// Start background transaction worker
 	transactionWorker := worker.NewTransactionWorker(queueRepo, transactionUC, worker.TransactionWorkerConfig{})
-	workerCtx, workerCancel := context.WithCancel(context.Background())
-	defer workerCancel()
-	go transactionWorker.Start(workerCtx)
+	runWorker("transaction-worker", transactionWorker.Start)
+
+	// Periodic jobs run through the scheduler so only one replica runs each activation
+	scheduler := worker.NewScheduler(schedulerRepo, worker.SchedulerConfig{
+		InstanceID:     cfg.Scheduler.InstanceID,
+		DefaultTimeout: cfg.Scheduler.DefaultTimeout,
+	})
+
+	// Start supplier priority auto-tuning worker
+	if cfg.Routing.PriorityTuningEnabled {
+		priorityTuningUC := usecase.NewPriorityTuningUsecase(productMappingRepo, usecase.PriorityTuningConfig{
+			ShortWindow: cfg.Routing.PriorityShortWindow,
+			LongWindow:  cfg.Routing.PriorityLongWindow,
+			MinSamples:  cfg.Routing.PriorityMinSamples,
+		})
+		priorityTuningWorker := worker.NewPriorityTuningWorker(priorityTuningUC, worker.PriorityTuningWorkerConfig{
+			Interval: cfg.Routing.PriorityTuningInterval,
+		})
+		if err := scheduler.Register(priorityTuningWorker.Job()); err != nil {
+			logger.Fatal("Failed to register scheduled job", logger.ErrorField(err))
+		}
+	}
+
+	// Start supplier price sync worker
+	if cfg.Pricing.SyncEnabled {
+		priceSyncWorker := worker.NewPriceSyncWorker(pricingUC, worker.PriceSyncWorkerConfig{
+			Interval: cfg.Pricing.SyncInterval,
+		})
+		if err := scheduler.Register(priceSyncWorker.Job()); err != nil {
+			logger.Fatal("Failed to register scheduled job", logger.ErrorField(err))
+		}
+	}
+
+	// Start supplier mapping validation worker
+	if cfg.Catalog.MappingValidationEnabled {
+		mappingValidationWorker := worker.NewMappingValidationWorker(mappingValidationUC, worker.MappingValidationWorkerConfig{
+			Interval: cfg.Catalog.MappingValidationInterval,
+		})
+		if err := scheduler.Register(mappingValidationWorker.Job()); err != nil {
+			logger.Fatal("Failed to register scheduled job", logger.ErrorField(err))
+		}
+	}
+
+	// Start unconfirmed transaction auto-cancel worker
+	if cfg.Expiry.Enabled {
+		transactionExpiryWorker := worker.NewTransactionExpiryWorker(transactionUC, worker.TransactionExpiryWorkerConfig{
+			Interval: cfg.Expiry.Interval,
+		})
+		if err := scheduler.Register(transactionExpiryWorker.Job()); err != nil {
+			logger.Fatal("Failed to register scheduled job", logger.ErrorField(err))
+		}
+	}
+
+	// Start supplier latency probe worker
+	if cfg.Probe.Enabled {
+		supplierProbeWorker := worker.NewSupplierProbeWorker(supplierProbeUC, worker.SupplierProbeWorkerConfig{
+			Interval: cfg.Probe.Interval,
+		})
+		if err := scheduler.Register(supplierProbeWorker.Job()); err != nil {
+			logger.Fatal("Failed to register scheduled job", logger.ErrorField(err))
+		}
+	}
+
+	// Start transaction anomaly watch worker
+	if cfg.Anomaly.Enabled {
+		anomalyWatchWorker := worker.NewAnomalyWatchWorker(anomalyUC, worker.AnomalyWatchWorkerConfig{
+			Interval: cfg.Anomaly.Interval,
+		})
+		if err := scheduler.Register(anomalyWatchWorker.Job()); err != nil {
+			logger.Fatal("Failed to register scheduled job", logger.ErrorField(err))
+		}
+	}
+
+	// Start stuck refund watch job
+	if cfg.Refund.WatchEnabled {
+		refundWatchWorker := worker.NewRefundWatchWorker(refundSLAUC, worker.RefundWatchWorkerConfig{
+			Interval: cfg.Refund.WatchInterval,
+		})
+		if err := scheduler.Register(refundWatchWorker.Job()); err != nil {
+			logger.Fatal("Failed to register scheduled job", logger.ErrorField(err))
+		}
+	}
+
+	// Start mutation webhook delivery job
+	if cfg.MutHook.Enabled {
+		mutationWebhookWorker := worker.NewMutationWebhookWorker(mutationWebhookUC, worker.MutationWebhookWorkerConfig{
+			Interval: cfg.MutHook.BatchInterval,
+		})
+		if err := scheduler.Register(mutationWebhookWorker.Job()); err != nil {
+			logger.Fatal("Failed to register scheduled job", logger.ErrorField(err))
+		}
+	}
+
+	// Start daily activity summary job
+	if cfg.Notify.DailySummaryEnabled {
+		dailySummaryWorker := worker.NewDailySummaryWorker(notificationUC, worker.DailySummaryWorkerConfig{
+			Schedule: cfg.Notify.DailySummarySchedule,
+		})
+		if err := scheduler.Register(dailySummaryWorker.Job()); err != nil {
+			logger.Fatal("Failed to register scheduled job", logger.ErrorField(err))
+		}
+	}
+
+	// Start price change digest job
+	if cfg.Notify.PriceDigestEnabled {
+		priceDigestWorker := worker.NewPriceDigestWorker(notificationUC, worker.PriceDigestWorkerConfig{
+			Schedule: cfg.Notify.PriceDigestSchedule,
+			Window:   cfg.Notify.PriceDigestWindow,
+		})
+		if err := scheduler.Register(priceDigestWorker.Job()); err != nil {
+			logger.Fatal("Failed to register scheduled job", logger.ErrorField(err))
+		}
+	}
+
+	// Start monthly partition maintenance of transactions and mutations
+	if cfg.Partition.MaintenanceEnabled {
+		partitionMaintenanceUC := usecase.NewPartitionMaintenanceUsecase(postgres.NewPartitionRepository(db), usecase.PartitionMaintenanceConfig{
+			PremakeMonths:   cfg.Partition.PremakeMonths,
+			RetentionMonths: cfg.Partition.RetentionMonths,
+		})
+		partitionMaintenanceWorker := worker.NewPartitionMaintenanceWorker(partitionMaintenanceUC, worker.PartitionMaintenanceWorkerConfig{
+			Interval: cfg.Partition.MaintenanceInterval,
+		})
+		if err := scheduler.Register(partitionMaintenanceWorker.Job()); err != nil {
+			logger.Fatal("Failed to register scheduled job", logger.ErrorField(err))
+		}
+	}
+
+	// Start nightly balance reconciliation against the mutation ledger
+	if cfg.Reconcile.Enabled {
+		balanceReconciliationWorker := worker.NewBalanceReconciliationWorker(reconciliationUC, worker.BalanceReconciliationWorkerConfig{
+			Schedule: cfg.Reconcile.Schedule,
+		})
+		if err := scheduler.Register(balanceReconciliationWorker.Job()); err != nil {
+			logger.Fatal("Failed to register scheduled job", logger.ErrorField(err))
+		}
+	}
+
+	runWorker("scheduler", scheduler.Start)
+
+	// Pool stats are per instance, so every replica samples its own pools
+	redisPoolSize := cfg.Redis.PoolSize
+	if strings.EqualFold(cfg.Redis.Mode, redisrepo.ModeCluster) {
+		redisPoolSize = 0 // PoolSize applies per node
+	}
+	poolStatsWorker := worker.NewPoolStatsWorker(db, rdb, worker.PoolStatsWorkerConfig{
+		Interval:            cfg.Pool.StatsInterval,
+		SaturationThreshold: cfg.Pool.SaturationThreshold,
+		RedisPoolSize:       redisPoolSize,
+	})
+	runWorker("pool-stats", poolStatsWorker.Start)
+
+	// Queue backpressure; every replica samples the shared backlog itself
+	backpressureUC := usecase.NewBackpressureUsecase(queueRepo, usecase.BackpressureConfig{
+		Enabled:         cfg.Intake.Enabled,
+		DegradedBacklog: cfg.Intake.DegradedBacklog,
+		ShedBacklog:     cfg.Intake.ShedBacklog,
+		DrainRate:       cfg.Intake.DrainRate,
+		BaseWait:        cfg.Intake.BaseWait,
+		PriorityClients: cfg.Intake.PriorityClients,
+	})
+	backpressureWorker := worker.NewBackpressureWorker(backpressureUC, worker.BackpressureWorkerConfig{
+		Interval: cfg.Intake.SampleInterval,
+	})
+	runWorker("backpressure", backpressureWorker.Start)
+
+	// Start statement worker (large monthly statements)
+	statementWorker := worker.NewStatementWorker(statementUC, worker.StatementWorkerConfig{
+		PollingInterval: cfg.Statement.PollInterval,
+	})
+	runWorker("statement-worker", statementWorker.Start)
 
 	// Set Gin mode
 	if cfg.App.IsProduction() {
@@ -126,12 +841,88 @@ func main() {
 	}
 
 	// Initialize auth service
-	authService := auth.NewJWTAuthService(cfg.Auth)
+	authService := auth.NewJWTAuthService(cfg.Auth, tokenRevocationRepo)
+	loginThrottleUC := usecase.NewLoginThrottleUsecase(loginAttemptRepo, usecase.LoginThrottleConfig{
+		MaxFailures:   cfg.Auth.LoginMaxFailures,
+		IPMaxFailures: cfg.Auth.LoginIPMaxFailures,
+		FailureWindow: cfg.Auth.LoginFailureWindow,
+		BaseLockout:   cfg.Auth.LoginLockoutBase,
+		MaxLockout:    cfg.Auth.LoginLockoutMax,
+	})
 
 	// Initialize handlers
-	transactionHandler := apihandler.NewTransactionHandler(transactionUC)
-	productHandler := apihandler.NewProductHandler(productUC)
-	authHandler := apihandler.NewAuthHandler(userRepo, authService)
+	transactionHandler := apihandler.NewTransactionHandler(transactionUC, amountConfirmationUC)
+	productHandler := apihandler.NewProductHandler(productUC, pricingUC, productHealthUC)
+	passwordResetUC := usecase.NewPasswordResetUsecase(passwordResetRepo, userRepo, notificationUC, authService, usecase.PasswordResetConfig{
+		TokenTTL:           cfg.Auth.PasswordResetTTL,
+		ResetURL:           cfg.Auth.PasswordResetURL,
+		MaxRequestsPerHour: cfg.Auth.PasswordResetMaxPerHour,
+	})
+	authHandler := apihandler.NewAuthHandler(userRepo, authService, loginThrottleUC, passwordResetUC)
+	routingOverrideHandler := apihandler.NewRoutingOverrideHandler(routingOverrideUC)
+	notificationHandler := apihandler.NewNotificationHandler(notificationUC)
+	mutationHandler := apihandler.NewMutationHandler(mutationUC)
+	mappingReviewHandler := apihandler.NewMappingReviewHandler(mappingValidationUC)
+	reconciliationHandler := apihandler.NewReconciliationHandler(reconciliationUC)
+	securityHandler := apihandler.NewSecurityHandler(securityEventUC)
+	reportHandler := apihandler.NewReportHandler(reportUC, refundSLAUC)
+	systemStatusUC := usecase.NewSystemStatusUsecase(worker.NewMonitor(), queueRepo, eventRepo)
+	schedulerHandler := apihandler.NewSchedulerHandler(scheduler, systemStatusUC)
+	feeHandler := apihandler.NewFeeHandler(feeUC)
+	promotionHandler := apihandler.NewPromotionHandler(promotionUC)
+	voucherHandler := apihandler.NewVoucherHandler(voucherUC)
+	statementHandler := apihandler.NewStatementHandler(statementUC)
+	supplierSLAHandler := apihandler.NewSupplierSLAHandler(supplierProbeUC)
+	destinationRuleHandler := apihandler.NewDestinationRuleHandler(destinationRuleUC)
+	favoriteHandler := apihandler.NewFavoriteHandler(favoriteUC)
+	balanceHandler := apihandler.NewBalanceHandler(transferUC)
+	quotaPlanHandler := apihandler.NewQuotaPlanHandler(quotaUC)
+	userPriceHandler := apihandler.NewUserPriceHandler(userPriceUC)
+	supplierWebhookHandler := apihandler.NewSupplierWebhookHandler(supplierWebhookUC)
+	h2hPortalHandler := apihandler.NewH2HPortalHandler(apiClientPortalUC)
+	cutoffScheduleHandler := apihandler.NewCutoffScheduleHandler(cutoffUC)
+	priceListHandler := apihandler.NewPriceListHandler(priceListUC, cfg.Catalog.PriceListFreshTTL)
+	downlineHandler := apihandler.NewDownlineHandler(downlineUC)
+	referralHandler := apihandler.NewReferralHandler(referralUC)
+	statusPageUC := usecase.NewStatusPageUsecase(statusIncidentRepo, productCategoryRepo, queueRepo, usecase.StatusPageConfig{
+		Window:               cfg.Status.Window,
+		MinSamples:           cfg.Status.MinSamples,
+		DegradedBelow:        cfg.Status.DegradedBelow,
+		DownBelow:            cfg.Status.DownBelow,
+		QueueDegradedBacklog: cfg.Status.QueueDegradedBacklog,
+		QueueDownBacklog:     cfg.Status.QueueDownBacklog,
+		IncidentHistory:      cfg.Status.IncidentHistory,
+		CacheTTL:             cfg.Status.CacheTTL,
+	})
+	statusPageHandler := apihandler.NewStatusPageHandler(statusPageUC)
+	supplierInvoiceUC := usecase.NewSupplierInvoiceUsecase(supplierInvoiceRepo, supplierRepo, usecase.SupplierInvoiceConfig{
+		MaxFileSize:     cfg.Invoice.MaxFileSize,
+		MaxLines:        cfg.Invoice.MaxLines,
+		AmountTolerance: cfg.Invoice.AmountTolerance,
+		Timezone:        cfg.Report.Timezone,
+	})
+	supplierInvoiceHandler := apihandler.NewSupplierInvoiceHandler(supplierInvoiceUC)
+	retryPolicyHandler := apihandler.NewRetryPolicyHandler(retryPolicyUC)
+	featureFlagHandler := apihandler.NewFeatureFlagHandler(featureFlagUC)
+	mutationWebhookHandler := apihandler.NewMutationWebhookHandler(mutationWebhookUC)
+	catalogHandler := apihandler.NewCatalogHandler(catalogUC)
+	supplierHandler := apihandler.NewSupplierHandler(supplierRegistryUC)
+	adminSigningUC := usecase.NewAdminSigningUsecase(adminSigningKeyRepo, userRepo)
+	impersonationUC := usecase.NewImpersonationUsecase(impersonationRepo, userRepo, authService, usecase.ImpersonationConfig{
+		TTL: cfg.Auth.ImpersonationTTL,
+	})
+	apihandler.SetImpersonationRecorder(impersonationUC)
+	apihandler.SetFeatureFlags(featureFlagUC)
+	apihandler.SetRequestBudget(apihandler.RequestBudgetConfig{
+		SyncWriteTimeout: cfg.API.SyncTimeout,
+		Reserve:          cfg.API.ResponseReserve,
+	})
+	impersonationHandler := apihandler.NewImpersonationHandler(impersonationUC)
+	loggingHandler := apihandler.NewLoggingHandler()
+	var chaosHandler *apihandler.ChaosHandler
+	if chaosInjector != nil {
+		chaosHandler = apihandler.NewChaosHandler(chaosInjector)
+	}
 
 	// Initialize metrics handler
 	metricsHandler := observability.NewMetricsHandler()
@@ -152,7 +943,7 @@ func main() {
 	router.GET("/live", metricsHandler.LivenessEndpoint())
 
 	// Setup API routes
-	apihandler.SetupRoutes(router, transactionHandler, productHandler, authHandler, authService, apiClientRepo)
+	apihandler.SetupRoutes(router, transactionHandler, productHandler, authHandler, routingOverrideHandler, notificationHandler, mutationHandler, mappingReviewHandler, securityHandler, reportHandler, schedulerHandler, feeHandler, statementHandler, supplierSLAHandler, destinationRuleHandler, chaosHandler, favoriteHandler, balanceHandler, quotaPlanHandler, userPriceHandler, supplierWebhookHandler, h2hPortalHandler, reconciliationHandler, cutoffScheduleHandler, priceListHandler, downlineHandler, retryPolicyHandler, loggingHandler, catalogHandler, supplierHandler, impersonationHandler, referralHandler, statusPageHandler, supplierInvoiceHandler, promotionHandler, voucherHandler, featureFlagHandler, mutationWebhookHandler, authService, apiClientRepo, nonceRepo, quotaUC, adminSigningUC, backpressureUC)
 
 	// Create HTTP server
 	server := &http.Server{
@@ -162,33 +953,44 @@ func main() {
 		WriteTimeout: time.Duration(cfg.API.TimeoutSeconds) * time.Second,
 	}
 
-	// Start server in a goroutine
-	go func() {
-		logger.Info("Starting server",
-			logger.String("port", cfg.App.Port),
-			logger.String("environment", cfg.App.Environment),
-		)
+	// HTTP intake stops first, letting open requests finish
+	app.Register(lifecycle.Component{
+		Name:      "http",
+		DependsOn: workers,
+		Start: func(ctx context.Context) error {
+			listener, err := net.Listen("tcp", server.Addr)
+			if err != nil {
+				return err
+			}
 
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatal("Failed to start server", logger.ErrorField(err))
-		}
-	}()
+			logger.Info("Starting server",
+				logger.String("port", cfg.App.Port),
+				logger.String("environment", cfg.App.Environment),
+			)
+			go func() {
+				if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+					logger.Fatal("Failed to start server", logger.ErrorField(err))
+				}
+			}()
+			return nil
+		},
+		Stop:        server.Shutdown,
+		StopTimeout: cfg.App.DrainTimeout,
+	})
+
+	if err := app.Start(context.Background()); err != nil {
+		logger.Fatal("Failed to start components", logger.ErrorField(err))
+	}
 
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	workerCancel()
+	sig := <-quit
 
-	logger.Info("Shutting down server...")
+	logger.Info("Shutting down server...", logger.String("signal", sig.String()))
 
-	// Create a deadline for shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	// Attempt graceful shutdown
-	if err := server.Shutdown(ctx); err != nil {
-		logger.Error("Server forced to shutdown", logger.ErrorField(err))
+	if err := app.Shutdown(); err != nil {
+		logger.Error("Shutdown did not complete cleanly", logger.ErrorField(err))
 	}
 
 	logger.Info("Server exited")
@@ -209,3 +1011,34 @@ func corsMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// connectDatabase opens the Postgres pool; with chaos enabled every
+// connection injects the configured database fault
+func connectDatabase(dsn string, injector *chaos.Injector, queryMetrics bool) (*sqlx.DB, error) {
+	if injector == nil && !queryMetrics {
+		return sqlx.Connect("postgres", dsn)
+	}
+
+	pqConnector, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Query metrics wrap the fault injector so injected latency shows up in
+	// the recorded query durations
+	var connector driver.Connector = pqConnector
+	if injector != nil {
+		connector = chaos.WrapConnector(connector, injector)
+	}
+	if queryMetrics {
+		connector = observability.WrapConnector(connector)
+	}
+
+	db := sqlx.NewDb(sql.OpenDB(connector), "postgres")
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
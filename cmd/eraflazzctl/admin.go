@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/internal/repository/postgres"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+// runSeedAdmin creates an admin user. The password is read from stdin so it
+// stays out of the shell history.
+func runSeedAdmin(app *app, args []string) error {
+	flags := flag.NewFlagSet("seed-admin", flag.ContinueOnError)
+	email := flags.String("email", "", "Email of the admin (required)")
+	username := flags.String("username", "", "Username, default the part of the email before @")
+	fullName := flags.String("name", "Administrator", "Full name")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	*email = strings.ToLower(strings.TrimSpace(*email))
+	if !utils.ValidateEmail(*email) {
+		return fmt.Errorf("a valid -email is required")
+	}
+	if *username == "" {
+		*username = strings.Split(*email, "@")[0]
+	}
+
+	userRepo := postgres.NewUserRepository(app.db)
+	if existing, _ := userRepo.GetByEmail(*email); existing != nil {
+		return fmt.Errorf("user with email %s already exists", *email)
+	}
+	if existing, _ := userRepo.GetByUsername(*username); existing != nil {
+		return fmt.Errorf("username %s is taken", *username)
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		return fmt.Errorf("failed to read password: %w", err)
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if len(password) < 8 {
+		return fmt.Errorf("password must be at least 8 characters")
+	}
+	passwordHash, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	user := &domain.User{
+		ID:           utils.GenerateUUID(),
+		Username:     *username,
+		Email:        *email,
+		PasswordHash: passwordHash,
+		FullName:     fullName,
+		Level:        domain.LevelAdmin,
+		IsActive:     true,
+		IsVerified:   true,
+	}
+	if err := userRepo.Create(user); err != nil {
+		return err
+	}
+
+	t := newTable("ID", "USERNAME", "EMAIL", "ROLE")
+	t.row(user.ID, user.Username, user.Email, domain.MapLevelToRole(user.Level))
+	t.flush()
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/alfanzaky/eraflazz/internal/repository/postgres"
+	"github.com/alfanzaky/eraflazz/internal/usecase"
+)
+
+func runBalance(app *app, args []string) error {
+	_, rest, err := subcommand(args, "recompute")
+	if err != nil {
+		return err
+	}
+	return runBalanceRecompute(app, rest)
+}
+
+// runBalanceRecompute compares a user's stored balance with the sum of their
+// mutations and, with -apply, overwrites the balance with the ledger
+func runBalanceRecompute(app *app, args []string) error {
+	flags := flag.NewFlagSet("balance recompute", flag.ContinueOnError)
+	userID := flags.String("user", "", "User ID (required)")
+	apply := flags.Bool("apply", false, "Set the stored balance to the ledger balance")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	reconciliationUC := usecase.NewReconciliationUsecase(postgres.NewReconciliationRepository(app.db), usecase.ReconciliationConfig{
+		BatchSize: app.cfg.Reconcile.BatchSize,
+	})
+	balance, err := reconciliationUC.RecomputeBalance(*userID, *apply)
+	if err != nil {
+		return err
+	}
+
+	t := newTable("USER ID", "STORED", "LEDGER", "DIFFERENCE", "MUTATIONS", "MATCHES")
+	t.row(balance.UserID, formatAmount(balance.StoredBalance), formatAmount(balance.LedgerBalance),
+		formatAmount(balance.StoredBalance-balance.LedgerBalance), strconv.Itoa(balance.MutationCount), strconv.FormatBool(balance.Matches))
+	t.flush()
+
+	switch {
+	case balance.Matches:
+		fmt.Println("\nBalance matches the ledger")
+	case *apply:
+		fmt.Println("\nStored balance set to the ledger balance")
+	default:
+		fmt.Println("\nBalance differs from the ledger; run again with -apply to correct it")
+	}
+	return nil
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
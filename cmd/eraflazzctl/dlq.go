@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/internal/repository/postgres"
+)
+
+// dlqPageSize is the number of failed events requeued per query by replay -all
+const dlqPageSize = 500
+
+// runDLQ works on the dead letter queue: outbox events that exhausted their
+// publish attempts
+func runDLQ(app *app, args []string) error {
+	name, rest, err := subcommand(args, "list", "show", "replay")
+	if err != nil {
+		return err
+	}
+
+	eventRepo := postgres.NewEventRepository(app.db)
+	switch name {
+	case "list":
+		return runDLQList(eventRepo, rest)
+	case "show":
+		return runDLQShow(eventRepo, rest)
+	default:
+		return runDLQReplay(eventRepo, rest)
+	}
+}
+
+func runDLQList(eventRepo domain.EventRepository, args []string) error {
+	flags := flag.NewFlagSet("dlq list", flag.ContinueOnError)
+	limit := flags.Int("limit", 50, "Maximum number of events")
+	offset := flags.Int("offset", 0, "Number of events to skip")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *limit <= 0 || *offset < 0 {
+		return fmt.Errorf("-limit must be positive and -offset not negative")
+	}
+
+	events, err := eventRepo.ListFailed(*limit, *offset)
+	if err != nil {
+		return err
+	}
+
+	t := newTable("ID", "EVENT TYPE", "AGGREGATE", "ATTEMPTS", "CREATED AT", "LAST ERROR")
+	for _, event := range events {
+		t.row(event.ID, event.EventType, event.AggregateType+"/"+event.AggregateID,
+			strconv.Itoa(event.Attempts), event.CreatedAt.Format(time.RFC3339), truncate(formatString(event.LastError), 60))
+	}
+	t.flush()
+	fmt.Printf("\n%d failed event(s)\n", len(events))
+	return nil
+}
+
+func runDLQShow(eventRepo domain.EventRepository, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: dlq show EVENT_ID")
+	}
+
+	event, err := eventRepo.GetByID(args[0])
+	if err != nil {
+		return err
+	}
+
+	t := newTable("FIELD", "VALUE")
+	t.row("id", event.ID)
+	t.row("event_type", event.EventType)
+	t.row("aggregate", event.AggregateType+"/"+event.AggregateID)
+	t.row("status", event.Status)
+	t.row("attempts", strconv.Itoa(event.Attempts))
+	t.row("last_error", formatString(event.LastError))
+	t.row("created_at", event.CreatedAt.Format(time.RFC3339))
+	t.row("published_at", formatTime(event.PublishedAt))
+	t.flush()
+
+	var payload bytes.Buffer
+	if err := json.Indent(&payload, []byte(event.Payload), "", "  "); err != nil {
+		payload.Reset()
+		payload.WriteString(event.Payload)
+	}
+	fmt.Printf("\nPayload:\n%s\n", payload.String())
+	return nil
+}
+
+// runDLQReplay puts failed events back in the outbox; the event relay worker
+// publishes them on its next pass
+func runDLQReplay(eventRepo domain.EventRepository, args []string) error {
+	flags := flag.NewFlagSet("dlq replay", flag.ContinueOnError)
+	all := flags.Bool("all", false, "Replay every failed event")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ids := flags.Args()
+	if *all == (len(ids) > 0) {
+		return fmt.Errorf("pass event IDs or -all")
+	}
+
+	if *all {
+		// Requeued events leave the failed list, so every page starts at 0
+		for {
+			events, err := eventRepo.ListFailed(dlqPageSize, 0)
+			if err != nil {
+				return err
+			}
+			for _, event := range events {
+				ids = append(ids, event.ID)
+				if err := eventRepo.Requeue(event.ID); err != nil {
+					return err
+				}
+			}
+			if len(events) < dlqPageSize {
+				break
+			}
+		}
+		fmt.Printf("%d event(s) requeued\n", len(ids))
+		return nil
+	}
+
+	t := newTable("ID", "RESULT")
+	var failed int
+	for _, id := range ids {
+		if err := eventRepo.Requeue(id); err != nil {
+			failed++
+			t.row(id, err.Error())
+			continue
+		}
+		t.row(id, "requeued")
+	}
+	t.flush()
+
+	if failed > 0 {
+		return fmt.Errorf("%d event(s) not requeued", failed)
+	}
+	return nil
+}
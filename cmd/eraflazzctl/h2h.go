@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/repository/postgres"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+func runH2H(app *app, args []string) error {
+	_, rest, err := subcommand(args, "rotate-secret")
+	if err != nil {
+		return err
+	}
+	return runRotateSecret(app, rest)
+}
+
+// runRotateSecret replaces the secret of an H2H client. The previous secret
+// stays valid for the grace period so the client can switch without downtime.
+func runRotateSecret(app *app, args []string) error {
+	flags := flag.NewFlagSet("h2h rotate-secret", flag.ContinueOnError)
+	clientID := flags.String("client", "", "Client ID of the API client (required)")
+	grace := flags.Duration("grace", app.cfg.H2H.SecretGracePeriod, "How long the previous secret stays valid, 0 revokes it now")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *clientID == "" {
+		return fmt.Errorf("-client is required")
+	}
+	if *grace < 0 {
+		return fmt.Errorf("-grace must not be negative")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	clientRepo := postgres.NewAPIClientRepository(app.db.DB)
+	client, err := clientRepo.FindByClientID(ctx, *clientID)
+	if err != nil {
+		return err
+	}
+
+	secret := utils.GenerateRandomString(64)
+	graceUntil := time.Now().Add(*grace)
+	if err := clientRepo.RotateSecret(ctx, client.ClientID, secret, graceUntil); err != nil {
+		return err
+	}
+
+	t := newTable("CLIENT ID", "NEW SECRET", "PREVIOUS SECRET VALID UNTIL")
+	t.row(client.ClientID, secret, graceUntil.Format(time.RFC3339))
+	t.flush()
+	return nil
+}
@@ -0,0 +1,146 @@
+// Command eraflazzctl runs common operations tasks directly against the
+// database, reusing the API's repositories and use cases. It reads the same
+// environment (.env) as the API.
+//
+// Usage:
+//
+//	eraflazzctl seed-admin -email admin@example.com [-username admin] [-name "Admin"]
+//	eraflazzctl h2h rotate-secret -client CLIENT_ID [-grace 24h]
+//	eraflazzctl dlq list [-limit 50] [-offset 0]
+//	eraflazzctl dlq show EVENT_ID
+//	eraflazzctl dlq replay [-all] [EVENT_ID ...]
+//	eraflazzctl prices sync [-supplier SUPPLIER_ID]
+//	eraflazzctl balance recompute -user USER_ID [-apply]
+//	eraflazzctl profit backfill [-from YYYY-MM-DD] [-to YYYY-MM-DD] [-window 24h]
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+
+	"github.com/alfanzaky/eraflazz/config"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+// command runs one subcommand with the arguments following its name
+type command struct {
+	usage   string
+	summary string
+	run     func(app *app, args []string) error
+}
+
+var commands = map[string]command{
+	"seed-admin": {"seed-admin -email EMAIL [-username NAME] [-name FULL_NAME]", "create an admin user", runSeedAdmin},
+	"h2h":        {"h2h rotate-secret -client CLIENT_ID [-grace 24h]", "rotate the secret of an H2H client", runH2H},
+	"dlq":        {"dlq list|show|replay", "inspect and replay failed outbox events", runDLQ},
+	"prices":     {"prices sync [-supplier SUPPLIER_ID]", "pull supplier price lists now", runPrices},
+	"balance":    {"balance recompute -user USER_ID [-apply]", "compare a balance with the ledger", runBalance},
+	"profit":     {"profit backfill [-from DATE] [-to DATE] [-window 24h]", "recompute the stored profit of past transactions", runProfit},
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "help" || os.Args[1] == "-h" || os.Args[1] == "--help" {
+		printUsage()
+		if len(os.Args) < 2 {
+			os.Exit(2)
+		}
+		return
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "eraflazzctl: unknown command %q\n\n", os.Args[1])
+		printUsage()
+		os.Exit(2)
+	}
+
+	app, err := newApp()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "eraflazzctl:", err)
+		os.Exit(1)
+	}
+	defer app.close()
+
+	if err := cmd.run(app, os.Args[2:]); err != nil {
+		// The flag package already printed the usage
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Fprintln(os.Stderr, "eraflazzctl:", err)
+		app.close()
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "Usage: eraflazzctl <command> [flags]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	w := tabwriter.NewWriter(os.Stderr, 0, 0, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s\t%s\n", commands[name].usage, commands[name].summary)
+	}
+	_ = w.Flush()
+	fmt.Fprintln(os.Stderr, "\nRun eraflazzctl <command> -h for the flags of a command.")
+}
+
+// app holds the configuration and connections shared by the commands
+type app struct {
+	cfg *config.Config
+	db  *sqlx.DB
+}
+
+func newApp() (*app, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// Keep informational logs of the use cases out of the command output
+	logger.Init(cfg.App.Environment)
+	level := cfg.Logging.Level
+	if level == "" {
+		level = "warn"
+	}
+	if err := logger.SetLevel(level); err != nil {
+		return nil, err
+	}
+
+	db, err := sqlx.Connect("postgres", cfg.Database.GetDSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	return &app{cfg: cfg, db: db}, nil
+}
+
+func (a *app) close() {
+	a.db.Close()
+	logger.Close()
+}
+
+// subcommand splits "list -limit 5" into the subcommand name and its flags
+func subcommand(args []string, names ...string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, fmt.Errorf("missing subcommand, one of: %s", strings.Join(names, ", "))
+	}
+	for _, name := range names {
+		if args[0] == name {
+			return name, args[1:], nil
+		}
+	}
+	return "", nil, fmt.Errorf("unknown subcommand %q, one of: %s", args[0], strings.Join(names, ", "))
+}
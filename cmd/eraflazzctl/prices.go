@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	digiflazzadapter "github.com/alfanzaky/eraflazz/internal/adapter/digiflazz"
+	adapterfactory "github.com/alfanzaky/eraflazz/internal/adapter/factory"
+	"github.com/alfanzaky/eraflazz/internal/adapter/sandbox"
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/internal/repository/postgres"
+	"github.com/alfanzaky/eraflazz/internal/usecase"
+	"github.com/alfanzaky/eraflazz/pkg/httpclient"
+)
+
+func runPrices(app *app, args []string) error {
+	_, rest, err := subcommand(args, "sync")
+	if err != nil {
+		return err
+	}
+	return runPriceSync(app, rest)
+}
+
+// runPriceSync pulls the price list of one or every active supplier, as the
+// price sync job does, and runs the margin guard on changed products
+func runPriceSync(app *app, args []string) error {
+	flags := flag.NewFlagSet("prices sync", flag.ContinueOnError)
+	supplierID := flags.String("supplier", "", "Supplier ID, default every active supplier")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	pricingUC := usecase.NewPricingUsecase(
+		postgres.NewProductRepository(app.db),
+		postgres.NewProductMappingRepository(app.db),
+		postgres.NewSupplierRepository(app.db),
+		postgres.NewPriceHistoryRepository(app.db),
+		postgres.NewUserRepository(app.db),
+		app.adapterFactory(),
+		nil, // Cached routing snapshots expire on their own
+		postgres.NewEventRepository(app.db),
+		usecase.PricingConfig{
+			MinMargin:    app.cfg.Pricing.MinMargin,
+			MarginAction: app.cfg.Pricing.MarginAction,
+		},
+	)
+
+	var results []*domain.PriceSyncResult
+	if *supplierID != "" {
+		result, err := pricingUC.SyncSupplierPrices(*supplierID)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+	} else {
+		all, err := pricingUC.SyncAllSupplierPrices()
+		if err != nil {
+			return err
+		}
+		results = all
+	}
+
+	t := newTable("SUPPLIER", "CHECKED", "UPDATED", "UNMATCHED", "FLAGGED", "DEACTIVATED")
+	for _, result := range results {
+		t.row(result.SupplierCode, strconv.Itoa(result.Checked), strconv.Itoa(result.Updated),
+			strconv.Itoa(result.Unmatched), strconv.Itoa(result.Flagged), strconv.Itoa(result.Deactivated))
+	}
+	t.flush()
+	return nil
+}
+
+// adapterFactory builds supplier adapters the way the API does, including
+// the supplier sandbox, but without fault injection
+func (a *app) adapterFactory() domain.SupplierAdapterFactory {
+	httpConfig := httpclient.Config{
+		MaxRetries:          a.cfg.Suppliers.HTTP.MaxRetries,
+		AttemptTimeout:      a.cfg.Suppliers.HTTP.AttemptTimeout,
+		RetryBackoff:        a.cfg.Suppliers.HTTP.RetryBackoff,
+		MaxIdleConnsPerHost: a.cfg.Suppliers.HTTP.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     a.cfg.Suppliers.HTTP.MaxConnsPerHost,
+		IdleConnTimeout:     a.cfg.Suppliers.HTTP.IdleConnTimeout,
+	}
+	transport := httpclient.NewTransport(httpConfig)
+
+	factory := adapterfactory.NewSupplierAdapterFactory()
+	factory.RegisterBuilder(domain.SupplierCodeDigiflazz, func(supplier *domain.Supplier) (domain.SupplierAdapter, error) {
+		timeoutSeconds := supplier.TimeoutSeconds
+		if timeoutSeconds <= 0 {
+			timeoutSeconds = a.cfg.Suppliers.Digiflazz.TimeoutSeconds
+		}
+		var next http.RoundTripper = transport
+		sandboxTransport, err := sandbox.NewTransport(sandbox.Config{
+			Mode: a.cfg.Suppliers.Sandbox.Mode,
+			Dir:  filepath.Join(a.cfg.Suppliers.Sandbox.Dir, strings.ToLower(supplier.Code)),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if sandboxTransport != nil {
+			next = sandboxTransport
+		}
+		client := httpclient.New(httpConfig, next, time.Duration(timeoutSeconds)*time.Second)
+		return digiflazzadapter.NewAdapter(a.cfg.Suppliers.Digiflazz, supplier, client)
+	})
+	return factory
+}
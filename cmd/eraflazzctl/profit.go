@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/repository/postgres"
+)
+
+func runProfit(app *app, args []string) error {
+	_, rest, err := subcommand(args, "backfill")
+	if err != nil {
+		return err
+	}
+	return runProfitBackfill(app, rest)
+}
+
+// runProfitBackfill recomputes the stored profit of historical transactions
+// one window at a time, so each statement stays within a partition and holds
+// its row locks briefly
+func runProfitBackfill(app *app, args []string) error {
+	flags := flag.NewFlagSet("profit backfill", flag.ContinueOnError)
+	fromFlag := flags.String("from", "", "First creation date, YYYY-MM-DD (default: oldest transaction)")
+	toFlag := flags.String("to", "", "Last creation date, YYYY-MM-DD, inclusive (default: today)")
+	window := flags.Duration("window", 24*time.Hour, "Creation time covered by one update")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *window <= 0 {
+		return fmt.Errorf("-window must be positive")
+	}
+
+	transactionRepo := postgres.NewTransactionRepository(app.db)
+
+	var from time.Time
+	if *fromFlag != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", *fromFlag, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid -from: use YYYY-MM-DD")
+		}
+		from = parsed
+	} else {
+		first, err := transactionRepo.GetFirstCreatedAt()
+		if err != nil {
+			return err
+		}
+		if first == nil {
+			fmt.Println("No transactions to backfill")
+			return nil
+		}
+		from = *first
+	}
+
+	to := time.Now().Add(time.Second)
+	if *toFlag != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", *toFlag, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid -to: use YYYY-MM-DD")
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+	if !to.After(from) {
+		return fmt.Errorf("-to must not be before -from")
+	}
+
+	t := newTable("FROM", "TO", "UPDATED")
+	var total int64
+	for start := from; start.Before(to); start = start.Add(*window) {
+		end := start.Add(*window)
+		if end.After(to) {
+			end = to
+		}
+
+		updated, err := transactionRepo.RecomputeProfit(start, end)
+		if err != nil {
+			t.flush()
+			return err
+		}
+		total += updated
+		if updated > 0 {
+			t.row(formatTime(&start), formatTime(&end), strconv.FormatInt(updated, 10))
+		}
+	}
+	t.flush()
+
+	fmt.Printf("\n%d transactions updated\n", total)
+	return nil
+}
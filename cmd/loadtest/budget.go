@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+)
+
+// Budget is the performance budget of the transaction path. A zero limit is
+// not checked.
+type Budget struct {
+	Simulate   PhaseBudget `json:"simulate"`
+	Create     PhaseBudget `json:"create"`     // Queries cover creating and processing a transaction
+	Completion PhaseBudget `json:"completion"` // Errors are transactions not ending in SUCCESS
+}
+
+// PhaseBudget limits one phase of the run
+type PhaseBudget struct {
+	P95Ms             float64            `json:"p95_ms"`
+	MaxErrorRate      float64            `json:"max_error_rate"`
+	QueriesPerRequest map[string]float64 `json:"queries_per_request,omitempty"` // Keyed by table, or "total"
+}
+
+// phaseResult is what one phase of the run measured
+type phaseResult struct {
+	Name              string      `json:"name"`
+	Requests          int         `json:"requests"`
+	Errors            int         `json:"errors"`
+	ErrorRate         float64     `json:"error_rate"`
+	P50Ms             float64     `json:"p50_ms"`
+	P95Ms             float64     `json:"p95_ms"`
+	P99Ms             float64     `json:"p99_ms"`
+	QueriesPerRequest queryCounts `json:"queries_per_request,omitempty"`
+}
+
+func newPhaseResult(name string, latencies []time.Duration, errors int) *phaseResult {
+	result := &phaseResult{
+		Name:     name,
+		Requests: len(latencies) + errors,
+		Errors:   errors,
+	}
+	if result.Requests > 0 {
+		result.ErrorRate = float64(errors) / float64(result.Requests)
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	result.P50Ms = percentileMs(latencies, 0.50)
+	result.P95Ms = percentileMs(latencies, 0.95)
+	result.P99Ms = percentileMs(latencies, 0.99)
+
+	return result
+}
+
+// percentileMs returns the nearest-rank percentile of sorted latencies
+func percentileMs(sorted []time.Duration, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return float64(sorted[rank].Microseconds()) / 1000
+}
+
+func loadBudget(path string) (*Budget, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read budget: %w", err)
+	}
+
+	var budget Budget
+	if err := json.Unmarshal(data, &budget); err != nil {
+		return nil, fmt.Errorf("invalid budget %s: %w", path, err)
+	}
+	return &budget, nil
+}
+
+func saveBudget(path string, budget *Budget) error {
+	data, err := json.MarshalIndent(budget, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// check returns every budget limit the result exceeds
+func (b PhaseBudget) check(result *phaseResult) []string {
+	var violations []string
+
+	if b.P95Ms > 0 && result.P95Ms > b.P95Ms {
+		violations = append(violations, fmt.Sprintf("%s: p95 %.1fms exceeds budget %.1fms", result.Name, result.P95Ms, b.P95Ms))
+	}
+	if result.ErrorRate > b.MaxErrorRate {
+		violations = append(violations, fmt.Sprintf("%s: error rate %.2f%% exceeds budget %.2f%%", result.Name, result.ErrorRate*100, b.MaxErrorRate*100))
+	}
+
+	tables := make([]string, 0, len(b.QueriesPerRequest))
+	for table := range b.QueriesPerRequest {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		limit := b.QueriesPerRequest[table]
+		if limit > 0 && result.QueriesPerRequest[table] > limit {
+			violations = append(violations, fmt.Sprintf("%s: %.2f queries on %s per request exceeds budget %.0f", result.Name, result.QueriesPerRequest[table], table, limit))
+		}
+	}
+
+	return violations
+}
+
+// recordBudget derives a budget from a baseline run: latency limits get the
+// given headroom, query limits are rounded up and error rates are kept from
+// the current budget. Tables queried less than once per two requests are
+// left out, they are background work rather than the request path.
+func recordBudget(current *Budget, simulate, create, completion *phaseResult, headroom float64) *Budget {
+	phase := func(existing PhaseBudget, result *phaseResult) PhaseBudget {
+		recorded := PhaseBudget{
+			P95Ms:        math.Ceil(result.P95Ms * headroom),
+			MaxErrorRate: existing.MaxErrorRate,
+		}
+		for table, count := range result.QueriesPerRequest {
+			if count < 0.5 {
+				continue
+			}
+			if recorded.QueriesPerRequest == nil {
+				recorded.QueriesPerRequest = make(map[string]float64)
+			}
+			recorded.QueriesPerRequest[table] = math.Ceil(count)
+		}
+		return recorded
+	}
+
+	return &Budget{
+		Simulate:   phase(current.Simulate, simulate),
+		Create:     phase(current.Create, create),
+		Completion: phase(current.Completion, completion),
+	}
+}
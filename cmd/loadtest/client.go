@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiClient calls the API as one logged in user
+type apiClient struct {
+	baseURL string
+	http    *http.Client
+	token   string
+}
+
+type apiResponse struct {
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+type transactionResult struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+func newAPIClient(baseURL string, timeout time.Duration) *apiClient {
+	return &apiClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				MaxIdleConns:        256,
+				MaxIdleConnsPerHost: 256,
+				IdleConnTimeout:     90 * time.Second,
+			},
+		},
+	}
+}
+
+// login stores the access token of the user
+func (c *apiClient) login(ctx context.Context, email, password string) error {
+	var resp struct {
+		Token string `json:"token"`
+	}
+	status, err := c.do(ctx, http.MethodPost, "/api/v1/auth/login", map[string]string{
+		"email":    email,
+		"password": password,
+	}, &resp)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK || resp.Token == "" {
+		return fmt.Errorf("login failed with status %d", status)
+	}
+
+	c.token = resp.Token
+	return nil
+}
+
+// createTransaction posts a transaction, or a dry-run when simulate is set
+func (c *apiClient) createTransaction(ctx context.Context, productCode, destination string, simulate bool) (*transactionResult, error) {
+	body := map[string]interface{}{
+		"product_code":       productCode,
+		"destination_number": destination,
+		"simulate":           simulate,
+	}
+
+	var resp apiResponse
+	status, err := c.do(ctx, http.MethodPost, "/api/v1/transactions", body, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK && status != http.StatusCreated {
+		return nil, fmt.Errorf("status %d: %s", status, resp.Message)
+	}
+	if simulate {
+		return &transactionResult{}, nil
+	}
+
+	var result transactionResult
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("invalid transaction response: %w", err)
+	}
+	return &result, nil
+}
+
+func (c *apiClient) getTransaction(ctx context.Context, id string) (*transactionResult, error) {
+	var resp apiResponse
+	status, err := c.do(ctx, http.MethodGet, "/api/v1/transactions/"+id, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("status %d: %s", status, resp.Message)
+	}
+
+	var result transactionResult
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("invalid transaction response: %w", err)
+	}
+	return &result, nil
+}
+
+func (c *apiClient) do(ctx context.Context, method, path string, body, out interface{}) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil && err != io.EOF {
+			return resp.StatusCode, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// queryCounts maps a table, or "total", to the number of database queries
+type queryCounts map[string]float64
+
+const totalQueries = "total"
+
+// scrapeQueryCounts reads the db_query_duration_seconds counters from the
+// Prometheus endpoint and sums them per table
+func (c *apiClient) scrapeQueryCounts(ctx context.Context) (queryCounts, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/metrics", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scrape metrics: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to scrape metrics: status %d", resp.StatusCode)
+	}
+
+	counts := queryCounts{}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "db_query_duration_seconds_count{") {
+			continue
+		}
+
+		end := strings.LastIndexByte(line, '}')
+		if end < 0 {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(line[end+1:]), 64)
+		if err != nil {
+			continue
+		}
+
+		table := metricLabel(line[:end], "table")
+		counts[table] += value
+		counts[totalQueries] += value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read metrics: %w", err)
+	}
+
+	return counts, nil
+}
+
+// metricLabel extracts a label value from the label set of a metric line
+func metricLabel(series, name string) string {
+	key := name + `="`
+	start := strings.Index(series, key)
+	if start < 0 {
+		return ""
+	}
+	value := series[start+len(key):]
+	if end := strings.IndexByte(value, '"'); end >= 0 {
+		return value[:end]
+	}
+	return value
+}
+
+// sub returns the queries made between two scrapes
+func (q queryCounts) sub(before queryCounts) queryCounts {
+	delta := queryCounts{}
+	for table, count := range q {
+		if diff := count - before[table]; diff > 0 {
+			delta[table] = diff
+		}
+	}
+	return delta
+}
+
+// scale multiplies every count by factor
+func (q queryCounts) scale(factor float64) queryCounts {
+	scaled := queryCounts{}
+	for table, count := range q {
+		scaled[table] = count * factor
+	}
+	return scaled
+}
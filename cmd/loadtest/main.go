@@ -0,0 +1,338 @@
+// Command loadtest drives the transaction path of a running API with
+// concurrent requests and checks latency, error rate and database queries per
+// request against a performance budget. The API must run with the supplier
+// sandbox in replay mode and DB_QUERY_METRICS=true; loadtest/seed.sql creates
+// the user, product and supplier it expects.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type options struct {
+	baseURL      string
+	email        string
+	password     string
+	productCode  string
+	destination  string // fmt pattern receiving the request number
+	requests     int
+	concurrency  int
+	warmup       int
+	pollInterval time.Duration
+	waitTimeout  time.Duration
+	settle       time.Duration
+	budgetPath   string
+	reportPath   string
+	record       bool
+	headroom     float64
+}
+
+func main() {
+	opts := options{}
+	flag.StringVar(&opts.baseURL, "base-url", "http://localhost:8080", "API base URL")
+	flag.StringVar(&opts.email, "email", "loadtest@eraflazz.local", "Email of the load test user")
+	flag.StringVar(&opts.password, "password", "LoadTest123", "Password of the load test user")
+	flag.StringVar(&opts.productCode, "product", "XLD10", "Product code to buy")
+	flag.StringVar(&opts.destination, "destination", "6281%04d5678", "Destination number pattern, must match the sandbox fixtures")
+	flag.IntVar(&opts.requests, "requests", 100, "Requests per phase")
+	flag.IntVar(&opts.concurrency, "concurrency", 10, "Concurrent requests")
+	flag.IntVar(&opts.warmup, "warmup", 10, "Unmeasured simulations before the run")
+	flag.DurationVar(&opts.pollInterval, "poll-interval", 50*time.Millisecond, "Interval between transaction status checks")
+	flag.DurationVar(&opts.waitTimeout, "wait-timeout", 60*time.Second, "Maximum time for a transaction to reach a final status")
+	flag.DurationVar(&opts.settle, "settle", 2*time.Second, "Wait for asynchronous follow-up work before counting queries")
+	flag.StringVar(&opts.budgetPath, "budget", "loadtest/budget.json", "Performance budget file, empty skips the checks")
+	flag.StringVar(&opts.reportPath, "report", "", "Write the measured results as JSON to this file")
+	flag.BoolVar(&opts.record, "record", false, "Write the measured results to the budget file instead of checking them")
+	flag.Float64Var(&opts.headroom, "headroom", 2, "Latency headroom applied by -record")
+	flag.Parse()
+
+	if err := run(opts); err != nil {
+		fmt.Fprintln(os.Stderr, "loadtest:", err)
+		os.Exit(1)
+	}
+}
+
+func run(opts options) error {
+	if opts.requests <= 0 || opts.concurrency <= 0 {
+		return fmt.Errorf("requests and concurrency must be positive")
+	}
+
+	ctx := context.Background()
+	client := newAPIClient(opts.baseURL, 30*time.Second)
+	if err := client.login(ctx, opts.email, opts.password); err != nil {
+		return err
+	}
+
+	// Warm up connection pools and caches
+	runConcurrently(opts.warmup, opts.concurrency, func(i int) {
+		_, _ = client.createTransaction(ctx, opts.productCode, fmt.Sprintf(opts.destination, i), true)
+	})
+
+	simulate, err := runSimulatePhase(ctx, client, opts)
+	if err != nil {
+		return err
+	}
+	create, completion, err := runCreatePhase(ctx, client, opts)
+	if err != nil {
+		return err
+	}
+
+	results := []*phaseResult{simulate, create, completion}
+	printResults(results)
+
+	if opts.reportPath != "" {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(opts.reportPath, append(data, '\n'), 0o644); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+	}
+
+	if opts.budgetPath == "" {
+		return nil
+	}
+
+	budget, err := loadBudget(opts.budgetPath)
+	if err != nil {
+		if !opts.record || !os.IsNotExist(err) {
+			return err
+		}
+		budget = &Budget{}
+	}
+
+	if opts.record {
+		if err := saveBudget(opts.budgetPath, recordBudget(budget, simulate, create, completion, opts.headroom)); err != nil {
+			return fmt.Errorf("failed to write budget: %w", err)
+		}
+		fmt.Println("\nBudget recorded to", opts.budgetPath)
+		return nil
+	}
+
+	var violations []string
+	violations = append(violations, budget.Simulate.check(simulate)...)
+	violations = append(violations, budget.Create.check(create)...)
+	violations = append(violations, budget.Completion.check(completion)...)
+	if len(violations) > 0 {
+		fmt.Println("\nPerformance budget exceeded:")
+		for _, violation := range violations {
+			fmt.Println("  -", violation)
+		}
+		return fmt.Errorf("%d budget violation(s)", len(violations))
+	}
+
+	fmt.Println("\nPerformance budget met")
+	return nil
+}
+
+// runSimulatePhase measures dry-run requests, which cover validation, pricing
+// and routing without touching balances or the supplier
+func runSimulatePhase(ctx context.Context, client *apiClient, opts options) (*phaseResult, error) {
+	before, err := client.scrapeQueryCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var recorder latencyRecorder
+	runConcurrently(opts.requests, opts.concurrency, func(i int) {
+		start := time.Now()
+		_, err := client.createTransaction(ctx, opts.productCode, fmt.Sprintf(opts.destination, i), true)
+		recorder.record(time.Since(start), err)
+	})
+
+	after, err := client.scrapeQueryCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := recorder.result("simulate")
+	result.QueriesPerRequest = after.sub(before).scale(1 / float64(opts.requests))
+	return result, nil
+}
+
+// runCreatePhase creates transactions and follows each one until the worker
+// finished processing it. Queries made by the status checks are measured
+// afterwards and subtracted, so the query counts cover creating and
+// processing only.
+func runCreatePhase(ctx context.Context, client *apiClient, opts options) (*phaseResult, *phaseResult, error) {
+	before, err := client.scrapeQueryCounts(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		created   latencyRecorder
+		completed latencyRecorder
+		polls     int64
+		mu        sync.Mutex
+		ids       []string
+	)
+	runConcurrently(opts.requests, opts.concurrency, func(i int) {
+		start := time.Now()
+		trx, err := client.createTransaction(ctx, opts.productCode, fmt.Sprintf(opts.destination, i), false)
+		created.record(time.Since(start), err)
+		if err != nil {
+			return
+		}
+
+		mu.Lock()
+		ids = append(ids, trx.ID)
+		mu.Unlock()
+
+		status, n := waitFinal(ctx, client, trx, opts.pollInterval, opts.waitTimeout)
+		atomic.AddInt64(&polls, int64(n))
+		if status != "SUCCESS" {
+			err = fmt.Errorf("transaction %s ended %s", trx.ID, status)
+		}
+		completed.record(time.Since(start), err)
+	})
+
+	time.Sleep(opts.settle)
+	after, err := client.scrapeQueryCounts(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	createResult := created.result("create")
+	completionResult := completed.result("completion")
+	if len(ids) == 0 {
+		return createResult, completionResult, nil
+	}
+
+	perPoll, err := measureLookups(ctx, client, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	queries := after.sub(before).sub(perPoll.scale(float64(polls)))
+	createResult.QueriesPerRequest = queries.scale(1 / float64(len(ids)))
+	return createResult, completionResult, nil
+}
+
+// waitFinal polls the transaction until it leaves PENDING/PROCESSING and
+// returns its final status and the number of status checks made
+func waitFinal(ctx context.Context, client *apiClient, trx *transactionResult, interval, timeout time.Duration) (string, int) {
+	deadline := time.Now().Add(timeout)
+	status := trx.Status
+	polls := 0
+
+	for status == "PENDING" || status == "PROCESSING" {
+		if time.Now().After(deadline) {
+			return "TIMED_OUT_WAITING", polls
+		}
+		time.Sleep(interval)
+
+		current, err := client.getTransaction(ctx, trx.ID)
+		polls++
+		if err != nil {
+			continue
+		}
+		status = current.Status
+	}
+
+	return status, polls
+}
+
+// measureLookups returns the queries made by one transaction status check
+func measureLookups(ctx context.Context, client *apiClient, ids []string) (queryCounts, error) {
+	lookups := len(ids)
+	if lookups > 50 {
+		lookups = 50
+	}
+
+	before, err := client.scrapeQueryCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < lookups; i++ {
+		_, _ = client.getTransaction(ctx, ids[i])
+	}
+	after, err := client.scrapeQueryCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return after.sub(before).scale(1 / float64(lookups)), nil
+}
+
+// runConcurrently calls fn for 0..n-1 on the given number of goroutines
+func runConcurrently(n, concurrency int, fn func(i int)) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// latencyRecorder collects latencies of successful requests and counts errors
+type latencyRecorder struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	errors    int
+	firstErr  error
+}
+
+func (r *latencyRecorder) record(latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.errors++
+		if r.firstErr == nil {
+			r.firstErr = err
+		}
+		return
+	}
+	r.latencies = append(r.latencies, latency)
+}
+
+func (r *latencyRecorder) result(name string) *phaseResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.firstErr != nil {
+		fmt.Fprintf(os.Stderr, "%s: %d error(s), first: %v\n", name, r.errors, r.firstErr)
+	}
+	return newPhaseResult(name, r.latencies, r.errors)
+}
+
+func printResults(results []*phaseResult) {
+	fmt.Printf("%-12s %9s %7s %10s %10s %10s\n", "phase", "requests", "errors", "p50 ms", "p95 ms", "p99 ms")
+	for _, r := range results {
+		fmt.Printf("%-12s %9d %7d %10.1f %10.1f %10.1f\n", r.Name, r.Requests, r.Errors, r.P50Ms, r.P95Ms, r.P99Ms)
+	}
+
+	for _, r := range results {
+		if len(r.QueriesPerRequest) == 0 {
+			continue
+		}
+		fmt.Printf("\nDB queries per %s request:\n", r.Name)
+
+		tables := make([]string, 0, len(r.QueriesPerRequest))
+		for table := range r.QueriesPerRequest {
+			tables = append(tables, table)
+		}
+		sort.Strings(tables)
+		for _, table := range tables {
+			fmt.Printf("  %-28s %8.2f\n", table, r.QueriesPerRequest[table])
+		}
+	}
+}
@@ -21,6 +21,39 @@ type Config struct {
 	API       APIConfig
 	Suppliers SupplierConfig
 	H2H       H2HConfig
+	Routing   RoutingConfig
+	Events    EventsConfig
+	Pricing   PricingConfig
+	Catalog   CatalogConfig
+
+	Security  SecurityConfig
+	Report    ReportConfig
+	Scheduler SchedulerConfig
+	Statement StatementConfig
+	Expiry    ExpiryConfig
+	Probe     SupplierProbeConfig
+	Chaos     ChaosConfig
+	Anomaly   AnomalyConfig
+	Refund    RefundSLAConfig
+	Transfer  TransferConfig
+	Referral  ReferralConfig
+	Phone     PhoneConfig
+	Status    StatusPageConfig
+	Intake    IntakeConfig
+	Health    ProductHealthConfig
+	Pool      PoolMonitorConfig
+	Notify    NotificationConfig
+	Partition PartitionConfig
+	Reconcile ReconciliationConfig
+	Invoice   SupplierInvoiceConfig
+	Voucher   VoucherConfig
+	Retry     RetryConfig
+	Duplicate DuplicateGuardConfig
+	TrxLock   TransactionLockConfig
+	Confirm   AmountConfirmConfig
+	Logging   LoggingConfig
+	Flags     FeatureFlagsConfig
+	MutHook   MutationWebhookConfig
 }
 
 // AppConfig holds application configuration
@@ -29,6 +62,11 @@ type AppConfig struct {
 	Environment string
 	Port        string
 	Debug       bool
+
+	// Shutdown bounds; the HTTP server gets DrainTimeout to finish requests,
+	// every other component StopTimeout
+	StopTimeout  time.Duration
+	DrainTimeout time.Duration
 }
 
 // DatabaseConfig holds database configuration
@@ -42,15 +80,29 @@ type DatabaseConfig struct {
 	MaxIdle  int
 	MaxOpen  int
 	MaxLife  time.Duration
+
+	// QueryMetrics records every query in db_query_duration_seconds
+	QueryMetrics bool
+
+	// IndexCheck explains the hot queries at startup and warns about those
+	// planned without an index on tables of at least IndexCheckMinRows rows
+	IndexCheck        bool
+	IndexCheckMinRows int
 }
 
 // RedisConfig holds Redis configuration
 type RedisConfig struct {
-	Host     string
-	Port     string
-	Password string
-	DB       int
-	PoolSize int
+	Mode             string // standalone (default), sentinel or cluster
+	Host             string // Standalone host, used when Addrs is empty
+	Port             string
+	Addrs            []string // Sentinel or cluster node addresses (host:port)
+	MasterName       string   // Sentinel master set name
+	Username         string
+	Password         string
+	SentinelPassword string
+	DB               int // Must be 0 in cluster mode
+	PoolSize         int
+	MaxRetries       int
 }
 
 // JWTConfig holds JWT configuration
@@ -70,28 +122,74 @@ type AuthConfig struct {
 	RefreshTokenTTL time.Duration
 	H2HAPIKey       string
 	H2HAPISecret    string
-	H2HAllowedIPs   []string
+
+	// Login throttling and lockout
+	LoginMaxFailures   int
+	LoginIPMaxFailures int
+	LoginFailureWindow time.Duration
+	LoginLockoutBase   time.Duration
+	LoginLockoutMax    time.Duration
+
+	// Password reset
+	PasswordResetTTL        time.Duration
+	PasswordResetURL        string // Page completing the reset; receives ?token=
+	PasswordResetMaxPerHour int    // Reset tokens issued per account per hour
+
+	// Admin impersonation
+	ImpersonationTTL time.Duration // Lifetime of an impersonation token
 }
 
 // SMTPConfig holds SMTP configuration
 type SMTPConfig struct {
-	Host     string
-	Port     int
-	Username string
-	Password string
-	From     string
+	Enabled    bool // Delivers EMAIL outbox messages; disabled keeps them queued
+	Host       string
+	Port       int
+	Username   string
+	Password   string
+	From       string
+	FromName   string
+	Encryption string        // starttls, tls (implicit, port 465) or none
+	Timeout    time.Duration // Per delivery attempt
+	MaxRetries int           // Immediate retries of transient SMTP failures
 }
 
 // APIConfig holds API configuration
 type APIConfig struct {
 	RateLimitPerMinute int
-	TimeoutSeconds     int
+	TimeoutSeconds     int // Write timeout of requests answered without supplier calls
 	MaxRequestSize     int64
+
+	// Requests calling suppliers before they answer get SyncTimeout; their
+	// supplier calls must end ResponseReserve before it, and none starts with
+	// less than MinSupplierBudget left
+	SyncTimeout       time.Duration
+	ResponseReserve   time.Duration
+	MinSupplierBudget time.Duration
 }
 
 // SupplierConfig holds external supplier configurations
 type SupplierConfig struct {
 	Digiflazz DigiflazzConfig
+	Sandbox   SupplierSandboxConfig
+	HTTP      SupplierHTTPConfig
+	// WebhookReplayWindow is how long processed webhook event IDs are rejected
+	WebhookReplayWindow time.Duration
+}
+
+// SupplierHTTPConfig controls the HTTP client shared by supplier adapters
+type SupplierHTTPConfig struct {
+	MaxRetries          int           // Extra attempts for transient failures of idempotent calls
+	AttemptTimeout      time.Duration // Bound of a single attempt within the supplier timeout
+	RetryBackoff        time.Duration // Wait before the first retry, doubled per retry
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int // 0 means unlimited
+	IdleConnTimeout     time.Duration
+}
+
+// SupplierSandboxConfig controls recording and replaying supplier HTTP traffic
+type SupplierSandboxConfig struct {
+	Mode string // off, record or replay
+	Dir  string // Fixture root; each supplier uses a subdirectory named after its code
 }
 
 // DigiflazzConfig holds Digiflazz supplier specific configuration
@@ -105,9 +203,322 @@ type DigiflazzConfig struct {
 
 // H2HConfig holds H2H API configuration
 type H2HConfig struct {
-	APIKey     string
-	APISecret  string
-	AllowedIPs []string
+	APIKey    string
+	APISecret string
+	// SecretGracePeriod is how long a client's old secret keeps working after it rotated
+	SecretGracePeriod time.Duration
+}
+
+// RoutingConfig holds smart routing and priority auto-tuning configuration
+type RoutingConfig struct {
+	PriorityTuningEnabled  bool
+	PriorityTuningInterval time.Duration
+	PriorityShortWindow    time.Duration
+	PriorityLongWindow     time.Duration
+	PriorityMinSamples     int
+	PriorityBlendWeight    float64       // Share of auto-tuned priority in routing (0.0 - 1.0)
+	CacheTTL               time.Duration // How long routing data of a product is cached in Redis
+	SpreadAccounts         bool          // Spread traffic over accounts sharing an adapter type
+}
+
+// EventsConfig holds outbox relay and event publisher configuration
+type EventsConfig struct {
+	RelayEnabled   bool
+	RelayInterval  time.Duration
+	BatchSize      int
+	MaxAttempts    int
+	WebhookURLs    []string
+	WebhookSecret  string
+	WebhookTimeout time.Duration
+	RedisStream    string // Empty disables the Redis stream publisher
+	NATSURL        string // Empty disables the NATS publisher
+	NATSToken      string
+	NATSSubject    string // Subject prefix, events go to <prefix>.<event_type>
+	NATSJetStream  bool
+	NATSTimeout    time.Duration
+
+	// NotificationsEnabled generates WhatsApp/email outbox messages from events
+	NotificationsEnabled bool
+	// CashbackEnabled pays promotion cashback from transaction completed events
+	CashbackEnabled bool
+	// HookTimeout bounds a single call of a plugin event hook
+	HookTimeout time.Duration
+}
+
+// PricingConfig holds supplier price sync and margin protection configuration
+type PricingConfig struct {
+	MinMargin    float64 // Minimum margin (Rupiah) between selling price and supplier cost
+	MarginAction string  // FLAG or DEACTIVATE when the margin is breached
+	SyncEnabled  bool
+	SyncInterval time.Duration
+}
+
+// CatalogConfig holds supplier catalog mapping validation configuration
+type CatalogConfig struct {
+	MappingValidationEnabled  bool
+	MappingValidationInterval time.Duration
+	PriceListFreshTTL         time.Duration // Age after which the public price list is rebuilt in the background
+	PriceListStaleTTL         time.Duration // How long a built price list is kept in Redis
+}
+
+// SecurityConfig holds access denial audit and burst alerting configuration
+type SecurityConfig struct {
+	AlertWebhookURL    string // Empty disables burst alerts
+	AlertWebhookSecret string
+	AlertThreshold     int // Denials per user/IP within AlertWindow that trigger an alert
+	AlertWindow        time.Duration
+	AlertCooldown      time.Duration
+}
+
+// ReportConfig holds finance report configuration
+type ReportConfig struct {
+	Timezone string        // IANA timezone used to cut report days and months
+	CacheTTL time.Duration // TTL of cached closed report periods
+}
+
+// SchedulerConfig holds background job scheduler configuration
+type SchedulerConfig struct {
+	InstanceID     string        // Replica identity recorded in job locks; defaults to hostname and pid
+	DefaultTimeout time.Duration // Run timeout for jobs that do not set their own
+}
+
+// StatementConfig holds monthly account statement configuration
+type StatementConfig struct {
+	SyncMaxRows  int           // Largest statement (mutations + transactions) rendered within the request
+	PollInterval time.Duration // How often the worker picks up queued statements
+	BatchSize    int           // Queued statements generated per worker tick
+}
+
+// ExpiryConfig holds auto-cancel configuration for unconfirmed pending transactions
+type ExpiryConfig struct {
+	Enabled          bool
+	Interval         time.Duration            // How often the expiry job runs
+	BatchSize        int                      // Expired transactions cancelled per run
+	Default          time.Duration            // Auto-cancel duration for all transactions (0 = never)
+	ChannelDurations map[string]time.Duration // Per channel overrides, e.g. WHATSAPP=15m
+	ProductDurations map[string]time.Duration // Per product code overrides, beat channel durations
+
+	// Processing SLA: past the expected duration the supplier is polled for the
+	// status, past the timeout the transaction is marked TIMEOUT
+	ProcessingExpected time.Duration
+	ProcessingTimeout  time.Duration
+	CategoryExpected   map[string]time.Duration // Per product category, e.g. PLN=2m
+	CategoryTimeout    map[string]time.Duration // Per product category, e.g. PLN=30m
+	ProductExpected    map[string]time.Duration // Per product code, beat category durations
+	ProductTimeout     map[string]time.Duration // Per product code, beat category durations
+
+	// SlowProcessing is how long one processing run may take before its
+	// stage timings are recorded on the transaction timeline
+	SlowProcessing time.Duration
+}
+
+// TransactionLockConfig holds the distributed lock taken while a transaction
+// is processed
+type TransactionLockConfig struct {
+	Enabled bool
+	TTL     time.Duration // Longest a worker may hold the lock of one transaction
+}
+
+// SupplierInvoiceConfig holds supplier recap ingestion limits
+type SupplierInvoiceConfig struct {
+	MaxFileSize     int64   // Bytes
+	MaxLines        int     // Lines read from one file
+	AmountTolerance float64 // Largest billed vs HPP difference still counted as a match
+}
+
+// VoucherConfig holds the code stock of voucher products
+type VoucherConfig struct {
+	CodeKey           string // Base64 32 byte key encrypting codes; empty disables the stock
+	LowStockThreshold int    // Available codes that trigger the low stock alert
+	MaxFileSize       int64  // Bytes
+	MaxCodes          int    // Codes read from one file
+}
+
+// DuplicateGuardConfig holds the check for orders repeating a recent one
+type DuplicateGuardConfig struct {
+	Mode   string        // OFF, CONFIRM (rejected unless allow_duplicate) or REJECT
+	Window time.Duration // How long a successful transaction blocks an identical order
+}
+
+// AmountConfirmConfig holds the confirmation step of large orders
+type AmountConfirmConfig struct {
+	DefaultThreshold float64       // Orders totalling more need confirming unless the user set their own; 0 = off
+	TokenTTL         time.Duration // How long a confirmation token can be redeemed
+	ExemptChannels   []string      // Channels never asked, e.g. H2H
+}
+
+// LoggingConfig holds the log levels. Components lists name=level[:sample_rate]
+// pairs; sampled components keep that share of their Debug and Info logs.
+type LoggingConfig struct {
+	Level      string // Default level; empty uses the environment default
+	Components string
+}
+
+// FeatureFlagsConfig holds feature flag evaluation. Flags are stored per
+// APP_ENV; flags not stored for it keep their default.
+type FeatureFlagsConfig struct {
+	CacheTTL time.Duration // How long a replica keeps evaluating flags changed elsewhere
+	// SyncFailoverDefault is the h2h.sync_failover state while the flag is not
+	// stored; routing.spread_accounts defaults to ROUTING_SPREAD_ACCOUNTS
+	SyncFailoverDefault bool
+}
+
+// MutationWebhookConfig holds the balance mutation webhooks of users. Queuing
+// runs on the outbox relay, so the relay must be enabled too.
+type MutationWebhookConfig struct {
+	Enabled       bool
+	BatchInterval time.Duration // How often queued mutations are batched and posted
+	BatchSize     int           // Most mutations in one delivery
+	MaxDepth      int           // Downline levels reaching an upline's webhook
+	MaxAttempts   int
+	Timeout       time.Duration // Timeout of one delivery request
+	AllowHTTP     bool          // Accept webhook URLs without TLS (development only)
+	AllowPrivate  bool          // Accept webhook hosts on loopback and private addresses (development only)
+}
+
+// SupplierProbeConfig holds active supplier latency probing and SLA targets
+type SupplierProbeConfig struct {
+	Enabled            bool
+	Interval           time.Duration // How often every active supplier is probed
+	Retention          time.Duration // How long probe results are kept
+	TargetP95Ms        int           // p95 probe latency a supplier must stay under
+	TargetAvailability float64       // Minimum percentage of successful probes
+	UnreachableAfter   int           // Consecutive failed pings before routing down-ranks a supplier
+}
+
+// AnomalyConfig holds transaction anomaly detection and alerting
+type AnomalyConfig struct {
+	Enabled              bool
+	Interval             time.Duration // How often outcome rates are checked
+	Window               time.Duration // Rolling window of transactions evaluated
+	SettleDelay          time.Duration // Newest transactions skipped because they are still in flight
+	MinSamples           int           // Minimum transactions before a rate is judged
+	FailureRateThreshold float64       // Failed percentage of finished transactions that alerts
+	PendingRateThreshold float64       // Percentage of routed transactions still processing that alerts
+	AlertCooldown        time.Duration // Minimum time between alerts for the same anomaly
+}
+
+// RefundSLAConfig holds refund latency targets and stuck refund alerting
+type RefundSLAConfig struct {
+	WatchEnabled     bool
+	WatchInterval    time.Duration // How often stuck refunds are checked
+	Target           time.Duration // Failure to refund latency counted as breached above this
+	StuckAfter       time.Duration // Failed transactions waiting longer for a release or refund are alerted
+	Lookback         time.Duration // Only transactions created within this window are checked
+	OutstandingLimit int           // Outstanding refunds listed and alerted per pass
+	AlertCooldown    time.Duration // Minimum time between alerts for the same transaction
+}
+
+// TransferConfig holds balance transfer limits and PIN lockout
+type TransferConfig struct {
+	MinAmount       float64
+	MaxAmount       map[int]float64 // Per transfer limit per user level (0 = unlimited)
+	DailyLimit      map[int]float64 // Total sent per day per user level (0 = unlimited)
+	PINMaxAttempts  int             // Wrong PINs before transfers are locked
+	PINLockDuration time.Duration
+}
+
+// ReferralConfig holds referral codes and downline registration limits
+type ReferralConfig struct {
+	MaxDownlines map[int]float64 // Direct downlines plus pending registrations per upline level (0 = unlimited)
+	CodeLength   int
+}
+
+// StatusPageConfig holds how the public status page rates product categories
+type StatusPageConfig struct {
+	Window               time.Duration // Rolling period of the success rates
+	MinSamples           int           // Finished transactions a category needs before its rate counts
+	DegradedBelow        float64       // Success rate percentage below which a category is degraded
+	DownBelow            float64       // Success rate percentage below which a category is down
+	QueueDegradedBacklog int64         // Queued orders that degrade every category
+	QueueDownBacklog     int64         // Queued orders that take every category down
+	IncidentHistory      time.Duration // How long resolved incidents stay listed
+	CacheTTL             time.Duration
+}
+
+// NotificationConfig holds notification thresholds and delivery settings
+type NotificationConfig struct {
+	LowBalanceThreshold  float64 // Balance (Rupiah) below which users are warned; 0 disables
+	DailySummaryEnabled  bool
+	DailySummarySchedule string // Cron expression in server local time
+	PriceDigestEnabled   bool
+	PriceDigestSchedule  string        // Cron expression in server local time
+	PriceDigestWindow    time.Duration // Period covered by one price change digest
+	DispatchInterval     time.Duration
+	DispatchBatchSize    int // Outbox messages sent per channel per dispatch run
+}
+
+// PartitionConfig holds monthly partition maintenance of transactions and mutations
+type PartitionConfig struct {
+	MaintenanceEnabled  bool
+	MaintenanceInterval time.Duration
+	PremakeMonths       int // Months created ahead of the current one
+	RetentionMonths     int // Partitions older than this many months are detached (0 = keep all)
+}
+
+// ReconciliationConfig holds the nightly balance reconciliation against the mutation ledger
+type ReconciliationConfig struct {
+	Enabled   bool
+	Schedule  string // Cron expression in server local time
+	BatchSize int    // Users recomputed per query
+}
+
+// RetryConfig holds the fallback auto-retry policy of failed transactions,
+// used when no policy in the retry_policies table matches
+type RetryConfig struct {
+	MaxAttempts       int
+	InitialDelay      time.Duration
+	MaxDelay          time.Duration
+	BackoffMultiplier float64
+	Jitter            bool
+	PolicyCacheTTL    time.Duration // How long stored policies are cached per replica
+}
+
+// PhoneConfig holds the numbering plans destinations and phone numbers are
+// normalized with, by ISO country code (see utils.PhonePlans)
+type PhoneConfig struct {
+	DefaultCountry    string
+	ProductCountries  map[string]string // Product code -> country
+	CategoryCountries map[string]string // Category code -> country
+}
+
+// IntakeConfig holds the queue backlog thresholds that degrade transaction
+// intake and shed low priority H2H orders
+type IntakeConfig struct {
+	Enabled         bool
+	SampleInterval  time.Duration
+	DegradedBacklog int64   // Queued orders from which ETAs are returned
+	ShedBacklog     int64   // Queued orders from which low priority H2H orders get 503; 0 never sheds
+	DrainRate       float64 // Orders per second the workers process, for wait estimates
+	BaseWait        time.Duration
+	PriorityClients []string // H2H client IDs never shed
+}
+
+// ProductHealthConfig holds the per product success rate and "disrupted"
+// badge shown in product listings
+type ProductHealthConfig struct {
+	Window         time.Duration // Rolling period of the success rate
+	MinSamples     int           // Finished transactions needed before a rate is shown
+	DisruptedBelow float64       // Success rate percentage under which a product is disrupted
+	CacheTTL       time.Duration
+}
+
+// PoolMonitorConfig holds database and Redis connection pool instrumentation
+type PoolMonitorConfig struct {
+	StatsInterval       time.Duration // How often pool stats are exported
+	SaturationThreshold float64       // In-use share of a pool (0-1) that logs a warning
+}
+
+// ChaosConfig holds fault injection for resilience testing; it is refused in production
+type ChaosConfig struct {
+	Enabled           bool
+	SupplierLatency   time.Duration // Added to every supplier call
+	SupplierErrorRate float64       // Share of supplier calls failed, 0.0 - 1.0
+	SupplierScope     string        // Supplier code to target (empty = all suppliers)
+	RedisLatency      time.Duration
+	RedisErrorRate    float64
+	DatabaseLatency   time.Duration
+	DatabaseErrorRate float64
 }
 
 // Load loads configuration from environment variables
@@ -124,6 +535,9 @@ func Load() (*Config, error) {
 			Environment: getEnv("APP_ENV", "development"),
 			Port:        getEnv("APP_PORT", "8080"),
 			Debug:       getEnvBool("APP_DEBUG", true),
+
+			StopTimeout:  getEnvDuration("APP_STOP_TIMEOUT", 10*time.Second),
+			DrainTimeout: getEnvDuration("APP_DRAIN_TIMEOUT", 30*time.Second),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -135,13 +549,24 @@ func Load() (*Config, error) {
 			MaxIdle:  getEnvInt("DB_MAX_IDLE", 10),
 			MaxOpen:  getEnvInt("DB_MAX_OPEN", 100),
 			MaxLife:  getEnvDuration("DB_MAX_LIFE", time.Hour),
+
+			QueryMetrics: getEnvBool("DB_QUERY_METRICS", false),
+
+			IndexCheck:        getEnvBool("DB_INDEX_CHECK", true),
+			IndexCheckMinRows: getEnvInt("DB_INDEX_CHECK_MIN_ROWS", 10000),
 		},
 		Redis: RedisConfig{
-			Host:     getEnv("REDIS_HOST", "localhost"),
-			Port:     getEnv("REDIS_PORT", "6379"),
-			Password: getEnv("REDIS_PASSWORD", ""),
-			DB:       getEnvInt("REDIS_DB", 0),
-			PoolSize: getEnvInt("REDIS_POOL_SIZE", 10),
+			Mode:             getEnv("REDIS_MODE", "standalone"),
+			Host:             getEnv("REDIS_HOST", "localhost"),
+			Port:             getEnv("REDIS_PORT", "6379"),
+			Addrs:            getEnvSlice("REDIS_ADDRS", nil),
+			MasterName:       getEnv("REDIS_SENTINEL_MASTER", ""),
+			Username:         getEnv("REDIS_USERNAME", ""),
+			Password:         getEnv("REDIS_PASSWORD", ""),
+			SentinelPassword: getEnv("REDIS_SENTINEL_PASSWORD", ""),
+			DB:               getEnvInt("REDIS_DB", 0),
+			PoolSize:         getEnvInt("REDIS_POOL_SIZE", 10),
+			MaxRetries:       getEnvInt("REDIS_MAX_RETRIES", 3),
 		},
 		JWT: JWTConfig{
 			Secret:         getEnv("JWT_SECRET", "your-secret-key"),
@@ -157,19 +582,39 @@ func Load() (*Config, error) {
 			RefreshTokenTTL: getEnvDuration("AUTH_REFRESH_TTL", 7*24*time.Hour),
 			H2HAPIKey:       getEnv("H2H_API_KEY", ""),
 			H2HAPISecret:    getEnv("H2H_API_SECRET", ""),
-			H2HAllowedIPs:   getEnvSlice("H2H_ALLOWED_IPS", []string{}),
+
+			LoginMaxFailures:   getEnvInt("AUTH_LOGIN_MAX_FAILURES", 5),
+			LoginIPMaxFailures: getEnvInt("AUTH_LOGIN_IP_MAX_FAILURES", 20),
+			LoginFailureWindow: getEnvDuration("AUTH_LOGIN_FAILURE_WINDOW", 15*time.Minute),
+			LoginLockoutBase:   getEnvDuration("AUTH_LOGIN_LOCKOUT_BASE", time.Minute),
+			LoginLockoutMax:    getEnvDuration("AUTH_LOGIN_LOCKOUT_MAX", 24*time.Hour),
+
+			PasswordResetTTL:        getEnvDuration("AUTH_PASSWORD_RESET_TTL", 30*time.Minute),
+			PasswordResetURL:        getEnv("AUTH_PASSWORD_RESET_URL", "https://eraflazz.com/reset-password"),
+			PasswordResetMaxPerHour: getEnvInt("AUTH_PASSWORD_RESET_MAX_PER_HOUR", 3),
+
+			ImpersonationTTL: getEnvDuration("AUTH_IMPERSONATION_TTL", 15*time.Minute),
 		},
 		SMTP: SMTPConfig{
-			Host:     getEnv("SMTP_HOST", "smtp.gmail.com"),
-			Port:     getEnvInt("SMTP_PORT", 587),
-			Username: getEnv("SMTP_USERNAME", ""),
-			Password: getEnv("SMTP_PASSWORD", ""),
-			From:     getEnv("SMTP_FROM", "noreply@eraflazz.com"),
+			Enabled:    getEnvBool("SMTP_ENABLED", false),
+			Host:       getEnv("SMTP_HOST", "smtp.gmail.com"),
+			Port:       getEnvInt("SMTP_PORT", 587),
+			Username:   getEnv("SMTP_USERNAME", ""),
+			Password:   getEnv("SMTP_PASSWORD", ""),
+			From:       getEnv("SMTP_FROM", "noreply@eraflazz.com"),
+			FromName:   getEnv("SMTP_FROM_NAME", "Eraflazz"),
+			Encryption: getEnv("SMTP_ENCRYPTION", "starttls"),
+			Timeout:    getEnvDuration("SMTP_TIMEOUT", 30*time.Second),
+			MaxRetries: getEnvInt("SMTP_MAX_RETRIES", 2),
 		},
 		API: APIConfig{
 			RateLimitPerMinute: getEnvInt("API_RATE_LIMIT", 100),
 			TimeoutSeconds:     getEnvInt("API_TIMEOUT", 30),
 			MaxRequestSize:     getEnvInt64("API_MAX_REQUEST_SIZE", 1048576), // 1MB
+
+			SyncTimeout:       getEnvDuration("API_SYNC_TIMEOUT", 60*time.Second),
+			ResponseReserve:   getEnvDuration("API_RESPONSE_RESERVE", 2*time.Second),
+			MinSupplierBudget: getEnvDuration("API_MIN_SUPPLIER_BUDGET", 3*time.Second),
 		},
 		Suppliers: SupplierConfig{
 			Digiflazz: DigiflazzConfig{
@@ -179,11 +624,261 @@ func Load() (*Config, error) {
 				Testing:        getEnvBool("DIGIFLAZZ_TESTING", true),
 				TimeoutSeconds: getEnvInt("DIGIFLAZZ_TIMEOUT", 30),
 			},
+			Sandbox: SupplierSandboxConfig{
+				Mode: getEnv("SUPPLIER_SANDBOX_MODE", "off"),
+				Dir:  getEnv("SUPPLIER_SANDBOX_DIR", "testdata/suppliers"),
+			},
+			HTTP: SupplierHTTPConfig{
+				MaxRetries:          getEnvInt("SUPPLIER_HTTP_MAX_RETRIES", 2),
+				AttemptTimeout:      getEnvDuration("SUPPLIER_HTTP_ATTEMPT_TIMEOUT", 10*time.Second),
+				RetryBackoff:        getEnvDuration("SUPPLIER_HTTP_RETRY_BACKOFF", 200*time.Millisecond),
+				MaxIdleConnsPerHost: getEnvInt("SUPPLIER_HTTP_MAX_IDLE_CONNS_PER_HOST", 20),
+				MaxConnsPerHost:     getEnvInt("SUPPLIER_HTTP_MAX_CONNS_PER_HOST", 100),
+				IdleConnTimeout:     getEnvDuration("SUPPLIER_HTTP_IDLE_CONN_TIMEOUT", 90*time.Second),
+			},
+			WebhookReplayWindow: getEnvDuration("SUPPLIER_WEBHOOK_REPLAY_WINDOW", 24*time.Hour),
 		},
 		H2H: H2HConfig{
-			APIKey:     getEnv("H2H_API_KEY", ""),
-			APISecret:  getEnv("H2H_API_SECRET", ""),
-			AllowedIPs: getEnvSlice("H2H_ALLOWED_IPS", []string{}),
+			APIKey:            getEnv("H2H_API_KEY", ""),
+			APISecret:         getEnv("H2H_API_SECRET", ""),
+			SecretGracePeriod: getEnvDuration("H2H_SECRET_GRACE_PERIOD", 24*time.Hour),
+		},
+		Routing: RoutingConfig{
+			PriorityTuningEnabled:  getEnvBool("ROUTING_PRIORITY_TUNING_ENABLED", true),
+			PriorityTuningInterval: getEnvDuration("ROUTING_PRIORITY_TUNING_INTERVAL", 5*time.Minute),
+			PriorityShortWindow:    getEnvDuration("ROUTING_PRIORITY_SHORT_WINDOW", time.Hour),
+			PriorityLongWindow:     getEnvDuration("ROUTING_PRIORITY_LONG_WINDOW", 24*time.Hour),
+			PriorityMinSamples:     getEnvInt("ROUTING_PRIORITY_MIN_SAMPLES", 10),
+			PriorityBlendWeight:    getEnvFloat64("ROUTING_PRIORITY_BLEND_WEIGHT", 0.5),
+			CacheTTL:               getEnvDuration("ROUTING_CACHE_TTL", 30*time.Second),
+			SpreadAccounts:         getEnvBool("ROUTING_SPREAD_ACCOUNTS", true),
+		},
+		Events: EventsConfig{
+			RelayEnabled:   getEnvBool("EVENTS_RELAY_ENABLED", true),
+			RelayInterval:  getEnvDuration("EVENTS_RELAY_INTERVAL", time.Second),
+			BatchSize:      getEnvInt("EVENTS_RELAY_BATCH_SIZE", 100),
+			MaxAttempts:    getEnvInt("EVENTS_MAX_ATTEMPTS", 10),
+			WebhookURLs:    getEnvSlice("EVENTS_WEBHOOK_URLS", []string{}),
+			WebhookSecret:  getEnv("EVENTS_WEBHOOK_SECRET", ""),
+			WebhookTimeout: getEnvDuration("EVENTS_WEBHOOK_TIMEOUT", 10*time.Second),
+			RedisStream:    getEnv("EVENTS_REDIS_STREAM", ""),
+			NATSURL:        getEnv("EVENTS_NATS_URL", ""),
+			NATSToken:      getEnv("EVENTS_NATS_TOKEN", ""),
+			NATSSubject:    getEnv("EVENTS_NATS_SUBJECT", "eraflazz.events"),
+			NATSJetStream:  getEnvBool("EVENTS_NATS_JETSTREAM", true),
+			NATSTimeout:    getEnvDuration("EVENTS_NATS_TIMEOUT", 5*time.Second),
+
+			NotificationsEnabled: getEnvBool("EVENTS_NOTIFICATIONS_ENABLED", true),
+			CashbackEnabled:      getEnvBool("EVENTS_CASHBACK_ENABLED", true),
+			HookTimeout:          getEnvDuration("EVENTS_HOOK_TIMEOUT", 5*time.Second),
+		},
+		Pricing: PricingConfig{
+			MinMargin:    getEnvFloat64("PRICING_MIN_MARGIN", 0),
+			MarginAction: getEnv("PRICING_MARGIN_ACTION", "FLAG"),
+			SyncEnabled:  getEnvBool("PRICING_SYNC_ENABLED", true),
+			SyncInterval: getEnvDuration("PRICING_SYNC_INTERVAL", time.Hour),
+		},
+		Catalog: CatalogConfig{
+			MappingValidationEnabled:  getEnvBool("CATALOG_MAPPING_VALIDATION_ENABLED", true),
+			MappingValidationInterval: getEnvDuration("CATALOG_MAPPING_VALIDATION_INTERVAL", 6*time.Hour),
+			PriceListFreshTTL:         getEnvDuration("CATALOG_PRICELIST_FRESH_TTL", 30*time.Second),
+			PriceListStaleTTL:         getEnvDuration("CATALOG_PRICELIST_STALE_TTL", 10*time.Minute),
+		},
+		Security: SecurityConfig{
+			AlertWebhookURL:    getEnv("SECURITY_ALERT_WEBHOOK_URL", ""),
+			AlertWebhookSecret: getEnv("SECURITY_ALERT_WEBHOOK_SECRET", ""),
+			AlertThreshold:     getEnvInt("SECURITY_ALERT_THRESHOLD", 10),
+			AlertWindow:        getEnvDuration("SECURITY_ALERT_WINDOW", 5*time.Minute),
+			AlertCooldown:      getEnvDuration("SECURITY_ALERT_COOLDOWN", 15*time.Minute),
+		},
+		Report: ReportConfig{
+			Timezone: getEnv("REPORT_TIMEZONE", "Asia/Jakarta"),
+			CacheTTL: getEnvDuration("REPORT_CACHE_TTL", 24*time.Hour),
+		},
+		Scheduler: SchedulerConfig{
+			InstanceID:     getEnv("SCHEDULER_INSTANCE_ID", ""),
+			DefaultTimeout: getEnvDuration("SCHEDULER_JOB_TIMEOUT", 10*time.Minute),
+		},
+		Statement: StatementConfig{
+			SyncMaxRows:  getEnvInt("STATEMENT_SYNC_MAX_ROWS", 2000),
+			PollInterval: getEnvDuration("STATEMENT_POLL_INTERVAL", 10*time.Second),
+			BatchSize:    getEnvInt("STATEMENT_BATCH_SIZE", 5),
+		},
+		Expiry: ExpiryConfig{
+			Enabled:            getEnvBool("TRANSACTION_EXPIRY_ENABLED", true),
+			Interval:           getEnvDuration("TRANSACTION_EXPIRY_INTERVAL", time.Minute),
+			BatchSize:          getEnvInt("TRANSACTION_EXPIRY_BATCH_SIZE", 100),
+			Default:            getEnvDuration("TRANSACTION_AUTO_CANCEL_DEFAULT", 0),
+			ChannelDurations:   getEnvDurationMap("TRANSACTION_AUTO_CANCEL_CHANNELS", map[string]time.Duration{}),
+			ProductDurations:   getEnvDurationMap("TRANSACTION_AUTO_CANCEL_PRODUCTS", map[string]time.Duration{}),
+			ProcessingExpected: getEnvDuration("TRANSACTION_SLA_EXPECTED_DEFAULT", 30*time.Second),
+			ProcessingTimeout:  getEnvDuration("TRANSACTION_SLA_TIMEOUT_DEFAULT", 10*time.Minute),
+			CategoryExpected: getEnvDurationMap("TRANSACTION_SLA_EXPECTED_CATEGORIES", map[string]time.Duration{
+				"PULSA": 10 * time.Second,
+				"DATA":  10 * time.Second,
+				"PLN":   2 * time.Minute,
+			}),
+			CategoryTimeout: getEnvDurationMap("TRANSACTION_SLA_TIMEOUT_CATEGORIES", map[string]time.Duration{
+				"PULSA": 3 * time.Minute,
+				"DATA":  3 * time.Minute,
+				"PLN":   30 * time.Minute,
+			}),
+			ProductExpected: getEnvDurationMap("TRANSACTION_SLA_EXPECTED_PRODUCTS", map[string]time.Duration{}),
+			ProductTimeout:  getEnvDurationMap("TRANSACTION_SLA_TIMEOUT_PRODUCTS", map[string]time.Duration{}),
+			SlowProcessing:  getEnvDuration("TRANSACTION_SLOW_PROCESSING", 10*time.Second),
+		},
+		Probe: SupplierProbeConfig{
+			Enabled:            getEnvBool("SUPPLIER_PROBE_ENABLED", true),
+			Interval:           getEnvDuration("SUPPLIER_PROBE_INTERVAL", time.Minute),
+			Retention:          getEnvDuration("SUPPLIER_PROBE_RETENTION", 7*24*time.Hour),
+			TargetP95Ms:        getEnvInt("SUPPLIER_SLA_TARGET_P95_MS", 3000),
+			TargetAvailability: getEnvFloat64("SUPPLIER_SLA_TARGET_AVAILABILITY", 99.0),
+			UnreachableAfter:   getEnvInt("SUPPLIER_UNREACHABLE_AFTER", 2),
+		},
+		Chaos: ChaosConfig{
+			Enabled:           getEnvBool("CHAOS_ENABLED", false),
+			SupplierLatency:   getEnvDuration("CHAOS_SUPPLIER_LATENCY", 0),
+			SupplierErrorRate: getEnvFloat64("CHAOS_SUPPLIER_ERROR_RATE", 0),
+			SupplierScope:     getEnv("CHAOS_SUPPLIER_SCOPE", ""),
+			RedisLatency:      getEnvDuration("CHAOS_REDIS_LATENCY", 0),
+			RedisErrorRate:    getEnvFloat64("CHAOS_REDIS_ERROR_RATE", 0),
+			DatabaseLatency:   getEnvDuration("CHAOS_DATABASE_LATENCY", 0),
+			DatabaseErrorRate: getEnvFloat64("CHAOS_DATABASE_ERROR_RATE", 0),
+		},
+		Anomaly: AnomalyConfig{
+			Enabled:              getEnvBool("ANOMALY_WATCH_ENABLED", true),
+			Interval:             getEnvDuration("ANOMALY_WATCH_INTERVAL", time.Minute),
+			Window:               getEnvDuration("ANOMALY_WINDOW", 15*time.Minute),
+			SettleDelay:          getEnvDuration("ANOMALY_SETTLE_DELAY", 2*time.Minute),
+			MinSamples:           getEnvInt("ANOMALY_MIN_SAMPLES", 10),
+			FailureRateThreshold: getEnvFloat64("ANOMALY_FAILURE_RATE_THRESHOLD", 50),
+			PendingRateThreshold: getEnvFloat64("ANOMALY_PENDING_RATE_THRESHOLD", 90),
+			AlertCooldown:        getEnvDuration("ANOMALY_ALERT_COOLDOWN", 30*time.Minute),
+		},
+		Refund: RefundSLAConfig{
+			WatchEnabled:     getEnvBool("REFUND_WATCH_ENABLED", true),
+			WatchInterval:    getEnvDuration("REFUND_WATCH_INTERVAL", 5*time.Minute),
+			Target:           getEnvDuration("REFUND_SLA_TARGET", 5*time.Minute),
+			StuckAfter:       getEnvDuration("REFUND_STUCK_AFTER", 30*time.Minute),
+			Lookback:         getEnvDuration("REFUND_LOOKBACK", 7*24*time.Hour),
+			OutstandingLimit: getEnvInt("REFUND_OUTSTANDING_LIMIT", 100),
+			AlertCooldown:    getEnvDuration("REFUND_ALERT_COOLDOWN", 6*time.Hour),
+		},
+		Transfer: TransferConfig{
+			MinAmount:       getEnvFloat64("TRANSFER_MIN_AMOUNT", 10000),
+			MaxAmount:       getEnvLevelAmounts("TRANSFER_MAX_AMOUNT", map[int]float64{1: 1000000, 2: 10000000, 3: 50000000}),
+			DailyLimit:      getEnvLevelAmounts("TRANSFER_DAILY_LIMIT", map[int]float64{1: 5000000, 2: 50000000, 3: 250000000}),
+			PINMaxAttempts:  getEnvInt("TRANSFER_PIN_MAX_ATTEMPTS", 3),
+			PINLockDuration: getEnvDuration("TRANSFER_PIN_LOCK_DURATION", 30*time.Minute),
+		},
+		Referral: ReferralConfig{
+			MaxDownlines: getEnvLevelAmounts("REFERRAL_MAX_DOWNLINES", map[int]float64{2: 100, 3: 1000}),
+			CodeLength:   getEnvInt("REFERRAL_CODE_LENGTH", 8),
+		},
+		Status: StatusPageConfig{
+			Window:               getEnvDuration("STATUS_PAGE_WINDOW", 15*time.Minute),
+			MinSamples:           getEnvInt("STATUS_PAGE_MIN_SAMPLES", 10),
+			DegradedBelow:        getEnvFloat64("STATUS_PAGE_DEGRADED_BELOW", 90),
+			DownBelow:            getEnvFloat64("STATUS_PAGE_DOWN_BELOW", 50),
+			QueueDegradedBacklog: int64(getEnvInt("STATUS_PAGE_QUEUE_DEGRADED_BACKLOG", 500)),
+			QueueDownBacklog:     int64(getEnvInt("STATUS_PAGE_QUEUE_DOWN_BACKLOG", 5000)),
+			IncidentHistory:      getEnvDuration("STATUS_PAGE_INCIDENT_HISTORY", 7*24*time.Hour),
+			CacheTTL:             getEnvDuration("STATUS_PAGE_CACHE_TTL", 30*time.Second),
+		},
+		Phone: PhoneConfig{
+			DefaultCountry:    getEnv("PHONE_DEFAULT_COUNTRY", "ID"),
+			ProductCountries:  getEnvStringMap("PHONE_PRODUCT_COUNTRIES", map[string]string{}),
+			CategoryCountries: getEnvStringMap("PHONE_CATEGORY_COUNTRIES", map[string]string{}),
+		},
+		Intake: IntakeConfig{
+			Enabled:         getEnvBool("BACKPRESSURE_ENABLED", true),
+			SampleInterval:  getEnvDuration("BACKPRESSURE_SAMPLE_INTERVAL", 5*time.Second),
+			DegradedBacklog: getEnvInt64("BACKPRESSURE_DEGRADED_BACKLOG", 500),
+			ShedBacklog:     getEnvInt64("BACKPRESSURE_SHED_BACKLOG", 2000),
+			DrainRate:       getEnvFloat64("BACKPRESSURE_DRAIN_RATE", 20),
+			BaseWait:        getEnvDuration("BACKPRESSURE_BASE_WAIT", 10*time.Second),
+			PriorityClients: getEnvSlice("BACKPRESSURE_PRIORITY_CLIENTS", nil),
+		},
+		Health: ProductHealthConfig{
+			Window:         getEnvDuration("PRODUCT_HEALTH_WINDOW", time.Hour),
+			MinSamples:     getEnvInt("PRODUCT_HEALTH_MIN_SAMPLES", 10),
+			DisruptedBelow: getEnvFloat64("PRODUCT_HEALTH_DISRUPTED_BELOW", 80),
+			CacheTTL:       getEnvDuration("PRODUCT_HEALTH_CACHE_TTL", time.Minute),
+		},
+		Pool: PoolMonitorConfig{
+			StatsInterval:       getEnvDuration("POOL_STATS_INTERVAL", 15*time.Second),
+			SaturationThreshold: getEnvFloat64("POOL_SATURATION_THRESHOLD", 0.8),
+		},
+		Notify: NotificationConfig{
+			LowBalanceThreshold:  getEnvFloat64("NOTIFY_LOW_BALANCE_THRESHOLD", 50000),
+			DailySummaryEnabled:  getEnvBool("NOTIFY_DAILY_SUMMARY_ENABLED", true),
+			DailySummarySchedule: getEnv("NOTIFY_DAILY_SUMMARY_SCHEDULE", "0 7 * * *"),
+			PriceDigestEnabled:   getEnvBool("NOTIFY_PRICE_DIGEST_ENABLED", true),
+			PriceDigestSchedule:  getEnv("NOTIFY_PRICE_DIGEST_SCHEDULE", "5 * * * *"),
+			PriceDigestWindow:    getEnvDuration("NOTIFY_PRICE_DIGEST_WINDOW", time.Hour),
+			DispatchInterval:     getEnvDuration("NOTIFY_DISPATCH_INTERVAL", 5*time.Second),
+			DispatchBatchSize:    getEnvInt("NOTIFY_DISPATCH_BATCH_SIZE", 50),
+		},
+		Partition: PartitionConfig{
+			MaintenanceEnabled:  getEnvBool("PARTITION_MAINTENANCE_ENABLED", true),
+			MaintenanceInterval: getEnvDuration("PARTITION_MAINTENANCE_INTERVAL", 6*time.Hour),
+			PremakeMonths:       getEnvInt("PARTITION_PREMAKE_MONTHS", 3),
+			RetentionMonths:     getEnvInt("PARTITION_RETENTION_MONTHS", 0),
+		},
+		Reconcile: ReconciliationConfig{
+			Enabled:   getEnvBool("BALANCE_RECONCILIATION_ENABLED", true),
+			Schedule:  getEnv("BALANCE_RECONCILIATION_SCHEDULE", "30 2 * * *"),
+			BatchSize: getEnvInt("BALANCE_RECONCILIATION_BATCH_SIZE", 500),
+		},
+		Invoice: SupplierInvoiceConfig{
+			MaxFileSize:     getEnvInt64("SUPPLIER_INVOICE_MAX_FILE_SIZE", 10485760), // 10MB
+			MaxLines:        getEnvInt("SUPPLIER_INVOICE_MAX_LINES", 100000),
+			AmountTolerance: getEnvFloat64("SUPPLIER_INVOICE_AMOUNT_TOLERANCE", 0.01),
+		},
+		Voucher: VoucherConfig{
+			CodeKey:           getEnv("VOUCHER_CODE_KEY", ""),
+			LowStockThreshold: getEnvInt("VOUCHER_LOW_STOCK_THRESHOLD", 10),
+			MaxFileSize:       getEnvInt64("VOUCHER_MAX_FILE_SIZE", 5242880), // 5MB
+			MaxCodes:          getEnvInt("VOUCHER_MAX_CODES", 50000),
+		},
+		Retry: RetryConfig{
+			MaxAttempts:       getEnvInt("RETRY_MAX_ATTEMPTS", 3),
+			InitialDelay:      getEnvDuration("RETRY_INITIAL_DELAY", 2*time.Second),
+			MaxDelay:          getEnvDuration("RETRY_MAX_DELAY", 30*time.Second),
+			BackoffMultiplier: getEnvFloat64("RETRY_BACKOFF_MULTIPLIER", 2.0),
+			Jitter:            getEnvBool("RETRY_JITTER", true),
+			PolicyCacheTTL:    getEnvDuration("RETRY_POLICY_CACHE_TTL", 30*time.Second),
+		},
+		Duplicate: DuplicateGuardConfig{
+			Mode:   getEnv("TRANSACTION_DUPLICATE_GUARD_MODE", "CONFIRM"),
+			Window: getEnvDuration("TRANSACTION_DUPLICATE_WINDOW", 5*time.Minute),
+		},
+		TrxLock: TransactionLockConfig{
+			Enabled: getEnvBool("TRANSACTION_LOCK_ENABLED", true),
+			TTL:     getEnvDuration("TRANSACTION_LOCK_TTL", 2*time.Minute),
+		},
+		Confirm: AmountConfirmConfig{
+			DefaultThreshold: getEnvFloat64("TRANSACTION_CONFIRM_THRESHOLD", 500000),
+			TokenTTL:         getEnvDuration("TRANSACTION_CONFIRM_TOKEN_TTL", 5*time.Minute),
+			ExemptChannels:   getEnvSlice("TRANSACTION_CONFIRM_EXEMPT_CHANNELS", []string{"H2H"}),
+		},
+		Logging: LoggingConfig{
+			Level:      getEnv("LOG_LEVEL", ""),
+			Components: getEnv("LOG_COMPONENTS", "routing=debug:0.01,worker=info,auth=warn"),
+		},
+		Flags: FeatureFlagsConfig{
+			CacheTTL:            getEnvDuration("FEATURE_FLAGS_CACHE_TTL", 10*time.Second),
+			SyncFailoverDefault: getEnvBool("FEATURE_SYNC_FAILOVER_DEFAULT", true),
+		},
+		MutHook: MutationWebhookConfig{
+			Enabled:       getEnvBool("MUTATION_WEBHOOK_ENABLED", true),
+			BatchInterval: getEnvDuration("MUTATION_WEBHOOK_BATCH_INTERVAL", time.Minute),
+			BatchSize:     getEnvInt("MUTATION_WEBHOOK_BATCH_SIZE", 500),
+			MaxDepth:      getEnvInt("MUTATION_WEBHOOK_MAX_DEPTH", 5),
+			MaxAttempts:   getEnvInt("MUTATION_WEBHOOK_MAX_ATTEMPTS", 10),
+			Timeout:       getEnvDuration("MUTATION_WEBHOOK_TIMEOUT", 10*time.Second),
+			AllowHTTP:     getEnvBool("MUTATION_WEBHOOK_ALLOW_HTTP", false),
+			AllowPrivate:  getEnvBool("MUTATION_WEBHOOK_ALLOW_PRIVATE", false),
 		},
 	}
 
@@ -201,6 +896,15 @@ func (r *RedisConfig) GetRedisAddr() string {
 	return fmt.Sprintf("%s:%s", r.Host, r.Port)
 }
 
+// GetRedisAddrs returns the configured node addresses, falling back to the
+// standalone host and port
+func (r *RedisConfig) GetRedisAddrs() []string {
+	if len(r.Addrs) > 0 {
+		return r.Addrs
+	}
+	return []string{r.GetRedisAddr()}
+}
+
 // IsDevelopment returns true if environment is development
 func (a *AppConfig) IsDevelopment() bool {
 	return a.Environment == "development"
@@ -238,6 +942,15 @@ func getEnvInt64(key string, defaultValue int64) int64 {
 	return defaultValue
 }
 
+func getEnvFloat64(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
 func getEnvBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		if boolValue, err := strconv.ParseBool(value); err == nil {
@@ -273,6 +986,72 @@ func getEnvSlice(key string, defaultValue []string) []string {
 	return defaultValue
 }
 
+// getEnvDurationMap parses KEY=duration pairs separated by commas, e.g.
+// "WHATSAPP=15m,TELEGRAM=15m". Invalid pairs are skipped.
+func getEnvDurationMap(key string, defaultValue map[string]time.Duration) map[string]time.Duration {
+	pairs := getEnvSlice(key, nil)
+	if len(pairs) == 0 {
+		return defaultValue
+	}
+
+	result := make(map[string]time.Duration, len(pairs))
+	for _, pair := range pairs {
+		name, value, found := strings.Cut(pair, "=")
+		if !found {
+			continue
+		}
+		duration, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+		result[strings.TrimSpace(name)] = duration
+	}
+	return result
+}
+
+// getEnvLevelAmounts parses LEVEL=amount pairs, e.g. 1=1000000,2=10000000
+func getEnvStringMap(key string, defaultValue map[string]string) map[string]string {
+	pairs := getEnvSlice(key, nil)
+	if len(pairs) == 0 {
+		return defaultValue
+	}
+
+	result := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		name, value, found := strings.Cut(pair, "=")
+		if !found {
+			continue
+		}
+		result[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return result
+}
+
+func getEnvLevelAmounts(key string, defaultValue map[int]float64) map[int]float64 {
+	pairs := getEnvSlice(key, nil)
+	if len(pairs) == 0 {
+		return defaultValue
+	}
+
+	result := make(map[int]float64, len(pairs))
+	for _, pair := range pairs {
+		level, value, found := strings.Cut(pair, "=")
+		if !found {
+			continue
+		}
+		levelInt, err := strconv.Atoi(strings.TrimSpace(level))
+		if err != nil {
+			continue
+		}
+		amount, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			continue
+		}
+		result[levelInt] = amount
+	}
+	return result
+}
+
 // Validate validates configuration
 func (c *Config) Validate() error {
 	// Validate required fields
@@ -288,6 +1067,33 @@ func (c *Config) Validate() error {
 	if c.JWT.Secret == "" || c.JWT.Secret == "your-secret-key" {
 		return fmt.Errorf("JWT secret must be set and not use default value")
 	}
+	switch strings.ToLower(strings.TrimSpace(c.Suppliers.Sandbox.Mode)) {
+	case "", "off", "record", "replay":
+	default:
+		return fmt.Errorf("unknown supplier sandbox mode %q", c.Suppliers.Sandbox.Mode)
+	}
+	if c.App.IsProduction() && strings.EqualFold(c.Suppliers.Sandbox.Mode, "replay") {
+		return fmt.Errorf("supplier sandbox replay mode is not allowed in production")
+	}
+	switch strings.ToLower(strings.TrimSpace(c.Redis.Mode)) {
+	case "", "standalone":
+	case "sentinel":
+		if c.Redis.MasterName == "" {
+			return fmt.Errorf("redis sentinel mode requires REDIS_SENTINEL_MASTER")
+		}
+	case "cluster":
+		if c.Redis.DB != 0 {
+			return fmt.Errorf("redis cluster mode only supports database 0")
+		}
+	default:
+		return fmt.Errorf("unknown redis mode %q", c.Redis.Mode)
+	}
+	if c.Chaos.Enabled && c.App.IsProduction() {
+		return fmt.Errorf("chaos fault injection is not allowed in production")
+	}
+	if c.API.SyncTimeout <= c.API.ResponseReserve+c.API.MinSupplierBudget {
+		return fmt.Errorf("API_SYNC_TIMEOUT must exceed API_RESPONSE_RESERVE plus API_MIN_SUPPLIER_BUDGET")
+	}
 
 	return nil
 }
@@ -300,7 +1106,7 @@ func (c *Config) Print() {
 	fmt.Printf("Port: %s\n", c.App.Port)
 	fmt.Printf("Debug: %v\n", c.App.Debug)
 	fmt.Printf("Database: %s:%s/%s\n", c.Database.Host, c.Database.Port, c.Database.Name)
-	fmt.Printf("Redis: %s:%s/%d\n", c.Redis.Host, c.Redis.Port, c.Redis.DB)
+	fmt.Printf("Redis: %s %s/%d\n", c.Redis.Mode, strings.Join(c.Redis.GetRedisAddrs(), ","), c.Redis.DB)
 	fmt.Printf("JWT Expiration: %v\n", c.JWT.ExpirationTime)
 	fmt.Printf("====================\n")
 }
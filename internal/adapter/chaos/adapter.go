@@ -0,0 +1,101 @@
+// Package chaos wraps supplier adapters with fault injection for resilience
+// testing outside production.
+package chaos
+
+import (
+	"context"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	faults "github.com/alfanzaky/eraflazz/pkg/chaos"
+)
+
+// Adapter injects the supplier fault before delegating each supplier call
+type Adapter struct {
+	next         domain.SupplierAdapter
+	injector     *faults.Injector
+	supplierCode string
+}
+
+// PostpaidAdapter is an Adapter that also forwards postpaid bill calls
+type PostpaidAdapter struct {
+	*Adapter
+	postpaid domain.PostpaidSupplierAdapter
+}
+
+// Wrap decorates a supplier adapter; a nil injector returns it unchanged.
+// Postpaid capable adapters stay postpaid capable once wrapped.
+func Wrap(next domain.SupplierAdapter, injector *faults.Injector, supplierCode string) domain.SupplierAdapter {
+	if injector == nil || next == nil {
+		return next
+	}
+	adapter := &Adapter{next: next, injector: injector, supplierCode: supplierCode}
+	if postpaid, ok := domain.AsPostpaidAdapter(next); ok {
+		return &PostpaidAdapter{Adapter: adapter, postpaid: postpaid}
+	}
+	return adapter
+}
+
+// TopUp injects the supplier fault and forwards the purchase
+func (a *Adapter) TopUp(request *domain.SupplierRequest) (*domain.SupplierResponse, error) {
+	if err := a.inject(); err != nil {
+		return nil, err
+	}
+	return a.next.TopUp(request)
+}
+
+// CheckBalance injects the supplier fault and forwards the balance check
+func (a *Adapter) CheckBalance() (float64, error) {
+	if err := a.inject(); err != nil {
+		return 0, err
+	}
+	return a.next.CheckBalance()
+}
+
+// Ping injects the supplier fault and forwards the ping
+func (a *Adapter) Ping() error {
+	if err := a.inject(); err != nil {
+		return err
+	}
+	return a.next.Ping()
+}
+
+// CheckStatus injects the supplier fault and forwards the status check
+func (a *Adapter) CheckStatus(trxID string) (*domain.SupplierResponse, error) {
+	if err := a.inject(); err != nil {
+		return nil, err
+	}
+	return a.next.CheckStatus(trxID)
+}
+
+// GetProductCatalog injects the supplier fault and forwards the catalog fetch
+func (a *Adapter) GetProductCatalog() ([]*domain.Product, error) {
+	if err := a.inject(); err != nil {
+		return nil, err
+	}
+	return a.next.GetProductCatalog()
+}
+
+// ParseResponse forwards without faults; it makes no supplier call
+func (a *Adapter) ParseResponse(response []byte) (*domain.SupplierResponse, error) {
+	return a.next.ParseResponse(response)
+}
+
+// CheckBill injects the supplier fault and forwards the bill inquiry
+func (a *PostpaidAdapter) CheckBill(request *domain.SupplierRequest) (*domain.BillInquiry, error) {
+	if err := a.inject(); err != nil {
+		return nil, err
+	}
+	return a.postpaid.CheckBill(request)
+}
+
+// PayBill injects the supplier fault and forwards the bill payment
+func (a *PostpaidAdapter) PayBill(request *domain.SupplierRequest) (*domain.SupplierResponse, error) {
+	if err := a.inject(); err != nil {
+		return nil, err
+	}
+	return a.postpaid.PayBill(request)
+}
+
+func (a *Adapter) inject() error {
+	return a.injector.Inject(context.Background(), faults.TargetSupplier, a.supplierCode)
+}
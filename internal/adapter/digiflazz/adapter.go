@@ -3,8 +3,6 @@ package digiflazz
 import (
 	"bytes"
 	"context"
-	"crypto/md5"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -13,6 +11,8 @@ import (
 
 	"github.com/alfanzaky/eraflazz/config"
 	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/httpclient"
+	"github.com/alfanzaky/eraflazz/pkg/suppliersign"
 )
 
 const (
@@ -30,27 +30,72 @@ var (
 // It translates domain abstraction into concrete Digiflazz HTTP calls
 // while keeping signature generation, timeout, and payload structure in one place.
 type Adapter struct {
-	cfg        config.DigiflazzConfig
-	httpClient *http.Client
-	timeout    time.Duration
+	baseURL     string
+	credentials suppliersign.Credentials
+	signer      suppliersign.Signer
+	testing     bool
+	httpClient  *http.Client
+	timeout     time.Duration
 }
 
-// NewAdapter creates a new Digiflazz adapter instance
-func NewAdapter(cfg config.DigiflazzConfig, client *http.Client) *Adapter {
-	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+// NewAdapter creates a Digiflazz adapter for one supplier account. URL,
+// credentials, timeout and signing method come from the supplier record;
+// cfg only fills settings the record leaves empty. A nil client uses a
+// retrying httpclient with its own connection pool.
+func NewAdapter(cfg config.DigiflazzConfig, supplier *domain.Supplier, client *http.Client) (*Adapter, error) {
+	baseURL := cfg.BaseURL
+	credentials := suppliersign.Credentials{Username: cfg.Username, APIKey: cfg.APIKey}
+	timeoutSeconds := cfg.TimeoutSeconds
+	method := suppliersign.MethodMD5
+
+	if supplier != nil {
+		if supplier.APIURL != "" {
+			baseURL = supplier.APIURL
+		}
+		if value := stringValue(supplier.APIUsername); value != "" {
+			credentials.Username = value
+		}
+		if value := stringValue(supplier.APIKey); value != "" {
+			credentials.APIKey = value
+		}
+		credentials.APISecret = stringValue(supplier.APISecret)
+		if supplier.TimeoutSeconds > 0 {
+			timeoutSeconds = supplier.TimeoutSeconds
+		}
+		if value := stringValue(supplier.SignMethod); value != "" {
+			method = value
+		}
+	}
+
+	if baseURL == "" {
+		return nil, fmt.Errorf("digiflazz base url is required")
+	}
+	if credentials.Username == "" || credentials.APIKey == "" {
+		return nil, fmt.Errorf("digiflazz username and api key are required")
+	}
+
+	signer, err := suppliersign.Get(method)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := time.Duration(timeoutSeconds) * time.Second
 	if timeout == 0 {
 		timeout = 30 * time.Second
 	}
 
 	if client == nil {
-		client = &http.Client{Timeout: timeout}
+		client = httpclient.New(httpclient.DefaultConfig(), nil, timeout)
 	}
 
 	return &Adapter{
-		cfg:        cfg,
-		httpClient: client,
-		timeout:    timeout,
-	}
+		baseURL:     baseURL,
+		credentials: credentials,
+		signer:      signer,
+		testing:     cfg.Testing,
+		httpClient:  client,
+		timeout:     timeout,
+	}, nil
 }
 
 // TopUp sends a top-up request to Digiflazz
@@ -59,13 +104,18 @@ func (a *Adapter) TopUp(request *domain.SupplierRequest) (*domain.SupplierRespon
 		return nil, fmt.Errorf("supplier request is required")
 	}
 
+	sign, err := a.generateSignature(request.RefID)
+	if err != nil {
+		return nil, err
+	}
+
 	payload := &topUpRequest{
-		Username:     a.cfg.Username,
+		Username:     a.credentials.Username,
 		BuyerSkuCode: request.ProductCode,
 		CustomerNo:   request.DestinationNumber,
 		RefID:        request.RefID,
-		Sign:         a.generateSignature(request.RefID),
-		Testing:      a.cfg.Testing,
+		Sign:         sign,
+		Testing:      a.testing,
 	}
 
 	if request.AdditionalData != nil {
@@ -77,7 +127,7 @@ func (a *Adapter) TopUp(request *domain.SupplierRequest) (*domain.SupplierRespon
 		}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), request.Timeout(a.timeout))
 	defer cancel()
 
 	start := time.Now()
@@ -92,15 +142,39 @@ func (a *Adapter) TopUp(request *domain.SupplierRequest) (*domain.SupplierRespon
 
 // CheckBalance returns current Digiflazz deposit balance
 func (a *Adapter) CheckBalance() (float64, error) {
-	payload := map[string]string{
-		"cmd":      "deposit",
-		"username": a.cfg.Username,
-		"sign":     a.generateSignature("deposit"),
+	ctx, cancel := context.WithTimeout(httpclient.WithIdempotent(context.Background()), a.timeout)
+	defer cancel()
+
+	deposit, err := a.requestDeposit(ctx)
+	if err != nil {
+		return 0, err
 	}
+	return deposit, nil
+}
 
+// Ping checks that Digiflazz answers a signed request. The deposit check is
+// its cheapest call; it is sent once, without retries, so the latency is one
+// round trip and a failure is not hidden by a successful retry.
+func (a *Adapter) Ping() error {
 	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
 	defer cancel()
 
+	_, err := a.requestDeposit(ctx)
+	return err
+}
+
+func (a *Adapter) requestDeposit(ctx context.Context) (float64, error) {
+	sign, err := a.generateSignature("deposit")
+	if err != nil {
+		return 0, err
+	}
+
+	payload := map[string]string{
+		"cmd":      "deposit",
+		"username": a.credentials.Username,
+		"sign":     sign,
+	}
+
 	var response digiflazzBalanceResponse
 	if err := a.doPost(ctx, balanceEndpoint, payload, &response); err != nil {
 		return 0, err
@@ -119,14 +193,19 @@ func (a *Adapter) CheckStatus(refID string) (*domain.SupplierResponse, error) {
 		return nil, fmt.Errorf("ref id is required")
 	}
 
+	sign, err := a.generateSignature(refID)
+	if err != nil {
+		return nil, err
+	}
+
 	payload := map[string]string{
-		"username": a.cfg.Username,
+		"username": a.credentials.Username,
 		"ref_id":   refID,
-		"sign":     a.generateSignature(refID),
+		"sign":     sign,
 		"type":     "status",
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	ctx, cancel := context.WithTimeout(httpclient.WithIdempotent(context.Background()), a.timeout)
 	defer cancel()
 
 	start := time.Now()
@@ -141,13 +220,18 @@ func (a *Adapter) CheckStatus(refID string) (*domain.SupplierResponse, error) {
 
 // GetProductCatalog pulls Digiflazz price list
 func (a *Adapter) GetProductCatalog() ([]*domain.Product, error) {
+	sign, err := a.generateSignature("pricelist")
+	if err != nil {
+		return nil, err
+	}
+
 	payload := map[string]string{
 		"cmd":      "prepaid",
-		"username": a.cfg.Username,
-		"sign":     a.generateSignature("pricelist"),
+		"username": a.credentials.Username,
+		"sign":     sign,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	ctx, cancel := context.WithTimeout(httpclient.WithIdempotent(context.Background()), a.timeout)
 	defer cancel()
 
 	var response digiflazzPriceListResponse
@@ -211,7 +295,7 @@ func (a *Adapter) doPost(ctx context.Context, path string, payload httpPayload,
 }
 
 func (a *Adapter) endpoint(path string) string {
-	base := strings.TrimRight(a.cfg.BaseURL, "/")
+	base := strings.TrimRight(a.baseURL, "/")
 	return base + path
 }
 
@@ -235,6 +319,11 @@ func (a *Adapter) mapTransactionResponse(resp *digiflazzTransactionResponse, dur
 		statusCode = http.StatusBadGateway
 	}
 
+	classification, rcDescription := classifyResponse(resp.Data.ResponseCode, resp.Data.Status)
+	if success {
+		classification = domain.SupplierResultSuccess
+	}
+
 	serial := resp.Data.Sn
 	if serial == "" {
 		serial = resp.Data.SerialNumber
@@ -249,6 +338,7 @@ func (a *Adapter) mapTransactionResponse(resp *digiflazzTransactionResponse, dur
 		"buyer_last_saldo": resp.Data.BuyerLastSaldo,
 		"tele":             resp.Data.Tele,
 		"rc":               resp.Data.ResponseCode,
+		"rc_description":   rcDescription,
 		"message":          resp.Data.Message,
 	}
 
@@ -260,13 +350,25 @@ func (a *Adapter) mapTransactionResponse(resp *digiflazzTransactionResponse, dur
 		StatusCode:   statusCode,
 		ResponseTime: int(duration.Milliseconds()),
 		Data:         dataMap,
+
+		ResponseCode:   resp.Data.ResponseCode,
+		Classification: classification,
 	}, nil
 }
 
-func (a *Adapter) generateSignature(seed string) string {
-	builder := a.cfg.Username + a.cfg.APIKey + seed
-	sum := md5.Sum([]byte(builder))
-	return hex.EncodeToString(sum[:])
+func (a *Adapter) generateSignature(seed string) (string, error) {
+	sign, err := a.signer.Sign(a.credentials, seed)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign digiflazz request: %w", err)
+	}
+	return sign, nil
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return strings.TrimSpace(*value)
 }
 
 // --- Digiflazz DTOs ---
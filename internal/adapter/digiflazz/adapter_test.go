@@ -0,0 +1,183 @@
+package digiflazz
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alfanzaky/eraflazz/config"
+	"github.com/alfanzaky/eraflazz/internal/adapter/sandbox"
+	"github.com/alfanzaky/eraflazz/internal/domain"
+)
+
+// fixtureDir holds the sanitized Digiflazz traffic recorded in sandbox record mode
+var fixtureDir = filepath.Join("..", "..", "..", "testdata", "suppliers", "digiflazz")
+
+// newReplayAdapter builds an adapter whose HTTP calls are served by the
+// sandbox replay transport from the recorded fixtures
+func newReplayAdapter(t *testing.T) *Adapter {
+	t.Helper()
+
+	replayer, err := sandbox.NewReplayerFromDir(fixtureDir, sandbox.DefaultSanitizer())
+	if err != nil {
+		t.Fatalf("NewReplayerFromDir() unexpected error: %v", err)
+	}
+
+	adapter, err := NewAdapter(config.DigiflazzConfig{
+		BaseURL:  "https://api.digiflazz.com/v1",
+		Username: "replay-user",
+		APIKey:   "replay-key",
+		Testing:  true,
+	}, nil, &http.Client{Transport: replayer})
+	if err != nil {
+		t.Fatalf("NewAdapter() unexpected error: %v", err)
+	}
+	return adapter
+}
+
+func TestReplayCheckBalance(t *testing.T) {
+	adapter := newReplayAdapter(t)
+
+	balance, err := adapter.CheckBalance()
+	if err != nil {
+		t.Fatalf("CheckBalance() unexpected error: %v", err)
+	}
+	if balance != 1500000 {
+		t.Errorf("CheckBalance() = %v, want 1500000", balance)
+	}
+	if err := adapter.Ping(); err != nil {
+		t.Errorf("Ping() unexpected error: %v", err)
+	}
+}
+
+func TestReplayTopUp(t *testing.T) {
+	tests := []struct {
+		name               string
+		productCode        string
+		destination        string
+		wantSuccess        bool
+		wantStatusCode     int
+		wantClassification string
+		wantRC             string
+		wantSerial         string
+	}{
+		{
+			name:               "success",
+			productCode:        "xld10",
+			destination:        "081212345678",
+			wantSuccess:        true,
+			wantStatusCode:     http.StatusOK,
+			wantClassification: domain.SupplierResultSuccess,
+			wantRC:             "00",
+			wantSerial:         "********************1234",
+		},
+		{
+			name:               "pending",
+			productCode:        "xld25",
+			destination:        "081212345678",
+			wantStatusCode:     http.StatusAccepted,
+			wantClassification: domain.SupplierResultPending,
+			wantRC:             "03",
+		},
+		{
+			name:               "seller unavailable is retryable",
+			productCode:        "tsel5",
+			destination:        "081212345678",
+			wantStatusCode:     http.StatusBadGateway,
+			wantClassification: domain.SupplierResultRetryable,
+			wantRC:             "53",
+		},
+		{
+			name:               "wrong destination is permanent",
+			productCode:        "tsel10",
+			destination:        "081200000000",
+			wantStatusCode:     http.StatusBadGateway,
+			wantClassification: domain.SupplierResultPermanent,
+			wantRC:             "54",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := newReplayAdapter(t)
+
+			response, err := adapter.TopUp(&domain.SupplierRequest{
+				ProductCode:       tt.productCode,
+				DestinationNumber: tt.destination,
+				RefID:             "TRX-" + strings.ToUpper(tt.productCode),
+			})
+			if err != nil {
+				t.Fatalf("TopUp() unexpected error: %v", err)
+			}
+
+			if response.Success != tt.wantSuccess {
+				t.Errorf("Success = %v, want %v", response.Success, tt.wantSuccess)
+			}
+			if response.StatusCode != tt.wantStatusCode {
+				t.Errorf("StatusCode = %d, want %d", response.StatusCode, tt.wantStatusCode)
+			}
+			if response.Classification != tt.wantClassification {
+				t.Errorf("Classification = %q, want %q", response.Classification, tt.wantClassification)
+			}
+			if response.ResponseCode != tt.wantRC {
+				t.Errorf("ResponseCode = %q, want %q", response.ResponseCode, tt.wantRC)
+			}
+			if response.SerialNumber != tt.wantSerial {
+				t.Errorf("SerialNumber = %q, want %q", response.SerialNumber, tt.wantSerial)
+			}
+			// The replayer rewrites the recorded ref_id to the request's
+			if want := "TRX-" + strings.ToUpper(tt.productCode); response.TrxID != want {
+				t.Errorf("TrxID = %q, want %q", response.TrxID, want)
+			}
+		})
+	}
+}
+
+func TestReplayPendingThenStatus(t *testing.T) {
+	adapter := newReplayAdapter(t)
+
+	pending, err := adapter.TopUp(&domain.SupplierRequest{ProductCode: "xld25", DestinationNumber: "081212345678", RefID: "TRX-PENDING"})
+	if err != nil {
+		t.Fatalf("TopUp() unexpected error: %v", err)
+	}
+	if pending.Classification != domain.SupplierResultPending {
+		t.Fatalf("TopUp() classification = %q, want pending", pending.Classification)
+	}
+
+	status, err := adapter.CheckStatus("TRX-PENDING")
+	if err != nil {
+		t.Fatalf("CheckStatus() unexpected error: %v", err)
+	}
+	if !status.Success || status.SerialNumber != "********************9876" || status.TrxID != "TRX-PENDING" {
+		t.Errorf("CheckStatus() = success %v, sn %q, trx %q; want the recorded success for TRX-PENDING",
+			status.Success, status.SerialNumber, status.TrxID)
+	}
+}
+
+func TestReplayRetrySequence(t *testing.T) {
+	adapter := newReplayAdapter(t)
+	request := &domain.SupplierRequest{ProductCode: "tsel5", DestinationNumber: "081212345678", RefID: "TRX-RETRY"}
+
+	// tsel5 was recorded failing once, then succeeding; the last fixture
+	// keeps being served afterwards
+	wantRC := []string{"53", "00", "00"}
+	for i, want := range wantRC {
+		response, err := adapter.TopUp(request)
+		if err != nil {
+			t.Fatalf("TopUp() attempt %d unexpected error: %v", i+1, err)
+		}
+		if response.ResponseCode != want {
+			t.Errorf("TopUp() attempt %d rc = %q, want %q", i+1, response.ResponseCode, want)
+		}
+	}
+}
+
+func TestReplayWithoutFixture(t *testing.T) {
+	adapter := newReplayAdapter(t)
+
+	_, err := adapter.TopUp(&domain.SupplierRequest{ProductCode: "isat10", DestinationNumber: "085712345678", RefID: "TRX-MISSING"})
+	if err == nil || !strings.Contains(err.Error(), "no supplier fixture") {
+		t.Fatalf("TopUp() error = %v, want a missing fixture error", err)
+	}
+}
@@ -0,0 +1,223 @@
+package digiflazz
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/httpclient"
+)
+
+// Pasca-bayar commands, sent to the same /transaction endpoint as prepaid
+const (
+	commandInquiry = "inq-pasca"
+	commandPayment = "pay-pasca"
+)
+
+var _ domain.PostpaidSupplierAdapter = (*Adapter)(nil)
+
+// CheckBill inquires the outstanding bill of a postpaid customer. The RefID
+// must be reused by PayBill to pay the inquired bill.
+func (a *Adapter) CheckBill(request *domain.SupplierRequest) (*domain.BillInquiry, error) {
+	var response digiflazzPostpaidResponse
+	if _, err := a.doPostpaid(commandInquiry, request, &response); err != nil {
+		return nil, err
+	}
+
+	if response.Data == nil {
+		return nil, fmt.Errorf("digiflazz response missing data: %s", response.Message)
+	}
+	return response.Data.toBillInquiry(), nil
+}
+
+// PayBill pays a bill previously inquired with the same RefID
+func (a *Adapter) PayBill(request *domain.SupplierRequest) (*domain.SupplierResponse, error) {
+	var response digiflazzTransactionResponse
+	duration, err := a.doPostpaid(commandPayment, request, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.mapTransactionResponse(&response, duration)
+}
+
+func (a *Adapter) doPostpaid(command string, request *domain.SupplierRequest, target interface{}) (time.Duration, error) {
+	if request == nil {
+		return 0, fmt.Errorf("supplier request is required")
+	}
+	if strings.TrimSpace(request.RefID) == "" {
+		return 0, fmt.Errorf("ref id is required")
+	}
+
+	sign, err := a.generateSignature(request.RefID)
+	if err != nil {
+		return 0, err
+	}
+
+	payload := &postpaidRequest{
+		Commands:     command,
+		Username:     a.credentials.Username,
+		BuyerSkuCode: request.ProductCode,
+		CustomerNo:   request.DestinationNumber,
+		RefID:        request.RefID,
+		Sign:         sign,
+		Testing:      a.testing,
+	}
+
+	// An inquiry can be repeated safely, a payment cannot
+	ctx := context.Background()
+	if command == commandInquiry {
+		ctx = httpclient.WithIdempotent(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, request.Timeout(a.timeout))
+	defer cancel()
+
+	start := time.Now()
+	if err := a.doPost(ctx, transactionEndpoint, payload, target); err != nil {
+		return 0, err
+	}
+
+	return time.Since(start), nil
+}
+
+// --- Digiflazz pasca-bayar DTOs ---
+
+type postpaidRequest struct {
+	Commands     string `json:"commands"`
+	Username     string `json:"username"`
+	BuyerSkuCode string `json:"buyer_sku_code"`
+	CustomerNo   string `json:"customer_no"`
+	RefID        string `json:"ref_id"`
+	Sign         string `json:"sign"`
+	Testing      bool   `json:"testing"`
+}
+
+type digiflazzPostpaidResponse struct {
+	Message string                 `json:"message"`
+	Data    *digiflazzPostpaidData `json:"data"`
+}
+
+type digiflazzPostpaidData struct {
+	RefID        string                 `json:"ref_id"`
+	CustomerNo   string                 `json:"customer_no"`
+	CustomerName string                 `json:"customer_name"`
+	BuyerSkuCode string                 `json:"buyer_sku_code"`
+	Admin        flexFloat              `json:"admin"`
+	Price        flexFloat              `json:"price"`
+	SellingPrice flexFloat              `json:"selling_price"`
+	Status       string                 `json:"status"`
+	ResponseCode string                 `json:"rc"`
+	Message      string                 `json:"message"`
+	Desc         map[string]interface{} `json:"desc"`
+}
+
+type digiflazzBillDetail struct {
+	Periode      string    `json:"periode"`
+	NilaiTagihan flexFloat `json:"nilai_tagihan"`
+	Admin        flexFloat `json:"admin"`
+	Denda        flexFloat `json:"denda"`
+	BiayaLain    flexFloat `json:"biaya_lain"`
+}
+
+func (data *digiflazzPostpaidData) toBillInquiry() *domain.BillInquiry {
+	classification, rcDescription := classifyResponse(data.ResponseCode, data.Status)
+	success := strings.EqualFold(data.Status, statusSuccess)
+	if success {
+		classification = domain.SupplierResultSuccess
+	}
+
+	message := data.Message
+	if message == "" {
+		message = rcDescription
+	}
+
+	inquiry := &domain.BillInquiry{
+		RefID:          data.RefID,
+		ProductCode:    data.BuyerSkuCode,
+		CustomerNo:     data.CustomerNo,
+		CustomerName:   data.CustomerName,
+		AdminFee:       float64(data.Admin),
+		Price:          float64(data.Price),
+		SellingPrice:   float64(data.SellingPrice),
+		Success:        success,
+		Message:        message,
+		ResponseCode:   data.ResponseCode,
+		Classification: classification,
+	}
+
+	// desc holds the periods under "detail" next to product specific
+	// scalars (tarif, daya, lembar_tagihan, ...)
+	for key, value := range data.Desc {
+		if key == "detail" {
+			inquiry.Periods = parseBillPeriods(value)
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			addBillDetail(inquiry, key, v)
+		case float64:
+			addBillDetail(inquiry, key, strconv.FormatFloat(v, 'f', -1, 64))
+		}
+	}
+
+	for _, period := range inquiry.Periods {
+		inquiry.Amount += period.Amount
+		inquiry.Penalty += period.Penalty + period.Other
+	}
+	if len(inquiry.Periods) == 0 {
+		inquiry.Amount = inquiry.Price - inquiry.AdminFee
+	}
+
+	return inquiry
+}
+
+func addBillDetail(inquiry *domain.BillInquiry, key, value string) {
+	if inquiry.Details == nil {
+		inquiry.Details = make(map[string]string)
+	}
+	inquiry.Details[key] = value
+}
+
+func parseBillPeriods(value interface{}) []*domain.BillPeriod {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var details []digiflazzBillDetail
+	if err := json.Unmarshal(raw, &details); err != nil {
+		return nil
+	}
+
+	periods := make([]*domain.BillPeriod, 0, len(details))
+	for _, detail := range details {
+		periods = append(periods, &domain.BillPeriod{
+			Period:   detail.Periode,
+			Amount:   float64(detail.NilaiTagihan),
+			AdminFee: float64(detail.Admin),
+			Penalty:  float64(detail.Denda),
+			Other:    float64(detail.BiayaLain),
+		})
+	}
+	return periods
+}
+
+// flexFloat decodes amounts Digiflazz sends either as numbers or as strings
+type flexFloat float64
+
+func (f *flexFloat) UnmarshalJSON(raw []byte) error {
+	value := strings.Trim(strings.TrimSpace(string(raw)), `"`)
+	if value == "" || value == "null" {
+		*f = 0
+		return nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("invalid digiflazz amount %q: %w", value, err)
+	}
+	*f = flexFloat(parsed)
+	return nil
+}
@@ -0,0 +1,82 @@
+package digiflazz
+
+import (
+	"strings"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+)
+
+// rcInfo describes how a Digiflazz response code must be handled
+type rcInfo struct {
+	classification string
+	description    string
+}
+
+// responseCodes classifies Digiflazz rc values. Codes tied to the request
+// itself (destination, nominal, duplicate ref) are permanent: retrying or
+// failing over would fail again or charge twice. Codes tied to the seller or
+// our account are retryable on another supplier.
+var responseCodes = map[string]rcInfo{
+	"00": {domain.SupplierResultSuccess, "Transaksi Sukses"},
+	"01": {domain.SupplierResultPending, "Timeout"},
+	"02": {domain.SupplierResultRetryable, "Transaksi Gagal"},
+	"03": {domain.SupplierResultPending, "Transaksi Pending"},
+	"40": {domain.SupplierResultPermanent, "Payload Error"},
+	"41": {domain.SupplierResultRetryable, "Signature tidak valid"},
+	"42": {domain.SupplierResultRetryable, "Gagal memproses API Buyer"},
+	"43": {domain.SupplierResultRetryable, "SKU tidak di temukan atau Non-Aktif"},
+	"44": {domain.SupplierResultRetryable, "Saldo tidak cukup"},
+	"45": {domain.SupplierResultRetryable, "IP tidak dikenali"},
+	"47": {domain.SupplierResultPermanent, "Transaksi sudah terjadi di buyer lain"},
+	"49": {domain.SupplierResultPermanent, "Ref ID tidak unik"},
+	"50": {domain.SupplierResultRetryable, "Transaksi Tidak Ditemukan"},
+	"51": {domain.SupplierResultPermanent, "Nomor Tujuan Diblokir"},
+	"52": {domain.SupplierResultPermanent, "Prefix Tidak Sesuai Operator"},
+	"53": {domain.SupplierResultRetryable, "Produk Seller Sedang Tidak Tersedia"},
+	"54": {domain.SupplierResultPermanent, "Nomor Tujuan Salah"},
+	"55": {domain.SupplierResultRetryable, "Produk Sedang Gangguan"},
+	"56": {domain.SupplierResultRetryable, "Limit saldo seller"},
+	"57": {domain.SupplierResultPermanent, "Jumlah Digit Kurang Atau Lebih"},
+	"58": {domain.SupplierResultRetryable, "Sedang Cut Off"},
+	"59": {domain.SupplierResultPermanent, "Tujuan di Luar Wilayah/Cluster"},
+	"60": {domain.SupplierResultPermanent, "Tagihan belum tersedia"},
+	"61": {domain.SupplierResultRetryable, "Belum pernah melakukan deposit"},
+	"62": {domain.SupplierResultRetryable, "Seller sedang mengalami gangguan"},
+	"63": {domain.SupplierResultRetryable, "Tidak support transaksi multi"},
+	"64": {domain.SupplierResultRetryable, "Tarik tiket gagal"},
+	"65": {domain.SupplierResultRetryable, "Limit transaksi multi"},
+	"66": {domain.SupplierResultRetryable, "Cut Off (Perbaikan Sistem Seller)"},
+	"67": {domain.SupplierResultRetryable, "Seller belum ter-verifikasi"},
+	"68": {domain.SupplierResultRetryable, "Stok habis"},
+	"69": {domain.SupplierResultRetryable, "Harga seller lebih besar dari ketentuan harga Buyer"},
+	"70": {domain.SupplierResultPending, "Timeout Dari Biller"},
+	"71": {domain.SupplierResultRetryable, "Produk Sedang Tidak Stabil"},
+	"72": {domain.SupplierResultPermanent, "Lakukan Unreg Paket Dahulu"},
+	"73": {domain.SupplierResultPermanent, "Kwh Melebihi Batas"},
+	"74": {domain.SupplierResultRetryable, "Transaksi Refund"},
+	"80": {domain.SupplierResultRetryable, "Akun diblokir oleh Seller"},
+	"81": {domain.SupplierResultRetryable, "Seller diblokir"},
+	"82": {domain.SupplierResultRetryable, "Akun belum ter-verifikasi"},
+	"83": {domain.SupplierResultRetryable, "Limitasi pricelist"},
+	"84": {domain.SupplierResultPermanent, "Nominal tidak valid"},
+	"85": {domain.SupplierResultRetryable, "Limitasi transaksi"},
+	"86": {domain.SupplierResultRetryable, "Limitasi cek PLN"},
+	"99": {domain.SupplierResultPending, "DF Router Issue"},
+}
+
+// classifyResponse returns the classification of an rc, falling back to the
+// transaction status for unknown codes
+func classifyResponse(rc, status string) (string, string) {
+	if info, ok := responseCodes[strings.TrimSpace(rc)]; ok {
+		return info.classification, info.description
+	}
+
+	switch {
+	case strings.EqualFold(status, statusSuccess):
+		return domain.SupplierResultSuccess, ""
+	case strings.EqualFold(status, statusPending):
+		return domain.SupplierResultPending, ""
+	default:
+		return domain.SupplierResultRetryable, ""
+	}
+}
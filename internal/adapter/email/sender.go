@@ -0,0 +1,42 @@
+package email
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/mailer"
+)
+
+// Sender delivers EMAIL outbox messages over SMTP
+type Sender struct {
+	mailer *mailer.Mailer
+}
+
+// NewSender wraps an SMTP mailer as an outbox message sender
+func NewSender(m *mailer.Mailer) *Sender {
+	return &Sender{mailer: m}
+}
+
+// Channel returns the outbox destination handled by the sender
+func (s *Sender) Channel() string {
+	return domain.NotificationChannelEmail
+}
+
+// Send emails an outbox message and returns its Message-ID
+func (s *Sender) Send(ctx context.Context, message *domain.Outbox) (string, error) {
+	if message.Subject == nil || *message.Subject == "" {
+		return "", &mailer.PermanentError{Err: errors.New("email subject is required")}
+	}
+
+	msg := &mailer.Message{
+		To:      message.RecipientNumber,
+		Subject: *message.Subject,
+		Body:    message.Message,
+	}
+	if message.RecipientName != nil {
+		msg.ToName = *message.RecipientName
+	}
+
+	return s.mailer.Send(ctx, msg)
+}
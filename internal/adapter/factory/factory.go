@@ -13,12 +13,23 @@ import (
 type supplierAdapterFactory struct {
 	mu       sync.RWMutex
 	adapters map[string]domain.SupplierAdapter
+	builders map[string]domain.SupplierAdapterBuilder
+	built    map[string]*builtAdapter // Keyed by supplier ID
+}
+
+// builtAdapter caches an adapter built for a supplier together with the
+// settings it was built from, so edited credentials trigger a rebuild
+type builtAdapter struct {
+	fingerprint string
+	adapter     domain.SupplierAdapter
 }
 
 // NewSupplierAdapterFactory creates a new supplier adapter registry instance.
 func NewSupplierAdapterFactory() domain.SupplierAdapterFactory {
 	return &supplierAdapterFactory{
 		adapters: make(map[string]domain.SupplierAdapter),
+		builders: make(map[string]domain.SupplierAdapterBuilder),
+		built:    make(map[string]*builtAdapter),
 	}
 }
 
@@ -28,7 +39,7 @@ func (f *supplierAdapterFactory) RegisterAdapter(code string, adapter domain.Sup
 		return
 	}
 
-	normalized := strings.ToUpper(strings.TrimSpace(code))
+	normalized := normalizeCode(code)
 	if normalized == "" {
 		return
 	}
@@ -38,9 +49,27 @@ func (f *supplierAdapterFactory) RegisterAdapter(code string, adapter domain.Sup
 	f.adapters[normalized] = adapter
 }
 
+// RegisterBuilder registers a builder creating adapters of the given type
+// from supplier records.
+func (f *supplierAdapterFactory) RegisterBuilder(adapterType string, builder domain.SupplierAdapterBuilder) {
+	if builder == nil {
+		return
+	}
+
+	normalized := normalizeCode(adapterType)
+	if normalized == "" {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.builders[normalized] = builder
+	f.built = make(map[string]*builtAdapter)
+}
+
 // GetAdapter returns the adapter implementation for a supplier code.
 func (f *supplierAdapterFactory) GetAdapter(code string) (domain.SupplierAdapter, error) {
-	normalized := strings.ToUpper(strings.TrimSpace(code))
+	normalized := normalizeCode(code)
 	if normalized == "" {
 		return nil, fmt.Errorf("supplier code is required")
 	}
@@ -55,3 +84,127 @@ func (f *supplierAdapterFactory) GetAdapter(code string) (domain.SupplierAdapter
 
 	return adapter, nil
 }
+
+// GetSupplierAdapter returns the adapter for a supplier record. An adapter
+// registered under the supplier code wins; otherwise the builder for the
+// supplier's adapter type creates one bound to the record's credentials.
+func (f *supplierAdapterFactory) GetSupplierAdapter(supplier *domain.Supplier) (domain.SupplierAdapter, error) {
+	if supplier == nil {
+		return nil, fmt.Errorf("supplier is required")
+	}
+
+	if adapter, err := f.GetAdapter(supplier.Code); err == nil {
+		return adapter, nil
+	}
+
+	adapterType := normalizeCode(supplier.GetAdapterType())
+	fingerprint := supplierFingerprint(supplier)
+
+	f.mu.RLock()
+	builder, ok := f.builders[adapterType]
+	cached := f.built[supplier.ID]
+	f.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("supplier adapter for %s not found", adapterType)
+	}
+	if cached != nil && cached.fingerprint == fingerprint {
+		return cached.adapter, nil
+	}
+
+	adapter, err := builder(supplier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build %s adapter for %s: %w", adapterType, supplier.Code, err)
+	}
+
+	f.mu.Lock()
+	f.built[supplier.ID] = &builtAdapter{fingerprint: fingerprint, adapter: adapter}
+	f.mu.Unlock()
+
+	return adapter, nil
+}
+
+// HasBuilder reports whether a builder is registered for the adapter type.
+func (f *supplierAdapterFactory) HasBuilder(adapterType string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	_, ok := f.builders[normalizeCode(adapterType)]
+	return ok
+}
+
+// Load builds the adapter of a supplier record now and caches it in place of
+// any adapter built from the record's previous settings. Requests already
+// holding the old adapter finish with it.
+func (f *supplierAdapterFactory) Load(supplier *domain.Supplier) (domain.SupplierAdapter, error) {
+	if supplier == nil {
+		return nil, fmt.Errorf("supplier is required")
+	}
+
+	if adapter, err := f.GetAdapter(supplier.Code); err == nil {
+		return adapter, nil
+	}
+
+	adapterType := normalizeCode(supplier.GetAdapterType())
+
+	f.mu.RLock()
+	builder, ok := f.builders[adapterType]
+	f.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported adapter type %s", adapterType)
+	}
+
+	adapter, err := builder(supplier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build %s adapter for %s: %w", adapterType, supplier.Code, err)
+	}
+
+	f.mu.Lock()
+	f.built[supplier.ID] = &builtAdapter{fingerprint: supplierFingerprint(supplier), adapter: adapter}
+	f.mu.Unlock()
+
+	return adapter, nil
+}
+
+// Unload drops the adapter built for a supplier; a later lookup rebuilds it.
+func (f *supplierAdapterFactory) Unload(supplierID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.built, supplierID)
+}
+
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// supplierFingerprint joins the supplier settings an adapter is built from
+func supplierFingerprint(supplier *domain.Supplier) string {
+	return strings.Join([]string{
+		supplier.Code,
+		supplier.APIURL,
+		stringValue(supplier.APIKey),
+		stringValue(supplier.APISecret),
+		stringValue(supplier.APIUsername),
+		stringValue(supplier.APIPassword),
+		stringValue(supplier.AdapterType),
+		stringValue(supplier.SignMethod),
+		fmt.Sprint(supplier.TimeoutSeconds),
+		requestRulesFingerprint(supplier.RequestRules),
+	}, "\x00")
+}
+
+// requestRulesFingerprint encodes the request rules the adapter is wrapped with
+func requestRulesFingerprint(rules domain.SupplierRequestRules) string {
+	value, err := rules.Value()
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,79 @@
+// Package normalize wraps supplier adapters with the supplier's request
+// rules, so destinations reach each supplier in the format it expects.
+package normalize
+
+import (
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+// Adapter rewrites the destination of each request before delegating it
+type Adapter struct {
+	domain.SupplierAdapter
+	rewriter     *domain.RequestRewriter
+	supplierCode string
+}
+
+// PostpaidAdapter is an Adapter that also rewrites postpaid bill requests
+type PostpaidAdapter struct {
+	*Adapter
+	postpaid domain.PostpaidSupplierAdapter
+}
+
+// Wrap decorates a supplier adapter with the supplier's request rules;
+// without rules it returns the adapter unchanged. Postpaid capable adapters
+// stay postpaid capable once wrapped.
+func Wrap(next domain.SupplierAdapter, supplier *domain.Supplier) (domain.SupplierAdapter, error) {
+	if next == nil || supplier.RequestRules.IsEmpty() {
+		return next, nil
+	}
+
+	rewriter, err := supplier.RequestRules.Compile()
+	if err != nil {
+		return nil, err
+	}
+
+	adapter := &Adapter{SupplierAdapter: next, rewriter: rewriter, supplierCode: supplier.Code}
+	if postpaid, ok := domain.AsPostpaidAdapter(next); ok {
+		return &PostpaidAdapter{Adapter: adapter, postpaid: postpaid}, nil
+	}
+	return adapter, nil
+}
+
+// TopUp rewrites the destination and forwards the purchase
+func (a *Adapter) TopUp(request *domain.SupplierRequest) (*domain.SupplierResponse, error) {
+	return a.SupplierAdapter.TopUp(a.rewrite(request))
+}
+
+// CheckBill rewrites the destination and forwards the bill inquiry
+func (a *PostpaidAdapter) CheckBill(request *domain.SupplierRequest) (*domain.BillInquiry, error) {
+	return a.postpaid.CheckBill(a.rewrite(request))
+}
+
+// PayBill rewrites the destination and forwards the bill payment
+func (a *PostpaidAdapter) PayBill(request *domain.SupplierRequest) (*domain.SupplierResponse, error) {
+	return a.postpaid.PayBill(a.rewrite(request))
+}
+
+// rewrite returns a copy of the request with the supplier's destination
+// format; the caller's request keeps the stored destination
+func (a *Adapter) rewrite(request *domain.SupplierRequest) *domain.SupplierRequest {
+	if request == nil {
+		return nil
+	}
+
+	destination := a.rewriter.Rewrite(request.ProductCode, request.DestinationNumber)
+	if destination == request.DestinationNumber {
+		return request
+	}
+
+	logger.Debug("Supplier request destination rewritten",
+		logger.String("supplier_code", a.supplierCode),
+		logger.String("product_code", request.ProductCode),
+		logger.String("ref_id", request.RefID),
+	)
+
+	rewritten := *request
+	rewritten.DestinationNumber = destination
+	return &rewritten
+}
@@ -0,0 +1,200 @@
+package normalize
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+)
+
+// recordingAdapter records the destination of every request it receives
+type recordingAdapter struct {
+	destinations []string
+}
+
+func (a *recordingAdapter) TopUp(request *domain.SupplierRequest) (*domain.SupplierResponse, error) {
+	a.destinations = append(a.destinations, request.DestinationNumber)
+	return &domain.SupplierResponse{}, nil
+}
+
+func (a *recordingAdapter) CheckBalance() (float64, error) { return 0, nil }
+
+func (a *recordingAdapter) Ping() error { return nil }
+
+func (a *recordingAdapter) CheckStatus(trxID string) (*domain.SupplierResponse, error) {
+	return &domain.SupplierResponse{}, nil
+}
+
+func (a *recordingAdapter) GetProductCatalog() ([]*domain.Product, error) { return nil, nil }
+
+func (a *recordingAdapter) ParseResponse(response []byte) (*domain.SupplierResponse, error) {
+	return &domain.SupplierResponse{}, nil
+}
+
+type recordingPostpaidAdapter struct {
+	recordingAdapter
+}
+
+func (a *recordingPostpaidAdapter) CheckBill(request *domain.SupplierRequest) (*domain.BillInquiry, error) {
+	a.destinations = append(a.destinations, request.DestinationNumber)
+	return &domain.BillInquiry{}, nil
+}
+
+func (a *recordingPostpaidAdapter) PayBill(request *domain.SupplierRequest) (*domain.SupplierResponse, error) {
+	a.destinations = append(a.destinations, request.DestinationNumber)
+	return &domain.SupplierResponse{}, nil
+}
+
+func TestWrapRewritesPerSupplier(t *testing.T) {
+	tests := []struct {
+		name        string
+		supplier    *domain.Supplier
+		productCode string
+		destination string
+		want        string
+	}{
+		{
+			name: "supplier expecting 62 numbers",
+			supplier: &domain.Supplier{Code: "DIGI", RequestRules: domain.SupplierRequestRules{Rules: []domain.RequestRule{
+				{Action: domain.RequestRulePhone62},
+			}}},
+			productCode: "TSEL10",
+			destination: "081234567890",
+			want:        "6281234567890",
+		},
+		{
+			name: "supplier expecting 0 numbers",
+			supplier: &domain.Supplier{Code: "LOKAL", RequestRules: domain.SupplierRequestRules{Rules: []domain.RequestRule{
+				{Action: domain.RequestRulePhone0},
+			}}},
+			productCode: "TSEL10",
+			destination: "6281234567890",
+			want:        "081234567890",
+		},
+		{
+			name: "supplier padding meter numbers",
+			supplier: &domain.Supplier{Code: "PLNH2H", RequestRules: domain.SupplierRequestRules{Rules: []domain.RequestRule{
+				{ProductPattern: `^PLN`, Action: domain.RequestRulePadLeft, Width: 12},
+			}}},
+			productCode: "PLN20",
+			destination: "12345678901",
+			want:        "012345678901",
+		},
+		{
+			name: "rule not matching the product",
+			supplier: &domain.Supplier{Code: "PLNH2H", RequestRules: domain.SupplierRequestRules{Rules: []domain.RequestRule{
+				{ProductPattern: `^PLN`, Action: domain.RequestRulePadLeft, Width: 12},
+			}}},
+			productCode: "TSEL10",
+			destination: "081234567890",
+			want:        "081234567890",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &recordingAdapter{}
+			adapter, err := Wrap(next, tt.supplier)
+			if err != nil {
+				t.Fatalf("Wrap() unexpected error: %v", err)
+			}
+
+			request := &domain.SupplierRequest{ProductCode: tt.productCode, DestinationNumber: tt.destination, RefID: "REF1"}
+			if _, err := adapter.TopUp(request); err != nil {
+				t.Fatalf("TopUp() unexpected error: %v", err)
+			}
+
+			if len(next.destinations) != 1 || next.destinations[0] != tt.want {
+				t.Errorf("supplier received %v, want [%s]", next.destinations, tt.want)
+			}
+			if request.DestinationNumber != tt.destination {
+				t.Errorf("caller's destination changed to %q, want %q", request.DestinationNumber, tt.destination)
+			}
+		})
+	}
+}
+
+func TestWrapPostpaid(t *testing.T) {
+	supplier := &domain.Supplier{Code: "PLNH2H", RequestRules: domain.SupplierRequestRules{Rules: []domain.RequestRule{
+		{Action: domain.RequestRuleAddPrefix, Value: "52"},
+	}}}
+	next := &recordingPostpaidAdapter{}
+
+	adapter, err := Wrap(next, supplier)
+	if err != nil {
+		t.Fatalf("Wrap() unexpected error: %v", err)
+	}
+	postpaid, ok := domain.AsPostpaidAdapter(adapter)
+	if !ok {
+		t.Fatal("wrapped postpaid adapter lost its postpaid capability")
+	}
+
+	request := &domain.SupplierRequest{ProductCode: "PLNPASCA", DestinationNumber: "1234567890"}
+	if _, err := postpaid.CheckBill(request); err != nil {
+		t.Fatalf("CheckBill() unexpected error: %v", err)
+	}
+	if _, err := postpaid.PayBill(request); err != nil {
+		t.Fatalf("PayBill() unexpected error: %v", err)
+	}
+	if _, err := postpaid.TopUp(request); err != nil {
+		t.Fatalf("TopUp() unexpected error: %v", err)
+	}
+
+	want := []string{"521234567890", "521234567890", "521234567890"}
+	if strings.Join(next.destinations, ",") != strings.Join(want, ",") {
+		t.Errorf("supplier received %v, want %v", next.destinations, want)
+	}
+}
+
+func TestWrapWithoutRules(t *testing.T) {
+	next := &recordingAdapter{}
+	adapter, err := Wrap(next, &domain.Supplier{Code: "DIGI"})
+	if err != nil {
+		t.Fatalf("Wrap() unexpected error: %v", err)
+	}
+	if adapter != domain.SupplierAdapter(next) {
+		t.Error("Wrap() without rules should return the adapter unchanged")
+	}
+	if _, ok := domain.AsPostpaidAdapter(adapter); ok {
+		t.Error("prepaid adapter became postpaid capable")
+	}
+}
+
+func TestWrapRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   domain.SupplierRequestRules
+		wantErr string
+	}{
+		{
+			name:    "unknown action",
+			rules:   domain.SupplierRequestRules{Rules: []domain.RequestRule{{Action: "reverse"}}},
+			wantErr: `request rule 1: unknown action "reverse"`,
+		},
+		{
+			name:    "invalid product pattern",
+			rules:   domain.SupplierRequestRules{Rules: []domain.RequestRule{{ProductPattern: "[", Action: domain.RequestRulePhone62}}},
+			wantErr: "request rule 1: invalid product_pattern",
+		},
+		{
+			name: "failing example",
+			rules: domain.SupplierRequestRules{
+				Rules:    []domain.RequestRule{{Action: domain.RequestRulePhone62}},
+				Examples: []domain.RequestRuleExample{{ProductCode: "TSEL10", Destination: "081234567890", Expected: "081234567890"}},
+			},
+			wantErr: "request rule example 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter, err := Wrap(&recordingAdapter{}, &domain.Supplier{Code: "DIGI", RequestRules: tt.rules})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Wrap() error = %v, want %q", err, tt.wantErr)
+			}
+			if adapter != nil {
+				t.Error("Wrap() returned an adapter with an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+)
+
+// CashbackPublisher pays promotion cashback for successful transactions as
+// their completion events are relayed from the outbox
+type CashbackPublisher struct {
+	promotionUC domain.PromotionUsecase
+}
+
+// NewCashbackPublisher constructs a cashback publisher
+func NewCashbackPublisher(promotionUC domain.PromotionUsecase) *CashbackPublisher {
+	return &CashbackPublisher{promotionUC: promotionUC}
+}
+
+// Name returns publisher name used in logs
+func (p *CashbackPublisher) Name() string {
+	return "cashback"
+}
+
+// Publish applies the cashback a completed transaction earned. Redelivered
+// events are safe, a transaction is paid cashback at most once.
+func (p *CashbackPublisher) Publish(ctx context.Context, event *domain.DomainEvent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if event.EventType != domain.EventTransactionCompleted {
+		return nil
+	}
+
+	var payload domain.TransactionEventPayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return fmt.Errorf("failed to decode transaction event: %w", err)
+	}
+	if payload.Status != domain.StatusSuccess {
+		return nil
+	}
+
+	_, err := p.promotionUC.ApplyCashback(payload.TransactionID)
+	return err
+}
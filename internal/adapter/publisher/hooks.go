@@ -0,0 +1,172 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/metrics"
+)
+
+// HookPublisher hands relayed outbox events to the plugin hooks subscribed to
+// their type. A hook failing, panicking or timing out is logged and counted
+// but never fails the event, so plugins cannot hold back the other publishers
+// or push events into the dead letter queue.
+type HookPublisher struct {
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	names  map[string]bool
+	byType map[string][]domain.EventHook
+}
+
+// hookPanic is the error of a hook call that panicked
+type hookPanic struct {
+	value interface{}
+	stack []byte
+}
+
+func (p *hookPanic) Error() string {
+	return fmt.Sprintf("panic: %v", p.value)
+}
+
+// NewHookPublisher constructs a publisher without hooks. Timeout bounds every
+// hook call.
+func NewHookPublisher(timeout time.Duration) *HookPublisher {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	return &HookPublisher{
+		timeout: timeout,
+		names:   make(map[string]bool),
+		byType:  make(map[string][]domain.EventHook),
+	}
+}
+
+// Register subscribes a hook to its event types. Hooks are registered at
+// startup, before the relay runs.
+func (p *HookPublisher) Register(hook domain.EventHook) error {
+	name := strings.TrimSpace(hook.Name())
+	if name == "" {
+		return fmt.Errorf("event hook name is required")
+	}
+	events := hook.Events()
+	if len(events) == 0 {
+		return fmt.Errorf("event hook %s subscribes to no events", name)
+	}
+	for _, eventType := range events {
+		if !domain.IsValidEventType(eventType) {
+			return fmt.Errorf("event hook %s subscribes to unknown event type %s", name, eventType)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.names[name] {
+		return fmt.Errorf("event hook %s registered twice", name)
+	}
+	p.names[name] = true
+
+	subscribed := make(map[string]bool, len(events))
+	for _, eventType := range events {
+		if subscribed[eventType] {
+			continue
+		}
+		subscribed[eventType] = true
+		p.byType[eventType] = append(p.byType[eventType], hook)
+	}
+
+	logger.Info("Event hook registered",
+		logger.String("hook", name),
+		logger.String("events", strings.Join(events, ",")),
+	)
+	return nil
+}
+
+// Len returns the number of registered hooks
+func (p *HookPublisher) Len() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return len(p.names)
+}
+
+// Name returns publisher name used in logs
+func (p *HookPublisher) Name() string {
+	return "hooks"
+}
+
+// Publish calls the hooks subscribed to the event one after another. Events
+// relayed again because another publisher failed reach the hooks again.
+func (p *HookPublisher) Publish(ctx context.Context, event *domain.DomainEvent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	p.mu.RLock()
+	hooks := p.byType[event.EventType]
+	p.mu.RUnlock()
+
+	for _, hook := range hooks {
+		p.call(ctx, hook, event)
+	}
+	return nil
+}
+
+// call runs one hook in its own goroutine, so a panic is recovered there and
+// a hook ignoring its context is abandoned once the timeout passes
+func (p *HookPublisher) call(ctx context.Context, hook domain.EventHook, event *domain.DomainEvent) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- &hookPanic{value: r, stack: debug.Stack()}
+			}
+		}()
+		done <- hook.Handle(ctx, event)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", p.timeout)
+	}
+	duration := time.Since(start)
+
+	status := "success"
+	if panicked, ok := err.(*hookPanic); ok {
+		status = "panic"
+		logger.Error("Event hook panicked",
+			logger.String("hook", hook.Name()),
+			logger.String("event_id", event.ID),
+			logger.String("event_type", event.EventType),
+			logger.Any("panic", panicked.value),
+			logger.String("stack", string(panicked.stack)),
+		)
+	} else if err != nil {
+		status = "failed"
+		if ctx.Err() != nil {
+			status = "timeout"
+		}
+		logger.Warn("Event hook failed",
+			logger.String("hook", hook.Name()),
+			logger.String("event_id", event.ID),
+			logger.String("event_type", event.EventType),
+			logger.Duration("duration", duration),
+			logger.ErrorField(err),
+		)
+	}
+	metrics.RecordEventHook(hook.Name(), event.EventType, status, duration.Seconds())
+}
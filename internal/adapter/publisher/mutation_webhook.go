@@ -0,0 +1,37 @@
+package publisher
+
+import (
+	"context"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+)
+
+// MutationWebhookPublisher queues balance mutations for the mutation webhooks
+// of their users and uplines as the events are relayed from the outbox. The
+// deliveries themselves are batched and posted by the mutation webhook job.
+type MutationWebhookPublisher struct {
+	webhookUC domain.MutationWebhookUsecase
+}
+
+// NewMutationWebhookPublisher constructs a mutation webhook publisher
+func NewMutationWebhookPublisher(webhookUC domain.MutationWebhookUsecase) *MutationWebhookPublisher {
+	return &MutationWebhookPublisher{webhookUC: webhookUC}
+}
+
+// Name returns publisher name used in logs
+func (p *MutationWebhookPublisher) Name() string {
+	return "mutation-webhooks"
+}
+
+// Publish queues a balance mutated event. Redelivered events are safe, a
+// mutation is queued at most once per webhook.
+func (p *MutationWebhookPublisher) Publish(ctx context.Context, event *domain.DomainEvent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if event.EventType != domain.EventBalanceMutated {
+		return nil
+	}
+
+	return p.webhookUC.EnqueueMutation(event)
+}
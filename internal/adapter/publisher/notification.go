@@ -0,0 +1,33 @@
+package publisher
+
+import (
+	"context"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+)
+
+// NotificationPublisher turns outbox events into user notifications (WhatsApp,
+// email) by rendering message templates into the outgoing message table
+type NotificationPublisher struct {
+	notificationUC domain.NotificationUsecase
+}
+
+// NewNotificationPublisher constructs a notification publisher
+func NewNotificationPublisher(notificationUC domain.NotificationUsecase) *NotificationPublisher {
+	return &NotificationPublisher{notificationUC: notificationUC}
+}
+
+// Name returns publisher name used in logs
+func (p *NotificationPublisher) Name() string {
+	return "notifications"
+}
+
+// Publish queues notification messages for the event
+func (p *NotificationPublisher) Publish(ctx context.Context, event *domain.DomainEvent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, err := p.notificationUC.GenerateMessages(event)
+	return err
+}
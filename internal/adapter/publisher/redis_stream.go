@@ -0,0 +1,52 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+)
+
+// RedisStreamPublisher appends outbox events to a Redis stream
+type RedisStreamPublisher struct {
+	client redis.UniversalClient
+	stream string
+}
+
+// NewRedisStreamPublisher constructs a Redis stream publisher
+func NewRedisStreamPublisher(client redis.UniversalClient, stream string) *RedisStreamPublisher {
+	return &RedisStreamPublisher{
+		client: client,
+		stream: stream,
+	}
+}
+
+// Name returns publisher name used in logs
+func (p *RedisStreamPublisher) Name() string {
+	return "redis_stream:" + p.stream
+}
+
+// Publish appends the event to the configured stream
+func (p *RedisStreamPublisher) Publish(ctx context.Context, event *domain.DomainEvent) error {
+	body, err := json.Marshal(event.Envelope())
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	err = p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: p.stream,
+		Values: map[string]interface{}{
+			"id":    event.ID,
+			"type":  event.EventType,
+			"event": string(body),
+		},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to publish event to stream %s: %w", p.stream, err)
+	}
+
+	return nil
+}
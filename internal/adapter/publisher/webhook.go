@@ -0,0 +1,142 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+// WebhookPublisher posts outbox events as JSON to a webhook endpoint
+type WebhookPublisher struct {
+	url        string
+	secret     string
+	httpClient *http.Client
+}
+
+// NewWebhookPublisher constructs a webhook publisher. When secret is set, the
+// request body is signed with HMAC-SHA256 in the X-Eraflazz-Signature header.
+func NewWebhookPublisher(url, secret string, timeout time.Duration, httpClient *http.Client) *WebhookPublisher {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: timeout}
+	}
+
+	return &WebhookPublisher{
+		url:        url,
+		secret:     secret,
+		httpClient: httpClient,
+	}
+}
+
+// Name returns publisher name used in logs
+func (p *WebhookPublisher) Name() string {
+	return "webhook:" + p.url
+}
+
+// Publish sends the event to the webhook endpoint. Any non-2xx response is an error.
+func (p *WebhookPublisher) Publish(ctx context.Context, event *domain.DomainEvent) error {
+	body, err := json.Marshal(event.Envelope())
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", event.ID)
+	req.Header.Set("X-Event-Type", event.EventType)
+	if p.secret != "" {
+		mac := hmac.New(sha256.New, []byte(p.secret))
+		mac.Write(body)
+		req.Header.Set("X-Eraflazz-Signature", hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// WebhookDispatcher posts events to webhook endpoints chosen per call, such
+// as the mutation webhooks of users, the way a WebhookPublisher does
+type WebhookDispatcher struct {
+	httpClient *http.Client
+}
+
+// NewWebhookDispatcher constructs a webhook dispatcher sharing one HTTP client
+// between every endpoint. The endpoints are chosen by users, so without a
+// client of its own the dispatcher only connects to public addresses.
+func NewWebhookDispatcher(timeout time.Duration, httpClient *http.Client) *WebhookDispatcher {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	if httpClient == nil {
+		httpClient = NewPublicOnlyClient(timeout)
+	}
+
+	return &WebhookDispatcher{httpClient: httpClient}
+}
+
+// NewPublicOnlyClient builds an HTTP client that refuses to connect to
+// loopback, private, link-local and other non-public addresses. The address
+// is checked when the connection is made, after DNS resolution, so a host
+// resolving elsewhere than when its URL was accepted is still refused.
+// Redirects are not followed and no proxy is used.
+func NewPublicOnlyClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if !utils.IsPublicIP(net.ParseIP(host)) {
+				return fmt.Errorf("connection to non-public address %s refused", host)
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+			MaxIdleConnsPerHost: 10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Dispatch sends the event to url, signed with secret. Any non-2xx response is an error.
+func (d *WebhookDispatcher) Dispatch(ctx context.Context, url, secret string, event *domain.DomainEvent) error {
+	return NewWebhookPublisher(url, secret, 0, d.httpClient).Publish(ctx, event)
+}
@@ -0,0 +1,37 @@
+package sandbox
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Config selects how supplier HTTP traffic is handled
+type Config struct {
+	Mode string // off, record or replay
+	Dir  string // Fixture directory for a single supplier
+}
+
+// NewTransport returns the supplier HTTP transport for the sandbox mode, or
+// nil when the sandbox is off so callers use their real transport
+func NewTransport(cfg Config) (http.RoundTripper, error) {
+	mode := strings.ToLower(strings.TrimSpace(cfg.Mode))
+
+	var transport http.RoundTripper
+	switch mode {
+	case "", ModeOff:
+		return nil, nil
+	case ModeRecord:
+		transport = NewRecorder(cfg.Dir, nil, DefaultSanitizer())
+	case ModeReplay:
+		replayer, err := NewReplayerFromDir(cfg.Dir, DefaultSanitizer())
+		if err != nil {
+			return nil, err
+		}
+		transport = replayer
+	default:
+		return nil, fmt.Errorf("unknown supplier sandbox mode %q", cfg.Mode)
+	}
+
+	return transport, nil
+}
@@ -0,0 +1,201 @@
+package sandbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Sandbox modes for supplier HTTP traffic
+const (
+	ModeOff    = "off"
+	ModeRecord = "record"
+	ModeReplay = "replay"
+)
+
+// Fixture is one recorded supplier interaction. Request and response bodies
+// are sanitized before they are written to disk.
+type Fixture struct {
+	Name       string          `json:"name"`
+	RecordedAt time.Time       `json:"recorded_at"`
+	Request    FixtureRequest  `json:"request"`
+	Response   FixtureResponse `json:"response"`
+}
+
+// FixtureRequest is the recorded supplier request
+type FixtureRequest struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// FixtureResponse is the recorded supplier response
+type FixtureResponse struct {
+	StatusCode  int             `json:"status_code"`
+	ContentType string          `json:"content_type,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"`
+}
+
+// Sanitizer scrubs credentials and customer data from recorded bodies.
+// Redacted keys are replaced entirely; masked keys keep their last four
+// characters so fixtures stay readable and still match replayed requests.
+type Sanitizer struct {
+	RedactKeys []string
+	MaskKeys   []string
+}
+
+// DefaultSanitizer covers Digiflazz credentials, signatures and customer identifiers
+func DefaultSanitizer() Sanitizer {
+	return Sanitizer{
+		RedactKeys: []string{"username", "sign", "key", "api_key", "apikey", "secret", "password", "token"},
+		MaskKeys:   []string{"customer_no", "tele", "sn", "serial_number", "wa"},
+	}
+}
+
+// SanitizeBody returns a sanitized copy of a JSON body. Non-JSON bodies are
+// stored as a JSON string.
+func (s Sanitizer) SanitizeBody(body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		encoded, _ := json.Marshal(string(body))
+		return encoded
+	}
+
+	sanitized, err := json.Marshal(s.sanitizeValue("", value))
+	if err != nil {
+		return nil
+	}
+	return sanitized
+}
+
+func (s Sanitizer) sanitizeValue(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = s.sanitizeValue(k, item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = s.sanitizeValue(key, item)
+		}
+		return out
+	case string:
+		if containsKey(s.RedactKeys, key) && v != "" {
+			return "REDACTED"
+		}
+		if containsKey(s.MaskKeys, key) {
+			return maskValue(v)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func containsKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func maskValue(value string) string {
+	runes := []rune(value)
+	if len(runes) <= 4 {
+		return value
+	}
+	return strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-4:])
+}
+
+// LoadFixtures reads every fixture in dir ordered by file name, which is the
+// recording order for fixtures written by the recorder
+func LoadFixtures(dir string) ([]*Fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fixtures: %w", err)
+	}
+	sort.Strings(paths)
+
+	fixtures := make([]*Fixture, 0, len(paths))
+	for _, path := range paths {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture %s: %w", path, err)
+		}
+
+		var fixture Fixture
+		if err := json.Unmarshal(raw, &fixture); err != nil {
+			return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+		}
+		if fixture.Name == "" {
+			fixture.Name = strings.TrimSuffix(filepath.Base(path), ".json")
+		}
+		fixtures = append(fixtures, &fixture)
+	}
+
+	return fixtures, nil
+}
+
+// SaveFixture writes a fixture into dir using its name as the file name
+func SaveFixture(dir string, fixture *Fixture) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create fixture dir: %w", err)
+	}
+
+	raw, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fixture: %w", err)
+	}
+
+	path := filepath.Join(dir, fixture.Name+".json")
+	if err := os.WriteFile(path, append(raw, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+
+	return nil
+}
+
+// matchKey identifies interchangeable requests: same method, path and values
+// of the match fields. Reference IDs and signatures differ on every call and
+// are deliberately left out.
+func matchKey(method, path string, body json.RawMessage, fields []string) string {
+	var values map[string]interface{}
+	_ = json.Unmarshal(body, &values)
+
+	parts := []string{strings.ToUpper(method), path}
+	for _, field := range fields {
+		if v, ok := values[field]; ok {
+			parts = append(parts, fmt.Sprintf("%s=%v", field, v))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// fixtureName builds a sortable, descriptive file name for a recorded request
+func fixtureName(seq int64, path string, body json.RawMessage) string {
+	var values map[string]interface{}
+	_ = json.Unmarshal(body, &values)
+
+	name := strings.Trim(strings.ReplaceAll(path, "/", "_"), "_")
+	for _, field := range []string{"cmd", "type", "buyer_sku_code"} {
+		if v, ok := values[field].(string); ok && v != "" {
+			name += "_" + v
+		}
+	}
+
+	return fmt.Sprintf("%019d_%s", seq, url.PathEscape(strings.ToLower(name)))
+}
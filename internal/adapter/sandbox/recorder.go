@@ -0,0 +1,103 @@
+package sandbox
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+// Recorder is an http.RoundTripper that forwards requests to the real
+// supplier and writes every interaction as a sanitized fixture
+type Recorder struct {
+	dir       string
+	next      http.RoundTripper
+	sanitizer Sanitizer
+
+	mu      sync.Mutex
+	lastSeq int64
+}
+
+// NewRecorder creates a recording transport writing fixtures into dir.
+// A nil next transport uses http.DefaultTransport.
+func NewRecorder(dir string, next http.RoundTripper, sanitizer Sanitizer) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &Recorder{
+		dir:       dir,
+		next:      next,
+		sanitizer: sanitizer,
+	}
+}
+
+// RoundTrip performs the request and records it. Recording failures are
+// logged and never fail the supplier call.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		reqBody = body
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	sanitizedReq := r.sanitizer.SanitizeBody(reqBody)
+	fixture := &Fixture{
+		Name:       fixtureName(r.nextSeq(), req.URL.Path, sanitizedReq),
+		RecordedAt: time.Now(),
+		Request: FixtureRequest{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Body:   sanitizedReq,
+		},
+		Response: FixtureResponse{
+			StatusCode:  resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+			Body:        r.sanitizer.SanitizeBody(respBody),
+		},
+	}
+
+	if err := SaveFixture(r.dir, fixture); err != nil {
+		logger.Warn("Failed to record supplier fixture",
+			logger.String("path", req.URL.Path),
+			logger.ErrorField(err),
+		)
+	}
+
+	return resp, nil
+}
+
+// nextSeq returns a strictly increasing sequence so fixture file names sort in
+// recording order even when requests land in the same nanosecond
+func (r *Recorder) nextSeq() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seq := time.Now().UnixNano()
+	if seq <= r.lastSeq {
+		seq = r.lastSeq + 1
+	}
+	r.lastSeq = seq
+	return seq
+}
@@ -0,0 +1,175 @@
+package sandbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Replayer is an http.RoundTripper that serves recorded fixtures instead of
+// calling the supplier. Fixtures with the same match key are served in
+// recording order; the last one keeps being served once the rest are used,
+// so a recorded pending-then-success sequence replays as such.
+type Replayer struct {
+	sanitizer   Sanitizer
+	matchFields []string
+
+	mu       sync.Mutex
+	fixtures map[string][]*Fixture
+	served   map[string]int
+}
+
+// DefaultMatchFields are the Digiflazz body fields that distinguish requests
+var DefaultMatchFields = []string{"cmd", "commands", "type", "buyer_sku_code", "customer_no"}
+
+// NewReplayer creates a replaying transport from fixtures. Incoming requests
+// go through the same sanitizer used while recording before matching.
+func NewReplayer(fixtures []*Fixture, sanitizer Sanitizer, matchFields []string) *Replayer {
+	if len(matchFields) == 0 {
+		matchFields = DefaultMatchFields
+	}
+
+	r := &Replayer{
+		sanitizer:   sanitizer,
+		matchFields: matchFields,
+		fixtures:    make(map[string][]*Fixture),
+		served:      make(map[string]int),
+	}
+	for _, fixture := range fixtures {
+		key := matchKey(fixture.Request.Method, fixture.Request.Path, fixture.Request.Body, matchFields)
+		r.fixtures[key] = append(r.fixtures[key], fixture)
+	}
+
+	return r
+}
+
+// NewReplayerFromDir loads fixtures from dir and creates a replaying transport
+func NewReplayerFromDir(dir string, sanitizer Sanitizer) (*Replayer, error) {
+	fixtures, err := LoadFixtures(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(fixtures) == 0 {
+		return nil, fmt.Errorf("no supplier fixtures found in %s", dir)
+	}
+
+	return NewReplayer(fixtures, sanitizer, nil), nil
+}
+
+// Reset rewinds every fixture sequence to the beginning
+func (r *Replayer) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.served = make(map[string]int)
+}
+
+// RoundTrip serves the next fixture matching the request
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		reqBody = body
+	}
+
+	key := matchKey(req.Method, req.URL.Path, r.sanitizer.SanitizeBody(reqBody), r.matchFields)
+	fixture := r.next(key)
+	if fixture == nil {
+		return nil, fmt.Errorf("no supplier fixture for %s", key)
+	}
+
+	body := []byte(fixture.Response.Body)
+	if refID := requestRefID(reqBody); refID != "" {
+		body = withRefID(body, refID)
+	}
+
+	header := make(http.Header)
+	contentType := fixture.Response.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	header.Set("Content-Type", contentType)
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fixture.Response.StatusCode, http.StatusText(fixture.Response.StatusCode)),
+		StatusCode:    fixture.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func (r *Replayer) next(key string) *Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fixtures := r.fixtures[key]
+	if len(fixtures) == 0 {
+		return nil
+	}
+
+	idx := r.served[key]
+	if idx >= len(fixtures) {
+		idx = len(fixtures) - 1
+	}
+	r.served[key] = idx + 1
+
+	return fixtures[idx]
+}
+
+func requestRefID(body []byte) string {
+	var values map[string]interface{}
+	if err := json.Unmarshal(body, &values); err != nil {
+		return ""
+	}
+	refID, _ := values["ref_id"].(string)
+	return refID
+}
+
+// withRefID rewrites every ref_id in the recorded response to the replayed
+// request's reference so responses line up with the transaction under test
+func withRefID(body []byte, refID string) []byte {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return body
+	}
+
+	rewritten, err := json.Marshal(replaceRefID(value, refID))
+	if err != nil {
+		return body
+	}
+	return rewritten
+}
+
+func replaceRefID(value interface{}, refID string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			if k == "ref_id" {
+				if _, ok := item.(string); ok {
+					v[k] = refID
+					continue
+				}
+			}
+			v[k] = replaceRefID(item, refID)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = replaceRefID(item, refID)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,72 @@
+package domain
+
+import "context"
+
+// Actor types recorded on balance mutations
+const (
+	ActorTypeUser   = "USER"   // A user ordering or transferring, over the API or H2H
+	ActorTypeAdmin  = "ADMIN"  // An admin acting through the admin API
+	ActorTypeSystem = "SYSTEM" // A worker or automatic flow
+)
+
+// System actor names for money moved without a request behind it
+const (
+	SystemActorRefund   = "transaction-refund" // Refund after a supplier failure
+	SystemActorCashback = "promotion-cashback" // Cashback of a promotion
+)
+
+// Actor identifies who or what moved money in a balance mutation
+type Actor struct {
+	Type      string
+	UserID    string // Acting user; empty for system actors
+	Name      string // H2H client ID for partner requests, component name for system actors
+	IPAddress string
+	UserAgent string
+}
+
+// SystemActor returns the actor of an automatic flow
+func SystemActor(name string) Actor {
+	return Actor{Type: ActorTypeSystem, Name: name}
+}
+
+// TransactionActor returns the user who placed the transaction, with the
+// address the order came from
+func TransactionActor(transaction *Transaction) Actor {
+	actor := Actor{Type: ActorTypeUser, UserID: transaction.UserID}
+	if transaction.UserIP != nil {
+		actor.IPAddress = *transaction.UserIP
+	}
+	if transaction.UserAgent != nil {
+		actor.UserAgent = *transaction.UserAgent
+	}
+	return actor
+}
+
+// Stamp records the actor on the mutation
+func (a Actor) Stamp(mutation *Mutation) {
+	mutation.ActorType = optionalString(a.Type)
+	mutation.ActorName = optionalString(a.Name)
+	mutation.CreatedBy = optionalString(a.UserID)
+	mutation.IPAddress = optionalString(a.IPAddress)
+	mutation.UserAgent = optionalString(a.UserAgent)
+}
+
+type actorKey struct{}
+
+// WithActor attaches the actor of the request to ctx
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor attached to ctx, if any
+func ActorFromContext(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	return actor, ok
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
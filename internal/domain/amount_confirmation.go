@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// AmountConfirmationPolicy defines which orders are large enough to need an
+// explicit confirmation, so a typo cannot buy a 1,000,000 token instead of a
+// 100,000 one
+type AmountConfirmationPolicy struct {
+	// DefaultThreshold applies to users without their own threshold; orders
+	// totalling more need confirming. 0 disables the check for them.
+	DefaultThreshold float64
+	// TokenTTL is how long a confirmation token can be redeemed
+	TokenTTL time.Duration
+	// ExemptChannels are never asked, e.g. H2H where no one can confirm
+	ExemptChannels []string
+}
+
+// Threshold returns the threshold of a user, their own when set
+func (p AmountConfirmationPolicy) Threshold(userThreshold *float64) float64 {
+	if userThreshold != nil {
+		return *userThreshold
+	}
+	return p.DefaultThreshold
+}
+
+// IsExempt reports whether orders of a channel skip the confirmation
+func (p AmountConfirmationPolicy) IsExempt(channel string) bool {
+	for _, exempt := range p.ExemptChannels {
+		if exempt == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// AmountConfirmation is an issued confirmation token. It is redeemed once,
+// for the same product, destination and total amount it was issued for.
+type AmountConfirmation struct {
+	Token             string    `json:"token"`
+	UserID            string    `json:"user_id"`
+	ProductCode       string    `json:"product_code"`
+	DestinationNumber string    `json:"destination_number"`
+	TotalAmount       float64   `json:"total_amount"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+// Matches reports whether the confirmation was issued for the transaction
+func (c *AmountConfirmation) Matches(trx *Transaction) bool {
+	return c.UserID == trx.UserID &&
+		c.ProductCode == trx.ProductCode &&
+		c.DestinationNumber == trx.DestinationNumber &&
+		c.TotalAmount == trx.TotalAmount()
+}
+
+// AmountConfirmationRequiredError reports an order above the user's threshold
+// that was not confirmed. It is lifted by ordering again with Token, or with
+// the transaction PIN when the user has one.
+type AmountConfirmationRequiredError struct {
+	TotalAmount float64
+	Threshold   float64
+	Token       string
+	ExpiresAt   time.Time
+	PINAllowed  bool
+	// TokenRejected is set when the order carried a token that was unknown,
+	// expired, already used or issued for another order
+	TokenRejected bool
+}
+
+func (e *AmountConfirmationRequiredError) Error() string {
+	return fmt.Sprintf("amount %.2f above confirmation threshold %.2f", e.TotalAmount, e.Threshold)
+}
+
+// AmountConfirmationSettings is a user's view of the confirmation rule
+type AmountConfirmationSettings struct {
+	Threshold       float64  `json:"threshold"` // Effective threshold, 0 = never asked
+	Custom          bool     `json:"custom"`    // Set by the user rather than the default
+	PINSet          bool     `json:"pin_set"`   // Orders can be confirmed with the PIN
+	TokenTTLSeconds int      `json:"token_ttl_seconds"`
+	ExemptChannels  []string `json:"exempt_channels,omitempty"`
+}
+
+// AmountConfirmationRepository stores issued confirmation tokens
+type AmountConfirmationRepository interface {
+	Save(confirmation *AmountConfirmation) error
+	// Consume returns and deletes a token, nil when it is unknown or expired
+	Consume(token string) (*AmountConfirmation, error)
+}
+
+// AmountConfirmationUsecase defines the confirmation step of large orders
+type AmountConfirmationUsecase interface {
+	// Check returns an *AmountConfirmationRequiredError when the transaction
+	// needs confirming and ctx carries no valid confirmation
+	Check(ctx context.Context, trx *Transaction) error
+	GetSettings(userID string) (*AmountConfirmationSettings, error)
+	// SetThreshold sets the user's own threshold; nil restores the default
+	SetThreshold(userID string, threshold *float64) (*AmountConfirmationSettings, error)
+}
+
+// OrderConfirmation carries how an order confirms its amount
+type OrderConfirmation struct {
+	Token string
+	PIN   string
+}
+
+type orderConfirmationKey struct{}
+
+// WithOrderConfirmation attaches the confirmation of the orders placed with ctx
+func WithOrderConfirmation(ctx context.Context, confirmation OrderConfirmation) context.Context {
+	if confirmation.Token == "" && confirmation.PIN == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, orderConfirmationKey{}, confirmation)
+}
+
+// OrderConfirmationFromContext returns the confirmation attached to ctx, if any
+func OrderConfirmationFromContext(ctx context.Context) OrderConfirmation {
+	confirmation, _ := ctx.Value(orderConfirmationKey{}).(OrderConfirmation)
+	return confirmation
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// OutcomeStats aggregates transaction outcomes of one supplier and product
+// within a time window
+type OutcomeStats struct {
+	SupplierID      string `json:"supplier_id" db:"supplier_id"`
+	SupplierCode    string `json:"supplier_code" db:"supplier_code"`
+	ProductID       string `json:"product_id" db:"product_id"`
+	ProductCode     string `json:"product_code" db:"product_code"`
+	Total           int    `json:"total" db:"total"`
+	SuccessCount    int    `json:"success_count" db:"success_count"`
+	FailedCount     int    `json:"failed_count" db:"failed_count"`
+	ProcessingCount int    `json:"processing_count" db:"processing_count"` // Sent to the supplier, no final status yet
+}
+
+// Anomaly is an abnormal outcome pattern of a supplier, or of one product at a supplier
+type Anomaly struct {
+	Kind         string    `json:"kind"`
+	Component    string    `json:"component"` // AnomalyComponentSupplier or AnomalyComponentProduct
+	SupplierID   string    `json:"supplier_id"`
+	SupplierCode string    `json:"supplier_code"`
+	ProductID    string    `json:"product_id,omitempty"`
+	ProductCode  string    `json:"product_code,omitempty"`
+	Samples      int       `json:"samples"`
+	Rate         float64   `json:"rate"`      // Percentage that crossed the threshold
+	Threshold    float64   `json:"threshold"` // Percentage
+	WindowStart  time.Time `json:"window_start"`
+	WindowEnd    time.Time `json:"window_end"`
+	DetectedAt   time.Time `json:"detected_at"`
+}
+
+// AnomalyRepository defines data access for transaction anomaly detection
+type AnomalyRepository interface {
+	// GetOutcomeStats aggregates transactions created within [start, end) per
+	// supplier and product, attributed to the final supplier when set
+	GetOutcomeStats(start, end time.Time) ([]*OutcomeStats, error)
+	// LastAlertAt returns when the anomaly with the given key was last
+	// alerted for the supplier, or nil when it never was
+	LastAlertAt(supplierID, key string) (*time.Time, error)
+}
+
+// AnomalyUsecase defines transaction anomaly detection operations
+type AnomalyUsecase interface {
+	// DetectAnomalies evaluates the rolling window, alerts new anomalies and
+	// returns every anomaly currently active
+	DetectAnomalies() ([]*Anomaly, error)
+}
+
+// Anomaly kinds and components
+const (
+	AnomalyFailureRate = "FAILURE_RATE" // Failed share of finished transactions spiked
+	AnomalyAllPending  = "ALL_PENDING"  // Supplier leaves (almost) everything pending
+
+	AnomalyComponentSupplier = "supplier"
+	AnomalyComponentProduct  = "product"
+)
+
+// Key identifies the anomaly for alert deduplication
+func (a *Anomaly) Key() string {
+	if a.ProductID == "" {
+		return fmt.Sprintf("%s:%s:%s", a.Kind, a.Component, a.SupplierID)
+	}
+	return fmt.Sprintf("%s:%s:%s:%s", a.Kind, a.Component, a.SupplierID, a.ProductID)
+}
+
+// AnomalyEventPayload is the payload of anomaly.detected events
+type AnomalyEventPayload struct {
+	Key string `json:"key"`
+	*Anomaly
+}
+
+// NewAnomalyDetectedEvent builds the outbox event alerting an anomaly
+func NewAnomalyDetectedEvent(anomaly *Anomaly) (*DomainEvent, error) {
+	return NewDomainEvent(EventAnomalyDetected, AggregateTypeSupplier, anomaly.SupplierID, &AnomalyEventPayload{
+		Key:     anomaly.Key(),
+		Anomaly: anomaly,
+	})
+}
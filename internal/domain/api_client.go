@@ -1,10 +1,13 @@
 package domain
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"net"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -19,9 +22,63 @@ type APIClient struct {
 	IPWhitelist          []string  `json:"ip_whitelist"`
 	IsActive             bool      `json:"is_active"`
 	MaxRequestsPerMinute int       `json:"max_requests_per_minute"`
+	UserID               *string   `json:"user_id,omitempty"`
+	SyncFailoverAttempts int       `json:"sync_failover_attempts"`
+	SyncFailoverBudgetMs int       `json:"sync_failover_budget_ms"`
+	Sandbox              bool      `json:"sandbox"`
+	QuotaPlanID          *string   `json:"quota_plan_id,omitempty"`
+	Scopes               []string  `json:"scopes"`
 	CreatedAt            time.Time `json:"created_at"`
 	UpdatedAt            time.Time `json:"updated_at"`
 	LastUsedAt           *time.Time `json:"last_used_at,omitempty"`
+
+	// Secret replaced by the last rotation, accepted until PreviousSecretExpiresAt
+	PreviousSecret          string     `json:"-"`
+	PreviousSecretExpiresAt *time.Time `json:"previous_secret_expires_at,omitempty"`
+
+	// Fields of transaction responses the client gets to see
+	ResponseFields ResponseProjection `json:"response_fields"`
+}
+
+// H2H access scopes. A read-only key can query the account and its history;
+// only a key with the transact scope can make payments.
+const (
+	H2HScopeRead     = "read"
+	H2HScopeTransact = "transact"
+)
+
+// DefaultH2HScopes returns the scopes of a client created without any
+func DefaultH2HScopes() []string {
+	return []string{H2HScopeRead, H2HScopeTransact}
+}
+
+// IsValidH2HScope checks whether scope is a known H2H access scope
+func IsValidH2HScope(scope string) bool {
+	return scope == H2HScopeRead || scope == H2HScopeTransact
+}
+
+// NormalizeH2HScopes validates scopes and returns them sorted without duplicates
+func NormalizeH2HScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+
+	seen := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !IsValidH2HScope(scope) {
+			return nil, fmt.Errorf("invalid scope: %s", scope)
+		}
+		seen[scope] = true
+	}
+
+	normalized := make([]string, 0, len(seen))
+	for _, scope := range DefaultH2HScopes() {
+		if seen[scope] {
+			normalized = append(normalized, scope)
+		}
+	}
+	return normalized, nil
 }
 
 // H2HRequestHeaders represents required headers for H2H requests
@@ -33,6 +90,31 @@ type H2HRequestHeaders struct {
 	Nonce     string `json:"nonce,omitempty"`
 }
 
+// SignatureWindow is how far an H2H request timestamp may drift from server time
+const SignatureWindow = 5 * time.Minute
+
+// NonceRepository remembers used H2H signatures so a captured request cannot
+// be replayed while its timestamp is still inside the signature window
+type NonceRepository interface {
+	// Reserve stores the nonce for ttl; it returns false when the nonce was already used
+	Reserve(nonce string, ttl time.Duration) (bool, error)
+}
+
+// SignatureTTL returns how long a request signed with timestamp stays
+// acceptable, which is at most twice the signature window
+func SignatureTTL(timestamp string, now time.Time) time.Duration {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return SignatureWindow
+	}
+
+	ttl := time.Unix(ts, 0).Add(SignatureWindow).Sub(now)
+	if ttl <= 0 {
+		return time.Second
+	}
+	return ttl
+}
+
 // ValidateSignature validates HMAC-SHA256 signature for H2H requests
 func ValidateSignature(secret, timestamp, signature string, payload []byte) error {
 	// Check timestamp validity (prevent replay attacks)
@@ -45,7 +127,7 @@ func ValidateSignature(secret, timestamp, signature string, payload []byte) erro
 	now := time.Now()
 	
 	// Allow 5 minute window for timestamp
-	if now.Sub(requestTime) > 5*time.Minute || requestTime.Sub(now) > 5*time.Minute {
+	if now.Sub(requestTime) > SignatureWindow || requestTime.Sub(now) > SignatureWindow {
 		return fmt.Errorf("timestamp expired or too far in future")
 	}
 
@@ -71,14 +153,27 @@ func GenerateSignature(secret, timestamp string, payload []byte) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
-// IsIPAllowed checks if IP address is in whitelist
+// IsIPAllowed checks if IP address is in whitelist. Entries are single
+// addresses or CIDR ranges.
 func (c *APIClient) IsIPAllowed(ip string) bool {
 	if len(c.IPWhitelist) == 0 {
 		return true // No whitelist restriction
 	}
 
-	for _, allowedIP := range c.IPWhitelist {
-		if strings.TrimSpace(allowedIP) == ip {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return false
+	}
+
+	for _, entry := range c.IPWhitelist {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			if _, network, err := net.ParseCIDR(entry); err == nil && network.Contains(parsed) {
+				return true
+			}
+			continue
+		}
+		if parsed.Equal(net.ParseIP(entry)) {
 			return true
 		}
 	}
@@ -86,8 +181,224 @@ func (c *APIClient) IsIPAllowed(ip string) bool {
 	return false
 }
 
+// MaxIPWhitelistEntries caps the addresses and ranges of one client
+const MaxIPWhitelistEntries = 50
+
+// NormalizeIPWhitelist validates whitelist entries and returns them in
+// canonical form, sorted without duplicates. A CIDR entry is reduced to its
+// network address, e.g. 10.0.0.7/24 becomes 10.0.0.0/24.
+func NormalizeIPWhitelist(entries []string) ([]string, error) {
+	if len(entries) > MaxIPWhitelistEntries {
+		return nil, fmt.Errorf("too many ip whitelist entries, at most %d are allowed", MaxIPWhitelistEntries)
+	}
+
+	seen := make(map[string]bool, len(entries))
+	normalized := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		var canonical string
+		if strings.Contains(entry, "/") {
+			_, network, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ip whitelist entry: %s", entry)
+			}
+			canonical = network.String()
+		} else {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid ip whitelist entry: %s", entry)
+			}
+			canonical = ip.String()
+		}
+		if !seen[canonical] {
+			seen[canonical] = true
+			normalized = append(normalized, canonical)
+		}
+	}
+
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// APIClientIPChange records one change of a client's IP whitelist
+type APIClientIPChange struct {
+	ID        string    `json:"id"`
+	ClientID  string    `json:"client_id"`
+	OldIPs    []string  `json:"old_ips"`
+	NewIPs    []string  `json:"new_ips"`
+	ActorType string    `json:"actor_type"`           // ADMIN or CLIENT
+	ChangedBy *string   `json:"changed_by,omitempty"` // Admin user ID
+	IPAddress string    `json:"ip_address"`           // Address the change was requested from
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ResponseProjection selects the fields of the transaction responses sent to
+// an H2H client, e.g. to hide HPP and profit from a partner. Include is a
+// whitelist; Exclude is removed after it. Both empty sends every field.
+type ResponseProjection struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// IsEmpty reports whether the projection leaves responses untouched
+func (p ResponseProjection) IsEmpty() bool {
+	return len(p.Include) == 0 && len(p.Exclude) == 0
+}
+
+// Apply removes the fields the projection hides from a decoded JSON object
+func (p ResponseProjection) Apply(fields map[string]interface{}) {
+	if len(p.Include) > 0 {
+		keep := make(map[string]bool, len(p.Include))
+		for _, field := range p.Include {
+			keep[field] = true
+		}
+		for field := range fields {
+			if !keep[field] {
+				delete(fields, field)
+			}
+		}
+	}
+	for _, field := range p.Exclude {
+		delete(fields, field)
+	}
+}
+
+// NormalizeResponseProjection validates the field names against known and
+// returns them sorted without duplicates
+func NormalizeResponseProjection(projection ResponseProjection, known []string) (ResponseProjection, error) {
+	valid := make(map[string]bool, len(known))
+	for _, field := range known {
+		valid[field] = true
+	}
+
+	normalize := func(fields []string) ([]string, error) {
+		seen := make(map[string]bool, len(fields))
+		normalized := make([]string, 0, len(fields))
+		for _, field := range fields {
+			field = strings.ToLower(strings.TrimSpace(field))
+			if !valid[field] {
+				return nil, fmt.Errorf("unknown response field: %s", field)
+			}
+			if !seen[field] {
+				seen[field] = true
+				normalized = append(normalized, field)
+			}
+		}
+		sort.Strings(normalized)
+		return normalized, nil
+	}
+
+	include, err := normalize(projection.Include)
+	if err != nil {
+		return ResponseProjection{}, err
+	}
+	exclude, err := normalize(projection.Exclude)
+	if err != nil {
+		return ResponseProjection{}, err
+	}
+	return ResponseProjection{Include: include, Exclude: exclude}, nil
+}
+
+// IP whitelist change actors
+const (
+	IPChangeActorAdmin  = "ADMIN"
+	IPChangeActorClient = "CLIENT"
+)
+
+// HasScope checks whether the client's key grants scope
+func (c *APIClient) HasScope(scope string) bool {
+	for _, granted := range c.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// SyncFailoverPolicy returns the client's inline failover policy, or nil when
+// failed transactions go through the asynchronous retry only
+func (c *APIClient) SyncFailoverPolicy() *SyncFailoverPolicy {
+	if c.SyncFailoverAttempts <= 0 || c.SyncFailoverBudgetMs <= 0 {
+		return nil
+	}
+
+	return &SyncFailoverPolicy{
+		MaxAttempts: c.SyncFailoverAttempts,
+		Budget:      time.Duration(c.SyncFailoverBudgetMs) * time.Millisecond,
+	}
+}
+
+// PreviousSecretActive reports whether the secret replaced by the last
+// rotation is still in its grace period
+func (c *APIClient) PreviousSecretActive(now time.Time) bool {
+	return c.PreviousSecret != "" && c.PreviousSecretExpiresAt != nil && now.Before(*c.PreviousSecretExpiresAt)
+}
+
+// VerifySignature validates a request signature with the client's secret or,
+// during a rotation's grace period, its previous secret. It reports whether
+// the previous secret signed the request.
+func (c *APIClient) VerifySignature(timestamp, signature string, payload []byte, now time.Time) (bool, error) {
+	err := ValidateSignature(c.Secret, timestamp, signature, payload)
+	if err == nil {
+		return false, nil
+	}
+	if err.Error() != "invalid signature" || !c.PreviousSecretActive(now) {
+		return false, err
+	}
+	if ValidateSignature(c.PreviousSecret, timestamp, signature, payload) != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // UpdateLastUsed updates the last used timestamp
 func (c *APIClient) UpdateLastUsed() {
 	now := time.Now()
 	c.LastUsedAt = &now
 }
+
+// APIClientRepository defines the API client operations used by use cases
+type APIClientRepository interface {
+	FindByClientID(ctx context.Context, clientID string) (*APIClient, error)
+	// RotateSecret replaces the client's secret, keeping the current one valid
+	// until graceUntil
+	RotateSecret(ctx context.Context, clientID, secret string, graceUntil time.Time) error
+	// UpdateIPWhitelist replaces the client's whitelist with change.NewIPs
+	// and records the change, filling its ID, OldIPs and CreatedAt
+	UpdateIPWhitelist(ctx context.Context, change *APIClientIPChange) error
+	// ListIPChanges lists the whitelist changes of a client, newest first
+	ListIPChanges(ctx context.Context, clientID string, limit int) ([]*APIClientIPChange, error)
+}
+
+// SecretRotation is returned once when a client rotates its secret
+type SecretRotation struct {
+	ClientID                string    `json:"client_id"`
+	Secret                  string    `json:"secret"`
+	PreviousSecretExpiresAt time.Time `json:"previous_secret_expires_at"`
+}
+
+// APIClientUsage summarizes the H2H transactions of an API client over a period
+type APIClientUsage struct {
+	ClientID   string     `json:"client_id"`
+	From       time.Time  `json:"from"`
+	To         time.Time  `json:"to"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ChannelUsage
+}
+
+// APIClientPortalUsecase serves the self-service endpoints of an
+// authenticated H2H client
+type APIClientPortalUsecase interface {
+	GetUsage(client *APIClient, period DateRange) (*APIClientUsage, error)
+	// GetQuota reports the remaining quota without counting a request;
+	// requests are reported for the given endpoint
+	GetQuota(client *APIClient, endpoint string) (*QuotaStatus, error)
+	ListTransactions(client *APIClient, period DateRange, tags TransactionTags, cursor string, limit int) ([]*Transaction, string, error)
+	// ListDeliveries returns the latest notifications sent to the client's account
+	ListDeliveries(client *APIClient, limit int) ([]*Outbox, error)
+	RotateSecret(client *APIClient) (*SecretRotation, error)
+	// UpdateIPWhitelist replaces the client's whitelist from ipAddress, which
+	// must stay allowed so the client cannot lock itself out
+	UpdateIPWhitelist(client *APIClient, ips []string, ipAddress string) (*APIClientIPChange, error)
+	ListIPChanges(client *APIClient, limit int) ([]*APIClientIPChange, error)
+}
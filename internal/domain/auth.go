@@ -19,6 +19,15 @@ type AuthClaims struct {
 	Role      string
 	IssuedAt  time.Time
 	ExpiresAt time.Time
+	// ImpersonatorID is the admin acting as UserID, set on impersonation
+	// tokens only, together with their ImpersonationSessionID
+	ImpersonatorID         string
+	ImpersonationSessionID string
+}
+
+// IsImpersonation reports whether the token was issued to an admin acting as the user
+func (c *AuthClaims) IsImpersonation() bool {
+	return c.ImpersonatorID != ""
 }
 
 // MapRoleToLevel converts role string to user level constant
@@ -38,7 +47,14 @@ func MapRoleToLevel(role string) int {
 // AuthService defines authentication helpers for JWT and H2H signature validation
 type AuthService interface {
 	GenerateAccessToken(user *User) (string, error)
+	// GenerateImpersonationToken issues a token acting as user for the
+	// session's admin, valid until the session expires
+	GenerateImpersonationToken(user *User, session *ImpersonationSession) (string, error)
+	// RevokeImpersonation rejects the token of an impersonation session
+	RevokeImpersonation(session *ImpersonationSession) error
 	ValidateToken(token string) (*AuthClaims, error)
+	// RevokeUserTokens invalidates every token issued to the user so far
+	RevokeUserTokens(userID string) error
 	ValidateH2HSignature(apiKey, signature, timestamp string, payload []byte) error
 }
 
@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// Transaction intake modes, switched by the queue backlog
+const (
+	IntakeNormal   = "NORMAL"
+	IntakeDegraded = "DEGRADED" // Orders are accepted with a longer ETA
+	IntakeShedding = "SHEDDING" // Low priority H2H orders are rejected as well
+)
+
+// IntakeState is the last sampled queue backlog and the intake mode it put
+// transaction creation in
+type IntakeState struct {
+	Mode    string `json:"mode"`
+	Backlog int64  `json:"backlog"`
+	// EstimatedWait is how long a newly queued order is expected to wait for
+	// a worker, derived from the backlog and the configured drain rate
+	EstimatedWait time.Duration `json:"-"`
+	// RetryAfter is when a shed client should try again: the time the
+	// backlog needs to drain under the shedding threshold
+	RetryAfter time.Duration `json:"-"`
+	SampledAt  time.Time     `json:"sampled_at"`
+}
+
+// BackpressureUsecase watches the transaction queue backlog and decides how
+// new orders are taken in
+type BackpressureUsecase interface {
+	// State returns the last sampled state without touching the queue
+	State() *IntakeState
+	// Refresh samples the backlog and switches the mode when a threshold is
+	// crossed. A failed sample keeps the previous state.
+	Refresh() (*IntakeState, error)
+	// IsPriorityClient reports whether an H2H client is never shed
+	IsPriorityClient(client *APIClient) bool
+}
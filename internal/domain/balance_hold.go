@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+// BalanceHold reserves part of a user's balance for an in-flight transaction.
+// The hold is captured (turned into a debit) on success and released otherwise.
+type BalanceHold struct {
+	ID            string     `json:"id" db:"id"`
+	UserID        string     `json:"user_id" db:"user_id"`
+	TransactionID string     `json:"transaction_id" db:"transaction_id"`
+	Amount        float64    `json:"amount" db:"amount"`
+	Status        string     `json:"status" db:"status"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+	SettledAt     *time.Time `json:"settled_at" db:"settled_at"`
+}
+
+// BalanceHoldRepository defines operations for balance hold data access. Hold,
+// Capture and Release keep users.held_balance in sync and are meant to run
+// inside a unit of work.
+type BalanceHoldRepository interface {
+	// Hold reserves amount when the available balance covers it, otherwise
+	// it fails with "insufficient balance"
+	Hold(hold *BalanceHold) error
+	GetByTransactionID(transactionID string) (*BalanceHold, error)
+	// Capture settles an active hold and deducts it from the balance,
+	// returning the hold and the balance after deduction
+	Capture(transactionID string) (*BalanceHold, float64, error)
+	// Release settles an active hold without touching the balance
+	Release(transactionID string) (*BalanceHold, error)
+}
+
+// Balance hold statuses
+const (
+	HoldStatusHeld     = "HELD"
+	HoldStatusCaptured = "CAPTURED"
+	HoldStatusReleased = "RELEASED"
+)
+
+// IsActive reports whether the hold still reserves balance
+func (h *BalanceHold) IsActive() bool {
+	return h.Status == HoldStatusHeld
+}
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ProductCategory groups products on storefront menus (PULSA, DATA, PLN, ...).
+// Products reference it by code.
+type ProductCategory struct {
+	Code         string  `json:"code" db:"code"`
+	Name         string  `json:"name" db:"name"`
+	IconURL      *string `json:"icon_url" db:"icon_url"`
+	DisplayOrder int     `json:"display_order" db:"display_order"` // Ascending on menus
+	IsActive     bool    `json:"is_active" db:"is_active"`         // Inactive entries are hidden from public menus
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ProductProvider is the operator or brand behind products (TELKOMSEL, PLN,
+// MOBILE LEGENDS, ...). Products reference it by code.
+type ProductProvider struct {
+	Code         string  `json:"code" db:"code"`
+	Name         string  `json:"name" db:"name"`
+	IconURL      *string `json:"icon_url" db:"icon_url"`
+	DisplayOrder int     `json:"display_order" db:"display_order"`
+	IsActive     bool    `json:"is_active" db:"is_active"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CatalogEntryUpdate holds the fields of a category or provider to change;
+// nil fields keep their current value and an empty IconURL clears the icon
+type CatalogEntryUpdate struct {
+	Name         *string
+	IconURL      *string
+	DisplayOrder *int
+	IsActive     *bool
+}
+
+// ProductCategoryRepository defines operations for product category data access
+type ProductCategoryRepository interface {
+	Create(category *ProductCategory) error
+	GetByCode(code string) (*ProductCategory, error)
+	Update(category *ProductCategory) error
+	// List lists categories in display order, optionally only active ones
+	List(activeOnly bool) ([]*ProductCategory, error)
+}
+
+// ProductProviderRepository defines operations for product provider data access
+type ProductProviderRepository interface {
+	Create(provider *ProductProvider) error
+	GetByCode(code string) (*ProductProvider, error)
+	Update(provider *ProductProvider) error
+	// List lists providers in display order, optionally only active ones.
+	// A category ("" for all) keeps the providers with an active product in it.
+	List(activeOnly bool, category string) ([]*ProductProvider, error)
+}
+
+// CatalogUsecase manages the product categories and providers and validates
+// the codes other use cases receive
+type CatalogUsecase interface {
+	CreateCategory(category *ProductCategory) error
+	UpdateCategory(code string, updates *CatalogEntryUpdate) (*ProductCategory, error)
+	GetCategory(code string) (*ProductCategory, error)
+	ListCategories(activeOnly bool) ([]*ProductCategory, error)
+
+	CreateProvider(provider *ProductProvider) error
+	UpdateProvider(code string, updates *CatalogEntryUpdate) (*ProductProvider, error)
+	GetProvider(code string) (*ProductProvider, error)
+	ListProviders(activeOnly bool, category string) ([]*ProductProvider, error)
+
+	// ValidateCategory and ValidateProvider check that a code is registered,
+	// active or not, and return "invalid product category" or "invalid
+	// product provider" otherwise
+	ValidateCategory(code string) error
+	ValidateProvider(code string) error
+}
+
+// Codes follow supplier brand names, which may contain spaces and dots
+// (MOBILE LEGENDS, BY.U)
+var catalogCodePattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9 ._&-]{0,49}$`)
+
+// NormalizeCatalogCode upper-cases a category or provider code and collapses
+// its whitespace
+func NormalizeCatalogCode(code string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(code), " "))
+}
+
+// checkCatalogEntry validates the fields shared by categories and providers
+func checkCatalogEntry(code, name string, iconURL *string, displayOrder int) error {
+	if !catalogCodePattern.MatchString(code) {
+		return fmt.Errorf("code must be 1-50 upper case letters, digits, spaces or . _ & -")
+	}
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if iconURL != nil && len(*iconURL) > 500 {
+		return fmt.Errorf("icon_url must not exceed 500 characters")
+	}
+	if displayOrder < 0 {
+		return fmt.Errorf("display_order must not be negative")
+	}
+	return nil
+}
+
+// Check validates the category definition
+func (c *ProductCategory) Check() error {
+	return checkCatalogEntry(c.Code, c.Name, c.IconURL, c.DisplayOrder)
+}
+
+// Check validates the provider definition
+func (p *ProductProvider) Check() error {
+	return checkCatalogEntry(p.Code, p.Name, p.IconURL, p.DisplayOrder)
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// CommissionReversal audits the reversal of one commission paid for a
+// transaction that was refunded afterwards. When the upline's available
+// balance cannot cover the commission, only that much is taken back and the
+// rest is recorded as shortfall for manual collection.
+type CommissionReversal struct {
+	ID                   string    `json:"id" db:"id"`
+	TransactionID        string    `json:"transaction_id" db:"transaction_id"`
+	UserID               string    `json:"user_id" db:"user_id"`                               // Upline who received the commission
+	CommissionMutationID string    `json:"commission_mutation_id" db:"commission_mutation_id"` // Mutation that paid the commission
+	ReversalMutationID   *string   `json:"reversal_mutation_id" db:"reversal_mutation_id"`     // Nil when nothing could be taken back
+	CommissionAmount     float64   `json:"commission_amount" db:"commission_amount"`
+	ReversedAmount       float64   `json:"reversed_amount" db:"reversed_amount"`
+	Shortfall            float64   `json:"shortfall" db:"shortfall"`
+	CreatedAt            time.Time `json:"created_at" db:"created_at"`
+}
+
+// CommissionReversalRepository defines operations for commission reversal data access
+type CommissionReversalRepository interface {
+	Create(reversal *CommissionReversal) error
+	ListByTransactionID(transactionID string) ([]*CommissionReversal, error)
+}
+
+// ReferenceTypeCommissionReversal marks mutations that take back a commission;
+// the reference ID is the refunded transaction
+const ReferenceTypeCommissionReversal = "COMMISSION_REVERSAL"
@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// CutoffSchedule is a period in which a product category or supplier cannot
+// process transactions: a biller's nightly cutoff (DAILY) or an entry of the
+// ops calendar such as a holiday or planned maintenance (ONCE).
+//
+// Daily windows use StartTime and EndTime in the ops timezone; an end before
+// the start wraps past midnight. One-off windows use StartsAt and EndsAt.
+type CutoffSchedule struct {
+	ID         string     `json:"id" db:"id"`
+	ScopeType  string     `json:"scope_type" db:"scope_type"`   // CATEGORY or SUPPLIER
+	ScopeValue string     `json:"scope_value" db:"scope_value"` // Category code or supplier ID
+	Kind       string     `json:"kind" db:"kind"`               // DAILY or ONCE
+	StartTime  *string    `json:"start_time" db:"start_time"`   // HH:MM, DAILY only
+	EndTime    *string    `json:"end_time" db:"end_time"`       // HH:MM, DAILY only
+	StartsAt   *time.Time `json:"starts_at" db:"starts_at"`     // ONCE only
+	EndsAt     *time.Time `json:"ends_at" db:"ends_at"`         // ONCE only
+	Action     string     `json:"action" db:"action"`           // QUEUE or BLOCK
+	Reason     *string    `json:"reason" db:"reason"`
+	IsActive   bool       `json:"is_active" db:"is_active"`
+	CreatedBy  *string    `json:"created_by" db:"created_by"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CutoffScheduleUpdate holds the fields of a schedule to change; nil fields
+// keep their current value
+type CutoffScheduleUpdate struct {
+	Kind      *string
+	StartTime *string
+	EndTime   *string
+	StartsAt  *time.Time
+	EndsAt    *time.Time
+	Action    *string
+	Reason    *string
+	IsActive  *bool
+}
+
+// ActiveCutoff is a cutoff in force at some moment and when it ends
+type ActiveCutoff struct {
+	Schedule *CutoffSchedule
+	Until    time.Time
+}
+
+// CutoffError reports an order that cannot be processed before a cutoff ends
+type CutoffError struct {
+	Until  time.Time
+	Reason string
+}
+
+func (e *CutoffError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("product is in cutoff until %s", e.Until.Format("2006-01-02 15:04"))
+	}
+	return fmt.Sprintf("product is in cutoff until %s: %s", e.Until.Format("2006-01-02 15:04"), e.Reason)
+}
+
+// CutoffScheduleRepository defines operations for cutoff schedule data access
+type CutoffScheduleRepository interface {
+	Create(schedule *CutoffSchedule) error
+	GetByID(id string) (*CutoffSchedule, error)
+	Update(schedule *CutoffSchedule) error
+	Delete(id string) error
+	List(scopeType, scopeValue string) ([]*CutoffSchedule, error)
+	// ListActive returns every active schedule; one-off windows that already
+	// ended are left out
+	ListActive() ([]*CutoffSchedule, error)
+}
+
+// CutoffUsecase defines business logic operations for cutoff schedules
+type CutoffUsecase interface {
+	CreateSchedule(schedule *CutoffSchedule) error
+	UpdateSchedule(id string, updates *CutoffScheduleUpdate) (*CutoffSchedule, error)
+	DeleteSchedule(id string) error
+	GetSchedule(id string) (*CutoffSchedule, error)
+	ListSchedules(scopeType, scopeValue string) ([]*CutoffSchedule, error)
+	// CategoryCutoff returns the cutoff of a category in force at now, or nil.
+	// A BLOCK cutoff wins over a QUEUE one.
+	CategoryCutoff(category string, now time.Time) (*ActiveCutoff, error)
+	// SupplierCutoffs returns the cutoffs in force at now of the given
+	// suppliers, keyed by supplier ID
+	SupplierCutoffs(supplierIDs []string, now time.Time) (map[string]*ActiveCutoff, error)
+}
+
+// Cutoff schedule constants
+const (
+	CutoffScopeCategory = "CATEGORY"
+	CutoffScopeSupplier = "SUPPLIER"
+
+	CutoffKindDaily = "DAILY"
+	CutoffKindOnce  = "ONCE"
+
+	CutoffActionQueue = "QUEUE" // Accept orders and hold them until the window opens
+	CutoffActionBlock = "BLOCK" // Reject new orders during the cutoff
+)
+
+// IsValidCutoffScope checks if the cutoff scope type is valid
+func IsValidCutoffScope(scopeType string) bool {
+	return scopeType == CutoffScopeCategory || scopeType == CutoffScopeSupplier
+}
+
+// IsValidCutoffAction checks if the cutoff action is valid
+func IsValidCutoffAction(action string) bool {
+	return action == CutoffActionQueue || action == CutoffActionBlock
+}
+
+// Check validates the window definition of the schedule
+func (s *CutoffSchedule) Check() error {
+	switch s.Kind {
+	case CutoffKindDaily:
+		if s.StartTime == nil || s.EndTime == nil {
+			return fmt.Errorf("start_time and end_time are required for daily cutoffs")
+		}
+		start, err := parseClock(*s.StartTime)
+		if err != nil {
+			return fmt.Errorf("invalid start_time: %w", err)
+		}
+		end, err := parseClock(*s.EndTime)
+		if err != nil {
+			return fmt.Errorf("invalid end_time: %w", err)
+		}
+		if start == end {
+			return fmt.Errorf("start_time and end_time must differ")
+		}
+	case CutoffKindOnce:
+		if s.StartsAt == nil || s.EndsAt == nil {
+			return fmt.Errorf("starts_at and ends_at are required for one-off cutoffs")
+		}
+		if !s.EndsAt.After(*s.StartsAt) {
+			return fmt.Errorf("ends_at must be after starts_at")
+		}
+	default:
+		return fmt.Errorf("invalid cutoff kind")
+	}
+	return nil
+}
+
+// ActiveAt reports whether the window covers t and, if so, when it ends.
+// Daily windows are evaluated in loc.
+func (s *CutoffSchedule) ActiveAt(t time.Time, loc *time.Location) (time.Time, bool) {
+	if !s.IsActive {
+		return time.Time{}, false
+	}
+
+	switch s.Kind {
+	case CutoffKindOnce:
+		if s.StartsAt == nil || s.EndsAt == nil || t.Before(*s.StartsAt) || !t.Before(*s.EndsAt) {
+			return time.Time{}, false
+		}
+		return *s.EndsAt, true
+	case CutoffKindDaily:
+		if s.StartTime == nil || s.EndTime == nil {
+			return time.Time{}, false
+		}
+		start, errStart := parseClock(*s.StartTime)
+		end, errEnd := parseClock(*s.EndTime)
+		if errStart != nil || errEnd != nil || start == end {
+			return time.Time{}, false
+		}
+
+		local := t.In(loc)
+		midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+		now := local.Sub(midnight)
+		switch {
+		case start < end && now >= start && now < end:
+			return midnight.Add(end), true
+		case start > end && now >= start:
+			// Window started today and ends tomorrow
+			return time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, loc).Add(end), true
+		case start > end && now < end:
+			// Window started yesterday and ends today
+			return midnight.Add(end), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// parseClock parses an HH:MM time of day into the offset from midnight
+func parseClock(value string) (time.Duration, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("expected HH:MM")
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
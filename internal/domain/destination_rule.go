@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DestinationRule describes the destination accepted by products of a
+// category: a phone number for pulsa, a meter ID for PLN, a player ID for
+// games, and so on
+type DestinationRule struct {
+	Category  string `json:"category" db:"category"`
+	Pattern   string `json:"pattern" db:"pattern"`       // Regular expression, empty = any
+	MinLength int    `json:"min_length" db:"min_length"` // 0 = no minimum
+	MaxLength int    `json:"max_length" db:"max_length"` // 0 = unlimited
+	Hint      string `json:"hint" db:"hint"`             // Expected format shown to users
+	// NormalizePhone stores phone numbers in the international form of the
+	// product's numbering plan (62xxx for Indonesia)
+	NormalizePhone bool `json:"normalize_phone" db:"normalize_phone"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DestinationError reports a destination that does not match its rule
+type DestinationError struct {
+	Hint string
+}
+
+func (e *DestinationError) Error() string {
+	if e.Hint == "" {
+		return "invalid destination number"
+	}
+	return "invalid destination number: " + e.Hint
+}
+
+// DestinationRuleRepository defines operations for destination rule data access
+type DestinationRuleRepository interface {
+	GetByCategory(category string) (*DestinationRule, error)
+	List() ([]*DestinationRule, error)
+	Upsert(rule *DestinationRule) error
+}
+
+// DestinationRuleUsecase defines business logic operations for destination rules
+type DestinationRuleUsecase interface {
+	ListRules() ([]*DestinationRule, error)
+	UpdateRule(rule *DestinationRule) error
+	// ValidateDestination checks a destination against the product override
+	// and its category rule and returns the normalized destination
+	ValidateDestination(product *Product, destination string) (string, error)
+}
+
+// NormalizeDestination strips the whitespace users paste in with numbers
+func NormalizeDestination(destination string) string {
+	return strings.Join(strings.Fields(destination), "")
+}
+
+// Check validates the rule definition itself
+func (r *DestinationRule) Check() error {
+	if r.MinLength < 0 || r.MaxLength < 0 {
+		return fmt.Errorf("destination length cannot be negative")
+	}
+	if r.MaxLength > 0 && r.MinLength > r.MaxLength {
+		return fmt.Errorf("destination min length exceeds max length")
+	}
+	if r.Pattern != "" {
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("invalid destination pattern: %w", err)
+		}
+	}
+	return nil
+}
+
+// Validate checks a normalized destination against the rule
+func (r *DestinationRule) Validate(destination string) error {
+	length := utf8.RuneCountInString(destination)
+	if length == 0 || length < r.MinLength || (r.MaxLength > 0 && length > r.MaxLength) {
+		return &DestinationError{Hint: r.Hint}
+	}
+	if r.Pattern != "" {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil || !re.MatchString(destination) {
+			return &DestinationError{Hint: r.Hint}
+		}
+	}
+	return nil
+}
+
+// ApplyDestinationOverride returns the rule with the product's own
+// destination settings layered over the category rule, which may be nil
+func (p *Product) ApplyDestinationOverride(rule *DestinationRule) *DestinationRule {
+	merged := DestinationRule{Category: p.Category}
+	if rule != nil {
+		merged = *rule
+	}
+	if p.DestinationPattern != nil {
+		merged.Pattern = *p.DestinationPattern
+	}
+	if p.DestinationMinLength != nil {
+		merged.MinLength = *p.DestinationMinLength
+	}
+	if p.DestinationMaxLength != nil {
+		merged.MaxLength = *p.DestinationMaxLength
+	}
+	if p.DestinationHint != nil {
+		merged.Hint = *p.DestinationHint
+	}
+	return &merged
+}
+
+// HasDestinationOverride reports whether the product customizes its destination rule
+func (p *Product) HasDestinationOverride() bool {
+	return p.DestinationPattern != nil || p.DestinationMinLength != nil ||
+		p.DestinationMaxLength != nil || p.DestinationHint != nil
+}
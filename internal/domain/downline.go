@@ -0,0 +1,65 @@
+package domain
+
+import "time"
+
+// DownlineNode is one user of a downline tree. Trees are returned flat in
+// breadth-first order; UplineID links each node to its parent.
+type DownlineNode struct {
+	ID              string    `json:"id" db:"id"`
+	Username        string    `json:"username" db:"username"`
+	FullName        *string   `json:"full_name" db:"full_name"`
+	Level           int       `json:"level" db:"level"`
+	IsActive        bool      `json:"is_active" db:"is_active"`
+	UplineID        string    `json:"upline_id" db:"upline_id"`
+	Depth           int       `json:"depth" db:"depth"` // 1 for direct downlines
+	DirectDownlines int       `json:"direct_downlines" db:"direct_downlines"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// DownlineLevelStats counts the downlines of one user level and the
+// successful transactions they made in the stats period
+type DownlineLevelStats struct {
+	Level        int     `json:"level" db:"level"`
+	Role         string  `json:"role" db:"-"`
+	Users        int     `json:"users" db:"users"`
+	ActiveUsers  int     `json:"active_users" db:"active_users"`
+	Transactions int     `json:"transactions" db:"transactions"`
+	Volume       float64 `json:"volume" db:"volume"` // Selling price plus admin fee
+}
+
+// DownlineStats summarises a user's downline network over [From, To)
+type DownlineStats struct {
+	UserID          string                `json:"user_id"`
+	MaxDepth        int                   `json:"max_depth"`
+	From            time.Time             `json:"from"`
+	To              time.Time             `json:"to"`
+	TotalDownlines  int                   `json:"total_downlines"`
+	ActiveDownlines int                   `json:"active_downlines"`
+	Transactions    int                   `json:"transactions"`
+	Volume          float64               `json:"volume"`
+	Commissions     float64               `json:"commissions"` // Earned by the user, net of reversals
+	ByLevel         []*DownlineLevelStats `json:"by_level"`
+}
+
+// DownlineRepository defines recursive queries over the user hierarchy. maxDepth
+// bounds every walk, which also keeps a corrupted (cyclic) hierarchy finite.
+type DownlineRepository interface {
+	// ListTree returns one page of the downlines of rootID up to maxDepth
+	// levels deep, with the total number of such downlines
+	ListTree(rootID string, maxDepth, limit, offset int) ([]*DownlineNode, int, error)
+	// IsDownline reports whether userID sits under uplineID within maxDepth levels
+	IsDownline(uplineID, userID string, maxDepth int) (bool, error)
+	GetLevelStats(rootID string, maxDepth int, from, to time.Time) ([]*DownlineLevelStats, error)
+	// GetCommissionTotal returns the commissions credited to a user minus the
+	// ones reversed in [from, to)
+	GetCommissionTotal(userID string, from, to time.Time) (float64, error)
+}
+
+// DownlineUsecase serves the downline tree and statistics. Admins may query
+// any user; other users their own network or a user within it.
+type DownlineUsecase interface {
+	GetTree(actorID string, actorLevel int, userID string, depth, page, limit int) ([]*DownlineNode, int, error)
+	// GetStats aggregates the downlines of userID over the calendar dates from
+	// and to (inclusive); zero dates default to the current month
+	GetStats(actorID string, actorLevel int, userID string, depth int, from, to time.Time) (*DownlineStats, error)
+}
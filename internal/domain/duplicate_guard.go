@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Duplicate guard modes
+const (
+	DuplicateGuardOff     = "OFF"
+	DuplicateGuardConfirm = "CONFIRM" // Rejected unless the order confirms it is intended
+	DuplicateGuardReject  = "REJECT"  // Always rejected
+)
+
+// DuplicateGuardPolicy defines when an order repeats a recent one: the same
+// user, product code and destination number as a transaction that is still
+// in progress or succeeded within Window
+type DuplicateGuardPolicy struct {
+	Mode   string
+	Window time.Duration
+}
+
+// Enabled reports whether duplicate orders are checked
+func (p DuplicateGuardPolicy) Enabled() bool {
+	return p.Mode == DuplicateGuardConfirm || p.Mode == DuplicateGuardReject
+}
+
+// IsValidDuplicateGuardMode checks if the duplicate guard mode is valid
+func IsValidDuplicateGuardMode(mode string) bool {
+	return mode == DuplicateGuardOff || mode == DuplicateGuardConfirm || mode == DuplicateGuardReject
+}
+
+// DuplicateTransactionError reports an order repeating Existing. Confirmable
+// errors are lifted by ordering again with the duplicate override.
+type DuplicateTransactionError struct {
+	Existing    *Transaction
+	Confirmable bool
+}
+
+func (e *DuplicateTransactionError) Error() string {
+	return fmt.Sprintf("duplicate of transaction %s (%s)", e.Existing.TrxCode, e.Existing.Status)
+}
+
+type duplicateOverrideKey struct{}
+
+// WithDuplicateOverride marks the orders placed with ctx as intended
+// repeats, lifting the duplicate guard in CONFIRM mode
+func WithDuplicateOverride(ctx context.Context) context.Context {
+	return context.WithValue(ctx, duplicateOverrideKey{}, true)
+}
+
+// HasDuplicateOverride reports whether ctx carries the duplicate override
+func HasDuplicateOverride(ctx context.Context) bool {
+	override, _ := ctx.Value(duplicateOverrideKey{}).(bool)
+	return override
+}
@@ -0,0 +1,248 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DomainEvent represents an event stored in the transactional outbox
+type DomainEvent struct {
+	ID            string     `json:"id" db:"id"`
+	EventType     string     `json:"event_type" db:"event_type"`
+	AggregateType string     `json:"aggregate_type" db:"aggregate_type"`
+	AggregateID   string     `json:"aggregate_id" db:"aggregate_id"`
+	Payload       string     `json:"payload" db:"payload"` // JSON encoded payload
+	SchemaVersion int        `json:"schema_version" db:"schema_version"`
+	Status        string     `json:"status" db:"status"`
+	Attempts      int        `json:"attempts" db:"attempts"`
+	LastError     *string    `json:"last_error" db:"last_error"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" db:"next_attempt_at"`
+	PublishedAt   *time.Time `json:"published_at" db:"published_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// EventRepository defines operations for outbox event data access
+type EventRepository interface {
+	Create(event *DomainEvent) error
+	ClaimPending(limit int, lease time.Duration) ([]*DomainEvent, error)
+	MarkPublished(id string) error
+	ScheduleRetry(id, lastError string, nextAttemptAt time.Time) error
+	MarkFailed(id, lastError string) error
+	GetByID(id string) (*DomainEvent, error)
+	// ListFailed lists the events that exhausted their publish attempts
+	// (the dead letter queue), oldest first
+	ListFailed(limit, offset int) ([]*DomainEvent, error)
+	// CountByStatus counts the events in a status, e.g. the pending backlog
+	// or the dead letter queue size
+	CountByStatus(status string) (int, error)
+	// Requeue puts a failed event back in the outbox with fresh attempts
+	Requeue(id string) error
+}
+
+// EventPublisher publishes outbox events to an external destination (webhook, queue, ...)
+type EventPublisher interface {
+	Name() string
+	Publish(ctx context.Context, event *DomainEvent) error
+}
+
+// WebhookDispatcher posts events to webhook endpoints chosen per call, each
+// request signed with the endpoint's secret
+type WebhookDispatcher interface {
+	Dispatch(ctx context.Context, url, secret string, event *DomainEvent) error
+}
+
+// EventHook is a plugin reacting to domain events, e.g. posting large
+// transactions to a chat channel. Hooks are registered at startup and called
+// by the outbox relay once the events are committed.
+type EventHook interface {
+	Name() string
+	// Events lists the event types the hook subscribes to
+	Events() []string
+	Handle(ctx context.Context, event *DomainEvent) error
+}
+
+// EventRelayUsecase defines operations for relaying outbox events to publishers
+type EventRelayUsecase interface {
+	RelayPendingEvents(ctx context.Context) (int, error)
+}
+
+// TxRepositories exposes repositories bound to a single database transaction
+type TxRepositories interface {
+	Users() UserRepository
+	Transactions() TransactionRepository
+	Mutations() MutationRepository
+	Events() EventRepository
+	BalanceHolds() BalanceHoldRepository
+	Timeline() TransactionTimelineRepository
+	Transfers() BalanceTransferRepository
+	CommissionReversals() CommissionReversalRepository
+	Referrals() ReferralRepository
+	Promotions() PromotionRepository
+	VoucherCodes() VoucherCodeRepository
+}
+
+// UnitOfWork runs a function inside a database transaction. The transaction is
+// committed when fn returns nil and rolled back otherwise.
+type UnitOfWork interface {
+	Do(fn func(repos TxRepositories) error) error
+}
+
+// Event type constants
+const (
+	EventTransactionCreated   = "transaction.created"
+	EventTransactionCompleted = "transaction.completed"
+	EventBalanceMutated       = "balance.mutated"
+	EventAnomalyDetected      = "anomaly.detected"
+	EventProductPriceChanged  = "product.price_changed"
+	EventRefundStuck          = "refund.stuck"
+	EventRefundIssued         = "refund.issued"
+
+	AggregateTypeTransaction = "TRANSACTION"
+	AggregateTypeUser        = "USER"
+	AggregateTypeSupplier    = "SUPPLIER"
+	AggregateTypeProduct     = "PRODUCT"
+
+	EventStatusPending   = "PENDING"
+	EventStatusPublished = "PUBLISHED"
+	EventStatusFailed    = "FAILED"
+
+	// EventSchemaVersion is bumped whenever an event payload changes incompatibly
+	EventSchemaVersion = 1
+)
+
+// NewDomainEvent builds a pending outbox event with a JSON encoded payload
+func NewDomainEvent(eventType, aggregateType, aggregateID string, payload interface{}) (*DomainEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event payload: %w", err)
+	}
+
+	now := time.Now()
+	return &DomainEvent{
+		ID:            uuid.New().String(),
+		EventType:     eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       string(data),
+		SchemaVersion: EventSchemaVersion,
+		Status:        EventStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}, nil
+}
+
+// IsValidEventType checks if the event type is valid
+func IsValidEventType(eventType string) bool {
+	return eventType == EventTransactionCreated ||
+		eventType == EventTransactionCompleted ||
+		eventType == EventBalanceMutated ||
+		eventType == EventAnomalyDetected ||
+		eventType == EventProductPriceChanged ||
+		eventType == EventRefundStuck ||
+		eventType == EventRefundIssued ||
+		eventType == EventSupplierUnhealthy ||
+		eventType == EventVoucherStockLow
+}
+
+// EventEnvelope is the wire format used when publishing events externally
+type EventEnvelope struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	SchemaVersion int             `json:"schema_version"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// Envelope converts the outbox event into its wire format
+func (e *DomainEvent) Envelope() *EventEnvelope {
+	data := json.RawMessage(e.Payload)
+	if len(data) == 0 {
+		data = json.RawMessage("{}")
+	}
+	return &EventEnvelope{
+		ID:            e.ID,
+		Type:          e.EventType,
+		AggregateType: e.AggregateType,
+		AggregateID:   e.AggregateID,
+		SchemaVersion: e.SchemaVersion,
+		OccurredAt:    e.CreatedAt,
+		Data:          data,
+	}
+}
+
+// TransactionEventPayload is the payload of transaction lifecycle events
+type TransactionEventPayload struct {
+	TransactionID     string  `json:"transaction_id"`
+	TrxCode           string  `json:"trx_code"`
+	UserID            string  `json:"user_id"`
+	ProductID         string  `json:"product_id"`
+	ProductCode       string  `json:"product_code"`
+	DestinationNumber string  `json:"destination_number"`
+	SupplierID        *string `json:"supplier_id,omitempty"`
+	SellingPrice      float64 `json:"selling_price"`
+	AdminFee          float64 `json:"admin_fee"`
+	TotalAmount       float64 `json:"total_amount"`
+	Status            string  `json:"status"`
+	SerialNumber      *string `json:"serial_number,omitempty"`
+	Message           *string `json:"message,omitempty"`
+}
+
+// BalanceEventPayload is the payload of balance mutation events
+type BalanceEventPayload struct {
+	MutationID    string  `json:"mutation_id"`
+	UserID        string  `json:"user_id"`
+	Type          string  `json:"type"`
+	Amount        float64 `json:"amount"`
+	BalanceBefore float64 `json:"balance_before"`
+	BalanceAfter  float64 `json:"balance_after"`
+	ReferenceType *string `json:"reference_type,omitempty"`
+	ReferenceID   *string `json:"reference_id,omitempty"`
+	Description   string  `json:"description"`
+}
+
+// NewTransactionEvent builds a transaction lifecycle event
+func NewTransactionEvent(eventType string, trx *Transaction) (*DomainEvent, error) {
+	supplierID := trx.FinalSupplierID
+	if supplierID == nil {
+		supplierID = trx.SupplierID
+	}
+
+	return NewDomainEvent(eventType, AggregateTypeTransaction, trx.ID, &TransactionEventPayload{
+		TransactionID:     trx.ID,
+		TrxCode:           trx.TrxCode,
+		UserID:            trx.UserID,
+		ProductID:         trx.ProductID,
+		ProductCode:       trx.ProductCode,
+		DestinationNumber: trx.DestinationNumber,
+		SupplierID:        supplierID,
+		SellingPrice:      trx.SellingPrice,
+		AdminFee:          trx.AdminFee,
+		TotalAmount:       trx.TotalAmount(),
+		Status:            trx.Status,
+		SerialNumber:      trx.SerialNumber,
+		Message:           trx.SupplierMessage,
+	})
+}
+
+// NewBalanceMutatedEvent builds a balance mutation event
+func NewBalanceMutatedEvent(mutation *Mutation) (*DomainEvent, error) {
+	return NewDomainEvent(EventBalanceMutated, AggregateTypeUser, mutation.UserID, &BalanceEventPayload{
+		MutationID:    mutation.ID,
+		UserID:        mutation.UserID,
+		Type:          mutation.Type,
+		Amount:        mutation.Amount,
+		BalanceBefore: mutation.BalanceBefore,
+		BalanceAfter:  mutation.BalanceAfter,
+		ReferenceType: mutation.ReferenceType,
+		ReferenceID:   mutation.ReferenceID,
+		Description:   mutation.Description,
+	})
+}
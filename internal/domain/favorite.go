@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Favorite is a saved product, optionally with a destination, that a user can
+// order again through the quick-order endpoint
+type Favorite struct {
+	ID                string  `json:"id" db:"id"`
+	UserID            string  `json:"user_id" db:"user_id"`
+	ProductID         string  `json:"product_id" db:"product_id"`
+	ProductCode       string  `json:"product_code" db:"product_code"` // Joined from products
+	ProductName       string  `json:"product_name" db:"product_name"` // Joined from products
+	DestinationNumber *string `json:"destination_number" db:"destination_number"`
+	Alias             *string `json:"alias" db:"alias"` // Customer label, e.g. "Pulsa Ibu"
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// FavoriteRepository defines operations for favorite data access
+type FavoriteRepository interface {
+	Create(favorite *Favorite) error
+	GetByID(id string) (*Favorite, error)
+	ListByUser(userID string) ([]*Favorite, error)
+	CountByUser(userID string) (int, error)
+	Update(favorite *Favorite) error
+	Delete(id, userID string) error
+}
+
+// FavoriteUsecase defines favorite management and quick orders
+type FavoriteUsecase interface {
+	CreateFavorite(userID, productCode string, destination, alias *string) (*Favorite, error)
+	ListFavorites(userID string) ([]*Favorite, error)
+	UpdateFavorite(userID, id string, destination, alias *string) (*Favorite, error)
+	DeleteFavorite(userID, id string) error
+	// QuickOrder creates a normal transaction from a favorite; destination
+	// overrides the saved destination and is required when none is saved
+	QuickOrder(ctx context.Context, userID, id string, destination *string, channel string) (*Transaction, error)
+}
+
+// MaxFavoritesPerUser caps how many favorites a single user can save
+const MaxFavoritesPerUser = 100
@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// FeatureFlag gates a behavior in one environment. While enabled it is on for
+// the listed users and for RolloutPercent of the others, picked by a hash of
+// the flag key and user ID, so a user stays in or out as the rollout grows.
+type FeatureFlag struct {
+	Key            string    `json:"key" db:"key"`
+	Environment    string    `json:"environment" db:"environment"`
+	Description    string    `json:"description" db:"description"`
+	Enabled        bool      `json:"enabled" db:"enabled"`                 // Off for everyone when false
+	RolloutPercent int       `json:"rollout_percent" db:"rollout_percent"` // 0 - 100
+	UserIDs        []string  `json:"user_ids" db:"-"`                      // On regardless of the rollout
+	UpdatedBy      *string   `json:"updated_by" db:"updated_by"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// FeatureFlagEvaluation explains the state of a flag for a user
+type FeatureFlagEvaluation struct {
+	Key         string `json:"key"`
+	Environment string `json:"environment"`
+	UserID      string `json:"user_id,omitempty"`
+	Enabled     bool   `json:"enabled"`
+	Reason      string `json:"reason"`
+	Stored      bool   `json:"stored"` // False when the flag falls back to its default
+}
+
+// FeatureFlagRepository defines operations for feature flag data access
+type FeatureFlagRepository interface {
+	// Upsert creates the flag of its environment or replaces its settings
+	Upsert(flag *FeatureFlag) error
+	Get(key, environment string) (*FeatureFlag, error)
+	Delete(key, environment string) error
+	ListByEnvironment(environment string) ([]*FeatureFlag, error)
+}
+
+// FeatureFlagCacheRepository shares the flags of an environment between
+// replicas, so evaluating them rarely reaches the database
+type FeatureFlagCacheRepository interface {
+	// GetFlags returns the cached flags of an environment, or nil on a miss
+	GetFlags(environment string) ([]*FeatureFlag, error)
+	SetFlags(environment string, flags []*FeatureFlag, ttl time.Duration) error
+	InvalidateFlags(environment string) error
+}
+
+// FeatureFlagEvaluator reports whether gated behaviors are on
+type FeatureFlagEvaluator interface {
+	// IsEnabled reports whether the flag is on for userID; an empty userID
+	// only matches flags rolled out to everyone
+	IsEnabled(key, userID string) bool
+}
+
+// FeatureFlagUsecase manages feature flags and evaluates them for the
+// environment of this process
+type FeatureFlagUsecase interface {
+	FeatureFlagEvaluator
+	// ListFlags returns every known flag of an environment, stored or default
+	ListFlags(environment string) ([]*FeatureFlag, error)
+	SetFlag(flag *FeatureFlag) (*FeatureFlag, error)
+	DeleteFlag(key, environment string) error
+	Evaluate(key, userID string) (*FeatureFlagEvaluation, error)
+}
+
+// Feature flag keys
+const (
+	// FlagRoutingSpreadAccounts spreads supplier traffic over the supplier's accounts
+	FlagRoutingSpreadAccounts = "routing.spread_accounts"
+	// FlagH2HSyncFailover processes H2H orders of clients with a sync failover
+	// policy within the request
+	FlagH2HSyncFailover = "h2h.sync_failover"
+)
+
+// FeatureFlagKeys lists the flags evaluated by the code
+var FeatureFlagKeys = []string{
+	FlagRoutingSpreadAccounts,
+	FlagH2HSyncFailover,
+}
+
+// Feature flag evaluation reasons
+const (
+	FlagReasonDefault   = "default"
+	FlagReasonDisabled  = "disabled"
+	FlagReasonUser      = "user"
+	FlagReasonRollout   = "rollout"
+	FlagReasonNoRollout = "not_in_rollout"
+)
+
+// IsValidFeatureFlagKey checks if the flag is evaluated by the code
+func IsValidFeatureFlagKey(key string) bool {
+	for _, k := range FeatureFlagKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Check validates the settings of the flag
+func (f *FeatureFlag) Check() error {
+	if !IsValidFeatureFlagKey(f.Key) {
+		return fmt.Errorf("unknown feature flag")
+	}
+	if f.Environment == "" {
+		return fmt.Errorf("environment is required")
+	}
+	if f.RolloutPercent < 0 || f.RolloutPercent > 100 {
+		return fmt.Errorf("rollout_percent must be between 0 and 100")
+	}
+	return nil
+}
+
+// Evaluate reports whether the flag is on for userID and why
+func (f *FeatureFlag) Evaluate(userID string) (bool, string) {
+	if !f.Enabled {
+		return false, FlagReasonDisabled
+	}
+	if userID != "" {
+		for _, id := range f.UserIDs {
+			if id == userID {
+				return true, FlagReasonUser
+			}
+		}
+	}
+	if f.RolloutPercent >= 100 {
+		return true, FlagReasonRollout
+	}
+	if userID == "" || f.RolloutPercent <= 0 || RolloutBucket(f.Key, userID) >= f.RolloutPercent {
+		return false, FlagReasonNoRollout
+	}
+	return true, FlagReasonRollout
+}
+
+// RolloutBucket places a user in one of 100 buckets of a flag. Buckets differ
+// per flag, so the first users of one rollout are not the first of every one.
+func RolloutBucket(key, userID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key + ":" + userID))
+	return int(h.Sum32() % 100)
+}
@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// FeeRule defines the admin fee charged on top of a transaction's selling
+// price. Empty Category or UserLevel match everything; the most specific
+// matching rule wins and Priority breaks ties.
+type FeeRule struct {
+	ID        string   `json:"id" db:"id"`
+	Name      string   `json:"name" db:"name"`
+	Category  *string  `json:"category" db:"category"`
+	UserLevel *int     `json:"user_level" db:"user_level"`
+	FeeType   string   `json:"fee_type" db:"fee_type"` // FLAT or PERCENTAGE
+	Amount    float64  `json:"amount" db:"amount"`     // Rupiah for FLAT, percent for PERCENTAGE
+	MinFee    *float64 `json:"min_fee" db:"min_fee"`
+	MaxFee    *float64 `json:"max_fee" db:"max_fee"`
+	Priority  int      `json:"priority" db:"priority"`
+	IsActive  bool     `json:"is_active" db:"is_active"`
+	CreatedBy *string  `json:"created_by" db:"created_by"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// FeeQuote is the admin fee resolved for a transaction
+type FeeQuote struct {
+	SellingPrice float64  `json:"selling_price"`
+	AdminFee     float64  `json:"admin_fee"`
+	Total        float64  `json:"total"`
+	Rule         *FeeRule `json:"rule,omitempty"`
+}
+
+// FeeRuleRepository defines operations for fee rule data access
+type FeeRuleRepository interface {
+	Create(rule *FeeRule) error
+	GetByID(id string) (*FeeRule, error)
+	Update(rule *FeeRule) error
+	Delete(id string) error
+	List(activeOnly bool) ([]*FeeRule, error)
+}
+
+// FeeUsecase defines business logic operations for admin fees
+type FeeUsecase interface {
+	CreateRule(rule *FeeRule) error
+	UpdateRule(rule *FeeRule) (*FeeRule, error)
+	DeleteRule(id string) error
+	GetRule(id string) (*FeeRule, error)
+	ListRules() ([]*FeeRule, error)
+	CalculateFee(category string, userLevel int, sellingPrice float64) (*FeeQuote, error)
+}
+
+// Fee type constants
+const (
+	FeeTypeFlat       = "FLAT"
+	FeeTypePercentage = "PERCENTAGE"
+)
+
+// IsValidFeeType checks if the fee type is valid
+func IsValidFeeType(feeType string) bool {
+	return feeType == FeeTypeFlat || feeType == FeeTypePercentage
+}
+
+// Matches reports whether the rule applies to a product category and user level
+func (r *FeeRule) Matches(category string, userLevel int) bool {
+	if !r.IsActive {
+		return false
+	}
+	if r.Category != nil && *r.Category != category {
+		return false
+	}
+	if r.UserLevel != nil && *r.UserLevel != userLevel {
+		return false
+	}
+	return true
+}
+
+// Specificity ranks matching rules: category and level beat category only,
+// which beats level only, which beats a catch-all rule
+func (r *FeeRule) Specificity() int {
+	specificity := 0
+	if r.Category != nil {
+		specificity += 2
+	}
+	if r.UserLevel != nil {
+		specificity++
+	}
+	return specificity
+}
+
+// Calculate returns the fee for a selling price, capped by MinFee and MaxFee
+// and rounded to whole rupiah
+func (r *FeeRule) Calculate(sellingPrice float64) float64 {
+	fee := r.Amount
+	if r.FeeType == FeeTypePercentage {
+		fee = sellingPrice * r.Amount / 100
+	}
+
+	if r.MinFee != nil && fee < *r.MinFee {
+		fee = *r.MinFee
+	}
+	if r.MaxFee != nil && fee > *r.MaxFee {
+		fee = *r.MaxFee
+	}
+
+	return math.Round(fee)
+}
+
+// SelectFeeRule returns the most specific active rule matching the category
+// and user level, or nil when no rule applies
+func SelectFeeRule(rules []*FeeRule, category string, userLevel int) *FeeRule {
+	var selected *FeeRule
+	for _, rule := range rules {
+		if !rule.Matches(category, userLevel) {
+			continue
+		}
+		if selected == nil ||
+			rule.Specificity() > selected.Specificity() ||
+			(rule.Specificity() == selected.Specificity() && rule.Priority > selected.Priority) {
+			selected = rule
+		}
+	}
+	return selected
+}
@@ -0,0 +1,85 @@
+package domain
+
+import "time"
+
+// ImpersonationSession lets an admin act as a user through a short-lived
+// token, so support sees exactly what the user sees. Sessions are read-only:
+// any request that could change data, balance-moving ones included, is
+// rejected, and every request is recorded as an ImpersonationAction.
+type ImpersonationSession struct {
+	ID        string     `json:"id" db:"id"`
+	AdminID   string     `json:"admin_id" db:"admin_id"`
+	UserID    string     `json:"user_id" db:"user_id"`
+	Reason    string     `json:"reason" db:"reason"`
+	IPAddress string     `json:"ip_address" db:"ip_address"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	EndedAt   *time.Time `json:"ended_at" db:"ended_at"`
+	EndedBy   *string    `json:"ended_by" db:"ended_by"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// IsActive reports whether the session token is still accepted
+func (s *ImpersonationSession) IsActive(now time.Time) bool {
+	return s.EndedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// ImpersonationAction is one request made with an impersonation token
+type ImpersonationAction struct {
+	ID         string    `json:"id" db:"id"`
+	SessionID  string    `json:"session_id" db:"session_id"`
+	Method     string    `json:"method" db:"method"`
+	Path       string    `json:"path" db:"path"`
+	Route      *string   `json:"route" db:"route"`
+	StatusCode int       `json:"status_code" db:"status_code"`
+	IPAddress  string    `json:"ip_address" db:"ip_address"`
+	UserAgent  *string   `json:"user_agent" db:"user_agent"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// IssuedImpersonation is returned when a session starts; the token is not
+// stored and cannot be retrieved again
+type IssuedImpersonation struct {
+	Session *ImpersonationSession `json:"session"`
+	Token   string                `json:"token"`
+}
+
+// ImpersonationSessionFilter filters session listings
+type ImpersonationSessionFilter struct {
+	AdminID *string
+	UserID  *string
+	Limit   int
+	Offset  int
+}
+
+// Impersonation response headers, set on every response to an impersonation
+// token so clients can show a banner
+const (
+	HeaderImpersonatedBy      = "X-Impersonated-By"
+	HeaderImpersonationID     = "X-Impersonation-Session"
+	HeaderImpersonationExpiry = "X-Impersonation-Expires-At"
+
+	// DenialImpersonationReadOnly is recorded when an impersonation token
+	// tries a request that could change data
+	DenialImpersonationReadOnly = "IMPERSONATION_READ_ONLY"
+)
+
+// ImpersonationRepository defines operations for impersonation audit data access
+type ImpersonationRepository interface {
+	CreateSession(session *ImpersonationSession) error
+	GetSession(id string) (*ImpersonationSession, error)
+	ListSessions(filter *ImpersonationSessionFilter) ([]*ImpersonationSession, int, error)
+	EndSession(id, endedBy string) error
+	RecordAction(action *ImpersonationAction) error
+	ListActions(sessionID string) ([]*ImpersonationAction, error)
+}
+
+// ImpersonationUsecase defines admin impersonation operations
+type ImpersonationUsecase interface {
+	Start(adminID, userID, reason, ipAddress string) (*IssuedImpersonation, error)
+	// End revokes the session token before it expires
+	End(sessionID, adminID string) (*ImpersonationSession, error)
+	GetSession(id string) (*ImpersonationSession, error)
+	ListSessions(filter *ImpersonationSessionFilter) ([]*ImpersonationSession, int, error)
+	ListActions(sessionID string) ([]*ImpersonationAction, error)
+	RecordAction(action *ImpersonationAction) error
+}
@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+// Login throttle subject types
+const (
+	LoginSubjectUser = "user"
+	LoginSubjectIP   = "ip"
+)
+
+// LoginLock describes an active lockout for a login subject
+type LoginLock struct {
+	SubjectType string        `json:"subject_type"`
+	Subject     string        `json:"subject"`
+	RetryAfter  time.Duration `json:"retry_after"`
+}
+
+// LoginAttemptRepository tracks failed logins and lockouts per subject (username or IP)
+type LoginAttemptRepository interface {
+	// IncrementFailures increments the failure counter within window and returns the new count
+	IncrementFailures(subjectType, subject string, window time.Duration) (int, error)
+	ResetFailures(subjectType, subject string) error
+	// Lock locks the subject for duration and returns how many times it has been locked
+	// within lockHistoryTTL (1 for the first lockout)
+	Lock(subjectType, subject string, duration, lockHistoryTTL time.Duration) (int, error)
+	// LockCount returns how many times the subject has been locked recently
+	LockCount(subjectType, subject string) (int, error)
+	// GetLockTTL returns the remaining lock duration, 0 when not locked
+	GetLockTTL(subjectType, subject string) (time.Duration, error)
+	// Unlock clears lock, lock history and failure counter of the subject
+	Unlock(subjectType, subject string) error
+}
+
+// LoginThrottleUsecase defines brute-force protection for the login endpoint
+type LoginThrottleUsecase interface {
+	// CheckLocked returns the active lock for the username or IP, nil when login is allowed
+	CheckLocked(username, ip string) (*LoginLock, error)
+	// RegisterFailure records a failed login and returns the lock applied, if any
+	RegisterFailure(username, ip string) (*LoginLock, error)
+	// RegisterSuccess clears the failure counter of the username
+	RegisterSuccess(username, ip string) error
+	Unlock(username, ip string) error
+}
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MappingReview records a product mapping whose supplier product code no longer
+// exists in the supplier catalog, together with candidate replacement codes
+type MappingReview struct {
+	ID               string             `json:"id" db:"id"`
+	ProductMappingID string             `json:"product_mapping_id" db:"product_mapping_id"`
+	ProductID        string             `json:"product_id" db:"product_id"`
+	SupplierID       string             `json:"supplier_id" db:"supplier_id"`
+	StaleCode        string             `json:"stale_code" db:"stale_code"`
+	Suggestions      []MappingCandidate `json:"suggestions" db:"-"`
+	SuggestionsJSON  string             `json:"-" db:"suggestions"` // JSON encoded suggestions
+	Status           string             `json:"status" db:"status"`
+	ResolvedCode     *string            `json:"resolved_code" db:"resolved_code"`
+	ReviewedBy       *string            `json:"reviewed_by" db:"reviewed_by"`
+	ReviewedAt       *time.Time         `json:"reviewed_at" db:"reviewed_at"`
+	DetectedAt       time.Time          `json:"detected_at" db:"detected_at"`
+	UpdatedAt        time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// MappingCandidate is a supplier catalog item suggested as replacement code
+type MappingCandidate struct {
+	Code  string   `json:"code"`
+	Name  string   `json:"name"`
+	Price float64  `json:"price"`
+	Score float64  `json:"score"` // 0.0 - 1.0, higher is a closer match
+	Notes []string `json:"notes,omitempty"`
+}
+
+// MappingValidationResult summarises a mapping validation run for one supplier
+type MappingValidationResult struct {
+	SupplierID   string `json:"supplier_id"`
+	SupplierCode string `json:"supplier_code"`
+	Checked      int    `json:"checked"`
+	Stale        int    `json:"stale"`
+	Resolved     int    `json:"resolved"` // open reviews closed because the code reappeared
+}
+
+// MappingReviewRepository defines operations for mapping review data access
+type MappingReviewRepository interface {
+	Upsert(review *MappingReview) error
+	GetByID(id string) (*MappingReview, error)
+	GetOpenByMappingID(mappingID string) (*MappingReview, error)
+	List(status string) ([]*MappingReview, error)
+	UpdateStatus(id, status string, resolvedCode, reviewedBy *string) error
+}
+
+// MappingValidationUsecase defines stale supplier code detection and review
+type MappingValidationUsecase interface {
+	ValidateSupplierMappings(supplierID string) (*MappingValidationResult, error)
+	ValidateAllMappings() ([]*MappingValidationResult, error)
+	ListReviews(status string) ([]*MappingReview, error)
+	AcceptReview(id, supplierProductCode string, reviewedBy *string) (*MappingReview, error)
+	DismissReview(id string, reviewedBy *string) (*MappingReview, error)
+}
+
+// Mapping review statuses
+const (
+	MappingReviewOpen      = "OPEN"
+	MappingReviewAccepted  = "ACCEPTED"
+	MappingReviewDismissed = "DISMISSED"
+	MappingReviewResolved  = "RESOLVED" // Code reappeared in the catalog
+)
+
+// IsValidMappingReviewStatus checks if the review status is valid
+func IsValidMappingReviewStatus(status string) bool {
+	switch status {
+	case MappingReviewOpen, MappingReviewAccepted, MappingReviewDismissed, MappingReviewResolved:
+		return true
+	}
+	return false
+}
+
+var (
+	nominalPattern = regexp.MustCompile(`\d{1,3}(?:[.,]\d{3})+|\d+`)
+	tokenPattern   = regexp.MustCompile(`[a-z0-9]+`)
+)
+
+// NominalFromName extracts the largest number in a product name, e.g.
+// "Telkomsel 5.000" -> 5000. Returns 0 when the name has no number.
+func NominalFromName(name string) float64 {
+	var nominal float64
+	for _, match := range nominalPattern.FindAllString(name, -1) {
+		digits := strings.NewReplacer(".", "", ",", "").Replace(match)
+		if value, err := strconv.ParseFloat(digits, 64); err == nil && value > nominal {
+			nominal = value
+		}
+	}
+	return nominal
+}
+
+// NameSimilarity returns the Dice coefficient of the lowercase word tokens of two names
+func NameSimilarity(a, b string) float64 {
+	tokensA := tokenPattern.FindAllString(strings.ToLower(a), -1)
+	tokensB := tokenPattern.FindAllString(strings.ToLower(b), -1)
+	if len(tokensA) == 0 || len(tokensB) == 0 {
+		return 0
+	}
+
+	counts := make(map[string]int, len(tokensA))
+	for _, token := range tokensA {
+		counts[token]++
+	}
+
+	shared := 0
+	for _, token := range tokensB {
+		if counts[token] > 0 {
+			counts[token]--
+			shared++
+		}
+	}
+
+	return float64(2*shared) / float64(len(tokensA)+len(tokensB))
+}
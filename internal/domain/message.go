@@ -39,12 +39,14 @@ type Outbox struct {
 	Destination     string  `json:"destination" db:"destination"`
 	RecipientNumber string  `json:"recipient_number" db:"recipient_number"`
 	RecipientName   *string `json:"recipient_name" db:"recipient_name"`
+	Subject         *string `json:"subject" db:"subject"` // Email only
 	Message         string  `json:"message" db:"message"`
 	MessageType     string  `json:"message_type" db:"message_type"`
 
 	// Related entities
 	UserID        *string `json:"user_id" db:"user_id"`
 	TransactionID *string `json:"transaction_id" db:"transaction_id"`
+	SourceEventID *string `json:"source_event_id" db:"source_event_id"` // Domain event the message was generated from
 
 	// Sending status
 	Status         string     `json:"status" db:"status"`
@@ -91,6 +93,15 @@ type OutboxRepository interface {
 	MarkAsSent(id string, externalID string) error
 	MarkAsFailed(id string, deliveryReport string) error
 	IncrementRetryCount(id string) error
+	// ClaimPending claims up to limit due messages of a destination for
+	// delivery, hiding them from other dispatchers for the lease
+	ClaimPending(destination string, limit int, lease time.Duration) ([]*Outbox, error)
+	// ScheduleRetry records a failed attempt and schedules the next one
+	ScheduleRetry(id, deliveryReport string, nextAttemptAt time.Time) error
+	// MarkAsUndeliverable fails a message without further retries
+	MarkAsUndeliverable(id, deliveryReport string) error
+	// GetByUserID returns the latest messages sent to a user, newest first
+	GetByUserID(userID string, limit int) ([]*Outbox, error)
 }
 
 // MessageUsecase defines business logic operations for messages
@@ -233,10 +244,13 @@ func FormatBalance(amount float64) string {
 func GenerateTransactionResponse(transaction *Transaction) string {
 	switch transaction.Status {
 	case StatusSuccess:
+		serialNumber := "-"
+		if transaction.SerialNumber != nil && *transaction.SerialNumber != "" {
+			serialNumber = *transaction.SerialNumber
+		}
 		return fmt.Sprintf("Transaksi BERHASIL! %s -> %s. SN: %s",
-			transaction.ProductCode, transaction.DestinationNumber,
-			*transaction.SerialNumber)
-	case StatusFailed:
+			transaction.ProductCode, transaction.DestinationNumber, serialNumber)
+	case StatusFailed, StatusTimeout:
 		return fmt.Sprintf("Transaksi GAGAL! %s -> %s. %s",
 			transaction.ProductCode, transaction.DestinationNumber,
 			func() string {
@@ -245,6 +259,9 @@ func GenerateTransactionResponse(transaction *Transaction) string {
 				}
 				return "Silakan coba beberapa saat lagi."
 			}())
+	case StatusRefund:
+		return fmt.Sprintf("Transaksi DIREFUND! %s -> %s. Saldo telah dikembalikan.",
+			transaction.ProductCode, transaction.DestinationNumber)
 	case StatusPending:
 		return fmt.Sprintf("Transaksi DIPROSES! %s -> %s. Mohon ditunggu...",
 			transaction.ProductCode, transaction.DestinationNumber)
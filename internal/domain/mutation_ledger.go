@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// MutationFilter narrows a user's mutation ledger. Empty fields do not
+// filter; the date range is required so only the matching monthly
+// partitions are scanned.
+type MutationFilter struct {
+	UserID        string
+	Period        DateRange
+	Type          string   // DEBIT or CREDIT
+	ReferenceType string   // e.g. TRANSACTION, DEPOSIT or COMMISSION
+	MinAmount     *float64 // Inclusive
+	MaxAmount     *float64 // Inclusive
+}
+
+// Validate checks the filter
+func (f MutationFilter) Validate() error {
+	if f.UserID == "" {
+		return fmt.Errorf("user is required")
+	}
+	if f.Period.IsZero() {
+		return fmt.Errorf("date range is required")
+	}
+	if f.Type != "" && !IsValidMutationType(f.Type) {
+		return fmt.Errorf("invalid mutation type")
+	}
+	if (f.MinAmount != nil && *f.MinAmount < 0) || (f.MaxAmount != nil && *f.MaxAmount < 0) {
+		return fmt.Errorf("amount must not be negative")
+	}
+	if f.MinAmount != nil && f.MaxAmount != nil && *f.MaxAmount < *f.MinAmount {
+		return fmt.Errorf("max amount must not be below min amount")
+	}
+	return nil
+}
+
+// MutationDailySummary aggregates the mutations of one day for charts
+type MutationDailySummary struct {
+	Day         time.Time `json:"day" db:"day"` // Start of the day in the report timezone
+	DebitCount  int       `json:"debit_count" db:"debit_count"`
+	CreditCount int       `json:"credit_count" db:"credit_count"`
+	TotalDebit  float64   `json:"total_debit" db:"total_debit"`   // Money in
+	TotalCredit float64   `json:"total_credit" db:"total_credit"` // Money out
+	NetAmount   float64   `json:"net_amount" db:"net_amount"`     // Debit less credit
+	// ClosingBalance is the balance after the day's last matching mutation
+	ClosingBalance float64 `json:"closing_balance" db:"closing_balance"`
+}
@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// MutationWebhook pushes the balance mutations of a user, and optionally of
+// the user's downlines, to the user's own accounting system. Mutations are
+// batched into one signed delivery per batch interval.
+type MutationWebhook struct {
+	ID               string    `json:"id" db:"id"`
+	UserID           string    `json:"user_id" db:"user_id"`
+	URL              string    `json:"url" db:"url"`
+	Secret           string    `json:"-" db:"secret"`                            // Signs every delivery, shown once
+	IncludeDownlines bool      `json:"include_downlines" db:"include_downlines"` // Also push the mutations of downlines
+	IsActive         bool      `json:"is_active" db:"is_active"`                 // Paused webhooks queue nothing
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// MutationWebhookSetup is a webhook with its secret, returned only when the
+// secret is issued
+type MutationWebhookSetup struct {
+	*MutationWebhook
+	Secret string `json:"secret,omitempty"`
+}
+
+// MutationWebhookDelivery is one batch of mutations posted to a webhook. Its
+// ID is the event ID of the request, so receivers can drop redeliveries.
+type MutationWebhookDelivery struct {
+	ID            string     `json:"id" db:"id"`
+	WebhookID     string     `json:"webhook_id" db:"webhook_id"`
+	UserID        string     `json:"user_id" db:"user_id"`
+	Status        string     `json:"status" db:"status"`
+	MutationCount int        `json:"mutation_count" db:"mutation_count"`
+	Attempts      int        `json:"attempts" db:"attempts"`
+	LastError     *string    `json:"last_error" db:"last_error"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" db:"next_attempt_at"`
+	DeliveredAt   *time.Time `json:"delivered_at" db:"delivered_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Mutation webhook delivery statuses
+const (
+	MutationDeliveryPending   = "PENDING"
+	MutationDeliveryDelivered = "DELIVERED"
+	MutationDeliveryFailed    = "FAILED"
+)
+
+// EventWalletStatement is the event type of mutation webhook deliveries. The
+// event is posted to the webhook directly, it never goes through the outbox.
+const EventWalletStatement = "wallet.statement"
+
+// MutationStatementEntry is one mutation of a wallet statement delivery
+type MutationStatementEntry struct {
+	BalanceEventPayload
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// WalletStatementPayload is the payload of a wallet statement delivery. The
+// user_id of each mutation tells whose balance moved.
+type WalletStatementPayload struct {
+	UserID    string                    `json:"user_id"`   // Owner of the webhook
+	Mutations []*MutationStatementEntry `json:"mutations"` // Oldest first
+}
+
+// MutationWebhookRepository defines data access for mutation webhooks, their
+// queued mutations and their deliveries
+type MutationWebhookRepository interface {
+	// Upsert creates the webhook of its user or replaces its settings
+	Upsert(webhook *MutationWebhook) error
+	GetByID(id string) (*MutationWebhook, error)
+	GetByUserID(userID string) (*MutationWebhook, error)
+	// Delete removes the webhook of a user with its queue and deliveries
+	Delete(userID string) error
+	// Enqueue queues a mutation for the active webhook of its user and of the
+	// uplines within maxDepth levels that include downlines, and returns how
+	// many webhooks it was queued for. A mutation is queued once per webhook.
+	Enqueue(entry *MutationStatementEntry, maxDepth int) (int, error)
+	// CreateDeliveries moves the queued mutations into pending deliveries of
+	// at most batchSize mutations, and returns how many mutations it moved
+	CreateDeliveries(batchSize int) (int, error)
+	// ClaimDue claims up to limit pending deliveries due now, hiding them from
+	// other dispatchers for the lease
+	ClaimDue(limit int, lease time.Duration) ([]*MutationWebhookDelivery, error)
+	// ListEntries returns the mutations of a delivery, oldest first
+	ListEntries(deliveryID string) ([]*MutationStatementEntry, error)
+	MarkDelivered(id string) error
+	ScheduleRetry(id, lastError string, nextAttemptAt time.Time) error
+	MarkFailed(id, lastError string) error
+	GetDelivery(id string) (*MutationWebhookDelivery, error)
+	// ListDeliveries returns the latest deliveries of a webhook, newest first
+	ListDeliveries(webhookID string, limit int) ([]*MutationWebhookDelivery, error)
+	// Requeue makes a failed delivery pending again with fresh attempts
+	Requeue(id string) error
+}
+
+// MutationWebhookUsecase manages the mutation webhooks of users and delivers
+// their mutations
+type MutationWebhookUsecase interface {
+	GetWebhook(userID string) (*MutationWebhook, error)
+	// SetWebhook creates or updates the webhook of a user. The secret is
+	// returned when the webhook is created.
+	SetWebhook(userID, url string, includeDownlines, isActive bool) (*MutationWebhookSetup, error)
+	RotateSecret(userID string) (*MutationWebhookSetup, error)
+	DeleteWebhook(userID string) error
+	ListDeliveries(userID string, limit int) ([]*MutationWebhookDelivery, error)
+	// ResendDelivery queues a failed delivery of the user's webhook again
+	ResendDelivery(userID, deliveryID string) (*MutationWebhookDelivery, error)
+
+	// EnqueueMutation queues a balance mutated event for the webhooks it affects
+	EnqueueMutation(event *DomainEvent) error
+	// DispatchDeliveries batches the queued mutations and posts the due
+	// deliveries, returning how many were delivered
+	DispatchDeliveries(ctx context.Context) (int, error)
+}
@@ -0,0 +1,198 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// NotificationPreference stores whether a user receives an event on a channel
+type NotificationPreference struct {
+	ID        string    `json:"id" db:"id"`
+	UserID    string    `json:"user_id" db:"user_id"`
+	EventType string    `json:"event_type" db:"event_type"`
+	Channel   string    `json:"channel" db:"channel"`
+	IsEnabled bool      `json:"is_enabled" db:"is_enabled"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// MessageTemplate is an admin editable notification template with {{placeholder}} variables
+type MessageTemplate struct {
+	ID        string    `json:"id" db:"id"`
+	EventType string    `json:"event_type" db:"event_type"`
+	Channel   string    `json:"channel" db:"channel"`
+	Subject   *string   `json:"subject" db:"subject"` // Email only
+	Body      string    `json:"body" db:"body"`
+	IsActive  bool      `json:"is_active" db:"is_active"`
+	UpdatedBy *string   `json:"updated_by" db:"updated_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// NotificationPreferenceRepository defines operations for notification preference data access
+type NotificationPreferenceRepository interface {
+	GetByUser(userID string) ([]*NotificationPreference, error)
+	Upsert(pref *NotificationPreference) error
+	// ListEnabledUsers returns the users with a stored preference enabling
+	// the event on any channel
+	ListEnabledUsers(eventType string) ([]string, error)
+}
+
+// MessageTemplateRepository defines operations for message template data access
+type MessageTemplateRepository interface {
+	GetByID(id string) (*MessageTemplate, error)
+	GetActive(eventType, channel string) (*MessageTemplate, error)
+	List() ([]*MessageTemplate, error)
+	Update(template *MessageTemplate) error
+}
+
+// NotificationUsecase defines preference management, template editing and
+// generation of outbox messages from domain events
+type NotificationUsecase interface {
+	GetPreferences(userID string) ([]*NotificationPreference, error)
+	UpdatePreference(userID, eventType, channel string, enabled bool) (*NotificationPreference, error)
+	ListTemplates() ([]*MessageTemplate, error)
+	GetTemplate(id string) (*MessageTemplate, error)
+	UpdateTemplate(id string, subject, body *string, isActive *bool, updatedBy *string) (*MessageTemplate, error)
+	PreviewTemplate(id string, data map[string]string) (subject, body string, err error)
+	GenerateMessages(event *DomainEvent) (int, error)
+	// QueueAccountMessage queues a mandatory account message (e.g. password
+	// reset) on the given channel, bypassing user preferences
+	QueueAccountMessage(userID, eventType, channel string, data map[string]string) error
+	// GenerateDailySummaries queues the summary of the given day for every
+	// user with activity that day and returns the number of messages queued
+	GenerateDailySummaries(day time.Time) (int, error)
+	// GeneratePriceChangeDigests queues, for every user who opted in, one
+	// digest per category of the price changes in [since, until) and returns
+	// the number of messages queued
+	GeneratePriceChangeDigests(since, until time.Time) (int, error)
+}
+
+// MessageSender delivers outbox messages of one channel and returns the
+// provider's message ID
+type MessageSender interface {
+	Channel() string
+	Send(ctx context.Context, message *Outbox) (string, error)
+}
+
+// OutboxDispatchUsecase delivers queued outbox messages through the configured senders
+type OutboxDispatchUsecase interface {
+	DispatchPending(ctx context.Context) (int, error)
+}
+
+// Notification constants
+const (
+	NotificationChannelWhatsApp = SourceWhatsApp
+	NotificationChannelEmail    = "EMAIL"
+
+	NotificationEventTransactionSuccess = "transaction.success"
+	NotificationEventTransactionFailed  = "transaction.failed"
+	NotificationEventBalanceMutated     = EventBalanceMutated
+	NotificationEventDepositConfirmed   = "deposit.confirmed"
+	NotificationEventLowBalance         = "balance.low"
+	NotificationEventDailySummary       = "daily.summary"
+	NotificationEventPriceChanged       = "price.changed"
+
+	// Account messages are always sent and cannot be unsubscribed
+	NotificationEventPasswordReset = "password.reset"
+)
+
+// NotificationChannels lists supported notification channels
+var NotificationChannels = []string{NotificationChannelWhatsApp, NotificationChannelEmail}
+
+// NotificationEventTypes lists events users can subscribe to
+var NotificationEventTypes = []string{
+	NotificationEventTransactionSuccess,
+	NotificationEventTransactionFailed,
+	NotificationEventBalanceMutated,
+	NotificationEventDepositConfirmed,
+	NotificationEventLowBalance,
+	NotificationEventDailySummary,
+	NotificationEventPriceChanged,
+}
+
+// templatePlaceholders lists the placeholders available per notification event
+var templatePlaceholders = map[string][]string{
+	NotificationEventTransactionSuccess: {"name", "username", "trx_code", "product_code", "destination", "sn", "price", "admin_fee", "total", "status", "message", "date"},
+	NotificationEventTransactionFailed:  {"name", "username", "trx_code", "product_code", "destination", "sn", "price", "admin_fee", "total", "status", "message", "date"},
+	NotificationEventBalanceMutated:     {"name", "username", "type", "amount", "balance_before", "balance", "description", "date"},
+	NotificationEventDepositConfirmed:   {"name", "username", "amount", "balance_before", "balance", "description", "date"},
+	NotificationEventLowBalance:         {"name", "username", "balance", "threshold", "date"},
+	NotificationEventDailySummary:       {"name", "username", "date", "total_transactions", "success_count", "failed_count", "total_spent", "total_deposit", "balance"},
+	NotificationEventPriceChanged:       {"name", "username", "category", "change_count", "changes", "period_start", "period_end"},
+	NotificationEventPasswordReset:      {"name", "username", "reset_link", "expires_in"},
+}
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([a-zA-Z0-9_]+)\s*\}\}`)
+
+// IsValidNotificationChannel checks if the channel is valid
+func IsValidNotificationChannel(channel string) bool {
+	for _, c := range NotificationChannels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidNotificationEventType checks if users can subscribe to the notification event type
+func IsValidNotificationEventType(eventType string) bool {
+	for _, e := range NotificationEventTypes {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// TemplatePlaceholders returns the placeholders available for an event type
+func TemplatePlaceholders(eventType string) []string {
+	return templatePlaceholders[eventType]
+}
+
+// DefaultNotificationEnabled is used when the user has no stored preference:
+// WhatsApp is opt-out, email is opt-in except for deposits and low balance
+// warnings. Price change digests are opt-in on every channel.
+func DefaultNotificationEnabled(eventType, channel string) bool {
+	if eventType == NotificationEventPriceChanged {
+		return false
+	}
+	if channel == NotificationChannelEmail {
+		return eventType == NotificationEventDepositConfirmed || eventType == NotificationEventLowBalance
+	}
+	return channel == NotificationChannelWhatsApp
+}
+
+// RenderTemplate replaces {{placeholder}} variables in text. Unknown placeholders render empty.
+func RenderTemplate(text string, data map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		key := placeholderPattern.FindStringSubmatch(match)[1]
+		return data[key]
+	})
+}
+
+// ValidateTemplate checks the template only uses placeholders available for its event type
+func ValidateTemplate(eventType, text string) error {
+	allowed := make(map[string]bool)
+	for _, p := range templatePlaceholders[eventType] {
+		allowed[p] = true
+	}
+
+	for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+		if !allowed[match[1]] {
+			return fmt.Errorf("unknown placeholder: {{%s}}", match[1])
+		}
+	}
+
+	return nil
+}
+
+// Render renders subject and body of the template
+func (t *MessageTemplate) Render(data map[string]string) (subject, body string) {
+	if t.Subject != nil {
+		subject = RenderTemplate(*t.Subject, data)
+	}
+	return subject, RenderTemplate(t.Body, data)
+}
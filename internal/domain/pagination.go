@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Cursor is a keyset position in a listing ordered by (created_at DESC, id DESC)
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// Cursor pagination limits
+const (
+	DefaultCursorLimit = 20
+	MaxCursorLimit     = 100
+)
+
+// EncodeCursor builds an opaque cursor from the last row of a page
+func EncodeCursor(createdAt time.Time, id string) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses an opaque cursor. An empty string yields a nil cursor (first page).
+func DecodeCursor(value string) (*Cursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	return &Cursor{CreatedAt: createdAt, ID: parts[1]}, nil
+}
+
+// NormalizeCursorLimit clamps a requested page size to the allowed range
+func NormalizeCursorLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultCursorLimit
+	}
+	if limit > MaxCursorLimit {
+		return MaxCursorLimit
+	}
+	return limit
+}
+
+// DateRange bounds a listing by created_at, both ends inclusive. Transactions
+// and mutations are partitioned by created_at month, so their listings take a
+// range to scan only the partitions it overlaps.
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// Listing date range limits
+const (
+	DefaultListingWindow = 90 * 24 * time.Hour
+	MaxListingRange      = 366 * 24 * time.Hour
+)
+
+// NewListingRange builds the date range of a listing. A zero to means now and
+// a zero from means DefaultListingWindow before to.
+func NewListingRange(from, to time.Time) (DateRange, error) {
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-DefaultListingWindow)
+	}
+	if to.Before(from) {
+		return DateRange{}, fmt.Errorf("end date must not be before start date")
+	}
+	if to.Sub(from) > MaxListingRange {
+		return DateRange{}, fmt.Errorf("date range too large")
+	}
+	return DateRange{From: from, To: to}, nil
+}
+
+// IsZero reports whether either end of the range is unset
+func (r DateRange) IsZero() bool {
+	return r.From.IsZero() || r.To.IsZero()
+}
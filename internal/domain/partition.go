@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+// Tables partitioned by created_at month
+const (
+	PartitionedTableTransactions = "transactions"
+	PartitionedTableMutations    = "mutations"
+)
+
+// PartitionedTables lists the tables kept up to date by partition maintenance
+var PartitionedTables = []string{PartitionedTableTransactions, PartitionedTableMutations}
+
+// TablePartition is one monthly partition of a partitioned table
+type TablePartition struct {
+	Name       string    `json:"name"`
+	MonthStart time.Time `json:"month_start"`
+}
+
+// PartitionRepository manages the monthly partitions of partitioned tables
+type PartitionRepository interface {
+	// CreateMonthlyPartition creates the partition of table for the month
+	// starting at monthStart and returns its name, or "" when the month is
+	// already covered by an existing partition
+	CreateMonthlyPartition(table string, monthStart time.Time) (string, error)
+	// ListMonthlyPartitions returns the attached monthly partitions of table,
+	// oldest first. The legacy and default partitions are not included.
+	ListMonthlyPartitions(table string) ([]*TablePartition, error)
+	// DetachPartition detaches a partition, keeping it as a standalone table.
+	// Detached mutations are added to the users' ledger checkpoints.
+	DetachPartition(table, partition string) error
+	// CountDefaultRows counts rows that fell into the default partition
+	CountDefaultRows(table string) (int64, error)
+}
+
+// PartitionMaintenanceResult summarises one maintenance pass over a table
+type PartitionMaintenanceResult struct {
+	Table       string   `json:"table"`
+	Created     []string `json:"created"`
+	Detached    []string `json:"detached"`
+	DefaultRows int64    `json:"default_rows"`
+}
+
+// PartitionMaintenanceUsecase creates upcoming monthly partitions and detaches
+// the ones past retention
+type PartitionMaintenanceUsecase interface {
+	MaintainPartitions() ([]*PartitionMaintenanceResult, error)
+}
@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+// PasswordResetToken is a single-use password reset token. Only the SHA-256
+// hash of the token is stored; the token itself is only sent to the user.
+type PasswordResetToken struct {
+	ID          string     `json:"id" db:"id"`
+	UserID      string     `json:"user_id" db:"user_id"`
+	TokenHash   string     `json:"-" db:"token_hash"`
+	Channel     string     `json:"channel" db:"channel"` // Notification channel the token was sent on
+	RequestedIP *string    `json:"requested_ip" db:"requested_ip"`
+	ExpiresAt   time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt      *time.Time `json:"used_at" db:"used_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// PasswordResetRepository defines operations for password reset token data access
+type PasswordResetRepository interface {
+	Create(token *PasswordResetToken) error
+	// Consume marks an unused, unexpired token as used and returns it; the
+	// token can only be consumed once
+	Consume(tokenHash string) (*PasswordResetToken, error)
+	// InvalidateUser expires every unused token of the user
+	InvalidateUser(userID string) error
+	// CountSince counts the tokens issued to the user since the given time
+	CountSince(userID string, since time.Time) (int, error)
+}
+
+// TokenRevocationRepository records when a user's access tokens were revoked
+type TokenRevocationRepository interface {
+	// RevokeBefore rejects the user's tokens issued before at; the marker is
+	// kept for ttl, which must cover the longest token lifetime
+	RevokeBefore(userID string, at time.Time, ttl time.Duration) error
+	// RevokedBefore returns the revocation time, zero when none is recorded
+	RevokedBefore(userID string) (time.Time, error)
+}
+
+// PasswordResetUsecase defines the forgot/reset password flow
+type PasswordResetUsecase interface {
+	// RequestReset sends a reset token to the account with the given email.
+	// Unknown emails succeed silently so accounts cannot be enumerated.
+	RequestReset(email, channel, ip string) error
+	// ResetPassword sets a new password with a reset token and revokes the
+	// user's existing sessions
+	ResetPassword(token, newPassword string) error
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// PriceChange is a price history entry with the product it belongs to, as
+// listed to users and summarised in price change digests. Prices change as
+// soon as they are saved, so EffectiveAt is when the change was recorded.
+type PriceChange struct {
+	ID          string    `json:"id" db:"id"`
+	ProductID   string    `json:"product_id" db:"product_id"`
+	ProductCode string    `json:"product_code" db:"product_code"`
+	ProductName string    `json:"product_name" db:"product_name"`
+	Category    string    `json:"category" db:"category"`
+	PriceType   string    `json:"price_type" db:"price_type"`
+	OldPrice    float64   `json:"old_price" db:"old_price"`
+	NewPrice    float64   `json:"new_price" db:"new_price"`
+	Source      string    `json:"source" db:"source"`
+	EffectiveAt time.Time `json:"effective_at" db:"created_at"`
+}
+
+// Difference returns the price increase, negative for a decrease
+func (c *PriceChange) Difference() float64 {
+	return c.NewPrice - c.OldPrice
+}
+
+// PriceChangeFilter narrows price change listings. Empty PriceTypes lists the
+// customer facing prices (see CustomerPriceTypes).
+type PriceChangeFilter struct {
+	Category   string
+	PriceTypes []string
+	Since      time.Time // Inclusive
+	Until      time.Time // Exclusive; zero for now
+	UserLevel  int       // Leaves out products restricted above this level; zero lists all
+	Limit      int
+	Offset     int
+}
+
+// CustomerPriceTypes are the price types users pay or sell at. Supplier
+// costs are internal and only listed to admins.
+var CustomerPriceTypes = []string{PriceTypeBase, PriceTypeSelling}
+
+// IsValidPriceType checks if the price type is valid
+func IsValidPriceType(priceType string) bool {
+	return priceType == PriceTypeSelling || priceType == PriceTypeBase || priceType == PriceTypeSupplier
+}
+
+// PriceChangedEventPayload is the payload of product.price_changed events,
+// written when the base or selling price of a product changes
+type PriceChangedEventPayload struct {
+	ProductID   string    `json:"product_id"`
+	ProductCode string    `json:"product_code"`
+	Category    string    `json:"category"`
+	PriceType   string    `json:"price_type"`
+	OldPrice    float64   `json:"old_price"`
+	NewPrice    float64   `json:"new_price"`
+	Source      string    `json:"source"`
+	ChangedBy   *string   `json:"changed_by,omitempty"`
+	EffectiveAt time.Time `json:"effective_at"`
+}
+
+// NewPriceChangedEvent builds the product.price_changed outbox event of a
+// recorded price change
+func NewPriceChangedEvent(product *Product, history *PriceHistory) (*DomainEvent, error) {
+	if product == nil || history == nil {
+		return nil, fmt.Errorf("product and price change are required")
+	}
+	return NewDomainEvent(EventProductPriceChanged, AggregateTypeProduct, product.ID, &PriceChangedEventPayload{
+		ProductID:   product.ID,
+		ProductCode: product.Code,
+		Category:    product.Category,
+		PriceType:   history.PriceType,
+		OldPrice:    history.OldPrice,
+		NewPrice:    history.NewPrice,
+		Source:      history.Source,
+		ChangedBy:   history.ChangedBy,
+		EffectiveAt: history.CreatedAt,
+	})
+}
@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+// PriceList is the public catalog of active products with their prices, as
+// served to storefronts
+type PriceList struct {
+	Category    string           `json:"category,omitempty"` // Empty for every category
+	GeneratedAt time.Time        `json:"generated_at"`
+	Items       []*PriceListItem `json:"items"`
+}
+
+// PriceListItem is one product of the public price list. LevelPrices holds,
+// per level role, the lowest price an active user of that level pays; levels
+// without active users are left out. SuccessRate and Disrupted come from the
+// product's health (see ProductHealth).
+type PriceListItem struct {
+	Code           string             `json:"code"`
+	Name           string             `json:"name"`
+	Category       string             `json:"category"`
+	Provider       string             `json:"provider"`
+	Nominal        *float64           `json:"nominal,omitempty"`
+	ValidityPeriod *string            `json:"validity_period,omitempty"`
+	Price          float64            `json:"price"` // Retail selling price
+	LevelPrices    map[string]float64 `json:"level_prices,omitempty"`
+	Available      bool               `json:"available"`
+	SuccessRate    *float64           `json:"success_rate,omitempty"`
+	Disrupted      bool               `json:"disrupted"`
+}
+
+// PriceListCacheRepository caches built price lists per category and guards
+// their rebuild so only one instance queries the database at a time
+type PriceListCacheRepository interface {
+	// Get returns the cached list of a category; ok is false on a cache miss
+	Get(category string) (list *PriceList, ok bool, err error)
+	Set(category string, list *PriceList, ttl time.Duration) error
+	// AcquireRebuild claims the rebuild of a category for ttl; it returns the
+	// claim's owner token, or "" when another instance holds the claim
+	AcquireRebuild(category string, ttl time.Duration) (owner string, err error)
+	// ReleaseRebuild drops the claim only while owner still holds it
+	ReleaseRebuild(category, owner string) error
+}
+
+// PriceListUsecase serves the public price list
+type PriceListUsecase interface {
+	// GetPriceList returns the price list of a category, or of every category
+	// when category is empty. The list may be up to the cache's stale window old.
+	GetPriceList(category string) (*PriceList, error)
+}
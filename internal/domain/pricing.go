@@ -0,0 +1,147 @@
+package domain
+
+import "time"
+
+// PriceHistory records a single price change of a product or one of its supplier mappings
+type PriceHistory struct {
+	ID               string    `json:"id" db:"id"`
+	ProductID        string    `json:"product_id" db:"product_id"`
+	ProductMappingID *string   `json:"product_mapping_id" db:"product_mapping_id"`
+	SupplierID       *string   `json:"supplier_id" db:"supplier_id"`
+	PriceType        string    `json:"price_type" db:"price_type"`
+	OldPrice         float64   `json:"old_price" db:"old_price"`
+	NewPrice         float64   `json:"new_price" db:"new_price"`
+	Source           string    `json:"source" db:"source"`
+	ChangedBy        *string   `json:"changed_by" db:"changed_by"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+}
+
+// PriceHistoryRepository defines operations for price history data access
+type PriceHistoryRepository interface {
+	Create(history *PriceHistory) error
+	ListByProduct(productID string, limit int) ([]*PriceHistory, error)
+	// ListChanges lists price changes with their product, newest first,
+	// together with the total count
+	ListChanges(filter *PriceChangeFilter) ([]*PriceChange, int, error)
+}
+
+// MarginCheck is the result of comparing supplier cost against selling price
+type MarginCheck struct {
+	ProductID    string  `json:"product_id"`
+	ProductCode  string  `json:"product_code"`
+	SellingPrice float64 `json:"selling_price"`
+	SupplierCost float64 `json:"supplier_cost"`
+	SupplierID   string  `json:"supplier_id,omitempty"`
+	Margin       float64 `json:"margin"`
+	MinMargin    float64 `json:"min_margin"`
+	Breached     bool    `json:"breached"`
+	Action       string  `json:"action,omitempty"` // Guard action applied when breached
+}
+
+// PriceSyncResult summarises a supplier price list sync
+type PriceSyncResult struct {
+	SupplierID   string `json:"supplier_id"`
+	SupplierCode string `json:"supplier_code"`
+	Checked      int    `json:"checked"`
+	Updated      int    `json:"updated"`
+	Unmatched    int    `json:"unmatched"`
+	Flagged      int    `json:"flagged"`
+	Deactivated  int    `json:"deactivated"`
+}
+
+// PricingUsecase defines price history tracking, supplier price sync and margin protection
+type PricingUsecase interface {
+	RecordPriceChange(history *PriceHistory) error
+	GetPriceHistory(productID string, limit int) ([]*PriceHistory, error)
+	// ListPriceChanges lists recent price changes across products
+	ListPriceChanges(filter *PriceChangeFilter) ([]*PriceChange, int, error)
+	SyncSupplierPrices(supplierID string) (*PriceSyncResult, error)
+	SyncAllSupplierPrices() ([]*PriceSyncResult, error)
+	CheckProductMargin(productID string) (*MarginCheck, error)
+	GuardTransactionMargin(productID string, mapping *ProductMapping, sellingPrice float64) (*MarginCheck, error)
+	// SimulatePricing computes margins for hypothetical product prices
+	// without changing the product
+	SimulatePricing(productID string, input *PriceSimulationInput) (*PriceSimulation, error)
+}
+
+// PriceSimulationInput holds the hypothetical prices; nil keeps the current price
+type PriceSimulationInput struct {
+	BasePrice    *float64
+	SellingPrice *float64
+}
+
+// PriceSimulation is the margin impact of hypothetical product prices
+type PriceSimulation struct {
+	ProductID           string                      `json:"product_id"`
+	ProductCode         string                      `json:"product_code"`
+	CurrentBasePrice    float64                     `json:"current_base_price"`
+	CurrentSellingPrice float64                     `json:"current_selling_price"`
+	BasePrice           float64                     `json:"base_price"`
+	SellingPrice        float64                     `json:"selling_price"`
+	SupplierCost        float64                     `json:"supplier_cost"` // Cheapest active mapping, 0 without one
+	SellingMargin       float64                     `json:"selling_margin"`
+	MinMargin           float64                     `json:"min_margin"`
+	Levels              []*LevelMarginSimulation    `json:"levels"`
+	Suppliers           []*SupplierMarginSimulation `json:"suppliers"`
+	Warnings            []string                    `json:"warnings"`
+}
+
+// LevelMarginSimulation is the price range users of a level pay under the
+// simulated base price and the margin left against the cheapest supplier
+type LevelMarginSimulation struct {
+	Level          int     `json:"level"`
+	Users          int     `json:"users"`
+	MinMarkup      float64 `json:"min_markup"`
+	MaxMarkup      float64 `json:"max_markup"`
+	LowestPrice    float64 `json:"lowest_price"`
+	HighestPrice   float64 `json:"highest_price"`
+	LowestMargin   float64 `json:"lowest_margin"`
+	HighestMargin  float64 `json:"highest_margin"`
+	BelowMinMargin bool    `json:"below_min_margin"`
+}
+
+// SupplierMarginSimulation is the margin of the simulated prices against one
+// supplier mapping
+type SupplierMarginSimulation struct {
+	MappingID           string  `json:"mapping_id"`
+	SupplierID          string  `json:"supplier_id"`
+	SupplierCode        string  `json:"supplier_code,omitempty"`
+	SupplierProductCode string  `json:"supplier_product_code"`
+	IsActive            bool    `json:"is_active"`
+	Cost                float64 `json:"cost"`
+	BaseMargin          float64 `json:"base_margin"`
+	SellingMargin       float64 `json:"selling_margin"`
+	BelowMinMargin      bool    `json:"below_min_margin"`
+}
+
+// Pricing constants
+const (
+	PriceTypeSelling  = "SELLING"
+	PriceTypeBase     = "BASE"
+	PriceTypeSupplier = "SUPPLIER"
+
+	PriceSourceAdmin = "ADMIN"
+	PriceSourceSync  = "SYNC"
+
+	MarginActionFlag       = "FLAG"
+	MarginActionDeactivate = "DEACTIVATE"
+)
+
+// IsValidMarginAction checks if the margin guard action is valid
+func IsValidMarginAction(action string) bool {
+	return action == MarginActionFlag || action == MarginActionDeactivate
+}
+
+// NewMarginCheck compares cost with selling price. The margin is breached when
+// cost exceeds selling price minus the minimum margin.
+func NewMarginCheck(product *Product, sellingPrice, cost, minMargin float64) *MarginCheck {
+	return &MarginCheck{
+		ProductID:    product.ID,
+		ProductCode:  product.Code,
+		SellingPrice: sellingPrice,
+		SupplierCost: cost,
+		Margin:       sellingPrice - cost,
+		MinMargin:    minMargin,
+		Breached:     cost > sellingPrice-minMargin,
+	}
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -29,6 +30,7 @@ type Product struct {
 	IsActive         bool `json:"is_active" db:"is_active"`
 	IsUnlimitedStock bool `json:"is_unlimited_stock" db:"is_unlimited_stock"`
 	StockQuantity    int  `json:"stock_quantity" db:"stock_quantity"`
+	IsVoucherStock   bool `json:"is_voucher_stock" db:"is_voucher_stock"` // Voucher sold from the code stock, stock_quantity follows its codes
 
 	// Business rules
 	AllowMarkup          bool    `json:"allow_markup" db:"allow_markup"`
@@ -36,11 +38,39 @@ type Product struct {
 	MinTransactionAmount float64 `json:"min_transaction_amount" db:"min_transaction_amount"`
 	MaxTransactionAmount float64 `json:"max_transaction_amount" db:"max_transaction_amount"`
 
+	// Margin guard (set when the best supplier price eats into the minimum margin)
+	MarginFlagged    bool       `json:"margin_flagged" db:"margin_flagged"`
+	MarginFlaggedAt  *time.Time `json:"margin_flagged_at" db:"margin_flagged_at"`
+	MarginFlagReason *string    `json:"margin_flag_reason" db:"margin_flag_reason"`
+
+	// Destination format override (nil fields fall back to the category rule)
+	DestinationPattern   *string `json:"destination_pattern" db:"destination_pattern"`
+	DestinationMinLength *int    `json:"destination_min_length" db:"destination_min_length"`
+	DestinationMaxLength *int    `json:"destination_max_length" db:"destination_max_length"`
+	DestinationHint      *string `json:"destination_hint" db:"destination_hint"`
+
+	// Visibility (nil lets every user level see and buy the product)
+	MinLevel *int `json:"min_level" db:"min_level"`
+
 	// Timestamps
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// VisibleTo reports whether a user of the given level may see and buy the product
+func (p *Product) VisibleTo(level int) bool {
+	return p.MinLevel == nil || level >= *p.MinLevel
+}
+
+// ProductRestrictedError reports an order for a product above the user's level
+type ProductRestrictedError struct {
+	MinLevel int
+}
+
+func (e *ProductRestrictedError) Error() string {
+	return fmt.Sprintf("product requires %s level or above", MapLevelToRole(e.MinLevel))
+}
+
 // ProductMapping represents mapping between product and supplier
 type ProductMapping struct {
 	ID                  string `json:"id" db:"id"`
@@ -64,6 +94,10 @@ type ProductMapping struct {
 	LastFailureAt  *time.Time `json:"last_failure_at" db:"last_failure_at"`
 	LastStockCheck *time.Time `json:"last_stock_check" db:"last_stock_check"`
 
+	// Auto-tuned priority (computed from rolling performance, admin priority stays untouched)
+	EffectivePriority *float64   `json:"effective_priority" db:"effective_priority"`
+	PriorityTunedAt   *time.Time `json:"priority_tuned_at" db:"priority_tuned_at"`
+
 	// Timestamps
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
@@ -79,11 +113,23 @@ type ProductRepository interface {
 	GetByCategory(category string) ([]*Product, error)
 	GetByProvider(provider string) ([]*Product, error)
 	GetActiveProducts() ([]*Product, error)
-	Search(query string) ([]*Product, error)
+	// Search ranks active products by full-text match on code, name,
+	// provider, category and description plus trigram similarity on name
+	// and code, so typos and partial words still match
+	Search(query string, limit int) ([]*ProductSearchResult, error)
 	List(filter *ProductFilter) ([]*Product, error)
 	Count(filter *ProductFilter) (int, error)
 	UpdateStatus(id string, isActive bool) error
 	UpdateStock(id string, stockQuantity int, isUnlimited bool) error
+	SetMarginFlag(id string, flagged bool, reason *string) error
+}
+
+// ProductCacheRepository caches products by ID. The batch methods take one
+// round trip whatever the number of products.
+type ProductCacheRepository interface {
+	// GetProducts returns the cached products keyed by ID; misses are left out
+	GetProducts(productIDs []string) (map[string]*Product, error)
+	CacheProducts(products []*Product) error
 }
 
 // ProductMappingRepository defines operations for product mapping data access
@@ -96,39 +142,74 @@ type ProductMappingRepository interface {
 	Update(mapping *ProductMapping) error
 	Delete(id string) error
 	GetBySupplierID(supplierID string) ([]*ProductMapping, error)
+	GetAllActiveMappings() ([]*ProductMapping, error)
+	GetPerformanceSince(since time.Time) ([]*MappingPerformance, error)
+	// UpdateEffectivePriority stores the auto-tuned priority of a mapping; nil
+	// clears it so routing falls back to the admin-set priority
+	UpdateEffectivePriority(id string, effectivePriority *float64) error
+	// ReorderPriorities sets the priority of each mapping of a product from its
+	// position in mappingIDs (first = 1) in one database transaction. It fails
+	// unless mappingIDs lists every mapping of the product exactly once.
+	ReorderPriorities(productID string, mappingIDs []string) error
+}
+
+// MappingPerformance represents aggregated transaction performance of a product mapping
+type MappingPerformance struct {
+	ProductID    string  `json:"product_id" db:"product_id"`
+	SupplierID   string  `json:"supplier_id" db:"supplier_id"`
+	TotalCount   int     `json:"total_count" db:"total_count"`
+	SuccessCount int     `json:"success_count" db:"success_count"`
+	AvgLatencyMs float64 `json:"avg_latency_ms" db:"avg_latency_ms"`
+}
+
+// PriorityTuningUsecase defines operations for auto-tuning mapping priorities
+type PriorityTuningUsecase interface {
+	TuneMappingPriorities() (int, error)
 }
 
 // ProductUsecase defines business logic operations for products
 type ProductUsecase interface {
 	CreateProduct(product *Product) error
-	UpdateProduct(id string, updates *Product) error
+	UpdateProduct(id string, updates *Product, changedBy *string) error
 	ListProducts(filter *ProductFilter) ([]*Product, int, error)
 	GetProduct(id string) (*Product, error)
 	GetProductByCode(code string) (*Product, error)
 	GetProductsByCategory(category string) ([]*Product, error)
 	GetActiveProducts() ([]*Product, error)
-	SearchProducts(query string) ([]*Product, error)
+	SearchProducts(query string, limit int) ([]*ProductSearchResult, error)
 	ToggleProductStatus(id string, isActive bool) error
 	UpdateProductStock(id string, stockQuantity int, isUnlimited bool) error
+	// SetDestinationOverride replaces the product's destination format
+	// override; nil fields fall back to the category rule
+	SetDestinationOverride(id string, pattern *string, minLength, maxLength *int, hint *string) (*Product, error)
+	// SetMinLevel restricts the product to users of minLevel and above;
+	// nil lifts the restriction
+	SetMinLevel(id string, minLevel *int) (*Product, error)
 	GetBestSupplier(productID string) (*ProductMapping, error)
-	UpdateProductMapping(mapping *ProductMapping) error
+	UpdateProductMapping(mapping *ProductMapping, changedBy *string) error
 	GetProductMappings(productID string) ([]*ProductMapping, error)
 	GetProductMapping(id string) (*ProductMapping, error)
 	CreateProductMapping(mapping *ProductMapping) error
 	DeleteProductMapping(id string) error
+	// ReorderProductMappings assigns priorities 1..n to the mappings of a
+	// product in the given order and returns them sorted by priority
+	ReorderProductMappings(productID string, mappingIDs []string) ([]*ProductMapping, error)
 }
 
 // ProductFilter represents filter criteria for listing products
 type ProductFilter struct {
-	Category *string
-	Provider *string
-	Query    *string
-	IsActive *bool
-	Page     int
-	PageSize int
+	Category      *string
+	Provider      *string
+	Query         *string
+	IsActive      *bool
+	MarginFlagged *bool
+	Page          int
+	PageSize      int
 }
 
-// Product validation constants
+// Product validation constants. Categories and providers are managed in the
+// product_categories and product_providers tables (see CatalogUsecase); the
+// category codes below are the built-in ones seeded there.
 const (
 	CategoryPulsa   = "PULSA"
 	CategoryData    = "DATA"
@@ -147,20 +228,6 @@ const (
 	StockStatusUnknown    = "UNKNOWN"
 )
 
-// IsValidCategory checks if the category is valid
-func IsValidCategory(category string) bool {
-	validCategories := []string{
-		CategoryPulsa, CategoryData, CategoryPLN, CategoryPDAM,
-		CategoryBPJS, CategoryGame, CategoryVoucher,
-	}
-	for _, c := range validCategories {
-		if c == category {
-			return true
-		}
-	}
-	return false
-}
-
 // IsValidType checks if the product type is valid
 func IsValidType(productType string) bool {
 	validTypes := []string{TypePrepaid, TypePostpaid, TypeVoucher}
@@ -190,3 +257,47 @@ func (pm *ProductMapping) GetEffectivePrice() float64 {
 func (pm *ProductMapping) IsAvailable() bool {
 	return pm.IsActive && pm.StockStatus == StockStatusAvailable
 }
+
+// GetSuccessRate calculates success rate percentage of the aggregated window
+func (mp *MappingPerformance) GetSuccessRate() float64 {
+	if mp.TotalCount == 0 {
+		return 0
+	}
+	return float64(mp.SuccessCount) / float64(mp.TotalCount) * 100
+}
+
+// Auto-tuned effective priorities range from EffectivePriorityBest to
+// EffectivePriorityWorst
+const (
+	EffectivePriorityBest  = 1.0
+	EffectivePriorityWorst = 10.0
+)
+
+// NormalizePriority maps an admin-set priority, an unbounded integer, onto
+// the effective priority range so both can be blended. The lowest and
+// highest priority among the routing candidates become the ends of the range.
+func NormalizePriority(priority, lowest, highest int) float64 {
+	if highest <= lowest {
+		return EffectivePriorityBest
+	}
+	share := float64(priority-lowest) / float64(highest-lowest)
+	return EffectivePriorityBest + share*(EffectivePriorityWorst-EffectivePriorityBest)
+}
+
+// GetBlendedPriority blends a baseline priority, the admin-set priority of
+// the mapping and its supplier normalized by NormalizePriority, with the
+// mapping's auto-tuned one. weight is the share (0.0 - 1.0) given to the
+// auto-tuned priority; 0 returns the baseline.
+func (pm *ProductMapping) GetBlendedPriority(basePriority float64, weight float64) float64 {
+	base := basePriority
+	if base < EffectivePriorityBest {
+		base = EffectivePriorityBest
+	}
+	if pm.EffectivePriority == nil || weight <= 0 {
+		return base
+	}
+	if weight > 1 {
+		weight = 1
+	}
+	return base*(1-weight) + *pm.EffectivePriority*weight
+}
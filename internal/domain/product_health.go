@@ -0,0 +1,23 @@
+package domain
+
+// ProductHealth is the recent reliability of a product as shown to
+// storefronts, which badge disrupted products as "gangguan"
+type ProductHealth struct {
+	// SuccessRate is the percentage of finished transactions that succeeded
+	// over the rolling window; nil while the product has too few samples
+	SuccessRate *float64 `json:"success_rate"`
+	Samples     int      `json:"samples"`
+	// Available reports whether an active supplier mapping is not out of stock
+	Available bool `json:"available"`
+	// Disrupted is set when the product is unavailable or its success rate is
+	// below the configured threshold
+	Disrupted bool `json:"disrupted"`
+}
+
+// ProductHealthUsecase derives product health from the mapping metrics
+type ProductHealthUsecase interface {
+	// GetProductHealth returns the health of every product keyed by product
+	// ID. Products without an active mapping are left out; callers treat them
+	// as unavailable.
+	GetProductHealth() (map[string]*ProductHealth, error)
+}
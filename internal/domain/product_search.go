@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ProductSearchResult is a product matched by a search query, ordered by rank
+type ProductSearchResult struct {
+	Product
+	Rank       float64           `json:"rank" db:"rank"`
+	Highlights map[string]string `json:"highlights,omitempty" db:"-"` // Field name -> text with matched terms wrapped in <mark>
+}
+
+// Product search limits
+const (
+	DefaultProductSearchLimit = 20
+	MaxProductSearchLimit     = 50
+	MinProductSearchLength    = 2
+
+	highlightOpen  = "<mark>"
+	highlightClose = "</mark>"
+)
+
+// ProductSearchTerms splits a search query into lower-cased alphanumeric terms
+func ProductSearchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// HighlightTerms wraps case-insensitive occurrences of terms in text with
+// <mark> tags and reports whether anything matched. Overlapping matches are
+// merged into one highlight.
+func HighlightTerms(text string, terms []string) (string, bool) {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		// Lower-casing changed the length; match on the original text only
+		lower = runes
+	}
+
+	marked := make([]bool, len(runes))
+	found := false
+	for _, term := range terms {
+		needle := []rune(strings.ToLower(term))
+		if len(needle) == 0 {
+			continue
+		}
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if string(lower[i:i+len(needle)]) == string(needle) {
+				for j := i; j < i+len(needle); j++ {
+					marked[j] = true
+				}
+				found = true
+			}
+		}
+	}
+	if !found {
+		return text, false
+	}
+
+	var b strings.Builder
+	for i, r := range runes {
+		if marked[i] && (i == 0 || !marked[i-1]) {
+			b.WriteString(highlightOpen)
+		}
+		b.WriteRune(r)
+		if marked[i] && (i == len(runes)-1 || !marked[i+1]) {
+			b.WriteString(highlightClose)
+		}
+	}
+	return b.String(), true
+}
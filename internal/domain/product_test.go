@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGetBlendedPriority(t *testing.T) {
+	tuned := func(priority float64) *ProductMapping {
+		return &ProductMapping{EffectivePriority: &priority}
+	}
+
+	tests := []struct {
+		name     string
+		mapping  *ProductMapping
+		priority int
+		lowest   int
+		highest  int
+		weight   float64
+		want     float64
+	}{
+		{name: "single candidate is best", mapping: &ProductMapping{}, priority: 40, lowest: 40, highest: 40, weight: 0.5, want: 1},
+		{name: "highest admin priority is worst", mapping: &ProductMapping{}, priority: 100, lowest: 1, highest: 100, weight: 0.5, want: 10},
+		{name: "admin priority scaled between", mapping: &ProductMapping{}, priority: 50, lowest: 1, highest: 100, weight: 0, want: 1 + 9*49.0/99},
+		{name: "weight 0 ignores tuning", mapping: tuned(10), priority: 1, lowest: 1, highest: 3, weight: 0, want: 1},
+		{name: "blended on one scale", mapping: tuned(1), priority: 3, lowest: 1, highest: 3, weight: 0.5, want: 5.5},
+		{name: "weight above 1 is capped", mapping: tuned(4), priority: 3, lowest: 1, highest: 3, weight: 2, want: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := NormalizePriority(tt.priority, tt.lowest, tt.highest)
+			got := tt.mapping.GetBlendedPriority(base, tt.weight)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("GetBlendedPriority() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// Promotion is a cashback campaign: a share of the selling price of
+// successful transactions is paid back to the buyer's balance. Empty
+// Category or UserLevels match everything.
+type Promotion struct {
+	ID          string  `json:"id" db:"id"`
+	Name        string  `json:"name" db:"name"`
+	Description *string `json:"description" db:"description"`
+	Category    *string `json:"category" db:"category"`
+	UserLevels  []int   `json:"user_levels" db:"-"` // User segments the promotion targets
+	Percentage  float64 `json:"percentage" db:"percentage"`
+
+	// Caps; nil means uncapped
+	MaxCashback *float64 `json:"max_cashback" db:"max_cashback"` // Per transaction
+	Budget      *float64 `json:"budget" db:"budget"`             // Whole promotion
+	Spent       float64  `json:"spent" db:"spent"`               // Cashback paid, net of reversals
+
+	// Orders placed within [StartsAt, EndsAt) earn cashback
+	StartsAt  time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt    time.Time `json:"ends_at" db:"ends_at"`
+	IsActive  bool      `json:"is_active" db:"is_active"`
+	CreatedBy *string   `json:"created_by" db:"created_by"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// PromotionCashback is the cashback paid for one transaction. A transaction
+// earns at most one cashback, from the promotion paying the most.
+type PromotionCashback struct {
+	ID                string     `json:"id" db:"id"`
+	PromotionID       string     `json:"promotion_id" db:"promotion_id"`
+	TransactionID     string     `json:"transaction_id" db:"transaction_id"`
+	UserID            string     `json:"user_id" db:"user_id"`
+	TransactionAmount float64    `json:"transaction_amount" db:"transaction_amount"` // Selling price the cashback was computed from
+	Amount            float64    `json:"amount" db:"amount"`
+	MutationID        string     `json:"mutation_id" db:"mutation_id"`
+	ReversedAmount    float64    `json:"reversed_amount" db:"reversed_amount"` // Taken back after a refund
+	ReversedAt        *time.Time `json:"reversed_at" db:"reversed_at"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+}
+
+// PromotionPerformance sums up the cashback paid by a promotion in a period
+type PromotionPerformance struct {
+	PromotionID       string   `json:"promotion_id" db:"promotion_id"`
+	Name              string   `json:"name" db:"name"`
+	Category          *string  `json:"category" db:"category"`
+	Transactions      int      `json:"transactions" db:"transactions"`
+	Users             int      `json:"users" db:"users"`
+	TransactionAmount float64  `json:"transaction_amount" db:"transaction_amount"`
+	CashbackAmount    float64  `json:"cashback_amount" db:"cashback_amount"`
+	ReversedAmount    float64  `json:"reversed_amount" db:"reversed_amount"`
+	NetCashback       float64  `json:"net_cashback" db:"net_cashback"`
+	Budget            *float64 `json:"budget" db:"budget"`
+	Spent             float64  `json:"spent" db:"spent"` // All time, net of reversals
+}
+
+// PromotionReport is the promotion performance report of a period
+type PromotionReport struct {
+	StartDate      time.Time               `json:"start_date"`
+	EndDate        time.Time               `json:"end_date"` // Exclusive
+	Transactions   int                     `json:"transactions"`
+	CashbackAmount float64                 `json:"cashback_amount"`
+	NetCashback    float64                 `json:"net_cashback"`
+	Promotions     []*PromotionPerformance `json:"promotions"`
+}
+
+// PromotionRepository defines data access for promotions and their cashback
+type PromotionRepository interface {
+	Create(promotion *Promotion) error
+	GetByID(id string) (*Promotion, error)
+	// GetByIDForUpdate returns a promotion, locking it inside a transaction
+	GetByIDForUpdate(id string) (*Promotion, error)
+	Update(promotion *Promotion) error
+	List(activeOnly bool) ([]*Promotion, error)
+	// AddSpent moves the cashback paid by a promotion; reversals pass a
+	// negative amount
+	AddSpent(id string, amount float64) error
+
+	// CreateCashback stores a cashback; a transaction has at most one
+	CreateCashback(cashback *PromotionCashback) error
+	// GetCashbackByTransactionID returns the cashback of a transaction, nil
+	// when it earned none
+	GetCashbackByTransactionID(transactionID string) (*PromotionCashback, error)
+	MarkCashbackReversed(id string, amount float64) error
+	// GetPerformance sums up the cashback paid within [start, end) per promotion
+	GetPerformance(start, end time.Time) ([]*PromotionPerformance, error)
+}
+
+// PromotionUsecase manages cashback promotions
+type PromotionUsecase interface {
+	CreatePromotion(promotion *Promotion) error
+	UpdatePromotion(promotion *Promotion) (*Promotion, error)
+	GetPromotion(id string) (*Promotion, error)
+	ListPromotions() ([]*Promotion, error)
+	// ApplyCashback pays the cashback a successful transaction earned. It is
+	// idempotent, so it may run again for the same transaction.
+	ApplyCashback(transactionID string) (*PromotionCashback, error)
+	// GetPromotionReport reports the cashback paid between the calendar dates
+	// of startDate and endDate (inclusive)
+	GetPromotionReport(startDate, endDate time.Time) (*PromotionReport, error)
+}
+
+// Mutation reference types of cashback; the reference ID is the transaction
+const (
+	ReferenceTypeCashback         = "CASHBACK"
+	ReferenceTypeCashbackReversal = "CASHBACK_REVERSAL"
+)
+
+// Matches reports whether an order of the category placed by a user of the
+// level at the given time earns the promotion's cashback
+func (p *Promotion) Matches(category string, userLevel int, at time.Time) bool {
+	if !p.IsActive || at.Before(p.StartsAt) || !at.Before(p.EndsAt) {
+		return false
+	}
+	if p.Category != nil && *p.Category != category {
+		return false
+	}
+	if len(p.UserLevels) == 0 {
+		return true
+	}
+	for _, level := range p.UserLevels {
+		if level == userLevel {
+			return true
+		}
+	}
+	return false
+}
+
+// Calculate returns the cashback for a selling price, rounded down to whole
+// rupiah and capped per transaction and by the budget left
+func (p *Promotion) Calculate(sellingPrice float64) float64 {
+	cashback := sellingPrice * p.Percentage / 100
+	if p.MaxCashback != nil && cashback > *p.MaxCashback {
+		cashback = *p.MaxCashback
+	}
+	if p.Budget != nil && cashback > *p.Budget-p.Spent {
+		cashback = *p.Budget - p.Spent
+	}
+	return math.Max(math.Floor(cashback), 0)
+}
+
+// SelectPromotion returns the matching promotion paying the most cashback
+// and that cashback, or nil when no promotion pays any
+func SelectPromotion(promotions []*Promotion, category string, userLevel int, at time.Time, sellingPrice float64) (*Promotion, float64) {
+	var selected *Promotion
+	best := 0.0
+	for _, promotion := range promotions {
+		if !promotion.Matches(category, userLevel, at) {
+			continue
+		}
+		if cashback := promotion.Calculate(sellingPrice); cashback > best {
+			selected, best = promotion, cashback
+		}
+	}
+	return selected, best
+}
@@ -4,6 +4,9 @@ package domain
 // that transport transaction IDs to workers for processing.
 type QueueRepository interface {
 	EnqueueTransaction(transactionID string) error
+	// EnqueueTransactions enqueues several transactions in one round trip,
+	// in the given order
+	EnqueueTransactions(transactionIDs []string) error
 	DequeueTransaction() (string, error)
 	GetQueueLength() (int64, error)
 }
@@ -0,0 +1,104 @@
+package domain
+
+import "time"
+
+// QuotaPlan limits how much an H2H client may use the API. Zero limits are
+// unlimited. Request limits apply per endpoint per minute; daily limits cover
+// transactions created by the client.
+type QuotaPlan struct {
+	ID                 string         `json:"id" db:"id"`
+	Name               string         `json:"name" db:"name"`
+	Description        *string        `json:"description" db:"description"`
+	RequestsPerMinute  int            `json:"requests_per_minute" db:"requests_per_minute"`
+	EndpointLimits     map[string]int `json:"endpoint_limits" db:"-"` // Requests per minute keyed by route path
+	EndpointLimitsJSON string         `json:"-" db:"endpoint_limits"` // JSON encoded endpoint limits
+	TransactionsPerDay int            `json:"transactions_per_day" db:"transactions_per_day"`
+	MaxAmountPerDay    float64        `json:"max_amount_per_day" db:"max_amount_per_day"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// RequestLimit returns the requests per minute allowed on an endpoint
+func (p *QuotaPlan) RequestLimit(endpoint string) int {
+	if limit, ok := p.EndpointLimits[endpoint]; ok {
+		return limit
+	}
+	return p.RequestsPerMinute
+}
+
+// QuotaStatus reports a client's quota after a request was counted. Limits of
+// zero are unlimited and their remaining values are meaningless.
+type QuotaStatus struct {
+	Exceeded string `json:"exceeded,omitempty"` // Quota that rejected the request, empty when allowed
+
+	RequestLimit      int       `json:"request_limit"`
+	RequestsRemaining int       `json:"requests_remaining"`
+	RequestsResetAt   time.Time `json:"requests_reset_at"`
+
+	TransactionLimit      int       `json:"transaction_limit"`
+	TransactionsRemaining int       `json:"transactions_remaining"`
+	AmountLimit           float64   `json:"amount_limit"`
+	AmountRemaining       float64   `json:"amount_remaining"`
+	DailyResetAt          time.Time `json:"daily_reset_at"`
+}
+
+// Allowed reports whether the request stayed within the quota
+func (s *QuotaStatus) Allowed() bool {
+	return s.Exceeded == ""
+}
+
+// Quota kinds reported in QuotaStatus.Exceeded
+const (
+	QuotaRequests     = "REQUESTS_PER_MINUTE"
+	QuotaTransactions = "TRANSACTIONS_PER_DAY"
+	QuotaAmount       = "AMOUNT_PER_DAY"
+)
+
+// QuotaPlanRepository defines operations for quota plan data access
+type QuotaPlanRepository interface {
+	Create(plan *QuotaPlan) error
+	GetByID(id string) (*QuotaPlan, error)
+	Update(plan *QuotaPlan) error
+	Delete(id string) error
+	List() ([]*QuotaPlan, error)
+	// AssignToClient sets or, with a nil plan, clears the plan of an API client
+	AssignToClient(clientID string, planID *string) error
+	CountClients(planID string) (int, error)
+}
+
+// QuotaCounterRepository keeps per-client usage counters shared by all replicas
+type QuotaCounterRepository interface {
+	// IncrementRequests counts a request in the minute window and returns the window's count
+	IncrementRequests(clientID, endpoint string, window time.Time) (int, error)
+	// ReserveTransaction counts a transaction for the day and returns the day's
+	// transaction count, including this one, and the amount used so far
+	ReserveTransaction(clientID, day string) (int, float64, error)
+	ReleaseTransaction(clientID, day string) error
+	// AddAmount adds a created transaction's amount to the day's usage
+	AddAmount(clientID, day string, amount float64) error
+	// GetUsage reads the endpoint's requests in the minute window and the
+	// day's transaction count and amount without changing them
+	GetUsage(clientID, endpoint string, window time.Time, day string) (int, int, float64, error)
+}
+
+// QuotaUsecase defines business logic operations for H2H quota plans
+type QuotaUsecase interface {
+	CreatePlan(plan *QuotaPlan) error
+	UpdatePlan(plan *QuotaPlan) (*QuotaPlan, error)
+	DeletePlan(id string) error
+	GetPlan(id string) (*QuotaPlan, error)
+	ListPlans() ([]*QuotaPlan, error)
+	AssignPlan(clientID string, planID *string) error
+
+	// CheckRequest counts a request against the client's per-minute quota
+	CheckRequest(client *APIClient, endpoint string) (*QuotaStatus, error)
+	// ReserveTransaction claims one of the client's daily transactions; it
+	// must be released when no transaction is created
+	ReserveTransaction(client *APIClient) (*QuotaStatus, error)
+	ReleaseTransaction(client *APIClient) error
+	RecordTransactionAmount(client *APIClient, amount float64) error
+	// GetStatus reports the client's quota on an endpoint without counting a request
+	GetStatus(client *APIClient, endpoint string) (*QuotaStatus, error)
+}
@@ -0,0 +1,91 @@
+package domain
+
+import "time"
+
+// LedgerBalance compares a user's stored balance with the balance recomputed
+// from the mutation ledger
+type LedgerBalance struct {
+	UserID           string   `db:"user_id"`
+	StoredBalance    float64  `db:"stored_balance"`
+	LedgerBalance    float64  `db:"ledger_balance"` // DEBIT (money in) minus CREDIT (money out) mutations
+	MutationCount    int      `db:"mutation_count"`
+	LastMutationID   *string  `db:"last_mutation_id"`
+	LastBalanceAfter *float64 `db:"last_balance_after"`
+	Matches          bool     `db:"matches"` // Compared as exact decimals in the database
+}
+
+// BalanceMismatch flags a user whose stored balance disagrees with the ledger
+type BalanceMismatch struct {
+	ID               string     `json:"id" db:"id"`
+	UserID           string     `json:"user_id" db:"user_id"`
+	StoredBalance    float64    `json:"stored_balance" db:"stored_balance"`
+	LedgerBalance    float64    `json:"ledger_balance" db:"ledger_balance"`
+	Difference       float64    `json:"difference" db:"difference"` // Stored minus ledger balance
+	MutationCount    int        `json:"mutation_count" db:"mutation_count"`
+	LastMutationID   *string    `json:"last_mutation_id" db:"last_mutation_id"`
+	LastBalanceAfter *float64   `json:"last_balance_after" db:"last_balance_after"`
+	Status           string     `json:"status" db:"status"`
+	Resolution       *string    `json:"resolution" db:"resolution"`
+	ResolvedBy       *string    `json:"resolved_by" db:"resolved_by"`
+	ResolvedAt       *time.Time `json:"resolved_at" db:"resolved_at"`
+	DetectedAt       time.Time  `json:"detected_at" db:"detected_at"`
+	LastCheckedAt    time.Time  `json:"last_checked_at" db:"last_checked_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// ReconciliationResult summarises a balance reconciliation run
+type ReconciliationResult struct {
+	UsersChecked    int       `json:"users_checked"`
+	Mismatches      int       `json:"mismatches"`
+	Cleared         int       `json:"cleared"`          // Open mismatches that balance again
+	TotalDifference float64   `json:"total_difference"` // Sum of the mismatch differences
+	StartedAt       time.Time `json:"started_at"`
+	FinishedAt      time.Time `json:"finished_at"`
+}
+
+// ReconciliationRepository defines data access for balance reconciliation
+type ReconciliationRepository interface {
+	// ListLedgerBalances recomputes the ledger balance of up to limit users
+	// ordered by ID, starting after afterUserID ("" for the first batch)
+	ListLedgerBalances(afterUserID string, limit int) ([]*LedgerBalance, error)
+	GetLedgerBalance(userID string) (*LedgerBalance, error)
+	// ApplyLedgerBalance locks the user and sets their stored balance to the
+	// ledger balance, returning the figures from before the update
+	ApplyLedgerBalance(userID string) (*LedgerBalance, error)
+	// UpsertMismatch opens a mismatch for the user, or refreshes the one
+	// that is already open
+	UpsertMismatch(mismatch *BalanceMismatch) error
+	// ClearMismatches closes the open mismatches of users that balance again
+	ClearMismatches(userIDs []string) (int, error)
+	GetMismatch(id string) (*BalanceMismatch, error)
+	ListMismatches(status string, limit, offset int) ([]*BalanceMismatch, error)
+	ResolveMismatch(id, resolution string, resolvedBy *string) error
+}
+
+// ReconciliationUsecase defines balance reconciliation operations
+type ReconciliationUsecase interface {
+	// ReconcileBalances compares every user's balance with the ledger and
+	// flags the users that disagree
+	ReconcileBalances() (*ReconciliationResult, error)
+	ListMismatches(status string, page, limit int) ([]*BalanceMismatch, error)
+	ResolveMismatch(id, resolution string, resolvedBy *string) (*BalanceMismatch, error)
+	// RecomputeBalance compares a user's balance with the ledger; apply also
+	// overwrites the stored balance with the ledger balance
+	RecomputeBalance(userID string, apply bool) (*LedgerBalance, error)
+}
+
+// Balance mismatch statuses
+const (
+	BalanceMismatchOpen     = "OPEN"
+	BalanceMismatchResolved = "RESOLVED" // Closed by an admin
+	BalanceMismatchCleared  = "CLEARED"  // Balanced again in a later run
+)
+
+// IsValidBalanceMismatchStatus checks if the mismatch status is valid
+func IsValidBalanceMismatchStatus(status string) bool {
+	switch status {
+	case BalanceMismatchOpen, BalanceMismatchResolved, BalanceMismatchCleared:
+		return true
+	}
+	return false
+}
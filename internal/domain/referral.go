@@ -0,0 +1,86 @@
+package domain
+
+import "time"
+
+// Downline registration statuses
+const (
+	RegistrationStatusPending  = "PENDING"
+	RegistrationStatusApproved = "APPROVED"
+	RegistrationStatusRejected = "REJECTED"
+)
+
+// DownlineRegistration is a sign-up made with an upline's referral code. The
+// user is created under the upline, one level below it and with its markup,
+// once the upline approves.
+type DownlineRegistration struct {
+	ID           string  `json:"id" db:"id"`
+	UplineID     string  `json:"upline_id" db:"upline_id"`
+	ReferralCode string  `json:"referral_code" db:"referral_code"`
+	Email        string  `json:"email" db:"email"`
+	FullName     string  `json:"full_name" db:"full_name"`
+	Phone        *string `json:"phone" db:"phone"`
+	PasswordHash string  `json:"-" db:"password_hash"`
+	Status       string  `json:"status" db:"status"`
+
+	// Decision
+	UserID    *string    `json:"user_id" db:"user_id"` // Created user once approved
+	Reason    *string    `json:"reason" db:"reason"`
+	DecidedBy *string    `json:"decided_by" db:"decided_by"`
+	DecidedAt *time.Time `json:"decided_at" db:"decided_at"`
+
+	IPAddress *string   `json:"ip_address" db:"ip_address"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// ReferralRegistrationRequest is the sign-up form of a referred user
+type ReferralRegistrationRequest struct {
+	ReferralCode string
+	Email        string
+	FullName     string
+	Phone        string
+	Password     string
+	IPAddress    string
+}
+
+// ReferralRepository defines operations for referral codes and downline
+// registrations
+type ReferralRepository interface {
+	// GetReferralCode returns the user's referral code, nil when none was issued
+	GetReferralCode(userID string) (*string, error)
+	// SetReferralCode replaces the user's code; a code in use by another user
+	// returns "referral code already exists"
+	SetReferralCode(userID, code string) error
+	// GetUserByReferralCode returns the user owning the code
+	GetUserByReferralCode(code string) (*User, error)
+
+	Create(registration *DownlineRegistration) error
+	// GetByIDForUpdate returns a registration, locking it inside a transaction
+	GetByIDForUpdate(id string) (*DownlineRegistration, error)
+	ListByUpline(uplineID, status string, limit int) ([]*DownlineRegistration, error)
+	// HasPendingEmail reports whether a registration for the email awaits approval
+	HasPendingEmail(email string) (bool, error)
+	// CountDownlines counts the direct downlines of a user and the
+	// registrations awaiting its approval
+	CountDownlines(uplineID string) (int, error)
+	// Decide records the decision on a pending registration
+	Decide(registration *DownlineRegistration) error
+}
+
+// ReferralUsecase manages referral codes and the registrations made with them
+type ReferralUsecase interface {
+	// GetCode returns the user's referral code, issuing one on first use
+	GetCode(userID string) (string, error)
+	// RotateCode replaces the user's code; the old one stops working
+	RotateCode(userID string) (string, error)
+	Register(req ReferralRegistrationRequest) (*DownlineRegistration, error)
+	ListRegistrations(uplineID, status string, limit int) ([]*DownlineRegistration, error)
+	// Approve creates the downline of a pending registration of the upline
+	Approve(uplineID, registrationID string) (*DownlineRegistration, error)
+	Reject(uplineID, registrationID, reason string) (*DownlineRegistration, error)
+}
+
+// DownlineLevel is the level of users registered under an upline: one level
+// below it, and never below reseller
+func DownlineLevel(uplineLevel int) int {
+	return max(uplineLevel-1, LevelReseller)
+}
@@ -0,0 +1,83 @@
+package domain
+
+import "time"
+
+// RefundStats aggregates the refunds completed within a period. Latency runs
+// from the last FAILED or TIMEOUT event of a transaction to its REFUNDED
+// event; refunds without a failure before them (forced refunds of successful
+// transactions) are counted but not measured.
+type RefundStats struct {
+	Count                 int     `json:"count" db:"count"`
+	TotalAmount           float64 `json:"total_amount" db:"total_amount"`
+	MeasuredCount         int     `json:"measured_count" db:"measured_count"`
+	AverageLatencySeconds float64 `json:"average_latency_seconds" db:"average_latency_seconds"`
+	P95LatencySeconds     float64 `json:"p95_latency_seconds" db:"p95_latency_seconds"`
+	MaxLatencySeconds     float64 `json:"max_latency_seconds" db:"max_latency_seconds"`
+	BreachedCount         int     `json:"breached_count" db:"breached_count"` // Measured refunds slower than the target
+}
+
+// OutstandingRefund is a failed or timed out transaction whose balance was
+// neither released nor refunded yet
+type OutstandingRefund struct {
+	TransactionID string    `json:"transaction_id" db:"transaction_id"`
+	TrxCode       string    `json:"trx_code" db:"trx_code"`
+	UserID        string    `json:"user_id" db:"user_id"`
+	ProductCode   string    `json:"product_code" db:"product_code"`
+	Status        string    `json:"status" db:"status"`
+	Amount        float64   `json:"amount" db:"amount"`
+	FailedAt      time.Time `json:"failed_at" db:"failed_at"`
+	AgeSeconds    int       `json:"age_seconds" db:"-"`
+}
+
+// RefundReport is the refund SLA report of a period
+type RefundReport struct {
+	StartDate     time.Time    `json:"start_date"`
+	EndDate       time.Time    `json:"end_date"` // Exclusive
+	TargetSeconds int          `json:"target_seconds"`
+	Refunds       *RefundStats `json:"refunds"`
+
+	// Outstanding refunds whose failure is older than OlderThanSeconds, oldest first
+	OlderThanSeconds  int                  `json:"older_than_seconds"`
+	OutstandingCount  int                  `json:"outstanding_count"`
+	OutstandingAmount float64              `json:"outstanding_amount"`
+	Outstanding       []*OutstandingRefund `json:"outstanding"`
+}
+
+// RefundSLARepository defines data access for refund SLA tracking, built on
+// the transaction timeline
+type RefundSLARepository interface {
+	// GetRefundStats aggregates refunds completed within [start, end)
+	GetRefundStats(start, end time.Time, target time.Duration) (*RefundStats, error)
+	// ListOutstanding returns outstanding refunds of transactions created
+	// since createdSince that failed before failedBefore, oldest first, with
+	// their total count and amount
+	ListOutstanding(createdSince, failedBefore time.Time, limit int) ([]*OutstandingRefund, int, float64, error)
+	// LastStuckAlertAt returns when the transaction was last alerted as a
+	// stuck refund, or nil when it never was
+	LastStuckAlertAt(transactionID string) (*time.Time, error)
+}
+
+// RefundSLAUsecase defines refund SLA reporting and stuck refund alerting
+type RefundSLAUsecase interface {
+	// GetRefundReport reports refunds completed between the calendar dates of
+	// startDate and endDate (inclusive) and the refunds outstanding for
+	// longer than olderThan; zero uses the stuck threshold
+	GetRefundReport(startDate, endDate time.Time, olderThan time.Duration) (*RefundReport, error)
+	// CheckStuckRefunds alerts outstanding refunds past the stuck threshold
+	// and returns them
+	CheckStuckRefunds() ([]*OutstandingRefund, error)
+}
+
+// RefundStuckEventPayload is the payload of refund.stuck events
+type RefundStuckEventPayload struct {
+	*OutstandingRefund
+	StuckAfterSeconds int `json:"stuck_after_seconds"`
+}
+
+// NewRefundStuckEvent builds the outbox event alerting a stuck refund
+func NewRefundStuckEvent(refund *OutstandingRefund, stuckAfter time.Duration) (*DomainEvent, error) {
+	return NewDomainEvent(EventRefundStuck, AggregateTypeTransaction, refund.TransactionID, &RefundStuckEventPayload{
+		OutstandingRefund: refund,
+		StuckAfterSeconds: int(stuckAfter.Seconds()),
+	})
+}
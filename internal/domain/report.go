@@ -0,0 +1,100 @@
+package domain
+
+import "time"
+
+// SupplierReportRow aggregates transactions routed to one supplier within one period
+type SupplierReportRow struct {
+	Period            time.Time `json:"period" db:"period"`
+	SupplierID        string    `json:"supplier_id" db:"supplier_id"`
+	SupplierCode      string    `json:"supplier_code" db:"supplier_code"`
+	SupplierName      string    `json:"supplier_name" db:"supplier_name"`
+	TotalTransactions int       `json:"total_transactions" db:"total_transactions"`
+	SuccessCount      int       `json:"success_count" db:"success_count"`
+	FailedCount       int       `json:"failed_count" db:"failed_count"` // FAILED and TIMEOUT
+	RefundCount       int       `json:"refund_count" db:"refund_count"`
+	SuccessRatio      float64   `json:"success_ratio" db:"-"`
+	TotalHPP          float64   `json:"total_hpp" db:"total_hpp"`         // Successful transactions only
+	TotalSelling      float64   `json:"total_selling" db:"total_selling"` // Successful transactions only
+	TotalAdminFee     float64   `json:"total_admin_fee" db:"total_admin_fee"`
+	GrossProfit       float64   `json:"gross_profit" db:"gross_profit"`
+	RefundTotal       float64   `json:"refund_total" db:"refund_total"`
+}
+
+// SupplierReport is a profit and settlement report grouped by supplier and period
+type SupplierReport struct {
+	Granularity string               `json:"granularity"`
+	StartDate   time.Time            `json:"start_date"`
+	EndDate     time.Time            `json:"end_date"` // Exclusive
+	Rows        []*SupplierReportRow `json:"rows"`
+	Totals      []*SupplierReportRow `json:"totals"` // Per supplier over the whole range
+	GeneratedAt time.Time            `json:"generated_at"`
+}
+
+// UserDailySummary aggregates one user's activity within a day
+type UserDailySummary struct {
+	UserID            string  `json:"user_id" db:"user_id"`
+	TotalTransactions int     `json:"total_transactions" db:"total_transactions"`
+	SuccessCount      int     `json:"success_count" db:"success_count"`
+	FailedCount       int     `json:"failed_count" db:"failed_count"` // FAILED and TIMEOUT
+	TotalSpent        float64 `json:"total_spent" db:"total_spent"`   // Successful transactions including admin fee
+	TotalDeposit      float64 `json:"total_deposit" db:"total_deposit"`
+	Balance           float64 `json:"balance" db:"balance"` // Current balance
+}
+
+// TagReportRow aggregates the transactions carrying one value of a tag key
+type TagReportRow struct {
+	TagValue          string  `json:"tag_value" db:"tag_value"` // Empty for transactions without the tag
+	TotalTransactions int     `json:"total_transactions" db:"total_transactions"`
+	SuccessCount      int     `json:"success_count" db:"success_count"`
+	FailedCount       int     `json:"failed_count" db:"failed_count"` // FAILED and TIMEOUT
+	RefundCount       int     `json:"refund_count" db:"refund_count"`
+	TotalSelling      float64 `json:"total_selling" db:"total_selling"` // Successful transactions only
+	TotalAdminFee     float64 `json:"total_admin_fee" db:"total_admin_fee"`
+	Profit            float64 `json:"profit" db:"profit"`
+}
+
+// TagReport groups transactions by the values of one tag key
+type TagReport struct {
+	TagKey      string          `json:"tag_key"`
+	UserID      string          `json:"user_id,omitempty"` // Set when limited to one account
+	StartDate   time.Time       `json:"start_date"`
+	EndDate     time.Time       `json:"end_date"` // Exclusive
+	Rows        []*TagReportRow `json:"rows"`
+	GeneratedAt time.Time       `json:"generated_at"`
+}
+
+// ReportRepository defines reporting aggregations over transaction data
+type ReportRepository interface {
+	GetSupplierSummary(startDate, endDate time.Time, granularity, timezone string) ([]*SupplierReportRow, error)
+	// GetUserDailySummaries returns the activity of every user with
+	// transactions or deposits within the range
+	GetUserDailySummaries(startDate, endDate time.Time) ([]*UserDailySummary, error)
+	// GetTagSummary groups the transactions created in [startDate, endDate)
+	// by the value of tagKey, limited to userID unless it is empty
+	GetTagSummary(startDate, endDate time.Time, tagKey, userID string) ([]*TagReportRow, error)
+}
+
+// ReportCacheRepository caches the rows of closed (no longer changing) report periods
+type ReportCacheRepository interface {
+	GetSupplierPeriod(granularity string, period time.Time) ([]*SupplierReportRow, bool, error)
+	SetSupplierPeriod(granularity string, period time.Time, rows []*SupplierReportRow, ttl time.Duration) error
+}
+
+// ReportUsecase defines finance reporting operations
+type ReportUsecase interface {
+	GetSupplierReport(startDate, endDate time.Time, granularity string, refresh bool) (*SupplierReport, error)
+	// GetTagReport groups transactions between the calendar dates of
+	// startDate and endDate (inclusive) by the values of tagKey
+	GetTagReport(startDate, endDate time.Time, tagKey, userID string) (*TagReport, error)
+}
+
+// Report granularities
+const (
+	ReportGranularityDay   = "DAY"
+	ReportGranularityMonth = "MONTH"
+)
+
+// IsValidReportGranularity validates report granularity
+func IsValidReportGranularity(granularity string) bool {
+	return granularity == ReportGranularityDay || granularity == ReportGranularityMonth
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// RetryPolicy tunes the auto-retry of failed transactions for one supplier
+// (or all suppliers when SupplierID is nil) and one error class
+type RetryPolicy struct {
+	ID                string  `json:"id" db:"id"`
+	SupplierID        *string `json:"supplier_id" db:"supplier_id"`   // Nil applies to every supplier
+	ErrorClass        string  `json:"error_class" db:"error_class"`   // TIMEOUT, FAILURE or ANY
+	MaxAttempts       int     `json:"max_attempts" db:"max_attempts"` // 0 disables the retry
+	InitialDelayMs    int     `json:"initial_delay_ms" db:"initial_delay_ms"`
+	MaxDelayMs        int     `json:"max_delay_ms" db:"max_delay_ms"`
+	BackoffMultiplier float64 `json:"backoff_multiplier" db:"backoff_multiplier"`
+	EnableJitter      bool    `json:"enable_jitter" db:"enable_jitter"`
+	IsActive          bool    `json:"is_active" db:"is_active"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// RetryPolicyUpdate holds the fields of a policy to change; nil fields keep
+// their current value
+type RetryPolicyUpdate struct {
+	MaxAttempts       *int
+	InitialDelayMs    *int
+	MaxDelayMs        *int
+	BackoffMultiplier *float64
+	EnableJitter      *bool
+	IsActive          *bool
+}
+
+// RetryPolicyRepository defines operations for retry policy data access
+type RetryPolicyRepository interface {
+	Create(policy *RetryPolicy) error
+	GetByID(id string) (*RetryPolicy, error)
+	Update(policy *RetryPolicy) error
+	Delete(id string) error
+	// List lists policies, optionally of one supplier ("" for all)
+	List(supplierID string) ([]*RetryPolicy, error)
+	ListActive() ([]*RetryPolicy, error)
+}
+
+// RetryPolicyUsecase manages retry policies and resolves the one applying to
+// a failed supplier call
+type RetryPolicyUsecase interface {
+	CreatePolicy(policy *RetryPolicy) error
+	UpdatePolicy(id string, updates *RetryPolicyUpdate) (*RetryPolicy, error)
+	DeletePolicy(id string) error
+	GetPolicy(id string) (*RetryPolicy, error)
+	ListPolicies(supplierID string) ([]*RetryPolicy, error)
+	// ResolvePolicy returns the most specific active policy: supplier and
+	// class, supplier and ANY, every supplier and class, every supplier and
+	// ANY, then the configured defaults (with an empty ID)
+	ResolvePolicy(supplierID, errorClass string) (*RetryPolicy, error)
+}
+
+// Retry error classes
+const (
+	RetryErrorTimeout = "TIMEOUT" // No answer from the supplier (timeout, network error)
+	RetryErrorFailure = "FAILURE" // The supplier answered with a retryable failure
+	RetryErrorAny     = "ANY"
+)
+
+// IsValidRetryErrorClass checks if the retry error class is valid
+func IsValidRetryErrorClass(errorClass string) bool {
+	return errorClass == RetryErrorTimeout || errorClass == RetryErrorFailure || errorClass == RetryErrorAny
+}
+
+// Check validates the limits of the policy
+func (p *RetryPolicy) Check() error {
+	if !IsValidRetryErrorClass(p.ErrorClass) {
+		return fmt.Errorf("invalid retry error class")
+	}
+	if p.MaxAttempts < 0 || p.MaxAttempts > 10 {
+		return fmt.Errorf("max_attempts must be between 0 and 10")
+	}
+	if p.InitialDelayMs < 0 || p.MaxDelayMs < 0 {
+		return fmt.Errorf("delays must not be negative")
+	}
+	if p.MaxDelayMs < p.InitialDelayMs {
+		return fmt.Errorf("max_delay_ms must not be below initial_delay_ms")
+	}
+	if p.BackoffMultiplier < 1 {
+		return fmt.Errorf("backoff_multiplier must be at least 1")
+	}
+	return nil
+}
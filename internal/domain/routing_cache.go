@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// RoutingSnapshot is the routing data of one product: its active mappings and
+// the suppliers they point to, so routing a transaction does not query the
+// database. Supplier metrics in a snapshot may lag by up to the cache TTL.
+type RoutingSnapshot struct {
+	ProductID string            `json:"product_id"`
+	Mappings  []*ProductMapping `json:"mappings"`
+	Suppliers []*Supplier       `json:"suppliers"`
+	CachedAt  time.Time         `json:"cached_at"`
+}
+
+// RoutingCacheRepository caches routing snapshots per product. Snapshots are
+// shared by every replica, so invalidating one takes effect everywhere.
+type RoutingCacheRepository interface {
+	// GetSnapshot returns the cached snapshot of a product, or nil on a miss
+	GetSnapshot(productID string) (*RoutingSnapshot, error)
+	SetSnapshot(snapshot *RoutingSnapshot, ttl time.Duration) error
+	// InvalidateProducts drops the snapshots of the products
+	InvalidateProducts(productIDs ...string) error
+	// InvalidateSupplier drops the snapshot of every product mapped to the supplier
+	InvalidateSupplier(supplierID string) error
+}
@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+// RoutingDecision records which supplier smart routing chose for a
+// transaction, how confident it was and which suppliers it passed over
+type RoutingDecision struct {
+	ID                  string             `json:"id" db:"id"`
+	TransactionID       string             `json:"transaction_id" db:"transaction_id"`
+	Source              string             `json:"source" db:"source"` // ROUTING, FAILOVER or ADMIN
+	SupplierID          string             `json:"supplier_id" db:"supplier_id"`
+	SupplierCode        string             `json:"supplier_code" db:"supplier_code"`
+	SupplierProductCode string             `json:"supplier_product_code" db:"supplier_product_code"`
+	SupplierPrice       float64            `json:"supplier_price" db:"supplier_price"`
+	Score               float64            `json:"score" db:"score"`
+	Confidence          float64            `json:"confidence" db:"confidence"` // 0.0 to 1.0
+	Reason              string             `json:"reason" db:"reason"`
+	Alternatives        []RoutingCandidate `json:"alternatives" db:"-"`
+	AlternativesJSON    string             `json:"-" db:"alternatives"` // JSON encoded alternatives
+	CreatedAt           time.Time          `json:"created_at" db:"created_at"`
+}
+
+// RoutingCandidate is a supplier that was scored but not chosen
+type RoutingCandidate struct {
+	SupplierID   string  `json:"supplier_id"`
+	SupplierCode string  `json:"supplier_code"`
+	Score        float64 `json:"score"`
+	Confidence   float64 `json:"confidence"`
+	Reason       string  `json:"reason"`
+}
+
+// Routing decision sources
+const (
+	RoutingSourceRouting  = "ROUTING"  // Supplier chosen when processing starts
+	RoutingSourceFailover = "FAILOVER" // Supplier chosen after the previous one failed
+	RoutingSourceAdmin    = "ADMIN"    // Supplier picked by an admin reprocessing the transaction
+)
+
+// RoutingDecisionRepository defines operations for routing decision data access
+type RoutingDecisionRepository interface {
+	Create(decision *RoutingDecision) error
+	ListByTransactionID(transactionID string) ([]*RoutingDecision, error)
+}
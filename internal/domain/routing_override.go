@@ -0,0 +1,132 @@
+package domain
+
+import "time"
+
+// RoutingOverride forces (PIN) or forbids (EXCLUDE) a supplier for a product or category.
+//
+// Precedence rules applied by smart routing before scoring:
+//  1. EXCLUDE always wins over PIN for the same supplier.
+//  2. Exclusions from product and category scope are both applied.
+//  3. Product-scoped pins replace category-scoped pins.
+//  4. When pins exist only pinned suppliers are eligible; if none of them is
+//     usable routing fails instead of falling back to other suppliers.
+type RoutingOverride struct {
+	ID         string  `json:"id" db:"id"`
+	ScopeType  string  `json:"scope_type" db:"scope_type"`   // PRODUCT or CATEGORY
+	ScopeValue string  `json:"scope_value" db:"scope_value"` // Product ID or category code
+	SupplierID string  `json:"supplier_id" db:"supplier_id"`
+	Action     string  `json:"action" db:"action"` // PIN or EXCLUDE
+	Reason     *string `json:"reason" db:"reason"`
+	IsActive   bool    `json:"is_active" db:"is_active"`
+	CreatedBy  *string `json:"created_by" db:"created_by"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// RoutingOverrideRepository defines operations for routing override data access
+type RoutingOverrideRepository interface {
+	Create(override *RoutingOverride) error
+	GetByID(id string) (*RoutingOverride, error)
+	Update(override *RoutingOverride) error
+	Delete(id string) error
+	List(scopeType, scopeValue string) ([]*RoutingOverride, error)
+	GetActiveForProduct(productID, category string) ([]*RoutingOverride, error)
+}
+
+// RoutingOverrideUsecase defines business logic operations for routing overrides
+type RoutingOverrideUsecase interface {
+	CreateOverride(override *RoutingOverride) error
+	UpdateOverride(id string, isActive *bool, reason *string) (*RoutingOverride, error)
+	DeleteOverride(id string) error
+	GetOverride(id string) (*RoutingOverride, error)
+	ListOverrides(scopeType, scopeValue string) ([]*RoutingOverride, error)
+}
+
+// RoutingPolicy is the resolved set of overrides for a single product
+type RoutingPolicy struct {
+	PinnedSupplierIDs   map[string]bool
+	ExcludedSupplierIDs map[string]bool
+	PinScope            string // Scope the effective pins come from
+}
+
+// Routing override constants
+const (
+	OverrideScopeProduct  = "PRODUCT"
+	OverrideScopeCategory = "CATEGORY"
+
+	OverrideActionPin     = "PIN"
+	OverrideActionExclude = "EXCLUDE"
+)
+
+// IsValidOverrideScope checks if the override scope type is valid
+func IsValidOverrideScope(scopeType string) bool {
+	return scopeType == OverrideScopeProduct || scopeType == OverrideScopeCategory
+}
+
+// IsValidOverrideAction checks if the override action is valid
+func IsValidOverrideAction(action string) bool {
+	return action == OverrideActionPin || action == OverrideActionExclude
+}
+
+// ResolveRoutingPolicy applies override precedence rules to active overrides of a product
+func ResolveRoutingPolicy(overrides []*RoutingOverride) *RoutingPolicy {
+	policy := &RoutingPolicy{
+		PinnedSupplierIDs:   make(map[string]bool),
+		ExcludedSupplierIDs: make(map[string]bool),
+	}
+
+	productPins := make(map[string]bool)
+	categoryPins := make(map[string]bool)
+
+	for _, o := range overrides {
+		if !o.IsActive {
+			continue
+		}
+		switch o.Action {
+		case OverrideActionExclude:
+			policy.ExcludedSupplierIDs[o.SupplierID] = true
+		case OverrideActionPin:
+			if o.ScopeType == OverrideScopeProduct {
+				productPins[o.SupplierID] = true
+			} else {
+				categoryPins[o.SupplierID] = true
+			}
+		}
+	}
+
+	pins := categoryPins
+	policy.PinScope = OverrideScopeCategory
+	if len(productPins) > 0 {
+		pins = productPins
+		policy.PinScope = OverrideScopeProduct
+	}
+
+	for supplierID := range pins {
+		if !policy.ExcludedSupplierIDs[supplierID] {
+			policy.PinnedSupplierIDs[supplierID] = true
+		}
+	}
+	if len(pins) == 0 {
+		policy.PinScope = ""
+	}
+
+	return policy
+}
+
+// HasPins reports whether the policy restricts routing to pinned suppliers
+func (p *RoutingPolicy) HasPins() bool {
+	return p.PinScope != ""
+}
+
+// Allows reports whether the supplier may be used under this policy
+func (p *RoutingPolicy) Allows(supplierID string) bool {
+	if p.ExcludedSupplierIDs[supplierID] {
+		return false
+	}
+	if p.HasPins() {
+		return p.PinnedSupplierIDs[supplierID]
+	}
+	return true
+}
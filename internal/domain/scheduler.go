@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// JobRun records the outcome of one scheduled job execution
+type JobRun struct {
+	JobName    string    `json:"job_name"`
+	Instance   string    `json:"instance"` // Replica that ran the job
+	Status     string    `json:"status"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	DurationMs int64     `json:"duration_ms"`
+	Error      *string   `json:"error,omitempty"`
+}
+
+// JobStatus describes a registered scheduled job
+type JobStatus struct {
+	Name      string    `json:"name"`
+	Schedule  string    `json:"schedule"`
+	NextRunAt time.Time `json:"next_run_at"`
+	Running   bool      `json:"running"` // Running on this replica
+	LastRun   *JobRun   `json:"last_run"`
+}
+
+// SchedulerRepository coordinates scheduled jobs across replicas
+type SchedulerRepository interface {
+	// AcquireJobLock claims one activation (runKey) of a job; it returns false
+	// when another replica already claimed it
+	AcquireJobLock(jobName, runKey, owner string, ttl time.Duration) (bool, error)
+	SaveJobRun(run *JobRun) error
+	GetJobRun(jobName string) (*JobRun, error)
+}
+
+// JobScheduler exposes the state of scheduled jobs
+type JobScheduler interface {
+	ListJobs() ([]*JobStatus, error)
+}
+
+// Job run statuses
+const (
+	JobRunSuccess = "SUCCESS"
+	JobRunFailed  = "FAILED"
+	JobRunSkipped = "SKIPPED" // Activation claimed by another replica
+)
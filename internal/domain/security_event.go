@@ -0,0 +1,65 @@
+package domain
+
+import "time"
+
+// SecurityEvent is a structured security audit record, e.g. a role-guard denial
+type SecurityEvent struct {
+	ID          string    `json:"id" db:"id"`
+	EventType   string    `json:"event_type" db:"event_type"`
+	Reason      string    `json:"reason" db:"reason"`
+	UserID      *string   `json:"user_id" db:"user_id"`
+	UserRole    *string   `json:"user_role" db:"user_role"`
+	IPAddress   string    `json:"ip_address" db:"ip_address"`
+	Method      string    `json:"method" db:"method"`
+	Resource    string    `json:"resource" db:"resource"`       // Route path that was denied
+	Requirement *string   `json:"requirement" db:"requirement"` // Required role/level, when applicable
+	UserAgent   *string   `json:"user_agent" db:"user_agent"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// SecurityEventFilter filters security event listings
+type SecurityEventFilter struct {
+	EventType *string
+	UserID    *string
+	IPAddress *string
+	Since     *time.Time
+	Limit     int
+}
+
+// SecurityEventRepository defines operations for security event data access
+type SecurityEventRepository interface {
+	Create(event *SecurityEvent) error
+	List(filter *SecurityEventFilter) ([]*SecurityEvent, error)
+	CountSince(eventType string, userID *string, ipAddress string, since time.Time) (int, error)
+}
+
+// SecurityEventUsecase records security events and raises burst alerts
+type SecurityEventUsecase interface {
+	RecordDenial(event *SecurityEvent) error
+	ListEvents(filter *SecurityEventFilter) ([]*SecurityEvent, error)
+}
+
+// SecurityBurstPayload is the payload of a denial burst alert
+type SecurityBurstPayload struct {
+	UserID     *string   `json:"user_id,omitempty"`
+	IPAddress  string    `json:"ip_address"`
+	Denials    int       `json:"denials"`
+	Window     string    `json:"window"`
+	LastReason string    `json:"last_reason"`
+	LastPath   string    `json:"last_resource"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// Security event constants
+const (
+	SecurityEventAccessDenied = "ACCESS_DENIED"
+
+	DenialUnauthenticated   = "UNAUTHENTICATED"
+	DenialInsufficientRole  = "INSUFFICIENT_ROLE"
+	DenialInsufficientLevel = "INSUFFICIENT_LEVEL"
+	DenialForeignResource   = "FOREIGN_RESOURCE"  // Accessing another user's data
+	DenialInvalidSignature  = "INVALID_SIGNATURE" // Unsigned or badly signed sensitive admin request
+
+	EventSecurityDenialBurst = "security.denial_burst"
+	AggregateTypeSecurity    = "SECURITY"
+)
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Statement is a user's monthly account statement
+type Statement struct {
+	User           *User          `json:"-"`
+	Year           int            `json:"year"`
+	Month          int            `json:"month"`
+	PeriodStart    time.Time      `json:"period_start"`
+	PeriodEnd      time.Time      `json:"period_end"` // Exclusive
+	OpeningBalance float64        `json:"opening_balance"`
+	ClosingBalance float64        `json:"closing_balance"`
+	TotalDebit     float64        `json:"total_debit"`  // Money in
+	TotalCredit    float64        `json:"total_credit"` // Money out
+	Mutations      []*Mutation    `json:"mutations"`
+	Transactions   []*Transaction `json:"transactions"`
+	GeneratedAt    time.Time      `json:"generated_at"`
+}
+
+// StatementJob tracks asynchronous generation of a statement file
+type StatementJob struct {
+	ID           string     `json:"id" db:"id"`
+	UserID       string     `json:"user_id" db:"user_id"`
+	Year         int        `json:"year" db:"period_year"`
+	Month        int        `json:"month" db:"period_month"`
+	Format       string     `json:"format" db:"format"`
+	Status       string     `json:"status" db:"status"`
+	RowCount     int        `json:"row_count" db:"row_count"`
+	Attempts     int        `json:"attempts" db:"attempts"`
+	FileName     *string    `json:"file_name" db:"file_name"`
+	ErrorMessage *string    `json:"error_message,omitempty" db:"error_message"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// StatementFile is a rendered statement
+type StatementFile struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+// StatementResult is either a file generated on the spot or a queued job
+// for accounts too large to render within the request
+type StatementResult struct {
+	File *StatementFile
+	Job  *StatementJob
+}
+
+// StatementRepository defines data access for account statements
+type StatementRepository interface {
+	CountEntries(userID string, start, end time.Time) (int, error)
+	GetOpeningBalance(userID string, start time.Time) (float64, error)
+	ListMutations(userID string, start, end time.Time) ([]*Mutation, error)
+	ListTransactions(userID string, start, end time.Time) ([]*Transaction, error)
+
+	CreateJob(job *StatementJob) error
+	GetJob(id string) (*StatementJob, error)
+	GetLatestJob(userID string, year, month int, format string) (*StatementJob, error)
+	// ClaimJobs marks up to limit pending jobs, and processing jobs not
+	// updated within staleAfter, as processing and returns them
+	ClaimJobs(limit int, staleAfter time.Duration) ([]*StatementJob, error)
+	CompleteJob(id, fileName string, rowCount int, content []byte) error
+	FailJob(id, message string) error
+	GetJobContent(id string) ([]byte, error)
+}
+
+// StatementUsecase defines account statement operations
+type StatementUsecase interface {
+	RequestStatement(userID string, year, month int, format string) (*StatementResult, error)
+	GetJob(userID, jobID string) (*StatementJob, error)
+	DownloadJob(userID, jobID string) (*StatementFile, error)
+	ProcessPendingJobs(ctx context.Context) (int, error)
+}
+
+// Statement formats
+const (
+	StatementFormatPDF = "PDF"
+	StatementFormatCSV = "CSV"
+)
+
+// Statement job statuses
+const (
+	StatementJobPending    = "PENDING"
+	StatementJobProcessing = "PROCESSING"
+	StatementJobReady      = "READY"
+	StatementJobFailed     = "FAILED"
+)
+
+// IsValidStatementFormat validates a statement format
+func IsValidStatementFormat(format string) bool {
+	return format == StatementFormatPDF || format == StatementFormatCSV
+}
@@ -0,0 +1,118 @@
+package domain
+
+import "time"
+
+// Operational statuses of the public status page, from best to worst
+const (
+	ServiceOperational = "operational"
+	ServiceDegraded    = "degraded"
+	ServiceDown        = "down"
+)
+
+// serviceSeverity orders the statuses so the worst can be picked
+var serviceSeverity = map[string]int{
+	ServiceOperational: 0,
+	ServiceDegraded:    1,
+	ServiceDown:        2,
+}
+
+// WorseStatus returns the worse of two operational statuses
+func WorseStatus(a, b string) string {
+	if serviceSeverity[b] > serviceSeverity[a] {
+		return b
+	}
+	return a
+}
+
+// IsValidIncidentImpact checks that an incident impact is degraded or down
+func IsValidIncidentImpact(impact string) bool {
+	return impact == ServiceDegraded || impact == ServiceDown
+}
+
+// CategoryOutcome counts the transactions of one product category within a
+// rolling window, by their final outcome
+type CategoryOutcome struct {
+	Category     string `db:"category"`
+	Total        int    `db:"total"`
+	SuccessCount int    `db:"success_count"`
+	FailedCount  int    `db:"failed_count"`
+}
+
+// StatusIncident is an admin note about an ongoing or past disruption. It
+// affects the listed categories, or every category when none is listed.
+type StatusIncident struct {
+	ID         string     `json:"id"`
+	Title      string     `json:"title"`
+	Message    string     `json:"message"`
+	Impact     string     `json:"impact"` // degraded or down
+	Categories []string   `json:"categories"`
+	StartedAt  time.Time  `json:"started_at"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+	CreatedBy  *string    `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Affects reports whether the incident concerns the category
+func (i *StatusIncident) Affects(category string) bool {
+	if len(i.Categories) == 0 {
+		return true
+	}
+	for _, code := range i.Categories {
+		if code == category {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusIncidentUpdate holds the fields of an incident to change; nil fields
+// keep their current value
+type StatusIncidentUpdate struct {
+	Title      *string
+	Message    *string
+	Impact     *string
+	Categories *[]string
+	Resolved   *bool // true resolves now, false reopens
+}
+
+// CategoryStatus is the operational status of one product category
+type CategoryStatus struct {
+	Code        string   `json:"code"`
+	Name        string   `json:"name"`
+	Status      string   `json:"status"`
+	SuccessRate *float64 `json:"success_rate"` // Nil without enough recent transactions
+	Samples     int      `json:"samples"`
+}
+
+// PublicStatus is the data behind the public status page
+type PublicStatus struct {
+	Status      string            `json:"status"`       // Worst category status
+	QueueStatus string            `json:"queue_status"` // From the backlog of orders waiting for a worker
+	Categories  []*CategoryStatus `json:"categories"`
+	Incidents   []*StatusIncident `json:"incidents"` // Open ones and those resolved recently
+	Window      string            `json:"window"`    // Period the success rates cover
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// StatusIncidentRepository defines operations for status incident data access
+type StatusIncidentRepository interface {
+	Create(incident *StatusIncident) error
+	GetByID(id string) (*StatusIncident, error)
+	Update(incident *StatusIncident) error
+	// List returns incidents open or resolved since the given time, newest first
+	List(since time.Time, limit int) ([]*StatusIncident, error)
+	// GetCategoryOutcomes counts the transactions created within [start, end)
+	// per product category
+	GetCategoryOutcomes(start, end time.Time) ([]*CategoryOutcome, error)
+}
+
+// StatusPageUsecase builds the public status page and manages its incidents
+type StatusPageUsecase interface {
+	// GetPublicStatus returns the current status, computed at most once per
+	// cache period
+	GetPublicStatus() (*PublicStatus, error)
+	CreateIncident(incident *StatusIncident) error
+	UpdateIncident(id string, updates *StatusIncidentUpdate) (*StatusIncident, error)
+	ListIncidents(limit int) ([]*StatusIncident, error)
+}
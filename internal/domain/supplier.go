@@ -1,6 +1,10 @@
 package domain
 
 import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
 	"time"
 )
 
@@ -11,11 +15,17 @@ type Supplier struct {
 	Code string `json:"code" db:"code"`
 
 	// API Configuration
-	APIURL      string  `json:"api_url" db:"api_url"`
-	APIKey      *string `json:"api_key" db:"api_key"`
-	APISecret   *string `json:"api_secret" db:"api_secret"`
-	APIUsername *string `json:"api_username" db:"api_username"`
-	APIPassword *string `json:"api_password" db:"api_password"`
+	APIURL        string  `json:"api_url" db:"api_url"`
+	APIKey        *string `json:"api_key" db:"api_key"`
+	APISecret     *string `json:"api_secret" db:"api_secret"`
+	APIUsername   *string `json:"api_username" db:"api_username"`
+	APIPassword   *string `json:"api_password" db:"api_password"`
+	AdapterType   *string `json:"adapter_type" db:"adapter_type"` // Adapter implementation (NULL = same as code)
+	SignMethod    *string `json:"sign_method" db:"sign_method"`   // Request signing method (NULL = adapter default)
+	WebhookSecret *string `json:"-" db:"webhook_secret"`          // HMAC key of inbound webhooks (NULL = webhooks rejected)
+
+	// RequestRules rewrite destinations into the format the supplier expects
+	RequestRules SupplierRequestRules `json:"request_rules" db:"request_rules"`
 
 	// Supplier status and settings
 	IsActive       bool `json:"is_active" db:"is_active"`
@@ -33,6 +43,12 @@ type Supplier struct {
 	TotalTransactions  int     `json:"total_transactions" db:"total_transactions"`
 	FailedTransactions int     `json:"failed_transactions" db:"failed_transactions"`
 
+	// Reachability from the periodic ping (see SupplierAdapter.Ping)
+	IsReachable       bool       `json:"is_reachable" db:"is_reachable"`
+	PingFailures      int        `json:"ping_failures" db:"ping_failures"` // Consecutive failed pings
+	LastPingLatencyMs *int       `json:"last_ping_latency_ms" db:"last_ping_latency_ms"`
+	LastPingedAt      *time.Time `json:"last_pinged_at" db:"last_pinged_at"`
+
 	// Timestamps
 	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
@@ -44,6 +60,8 @@ type Supplier struct {
 type SupplierRepository interface {
 	Create(supplier *Supplier) error
 	GetByID(id string) (*Supplier, error)
+	// GetByIDs fetches several suppliers in one query; unknown IDs are skipped
+	GetByIDs(ids []string) ([]*Supplier, error)
 	GetByCode(code string) (*Supplier, error)
 	Update(supplier *Supplier) error
 	Delete(id string) error
@@ -52,6 +70,11 @@ type SupplierRepository interface {
 	UpdateMetrics(id string, success bool, responseTimeMs int) error
 	GetBalance(id string) (float64, error)
 	UpdateBalance(id string, newBalance float64) error
+	// RecordProbeLatency blends an active probe latency into avg_response_time_ms
+	RecordProbeLatency(id string, responseTimeMs int) error
+	// RecordPing stores a ping result; the supplier turns unreachable after
+	// unreachableAfter consecutive failures and reachable on a success
+	RecordPing(id string, success bool, latencyMs, unreachableAfter int) error
 }
 
 // SupplierUsecase defines business logic operations for suppliers
@@ -74,6 +97,17 @@ type SupplierRequest struct {
 	DestinationNumber string            `json:"destination_number"`
 	RefID             string            `json:"ref_id"`
 	AdditionalData    map[string]string `json:"additional_data,omitempty"`
+	// Deadline is set when the call runs inside a request that must answer by then
+	Deadline time.Time `json:"-"`
+}
+
+// Timeout returns the supplier's own timeout, shortened to what is left
+// before the request deadline
+func (r *SupplierRequest) Timeout(supplierTimeout time.Duration) time.Duration {
+	if r.Deadline.IsZero() {
+		return supplierTimeout
+	}
+	return min(supplierTimeout, time.Until(r.Deadline))
 }
 
 // SupplierResponse represents a response from supplier API
@@ -85,21 +119,146 @@ type SupplierResponse struct {
 	StatusCode   int                    `json:"status_code"`
 	ResponseTime int                    `json:"response_time_ms"`
 	Data         map[string]interface{} `json:"data,omitempty"`
+
+	// Supplier result code and how it must be handled (see SupplierResult* constants)
+	ResponseCode   string `json:"response_code,omitempty"`
+	Classification string `json:"classification,omitempty"`
+}
+
+// Supplier result classifications
+const (
+	SupplierResultSuccess   = "SUCCESS"
+	SupplierResultPending   = "PENDING"   // Still processed by supplier; do not retry, fail over or refund
+	SupplierResultRetryable = "RETRYABLE" // Failed at this supplier; another attempt or supplier may succeed
+	SupplierResultPermanent = "PERMANENT" // Failed for the request itself (e.g. wrong number); refund without retrying
+)
+
+// IsPending reports whether the supplier is still processing the request
+func (r *SupplierResponse) IsPending() bool {
+	return r.Classification == SupplierResultPending
+}
+
+// IsPermanentFailure reports whether retrying or failing over cannot succeed
+func (r *SupplierResponse) IsPermanentFailure() bool {
+	return r.Classification == SupplierResultPermanent
 }
 
 // SupplierAdapter defines the interface for supplier integrations
 type SupplierAdapter interface {
 	TopUp(request *SupplierRequest) (*SupplierResponse, error)
 	CheckBalance() (float64, error)
+	// Ping checks that the supplier answers, using the cheapest call it
+	// offers, once and without retries
+	Ping() error
 	CheckStatus(trxID string) (*SupplierResponse, error)
 	GetProductCatalog() ([]*Product, error)
 	ParseResponse(response []byte) (*SupplierResponse, error)
 }
 
-// SupplierAdapterFactory resolves supplier adapters by supplier code
+// PostpaidSupplierAdapter is implemented by adapters that also serve postpaid
+// (pasca-bayar) bills such as PLN, PDAM and BPJS. A bill is inquired first and
+// paid with the same RefID; detect support with AsPostpaidAdapter.
+type PostpaidSupplierAdapter interface {
+	SupplierAdapter
+	CheckBill(request *SupplierRequest) (*BillInquiry, error)
+	PayBill(request *SupplierRequest) (*SupplierResponse, error)
+}
+
+// AsPostpaidAdapter returns the adapter's postpaid capability, if it has one
+func AsPostpaidAdapter(adapter SupplierAdapter) (PostpaidSupplierAdapter, bool) {
+	postpaid, ok := adapter.(PostpaidSupplierAdapter)
+	return postpaid, ok
+}
+
+// BillInquiry is the outstanding bill of a postpaid customer as reported by
+// the supplier. Amount excludes the admin fee; Price is what the supplier
+// charges us to pay the whole bill.
+type BillInquiry struct {
+	RefID        string            `json:"ref_id"`
+	ProductCode  string            `json:"product_code"`
+	CustomerNo   string            `json:"customer_no"`
+	CustomerName string            `json:"customer_name"`
+	Amount       float64           `json:"amount"`
+	AdminFee     float64           `json:"admin_fee"`
+	Penalty      float64           `json:"penalty"`
+	Price        float64           `json:"price"`
+	SellingPrice float64           `json:"selling_price"`
+	Periods      []*BillPeriod     `json:"periods,omitempty"`
+	Details      map[string]string `json:"details,omitempty"` // Product specific fields, e.g. tariff and power
+
+	Success        bool   `json:"success"`
+	Message        string `json:"message"`
+	ResponseCode   string `json:"response_code,omitempty"`
+	Classification string `json:"classification,omitempty"`
+}
+
+// BillPeriod is one billed period (usually a month) of a postpaid bill
+type BillPeriod struct {
+	Period   string  `json:"period"`
+	Amount   float64 `json:"amount"`
+	AdminFee float64 `json:"admin_fee"`
+	Penalty  float64 `json:"penalty"`
+	Other    float64 `json:"other,omitempty"`
+}
+
+// SupplierAdapterBuilder creates an adapter bound to the credentials of one supplier record
+type SupplierAdapterBuilder func(supplier *Supplier) (SupplierAdapter, error)
+
+// SupplierAdapterFactory resolves supplier adapters by supplier code. Adapters
+// registered with RegisterBuilder are built per supplier record, so several
+// suppliers (accounts) can share one adapter type.
 type SupplierAdapterFactory interface {
 	RegisterAdapter(code string, adapter SupplierAdapter)
+	RegisterBuilder(adapterType string, builder SupplierAdapterBuilder)
 	GetAdapter(code string) (SupplierAdapter, error)
+	GetSupplierAdapter(supplier *Supplier) (SupplierAdapter, error)
+	// HasBuilder reports whether adapters of the type can be built
+	HasBuilder(adapterType string) bool
+	// Load builds the adapter of a supplier record now, replacing the one
+	// built from its previous settings; the error tells why it cannot go live
+	Load(supplier *Supplier) (SupplierAdapter, error)
+	// Unload drops the adapter built for a supplier
+	Unload(supplierID string)
+}
+
+// SupplierUpdate holds the supplier settings to change; nil fields keep their
+// current value and an empty credential, sign method or adapter type clears it
+type SupplierUpdate struct {
+	Name                *string
+	APIURL              *string
+	APIKey              *string
+	APISecret           *string
+	APIUsername         *string
+	APIPassword         *string
+	AdapterType         *string
+	SignMethod          *string
+	WebhookSecret       *string
+	IsActive            *bool
+	Priority            *int
+	TimeoutSeconds      *int
+	RetryAttempts       *int
+	MinBalanceThreshold *float64
+	RequestRules        *SupplierRequestRules
+}
+
+// SupplierRegistryUsecase manages supplier accounts and keeps their adapters
+// live, so a new account of a supported adapter type needs no deploy
+type SupplierRegistryUsecase interface {
+	// LoadAdapters builds the adapters of every active supplier at startup.
+	// Suppliers whose adapter cannot be built are logged and skipped.
+	LoadAdapters() (int, error)
+
+	CreateSupplier(supplier *Supplier) error
+	UpdateSupplier(id string, updates *SupplierUpdate) (*Supplier, error)
+	GetSupplier(id string) (*Supplier, error)
+	ListSuppliers() ([]*Supplier, error)
+	// PreviewRequestRules returns the destination the supplier would receive
+	// for the product, under the given rules or, when nil, the stored ones
+	PreviewRequestRules(id string, rules *SupplierRequestRules, productCode, destination string) (string, error)
+	// CopyMappings maps the products of the source account to the target
+	// account of the same adapter type and returns how many were copied;
+	// products the target already maps are kept as they are
+	CopyMappings(targetID, sourceID string) (int, error)
 }
 
 // Supplier validation constants
@@ -128,6 +287,58 @@ func IsValidSupplierCode(code string) bool {
 	return false
 }
 
+// Codes name the sandbox fixture directory too, so they stay path safe
+var supplierCodePattern = regexp.MustCompile(`^[A-Z0-9_]{2,20}$`)
+
+// Check validates the supplier settings an admin can change
+func (s *Supplier) Check() error {
+	if !supplierCodePattern.MatchString(s.Code) {
+		return fmt.Errorf("code must be 2-20 upper case letters, digits or _")
+	}
+	if strings.TrimSpace(s.Name) == "" || len(s.Name) > 100 {
+		return fmt.Errorf("name is required and must not exceed 100 characters")
+	}
+	if parsed, err := url.Parse(s.APIURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("api_url must be an http or https URL")
+	}
+	if len(s.APIURL) > 255 {
+		return fmt.Errorf("api_url must not exceed 255 characters")
+	}
+	if s.AdapterType != nil && !supplierCodePattern.MatchString(*s.AdapterType) {
+		return fmt.Errorf("adapter_type must be 2-20 upper case letters, digits or _")
+	}
+	if s.Priority < 1 {
+		return fmt.Errorf("priority must be at least 1")
+	}
+	if s.TimeoutSeconds < 1 || s.TimeoutSeconds > 300 {
+		return fmt.Errorf("timeout_seconds must be between 1 and 300")
+	}
+	if s.RetryAttempts < 0 || s.RetryAttempts > 10 {
+		return fmt.Errorf("retry_attempts must be between 0 and 10")
+	}
+	if s.MinBalanceThreshold < 0 {
+		return fmt.Errorf("min_balance_threshold must not be negative")
+	}
+	if _, err := s.RequestRules.Compile(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetAdapterType returns the adapter implementation used by the supplier
+func (s *Supplier) GetAdapterType() string {
+	if s.AdapterType != nil && *s.AdapterType != "" {
+		return *s.AdapterType
+	}
+	return s.Code
+}
+
+// IsAccountOf reports whether both suppliers are accounts of the same
+// supplier, i.e. they share an adapter type
+func (s *Supplier) IsAccountOf(other *Supplier) bool {
+	return strings.EqualFold(s.GetAdapterType(), other.GetAdapterType())
+}
+
 // IsHealthy checks if the supplier is healthy based on metrics
 func (s *Supplier) IsHealthy() bool {
 	if !s.IsActive {
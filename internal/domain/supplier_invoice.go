@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"io"
+	"time"
+)
+
+// SupplierInvoice is a recap file a supplier sent, matched line by line
+// against our transactions. The counts and totals summarise the match for
+// finance.
+type SupplierInvoice struct {
+	ID           string     `json:"id" db:"id"`
+	SupplierID   string     `json:"supplier_id" db:"supplier_id"`
+	SupplierCode string     `json:"supplier_code" db:"supplier_code"`
+	FileName     string     `json:"file_name" db:"file_name"`
+	PeriodStart  *time.Time `json:"period_start" db:"period_start"` // Inclusive days; without a period no UNBILLED lines are searched
+	PeriodEnd    *time.Time `json:"period_end" db:"period_end"`
+
+	LineCount           int `json:"line_count" db:"line_count"` // Lines read from the file
+	MatchedCount        int `json:"matched_count" db:"matched_count"`
+	MissingCount        int `json:"missing_count" db:"missing_count"`
+	AmountMismatchCount int `json:"amount_mismatch_count" db:"amount_mismatch_count"`
+	StatusMismatchCount int `json:"status_mismatch_count" db:"status_mismatch_count"`
+	DuplicateCount      int `json:"duplicate_count" db:"duplicate_count"`
+	UnbilledCount       int `json:"unbilled_count" db:"unbilled_count"`
+
+	// InvoicedTotal is what the supplier bills; ExpectedTotal what our
+	// successful transactions cost at the supplier (HPP), billed or not.
+	// Difference is invoiced minus expected: positive means billed too much.
+	InvoicedTotal float64 `json:"invoiced_total" db:"invoiced_total"`
+	ExpectedTotal float64 `json:"expected_total" db:"expected_total"`
+	Difference    float64 `json:"difference" db:"difference"`
+
+	UploadedBy *string   `json:"uploaded_by" db:"uploaded_by"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// SupplierInvoiceLine is one line of an invoice with its match result
+type SupplierInvoiceLine struct {
+	ID                string   `json:"id" db:"id"`
+	InvoiceID         string   `json:"invoice_id" db:"invoice_id"`
+	LineNumber        int      `json:"line_number" db:"line_number"` // File line, 0 for UNBILLED
+	SupplierTrxID     *string  `json:"supplier_trx_id" db:"supplier_trx_id"`
+	RefID             *string  `json:"ref_id" db:"ref_id"`
+	ProductCode       *string  `json:"product_code" db:"product_code"`
+	Destination       *string  `json:"destination" db:"destination"`
+	InvoicedStatus    *string  `json:"invoiced_status" db:"invoiced_status"`
+	InvoicedAmount    *float64 `json:"invoiced_amount" db:"invoiced_amount"`
+	TransactionID     *string  `json:"transaction_id" db:"transaction_id"`
+	TrxCode           *string  `json:"trx_code" db:"trx_code"`
+	TransactionStatus *string  `json:"transaction_status" db:"transaction_status"`
+	ExpectedAmount    *float64 `json:"expected_amount" db:"expected_amount"` // Transaction HPP
+	Result            string   `json:"result" db:"result"`
+	Note              *string  `json:"note" db:"note"`
+}
+
+// InvoiceTransaction is the part of a transaction an invoice line is matched on
+type InvoiceTransaction struct {
+	ID                string    `db:"id"`
+	TrxCode           string    `db:"trx_code"`
+	SupplierID        *string   `db:"supplier_id"`
+	SupplierTrxID     *string   `db:"supplier_trx_id"`
+	ProductCode       string    `db:"product_code"`
+	DestinationNumber string    `db:"destination_number"`
+	Status            string    `db:"status"`
+	HPP               float64   `db:"hpp"`
+	CreatedAt         time.Time `db:"created_at"`
+}
+
+// Invoice line match results
+const (
+	InvoiceLineMatched        = "MATCHED"
+	InvoiceLineMissing        = "MISSING"         // Billed, but no transaction of ours
+	InvoiceLineAmountMismatch = "AMOUNT_MISMATCH" // Billed amount differs from the HPP
+	InvoiceLineStatusMismatch = "STATUS_MISMATCH" // Billed, but our transaction did not succeed
+	InvoiceLineDuplicate      = "DUPLICATE"       // Transaction already billed on an earlier line
+	InvoiceLineUnbilled       = "UNBILLED"        // Our successful transaction the file leaves out
+)
+
+// IsValidInvoiceLineResult checks if the invoice line result is valid
+func IsValidInvoiceLineResult(result string) bool {
+	switch result {
+	case InvoiceLineMatched, InvoiceLineMissing, InvoiceLineAmountMismatch,
+		InvoiceLineStatusMismatch, InvoiceLineDuplicate, InvoiceLineUnbilled:
+		return true
+	}
+	return false
+}
+
+// SupplierInvoiceUpload is a recap file to ingest. Period bounds are
+// optional inclusive days.
+type SupplierInvoiceUpload struct {
+	SupplierID  string
+	FileName    string
+	File        io.Reader
+	PeriodStart *time.Time
+	PeriodEnd   *time.Time
+	UploadedBy  *string
+}
+
+// SupplierInvoiceRepository defines operations for supplier invoice data access
+type SupplierInvoiceRepository interface {
+	// Create stores the invoice and its lines in one database transaction
+	Create(invoice *SupplierInvoice, lines []*SupplierInvoiceLine) error
+	GetByID(id string) (*SupplierInvoice, error)
+	List(supplierID string, limit, offset int) ([]*SupplierInvoice, error)
+	// ListLines returns the lines of an invoice in file order, UNBILLED last;
+	// an empty result lists every line
+	ListLines(invoiceID, result string) ([]*SupplierInvoiceLine, error)
+	// FindTransactions returns the transactions whose trx_code is one of
+	// refIDs, or routed to the supplier with one of supplierTrxIDs
+	FindTransactions(supplierID string, supplierTrxIDs, refIDs []string) ([]*InvoiceTransaction, error)
+	// ListSuccessful returns the successful transactions of the supplier
+	// created within [from, to)
+	ListSuccessful(supplierID string, from, to time.Time) ([]*InvoiceTransaction, error)
+}
+
+// SupplierInvoiceUsecase ingests supplier recap files and reports the match
+type SupplierInvoiceUsecase interface {
+	IngestInvoice(upload *SupplierInvoiceUpload) (*SupplierInvoice, error)
+	GetInvoice(id string) (*SupplierInvoice, error)
+	ListInvoices(supplierID string, page, limit int) ([]*SupplierInvoice, error)
+	ListInvoiceLines(invoiceID, result string) ([]*SupplierInvoiceLine, error)
+}
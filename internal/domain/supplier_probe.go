@@ -0,0 +1,86 @@
+package domain
+
+import "time"
+
+// SupplierProbe is one active latency measurement of a supplier API
+type SupplierProbe struct {
+	ID           string    `json:"id" db:"id"`
+	SupplierID   string    `json:"supplier_id" db:"supplier_id"`
+	Operation    string    `json:"operation" db:"operation"`
+	LatencyMs    int       `json:"latency_ms" db:"latency_ms"`
+	Success      bool      `json:"success" db:"success"`
+	ErrorMessage *string   `json:"error_message,omitempty" db:"error_message"`
+	ProbedAt     time.Time `json:"probed_at" db:"probed_at"`
+}
+
+// SupplierLatencyStats aggregates the probes of a supplier within a window.
+// Percentiles only cover successful probes.
+type SupplierLatencyStats struct {
+	SupplierID   string   `json:"supplier_id" db:"supplier_id"`
+	SupplierCode string   `json:"supplier_code" db:"supplier_code"`
+	SupplierName string   `json:"supplier_name" db:"supplier_name"`
+	Samples      int      `json:"samples" db:"samples"`
+	SuccessCount int      `json:"success_count" db:"success_count"`
+	P50Ms        *float64 `json:"p50_ms" db:"p50_ms"`
+	P95Ms        *float64 `json:"p95_ms" db:"p95_ms"`
+	AvgMs        *float64 `json:"avg_ms" db:"avg_ms"`
+}
+
+// SupplierSLA reports whether a supplier met the latency and availability targets
+type SupplierSLA struct {
+	*SupplierLatencyStats
+	Availability          float64 `json:"availability"` // Percentage of successful probes
+	TargetP95Ms           int     `json:"target_p95_ms"`
+	TargetAvailability    float64 `json:"target_availability"`
+	LatencyCompliant      bool    `json:"latency_compliant"`
+	AvailabilityCompliant bool    `json:"availability_compliant"`
+	Compliant             bool    `json:"compliant"`
+}
+
+// SupplierSLAReport is the SLA compliance of all probed suppliers
+type SupplierSLAReport struct {
+	WindowStart time.Time      `json:"window_start"`
+	WindowEnd   time.Time      `json:"window_end"`
+	Suppliers   []*SupplierSLA `json:"suppliers"`
+}
+
+// SupplierProbeRepository defines data access for supplier latency probes
+type SupplierProbeRepository interface {
+	Create(probe *SupplierProbe) error
+	GetLatencyStats(since time.Time) ([]*SupplierLatencyStats, error)
+	DeleteBefore(before time.Time) (int64, error)
+}
+
+// SupplierProbeUsecase defines supplier latency probing and SLA reporting
+type SupplierProbeUsecase interface {
+	// ProbeSuppliers probes every active supplier once and returns how many were probed
+	ProbeSuppliers() (int, error)
+	GetSLAReport(window time.Duration) (*SupplierSLAReport, error)
+}
+
+// Supplier probe operations
+const (
+	SupplierProbeBalance = "BALANCE" // Probes stored before pings replaced balance calls
+	SupplierProbePing    = "PING"
+)
+
+// EventSupplierUnhealthy alerts that a supplier stopped answering its probes
+const EventSupplierUnhealthy = "supplier.unhealthy"
+
+// SupplierUnhealthyEventPayload is the payload of supplier.unhealthy events
+type SupplierUnhealthyEventPayload struct {
+	SupplierID   string `json:"supplier_id"`
+	SupplierCode string `json:"supplier_code"`
+	FailedPings  int    `json:"failed_pings"`
+	LastError    string `json:"last_error"`
+}
+
+// NewSupplierUnhealthyEvent builds the outbox event alerting an unreachable supplier
+func NewSupplierUnhealthyEvent(supplier *Supplier, failedPings int, lastError string) (*DomainEvent, error) {
+	return NewDomainEvent(EventSupplierUnhealthy, AggregateTypeSupplier, supplier.ID, &SupplierUnhealthyEventPayload{
+		SupplierID:   supplier.ID,
+		SupplierCode: supplier.Code,
+		FailedPings:  failedPings,
+		LastError:    lastError,
+	})
+}
@@ -0,0 +1,196 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Request rule actions, applied to the destination number in rule order
+const (
+	RequestRuleStripNonDigits = "strip_non_digits" // Drop everything but digits
+	RequestRulePhone62        = "phone_62"         // 08xx and +628xx become 628xx
+	RequestRulePhone0         = "phone_0"          // 628xx and +628xx become 08xx
+	RequestRulePadLeft        = "pad_left"         // Left-pad to Width with Value (default "0")
+	RequestRuleAddPrefix      = "add_prefix"       // Prepend Value unless already there
+	RequestRuleStripPrefix    = "strip_prefix"     // Remove a leading Value
+
+	// MaxRequestRules bounds the rules and examples of one supplier
+	MaxRequestRules = 50
+)
+
+// SupplierRequestRules rewrite the destination of the requests sent to one
+// supplier, so a product mapping works whatever format the supplier expects
+// (62 or 0 phone prefix, zero-padded meter IDs, ...). Examples are checked
+// whenever the rules are saved; rules failing one of them are rejected.
+type SupplierRequestRules struct {
+	Rules    []RequestRule        `json:"rules"`
+	Examples []RequestRuleExample `json:"examples,omitempty"`
+}
+
+// RequestRule is one rewrite step
+type RequestRule struct {
+	// ProductPattern limits the rule to supplier product codes matching the
+	// regular expression; empty applies it to every product
+	ProductPattern string `json:"product_pattern,omitempty"`
+	Action         string `json:"action"`
+	Width          int    `json:"width,omitempty"` // pad_left only
+	Value          string `json:"value,omitempty"` // Prefix, or the pad_left character
+}
+
+// RequestRuleExample is a destination and the form the supplier must receive
+type RequestRuleExample struct {
+	ProductCode string `json:"product_code"`
+	Destination string `json:"destination"`
+	Expected    string `json:"expected"`
+}
+
+// RequestRewriter applies compiled request rules
+type RequestRewriter struct {
+	rules    []RequestRule
+	patterns []*regexp.Regexp // Parallel to rules; nil matches every product
+}
+
+// IsEmpty reports whether there is no rule to apply
+func (r SupplierRequestRules) IsEmpty() bool {
+	return len(r.Rules) == 0
+}
+
+// Compile checks the rules and runs the examples against them
+func (r SupplierRequestRules) Compile() (*RequestRewriter, error) {
+	if len(r.Rules) > MaxRequestRules || len(r.Examples) > MaxRequestRules {
+		return nil, fmt.Errorf("request rules allow at most %d rules and %d examples", MaxRequestRules, MaxRequestRules)
+	}
+
+	rewriter := &RequestRewriter{
+		rules:    r.Rules,
+		patterns: make([]*regexp.Regexp, len(r.Rules)),
+	}
+	for i, rule := range r.Rules {
+		switch rule.Action {
+		case RequestRuleStripNonDigits, RequestRulePhone62, RequestRulePhone0:
+		case RequestRulePadLeft:
+			if rule.Width < 1 || rule.Width > 64 {
+				return nil, fmt.Errorf("request rule %d: width must be between 1 and 64", i+1)
+			}
+			if len([]rune(rule.Value)) > 1 {
+				return nil, fmt.Errorf("request rule %d: pad value must be one character", i+1)
+			}
+		case RequestRuleAddPrefix, RequestRuleStripPrefix:
+			if rule.Value == "" {
+				return nil, fmt.Errorf("request rule %d: value is required", i+1)
+			}
+		default:
+			return nil, fmt.Errorf("request rule %d: unknown action %q", i+1, rule.Action)
+		}
+
+		if rule.ProductPattern != "" {
+			pattern, err := regexp.Compile(rule.ProductPattern)
+			if err != nil {
+				return nil, fmt.Errorf("request rule %d: invalid product_pattern: %v", i+1, err)
+			}
+			rewriter.patterns[i] = pattern
+		}
+	}
+
+	for i, example := range r.Examples {
+		if got := rewriter.Rewrite(example.ProductCode, example.Destination); got != example.Expected {
+			return nil, fmt.Errorf("request rule example %d: %s for %s gives %s, expected %s",
+				i+1, example.Destination, example.ProductCode, got, example.Expected)
+		}
+	}
+
+	return rewriter, nil
+}
+
+// Rewrite returns the destination in the form the supplier expects for the
+// supplier product code
+func (w *RequestRewriter) Rewrite(productCode, destination string) string {
+	for i, rule := range w.rules {
+		if w.patterns[i] != nil && !w.patterns[i].MatchString(productCode) {
+			continue
+		}
+		destination = applyRequestRule(rule, destination)
+	}
+	return destination
+}
+
+func applyRequestRule(rule RequestRule, destination string) string {
+	switch rule.Action {
+	case RequestRuleStripNonDigits:
+		return strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, destination)
+	case RequestRulePhone62:
+		destination = strings.TrimPrefix(destination, "+")
+		if strings.HasPrefix(destination, "08") {
+			return "62" + destination[1:]
+		}
+		return destination
+	case RequestRulePhone0:
+		destination = strings.TrimPrefix(destination, "+")
+		if strings.HasPrefix(destination, "628") {
+			return "0" + destination[2:]
+		}
+		return destination
+	case RequestRulePadLeft:
+		pad := rule.Value
+		if pad == "" {
+			pad = "0"
+		}
+		if missing := rule.Width - len([]rune(destination)); missing > 0 {
+			return strings.Repeat(pad, missing) + destination
+		}
+		return destination
+	case RequestRuleAddPrefix:
+		if strings.HasPrefix(destination, rule.Value) {
+			return destination
+		}
+		return rule.Value + destination
+	case RequestRuleStripPrefix:
+		return strings.TrimPrefix(destination, rule.Value)
+	default:
+		return destination
+	}
+}
+
+// Value stores the rules as a JSON object. It is a string since byte slices
+// are sent to PostgreSQL as bytea.
+func (r SupplierRequestRules) Value() (driver.Value, error) {
+	if r.Rules == nil {
+		r.Rules = []RequestRule{}
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan reads the rules from a JSON object column
+func (r *SupplierRequestRules) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*r = SupplierRequestRules{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into SupplierRequestRules", src)
+	}
+
+	var rules SupplierRequestRules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return fmt.Errorf("failed to decode supplier request rules: %w", err)
+	}
+	*r = rules
+	return nil
+}
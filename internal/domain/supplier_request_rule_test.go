@@ -0,0 +1,198 @@
+package domain
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSupplierRequestRulesRewrite(t *testing.T) {
+	// Rule sets of suppliers expecting different destination formats
+	suppliers := map[string]SupplierRequestRules{
+		"PHONE_62": {Rules: []RequestRule{
+			{Action: RequestRuleStripNonDigits},
+			{Action: RequestRulePhone62},
+		}},
+		"PHONE_0": {Rules: []RequestRule{
+			{ProductPattern: `^(TSEL|XL)\d+$`, Action: RequestRulePhone0},
+		}},
+		"METER_PADDED": {Rules: []RequestRule{
+			{ProductPattern: `^PLN`, Action: RequestRuleStripNonDigits},
+			{ProductPattern: `^PLN`, Action: RequestRulePadLeft, Width: 12},
+		}},
+		"PREFIXED": {Rules: []RequestRule{
+			{ProductPattern: `^BPJS`, Action: RequestRuleAddPrefix, Value: "8888"},
+			{ProductPattern: `^GAME`, Action: RequestRuleStripPrefix, Value: "ID-"},
+			{ProductPattern: `^GAME`, Action: RequestRulePadLeft, Width: 8, Value: "x"},
+		}},
+	}
+
+	tests := []struct {
+		supplier    string
+		productCode string
+		destination string
+		want        string
+	}{
+		{supplier: "PHONE_62", productCode: "TSEL10", destination: "0812-3456-7890", want: "6281234567890"},
+		{supplier: "PHONE_62", productCode: "TSEL10", destination: "+6281234567890", want: "6281234567890"},
+		{supplier: "PHONE_62", productCode: "TSEL10", destination: "6281234567890", want: "6281234567890"},
+		{supplier: "PHONE_62", productCode: "PLN20", destination: "12345678901", want: "12345678901"},
+		{supplier: "PHONE_0", productCode: "TSEL10", destination: "6281234567890", want: "081234567890"},
+		{supplier: "PHONE_0", productCode: "XL5", destination: "+6281234567890", want: "081234567890"},
+		{supplier: "PHONE_0", productCode: "XL5", destination: "081234567890", want: "081234567890"},
+		{supplier: "PHONE_0", productCode: "ISAT10", destination: "6281234567890", want: "6281234567890"},
+		{supplier: "METER_PADDED", productCode: "PLN20", destination: "1234 5678 9", want: "000123456789"},
+		{supplier: "METER_PADDED", productCode: "PLN20", destination: "123456789012", want: "123456789012"},
+		{supplier: "METER_PADDED", productCode: "PLN20", destination: "1234567890123", want: "1234567890123"},
+		{supplier: "METER_PADDED", productCode: "TSEL10", destination: "0812 3456", want: "0812 3456"},
+		{supplier: "PREFIXED", productCode: "BPJS1", destination: "12345", want: "888812345"},
+		{supplier: "PREFIXED", productCode: "BPJS1", destination: "888812345", want: "888812345"},
+		{supplier: "PREFIXED", productCode: "GAMEML", destination: "ID-12345", want: "xxx12345"},
+		{supplier: "PREFIXED", productCode: "PLN20", destination: "ID-12345", want: "ID-12345"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.supplier+"/"+tt.productCode+"/"+tt.destination, func(t *testing.T) {
+			rewriter, err := suppliers[tt.supplier].Compile()
+			if err != nil {
+				t.Fatalf("Compile() unexpected error: %v", err)
+			}
+			if got := rewriter.Rewrite(tt.productCode, tt.destination); got != tt.want {
+				t.Errorf("Rewrite(%q, %q) = %q, want %q", tt.productCode, tt.destination, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSupplierRequestRulesCompileRejects(t *testing.T) {
+	tooMany := make([]RequestRule, MaxRequestRules+1)
+	for i := range tooMany {
+		tooMany[i] = RequestRule{Action: RequestRuleStripNonDigits}
+	}
+
+	tests := []struct {
+		name    string
+		rules   SupplierRequestRules
+		wantErr string
+	}{
+		{
+			name:    "unknown action",
+			rules:   SupplierRequestRules{Rules: []RequestRule{{Action: "uppercase"}}},
+			wantErr: `request rule 1: unknown action "uppercase"`,
+		},
+		{
+			name:    "empty action",
+			rules:   SupplierRequestRules{Rules: []RequestRule{{Action: RequestRulePhone62}, {}}},
+			wantErr: `request rule 2: unknown action ""`,
+		},
+		{
+			name:    "pad width zero",
+			rules:   SupplierRequestRules{Rules: []RequestRule{{Action: RequestRulePadLeft}}},
+			wantErr: "request rule 1: width must be between 1 and 64",
+		},
+		{
+			name:    "pad width too large",
+			rules:   SupplierRequestRules{Rules: []RequestRule{{Action: RequestRulePadLeft, Width: 65}}},
+			wantErr: "request rule 1: width must be between 1 and 64",
+		},
+		{
+			name:    "pad value longer than one character",
+			rules:   SupplierRequestRules{Rules: []RequestRule{{Action: RequestRulePadLeft, Width: 12, Value: "00"}}},
+			wantErr: "request rule 1: pad value must be one character",
+		},
+		{
+			name:    "add prefix without value",
+			rules:   SupplierRequestRules{Rules: []RequestRule{{Action: RequestRuleAddPrefix}}},
+			wantErr: "request rule 1: value is required",
+		},
+		{
+			name:    "strip prefix without value",
+			rules:   SupplierRequestRules{Rules: []RequestRule{{Action: RequestRuleStripPrefix}}},
+			wantErr: "request rule 1: value is required",
+		},
+		{
+			name:    "invalid product pattern",
+			rules:   SupplierRequestRules{Rules: []RequestRule{{ProductPattern: "(TSEL", Action: RequestRulePhone62}}},
+			wantErr: "request rule 1: invalid product_pattern",
+		},
+		{
+			name:    "too many rules",
+			rules:   SupplierRequestRules{Rules: tooMany},
+			wantErr: "request rules allow at most 50 rules and 50 examples",
+		},
+		{
+			name: "failing example",
+			rules: SupplierRequestRules{
+				Rules: []RequestRule{{Action: RequestRulePhone0}},
+				Examples: []RequestRuleExample{
+					{ProductCode: "TSEL10", Destination: "6281234567890", Expected: "081234567890"},
+					{ProductCode: "TSEL10", Destination: "081234567890", Expected: "6281234567890"},
+				},
+			},
+			wantErr: "request rule example 2: 081234567890 for TSEL10 gives 081234567890, expected 6281234567890",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rewriter, err := tt.rules.Compile()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Compile() error = %v, want %q", err, tt.wantErr)
+			}
+			if rewriter != nil {
+				t.Errorf("Compile() returned a rewriter with an error")
+			}
+		})
+	}
+}
+
+func TestSupplierRequestRulesExamplesPass(t *testing.T) {
+	rules := SupplierRequestRules{
+		Rules: []RequestRule{
+			{ProductPattern: `^PLN`, Action: RequestRulePadLeft, Width: 12},
+			{ProductPattern: `^TSEL`, Action: RequestRulePhone62},
+		},
+		Examples: []RequestRuleExample{
+			{ProductCode: "PLN20", Destination: "12345678901", Expected: "012345678901"},
+			{ProductCode: "TSEL10", Destination: "081234567890", Expected: "6281234567890"},
+		},
+	}
+	if _, err := rules.Compile(); err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+}
+
+func TestSupplierRequestRulesValueScan(t *testing.T) {
+	rules := SupplierRequestRules{
+		Rules:    []RequestRule{{ProductPattern: `^PLN`, Action: RequestRulePadLeft, Width: 12, Value: "0"}},
+		Examples: []RequestRuleExample{{ProductCode: "PLN20", Destination: "1", Expected: "000000000001"}},
+	}
+
+	value, err := rules.Value()
+	if err != nil {
+		t.Fatalf("Value() unexpected error: %v", err)
+	}
+
+	var scanned SupplierRequestRules
+	if err := scanned.Scan([]byte(value.(string))); err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(scanned, rules) {
+		t.Errorf("Scan(Value()) = %+v, want %+v", scanned, rules)
+	}
+
+	empty, err := SupplierRequestRules{}.Value()
+	if err != nil || empty != `{"rules":[]}` {
+		t.Errorf("empty Value() = %v, %v, want {\"rules\":[]}", empty, err)
+	}
+
+	if err := scanned.Scan(nil); err != nil || !scanned.IsEmpty() {
+		t.Errorf("Scan(nil) = %+v, %v, want empty rules", scanned, err)
+	}
+	if err := scanned.Scan(42); err == nil {
+		t.Error("Scan(42) error = nil, want an error")
+	}
+	if err := scanned.Scan(`{"rules":`); err == nil {
+		t.Error("Scan(truncated JSON) error = nil, want an error")
+	}
+}
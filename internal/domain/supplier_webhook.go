@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// SupplierWebhook is an inbound supplier notification as received, before
+// its signature is verified
+type SupplierWebhook struct {
+	SupplierCode string
+	Signature    string
+	Payload      []byte
+}
+
+// WebhookRejection is returned when a supplier webhook is refused before its
+// payload is processed; Reason is one of the WebhookReject* constants
+type WebhookRejection struct {
+	Reason string
+}
+
+func (e *WebhookRejection) Error() string {
+	return "supplier webhook rejected: " + e.Reason
+}
+
+// Supplier webhook rejection reasons
+const (
+	WebhookRejectUnknownSupplier  = "unknown_supplier"
+	WebhookRejectNoSecret         = "no_secret"
+	WebhookRejectInvalidSignature = "invalid_signature"
+	WebhookRejectReplay           = "replay"
+)
+
+// WebhookEventRepository remembers processed webhook event IDs so a captured
+// notification cannot be replayed within the replay window
+type WebhookEventRepository interface {
+	// Reserve stores the event ID for ttl; it returns false when it was already seen
+	Reserve(supplierCode, eventID string, ttl time.Duration) (bool, error)
+	// Release forgets an event ID whose processing failed so a redelivery is accepted
+	Release(supplierCode, eventID string) error
+}
+
+// SupplierWebhookUsecase verifies supplier webhooks and applies the reported
+// transaction result
+type SupplierWebhookUsecase interface {
+	HandleWebhook(ctx context.Context, webhook *SupplierWebhook) error
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"context"
 	"time"
 )
 
@@ -17,14 +18,20 @@ type Transaction struct {
 	ProductCode       string `json:"product_code" db:"product_code"`
 
 	// Pricing information (snapshot)
-	HPP          float64 `json:"hpp" db:"hpp"`
+	HPP          float64 `json:"hpp" db:"hpp"` // Product base price until routed, then the cost of the supplier attempted last
 	SellingPrice float64 `json:"selling_price" db:"selling_price"`
 	AdminFee     float64 `json:"admin_fee" db:"admin_fee"`
-	Profit       float64 `json:"profit" db:"profit"`
+	Profit       float64 `json:"profit" db:"profit"` // Stored when the transaction is updated, see TransactionRepository.Update
 
 	// Status
 	Status string `json:"status" db:"status"`
 
+	// Ordering channel and auto-cancel deadline for pending transactions
+	Channel   string     `json:"channel" db:"channel"`
+	ExpiresAt *time.Time `json:"expires_at" db:"expires_at"`
+	// ScheduledAt is when a transaction held by a cutoff is released for processing
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty" db:"scheduled_at"`
+
 	// Supplier response
 	SerialNumber    *string `json:"serial_number" db:"serial_number"`
 	SupplierMessage *string `json:"supplier_message" db:"supplier_message"`
@@ -45,6 +52,8 @@ type Transaction struct {
 	UserAgent   *string `json:"user_agent" db:"user_agent"`
 	APIEndpoint *string `json:"api_endpoint" db:"api_endpoint"`
 	Notes       *string `json:"notes" db:"notes"`
+	// Tags are set by the ordering user or H2H client at creation
+	Tags TransactionTags `json:"tags,omitempty" db:"tags"`
 }
 
 // Mutation represents a balance mutation (double-entry accounting)
@@ -64,7 +73,9 @@ type Mutation struct {
 	Description string  `json:"description" db:"description"`
 	Notes       *string `json:"notes" db:"notes"`
 
-	// System information
+	// System information: who or what moved the money, see Actor
+	ActorType *string `json:"actor_type" db:"actor_type"`
+	ActorName *string `json:"actor_name" db:"actor_name"`
 	CreatedBy *string `json:"created_by" db:"created_by"`
 	IPAddress *string `json:"ip_address" db:"ip_address"`
 	UserAgent *string `json:"user_agent" db:"user_agent"`
@@ -78,48 +89,272 @@ type TransactionRepository interface {
 	Create(transaction *Transaction) error
 	GetByID(id string) (*Transaction, error)
 	GetByTrxCode(trxCode string) (*Transaction, error)
+	// Update also stores the HPP and recomputes the stored profit in the same
+	// statement: the gross profit (CalculateProfit) less the net upline
+	// commissions for a successful transaction, 0 otherwise.
+	// transaction.Profit is set to the stored value.
 	Update(transaction *Transaction) error
-	GetByUserID(userID string, limit, offset int) ([]*Transaction, error)
+	// GetByUserID and GetByUserIDAfter require a date range so only the
+	// matching monthly partitions are scanned. Non-empty tags only keep
+	// transactions carrying all of them.
+	GetByUserID(userID string, period DateRange, tags TransactionTags, limit, offset int) ([]*Transaction, error)
+	GetByUserIDAfter(userID string, period DateRange, tags TransactionTags, cursor *Cursor, limit int) ([]*Transaction, error)
 	GetByStatus(status string) ([]*Transaction, error)
 	GetPendingTransactions() ([]*Transaction, error)
 	UpdateStatus(id, status string) error
+	// TransitionStatus changes the status only when it still equals from and
+	// reports whether the transaction was updated
+	TransitionStatus(id, from, to string) (bool, error)
+	// GetExpiredPending returns up to limit pending transactions past their expiry
+	GetExpiredPending(limit int) ([]*Transaction, error)
+	// Schedule moves a transaction from the from status to PENDING_SCHEDULE
+	// until scheduledAt, clearing its expiry, and reports whether it was updated
+	Schedule(id, from string, scheduledAt time.Time) (bool, error)
+	// GetScheduledDue returns up to limit scheduled transactions whose release
+	// time is at or before the given time, earliest first
+	GetScheduledDue(before time.Time, limit int) ([]*Transaction, error)
+	// ReleaseScheduled moves a scheduled transaction back to PENDING with a new
+	// expiry and reports whether it was updated
+	ReleaseScheduled(id string, expiresAt *time.Time) (bool, error)
+	// GetProcessingBefore returns up to limit processing transactions created
+	// before the given time, oldest first
+	GetProcessingBefore(before time.Time, limit int) ([]*Transaction, error)
+	// GetChannelUsage counts a user's transactions on a channel within a date range
+	GetChannelUsage(userID, channel string, period DateRange) (*ChannelUsage, error)
 	UpdateSupplierInfo(id, supplierID, supplierTrxID string) error
+	// FindDuplicate returns the latest transaction other than excludeID with
+	// the same user, product and destination that is still in progress
+	// (created since activeSince) or succeeded since completedSince, or nil
+	FindDuplicate(userID, productCode, destinationNumber, excludeID string, activeSince, completedSince time.Time) (*Transaction, error)
 	GetTransactionsByDateRange(startDate, endDate time.Time) ([]*Transaction, error)
+	// RecomputeProfit recomputes the stored profit of the transactions created
+	// in [from, to) and returns how many changed
+	RecomputeProfit(from, to time.Time) (int64, error)
+	// GetFirstCreatedAt returns the creation time of the oldest transaction,
+	// or nil when there is none
+	GetFirstCreatedAt() (*time.Time, error)
 }
 
 // MutationRepository defines operations for mutation data access
 type MutationRepository interface {
 	Create(mutation *Mutation) error
 	GetByID(id string) (*Mutation, error)
-	GetByUserID(userID string, limit, offset int) ([]*Mutation, error)
+	// GetByUserID, GetByUserIDAfter and GetBalanceHistory require a date range
+	// so only the matching monthly partitions are scanned
+	GetByUserID(userID string, period DateRange, limit, offset int) ([]*Mutation, error)
+	GetByUserIDAfter(userID string, period DateRange, cursor *Cursor, limit int) ([]*Mutation, error)
 	GetByReference(referenceType, referenceID string) ([]*Mutation, error)
-	GetBalanceHistory(userID string, limit, offset int) ([]*Mutation, error)
+	GetBalanceHistory(userID string, period DateRange, limit, offset int) ([]*Mutation, error)
 	GetCurrentBalance(userID string) (float64, error)
+	// List returns the mutations matching filter, newest first, and Count
+	// how many there are in total
+	List(filter MutationFilter, limit, offset int) ([]*Mutation, error)
+	Count(filter MutationFilter) (int, error)
+	// GetDailySummary aggregates the mutations matching filter per day of the
+	// IANA timezone in one query, oldest first. Days are returned as
+	// wall-clock times (UTC location).
+	GetDailySummary(filter MutationFilter, timezone string) ([]*MutationDailySummary, error)
 }
 
-// TransactionUsecase defines business logic operations for transactions
+// TransactionUsecase defines business logic operations for transactions.
+// Methods taking a context log through logger.FromContext(ctx), so their
+// lines carry the request's trace_id and user.
 type TransactionUsecase interface {
-	CreateTransaction(userID, productCode, destinationNumber string) (*Transaction, error)
-	ProcessTransaction(transactionID string) error
+	CreateTransaction(ctx context.Context, userID, productCode, destinationNumber, channel string) (*Transaction, error)
+	CreateTransactionSync(ctx context.Context, userID, productCode, destinationNumber, channel string, policy *SyncFailoverPolicy) (*Transaction, error)
+	SimulateTransaction(ctx context.Context, userID, productCode, destinationNumber, channel string) (*TransactionSimulation, error)
+	ProcessTransaction(ctx context.Context, transactionID string) error
 	ProcessPendingTransactions() error
 	RetryFailedTransaction(transactionID string) error
 	GetTransaction(id string) (*Transaction, error)
-	GetUserTransactions(userID string, page, limit int) ([]*Transaction, error)
+	// GetUserTransactions and GetUserTransactionsByCursor only list
+	// transactions carrying all the given tags, if any
+	GetUserTransactions(userID string, period DateRange, tags TransactionTags, page, limit int) ([]*Transaction, error)
+	GetUserTransactionsByCursor(userID string, period DateRange, tags TransactionTags, cursor string, limit int) ([]*Transaction, string, error)
 	GetTransactionByTrxCode(trxCode string) (*Transaction, error)
-	CancelTransaction(transactionID string) error
+	GetTransactionTimeline(transactionID string) ([]*TransactionTimelineEntry, error)
+	GetRoutingDecisions(transactionID string) ([]*RoutingDecision, error)
+	CancelTransaction(ctx context.Context, transactionID string) error
+	ExpireTransactions() (int, error)
+	// PollProcessingTransactions checks processing transactions past their
+	// expected SLA with the supplier and times out those past their timeout
+	PollProcessingTransactions() (*ProcessingPollResult, error)
+	// ReleaseScheduledTransactions returns transactions held by a cutoff that
+	// ended to PENDING and queues them. It returns how many were released.
+	ReleaseScheduledTransactions() (int, error)
 	RefundTransaction(transactionID string) error
+	// ForceRefundTransaction refunds a SUCCESS, FAILED or TIMEOUT transaction
+	// on an admin's request and records who forced it and why
+	ForceRefundTransaction(transactionID string, admin Actor, reason string) (*Transaction, error)
+	// ReprocessTransaction sends a FAILED or TIMEOUT transaction whose balance
+	// was never charged to a supplier again on an admin's request: supplierID
+	// when set, bypassing smart routing, otherwise the one routing picks
+	ReprocessTransaction(ctx context.Context, transactionID, supplierID string, admin Actor, reason string) (*Transaction, error)
+	// ApplySupplierResult completes a processing transaction with a result the
+	// supplier sent after reporting it pending
+	ApplySupplierResult(ctx context.Context, supplier *Supplier, response *SupplierResponse) error
 	GetTransactionStats(userID string, startDate, endDate time.Time) (*TransactionStats, error)
+	// GetTransactionStatsByTag computes the statistics per value of one tag
+	// key; transactions without the tag are grouped under an empty value
+	GetTransactionStatsByTag(userID, tagKey string, startDate, endDate time.Time) ([]*TaggedTransactionStats, error)
+}
+
+// MaxSyncFailoverAttempts caps alternative suppliers tried inside one request
+const MaxSyncFailoverAttempts = 5
+
+// SyncFailoverPolicy lets a transaction fail over to alternative suppliers
+// before the request returns instead of through the asynchronous retry.
+// Budget limits when a new attempt may start; an in-flight supplier call is
+// never abandoned since the supplier could still deliver it.
+type SyncFailoverPolicy struct {
+	MaxAttempts int
+	Budget      time.Duration
+}
+
+// TransactionSimulation describes what an order would do without holding
+// balance or calling a supplier. Accepted is false when the order would be
+// rejected at creation or has no supplier to route to.
+type TransactionSimulation struct {
+	Simulated         bool       `json:"simulated"`
+	Accepted          bool       `json:"accepted"`
+	RejectReason      string     `json:"reject_reason,omitempty"`
+	ProductCode       string     `json:"product_code"`
+	DestinationNumber string     `json:"destination_number"`
+	Channel           string     `json:"channel"`
+	HPP               float64    `json:"hpp"`
+	SellingPrice      float64    `json:"selling_price"`
+	AdminFee          float64    `json:"admin_fee"`
+	TotalAmount       float64    `json:"total_amount"`
+	AvailableBalance  float64    `json:"available_balance"`
+	SufficientBalance bool       `json:"sufficient_balance"`
+	ExpiresAt         *time.Time `json:"expires_at"`
+	ScheduledAt       *time.Time `json:"scheduled_at,omitempty"` // Set when a cutoff would hold the order
+
+	// Routing decision; empty when no supplier is available
+	SupplierCode        string  `json:"supplier_code,omitempty"`
+	SupplierName        string  `json:"supplier_name,omitempty"`
+	SupplierProductCode string  `json:"supplier_product_code,omitempty"`
+	SupplierPrice       float64 `json:"supplier_price"`
+	EstimatedProfit     float64 `json:"estimated_profit"`
+	RoutingReason       string  `json:"routing_reason,omitempty"`
+	RoutingConfidence   float64 `json:"routing_confidence"`
+}
+
+// AutoCancelPolicy defines how long a transaction may stay pending before it
+// is cancelled automatically. A product override wins over the channel
+// duration, which wins over the default; zero means never.
+type AutoCancelPolicy struct {
+	Default  time.Duration
+	Channels map[string]time.Duration // Keyed by transaction channel
+	Products map[string]time.Duration // Keyed by product code
+}
+
+// Timeout returns the auto-cancel duration for a transaction
+func (p AutoCancelPolicy) Timeout(channel, productCode string) time.Duration {
+	if timeout, ok := p.Products[productCode]; ok {
+		return timeout
+	}
+	if timeout, ok := p.Channels[channel]; ok {
+		return timeout
+	}
+	return p.Default
+}
+
+// ExpiresAt returns when a transaction created at createdAt expires, or nil
+// when it never does
+func (p AutoCancelPolicy) ExpiresAt(channel, productCode string, createdAt time.Time) *time.Time {
+	timeout := p.Timeout(channel, productCode)
+	if timeout <= 0 {
+		return nil
+	}
+	expiresAt := createdAt.Add(timeout)
+	return &expiresAt
+}
+
+// ProcessingSLAPolicy defines how long a transaction may stay processing at
+// the supplier. Past Expected the supplier is asked for the status; past
+// Timeout the transaction is given up as TIMEOUT and its balance released. A
+// product override wins over the category, which wins over the default; a
+// zero timeout override means never.
+type ProcessingSLAPolicy struct {
+	Expected         time.Duration
+	Timeout          time.Duration
+	CategoryExpected map[string]time.Duration // Keyed by product category
+	CategoryTimeout  map[string]time.Duration // Keyed by product category
+	ProductExpected  map[string]time.Duration // Keyed by product code
+	ProductTimeout   map[string]time.Duration // Keyed by product code
+}
+
+// ProcessingSLA is the processing SLA resolved for one product
+type ProcessingSLA struct {
+	Expected time.Duration
+	Timeout  time.Duration
+}
+
+// For returns the processing SLA of a product
+func (p ProcessingSLAPolicy) For(category, productCode string) ProcessingSLA {
+	return ProcessingSLA{
+		Expected: slaDuration(p.Expected, p.CategoryExpected, p.ProductExpected, category, productCode),
+		Timeout:  slaDuration(p.Timeout, p.CategoryTimeout, p.ProductTimeout, category, productCode),
+	}
+}
+
+// ShortestExpected returns the smallest expected duration of any product, so
+// a poll pass knows which transactions cannot be due yet
+func (p ProcessingSLAPolicy) ShortestExpected() time.Duration {
+	shortest := p.Expected
+	for _, overrides := range []map[string]time.Duration{p.CategoryExpected, p.ProductExpected} {
+		for _, expected := range overrides {
+			if expected < shortest {
+				shortest = expected
+			}
+		}
+	}
+	return shortest
+}
+
+func slaDuration(fallback time.Duration, categories, products map[string]time.Duration, category, productCode string) time.Duration {
+	if duration, ok := products[productCode]; ok {
+		return duration
+	}
+	if duration, ok := categories[category]; ok {
+		return duration
+	}
+	return fallback
+}
+
+// ProcessingPollResult summarizes one pass over processing transactions
+type ProcessingPollResult struct {
+	Checked   int // Transactions whose status was asked from the supplier
+	Completed int // Reported successful by the supplier
+	Failed    int // Reported failed by the supplier and refunded
+	TimedOut  int // Still unresolved past their timeout
 }
 
 // TransactionUsecase defines business logic operations for mutations
 type MutationUsecase interface {
-	CreateMutation(userID, mutationType string, amount, balanceBefore, balanceAfter float64, description string, referenceType, referenceID *string) error
-	GetUserMutations(userID string, page, limit int) ([]*Mutation, error)
+	CreateMutation(userID, mutationType string, amount, balanceBefore, balanceAfter float64, description string, referenceType, referenceID *string, actor Actor) error
+	GetUserMutations(userID string, period DateRange, page, limit int) ([]*Mutation, error)
+	GetUserMutationsByCursor(userID string, period DateRange, cursor string, limit int) ([]*Mutation, string, error)
+	// ListUserMutations returns one page of the mutations matching filter
+	// and the total number of matches
+	ListUserMutations(filter MutationFilter, page, limit int) ([]*Mutation, int, error)
+	// GetDailyMutationSummary aggregates the mutations matching filter per day
+	GetDailyMutationSummary(filter MutationFilter) ([]*MutationDailySummary, error)
 	GetBalanceHistory(userID string, startDate, endDate time.Time) ([]*Mutation, error)
 	GetCurrentBalance(userID string) (float64, error)
 	ValidateBalance(userID string, requiredAmount float64) error
 }
 
+// ChannelUsage counts a user's transactions on one channel by outcome
+type ChannelUsage struct {
+	TotalTransactions int     `json:"total_transactions" db:"total_transactions"`
+	SuccessCount      int     `json:"success_count" db:"success_count"`
+	FailedCount       int     `json:"failed_count" db:"failed_count"`           // FAILED, TIMEOUT and REFUND
+	InProgressCount   int     `json:"in_progress_count" db:"in_progress_count"` // PENDING, PENDING_SCHEDULE and PROCESSING
+	SuccessAmount     float64 `json:"success_amount" db:"success_amount"`       // Charged for successful transactions
+}
+
 // TransactionStats represents transaction statistics
 type TransactionStats struct {
 	TotalTransactions int     `json:"total_transactions"`
@@ -131,14 +366,21 @@ type TransactionStats struct {
 	AverageAmount     float64 `json:"average_amount"`
 }
 
+// TaggedTransactionStats represents the statistics of one tag value
+type TaggedTransactionStats struct {
+	TagValue string `json:"tag_value"` // Empty for transactions without the tag
+	TransactionStats
+}
+
 // Transaction validation constants
 const (
-	StatusPending    = "PENDING"
-	StatusProcessing = "PROCESSING"
-	StatusSuccess    = "SUCCESS"
-	StatusFailed     = "FAILED"
-	StatusRefund     = "REFUND"
-	StatusTimeout    = "TIMEOUT"
+	StatusPending         = "PENDING"
+	StatusPendingSchedule = "PENDING_SCHEDULE" // Held until a cutoff ends
+	StatusProcessing      = "PROCESSING"
+	StatusSuccess         = "SUCCESS"
+	StatusFailed          = "FAILED"
+	StatusRefund          = "REFUND"
+	StatusTimeout         = "TIMEOUT"
 
 	MutationTypeDebit  = "DEBIT"  // Money in
 	MutationTypeCredit = "CREDIT" // Money out
@@ -148,12 +390,19 @@ const (
 	ReferenceTypeWithdrawal  = "WITHDRAWAL"
 	ReferenceTypeCommission  = "COMMISSION"
 	ReferenceTypePenalty     = "PENALTY"
+	ReferenceTypeTransfer    = "TRANSFER"
+
+	ChannelAPI      = "API"
+	ChannelH2H      = "H2H"
+	ChannelWhatsApp = SourceWhatsApp
+	ChannelTelegram = SourceTelegram
+	ChannelSMS      = SourceSMS
 )
 
 // IsValidStatus checks if the transaction status is valid
 func IsValidStatus(status string) bool {
 	validStatuses := []string{
-		StatusPending, StatusProcessing, StatusSuccess,
+		StatusPending, StatusPendingSchedule, StatusProcessing, StatusSuccess,
 		StatusFailed, StatusRefund, StatusTimeout,
 	}
 	for _, s := range validStatuses {
@@ -164,6 +413,17 @@ func IsValidStatus(status string) bool {
 	return false
 }
 
+// IsValidChannel checks if the transaction channel is valid
+func IsValidChannel(channel string) bool {
+	validChannels := []string{ChannelAPI, ChannelH2H, ChannelWhatsApp, ChannelTelegram, ChannelSMS}
+	for _, c := range validChannels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
 // IsValidMutationType checks if the mutation type is valid
 func IsValidMutationType(mutationType string) bool {
 	return mutationType == MutationTypeDebit || mutationType == MutationTypeCredit
@@ -188,9 +448,21 @@ func (t *Transaction) GetDuration() *time.Duration {
 	return &duration
 }
 
-// CalculateProfit returns the profit for this transaction
+// CalculateProfit returns the gross profit of this transaction, before
+// upline commissions. The admin fee is paid by the buyer on top of the
+// selling price.
 func (t *Transaction) CalculateProfit() float64 {
-	return t.SellingPrice - t.HPP - t.AdminFee
+	return t.SellingPrice + t.AdminFee - t.HPP
+}
+
+// TotalAmount returns the amount charged to the buyer's balance
+func (t *Transaction) TotalAmount() float64 {
+	return t.SellingPrice + t.AdminFee
+}
+
+// IsPastExpiry checks if a pending transaction has passed its auto-cancel deadline
+func (t *Transaction) IsPastExpiry(now time.Time) bool {
+	return t.Status == StatusPending && t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
 }
 
 // IsExpired checks if the transaction is expired (for timeout handling)
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// TransactionLock is a held processing lock on one transaction. Token is a
+// fencing token growing with every acquisition of the same transaction, so a
+// holder whose lock expired and was taken over can tell it was superseded.
+type TransactionLock struct {
+	TransactionID string
+	Owner         string
+	Token         int64
+	AcquiredAt    time.Time
+}
+
+// TransactionLockRepository serializes the processing of a transaction
+// across workers and replicas
+type TransactionLockRepository interface {
+	// Acquire takes the lock for ttl; it returns nil when another holder has it
+	Acquire(transactionID string, ttl time.Duration) (*TransactionLock, error)
+	// Validate reports whether lock is still held, i.e. neither expired nor
+	// taken over by a newer token
+	Validate(lock *TransactionLock) (bool, error)
+	// Release drops the lock unless it already passed to another holder
+	Release(lock *TransactionLock) error
+}
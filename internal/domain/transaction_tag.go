@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Transaction tag limits
+const (
+	MaxTransactionTags     = 10
+	MaxTransactionTagValue = 64
+)
+
+var transactionTagKeyPattern = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+// TransactionTags are partner-defined labels of a transaction, such as
+// {"campaign": "RAMADAN24", "branch": "JKT-01"}, stored as a JSONB object so
+// listings can filter on them and reports can group by one key
+type TransactionTags map[string]string
+
+// NormalizeTransactionTags validates tags and returns them with lower-case
+// keys and trimmed values. Keys are 1-32 characters of a-z, 0-9 or _ and
+// values 1-64 characters.
+func NormalizeTransactionTags(tags map[string]string) (TransactionTags, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	if len(tags) > MaxTransactionTags {
+		return nil, fmt.Errorf("too many tags, at most %d are allowed", MaxTransactionTags)
+	}
+
+	normalized := make(TransactionTags, len(tags))
+	for key, value := range tags {
+		key = NormalizeTransactionTagKey(key)
+		if !transactionTagKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid tag key: %s", key)
+		}
+		value = strings.TrimSpace(value)
+		if value == "" || utf8.RuneCountInString(value) > MaxTransactionTagValue {
+			return nil, fmt.Errorf("invalid tag value for %s", key)
+		}
+		if _, exists := normalized[key]; exists {
+			return nil, fmt.Errorf("duplicate tag key: %s", key)
+		}
+		normalized[key] = value
+	}
+
+	return normalized, nil
+}
+
+// NormalizeTransactionTagKey returns the stored form of a tag key
+func NormalizeTransactionTagKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+// IsValidTransactionTagKey checks if a normalized tag key is valid
+func IsValidTransactionTagKey(key string) bool {
+	return transactionTagKeyPattern.MatchString(key)
+}
+
+// Value stores the tags as a JSON object, {} when there are none. It is a
+// string since byte slices are sent to PostgreSQL as bytea.
+func (t TransactionTags) Value() (driver.Value, error) {
+	if len(t) == 0 {
+		return "{}", nil
+	}
+	data, err := json.Marshal(map[string]string(t))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan reads the tags from a JSON object column
+func (t *TransactionTags) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into TransactionTags", src)
+	}
+
+	var tags map[string]string
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return fmt.Errorf("failed to decode transaction tags: %w", err)
+	}
+	if len(tags) == 0 {
+		*t = nil
+		return nil
+	}
+	*t = tags
+	return nil
+}
+
+type transactionTagsKey struct{}
+
+// WithTransactionTags attaches the tags to set on the orders placed with ctx
+func WithTransactionTags(ctx context.Context, tags TransactionTags) context.Context {
+	if len(tags) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, transactionTagsKey{}, tags)
+}
+
+// TransactionTagsFromContext returns the tags attached to ctx, if any
+func TransactionTagsFromContext(ctx context.Context) TransactionTags {
+	tags, _ := ctx.Value(transactionTagsKey{}).(TransactionTags)
+	return tags
+}
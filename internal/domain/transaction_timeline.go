@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TransactionTimelineEntry is one append-only step in the history of a transaction
+type TransactionTimelineEntry struct {
+	ID            string                 `json:"id" db:"id"`
+	Sequence      int64                  `json:"sequence" db:"sequence"`
+	TransactionID string                 `json:"transaction_id" db:"transaction_id"`
+	EventType     string                 `json:"event_type" db:"event_type"`
+	Status        string                 `json:"status" db:"status"` // Transaction status after the event
+	SupplierID    *string                `json:"supplier_id" db:"supplier_id"`
+	Attempt       *int                   `json:"attempt" db:"attempt"`
+	Message       *string                `json:"message" db:"message"`
+	Details       map[string]interface{} `json:"details,omitempty" db:"-"`
+	DetailsJSON   *string                `json:"-" db:"details"`
+	CreatedAt     time.Time              `json:"created_at" db:"created_at"`
+}
+
+// TransactionTimelineRepository defines operations for the transaction timeline
+type TransactionTimelineRepository interface {
+	Append(entry *TransactionTimelineEntry) error
+	ListByTransactionID(transactionID string) ([]*TransactionTimelineEntry, error)
+}
+
+// Transaction timeline event types
+const (
+	TimelineCreated            = "CREATED"
+	TimelineProcessing         = "PROCESSING"
+	TimelineRouted             = "ROUTED"
+	TimelineRoutingFailed      = "ROUTING_FAILED"
+	TimelineSupplierAttempt    = "SUPPLIER_ATTEMPT"
+	TimelineStatusChanged      = "STATUS_CHANGED"
+	TimelineRetryRequested     = "RETRY_REQUESTED"
+	TimelineBalanceCaptured    = "BALANCE_CAPTURED"
+	TimelineBalanceReleased    = "BALANCE_RELEASED"
+	TimelineRefunded           = "REFUNDED"
+	TimelineCommissionReversed = "COMMISSION_REVERSED"
+	TimelineFailover           = "SYNC_FAILOVER"
+	TimelineExpired            = "EXPIRED"
+	TimelineTimedOut           = "TIMED_OUT"
+	TimelineScheduled          = "SCHEDULED"
+	TimelineReleased           = "RELEASED"
+	TimelineForceRefunded      = "FORCE_REFUNDED" // Refund forced by an admin
+	TimelineReprocessed        = "REPROCESSED"    // Sent to a supplier again by an admin
+	TimelineCashbackPaid       = "CASHBACK_PAID"
+	TimelineCashbackReversed   = "CASHBACK_REVERSED"
+	TimelineSlowProcessing     = "SLOW_PROCESSING" // Stage timings of a processing run over the threshold
+)
+
+// NewTransactionTimelineEntry builds a timeline entry for the transaction's current state
+func NewTransactionTimelineEntry(transaction *Transaction, eventType, message string, details map[string]interface{}) *TransactionTimelineEntry {
+	entry := &TransactionTimelineEntry{
+		ID:            uuid.New().String(),
+		TransactionID: transaction.ID,
+		EventType:     eventType,
+		Status:        transaction.Status,
+		SupplierID:    transaction.SupplierID,
+		Details:       details,
+		CreatedAt:     time.Now(),
+	}
+	if message != "" {
+		entry.Message = &message
+	}
+	return entry
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// BalanceTransfer moves balance from one user to their direct upline or
+// downline. Both sides are recorded as mutations referencing the transfer.
+type BalanceTransfer struct {
+	ID                string  `json:"id" db:"id"`
+	SenderID          string  `json:"sender_id" db:"sender_id"`
+	SenderUsername    string  `json:"sender_username" db:"sender_username"` // Joined from users
+	RecipientID       string  `json:"recipient_id" db:"recipient_id"`
+	RecipientUsername string  `json:"recipient_username" db:"recipient_username"` // Joined from users
+	Amount            float64 `json:"amount" db:"amount"`
+	Note              *string `json:"note" db:"note"`
+
+	// Direction is IN or OUT relative to the user viewing the history
+	Direction string `json:"direction,omitempty" db:"-"`
+
+	// Timestamp
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// BalanceTransferRepository defines operations for balance transfer data access
+type BalanceTransferRepository interface {
+	Create(transfer *BalanceTransfer) error
+	// LockUsers locks the user rows in a stable order so concurrent transfers
+	// between the same users cannot deadlock; only meaningful inside a transaction
+	LockUsers(userIDs ...string) error
+	// SumSentSince totals the amount a user transferred out since the given time
+	SumSentSince(senderID string, since time.Time) (float64, error)
+	// GetByUserAfter lists transfers sent or received by a user, newest first
+	GetByUserAfter(userID string, cursor *Cursor, limit int) ([]*BalanceTransfer, error)
+}
+
+// BalanceTransferUsecase defines balance transfers and the transaction PIN
+// that confirms them
+type BalanceTransferUsecase interface {
+	SetPIN(userID, password, pin string) error
+	// VerifyPIN checks the transaction PIN, locking it after repeated wrong attempts
+	VerifyPIN(userID, pin string) error
+	// Transfer records both mutations as moved by actor, the sender's request
+	Transfer(senderID, recipientUsername string, amount float64, pin string, note *string, actor Actor) (*BalanceTransfer, error)
+	ListTransfers(userID, cursor string, limit int) ([]*BalanceTransfer, string, error)
+}
+
+// TransferLimit bounds the transfers of a user level; zero means unlimited
+type TransferLimit struct {
+	MaxAmount  float64 `json:"max_amount"`  // Per transfer
+	DailyLimit float64 `json:"daily_limit"` // Total sent per day
+}
+
+// Transfer constants
+const (
+	TransferDirectionIn  = "IN"
+	TransferDirectionOut = "OUT"
+
+	// PINAttemptSubject is the attempt tracker subject type for wrong PINs
+	PINAttemptSubject = "pin"
+)
+
+var pinPattern = regexp.MustCompile(`^[0-9]{6}$`)
+
+// IsValidPIN checks that a transaction PIN has exactly 6 digits
+func IsValidPIN(pin string) bool {
+	return pinPattern.MatchString(pin)
+}
+
+// PINLockedError is returned while a user is locked out after too many wrong PINs
+type PINLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *PINLockedError) Error() string {
+	return fmt.Sprintf("PIN locked, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// CanTransferTo checks that balance may move between the two users: a direct
+// upline and downline pair, or any user when the sender is an admin
+func (u *User) CanTransferTo(recipient *User) bool {
+	if u.Level == LevelAdmin {
+		return true
+	}
+	if recipient.UplineID != nil && *recipient.UplineID == u.ID {
+		return true
+	}
+	return u.UplineID != nil && *u.UplineID == recipient.ID
+}
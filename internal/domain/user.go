@@ -21,6 +21,7 @@ type User struct {
 	
 	// Financial information
 	Balance         float64 `json:"balance" db:"balance"`
+	HeldBalance     float64 `json:"held_balance" db:"held_balance"` // Reserved by in-flight transactions
 	CreditLimit     float64 `json:"credit_limit" db:"credit_limit"`
 	MarkupPercentage float64 `json:"markup_percentage" db:"markup_percentage"`
 	
@@ -46,6 +47,25 @@ type UserRepository interface {
 	GetDownlines(uplineID string) ([]*User, error)
 	UpdateBalance(id string, newBalance float64) error
 	GetBalance(id string) (float64, error)
+	// GetPINHash returns the transaction PIN hash, nil when no PIN is set
+	GetPINHash(id string) (*string, error)
+	UpdatePIN(id, pinHash string) error
+	// GetAmountConfirmThreshold returns the user's own order confirmation
+	// threshold, nil when the default applies
+	GetAmountConfirmThreshold(id string) (*float64, error)
+	UpdateAmountConfirmThreshold(id string, threshold *float64) error
+	UpdatePassword(id, passwordHash string) error
+	// GetMarkupStatsByLevel summarises the markup of active users per level
+	GetMarkupStatsByLevel() ([]*LevelMarkupStats, error)
+}
+
+// LevelMarkupStats is the markup spread of the active users of one level
+type LevelMarkupStats struct {
+	Level     int     `json:"level" db:"level"`
+	Users     int     `json:"users" db:"users"`
+	MinMarkup float64 `json:"min_markup" db:"min_markup"`
+	MaxMarkup float64 `json:"max_markup" db:"max_markup"`
+	AvgMarkup float64 `json:"avg_markup" db:"avg_markup"`
 }
 
 // UserUsecase defines business logic operations for users
@@ -86,10 +106,15 @@ func (u *User) GetEffectivePrice(basePrice float64) float64 {
 	return basePrice * (1 + u.MarkupPercentage/100)
 }
 
-// HasSufficientBalance checks if user has enough balance for a transaction
+// AvailableBalance returns the balance that is not reserved by balance holds
+func (u *User) AvailableBalance() float64 {
+	return u.Balance - u.HeldBalance
+}
+
+// HasSufficientBalance checks if user has enough available balance for a transaction
 func (u *User) HasSufficientBalance(amount float64) bool {
 	if u.AllowDebt {
-		return u.Balance+u.CreditLimit >= amount
+		return u.AvailableBalance()+u.CreditLimit >= amount
 	}
-	return u.Balance >= amount
+	return u.AvailableBalance() >= amount
 }
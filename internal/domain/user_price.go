@@ -0,0 +1,84 @@
+package domain
+
+import "time"
+
+// UserProductPrice is a selling price an admin or upline fixed for one user on
+// one product. It takes precedence over the user's markup.
+type UserProductPrice struct {
+	ID          string    `json:"id" db:"id"`
+	UserID      string    `json:"user_id" db:"user_id"`
+	ProductID   string    `json:"product_id" db:"product_id"`
+	ProductCode string    `json:"product_code" db:"product_code"` // Joined from products
+	ProductName string    `json:"product_name" db:"product_name"` // Joined from products
+	Price       float64   `json:"price" db:"price"`
+	SetBy       string    `json:"set_by" db:"set_by"` // User who last set the price
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ProductTierPrice is a selling price an admin fixed for every user of one
+// level on one product. It takes precedence over the markup of those users,
+// an override for a single user takes precedence over it.
+type ProductTierPrice struct {
+	ID          string    `json:"id" db:"id"`
+	ProductID   string    `json:"product_id" db:"product_id"`
+	ProductCode string    `json:"product_code" db:"product_code"` // Joined from products
+	ProductName string    `json:"product_name" db:"product_name"` // Joined from products
+	Level       int       `json:"level" db:"level"`
+	Price       float64   `json:"price" db:"price"`
+	SetBy       string    `json:"set_by" db:"set_by"` // Admin who last set the price
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Price sources reported by price resolution, in order of precedence
+const (
+	PriceSourceOverride = "OVERRIDE"
+	PriceSourceTier     = "TIER"
+	PriceSourceMarkup   = "MARKUP"
+)
+
+// ResolvedPrice is the selling price of a product for a user and the
+// precedence layer it came from
+type ResolvedPrice struct {
+	Price  float64 `json:"price"`
+	Source string  `json:"source"` // OVERRIDE, TIER or MARKUP
+}
+
+// UserProductPriceRepository defines operations for price override data access
+type UserProductPriceRepository interface {
+	// Upsert creates the override or replaces the price of the existing one
+	// for the same user and product
+	Upsert(price *UserProductPrice) error
+	Get(userID, productID string) (*UserProductPrice, error)
+	ListByUser(userID string) ([]*UserProductPrice, error)
+	Delete(userID, productID string) error
+}
+
+// ProductTierPriceRepository defines operations for tier price data access
+type ProductTierPriceRepository interface {
+	// Upsert creates the tier price or replaces the price of the existing one
+	// for the same product and level
+	Upsert(price *ProductTierPrice) error
+	Get(productID string, level int) (*ProductTierPrice, error)
+	ListByProduct(productID string) ([]*ProductTierPrice, error)
+	Delete(productID string, level int) error
+}
+
+// UserPriceUsecase defines price override and tier price management and
+// price resolution. Admins manage overrides of any user, other users only of
+// their direct downlines. Tier prices are managed by admins.
+type UserPriceUsecase interface {
+	SetPrice(actorID string, actorLevel int, userID, productCode string, price float64) (*UserProductPrice, error)
+	DeletePrice(actorID string, actorLevel int, userID, productCode string) error
+	ListPrices(actorID string, actorLevel int, userID string) ([]*UserProductPrice, error)
+
+	SetTierPrice(adminID, productCode string, level int, price float64) (*ProductTierPrice, error)
+	DeleteTierPrice(adminID, productCode string, level int) error
+	ListTierPrices(productCode string) ([]*ProductTierPrice, error)
+
+	// ResolvePrice returns the selling price of a product for a user: an
+	// override when one is set, otherwise the tier price of the user's level,
+	// otherwise the user's markup price
+	ResolvePrice(user *User, product *Product) (*ResolvedPrice, error)
+}
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"io"
+	"time"
+)
+
+// VoucherCode is one code of a voucher product kept in stock. The code is
+// stored encrypted and only revealed as the SN of the transaction it is
+// allocated to.
+type VoucherCode struct {
+	ID            string     `json:"id" db:"id"`
+	ProductID     string     `json:"product_id" db:"product_id"`
+	CodeEncrypted string     `json:"-" db:"code_encrypted"`
+	CodeHash      string     `json:"-" db:"code_hash"` // Keyed hash, finds duplicates without decrypting
+	Status        string     `json:"status" db:"status"`
+	TransactionID *string    `json:"transaction_id" db:"transaction_id"`
+	ExpiresAt     *time.Time `json:"expires_at" db:"expires_at"`
+	UploadedBy    *string    `json:"uploaded_by" db:"uploaded_by"`
+	AllocatedAt   *time.Time `json:"allocated_at" db:"allocated_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Voucher code statuses
+const (
+	VoucherCodeAvailable = "AVAILABLE"
+	VoucherCodeAllocated = "ALLOCATED"
+)
+
+// VoucherCodeUpload is a file of codes for a product, one per line
+type VoucherCodeUpload struct {
+	ProductID  string
+	File       io.Reader
+	ExpiresAt  *time.Time // Applies to every code of the file
+	UploadedBy *string
+}
+
+// VoucherUploadResult summarises an upload
+type VoucherUploadResult struct {
+	ProductID  string `json:"product_id"`
+	Lines      int    `json:"lines"`      // Codes read from the file
+	Inserted   int    `json:"inserted"`   // New codes added to the stock
+	Duplicates int    `json:"duplicates"` // Codes already uploaded, in this file or before
+	Available  int    `json:"available"`
+}
+
+// VoucherStock counts the codes of a product by state
+type VoucherStock struct {
+	ProductID   string `json:"product_id" db:"product_id"`
+	ProductCode string `json:"product_code" db:"product_code"`
+	Available   int    `json:"available" db:"available"`
+	Allocated   int    `json:"allocated" db:"allocated"`
+	Expired     int    `json:"expired" db:"expired"` // Available codes past their expiry
+	Threshold   int    `json:"low_stock_threshold" db:"-"`
+	LowStock    bool   `json:"low_stock" db:"-"`
+}
+
+// VoucherCodeRepository defines data access for voucher code stock
+type VoucherCodeRepository interface {
+	// Create stores a code and reports false when its hash already exists
+	Create(code *VoucherCode) (bool, error)
+	// Allocate assigns the available code expiring first to a transaction,
+	// nil when the product is out of stock
+	Allocate(productID, transactionID string) (*VoucherCode, error)
+	// SyncProductStock sets the product's stock quantity to its available,
+	// unexpired codes and returns it
+	SyncProductStock(productID string) (int, error)
+	GetStock(productID string) (*VoucherStock, error)
+	// SetStockMode switches a product between the code stock and its suppliers
+	SetStockMode(productID string, enabled bool) error
+}
+
+// VoucherUsecase manages the code stock of voucher products
+type VoucherUsecase interface {
+	UploadCodes(upload *VoucherCodeUpload) (*VoucherUploadResult, error)
+	GetStock(productID string) (*VoucherStock, error)
+	// SetStockMode opts a voucher product in to or out of the code stock and
+	// returns its stock
+	SetStockMode(productID string, enabled bool) (*VoucherStock, error)
+
+	// SellsFromStock reports whether orders of the product are fulfilled
+	// from the code stock: the product opted in and the code key is set
+	SellsFromStock(product *Product) bool
+	// AllocateCode takes a code of the product for a transaction inside its
+	// completion and returns the code in clear
+	AllocateCode(repos TxRepositories, transaction *Transaction, product *Product) (string, error)
+}
+
+// EventVoucherStockLow alerts that a stocked voucher is running out
+const EventVoucherStockLow = "voucher.stock_low"
+
+// VoucherStockLowEventPayload is the payload of voucher.stock_low events
+type VoucherStockLowEventPayload struct {
+	ProductID   string `json:"product_id"`
+	ProductCode string `json:"product_code"`
+	Available   int    `json:"available"`
+	Threshold   int    `json:"threshold"`
+}
+
+// NewVoucherStockLowEvent builds the outbox event alerting a low voucher stock
+func NewVoucherStockLowEvent(product *Product, available, threshold int) (*DomainEvent, error) {
+	return NewDomainEvent(EventVoucherStockLow, AggregateTypeProduct, product.ID, &VoucherStockLowEventPayload{
+		ProductID:   product.ID,
+		ProductCode: product.Code,
+		Available:   available,
+		Threshold:   threshold,
+	})
+}
+
+// IsStockedVoucher reports whether the product is a voucher opted in to the
+// code stock instead of its suppliers
+func (p *Product) IsStockedVoucher() bool {
+	return p.Category == CategoryVoucher && p.IsVoucherStock
+}
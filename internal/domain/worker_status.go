@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// WorkerStatus describes the long running workers of one type on this replica
+type WorkerStatus struct {
+	Name        string     `json:"name"`
+	Goroutines  int        `json:"goroutines"`
+	Processed   int64      `json:"processed"` // Items handled successfully since start
+	Failed      int64      `json:"failed"`    // Passes or items that returned an error
+	LastRunAt   *time.Time `json:"last_run_at"`
+	LastError   *string    `json:"last_error"`
+	LastErrorAt *time.Time `json:"last_error_at"`
+	InFlight    []string   `json:"in_flight"` // Transaction IDs being processed
+}
+
+// WorkerMonitor exposes the counters kept by the workers of this replica
+type WorkerMonitor interface {
+	StartedAt() time.Time
+	Workers() []*WorkerStatus
+}
+
+// SystemWorkersStatus is the live worker and queue status of one replica.
+// Queue depths and the dead letter queue are shared by every replica.
+type SystemWorkersStatus struct {
+	StartedAt     time.Time        `json:"started_at"`
+	UptimeSeconds int64            `json:"uptime_seconds"`
+	Goroutines    int              `json:"goroutines"` // Every goroutine of the process
+	Workers       []*WorkerStatus  `json:"workers"`
+	QueueDepths   map[string]int64 `json:"queue_depths"`
+	DLQSize       int              `json:"dlq_size"` // Outbox events that exhausted their attempts
+	InFlight      []string         `json:"in_flight"`
+}
+
+// SystemStatusUsecase defines operations for inspecting background processing
+type SystemStatusUsecase interface {
+	GetWorkersStatus() (*SystemWorkersStatus, error)
+}
+
+// Queue names reported in SystemWorkersStatus.QueueDepths
+const (
+	QueueTransactions = "transactions"
+	QueueEvents       = "events"
+)
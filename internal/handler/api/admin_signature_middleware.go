@@ -0,0 +1,103 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// adminSignatureRequirement is recorded on security events of rejected
+// signed admin requests
+const adminSignatureRequirement = "admin_signature"
+
+// adminSignatureMiddleware requires destructive admin requests to be signed
+// with the admin's signing key on top of the JWT. The signature covers the
+// timestamp, method, request URI and body (see domain.AdminSigningPayload)
+// and is accepted once. It must run after authMiddleware and adminMiddleware.
+func adminSignatureMiddleware(signingUC domain.AdminSigningUsecase, nonceRepo domain.NonceRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if signingUC == nil {
+			xresponse.InternalServerError(c, "Admin request signing not available")
+			c.Abort()
+			return
+		}
+
+		userID := c.GetString("user_id")
+		keyID := c.GetHeader(domain.HeaderAdminKeyID)
+		timestamp := c.GetHeader(domain.HeaderAdminTimestamp)
+		signature := strings.ToLower(c.GetHeader(domain.HeaderAdminSignature))
+		if keyID == "" || timestamp == "" || signature == "" {
+			rejectAdminSignature(c, "Signed request required: "+domain.HeaderAdminKeyID+", "+
+				domain.HeaderAdminTimestamp+" and "+domain.HeaderAdminSignature+" headers are missing")
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			body, _ = io.ReadAll(c.Request.Body)
+			c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+		}
+
+		payload := domain.AdminSigningPayload(c.Request.Method, c.Request.URL.RequestURI(), body)
+		if err := signingUC.Verify(userID, keyID, timestamp, signature, payload); err != nil {
+			switch err.Error() {
+			case "invalid signing key", "invalid signature", "invalid timestamp format",
+				"timestamp expired or too far in future":
+				rejectAdminSignature(c, "Invalid request signature: "+err.Error())
+			default:
+				logger.Component(logger.ComponentAuth).Error("Failed to verify admin request signature",
+					logger.String("user_id", userID),
+					logger.ErrorField(err),
+				)
+				xresponse.InternalServerError(c, "Failed to verify request signature")
+				c.Abort()
+			}
+			return
+		}
+
+		if nonceRepo != nil {
+			reserved, err := nonceRepo.Reserve("admin:"+keyID+":"+signature, domain.SignatureTTL(timestamp, time.Now()))
+			if err != nil {
+				logger.Component(logger.ComponentAuth).Error("Failed to check admin request nonce",
+					logger.String("user_id", userID),
+					logger.ErrorField(err),
+				)
+				xresponse.Error(c, http.StatusServiceUnavailable, "NONCE_UNAVAILABLE", "Unable to verify request uniqueness")
+				c.Abort()
+				return
+			}
+			if !reserved {
+				rejectAdminSignature(c, "Request already processed")
+				return
+			}
+		}
+
+		logger.Component(logger.ComponentAuth).Info("Signed admin request accepted",
+			logger.String("user_id", userID),
+			logger.String("key_id", keyID),
+			logger.String("method", c.Request.Method),
+			logger.String("path", c.FullPath()),
+		)
+
+		c.Next()
+	}
+}
+
+func rejectAdminSignature(c *gin.Context, message string) {
+	logger.Component(logger.ComponentAuth).Warn("Admin request signature rejected",
+		logger.String("user_id", c.GetString("user_id")),
+		logger.String("path", c.FullPath()),
+		logger.String("ip", c.ClientIP()),
+		logger.String("reason", message),
+	)
+	recordAccessDenial(c, domain.DenialInvalidSignature, adminSignatureRequirement)
+	xresponse.Error(c, http.StatusUnauthorized, domain.DenialInvalidSignature, message)
+	c.Abort()
+}
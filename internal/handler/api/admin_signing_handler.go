@@ -0,0 +1,92 @@
+package api
+
+import (
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminSigningHandler handles the signing keys admins use for destructive
+// admin requests
+type AdminSigningHandler struct {
+	signingUC domain.AdminSigningUsecase
+	roleGuard *RoleGuard
+}
+
+// NewAdminSigningHandler creates a new admin signing handler
+func NewAdminSigningHandler(signingUC domain.AdminSigningUsecase) *AdminSigningHandler {
+	return &AdminSigningHandler{
+		signingUC: signingUC,
+		roleGuard: NewRoleGuard(),
+	}
+}
+
+// CreateSigningKeyRequest payload; the admin's password is checked again
+type CreateSigningKeyRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// CreateKey issues a signing key for the current admin, revoking their
+// previous one. The secret is only returned here.
+func (h *AdminSigningHandler) CreateKey(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "create_admin_signing_key", "admin")
+
+	var req CreateSigningKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	userID, _, _, _ := h.roleGuard.GetCurrentUser(c)
+	key, err := h.signingUC.CreateKey(userID, req.Password)
+	if err != nil {
+		switch err.Error() {
+		case "invalid password":
+			xresponse.InvalidCredentials(c, "Invalid password")
+		case "signing keys are only issued to admins":
+			xresponse.Forbidden(c, err.Error())
+		case "user not found":
+			xresponse.NotFound(c, err.Error())
+		default:
+			logger.Error("Failed to create admin signing key", logger.ErrorField(err))
+			xresponse.InternalServerError(c, "Failed to create signing key")
+		}
+		return
+	}
+
+	xresponse.Created(c, "Signing key created. Store the secret now, it cannot be retrieved again", key)
+}
+
+// ListKeys lists the active signing keys of every admin, without secrets
+func (h *AdminSigningHandler) ListKeys(c *gin.Context) {
+	keys, err := h.signingUC.ListKeys()
+	if err != nil {
+		logger.Error("Failed to list admin signing keys", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to list signing keys")
+		return
+	}
+	if keys == nil {
+		keys = []*domain.AdminSigningKey{}
+	}
+
+	xresponse.Success(c, "Signing keys fetched", keys)
+}
+
+// RevokeKey revokes a signing key of any admin
+func (h *AdminSigningHandler) RevokeKey(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "revoke_admin_signing_key", c.Param("id"))
+
+	userID, _, _, _ := h.roleGuard.GetCurrentUser(c)
+	if err := h.signingUC.RevokeKey(c.Param("id"), userID); err != nil {
+		if err.Error() == "admin signing key not found" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		logger.Error("Failed to revoke admin signing key", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to revoke signing key")
+		return
+	}
+
+	xresponse.Success(c, "Signing key revoked", nil)
+}
@@ -4,6 +4,8 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/alfanzaky/eraflazz/internal/domain"
 	"github.com/alfanzaky/eraflazz/internal/repository/postgres"
@@ -28,13 +30,43 @@ func (h *APIClientHandler) CreateAPIClient(c *gin.Context) {
 		ClientID             string   `json:"client_id" binding:"required"`
 		IPWhitelist          []string `json:"ip_whitelist"`
 		MaxRequestsPerMinute int      `json:"max_requests_per_minute"`
+		UserID               *string  `json:"user_id"`
+		SyncFailoverAttempts int      `json:"sync_failover_attempts"`
+		SyncFailoverBudgetMs int      `json:"sync_failover_budget_ms"`
+		Sandbox              bool     `json:"sandbox"`
+		Scopes               []string `json:"scopes"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
-		xresponse.BadRequest(c, "Invalid request format: "+err.Error())
+		respondBindingError(c, err)
 		return
 	}
 
+	if request.SyncFailoverAttempts < 0 || request.SyncFailoverAttempts > domain.MaxSyncFailoverAttempts {
+		xresponse.BadRequest(c, fmt.Sprintf("sync_failover_attempts must be between 0 and %d", domain.MaxSyncFailoverAttempts))
+		return
+	}
+	if request.SyncFailoverBudgetMs < 0 {
+		xresponse.BadRequest(c, "sync_failover_budget_ms must not be negative")
+		return
+	}
+
+	ipWhitelist, err := domain.NormalizeIPWhitelist(request.IPWhitelist)
+	if err != nil {
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	scopes := domain.DefaultH2HScopes()
+	if request.Scopes != nil {
+		normalized, err := domain.NormalizeH2HScopes(request.Scopes)
+		if err != nil {
+			xresponse.BadRequest(c, err.Error())
+			return
+		}
+		scopes = normalized
+	}
+
 	// Generate API key and secret
 	apiKey := generateRandomString(32)
 	secret := generateRandomString(64)
@@ -48,9 +80,14 @@ func (h *APIClientHandler) CreateAPIClient(c *gin.Context) {
 		ClientID:             request.ClientID,
 		APIKey:               apiKey,
 		Secret:               secret,
-		IPWhitelist:          request.IPWhitelist,
+		IPWhitelist:          ipWhitelist,
 		IsActive:             true,
 		MaxRequestsPerMinute: request.MaxRequestsPerMinute,
+		UserID:               request.UserID,
+		SyncFailoverAttempts: request.SyncFailoverAttempts,
+		SyncFailoverBudgetMs: request.SyncFailoverBudgetMs,
+		Sandbox:              request.Sandbox,
+		Scopes:               scopes,
 	}
 
 	if err := h.clientRepo.Create(c.Request.Context(), client); err != nil {
@@ -97,6 +134,187 @@ func (h *APIClientHandler) GetAPIClient(c *gin.Context) {
 	xresponse.Success(c, "API client retrieved successfully", client)
 }
 
+// UpdateScopes replaces the access scopes of an API client's key
+func (h *APIClientHandler) UpdateScopes(c *gin.Context) {
+	clientID := c.Param("client_id")
+	if clientID == "" {
+		xresponse.BadRequest(c, "Client ID is required")
+		return
+	}
+
+	var request struct {
+		Scopes []string `json:"scopes" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	scopes, err := domain.NormalizeH2HScopes(request.Scopes)
+	if err != nil {
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.clientRepo.UpdateScopes(c.Request.Context(), clientID, scopes); err != nil {
+		if err.Error() == "api client not found" {
+			xresponse.NotFound(c, "API client not found")
+			return
+		}
+		logger.Error("Failed to update API client scopes",
+			logger.String("client_id", clientID),
+			logger.ErrorField(err),
+		)
+		xresponse.InternalServerError(c, "Failed to update API client scopes")
+		return
+	}
+
+	logger.Info("API client scopes updated",
+		logger.String("client_id", clientID),
+		logger.Any("scopes", scopes),
+	)
+
+	xresponse.Success(c, "API client scopes updated successfully", gin.H{
+		"client_id": clientID,
+		"scopes":    scopes,
+	})
+}
+
+// UpdateResponseFields replaces the response projection of an API client's
+// transaction endpoints. Empty lists send every field again.
+func (h *APIClientHandler) UpdateResponseFields(c *gin.Context) {
+	clientID := c.Param("client_id")
+	if clientID == "" {
+		xresponse.BadRequest(c, "Client ID is required")
+		return
+	}
+
+	var request domain.ResponseProjection
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	projection, err := domain.NormalizeResponseProjection(request, transactionResponseFields)
+	if err != nil {
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.clientRepo.UpdateResponseFields(c.Request.Context(), clientID, projection); err != nil {
+		if err.Error() == "api client not found" {
+			xresponse.NotFound(c, "API client not found")
+			return
+		}
+		logger.Error("Failed to update API client response fields",
+			logger.String("client_id", clientID),
+			logger.ErrorField(err),
+		)
+		xresponse.InternalServerError(c, "Failed to update API client response fields")
+		return
+	}
+
+	logger.Info("API client response fields updated",
+		logger.String("client_id", clientID),
+		logger.Any("include", projection.Include),
+		logger.Any("exclude", projection.Exclude),
+	)
+
+	xresponse.Success(c, "API client response fields updated successfully", gin.H{
+		"client_id":        clientID,
+		"response_fields":  projection,
+		"available_fields": transactionResponseFields,
+	})
+}
+
+// UpdateIPWhitelist replaces the IP whitelist of an API client. An empty
+// list lifts the restriction.
+func (h *APIClientHandler) UpdateIPWhitelist(c *gin.Context) {
+	clientID := c.Param("client_id")
+	if clientID == "" {
+		xresponse.BadRequest(c, "Client ID is required")
+		return
+	}
+
+	var request struct {
+		IPWhitelist []string `json:"ip_whitelist"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	ips, err := domain.NormalizeIPWhitelist(request.IPWhitelist)
+	if err != nil {
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	change := &domain.APIClientIPChange{
+		ClientID:  clientID,
+		NewIPs:    ips,
+		ActorType: domain.IPChangeActorAdmin,
+		IPAddress: c.ClientIP(),
+	}
+	if adminID := c.GetString("user_id"); adminID != "" {
+		change.ChangedBy = &adminID
+	}
+
+	if err := h.clientRepo.UpdateIPWhitelist(c.Request.Context(), change); err != nil {
+		if err.Error() == "api client not found" {
+			xresponse.NotFound(c, "API client not found")
+			return
+		}
+		logger.Error("Failed to update API client IP whitelist",
+			logger.String("client_id", clientID),
+			logger.ErrorField(err),
+		)
+		xresponse.InternalServerError(c, "Failed to update API client IP whitelist")
+		return
+	}
+
+	logger.Component(logger.ComponentAuth).Info("API client IP whitelist updated by admin",
+		logger.String("client_id", clientID),
+		logger.Any("old_ips", change.OldIPs),
+		logger.Any("new_ips", change.NewIPs),
+		logger.String("admin_id", c.GetString("user_id")),
+	)
+
+	xresponse.Success(c, "API client IP whitelist updated successfully", change)
+}
+
+// ListIPChanges lists the IP whitelist changes of an API client, newest first
+func (h *APIClientHandler) ListIPChanges(c *gin.Context) {
+	clientID := c.Param("client_id")
+	if clientID == "" {
+		xresponse.BadRequest(c, "Client ID is required")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > 500 {
+		limit = 100
+	}
+
+	changes, err := h.clientRepo.ListIPChanges(c.Request.Context(), clientID, limit)
+	if err != nil {
+		logger.Error("Failed to list API client IP whitelist changes",
+			logger.String("client_id", clientID),
+			logger.ErrorField(err),
+		)
+		xresponse.InternalServerError(c, "Failed to list API client IP whitelist changes")
+		return
+	}
+
+	xresponse.Success(c, "API client IP whitelist changes retrieved successfully", changes)
+}
+
+// isIPWhitelistError reports whether err rejects the submitted IP whitelist
+func isIPWhitelistError(err error) bool {
+	message := err.Error()
+	return strings.Contains(message, "ip whitelist") && !strings.HasPrefix(message, "failed to")
+}
+
 // ListAPIClients lists all active API clients (admin only)
 func (h *APIClientHandler) ListAPIClients(c *gin.Context) {
 	// TODO: Implement pagination and filtering
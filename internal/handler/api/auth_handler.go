@@ -2,7 +2,9 @@ package api
 
 import (
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/alfanzaky/eraflazz/internal/domain"
@@ -13,8 +15,10 @@ import (
 )
 
 type AuthHandler struct {
-	userRepo    domain.UserRepository
-	authService domain.AuthService
+	userRepo      domain.UserRepository
+	authService   domain.AuthService
+	loginThrottle domain.LoginThrottleUsecase
+	passwordReset domain.PasswordResetUsecase
 }
 
 func (h *AuthHandler) generateUniqueUsername(email string) string {
@@ -38,8 +42,8 @@ func (h *AuthHandler) generateUniqueUsername(email string) string {
 	}
 }
 
-func NewAuthHandler(userRepo domain.UserRepository, authService domain.AuthService) *AuthHandler {
-	return &AuthHandler{userRepo: userRepo, authService: authService}
+func NewAuthHandler(userRepo domain.UserRepository, authService domain.AuthService, loginThrottle domain.LoginThrottleUsecase, passwordReset domain.PasswordResetUsecase) *AuthHandler {
+	return &AuthHandler{userRepo: userRepo, authService: authService, loginThrottle: loginThrottle, passwordReset: passwordReset}
 }
 
 type registerRequest struct {
@@ -52,7 +56,7 @@ type registerRequest struct {
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req registerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		xresponse.BadRequest(c, "Invalid payload: "+err.Error())
+		respondBindingError(c, err)
 		return
 	}
 
@@ -72,7 +76,11 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	hashedPassword := utils.HashPassword(req.Password)
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		xresponse.BadRequest(c, "Password maksimal 72 karakter")
+		return
+	}
 	username := h.generateUniqueUsername(req.Email)
 	fullName := req.Name
 
@@ -107,22 +115,41 @@ type loginRequest struct {
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req loginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		xresponse.BadRequest(c, "Invalid payload: "+err.Error())
+		respondBindingError(c, err)
 		return
 	}
 
 	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
+	clientIP := c.ClientIP()
+
+	if h.loginThrottle != nil {
+		lock, err := h.loginThrottle.CheckLocked(req.Email, clientIP)
+		if err != nil {
+			// Fail open so a Redis outage does not block every login
+			logger.Error("Failed to check login lock", logger.ErrorField(err))
+		} else if lock != nil {
+			respondLoginLocked(c, lock)
+			return
+		}
+	}
+
 	user, err := h.userRepo.GetByEmail(req.Email)
 	if err != nil || user == nil {
-		xresponse.Unauthorized(c, "Email atau password salah")
+		h.registerLoginFailure(c, req.Email, clientIP)
 		return
 	}
 
 	if !utils.VerifyPassword(req.Password, user.PasswordHash) {
-		xresponse.Unauthorized(c, "Email atau password salah")
+		h.registerLoginFailure(c, req.Email, clientIP)
 		return
 	}
 
+	if h.loginThrottle != nil {
+		if err := h.loginThrottle.RegisterSuccess(req.Email, clientIP); err != nil {
+			logger.Error("Failed to reset login failures", logger.ErrorField(err))
+		}
+	}
+
 	token, err := h.authService.GenerateAccessToken(user)
 	if err != nil {
 		logger.Error("Failed to generate token", logger.ErrorField(err))
@@ -136,3 +163,135 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		"token":   token,
 	})
 }
+
+// registerLoginFailure records a failed login and responds with 401, or 423 when
+// the failure triggered a lockout
+func (h *AuthHandler) registerLoginFailure(c *gin.Context, email, clientIP string) {
+	if h.loginThrottle != nil {
+		lock, err := h.loginThrottle.RegisterFailure(email, clientIP)
+		if err != nil {
+			logger.Error("Failed to record login failure", logger.ErrorField(err))
+		} else if lock != nil {
+			respondLoginLocked(c, lock)
+			return
+		}
+	}
+
+	xresponse.Unauthorized(c, "Email atau password salah")
+}
+
+func respondLoginLocked(c *gin.Context, lock *domain.LoginLock) {
+	seconds := int(math.Ceil(lock.RetryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	xresponse.AccountLocked(c, fmt.Sprintf("Terlalu banyak percobaan login gagal, coba lagi dalam %d detik", seconds))
+}
+
+type forgotPasswordRequest struct {
+	Email   string `json:"email" binding:"required"`
+	Channel string `json:"channel"` // EMAIL (default) or WHATSAPP
+}
+
+// ForgotPassword sends a password reset token. The response is the same
+// whether or not the email belongs to an account.
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req forgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	if h.passwordReset == nil {
+		xresponse.InternalServerError(c, "Password reset not available")
+		return
+	}
+
+	if err := h.passwordReset.RequestReset(req.Email, req.Channel, c.ClientIP()); err != nil {
+		if err.Error() == "invalid notification channel" {
+			xresponse.BadRequest(c, "Channel harus EMAIL atau WHATSAPP")
+			return
+		}
+		logger.Error("Failed to request password reset", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Gagal memproses permintaan reset password")
+		return
+	}
+
+	xresponse.Success(c, "Jika akun terdaftar, instruksi reset password telah dikirim", nil)
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// ResetPassword sets a new password with a reset token and logs out every
+// existing session of the account
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req resetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	if h.passwordReset == nil {
+		xresponse.InternalServerError(c, "Password reset not available")
+		return
+	}
+
+	if err := h.passwordReset.ResetPassword(req.Token, req.Password); err != nil {
+		switch {
+		case err.Error() == "invalid or expired reset token":
+			xresponse.BadRequest(c, "Token reset tidak valid atau sudah kedaluwarsa")
+		case strings.HasPrefix(err.Error(), "password must be"):
+			xresponse.BadRequest(c, "Password harus 8 - 72 karakter")
+		default:
+			logger.Error("Failed to reset password", logger.ErrorField(err))
+			xresponse.InternalServerError(c, "Gagal mereset password")
+		}
+		return
+	}
+
+	xresponse.Success(c, "Password berhasil direset, silakan login kembali", nil)
+}
+
+type unlockLoginRequest struct {
+	Email string `json:"email"`
+	IP    string `json:"ip"`
+}
+
+// UnlockLogin clears login lockout of an email and/or IP (admin only)
+func (h *AuthHandler) UnlockLogin(c *gin.Context) {
+	var req unlockLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
+	req.IP = strings.TrimSpace(req.IP)
+	if req.Email == "" && req.IP == "" {
+		xresponse.BadRequest(c, "email or ip is required")
+		return
+	}
+
+	if h.loginThrottle == nil {
+		xresponse.InternalServerError(c, "Login throttling not available")
+		return
+	}
+
+	if err := h.loginThrottle.Unlock(req.Email, req.IP); err != nil {
+		logger.Error("Failed to unlock login", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to unlock login")
+		return
+	}
+
+	logger.Info("Login lock cleared by admin",
+		logger.String("admin_id", c.GetString("user_id")),
+		logger.String("email", req.Email),
+		logger.String("ip", req.IP),
+	)
+
+	xresponse.Success(c, "Login unlocked", gin.H{
+		"email": req.Email,
+		"ip":    req.IP,
+	})
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// intakeStateKey holds the intake state an order request was accepted under
+const intakeStateKey = "intake_state"
+
+// intakeMiddleware attaches the current intake state to order requests and
+// advertises it in the X-Intake-Mode header. While intake is shedding, H2H
+// clients not listed as priority are turned away with 503 and Retry-After;
+// on H2H routes it must run after H2HAuth. backpressureUC may be nil.
+func intakeMiddleware(backpressureUC domain.BackpressureUsecase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if backpressureUC == nil {
+			c.Next()
+			return
+		}
+
+		state := backpressureUC.State()
+		c.Set(intakeStateKey, state)
+		c.Header("X-Intake-Mode", state.Mode)
+
+		if state.Mode != domain.IntakeShedding {
+			c.Next()
+			return
+		}
+
+		client, isH2H := GetClientFromContext(c)
+		if !isH2H || backpressureUC.IsPriorityClient(client) {
+			c.Next()
+			return
+		}
+
+		retryAfter := int(math.Ceil(state.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+
+		logger.Warn("H2H order shed - transaction queue overloaded",
+			logger.String("client_id", client.ClientID),
+			logger.Int64("backlog", state.Backlog),
+		)
+
+		xresponse.ErrorWithDetails(c, http.StatusServiceUnavailable, xresponse.ErrCodeServiceOverloaded,
+			"Transaction queue is overloaded, retry later", gin.H{
+				"retry_after": retryAfter,
+			})
+		c.Abort()
+	}
+}
+
+// applyIntakeEstimate tells the caller how long a queued order is expected
+// to wait while intake is not NORMAL
+func applyIntakeEstimate(c *gin.Context, response *TransactionResponse, trx *domain.Transaction) {
+	value, ok := c.Get(intakeStateKey)
+	if !ok || trx.Status != domain.StatusPending || trx.ScheduledAt != nil {
+		return
+	}
+	state, ok := value.(*domain.IntakeState)
+	if !ok || state.Mode == domain.IntakeNormal {
+		return
+	}
+
+	seconds := int(math.Ceil(state.EstimatedWait.Seconds()))
+	response.EstimatedWaitSeconds = &seconds
+	estimatedAt := trx.CreatedAt.Add(time.Duration(seconds) * time.Second).Format("2006-01-02 15:04:05")
+	response.EstimatedCompletionAt = &estimatedAt
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// BalanceHandler handles balance transfers and the transaction PIN
+type BalanceHandler struct {
+	transferUC domain.BalanceTransferUsecase
+	roleGuard  *RoleGuard
+}
+
+// NewBalanceHandler creates a new balance handler
+func NewBalanceHandler(transferUC domain.BalanceTransferUsecase) *BalanceHandler {
+	return &BalanceHandler{
+		transferUC: transferUC,
+		roleGuard:  NewRoleGuard(),
+	}
+}
+
+// BalanceTransferRequest payload
+type BalanceTransferRequest struct {
+	RecipientUsername string  `json:"recipient_username" binding:"required"`
+	Amount            float64 `json:"amount" binding:"required,gt=0"`
+	PIN               string  `json:"pin" binding:"required"`
+	Note              *string `json:"note"`
+}
+
+// SetPINRequest payload
+type SetPINRequest struct {
+	Password string `json:"password" binding:"required"`
+	PIN      string `json:"pin" binding:"required"`
+}
+
+// Transfer moves balance from the current user to an upline or downline
+func (h *BalanceHandler) Transfer(c *gin.Context) {
+	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req BalanceTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	h.roleGuard.LogAccess(c, "balance_transfer", req.RecipientUsername)
+
+	transfer, err := h.transferUC.Transfer(userID, req.RecipientUsername, req.Amount, req.PIN, req.Note, requestActor(c))
+	if err != nil {
+		var lockedErr *domain.PINLockedError
+		if errors.As(err, &lockedErr) {
+			seconds := int(math.Ceil(lockedErr.RetryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			xresponse.AccountLocked(c, fmt.Sprintf("Terlalu banyak PIN salah, coba lagi dalam %d detik", seconds))
+			return
+		}
+
+		switch {
+		case err.Error() == "insufficient balance":
+			xresponse.InsufficientBalance(c, "Insufficient balance for this transfer")
+		case err.Error() == "recipient not found":
+			xresponse.UserNotFound(c, "Recipient not found")
+		case err.Error() == "invalid PIN":
+			xresponse.Forbidden(c, "Invalid PIN")
+		case err.Error() == "transfers are only allowed between upline and downline":
+			xresponse.Forbidden(c, err.Error())
+		case strings.HasPrefix(err.Error(), "failed to"):
+			logger.Error("Failed to transfer balance", logger.String("user_id", userID), logger.ErrorField(err))
+			xresponse.InternalServerError(c, "Failed to transfer balance")
+		default:
+			xresponse.BadRequest(c, err.Error())
+		}
+		return
+	}
+
+	xresponse.Created(c, "Balance transferred successfully", transfer)
+}
+
+// ListTransfers lists transfers sent or received by the current user using
+// keyset pagination (cursor and limit query parameters)
+func (h *BalanceHandler) ListTransfers(c *gin.Context) {
+	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	transfers, nextCursor, err := h.transferUC.ListTransfers(userID, c.Query("cursor"), limit)
+	if err != nil {
+		if err.Error() == "invalid cursor" {
+			xresponse.BadRequest(c, err.Error())
+			return
+		}
+		logger.Error("Failed to list balance transfers", logger.String("user_id", userID), logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to retrieve transfers")
+		return
+	}
+
+	xresponse.CursorPaginated(c, "Transfers retrieved successfully", transfers, limit, nextCursor)
+}
+
+// SetPIN sets or replaces the transaction PIN of the current user
+func (h *BalanceHandler) SetPIN(c *gin.Context) {
+	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req SetPINRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	h.roleGuard.LogAccess(c, "set_transaction_pin", "own_account")
+
+	if err := h.transferUC.SetPIN(userID, req.Password, req.PIN); err != nil {
+		switch {
+		case err.Error() == "invalid password":
+			xresponse.InvalidCredentials(c, "Invalid password")
+		case strings.HasPrefix(err.Error(), "failed to"):
+			logger.Error("Failed to set transaction PIN", logger.String("user_id", userID), logger.ErrorField(err))
+			xresponse.InternalServerError(c, "Failed to set PIN")
+		default:
+			xresponse.BadRequest(c, err.Error())
+		}
+		return
+	}
+
+	xresponse.Success(c, "PIN updated successfully", nil)
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// bindingBodyField is the details key for errors that concern the request
+// body as a whole rather than one field
+const bindingBodyField = "body"
+
+var registerFieldNamesOnce sync.Once
+
+// registerBindingFieldNames makes validation errors report fields by their
+// json (or form) name instead of the Go struct field name
+func registerBindingFieldNames() {
+	registerFieldNamesOnce.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			for _, tag := range []string{"json", "form"} {
+				name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+				if name != "" && name != "-" {
+					return name
+				}
+			}
+			return ""
+		})
+	})
+}
+
+// respondBindingError responds 400 with the binding error translated to
+// field-keyed details, e.g. {"destination_number": "required"}
+func respondBindingError(c *gin.Context, err error) {
+	xresponse.ValidationError(c, bindingErrorDetails(err))
+}
+
+// bindingErrorDetails converts validator and JSON decoding errors into a map
+// of field to failed rule, without exposing Go type or struct names
+func bindingErrorDetails(err error) map[string]string {
+	details := make(map[string]string)
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		for _, fieldErr := range validationErrs {
+			rule := fieldErr.Tag()
+			if fieldErr.Param() != "" {
+				rule += "=" + fieldErr.Param()
+			}
+			details[bindingFieldPath(fieldErr.Namespace())] = rule
+		}
+		return details
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		details[typeErr.Field] = "type=" + jsonTypeName(typeErr.Type)
+		return details
+	}
+
+	if errors.Is(err, io.EOF) {
+		details[bindingBodyField] = "required"
+		return details
+	}
+
+	details[bindingBodyField] = "invalid"
+	return details
+}
+
+// bindingFieldPath drops the root struct name from a validator namespace,
+// "CreateTransactionRequest.items[0].code" becomes "items[0].code"
+func bindingFieldPath(namespace string) string {
+	if dot := strings.IndexByte(namespace, '.'); dot >= 0 {
+		return namespace[dot+1:]
+	}
+	return namespace
+}
+
+// jsonTypeName names the JSON type expected for a Go type
+func jsonTypeName(t reflect.Type) string {
+	if t == nil {
+		return "unknown"
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// bindListingRange reads the start_date and end_date query parameters
+// (YYYY-MM-DD, inclusive) of a transaction or mutation listing. Without them
+// the listing covers the last domain.DefaultListingWindow. It responds with
+// 400 and returns false when the range is invalid.
+func bindListingRange(c *gin.Context) (domain.DateRange, bool) {
+	var from, to time.Time
+	var err error
+
+	if value := c.Query("start_date"); value != "" {
+		from, err = time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			xresponse.BadRequest(c, "Invalid start_date format. Use YYYY-MM-DD")
+			return domain.DateRange{}, false
+		}
+	}
+	if value := c.Query("end_date"); value != "" {
+		to, err = time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			xresponse.BadRequest(c, "Invalid end_date format. Use YYYY-MM-DD")
+			return domain.DateRange{}, false
+		}
+		to = to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	period, err := domain.NewListingRange(from, to)
+	if err != nil {
+		xresponse.BadRequest(c, err.Error())
+		return domain.DateRange{}, false
+	}
+	return period, true
+}
+
+// bindTagFilter reads the repeatable tag query parameter (tag=key:value) of
+// a transaction listing. It responds with 400 and returns false when a tag
+// is invalid.
+func bindTagFilter(c *gin.Context) (domain.TransactionTags, bool) {
+	values := c.QueryArray("tag")
+	if len(values) == 0 {
+		return nil, true
+	}
+
+	tags := make(map[string]string, len(values))
+	for _, value := range values {
+		key, tagValue, found := strings.Cut(value, ":")
+		if !found {
+			xresponse.BadRequest(c, "Invalid tag filter. Use tag=key:value")
+			return nil, false
+		}
+		tags[key] = tagValue
+	}
+
+	normalized, err := domain.NormalizeTransactionTags(tags)
+	if err != nil {
+		xresponse.BadRequest(c, err.Error())
+		return nil, false
+	}
+	return normalized, true
+}
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// CatalogHandler handles the product category and provider endpoints: admin
+// management and the public lists behind storefront menus
+type CatalogHandler struct {
+	catalogUC domain.CatalogUsecase
+	roleGuard *RoleGuard
+}
+
+// NewCatalogHandler creates a new catalog handler
+func NewCatalogHandler(catalogUC domain.CatalogUsecase) *CatalogHandler {
+	return &CatalogHandler{
+		catalogUC: catalogUC,
+		roleGuard: NewRoleGuard(),
+	}
+}
+
+// CreateCatalogEntryRequest payload for a category or provider. The code is
+// upper-cased and cannot change later.
+type CreateCatalogEntryRequest struct {
+	Code         string  `json:"code" binding:"required"`
+	Name         string  `json:"name" binding:"required"`
+	IconURL      *string `json:"icon_url"`
+	DisplayOrder int     `json:"display_order"`
+}
+
+// UpdateCatalogEntryRequest payload; omitted fields keep their value and an
+// empty icon_url removes the icon
+type UpdateCatalogEntryRequest struct {
+	Name         *string `json:"name"`
+	IconURL      *string `json:"icon_url"`
+	DisplayOrder *int    `json:"display_order"`
+	IsActive     *bool   `json:"is_active"`
+}
+
+func (req *UpdateCatalogEntryRequest) toUpdate() *domain.CatalogEntryUpdate {
+	return &domain.CatalogEntryUpdate{
+		Name:         req.Name,
+		IconURL:      req.IconURL,
+		DisplayOrder: req.DisplayOrder,
+		IsActive:     req.IsActive,
+	}
+}
+
+// CreateCategory creates a new product category
+func (h *CatalogHandler) CreateCategory(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "create_product_category", "admin")
+
+	var req CreateCatalogEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	category := &domain.ProductCategory{
+		Code:         req.Code,
+		Name:         req.Name,
+		IconURL:      req.IconURL,
+		DisplayOrder: req.DisplayOrder,
+	}
+	if err := h.catalogUC.CreateCategory(category); err != nil {
+		respondCatalogError(c, err, "Failed to create product category")
+		return
+	}
+
+	xresponse.Created(c, "Product category created", category)
+}
+
+// ListCategories lists every product category, active or not
+func (h *CatalogHandler) ListCategories(c *gin.Context) {
+	categories, err := h.catalogUC.ListCategories(false)
+	if err != nil {
+		respondCatalogError(c, err, "Failed to list product categories")
+		return
+	}
+	if categories == nil {
+		categories = []*domain.ProductCategory{}
+	}
+
+	xresponse.Success(c, "Product categories fetched", categories)
+}
+
+// GetCategory returns a product category by code
+func (h *CatalogHandler) GetCategory(c *gin.Context) {
+	category, err := h.catalogUC.GetCategory(c.Param("code"))
+	if err != nil {
+		respondCatalogError(c, err, "Failed to get product category")
+		return
+	}
+
+	xresponse.Success(c, "Product category fetched", category)
+}
+
+// UpdateCategory changes the name, icon, display order or state of a category
+func (h *CatalogHandler) UpdateCategory(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "update_product_category", "admin")
+
+	var req UpdateCatalogEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	category, err := h.catalogUC.UpdateCategory(c.Param("code"), req.toUpdate())
+	if err != nil {
+		respondCatalogError(c, err, "Failed to update product category")
+		return
+	}
+
+	xresponse.Success(c, "Product category updated", category)
+}
+
+// CreateProvider creates a new product provider
+func (h *CatalogHandler) CreateProvider(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "create_product_provider", "admin")
+
+	var req CreateCatalogEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	provider := &domain.ProductProvider{
+		Code:         req.Code,
+		Name:         req.Name,
+		IconURL:      req.IconURL,
+		DisplayOrder: req.DisplayOrder,
+	}
+	if err := h.catalogUC.CreateProvider(provider); err != nil {
+		respondCatalogError(c, err, "Failed to create product provider")
+		return
+	}
+
+	xresponse.Created(c, "Product provider created", provider)
+}
+
+// ListProviders lists every product provider, optionally of one category
+func (h *CatalogHandler) ListProviders(c *gin.Context) {
+	h.listProviders(c, false)
+}
+
+// GetProvider returns a product provider by code
+func (h *CatalogHandler) GetProvider(c *gin.Context) {
+	provider, err := h.catalogUC.GetProvider(c.Param("code"))
+	if err != nil {
+		respondCatalogError(c, err, "Failed to get product provider")
+		return
+	}
+
+	xresponse.Success(c, "Product provider fetched", provider)
+}
+
+// UpdateProvider changes the name, icon, display order or state of a provider
+func (h *CatalogHandler) UpdateProvider(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "update_product_provider", "admin")
+
+	var req UpdateCatalogEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	provider, err := h.catalogUC.UpdateProvider(c.Param("code"), req.toUpdate())
+	if err != nil {
+		respondCatalogError(c, err, "Failed to update product provider")
+		return
+	}
+
+	xresponse.Success(c, "Product provider updated", provider)
+}
+
+// ListPublicCategories lists the active categories for storefront menus
+func (h *CatalogHandler) ListPublicCategories(c *gin.Context) {
+	categories, err := h.catalogUC.ListCategories(true)
+	if err != nil {
+		respondCatalogError(c, err, "Failed to list product categories")
+		return
+	}
+	if categories == nil {
+		categories = []*domain.ProductCategory{}
+	}
+
+	xresponse.Success(c, "Product categories fetched", categories)
+}
+
+// ListPublicProviders lists the active providers for storefront menus. With
+// ?category= only the providers selling an active product in it are listed.
+func (h *CatalogHandler) ListPublicProviders(c *gin.Context) {
+	h.listProviders(c, true)
+}
+
+func (h *CatalogHandler) listProviders(c *gin.Context, activeOnly bool) {
+	providers, err := h.catalogUC.ListProviders(activeOnly, c.Query("category"))
+	if err != nil {
+		respondCatalogError(c, err, "Failed to list product providers")
+		return
+	}
+	if providers == nil {
+		providers = []*domain.ProductProvider{}
+	}
+
+	xresponse.Success(c, "Product providers fetched", providers)
+}
+
+// respondCatalogError maps catalog errors to responses
+func respondCatalogError(c *gin.Context, err error, failure string) {
+	message := err.Error()
+	switch {
+	case strings.HasSuffix(message, "not found"):
+		xresponse.NotFound(c, message)
+	case strings.HasSuffix(message, "already exists"):
+		xresponse.Conflict(c, message)
+	case strings.HasPrefix(message, "failed to"):
+		logger.Error(failure, logger.ErrorField(err))
+		xresponse.InternalServerError(c, failure)
+	default:
+		xresponse.BadRequest(c, message)
+	}
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"time"
+
+	"github.com/alfanzaky/eraflazz/pkg/chaos"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// ChaosHandler toggles fault injection at runtime. It is only routed when
+// chaos is enabled, which configuration refuses in production.
+type ChaosHandler struct {
+	injector  *chaos.Injector
+	roleGuard *RoleGuard
+}
+
+// NewChaosHandler creates a new chaos handler
+func NewChaosHandler(injector *chaos.Injector) *ChaosHandler {
+	return &ChaosHandler{
+		injector:  injector,
+		roleGuard: NewRoleGuard(),
+	}
+}
+
+// SetFaultRequest payload. Latency and Duration are Go durations such as
+// 500ms or 10m; Duration limits how long the fault stays active.
+type SetFaultRequest struct {
+	Latency   string  `json:"latency"`
+	ErrorRate float64 `json:"error_rate"`
+	Scope     string  `json:"scope"`
+	Duration  string  `json:"duration"`
+}
+
+// ListFaults lists the active faults
+func (h *ChaosHandler) ListFaults(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "list_chaos_faults", "admin")
+
+	xresponse.Success(c, "Chaos faults fetched", h.injector.List())
+}
+
+// SetFault replaces the fault of a target (supplier, redis or database)
+func (h *ChaosHandler) SetFault(c *gin.Context) {
+	target := c.Param("target")
+	h.roleGuard.LogAccess(c, "set_chaos_fault", target)
+
+	var req SetFaultRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	fault := chaos.Fault{
+		Target:    target,
+		ErrorRate: req.ErrorRate,
+		Scope:     req.Scope,
+	}
+	if req.Latency != "" {
+		latency, err := time.ParseDuration(req.Latency)
+		if err != nil {
+			xresponse.BadRequest(c, "latency must be a duration, e.g. 500ms or 5s")
+			return
+		}
+		fault.Latency = latency
+	}
+	if req.Duration != "" {
+		duration, err := time.ParseDuration(req.Duration)
+		if err != nil || duration <= 0 {
+			xresponse.BadRequest(c, "duration must be a positive duration, e.g. 10m")
+			return
+		}
+		expiresAt := time.Now().Add(duration)
+		fault.ExpiresAt = &expiresAt
+	}
+
+	if err := h.injector.Set(fault); err != nil {
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	logger.Warn("Chaos fault set",
+		logger.String("target", fault.Target),
+		logger.Duration("latency", fault.Latency),
+		logger.Float64("error_rate", fault.ErrorRate),
+		logger.String("scope", fault.Scope),
+	)
+
+	xresponse.Success(c, "Chaos fault set", fault)
+}
+
+// ClearFault removes the fault of a target
+func (h *ChaosHandler) ClearFault(c *gin.Context) {
+	target := c.Param("target")
+	h.roleGuard.LogAccess(c, "clear_chaos_fault", target)
+
+	if !chaos.IsValidTarget(target) {
+		xresponse.BadRequest(c, "invalid chaos target")
+		return
+	}
+
+	h.injector.Clear(target)
+	logger.Warn("Chaos fault cleared", logger.String("target", target))
+
+	xresponse.Success(c, "Chaos fault cleared", gin.H{"target": target})
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// compressionMinSize is the smallest body worth compressing; below it the
+// gzip framing costs more than it saves
+const compressionMinSize = 1024
+
+var gzipWriterPool = sync.Pool{
+	New: func() interface{} {
+		return gzip.NewWriter(nil)
+	},
+}
+
+// bufferedResponseWriter holds the body back so the middleware can decide on
+// the encoding once the handler is done
+type bufferedResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedResponseWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// compressionMiddleware gzips responses for clients that accept it. Bodies
+// under compressionMinSize, bodiless statuses and responses that already carry
+// a Content-Encoding are passed through unchanged.
+func compressionMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Vary", "Accept-Encoding")
+
+		if c.Request.Method == http.MethodHead || !acceptsGzip(c.GetHeader("Accept-Encoding")) {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		writer := &bufferedResponseWriter{ResponseWriter: original}
+		c.Writer = writer
+		c.Next()
+		c.Writer = original
+
+		status := original.Status()
+		body := writer.body.Bytes()
+		if len(body) < compressionMinSize ||
+			status == http.StatusNoContent || status == http.StatusNotModified ||
+			original.Header().Get("Content-Encoding") != "" {
+			if len(body) == 0 {
+				original.WriteHeaderNow()
+				return
+			}
+			if _, err := original.Write(body); err != nil {
+				logger.Warn("Failed to write response body", logger.ErrorField(err))
+			}
+			return
+		}
+
+		gz := gzipWriterPool.Get().(*gzip.Writer)
+		defer gzipWriterPool.Put(gz)
+
+		var compressed bytes.Buffer
+		gz.Reset(&compressed)
+		_, err := gz.Write(body)
+		if err == nil {
+			err = gz.Close()
+		}
+		if err != nil {
+			logger.Warn("Failed to compress response", logger.ErrorField(err))
+			if _, err := original.Write(body); err != nil {
+				logger.Warn("Failed to write response body", logger.ErrorField(err))
+			}
+			return
+		}
+
+		header := original.Header()
+		header.Set("Content-Encoding", "gzip")
+		header.Set("Content-Length", strconv.Itoa(compressed.Len()))
+		if _, err := original.Write(compressed.Bytes()); err != nil {
+			logger.Warn("Failed to write compressed response", logger.ErrorField(err))
+		}
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip, honouring
+// an explicit q=0 refusal
+func acceptsGzip(acceptEncoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(fields[0]))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+
+		refused := false
+		for _, param := range fields[1:] {
+			param = strings.ReplaceAll(strings.TrimSpace(param), " ", "")
+			if q, ok := strings.CutPrefix(param, "q="); ok {
+				if value, err := strconv.ParseFloat(q, 64); err == nil && value == 0 {
+					refused = true
+				}
+			}
+		}
+		if !refused {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// CutoffScheduleHandler handles admin cutoff hours and ops calendar endpoints
+type CutoffScheduleHandler struct {
+	cutoffUC  domain.CutoffUsecase
+	roleGuard *RoleGuard
+}
+
+// NewCutoffScheduleHandler creates a new cutoff schedule handler
+func NewCutoffScheduleHandler(cutoffUC domain.CutoffUsecase) *CutoffScheduleHandler {
+	return &CutoffScheduleHandler{
+		cutoffUC:  cutoffUC,
+		roleGuard: NewRoleGuard(),
+	}
+}
+
+// CreateCutoffScheduleRequest payload. DAILY windows take start_time and
+// end_time (HH:MM), ONCE windows take starts_at and ends_at.
+type CreateCutoffScheduleRequest struct {
+	ScopeType  string     `json:"scope_type" binding:"required"`
+	ScopeValue string     `json:"scope_value" binding:"required"`
+	Kind       string     `json:"kind" binding:"required"`
+	StartTime  *string    `json:"start_time"`
+	EndTime    *string    `json:"end_time"`
+	StartsAt   *time.Time `json:"starts_at"`
+	EndsAt     *time.Time `json:"ends_at"`
+	Action     string     `json:"action"`
+	Reason     *string    `json:"reason"`
+}
+
+// UpdateCutoffScheduleRequest payload; omitted fields keep their value
+type UpdateCutoffScheduleRequest struct {
+	Kind      *string    `json:"kind"`
+	StartTime *string    `json:"start_time"`
+	EndTime   *string    `json:"end_time"`
+	StartsAt  *time.Time `json:"starts_at"`
+	EndsAt    *time.Time `json:"ends_at"`
+	Action    *string    `json:"action"`
+	Reason    *string    `json:"reason"`
+	IsActive  *bool      `json:"is_active"`
+}
+
+// CreateSchedule creates a new cutoff window
+func (h *CutoffScheduleHandler) CreateSchedule(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "create_cutoff_schedule", "admin")
+
+	var req CreateCutoffScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	schedule := &domain.CutoffSchedule{
+		ScopeType:  req.ScopeType,
+		ScopeValue: req.ScopeValue,
+		Kind:       req.Kind,
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+		StartsAt:   req.StartsAt,
+		EndsAt:     req.EndsAt,
+		Action:     req.Action,
+		Reason:     req.Reason,
+	}
+	if userID, _, _, exists := h.roleGuard.GetCurrentUser(c); exists && userID != "" {
+		schedule.CreatedBy = &userID
+	}
+
+	if err := h.cutoffUC.CreateSchedule(schedule); err != nil {
+		logger.Error("Failed to create cutoff schedule", logger.ErrorField(err))
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Created(c, "Cutoff schedule created", schedule)
+}
+
+// ListSchedules lists cutoff windows filtered by scope_type and scope_value
+func (h *CutoffScheduleHandler) ListSchedules(c *gin.Context) {
+	schedules, err := h.cutoffUC.ListSchedules(c.Query("scope_type"), c.Query("scope_value"))
+	if err != nil {
+		logger.Error("Failed to list cutoff schedules", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to list cutoff schedules")
+		return
+	}
+
+	xresponse.Success(c, "Cutoff schedules fetched", schedules)
+}
+
+// GetSchedule returns a cutoff window by ID
+func (h *CutoffScheduleHandler) GetSchedule(c *gin.Context) {
+	schedule, err := h.cutoffUC.GetSchedule(c.Param("id"))
+	if err != nil {
+		xresponse.NotFound(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Cutoff schedule fetched", schedule)
+}
+
+// UpdateSchedule changes the window, action or state of a cutoff
+func (h *CutoffScheduleHandler) UpdateSchedule(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "update_cutoff_schedule", "admin")
+
+	var req UpdateCutoffScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	schedule, err := h.cutoffUC.UpdateSchedule(c.Param("id"), &domain.CutoffScheduleUpdate{
+		Kind:      req.Kind,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+		Action:    req.Action,
+		Reason:    req.Reason,
+		IsActive:  req.IsActive,
+	})
+	if err != nil {
+		if err.Error() == "cutoff schedule not found" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Cutoff schedule updated", schedule)
+}
+
+// DeleteSchedule removes a cutoff window
+func (h *CutoffScheduleHandler) DeleteSchedule(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "delete_cutoff_schedule", "admin")
+
+	id := c.Param("id")
+	if err := h.cutoffUC.DeleteSchedule(id); err != nil {
+		if err.Error() == "cutoff schedule not found" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Cutoff schedule deleted", gin.H{"schedule_id": id})
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// DestinationRuleHandler handles destination format rules per product category
+type DestinationRuleHandler struct {
+	destinationRuleUC domain.DestinationRuleUsecase
+	roleGuard         *RoleGuard
+}
+
+// NewDestinationRuleHandler creates a new destination rule handler
+func NewDestinationRuleHandler(destinationRuleUC domain.DestinationRuleUsecase) *DestinationRuleHandler {
+	return &DestinationRuleHandler{
+		destinationRuleUC: destinationRuleUC,
+		roleGuard:         NewRoleGuard(),
+	}
+}
+
+// DestinationRuleRequest payload
+type DestinationRuleRequest struct {
+	Pattern        string `json:"pattern"`
+	MinLength      int    `json:"min_length"`
+	MaxLength      int    `json:"max_length"`
+	Hint           string `json:"hint" binding:"required"`
+	NormalizePhone bool   `json:"normalize_phone"`
+}
+
+// ListRules lists the destination rule of every category
+func (h *DestinationRuleHandler) ListRules(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "list_destination_rules", "admin")
+
+	rules, err := h.destinationRuleUC.ListRules()
+	if err != nil {
+		logger.Error("Failed to list destination rules", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to list destination rules")
+		return
+	}
+
+	xresponse.Success(c, "Destination rules fetched", rules)
+}
+
+// UpdateRule creates or replaces the destination rule of a category
+func (h *DestinationRuleHandler) UpdateRule(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "update_destination_rule", c.Param("category"))
+
+	var req DestinationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	rule := &domain.DestinationRule{
+		Category:       c.Param("category"),
+		Pattern:        req.Pattern,
+		MinLength:      req.MinLength,
+		MaxLength:      req.MaxLength,
+		Hint:           req.Hint,
+		NormalizePhone: req.NormalizePhone,
+	}
+
+	if err := h.destinationRuleUC.UpdateRule(rule); err != nil {
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Destination rule updated", rule)
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// DownlineHandler handles the downline tree and statistics endpoints. Users
+// query their own network (or a user within it) with ?user_id=; admins use
+// the /admin/users/:id routes for any user.
+type DownlineHandler struct {
+	downlineUC domain.DownlineUsecase
+	roleGuard  *RoleGuard
+}
+
+// NewDownlineHandler creates a new downline handler
+func NewDownlineHandler(downlineUC domain.DownlineUsecase) *DownlineHandler {
+	return &DownlineHandler{
+		downlineUC: downlineUC,
+		roleGuard:  NewRoleGuard(),
+	}
+}
+
+// GetTree returns one page of the downline tree, shallowest levels first.
+// Query: depth (levels below the user), page, limit.
+func (h *DownlineHandler) GetTree(c *gin.Context) {
+	actorID, _, actorLevel, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	depth, ok := parseDepth(c)
+	if !ok {
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	nodes, total, err := h.downlineUC.GetTree(actorID, actorLevel, h.targetUser(c), depth, page, limit)
+	if err != nil {
+		respondDownlineError(c, err, "Failed to get downline tree")
+		return
+	}
+	if nodes == nil {
+		nodes = []*domain.DownlineNode{}
+	}
+
+	xresponse.Paginated(c, "Downline tree fetched", nodes, page, limit, total)
+}
+
+// GetStats returns downline counts per level and their transaction volume
+// and the user's commissions. Query: depth, start_date and end_date
+// (YYYY-MM-DD, inclusive, default current month).
+func (h *DownlineHandler) GetStats(c *gin.Context) {
+	actorID, _, actorLevel, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	depth, ok := parseDepth(c)
+	if !ok {
+		return
+	}
+
+	var startDate, endDate time.Time
+	var err error
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		startDate, err = time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			xresponse.BadRequest(c, "Invalid start_date format. Use YYYY-MM-DD")
+			return
+		}
+	}
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		endDate, err = time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			xresponse.BadRequest(c, "Invalid end_date format. Use YYYY-MM-DD")
+			return
+		}
+	}
+
+	stats, err := h.downlineUC.GetStats(actorID, actorLevel, h.targetUser(c), depth, startDate, endDate)
+	if err != nil {
+		respondDownlineError(c, err, "Failed to get downline stats")
+		return
+	}
+
+	xresponse.Success(c, "Downline stats fetched", stats)
+}
+
+// targetUser returns the user whose network is queried; empty means the caller
+func (h *DownlineHandler) targetUser(c *gin.Context) string {
+	if id := c.Param("id"); id != "" {
+		return id
+	}
+	return c.Query("user_id")
+}
+
+func parseDepth(c *gin.Context) (int, bool) {
+	raw := c.Query("depth")
+	if raw == "" {
+		return 0, true
+	}
+	depth, err := strconv.Atoi(raw)
+	if err != nil || depth < 1 {
+		xresponse.BadRequest(c, "depth must be a positive number")
+		return 0, false
+	}
+	return depth, true
+}
+
+// respondDownlineError maps downline errors to responses
+func respondDownlineError(c *gin.Context, err error, failure string) {
+	message := err.Error()
+	switch {
+	case message == "user not found":
+		xresponse.NotFound(c, message)
+	case message == "user is not in your downline":
+		xresponse.Forbidden(c, message)
+	case strings.HasPrefix(message, "depth must"), message == "end date must not be before start date",
+		message == "stats range too large":
+		xresponse.BadRequest(c, message)
+	default:
+		logger.Error(failure, logger.ErrorField(err))
+		xresponse.InternalServerError(c, failure)
+	}
+}
@@ -0,0 +1,225 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/metrics"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// FavoriteHandler handles saved favorites and quick orders
+type FavoriteHandler struct {
+	favoriteUC domain.FavoriteUsecase
+	roleGuard  *RoleGuard
+}
+
+// NewFavoriteHandler creates a new favorite handler
+func NewFavoriteHandler(favoriteUC domain.FavoriteUsecase) *FavoriteHandler {
+	return &FavoriteHandler{
+		favoriteUC: favoriteUC,
+		roleGuard:  NewRoleGuard(),
+	}
+}
+
+// CreateFavoriteRequest payload
+type CreateFavoriteRequest struct {
+	ProductCode       string  `json:"product_code" binding:"required"`
+	DestinationNumber *string `json:"destination_number"`
+	Alias             *string `json:"alias"`
+}
+
+// UpdateFavoriteRequest payload; an empty string clears the field
+type UpdateFavoriteRequest struct {
+	DestinationNumber *string `json:"destination_number"`
+	Alias             *string `json:"alias"`
+}
+
+// QuickOrderRequest payload; both fields are optional
+type QuickOrderRequest struct {
+	DestinationNumber *string           `json:"destination_number"` // Overrides the saved destination
+	Channel           string            `json:"channel,omitempty"`
+	AllowDuplicate    bool              `json:"allow_duplicate,omitempty"` // Confirms repeating a recent order
+	Tags              map[string]string `json:"tags,omitempty"`
+	ConfirmationToken string            `json:"confirmation_token,omitempty"` // Confirms an order above the amount threshold
+	PIN               string            `json:"pin,omitempty"`                // Confirms it with the transaction PIN instead
+}
+
+// ListFavorites lists the favorites of the current user
+func (h *FavoriteHandler) ListFavorites(c *gin.Context) {
+	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	favorites, err := h.favoriteUC.ListFavorites(userID)
+	if err != nil {
+		logger.Error("Failed to list favorites", logger.String("user_id", userID), logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to list favorites")
+		return
+	}
+
+	xresponse.Success(c, "Favorites fetched", favorites)
+}
+
+// CreateFavorite saves a product and optional destination for the current user
+func (h *FavoriteHandler) CreateFavorite(c *gin.Context) {
+	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req CreateFavoriteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	favorite, err := h.favoriteUC.CreateFavorite(userID, req.ProductCode, req.DestinationNumber, req.Alias)
+	if err != nil {
+		respondFavoriteError(c, err)
+		return
+	}
+
+	xresponse.Created(c, "Favorite created", favorite)
+}
+
+// UpdateFavorite changes the destination or alias of a favorite
+func (h *FavoriteHandler) UpdateFavorite(c *gin.Context) {
+	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req UpdateFavoriteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	favorite, err := h.favoriteUC.UpdateFavorite(userID, c.Param("id"), req.DestinationNumber, req.Alias)
+	if err != nil {
+		respondFavoriteError(c, err)
+		return
+	}
+
+	xresponse.Success(c, "Favorite updated", favorite)
+}
+
+// DeleteFavorite removes a favorite of the current user
+func (h *FavoriteHandler) DeleteFavorite(c *gin.Context) {
+	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id := c.Param("id")
+	if err := h.favoriteUC.DeleteFavorite(userID, id); err != nil {
+		if err.Error() == "favorite not found" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		logger.Error("Failed to delete favorite", logger.String("favorite_id", id), logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to delete favorite")
+		return
+	}
+
+	xresponse.Success(c, "Favorite deleted", gin.H{"favorite_id": id})
+}
+
+// QuickOrder creates a transaction from a favorite of the current user
+func (h *FavoriteHandler) QuickOrder(c *gin.Context) {
+	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "Authentication required")
+		return
+	}
+
+	var req QuickOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondBindingError(c, err)
+		return
+	}
+
+	channel := strings.ToUpper(req.Channel)
+	if channel == domain.ChannelH2H {
+		xresponse.BadRequest(c, "Invalid channel")
+		return
+	}
+
+	tags, err := domain.NormalizeTransactionTags(req.Tags)
+	if err != nil {
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	favoriteID := c.Param("favorite_id")
+	h.roleGuard.LogAccess(c, "quick_order", favoriteID)
+
+	transaction, err := h.favoriteUC.QuickOrder(orderContext(c, req.AllowDuplicate, tags, domain.OrderConfirmation{Token: req.ConfirmationToken, PIN: req.PIN}), userID, favoriteID, req.DestinationNumber, channel)
+	if err != nil {
+		logger.Error("Failed to create quick order",
+			logger.String("user_id", userID),
+			logger.String("favorite_id", favoriteID),
+			logger.ErrorField(err),
+		)
+
+		switch err.Error() {
+		case "favorite not found":
+			xresponse.NotFound(c, "Favorite not found")
+		case "destination number is required":
+			xresponse.BadRequest(c, "Destination number is required for this favorite")
+		default:
+			respondCreateTransactionError(c, err)
+		}
+		return
+	}
+
+	userRole, _ := c.Get("user_role")
+	roleStr, _ := userRole.(string)
+	metrics.RecordTransaction(transaction.Status, "unknown", roleStr, transaction.SellingPrice)
+
+	logger.Info("Transaction created via quick order",
+		logger.String("trx_id", transaction.ID),
+		logger.String("trx_code", transaction.TrxCode),
+		logger.String("favorite_id", favoriteID),
+		logger.String("user_id", userID),
+	)
+
+	xresponse.Created(c, "Transaction created successfully", buildTransactionResponse(transaction))
+}
+
+// respondFavoriteError maps favorite create/update errors to responses
+func respondFavoriteError(c *gin.Context, err error) {
+	var destinationErr *domain.DestinationError
+	if errors.As(err, &destinationErr) {
+		message := "Invalid destination number"
+		if destinationErr.Hint != "" {
+			message += ". Expected format: " + destinationErr.Hint
+		}
+		xresponse.BadRequest(c, message)
+		return
+	}
+
+	switch {
+	case err.Error() == "favorite not found":
+		xresponse.NotFound(c, "Favorite not found")
+	case err.Error() == "product not found":
+		xresponse.InvalidProduct(c, "Product not found or unavailable")
+	case strings.Contains(err.Error(), "duplicate key"):
+		xresponse.Conflict(c, "This product and destination is already a favorite")
+	case strings.HasPrefix(err.Error(), "failed to"):
+		logger.Error("Failed to save favorite", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to save favorite")
+	default:
+		xresponse.BadRequest(c, err.Error())
+	}
+}
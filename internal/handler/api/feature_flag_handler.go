@@ -0,0 +1,144 @@
+package api
+
+import (
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// featureFlags gates the behaviors handlers choose; nil leaves them on
+var featureFlags domain.FeatureFlagEvaluator
+
+// SetFeatureFlags registers the evaluator of the flags gating handler behaviors
+func SetFeatureFlags(flags domain.FeatureFlagEvaluator) {
+	featureFlags = flags
+}
+
+// syncFailoverPolicy returns the client's sync failover policy while the sync
+// failover flag is on for the client's account
+func syncFailoverPolicy(client *domain.APIClient) *domain.SyncFailoverPolicy {
+	policy := client.SyncFailoverPolicy()
+	if policy == nil || featureFlags == nil {
+		return policy
+	}
+
+	userID := ""
+	if client.UserID != nil {
+		userID = *client.UserID
+	}
+	if !featureFlags.IsEnabled(domain.FlagH2HSyncFailover, userID) {
+		return nil
+	}
+	return policy
+}
+
+// FeatureFlagHandler handles admin feature flag endpoints
+type FeatureFlagHandler struct {
+	flagUC    domain.FeatureFlagUsecase
+	roleGuard *RoleGuard
+}
+
+// NewFeatureFlagHandler creates a new feature flag handler
+func NewFeatureFlagHandler(flagUC domain.FeatureFlagUsecase) *FeatureFlagHandler {
+	return &FeatureFlagHandler{
+		flagUC:    flagUC,
+		roleGuard: NewRoleGuard(),
+	}
+}
+
+// SetFeatureFlagRequest payload. Omit environment for the environment of the
+// server; rollout_percent defaults to 100.
+type SetFeatureFlagRequest struct {
+	Environment    string   `json:"environment"`
+	Description    string   `json:"description"`
+	Enabled        *bool    `json:"enabled" binding:"required"`
+	RolloutPercent *int     `json:"rollout_percent"`
+	UserIDs        []string `json:"user_ids"`
+}
+
+// ListFlags lists every known flag of the environment query parameter, or of
+// the server's environment
+func (h *FeatureFlagHandler) ListFlags(c *gin.Context) {
+	flags, err := h.flagUC.ListFlags(c.Query("environment"))
+	if err != nil {
+		logger.Error("Failed to list feature flags", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to list feature flags")
+		return
+	}
+
+	xresponse.Success(c, "Feature flags fetched", flags)
+}
+
+// SetFlag stores the settings of a flag, replacing the previous ones
+func (h *FeatureFlagHandler) SetFlag(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "set_feature_flag", "admin")
+
+	var req SetFeatureFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	flag := &domain.FeatureFlag{
+		Key:            c.Param("key"),
+		Environment:    req.Environment,
+		Description:    req.Description,
+		Enabled:        *req.Enabled,
+		RolloutPercent: 100,
+		UserIDs:        req.UserIDs,
+	}
+	if req.RolloutPercent != nil {
+		flag.RolloutPercent = *req.RolloutPercent
+	}
+	if userID, _, _, exists := h.roleGuard.GetCurrentUser(c); exists && userID != "" {
+		flag.UpdatedBy = &userID
+	}
+
+	flag, err := h.flagUC.SetFlag(flag)
+	if err != nil {
+		if err.Error() == "unknown feature flag" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		logger.Error("Failed to set feature flag", logger.ErrorField(err))
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Feature flag saved", flag)
+}
+
+// DeleteFlag removes a stored flag, returning it to its default
+func (h *FeatureFlagHandler) DeleteFlag(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "delete_feature_flag", "admin")
+
+	if err := h.flagUC.DeleteFlag(c.Param("key"), c.Query("environment")); err != nil {
+		if err.Error() == "feature flag not found" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		logger.Error("Failed to delete feature flag", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to delete feature flag")
+		return
+	}
+
+	xresponse.Success(c, "Feature flag deleted", nil)
+}
+
+// EvaluateFlag shows whether a flag is on for the user_id query parameter in
+// the server's environment
+func (h *FeatureFlagHandler) EvaluateFlag(c *gin.Context) {
+	evaluation, err := h.flagUC.Evaluate(c.Param("key"), c.Query("user_id"))
+	if err != nil {
+		if err.Error() == "unknown feature flag" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		logger.Error("Failed to evaluate feature flag", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to evaluate feature flag")
+		return
+	}
+
+	xresponse.Success(c, "Feature flag evaluated", evaluation)
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// FeeHandler handles admin fee rule endpoints
+type FeeHandler struct {
+	feeUC     domain.FeeUsecase
+	roleGuard *RoleGuard
+}
+
+// NewFeeHandler creates a new fee handler
+func NewFeeHandler(feeUC domain.FeeUsecase) *FeeHandler {
+	return &FeeHandler{
+		feeUC:     feeUC,
+		roleGuard: NewRoleGuard(),
+	}
+}
+
+// FeeRuleRequest payload for creating or replacing a fee rule. is_active
+// defaults to true.
+type FeeRuleRequest struct {
+	Name      string   `json:"name" binding:"required"`
+	Category  *string  `json:"category"`
+	UserLevel *int     `json:"user_level"`
+	FeeType   string   `json:"fee_type" binding:"required"`
+	Amount    float64  `json:"amount"`
+	MinFee    *float64 `json:"min_fee"`
+	MaxFee    *float64 `json:"max_fee"`
+	Priority  int      `json:"priority"`
+	IsActive  *bool    `json:"is_active"`
+}
+
+func (req *FeeRuleRequest) toFeeRule() *domain.FeeRule {
+	rule := &domain.FeeRule{
+		Name:      req.Name,
+		Category:  req.Category,
+		UserLevel: req.UserLevel,
+		FeeType:   req.FeeType,
+		Amount:    req.Amount,
+		MinFee:    req.MinFee,
+		MaxFee:    req.MaxFee,
+		Priority:  req.Priority,
+		IsActive:  true,
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+	return rule
+}
+
+// CreateRule creates a new fee rule
+func (h *FeeHandler) CreateRule(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "create_fee_rule", "admin")
+
+	var req FeeRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	rule := req.toFeeRule()
+	if userID, _, _, exists := h.roleGuard.GetCurrentUser(c); exists && userID != "" {
+		rule.CreatedBy = &userID
+	}
+
+	if err := h.feeUC.CreateRule(rule); err != nil {
+		logger.Error("Failed to create fee rule", logger.ErrorField(err))
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Created(c, "Fee rule created", rule)
+}
+
+// ListRules lists all fee rules
+func (h *FeeHandler) ListRules(c *gin.Context) {
+	rules, err := h.feeUC.ListRules()
+	if err != nil {
+		logger.Error("Failed to list fee rules", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to list fee rules")
+		return
+	}
+
+	xresponse.Success(c, "Fee rules fetched", rules)
+}
+
+// GetRule returns a fee rule by ID
+func (h *FeeHandler) GetRule(c *gin.Context) {
+	rule, err := h.feeUC.GetRule(c.Param("id"))
+	if err != nil {
+		if err.Error() == "fee rule not found" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		logger.Error("Failed to get fee rule", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to get fee rule")
+		return
+	}
+
+	xresponse.Success(c, "Fee rule fetched", rule)
+}
+
+// UpdateRule replaces a fee rule
+func (h *FeeHandler) UpdateRule(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "update_fee_rule", "admin")
+
+	var req FeeRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	rule := req.toFeeRule()
+	rule.ID = c.Param("id")
+
+	updated, err := h.feeUC.UpdateRule(rule)
+	if err != nil {
+		if err.Error() == "fee rule not found" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Fee rule updated", updated)
+}
+
+// DeleteRule removes a fee rule
+func (h *FeeHandler) DeleteRule(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "delete_fee_rule", "admin")
+
+	id := c.Param("id")
+	if err := h.feeUC.DeleteRule(id); err != nil {
+		if err.Error() == "fee rule not found" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Fee rule deleted", gin.H{"rule_id": id})
+}
+
+// QuoteFee previews the admin fee for a category, user level and selling price
+func (h *FeeHandler) QuoteFee(c *gin.Context) {
+	level, err := strconv.Atoi(c.Query("user_level"))
+	if err != nil || !domain.IsValidLevel(level) {
+		xresponse.BadRequest(c, "Invalid user_level")
+		return
+	}
+
+	sellingPrice, err := strconv.ParseFloat(c.Query("selling_price"), 64)
+	if err != nil || sellingPrice <= 0 {
+		xresponse.BadRequest(c, "Invalid selling_price")
+		return
+	}
+
+	quote, err := h.feeUC.CalculateFee(c.Query("category"), level, sellingPrice)
+	if err != nil {
+		logger.Error("Failed to calculate fee quote", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to calculate fee")
+		return
+	}
+
+	xresponse.Success(c, "Fee quote calculated", quote)
+}
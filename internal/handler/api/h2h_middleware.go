@@ -9,16 +9,24 @@ import (
 
 	"github.com/alfanzaky/eraflazz/internal/domain"
 	"github.com/alfanzaky/eraflazz/internal/repository/postgres"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/metrics"
 	"github.com/gin-gonic/gin"
 )
 
+// h2hPreviousSecretKey marks requests signed with the secret replaced by the
+// client's last rotation
+const h2hPreviousSecretKey = "h2h_previous_secret"
+
 type H2HMiddleware struct {
 	clientRepo *postgres.APIClientRepository
+	nonceRepo  domain.NonceRepository
 }
 
-func NewH2HMiddleware(clientRepo *postgres.APIClientRepository) *H2HMiddleware {
+func NewH2HMiddleware(clientRepo *postgres.APIClientRepository, nonceRepo domain.NonceRepository) *H2HMiddleware {
 	return &H2HMiddleware{
 		clientRepo: clientRepo,
+		nonceRepo:  nonceRepo,
 	}
 }
 
@@ -65,9 +73,13 @@ func (m *H2HMiddleware) H2HAuth() gin.HandlerFunc {
 			return
 		}
 
-		// Check IP whitelist
+		// Check the client's own IP whitelist
 		clientIP := c.ClientIP()
 		if !client.IsIPAllowed(clientIP) {
+			logger.Component(logger.ComponentAuth).Warn("H2H request rejected - IP not allowed",
+				logger.String("client_id", headers.ClientID),
+				logger.String("ip", clientIP),
+			)
 			c.JSON(http.StatusForbidden, gin.H{
 				"error": "IP address not allowed",
 				"code":  "IP_NOT_ALLOWED",
@@ -83,8 +95,9 @@ func (m *H2HMiddleware) H2HAuth() gin.HandlerFunc {
 			c.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 		}
 
-		// Validate signature
-		err = domain.ValidateSignature(client.Secret, headers.Timestamp, headers.Signature, bodyBytes)
+		// Validate signature; the secret replaced by a rotation is accepted
+		// during its grace period
+		previousSecret, err := client.VerifySignature(headers.Timestamp, headers.Signature, bodyBytes, time.Now())
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "Invalid signature: " + err.Error(),
@@ -94,6 +107,11 @@ func (m *H2HMiddleware) H2HAuth() gin.HandlerFunc {
 			return
 		}
 
+		// Reject replays of a signature that was already accepted
+		if !m.reserveSignature(c, headers) {
+			return
+		}
+
 		// Update last used timestamp (async, don't block request)
 		go func() {
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -104,6 +122,90 @@ func (m *H2HMiddleware) H2HAuth() gin.HandlerFunc {
 		// Set client info in context
 		c.Set("client_id", headers.ClientID)
 		c.Set("client_info", client)
+		c.Set(h2hPreviousSecretKey, previousSecret)
+		actor := domain.Actor{
+			Type:      domain.ActorTypeUser,
+			Name:      headers.ClientID,
+			IPAddress: clientIP,
+			UserAgent: c.Request.UserAgent(),
+		}
+		if client.UserID != nil {
+			actor.UserID = *client.UserID
+		}
+		ctx := logger.WithContext(c.Request.Context(), logger.String("client_id", headers.ClientID))
+		c.Request = c.Request.WithContext(domain.WithActor(ctx, actor))
+
+		c.Next()
+	}
+}
+
+// reserveSignature stores the request signature until its timestamp leaves the
+// signature window. It aborts the request and returns false when the signature
+// was already used or the nonce store is unavailable.
+func (m *H2HMiddleware) reserveSignature(c *gin.Context, headers *domain.H2HRequestHeaders) bool {
+	if m.nonceRepo == nil {
+		return true
+	}
+
+	ttl := domain.SignatureTTL(headers.Timestamp, time.Now())
+	reserved, err := m.nonceRepo.Reserve(headers.APIKey+":"+headers.Signature, ttl)
+	if err != nil {
+		logger.Component(logger.ComponentAuth).Error("Failed to check H2H request nonce",
+			logger.String("client_id", headers.ClientID),
+			logger.ErrorField(err),
+		)
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Unable to verify request uniqueness",
+			"code":  "NONCE_UNAVAILABLE",
+		})
+		c.Abort()
+		return false
+	}
+
+	if !reserved {
+		metrics.RecordH2HReplayRejection(headers.ClientID)
+		logger.Component(logger.ComponentAuth).Warn("H2H request rejected - signature already used",
+			logger.String("client_id", headers.ClientID),
+			logger.String("ip", c.ClientIP()),
+		)
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Request already processed",
+			"code":  "REPLAYED_REQUEST",
+		})
+		c.Abort()
+		return false
+	}
+
+	return true
+}
+
+// RequireScope rejects H2H requests whose key lacks scope. It must run after
+// H2HAuth.
+func (m *H2HMiddleware) RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client, ok := GetClientFromContext(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "H2H client not authenticated",
+				"code":  "INVALID_CLIENT",
+			})
+			c.Abort()
+			return
+		}
+
+		if !client.HasScope(scope) {
+			logger.Component(logger.ComponentAuth).Warn("H2H request rejected - missing scope",
+				logger.String("client_id", client.ClientID),
+				logger.String("scope", scope),
+				logger.String("path", c.FullPath()),
+			)
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "API key lacks the " + scope + " scope",
+				"code":  "INSUFFICIENT_SCOPE",
+			})
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
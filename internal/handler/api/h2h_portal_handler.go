@@ -0,0 +1,243 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// h2hPaymentEndpoint is the route whose request quota is reported by default
+const h2hPaymentEndpoint = "/api/v1/h2h/payment"
+
+// H2HPortalHandler serves the self-service endpoints of H2H clients under
+// /h2h/me. Requests are authenticated by H2HAuth like any other H2H call.
+type H2HPortalHandler struct {
+	portalUC domain.APIClientPortalUsecase
+}
+
+// NewH2HPortalHandler creates a new H2H self-service handler
+func NewH2HPortalHandler(portalUC domain.APIClientPortalUsecase) *H2HPortalHandler {
+	return &H2HPortalHandler{portalUC: portalUC}
+}
+
+// GetProfile returns the authenticated client's settings
+func (h *H2HPortalHandler) GetProfile(c *gin.Context) {
+	client, ok := portalClient(c)
+	if !ok {
+		return
+	}
+
+	profile := *client
+	profile.Secret = ""
+	xresponse.Success(c, "API client retrieved successfully", profile)
+}
+
+// GetUsage returns the client's H2H transaction counts; start_date and
+// end_date (YYYY-MM-DD) default to the last 90 days
+func (h *H2HPortalHandler) GetUsage(c *gin.Context) {
+	client, ok := portalClient(c)
+	if !ok {
+		return
+	}
+	period, ok := bindListingRange(c)
+	if !ok {
+		return
+	}
+
+	usage, err := h.portalUC.GetUsage(client, period)
+	if err != nil {
+		respondPortalError(c, client, "Failed to retrieve usage", err)
+		return
+	}
+
+	xresponse.Success(c, "Usage retrieved successfully", usage)
+}
+
+// GetQuota returns the client's remaining quota. Requests per minute are
+// reported for ?endpoint= (a route path), the payment endpoint by default.
+func (h *H2HPortalHandler) GetQuota(c *gin.Context) {
+	client, ok := portalClient(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.portalUC.GetQuota(client, c.DefaultQuery("endpoint", h2hPaymentEndpoint))
+	if err != nil {
+		respondPortalError(c, client, "Failed to retrieve quota", err)
+		return
+	}
+
+	xresponse.Success(c, "Quota retrieved successfully", status)
+}
+
+// ListTransactions lists the client's recent transactions with cursor pagination
+func (h *H2HPortalHandler) ListTransactions(c *gin.Context) {
+	client, ok := portalClient(c)
+	if !ok {
+		return
+	}
+	period, ok := bindListingRange(c)
+	if !ok {
+		return
+	}
+	tags, ok := bindTagFilter(c)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	transactions, nextCursor, err := h.portalUC.ListTransactions(client, period, tags, c.Query("cursor"), limit)
+	if err != nil {
+		if err.Error() == "invalid cursor" {
+			xresponse.BadRequest(c, err.Error())
+			return
+		}
+		respondPortalError(c, client, "Failed to retrieve transactions", err)
+		return
+	}
+
+	responses := make([]interface{}, len(transactions))
+	for i, trx := range transactions {
+		responses[i] = projectTransactionResponse(c, buildTransactionResponse(trx))
+	}
+
+	xresponse.CursorPaginated(c, "Transactions retrieved successfully", responses, limit, nextCursor)
+}
+
+// ListDeliveries returns the latest notifications sent to the client's
+// account with their delivery status, retries and delivery report
+func (h *H2HPortalHandler) ListDeliveries(c *gin.Context) {
+	client, ok := portalClient(c)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+
+	deliveries, err := h.portalUC.ListDeliveries(client, limit)
+	if err != nil {
+		respondPortalError(c, client, "Failed to retrieve deliveries", err)
+		return
+	}
+
+	xresponse.Success(c, "Deliveries retrieved successfully", deliveries)
+}
+
+// RotateSecret issues a new secret. The request must be signed with the
+// current secret, which keeps working for the grace period.
+func (h *H2HPortalHandler) RotateSecret(c *gin.Context) {
+	client, ok := portalClient(c)
+	if !ok {
+		return
+	}
+	if c.GetBool(h2hPreviousSecretKey) {
+		xresponse.Forbidden(c, "Secret rotation must be signed with the current secret")
+		return
+	}
+
+	rotation, err := h.portalUC.RotateSecret(client)
+	if err != nil {
+		respondPortalError(c, client, "Failed to rotate secret", err)
+		return
+	}
+
+	xresponse.Success(c, "Secret rotated successfully", gin.H{
+		"client_id":                  rotation.ClientID,
+		"secret":                     rotation.Secret,
+		"previous_secret_expires_at": rotation.PreviousSecretExpiresAt,
+		"warning":                    "Please save this secret securely. It won't be shown again.",
+	})
+}
+
+// UpdateIPWhitelistRequest payload; entries are addresses or CIDR ranges
+type UpdateIPWhitelistRequest struct {
+	IPWhitelist []string `json:"ip_whitelist" binding:"required"`
+}
+
+// UpdateIPWhitelist replaces the client's IP whitelist. The list must not be
+// empty and must include the address the request comes from.
+func (h *H2HPortalHandler) UpdateIPWhitelist(c *gin.Context) {
+	client, ok := portalClient(c)
+	if !ok {
+		return
+	}
+	if c.GetBool(h2hPreviousSecretKey) {
+		xresponse.Forbidden(c, "IP whitelist changes must be signed with the current secret")
+		return
+	}
+
+	var req UpdateIPWhitelistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	change, err := h.portalUC.UpdateIPWhitelist(client, req.IPWhitelist, c.ClientIP())
+	if err != nil {
+		if isIPWhitelistError(err) {
+			xresponse.BadRequest(c, err.Error())
+			return
+		}
+		respondPortalError(c, client, "Failed to update IP whitelist", err)
+		return
+	}
+
+	xresponse.Success(c, "IP whitelist updated successfully", change)
+}
+
+// ListIPChanges returns the latest changes of the client's IP whitelist
+func (h *H2HPortalHandler) ListIPChanges(c *gin.Context) {
+	client, ok := portalClient(c)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+
+	changes, err := h.portalUC.ListIPChanges(client, limit)
+	if err != nil {
+		respondPortalError(c, client, "Failed to retrieve IP whitelist changes", err)
+		return
+	}
+
+	xresponse.Success(c, "IP whitelist changes retrieved successfully", changes)
+}
+
+// portalClient returns the authenticated H2H client, responding 401 without one
+func portalClient(c *gin.Context) (*domain.APIClient, bool) {
+	client, exists := GetClientFromContext(c)
+	if !exists {
+		xresponse.Unauthorized(c, "Client not authenticated")
+		return nil, false
+	}
+	return client, true
+}
+
+// respondPortalError maps H2H self-service errors to responses
+func respondPortalError(c *gin.Context, client *domain.APIClient, message string, err error) {
+	switch err.Error() {
+	case "api client is not linked to an account":
+		xresponse.Forbidden(c, "API client is not linked to an account")
+	case "api client not found":
+		xresponse.NotFound(c, "API client not found")
+	default:
+		logger.Error(message,
+			logger.String("client_id", client.ClientID),
+			logger.ErrorField(err),
+		)
+		xresponse.InternalServerError(c, message)
+	}
+}
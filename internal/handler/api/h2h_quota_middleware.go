@@ -0,0 +1,141 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// h2hTransactionKey holds the transaction an H2H handler created so the
+// quota middleware can record its amount
+const h2hTransactionKey = "h2h_transaction"
+
+// H2HQuotaMiddleware enforces the quota plans of H2H clients. It must run
+// after H2HAuth. Quota storage failures let requests through so an unhealthy
+// counter store does not stop partner traffic.
+type H2HQuotaMiddleware struct {
+	quotaUC domain.QuotaUsecase
+}
+
+func NewH2HQuotaMiddleware(quotaUC domain.QuotaUsecase) *H2HQuotaMiddleware {
+	return &H2HQuotaMiddleware{quotaUC: quotaUC}
+}
+
+// RateLimit enforces the client's requests per minute on the current route
+func (m *H2HQuotaMiddleware) RateLimit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client, exists := GetClientFromContext(c)
+		if !exists || m.quotaUC == nil {
+			c.Next()
+			return
+		}
+
+		status, err := m.quotaUC.CheckRequest(client, c.FullPath())
+		if err != nil {
+			logger.Warn("Failed to check H2H request quota",
+				logger.String("client_id", client.ClientID),
+				logger.ErrorField(err),
+			)
+			c.Next()
+			return
+		}
+
+		if status.RequestLimit > 0 {
+			c.Header("X-RateLimit-Limit", strconv.Itoa(status.RequestLimit))
+			c.Header("X-RateLimit-Remaining", strconv.Itoa(status.RequestsRemaining))
+			c.Header("X-RateLimit-Reset", strconv.FormatInt(status.RequestsResetAt.Unix(), 10))
+		}
+
+		if !status.Allowed() {
+			rejectQuota(c, client, status, "RATE_LIMITED", "Request rate limit exceeded", status.RequestsResetAt)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// TransactionQuota reserves one of the client's daily transactions for the
+// request and records the amount of the transaction the handler created
+func (m *H2HQuotaMiddleware) TransactionQuota() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client, exists := GetClientFromContext(c)
+		if !exists || m.quotaUC == nil {
+			c.Next()
+			return
+		}
+
+		status, err := m.quotaUC.ReserveTransaction(client)
+		if err != nil {
+			logger.Warn("Failed to reserve H2H transaction quota",
+				logger.String("client_id", client.ClientID),
+				logger.ErrorField(err),
+			)
+			c.Next()
+			return
+		}
+
+		// Remaining values are those before this request's transaction
+		if status.TransactionLimit > 0 {
+			c.Header("X-Quota-Transactions-Limit", strconv.Itoa(status.TransactionLimit))
+			c.Header("X-Quota-Transactions-Remaining", strconv.Itoa(status.TransactionsRemaining))
+		}
+		if status.AmountLimit > 0 {
+			c.Header("X-Quota-Amount-Limit", strconv.FormatFloat(status.AmountLimit, 'f', -1, 64))
+			c.Header("X-Quota-Amount-Remaining", strconv.FormatFloat(status.AmountRemaining, 'f', -1, 64))
+		}
+		if status.TransactionLimit > 0 || status.AmountLimit > 0 {
+			c.Header("X-Quota-Reset", strconv.FormatInt(status.DailyResetAt.Unix(), 10))
+		}
+
+		if !status.Allowed() {
+			rejectQuota(c, client, status, "QUOTA_EXCEEDED", "Daily transaction quota exceeded", status.DailyResetAt)
+			return
+		}
+
+		c.Next()
+
+		transaction, ok := c.Get(h2hTransactionKey)
+		if !ok {
+			if err := m.quotaUC.ReleaseTransaction(client); err != nil {
+				logger.Warn("Failed to release H2H transaction quota",
+					logger.String("client_id", client.ClientID),
+					logger.ErrorField(err),
+				)
+			}
+			return
+		}
+
+		if trx, ok := transaction.(*domain.Transaction); ok {
+			if err := m.quotaUC.RecordTransactionAmount(client, trx.TotalAmount()); err != nil {
+				logger.Warn("Failed to record H2H transaction amount",
+					logger.String("client_id", client.ClientID),
+					logger.String("trx_id", trx.ID),
+					logger.ErrorField(err),
+				)
+			}
+		}
+	}
+}
+
+func rejectQuota(c *gin.Context, client *domain.APIClient, status *domain.QuotaStatus, code, message string, resetAt time.Time) {
+	retryAfter := int(time.Until(resetAt).Seconds()) + 1
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+
+	logger.Warn("H2H request rejected - quota exceeded",
+		logger.String("client_id", client.ClientID),
+		logger.String("quota", status.Exceeded),
+		logger.String("path", c.FullPath()),
+	)
+
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error": message,
+		"code":  code,
+		"quota": status.Exceeded,
+	})
+	c.Abort()
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// ImpersonationHandler handles admin impersonation sessions and their audit trail
+type ImpersonationHandler struct {
+	impersonationUC domain.ImpersonationUsecase
+	roleGuard       *RoleGuard
+}
+
+// NewImpersonationHandler creates a new impersonation handler
+func NewImpersonationHandler(impersonationUC domain.ImpersonationUsecase) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		impersonationUC: impersonationUC,
+		roleGuard:       NewRoleGuard(),
+	}
+}
+
+// StartImpersonationRequest payload
+type StartImpersonationRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+// StartSession issues a read-only token acting as the user. The token is
+// only returned here.
+func (h *ImpersonationHandler) StartSession(c *gin.Context) {
+	var req StartImpersonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	h.roleGuard.LogAccess(c, "start_impersonation", req.UserID)
+
+	adminID, _, _, _ := h.roleGuard.GetCurrentUser(c)
+	issued, err := h.impersonationUC.Start(adminID, req.UserID, req.Reason, c.ClientIP())
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			xresponse.NotFound(c, err.Error())
+		case "admins cannot be impersonated", "cannot impersonate yourself":
+			xresponse.Forbidden(c, err.Error())
+		case "reason is required", "reason is too long", "user is not active":
+			xresponse.BadRequest(c, err.Error())
+		default:
+			logger.Error("Failed to start impersonation", logger.ErrorField(err))
+			xresponse.InternalServerError(c, "Failed to start impersonation")
+		}
+		return
+	}
+
+	xresponse.Created(c, "Impersonation started. Requests with this token are read-only and audited", issued)
+}
+
+// ListSessions lists impersonation sessions, newest first.
+// Query: admin_id, user_id, page, limit.
+func (h *ImpersonationHandler) ListSessions(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	filter := &domain.ImpersonationSessionFilter{
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+	}
+	if adminID := c.Query("admin_id"); adminID != "" {
+		filter.AdminID = &adminID
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		filter.UserID = &userID
+	}
+
+	sessions, total, err := h.impersonationUC.ListSessions(filter)
+	if err != nil {
+		logger.Error("Failed to list impersonation sessions", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to list impersonation sessions")
+		return
+	}
+	if sessions == nil {
+		sessions = []*domain.ImpersonationSession{}
+	}
+
+	xresponse.Paginated(c, "Impersonation sessions fetched", sessions, page, limit, total)
+}
+
+// GetSession returns one impersonation session
+func (h *ImpersonationHandler) GetSession(c *gin.Context) {
+	session, err := h.impersonationUC.GetSession(c.Param("id"))
+	if err != nil {
+		if err.Error() == "impersonation session not found" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		logger.Error("Failed to get impersonation session", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to get impersonation session")
+		return
+	}
+
+	xresponse.Success(c, "Impersonation session fetched", session)
+}
+
+// ListActions lists every request made during a session, oldest first
+func (h *ImpersonationHandler) ListActions(c *gin.Context) {
+	actions, err := h.impersonationUC.ListActions(c.Param("id"))
+	if err != nil {
+		if err.Error() == "impersonation session not found" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		logger.Error("Failed to list impersonation actions", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to list impersonation actions")
+		return
+	}
+	if actions == nil {
+		actions = []*domain.ImpersonationAction{}
+	}
+
+	xresponse.Success(c, "Impersonation actions fetched", actions)
+}
+
+// EndSession revokes a session token before it expires
+func (h *ImpersonationHandler) EndSession(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "end_impersonation", c.Param("id"))
+
+	adminID, _, _, _ := h.roleGuard.GetCurrentUser(c)
+	session, err := h.impersonationUC.End(c.Param("id"), adminID)
+	if err != nil {
+		switch err.Error() {
+		case "impersonation session not found":
+			xresponse.NotFound(c, err.Error())
+		case "impersonation session already ended":
+			xresponse.BadRequest(c, err.Error())
+		default:
+			logger.Error("Failed to end impersonation", logger.ErrorField(err))
+			xresponse.InternalServerError(c, "Failed to end impersonation")
+		}
+		return
+	}
+
+	xresponse.Success(c, "Impersonation ended", session)
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// impersonationRecorder stores the requests made with impersonation tokens;
+// nil disables persistence
+var impersonationRecorder domain.ImpersonationUsecase
+
+// SetImpersonationRecorder registers the use case that audits impersonated requests
+func SetImpersonationRecorder(recorder domain.ImpersonationUsecase) {
+	impersonationRecorder = recorder
+}
+
+// beginImpersonation flags a request made with an impersonation token and
+// rejects it unless it is read-only. Returns false when the request was
+// aborted.
+func beginImpersonation(c *gin.Context, claims *domain.AuthClaims) bool {
+	c.Set("impersonator_id", claims.ImpersonatorID)
+	c.Set("impersonation_session_id", claims.ImpersonationSessionID)
+	c.Header(domain.HeaderImpersonatedBy, claims.ImpersonatorID)
+	c.Header(domain.HeaderImpersonationID, claims.ImpersonationSessionID)
+	c.Header(domain.HeaderImpersonationExpiry, claims.ExpiresAt.Format(time.RFC3339))
+	c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context(),
+		logger.String("impersonator_id", claims.ImpersonatorID),
+	))
+
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+
+	logger.Component(logger.ComponentAuth).Warn("Impersonated write request rejected",
+		logger.String("session_id", claims.ImpersonationSessionID),
+		logger.String("impersonator_id", claims.ImpersonatorID),
+		logger.String("user_id", claims.UserID),
+		logger.String("method", c.Request.Method),
+		logger.String("path", c.Request.URL.Path),
+	)
+	recordAccessDenial(c, domain.DenialImpersonationReadOnly, "")
+	xresponse.Error(c, http.StatusForbidden, domain.DenialImpersonationReadOnly,
+		"Impersonation sessions are read-only")
+	c.Abort()
+	return false
+}
+
+// recordImpersonationAction audits a finished impersonated request, rejected
+// ones included. Persistence runs in the background like access denials.
+func recordImpersonationAction(c *gin.Context, sessionID string) {
+	if impersonationRecorder == nil {
+		return
+	}
+
+	action := &domain.ImpersonationAction{
+		SessionID:  sessionID,
+		Method:     c.Request.Method,
+		Path:       c.Request.URL.RequestURI(),
+		StatusCode: c.Writer.Status(),
+		IPAddress:  c.ClientIP(),
+	}
+	if route := c.FullPath(); route != "" {
+		action.Route = &route
+	}
+	if userAgent := c.Request.UserAgent(); userAgent != "" {
+		action.UserAgent = &userAgent
+	}
+
+	go func() {
+		if err := impersonationRecorder.RecordAction(action); err != nil {
+			logger.Component(logger.ComponentAuth).Error("Failed to record impersonation action",
+				logger.String("session_id", sessionID),
+				logger.String("path", action.Path),
+				logger.ErrorField(err),
+			)
+		}
+	}()
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// LoggingHandler adjusts log levels and sampling at runtime. Changes apply
+// to the replica serving the request and last until it restarts.
+type LoggingHandler struct {
+	roleGuard *RoleGuard
+}
+
+// NewLoggingHandler creates a new logging handler
+func NewLoggingHandler() *LoggingHandler {
+	return &LoggingHandler{
+		roleGuard: NewRoleGuard(),
+	}
+}
+
+// SetLogLevelRequest payload
+type SetLogLevelRequest struct {
+	Level string `json:"level" binding:"required"`
+}
+
+// SetLogComponentRequest payload. An empty level follows the default level;
+// sample_rate (0-1) keeps that share of Debug and Info logs, 0 keeps all.
+type SetLogComponentRequest struct {
+	Level      string  `json:"level"`
+	SampleRate float64 `json:"sample_rate"`
+}
+
+// GetLevels returns the default level and the per component settings
+func (h *LoggingHandler) GetLevels(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "get_log_levels", "admin")
+
+	xresponse.Success(c, "Log levels fetched", logger.Levels())
+}
+
+// SetLevel changes the default level
+func (h *LoggingHandler) SetLevel(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "set_log_level", "admin")
+
+	var req SetLogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	if err := logger.SetLevel(req.Level); err != nil {
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	logger.Warn("Log level changed", logger.String("level", req.Level))
+	xresponse.Success(c, "Log level changed", logger.Levels())
+}
+
+// SetComponent changes the level and sample rate of a component
+func (h *LoggingHandler) SetComponent(c *gin.Context) {
+	component := c.Param("component")
+	h.roleGuard.LogAccess(c, "set_log_component", component)
+
+	var req SetLogComponentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	settings := logger.ComponentSettings{Level: req.Level, SampleRate: req.SampleRate}
+	if err := logger.SetComponent(component, settings); err != nil {
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	logger.Warn("Log component changed",
+		logger.String("component", component),
+		logger.String("level", req.Level),
+		logger.Float64("sample_rate", req.SampleRate),
+	)
+	xresponse.Success(c, "Log component changed", logger.Levels())
+}
+
+// ResetComponent makes a component follow the default level, unsampled
+func (h *LoggingHandler) ResetComponent(c *gin.Context) {
+	component := c.Param("component")
+	h.roleGuard.LogAccess(c, "reset_log_component", component)
+
+	logger.ResetComponent(component)
+	logger.Warn("Log component reset", logger.String("component", component))
+
+	xresponse.Success(c, "Log component reset", logger.Levels())
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// MappingReviewHandler handles stale supplier code review endpoints
+type MappingReviewHandler struct {
+	validationUC domain.MappingValidationUsecase
+	roleGuard    *RoleGuard
+}
+
+// NewMappingReviewHandler creates a new mapping review handler
+func NewMappingReviewHandler(validationUC domain.MappingValidationUsecase) *MappingReviewHandler {
+	return &MappingReviewHandler{
+		validationUC: validationUC,
+		roleGuard:    NewRoleGuard(),
+	}
+}
+
+// AcceptMappingReviewRequest payload
+type AcceptMappingReviewRequest struct {
+	SupplierProductCode string `json:"supplier_product_code" binding:"required"`
+}
+
+// ListReviews lists mapping reviews, defaulting to open ones
+func (h *MappingReviewHandler) ListReviews(c *gin.Context) {
+	reviews, err := h.validationUC.ListReviews(c.DefaultQuery("status", domain.MappingReviewOpen))
+	if err != nil {
+		if err.Error() == "invalid review status" {
+			xresponse.BadRequest(c, err.Error())
+			return
+		}
+		logger.Error("Failed to list mapping reviews", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to list mapping reviews")
+		return
+	}
+
+	xresponse.Success(c, "Mapping reviews fetched", reviews)
+}
+
+// AcceptReview replaces the stale supplier code with the chosen code
+func (h *MappingReviewHandler) AcceptReview(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "accept_mapping_review", "admin")
+
+	var req AcceptMappingReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	review, err := h.validationUC.AcceptReview(c.Param("id"), req.SupplierProductCode, h.currentUserID(c))
+	if err != nil {
+		h.respondReviewError(c, err)
+		return
+	}
+
+	xresponse.Success(c, "Mapping review accepted", review)
+}
+
+// DismissReview closes a review without changing the mapping
+func (h *MappingReviewHandler) DismissReview(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "dismiss_mapping_review", "admin")
+
+	review, err := h.validationUC.DismissReview(c.Param("id"), h.currentUserID(c))
+	if err != nil {
+		h.respondReviewError(c, err)
+		return
+	}
+
+	xresponse.Success(c, "Mapping review dismissed", review)
+}
+
+// ValidateSupplierMappings runs the mapping validation for one supplier
+func (h *MappingReviewHandler) ValidateSupplierMappings(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "validate_supplier_mappings", "admin")
+
+	result, err := h.validationUC.ValidateSupplierMappings(c.Param("id"))
+	if err != nil {
+		if err.Error() == "supplier not found" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Supplier mappings validated", result)
+}
+
+func (h *MappingReviewHandler) respondReviewError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "mapping review not found", "product mapping not found":
+		xresponse.NotFound(c, err.Error())
+	case "mapping review already closed":
+		xresponse.Conflict(c, err.Error())
+	default:
+		xresponse.BadRequest(c, err.Error())
+	}
+}
+
+func (h *MappingReviewHandler) currentUserID(c *gin.Context) *string {
+	if userID, _, _, exists := h.roleGuard.GetCurrentUser(c); exists && userID != "" {
+		return &userID
+	}
+	return nil
+}
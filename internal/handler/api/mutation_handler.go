@@ -0,0 +1,158 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// MutationHandler handles balance mutation endpoints
+type MutationHandler struct {
+	mutationUC domain.MutationUsecase
+	roleGuard  *RoleGuard
+}
+
+// NewMutationHandler creates a new mutation handler
+func NewMutationHandler(mutationUC domain.MutationUsecase) *MutationHandler {
+	return &MutationHandler{
+		mutationUC: mutationUC,
+		roleGuard:  NewRoleGuard(),
+	}
+}
+
+// GetUserMutations retrieves balance mutations of the current user created
+// between start_date and end_date (default the last 90 days). Supports
+// page/limit pagination, or keyset pagination when the cursor parameter is
+// set. Page mode also filters by type, reference_type, min_amount and
+// max_amount and reports the total number of matches.
+func (h *MutationHandler) GetUserMutations(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		if clientID, isH2H := GetClientIDFromContext(c); isH2H {
+			userID = clientID
+		} else {
+			xresponse.Unauthorized(c, "Authentication required")
+			return
+		}
+	}
+
+	h.roleGuard.LogAccess(c, "get_user_mutations", "own_mutations")
+
+	filter, ok := bindMutationFilter(c, userID)
+	if !ok {
+		return
+	}
+
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		if filter.Type != "" || filter.ReferenceType != "" || filter.MinAmount != nil || filter.MaxAmount != nil {
+			xresponse.BadRequest(c, "Filters are only supported with page pagination")
+			return
+		}
+		period := filter.Period
+		mutations, nextCursor, err := h.mutationUC.GetUserMutationsByCursor(userID, period, cursor, limit)
+		if err != nil {
+			if err.Error() == "invalid cursor" {
+				xresponse.BadRequest(c, err.Error())
+				return
+			}
+			logger.Error("Failed to get user mutations", logger.String("user_id", userID), logger.ErrorField(err))
+			xresponse.InternalServerError(c, "Failed to retrieve mutations")
+			return
+		}
+
+		xresponse.CursorPaginated(c, "Mutations retrieved successfully", mutations, limit, nextCursor)
+		return
+	}
+
+	mutations, total, err := h.mutationUC.ListUserMutations(filter, page, limit)
+	if err != nil {
+		logger.Error("Failed to get user mutations", logger.String("user_id", userID), logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to retrieve mutations")
+		return
+	}
+
+	xresponse.Paginated(c, "Mutations retrieved successfully", mutations, page, limit, total)
+}
+
+// GetDailySummary aggregates the current user's mutations per day for
+// charts. It accepts the same date range and filters as GetUserMutations.
+func (h *MutationHandler) GetDailySummary(c *gin.Context) {
+	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		if clientID, isH2H := GetClientIDFromContext(c); isH2H {
+			userID = clientID
+		} else {
+			xresponse.Unauthorized(c, "Authentication required")
+			return
+		}
+	}
+
+	h.roleGuard.LogAccess(c, "get_mutation_daily_summary", "own_mutations")
+
+	filter, ok := bindMutationFilter(c, userID)
+	if !ok {
+		return
+	}
+
+	summaries, err := h.mutationUC.GetDailyMutationSummary(filter)
+	if err != nil {
+		logger.Error("Failed to get daily mutation summary", logger.String("user_id", userID), logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to retrieve mutation summary")
+		return
+	}
+
+	xresponse.Success(c, "Mutation summary retrieved successfully", summaries)
+}
+
+// bindMutationFilter reads the date range and the type, reference_type,
+// min_amount and max_amount query parameters of a mutation listing. It
+// responds with 400 and returns false when a parameter is invalid.
+func bindMutationFilter(c *gin.Context, userID string) (domain.MutationFilter, bool) {
+	period, ok := bindListingRange(c)
+	if !ok {
+		return domain.MutationFilter{}, false
+	}
+
+	filter := domain.MutationFilter{
+		UserID:        userID,
+		Period:        period,
+		Type:          strings.ToUpper(c.Query("type")),
+		ReferenceType: strings.ToUpper(c.Query("reference_type")),
+	}
+
+	for param, target := range map[string]**float64{
+		"min_amount": &filter.MinAmount,
+		"max_amount": &filter.MaxAmount,
+	} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		amount, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			xresponse.BadRequest(c, "Invalid "+param)
+			return domain.MutationFilter{}, false
+		}
+		*target = &amount
+	}
+
+	if err := filter.Validate(); err != nil {
+		xresponse.BadRequest(c, err.Error())
+		return domain.MutationFilter{}, false
+	}
+	return filter, true
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// MutationWebhookHandler handles the mutation webhook of the current user
+type MutationWebhookHandler struct {
+	webhookUC domain.MutationWebhookUsecase
+	roleGuard *RoleGuard
+}
+
+// NewMutationWebhookHandler creates a new mutation webhook handler
+func NewMutationWebhookHandler(webhookUC domain.MutationWebhookUsecase) *MutationWebhookHandler {
+	return &MutationWebhookHandler{
+		webhookUC: webhookUC,
+		roleGuard: NewRoleGuard(),
+	}
+}
+
+// SetMutationWebhookRequest payload. is_active defaults to true.
+type SetMutationWebhookRequest struct {
+	URL              string `json:"url" binding:"required"`
+	IncludeDownlines bool   `json:"include_downlines"`
+	IsActive         *bool  `json:"is_active"`
+}
+
+// mutationWebhookSecretWarning accompanies every response showing a secret
+const mutationWebhookSecretWarning = "Please save this secret securely. It won't be shown again."
+
+// GetWebhook returns the current user's mutation webhook
+func (h *MutationWebhookHandler) GetWebhook(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.webhookUC.GetWebhook(userID)
+	if err != nil {
+		respondMutationWebhookError(c, err, "Failed to get mutation webhook")
+		return
+	}
+
+	xresponse.Success(c, "Mutation webhook fetched", webhook)
+}
+
+// SetWebhook creates or updates the current user's mutation webhook. The
+// secret is only in the response when the webhook is created.
+func (h *MutationWebhookHandler) SetWebhook(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req SetMutationWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	setup, err := h.webhookUC.SetWebhook(userID, req.URL, req.IncludeDownlines, isActive)
+	if err != nil {
+		respondMutationWebhookError(c, err, "Failed to save mutation webhook")
+		return
+	}
+
+	if setup.Secret != "" {
+		xresponse.Created(c, "Mutation webhook created", gin.H{
+			"webhook": setup,
+			"warning": mutationWebhookSecretWarning,
+		})
+		return
+	}
+	xresponse.Success(c, "Mutation webhook saved", setup)
+}
+
+// RotateSecret issues a new secret for the current user's mutation webhook
+func (h *MutationWebhookHandler) RotateSecret(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	setup, err := h.webhookUC.RotateSecret(userID)
+	if err != nil {
+		respondMutationWebhookError(c, err, "Failed to rotate mutation webhook secret")
+		return
+	}
+
+	xresponse.Success(c, "Secret rotated successfully", gin.H{
+		"webhook": setup,
+		"warning": mutationWebhookSecretWarning,
+	})
+}
+
+// DeleteWebhook removes the current user's mutation webhook with its
+// queued mutations and deliveries
+func (h *MutationWebhookHandler) DeleteWebhook(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookUC.DeleteWebhook(userID); err != nil {
+		respondMutationWebhookError(c, err, "Failed to delete mutation webhook")
+		return
+	}
+
+	xresponse.Success(c, "Mutation webhook deleted", nil)
+}
+
+// ListDeliveries returns the latest deliveries of the current user's
+// mutation webhook, newest first. Query: limit.
+func (h *MutationWebhookHandler) ListDeliveries(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+
+	deliveries, err := h.webhookUC.ListDeliveries(userID, limit)
+	if err != nil {
+		respondMutationWebhookError(c, err, "Failed to list mutation webhook deliveries")
+		return
+	}
+	if deliveries == nil {
+		deliveries = []*domain.MutationWebhookDelivery{}
+	}
+
+	xresponse.Success(c, "Deliveries retrieved successfully", deliveries)
+}
+
+// ResendDelivery queues a failed delivery again
+func (h *MutationWebhookHandler) ResendDelivery(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookUC.ResendDelivery(userID, c.Param("id"))
+	if err != nil {
+		respondMutationWebhookError(c, err, "Failed to resend mutation webhook delivery")
+		return
+	}
+
+	xresponse.Success(c, "Delivery queued for resend", delivery)
+}
+
+func (h *MutationWebhookHandler) currentUser(c *gin.Context) (string, bool) {
+	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists || userID == "" {
+		xresponse.Unauthorized(c, "User not authenticated")
+		return "", false
+	}
+	return userID, true
+}
+
+func respondMutationWebhookError(c *gin.Context, err error, failure string) {
+	switch err.Error() {
+	case "mutation webhook not found", "mutation webhook delivery not found":
+		xresponse.NotFound(c, err.Error())
+	case "invalid webhook url", "webhook url must use https", "webhook url host cannot be resolved",
+		"webhook url must not point to a private address", "user cannot have downlines":
+		xresponse.BadRequest(c, err.Error())
+	case "only failed deliveries can be resent":
+		xresponse.Conflict(c, err.Error())
+	default:
+		logger.Error(failure, logger.ErrorField(err))
+		xresponse.InternalServerError(c, failure)
+	}
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler handles notification preference and message template endpoints
+type NotificationHandler struct {
+	notificationUC domain.NotificationUsecase
+	roleGuard      *RoleGuard
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationUC domain.NotificationUsecase) *NotificationHandler {
+	return &NotificationHandler{
+		notificationUC: notificationUC,
+		roleGuard:      NewRoleGuard(),
+	}
+}
+
+// UpdateNotificationPreferenceRequest payload
+type UpdateNotificationPreferenceRequest struct {
+	EventType string `json:"event_type" binding:"required"`
+	Channel   string `json:"channel" binding:"required"`
+	IsEnabled *bool  `json:"is_enabled" binding:"required"`
+}
+
+// UpdateMessageTemplateRequest payload
+type UpdateMessageTemplateRequest struct {
+	Subject  *string `json:"subject"`
+	Body     *string `json:"body"`
+	IsActive *bool   `json:"is_active"`
+}
+
+// PreviewMessageTemplateRequest payload
+type PreviewMessageTemplateRequest struct {
+	Data map[string]string `json:"data"`
+}
+
+// GetPreferences returns the notification preferences of the current user
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	prefs, err := h.notificationUC.GetPreferences(userID)
+	if err != nil {
+		logger.Error("Failed to get notification preferences", logger.String("user_id", userID), logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to get notification preferences")
+		return
+	}
+
+	xresponse.Success(c, "Notification preferences fetched", prefs)
+}
+
+// UpdatePreference enables or disables an event on a channel for the current user
+func (h *NotificationHandler) UpdatePreference(c *gin.Context) {
+	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req UpdateNotificationPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	pref, err := h.notificationUC.UpdatePreference(userID, req.EventType, req.Channel, *req.IsEnabled)
+	if err != nil {
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Notification preference updated", pref)
+}
+
+// ListTemplates lists all message templates
+func (h *NotificationHandler) ListTemplates(c *gin.Context) {
+	templates, err := h.notificationUC.ListTemplates()
+	if err != nil {
+		logger.Error("Failed to list message templates", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to list message templates")
+		return
+	}
+
+	xresponse.Success(c, "Message templates fetched", templates)
+}
+
+// GetTemplate returns a message template with its available placeholders
+func (h *NotificationHandler) GetTemplate(c *gin.Context) {
+	template, err := h.notificationUC.GetTemplate(c.Param("id"))
+	if err != nil {
+		xresponse.NotFound(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Message template fetched", gin.H{
+		"template":     template,
+		"placeholders": domain.TemplatePlaceholders(template.EventType),
+	})
+}
+
+// UpdateTemplate updates subject, body or status of a message template
+func (h *NotificationHandler) UpdateTemplate(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "update_message_template", "admin")
+
+	var req UpdateMessageTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	var updatedBy *string
+	if userID, _, _, exists := h.roleGuard.GetCurrentUser(c); exists && userID != "" {
+		updatedBy = &userID
+	}
+
+	template, err := h.notificationUC.UpdateTemplate(c.Param("id"), req.Subject, req.Body, req.IsActive, updatedBy)
+	if err != nil {
+		if err.Error() == "message template not found" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Message template updated", template)
+}
+
+// PreviewTemplate renders a message template with sample data
+func (h *NotificationHandler) PreviewTemplate(c *gin.Context) {
+	var req PreviewMessageTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	subject, body, err := h.notificationUC.PreviewTemplate(c.Param("id"), req.Data)
+	if err != nil {
+		xresponse.NotFound(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Message template rendered", gin.H{
+		"subject": subject,
+		"body":    body,
+	})
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// PriceListHandler handles the public price list endpoint
+type PriceListHandler struct {
+	priceListUC domain.PriceListUsecase
+	maxAge      time.Duration
+}
+
+// NewPriceListHandler creates a new price list handler. maxAge is how long
+// browsers and CDNs may reuse a response without revalidating it.
+func NewPriceListHandler(priceListUC domain.PriceListUsecase, maxAge time.Duration) *PriceListHandler {
+	return &PriceListHandler{
+		priceListUC: priceListUC,
+		maxAge:      maxAge,
+	}
+}
+
+// GetPriceList returns the public price list, optionally of one category
+func (h *PriceListHandler) GetPriceList(c *gin.Context) {
+	list, err := h.priceListUC.GetPriceList(c.Query("category"))
+	if err != nil {
+		if err.Error() == "invalid product category" {
+			xresponse.BadRequest(c, err.Error())
+			return
+		}
+		logger.Error("Failed to get price list", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to get price list")
+		return
+	}
+
+	modified := !notModified(c, list, list.GeneratedAt)
+	// Unlike the authenticated catalog the list is the same for everyone
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(h.maxAge.Seconds())))
+	if !modified {
+		return
+	}
+
+	xresponse.Success(c, "Price list fetched", list)
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/alfanzaky/eraflazz/internal/domain"
 	"github.com/alfanzaky/eraflazz/pkg/logger"
@@ -13,13 +14,17 @@ import (
 // ProductHandler handles admin product endpoints
 type ProductHandler struct {
 	productUC domain.ProductUsecase
+	pricingUC domain.PricingUsecase
+	healthUC  domain.ProductHealthUsecase
 	roleGuard *RoleGuard
 }
 
 // NewProductHandler creates a new product handler
-func NewProductHandler(productUC domain.ProductUsecase) *ProductHandler {
+func NewProductHandler(productUC domain.ProductUsecase, pricingUC domain.PricingUsecase, healthUC domain.ProductHealthUsecase) *ProductHandler {
 	return &ProductHandler{
 		productUC: productUC,
+		pricingUC: pricingUC,
+		healthUC:  healthUC,
 		roleGuard: NewRoleGuard(),
 	}
 }
@@ -41,10 +46,42 @@ type ProductResponse struct {
 	IsActive             bool     `json:"is_active"`
 	IsUnlimitedStock     bool     `json:"is_unlimited_stock"`
 	StockQuantity        int      `json:"stock_quantity"`
+	IsVoucherStock       bool     `json:"is_voucher_stock"`
 	AllowMarkup          bool     `json:"allow_markup"`
 	MaxMarkupPercentage  float64  `json:"max_markup_percentage"`
 	MinTransactionAmount float64  `json:"min_transaction_amount"`
 	MaxTransactionAmount float64  `json:"max_transaction_amount"`
+
+	MarginFlagged    bool       `json:"margin_flagged"`
+	MarginFlaggedAt  *time.Time `json:"margin_flagged_at,omitempty"`
+	MarginFlagReason *string    `json:"margin_flag_reason,omitempty"`
+
+	DestinationPattern   *string `json:"destination_pattern,omitempty"`
+	DestinationMinLength *int    `json:"destination_min_length,omitempty"`
+	DestinationMaxLength *int    `json:"destination_max_length,omitempty"`
+	DestinationHint      *string `json:"destination_hint,omitempty"`
+
+	MinLevel *int    `json:"min_level,omitempty"`
+	MinRole  *string `json:"min_role,omitempty"`
+}
+
+// ProductSearchResponse is a search hit returned to any authenticated user,
+// so it leaves out supplier cost and margin details
+type ProductSearchResponse struct {
+	ID             string            `json:"id"`
+	Code           string            `json:"code"`
+	Name           string            `json:"name"`
+	Category       string            `json:"category"`
+	Provider       string            `json:"provider"`
+	Type           string            `json:"type"`
+	SellingPrice   float64           `json:"selling_price"`
+	Nominal        *float64          `json:"nominal,omitempty"`
+	ValidityPeriod *string           `json:"validity_period,omitempty"`
+	Rank           float64           `json:"rank"`
+	Highlights     map[string]string `json:"highlights,omitempty"`
+	Available      bool              `json:"available"`
+	SuccessRate    *float64          `json:"success_rate,omitempty"`
+	Disrupted      bool              `json:"disrupted"`
 }
 
 // CreateProductRequest payload
@@ -64,6 +101,10 @@ type CreateProductRequest struct {
 	MaxMarkupPercentage  float64  `json:"max_markup_percentage"`
 	MinTransactionAmount float64  `json:"min_transaction_amount"`
 	MaxTransactionAmount float64  `json:"max_transaction_amount"`
+	DestinationPattern   *string  `json:"destination_pattern"`
+	DestinationMinLength *int     `json:"destination_min_length"`
+	DestinationMaxLength *int     `json:"destination_max_length"`
+	DestinationHint      *string  `json:"destination_hint"`
 }
 
 // UpdateProductRequest payload
@@ -89,6 +130,22 @@ type ToggleStatusRequest struct {
 	IsActive bool `json:"is_active" binding:"required"`
 }
 
+// DestinationOverrideRequest payload. Omitted fields fall back to the
+// category rule; an empty body removes the override.
+type DestinationOverrideRequest struct {
+	Pattern   *string `json:"pattern"`
+	MinLength *int    `json:"min_length"`
+	MaxLength *int    `json:"max_length"`
+	Hint      *string `json:"hint"`
+}
+
+// ProductRestrictionRequest payload. Either field sets the minimum level;
+// an empty body lifts the restriction.
+type ProductRestrictionRequest struct {
+	MinLevel *int    `json:"min_level"`
+	MinRole  *string `json:"min_role"`
+}
+
 // UpdateStockRequest payload
 type UpdateStockRequest struct {
 	StockQuantity int  `json:"stock_quantity" binding:"required"`
@@ -116,6 +173,18 @@ type UpdateMappingRequest struct {
 	StockStatus         *string  `json:"stock_status"`
 }
 
+// ReorderMappingsRequest payload: every mapping ID of the product, highest
+// priority first
+type ReorderMappingsRequest struct {
+	MappingIDs []string `json:"mapping_ids" binding:"required,min=1,dive,required"`
+}
+
+// SimulatePricingRequest payload. Omitted prices keep the current value.
+type SimulatePricingRequest struct {
+	BasePrice    *float64 `json:"base_price" binding:"omitempty,gt=0"`
+	SellingPrice *float64 `json:"selling_price" binding:"omitempty,gt=0"`
+}
+
 // CreateProduct handles creating a new product
 func (h *ProductHandler) CreateProduct(c *gin.Context) {
 	// Check if user has admin privileges
@@ -134,7 +203,7 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 
 	var req CreateProductRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		xresponse.ValidationError(c, err.Error())
+		respondBindingError(c, err)
 		return
 	}
 
@@ -154,6 +223,10 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 		MaxMarkupPercentage:  req.MaxMarkupPercentage,
 		MinTransactionAmount: req.MinTransactionAmount,
 		MaxTransactionAmount: req.MaxTransactionAmount,
+		DestinationPattern:   req.DestinationPattern,
+		DestinationMinLength: req.DestinationMinLength,
+		DestinationMaxLength: req.DestinationMaxLength,
+		DestinationHint:      req.DestinationHint,
 		IsActive:             true,
 	}
 
@@ -184,6 +257,11 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 			filter.IsActive = &isActive
 		}
 	}
+	if v := c.Query("margin_flagged"); v != "" {
+		if flagged, err := strconv.ParseBool(v); err == nil {
+			filter.MarginFlagged = &flagged
+		}
+	}
 	if v := c.Query("page"); v != "" {
 		if page, err := strconv.Atoi(v); err == nil && page > 0 {
 			filter.Page = page
@@ -216,9 +294,101 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 		limit = 50
 	}
 
+	var lastModified time.Time
+	for _, p := range products {
+		if p.UpdatedAt.After(lastModified) {
+			lastModified = p.UpdatedAt
+		}
+	}
+	if notModified(c, gin.H{"products": responses, "page": page, "limit": limit, "total": total}, lastModified) {
+		return
+	}
+
 	xresponse.Paginated(c, "Products fetched", responses, page, limit, total)
 }
 
+// SearchProducts searches active products by code and name, tolerating
+// typos and partial words
+func (h *ProductHandler) SearchProducts(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "search_products", c.Query("q"))
+
+	limit := 0
+	if v := c.Query("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			xresponse.BadRequest(c, "limit must be a positive number")
+			return
+		}
+		limit = parsed
+	}
+
+	_, _, userLevel, _ := h.roleGuard.GetCurrentUser(c)
+
+	results, err := h.productUC.SearchProducts(c.Query("q"), limit)
+	if err != nil {
+		if err.Error() == "search query too short" {
+			xresponse.BadRequest(c, "Search query must be at least 2 characters")
+			return
+		}
+		logger.Error("Failed to search products", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to search products")
+		return
+	}
+
+	health, err := h.healthUC.GetProductHealth()
+	if err != nil {
+		logger.Warn("Failed to load product health for search results", logger.ErrorField(err))
+	}
+
+	responses := make([]*ProductSearchResponse, 0, len(results))
+	for _, result := range results {
+		if !result.VisibleTo(userLevel) {
+			continue
+		}
+
+		response := &ProductSearchResponse{
+			ID:             result.ID,
+			Code:           result.Code,
+			Name:           result.Name,
+			Category:       result.Category,
+			Provider:       result.Provider,
+			Type:           result.Type,
+			SellingPrice:   result.SellingPrice,
+			Nominal:        result.Nominal,
+			ValidityPeriod: result.ValidityPeriod,
+			Rank:           result.Rank,
+			Highlights:     result.Highlights,
+			Available:      result.IsUnlimitedStock || result.StockQuantity > 0,
+		}
+		if health != nil {
+			productHealth, ok := health[result.ID]
+			if !ok {
+				productHealth = &domain.ProductHealth{Disrupted: true} // No active supplier
+			}
+			response.Available = response.Available && productHealth.Available
+			response.SuccessRate = productHealth.SuccessRate
+			response.Disrupted = productHealth.Disrupted
+		}
+		responses = append(responses, response)
+	}
+
+	// Health changes without the products changing, so with health in the
+	// response only the ETag validates cached copies
+	var lastModified time.Time
+	if health == nil {
+		for _, result := range results {
+			if result.VisibleTo(userLevel) && result.UpdatedAt.After(lastModified) {
+				lastModified = result.UpdatedAt
+			}
+		}
+	}
+	if notModified(c, responses, lastModified) {
+		return
+	}
+
+	xresponse.Success(c, "Products found", responses)
+}
+
 // GetProduct returns a product by ID
 func (h *ProductHandler) GetProduct(c *gin.Context) {
 	id := c.Param("id")
@@ -233,7 +403,12 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 		return
 	}
 
-	xresponse.Success(c, "Product fetched", h.toProductResponse(product))
+	response := h.toProductResponse(product)
+	if notModified(c, response, product.UpdatedAt) {
+		return
+	}
+
+	xresponse.Success(c, "Product fetched", response)
 }
 
 // UpdateProduct updates a product
@@ -246,7 +421,7 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 
 	var req UpdateProductRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		xresponse.ValidationError(c, err.Error())
+		respondBindingError(c, err)
 		return
 	}
 
@@ -288,7 +463,7 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 		updates.MaxTransactionAmount = *req.MaxTransactionAmount
 	}
 
-	if err := h.productUC.UpdateProduct(id, updates); err != nil {
+	if err := h.productUC.UpdateProduct(id, updates, h.currentUserID(c)); err != nil {
 		xresponse.BadRequest(c, err.Error())
 		return
 	}
@@ -307,7 +482,7 @@ func (h *ProductHandler) ToggleProductStatus(c *gin.Context) {
 
 	var req ToggleStatusRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		xresponse.ValidationError(c, err.Error())
+		respondBindingError(c, err)
 		return
 	}
 
@@ -329,7 +504,7 @@ func (h *ProductHandler) UpdateProductStock(c *gin.Context) {
 
 	var req UpdateStockRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		xresponse.ValidationError(c, err.Error())
+		respondBindingError(c, err)
 		return
 	}
 
@@ -345,6 +520,83 @@ func (h *ProductHandler) UpdateProductStock(c *gin.Context) {
 	})
 }
 
+// UpdateDestinationOverride replaces the destination format override of a product
+func (h *ProductHandler) UpdateDestinationOverride(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		xresponse.BadRequest(c, "product id is required")
+		return
+	}
+
+	h.roleGuard.LogAccess(c, "update_product_destination_rule", id)
+
+	var req DestinationOverrideRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBindingError(c, err)
+			return
+		}
+	}
+
+	product, err := h.productUC.SetDestinationOverride(id, req.Pattern, req.MinLength, req.MaxLength, req.Hint)
+	if err != nil {
+		if err.Error() == "product not found" {
+			xresponse.NotFound(c, "Product not found")
+			return
+		}
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Product destination rule updated", h.toProductResponse(product))
+}
+
+// UpdateProductRestriction sets the minimum user level allowed to see and buy a product
+func (h *ProductHandler) UpdateProductRestriction(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		xresponse.BadRequest(c, "product id is required")
+		return
+	}
+
+	h.roleGuard.LogAccess(c, "update_product_restriction", id)
+
+	var req ProductRestrictionRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBindingError(c, err)
+			return
+		}
+	}
+
+	minLevel := req.MinLevel
+	if req.MinRole != nil {
+		if minLevel != nil {
+			xresponse.BadRequest(c, "Provide either min_level or min_role, not both")
+			return
+		}
+		role := strings.ToUpper(strings.TrimSpace(*req.MinRole))
+		if role != domain.RoleReseller && role != domain.RoleAgent && role != domain.RoleMaster && role != domain.RoleAdmin {
+			xresponse.BadRequest(c, "min_role must be one of RESELLER, AGENT, MASTER or ADMIN")
+			return
+		}
+		level := domain.MapRoleToLevel(role)
+		minLevel = &level
+	}
+
+	product, err := h.productUC.SetMinLevel(id, minLevel)
+	if err != nil {
+		if err.Error() == "product not found" {
+			xresponse.NotFound(c, "Product not found")
+			return
+		}
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Product restriction updated", h.toProductResponse(product))
+}
+
 // ListProductMappings returns mappings for a product
 func (h *ProductHandler) ListProductMappings(c *gin.Context) {
 	productID := c.Param("id")
@@ -372,7 +624,7 @@ func (h *ProductHandler) CreateProductMapping(c *gin.Context) {
 
 	var req CreateMappingRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		xresponse.ValidationError(c, err.Error())
+		respondBindingError(c, err)
 		return
 	}
 
@@ -405,7 +657,7 @@ func (h *ProductHandler) UpdateProductMapping(c *gin.Context) {
 
 	var req UpdateMappingRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		xresponse.ValidationError(c, err.Error())
+		respondBindingError(c, err)
 		return
 	}
 
@@ -429,7 +681,7 @@ func (h *ProductHandler) UpdateProductMapping(c *gin.Context) {
 		mapping.StockStatus = strings.ToUpper(*req.StockStatus)
 	}
 
-	if err := h.productUC.UpdateProductMapping(mapping); err != nil {
+	if err := h.productUC.UpdateProductMapping(mapping, h.currentUserID(c)); err != nil {
 		xresponse.BadRequest(c, err.Error())
 		return
 	}
@@ -437,6 +689,34 @@ func (h *ProductHandler) UpdateProductMapping(c *gin.Context) {
 	xresponse.Success(c, "Product mapping updated", mapping)
 }
 
+// ReorderProductMappings sets the priorities of all mappings of a product from
+// their order in the request
+func (h *ProductHandler) ReorderProductMappings(c *gin.Context) {
+	productID := c.Param("id")
+	if productID == "" {
+		xresponse.BadRequest(c, "product id is required")
+		return
+	}
+
+	var req ReorderMappingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	mappings, err := h.productUC.ReorderProductMappings(productID, req.MappingIDs)
+	if err != nil {
+		if err.Error() == "product not found" {
+			xresponse.NotFound(c, "Product not found")
+			return
+		}
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Product mappings reordered", mappings)
+}
+
 // DeleteProductMapping removes mapping
 func (h *ProductHandler) DeleteProductMapping(c *gin.Context) {
 	mappingID := c.Param("id")
@@ -453,8 +733,193 @@ func (h *ProductHandler) DeleteProductMapping(c *gin.Context) {
 	xresponse.Success(c, "Product mapping deleted", gin.H{"mapping_id": mappingID})
 }
 
+// GetPriceHistory returns the price change history of a product
+func (h *ProductHandler) GetPriceHistory(c *gin.Context) {
+	productID := c.Param("id")
+	if productID == "" {
+		xresponse.BadRequest(c, "product id is required")
+		return
+	}
+
+	limit := 0
+	if v := c.Query("limit"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	history, err := h.pricingUC.GetPriceHistory(productID, limit)
+	if err != nil {
+		if err.Error() == "product not found" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		logger.Error("Failed to get price history", logger.String("product_id", productID), logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to get price history")
+		return
+	}
+
+	xresponse.Success(c, "Price history fetched", history)
+}
+
+// defaultPriceChangeWindow is listed when no since is given
+const defaultPriceChangeWindow = 7 * 24 * time.Hour
+
+// ListPriceChanges lists recent base and selling price changes across
+// products with their effective dates, newest first. Query: category, since
+// and until (RFC3339, default the last 7 days), page, limit. Admins may also
+// pass price_type, SUPPLIER included.
+func (h *ProductHandler) ListPriceChanges(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	_, _, userLevel, _ := h.roleGuard.GetCurrentUser(c)
+	filter := &domain.PriceChangeFilter{
+		Category:  c.Query("category"),
+		Since:     time.Now().Add(-defaultPriceChangeWindow),
+		UserLevel: userLevel,
+		Limit:     limit,
+		Offset:    (page - 1) * limit,
+	}
+	if sinceStr := c.Query("since"); sinceStr != "" {
+		since, err := time.Parse(time.RFC3339, sinceStr)
+		if err != nil {
+			xresponse.BadRequest(c, "since must be an RFC3339 timestamp")
+			return
+		}
+		filter.Since = since
+	}
+	if untilStr := c.Query("until"); untilStr != "" {
+		until, err := time.Parse(time.RFC3339, untilStr)
+		if err != nil {
+			xresponse.BadRequest(c, "until must be an RFC3339 timestamp")
+			return
+		}
+		filter.Until = until
+	}
+	if priceType := strings.ToUpper(strings.TrimSpace(c.Query("price_type"))); priceType != "" {
+		if userLevel != domain.LevelAdmin {
+			xresponse.Forbidden(c, "price_type filter is only available to admins")
+			return
+		}
+		filter.PriceTypes = []string{priceType}
+	}
+
+	changes, total, err := h.pricingUC.ListPriceChanges(filter)
+	if err != nil {
+		switch err.Error() {
+		case "invalid price type", "until must be after since":
+			xresponse.BadRequest(c, err.Error())
+		default:
+			logger.Error("Failed to list price changes", logger.ErrorField(err))
+			xresponse.InternalServerError(c, "Failed to list price changes")
+		}
+		return
+	}
+	if changes == nil {
+		changes = []*domain.PriceChange{}
+	}
+
+	xresponse.Paginated(c, "Price changes fetched", changes, page, limit, total)
+}
+
+// CheckProductMargin re-evaluates the margin of a product against its cheapest supplier
+func (h *ProductHandler) CheckProductMargin(c *gin.Context) {
+	productID := c.Param("id")
+	if productID == "" {
+		xresponse.BadRequest(c, "product id is required")
+		return
+	}
+
+	check, err := h.pricingUC.CheckProductMargin(productID)
+	if err != nil {
+		if err.Error() == "product not found" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		logger.Error("Failed to check product margin", logger.String("product_id", productID), logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to check product margin")
+		return
+	}
+
+	xresponse.Success(c, "Product margin checked", check)
+}
+
+// SimulatePricing returns the margins hypothetical base and selling prices
+// would give per user level and supplier mapping, without saving them
+func (h *ProductHandler) SimulatePricing(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "simulate_pricing", "admin")
+
+	productID := c.Param("id")
+	if productID == "" {
+		xresponse.BadRequest(c, "product id is required")
+		return
+	}
+
+	var req SimulatePricingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	simulation, err := h.pricingUC.SimulatePricing(productID, &domain.PriceSimulationInput{
+		BasePrice:    req.BasePrice,
+		SellingPrice: req.SellingPrice,
+	})
+	if err != nil {
+		switch err.Error() {
+		case "product not found":
+			xresponse.NotFound(c, err.Error())
+		case "prices must be greater than zero":
+			xresponse.BadRequest(c, err.Error())
+		default:
+			logger.Error("Failed to simulate product pricing", logger.String("product_id", productID), logger.ErrorField(err))
+			xresponse.InternalServerError(c, "Failed to simulate pricing")
+		}
+		return
+	}
+
+	xresponse.Success(c, "Pricing simulated", simulation)
+}
+
+// SyncSupplierPrices pulls the latest price list of a supplier
+func (h *ProductHandler) SyncSupplierPrices(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "sync_supplier_prices", "admin")
+
+	supplierID := c.Param("id")
+	if supplierID == "" {
+		xresponse.BadRequest(c, "supplier id is required")
+		return
+	}
+
+	result, err := h.pricingUC.SyncSupplierPrices(supplierID)
+	if err != nil {
+		if err.Error() == "supplier not found" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Supplier prices synced", result)
+}
+
+func (h *ProductHandler) currentUserID(c *gin.Context) *string {
+	if userID, _, _, exists := h.roleGuard.GetCurrentUser(c); exists && userID != "" {
+		return &userID
+	}
+	return nil
+}
+
 func (h *ProductHandler) toProductResponse(product *domain.Product) *ProductResponse {
-	return &ProductResponse{
+	response := &ProductResponse{
 		ID:                   product.ID,
 		Code:                 product.Code,
 		Name:                 product.Name,
@@ -470,9 +935,23 @@ func (h *ProductHandler) toProductResponse(product *domain.Product) *ProductResp
 		IsActive:             product.IsActive,
 		IsUnlimitedStock:     product.IsUnlimitedStock,
 		StockQuantity:        product.StockQuantity,
+		IsVoucherStock:       product.IsVoucherStock,
 		AllowMarkup:          product.AllowMarkup,
 		MaxMarkupPercentage:  product.MaxMarkupPercentage,
 		MinTransactionAmount: product.MinTransactionAmount,
 		MaxTransactionAmount: product.MaxTransactionAmount,
-	}
+		MarginFlagged:        product.MarginFlagged,
+		MarginFlaggedAt:      product.MarginFlaggedAt,
+		MarginFlagReason:     product.MarginFlagReason,
+		DestinationPattern:   product.DestinationPattern,
+		DestinationMinLength: product.DestinationMinLength,
+		DestinationMaxLength: product.DestinationMaxLength,
+		DestinationHint:      product.DestinationHint,
+	}
+	if product.MinLevel != nil {
+		role := domain.MapLevelToRole(*product.MinLevel)
+		response.MinLevel = product.MinLevel
+		response.MinRole = &role
+	}
+	return response
 }
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// PromotionHandler handles admin cashback promotion endpoints
+type PromotionHandler struct {
+	promotionUC domain.PromotionUsecase
+	roleGuard   *RoleGuard
+}
+
+// NewPromotionHandler creates a new promotion handler
+func NewPromotionHandler(promotionUC domain.PromotionUsecase) *PromotionHandler {
+	return &PromotionHandler{
+		promotionUC: promotionUC,
+		roleGuard:   NewRoleGuard(),
+	}
+}
+
+// PromotionRequest payload for creating or replacing a promotion
+type PromotionRequest struct {
+	Name        string    `json:"name" binding:"required"`
+	Description *string   `json:"description"`
+	Category    *string   `json:"category"`
+	UserLevels  []int     `json:"user_levels"`
+	Percentage  float64   `json:"percentage" binding:"required"`
+	MaxCashback *float64  `json:"max_cashback"`
+	Budget      *float64  `json:"budget"`
+	StartsAt    time.Time `json:"starts_at" binding:"required"`
+	EndsAt      time.Time `json:"ends_at" binding:"required"`
+	IsActive    *bool     `json:"is_active"`
+}
+
+func (req *PromotionRequest) toPromotion() *domain.Promotion {
+	promotion := &domain.Promotion{
+		Name:        req.Name,
+		Description: req.Description,
+		Category:    req.Category,
+		UserLevels:  req.UserLevels,
+		Percentage:  req.Percentage,
+		MaxCashback: req.MaxCashback,
+		Budget:      req.Budget,
+		StartsAt:    req.StartsAt,
+		EndsAt:      req.EndsAt,
+		IsActive:    true,
+	}
+	if req.IsActive != nil {
+		promotion.IsActive = *req.IsActive
+	}
+	return promotion
+}
+
+// CreatePromotion creates a new promotion
+func (h *PromotionHandler) CreatePromotion(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "create_promotion", "admin")
+
+	var req PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	promotion := req.toPromotion()
+	if userID, _, _, exists := h.roleGuard.GetCurrentUser(c); exists && userID != "" {
+		promotion.CreatedBy = &userID
+	}
+
+	if err := h.promotionUC.CreatePromotion(promotion); err != nil {
+		logger.Error("Failed to create promotion", logger.ErrorField(err))
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Created(c, "Promotion created", promotion)
+}
+
+// ListPromotions lists all promotions
+func (h *PromotionHandler) ListPromotions(c *gin.Context) {
+	promotions, err := h.promotionUC.ListPromotions()
+	if err != nil {
+		logger.Error("Failed to list promotions", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to list promotions")
+		return
+	}
+
+	xresponse.Success(c, "Promotions fetched", promotions)
+}
+
+// GetPromotion returns a promotion by ID
+func (h *PromotionHandler) GetPromotion(c *gin.Context) {
+	promotion, err := h.promotionUC.GetPromotion(c.Param("id"))
+	if err != nil {
+		xresponse.NotFound(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Promotion fetched", promotion)
+}
+
+// UpdatePromotion replaces a promotion
+func (h *PromotionHandler) UpdatePromotion(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "update_promotion", "admin")
+
+	var req PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	promotion := req.toPromotion()
+	promotion.ID = c.Param("id")
+
+	updated, err := h.promotionUC.UpdatePromotion(promotion)
+	if err != nil {
+		if err.Error() == "promotion not found" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Promotion updated", updated)
+}
+
+// GetPromotionReport reports the cashback paid per promotion between
+// start_date and end_date (YYYY-MM-DD, default the current month)
+func (h *PromotionHandler) GetPromotionReport(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "get_promotion_report", "admin")
+
+	now := time.Now()
+	startDate := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC) // Default to current month
+	endDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var err error
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		startDate, err = time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			xresponse.BadRequest(c, "Invalid start_date format. Use YYYY-MM-DD")
+			return
+		}
+	}
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		endDate, err = time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			xresponse.BadRequest(c, "Invalid end_date format. Use YYYY-MM-DD")
+			return
+		}
+	}
+
+	report, err := h.promotionUC.GetPromotionReport(startDate, endDate)
+	if err != nil {
+		switch err.Error() {
+		case "end date must not be before start date", "report range too large":
+			xresponse.BadRequest(c, err.Error())
+		default:
+			logger.Error("Failed to build promotion report", logger.ErrorField(err))
+			xresponse.InternalServerError(c, "Failed to build promotion report")
+		}
+		return
+	}
+
+	xresponse.Success(c, "Promotion report generated", report)
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// QuotaPlanHandler handles admin H2H quota plan endpoints
+type QuotaPlanHandler struct {
+	quotaUC   domain.QuotaUsecase
+	roleGuard *RoleGuard
+}
+
+// NewQuotaPlanHandler creates a new quota plan handler
+func NewQuotaPlanHandler(quotaUC domain.QuotaUsecase) *QuotaPlanHandler {
+	return &QuotaPlanHandler{
+		quotaUC:   quotaUC,
+		roleGuard: NewRoleGuard(),
+	}
+}
+
+// QuotaPlanRequest payload for creating or replacing a quota plan
+type QuotaPlanRequest struct {
+	Name               string         `json:"name" binding:"required"`
+	Description        *string        `json:"description"`
+	RequestsPerMinute  int            `json:"requests_per_minute"`
+	EndpointLimits     map[string]int `json:"endpoint_limits"`
+	TransactionsPerDay int            `json:"transactions_per_day"`
+	MaxAmountPerDay    float64        `json:"max_amount_per_day"`
+}
+
+func (req *QuotaPlanRequest) toQuotaPlan() *domain.QuotaPlan {
+	return &domain.QuotaPlan{
+		Name:               req.Name,
+		Description:        req.Description,
+		RequestsPerMinute:  req.RequestsPerMinute,
+		EndpointLimits:     req.EndpointLimits,
+		TransactionsPerDay: req.TransactionsPerDay,
+		MaxAmountPerDay:    req.MaxAmountPerDay,
+	}
+}
+
+// AssignQuotaPlanRequest payload for assigning a plan to an API client; a
+// null plan_id removes the plan
+type AssignQuotaPlanRequest struct {
+	PlanID *string `json:"plan_id"`
+}
+
+// CreatePlan creates a new quota plan
+func (h *QuotaPlanHandler) CreatePlan(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "create_quota_plan", "admin")
+
+	var req QuotaPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	plan := req.toQuotaPlan()
+	if err := h.quotaUC.CreatePlan(plan); err != nil {
+		logger.Error("Failed to create quota plan", logger.ErrorField(err))
+		if strings.Contains(err.Error(), "duplicate key") {
+			xresponse.Conflict(c, "Quota plan name already exists")
+			return
+		}
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Created(c, "Quota plan created", plan)
+}
+
+// ListPlans lists all quota plans
+func (h *QuotaPlanHandler) ListPlans(c *gin.Context) {
+	plans, err := h.quotaUC.ListPlans()
+	if err != nil {
+		logger.Error("Failed to list quota plans", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to list quota plans")
+		return
+	}
+
+	xresponse.Success(c, "Quota plans fetched", plans)
+}
+
+// GetPlan returns a quota plan by ID
+func (h *QuotaPlanHandler) GetPlan(c *gin.Context) {
+	plan, err := h.quotaUC.GetPlan(c.Param("id"))
+	if err != nil {
+		xresponse.NotFound(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Quota plan fetched", plan)
+}
+
+// UpdatePlan replaces a quota plan; assigned clients pick it up within the
+// plan cache TTL
+func (h *QuotaPlanHandler) UpdatePlan(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "update_quota_plan", "admin")
+
+	var req QuotaPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	plan := req.toQuotaPlan()
+	plan.ID = c.Param("id")
+
+	updated, err := h.quotaUC.UpdatePlan(plan)
+	if err != nil {
+		if err.Error() == "quota plan not found" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "duplicate key") {
+			xresponse.Conflict(c, "Quota plan name already exists")
+			return
+		}
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Quota plan updated", updated)
+}
+
+// DeletePlan removes a quota plan that no client uses
+func (h *QuotaPlanHandler) DeletePlan(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "delete_quota_plan", "admin")
+
+	id := c.Param("id")
+	if err := h.quotaUC.DeletePlan(id); err != nil {
+		if err.Error() == "quota plan not found" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		if strings.HasPrefix(err.Error(), "quota plan is assigned") {
+			xresponse.Conflict(c, err.Error())
+			return
+		}
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Quota plan deleted", gin.H{"plan_id": id})
+}
+
+// AssignPlan assigns a quota plan to an API client or removes it
+func (h *QuotaPlanHandler) AssignPlan(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "assign_quota_plan", "admin")
+
+	var req AssignQuotaPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	clientID := c.Param("client_id")
+	if err := h.quotaUC.AssignPlan(clientID, req.PlanID); err != nil {
+		switch err.Error() {
+		case "quota plan not found", "api client not found":
+			xresponse.NotFound(c, err.Error())
+		default:
+			logger.Error("Failed to assign quota plan",
+				logger.String("client_id", clientID),
+				logger.ErrorField(err),
+			)
+			xresponse.BadRequest(c, err.Error())
+		}
+		return
+	}
+
+	xresponse.Success(c, "Quota plan assigned", gin.H{
+		"client_id":     clientID,
+		"quota_plan_id": req.PlanID,
+	})
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// ReconciliationHandler handles balance reconciliation endpoints
+type ReconciliationHandler struct {
+	reconciliationUC domain.ReconciliationUsecase
+	roleGuard        *RoleGuard
+}
+
+// NewReconciliationHandler creates a new reconciliation handler
+func NewReconciliationHandler(reconciliationUC domain.ReconciliationUsecase) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		reconciliationUC: reconciliationUC,
+		roleGuard:        NewRoleGuard(),
+	}
+}
+
+// ResolveBalanceMismatchRequest payload
+type ResolveBalanceMismatchRequest struct {
+	Resolution string `json:"resolution" binding:"required,max=500"`
+}
+
+// ListMismatches lists balance mismatches, defaulting to open ones, largest
+// difference first
+func (h *ReconciliationHandler) ListMismatches(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	mismatches, err := h.reconciliationUC.ListMismatches(c.DefaultQuery("status", domain.BalanceMismatchOpen), page, limit)
+	if err != nil {
+		if err.Error() == "invalid mismatch status" {
+			xresponse.BadRequest(c, err.Error())
+			return
+		}
+		logger.Error("Failed to list balance mismatches", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to list balance mismatches")
+		return
+	}
+
+	xresponse.Success(c, "Balance mismatches fetched", mismatches)
+}
+
+// ResolveMismatch closes an open mismatch after the difference was corrected
+// or explained; the balance itself is left untouched
+func (h *ReconciliationHandler) ResolveMismatch(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "resolve_balance_mismatch", "admin")
+
+	var req ResolveBalanceMismatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	mismatch, err := h.reconciliationUC.ResolveMismatch(c.Param("id"), req.Resolution, h.currentUserID(c))
+	if err != nil {
+		switch err.Error() {
+		case "balance mismatch not found":
+			xresponse.NotFound(c, err.Error())
+		case "balance mismatch already closed":
+			xresponse.Conflict(c, err.Error())
+		case "resolution is required":
+			xresponse.BadRequest(c, err.Error())
+		default:
+			logger.Error("Failed to resolve balance mismatch",
+				logger.String("mismatch_id", c.Param("id")),
+				logger.ErrorField(err),
+			)
+			xresponse.InternalServerError(c, "Failed to resolve balance mismatch")
+		}
+		return
+	}
+
+	xresponse.Success(c, "Balance mismatch resolved", mismatch)
+}
+
+func (h *ReconciliationHandler) currentUserID(c *gin.Context) *string {
+	if userID, _, _, exists := h.roleGuard.GetCurrentUser(c); exists && userID != "" {
+		return &userID
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// ReferralHandler handles referral codes, sign-ups made with them and the
+// upline's approval of those sign-ups
+type ReferralHandler struct {
+	referralUC domain.ReferralUsecase
+	roleGuard  *RoleGuard
+}
+
+// NewReferralHandler creates a new referral handler
+func NewReferralHandler(referralUC domain.ReferralUsecase) *ReferralHandler {
+	return &ReferralHandler{
+		referralUC: referralUC,
+		roleGuard:  NewRoleGuard(),
+	}
+}
+
+// ReferralRegisterRequest payload
+type ReferralRegisterRequest struct {
+	ReferralCode string `json:"referral_code" binding:"required"`
+	Name         string `json:"name" binding:"required"`
+	Email        string `json:"email" binding:"required"`
+	Password     string `json:"password" binding:"required"`
+	Phone        string `json:"phone"`
+}
+
+// RejectRegistrationRequest payload
+type RejectRegistrationRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// Register records a sign-up under the upline owning the referral code. The
+// account is created once the upline approves.
+func (h *ReferralHandler) Register(c *gin.Context) {
+	var req ReferralRegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	registration, err := h.referralUC.Register(domain.ReferralRegistrationRequest{
+		ReferralCode: req.ReferralCode,
+		Email:        req.Email,
+		FullName:     req.Name,
+		Phone:        req.Phone,
+		Password:     req.Password,
+		IPAddress:    c.ClientIP(),
+	})
+	if err != nil {
+		respondReferralError(c, err, "Failed to register")
+		return
+	}
+
+	xresponse.Created(c, "Registration submitted, waiting for upline approval", gin.H{
+		"registration_id": registration.ID,
+		"status":          registration.Status,
+	})
+}
+
+// GetCode returns the current user's referral code
+func (h *ReferralHandler) GetCode(c *gin.Context) {
+	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	code, err := h.referralUC.GetCode(userID)
+	if err != nil {
+		respondReferralError(c, err, "Failed to get referral code")
+		return
+	}
+
+	xresponse.Success(c, "Referral code fetched", gin.H{"referral_code": code})
+}
+
+// RotateCode replaces the current user's referral code
+func (h *ReferralHandler) RotateCode(c *gin.Context) {
+	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	h.roleGuard.LogAccess(c, "rotate_referral_code", userID)
+
+	code, err := h.referralUC.RotateCode(userID)
+	if err != nil {
+		respondReferralError(c, err, "Failed to rotate referral code")
+		return
+	}
+
+	xresponse.Success(c, "Referral code rotated", gin.H{"referral_code": code})
+}
+
+// ListRegistrations lists the sign-ups made with the current user's codes.
+// Query: status (PENDING, APPROVED or REJECTED), limit.
+func (h *ReferralHandler) ListRegistrations(c *gin.Context) {
+	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	registrations, err := h.referralUC.ListRegistrations(userID, c.Query("status"), limit)
+	if err != nil {
+		respondReferralError(c, err, "Failed to list registrations")
+		return
+	}
+	if registrations == nil {
+		registrations = []*domain.DownlineRegistration{}
+	}
+
+	xresponse.Success(c, "Registrations fetched", registrations)
+}
+
+// ApproveRegistration creates the downline of a pending registration
+func (h *ReferralHandler) ApproveRegistration(c *gin.Context) {
+	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	h.roleGuard.LogAccess(c, "approve_downline_registration", c.Param("id"))
+
+	registration, err := h.referralUC.Approve(userID, c.Param("id"))
+	if err != nil {
+		respondReferralError(c, err, "Failed to approve registration")
+		return
+	}
+
+	xresponse.Success(c, "Registration approved", registration)
+}
+
+// RejectRegistration declines a pending registration
+func (h *ReferralHandler) RejectRegistration(c *gin.Context) {
+	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req RejectRegistrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	h.roleGuard.LogAccess(c, "reject_downline_registration", c.Param("id"))
+
+	registration, err := h.referralUC.Reject(userID, c.Param("id"), req.Reason)
+	if err != nil {
+		respondReferralError(c, err, "Failed to reject registration")
+		return
+	}
+
+	xresponse.Success(c, "Registration rejected", registration)
+}
+
+// respondReferralError maps referral errors to responses
+func respondReferralError(c *gin.Context, err error, failure string) {
+	message := err.Error()
+	switch {
+	case message == "referral code not found", message == "registration not found", message == "user not found":
+		xresponse.NotFound(c, message)
+	case message == "email already registered", message == "registration already pending",
+		message == "registration is not pending":
+		xresponse.Conflict(c, message)
+	case message == "user level cannot have downlines", message == "downline limit reached":
+		xresponse.Forbidden(c, message)
+	case strings.HasPrefix(message, "failed to"):
+		logger.Error(failure, logger.ErrorField(err))
+		xresponse.InternalServerError(c, failure)
+	default:
+		xresponse.BadRequest(c, message)
+	}
+}
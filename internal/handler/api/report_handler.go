@@ -0,0 +1,236 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// ReportHandler handles finance report endpoints
+type ReportHandler struct {
+	reportUC  domain.ReportUsecase
+	refundUC  domain.RefundSLAUsecase
+	roleGuard *RoleGuard
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(reportUC domain.ReportUsecase, refundUC domain.RefundSLAUsecase) *ReportHandler {
+	return &ReportHandler{
+		reportUC:  reportUC,
+		refundUC:  refundUC,
+		roleGuard: NewRoleGuard(),
+	}
+}
+
+// GetSupplierReport returns the profit and settlement report per supplier.
+// Query: start_date, end_date (YYYY-MM-DD, inclusive), granularity (day|month),
+// format (json|csv) and refresh=true to bypass cached periods.
+func (h *ReportHandler) GetSupplierReport(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "get_supplier_report", "admin")
+
+	now := time.Now()
+	startDate := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC) // Default to current month
+	endDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var err error
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		startDate, err = time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			xresponse.BadRequest(c, "Invalid start_date format. Use YYYY-MM-DD")
+			return
+		}
+	}
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		endDate, err = time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			xresponse.BadRequest(c, "Invalid end_date format. Use YYYY-MM-DD")
+			return
+		}
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", "json"))
+	if format != "json" && format != "csv" {
+		xresponse.BadRequest(c, "format must be json or csv")
+		return
+	}
+
+	report, err := h.reportUC.GetSupplierReport(startDate, endDate, c.DefaultQuery("granularity", domain.ReportGranularityMonth), c.Query("refresh") == "true")
+	if err != nil {
+		switch err.Error() {
+		case "invalid report granularity", "end date must not be before start date", "report range too large":
+			xresponse.BadRequest(c, err.Error())
+		default:
+			logger.Error("Failed to build supplier report", logger.ErrorField(err))
+			xresponse.InternalServerError(c, "Failed to build supplier report")
+		}
+		return
+	}
+
+	if format == "csv" {
+		h.writeSupplierReportCSV(c, report)
+		return
+	}
+
+	xresponse.Success(c, "Supplier report generated", report)
+}
+
+// GetTagReport groups transactions by the values of one tag key. Query: key
+// (required), start_date, end_date (YYYY-MM-DD, inclusive, default the
+// current month) and user_id to limit the report to one account.
+func (h *ReportHandler) GetTagReport(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "get_tag_report", "admin")
+
+	tagKey := c.Query("key")
+	if tagKey == "" {
+		xresponse.BadRequest(c, "key is required")
+		return
+	}
+
+	now := time.Now()
+	startDate := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC) // Default to current month
+	endDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var err error
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		startDate, err = time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			xresponse.BadRequest(c, "Invalid start_date format. Use YYYY-MM-DD")
+			return
+		}
+	}
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		endDate, err = time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			xresponse.BadRequest(c, "Invalid end_date format. Use YYYY-MM-DD")
+			return
+		}
+	}
+
+	report, err := h.reportUC.GetTagReport(startDate, endDate, tagKey, c.Query("user_id"))
+	if err != nil {
+		switch err.Error() {
+		case "invalid tag key", "end date must not be before start date", "report range too large":
+			xresponse.BadRequest(c, err.Error())
+		default:
+			logger.Error("Failed to build tag report", logger.ErrorField(err))
+			xresponse.InternalServerError(c, "Failed to build tag report")
+		}
+		return
+	}
+
+	xresponse.Success(c, "Tag report generated", report)
+}
+
+// GetRefundReport returns refund counts, amounts and latency from failure to
+// refund, plus the refunds still outstanding. Query: start_date, end_date
+// (YYYY-MM-DD, inclusive, default the current month) and older_than (a
+// duration such as 30m, default the stuck threshold).
+func (h *ReportHandler) GetRefundReport(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "get_refund_report", "admin")
+
+	now := time.Now()
+	startDate := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC) // Default to current month
+	endDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var err error
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		startDate, err = time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			xresponse.BadRequest(c, "Invalid start_date format. Use YYYY-MM-DD")
+			return
+		}
+	}
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		endDate, err = time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			xresponse.BadRequest(c, "Invalid end_date format. Use YYYY-MM-DD")
+			return
+		}
+	}
+
+	var olderThan time.Duration
+	if olderThanStr := c.Query("older_than"); olderThanStr != "" {
+		olderThan, err = time.ParseDuration(olderThanStr)
+		if err != nil {
+			xresponse.BadRequest(c, "Invalid older_than format. Use a duration such as 30m or 2h")
+			return
+		}
+	}
+
+	report, err := h.refundUC.GetRefundReport(startDate, endDate, olderThan)
+	if err != nil {
+		switch err.Error() {
+		case "end date must not be before start date", "report range too large", "older_than must not be negative":
+			xresponse.BadRequest(c, err.Error())
+		default:
+			logger.Error("Failed to build refund report", logger.ErrorField(err))
+			xresponse.InternalServerError(c, "Failed to build refund report")
+		}
+		return
+	}
+
+	xresponse.Success(c, "Refund report generated", report)
+}
+
+func (h *ReportHandler) writeSupplierReportCSV(c *gin.Context, report *domain.SupplierReport) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	_ = writer.Write([]string{
+		"period", "supplier_code", "supplier_name", "total_transactions", "success_count",
+		"failed_count", "refund_count", "success_ratio", "total_hpp", "total_selling",
+		"total_admin_fee", "gross_profit", "refund_total",
+	})
+
+	periodFormat := "2006-01"
+	if report.Granularity == domain.ReportGranularityDay {
+		periodFormat = "2006-01-02"
+	}
+	for _, row := range report.Rows {
+		_ = writer.Write(supplierReportCSVRecord(row.Period.Format(periodFormat), row))
+	}
+	for _, total := range report.Totals {
+		_ = writer.Write(supplierReportCSVRecord("TOTAL", total))
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		logger.Error("Failed to write supplier report CSV", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to export supplier report")
+		return
+	}
+
+	filename := fmt.Sprintf("supplier-report-%s-%s.csv",
+		report.StartDate.Format("20060102"),
+		report.EndDate.AddDate(0, 0, -1).Format("20060102"),
+	)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+func supplierReportCSVRecord(period string, row *domain.SupplierReportRow) []string {
+	return []string{
+		period,
+		row.SupplierCode,
+		row.SupplierName,
+		strconv.Itoa(row.TotalTransactions),
+		strconv.Itoa(row.SuccessCount),
+		strconv.Itoa(row.FailedCount),
+		strconv.Itoa(row.RefundCount),
+		strconv.FormatFloat(row.SuccessRatio, 'f', 4, 64),
+		strconv.FormatFloat(row.TotalHPP, 'f', 2, 64),
+		strconv.FormatFloat(row.TotalSelling, 'f', 2, 64),
+		strconv.FormatFloat(row.TotalAdminFee, 'f', 2, 64),
+		strconv.FormatFloat(row.GrossProfit, 'f', 2, 64),
+		strconv.FormatFloat(row.RefundTotal, 'f', 2, 64),
+	}
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestBudgetConfig sets how long synchronous requests, those calling
+// suppliers before they answer, may take. Other requests keep the server's
+// write timeout.
+type RequestBudgetConfig struct {
+	SyncWriteTimeout time.Duration
+	// Reserve is kept back from the deadline to write the response
+	Reserve time.Duration
+}
+
+var requestBudget = RequestBudgetConfig{
+	SyncWriteTimeout: 60 * time.Second,
+	Reserve:          2 * time.Second,
+}
+
+// SetRequestBudget configures the budget of synchronous requests
+func SetRequestBudget(config RequestBudgetConfig) {
+	if config.SyncWriteTimeout > 0 {
+		requestBudget.SyncWriteTimeout = config.SyncWriteTimeout
+	}
+	if config.Reserve >= 0 && config.Reserve < requestBudget.SyncWriteTimeout {
+		requestBudget.Reserve = config.Reserve
+	}
+}
+
+// syncRequestBudget gives a synchronous request the sync write timeout and a
+// context deadline a reserve before it; supplier calls inside the request
+// are bounded by that deadline
+func syncRequestBudget() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		applyRequestBudget(c)
+	}
+}
+
+// h2hRequestBudget applies the sync budget to H2H clients processed within
+// the request; others are queued and keep the server's write timeout. It must
+// run after H2HAuth.
+func h2hRequestBudget() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client, ok := GetClientFromContext(c)
+		if !ok || syncFailoverPolicy(client) == nil {
+			c.Next()
+			return
+		}
+		applyRequestBudget(c)
+	}
+}
+
+func applyRequestBudget(c *gin.Context) {
+	deadline := time.Now().Add(requestBudget.SyncWriteTimeout)
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(deadline); err != nil {
+		logger.FromContext(c.Request.Context()).Warn("Failed to extend write deadline of synchronous request", logger.ErrorField(err))
+	}
+
+	ctx, cancel := context.WithDeadline(c.Request.Context(), deadline.Add(-requestBudget.Reserve))
+	defer cancel()
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
+}
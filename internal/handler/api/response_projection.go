@@ -0,0 +1,73 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// transactionResponseFields are the fields an H2H client projection may
+// name: those of transaction responses and of simulated orders
+var transactionResponseFields = jsonFieldNames(TransactionResponse{}, domain.TransactionSimulation{})
+
+// projectTransactionResponse applies the response projection of the calling
+// H2H client to a transaction payload. Payloads for users and for clients
+// without a projection are returned as they are.
+func projectTransactionResponse(c *gin.Context, payload interface{}) interface{} {
+	client, isH2H := GetClientFromContext(c)
+	if !isH2H || client.ResponseFields.IsEmpty() {
+		return payload
+	}
+
+	fields, err := decodeResponseFields(payload)
+	if err != nil {
+		// The unprojected payload could leak hidden fields
+		logger.Error("Failed to project H2H response",
+			logger.String("client_id", client.ClientID),
+			logger.ErrorField(err),
+		)
+		return gin.H{}
+	}
+	client.ResponseFields.Apply(fields)
+	return fields
+}
+
+// decodeResponseFields turns a payload into its JSON object, keeping numbers
+// exactly as they would have been serialized
+func decodeResponseFields(payload interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// jsonFieldNames lists the JSON names of the fields of struct values
+func jsonFieldNames(values ...interface{}) []string {
+	seen := make(map[string]bool)
+	names := make([]string, 0)
+	for _, value := range values {
+		t := reflect.TypeOf(value)
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+			if name == "" || name == "-" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// RetryPolicyHandler handles admin auto-retry policy endpoints
+type RetryPolicyHandler struct {
+	retryPolicyUC domain.RetryPolicyUsecase
+	roleGuard     *RoleGuard
+}
+
+// NewRetryPolicyHandler creates a new retry policy handler
+func NewRetryPolicyHandler(retryPolicyUC domain.RetryPolicyUsecase) *RetryPolicyHandler {
+	return &RetryPolicyHandler{
+		retryPolicyUC: retryPolicyUC,
+		roleGuard:     NewRoleGuard(),
+	}
+}
+
+// CreateRetryPolicyRequest payload. Omit supplier_id for a policy applying
+// to every supplier; error_class is TIMEOUT, FAILURE or ANY.
+type CreateRetryPolicyRequest struct {
+	SupplierID        *string  `json:"supplier_id"`
+	ErrorClass        string   `json:"error_class" binding:"required"`
+	MaxAttempts       *int     `json:"max_attempts" binding:"required"`
+	InitialDelayMs    *int     `json:"initial_delay_ms"`
+	MaxDelayMs        *int     `json:"max_delay_ms"`
+	BackoffMultiplier *float64 `json:"backoff_multiplier"`
+	EnableJitter      *bool    `json:"enable_jitter"`
+}
+
+// UpdateRetryPolicyRequest payload; omitted fields keep their value
+type UpdateRetryPolicyRequest struct {
+	MaxAttempts       *int     `json:"max_attempts"`
+	InitialDelayMs    *int     `json:"initial_delay_ms"`
+	MaxDelayMs        *int     `json:"max_delay_ms"`
+	BackoffMultiplier *float64 `json:"backoff_multiplier"`
+	EnableJitter      *bool    `json:"enable_jitter"`
+	IsActive          *bool    `json:"is_active"`
+}
+
+// CreatePolicy creates a new retry policy
+func (h *RetryPolicyHandler) CreatePolicy(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "create_retry_policy", "admin")
+
+	var req CreateRetryPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	policy := &domain.RetryPolicy{
+		SupplierID:        req.SupplierID,
+		ErrorClass:        req.ErrorClass,
+		MaxAttempts:       *req.MaxAttempts,
+		InitialDelayMs:    2000,
+		MaxDelayMs:        30000,
+		BackoffMultiplier: 2,
+		EnableJitter:      true,
+	}
+	if req.InitialDelayMs != nil {
+		policy.InitialDelayMs = *req.InitialDelayMs
+	}
+	if req.MaxDelayMs != nil {
+		policy.MaxDelayMs = *req.MaxDelayMs
+	}
+	if req.BackoffMultiplier != nil {
+		policy.BackoffMultiplier = *req.BackoffMultiplier
+	}
+	if req.EnableJitter != nil {
+		policy.EnableJitter = *req.EnableJitter
+	}
+
+	if err := h.retryPolicyUC.CreatePolicy(policy); err != nil {
+		if err.Error() == "retry policy already exists" {
+			xresponse.Conflict(c, err.Error())
+			return
+		}
+		logger.Error("Failed to create retry policy", logger.ErrorField(err))
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Created(c, "Retry policy created", policy)
+}
+
+// ListPolicies lists retry policies, optionally filtered by supplier_id
+func (h *RetryPolicyHandler) ListPolicies(c *gin.Context) {
+	policies, err := h.retryPolicyUC.ListPolicies(c.Query("supplier_id"))
+	if err != nil {
+		logger.Error("Failed to list retry policies", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to list retry policies")
+		return
+	}
+
+	xresponse.Success(c, "Retry policies fetched", policies)
+}
+
+// ResolvePolicy shows the policy a failure of error_class at supplier_id
+// would get. A policy without an ID is the configured default.
+func (h *RetryPolicyHandler) ResolvePolicy(c *gin.Context) {
+	policy, err := h.retryPolicyUC.ResolvePolicy(c.Query("supplier_id"), c.Query("error_class"))
+	if err != nil {
+		if err.Error() == "invalid retry error class" {
+			xresponse.BadRequest(c, err.Error())
+			return
+		}
+		logger.Error("Failed to resolve retry policy", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to resolve retry policy")
+		return
+	}
+
+	xresponse.Success(c, "Retry policy resolved", policy)
+}
+
+// GetPolicy returns a retry policy by ID
+func (h *RetryPolicyHandler) GetPolicy(c *gin.Context) {
+	policy, err := h.retryPolicyUC.GetPolicy(c.Param("id"))
+	if err != nil {
+		xresponse.NotFound(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Retry policy fetched", policy)
+}
+
+// UpdatePolicy changes the limits or state of a retry policy
+func (h *RetryPolicyHandler) UpdatePolicy(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "update_retry_policy", "admin")
+
+	var req UpdateRetryPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	policy, err := h.retryPolicyUC.UpdatePolicy(c.Param("id"), &domain.RetryPolicyUpdate{
+		MaxAttempts:       req.MaxAttempts,
+		InitialDelayMs:    req.InitialDelayMs,
+		MaxDelayMs:        req.MaxDelayMs,
+		BackoffMultiplier: req.BackoffMultiplier,
+		EnableJitter:      req.EnableJitter,
+		IsActive:          req.IsActive,
+	})
+	if err != nil {
+		if err.Error() == "retry policy not found" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Retry policy updated", policy)
+}
+
+// DeletePolicy removes a retry policy
+func (h *RetryPolicyHandler) DeletePolicy(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "delete_retry_policy", "admin")
+
+	id := c.Param("id")
+	if err := h.retryPolicyUC.DeletePolicy(id); err != nil {
+		if err.Error() == "retry policy not found" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Retry policy deleted", gin.H{"policy_id": id})
+}
@@ -1,12 +1,24 @@
 package api
 
 import (
+	"strconv"
+
 	"github.com/alfanzaky/eraflazz/internal/domain"
 	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/metrics"
 	"github.com/alfanzaky/eraflazz/pkg/xresponse"
 	"github.com/gin-gonic/gin"
 )
 
+// securityRecorder persists access denials; nil disables persistence
+var securityRecorder domain.SecurityEventUsecase
+
+// SetSecurityEventRecorder registers the use case that stores access denials
+// raised by role guards and the admin middleware
+func SetSecurityEventRecorder(recorder domain.SecurityEventUsecase) {
+	securityRecorder = recorder
+}
+
 // RoleGuard provides helper functions for role-based access control in handlers
 type RoleGuard struct{}
 
@@ -55,27 +67,29 @@ func (rg *RoleGuard) RequireRole(requiredRole string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		_, role, _, exists := rg.GetCurrentUser(c)
 		if !exists {
-			logger.Warn("Access denied - user not authenticated",
+			logger.Component(logger.ComponentAuth).Warn("Access denied - user not authenticated",
 				logger.String("required_role", requiredRole),
 				logger.String("ip", c.ClientIP()),
 			)
+			recordAccessDenial(c, domain.DenialUnauthenticated, requiredRole)
 			xresponse.Unauthorized(c, "Authentication required")
 			c.Abort()
 			return
 		}
 
 		if role != requiredRole {
-			logger.Warn("Access denied - insufficient role",
+			logger.Component(logger.ComponentAuth).Warn("Access denied - insufficient role",
 				logger.String("user_role", role),
 				logger.String("required_role", requiredRole),
 				logger.String("ip", c.ClientIP()),
 			)
+			recordAccessDenial(c, domain.DenialInsufficientRole, requiredRole)
 			xresponse.Forbidden(c, "Insufficient permissions")
 			c.Abort()
 			return
 		}
 
-		logger.Debug("Role access granted",
+		logger.Component(logger.ComponentAuth).Debug("Role access granted",
 			logger.String("user_role", role),
 			logger.String("required_role", requiredRole),
 		)
@@ -89,29 +103,31 @@ func (rg *RoleGuard) RequireMinimumLevel(minLevel int) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		_, _, userLevel, exists := rg.GetCurrentUser(c)
 		if !exists {
-			logger.Warn("Access denied - user not authenticated",
-				logger.String("required_level", string(rune(minLevel))),
+			logger.Component(logger.ComponentAuth).Warn("Access denied - user not authenticated",
+				logger.String("required_level", strconv.Itoa(minLevel)),
 				logger.String("ip", c.ClientIP()),
 			)
+			recordAccessDenial(c, domain.DenialUnauthenticated, "level:"+strconv.Itoa(minLevel))
 			xresponse.Unauthorized(c, "Authentication required")
 			c.Abort()
 			return
 		}
 
 		if userLevel < minLevel {
-			logger.Warn("Access denied - insufficient level",
-				logger.String("user_level", string(rune(userLevel))),
-				logger.String("required_level", string(rune(minLevel))),
+			logger.Component(logger.ComponentAuth).Warn("Access denied - insufficient level",
+				logger.String("user_level", strconv.Itoa(userLevel)),
+				logger.String("required_level", strconv.Itoa(minLevel)),
 				logger.String("ip", c.ClientIP()),
 			)
+			recordAccessDenial(c, domain.DenialInsufficientLevel, "level:"+strconv.Itoa(minLevel))
 			xresponse.Forbidden(c, "Insufficient permissions")
 			c.Abort()
 			return
 		}
 
-		logger.Debug("Level access granted",
-			logger.String("user_level", string(rune(userLevel))),
-			logger.String("required_level", string(rune(minLevel))),
+		logger.Component(logger.ComponentAuth).Debug("Level access granted",
+			logger.String("user_level", strconv.Itoa(userLevel)),
+			logger.String("required_level", strconv.Itoa(minLevel)),
 		)
 
 		c.Next()
@@ -142,7 +158,7 @@ func (rg *RoleGuard) CanAccessOwnData(c *gin.Context, resourceUserID string) boo
 
 	// Admin can access any data
 	if role == domain.RoleAdmin || userLevel >= domain.LevelAdmin {
-		logger.Debug("Admin access granted - can access any data",
+		logger.Component(logger.ComponentAuth).Debug("Admin access granted - can access any data",
 			logger.String("admin_id", userID),
 			logger.String("resource_user_id", resourceUserID),
 		)
@@ -151,17 +167,18 @@ func (rg *RoleGuard) CanAccessOwnData(c *gin.Context, resourceUserID string) boo
 
 	// Users can only access their own data
 	if userID == resourceUserID {
-		logger.Debug("Self access granted",
+		logger.Component(logger.ComponentAuth).Debug("Self access granted",
 			logger.String("user_id", userID),
 		)
 		return true
 	}
 
-	logger.Warn("Access denied - cannot access other user's data",
+	logger.Component(logger.ComponentAuth).Warn("Access denied - cannot access other user's data",
 		logger.String("user_id", userID),
 		logger.String("resource_user_id", resourceUserID),
 		logger.String("user_role", role),
 	)
+	recordAccessDenial(c, domain.DenialForeignResource, "owner")
 
 	return false
 }
@@ -194,7 +211,7 @@ func (rg *RoleGuard) RequireUserOrH2H() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Check if it's an H2H client
 		if rg.IsH2HClient(c) {
-			logger.Debug("H2H client access granted")
+			logger.Component(logger.ComponentAuth).Debug("H2H client access granted")
 			c.Next()
 			return
 		}
@@ -202,15 +219,16 @@ func (rg *RoleGuard) RequireUserOrH2H() gin.HandlerFunc {
 		// Check if it's an authenticated user
 		_, _, _, exists := rg.GetCurrentUser(c)
 		if !exists {
-			logger.Warn("Access denied - not authenticated user or H2H client",
+			logger.Component(logger.ComponentAuth).Warn("Access denied - not authenticated user or H2H client",
 				logger.String("ip", c.ClientIP()),
 			)
+			recordAccessDenial(c, domain.DenialUnauthenticated, "user_or_h2h")
 			xresponse.Unauthorized(c, "Authentication required")
 			c.Abort()
 			return
 		}
 
-		logger.Debug("User access granted")
+		logger.Component(logger.ComponentAuth).Debug("User access granted")
 		c.Next()
 	}
 }
@@ -218,7 +236,7 @@ func (rg *RoleGuard) RequireUserOrH2H() gin.HandlerFunc {
 // LogAccess logs access with user information
 func (rg *RoleGuard) LogAccess(c *gin.Context, action string, resource string) {
 	if clientID, exists := GetClientIDFromContext(c); exists {
-		logger.Info("H2H client action",
+		logger.Component(logger.ComponentAuth).Info("H2H client action",
 			logger.String("client_id", clientID),
 			logger.String("action", action),
 			logger.String("resource", resource),
@@ -229,7 +247,7 @@ func (rg *RoleGuard) LogAccess(c *gin.Context, action string, resource string) {
 
 	userID, role, _, exists := rg.GetCurrentUser(c)
 	if exists {
-		logger.Info("User action",
+		logger.Component(logger.ComponentAuth).Info("User action",
 			logger.String("user_id", userID),
 			logger.String("role", role),
 			logger.String("action", action),
@@ -238,3 +256,45 @@ func (rg *RoleGuard) LogAccess(c *gin.Context, action string, resource string) {
 		)
 	}
 }
+
+// recordAccessDenial counts the denial and stores it as a structured security
+// event. Persistence runs in the background so denials never slow responses.
+func recordAccessDenial(c *gin.Context, reason, requirement string) {
+	metrics.RecordAccessDenial(reason, requirement)
+
+	if securityRecorder == nil {
+		return
+	}
+
+	event := &domain.SecurityEvent{
+		Reason:    reason,
+		IPAddress: c.ClientIP(),
+		Method:    c.Request.Method,
+		Resource:  c.FullPath(),
+	}
+	if event.Resource == "" {
+		event.Resource = c.Request.URL.Path
+	}
+	if requirement != "" {
+		event.Requirement = &requirement
+	}
+	if userID := c.GetString("user_id"); userID != "" {
+		event.UserID = &userID
+	}
+	if role := c.GetString("user_role"); role != "" {
+		event.UserRole = &role
+	}
+	if userAgent := c.Request.UserAgent(); userAgent != "" {
+		event.UserAgent = &userAgent
+	}
+
+	go func() {
+		if err := securityRecorder.RecordDenial(event); err != nil {
+			logger.Component(logger.ComponentAuth).Error("Failed to record access denial",
+				logger.String("reason", event.Reason),
+				logger.String("ip", event.IPAddress),
+				logger.ErrorField(err),
+			)
+		}
+	}()
+}
@@ -1,11 +1,8 @@
 package api
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
-	"io"
-	"net"
 	"strings"
 	"time"
 
@@ -24,121 +21,107 @@ func SetupRoutes(
 	transactionHandler *TransactionHandler,
 	productHandler *ProductHandler,
 	authHandler *AuthHandler,
+	routingOverrideHandler *RoutingOverrideHandler,
+	notificationHandler *NotificationHandler,
+	mutationHandler *MutationHandler,
+	mappingReviewHandler *MappingReviewHandler,
+	securityHandler *SecurityHandler,
+	reportHandler *ReportHandler,
+	schedulerHandler *SchedulerHandler,
+	feeHandler *FeeHandler,
+	statementHandler *StatementHandler,
+	supplierSLAHandler *SupplierSLAHandler,
+	destinationRuleHandler *DestinationRuleHandler,
+	chaosHandler *ChaosHandler,
+	favoriteHandler *FavoriteHandler,
+	balanceHandler *BalanceHandler,
+	quotaPlanHandler *QuotaPlanHandler,
+	userPriceHandler *UserPriceHandler,
+	supplierWebhookHandler *SupplierWebhookHandler,
+	h2hPortalHandler *H2HPortalHandler,
+	reconciliationHandler *ReconciliationHandler,
+	cutoffScheduleHandler *CutoffScheduleHandler,
+	priceListHandler *PriceListHandler,
+	downlineHandler *DownlineHandler,
+	retryPolicyHandler *RetryPolicyHandler,
+	loggingHandler *LoggingHandler,
+	catalogHandler *CatalogHandler,
+	supplierHandler *SupplierHandler,
+	impersonationHandler *ImpersonationHandler,
+	referralHandler *ReferralHandler,
+	statusPageHandler *StatusPageHandler,
+	supplierInvoiceHandler *SupplierInvoiceHandler,
+	promotionHandler *PromotionHandler,
+	voucherHandler *VoucherHandler,
+	featureFlagHandler *FeatureFlagHandler,
+	mutationWebhookHandler *MutationWebhookHandler,
 	authService domain.AuthService,
 	clientRepo *postgres.APIClientRepository,
+	nonceRepo domain.NonceRepository,
+	quotaUC domain.QuotaUsecase,
+	signingUC domain.AdminSigningUsecase,
+	backpressureUC domain.BackpressureUsecase,
 ) {
+	registerBindingFieldNames()
+
 	v1 := router.Group("/api/v1")
 	{
-		configureTransactionRoutes(v1, transactionHandler, authService)
+		configureTransactionRoutes(v1, transactionHandler, authService, signingUC, nonceRepo, backpressureUC)
+		configureFavoriteRoutes(v1, favoriteHandler, authService)
+		configureMutationRoutes(v1, mutationHandler, authService)
+		configureBalanceRoutes(v1, balanceHandler, authService)
+		configureStatementRoutes(v1, statementHandler, authService)
+		configureMutationWebhookRoutes(v1, mutationWebhookHandler, authService)
+		configureProductRoutes(v1, productHandler, authService)
 		configureAdminProductRoutes(v1, productHandler, authService)
+		configureAdminVoucherRoutes(v1, voucherHandler, authService)
+		configureAdminRoutingRoutes(v1, routingOverrideHandler, authService)
+		configureAdminMappingReviewRoutes(v1, mappingReviewHandler, authService)
+		configureAdminReconciliationRoutes(v1, reconciliationHandler, authService)
+		configureAdminSecurityRoutes(v1, securityHandler, authService)
+		configureAdminReportRoutes(v1, reportHandler, authService)
+		configureAdminSchedulerRoutes(v1, schedulerHandler, authService)
+		configureAdminFeeRoutes(v1, feeHandler, authService)
+		configureAdminPromotionRoutes(v1, promotionHandler, authService)
+		configureAdminSupplierRoutes(v1, supplierHandler, supplierSLAHandler, authService)
+		configureAdminSupplierInvoiceRoutes(v1, supplierInvoiceHandler, authService)
+		configureAdminDestinationRuleRoutes(v1, destinationRuleHandler, authService)
+		configureAdminChaosRoutes(v1, chaosHandler, authService)
+		configureAdminCutoffRoutes(v1, cutoffScheduleHandler, authService)
+		configureAdminRetryPolicyRoutes(v1, retryPolicyHandler, authService)
+		configureAdminFeatureFlagRoutes(v1, featureFlagHandler, authService)
+		configureAdminLoggingRoutes(v1, loggingHandler, authService)
+		configureAdminCatalogRoutes(v1, catalogHandler, authService)
+		configureAdminQuotaRoutes(v1, quotaPlanHandler, authService)
+		configureAdminAPIClientRoutes(v1, NewAPIClientHandler(clientRepo), authService)
+		configureAdminSigningRoutes(v1, NewAdminSigningHandler(signingUC), authService)
+		configureAdminImpersonationRoutes(v1, impersonationHandler, authService)
+		configureUserPriceRoutes(v1, userPriceHandler, authService)
+		configureDownlineRoutes(v1, downlineHandler, authService)
+		configureReferralRoutes(v1, referralHandler, authService)
+		configureAdminStatusRoutes(v1, statusPageHandler, authService)
 		configureAuthRoutes(v1, authHandler)
-		configureH2HRoutes(v1, clientRepo)
-		configurePublicRoutes(v1)
+		configureAdminAuthRoutes(v1, authHandler, authService)
+		configureNotificationRoutes(v1, notificationHandler, authService)
+		configureH2HRoutes(v1, transactionHandler, h2hPortalHandler, clientRepo, nonceRepo, quotaUC, backpressureUC)
+		configureSupplierWebhookRoutes(v1, supplierWebhookHandler)
+		configurePublicRoutes(v1, priceListHandler, catalogHandler, statusPageHandler)
 	}
 
 	logger.Info("API routes configured successfully")
 }
 
-// h2hMiddleware secures H2H routes using API key + signature verification
-func h2hMiddleware(authService domain.AuthService, allowedIPs []string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		if authService == nil {
-			xresponse.InternalServerError(c, "Auth service not available")
-			c.Abort()
-			return
-		}
-
-		// IP Whitelist validation
-		clientIP := net.ParseIP(c.ClientIP())
-		if len(allowedIPs) > 0 && (clientIP == nil || !isIPAllowed(clientIP, allowedIPs)) {
-			logger.Warn("H2H access denied - IP not allowed",
-				logger.String("client_ip", c.ClientIP()),
-				logger.Any("allowed_ips", allowedIPs),
-			)
-			xresponse.Forbidden(c, "IP address not allowed")
-			c.Abort()
-			return
-		}
-
-		// Extract H2H authentication headers
-		apiKey := strings.TrimSpace(c.GetHeader("X-API-Key"))
-		signature := strings.TrimSpace(c.GetHeader("X-Signature"))
-		timestamp := strings.TrimSpace(c.GetHeader("X-Timestamp"))
-
-		// Validate required headers
-		if apiKey == "" || signature == "" || timestamp == "" {
-			logger.Warn("H2H authentication failed - missing headers",
-				logger.String("client_ip", c.ClientIP()),
-				logger.Bool("has_api_key", apiKey != ""),
-				logger.Bool("has_signature", signature != ""),
-				logger.Bool("has_timestamp", timestamp != ""),
-			)
-			xresponse.Unauthorized(c, "Missing H2H authentication headers")
-			c.Abort()
-			return
-		}
-
-		// Validate timestamp format and age
-		reqTime, err := time.Parse(time.RFC3339, timestamp)
-		if err != nil {
-			logger.Warn("H2H authentication failed - invalid timestamp format",
-				logger.String("client_ip", c.ClientIP()),
-				logger.String("timestamp", timestamp),
-				logger.String("error", err.Error()),
-			)
-			xresponse.BadRequest(c, "Invalid timestamp format")
-			c.Abort()
-			return
-		}
-
-		// Check timestamp age (prevent replay attacks)
-		if time.Since(reqTime) > 5*time.Minute {
-			logger.Warn("H2H authentication failed - timestamp too old",
-				logger.String("client_ip", c.ClientIP()),
-				logger.String("timestamp", timestamp),
-				logger.String("req_time", reqTime.Format(time.RFC3339)),
-			)
-			xresponse.Unauthorized(c, "Request timestamp too old")
-			c.Abort()
-			return
-		}
-
-		// Read request body for signature validation
-		bodyBytes, err := io.ReadAll(c.Request.Body)
-		if err != nil {
-			logger.Error("H2H authentication failed - failed to read request body",
-				logger.String("client_ip", c.ClientIP()),
-				logger.String("error", err.Error()),
-			)
-			xresponse.InternalServerError(c, "Failed to read request body")
-			c.Abort()
-			return
-		}
-		// Restore request body for subsequent handlers
-		c.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
-
-		// Validate H2H signature
-		if err := authService.ValidateH2HSignature(apiKey, signature, timestamp, bodyBytes); err != nil {
-			logger.Warn("H2H authentication failed - invalid signature",
-				logger.String("client_ip", c.ClientIP()),
-				logger.String("api_key", apiKey),
-				logger.String("error", err.Error()),
-			)
-			xresponse.Unauthorized(c, "Invalid H2H signature")
-			c.Abort()
-			return
-		}
-
-		// Set API key in context for handlers
-		c.Set("h2h_api_key", apiKey)
-
-		logger.Info("H2H authentication successful",
-			logger.String("client_ip", c.ClientIP()),
-			logger.String("api_key", apiKey),
-			logger.String("endpoint", c.Request.URL.Path),
-		)
-
-		c.Next()
+// requestActor returns the actor the auth middleware attached to the request,
+// falling back to the authenticated user
+func requestActor(c *gin.Context) domain.Actor {
+	if actor, ok := domain.ActorFromContext(c.Request.Context()); ok {
+		return actor
+	}
+	return domain.Actor{
+		Type:      domain.ActorTypeUser,
+		UserID:    c.GetString("user_id"),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
 	}
 }
 
@@ -147,42 +130,108 @@ func configureAuthRoutes(group *gin.RouterGroup, authHandler *AuthHandler) {
 	{
 		authRoutes.POST("/register", authHandler.Register)
 		authRoutes.POST("/login", authHandler.Login)
+		authRoutes.POST("/forgot-password", authHandler.ForgotPassword)
+		authRoutes.POST("/reset-password", authHandler.ResetPassword)
 	}
 }
 
-func isIPAllowed(ip net.IP, allowed []string) bool {
-	for _, entry := range allowed {
-		entry = strings.TrimSpace(entry)
-		if entry == "" {
-			continue
-		}
-
-		if strings.Contains(entry, "/") {
-			_, cidr, err := net.ParseCIDR(entry)
-			if err == nil && cidr.Contains(ip) {
-				return true
-			}
-			continue
-		}
-
-		if ip.Equal(net.ParseIP(entry)) {
-			return true
-		}
+func configureAdminAuthRoutes(group *gin.RouterGroup, authHandler *AuthHandler, authService domain.AuthService) {
+	adminRoutes := group.Group("/admin/auth")
+	adminRoutes.Use(authMiddleware(authService), adminMiddleware())
+	{
+		adminRoutes.POST("/unlock", authHandler.UnlockLogin)
 	}
-
-	return false
 }
 
-func configureTransactionRoutes(group *gin.RouterGroup, transactionHandler *TransactionHandler, authService domain.AuthService) {
+func configureTransactionRoutes(group *gin.RouterGroup, transactionHandler *TransactionHandler, authService domain.AuthService, signingUC domain.AdminSigningUsecase, nonceRepo domain.NonceRepository, backpressureUC domain.BackpressureUsecase) {
 	routes := group.Group("/transactions")
 	routes.Use(authMiddleware(authService))
 	{
-		routes.POST("", transactionHandler.CreateTransaction)
+		routes.POST("", intakeMiddleware(backpressureUC), transactionHandler.CreateTransaction)
 		routes.GET("/:id", transactionHandler.GetTransaction)
 		routes.GET("/code/:code", transactionHandler.GetTransactionByCode)
 		routes.GET("/user", transactionHandler.GetUserTransactions)
 		routes.DELETE("/:id", transactionHandler.CancelTransaction)
 		routes.GET("/stats", transactionHandler.GetTransactionStats)
+		routes.GET("/confirmation-settings", transactionHandler.GetConfirmationSettings)
+		routes.PUT("/confirmation-settings", transactionHandler.UpdateConfirmationSettings)
+	}
+
+	adminRoutes := group.Group("/admin/transactions")
+	adminRoutes.Use(authMiddleware(authService), adminMiddleware())
+	{
+		adminRoutes.GET("/:id", transactionHandler.GetAdminTransaction)
+		adminRoutes.GET("/:id/timeline", transactionHandler.GetTransactionTimeline)
+		adminRoutes.POST("/:id/force-refund", adminSignatureMiddleware(signingUC, nonceRepo), transactionHandler.ForceRefund)
+		adminRoutes.POST("/:id/reprocess", adminSignatureMiddleware(signingUC, nonceRepo), syncRequestBudget(), transactionHandler.Reprocess)
+	}
+}
+
+func configureFavoriteRoutes(group *gin.RouterGroup, favoriteHandler *FavoriteHandler, authService domain.AuthService) {
+	routes := group.Group("/favorites")
+	routes.Use(authMiddleware(authService))
+	{
+		routes.GET("", favoriteHandler.ListFavorites)
+		routes.POST("", favoriteHandler.CreateFavorite)
+		routes.PUT("/:id", favoriteHandler.UpdateFavorite)
+		routes.DELETE("/:id", favoriteHandler.DeleteFavorite)
+	}
+
+	quickOrders := group.Group("/transactions/quick")
+	quickOrders.Use(authMiddleware(authService))
+	{
+		quickOrders.POST("/:favorite_id", favoriteHandler.QuickOrder)
+	}
+}
+
+func configureMutationRoutes(group *gin.RouterGroup, mutationHandler *MutationHandler, authService domain.AuthService) {
+	routes := group.Group("/mutations")
+	routes.Use(authMiddleware(authService))
+	{
+		routes.GET("", mutationHandler.GetUserMutations)
+		routes.GET("/daily", mutationHandler.GetDailySummary)
+	}
+}
+
+func configureBalanceRoutes(group *gin.RouterGroup, balanceHandler *BalanceHandler, authService domain.AuthService) {
+	routes := group.Group("/balance")
+	routes.Use(authMiddleware(authService))
+	{
+		routes.POST("/transfer", balanceHandler.Transfer)
+		routes.GET("/transfers", balanceHandler.ListTransfers)
+		routes.PUT("/pin", balanceHandler.SetPIN)
+	}
+}
+
+func configureStatementRoutes(group *gin.RouterGroup, statementHandler *StatementHandler, authService domain.AuthService) {
+	routes := group.Group("/statements")
+	routes.Use(authMiddleware(authService))
+	{
+		routes.GET("/:year/:month", statementHandler.GetStatement)
+		routes.GET("/jobs/:id", statementHandler.GetStatementJob)
+		routes.GET("/jobs/:id/download", statementHandler.DownloadStatementJob)
+	}
+}
+
+func configureMutationWebhookRoutes(group *gin.RouterGroup, mutationWebhookHandler *MutationWebhookHandler, authService domain.AuthService) {
+	routes := group.Group("/mutation-webhook")
+	routes.Use(authMiddleware(authService))
+	{
+		routes.GET("", mutationWebhookHandler.GetWebhook)
+		routes.PUT("", mutationWebhookHandler.SetWebhook)
+		routes.DELETE("", mutationWebhookHandler.DeleteWebhook)
+		routes.POST("/secret/rotate", mutationWebhookHandler.RotateSecret)
+		routes.GET("/deliveries", mutationWebhookHandler.ListDeliveries)
+		routes.POST("/deliveries/:id/resend", mutationWebhookHandler.ResendDelivery)
+	}
+}
+
+func configureProductRoutes(group *gin.RouterGroup, productHandler *ProductHandler, authService domain.AuthService) {
+	routes := group.Group("/products")
+	routes.Use(authMiddleware(authService), compressionMiddleware())
+	{
+		routes.GET("/search", productHandler.SearchProducts)
+		routes.GET("/price-changes", productHandler.ListPriceChanges)
 	}
 }
 
@@ -190,7 +239,7 @@ func configureAdminProductRoutes(group *gin.RouterGroup, productHandler *Product
 	adminRoutes := group.Group("/admin")
 	adminRoutes.Use(authMiddleware(authService), adminMiddleware())
 	{
-		products := adminRoutes.Group("/products")
+		products := adminRoutes.Group("/products", compressionMiddleware())
 		{
 			products.POST("", productHandler.CreateProduct)
 			products.GET("", productHandler.ListProducts)
@@ -198,8 +247,14 @@ func configureAdminProductRoutes(group *gin.RouterGroup, productHandler *Product
 			products.PUT("/:id", productHandler.UpdateProduct)
 			products.PATCH("/:id/status", productHandler.ToggleProductStatus)
 			products.PATCH("/:id/stock", productHandler.UpdateProductStock)
+			products.PUT("/:id/destination-rule", productHandler.UpdateDestinationOverride)
+			products.PUT("/:id/restriction", productHandler.UpdateProductRestriction)
 			products.GET("/:id/mappings", productHandler.ListProductMappings)
 			products.POST("/:id/mappings", productHandler.CreateProductMapping)
+			products.PATCH("/:id/mappings/reorder", productHandler.ReorderProductMappings)
+			products.GET("/:id/price-history", productHandler.GetPriceHistory)
+			products.POST("/:id/margin-check", productHandler.CheckProductMargin)
+			products.POST("/:id/simulate-pricing", productHandler.SimulatePricing)
 		}
 
 		mappings := adminRoutes.Group("/product-mappings")
@@ -207,13 +262,393 @@ func configureAdminProductRoutes(group *gin.RouterGroup, productHandler *Product
 			mappings.PUT("/:id", productHandler.UpdateProductMapping)
 			mappings.DELETE("/:id", productHandler.DeleteProductMapping)
 		}
+
+		adminRoutes.POST("/suppliers/:id/price-sync", productHandler.SyncSupplierPrices)
+	}
+}
+
+func configureAdminRoutingRoutes(group *gin.RouterGroup, routingOverrideHandler *RoutingOverrideHandler, authService domain.AuthService) {
+	adminRoutes := group.Group("/admin")
+	adminRoutes.Use(authMiddleware(authService), adminMiddleware())
+	{
+		overrides := adminRoutes.Group("/routing-overrides")
+		{
+			overrides.POST("", routingOverrideHandler.CreateOverride)
+			overrides.GET("", routingOverrideHandler.ListOverrides)
+			overrides.GET("/:id", routingOverrideHandler.GetOverride)
+			overrides.PATCH("/:id", routingOverrideHandler.UpdateOverride)
+			overrides.DELETE("/:id", routingOverrideHandler.DeleteOverride)
+		}
+	}
+}
+
+func configureAdminFeeRoutes(group *gin.RouterGroup, feeHandler *FeeHandler, authService domain.AuthService) {
+	rules := group.Group("/admin/fee-rules")
+	rules.Use(authMiddleware(authService), adminMiddleware())
+	{
+		rules.GET("/quote", feeHandler.QuoteFee)
+		rules.POST("", feeHandler.CreateRule)
+		rules.GET("", feeHandler.ListRules)
+		rules.GET("/:id", feeHandler.GetRule)
+		rules.PUT("/:id", feeHandler.UpdateRule)
+		rules.DELETE("/:id", feeHandler.DeleteRule)
+	}
+}
+
+func configureAdminVoucherRoutes(group *gin.RouterGroup, voucherHandler *VoucherHandler, authService domain.AuthService) {
+	products := group.Group("/admin/products")
+	products.Use(authMiddleware(authService), adminMiddleware())
+	{
+		products.POST("/:id/voucher-codes", voucherHandler.UploadCodes)
+		products.GET("/:id/voucher-codes", voucherHandler.GetStock)
+		products.PUT("/:id/voucher-stock", voucherHandler.SetStockMode)
+	}
+}
+
+func configureAdminPromotionRoutes(group *gin.RouterGroup, promotionHandler *PromotionHandler, authService domain.AuthService) {
+	promotions := group.Group("/admin/promotions")
+	promotions.Use(authMiddleware(authService), adminMiddleware())
+	{
+		promotions.GET("/report", promotionHandler.GetPromotionReport)
+		promotions.POST("", promotionHandler.CreatePromotion)
+		promotions.GET("", promotionHandler.ListPromotions)
+		promotions.GET("/:id", promotionHandler.GetPromotion)
+		promotions.PUT("/:id", promotionHandler.UpdatePromotion)
+	}
+}
+
+func configureAdminQuotaRoutes(group *gin.RouterGroup, quotaPlanHandler *QuotaPlanHandler, authService domain.AuthService) {
+	adminRoutes := group.Group("/admin")
+	adminRoutes.Use(authMiddleware(authService), adminMiddleware())
+	{
+		plans := adminRoutes.Group("/quota-plans")
+		{
+			plans.POST("", quotaPlanHandler.CreatePlan)
+			plans.GET("", quotaPlanHandler.ListPlans)
+			plans.GET("/:id", quotaPlanHandler.GetPlan)
+			plans.PUT("/:id", quotaPlanHandler.UpdatePlan)
+			plans.DELETE("/:id", quotaPlanHandler.DeletePlan)
+		}
+		adminRoutes.PUT("/api-clients/:client_id/quota-plan", quotaPlanHandler.AssignPlan)
+	}
+}
+
+func configureUserPriceRoutes(group *gin.RouterGroup, userPriceHandler *UserPriceHandler, authService domain.AuthService) {
+	downlines := group.Group("/downlines/:id/prices")
+	downlines.Use(authMiddleware(authService))
+	{
+		downlines.GET("", userPriceHandler.ListPrices)
+		downlines.PUT("/:product_code", userPriceHandler.SetPrice)
+		downlines.DELETE("/:product_code", userPriceHandler.DeletePrice)
+	}
+
+	adminRoutes := group.Group("/admin/users/:id/prices")
+	adminRoutes.Use(authMiddleware(authService), adminMiddleware())
+	{
+		adminRoutes.GET("", userPriceHandler.ListPrices)
+		adminRoutes.PUT("/:product_code", userPriceHandler.SetPrice)
+		adminRoutes.DELETE("/:product_code", userPriceHandler.DeletePrice)
+	}
+
+	tierRoutes := group.Group("/admin/tier-prices/:product_code")
+	tierRoutes.Use(authMiddleware(authService), adminMiddleware())
+	{
+		tierRoutes.GET("", userPriceHandler.ListTierPrices)
+		tierRoutes.PUT("/:level", userPriceHandler.SetTierPrice)
+		tierRoutes.DELETE("/:level", userPriceHandler.DeleteTierPrice)
+	}
+}
+
+func configureDownlineRoutes(group *gin.RouterGroup, downlineHandler *DownlineHandler, authService domain.AuthService) {
+	routes := group.Group("/downlines")
+	routes.Use(authMiddleware(authService))
+	{
+		routes.GET("/tree", downlineHandler.GetTree)
+		routes.GET("/stats", downlineHandler.GetStats)
+	}
+
+	adminRoutes := group.Group("/admin/users/:id/downlines")
+	adminRoutes.Use(authMiddleware(authService), adminMiddleware())
+	{
+		adminRoutes.GET("/tree", downlineHandler.GetTree)
+		adminRoutes.GET("/stats", downlineHandler.GetStats)
+	}
+}
+
+func configureReferralRoutes(group *gin.RouterGroup, referralHandler *ReferralHandler, authService domain.AuthService) {
+	group.POST("/auth/register/referral", referralHandler.Register)
+
+	codeRoutes := group.Group("/referral")
+	codeRoutes.Use(authMiddleware(authService))
+	{
+		codeRoutes.GET("/code", referralHandler.GetCode)
+		codeRoutes.POST("/code/rotate", referralHandler.RotateCode)
+	}
+
+	registrationRoutes := group.Group("/downlines/registrations")
+	registrationRoutes.Use(authMiddleware(authService))
+	{
+		registrationRoutes.GET("", referralHandler.ListRegistrations)
+		registrationRoutes.POST("/:id/approve", referralHandler.ApproveRegistration)
+		registrationRoutes.POST("/:id/reject", referralHandler.RejectRegistration)
+	}
+}
+
+func configureAdminMappingReviewRoutes(group *gin.RouterGroup, mappingReviewHandler *MappingReviewHandler, authService domain.AuthService) {
+	adminRoutes := group.Group("/admin")
+	adminRoutes.Use(authMiddleware(authService), adminMiddleware())
+	{
+		reviews := adminRoutes.Group("/mapping-reviews")
+		{
+			reviews.GET("", mappingReviewHandler.ListReviews)
+			reviews.POST("/:id/accept", mappingReviewHandler.AcceptReview)
+			reviews.POST("/:id/dismiss", mappingReviewHandler.DismissReview)
+		}
+
+		adminRoutes.POST("/suppliers/:id/mapping-validation", mappingReviewHandler.ValidateSupplierMappings)
+	}
+}
+
+func configureAdminReconciliationRoutes(group *gin.RouterGroup, reconciliationHandler *ReconciliationHandler, authService domain.AuthService) {
+	adminRoutes := group.Group("/admin/reconciliation")
+	adminRoutes.Use(authMiddleware(authService), adminMiddleware())
+	{
+		mismatches := adminRoutes.Group("/balance-mismatches")
+		{
+			mismatches.GET("", reconciliationHandler.ListMismatches)
+			mismatches.POST("/:id/resolve", reconciliationHandler.ResolveMismatch)
+		}
+	}
+}
+
+func configureAdminSecurityRoutes(group *gin.RouterGroup, securityHandler *SecurityHandler, authService domain.AuthService) {
+	adminRoutes := group.Group("/admin/security-events")
+	adminRoutes.Use(authMiddleware(authService), adminMiddleware())
+	{
+		adminRoutes.GET("", securityHandler.ListAccessDenials)
+	}
+}
+
+func configureAdminReportRoutes(group *gin.RouterGroup, reportHandler *ReportHandler, authService domain.AuthService) {
+	reports := group.Group("/admin/reports")
+	reports.Use(authMiddleware(authService), adminMiddleware())
+	{
+		reports.GET("/suppliers", reportHandler.GetSupplierReport)
+		reports.GET("/tags", reportHandler.GetTagReport)
+		reports.GET("/refunds", reportHandler.GetRefundReport)
+	}
+}
+
+func configureAdminSchedulerRoutes(group *gin.RouterGroup, schedulerHandler *SchedulerHandler, authService domain.AuthService) {
+	jobs := group.Group("/admin/jobs")
+	jobs.Use(authMiddleware(authService), adminMiddleware())
+	{
+		jobs.GET("", schedulerHandler.ListJobs)
+	}
+
+	system := group.Group("/admin/system")
+	system.Use(authMiddleware(authService), adminMiddleware())
+	{
+		system.GET("/workers", schedulerHandler.GetWorkersStatus)
 	}
 }
 
-func configureH2HRoutes(group *gin.RouterGroup, clientRepo *postgres.APIClientRepository) {
-	h2hMiddleware := NewH2HMiddleware(clientRepo)
+func configureAdminSupplierRoutes(group *gin.RouterGroup, supplierHandler *SupplierHandler, supplierSLAHandler *SupplierSLAHandler, authService domain.AuthService) {
+	suppliers := group.Group("/admin/suppliers")
+	suppliers.Use(authMiddleware(authService), adminMiddleware())
+	{
+		suppliers.GET("/sla", supplierSLAHandler.GetSLAReport)
+		suppliers.POST("", supplierHandler.CreateSupplier)
+		suppliers.GET("", supplierHandler.ListSuppliers)
+		suppliers.GET("/:id", supplierHandler.GetSupplier)
+		suppliers.PATCH("/:id", supplierHandler.UpdateSupplier)
+		suppliers.POST("/:id/request-rules/preview", supplierHandler.PreviewRequestRules)
+		suppliers.POST("/:id/mappings/copy", supplierHandler.CopyMappings)
+	}
+}
+
+// configureAdminSupplierInvoiceRoutes registers supplier recap uploads and
+// their match reports
+func configureAdminSupplierInvoiceRoutes(group *gin.RouterGroup, supplierInvoiceHandler *SupplierInvoiceHandler, authService domain.AuthService) {
+	suppliers := group.Group("/admin/suppliers")
+	suppliers.Use(authMiddleware(authService), adminMiddleware())
+	{
+		suppliers.POST("/:id/invoices", supplierInvoiceHandler.Upload)
+	}
+
+	invoices := group.Group("/admin/supplier-invoices")
+	invoices.Use(authMiddleware(authService), adminMiddleware())
+	{
+		invoices.GET("", supplierInvoiceHandler.ListInvoices)
+		invoices.GET("/:id", supplierInvoiceHandler.GetInvoice)
+		invoices.GET("/:id/lines", supplierInvoiceHandler.ListLines)
+	}
+}
+
+func configureAdminDestinationRuleRoutes(group *gin.RouterGroup, destinationRuleHandler *DestinationRuleHandler, authService domain.AuthService) {
+	rules := group.Group("/admin/destination-rules")
+	rules.Use(authMiddleware(authService), adminMiddleware())
+	{
+		rules.GET("", destinationRuleHandler.ListRules)
+		rules.PUT("/:category", destinationRuleHandler.UpdateRule)
+	}
+}
+
+// configureAdminChaosRoutes registers fault injection toggles; chaosHandler
+// is nil unless chaos is enabled
+func configureAdminChaosRoutes(group *gin.RouterGroup, chaosHandler *ChaosHandler, authService domain.AuthService) {
+	if chaosHandler == nil {
+		return
+	}
+
+	faults := group.Group("/admin/chaos/faults")
+	faults.Use(authMiddleware(authService), adminMiddleware())
+	{
+		faults.GET("", chaosHandler.ListFaults)
+		faults.PUT("/:target", chaosHandler.SetFault)
+		faults.DELETE("/:target", chaosHandler.ClearFault)
+	}
+}
+
+// configureAdminLoggingRoutes registers runtime log level and sampling
+// controls; changes apply only to the replica serving the request
+func configureAdminLoggingRoutes(group *gin.RouterGroup, loggingHandler *LoggingHandler, authService domain.AuthService) {
+	logging := group.Group("/admin/logging")
+	logging.Use(authMiddleware(authService), adminMiddleware())
+	{
+		logging.GET("", loggingHandler.GetLevels)
+		logging.PUT("/level", loggingHandler.SetLevel)
+		logging.PUT("/components/:component", loggingHandler.SetComponent)
+		logging.DELETE("/components/:component", loggingHandler.ResetComponent)
+	}
+}
+
+func configureAdminCatalogRoutes(group *gin.RouterGroup, catalogHandler *CatalogHandler, authService domain.AuthService) {
+	adminRoutes := group.Group("/admin")
+	adminRoutes.Use(authMiddleware(authService), adminMiddleware())
+	{
+		categories := adminRoutes.Group("/product-categories")
+		{
+			categories.POST("", catalogHandler.CreateCategory)
+			categories.GET("", catalogHandler.ListCategories)
+			categories.GET("/:code", catalogHandler.GetCategory)
+			categories.PATCH("/:code", catalogHandler.UpdateCategory)
+		}
+
+		providers := adminRoutes.Group("/product-providers")
+		{
+			providers.POST("", catalogHandler.CreateProvider)
+			providers.GET("", catalogHandler.ListProviders)
+			providers.GET("/:code", catalogHandler.GetProvider)
+			providers.PATCH("/:code", catalogHandler.UpdateProvider)
+		}
+	}
+}
+
+func configureAdminCutoffRoutes(group *gin.RouterGroup, cutoffScheduleHandler *CutoffScheduleHandler, authService domain.AuthService) {
+	schedules := group.Group("/admin/cutoff-schedules")
+	schedules.Use(authMiddleware(authService), adminMiddleware())
+	{
+		schedules.POST("", cutoffScheduleHandler.CreateSchedule)
+		schedules.GET("", cutoffScheduleHandler.ListSchedules)
+		schedules.GET("/:id", cutoffScheduleHandler.GetSchedule)
+		schedules.PATCH("/:id", cutoffScheduleHandler.UpdateSchedule)
+		schedules.DELETE("/:id", cutoffScheduleHandler.DeleteSchedule)
+	}
+}
+
+func configureAdminStatusRoutes(group *gin.RouterGroup, statusPageHandler *StatusPageHandler, authService domain.AuthService) {
+	incidents := group.Group("/admin/status/incidents")
+	incidents.Use(authMiddleware(authService), adminMiddleware())
+	{
+		incidents.POST("", statusPageHandler.CreateIncident)
+		incidents.GET("", statusPageHandler.ListIncidents)
+		incidents.PATCH("/:id", statusPageHandler.UpdateIncident)
+	}
+}
+
+func configureAdminRetryPolicyRoutes(group *gin.RouterGroup, retryPolicyHandler *RetryPolicyHandler, authService domain.AuthService) {
+	policies := group.Group("/admin/retry-policies")
+	policies.Use(authMiddleware(authService), adminMiddleware())
+	{
+		policies.POST("", retryPolicyHandler.CreatePolicy)
+		policies.GET("", retryPolicyHandler.ListPolicies)
+		policies.GET("/resolve", retryPolicyHandler.ResolvePolicy)
+		policies.GET("/:id", retryPolicyHandler.GetPolicy)
+		policies.PATCH("/:id", retryPolicyHandler.UpdatePolicy)
+		policies.DELETE("/:id", retryPolicyHandler.DeletePolicy)
+	}
+}
+
+func configureAdminFeatureFlagRoutes(group *gin.RouterGroup, featureFlagHandler *FeatureFlagHandler, authService domain.AuthService) {
+	flags := group.Group("/admin/feature-flags")
+	flags.Use(authMiddleware(authService), adminMiddleware())
+	{
+		flags.GET("", featureFlagHandler.ListFlags)
+		flags.PUT("/:key", featureFlagHandler.SetFlag)
+		flags.DELETE("/:key", featureFlagHandler.DeleteFlag)
+		flags.GET("/:key/evaluate", featureFlagHandler.EvaluateFlag)
+	}
+}
+
+func configureAdminSigningRoutes(group *gin.RouterGroup, adminSigningHandler *AdminSigningHandler, authService domain.AuthService) {
+	keys := group.Group("/admin/signing-keys")
+	keys.Use(authMiddleware(authService), adminMiddleware())
+	{
+		keys.POST("", adminSigningHandler.CreateKey)
+		keys.GET("", adminSigningHandler.ListKeys)
+		keys.DELETE("/:id", adminSigningHandler.RevokeKey)
+	}
+}
+
+func configureAdminImpersonationRoutes(group *gin.RouterGroup, impersonationHandler *ImpersonationHandler, authService domain.AuthService) {
+	impersonation := group.Group("/admin/impersonation")
+	impersonation.Use(authMiddleware(authService), adminMiddleware())
+	{
+		impersonation.POST("", impersonationHandler.StartSession)
+		impersonation.GET("/sessions", impersonationHandler.ListSessions)
+		impersonation.GET("/sessions/:id", impersonationHandler.GetSession)
+		impersonation.GET("/sessions/:id/actions", impersonationHandler.ListActions)
+		impersonation.POST("/sessions/:id/end", impersonationHandler.EndSession)
+	}
+}
+
+func configureAdminAPIClientRoutes(group *gin.RouterGroup, apiClientHandler *APIClientHandler, authService domain.AuthService) {
+	clients := group.Group("/admin/api-clients")
+	clients.Use(authMiddleware(authService), adminMiddleware())
+	{
+		clients.POST("", apiClientHandler.CreateAPIClient)
+		clients.GET("/:client_id", apiClientHandler.GetAPIClient)
+		clients.PUT("/:client_id/scopes", apiClientHandler.UpdateScopes)
+		clients.PUT("/:client_id/response-fields", apiClientHandler.UpdateResponseFields)
+		clients.PUT("/:client_id/ip-whitelist", apiClientHandler.UpdateIPWhitelist)
+		clients.GET("/:client_id/ip-whitelist/changes", apiClientHandler.ListIPChanges)
+	}
+}
+
+func configureNotificationRoutes(group *gin.RouterGroup, notificationHandler *NotificationHandler, authService domain.AuthService) {
+	preferences := group.Group("/notifications/preferences")
+	preferences.Use(authMiddleware(authService))
+	{
+		preferences.GET("", notificationHandler.GetPreferences)
+		preferences.PUT("", notificationHandler.UpdatePreference)
+	}
+
+	templates := group.Group("/admin/message-templates")
+	templates.Use(authMiddleware(authService), adminMiddleware())
+	{
+		templates.GET("", notificationHandler.ListTemplates)
+		templates.GET("/:id", notificationHandler.GetTemplate)
+		templates.PUT("/:id", notificationHandler.UpdateTemplate)
+		templates.POST("/:id/preview", notificationHandler.PreviewTemplate)
+	}
+}
+
+func configureH2HRoutes(group *gin.RouterGroup, transactionHandler *TransactionHandler, h2hPortalHandler *H2HPortalHandler, clientRepo *postgres.APIClientRepository, nonceRepo domain.NonceRepository, quotaUC domain.QuotaUsecase, backpressureUC domain.BackpressureUsecase) {
+	h2hMiddleware := NewH2HMiddleware(clientRepo, nonceRepo)
+	quotaMiddleware := NewH2HQuotaMiddleware(quotaUC)
 	h2hRoutes := group.Group("/h2h")
-	h2hRoutes.Use(h2hMiddleware.H2HAuth())
+	h2hRoutes.Use(h2hMiddleware.H2HAuth(), quotaMiddleware.RateLimit())
 	{
 		// H2H callback endpoint for supplier notifications
 		h2hRoutes.POST("/callback", func(c *gin.Context) {
@@ -236,15 +671,41 @@ func configureH2HRoutes(group *gin.RouterGroup, clientRepo *postgres.APIClientRe
 		// TODO: Add H2H inquiry endpoint when ready
 		// h2hRoutes.POST("/inquiry", transactionHandler.H2HInquiry)
 
-		// TODO: Add H2H payment endpoint when ready
-		// h2hRoutes.POST("/payment", transactionHandler.H2HPayment)
+		// Shed orders are rejected before they reserve a daily quota slot
+		h2hRoutes.POST("/payment", h2hMiddleware.RequireScope(domain.H2HScopeTransact), intakeMiddleware(backpressureUC), quotaMiddleware.TransactionQuota(), h2hRequestBudget(), transactionHandler.H2HPayment)
 
 		// TODO: Add H2H status check endpoint when ready
 		// h2hRoutes.POST("/status", transactionHandler.H2HStatus)
+
+		// Client self-service
+		me := h2hRoutes.Group("/me")
+		{
+			// The profile and secret rotation are available to every key so
+			// a client can always see its scopes and manage its credentials
+			me.GET("", h2hPortalHandler.GetProfile)
+
+			history := me.Group("", h2hMiddleware.RequireScope(domain.H2HScopeRead))
+			history.GET("/usage", h2hPortalHandler.GetUsage)
+			history.GET("/quota", h2hPortalHandler.GetQuota)
+			history.GET("/transactions", h2hPortalHandler.ListTransactions)
+			history.GET("/deliveries", h2hPortalHandler.ListDeliveries)
+			me.POST("/secret/rotate", h2hPortalHandler.RotateSecret)
+			me.PUT("/ip-whitelist", h2hPortalHandler.UpdateIPWhitelist)
+			me.GET("/ip-whitelist/changes", h2hPortalHandler.ListIPChanges)
+		}
+	}
+}
+
+// configureSupplierWebhookRoutes registers the inbound supplier webhooks. They
+// carry no user credentials; each request is authenticated by its signature.
+func configureSupplierWebhookRoutes(group *gin.RouterGroup, supplierWebhookHandler *SupplierWebhookHandler) {
+	webhooks := group.Group("/webhooks/suppliers")
+	{
+		webhooks.POST("/:code", supplierWebhookHandler.HandleWebhook)
 	}
 }
 
-func configurePublicRoutes(group *gin.RouterGroup) {
+func configurePublicRoutes(group *gin.RouterGroup, priceListHandler *PriceListHandler, catalogHandler *CatalogHandler, statusPageHandler *StatusPageHandler) {
 	public := group.Group("/public")
 	{
 		public.GET("/ping", func(c *gin.Context) {
@@ -256,6 +717,10 @@ func configurePublicRoutes(group *gin.RouterGroup) {
 				"status":  "ok",
 			})
 		})
+		public.GET("/pricelist", compressionMiddleware(), priceListHandler.GetPriceList)
+		public.GET("/categories", catalogHandler.ListPublicCategories)
+		public.GET("/providers", catalogHandler.ListPublicProviders)
+		public.GET("/status", statusPageHandler.GetPublicStatus)
 	}
 }
 
@@ -287,6 +752,8 @@ func authMiddleware(authService domain.AuthService) gin.HandlerFunc {
 			switch {
 			case errors.Is(err, authpkg.ErrExpiredToken):
 				xresponse.Unauthorized(c, "Token expired")
+			case errors.Is(err, authpkg.ErrRevokedToken):
+				xresponse.Unauthorized(c, "Token revoked")
 			case errors.Is(err, authpkg.ErrInvalidToken):
 				xresponse.Unauthorized(c, "Invalid token")
 			case errors.Is(err, authpkg.ErrSignatureInvalid):
@@ -314,10 +781,29 @@ func authMiddleware(authService domain.AuthService) gin.HandlerFunc {
 		c.Set("user_level", level)
 		c.Set("token_issued_at", claims.IssuedAt)
 		c.Set("token_expires_at", claims.ExpiresAt)
+		actor := domain.Actor{
+			Type:      domain.ActorTypeUser,
+			UserID:    userID,
+			IPAddress: c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}
+		if role == domain.RoleAdmin {
+			actor.Type = domain.ActorTypeAdmin
+		}
+		ctx := logger.WithContext(c.Request.Context(), logger.String("user_id", userID))
+		c.Request = c.Request.WithContext(domain.WithActor(ctx, actor))
+
+		// Impersonation tokens are read-only and every request is audited
+		if claims.IsImpersonation() {
+			defer recordImpersonationAction(c, claims.ImpersonationSessionID)
+			if !beginImpersonation(c, claims) {
+				return
+			}
+		}
 
 		// Log successful authentication with TTL info
 		ttl := time.Until(claims.ExpiresAt)
-		logger.Debug("User authenticated via middleware",
+		logger.Component(logger.ComponentAuth).Debug("User authenticated via middleware",
 			logger.String("user_id", userID),
 			logger.String("role", role),
 			logger.String("level", fmt.Sprintf("%d", level)),
@@ -334,6 +820,7 @@ func adminMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		roleVal, exists := c.Get("user_role")
 		if !exists {
+			recordAccessDenial(c, domain.DenialUnauthenticated, domain.RoleAdmin)
 			xresponse.Unauthorized(c, "User not authenticated")
 			c.Abort()
 			return
@@ -341,17 +828,18 @@ func adminMiddleware() gin.HandlerFunc {
 
 		role, _ := roleVal.(string)
 		if strings.ToUpper(role) != domain.RoleAdmin {
-			logger.Warn("Admin access denied",
+			logger.Component(logger.ComponentAuth).Warn("Admin access denied",
 				logger.String("user_role", role),
 				logger.String("required_role", domain.RoleAdmin),
 				logger.String("ip", c.ClientIP()),
 			)
+			recordAccessDenial(c, domain.DenialInsufficientRole, domain.RoleAdmin)
 			xresponse.Forbidden(c, "Admin access required")
 			c.Abort()
 			return
 		}
 
-		logger.Debug("Admin access granted",
+		logger.Component(logger.ComponentAuth).Debug("Admin access granted",
 			logger.String("user_id", c.GetString("user_id")),
 			logger.String("ip", c.ClientIP()),
 		)
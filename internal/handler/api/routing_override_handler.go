@@ -0,0 +1,136 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// RoutingOverrideHandler handles admin supplier pin/exclude endpoints
+type RoutingOverrideHandler struct {
+	overrideUC domain.RoutingOverrideUsecase
+	roleGuard  *RoleGuard
+}
+
+// NewRoutingOverrideHandler creates a new routing override handler
+func NewRoutingOverrideHandler(overrideUC domain.RoutingOverrideUsecase) *RoutingOverrideHandler {
+	return &RoutingOverrideHandler{
+		overrideUC: overrideUC,
+		roleGuard:  NewRoleGuard(),
+	}
+}
+
+// CreateRoutingOverrideRequest payload
+type CreateRoutingOverrideRequest struct {
+	ScopeType  string  `json:"scope_type" binding:"required"`
+	ScopeValue string  `json:"scope_value" binding:"required"`
+	SupplierID string  `json:"supplier_id" binding:"required"`
+	Action     string  `json:"action" binding:"required"`
+	Reason     *string `json:"reason"`
+}
+
+// UpdateRoutingOverrideRequest payload
+type UpdateRoutingOverrideRequest struct {
+	IsActive *bool   `json:"is_active"`
+	Reason   *string `json:"reason"`
+}
+
+// CreateOverride creates a new pin/exclude rule
+func (h *RoutingOverrideHandler) CreateOverride(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "create_routing_override", "admin")
+
+	var req CreateRoutingOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	override := &domain.RoutingOverride{
+		ScopeType:  req.ScopeType,
+		ScopeValue: req.ScopeValue,
+		SupplierID: req.SupplierID,
+		Action:     req.Action,
+		Reason:     req.Reason,
+	}
+	if userID, _, _, exists := h.roleGuard.GetCurrentUser(c); exists && userID != "" {
+		override.CreatedBy = &userID
+	}
+
+	if err := h.overrideUC.CreateOverride(override); err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			xresponse.Conflict(c, "Override for this supplier and scope already exists")
+			return
+		}
+		logger.Error("Failed to create routing override", logger.ErrorField(err))
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Created(c, "Routing override created", override)
+}
+
+// ListOverrides lists overrides filtered by scope_type and scope_value
+func (h *RoutingOverrideHandler) ListOverrides(c *gin.Context) {
+	overrides, err := h.overrideUC.ListOverrides(c.Query("scope_type"), c.Query("scope_value"))
+	if err != nil {
+		logger.Error("Failed to list routing overrides", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to list routing overrides")
+		return
+	}
+
+	xresponse.Success(c, "Routing overrides fetched", overrides)
+}
+
+// GetOverride returns an override by ID
+func (h *RoutingOverrideHandler) GetOverride(c *gin.Context) {
+	override, err := h.overrideUC.GetOverride(c.Param("id"))
+	if err != nil {
+		xresponse.NotFound(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Routing override fetched", override)
+}
+
+// UpdateOverride toggles an override or updates its reason
+func (h *RoutingOverrideHandler) UpdateOverride(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "update_routing_override", "admin")
+
+	var req UpdateRoutingOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	override, err := h.overrideUC.UpdateOverride(c.Param("id"), req.IsActive, req.Reason)
+	if err != nil {
+		if err.Error() == "routing override not found" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Routing override updated", override)
+}
+
+// DeleteOverride removes an override
+func (h *RoutingOverrideHandler) DeleteOverride(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "delete_routing_override", "admin")
+
+	id := c.Param("id")
+	if err := h.overrideUC.DeleteOverride(id); err != nil {
+		if err.Error() == "routing override not found" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	xresponse.Success(c, "Routing override deleted", gin.H{"override_id": id})
+}
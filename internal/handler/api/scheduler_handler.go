@@ -0,0 +1,53 @@
+package api
+
+import (
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// SchedulerHandler handles background job status endpoints
+type SchedulerHandler struct {
+	scheduler domain.JobScheduler
+	statusUC  domain.SystemStatusUsecase
+	roleGuard *RoleGuard
+}
+
+// NewSchedulerHandler creates a new scheduler handler
+func NewSchedulerHandler(scheduler domain.JobScheduler, statusUC domain.SystemStatusUsecase) *SchedulerHandler {
+	return &SchedulerHandler{
+		scheduler: scheduler,
+		statusUC:  statusUC,
+		roleGuard: NewRoleGuard(),
+	}
+}
+
+// ListJobs lists registered jobs with their next activation and last run
+func (h *SchedulerHandler) ListJobs(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "list_scheduled_jobs", "admin")
+
+	jobs, err := h.scheduler.ListJobs()
+	if err != nil {
+		logger.Error("Failed to list scheduled jobs", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to list scheduled jobs")
+		return
+	}
+
+	xresponse.Success(c, "Scheduled jobs fetched", jobs)
+}
+
+// GetWorkersStatus reports the live workers of the serving replica with the
+// shared queue depths and dead letter queue size
+func (h *SchedulerHandler) GetWorkersStatus(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "get_workers_status", "admin")
+
+	status, err := h.statusUC.GetWorkersStatus()
+	if err != nil {
+		logger.Error("Failed to get workers status", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to get workers status")
+		return
+	}
+
+	xresponse.Success(c, "Workers status fetched", status)
+}
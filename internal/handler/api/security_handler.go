@@ -0,0 +1,67 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// SecurityHandler handles security audit endpoints
+type SecurityHandler struct {
+	securityEventUC domain.SecurityEventUsecase
+	roleGuard       *RoleGuard
+}
+
+// NewSecurityHandler creates a new security handler
+func NewSecurityHandler(securityEventUC domain.SecurityEventUsecase) *SecurityHandler {
+	return &SecurityHandler{
+		securityEventUC: securityEventUC,
+		roleGuard:       NewRoleGuard(),
+	}
+}
+
+// ListAccessDenials lists recent role-guard denials. Supports user_id, ip,
+// since (RFC3339) and limit query parameters.
+func (h *SecurityHandler) ListAccessDenials(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "list_access_denials", "admin")
+
+	eventType := domain.SecurityEventAccessDenied
+	filter := &domain.SecurityEventFilter{EventType: &eventType}
+
+	if userID := strings.TrimSpace(c.Query("user_id")); userID != "" {
+		filter.UserID = &userID
+	}
+	if ip := strings.TrimSpace(c.Query("ip")); ip != "" {
+		filter.IPAddress = &ip
+	}
+	if sinceStr := c.Query("since"); sinceStr != "" {
+		since, err := time.Parse(time.RFC3339, sinceStr)
+		if err != nil {
+			xresponse.BadRequest(c, "since must be an RFC3339 timestamp")
+			return
+		}
+		filter.Since = &since
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			xresponse.BadRequest(c, "limit must be a positive number")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	events, err := h.securityEventUC.ListEvents(filter)
+	if err != nil {
+		logger.Error("Failed to list access denials", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to list access denials")
+		return
+	}
+
+	xresponse.Success(c, "Access denials fetched", events)
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// StatementHandler handles monthly account statement endpoints
+type StatementHandler struct {
+	statementUC domain.StatementUsecase
+	roleGuard   *RoleGuard
+}
+
+// NewStatementHandler creates a new statement handler
+func NewStatementHandler(statementUC domain.StatementUsecase) *StatementHandler {
+	return &StatementHandler{
+		statementUC: statementUC,
+		roleGuard:   NewRoleGuard(),
+	}
+}
+
+// StatementJobResponse describes a queued statement with its links
+type StatementJobResponse struct {
+	*domain.StatementJob
+	StatusURL   string `json:"status_url"`
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+// GetStatement returns the statement of the current user for a month.
+// Query: format (pdf|csv). Small statements are returned as a file; large
+// ones are generated in the background and answered with 202 and a job.
+func (h *StatementHandler) GetStatement(c *gin.Context) {
+	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "Authentication required")
+		return
+	}
+
+	h.roleGuard.LogAccess(c, "get_statement", "own_statement")
+
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		xresponse.BadRequest(c, "Invalid year")
+		return
+	}
+	month, err := strconv.Atoi(c.Param("month"))
+	if err != nil {
+		xresponse.BadRequest(c, "Invalid month")
+		return
+	}
+
+	result, err := h.statementUC.RequestStatement(userID, year, month, c.DefaultQuery("format", "pdf"))
+	if err != nil {
+		switch err.Error() {
+		case "invalid statement format", "invalid statement period", "statement period is in the future":
+			xresponse.BadRequest(c, err.Error())
+		default:
+			logger.Error("Failed to generate statement", logger.String("user_id", userID), logger.ErrorField(err))
+			xresponse.InternalServerError(c, "Failed to generate statement")
+		}
+		return
+	}
+
+	if result.File != nil {
+		writeStatementFile(c, result.File)
+		return
+	}
+
+	response := newStatementJobResponse(result.Job)
+	if result.Job.Status == domain.StatementJobReady {
+		xresponse.Success(c, "Statement is ready", response)
+		return
+	}
+	xresponse.SuccessWithCode(c, http.StatusAccepted, "Statement is being generated", response)
+}
+
+// GetStatementJob returns the status of a statement job of the current user
+func (h *StatementHandler) GetStatementJob(c *gin.Context) {
+	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "Authentication required")
+		return
+	}
+
+	job, err := h.statementUC.GetJob(userID, c.Param("id"))
+	if err != nil {
+		if err.Error() == "statement job not found" {
+			xresponse.NotFound(c, err.Error())
+			return
+		}
+		logger.Error("Failed to get statement job", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to get statement job")
+		return
+	}
+
+	xresponse.Success(c, "Statement job fetched", newStatementJobResponse(job))
+}
+
+// DownloadStatementJob downloads the file of a ready statement job
+func (h *StatementHandler) DownloadStatementJob(c *gin.Context) {
+	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "Authentication required")
+		return
+	}
+
+	h.roleGuard.LogAccess(c, "download_statement", "own_statement")
+
+	file, err := h.statementUC.DownloadJob(userID, c.Param("id"))
+	if err != nil {
+		switch err.Error() {
+		case "statement job not found":
+			xresponse.NotFound(c, err.Error())
+		case "statement is not ready":
+			xresponse.Conflict(c, err.Error())
+		default:
+			logger.Error("Failed to download statement", logger.ErrorField(err))
+			xresponse.InternalServerError(c, "Failed to download statement")
+		}
+		return
+	}
+
+	writeStatementFile(c, file)
+}
+
+func newStatementJobResponse(job *domain.StatementJob) *StatementJobResponse {
+	base := "/api/v1/statements/jobs/" + job.ID
+	response := &StatementJobResponse{
+		StatementJob: job,
+		StatusURL:    base,
+	}
+	if job.Status == domain.StatementJobReady {
+		response.DownloadURL = base + "/download"
+	}
+	return response
+}
+
+func writeStatementFile(c *gin.Context, file *domain.StatementFile) {
+	c.Header("Content-Disposition", "attachment; filename="+file.FileName)
+	c.Data(http.StatusOK, file.ContentType, file.Content)
+}
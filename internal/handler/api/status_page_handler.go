@@ -0,0 +1,146 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// StatusPageHandler serves the public status page data and the admin
+// incident endpoints
+type StatusPageHandler struct {
+	statusUC  domain.StatusPageUsecase
+	roleGuard *RoleGuard
+}
+
+// NewStatusPageHandler creates a new status page handler
+func NewStatusPageHandler(statusUC domain.StatusPageUsecase) *StatusPageHandler {
+	return &StatusPageHandler{
+		statusUC:  statusUC,
+		roleGuard: NewRoleGuard(),
+	}
+}
+
+// CreateStatusIncidentRequest payload. Empty categories affect every category.
+type CreateStatusIncidentRequest struct {
+	Title      string     `json:"title" binding:"required"`
+	Message    string     `json:"message" binding:"required"`
+	Impact     string     `json:"impact" binding:"required"`
+	Categories []string   `json:"categories"`
+	StartedAt  *time.Time `json:"started_at"`
+}
+
+// UpdateStatusIncidentRequest payload; omitted fields keep their value.
+// resolved true resolves the incident, false reopens it.
+type UpdateStatusIncidentRequest struct {
+	Title      *string   `json:"title"`
+	Message    *string   `json:"message"`
+	Impact     *string   `json:"impact"`
+	Categories *[]string `json:"categories"`
+	Resolved   *bool     `json:"resolved"`
+}
+
+// GetPublicStatus returns the operational status per product category and
+// the recent incidents
+func (h *StatusPageHandler) GetPublicStatus(c *gin.Context) {
+	status, err := h.statusUC.GetPublicStatus()
+	if err != nil {
+		logger.Error("Failed to get public status", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to get status")
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=30")
+	xresponse.Success(c, "Status fetched", status)
+}
+
+// CreateIncident posts a new incident on the status page
+func (h *StatusPageHandler) CreateIncident(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "create_status_incident", "admin")
+
+	var req CreateStatusIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	incident := &domain.StatusIncident{
+		Title:      req.Title,
+		Message:    req.Message,
+		Impact:     strings.ToLower(req.Impact),
+		Categories: req.Categories,
+	}
+	if req.StartedAt != nil {
+		incident.StartedAt = *req.StartedAt
+	}
+	if userID, _, _, exists := h.roleGuard.GetCurrentUser(c); exists && userID != "" {
+		incident.CreatedBy = &userID
+	}
+
+	if err := h.statusUC.CreateIncident(incident); err != nil {
+		respondStatusIncidentError(c, err, "Failed to create status incident")
+		return
+	}
+
+	xresponse.Created(c, "Status incident created", incident)
+}
+
+// UpdateIncident changes, resolves or reopens an incident
+func (h *StatusPageHandler) UpdateIncident(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "update_status_incident", c.Param("id"))
+
+	var req UpdateStatusIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+	if req.Impact != nil {
+		impact := strings.ToLower(*req.Impact)
+		req.Impact = &impact
+	}
+
+	incident, err := h.statusUC.UpdateIncident(c.Param("id"), &domain.StatusIncidentUpdate{
+		Title:      req.Title,
+		Message:    req.Message,
+		Impact:     req.Impact,
+		Categories: req.Categories,
+		Resolved:   req.Resolved,
+	})
+	if err != nil {
+		respondStatusIncidentError(c, err, "Failed to update status incident")
+		return
+	}
+
+	xresponse.Success(c, "Status incident updated", incident)
+}
+
+// ListIncidents lists every incident, newest first. Query: limit (max 100).
+func (h *StatusPageHandler) ListIncidents(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	incidents, err := h.statusUC.ListIncidents(limit)
+	if err != nil {
+		respondStatusIncidentError(c, err, "Failed to list status incidents")
+		return
+	}
+
+	xresponse.Success(c, "Status incidents fetched", incidents)
+}
+
+// respondStatusIncidentError maps status incident errors to responses
+func respondStatusIncidentError(c *gin.Context, err error, failure string) {
+	message := err.Error()
+	switch {
+	case message == "status incident not found":
+		xresponse.NotFound(c, message)
+	case strings.HasPrefix(message, "failed to"):
+		logger.Error(failure, logger.ErrorField(err))
+		xresponse.InternalServerError(c, failure)
+	default:
+		xresponse.BadRequest(c, message)
+	}
+}
@@ -0,0 +1,270 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// maskedCredential replaces stored supplier secrets in responses
+const maskedCredential = "********"
+
+// SupplierHandler handles admin management of supplier accounts
+type SupplierHandler struct {
+	registryUC domain.SupplierRegistryUsecase
+	roleGuard  *RoleGuard
+}
+
+// NewSupplierHandler creates a new supplier handler
+func NewSupplierHandler(registryUC domain.SupplierRegistryUsecase) *SupplierHandler {
+	return &SupplierHandler{
+		registryUC: registryUC,
+		roleGuard:  NewRoleGuard(),
+	}
+}
+
+// CreateSupplierRequest payload. adapter_type picks one of the adapters this
+// build supports and defaults to the code, so a second Digiflazz account is
+// e.g. code DIGIFLAZZ2 with adapter_type DIGIFLAZZ.
+type CreateSupplierRequest struct {
+	Code                string   `json:"code" binding:"required"`
+	Name                string   `json:"name" binding:"required"`
+	APIURL              string   `json:"api_url" binding:"required"`
+	APIKey              *string  `json:"api_key"`
+	APISecret           *string  `json:"api_secret"`
+	APIUsername         *string  `json:"api_username"`
+	APIPassword         *string  `json:"api_password"`
+	AdapterType         *string  `json:"adapter_type"`
+	SignMethod          *string  `json:"sign_method"`
+	WebhookSecret       *string  `json:"webhook_secret"`
+	IsActive            *bool    `json:"is_active"`
+	Priority            int      `json:"priority"`
+	TimeoutSeconds      int      `json:"timeout_seconds"`
+	RetryAttempts       *int     `json:"retry_attempts"`
+	MinBalanceThreshold *float64 `json:"min_balance_threshold"`
+
+	RequestRules *domain.SupplierRequestRules `json:"request_rules"`
+}
+
+// UpdateSupplierRequest payload; omitted fields keep their value and an empty
+// credential, sign method or adapter type clears it. The code cannot change.
+type UpdateSupplierRequest struct {
+	Name                *string  `json:"name"`
+	APIURL              *string  `json:"api_url"`
+	APIKey              *string  `json:"api_key"`
+	APISecret           *string  `json:"api_secret"`
+	APIUsername         *string  `json:"api_username"`
+	APIPassword         *string  `json:"api_password"`
+	AdapterType         *string  `json:"adapter_type"`
+	SignMethod          *string  `json:"sign_method"`
+	WebhookSecret       *string  `json:"webhook_secret"`
+	IsActive            *bool    `json:"is_active"`
+	Priority            *int     `json:"priority"`
+	TimeoutSeconds      *int     `json:"timeout_seconds"`
+	RetryAttempts       *int     `json:"retry_attempts"`
+	MinBalanceThreshold *float64 `json:"min_balance_threshold"`
+
+	// RequestRules replaces every rule and example at once
+	RequestRules *domain.SupplierRequestRules `json:"request_rules"`
+}
+
+// PreviewRequestRulesRequest payload; without request_rules the stored rules
+// of the supplier are used
+type PreviewRequestRulesRequest struct {
+	ProductCode  string                       `json:"product_code" binding:"required"`
+	Destination  string                       `json:"destination" binding:"required"`
+	RequestRules *domain.SupplierRequestRules `json:"request_rules"`
+}
+
+// CopyMappingsRequest payload
+type CopyMappingsRequest struct {
+	SourceSupplierID string `json:"source_supplier_id" binding:"required"`
+}
+
+// CreateSupplier creates a supplier account; an active one serves
+// transactions as soon as it is created
+func (h *SupplierHandler) CreateSupplier(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "create_supplier", "admin")
+
+	var req CreateSupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	supplier := &domain.Supplier{
+		Code:           req.Code,
+		Name:           req.Name,
+		APIURL:         req.APIURL,
+		APIKey:         req.APIKey,
+		APISecret:      req.APISecret,
+		APIUsername:    req.APIUsername,
+		APIPassword:    req.APIPassword,
+		AdapterType:    req.AdapterType,
+		SignMethod:     req.SignMethod,
+		WebhookSecret:  req.WebhookSecret,
+		IsActive:       true,
+		Priority:       req.Priority,
+		TimeoutSeconds: req.TimeoutSeconds,
+		RetryAttempts:  domain.DefaultRetryAttempts,
+	}
+	if req.IsActive != nil {
+		supplier.IsActive = *req.IsActive
+	}
+	if req.RetryAttempts != nil {
+		supplier.RetryAttempts = *req.RetryAttempts
+	}
+	if req.MinBalanceThreshold != nil {
+		supplier.MinBalanceThreshold = *req.MinBalanceThreshold
+	}
+	if req.RequestRules != nil {
+		supplier.RequestRules = *req.RequestRules
+	}
+
+	if err := h.registryUC.CreateSupplier(supplier); err != nil {
+		respondSupplierError(c, err, "Failed to create supplier")
+		return
+	}
+
+	xresponse.Created(c, "Supplier created", maskSupplier(supplier))
+}
+
+// ListSuppliers lists every supplier account by priority
+func (h *SupplierHandler) ListSuppliers(c *gin.Context) {
+	suppliers, err := h.registryUC.ListSuppliers()
+	if err != nil {
+		respondSupplierError(c, err, "Failed to list suppliers")
+		return
+	}
+
+	masked := make([]*domain.Supplier, 0, len(suppliers))
+	for _, supplier := range suppliers {
+		masked = append(masked, maskSupplier(supplier))
+	}
+
+	xresponse.Success(c, "Suppliers fetched", masked)
+}
+
+// GetSupplier returns a supplier account by ID
+func (h *SupplierHandler) GetSupplier(c *gin.Context) {
+	supplier, err := h.registryUC.GetSupplier(c.Param("id"))
+	if err != nil {
+		respondSupplierError(c, err, "Failed to get supplier")
+		return
+	}
+
+	xresponse.Success(c, "Supplier fetched", maskSupplier(supplier))
+}
+
+// UpdateSupplier changes a supplier account; its adapter is rebuilt with the
+// new settings without a restart
+func (h *SupplierHandler) UpdateSupplier(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "update_supplier", "admin")
+
+	var req UpdateSupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	supplier, err := h.registryUC.UpdateSupplier(c.Param("id"), &domain.SupplierUpdate{
+		Name:                req.Name,
+		APIURL:              req.APIURL,
+		APIKey:              req.APIKey,
+		APISecret:           req.APISecret,
+		APIUsername:         req.APIUsername,
+		APIPassword:         req.APIPassword,
+		AdapterType:         req.AdapterType,
+		SignMethod:          req.SignMethod,
+		WebhookSecret:       req.WebhookSecret,
+		IsActive:            req.IsActive,
+		Priority:            req.Priority,
+		TimeoutSeconds:      req.TimeoutSeconds,
+		RetryAttempts:       req.RetryAttempts,
+		MinBalanceThreshold: req.MinBalanceThreshold,
+		RequestRules:        req.RequestRules,
+	})
+	if err != nil {
+		respondSupplierError(c, err, "Failed to update supplier")
+		return
+	}
+
+	xresponse.Success(c, "Supplier updated", maskSupplier(supplier))
+}
+
+// PreviewRequestRules shows the destination the supplier would receive for a
+// product, to try rules out before saving them
+func (h *SupplierHandler) PreviewRequestRules(c *gin.Context) {
+	var req PreviewRequestRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	destination, err := h.registryUC.PreviewRequestRules(c.Param("id"), req.RequestRules, req.ProductCode, req.Destination)
+	if err != nil {
+		respondSupplierError(c, err, "Failed to preview request rules")
+		return
+	}
+
+	xresponse.Success(c, "Request rules applied", gin.H{
+		"product_code": req.ProductCode,
+		"destination":  req.Destination,
+		"rewritten":    destination,
+	})
+}
+
+// CopyMappings maps the products of another account of the same supplier to
+// this account
+func (h *SupplierHandler) CopyMappings(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "copy_supplier_mappings", "admin")
+
+	var req CopyMappingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	copied, err := h.registryUC.CopyMappings(c.Param("id"), req.SourceSupplierID)
+	if err != nil {
+		respondSupplierError(c, err, "Failed to copy supplier mappings")
+		return
+	}
+
+	xresponse.Success(c, "Supplier mappings copied", gin.H{
+		"supplier_id":        c.Param("id"),
+		"source_supplier_id": req.SourceSupplierID,
+		"copied":             copied,
+	})
+}
+
+// maskSupplier returns a copy of the supplier with its secrets masked
+func maskSupplier(supplier *domain.Supplier) *domain.Supplier {
+	masked := *supplier
+	for _, secret := range []**string{&masked.APIKey, &masked.APISecret, &masked.APIPassword} {
+		if *secret != nil {
+			value := maskedCredential
+			*secret = &value
+		}
+	}
+	return &masked
+}
+
+// respondSupplierError maps supplier registry errors to responses
+func respondSupplierError(c *gin.Context, err error, failure string) {
+	message := err.Error()
+	switch {
+	case message == "supplier not found":
+		xresponse.NotFound(c, message)
+	case message == "supplier already exists":
+		xresponse.Conflict(c, message)
+	case strings.HasPrefix(message, "failed to"):
+		logger.Error(failure, logger.ErrorField(err))
+		xresponse.InternalServerError(c, failure)
+	default:
+		xresponse.BadRequest(c, message)
+	}
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// maxInvoiceUploadBody bounds the multipart request; the use case enforces
+// the configured file size itself
+const maxInvoiceUploadBody = 64 << 20
+
+// SupplierInvoiceHandler handles supplier invoice upload and match reports
+type SupplierInvoiceHandler struct {
+	invoiceUC domain.SupplierInvoiceUsecase
+	roleGuard *RoleGuard
+}
+
+// NewSupplierInvoiceHandler creates a new supplier invoice handler
+func NewSupplierInvoiceHandler(invoiceUC domain.SupplierInvoiceUsecase) *SupplierInvoiceHandler {
+	return &SupplierInvoiceHandler{
+		invoiceUC: invoiceUC,
+		roleGuard: NewRoleGuard(),
+	}
+}
+
+// Upload ingests a supplier recap CSV sent as the multipart field "file".
+// Optional form fields period_start and period_end (YYYY-MM-DD, inclusive)
+// also list our successful transactions the file leaves out.
+func (h *SupplierInvoiceHandler) Upload(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "upload_supplier_invoice", "admin")
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxInvoiceUploadBody)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		xresponse.BadRequest(c, "file is required")
+		return
+	}
+
+	upload := &domain.SupplierInvoiceUpload{
+		SupplierID: c.Param("id"),
+		FileName:   fileHeader.Filename,
+	}
+	for field, target := range map[string]**time.Time{
+		"period_start": &upload.PeriodStart,
+		"period_end":   &upload.PeriodEnd,
+	} {
+		value := c.PostForm(field)
+		if value == "" {
+			continue
+		}
+		day, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			xresponse.BadRequest(c, fmt.Sprintf("Invalid %s format. Use YYYY-MM-DD", field))
+			return
+		}
+		*target = &day
+	}
+	if userID, _, _, exists := h.roleGuard.GetCurrentUser(c); exists && userID != "" {
+		upload.UploadedBy = &userID
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		logger.Error("Failed to open supplier invoice upload", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to read invoice file")
+		return
+	}
+	defer file.Close()
+	upload.File = file
+
+	invoice, err := h.invoiceUC.IngestInvoice(upload)
+	if err != nil {
+		h.respondError(c, err, "Failed to ingest supplier invoice")
+		return
+	}
+
+	xresponse.Created(c, "Supplier invoice ingested", invoice)
+}
+
+// ListInvoices lists ingested invoices newest first. Query: supplier_id,
+// page and limit.
+func (h *SupplierInvoiceHandler) ListInvoices(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	invoices, err := h.invoiceUC.ListInvoices(c.Query("supplier_id"), page, limit)
+	if err != nil {
+		h.respondError(c, err, "Failed to list supplier invoices")
+		return
+	}
+
+	xresponse.Success(c, "Supplier invoices fetched", invoices)
+}
+
+// GetInvoice returns an invoice with its match summary
+func (h *SupplierInvoiceHandler) GetInvoice(c *gin.Context) {
+	invoice, err := h.invoiceUC.GetInvoice(c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get supplier invoice")
+		return
+	}
+
+	xresponse.Success(c, "Supplier invoice fetched", invoice)
+}
+
+// ListLines returns the match report of an invoice. Query: result to keep
+// one kind of line (e.g. AMOUNT_MISMATCH) and format (json|csv).
+func (h *SupplierInvoiceHandler) ListLines(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "get_supplier_invoice_lines", "admin")
+
+	format := strings.ToLower(c.DefaultQuery("format", "json"))
+	if format != "json" && format != "csv" {
+		xresponse.BadRequest(c, "format must be json or csv")
+		return
+	}
+
+	lines, err := h.invoiceUC.ListInvoiceLines(c.Param("id"), c.Query("result"))
+	if err != nil {
+		h.respondError(c, err, "Failed to list supplier invoice lines")
+		return
+	}
+
+	if format == "csv" {
+		h.writeLinesCSV(c, lines)
+		return
+	}
+
+	xresponse.Success(c, "Supplier invoice lines fetched", lines)
+}
+
+func (h *SupplierInvoiceHandler) writeLinesCSV(c *gin.Context, lines []*domain.SupplierInvoiceLine) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	_ = writer.Write([]string{
+		"line_number", "result", "supplier_trx_id", "ref_id", "product_code", "destination",
+		"invoiced_status", "invoiced_amount", "trx_code", "transaction_status", "expected_amount", "note",
+	})
+	for _, line := range lines {
+		_ = writer.Write([]string{
+			strconv.Itoa(line.LineNumber),
+			line.Result,
+			csvString(line.SupplierTrxID),
+			csvString(line.RefID),
+			csvString(line.ProductCode),
+			csvString(line.Destination),
+			csvString(line.InvoicedStatus),
+			csvAmount(line.InvoicedAmount),
+			csvString(line.TrxCode),
+			csvString(line.TransactionStatus),
+			csvAmount(line.ExpectedAmount),
+			csvString(line.Note),
+		})
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		logger.Error("Failed to write supplier invoice CSV", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to export supplier invoice lines")
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=supplier-invoice-"+c.Param("id")+".csv")
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+func (h *SupplierInvoiceHandler) respondError(c *gin.Context, err error, message string) {
+	msg := err.Error()
+	switch {
+	case msg == "supplier not found", msg == "supplier invoice not found":
+		xresponse.NotFound(c, msg)
+	case strings.HasPrefix(msg, "invoice "), strings.HasPrefix(msg, "invalid invoice"),
+		strings.HasPrefix(msg, "period_"), strings.HasPrefix(msg, "line "):
+		xresponse.BadRequest(c, msg)
+	default:
+		logger.Error(message, logger.ErrorField(err))
+		xresponse.InternalServerError(c, message)
+	}
+}
+
+func csvString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func csvAmount(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', 2, 64)
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// maxSLAWindow bounds the SLA window to the default probe retention
+const maxSLAWindow = 7 * 24 * time.Hour
+
+// SupplierSLAHandler handles supplier latency and SLA endpoints
+type SupplierSLAHandler struct {
+	probeUC   domain.SupplierProbeUsecase
+	roleGuard *RoleGuard
+}
+
+// NewSupplierSLAHandler creates a new supplier SLA handler
+func NewSupplierSLAHandler(probeUC domain.SupplierProbeUsecase) *SupplierSLAHandler {
+	return &SupplierSLAHandler{
+		probeUC:   probeUC,
+		roleGuard: NewRoleGuard(),
+	}
+}
+
+// GetSLAReport returns probe latency percentiles and SLA compliance per
+// supplier. Query: window (Go duration, e.g. 1h or 24h; default 24h).
+func (h *SupplierSLAHandler) GetSLAReport(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "get_supplier_sla", "admin")
+
+	var window time.Duration
+	if windowStr := c.Query("window"); windowStr != "" {
+		parsed, err := time.ParseDuration(windowStr)
+		if err != nil || parsed <= 0 || parsed > maxSLAWindow {
+			xresponse.BadRequest(c, "window must be a duration between 1s and 168h")
+			return
+		}
+		window = parsed
+	}
+
+	report, err := h.probeUC.GetSLAReport(window)
+	if err != nil {
+		logger.Error("Failed to get supplier SLA report", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to get supplier SLA report")
+		return
+	}
+
+	xresponse.Success(c, "Supplier SLA report fetched", report)
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/metrics"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookBodySize caps the supplier webhook payload read for verification
+const maxWebhookBodySize = 1 << 20
+
+// SupplierWebhookHandler receives transaction results pushed by suppliers
+type SupplierWebhookHandler struct {
+	webhookUC domain.SupplierWebhookUsecase
+}
+
+// NewSupplierWebhookHandler creates a new supplier webhook handler
+func NewSupplierWebhookHandler(webhookUC domain.SupplierWebhookUsecase) *SupplierWebhookHandler {
+	return &SupplierWebhookHandler{webhookUC: webhookUC}
+}
+
+// HandleWebhook verifies and applies a supplier webhook. The signature is read
+// from X-Hub-Signature (Digiflazz) or X-Signature.
+func (h *SupplierWebhookHandler) HandleWebhook(c *gin.Context) {
+	supplierCode := c.Param("code")
+
+	payload, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBodySize))
+	if err != nil {
+		xresponse.BadRequest(c, "Invalid webhook payload")
+		return
+	}
+
+	webhook := &domain.SupplierWebhook{
+		SupplierCode: supplierCode,
+		Signature:    firstHeader(c, "X-Hub-Signature", "X-Signature"),
+		Payload:      payload,
+	}
+
+	err = h.webhookUC.HandleWebhook(c.Request.Context(), webhook)
+	if err == nil {
+		xresponse.Success(c, "Webhook processed", nil)
+		return
+	}
+
+	var rejection *domain.WebhookRejection
+	if errors.As(err, &rejection) {
+		// Unknown codes are not used as label values, they come from the URL
+		label := supplierCode
+		if rejection.Reason == domain.WebhookRejectUnknownSupplier {
+			label = "unknown"
+		}
+		metrics.RecordSupplierWebhookRejection(label, rejection.Reason)
+
+		logger.Warn("Supplier webhook rejected",
+			logger.String("supplier_code", supplierCode),
+			logger.String("reason", rejection.Reason),
+			logger.String("client_ip", c.ClientIP()),
+		)
+
+		switch rejection.Reason {
+		case domain.WebhookRejectReplay:
+			// Redeliveries of a processed event must not be retried again
+			xresponse.Success(c, "Webhook already processed", nil)
+		default:
+			xresponse.Unauthorized(c, "Invalid webhook signature")
+		}
+		return
+	}
+
+	switch {
+	case err.Error() == "transaction not found":
+		xresponse.NotFound(c, "Transaction not found")
+	case err.Error() == "transaction was not routed to this supplier":
+		xresponse.Conflict(c, err.Error())
+	case strings.HasPrefix(err.Error(), "invalid webhook payload"):
+		xresponse.BadRequest(c, "Invalid webhook payload")
+	default:
+		logger.Error("Failed to process supplier webhook",
+			logger.String("supplier_code", supplierCode),
+			logger.ErrorField(err),
+		)
+		xresponse.InternalServerError(c, "Failed to process webhook")
+	}
+}
+
+// firstHeader returns the first non-empty header of names
+func firstHeader(c *gin.Context, names ...string) string {
+	for _, name := range names {
+		if value := c.GetHeader(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
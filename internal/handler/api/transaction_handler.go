@@ -1,7 +1,14 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/alfanzaky/eraflazz/internal/domain"
@@ -14,15 +21,17 @@ import (
 
 // TransactionHandler handles transaction-related HTTP requests
 type TransactionHandler struct {
-	transactionUC domain.TransactionUsecase
-	roleGuard     *RoleGuard
+	transactionUC  domain.TransactionUsecase
+	confirmationUC domain.AmountConfirmationUsecase
+	roleGuard      *RoleGuard
 }
 
 // NewTransactionHandler creates a new transaction handler
-func NewTransactionHandler(transactionUC domain.TransactionUsecase) *TransactionHandler {
+func NewTransactionHandler(transactionUC domain.TransactionUsecase, confirmationUC domain.AmountConfirmationUsecase) *TransactionHandler {
 	return &TransactionHandler{
-		transactionUC: transactionUC,
-		roleGuard:     NewRoleGuard(),
+		transactionUC:  transactionUC,
+		confirmationUC: confirmationUC,
+		roleGuard:      NewRoleGuard(),
 	}
 }
 
@@ -31,6 +40,14 @@ type CreateTransactionRequest struct {
 	ProductCode       string  `json:"product_code" binding:"required"`
 	DestinationNumber string  `json:"destination_number" binding:"required"`
 	CustomerNotes     *string `json:"customer_notes,omitempty"`
+	Channel           string  `json:"channel,omitempty"`         // API (default), WHATSAPP, TELEGRAM or SMS
+	Simulate          bool    `json:"simulate,omitempty"`        // Dry-run: no balance hold, no supplier call
+	AllowDuplicate    bool    `json:"allow_duplicate,omitempty"` // Confirms repeating a recent order
+	// ConfirmationToken or PIN confirms an order above the amount threshold
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
+	PIN               string `json:"pin,omitempty"`
+	// Tags label the transaction for filtering and reports, e.g. {"branch": "JKT-01"}
+	Tags map[string]string `json:"tags,omitempty"`
 }
 
 // TransactionResponse represents response for transaction
@@ -43,61 +60,75 @@ type TransactionResponse struct {
 	HPP               float64 `json:"hpp"`
 	SellingPrice      float64 `json:"selling_price"`
 	AdminFee          float64 `json:"admin_fee"`
+	TotalAmount       float64 `json:"total_amount"`
 	Profit            float64 `json:"profit"`
 	Status            string  `json:"status"`
+	Channel           string  `json:"channel"`
+	ExpiresAt         *string `json:"expires_at,omitempty"`
+	ScheduledAt       *string `json:"scheduled_at,omitempty"` // Set while held by a biller cutoff
 	SerialNumber      *string `json:"serial_number,omitempty"`
 	SupplierMessage   *string `json:"supplier_message,omitempty"`
 	CreatedAt         string  `json:"created_at"`
 	ProcessedAt       *string `json:"processed_at,omitempty"`
 	CompletedAt       *string `json:"completed_at,omitempty"`
+
+	// Set for queued orders while the queue is backed up
+	EstimatedWaitSeconds  *int    `json:"estimated_wait_seconds,omitempty"`
+	EstimatedCompletionAt *string `json:"estimated_completion_at,omitempty"`
+
+	Tags domain.TransactionTags `json:"tags,omitempty"`
 }
 
 // CreateTransaction creates a new transaction
 func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 	var req CreateTransactionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		logger.Error("Invalid request body", logger.ErrorField(err))
-		xresponse.BadRequest(c, "Invalid request format")
+		logger.FromContext(c.Request.Context()).Error("Invalid request body", logger.ErrorField(err))
+		respondBindingError(c, err)
 		return
 	}
 
 	// Check if user or H2H client is authenticated
+	channel := strings.ToUpper(req.Channel)
 	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
 	if !exists {
 		// Check if it's an H2H client
 		if clientID, isH2H := GetClientIDFromContext(c); isH2H {
 			userID = clientID
+			channel = domain.ChannelH2H
 		} else {
 			xresponse.Unauthorized(c, "Authentication required")
 			return
 		}
+	} else if channel == domain.ChannelH2H {
+		xresponse.BadRequest(c, "Invalid channel")
+		return
 	}
 
 	// Log the access attempt
 	h.roleGuard.LogAccess(c, "create_transaction", req.ProductCode)
 
+	tags, err := domain.NormalizeTransactionTags(req.Tags)
+	if err != nil {
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	if req.Simulate {
+		h.simulateTransaction(c, userID, req.ProductCode, req.DestinationNumber, channel)
+		return
+	}
+
 	// Create transaction
-	transaction, err := h.transactionUC.CreateTransaction(userID, req.ProductCode, req.DestinationNumber)
+	confirmation := domain.OrderConfirmation{Token: req.ConfirmationToken, PIN: req.PIN}
+	transaction, err := h.transactionUC.CreateTransaction(orderContext(c, req.AllowDuplicate, tags, confirmation), userID, req.ProductCode, req.DestinationNumber, channel)
 	if err != nil {
-		logger.Error("Failed to create transaction",
-			logger.String("user_id", userID),
+		logger.FromContext(c.Request.Context()).Error("Failed to create transaction",
 			logger.String("product_code", req.ProductCode),
 			logger.ErrorField(err),
 		)
 
-		// Handle specific error types
-		switch err.Error() {
-		case "user not found":
-			xresponse.UserNotFound(c, "User account not found")
-		case "product not found":
-			xresponse.InvalidProduct(c, "Product not found or unavailable")
-		case "insufficient balance":
-			xresponse.InsufficientBalance(c, "Insufficient balance for this transaction")
-		case "invalid phone number format":
-			xresponse.BadRequest(c, "Invalid phone number format")
-		default:
-			xresponse.InternalServerError(c, "Failed to create transaction")
-		}
+		respondCreateTransactionError(c, err)
 		return
 	}
 
@@ -136,9 +167,22 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 		HPP:               transaction.HPP,
 		SellingPrice:      transaction.SellingPrice,
 		AdminFee:          transaction.AdminFee,
-		Profit:            transaction.CalculateProfit(),
+		TotalAmount:       transaction.TotalAmount(),
+		Profit:            transaction.Profit,
 		Status:            transaction.Status,
+		Channel:           transaction.Channel,
 		CreatedAt:         transaction.CreatedAt.Format("2006-01-02 15:04:05"),
+		Tags:              transaction.Tags,
+	}
+
+	if transaction.ExpiresAt != nil {
+		expiresAt := transaction.ExpiresAt.Format("2006-01-02 15:04:05")
+		response.ExpiresAt = &expiresAt
+	}
+
+	if transaction.ScheduledAt != nil {
+		scheduledAt := transaction.ScheduledAt.Format("2006-01-02 15:04:05")
+		response.ScheduledAt = &scheduledAt
 	}
 
 	if transaction.ProcessedAt != nil {
@@ -150,16 +194,207 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 		completedAt := transaction.CompletedAt.Format("2006-01-02 15:04:05")
 		response.CompletedAt = &completedAt
 	}
+	applyIntakeEstimate(c, &response, transaction)
 
-	logger.Info("Transaction created via API",
+	logger.FromContext(c.Request.Context()).Info("Transaction created via API",
 		logger.String("trx_id", transaction.ID),
 		logger.String("trx_code", transaction.TrxCode),
-		logger.String("user_id", userID),
 	)
 
 	xresponse.Created(c, "Transaction created successfully", response)
 }
 
+// H2HPayment creates a transaction for an authenticated H2H client, charged to
+// the client's account. Clients with synchronous failover enabled get the
+// processed result in the response; others get the queued transaction.
+func (h *TransactionHandler) H2HPayment(c *gin.Context) {
+	var req CreateTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.FromContext(c.Request.Context()).Error("Invalid request body", logger.ErrorField(err))
+		respondBindingError(c, err)
+		return
+	}
+
+	client, exists := GetClientFromContext(c)
+	if !exists {
+		xresponse.Unauthorized(c, "Client not authenticated")
+		return
+	}
+	if client.UserID == nil {
+		xresponse.Forbidden(c, "API client is not linked to an account")
+		return
+	}
+	userID := *client.UserID
+
+	tags, err := domain.NormalizeTransactionTags(req.Tags)
+	if err != nil {
+		xresponse.BadRequest(c, err.Error())
+		return
+	}
+
+	// Sandbox clients never spend balance, whatever the request says
+	if req.Simulate || client.Sandbox {
+		h.simulateTransaction(c, userID, req.ProductCode, req.DestinationNumber, domain.ChannelH2H)
+		return
+	}
+
+	var transaction *domain.Transaction
+	ctx := orderContext(c, req.AllowDuplicate, tags, domain.OrderConfirmation{Token: req.ConfirmationToken, PIN: req.PIN})
+	policy := syncFailoverPolicy(client)
+	if policy != nil {
+		transaction, err = h.transactionUC.CreateTransactionSync(ctx, userID, req.ProductCode, req.DestinationNumber, domain.ChannelH2H, policy)
+	} else {
+		transaction, err = h.transactionUC.CreateTransaction(ctx, userID, req.ProductCode, req.DestinationNumber, domain.ChannelH2H)
+	}
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Error("Failed to create H2H transaction",
+			logger.String("product_code", req.ProductCode),
+			logger.ErrorField(err),
+		)
+		respondCreateTransactionError(c, err)
+		return
+	}
+
+	c.Set(h2hTransactionKey, transaction)
+	metrics.RecordTransaction(transaction.Status, "unknown", "h2h", transaction.SellingPrice)
+
+	logger.FromContext(c.Request.Context()).Info("Transaction created via H2H",
+		logger.String("trx_id", transaction.ID),
+		logger.String("trx_code", transaction.TrxCode),
+		logger.Bool("sync", policy != nil),
+		logger.String("status", transaction.Status),
+	)
+
+	response := buildTransactionResponse(transaction)
+	applyIntakeEstimate(c, &response, transaction)
+
+	// A synchronous order without a final result ran out of request time; it
+	// completes asynchronously and is reported like a queued order
+	if policy != nil && !transaction.IsFinalStatus() {
+		xresponse.SuccessWithCode(c, http.StatusAccepted, "Transaction accepted, result will follow asynchronously", projectTransactionResponse(c, response))
+		return
+	}
+	xresponse.Created(c, "Transaction created successfully", projectTransactionResponse(c, response))
+}
+
+// simulateTransaction answers an order request with its simulated outcome
+func (h *TransactionHandler) simulateTransaction(c *gin.Context, userID, productCode, destinationNumber, channel string) {
+	simulation, err := h.transactionUC.SimulateTransaction(c.Request.Context(), userID, productCode, destinationNumber, channel)
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Warn("Failed to simulate transaction",
+			logger.String("product_code", productCode),
+			logger.ErrorField(err),
+		)
+		respondCreateTransactionError(c, err)
+		return
+	}
+
+	xresponse.Success(c, "Transaction simulated", projectTransactionResponse(c, simulation))
+}
+
+// orderContext returns the request context carrying the order's tags, its
+// amount confirmation and, when the order confirms it repeats a recent one,
+// the duplicate override
+func orderContext(c *gin.Context, allowDuplicate bool, tags domain.TransactionTags, confirmation domain.OrderConfirmation) context.Context {
+	ctx := domain.WithTransactionTags(c.Request.Context(), tags)
+	ctx = domain.WithOrderConfirmation(ctx, confirmation)
+	if allowDuplicate {
+		return domain.WithDuplicateOverride(ctx)
+	}
+	return ctx
+}
+
+// respondCreateTransactionError maps transaction creation errors to responses
+func respondCreateTransactionError(c *gin.Context, err error) {
+	var duplicateErr *domain.DuplicateTransactionError
+	if errors.As(err, &duplicateErr) {
+		message := "Identical transaction is already in progress or recently succeeded"
+		if duplicateErr.Confirmable {
+			message += ". Resend with allow_duplicate=true to order again"
+		}
+		xresponse.ErrorWithDetails(c, http.StatusConflict, xresponse.ErrCodeDuplicateTransaction, message, gin.H{
+			"trx_code":    duplicateErr.Existing.TrxCode,
+			"status":      duplicateErr.Existing.Status,
+			"created_at":  duplicateErr.Existing.CreatedAt.Format("2006-01-02 15:04:05"),
+			"confirmable": duplicateErr.Confirmable,
+		})
+		return
+	}
+
+	var confirmErr *domain.AmountConfirmationRequiredError
+	if errors.As(err, &confirmErr) {
+		message := "Order amount is above your confirmation threshold. Resend with confirmation_token"
+		if confirmErr.PINAllowed {
+			message += " or pin"
+		}
+		message += " to place it"
+		if confirmErr.TokenRejected {
+			message = "Confirmation token is invalid, expired or for another order. " + message
+		}
+		xresponse.ErrorWithDetails(c, http.StatusPreconditionRequired, xresponse.ErrCodeConfirmationRequired, message, gin.H{
+			"confirmation_token": confirmErr.Token,
+			"expires_at":         confirmErr.ExpiresAt.Format(time.RFC3339),
+			"total_amount":       confirmErr.TotalAmount,
+			"threshold":          confirmErr.Threshold,
+			"pin_allowed":        confirmErr.PINAllowed,
+		})
+		return
+	}
+
+	var lockedErr *domain.PINLockedError
+	if errors.As(err, &lockedErr) {
+		seconds := int(math.Ceil(lockedErr.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(seconds))
+		xresponse.AccountLocked(c, fmt.Sprintf("Too many wrong PINs, try again in %d seconds", seconds))
+		return
+	}
+
+	var destinationErr *domain.DestinationError
+	if errors.As(err, &destinationErr) {
+		message := "Invalid destination number"
+		if destinationErr.Hint != "" {
+			message += ". Expected format: " + destinationErr.Hint
+		}
+		xresponse.BadRequest(c, message)
+		return
+	}
+
+	var cutoffErr *domain.CutoffError
+	if errors.As(err, &cutoffErr) {
+		xresponse.Conflict(c, cutoffErr.Error())
+		return
+	}
+
+	var restrictedErr *domain.ProductRestrictedError
+	if errors.As(err, &restrictedErr) {
+		xresponse.ErrorWithDetails(c, http.StatusForbidden, xresponse.ErrCodeProductRestricted,
+			"Product requires "+domain.MapLevelToRole(restrictedErr.MinLevel)+" level or above", gin.H{
+				"min_level": restrictedErr.MinLevel,
+				"min_role":  domain.MapLevelToRole(restrictedErr.MinLevel),
+			})
+		return
+	}
+
+	switch err.Error() {
+	case "user not found":
+		xresponse.UserNotFound(c, "User account not found")
+	case "product not found":
+		xresponse.InvalidProduct(c, "Product not found or unavailable")
+	case "product is out of stock":
+		xresponse.InvalidProduct(c, "Product is out of stock")
+	case "insufficient balance":
+		xresponse.InsufficientBalance(c, "Insufficient balance for this transaction")
+	case "invalid channel":
+		xresponse.BadRequest(c, "Invalid channel")
+	case "invalid PIN":
+		xresponse.Forbidden(c, "Invalid PIN")
+	case "transaction PIN is not set":
+		xresponse.BadRequest(c, "Transaction PIN is not set, confirm with confirmation_token instead")
+	default:
+		xresponse.InternalServerError(c, "Failed to create transaction")
+	}
+}
+
 // GetTransaction retrieves a transaction by ID
 func (h *TransactionHandler) GetTransaction(c *gin.Context) {
 	trxID := c.Param("id")
@@ -168,13 +403,9 @@ func (h *TransactionHandler) GetTransaction(c *gin.Context) {
 		return
 	}
 
-	// Get authenticated user or H2H client
-	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
-	if !exists {
-		// Check if it's an H2H client
-		if clientID, isH2H := GetClientIDFromContext(c); isH2H {
-			userID = clientID
-		} else {
+	// Require an authenticated user or H2H client
+	if _, _, _, exists := h.roleGuard.GetCurrentUser(c); !exists {
+		if _, isH2H := GetClientIDFromContext(c); !isH2H {
 			xresponse.Unauthorized(c, "Authentication required")
 			return
 		}
@@ -185,9 +416,8 @@ func (h *TransactionHandler) GetTransaction(c *gin.Context) {
 	// Get transaction
 	transaction, err := h.transactionUC.GetTransaction(trxID)
 	if err != nil {
-		logger.Error("Failed to get transaction",
+		logger.FromContext(c.Request.Context()).Error("Failed to get transaction",
 			logger.String("trx_id", trxID),
-			logger.String("user_id", userID),
 			logger.ErrorField(err),
 		)
 
@@ -205,11 +435,188 @@ func (h *TransactionHandler) GetTransaction(c *gin.Context) {
 		return
 	}
 
-	response := h.buildTransactionResponse(transaction)
+	response := buildTransactionResponse(transaction)
 
 	xresponse.Success(c, "Transaction retrieved successfully", response)
 }
 
+// AdminTransactionResponse is the admin view of a transaction, including the
+// supplier routing decisions behind it
+type AdminTransactionResponse struct {
+	TransactionResponse
+	ProductID        string                    `json:"product_id"`
+	SupplierID       *string                   `json:"supplier_id"`
+	SupplierTrxID    *string                   `json:"supplier_trx_id"`
+	RoutingAttempts  int                       `json:"routing_attempts"`
+	RoutingDecisions []*domain.RoutingDecision `json:"routing_decisions"`
+}
+
+// GetAdminTransaction returns a transaction with its routing decisions (admin)
+func (h *TransactionHandler) GetAdminTransaction(c *gin.Context) {
+	trxID := c.Param("id")
+	h.roleGuard.LogAccess(c, "get_admin_transaction", trxID)
+
+	transaction, err := h.transactionUC.GetTransaction(trxID)
+	if err != nil {
+		if err.Error() == "transaction not found" {
+			xresponse.NotFound(c, "Transaction not found")
+			return
+		}
+		logger.FromContext(c.Request.Context()).Error("Failed to get transaction",
+			logger.String("trx_id", trxID),
+			logger.ErrorField(err),
+		)
+		xresponse.InternalServerError(c, "Failed to retrieve transaction")
+		return
+	}
+
+	decisions, err := h.transactionUC.GetRoutingDecisions(trxID)
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Error("Failed to get routing decisions",
+			logger.String("trx_id", trxID),
+			logger.ErrorField(err),
+		)
+		xresponse.InternalServerError(c, "Failed to retrieve routing decisions")
+		return
+	}
+	if decisions == nil {
+		decisions = []*domain.RoutingDecision{}
+	}
+
+	xresponse.Success(c, "Transaction retrieved successfully", AdminTransactionResponse{
+		TransactionResponse: buildTransactionResponse(transaction),
+		ProductID:           transaction.ProductID,
+		SupplierID:          transaction.SupplierID,
+		SupplierTrxID:       transaction.SupplierTrxID,
+		RoutingAttempts:     transaction.RoutingAttempts,
+		RoutingDecisions:    decisions,
+	})
+}
+
+// ForceRefundRequest payload; the reason is kept on the transaction timeline
+type ForceRefundRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// ForceRefund refunds a finished transaction (admin, signed request)
+func (h *TransactionHandler) ForceRefund(c *gin.Context) {
+	trxID := c.Param("id")
+	h.roleGuard.LogAccess(c, "force_refund_transaction", trxID)
+
+	var req ForceRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	transaction, err := h.transactionUC.ForceRefundTransaction(trxID, requestActor(c), req.Reason)
+	if err != nil {
+		switch err.Error() {
+		case "transaction not found":
+			xresponse.NotFound(c, "Transaction not found")
+		case "transaction already refunded", "transaction is still in progress":
+			xresponse.Conflict(c, err.Error())
+		case "reason is required", "transaction balance was never charged":
+			xresponse.BadRequest(c, err.Error())
+		default:
+			logger.FromContext(c.Request.Context()).Error("Failed to force refund transaction",
+				logger.String("trx_id", trxID),
+				logger.ErrorField(err),
+			)
+			xresponse.InternalServerError(c, "Failed to refund transaction")
+		}
+		return
+	}
+
+	xresponse.Success(c, "Transaction refunded", buildTransactionResponse(transaction))
+}
+
+// ReprocessRequest payload. supplier_id sends the transaction to that
+// supplier instead of the one smart routing picks; the reason is kept on the
+// transaction timeline.
+type ReprocessRequest struct {
+	SupplierID string `json:"supplier_id"`
+	Reason     string `json:"reason"`
+}
+
+// Reprocess sends a failed or timed out transaction to a supplier again
+// (admin, signed request). The response carries the new outcome.
+func (h *TransactionHandler) Reprocess(c *gin.Context) {
+	trxID := c.Param("id")
+	h.roleGuard.LogAccess(c, "reprocess_transaction", trxID)
+
+	var req ReprocessRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondBindingError(c, err)
+		return
+	}
+
+	transaction, err := h.transactionUC.ReprocessTransaction(c.Request.Context(), trxID, strings.TrimSpace(req.SupplierID), requestActor(c), req.Reason)
+	if err != nil {
+		var cutoffErr *domain.CutoffError
+		if errors.As(err, &cutoffErr) {
+			xresponse.Conflict(c, cutoffErr.Error())
+			return
+		}
+
+		message := err.Error()
+		switch {
+		case message == "transaction not found":
+			xresponse.NotFound(c, "Transaction not found")
+		case message == "supplier not found":
+			xresponse.NotFound(c, "Supplier not found")
+		case message == "transaction already succeeded",
+			message == "transaction already refunded",
+			message == "transaction is still in progress",
+			message == "transaction balance was already charged",
+			message == "transaction status changed, reload and try again",
+			message == "transaction is being processed by another worker":
+			xresponse.Conflict(c, message)
+		case message == "supplier is not active",
+			message == "supplier has no active mapping for this product",
+			message == "insufficient balance",
+			strings.HasPrefix(message, "no active mappings"),
+			strings.HasPrefix(message, "no supplier available"):
+			xresponse.BadRequest(c, message)
+		default:
+			logger.FromContext(c.Request.Context()).Error("Failed to reprocess transaction",
+				logger.String("trx_id", trxID),
+				logger.ErrorField(err),
+			)
+			xresponse.InternalServerError(c, "Failed to reprocess transaction")
+		}
+		return
+	}
+
+	if !transaction.IsFinalStatus() {
+		xresponse.SuccessWithCode(c, http.StatusAccepted, "Transaction reprocess accepted, result will follow asynchronously", buildTransactionResponse(transaction))
+		return
+	}
+	xresponse.Success(c, "Transaction reprocessed", buildTransactionResponse(transaction))
+}
+
+// GetTransactionTimeline returns the ordered event history of a transaction (admin)
+func (h *TransactionHandler) GetTransactionTimeline(c *gin.Context) {
+	trxID := c.Param("id")
+	h.roleGuard.LogAccess(c, "get_transaction_timeline", trxID)
+
+	timeline, err := h.transactionUC.GetTransactionTimeline(trxID)
+	if err != nil {
+		if err.Error() == "transaction not found" {
+			xresponse.NotFound(c, "Transaction not found")
+			return
+		}
+		logger.FromContext(c.Request.Context()).Error("Failed to get transaction timeline",
+			logger.String("trx_id", trxID),
+			logger.ErrorField(err),
+		)
+		xresponse.InternalServerError(c, "Failed to retrieve transaction timeline")
+		return
+	}
+
+	xresponse.Success(c, "Transaction timeline retrieved successfully", timeline)
+}
+
 // GetTransactionByCode retrieves a transaction by transaction code
 func (h *TransactionHandler) GetTransactionByCode(c *gin.Context) {
 	trxCode := c.Param("code")
@@ -218,13 +625,9 @@ func (h *TransactionHandler) GetTransactionByCode(c *gin.Context) {
 		return
 	}
 
-	// Get authenticated user or H2H client
-	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
-	if !exists {
-		// Check if it's an H2H client
-		if clientID, isH2H := GetClientIDFromContext(c); isH2H {
-			userID = clientID
-		} else {
+	// Require an authenticated user or H2H client
+	if _, _, _, exists := h.roleGuard.GetCurrentUser(c); !exists {
+		if _, isH2H := GetClientIDFromContext(c); !isH2H {
 			xresponse.Unauthorized(c, "Authentication required")
 			return
 		}
@@ -235,9 +638,8 @@ func (h *TransactionHandler) GetTransactionByCode(c *gin.Context) {
 	// Get transaction
 	transaction, err := h.transactionUC.GetTransactionByTrxCode(trxCode)
 	if err != nil {
-		logger.Error("Failed to get transaction by code",
+		logger.FromContext(c.Request.Context()).Error("Failed to get transaction by code",
 			logger.String("trx_code", trxCode),
-			logger.String("user_id", userID),
 			logger.ErrorField(err),
 		)
 
@@ -255,12 +657,14 @@ func (h *TransactionHandler) GetTransactionByCode(c *gin.Context) {
 		return
 	}
 
-	response := h.buildTransactionResponse(transaction)
+	response := buildTransactionResponse(transaction)
 
 	xresponse.Success(c, "Transaction retrieved successfully", response)
 }
 
-// GetUserTransactions retrieves user transactions with pagination
+// GetUserTransactions retrieves user transactions created between start_date
+// and end_date (default the last 90 days) with pagination. Repeated
+// tag=key:value parameters only keep transactions carrying all the tags.
 func (h *TransactionHandler) GetUserTransactions(c *gin.Context) {
 	// Get pagination parameters
 	pageStr := c.DefaultQuery("page", "1")
@@ -290,11 +694,44 @@ func (h *TransactionHandler) GetUserTransactions(c *gin.Context) {
 
 	h.roleGuard.LogAccess(c, "get_user_transactions", "own_transactions")
 
+	// Listings are bounded by date so only the matching partitions are read
+	period, ok := bindListingRange(c)
+	if !ok {
+		return
+	}
+	tags, ok := bindTagFilter(c)
+	if !ok {
+		return
+	}
+
+	// Cursor mode: ?cursor= (empty for the first page) switches to keyset pagination
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		transactions, nextCursor, err := h.transactionUC.GetUserTransactionsByCursor(userID, period, tags, cursor, limit)
+		if err != nil {
+			if err.Error() == "invalid cursor" {
+				xresponse.BadRequest(c, err.Error())
+				return
+			}
+			logger.FromContext(c.Request.Context()).Error("Failed to get user transactions",
+				logger.ErrorField(err),
+			)
+			xresponse.InternalServerError(c, "Failed to retrieve transactions")
+			return
+		}
+
+		responses := make([]TransactionResponse, len(transactions))
+		for i, trx := range transactions {
+			responses[i] = buildTransactionResponse(trx)
+		}
+
+		xresponse.CursorPaginated(c, "Transactions retrieved successfully", responses, limit, nextCursor)
+		return
+	}
+
 	// Get transactions
-	transactions, err := h.transactionUC.GetUserTransactions(userID, page, limit)
+	transactions, err := h.transactionUC.GetUserTransactions(userID, period, tags, page, limit)
 	if err != nil {
-		logger.Error("Failed to get user transactions",
-			logger.String("user_id", userID),
+		logger.FromContext(c.Request.Context()).Error("Failed to get user transactions",
 			logger.ErrorField(err),
 		)
 		xresponse.InternalServerError(c, "Failed to retrieve transactions")
@@ -304,7 +741,7 @@ func (h *TransactionHandler) GetUserTransactions(c *gin.Context) {
 	// Build response
 	responses := make([]TransactionResponse, len(transactions))
 	for i, trx := range transactions {
-		responses[i] = h.buildTransactionResponse(trx)
+		responses[i] = buildTransactionResponse(trx)
 	}
 
 	xresponse.Success(c, "Transactions retrieved successfully", responses)
@@ -318,13 +755,9 @@ func (h *TransactionHandler) CancelTransaction(c *gin.Context) {
 		return
 	}
 
-	// Get authenticated user or H2H client
-	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
-	if !exists {
-		// Check if it's an H2H client
-		if clientID, isH2H := GetClientIDFromContext(c); isH2H {
-			userID = clientID
-		} else {
+	// Require an authenticated user or H2H client
+	if _, _, _, exists := h.roleGuard.GetCurrentUser(c); !exists {
+		if _, isH2H := GetClientIDFromContext(c); !isH2H {
 			xresponse.Unauthorized(c, "Authentication required")
 			return
 		}
@@ -350,25 +783,25 @@ func (h *TransactionHandler) CancelTransaction(c *gin.Context) {
 	}
 
 	// Cancel transaction
-	err = h.transactionUC.CancelTransaction(trxID)
+	err = h.transactionUC.CancelTransaction(c.Request.Context(), trxID)
 	if err != nil {
-		logger.Error("Failed to cancel transaction",
+		logger.FromContext(c.Request.Context()).Error("Failed to cancel transaction",
 			logger.String("trx_id", trxID),
-			logger.String("user_id", userID),
 			logger.ErrorField(err),
 		)
 
 		if err.Error() == "cannot cancel transaction in "+transaction.Status {
 			xresponse.BadRequest(c, "Cannot cancel transaction in "+transaction.Status+" status")
+		} else if err.Error() == "transaction is no longer pending" {
+			xresponse.Conflict(c, "Transaction is already being processed")
 		} else {
 			xresponse.InternalServerError(c, "Failed to cancel transaction")
 		}
 		return
 	}
 
-	logger.Info("Transaction cancelled via API",
+	logger.FromContext(c.Request.Context()).Info("Transaction cancelled via API",
 		logger.String("trx_id", trxID),
-		logger.String("user_id", userID),
 	)
 
 	xresponse.Success(c, "Transaction cancelled successfully", nil)
@@ -419,11 +852,29 @@ func (h *TransactionHandler) GetTransactionStats(c *gin.Context) {
 
 	h.roleGuard.LogAccess(c, "get_transaction_stats", "own_stats")
 
+	// group_by_tag=key splits the statistics per value of one tag
+	if tagKey := c.Query("group_by_tag"); tagKey != "" {
+		stats, err := h.transactionUC.GetTransactionStatsByTag(userID, tagKey, startDate, endDate)
+		if err != nil {
+			if err.Error() == "invalid tag key" {
+				xresponse.BadRequest(c, err.Error())
+				return
+			}
+			logger.FromContext(c.Request.Context()).Error("Failed to get transaction stats by tag",
+				logger.ErrorField(err),
+			)
+			xresponse.InternalServerError(c, "Failed to retrieve statistics")
+			return
+		}
+
+		xresponse.Success(c, "Statistics retrieved successfully", stats)
+		return
+	}
+
 	// Get statistics
 	stats, err := h.transactionUC.GetTransactionStats(userID, startDate, endDate)
 	if err != nil {
-		logger.Error("Failed to get transaction stats",
-			logger.String("user_id", userID),
+		logger.FromContext(c.Request.Context()).Error("Failed to get transaction stats",
 			logger.ErrorField(err),
 		)
 		xresponse.InternalServerError(c, "Failed to retrieve statistics")
@@ -434,7 +885,7 @@ func (h *TransactionHandler) GetTransactionStats(c *gin.Context) {
 }
 
 // buildTransactionResponse builds transaction response from domain model
-func (h *TransactionHandler) buildTransactionResponse(trx *domain.Transaction) TransactionResponse {
+func buildTransactionResponse(trx *domain.Transaction) TransactionResponse {
 	response := TransactionResponse{
 		ID:                trx.ID,
 		TrxCode:           trx.TrxCode,
@@ -444,11 +895,24 @@ func (h *TransactionHandler) buildTransactionResponse(trx *domain.Transaction) T
 		HPP:               trx.HPP,
 		SellingPrice:      trx.SellingPrice,
 		AdminFee:          trx.AdminFee,
-		Profit:            trx.CalculateProfit(),
+		TotalAmount:       trx.TotalAmount(),
+		Profit:            trx.Profit,
 		Status:            trx.Status,
+		Channel:           trx.Channel,
 		SerialNumber:      trx.SerialNumber,
 		SupplierMessage:   trx.SupplierMessage,
 		CreatedAt:         trx.CreatedAt.Format("2006-01-02 15:04:05"),
+		Tags:              trx.Tags,
+	}
+
+	if trx.ExpiresAt != nil {
+		expiresAt := trx.ExpiresAt.Format("2006-01-02 15:04:05")
+		response.ExpiresAt = &expiresAt
+	}
+
+	if trx.ScheduledAt != nil {
+		scheduledAt := trx.ScheduledAt.Format("2006-01-02 15:04:05")
+		response.ScheduledAt = &scheduledAt
 	}
 
 	if trx.ProcessedAt != nil {
@@ -463,3 +927,68 @@ func (h *TransactionHandler) buildTransactionResponse(trx *domain.Transaction) T
 
 	return response
 }
+
+// UpdateConfirmationSettingsRequest payload. A null threshold restores the
+// default; 0 turns the confirmation step off.
+type UpdateConfirmationSettingsRequest struct {
+	Threshold *float64 `json:"threshold"`
+}
+
+// GetConfirmationSettings returns the amount confirmation rule of the current user
+func (h *TransactionHandler) GetConfirmationSettings(c *gin.Context) {
+	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "User not authenticated")
+		return
+	}
+	if h.confirmationUC == nil {
+		xresponse.InternalServerError(c, "Amount confirmation not available")
+		return
+	}
+
+	settings, err := h.confirmationUC.GetSettings(userID)
+	if err != nil {
+		logger.Error("Failed to get confirmation settings", logger.String("user_id", userID), logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to get confirmation settings")
+		return
+	}
+
+	xresponse.Success(c, "Confirmation settings retrieved successfully", settings)
+}
+
+// UpdateConfirmationSettings sets the amount confirmation threshold of the current user
+func (h *TransactionHandler) UpdateConfirmationSettings(c *gin.Context) {
+	userID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "User not authenticated")
+		return
+	}
+	if h.confirmationUC == nil {
+		xresponse.InternalServerError(c, "Amount confirmation not available")
+		return
+	}
+
+	var req UpdateConfirmationSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	h.roleGuard.LogAccess(c, "update_confirmation_settings", "own_account")
+
+	settings, err := h.confirmationUC.SetThreshold(userID, req.Threshold)
+	if err != nil {
+		switch {
+		case err.Error() == "threshold must not be negative":
+			xresponse.BadRequest(c, err.Error())
+		case err.Error() == "user not found":
+			xresponse.UserNotFound(c, "User account not found")
+		default:
+			logger.Error("Failed to update confirmation settings", logger.String("user_id", userID), logger.ErrorField(err))
+			xresponse.InternalServerError(c, "Failed to update confirmation settings")
+		}
+		return
+	}
+
+	xresponse.Success(c, "Confirmation settings updated successfully", settings)
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// UserPriceHandler handles per-user selling price overrides. The same
+// handlers serve admins and uplines; the use case limits uplines to their
+// direct downlines.
+type UserPriceHandler struct {
+	priceUC   domain.UserPriceUsecase
+	roleGuard *RoleGuard
+}
+
+// NewUserPriceHandler creates a new price override handler
+func NewUserPriceHandler(priceUC domain.UserPriceUsecase) *UserPriceHandler {
+	return &UserPriceHandler{
+		priceUC:   priceUC,
+		roleGuard: NewRoleGuard(),
+	}
+}
+
+// SetUserPriceRequest payload
+type SetUserPriceRequest struct {
+	Price float64 `json:"price" binding:"required,gt=0"`
+}
+
+// ListPrices lists the price overrides of a user
+func (h *UserPriceHandler) ListPrices(c *gin.Context) {
+	actorID, _, actorLevel, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	prices, err := h.priceUC.ListPrices(actorID, actorLevel, c.Param("id"))
+	if err != nil {
+		respondUserPriceError(c, err, "Failed to list user prices")
+		return
+	}
+
+	xresponse.Success(c, "User prices fetched", prices)
+}
+
+// SetPrice creates or replaces the price override of a user on a product
+func (h *UserPriceHandler) SetPrice(c *gin.Context) {
+	actorID, _, actorLevel, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req SetUserPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	userID := c.Param("id")
+	h.roleGuard.LogAccess(c, "set_user_price", userID)
+
+	price, err := h.priceUC.SetPrice(actorID, actorLevel, userID, c.Param("product_code"), req.Price)
+	if err != nil {
+		respondUserPriceError(c, err, "Failed to set user price")
+		return
+	}
+
+	xresponse.Success(c, "User price set", price)
+}
+
+// DeletePrice removes the price override of a user on a product
+func (h *UserPriceHandler) DeletePrice(c *gin.Context) {
+	actorID, _, actorLevel, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userID := c.Param("id")
+	productCode := c.Param("product_code")
+	h.roleGuard.LogAccess(c, "delete_user_price", userID)
+
+	if err := h.priceUC.DeletePrice(actorID, actorLevel, userID, productCode); err != nil {
+		respondUserPriceError(c, err, "Failed to delete user price")
+		return
+	}
+
+	xresponse.Success(c, "User price deleted", gin.H{"user_id": userID, "product_code": productCode})
+}
+
+// ListTierPrices lists the tier prices of a product
+func (h *UserPriceHandler) ListTierPrices(c *gin.Context) {
+	prices, err := h.priceUC.ListTierPrices(c.Param("product_code"))
+	if err != nil {
+		respondUserPriceError(c, err, "Failed to list tier prices")
+		return
+	}
+
+	xresponse.Success(c, "Tier prices fetched", prices)
+}
+
+// SetTierPrice creates or replaces the price of a product for every user of
+// the level in the path
+func (h *UserPriceHandler) SetTierPrice(c *gin.Context) {
+	adminID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	level, err := strconv.Atoi(c.Param("level"))
+	if err != nil {
+		xresponse.BadRequest(c, "invalid level")
+		return
+	}
+
+	var req SetUserPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	productCode := c.Param("product_code")
+	h.roleGuard.LogAccess(c, "set_tier_price", productCode)
+
+	price, err := h.priceUC.SetTierPrice(adminID, productCode, level, req.Price)
+	if err != nil {
+		respondUserPriceError(c, err, "Failed to set tier price")
+		return
+	}
+
+	xresponse.Success(c, "Tier price set", price)
+}
+
+// DeleteTierPrice removes the price of a product for the level in the path
+func (h *UserPriceHandler) DeleteTierPrice(c *gin.Context) {
+	adminID, _, _, exists := h.roleGuard.GetCurrentUser(c)
+	if !exists {
+		xresponse.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	level, err := strconv.Atoi(c.Param("level"))
+	if err != nil {
+		xresponse.BadRequest(c, "invalid level")
+		return
+	}
+
+	productCode := c.Param("product_code")
+	h.roleGuard.LogAccess(c, "delete_tier_price", productCode)
+
+	if err := h.priceUC.DeleteTierPrice(adminID, productCode, level); err != nil {
+		respondUserPriceError(c, err, "Failed to delete tier price")
+		return
+	}
+
+	xresponse.Success(c, "Tier price deleted", gin.H{"product_code": productCode, "level": level})
+}
+
+// respondUserPriceError maps price override errors to responses
+func respondUserPriceError(c *gin.Context, err error, failure string) {
+	switch err.Error() {
+	case "user not found", "product not found", "user product price not found", "product tier price not found":
+		xresponse.NotFound(c, err.Error())
+	case "prices can only be managed for your own downlines":
+		xresponse.Forbidden(c, err.Error())
+	case "price must be greater than zero", "price is below the product base price",
+		"price out of allowed range", "price is below your own price", "invalid level":
+		xresponse.BadRequest(c, err.Error())
+	default:
+		logger.Error(failure, logger.ErrorField(err))
+		xresponse.InternalServerError(c, failure)
+	}
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/xresponse"
+	"github.com/gin-gonic/gin"
+)
+
+// maxVoucherUploadBody bounds the multipart request; the use case enforces
+// the configured file size itself
+const maxVoucherUploadBody = 32 << 20
+
+// VoucherHandler handles the code stock of voucher products
+type VoucherHandler struct {
+	voucherUC domain.VoucherUsecase
+	roleGuard *RoleGuard
+}
+
+// NewVoucherHandler creates a new voucher handler
+func NewVoucherHandler(voucherUC domain.VoucherUsecase) *VoucherHandler {
+	return &VoucherHandler{
+		voucherUC: voucherUC,
+		roleGuard: NewRoleGuard(),
+	}
+}
+
+// UploadCodes adds the codes of a file sent as the multipart field "file",
+// one per line, to a product's stock. The optional form field expires_at
+// (RFC3339 or YYYY-MM-DD) applies to every code of the file.
+func (h *VoucherHandler) UploadCodes(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "upload_voucher_codes", "admin")
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxVoucherUploadBody)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		xresponse.BadRequest(c, "file is required")
+		return
+	}
+
+	upload := &domain.VoucherCodeUpload{ProductID: c.Param("id")}
+	if value := c.PostForm("expires_at"); value != "" {
+		expiresAt, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			expiresAt, err = time.Parse("2006-01-02", value)
+		}
+		if err != nil {
+			xresponse.BadRequest(c, "Invalid expires_at format. Use RFC3339 or YYYY-MM-DD")
+			return
+		}
+		upload.ExpiresAt = &expiresAt
+	}
+	if userID, _, _, exists := h.roleGuard.GetCurrentUser(c); exists && userID != "" {
+		upload.UploadedBy = &userID
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		logger.Error("Failed to open voucher code upload", logger.ErrorField(err))
+		xresponse.InternalServerError(c, "Failed to read voucher file")
+		return
+	}
+	defer file.Close()
+	upload.File = file
+
+	result, err := h.voucherUC.UploadCodes(upload)
+	if err != nil {
+		h.respondError(c, err, "Failed to upload voucher codes")
+		return
+	}
+
+	xresponse.Created(c, "Voucher codes uploaded", result)
+}
+
+// SetVoucherStockModeRequest payload
+type SetVoucherStockModeRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// SetStockMode switches a voucher product between the code stock and its
+// suppliers
+func (h *VoucherHandler) SetStockMode(c *gin.Context) {
+	h.roleGuard.LogAccess(c, "set_voucher_stock_mode", c.Param("id"))
+
+	var req SetVoucherStockModeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	stock, err := h.voucherUC.SetStockMode(c.Param("id"), *req.Enabled)
+	if err != nil {
+		h.respondError(c, err, "Failed to set voucher stock mode")
+		return
+	}
+
+	xresponse.Success(c, "Voucher stock mode updated", stock)
+}
+
+// GetStock counts the codes of a product by state
+func (h *VoucherHandler) GetStock(c *gin.Context) {
+	stock, err := h.voucherUC.GetStock(c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get voucher stock")
+		return
+	}
+
+	xresponse.Success(c, "Voucher stock fetched", stock)
+}
+
+func (h *VoucherHandler) respondError(c *gin.Context, err error, message string) {
+	msg := err.Error()
+	switch {
+	case msg == "product not found":
+		xresponse.NotFound(c, msg)
+	case msg == "product is not a stocked voucher", msg == "product is not a voucher",
+		msg == "expires_at must be in the future",
+		strings.HasPrefix(msg, "voucher file"), strings.HasPrefix(msg, "invalid voucher file"),
+		strings.HasPrefix(msg, "line "):
+		xresponse.BadRequest(c, msg)
+	case msg == "voucher code key is not configured":
+		xresponse.Error(c, http.StatusServiceUnavailable, xresponse.ErrCodeInternalError, msg)
+	default:
+		logger.Error(message, logger.ErrorField(err))
+		xresponse.InternalServerError(c, message)
+	}
+}
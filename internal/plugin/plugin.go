@@ -0,0 +1,38 @@
+// Package plugin collects the custom behaviors compiled into this build.
+// Operators add a file to this package whose init function registers their
+// hooks, so custom behavior needs no change to the core:
+//
+//	func init() {
+//		plugin.RegisterHook(&largeTransactionAlert{})
+//	}
+package plugin
+
+import (
+	"sync"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+)
+
+var (
+	mu    sync.Mutex
+	hooks []domain.EventHook
+)
+
+// RegisterHook adds an event hook. The hook is checked when the event relay
+// starts; an invalid hook stops startup.
+func RegisterHook(hook domain.EventHook) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	hooks = append(hooks, hook)
+}
+
+// Hooks returns the registered event hooks in registration order
+func Hooks() []domain.EventHook {
+	mu.Lock()
+	defer mu.Unlock()
+
+	registered := make([]domain.EventHook, len(hooks))
+	copy(registered, hooks)
+	return registered
+}
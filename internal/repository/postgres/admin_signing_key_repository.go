@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const adminSigningKeyColumns = `id, user_id, secret, created_at, last_used_at, revoked_at, revoked_by`
+
+type adminSigningKeyRepository struct {
+	db *sqlx.DB
+}
+
+// NewAdminSigningKeyRepository creates a new admin signing key repository
+func NewAdminSigningKeyRepository(db *sqlx.DB) domain.AdminSigningKeyRepository {
+	return &adminSigningKeyRepository{db: db}
+}
+
+// Rotate revokes the admin's active key and stores the new one atomically
+func (r *adminSigningKeyRepository) Rotate(key *domain.AdminSigningKey) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	revoke := `
+		UPDATE admin_signing_keys SET revoked_at = NOW(), revoked_by = $1
+		WHERE user_id = $1 AND revoked_at IS NULL`
+	if _, err := tx.Exec(revoke, key.UserID); err != nil {
+		return fmt.Errorf("failed to revoke admin signing key: %w", err)
+	}
+
+	insert := `
+		INSERT INTO admin_signing_keys (id, user_id, secret, created_at)
+		VALUES (:id, :user_id, :secret, :created_at)`
+	if _, err := tx.NamedExec(insert, key); err != nil {
+		logger.Error("Failed to create admin signing key",
+			logger.String("user_id", key.UserID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to create admin signing key: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logger.Info("Admin signing key created",
+		logger.String("key_id", key.ID),
+		logger.String("user_id", key.UserID),
+	)
+
+	return nil
+}
+
+// GetByID retrieves an admin signing key by ID
+func (r *adminSigningKeyRepository) GetByID(id string) (*domain.AdminSigningKey, error) {
+	query := `SELECT ` + adminSigningKeyColumns + ` FROM admin_signing_keys WHERE id = $1`
+
+	var key domain.AdminSigningKey
+	if err := r.db.Get(&key, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("admin signing key not found")
+		}
+		return nil, fmt.Errorf("failed to get admin signing key: %w", err)
+	}
+
+	return &key, nil
+}
+
+// ListActive lists the active keys of every admin, newest first
+func (r *adminSigningKeyRepository) ListActive() ([]*domain.AdminSigningKey, error) {
+	query := `
+		SELECT ` + adminSigningKeyColumns + `
+		FROM admin_signing_keys
+		WHERE revoked_at IS NULL
+		ORDER BY created_at DESC`
+
+	var keys []*domain.AdminSigningKey
+	if err := r.db.Select(&keys, query); err != nil {
+		return nil, fmt.Errorf("failed to list admin signing keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Revoke revokes an active admin signing key
+func (r *adminSigningKeyRepository) Revoke(id, revokedBy string) error {
+	query := `
+		UPDATE admin_signing_keys SET revoked_at = NOW(), revoked_by = $2
+		WHERE id = $1 AND revoked_at IS NULL`
+
+	result, err := r.db.Exec(query, id, revokedBy)
+	if err != nil {
+		logger.Error("Failed to revoke admin signing key",
+			logger.String("key_id", id),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to revoke admin signing key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("admin signing key not found")
+	}
+
+	logger.Info("Admin signing key revoked",
+		logger.String("key_id", id),
+		logger.String("revoked_by", revokedBy),
+	)
+
+	return nil
+}
+
+// UpdateLastUsed records that the key signed a request
+func (r *adminSigningKeyRepository) UpdateLastUsed(id string) error {
+	if _, err := r.db.Exec(`UPDATE admin_signing_keys SET last_used_at = NOW() WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to update admin signing key usage: %w", err)
+	}
+	return nil
+}
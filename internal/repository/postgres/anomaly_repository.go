@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+)
+
+type anomalyRepository struct {
+	db *sqlx.DB
+}
+
+// NewAnomalyRepository creates a new transaction anomaly repository
+func NewAnomalyRepository(db *sqlx.DB) domain.AnomalyRepository {
+	return &anomalyRepository{db: db}
+}
+
+// GetOutcomeStats aggregates routed transactions per supplier and product
+func (r *anomalyRepository) GetOutcomeStats(start, end time.Time) ([]*domain.OutcomeStats, error) {
+	query := `
+		SELECT s.id AS supplier_id, s.code AS supplier_code,
+			t.product_id, t.product_code,
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE t.status = 'SUCCESS') AS success_count,
+			COUNT(*) FILTER (WHERE t.status IN ('FAILED', 'TIMEOUT')) AS failed_count,
+			COUNT(*) FILTER (WHERE t.status = 'PROCESSING') AS processing_count
+		FROM transactions t
+		JOIN suppliers s ON s.id = COALESCE(t.final_supplier_id, t.supplier_id)
+		WHERE t.created_at >= $1 AND t.created_at < $2
+		GROUP BY s.id, s.code, t.product_id, t.product_code
+		ORDER BY s.code, t.product_code
+	`
+
+	var stats []*domain.OutcomeStats
+	if err := r.db.Select(&stats, query, start, end); err != nil {
+		return nil, fmt.Errorf("failed to get transaction outcome stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// LastAlertAt looks the anomaly up in the outbox, so the alert cooldown holds
+// across replicas and restarts
+func (r *anomalyRepository) LastAlertAt(supplierID, key string) (*time.Time, error) {
+	query := `
+		SELECT MAX(created_at) FROM domain_events
+		WHERE aggregate_type = $1 AND aggregate_id = $2 AND event_type = $3
+			AND payload->>'key' = $4
+	`
+
+	var last *time.Time
+	err := r.db.Get(&last, query, domain.AggregateTypeSupplier, supplierID, domain.EventAnomalyDetected, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last anomaly alert: %w", err)
+	}
+
+	return last, nil
+}
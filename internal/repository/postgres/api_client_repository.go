@@ -3,8 +3,10 @@ package postgres
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"fmt"
+	"time"
+
+	"github.com/lib/pq"
 
 	"github.com/alfanzaky/eraflazz/internal/domain"
 )
@@ -21,25 +23,40 @@ func NewAPIClientRepository(db *sql.DB) *APIClientRepository {
 func (r *APIClientRepository) FindByClientID(ctx context.Context, clientID string) (*domain.APIClient, error) {
 	query := `
 		SELECT id, client_id, api_key, secret, ip_whitelist, is_active, 
-			   max_requests_per_minute, created_at, updated_at, last_used_at
+			   max_requests_per_minute, user_id, sync_failover_attempts, sync_failover_budget_ms, sandbox, quota_plan_id,
+			   created_at, updated_at, last_used_at, previous_secret, previous_secret_expires_at, scopes,
+			   response_include_fields, response_exclude_fields
 		FROM api_clients 
 		WHERE client_id = $1 AND is_active = true`
 
 	var client domain.APIClient
-	var ipWhitelistJSON []byte
 	var lastUsedAt sql.NullTime
+	var userID sql.NullString
+	var quotaPlanID sql.NullString
+	var previousSecret sql.NullString
+	var previousSecretExpiresAt sql.NullTime
 
 	err := r.db.QueryRowContext(ctx, query, clientID).Scan(
 		&client.ID,
 		&client.ClientID,
 		&client.APIKey,
 		&client.Secret,
-		&ipWhitelistJSON,
+		pq.Array(&client.IPWhitelist),
 		&client.IsActive,
 		&client.MaxRequestsPerMinute,
+		&userID,
+		&client.SyncFailoverAttempts,
+		&client.SyncFailoverBudgetMs,
+		&client.Sandbox,
+		&quotaPlanID,
 		&client.CreatedAt,
 		&client.UpdatedAt,
 		&lastUsedAt,
+		&previousSecret,
+		&previousSecretExpiresAt,
+		pq.Array(&client.Scopes),
+		pq.Array(&client.ResponseFields.Include),
+		pq.Array(&client.ResponseFields.Exclude),
 	)
 
 	if err != nil {
@@ -49,16 +66,21 @@ func (r *APIClientRepository) FindByClientID(ctx context.Context, clientID strin
 		return nil, err
 	}
 
-	// Parse IP whitelist JSON array
-	if len(ipWhitelistJSON) > 0 {
-		if err := json.Unmarshal(ipWhitelistJSON, &client.IPWhitelist); err != nil {
-			return nil, fmt.Errorf("failed to parse ip_whitelist: %w", err)
-		}
-	}
-
 	if lastUsedAt.Valid {
 		client.LastUsedAt = &lastUsedAt.Time
 	}
+	if userID.Valid {
+		client.UserID = &userID.String
+	}
+	if quotaPlanID.Valid {
+		client.QuotaPlanID = &quotaPlanID.String
+	}
+	if previousSecret.Valid {
+		client.PreviousSecret = previousSecret.String
+	}
+	if previousSecretExpiresAt.Valid {
+		client.PreviousSecretExpiresAt = &previousSecretExpiresAt.Time
+	}
 
 	return &client, nil
 }
@@ -67,25 +89,40 @@ func (r *APIClientRepository) FindByClientID(ctx context.Context, clientID strin
 func (r *APIClientRepository) FindByAPIKey(ctx context.Context, apiKey string) (*domain.APIClient, error) {
 	query := `
 		SELECT id, client_id, api_key, secret, ip_whitelist, is_active, 
-			   max_requests_per_minute, created_at, updated_at, last_used_at
+			   max_requests_per_minute, user_id, sync_failover_attempts, sync_failover_budget_ms, sandbox, quota_plan_id,
+			   created_at, updated_at, last_used_at, previous_secret, previous_secret_expires_at, scopes,
+			   response_include_fields, response_exclude_fields
 		FROM api_clients 
 		WHERE api_key = $1 AND is_active = true`
 
 	var client domain.APIClient
-	var ipWhitelistJSON []byte
 	var lastUsedAt sql.NullTime
+	var userID sql.NullString
+	var quotaPlanID sql.NullString
+	var previousSecret sql.NullString
+	var previousSecretExpiresAt sql.NullTime
 
 	err := r.db.QueryRowContext(ctx, query, apiKey).Scan(
 		&client.ID,
 		&client.ClientID,
 		&client.APIKey,
 		&client.Secret,
-		&ipWhitelistJSON,
+		pq.Array(&client.IPWhitelist),
 		&client.IsActive,
 		&client.MaxRequestsPerMinute,
+		&userID,
+		&client.SyncFailoverAttempts,
+		&client.SyncFailoverBudgetMs,
+		&client.Sandbox,
+		&quotaPlanID,
 		&client.CreatedAt,
 		&client.UpdatedAt,
 		&lastUsedAt,
+		&previousSecret,
+		&previousSecretExpiresAt,
+		pq.Array(&client.Scopes),
+		pq.Array(&client.ResponseFields.Include),
+		pq.Array(&client.ResponseFields.Exclude),
 	)
 
 	if err != nil {
@@ -95,16 +132,21 @@ func (r *APIClientRepository) FindByAPIKey(ctx context.Context, apiKey string) (
 		return nil, err
 	}
 
-	// Parse IP whitelist JSON array
-	if len(ipWhitelistJSON) > 0 {
-		if err := json.Unmarshal(ipWhitelistJSON, &client.IPWhitelist); err != nil {
-			return nil, fmt.Errorf("failed to parse ip_whitelist: %w", err)
-		}
-	}
-
 	if lastUsedAt.Valid {
 		client.LastUsedAt = &lastUsedAt.Time
 	}
+	if userID.Valid {
+		client.UserID = &userID.String
+	}
+	if quotaPlanID.Valid {
+		client.QuotaPlanID = &quotaPlanID.String
+	}
+	if previousSecret.Valid {
+		client.PreviousSecret = previousSecret.String
+	}
+	if previousSecretExpiresAt.Valid {
+		client.PreviousSecretExpiresAt = &previousSecretExpiresAt.Time
+	}
 
 	return &client, nil
 }
@@ -117,25 +159,186 @@ func (r *APIClientRepository) UpdateLastUsed(ctx context.Context, clientID strin
 	return err
 }
 
+// RotateSecret replaces a client's secret and keeps the current one valid
+// until graceUntil
+func (r *APIClientRepository) RotateSecret(ctx context.Context, clientID, secret string, graceUntil time.Time) error {
+	query := `
+		UPDATE api_clients SET
+			previous_secret = secret, previous_secret_expires_at = $3, secret = $2
+		WHERE client_id = $1 AND is_active = true`
+
+	result, err := r.db.ExecContext(ctx, query, clientID, secret, graceUntil)
+	if err != nil {
+		return fmt.Errorf("failed to rotate api client secret: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to rotate api client secret: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("api client not found")
+	}
+	return nil
+}
+
+// UpdateScopes replaces the access scopes of a client
+func (r *APIClientRepository) UpdateScopes(ctx context.Context, clientID string, scopes []string) error {
+	query := `UPDATE api_clients SET scopes = $2 WHERE client_id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, clientID, pq.Array(scopes))
+	if err != nil {
+		return fmt.Errorf("failed to update api client scopes: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update api client scopes: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("api client not found")
+	}
+	return nil
+}
+
+// UpdateResponseFields replaces the response projection of a client
+func (r *APIClientRepository) UpdateResponseFields(ctx context.Context, clientID string, projection domain.ResponseProjection) error {
+	query := `UPDATE api_clients SET response_include_fields = $2, response_exclude_fields = $3 WHERE client_id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, clientID, pq.Array(projection.Include), pq.Array(projection.Exclude))
+	if err != nil {
+		return fmt.Errorf("failed to update api client response fields: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update api client response fields: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("api client not found")
+	}
+	return nil
+}
+
+// UpdateIPWhitelist replaces a client's IP whitelist and records the change
+// in the same transaction
+func (r *APIClientRepository) UpdateIPWhitelist(ctx context.Context, change *domain.APIClientIPChange) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var oldIPs []string
+	err = tx.QueryRowContext(ctx, `SELECT ip_whitelist FROM api_clients WHERE client_id = $1 FOR UPDATE`, change.ClientID).
+		Scan(pq.Array(&oldIPs))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("api client not found")
+		}
+		return fmt.Errorf("failed to get api client ip whitelist: %w", err)
+	}
+	if oldIPs == nil {
+		oldIPs = []string{}
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE api_clients SET ip_whitelist = $2 WHERE client_id = $1`,
+		change.ClientID, pq.Array(change.NewIPs)); err != nil {
+		return fmt.Errorf("failed to update api client ip whitelist: %w", err)
+	}
+
+	query := `
+		INSERT INTO api_client_ip_changes (client_id, old_ips, new_ips, actor_type, changed_by, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+	err = tx.QueryRowContext(ctx, query,
+		change.ClientID,
+		pq.Array(oldIPs),
+		pq.Array(change.NewIPs),
+		change.ActorType,
+		change.ChangedBy,
+		change.IPAddress,
+	).Scan(&change.ID, &change.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record api client ip whitelist change: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	change.OldIPs = oldIPs
+	return nil
+}
+
+// ListIPChanges lists the IP whitelist changes of a client, newest first
+func (r *APIClientRepository) ListIPChanges(ctx context.Context, clientID string, limit int) ([]*domain.APIClientIPChange, error) {
+	query := `
+		SELECT id, client_id, old_ips, new_ips, actor_type, changed_by, ip_address, created_at
+		FROM api_client_ip_changes
+		WHERE client_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, clientID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api client ip whitelist changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []*domain.APIClientIPChange{}
+	for rows.Next() {
+		var change domain.APIClientIPChange
+		var changedBy sql.NullString
+		if err := rows.Scan(
+			&change.ID,
+			&change.ClientID,
+			pq.Array(&change.OldIPs),
+			pq.Array(&change.NewIPs),
+			&change.ActorType,
+			&changedBy,
+			&change.IPAddress,
+			&change.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan api client ip whitelist change: %w", err)
+		}
+		if changedBy.Valid {
+			change.ChangedBy = &changedBy.String
+		}
+		changes = append(changes, &change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list api client ip whitelist changes: %w", err)
+	}
+
+	return changes, nil
+}
+
 // Create creates a new API client
 func (r *APIClientRepository) Create(ctx context.Context, client *domain.APIClient) error {
 	query := `
-		INSERT INTO api_clients (client_id, api_key, secret, ip_whitelist, is_active, max_requests_per_minute)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO api_clients (client_id, api_key, secret, ip_whitelist, is_active, max_requests_per_minute,
+			user_id, sync_failover_attempts, sync_failover_budget_ms, sandbox, quota_plan_id, scopes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id, created_at, updated_at`
 
-	ipWhitelistJSON, err := json.Marshal(client.IPWhitelist)
-	if err != nil {
-		return fmt.Errorf("failed to marshal ip_whitelist: %w", err)
+	if len(client.Scopes) == 0 {
+		client.Scopes = domain.DefaultH2HScopes()
+	}
+	if client.IPWhitelist == nil {
+		client.IPWhitelist = []string{}
 	}
 
-	err = r.db.QueryRowContext(ctx, query,
+	err := r.db.QueryRowContext(ctx, query,
 		client.ClientID,
 		client.APIKey,
 		client.Secret,
-		ipWhitelistJSON,
+		pq.Array(client.IPWhitelist),
 		client.IsActive,
 		client.MaxRequestsPerMinute,
+		client.UserID,
+		client.SyncFailoverAttempts,
+		client.SyncFailoverBudgetMs,
+		client.Sandbox,
+		client.QuotaPlanID,
+		pq.Array(client.Scopes),
 	).Scan(&client.ID, &client.CreatedAt, &client.UpdatedAt)
 
 	return err
@@ -145,25 +348,40 @@ func (r *APIClientRepository) Create(ctx context.Context, client *domain.APIClie
 func (r *APIClientRepository) FindByID(ctx context.Context, id string) (*domain.APIClient, error) {
 	query := `
 		SELECT id, client_id, api_key, secret, ip_whitelist, is_active, 
-			   max_requests_per_minute, created_at, updated_at, last_used_at
+			   max_requests_per_minute, user_id, sync_failover_attempts, sync_failover_budget_ms, sandbox, quota_plan_id,
+			   created_at, updated_at, last_used_at, previous_secret, previous_secret_expires_at, scopes,
+			   response_include_fields, response_exclude_fields
 		FROM api_clients 
 		WHERE id = $1`
 
 	var client domain.APIClient
-	var ipWhitelistJSON []byte
 	var lastUsedAt sql.NullTime
+	var userID sql.NullString
+	var quotaPlanID sql.NullString
+	var previousSecret sql.NullString
+	var previousSecretExpiresAt sql.NullTime
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&client.ID,
 		&client.ClientID,
 		&client.APIKey,
 		&client.Secret,
-		&ipWhitelistJSON,
+		pq.Array(&client.IPWhitelist),
 		&client.IsActive,
 		&client.MaxRequestsPerMinute,
+		&userID,
+		&client.SyncFailoverAttempts,
+		&client.SyncFailoverBudgetMs,
+		&client.Sandbox,
+		&quotaPlanID,
 		&client.CreatedAt,
 		&client.UpdatedAt,
 		&lastUsedAt,
+		&previousSecret,
+		&previousSecretExpiresAt,
+		pq.Array(&client.Scopes),
+		pq.Array(&client.ResponseFields.Include),
+		pq.Array(&client.ResponseFields.Exclude),
 	)
 
 	if err != nil {
@@ -173,16 +391,21 @@ func (r *APIClientRepository) FindByID(ctx context.Context, id string) (*domain.
 		return nil, err
 	}
 
-	// Parse IP whitelist JSON array
-	if len(ipWhitelistJSON) > 0 {
-		if err := json.Unmarshal(ipWhitelistJSON, &client.IPWhitelist); err != nil {
-			return nil, fmt.Errorf("failed to parse ip_whitelist: %w", err)
-		}
-	}
-
 	if lastUsedAt.Valid {
 		client.LastUsedAt = &lastUsedAt.Time
 	}
+	if userID.Valid {
+		client.UserID = &userID.String
+	}
+	if quotaPlanID.Valid {
+		client.QuotaPlanID = &quotaPlanID.String
+	}
+	if previousSecret.Valid {
+		client.PreviousSecret = previousSecret.String
+	}
+	if previousSecretExpiresAt.Valid {
+		client.PreviousSecretExpiresAt = &previousSecretExpiresAt.Time
+	}
 
 	return &client, nil
 }
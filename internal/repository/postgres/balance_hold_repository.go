@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const balanceHoldColumns = `
+	id, user_id, transaction_id, amount, status, created_at, updated_at, settled_at`
+
+type balanceHoldRepository struct {
+	db dbExecutor
+}
+
+// NewBalanceHoldRepository creates a new balance hold repository
+func NewBalanceHoldRepository(db *sqlx.DB) domain.BalanceHoldRepository {
+	return &balanceHoldRepository{db: db}
+}
+
+// Hold reserves balance for a transaction. The reservation only succeeds when
+// balance minus existing holds (plus credit limit for debt-enabled users)
+// covers the amount, so concurrent transactions cannot overspend.
+func (r *balanceHoldRepository) Hold(hold *domain.BalanceHold) error {
+	query := `
+		UPDATE users SET held_balance = held_balance + $2
+		WHERE id = $1
+			AND balance - held_balance + CASE WHEN allow_debt THEN credit_limit ELSE 0 END >= $2
+	`
+
+	result, err := r.db.Exec(query, hold.UserID, hold.Amount)
+	if err != nil {
+		logger.Error("Failed to reserve balance",
+			logger.String("user_id", hold.UserID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to reserve balance: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("insufficient balance")
+	}
+
+	hold.Status = domain.HoldStatusHeld
+	insert := `
+		INSERT INTO balance_holds (
+			id, user_id, transaction_id, amount, status, created_at, updated_at
+		) VALUES (
+			:id, :user_id, :transaction_id, :amount, :status, NOW(), NOW()
+		)`
+
+	if _, err := r.db.NamedExec(insert, hold); err != nil {
+		logger.Error("Failed to create balance hold",
+			logger.String("transaction_id", hold.TransactionID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to create balance hold: %w", err)
+	}
+
+	return nil
+}
+
+// GetByTransactionID retrieves the latest hold of a transaction, nil when there is none
+func (r *balanceHoldRepository) GetByTransactionID(transactionID string) (*domain.BalanceHold, error) {
+	query := `SELECT ` + balanceHoldColumns + ` FROM balance_holds WHERE transaction_id = $1 ORDER BY created_at DESC LIMIT 1`
+
+	var hold domain.BalanceHold
+	if err := r.db.Get(&hold, query, transactionID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get balance hold: %w", err)
+	}
+
+	return &hold, nil
+}
+
+// Capture settles an active hold and deducts its amount from the balance
+func (r *balanceHoldRepository) Capture(transactionID string) (*domain.BalanceHold, float64, error) {
+	hold, err := r.settle(transactionID, domain.HoldStatusCaptured)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		UPDATE users SET balance = balance - $2, held_balance = held_balance - $2
+		WHERE id = $1
+		RETURNING balance
+	`
+
+	var balanceAfter float64
+	if err := r.db.Get(&balanceAfter, query, hold.UserID, hold.Amount); err != nil {
+		return nil, 0, fmt.Errorf("failed to capture balance hold: %w", err)
+	}
+
+	return hold, balanceAfter, nil
+}
+
+// Release settles an active hold and returns the reserved amount to the available balance
+func (r *balanceHoldRepository) Release(transactionID string) (*domain.BalanceHold, error) {
+	hold, err := r.settle(transactionID, domain.HoldStatusReleased)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `UPDATE users SET held_balance = held_balance - $2 WHERE id = $1`
+	if _, err := r.db.Exec(query, hold.UserID, hold.Amount); err != nil {
+		return nil, fmt.Errorf("failed to release balance hold: %w", err)
+	}
+
+	return hold, nil
+}
+
+func (r *balanceHoldRepository) settle(transactionID, status string) (*domain.BalanceHold, error) {
+	query := `
+		UPDATE balance_holds SET
+			status = $3, settled_at = NOW(), updated_at = NOW()
+		WHERE transaction_id = $1 AND status = $2
+		RETURNING ` + balanceHoldColumns
+
+	var hold domain.BalanceHold
+	if err := r.db.Get(&hold, query, transactionID, domain.HoldStatusHeld, status); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("active balance hold not found")
+		}
+		return nil, fmt.Errorf("failed to settle balance hold: %w", err)
+	}
+
+	return &hold, nil
+}
@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const balanceTransferColumns = `
+	t.id, t.sender_id, s.username AS sender_username,
+	t.recipient_id, r.username AS recipient_username,
+	t.amount, t.note, t.created_at`
+
+type balanceTransferRepository struct {
+	db dbExecutor
+}
+
+// NewBalanceTransferRepository creates a new balance transfer repository
+func NewBalanceTransferRepository(db *sqlx.DB) domain.BalanceTransferRepository {
+	return &balanceTransferRepository{db: db}
+}
+
+// Create stores a balance transfer
+func (r *balanceTransferRepository) Create(transfer *domain.BalanceTransfer) error {
+	query := `
+		INSERT INTO balance_transfers (
+			id, sender_id, recipient_id, amount, note, created_at
+		) VALUES (
+			:id, :sender_id, :recipient_id, :amount, :note, NOW()
+		)`
+
+	if _, err := r.db.NamedExec(query, transfer); err != nil {
+		logger.Error("Failed to create balance transfer",
+			logger.String("sender_id", transfer.SenderID),
+			logger.String("recipient_id", transfer.RecipientID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to create balance transfer: %w", err)
+	}
+
+	return nil
+}
+
+// LockUsers locks the given user rows ordered by ID
+func (r *balanceTransferRepository) LockUsers(userIDs ...string) error {
+	ids := append([]string(nil), userIDs...)
+	sort.Strings(ids)
+
+	var locked []string
+	query := `SELECT id FROM users WHERE id = ANY($1) ORDER BY id FOR UPDATE`
+	if err := r.db.Select(&locked, query, pq.Array(ids)); err != nil {
+		return fmt.Errorf("failed to lock users: %w", err)
+	}
+	if len(locked) != len(ids) {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// SumSentSince totals the transfers sent by a user since the given time
+func (r *balanceTransferRepository) SumSentSince(senderID string, since time.Time) (float64, error) {
+	query := `SELECT COALESCE(SUM(amount), 0) FROM balance_transfers WHERE sender_id = $1 AND created_at >= $2`
+
+	var total float64
+	if err := r.db.Get(&total, query, senderID, since); err != nil {
+		return 0, fmt.Errorf("failed to sum sent transfers: %w", err)
+	}
+
+	return total, nil
+}
+
+// GetByUserAfter lists transfers of a user using keyset pagination
+func (r *balanceTransferRepository) GetByUserAfter(userID string, cursor *domain.Cursor, limit int) ([]*domain.BalanceTransfer, error) {
+	var (
+		transfers = make([]*domain.BalanceTransfer, 0)
+		err       error
+	)
+
+	base := `
+		SELECT ` + balanceTransferColumns + `
+		FROM balance_transfers t
+		JOIN users s ON s.id = t.sender_id
+		JOIN users r ON r.id = t.recipient_id
+		WHERE (t.sender_id = $1 OR t.recipient_id = $1)`
+
+	if cursor == nil {
+		query := base + `
+		ORDER BY t.created_at DESC, t.id DESC
+		LIMIT $2`
+		err = r.db.Select(&transfers, query, userID, limit)
+	} else {
+		query := base + ` AND (t.created_at, t.id) < ($2, $3)
+		ORDER BY t.created_at DESC, t.id DESC
+		LIMIT $4`
+		err = r.db.Select(&transfers, query, userID, cursor.CreatedAt, cursor.ID, limit)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance transfers: %w", err)
+	}
+
+	return transfers, nil
+}
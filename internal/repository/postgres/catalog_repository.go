@@ -0,0 +1,201 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const catalogEntryColumns = `code, name, icon_url, display_order, is_active, created_at, updated_at`
+
+type productCategoryRepository struct {
+	db *sqlx.DB
+}
+
+// NewProductCategoryRepository creates a new product category repository
+func NewProductCategoryRepository(db *sqlx.DB) domain.ProductCategoryRepository {
+	return &productCategoryRepository{db: db}
+}
+
+// Create creates a new product category
+func (r *productCategoryRepository) Create(category *domain.ProductCategory) error {
+	query := `
+		INSERT INTO product_categories (code, name, icon_url, display_order, is_active, created_at, updated_at)
+		VALUES (:code, :name, :icon_url, :display_order, :is_active, NOW(), NOW())`
+
+	if _, err := r.db.NamedExec(query, category); err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return fmt.Errorf("product category already exists")
+		}
+		logger.Error("Failed to create product category",
+			logger.String("code", category.Code),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to create product category: %w", err)
+	}
+
+	logger.Info("Product category created", logger.String("code", category.Code))
+
+	return nil
+}
+
+// GetByCode retrieves a product category by code
+func (r *productCategoryRepository) GetByCode(code string) (*domain.ProductCategory, error) {
+	query := `SELECT ` + catalogEntryColumns + ` FROM product_categories WHERE code = $1`
+
+	var category domain.ProductCategory
+	if err := r.db.Get(&category, query, code); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product category not found")
+		}
+		return nil, fmt.Errorf("failed to get product category: %w", err)
+	}
+
+	return &category, nil
+}
+
+// Update updates the display fields and state of a product category
+func (r *productCategoryRepository) Update(category *domain.ProductCategory) error {
+	query := `
+		UPDATE product_categories SET
+			name = :name, icon_url = :icon_url, display_order = :display_order,
+			is_active = :is_active, updated_at = NOW()
+		WHERE code = :code`
+
+	result, err := r.db.NamedExec(query, category)
+	if err != nil {
+		logger.Error("Failed to update product category",
+			logger.String("code", category.Code),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to update product category: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("product category not found")
+	}
+
+	return nil
+}
+
+// List lists product categories in display order
+func (r *productCategoryRepository) List(activeOnly bool) ([]*domain.ProductCategory, error) {
+	query := `
+		SELECT ` + catalogEntryColumns + `
+		FROM product_categories
+		WHERE (NOT $1 OR is_active = true)
+		ORDER BY display_order, code`
+
+	var categories []*domain.ProductCategory
+	if err := r.db.Select(&categories, query, activeOnly); err != nil {
+		return nil, fmt.Errorf("failed to list product categories: %w", err)
+	}
+
+	return categories, nil
+}
+
+type productProviderRepository struct {
+	db *sqlx.DB
+}
+
+// NewProductProviderRepository creates a new product provider repository
+func NewProductProviderRepository(db *sqlx.DB) domain.ProductProviderRepository {
+	return &productProviderRepository{db: db}
+}
+
+// Create creates a new product provider
+func (r *productProviderRepository) Create(provider *domain.ProductProvider) error {
+	query := `
+		INSERT INTO product_providers (code, name, icon_url, display_order, is_active, created_at, updated_at)
+		VALUES (:code, :name, :icon_url, :display_order, :is_active, NOW(), NOW())`
+
+	if _, err := r.db.NamedExec(query, provider); err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return fmt.Errorf("product provider already exists")
+		}
+		logger.Error("Failed to create product provider",
+			logger.String("code", provider.Code),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to create product provider: %w", err)
+	}
+
+	logger.Info("Product provider created", logger.String("code", provider.Code))
+
+	return nil
+}
+
+// GetByCode retrieves a product provider by code
+func (r *productProviderRepository) GetByCode(code string) (*domain.ProductProvider, error) {
+	query := `SELECT ` + catalogEntryColumns + ` FROM product_providers WHERE code = $1`
+
+	var provider domain.ProductProvider
+	if err := r.db.Get(&provider, query, code); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product provider not found")
+		}
+		return nil, fmt.Errorf("failed to get product provider: %w", err)
+	}
+
+	return &provider, nil
+}
+
+// Update updates the display fields and state of a product provider
+func (r *productProviderRepository) Update(provider *domain.ProductProvider) error {
+	query := `
+		UPDATE product_providers SET
+			name = :name, icon_url = :icon_url, display_order = :display_order,
+			is_active = :is_active, updated_at = NOW()
+		WHERE code = :code`
+
+	result, err := r.db.NamedExec(query, provider)
+	if err != nil {
+		logger.Error("Failed to update product provider",
+			logger.String("code", provider.Code),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to update product provider: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("product provider not found")
+	}
+
+	return nil
+}
+
+// List lists product providers in display order, optionally only the ones
+// with an active product in category
+func (r *productProviderRepository) List(activeOnly bool, category string) ([]*domain.ProductProvider, error) {
+	query := `
+		SELECT ` + catalogEntryColumns + `
+		FROM product_providers pp
+		WHERE (NOT $1 OR pp.is_active = true)
+			AND ($2 = '' OR EXISTS (
+				SELECT 1 FROM products p
+				WHERE p.provider = pp.code AND p.category = $2 AND p.is_active = true
+			))
+		ORDER BY pp.display_order, pp.code`
+
+	var providers []*domain.ProductProvider
+	if err := r.db.Select(&providers, query, activeOnly, category); err != nil {
+		return nil, fmt.Errorf("failed to list product providers: %w", err)
+	}
+
+	return providers, nil
+}
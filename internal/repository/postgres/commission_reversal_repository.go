@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const commissionReversalColumns = `
+	id, transaction_id, user_id, commission_mutation_id, reversal_mutation_id,
+	commission_amount, reversed_amount, shortfall, created_at`
+
+type commissionReversalRepository struct {
+	db dbExecutor
+}
+
+// NewCommissionReversalRepository creates a new commission reversal repository
+func NewCommissionReversalRepository(db *sqlx.DB) domain.CommissionReversalRepository {
+	return &commissionReversalRepository{db: db}
+}
+
+// Create stores a commission reversal; a commission can only be reversed once
+func (r *commissionReversalRepository) Create(reversal *domain.CommissionReversal) error {
+	query := `
+		INSERT INTO commission_reversals (` + commissionReversalColumns + `
+		) VALUES (
+			:id, :transaction_id, :user_id, :commission_mutation_id, :reversal_mutation_id,
+			:commission_amount, :reversed_amount, :shortfall, :created_at
+		)`
+
+	if _, err := r.db.NamedExec(query, reversal); err != nil {
+		logger.Error("Failed to create commission reversal",
+			logger.String("transaction_id", reversal.TransactionID),
+			logger.String("commission_mutation_id", reversal.CommissionMutationID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to create commission reversal: %w", err)
+	}
+
+	return nil
+}
+
+// ListByTransactionID returns the commission reversals of a transaction, oldest first
+func (r *commissionReversalRepository) ListByTransactionID(transactionID string) ([]*domain.CommissionReversal, error) {
+	query := `SELECT ` + commissionReversalColumns + `
+		FROM commission_reversals
+		WHERE transaction_id = $1
+		ORDER BY created_at ASC, id ASC`
+
+	reversals := make([]*domain.CommissionReversal, 0)
+	if err := r.db.Select(&reversals, query, transactionID); err != nil {
+		return nil, fmt.Errorf("failed to list commission reversals: %w", err)
+	}
+
+	return reversals, nil
+}
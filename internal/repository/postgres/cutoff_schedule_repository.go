@@ -0,0 +1,161 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const cutoffScheduleColumns = `
+	id, scope_type, scope_value, kind, start_time, end_time, starts_at, ends_at,
+	action, reason, is_active, created_by, created_at, updated_at`
+
+type cutoffScheduleRepository struct {
+	db *sqlx.DB
+}
+
+// NewCutoffScheduleRepository creates a new cutoff schedule repository
+func NewCutoffScheduleRepository(db *sqlx.DB) domain.CutoffScheduleRepository {
+	return &cutoffScheduleRepository{db: db}
+}
+
+// Create creates a new cutoff schedule
+func (r *cutoffScheduleRepository) Create(schedule *domain.CutoffSchedule) error {
+	query := `
+		INSERT INTO cutoff_schedules (
+			id, scope_type, scope_value, kind, start_time, end_time, starts_at, ends_at,
+			action, reason, is_active, created_by, created_at, updated_at
+		) VALUES (
+			:id, :scope_type, :scope_value, :kind, :start_time, :end_time, :starts_at, :ends_at,
+			:action, :reason, :is_active, :created_by, NOW(), NOW()
+		)`
+
+	if _, err := r.db.NamedExec(query, schedule); err != nil {
+		logger.Error("Failed to create cutoff schedule",
+			logger.String("scope_type", schedule.ScopeType),
+			logger.String("scope_value", schedule.ScopeValue),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to create cutoff schedule: %w", err)
+	}
+
+	logger.Info("Cutoff schedule created",
+		logger.String("schedule_id", schedule.ID),
+		logger.String("scope_type", schedule.ScopeType),
+		logger.String("scope_value", schedule.ScopeValue),
+		logger.String("kind", schedule.Kind),
+		logger.String("action", schedule.Action),
+	)
+
+	return nil
+}
+
+// GetByID retrieves a cutoff schedule by ID
+func (r *cutoffScheduleRepository) GetByID(id string) (*domain.CutoffSchedule, error) {
+	query := `SELECT ` + cutoffScheduleColumns + ` FROM cutoff_schedules WHERE id = $1`
+
+	var schedule domain.CutoffSchedule
+	if err := r.db.Get(&schedule, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("cutoff schedule not found")
+		}
+		return nil, fmt.Errorf("failed to get cutoff schedule: %w", err)
+	}
+
+	return &schedule, nil
+}
+
+// Update updates the window, action and state of a cutoff schedule
+func (r *cutoffScheduleRepository) Update(schedule *domain.CutoffSchedule) error {
+	query := `
+		UPDATE cutoff_schedules SET
+			kind = :kind, start_time = :start_time, end_time = :end_time,
+			starts_at = :starts_at, ends_at = :ends_at, action = :action,
+			reason = :reason, is_active = :is_active, updated_at = NOW()
+		WHERE id = :id
+	`
+
+	result, err := r.db.NamedExec(query, schedule)
+	if err != nil {
+		logger.Error("Failed to update cutoff schedule",
+			logger.String("schedule_id", schedule.ID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to update cutoff schedule: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("cutoff schedule not found")
+	}
+
+	return nil
+}
+
+// Delete removes a cutoff schedule
+func (r *cutoffScheduleRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM cutoff_schedules WHERE id = $1`, id)
+	if err != nil {
+		logger.Error("Failed to delete cutoff schedule",
+			logger.String("schedule_id", id),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to delete cutoff schedule: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("cutoff schedule not found")
+	}
+
+	return nil
+}
+
+// List lists cutoff schedules, optionally filtered by scope
+func (r *cutoffScheduleRepository) List(scopeType, scopeValue string) ([]*domain.CutoffSchedule, error) {
+	query := `
+		SELECT ` + cutoffScheduleColumns + `
+		FROM cutoff_schedules
+		WHERE ($1 = '' OR scope_type = $1)
+		AND ($2 = '' OR scope_value = $2)
+		ORDER BY scope_type, scope_value, kind, start_time, starts_at
+	`
+
+	var schedules []*domain.CutoffSchedule
+	if err := r.db.Select(&schedules, query, scopeType, scopeValue); err != nil {
+		logger.Error("Failed to list cutoff schedules", logger.ErrorField(err))
+		return nil, fmt.Errorf("failed to list cutoff schedules: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// ListActive returns active schedules whose window can still apply
+func (r *cutoffScheduleRepository) ListActive() ([]*domain.CutoffSchedule, error) {
+	query := `
+		SELECT ` + cutoffScheduleColumns + `
+		FROM cutoff_schedules
+		WHERE is_active = TRUE
+		AND (kind = $1 OR ends_at > NOW())
+	`
+
+	var schedules []*domain.CutoffSchedule
+	if err := r.db.Select(&schedules, query, domain.CutoffKindDaily); err != nil {
+		logger.Error("Failed to list active cutoff schedules", logger.ErrorField(err))
+		return nil, fmt.Errorf("failed to list active cutoff schedules: %w", err)
+	}
+
+	return schedules, nil
+}
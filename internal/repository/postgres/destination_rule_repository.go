@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const destinationRuleColumns = `
+	category, pattern, min_length, max_length, hint, normalize_phone, created_at, updated_at`
+
+type destinationRuleRepository struct {
+	db *sqlx.DB
+}
+
+// NewDestinationRuleRepository creates a new destination rule repository
+func NewDestinationRuleRepository(db *sqlx.DB) domain.DestinationRuleRepository {
+	return &destinationRuleRepository{db: db}
+}
+
+// GetByCategory retrieves the destination rule of a product category
+func (r *destinationRuleRepository) GetByCategory(category string) (*domain.DestinationRule, error) {
+	query := `SELECT ` + destinationRuleColumns + ` FROM destination_rules WHERE category = $1`
+
+	var rule domain.DestinationRule
+	if err := r.db.Get(&rule, query, category); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("destination rule not found")
+		}
+		return nil, fmt.Errorf("failed to get destination rule: %w", err)
+	}
+
+	return &rule, nil
+}
+
+// List lists all destination rules ordered by category
+func (r *destinationRuleRepository) List() ([]*domain.DestinationRule, error) {
+	query := `SELECT ` + destinationRuleColumns + ` FROM destination_rules ORDER BY category ASC`
+
+	var rules []*domain.DestinationRule
+	if err := r.db.Select(&rules, query); err != nil {
+		return nil, fmt.Errorf("failed to list destination rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// Upsert creates or replaces the destination rule of a category
+func (r *destinationRuleRepository) Upsert(rule *domain.DestinationRule) error {
+	query := `
+		INSERT INTO destination_rules (category, pattern, min_length, max_length, hint, normalize_phone)
+		VALUES (:category, :pattern, :min_length, :max_length, :hint, :normalize_phone)
+		ON CONFLICT (category) DO UPDATE SET
+			pattern = EXCLUDED.pattern,
+			min_length = EXCLUDED.min_length,
+			max_length = EXCLUDED.max_length,
+			hint = EXCLUDED.hint,
+			normalize_phone = EXCLUDED.normalize_phone
+	`
+
+	if _, err := r.db.NamedExec(query, rule); err != nil {
+		logger.Error("Failed to save destination rule",
+			logger.String("category", rule.Category),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to save destination rule: %w", err)
+	}
+
+	logger.Info("Destination rule saved", logger.String("category", rule.Category))
+
+	return nil
+}
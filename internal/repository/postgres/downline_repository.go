@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+)
+
+// downlineTreeCTE walks the downlines of $1 breadth-first, at most $2 levels deep
+const downlineTreeCTE = `
+	WITH RECURSIVE tree AS (
+		SELECT id, 1 AS depth FROM users WHERE upline_id = $1
+		UNION ALL
+		SELECT u.id, t.depth + 1 FROM users u
+		JOIN tree t ON u.upline_id = t.id
+		WHERE t.depth < $2
+	)`
+
+type downlineRepository struct {
+	db *sqlx.DB
+}
+
+// NewDownlineRepository creates a new downline hierarchy repository
+func NewDownlineRepository(db *sqlx.DB) domain.DownlineRepository {
+	return &downlineRepository{db: db}
+}
+
+// ListTree returns one page of the downline tree of rootID, shallowest first
+func (r *downlineRepository) ListTree(rootID string, maxDepth, limit, offset int) ([]*domain.DownlineNode, int, error) {
+	var total int
+	if err := r.db.Get(&total, downlineTreeCTE+` SELECT COUNT(*) FROM tree`, rootID, maxDepth); err != nil {
+		return nil, 0, fmt.Errorf("failed to count downlines: %w", err)
+	}
+
+	query := downlineTreeCTE + `
+		SELECT u.id, u.username, u.full_name, u.level, u.is_active, u.upline_id, t.depth,
+			(SELECT COUNT(*) FROM users c WHERE c.upline_id = u.id) AS direct_downlines,
+			u.created_at
+		FROM tree t
+		JOIN users u ON u.id = t.id
+		ORDER BY t.depth, u.created_at, u.id
+		LIMIT $3 OFFSET $4
+	`
+
+	var nodes []*domain.DownlineNode
+	if err := r.db.Select(&nodes, query, rootID, maxDepth, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list downline tree: %w", err)
+	}
+
+	return nodes, total, nil
+}
+
+// IsDownline walks up from userID looking for uplineID
+func (r *downlineRepository) IsDownline(uplineID, userID string, maxDepth int) (bool, error) {
+	query := `
+		WITH RECURSIVE chain AS (
+			SELECT id, upline_id, 1 AS depth FROM users WHERE id = $2
+			UNION ALL
+			SELECT u.id, u.upline_id, c.depth + 1 FROM users u
+			JOIN chain c ON u.id = c.upline_id
+			WHERE c.depth < $3
+		)
+		SELECT EXISTS (SELECT 1 FROM chain WHERE upline_id = $1)
+	`
+
+	var found bool
+	if err := r.db.Get(&found, query, uplineID, userID, maxDepth); err != nil {
+		return false, fmt.Errorf("failed to check downline: %w", err)
+	}
+	return found, nil
+}
+
+// GetLevelStats counts the downlines per level with their successful
+// transactions in [from, to)
+func (r *downlineRepository) GetLevelStats(rootID string, maxDepth int, from, to time.Time) ([]*domain.DownlineLevelStats, error) {
+	query := `
+		WITH RECURSIVE tree AS (
+			SELECT id, level, is_active, 1 AS depth FROM users WHERE upline_id = $1
+			UNION ALL
+			SELECT u.id, u.level, u.is_active, t.depth + 1 FROM users u
+			JOIN tree t ON u.upline_id = t.id
+			WHERE t.depth < $2
+		),
+		volume AS (
+			SELECT t.level, COUNT(*) AS transactions, SUM(x.selling_price + x.admin_fee) AS volume
+			FROM tree t
+			JOIN transactions x ON x.user_id = t.id
+			WHERE x.status = $3 AND x.created_at >= $4 AND x.created_at < $5
+			GROUP BY t.level
+		)
+		SELECT t.level,
+			COUNT(*) AS users,
+			COUNT(*) FILTER (WHERE t.is_active) AS active_users,
+			COALESCE(MAX(v.transactions), 0) AS transactions,
+			COALESCE(MAX(v.volume), 0) AS volume
+		FROM tree t
+		LEFT JOIN volume v ON v.level = t.level
+		GROUP BY t.level
+		ORDER BY t.level
+	`
+
+	var stats []*domain.DownlineLevelStats
+	if err := r.db.Select(&stats, query, rootID, maxDepth, domain.StatusSuccess, from, to); err != nil {
+		return nil, fmt.Errorf("failed to get downline level stats: %w", err)
+	}
+	return stats, nil
+}
+
+// GetCommissionTotal nets the commission and commission reversal mutations
+// of a user in [from, to)
+func (r *downlineRepository) GetCommissionTotal(userID string, from, to time.Time) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(CASE WHEN reference_type = $2 THEN amount ELSE -amount END), 0)
+		FROM mutations
+		WHERE user_id = $1
+			AND ((reference_type = $2 AND type = $3) OR (reference_type = $4 AND type = $5))
+			AND created_at >= $6 AND created_at < $7
+	`
+
+	var total float64
+	err := r.db.Get(&total, query, userID,
+		domain.ReferenceTypeCommission, domain.MutationTypeDebit,
+		domain.ReferenceTypeCommissionReversal, domain.MutationTypeCredit,
+		from, to,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get commission total: %w", err)
+	}
+	return total, nil
+}
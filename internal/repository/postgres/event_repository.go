@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const domainEventColumns = `
+	id, event_type, aggregate_type, aggregate_id, payload::text AS payload,
+	schema_version, status, attempts, last_error, next_attempt_at, published_at,
+	created_at, updated_at`
+
+type eventRepository struct {
+	db dbExecutor
+}
+
+// NewEventRepository creates a new outbox event repository instance
+func NewEventRepository(db *sqlx.DB) domain.EventRepository {
+	return &eventRepository{db: db}
+}
+
+// Create stores a new outbox event
+func (r *eventRepository) Create(event *domain.DomainEvent) error {
+	query := `
+		INSERT INTO domain_events (
+			id, event_type, aggregate_type, aggregate_id, payload, schema_version,
+			status, attempts, next_attempt_at, created_at, updated_at
+		) VALUES (
+			:id, :event_type, :aggregate_type, :aggregate_id, CAST(:payload AS JSONB), :schema_version,
+			:status, :attempts, :next_attempt_at, NOW(), NOW()
+		)`
+
+	_, err := r.db.NamedExec(query, event)
+	if err != nil {
+		logger.Error("Failed to create domain event",
+			logger.String("event_type", event.EventType),
+			logger.String("aggregate_id", event.AggregateID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to create domain event: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimPending claims up to limit due events for relaying. Claimed events are
+// leased by pushing next_attempt_at forward so concurrent relays skip them.
+func (r *eventRepository) ClaimPending(limit int, lease time.Duration) ([]*domain.DomainEvent, error) {
+	query := `
+		UPDATE domain_events SET
+			next_attempt_at = $3
+		WHERE id IN (
+			SELECT id FROM domain_events
+			WHERE status = $1 AND next_attempt_at <= NOW()
+			ORDER BY created_at ASC
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + domainEventColumns
+
+	var events []*domain.DomainEvent
+	err := r.db.Select(&events, query, domain.EventStatusPending, limit, time.Now().Add(lease))
+	if err != nil {
+		logger.Error("Failed to claim pending domain events", logger.ErrorField(err))
+		return nil, fmt.Errorf("failed to claim pending domain events: %w", err)
+	}
+
+	return events, nil
+}
+
+// MarkPublished marks an event as published
+func (r *eventRepository) MarkPublished(id string) error {
+	query := `
+		UPDATE domain_events SET
+			status = $2, attempts = attempts + 1, last_error = NULL, published_at = NOW()
+		WHERE id = $1
+	`
+	return r.exec("mark domain event published", query, id, domain.EventStatusPublished)
+}
+
+// ScheduleRetry records a failed publish attempt and schedules the next one
+func (r *eventRepository) ScheduleRetry(id, lastError string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE domain_events SET
+			attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+		WHERE id = $1
+	`
+	return r.exec("schedule domain event retry", query, id, lastError, nextAttemptAt)
+}
+
+// MarkFailed marks an event as permanently failed
+func (r *eventRepository) MarkFailed(id, lastError string) error {
+	query := `
+		UPDATE domain_events SET
+			status = $2, attempts = attempts + 1, last_error = $3
+		WHERE id = $1
+	`
+	return r.exec("mark domain event failed", query, id, domain.EventStatusFailed, lastError)
+}
+
+// GetByID returns one outbox event
+func (r *eventRepository) GetByID(id string) (*domain.DomainEvent, error) {
+	query := `SELECT ` + domainEventColumns + ` FROM domain_events WHERE id = $1`
+
+	var event domain.DomainEvent
+	if err := r.db.Get(&event, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("domain event not found")
+		}
+		return nil, fmt.Errorf("failed to get domain event: %w", err)
+	}
+	return &event, nil
+}
+
+// ListFailed lists permanently failed events, oldest first
+func (r *eventRepository) ListFailed(limit, offset int) ([]*domain.DomainEvent, error) {
+	query := `SELECT ` + domainEventColumns + ` FROM domain_events
+		WHERE status = $1
+		ORDER BY created_at ASC, id ASC
+		LIMIT $2 OFFSET $3`
+
+	var events []*domain.DomainEvent
+	if err := r.db.Select(&events, query, domain.EventStatusFailed, limit, offset); err != nil {
+		return nil, fmt.Errorf("failed to list failed domain events: %w", err)
+	}
+	return events, nil
+}
+
+// CountByStatus counts the events in a status
+func (r *eventRepository) CountByStatus(status string) (int, error) {
+	var count int
+	if err := r.db.Get(&count, `SELECT COUNT(*) FROM domain_events WHERE status = $1`, status); err != nil {
+		return 0, fmt.Errorf("failed to count domain events: %w", err)
+	}
+	return count, nil
+}
+
+// Requeue makes a failed event pending again with its attempts reset; the
+// last error is kept until the next attempt
+func (r *eventRepository) Requeue(id string) error {
+	query := `
+		UPDATE domain_events SET
+			status = $2, attempts = 0, next_attempt_at = NOW()
+		WHERE id = $1 AND status = $3
+	`
+	return r.exec("requeue domain event", query, id, domain.EventStatusPending, domain.EventStatusFailed)
+}
+
+func (r *eventRepository) exec(action, query string, args ...interface{}) error {
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		logger.Error("Failed to "+action, logger.ErrorField(err))
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("domain event not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const favoriteColumns = `
+	f.id, f.user_id, f.product_id, p.code AS product_code, p.name AS product_name,
+	f.destination_number, f.alias, f.created_at, f.updated_at`
+
+type favoriteRepository struct {
+	db *sqlx.DB
+}
+
+// NewFavoriteRepository creates a new favorite repository
+func NewFavoriteRepository(db *sqlx.DB) domain.FavoriteRepository {
+	return &favoriteRepository{db: db}
+}
+
+// Create creates a new favorite
+func (r *favoriteRepository) Create(favorite *domain.Favorite) error {
+	query := `
+		INSERT INTO user_favorites (
+			id, user_id, product_id, destination_number, alias, created_at, updated_at
+		) VALUES (
+			:id, :user_id, :product_id, :destination_number, :alias, NOW(), NOW()
+		)`
+
+	if _, err := r.db.NamedExec(query, favorite); err != nil {
+		logger.Error("Failed to create favorite",
+			logger.String("user_id", favorite.UserID),
+			logger.String("product_id", favorite.ProductID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to create favorite: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a favorite by ID
+func (r *favoriteRepository) GetByID(id string) (*domain.Favorite, error) {
+	query := `
+		SELECT ` + favoriteColumns + `
+		FROM user_favorites f
+		JOIN products p ON p.id = f.product_id
+		WHERE f.id = $1
+	`
+
+	var favorite domain.Favorite
+	if err := r.db.Get(&favorite, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("favorite not found")
+		}
+		return nil, fmt.Errorf("failed to get favorite: %w", err)
+	}
+
+	return &favorite, nil
+}
+
+// ListByUser lists the favorites of a user, newest first
+func (r *favoriteRepository) ListByUser(userID string) ([]*domain.Favorite, error) {
+	query := `
+		SELECT ` + favoriteColumns + `
+		FROM user_favorites f
+		JOIN products p ON p.id = f.product_id
+		WHERE f.user_id = $1
+		ORDER BY f.created_at DESC
+	`
+
+	favorites := make([]*domain.Favorite, 0)
+	if err := r.db.Select(&favorites, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to list favorites: %w", err)
+	}
+
+	return favorites, nil
+}
+
+// CountByUser counts the favorites of a user
+func (r *favoriteRepository) CountByUser(userID string) (int, error) {
+	var count int
+	if err := r.db.Get(&count, `SELECT COUNT(*) FROM user_favorites WHERE user_id = $1`, userID); err != nil {
+		return 0, fmt.Errorf("failed to count favorites: %w", err)
+	}
+
+	return count, nil
+}
+
+// Update updates the destination and alias of a favorite owned by its user
+func (r *favoriteRepository) Update(favorite *domain.Favorite) error {
+	query := `
+		UPDATE user_favorites SET
+			destination_number = $3, alias = $4, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+	`
+
+	result, err := r.db.Exec(query, favorite.ID, favorite.UserID, favorite.DestinationNumber, favorite.Alias)
+	if err != nil {
+		logger.Error("Failed to update favorite",
+			logger.String("favorite_id", favorite.ID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to update favorite: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("favorite not found")
+	}
+
+	return nil
+}
+
+// Delete removes a favorite owned by the given user
+func (r *favoriteRepository) Delete(id, userID string) error {
+	result, err := r.db.Exec(`DELETE FROM user_favorites WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		logger.Error("Failed to delete favorite",
+			logger.String("favorite_id", id),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to delete favorite: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("favorite not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const featureFlagColumns = `
+	key, environment, description, enabled, rollout_percent, user_ids,
+	updated_by, created_at, updated_at`
+
+// featureFlagRow scans the user ID array of a flag
+type featureFlagRow struct {
+	domain.FeatureFlag
+	UserIDs pq.StringArray `db:"user_ids"`
+}
+
+func (row *featureFlagRow) flag() *domain.FeatureFlag {
+	flag := row.FeatureFlag
+	flag.UserIDs = []string(row.UserIDs)
+	if flag.UserIDs == nil {
+		flag.UserIDs = []string{}
+	}
+	return &flag
+}
+
+type featureFlagRepository struct {
+	db *sqlx.DB
+}
+
+// NewFeatureFlagRepository creates a new feature flag repository
+func NewFeatureFlagRepository(db *sqlx.DB) domain.FeatureFlagRepository {
+	return &featureFlagRepository{db: db}
+}
+
+// Upsert creates the flag of its environment or replaces its settings
+func (r *featureFlagRepository) Upsert(flag *domain.FeatureFlag) error {
+	query := `
+		INSERT INTO feature_flags (
+			key, environment, description, enabled, rollout_percent, user_ids,
+			updated_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		ON CONFLICT (key, environment) DO UPDATE SET
+			description = EXCLUDED.description, enabled = EXCLUDED.enabled,
+			rollout_percent = EXCLUDED.rollout_percent, user_ids = EXCLUDED.user_ids,
+			updated_by = EXCLUDED.updated_by, updated_at = NOW()
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowx(query,
+		flag.Key, flag.Environment, flag.Description, flag.Enabled, flag.RolloutPercent,
+		pq.Array(flag.UserIDs), flag.UpdatedBy,
+	).Scan(&flag.CreatedAt, &flag.UpdatedAt)
+	if err != nil {
+		logger.Error("Failed to save feature flag",
+			logger.String("key", flag.Key),
+			logger.String("environment", flag.Environment),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to save feature flag: %w", err)
+	}
+
+	logger.Info("Feature flag saved",
+		logger.String("key", flag.Key),
+		logger.String("environment", flag.Environment),
+		logger.Bool("enabled", flag.Enabled),
+		logger.Int("rollout_percent", flag.RolloutPercent),
+		logger.Int("users", len(flag.UserIDs)),
+	)
+
+	return nil
+}
+
+// Get retrieves the flag of an environment
+func (r *featureFlagRepository) Get(key, environment string) (*domain.FeatureFlag, error) {
+	query := `SELECT ` + featureFlagColumns + ` FROM feature_flags WHERE key = $1 AND environment = $2`
+
+	var row featureFlagRow
+	if err := r.db.Get(&row, query, key, environment); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("feature flag not found")
+		}
+		return nil, fmt.Errorf("failed to get feature flag: %w", err)
+	}
+
+	return row.flag(), nil
+}
+
+// Delete removes the flag of an environment
+func (r *featureFlagRepository) Delete(key, environment string) error {
+	result, err := r.db.Exec(`DELETE FROM feature_flags WHERE key = $1 AND environment = $2`, key, environment)
+	if err != nil {
+		logger.Error("Failed to delete feature flag",
+			logger.String("key", key),
+			logger.String("environment", environment),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to delete feature flag: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("feature flag not found")
+	}
+
+	return nil
+}
+
+// ListByEnvironment lists the flags of an environment by key
+func (r *featureFlagRepository) ListByEnvironment(environment string) ([]*domain.FeatureFlag, error) {
+	query := `SELECT ` + featureFlagColumns + ` FROM feature_flags WHERE environment = $1 ORDER BY key`
+
+	var rows []*featureFlagRow
+	if err := r.db.Select(&rows, query, environment); err != nil {
+		logger.Error("Failed to list feature flags", logger.ErrorField(err))
+		return nil, fmt.Errorf("failed to list feature flags: %w", err)
+	}
+
+	flags := make([]*domain.FeatureFlag, len(rows))
+	for i, row := range rows {
+		flags[i] = row.flag()
+	}
+
+	return flags, nil
+}
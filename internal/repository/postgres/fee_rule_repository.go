@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const feeRuleColumns = `
+	id, name, category, user_level, fee_type, amount, min_fee, max_fee,
+	priority, is_active, created_by, created_at, updated_at`
+
+type feeRuleRepository struct {
+	db *sqlx.DB
+}
+
+// NewFeeRuleRepository creates a new fee rule repository
+func NewFeeRuleRepository(db *sqlx.DB) domain.FeeRuleRepository {
+	return &feeRuleRepository{db: db}
+}
+
+// Create creates a new fee rule
+func (r *feeRuleRepository) Create(rule *domain.FeeRule) error {
+	query := `
+		INSERT INTO fee_rules (
+			id, name, category, user_level, fee_type, amount, min_fee, max_fee,
+			priority, is_active, created_by, created_at, updated_at
+		) VALUES (
+			:id, :name, :category, :user_level, :fee_type, :amount, :min_fee, :max_fee,
+			:priority, :is_active, :created_by, NOW(), NOW()
+		)`
+
+	_, err := r.db.NamedExec(query, rule)
+	if err != nil {
+		logger.Error("Failed to create fee rule",
+			logger.String("name", rule.Name),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to create fee rule: %w", err)
+	}
+
+	logger.Info("Fee rule created",
+		logger.String("rule_id", rule.ID),
+		logger.String("name", rule.Name),
+		logger.String("fee_type", rule.FeeType),
+		logger.Float64("amount", rule.Amount),
+	)
+
+	return nil
+}
+
+// GetByID retrieves a fee rule by ID
+func (r *feeRuleRepository) GetByID(id string) (*domain.FeeRule, error) {
+	query := `SELECT ` + feeRuleColumns + ` FROM fee_rules WHERE id = $1`
+
+	var rule domain.FeeRule
+	if err := r.db.Get(&rule, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("fee rule not found")
+		}
+		return nil, fmt.Errorf("failed to get fee rule: %w", err)
+	}
+
+	return &rule, nil
+}
+
+// Update updates a fee rule
+func (r *feeRuleRepository) Update(rule *domain.FeeRule) error {
+	query := `
+		UPDATE fee_rules SET
+			name = :name, category = :category, user_level = :user_level,
+			fee_type = :fee_type, amount = :amount, min_fee = :min_fee, max_fee = :max_fee,
+			priority = :priority, is_active = :is_active, updated_at = NOW()
+		WHERE id = :id
+	`
+
+	result, err := r.db.NamedExec(query, rule)
+	if err != nil {
+		logger.Error("Failed to update fee rule",
+			logger.String("rule_id", rule.ID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to update fee rule: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("fee rule not found")
+	}
+
+	return nil
+}
+
+// Delete removes a fee rule
+func (r *feeRuleRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM fee_rules WHERE id = $1`, id)
+	if err != nil {
+		logger.Error("Failed to delete fee rule",
+			logger.String("rule_id", id),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to delete fee rule: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("fee rule not found")
+	}
+
+	return nil
+}
+
+// List lists fee rules, optionally only the active ones
+func (r *feeRuleRepository) List(activeOnly bool) ([]*domain.FeeRule, error) {
+	query := `
+		SELECT ` + feeRuleColumns + `
+		FROM fee_rules
+		WHERE ($1 = FALSE OR is_active = TRUE)
+		ORDER BY category NULLS LAST, user_level NULLS LAST, priority DESC, created_at
+	`
+
+	var rules []*domain.FeeRule
+	if err := r.db.Select(&rules, query, activeOnly); err != nil {
+		logger.Error("Failed to list fee rules", logger.ErrorField(err))
+		return nil, fmt.Errorf("failed to list fee rules: %w", err)
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const (
+	impersonationSessionColumns = `id, admin_id, user_id, reason, ip_address, expires_at, ended_at, ended_by, created_at`
+	impersonationActionColumns  = `id, session_id, method, path, route, status_code, ip_address, user_agent, created_at`
+)
+
+type impersonationRepository struct {
+	db *sqlx.DB
+}
+
+// NewImpersonationRepository creates a new impersonation audit repository
+func NewImpersonationRepository(db *sqlx.DB) domain.ImpersonationRepository {
+	return &impersonationRepository{db: db}
+}
+
+// CreateSession stores a new impersonation session
+func (r *impersonationRepository) CreateSession(session *domain.ImpersonationSession) error {
+	query := `
+		INSERT INTO impersonation_sessions (id, admin_id, user_id, reason, ip_address, expires_at, created_at)
+		VALUES (:id, :admin_id, :user_id, :reason, :ip_address, :expires_at, :created_at)`
+
+	if _, err := r.db.NamedExec(query, session); err != nil {
+		logger.Error("Failed to create impersonation session",
+			logger.String("admin_id", session.AdminID),
+			logger.String("user_id", session.UserID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to create impersonation session: %w", err)
+	}
+
+	return nil
+}
+
+// GetSession retrieves an impersonation session by ID
+func (r *impersonationRepository) GetSession(id string) (*domain.ImpersonationSession, error) {
+	query := `SELECT ` + impersonationSessionColumns + ` FROM impersonation_sessions WHERE id = $1`
+
+	var session domain.ImpersonationSession
+	if err := r.db.Get(&session, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("impersonation session not found")
+		}
+		return nil, fmt.Errorf("failed to get impersonation session: %w", err)
+	}
+
+	return &session, nil
+}
+
+// ListSessions lists impersonation sessions, newest first, with the total count
+func (r *impersonationRepository) ListSessions(filter *domain.ImpersonationSessionFilter) ([]*domain.ImpersonationSession, int, error) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	if filter.AdminID != nil {
+		args = append(args, *filter.AdminID)
+		conditions = append(conditions, fmt.Sprintf("admin_id = $%d", len(args)))
+	}
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.Get(&total, "SELECT COUNT(*) FROM impersonation_sessions"+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count impersonation sessions: %w", err)
+	}
+
+	query := "SELECT " + impersonationSessionColumns + " FROM impersonation_sessions" + where
+	args = append(args, filter.Limit, filter.Offset)
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	var sessions []*domain.ImpersonationSession
+	if err := r.db.Select(&sessions, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list impersonation sessions: %w", err)
+	}
+
+	return sessions, total, nil
+}
+
+// EndSession marks an active impersonation session as ended
+func (r *impersonationRepository) EndSession(id, endedBy string) error {
+	query := `
+		UPDATE impersonation_sessions SET ended_at = NOW(), ended_by = $2
+		WHERE id = $1 AND ended_at IS NULL`
+
+	result, err := r.db.Exec(query, id, endedBy)
+	if err != nil {
+		return fmt.Errorf("failed to end impersonation session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("impersonation session already ended")
+	}
+
+	return nil
+}
+
+// RecordAction stores one request made with an impersonation token
+func (r *impersonationRepository) RecordAction(action *domain.ImpersonationAction) error {
+	query := `
+		INSERT INTO impersonation_actions (` + impersonationActionColumns + `)
+		VALUES (:id, :session_id, :method, :path, :route, :status_code, :ip_address, :user_agent, :created_at)`
+
+	if _, err := r.db.NamedExec(query, action); err != nil {
+		return fmt.Errorf("failed to record impersonation action: %w", err)
+	}
+
+	return nil
+}
+
+// ListActions lists the requests of an impersonation session in order
+func (r *impersonationRepository) ListActions(sessionID string) ([]*domain.ImpersonationAction, error) {
+	query := `
+		SELECT ` + impersonationActionColumns + `
+		FROM impersonation_actions
+		WHERE session_id = $1
+		ORDER BY created_at`
+
+	var actions []*domain.ImpersonationAction
+	if err := r.db.Select(&actions, query, sessionID); err != nil {
+		return nil, fmt.Errorf("failed to list impersonation actions: %w", err)
+	}
+
+	return actions, nil
+}
@@ -0,0 +1,257 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+// sampleID stands in for user and product IDs in the explained queries
+const sampleID = "'00000000-0000-0000-0000-000000000000'"
+
+// hotQuery is a frequent query that must be served by an index. Queries use
+// literal sample values so they can be explained without arguments.
+type hotQuery struct {
+	name  string
+	table string // Table, or parent of the partitions, that must not be scanned sequentially
+	query string
+}
+
+var hotQueries = []hotQuery{
+	{
+		name:  "transactions by status and age",
+		table: "transactions",
+		query: `SELECT id FROM transactions WHERE status = 'PROCESSING' AND created_at <= NOW() - INTERVAL '30 minutes' ORDER BY created_at LIMIT 100`,
+	},
+	{
+		name:  "transaction by code",
+		table: "transactions",
+		query: `SELECT id FROM transactions WHERE trx_code = 'TRX-SAMPLE'`,
+	},
+	{
+		name:  "user transaction history",
+		table: "transactions",
+		query: `SELECT id FROM transactions WHERE user_id = ` + sampleID + ` AND created_at >= NOW() - INTERVAL '30 days' ORDER BY created_at DESC, id DESC LIMIT 50`,
+	},
+	{
+		name:  "duplicate order guard",
+		table: "transactions",
+		query: `SELECT id FROM transactions WHERE user_id = ` + sampleID + ` AND product_code = 'SAMPLE' AND destination_number = '0800000000' AND created_at >= NOW() - INTERVAL '5 minutes' ORDER BY created_at DESC LIMIT 1`,
+	},
+	{
+		name:  "account channel usage",
+		table: "transactions",
+		query: `SELECT COUNT(*) FROM transactions WHERE user_id = ` + sampleID + ` AND channel = 'H2H' AND created_at >= NOW() - INTERVAL '1 day'`,
+	},
+	{
+		name:  "user mutation history",
+		table: "mutations",
+		query: `SELECT id FROM mutations WHERE user_id = ` + sampleID + ` AND created_at >= NOW() - INTERVAL '30 days' ORDER BY created_at DESC, id DESC LIMIT 50`,
+	},
+	{
+		name:  "commission total",
+		table: "mutations",
+		query: `SELECT SUM(amount) FROM mutations WHERE user_id = ` + sampleID + ` AND reference_type IN ('COMMISSION', 'COMMISSION_REVERSAL') AND created_at >= NOW() - INTERVAL '30 days'`,
+	},
+	{
+		name:  "active product mappings",
+		table: "product_mappings",
+		query: `SELECT id FROM product_mappings WHERE product_id = ` + sampleID + ` AND is_active = TRUE ORDER BY priority`,
+	},
+}
+
+// requiredIndexes are the indexes the hot queries rely on
+var requiredIndexes = []string{
+	"idx_transactions_status_created",
+	"idx_transactions_trx_code",
+	"idx_transactions_user_created_id",
+	"idx_transactions_user_product_destination",
+	"idx_transactions_user_channel_created",
+	"idx_mutations_user_created_id",
+	"idx_mutations_user_reference_created",
+	"idx_product_mappings_product_active",
+}
+
+// IndexAdvisorConfig defines when the index advisor reports a query
+type IndexAdvisorConfig struct {
+	// MinRows skips sequential scans of tables estimated smaller than this;
+	// the planner rightly prefers them on small tables
+	MinRows int64
+	// Timeout bounds the whole check
+	Timeout time.Duration
+}
+
+// DefaultIndexAdvisorConfig returns default index advisor configuration
+func DefaultIndexAdvisorConfig() IndexAdvisorConfig {
+	return IndexAdvisorConfig{
+		MinRows: 10000,
+		Timeout: 30 * time.Second,
+	}
+}
+
+// IndexFinding is a required index that is missing or a hot query planned
+// with a sequential scan
+type IndexFinding struct {
+	Query    string
+	Relation string
+	Rows     int64
+	Reason   string
+}
+
+// IndexAdvisor checks at startup that the hot queries are served by indexes
+type IndexAdvisor struct {
+	db     *sqlx.DB
+	config IndexAdvisorConfig
+}
+
+// NewIndexAdvisor creates a new index advisor
+func NewIndexAdvisor(db *sqlx.DB, config IndexAdvisorConfig) *IndexAdvisor {
+	defaults := DefaultIndexAdvisorConfig()
+	if config.MinRows <= 0 {
+		config.MinRows = defaults.MinRows
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	return &IndexAdvisor{db: db, config: config}
+}
+
+// Run checks the indexes and logs a warning per finding. It never fails
+// startup.
+func (a *IndexAdvisor) Run() {
+	ctx, cancel := context.WithTimeout(context.Background(), a.config.Timeout)
+	defer cancel()
+
+	findings, err := a.Check(ctx)
+	if err != nil {
+		logger.Warn("Index check failed", logger.ErrorField(err))
+		return
+	}
+
+	for _, finding := range findings {
+		logger.Warn("Hot query without index support",
+			logger.String("query", finding.Query),
+			logger.String("relation", finding.Relation),
+			logger.Int64("estimated_rows", finding.Rows),
+			logger.String("reason", finding.Reason),
+		)
+	}
+	logger.Info("Index check completed",
+		logger.Int("queries", len(hotQueries)),
+		logger.Int("findings", len(findings)),
+	)
+}
+
+// Check reports the missing required indexes and the hot queries whose plan
+// scans a large relation sequentially
+func (a *IndexAdvisor) Check(ctx context.Context) ([]IndexFinding, error) {
+	findings, err := a.missingIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, hot := range hotQueries {
+		scans, err := a.sequentialScans(ctx, hot.query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to explain %q: %w", hot.name, err)
+		}
+
+		for _, relation := range scans {
+			if relation != hot.table && !strings.HasPrefix(relation, hot.table+"_") {
+				continue
+			}
+			rows, err := a.estimatedRows(ctx, relation)
+			if err != nil {
+				return nil, err
+			}
+			if rows < a.config.MinRows {
+				continue
+			}
+			findings = append(findings, IndexFinding{
+				Query:    hot.name,
+				Relation: relation,
+				Rows:     rows,
+				Reason:   "sequential scan",
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+// missingIndexes reports the required indexes that do not exist
+func (a *IndexAdvisor) missingIndexes(ctx context.Context) ([]IndexFinding, error) {
+	var existing []string
+	query := `SELECT indexname FROM pg_indexes WHERE schemaname = current_schema() AND indexname = ANY($1)`
+	if err := a.db.SelectContext(ctx, &existing, query, pq.Array(requiredIndexes)); err != nil {
+		return nil, fmt.Errorf("failed to list indexes: %w", err)
+	}
+
+	found := make(map[string]bool, len(existing))
+	for _, name := range existing {
+		found[name] = true
+	}
+
+	var findings []IndexFinding
+	for _, name := range requiredIndexes {
+		if !found[name] {
+			findings = append(findings, IndexFinding{Query: "required index", Relation: name, Reason: "index missing, are all migrations applied?"})
+		}
+	}
+	return findings, nil
+}
+
+// planNode is the part of an EXPLAIN (FORMAT JSON) plan node the advisor reads
+type planNode struct {
+	NodeType     string     `json:"Node Type"`
+	RelationName string     `json:"Relation Name"`
+	Plans        []planNode `json:"Plans"`
+}
+
+// sequentialScans returns the relations the query plan scans sequentially
+func (a *IndexAdvisor) sequentialScans(ctx context.Context, query string) ([]string, error) {
+	var raw string
+	if err := a.db.GetContext(ctx, &raw, "EXPLAIN (FORMAT JSON) "+query); err != nil {
+		return nil, err
+	}
+
+	var plans []struct {
+		Plan planNode `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(raw), &plans); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+
+	var relations []string
+	var walk func(node planNode)
+	walk = func(node planNode) {
+		if node.NodeType == "Seq Scan" && node.RelationName != "" {
+			relations = append(relations, node.RelationName)
+		}
+		for _, child := range node.Plans {
+			walk(child)
+		}
+	}
+	for _, plan := range plans {
+		walk(plan.Plan)
+	}
+	return relations, nil
+}
+
+// estimatedRows returns the planner's row estimate of a relation, 0 when it
+// was never analyzed
+func (a *IndexAdvisor) estimatedRows(ctx context.Context, relation string) (int64, error) {
+	var rows int64
+	query := `SELECT GREATEST(reltuples, 0)::BIGINT FROM pg_class WHERE oid = to_regclass($1)`
+	if err := a.db.GetContext(ctx, &rows, query, relation); err != nil {
+		return 0, fmt.Errorf("failed to estimate %s rows: %w", relation, err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const mappingReviewColumns = `
+	id, product_mapping_id, product_id, supplier_id, stale_code,
+	suggestions::text AS suggestions, status, resolved_code, reviewed_by, reviewed_at,
+	detected_at, updated_at`
+
+type mappingReviewRepository struct {
+	db *sqlx.DB
+}
+
+// NewMappingReviewRepository creates a new mapping review repository
+func NewMappingReviewRepository(db *sqlx.DB) domain.MappingReviewRepository {
+	return &mappingReviewRepository{db: db}
+}
+
+// Upsert opens a review for a stale mapping, or refreshes the suggestions of
+// the review that is already open for it
+func (r *mappingReviewRepository) Upsert(review *domain.MappingReview) error {
+	suggestions := review.Suggestions
+	if suggestions == nil {
+		suggestions = []domain.MappingCandidate{}
+	}
+	data, err := json.Marshal(suggestions)
+	if err != nil {
+		return fmt.Errorf("failed to encode mapping suggestions: %w", err)
+	}
+	review.SuggestionsJSON = string(data)
+
+	query := `
+		INSERT INTO mapping_reviews (
+			id, product_mapping_id, product_id, supplier_id, stale_code,
+			suggestions, status, detected_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, CAST($6 AS JSONB), $7, NOW(), NOW()
+		)
+		ON CONFLICT (product_mapping_id) WHERE status = 'OPEN' DO UPDATE SET
+			stale_code = EXCLUDED.stale_code,
+			suggestions = EXCLUDED.suggestions,
+			updated_at = NOW()
+		RETURNING id, detected_at, updated_at
+	`
+
+	err = r.db.QueryRowx(query,
+		review.ID, review.ProductMappingID, review.ProductID, review.SupplierID, review.StaleCode,
+		review.SuggestionsJSON, domain.MappingReviewOpen,
+	).Scan(&review.ID, &review.DetectedAt, &review.UpdatedAt)
+	if err != nil {
+		logger.Error("Failed to upsert mapping review",
+			logger.String("mapping_id", review.ProductMappingID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to upsert mapping review: %w", err)
+	}
+	review.Status = domain.MappingReviewOpen
+
+	return nil
+}
+
+// GetByID retrieves a mapping review by ID
+func (r *mappingReviewRepository) GetByID(id string) (*domain.MappingReview, error) {
+	query := `SELECT ` + mappingReviewColumns + ` FROM mapping_reviews WHERE id = $1`
+
+	var review domain.MappingReview
+	if err := r.db.Get(&review, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("mapping review not found")
+		}
+		return nil, fmt.Errorf("failed to get mapping review: %w", err)
+	}
+
+	if err := decodeMappingSuggestions(&review); err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// GetOpenByMappingID retrieves the open review of a mapping, nil when there is none
+func (r *mappingReviewRepository) GetOpenByMappingID(mappingID string) (*domain.MappingReview, error) {
+	query := `SELECT ` + mappingReviewColumns + ` FROM mapping_reviews WHERE product_mapping_id = $1 AND status = $2`
+
+	var review domain.MappingReview
+	if err := r.db.Get(&review, query, mappingID, domain.MappingReviewOpen); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get mapping review: %w", err)
+	}
+
+	if err := decodeMappingSuggestions(&review); err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// List returns mapping reviews, optionally filtered by status
+func (r *mappingReviewRepository) List(status string) ([]*domain.MappingReview, error) {
+	query := `SELECT ` + mappingReviewColumns + ` FROM mapping_reviews`
+	args := []interface{}{}
+	if status != "" {
+		query += ` WHERE status = $1`
+		args = append(args, status)
+	}
+	query += ` ORDER BY detected_at DESC`
+
+	var reviews []*domain.MappingReview
+	if err := r.db.Select(&reviews, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list mapping reviews: %w", err)
+	}
+
+	for _, review := range reviews {
+		if err := decodeMappingSuggestions(review); err != nil {
+			return nil, err
+		}
+	}
+	return reviews, nil
+}
+
+// UpdateStatus closes a review
+func (r *mappingReviewRepository) UpdateStatus(id, status string, resolvedCode, reviewedBy *string) error {
+	query := `
+		UPDATE mapping_reviews SET
+			status = $2, resolved_code = $3, reviewed_by = $4, reviewed_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`
+
+	result, err := r.db.Exec(query, id, status, resolvedCode, reviewedBy)
+	if err != nil {
+		return fmt.Errorf("failed to update mapping review: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("mapping review not found")
+	}
+
+	return nil
+}
+
+func decodeMappingSuggestions(review *domain.MappingReview) error {
+	review.Suggestions = []domain.MappingCandidate{}
+	if review.SuggestionsJSON == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(review.SuggestionsJSON), &review.Suggestions); err != nil {
+		return fmt.Errorf("failed to decode mapping suggestions: %w", err)
+	}
+	return nil
+}
@@ -11,7 +11,7 @@ import (
 )
 
 type mutationRepository struct {
-	db *sqlx.DB
+	db dbExecutor
 }
 
 // NewMutationRepository creates a new mutation repository instance
@@ -24,11 +24,11 @@ func (r *mutationRepository) Create(mutation *domain.Mutation) error {
         INSERT INTO mutations (
             id, user_id, type, amount, balance_before, balance_after,
             reference_type, reference_id, description, notes,
-            created_by, ip_address, user_agent, created_at
+            actor_type, actor_name, created_by, ip_address, user_agent, created_at
         ) VALUES (
             :id, :user_id, :type, :amount, :balance_before, :balance_after,
             :reference_type, :reference_id, :description, :notes,
-            :created_by, :ip_address, :user_agent, NOW()
+            :actor_type, :actor_name, :created_by, :ip_address, :user_agent, NOW()
         )`
 
 	_, err := r.db.NamedExec(query, mutation)
@@ -53,15 +53,50 @@ func (r *mutationRepository) GetByID(id string) (*domain.Mutation, error) {
 	return &mutation, nil
 }
 
-func (r *mutationRepository) GetByUserID(userID string, limit, offset int) ([]*domain.Mutation, error) {
+func (r *mutationRepository) GetByUserID(userID string, period domain.DateRange, limit, offset int) ([]*domain.Mutation, error) {
+	if period.IsZero() {
+		return nil, fmt.Errorf("date range is required")
+	}
+
 	query := `
         SELECT * FROM mutations
-        WHERE user_id = $1
+        WHERE user_id = $1 AND created_at BETWEEN $2 AND $3
         ORDER BY created_at DESC
-        LIMIT $2 OFFSET $3`
+        LIMIT $4 OFFSET $5`
 
 	var mutations []*domain.Mutation
-	err := r.db.Select(&mutations, query, userID, limit, offset)
+	err := r.db.Select(&mutations, query, userID, period.From, period.To, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user mutations: %w", err)
+	}
+	return mutations, nil
+}
+
+func (r *mutationRepository) GetByUserIDAfter(userID string, period domain.DateRange, cursor *domain.Cursor, limit int) ([]*domain.Mutation, error) {
+	if period.IsZero() {
+		return nil, fmt.Errorf("date range is required")
+	}
+
+	var (
+		mutations []*domain.Mutation
+		err       error
+	)
+
+	if cursor == nil {
+		query := `
+        SELECT * FROM mutations
+        WHERE user_id = $1 AND created_at BETWEEN $2 AND $3
+        ORDER BY created_at DESC, id DESC
+        LIMIT $4`
+		err = r.db.Select(&mutations, query, userID, period.From, period.To, limit)
+	} else {
+		query := `
+        SELECT * FROM mutations
+        WHERE user_id = $1 AND created_at BETWEEN $2 AND $3 AND (created_at, id) < ($4, $5)
+        ORDER BY created_at DESC, id DESC
+        LIMIT $6`
+		err = r.db.Select(&mutations, query, userID, period.From, period.To, cursor.CreatedAt, cursor.ID, limit)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user mutations: %w", err)
 	}
@@ -82,8 +117,8 @@ func (r *mutationRepository) GetByReference(referenceType, referenceID string) (
 	return mutations, nil
 }
 
-func (r *mutationRepository) GetBalanceHistory(userID string, limit, offset int) ([]*domain.Mutation, error) {
-	return r.GetByUserID(userID, limit, offset)
+func (r *mutationRepository) GetBalanceHistory(userID string, period domain.DateRange, limit, offset int) ([]*domain.Mutation, error) {
+	return r.GetByUserID(userID, period, limit, offset)
 }
 
 func (r *mutationRepository) GetCurrentBalance(userID string) (float64, error) {
@@ -104,3 +139,96 @@ func (r *mutationRepository) GetCurrentBalance(userID string) (float64, error) {
 	}
 	return balance, nil
 }
+
+// mutationFilterSQL builds the WHERE clause of a filtered mutation query
+func mutationFilterSQL(filter domain.MutationFilter) *sqlFilter {
+	where := newSQLFilter().
+		Where("user_id = ?", filter.UserID).
+		Where("created_at BETWEEN ? AND ?", filter.Period.From, filter.Period.To)
+
+	if filter.Type != "" {
+		where.Where("type = ?", filter.Type)
+	}
+	if filter.ReferenceType != "" {
+		where.Where("reference_type = ?", filter.ReferenceType)
+	}
+	if filter.MinAmount != nil {
+		where.Where("amount >= ?", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		where.Where("amount <= ?", *filter.MaxAmount)
+	}
+
+	return where
+}
+
+func (r *mutationRepository) List(filter domain.MutationFilter, limit, offset int) ([]*domain.Mutation, error) {
+	if filter.Period.IsZero() {
+		return nil, fmt.Errorf("date range is required")
+	}
+
+	where := mutationFilterSQL(filter)
+	clause, err := where.Clause()
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(`
+        SELECT * FROM mutations
+        WHERE %s
+        ORDER BY created_at DESC, id DESC
+        LIMIT %s OFFSET %s`, clause, where.Arg(limit), where.Arg(offset))
+
+	var mutations []*domain.Mutation
+	if err := r.db.Select(&mutations, query, where.Args()...); err != nil {
+		return nil, fmt.Errorf("failed to list mutations: %w", err)
+	}
+	return mutations, nil
+}
+
+func (r *mutationRepository) Count(filter domain.MutationFilter) (int, error) {
+	if filter.Period.IsZero() {
+		return 0, fmt.Errorf("date range is required")
+	}
+
+	where := mutationFilterSQL(filter)
+	clause, err := where.Clause()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := r.db.Get(&count, "SELECT COUNT(*) FROM mutations WHERE "+clause, where.Args()...); err != nil {
+		return 0, fmt.Errorf("failed to count mutations: %w", err)
+	}
+	return count, nil
+}
+
+func (r *mutationRepository) GetDailySummary(filter domain.MutationFilter, timezone string) ([]*domain.MutationDailySummary, error) {
+	if filter.Period.IsZero() {
+		return nil, fmt.Errorf("date range is required")
+	}
+
+	where := mutationFilterSQL(filter)
+	clause, err := where.Clause()
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(`
+        SELECT date_trunc('day', created_at AT TIME ZONE %s) AS day,
+            COUNT(*) FILTER (WHERE type = 'DEBIT') AS debit_count,
+            COUNT(*) FILTER (WHERE type = 'CREDIT') AS credit_count,
+            COALESCE(SUM(amount) FILTER (WHERE type = 'DEBIT'), 0) AS total_debit,
+            COALESCE(SUM(amount) FILTER (WHERE type = 'CREDIT'), 0) AS total_credit,
+            COALESCE(SUM(CASE WHEN type = 'DEBIT' THEN amount ELSE -amount END), 0) AS net_amount,
+            (array_agg(balance_after ORDER BY created_at DESC, id DESC))[1] AS closing_balance
+        FROM mutations
+        WHERE %s
+        GROUP BY 1
+        ORDER BY 1`, where.Arg(timezone), clause)
+
+	var summaries []*domain.MutationDailySummary
+	if err := r.db.Select(&summaries, query, where.Args()...); err != nil {
+		return nil, fmt.Errorf("failed to get daily mutation summary: %w", err)
+	}
+	return summaries, nil
+}
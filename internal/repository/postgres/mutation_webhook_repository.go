@@ -0,0 +1,318 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const mutationWebhookColumns = `
+	id, user_id, url, secret, include_downlines, is_active, created_at, updated_at`
+
+const mutationDeliveryColumns = `
+	id, webhook_id, user_id, status, mutation_count, attempts, last_error,
+	next_attempt_at, delivered_at, created_at, updated_at`
+
+type mutationWebhookRepository struct {
+	db *sqlx.DB
+}
+
+// NewMutationWebhookRepository creates a new mutation webhook repository
+func NewMutationWebhookRepository(db *sqlx.DB) domain.MutationWebhookRepository {
+	return &mutationWebhookRepository{db: db}
+}
+
+// Upsert creates the webhook of its user or replaces its settings
+func (r *mutationWebhookRepository) Upsert(webhook *domain.MutationWebhook) error {
+	query := `
+		INSERT INTO mutation_webhooks (
+			user_id, url, secret, include_downlines, is_active, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			url = EXCLUDED.url, secret = EXCLUDED.secret,
+			include_downlines = EXCLUDED.include_downlines, is_active = EXCLUDED.is_active,
+			updated_at = NOW()
+		RETURNING id, created_at, updated_at`
+
+	err := r.db.QueryRowx(query,
+		webhook.UserID, webhook.URL, webhook.Secret, webhook.IncludeDownlines, webhook.IsActive,
+	).Scan(&webhook.ID, &webhook.CreatedAt, &webhook.UpdatedAt)
+	if err != nil {
+		logger.Error("Failed to save mutation webhook",
+			logger.String("user_id", webhook.UserID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to save mutation webhook: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a webhook
+func (r *mutationWebhookRepository) GetByID(id string) (*domain.MutationWebhook, error) {
+	return r.get(`SELECT `+mutationWebhookColumns+` FROM mutation_webhooks WHERE id = $1`, id)
+}
+
+// GetByUserID retrieves the webhook of a user
+func (r *mutationWebhookRepository) GetByUserID(userID string) (*domain.MutationWebhook, error) {
+	return r.get(`SELECT `+mutationWebhookColumns+` FROM mutation_webhooks WHERE user_id = $1`, userID)
+}
+
+func (r *mutationWebhookRepository) get(query string, arg interface{}) (*domain.MutationWebhook, error) {
+	var webhook domain.MutationWebhook
+	if err := r.db.Get(&webhook, query, arg); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("mutation webhook not found")
+		}
+		return nil, fmt.Errorf("failed to get mutation webhook: %w", err)
+	}
+	return &webhook, nil
+}
+
+// Delete removes the webhook of a user; its queue and deliveries cascade
+func (r *mutationWebhookRepository) Delete(userID string) error {
+	result, err := r.db.Exec(`DELETE FROM mutation_webhooks WHERE user_id = $1`, userID)
+	if err != nil {
+		logger.Error("Failed to delete mutation webhook",
+			logger.String("user_id", userID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to delete mutation webhook: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("mutation webhook not found")
+	}
+
+	return nil
+}
+
+// Enqueue walks up from the mutation's user and queues the mutation for the
+// webhooks found on the way in one statement
+func (r *mutationWebhookRepository) Enqueue(entry *domain.MutationStatementEntry, maxDepth int) (int, error) {
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode mutation: %w", err)
+	}
+
+	query := `
+		WITH RECURSIVE chain AS (
+			SELECT id, upline_id, 0 AS depth FROM users WHERE id = $1
+			UNION ALL
+			SELECT u.id, u.upline_id, c.depth + 1 FROM users u
+			JOIN chain c ON u.id = c.upline_id
+			WHERE c.depth < $2
+		)
+		INSERT INTO mutation_webhook_items (webhook_id, mutation_id, payload, created_at)
+		SELECT w.id, $3, CAST($4 AS JSONB), $5
+		FROM chain c
+		JOIN mutation_webhooks w ON w.user_id = c.id
+		WHERE w.is_active AND (c.depth = 0 OR w.include_downlines)
+		ON CONFLICT (webhook_id, mutation_id) DO NOTHING
+	`
+
+	result, err := r.db.Exec(query, entry.UserID, maxDepth, entry.MutationID, string(payload), entry.OccurredAt)
+	if err != nil {
+		logger.Error("Failed to queue mutation for webhooks",
+			logger.String("mutation_id", entry.MutationID),
+			logger.ErrorField(err),
+		)
+		return 0, fmt.Errorf("failed to queue mutation for webhooks: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	return int(rowsAffected), nil
+}
+
+// CreateDeliveries splits the queue of each webhook into batches of
+// batchSize mutations, oldest first, and creates a pending delivery per batch
+func (r *mutationWebhookRepository) CreateDeliveries(batchSize int) (int, error) {
+	query := `
+		WITH queued AS (
+			SELECT id, webhook_id,
+				(ROW_NUMBER() OVER (PARTITION BY webhook_id ORDER BY created_at, id) - 1) / $1 AS batch
+			FROM mutation_webhook_items
+			WHERE delivery_id IS NULL
+		),
+		batches AS (
+			SELECT webhook_id, batch, gen_random_uuid() AS id, COUNT(*) AS mutation_count
+			FROM queued
+			GROUP BY webhook_id, batch
+		),
+		created AS (
+			INSERT INTO mutation_webhook_deliveries (id, webhook_id, user_id, status, mutation_count, next_attempt_at)
+			SELECT b.id, b.webhook_id, w.user_id, $2, b.mutation_count, NOW()
+			FROM batches b
+			JOIN mutation_webhooks w ON w.id = b.webhook_id
+			RETURNING id
+		)
+		UPDATE mutation_webhook_items i SET delivery_id = b.id
+		FROM queued q
+		JOIN batches b ON b.webhook_id = q.webhook_id AND b.batch = q.batch
+		WHERE i.id = q.id AND b.id IN (SELECT id FROM created)
+	`
+
+	result, err := r.db.Exec(query, batchSize, domain.MutationDeliveryPending)
+	if err != nil {
+		logger.Error("Failed to create mutation webhook deliveries", logger.ErrorField(err))
+		return 0, fmt.Errorf("failed to create mutation webhook deliveries: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	return int(rowsAffected), nil
+}
+
+// ClaimDue claims up to limit due deliveries. Claimed deliveries are leased
+// by pushing next_attempt_at forward so concurrent dispatchers skip them.
+func (r *mutationWebhookRepository) ClaimDue(limit int, lease time.Duration) ([]*domain.MutationWebhookDelivery, error) {
+	query := `
+		UPDATE mutation_webhook_deliveries SET
+			next_attempt_at = $3
+		WHERE id IN (
+			SELECT id FROM mutation_webhook_deliveries
+			WHERE status = $1 AND next_attempt_at <= NOW()
+			ORDER BY created_at ASC
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + mutationDeliveryColumns
+
+	var deliveries []*domain.MutationWebhookDelivery
+	err := r.db.Select(&deliveries, query, domain.MutationDeliveryPending, limit, time.Now().Add(lease))
+	if err != nil {
+		logger.Error("Failed to claim mutation webhook deliveries", logger.ErrorField(err))
+		return nil, fmt.Errorf("failed to claim mutation webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// ListEntries returns the mutations of a delivery, oldest first
+func (r *mutationWebhookRepository) ListEntries(deliveryID string) ([]*domain.MutationStatementEntry, error) {
+	query := `
+		SELECT payload::text FROM mutation_webhook_items
+		WHERE delivery_id = $1
+		ORDER BY created_at, id
+	`
+
+	var payloads []string
+	if err := r.db.Select(&payloads, query, deliveryID); err != nil {
+		return nil, fmt.Errorf("failed to list delivery mutations: %w", err)
+	}
+
+	entries := make([]*domain.MutationStatementEntry, len(payloads))
+	for i, payload := range payloads {
+		var entry domain.MutationStatementEntry
+		if err := json.Unmarshal([]byte(payload), &entry); err != nil {
+			return nil, fmt.Errorf("failed to decode delivery mutation: %w", err)
+		}
+		entries[i] = &entry
+	}
+
+	return entries, nil
+}
+
+// MarkDelivered marks a delivery as delivered
+func (r *mutationWebhookRepository) MarkDelivered(id string) error {
+	query := `
+		UPDATE mutation_webhook_deliveries SET
+			status = $2, attempts = attempts + 1, last_error = NULL, delivered_at = NOW()
+		WHERE id = $1
+	`
+	return r.exec("mark mutation webhook delivery delivered", query, id, domain.MutationDeliveryDelivered)
+}
+
+// ScheduleRetry records a failed attempt and schedules the next one
+func (r *mutationWebhookRepository) ScheduleRetry(id, lastError string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE mutation_webhook_deliveries SET
+			attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+		WHERE id = $1
+	`
+	return r.exec("schedule mutation webhook delivery retry", query, id, lastError, nextAttemptAt)
+}
+
+// MarkFailed marks a delivery as permanently failed
+func (r *mutationWebhookRepository) MarkFailed(id, lastError string) error {
+	query := `
+		UPDATE mutation_webhook_deliveries SET
+			status = $2, attempts = attempts + 1, last_error = $3
+		WHERE id = $1
+	`
+	return r.exec("mark mutation webhook delivery failed", query, id, domain.MutationDeliveryFailed, lastError)
+}
+
+// GetDelivery retrieves a delivery
+func (r *mutationWebhookRepository) GetDelivery(id string) (*domain.MutationWebhookDelivery, error) {
+	query := `SELECT ` + mutationDeliveryColumns + ` FROM mutation_webhook_deliveries WHERE id = $1`
+
+	var delivery domain.MutationWebhookDelivery
+	if err := r.db.Get(&delivery, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("mutation webhook delivery not found")
+		}
+		return nil, fmt.Errorf("failed to get mutation webhook delivery: %w", err)
+	}
+	return &delivery, nil
+}
+
+// ListDeliveries returns the latest deliveries of a webhook, newest first
+func (r *mutationWebhookRepository) ListDeliveries(webhookID string, limit int) ([]*domain.MutationWebhookDelivery, error) {
+	query := `SELECT ` + mutationDeliveryColumns + ` FROM mutation_webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2`
+
+	var deliveries []*domain.MutationWebhookDelivery
+	if err := r.db.Select(&deliveries, query, webhookID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list mutation webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// Requeue makes a failed delivery pending again with its attempts reset; the
+// last error is kept until the next attempt
+func (r *mutationWebhookRepository) Requeue(id string) error {
+	query := `
+		UPDATE mutation_webhook_deliveries SET
+			status = $2, attempts = 0, next_attempt_at = NOW()
+		WHERE id = $1 AND status = $3
+	`
+	return r.exec("requeue mutation webhook delivery", query, id, domain.MutationDeliveryPending, domain.MutationDeliveryFailed)
+}
+
+func (r *mutationWebhookRepository) exec(action, query string, args ...interface{}) error {
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		logger.Error("Failed to "+action, logger.ErrorField(err))
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("mutation webhook delivery not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const messageTemplateColumns = `
+	id, event_type, channel, subject, body, is_active,
+	updated_by, created_at, updated_at`
+
+type notificationPreferenceRepository struct {
+	db *sqlx.DB
+}
+
+// NewNotificationPreferenceRepository creates a new notification preference repository
+func NewNotificationPreferenceRepository(db *sqlx.DB) domain.NotificationPreferenceRepository {
+	return &notificationPreferenceRepository{db: db}
+}
+
+// GetByUser retrieves all stored preferences of a user
+func (r *notificationPreferenceRepository) GetByUser(userID string) ([]*domain.NotificationPreference, error) {
+	query := `
+		SELECT id, user_id, event_type, channel, is_enabled, created_at, updated_at
+		FROM notification_preferences
+		WHERE user_id = $1
+		ORDER BY event_type, channel
+	`
+
+	var prefs []*domain.NotificationPreference
+	if err := r.db.Select(&prefs, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+
+	return prefs, nil
+}
+
+// Upsert creates or updates the preference for the user, event and channel
+func (r *notificationPreferenceRepository) Upsert(pref *domain.NotificationPreference) error {
+	query := `
+		INSERT INTO notification_preferences (
+			id, user_id, event_type, channel, is_enabled, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, NOW(), NOW()
+		)
+		ON CONFLICT (user_id, event_type, channel) DO UPDATE SET
+			is_enabled = EXCLUDED.is_enabled, updated_at = NOW()
+		RETURNING id, created_at, updated_at
+	`
+
+	row := r.db.QueryRowx(query, pref.ID, pref.UserID, pref.EventType, pref.Channel, pref.IsEnabled)
+	if err := row.Scan(&pref.ID, &pref.CreatedAt, &pref.UpdatedAt); err != nil {
+		logger.Error("Failed to upsert notification preference",
+			logger.String("user_id", pref.UserID),
+			logger.String("event_type", pref.EventType),
+			logger.String("channel", pref.Channel),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to upsert notification preference: %w", err)
+	}
+
+	return nil
+}
+
+// ListEnabledUsers returns the users with a stored preference enabling the event
+func (r *notificationPreferenceRepository) ListEnabledUsers(eventType string) ([]string, error) {
+	query := `
+		SELECT DISTINCT user_id
+		FROM notification_preferences
+		WHERE event_type = $1 AND is_enabled = true
+		ORDER BY user_id
+	`
+
+	var userIDs []string
+	if err := r.db.Select(&userIDs, query, eventType); err != nil {
+		return nil, fmt.Errorf("failed to list notification subscribers: %w", err)
+	}
+
+	return userIDs, nil
+}
+
+type messageTemplateRepository struct {
+	db *sqlx.DB
+}
+
+// NewMessageTemplateRepository creates a new message template repository
+func NewMessageTemplateRepository(db *sqlx.DB) domain.MessageTemplateRepository {
+	return &messageTemplateRepository{db: db}
+}
+
+// GetByID retrieves a message template by ID
+func (r *messageTemplateRepository) GetByID(id string) (*domain.MessageTemplate, error) {
+	query := `SELECT ` + messageTemplateColumns + ` FROM message_templates WHERE id = $1`
+
+	var template domain.MessageTemplate
+	if err := r.db.Get(&template, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("message template not found")
+		}
+		return nil, fmt.Errorf("failed to get message template: %w", err)
+	}
+
+	return &template, nil
+}
+
+// GetActive retrieves the active template for an event and channel
+func (r *messageTemplateRepository) GetActive(eventType, channel string) (*domain.MessageTemplate, error) {
+	query := `SELECT ` + messageTemplateColumns + `
+		FROM message_templates
+		WHERE event_type = $1 AND channel = $2 AND is_active = true`
+
+	var template domain.MessageTemplate
+	if err := r.db.Get(&template, query, eventType, channel); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("message template not found")
+		}
+		return nil, fmt.Errorf("failed to get message template: %w", err)
+	}
+
+	return &template, nil
+}
+
+// List retrieves all message templates
+func (r *messageTemplateRepository) List() ([]*domain.MessageTemplate, error) {
+	query := `SELECT ` + messageTemplateColumns + ` FROM message_templates ORDER BY event_type, channel`
+
+	var templates []*domain.MessageTemplate
+	if err := r.db.Select(&templates, query); err != nil {
+		return nil, fmt.Errorf("failed to list message templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+// Update updates subject, body and status of a message template
+func (r *messageTemplateRepository) Update(template *domain.MessageTemplate) error {
+	query := `
+		UPDATE message_templates SET
+			subject = $2, body = $3, is_active = $4, updated_by = $5, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	result, err := r.db.Exec(query, template.ID, template.Subject, template.Body, template.IsActive, template.UpdatedBy)
+	if err != nil {
+		logger.Error("Failed to update message template",
+			logger.String("template_id", template.ID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to update message template: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("message template not found")
+	}
+
+	logger.Info("Message template updated",
+		logger.String("template_id", template.ID),
+		logger.String("event_type", template.EventType),
+		logger.String("channel", template.Channel),
+	)
+
+	return nil
+}
@@ -0,0 +1,253 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const outboxColumns = `
+	id, destination, recipient_number, recipient_name, subject, message, message_type,
+	user_id, transaction_id, source_event_id, status, retry_count, max_retries, sent_at,
+	delivery_report, external_id, scheduled_at, expires_at, priority,
+	created_by, created_at, updated_at`
+
+type outboxRepository struct {
+	db *sqlx.DB
+}
+
+// NewOutboxRepository creates a new outgoing message repository
+func NewOutboxRepository(db *sqlx.DB) domain.OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// Create queues a new outgoing message. Messages generated from a domain event
+// are inserted at most once per event, destination and message type.
+func (r *outboxRepository) Create(outbox *domain.Outbox) error {
+	query := `
+		INSERT INTO outbox (
+			id, destination, recipient_number, recipient_name, subject, message, message_type,
+			user_id, transaction_id, source_event_id, status, retry_count, max_retries,
+			scheduled_at, expires_at, priority, created_by, created_at, updated_at
+		) VALUES (
+			:id, :destination, :recipient_number, :recipient_name, :subject, :message, :message_type,
+			:user_id, :transaction_id, :source_event_id, :status, :retry_count, :max_retries,
+			:scheduled_at, :expires_at, :priority, :created_by, NOW(), NOW()
+		)
+		ON CONFLICT (source_event_id, destination, message_type) WHERE source_event_id IS NOT NULL DO NOTHING`
+
+	_, err := r.db.NamedExec(query, outbox)
+	if err != nil {
+		logger.Error("Failed to create outbox message",
+			logger.String("destination", outbox.Destination),
+			logger.String("message_type", outbox.MessageType),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to create outbox message: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an outgoing message by ID
+func (r *outboxRepository) GetByID(id string) (*domain.Outbox, error) {
+	query := `SELECT ` + outboxColumns + ` FROM outbox WHERE id = $1`
+
+	var outbox domain.Outbox
+	if err := r.db.Get(&outbox, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("outbox message not found")
+		}
+		return nil, fmt.Errorf("failed to get outbox message: %w", err)
+	}
+
+	return &outbox, nil
+}
+
+// Update updates sending state of an outgoing message
+func (r *outboxRepository) Update(outbox *domain.Outbox) error {
+	query := `
+		UPDATE outbox SET
+			status = :status, retry_count = :retry_count, sent_at = :sent_at,
+			delivery_report = :delivery_report, external_id = :external_id,
+			scheduled_at = :scheduled_at, updated_at = NOW()
+		WHERE id = :id`
+
+	result, err := r.db.NamedExec(query, outbox)
+	if err != nil {
+		return fmt.Errorf("failed to update outbox message: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("outbox message not found")
+	}
+
+	return nil
+}
+
+// GetByStatus retrieves outgoing messages by status
+func (r *outboxRepository) GetByStatus(status string) ([]*domain.Outbox, error) {
+	query := `SELECT ` + outboxColumns + ` FROM outbox WHERE status = $1 ORDER BY priority, scheduled_at`
+	return r.selectMessages(query, status)
+}
+
+// GetPendingMessages retrieves due pending messages and failed messages that can be retried
+func (r *outboxRepository) GetPendingMessages() ([]*domain.Outbox, error) {
+	query := `SELECT ` + outboxColumns + `
+		FROM outbox
+		WHERE (status = $1 OR (status = $2 AND retry_count < max_retries))
+		AND scheduled_at <= NOW()
+		AND (expires_at IS NULL OR expires_at > NOW())
+		ORDER BY priority, scheduled_at
+		LIMIT 100`
+	return r.selectMessages(query, domain.MessageStatusPending, domain.MessageStatusFailed)
+}
+
+// GetScheduledMessages retrieves pending messages scheduled in the future
+func (r *outboxRepository) GetScheduledMessages() ([]*domain.Outbox, error) {
+	query := `SELECT ` + outboxColumns + `
+		FROM outbox
+		WHERE status = $1 AND scheduled_at > NOW()
+		ORDER BY scheduled_at`
+	return r.selectMessages(query, domain.MessageStatusPending)
+}
+
+// GetExpiredMessages retrieves unsent messages past their expiry
+func (r *outboxRepository) GetExpiredMessages() ([]*domain.Outbox, error) {
+	query := `SELECT ` + outboxColumns + `
+		FROM outbox
+		WHERE status IN ($1, $2) AND expires_at IS NOT NULL AND expires_at <= NOW()
+		ORDER BY expires_at`
+	return r.selectMessages(query, domain.MessageStatusPending, domain.MessageStatusFailed)
+}
+
+// GetByUserID retrieves the latest outgoing messages of a user
+func (r *outboxRepository) GetByUserID(userID string, limit int) ([]*domain.Outbox, error) {
+	query := `SELECT ` + outboxColumns + ` FROM outbox WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2`
+	return r.selectMessages(query, userID, limit)
+}
+
+// MarkAsSent marks an outgoing message as sent
+func (r *outboxRepository) MarkAsSent(id string, externalID string) error {
+	query := `
+		UPDATE outbox SET
+			status = $2, external_id = $3, sent_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := r.db.Exec(query, id, domain.MessageStatusSent, externalID); err != nil {
+		return fmt.Errorf("failed to mark outbox message as sent: %w", err)
+	}
+
+	return nil
+}
+
+// MarkAsFailed marks an outgoing message as failed
+func (r *outboxRepository) MarkAsFailed(id string, deliveryReport string) error {
+	query := `
+		UPDATE outbox SET
+			status = $2, delivery_report = $3, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := r.db.Exec(query, id, domain.MessageStatusFailed, deliveryReport); err != nil {
+		return fmt.Errorf("failed to mark outbox message as failed: %w", err)
+	}
+
+	return nil
+}
+
+// IncrementRetryCount increments the retry counter of an outgoing message
+func (r *outboxRepository) IncrementRetryCount(id string) error {
+	query := `UPDATE outbox SET retry_count = retry_count + 1, updated_at = NOW() WHERE id = $1`
+
+	if _, err := r.db.Exec(query, id); err != nil {
+		return fmt.Errorf("failed to increment outbox retry count: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimPending claims up to limit due messages of a destination for delivery.
+// Claimed messages are leased by pushing scheduled_at forward so concurrent
+// dispatchers skip them.
+func (r *outboxRepository) ClaimPending(destination string, limit int, lease time.Duration) ([]*domain.Outbox, error) {
+	query := `
+		UPDATE outbox SET
+			status = $4, scheduled_at = $5, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE destination = $1
+			AND (status IN ($2, $4) OR (status = $3 AND retry_count < max_retries))
+			AND scheduled_at <= NOW()
+			AND (expires_at IS NULL OR expires_at > NOW())
+			ORDER BY priority, scheduled_at
+			LIMIT $6
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + outboxColumns
+
+	var messages []*domain.Outbox
+	err := r.db.Select(&messages, query,
+		destination, domain.MessageStatusPending, domain.MessageStatusFailed,
+		domain.MessageStatusSending, time.Now().Add(lease), limit,
+	)
+	if err != nil {
+		logger.Error("Failed to claim pending outbox messages",
+			logger.String("destination", destination),
+			logger.ErrorField(err),
+		)
+		return nil, fmt.Errorf("failed to claim pending outbox messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// ScheduleRetry records a failed delivery attempt and schedules the next one
+func (r *outboxRepository) ScheduleRetry(id, deliveryReport string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE outbox SET
+			status = $2, retry_count = retry_count + 1, delivery_report = $3,
+			scheduled_at = $4, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := r.db.Exec(query, id, domain.MessageStatusFailed, deliveryReport, nextAttemptAt); err != nil {
+		return fmt.Errorf("failed to schedule outbox retry: %w", err)
+	}
+
+	return nil
+}
+
+// MarkAsUndeliverable fails an outgoing message and exhausts its retries
+func (r *outboxRepository) MarkAsUndeliverable(id, deliveryReport string) error {
+	query := `
+		UPDATE outbox SET
+			status = $2, retry_count = GREATEST(retry_count + 1, max_retries),
+			delivery_report = $3, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := r.db.Exec(query, id, domain.MessageStatusFailed, deliveryReport); err != nil {
+		return fmt.Errorf("failed to mark outbox message as undeliverable: %w", err)
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) selectMessages(query string, args ...interface{}) ([]*domain.Outbox, error) {
+	var messages []*domain.Outbox
+	if err := r.db.Select(&messages, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get outbox messages: %w", err)
+	}
+	return messages, nil
+}
@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+)
+
+type partitionRepository struct {
+	db *sqlx.DB
+}
+
+// NewPartitionRepository creates a new partition repository
+func NewPartitionRepository(db *sqlx.DB) domain.PartitionRepository {
+	return &partitionRepository{db: db}
+}
+
+// CreateMonthlyPartition creates the monthly partition through
+// create_monthly_partition(), which also adds the per-partition indexes
+func (r *partitionRepository) CreateMonthlyPartition(table string, monthStart time.Time) (string, error) {
+	if !isPartitionedTable(table) {
+		return "", fmt.Errorf("unknown partitioned table %q", table)
+	}
+
+	var name string
+	query := `SELECT COALESCE(create_monthly_partition($1, $2::date), '')`
+	if err := r.db.Get(&name, query, table, monthStart.Format("2006-01-02")); err != nil {
+		return "", fmt.Errorf("failed to create %s partition: %w", table, err)
+	}
+	return name, nil
+}
+
+// ListMonthlyPartitions returns the partitions named <table>_YYYY_MM
+func (r *partitionRepository) ListMonthlyPartitions(table string) ([]*domain.TablePartition, error) {
+	if !isPartitionedTable(table) {
+		return nil, fmt.Errorf("unknown partitioned table %q", table)
+	}
+
+	query := `
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = $1::regclass
+		ORDER BY c.relname
+	`
+
+	var names []string
+	if err := r.db.Select(&names, query, table); err != nil {
+		return nil, fmt.Errorf("failed to list %s partitions: %w", table, err)
+	}
+
+	partitions := make([]*domain.TablePartition, 0, len(names))
+	for _, name := range names {
+		monthStart, ok := partitionMonth(table, name)
+		if !ok {
+			continue
+		}
+		partitions = append(partitions, &domain.TablePartition{Name: name, MonthStart: monthStart})
+	}
+	return partitions, nil
+}
+
+// DetachPartition detaches a monthly partition; the table and its rows stay.
+// The mutations of a detached mutations partition are added to the users'
+// ledger checkpoints in the same database transaction.
+func (r *partitionRepository) DetachPartition(table, partition string) error {
+	if !isPartitionedTable(table) {
+		return fmt.Errorf("unknown partitioned table %q", table)
+	}
+	if _, ok := partitionMonth(table, partition); !ok {
+		return fmt.Errorf("%q is not a monthly partition of %s", partition, table)
+	}
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s", pq.QuoteIdentifier(table), pq.QuoteIdentifier(partition))
+	if _, err := tx.Exec(query); err != nil {
+		return fmt.Errorf("failed to detach partition %s: %w", partition, err)
+	}
+	if table == domain.PartitionedTableMutations {
+		if err := foldLedgerCheckpoints(tx, partition); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// foldLedgerCheckpoints adds the mutations of a detached partition to the
+// users' opening balances. A partition is folded once; folding it again, e.g.
+// after it was attached back by hand, fails the detach.
+func foldLedgerCheckpoints(tx *sqlx.Tx, partition string) error {
+	fold := fmt.Sprintf(`
+		WITH detached AS (
+			SELECT user_id,
+				SUM(CASE WHEN type = 'DEBIT' THEN amount ELSE -amount END) AS balance,
+				COUNT(*) AS mutation_count
+			FROM %s
+			GROUP BY user_id
+		), folded AS (
+			INSERT INTO ledger_checkpoints (user_id, opening_balance, mutation_count)
+			SELECT user_id, balance, mutation_count FROM detached
+			ON CONFLICT (user_id) DO UPDATE SET
+				opening_balance = ledger_checkpoints.opening_balance + EXCLUDED.opening_balance,
+				mutation_count = ledger_checkpoints.mutation_count + EXCLUDED.mutation_count
+			RETURNING 1
+		)
+		INSERT INTO ledger_checkpoint_partitions (partition_name, users, mutation_count)
+		SELECT $1, (SELECT COUNT(*) FROM folded), COALESCE((SELECT SUM(mutation_count) FROM detached), 0)`,
+		pq.QuoteIdentifier(partition))
+
+	if _, err := tx.Exec(fold, partition); err != nil {
+		return fmt.Errorf("failed to fold partition %s into ledger checkpoints: %w", partition, err)
+	}
+	return nil
+}
+
+// CountDefaultRows counts the rows of <table>_default
+func (r *partitionRepository) CountDefaultRows(table string) (int64, error) {
+	if !isPartitionedTable(table) {
+		return 0, fmt.Errorf("unknown partitioned table %q", table)
+	}
+
+	var count int64
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", pq.QuoteIdentifier(table+"_default"))
+	if err := r.db.Get(&count, query); err != nil {
+		return 0, fmt.Errorf("failed to count %s default partition rows: %w", table, err)
+	}
+	return count, nil
+}
+
+func isPartitionedTable(table string) bool {
+	for _, t := range domain.PartitionedTables {
+		if t == table {
+			return true
+		}
+	}
+	return false
+}
+
+// partitionMonth parses the month of a partition named <table>_YYYY_MM
+func partitionMonth(table, name string) (time.Time, bool) {
+	suffix := strings.TrimPrefix(name, table+"_")
+	if suffix == name || len(suffix) != len("2006_01") {
+		return time.Time{}, false
+	}
+	monthStart, err := time.ParseInLocation("2006_01", suffix, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return monthStart, true
+}
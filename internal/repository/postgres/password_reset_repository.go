@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const passwordResetColumns = `id, user_id, token_hash, channel, requested_ip, expires_at, used_at, created_at`
+
+type passwordResetRepository struct {
+	db *sqlx.DB
+}
+
+// NewPasswordResetRepository creates a new password reset token repository
+func NewPasswordResetRepository(db *sqlx.DB) domain.PasswordResetRepository {
+	return &passwordResetRepository{db: db}
+}
+
+// Create stores a new password reset token
+func (r *passwordResetRepository) Create(token *domain.PasswordResetToken) error {
+	query := `
+		INSERT INTO password_reset_tokens (id, user_id, token_hash, channel, requested_ip, expires_at, created_at)
+		VALUES (:id, :user_id, :token_hash, :channel, :requested_ip, :expires_at, NOW())`
+
+	if _, err := r.db.NamedExec(query, token); err != nil {
+		logger.Error("Failed to create password reset token",
+			logger.String("user_id", token.UserID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to create password reset token: %w", err)
+	}
+
+	return nil
+}
+
+// Consume marks an unused, unexpired token as used. The conditional update
+// lets only one of several concurrent requests consume the token.
+func (r *passwordResetRepository) Consume(tokenHash string) (*domain.PasswordResetToken, error) {
+	query := `
+		UPDATE password_reset_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING ` + passwordResetColumns
+
+	var token domain.PasswordResetToken
+	if err := r.db.Get(&token, query, tokenHash); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("invalid or expired reset token")
+		}
+		return nil, fmt.Errorf("failed to consume password reset token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// InvalidateUser expires every unused token of the user
+func (r *passwordResetRepository) InvalidateUser(userID string) error {
+	query := `UPDATE password_reset_tokens SET expires_at = NOW() WHERE user_id = $1 AND used_at IS NULL AND expires_at > NOW()`
+
+	if _, err := r.db.Exec(query, userID); err != nil {
+		return fmt.Errorf("failed to invalidate password reset tokens: %w", err)
+	}
+
+	return nil
+}
+
+// CountSince counts the tokens issued to the user since the given time
+func (r *passwordResetRepository) CountSince(userID string, since time.Time) (int, error) {
+	query := `SELECT COUNT(*) FROM password_reset_tokens WHERE user_id = $1 AND created_at >= $2`
+
+	var count int
+	if err := r.db.Get(&count, query, userID, since); err != nil {
+		return 0, fmt.Errorf("failed to count password reset tokens: %w", err)
+	}
+
+	return count, nil
+}
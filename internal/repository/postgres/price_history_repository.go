@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+type priceHistoryRepository struct {
+	db *sqlx.DB
+}
+
+// NewPriceHistoryRepository creates a new price history repository
+func NewPriceHistoryRepository(db *sqlx.DB) domain.PriceHistoryRepository {
+	return &priceHistoryRepository{db: db}
+}
+
+// Create stores a price change
+func (r *priceHistoryRepository) Create(history *domain.PriceHistory) error {
+	query := `
+		INSERT INTO product_price_history (
+			id, product_id, product_mapping_id, supplier_id, price_type,
+			old_price, new_price, source, changed_by, created_at
+		) VALUES (
+			:id, :product_id, :product_mapping_id, :supplier_id, :price_type,
+			:old_price, :new_price, :source, :changed_by, NOW()
+		)`
+
+	_, err := r.db.NamedExec(query, history)
+	if err != nil {
+		logger.Error("Failed to create price history",
+			logger.String("product_id", history.ProductID),
+			logger.String("price_type", history.PriceType),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to create price history: %w", err)
+	}
+
+	return nil
+}
+
+// ListByProduct returns the latest price changes of a product
+func (r *priceHistoryRepository) ListByProduct(productID string, limit int) ([]*domain.PriceHistory, error) {
+	query := `
+		SELECT id, product_id, product_mapping_id, supplier_id, price_type,
+			old_price, new_price, source, changed_by, created_at
+		FROM product_price_history
+		WHERE product_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	var history []*domain.PriceHistory
+	if err := r.db.Select(&history, query, productID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list price history: %w", err)
+	}
+
+	return history, nil
+}
+
+// ListChanges lists price changes joined with their product, newest first
+func (r *priceHistoryRepository) ListChanges(filter *domain.PriceChangeFilter) ([]*domain.PriceChange, int, error) {
+	where := " WHERE h.price_type = ANY($1)"
+	args := []interface{}{pq.Array(filter.PriceTypes)}
+	argPos := 2
+
+	if !filter.Since.IsZero() {
+		where += fmt.Sprintf(" AND h.created_at >= $%d", argPos)
+		args = append(args, filter.Since)
+		argPos++
+	}
+	if !filter.Until.IsZero() {
+		where += fmt.Sprintf(" AND h.created_at < $%d", argPos)
+		args = append(args, filter.Until)
+		argPos++
+	}
+	if filter.Category != "" {
+		where += fmt.Sprintf(" AND p.category = $%d", argPos)
+		args = append(args, filter.Category)
+		argPos++
+	}
+	if filter.UserLevel > 0 {
+		where += fmt.Sprintf(" AND (p.min_level IS NULL OR p.min_level <= $%d)", argPos)
+		args = append(args, filter.UserLevel)
+		argPos++
+	}
+
+	from := " FROM product_price_history h JOIN products p ON p.id = h.product_id"
+
+	var total int
+	if err := r.db.Get(&total, "SELECT COUNT(*)"+from+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count price changes: %w", err)
+	}
+
+	query := `
+		SELECT h.id, h.product_id, p.code AS product_code, p.name AS product_name, p.category,
+			h.price_type, h.old_price, h.new_price, h.source, h.created_at` + from + where +
+		" ORDER BY h.created_at DESC, h.id"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argPos, argPos+1)
+		args = append(args, filter.Limit, filter.Offset)
+	}
+
+	var changes []*domain.PriceChange
+	if err := r.db.Select(&changes, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list price changes: %w", err)
+	}
+
+	return changes, total, nil
+}
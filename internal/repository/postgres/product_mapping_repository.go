@@ -2,6 +2,7 @@ package postgres
 
 import (
     "fmt"
+    "time"
 
     "github.com/jmoiron/sqlx"
 
@@ -123,3 +124,100 @@ func (r *productMappingRepository) GetBySupplierID(supplierID string) ([]*domain
     }
     return mappings, nil
 }
+
+func (r *productMappingRepository) GetAllActiveMappings() ([]*domain.ProductMapping, error) {
+    query := `SELECT * FROM product_mappings WHERE is_active = TRUE ORDER BY product_id, priority ASC`
+    var mappings []*domain.ProductMapping
+    if err := r.db.Select(&mappings, query); err != nil {
+        return nil, fmt.Errorf("failed to get active product mappings: %w", err)
+    }
+    return mappings, nil
+}
+
+// GetPerformanceSince aggregates finished transactions per product/supplier pair
+func (r *productMappingRepository) GetPerformanceSince(since time.Time) ([]*domain.MappingPerformance, error) {
+    query := `
+        SELECT
+            product_id,
+            supplier_id,
+            COUNT(*) AS total_count,
+            COUNT(*) FILTER (WHERE status = $2) AS success_count,
+            COALESCE(AVG(EXTRACT(EPOCH FROM (completed_at - processed_at)) * 1000)
+                FILTER (WHERE completed_at IS NOT NULL AND processed_at IS NOT NULL), 0) AS avg_latency_ms
+        FROM transactions
+        WHERE created_at >= $1
+            AND supplier_id IS NOT NULL
+            AND status IN ($2, $3, $4)
+        GROUP BY product_id, supplier_id`
+    var performances []*domain.MappingPerformance
+    if err := r.db.Select(&performances, query, since, domain.StatusSuccess, domain.StatusFailed, domain.StatusTimeout); err != nil {
+        return nil, fmt.Errorf("failed to get product mapping performance: %w", err)
+    }
+    return performances, nil
+}
+
+func (r *productMappingRepository) UpdateEffectivePriority(id string, effectivePriority *float64) error {
+    query := `
+        UPDATE product_mappings SET
+            effective_priority = $2,
+            priority_tuned_at = NOW()
+        WHERE id = $1`
+    result, err := r.db.Exec(query, id, effectivePriority)
+    if err != nil {
+        logger.Error("Failed to update effective priority",
+            logger.String("mapping_id", id),
+            logger.ErrorField(err),
+        )
+        return fmt.Errorf("failed to update effective priority: %w", err)
+    }
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("failed to check rows affected: %w", err)
+    }
+    if rowsAffected == 0 {
+        return fmt.Errorf("product mapping not found")
+    }
+    return nil
+}
+
+func (r *productMappingRepository) ReorderPriorities(productID string, mappingIDs []string) error {
+    tx, err := r.db.Beginx()
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer func() { _ = tx.Rollback() }()
+
+    // Lock the product's mappings so none is added or removed while reordering
+    var current []string
+    if err := tx.Select(&current, `SELECT id FROM product_mappings WHERE product_id = $1 FOR UPDATE`, productID); err != nil {
+        return fmt.Errorf("failed to lock product mappings: %w", err)
+    }
+    if len(current) != len(mappingIDs) {
+        return fmt.Errorf("mapping list must contain every mapping of the product exactly once")
+    }
+
+    for i, id := range mappingIDs {
+        result, err := tx.Exec(`
+            UPDATE product_mappings SET priority = $3, updated_at = NOW()
+            WHERE id = $1 AND product_id = $2`, id, productID, i+1)
+        if err != nil {
+            logger.Error("Failed to reorder product mapping",
+                logger.String("mapping_id", id),
+                logger.ErrorField(err),
+            )
+            return fmt.Errorf("failed to reorder product mappings: %w", err)
+        }
+        rowsAffected, err := result.RowsAffected()
+        if err != nil {
+            return fmt.Errorf("failed to check rows affected: %w", err)
+        }
+        if rowsAffected == 0 {
+            return fmt.Errorf("mapping list must contain every mapping of the product exactly once")
+        }
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("failed to commit mapping reorder: %w", err)
+    }
+    return nil
+}
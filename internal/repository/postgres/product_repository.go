@@ -25,8 +25,11 @@ func (r *productRepository) Create(product *domain.Product) error {
 		INSERT INTO products (id, code, name, description, category, provider, type,
 			base_price, selling_price, min_price, nominal, validity_period,
 			is_active, is_unlimited_stock, stock_quantity, allow_markup,
-			max_markup_percentage, min_transaction_amount, max_transaction_amount)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+			max_markup_percentage, min_transaction_amount, max_transaction_amount,
+			destination_pattern, destination_min_length, destination_max_length, destination_hint,
+			min_level)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19,
+			$20, $21, $22, $23, $24)
 	`
 
 	_, err := r.db.Exec(query,
@@ -35,7 +38,8 @@ func (r *productRepository) Create(product *domain.Product) error {
 		product.SellingPrice, product.MinPrice, product.Nominal, product.ValidityPeriod,
 		product.IsActive, product.IsUnlimitedStock, product.StockQuantity,
 		product.AllowMarkup, product.MaxMarkupPercentage, product.MinTransactionAmount,
-		product.MaxTransactionAmount,
+		product.MaxTransactionAmount, product.DestinationPattern, product.DestinationMinLength,
+		product.DestinationMaxLength, product.DestinationHint, product.MinLevel,
 	)
 
 	if err != nil {
@@ -59,8 +63,11 @@ func (r *productRepository) GetByID(id string) (*domain.Product, error) {
 	query := `
 		SELECT id, code, name, description, category, provider, type,
 			base_price, selling_price, min_price, nominal, validity_period,
-			is_active, is_unlimited_stock, stock_quantity, allow_markup,
+			is_active, is_unlimited_stock, stock_quantity, is_voucher_stock, allow_markup,
 			max_markup_percentage, min_transaction_amount, max_transaction_amount,
+			margin_flagged, margin_flagged_at, margin_flag_reason,
+			destination_pattern, destination_min_length, destination_max_length, destination_hint,
+			min_level,
 			created_at, updated_at
 		FROM products WHERE id = $1
 	`
@@ -86,8 +93,11 @@ func (r *productRepository) GetByCode(code string) (*domain.Product, error) {
 	query := `
 		SELECT id, code, name, description, category, provider, type,
 			base_price, selling_price, min_price, nominal, validity_period,
-			is_active, is_unlimited_stock, stock_quantity, allow_markup,
+			is_active, is_unlimited_stock, stock_quantity, is_voucher_stock, allow_markup,
 			max_markup_percentage, min_transaction_amount, max_transaction_amount,
+			margin_flagged, margin_flagged_at, margin_flag_reason,
+			destination_pattern, destination_min_length, destination_max_length, destination_hint,
+			min_level,
 			created_at, updated_at
 		FROM products WHERE code = $1
 	`
@@ -115,7 +125,9 @@ func (r *productRepository) Update(product *domain.Product) error {
 			code = $2, name = $3, description = $4, category = $5, provider = $6, type = $7,
 			base_price = $8, selling_price = $9, min_price = $10, nominal = $11, validity_period = $12,
 			is_active = $13, is_unlimited_stock = $14, stock_quantity = $15, allow_markup = $16,
-			max_markup_percentage = $17, min_transaction_amount = $18, max_transaction_amount = $19
+			max_markup_percentage = $17, min_transaction_amount = $18, max_transaction_amount = $19,
+			destination_pattern = $20, destination_min_length = $21, destination_max_length = $22,
+			destination_hint = $23, min_level = $24
 		WHERE id = $1
 	`
 
@@ -125,7 +137,8 @@ func (r *productRepository) Update(product *domain.Product) error {
 		product.SellingPrice, product.MinPrice, product.Nominal, product.ValidityPeriod,
 		product.IsActive, product.IsUnlimitedStock, product.StockQuantity,
 		product.AllowMarkup, product.MaxMarkupPercentage, product.MinTransactionAmount,
-		product.MaxTransactionAmount,
+		product.MaxTransactionAmount, product.DestinationPattern, product.DestinationMinLength,
+		product.DestinationMaxLength, product.DestinationHint, product.MinLevel,
 	)
 
 	if err != nil {
@@ -187,8 +200,11 @@ func (r *productRepository) GetByCategory(category string) ([]*domain.Product, e
 	query := `
 		SELECT id, code, name, description, category, provider, type,
 			base_price, selling_price, min_price, nominal, validity_period,
-			is_active, is_unlimited_stock, stock_quantity, allow_markup,
+			is_active, is_unlimited_stock, stock_quantity, is_voucher_stock, allow_markup,
 			max_markup_percentage, min_transaction_amount, max_transaction_amount,
+			margin_flagged, margin_flagged_at, margin_flag_reason,
+			destination_pattern, destination_min_length, destination_max_length, destination_hint,
+			min_level,
 			created_at, updated_at
 		FROM products WHERE category = $1 ORDER BY code ASC
 	`
@@ -208,35 +224,14 @@ func (r *productRepository) GetByCategory(category string) ([]*domain.Product, e
 
 // Count returns total products for a given filter
 func (r *productRepository) Count(filter *domain.ProductFilter) (int, error) {
-	query := `SELECT COUNT(*) FROM products WHERE 1=1`
-	var args []interface{}
-	var conditions []string
-
-	if filter != nil {
-		if filter.Category != nil {
-			conditions = append(conditions, fmt.Sprintf("category = $%d", len(args)+1))
-			args = append(args, *filter.Category)
-		}
-		if filter.Provider != nil {
-			conditions = append(conditions, fmt.Sprintf("provider = $%d", len(args)+1))
-			args = append(args, *filter.Provider)
-		}
-		if filter.IsActive != nil {
-			conditions = append(conditions, fmt.Sprintf("is_active = $%d", len(args)+1))
-			args = append(args, *filter.IsActive)
-		}
-		if filter.Query != nil && strings.TrimSpace(*filter.Query) != "" {
-			conditions = append(conditions, fmt.Sprintf("(code ILIKE $%d OR name ILIKE $%d)", len(args)+1, len(args)+1))
-			args = append(args, "%"+strings.TrimSpace(*filter.Query)+"%")
-		}
-	}
-
-	if len(conditions) > 0 {
-		query += " AND " + strings.Join(conditions, " AND ")
+	where := productFilterSQL(filter)
+	clause, err := where.Clause()
+	if err != nil {
+		return 0, err
 	}
 
 	var total int
-	if err := r.db.Get(&total, query, args...); err != nil {
+	if err := r.db.Get(&total, "SELECT COUNT(*) FROM products WHERE "+clause, where.Args()...); err != nil {
 		return 0, fmt.Errorf("failed to count products: %w", err)
 	}
 
@@ -248,8 +243,11 @@ func (r *productRepository) GetByProvider(provider string) ([]*domain.Product, e
 	query := `
 		SELECT id, code, name, description, category, provider, type,
 			base_price, selling_price, min_price, nominal, validity_period,
-			is_active, is_unlimited_stock, stock_quantity, allow_markup,
+			is_active, is_unlimited_stock, stock_quantity, is_voucher_stock, allow_markup,
 			max_markup_percentage, min_transaction_amount, max_transaction_amount,
+			margin_flagged, margin_flagged_at, margin_flag_reason,
+			destination_pattern, destination_min_length, destination_max_length, destination_hint,
+			min_level,
 			created_at, updated_at
 		FROM products WHERE provider = $1 ORDER BY code ASC
 	`
@@ -272,8 +270,11 @@ func (r *productRepository) GetActiveProducts() ([]*domain.Product, error) {
 	query := `
 		SELECT id, code, name, description, category, provider, type,
 			base_price, selling_price, min_price, nominal, validity_period,
-			is_active, is_unlimited_stock, stock_quantity, allow_markup,
+			is_active, is_unlimited_stock, stock_quantity, is_voucher_stock, allow_markup,
 			max_markup_percentage, min_transaction_amount, max_transaction_amount,
+			margin_flagged, margin_flagged_at, margin_flag_reason,
+			destination_pattern, destination_min_length, destination_max_length, destination_hint,
+			min_level,
 			created_at, updated_at
 		FROM products WHERE is_active = true ORDER BY category, code ASC
 	`
@@ -288,23 +289,44 @@ func (r *productRepository) GetActiveProducts() ([]*domain.Product, error) {
 	return products, nil
 }
 
-// Search searches products by name or code
-func (r *productRepository) Search(query string) ([]*domain.Product, error) {
-	searchQuery := `%` + query + `%`
+// Search ranks active products by full-text match (prefix match per term)
+// plus trigram similarity on name and code
+func (r *productRepository) Search(query string, limit int) ([]*domain.ProductSearchResult, error) {
+	terms := domain.ProductSearchTerms(query)
+	if len(terms) == 0 {
+		return []*domain.ProductSearchResult{}, nil
+	}
+
+	prefixes := make([]string, len(terms))
+	for i, term := range terms {
+		prefixes[i] = term + ":*"
+	}
+	tsQuery := strings.Join(prefixes, " & ")
+	fuzzyQuery := strings.Join(terms, " ")
+
 	sql := `
 		SELECT id, code, name, description, category, provider, type,
 			base_price, selling_price, min_price, nominal, validity_period,
-			is_active, is_unlimited_stock, stock_quantity, allow_markup,
+			is_active, is_unlimited_stock, stock_quantity, is_voucher_stock, allow_markup,
 			max_markup_percentage, min_transaction_amount, max_transaction_amount,
-			created_at, updated_at
-		FROM products 
-		WHERE (code ILIKE $1 OR name ILIKE $1) AND is_active = true
-		ORDER BY code ASC
-		LIMIT 50
+			margin_flagged, margin_flagged_at, margin_flag_reason,
+			destination_pattern, destination_min_length, destination_max_length, destination_hint,
+			min_level,
+			created_at, updated_at,
+			ts_rank(search_vector, to_tsquery('simple', $1))
+				+ GREATEST(word_similarity($2, lower(name)), similarity($2, lower(code))) AS rank
+		FROM products
+		WHERE is_active = true AND (
+			search_vector @@ to_tsquery('simple', $1)
+			OR $2 <% lower(name)
+			OR lower(code) % $2
+		)
+		ORDER BY rank DESC, code ASC
+		LIMIT $3
 	`
 
-	var products []*domain.Product
-	err := r.db.Select(&products, sql, searchQuery)
+	var results []*domain.ProductSearchResult
+	err := r.db.Select(&results, sql, tsQuery, fuzzyQuery, limit)
 	if err != nil {
 		logger.Error("Failed to search products",
 			logger.String("query", query),
@@ -313,7 +335,7 @@ func (r *productRepository) Search(query string) ([]*domain.Product, error) {
 		return nil, fmt.Errorf("failed to search products: %w", err)
 	}
 
-	return products, nil
+	return results, nil
 }
 
 // GetProductsByType retrieves products by type
@@ -321,8 +343,11 @@ func (r *productRepository) GetProductsByType(productType string) ([]*domain.Pro
 	query := `
 		SELECT id, code, name, description, category, provider, type,
 			base_price, selling_price, min_price, nominal, validity_period,
-			is_active, is_unlimited_stock, stock_quantity, allow_markup,
+			is_active, is_unlimited_stock, stock_quantity, is_voucher_stock, allow_markup,
 			max_markup_percentage, min_transaction_amount, max_transaction_amount,
+			margin_flagged, margin_flagged_at, margin_flag_reason,
+			destination_pattern, destination_min_length, destination_max_length, destination_hint,
+			min_level,
 			created_at, updated_at
 		FROM products WHERE type = $1 AND is_active = true ORDER BY code ASC
 	`
@@ -340,44 +365,53 @@ func (r *productRepository) GetProductsByType(productType string) ([]*domain.Pro
 	return products, nil
 }
 
+// productFilterSQL builds the WHERE clause shared by List and Count
+func productFilterSQL(filter *domain.ProductFilter) *sqlFilter {
+	where := newSQLFilter()
+	if filter == nil {
+		return where
+	}
+
+	if filter.Category != nil {
+		where.Where("category = ?", *filter.Category)
+	}
+	if filter.Provider != nil {
+		where.Where("provider = ?", *filter.Provider)
+	}
+	if filter.IsActive != nil {
+		where.Where("is_active = ?", *filter.IsActive)
+	}
+	if filter.MarginFlagged != nil {
+		where.Where("margin_flagged = ?", *filter.MarginFlagged)
+	}
+	if filter.Query != nil && strings.TrimSpace(*filter.Query) != "" {
+		pattern := "%" + strings.TrimSpace(*filter.Query) + "%"
+		where.Where("(code ILIKE ? OR name ILIKE ?)", pattern, pattern)
+	}
+
+	return where
+}
+
 // List returns products using flexible filters
 func (r *productRepository) List(filter *domain.ProductFilter) ([]*domain.Product, error) {
 	baseQuery := `
 		SELECT id, code, name, description, category, provider, type,
 			base_price, selling_price, min_price, nominal, validity_period,
-			is_active, is_unlimited_stock, stock_quantity, allow_markup,
+			is_active, is_unlimited_stock, stock_quantity, is_voucher_stock, allow_markup,
 			max_markup_percentage, min_transaction_amount, max_transaction_amount,
+			margin_flagged, margin_flagged_at, margin_flag_reason,
+			destination_pattern, destination_min_length, destination_max_length, destination_hint,
+			min_level,
 			created_at, updated_at
 		FROM products
-		WHERE 1=1`
-
-	var args []interface{}
-	var conditions []string
+		WHERE `
 
-	if filter != nil {
-		if filter.Category != nil {
-			conditions = append(conditions, fmt.Sprintf("category = $%d", len(args)+1))
-			args = append(args, *filter.Category)
-		}
-		if filter.Provider != nil {
-			conditions = append(conditions, fmt.Sprintf("provider = $%d", len(args)+1))
-			args = append(args, *filter.Provider)
-		}
-		if filter.IsActive != nil {
-			conditions = append(conditions, fmt.Sprintf("is_active = $%d", len(args)+1))
-			args = append(args, *filter.IsActive)
-		}
-		if filter.Query != nil && strings.TrimSpace(*filter.Query) != "" {
-			conditions = append(conditions, fmt.Sprintf("(code ILIKE $%d OR name ILIKE $%d)", len(args)+1, len(args)+1))
-			args = append(args, "%"+strings.TrimSpace(*filter.Query)+"%")
-		}
-	}
-
-	if len(conditions) > 0 {
-		baseQuery += " AND " + strings.Join(conditions, " AND ")
+	where := productFilterSQL(filter)
+	clause, err := where.Clause()
+	if err != nil {
+		return nil, err
 	}
-
-	baseQuery += " ORDER BY category, code ASC"
+	baseQuery += clause + " ORDER BY category, code ASC"
 
 	limit := 50
 	offset := 0
@@ -395,7 +429,7 @@ func (r *productRepository) List(filter *domain.ProductFilter) ([]*domain.Produc
 	baseQuery += fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
 
 	var products []*domain.Product
-	if err := r.db.Select(&products, baseQuery, args...); err != nil {
+	if err := r.db.Select(&products, baseQuery, where.Args()...); err != nil {
 		logger.Error("Failed to list products", logger.ErrorField(err))
 		return nil, fmt.Errorf("failed to list products: %w", err)
 	}
@@ -454,3 +488,36 @@ func (r *productRepository) UpdateStock(id string, quantity int, isUnlimited boo
 
 	return nil
 }
+
+// SetMarginFlag flags or clears the margin guard flag of a product
+func (r *productRepository) SetMarginFlag(id string, flagged bool, reason *string) error {
+	query := `
+		UPDATE products SET
+			margin_flagged = $2,
+			margin_flagged_at = CASE WHEN $2 THEN COALESCE(margin_flagged_at, NOW()) ELSE NULL END,
+			margin_flag_reason = $3,
+			updated_at = NOW()
+		WHERE id = $1
+	`
+
+	result, err := r.db.Exec(query, id, flagged, reason)
+	if err != nil {
+		logger.Error("Failed to update product margin flag",
+			logger.String("product_id", id),
+			logger.Bool("flagged", flagged),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to update product margin flag: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("product not found")
+	}
+
+	return nil
+}
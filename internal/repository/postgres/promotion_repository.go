@@ -0,0 +1,264 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const promotionColumns = `
+	id, name, description, category, user_levels, percentage, max_cashback,
+	budget, spent, starts_at, ends_at, is_active, created_by, created_at, updated_at`
+
+const promotionCashbackColumns = `
+	id, promotion_id, transaction_id, user_id, transaction_amount, amount,
+	mutation_id, reversed_amount, reversed_at, created_at`
+
+type promotionRepository struct {
+	db dbExecutor
+}
+
+// NewPromotionRepository creates a new promotion repository
+func NewPromotionRepository(db *sqlx.DB) domain.PromotionRepository {
+	return &promotionRepository{db: db}
+}
+
+// promotionRow carries the user levels of a promotion as a Postgres array
+type promotionRow struct {
+	domain.Promotion
+	UserLevels pq.Int64Array `db:"user_levels"`
+}
+
+func newPromotionRow(promotion *domain.Promotion) *promotionRow {
+	row := &promotionRow{Promotion: *promotion, UserLevels: pq.Int64Array{}}
+	for _, level := range promotion.UserLevels {
+		row.UserLevels = append(row.UserLevels, int64(level))
+	}
+	return row
+}
+
+func (row *promotionRow) toPromotion() *domain.Promotion {
+	promotion := row.Promotion
+	promotion.UserLevels = make([]int, 0, len(row.UserLevels))
+	for _, level := range row.UserLevels {
+		promotion.UserLevels = append(promotion.UserLevels, int(level))
+	}
+	return &promotion
+}
+
+// Create creates a new promotion
+func (r *promotionRepository) Create(promotion *domain.Promotion) error {
+	query := `
+		INSERT INTO promotions (
+			id, name, description, category, user_levels, percentage, max_cashback,
+			budget, spent, starts_at, ends_at, is_active, created_by, created_at, updated_at
+		) VALUES (
+			:id, :name, :description, :category, :user_levels, :percentage, :max_cashback,
+			:budget, 0, :starts_at, :ends_at, :is_active, :created_by, NOW(), NOW()
+		)`
+
+	if _, err := r.db.NamedExec(query, newPromotionRow(promotion)); err != nil {
+		logger.Error("Failed to create promotion",
+			logger.String("name", promotion.Name),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to create promotion: %w", err)
+	}
+
+	logger.Info("Promotion created",
+		logger.String("promotion_id", promotion.ID),
+		logger.String("name", promotion.Name),
+		logger.Float64("percentage", promotion.Percentage),
+	)
+
+	return nil
+}
+
+// GetByID retrieves a promotion by ID
+func (r *promotionRepository) GetByID(id string) (*domain.Promotion, error) {
+	return r.get(`SELECT `+promotionColumns+` FROM promotions WHERE id = $1`, id)
+}
+
+// GetByIDForUpdate retrieves a promotion and locks its row
+func (r *promotionRepository) GetByIDForUpdate(id string) (*domain.Promotion, error) {
+	return r.get(`SELECT `+promotionColumns+` FROM promotions WHERE id = $1 FOR UPDATE`, id)
+}
+
+func (r *promotionRepository) get(query, id string) (*domain.Promotion, error) {
+	var row promotionRow
+	if err := r.db.Get(&row, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("promotion not found")
+		}
+		return nil, fmt.Errorf("failed to get promotion: %w", err)
+	}
+
+	return row.toPromotion(), nil
+}
+
+// Update updates the rule, period and caps of a promotion
+func (r *promotionRepository) Update(promotion *domain.Promotion) error {
+	query := `
+		UPDATE promotions SET
+			name = :name, description = :description, category = :category,
+			user_levels = :user_levels, percentage = :percentage, max_cashback = :max_cashback,
+			budget = :budget, starts_at = :starts_at, ends_at = :ends_at,
+			is_active = :is_active, updated_at = NOW()
+		WHERE id = :id
+	`
+
+	result, err := r.db.NamedExec(query, newPromotionRow(promotion))
+	if err != nil {
+		logger.Error("Failed to update promotion",
+			logger.String("promotion_id", promotion.ID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to update promotion: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("promotion not found")
+	}
+
+	return nil
+}
+
+// List lists promotions, newest first, optionally only the active ones
+func (r *promotionRepository) List(activeOnly bool) ([]*domain.Promotion, error) {
+	query := `
+		SELECT ` + promotionColumns + `
+		FROM promotions
+		WHERE ($1 = FALSE OR is_active = TRUE)
+		ORDER BY starts_at DESC, created_at DESC
+	`
+
+	var rows []*promotionRow
+	if err := r.db.Select(&rows, query, activeOnly); err != nil {
+		logger.Error("Failed to list promotions", logger.ErrorField(err))
+		return nil, fmt.Errorf("failed to list promotions: %w", err)
+	}
+
+	promotions := make([]*domain.Promotion, 0, len(rows))
+	for _, row := range rows {
+		promotions = append(promotions, row.toPromotion())
+	}
+
+	return promotions, nil
+}
+
+// AddSpent adds to the cashback paid by a promotion
+func (r *promotionRepository) AddSpent(id string, amount float64) error {
+	result, err := r.db.Exec(`UPDATE promotions SET spent = spent + $2, updated_at = NOW() WHERE id = $1`, id, amount)
+	if err != nil {
+		return fmt.Errorf("failed to update promotion spent: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("promotion not found")
+	}
+
+	return nil
+}
+
+// CreateCashback stores the cashback paid for a transaction
+func (r *promotionRepository) CreateCashback(cashback *domain.PromotionCashback) error {
+	query := `
+		INSERT INTO promotion_cashbacks (` + promotionCashbackColumns + `
+		) VALUES (
+			:id, :promotion_id, :transaction_id, :user_id, :transaction_amount, :amount,
+			:mutation_id, :reversed_amount, :reversed_at, :created_at
+		)`
+
+	if _, err := r.db.NamedExec(query, cashback); err != nil {
+		logger.Error("Failed to create promotion cashback",
+			logger.String("promotion_id", cashback.PromotionID),
+			logger.String("transaction_id", cashback.TransactionID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to create promotion cashback: %w", err)
+	}
+
+	return nil
+}
+
+// GetCashbackByTransactionID returns the cashback of a transaction, nil when
+// it earned none
+func (r *promotionRepository) GetCashbackByTransactionID(transactionID string) (*domain.PromotionCashback, error) {
+	query := `SELECT ` + promotionCashbackColumns + ` FROM promotion_cashbacks WHERE transaction_id = $1`
+
+	var cashback domain.PromotionCashback
+	if err := r.db.Get(&cashback, query, transactionID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get promotion cashback: %w", err)
+	}
+
+	return &cashback, nil
+}
+
+// MarkCashbackReversed records the amount taken back from a cashback
+func (r *promotionRepository) MarkCashbackReversed(id string, amount float64) error {
+	query := `
+		UPDATE promotion_cashbacks
+		SET reversed_amount = $2, reversed_at = NOW()
+		WHERE id = $1 AND reversed_at IS NULL`
+
+	result, err := r.db.Exec(query, id, amount)
+	if err != nil {
+		return fmt.Errorf("failed to reverse promotion cashback: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("promotion cashback already reversed")
+	}
+
+	return nil
+}
+
+// GetPerformance sums up the cashback paid within [start, end) per promotion
+func (r *promotionRepository) GetPerformance(start, end time.Time) ([]*domain.PromotionPerformance, error) {
+	query := `
+		SELECT p.id AS promotion_id, p.name, p.category, p.budget, p.spent,
+			COUNT(c.id) AS transactions,
+			COUNT(DISTINCT c.user_id) AS users,
+			COALESCE(SUM(c.transaction_amount), 0) AS transaction_amount,
+			COALESCE(SUM(c.amount), 0) AS cashback_amount,
+			COALESCE(SUM(c.reversed_amount), 0) AS reversed_amount,
+			COALESCE(SUM(c.amount - c.reversed_amount), 0) AS net_cashback
+		FROM promotions p
+		JOIN promotion_cashbacks c ON c.promotion_id = p.id
+		WHERE c.created_at >= $1 AND c.created_at < $2
+		GROUP BY p.id
+		ORDER BY net_cashback DESC, p.name
+	`
+
+	performance := make([]*domain.PromotionPerformance, 0)
+	if err := r.db.Select(&performance, query, start, end); err != nil {
+		logger.Error("Failed to get promotion performance", logger.ErrorField(err))
+		return nil, fmt.Errorf("failed to get promotion performance: %w", err)
+	}
+
+	return performance, nil
+}
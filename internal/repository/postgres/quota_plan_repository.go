@@ -0,0 +1,217 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const quotaPlanColumns = `
+	id, name, description, requests_per_minute, endpoint_limits::text AS endpoint_limits,
+	transactions_per_day, max_amount_per_day, created_at, updated_at`
+
+type quotaPlanRepository struct {
+	db *sqlx.DB
+}
+
+// NewQuotaPlanRepository creates a new H2H quota plan repository
+func NewQuotaPlanRepository(db *sqlx.DB) domain.QuotaPlanRepository {
+	return &quotaPlanRepository{db: db}
+}
+
+// Create creates a new quota plan
+func (r *quotaPlanRepository) Create(plan *domain.QuotaPlan) error {
+	if err := encodeEndpointLimits(plan); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO api_quota_plans (
+			id, name, description, requests_per_minute, endpoint_limits,
+			transactions_per_day, max_amount_per_day, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, CAST($5 AS JSONB), $6, $7, NOW(), NOW()
+		)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowx(query,
+		plan.ID, plan.Name, plan.Description, plan.RequestsPerMinute, plan.EndpointLimitsJSON,
+		plan.TransactionsPerDay, plan.MaxAmountPerDay,
+	).Scan(&plan.CreatedAt, &plan.UpdatedAt)
+	if err != nil {
+		logger.Error("Failed to create quota plan",
+			logger.String("name", plan.Name),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to create quota plan: %w", err)
+	}
+
+	logger.Info("Quota plan created",
+		logger.String("plan_id", plan.ID),
+		logger.String("name", plan.Name),
+	)
+
+	return nil
+}
+
+// GetByID retrieves a quota plan by ID
+func (r *quotaPlanRepository) GetByID(id string) (*domain.QuotaPlan, error) {
+	query := `SELECT ` + quotaPlanColumns + ` FROM api_quota_plans WHERE id = $1`
+
+	var plan domain.QuotaPlan
+	if err := r.db.Get(&plan, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("quota plan not found")
+		}
+		return nil, fmt.Errorf("failed to get quota plan: %w", err)
+	}
+
+	if err := decodeEndpointLimits(&plan); err != nil {
+		return nil, err
+	}
+
+	return &plan, nil
+}
+
+// Update updates a quota plan
+func (r *quotaPlanRepository) Update(plan *domain.QuotaPlan) error {
+	if err := encodeEndpointLimits(plan); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE api_quota_plans SET
+			name = $2, description = $3, requests_per_minute = $4, endpoint_limits = CAST($5 AS JSONB),
+			transactions_per_day = $6, max_amount_per_day = $7, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	result, err := r.db.Exec(query,
+		plan.ID, plan.Name, plan.Description, plan.RequestsPerMinute, plan.EndpointLimitsJSON,
+		plan.TransactionsPerDay, plan.MaxAmountPerDay,
+	)
+	if err != nil {
+		logger.Error("Failed to update quota plan",
+			logger.String("plan_id", plan.ID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to update quota plan: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("quota plan not found")
+	}
+
+	return nil
+}
+
+// Delete removes a quota plan
+func (r *quotaPlanRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM api_quota_plans WHERE id = $1`, id)
+	if err != nil {
+		logger.Error("Failed to delete quota plan",
+			logger.String("plan_id", id),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to delete quota plan: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("quota plan not found")
+	}
+
+	return nil
+}
+
+// List lists all quota plans
+func (r *quotaPlanRepository) List() ([]*domain.QuotaPlan, error) {
+	query := `SELECT ` + quotaPlanColumns + ` FROM api_quota_plans ORDER BY name`
+
+	var plans []*domain.QuotaPlan
+	if err := r.db.Select(&plans, query); err != nil {
+		logger.Error("Failed to list quota plans", logger.ErrorField(err))
+		return nil, fmt.Errorf("failed to list quota plans: %w", err)
+	}
+
+	for _, plan := range plans {
+		if err := decodeEndpointLimits(plan); err != nil {
+			return nil, err
+		}
+	}
+
+	return plans, nil
+}
+
+// AssignToClient sets or clears the quota plan of an active API client
+func (r *quotaPlanRepository) AssignToClient(clientID string, planID *string) error {
+	result, err := r.db.Exec(`
+		UPDATE api_clients SET quota_plan_id = $2, updated_at = NOW()
+		WHERE client_id = $1 AND is_active = true
+	`, clientID, planID)
+	if err != nil {
+		logger.Error("Failed to assign quota plan",
+			logger.String("client_id", clientID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to assign quota plan: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("api client not found")
+	}
+
+	return nil
+}
+
+// CountClients counts the API clients assigned to a quota plan
+func (r *quotaPlanRepository) CountClients(planID string) (int, error) {
+	var count int
+	if err := r.db.Get(&count, `SELECT COUNT(*) FROM api_clients WHERE quota_plan_id = $1`, planID); err != nil {
+		return 0, fmt.Errorf("failed to count quota plan clients: %w", err)
+	}
+	return count, nil
+}
+
+func encodeEndpointLimits(plan *domain.QuotaPlan) error {
+	limits := plan.EndpointLimits
+	if limits == nil {
+		limits = map[string]int{}
+	}
+	data, err := json.Marshal(limits)
+	if err != nil {
+		return fmt.Errorf("failed to encode endpoint limits: %w", err)
+	}
+	plan.EndpointLimitsJSON = string(data)
+	return nil
+}
+
+func decodeEndpointLimits(plan *domain.QuotaPlan) error {
+	plan.EndpointLimits = map[string]int{}
+	if plan.EndpointLimitsJSON == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(plan.EndpointLimitsJSON), &plan.EndpointLimits); err != nil {
+		return fmt.Errorf("failed to decode endpoint limits: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const balanceMismatchColumns = `
+	id, user_id, stored_balance, ledger_balance, difference, mutation_count,
+	last_mutation_id, last_balance_after, status, resolution, resolved_by, resolved_at,
+	detected_at, last_checked_at, updated_at`
+
+// ledgerBalanceQuery recomputes users' balances from their mutations, on top
+// of the opening balance checkpointed when older mutation partitions were
+// detached. Stored balance and ledger are read by one statement, so they come
+// from the same snapshot.
+const ledgerBalanceQuery = `
+	SELECT
+		u.id AS user_id,
+		u.balance AS stored_balance,
+		COALESCE(cp.opening_balance, 0) + COALESCE(l.ledger_balance, 0) AS ledger_balance,
+		COALESCE(cp.mutation_count, 0) + COALESCE(l.mutation_count, 0) AS mutation_count,
+		last.id AS last_mutation_id,
+		last.balance_after AS last_balance_after,
+		u.balance = COALESCE(cp.opening_balance, 0) + COALESCE(l.ledger_balance, 0) AS matches
+	FROM users u
+	LEFT JOIN ledger_checkpoints cp ON cp.user_id = u.id
+	LEFT JOIN LATERAL (
+		SELECT
+			SUM(CASE WHEN m.type = 'DEBIT' THEN m.amount ELSE -m.amount END) AS ledger_balance,
+			COUNT(*) AS mutation_count
+		FROM mutations m
+		WHERE m.user_id = u.id
+	) l ON true
+	LEFT JOIN LATERAL (
+		SELECT m.id, m.balance_after
+		FROM mutations m
+		WHERE m.user_id = u.id
+		ORDER BY m.created_at DESC, m.id DESC
+		LIMIT 1
+	) last ON true`
+
+type reconciliationRepository struct {
+	db *sqlx.DB
+}
+
+// NewReconciliationRepository creates a new balance reconciliation repository
+func NewReconciliationRepository(db *sqlx.DB) domain.ReconciliationRepository {
+	return &reconciliationRepository{db: db}
+}
+
+// ListLedgerBalances recomputes the ledger balance of a batch of users
+func (r *reconciliationRepository) ListLedgerBalances(afterUserID string, limit int) ([]*domain.LedgerBalance, error) {
+	query := ledgerBalanceQuery + `
+		ORDER BY u.id
+		LIMIT $1
+	`
+	args := []interface{}{limit}
+	if afterUserID != "" {
+		query = ledgerBalanceQuery + `
+			WHERE u.id > $2::uuid
+			ORDER BY u.id
+			LIMIT $1
+		`
+		args = append(args, afterUserID)
+	}
+
+	var balances []*domain.LedgerBalance
+	if err := r.db.Select(&balances, query, args...); err != nil {
+		logger.Error("Failed to compute ledger balances", logger.ErrorField(err))
+		return nil, fmt.Errorf("failed to compute ledger balances: %w", err)
+	}
+	return balances, nil
+}
+
+// GetLedgerBalance recomputes the ledger balance of one user
+func (r *reconciliationRepository) GetLedgerBalance(userID string) (*domain.LedgerBalance, error) {
+	query := ledgerBalanceQuery + ` WHERE u.id = $1`
+
+	var balance domain.LedgerBalance
+	if err := r.db.Get(&balance, query, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to compute ledger balance: %w", err)
+	}
+	return &balance, nil
+}
+
+// ApplyLedgerBalance sets the stored balance of a user to their ledger
+// balance. The user row is locked first, so balance updates in flight finish
+// before the ledger is summed.
+func (r *reconciliationRepository) ApplyLedgerBalance(userID string) (*domain.LedgerBalance, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var lockedID string
+	if err := tx.Get(&lockedID, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to lock user: %w", err)
+	}
+
+	var balance domain.LedgerBalance
+	if err := tx.Get(&balance, ledgerBalanceQuery+` WHERE u.id = $1`, userID); err != nil {
+		return nil, fmt.Errorf("failed to compute ledger balance: %w", err)
+	}
+
+	if !balance.Matches {
+		query := `
+			UPDATE users SET balance = (
+				SELECT COALESCE(SUM(CASE WHEN m.type = 'DEBIT' THEN m.amount ELSE -m.amount END), 0)
+				FROM mutations m
+				WHERE m.user_id = $1
+			) + COALESCE((
+				SELECT opening_balance FROM ledger_checkpoints WHERE user_id = $1
+			), 0)
+			WHERE id = $1
+		`
+		if _, err := tx.Exec(query, userID); err != nil {
+			logger.Error("Failed to apply ledger balance",
+				logger.String("user_id", userID),
+				logger.ErrorField(err),
+			)
+			return nil, fmt.Errorf("failed to apply ledger balance: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return &balance, nil
+}
+
+// UpsertMismatch opens a mismatch for a user, or refreshes the figures of the
+// mismatch that is already open for them
+func (r *reconciliationRepository) UpsertMismatch(mismatch *domain.BalanceMismatch) error {
+	query := `
+		INSERT INTO balance_mismatches (
+			id, user_id, stored_balance, ledger_balance, difference, mutation_count,
+			last_mutation_id, last_balance_after, status, detected_at, last_checked_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW(), NOW()
+		)
+		ON CONFLICT (user_id) WHERE status = 'OPEN' DO UPDATE SET
+			stored_balance = EXCLUDED.stored_balance,
+			ledger_balance = EXCLUDED.ledger_balance,
+			difference = EXCLUDED.difference,
+			mutation_count = EXCLUDED.mutation_count,
+			last_mutation_id = EXCLUDED.last_mutation_id,
+			last_balance_after = EXCLUDED.last_balance_after,
+			last_checked_at = NOW(),
+			updated_at = NOW()
+		RETURNING id, detected_at, last_checked_at, updated_at
+	`
+
+	err := r.db.QueryRowx(query,
+		mismatch.ID, mismatch.UserID, mismatch.StoredBalance, mismatch.LedgerBalance, mismatch.Difference,
+		mismatch.MutationCount, mismatch.LastMutationID, mismatch.LastBalanceAfter, domain.BalanceMismatchOpen,
+	).Scan(&mismatch.ID, &mismatch.DetectedAt, &mismatch.LastCheckedAt, &mismatch.UpdatedAt)
+	if err != nil {
+		logger.Error("Failed to upsert balance mismatch",
+			logger.String("user_id", mismatch.UserID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to upsert balance mismatch: %w", err)
+	}
+	mismatch.Status = domain.BalanceMismatchOpen
+
+	return nil
+}
+
+// ClearMismatches closes the open mismatches of users that balance again
+func (r *reconciliationRepository) ClearMismatches(userIDs []string) (int, error) {
+	if len(userIDs) == 0 {
+		return 0, nil
+	}
+
+	query := `
+		UPDATE balance_mismatches SET
+			status = $2, resolution = $3, resolved_at = NOW(), last_checked_at = NOW(), updated_at = NOW()
+		WHERE status = $4 AND user_id = ANY($1)
+	`
+
+	result, err := r.db.Exec(query, pq.Array(userIDs), domain.BalanceMismatchCleared,
+		"Balance matches the ledger again", domain.BalanceMismatchOpen)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear balance mismatches: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear balance mismatches: %w", err)
+	}
+	return int(rows), nil
+}
+
+// GetMismatch retrieves a balance mismatch by ID
+func (r *reconciliationRepository) GetMismatch(id string) (*domain.BalanceMismatch, error) {
+	query := `SELECT ` + balanceMismatchColumns + ` FROM balance_mismatches WHERE id = $1`
+
+	var mismatch domain.BalanceMismatch
+	if err := r.db.Get(&mismatch, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("balance mismatch not found")
+		}
+		return nil, fmt.Errorf("failed to get balance mismatch: %w", err)
+	}
+	return &mismatch, nil
+}
+
+// ListMismatches returns balance mismatches, largest difference first,
+// optionally filtered by status
+func (r *reconciliationRepository) ListMismatches(status string, limit, offset int) ([]*domain.BalanceMismatch, error) {
+	query := `SELECT ` + balanceMismatchColumns + ` FROM balance_mismatches`
+	args := []interface{}{}
+	if status != "" {
+		query += ` WHERE status = $1`
+		args = append(args, status)
+	}
+	query += fmt.Sprintf(` ORDER BY ABS(difference) DESC, detected_at DESC LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	var mismatches []*domain.BalanceMismatch
+	if err := r.db.Select(&mismatches, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list balance mismatches: %w", err)
+	}
+	return mismatches, nil
+}
+
+// ResolveMismatch closes an open mismatch on behalf of an admin
+func (r *reconciliationRepository) ResolveMismatch(id, resolution string, resolvedBy *string) error {
+	query := `
+		UPDATE balance_mismatches SET
+			status = $2, resolution = $3, resolved_by = $4, resolved_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = $5
+	`
+
+	result, err := r.db.Exec(query, id, domain.BalanceMismatchResolved, resolution, resolvedBy, domain.BalanceMismatchOpen)
+	if err != nil {
+		return fmt.Errorf("failed to resolve balance mismatch: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to resolve balance mismatch: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("balance mismatch already closed")
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const downlineRegistrationColumns = `
+	id, upline_id, referral_code, email, full_name, phone, password_hash, status,
+	user_id, reason, decided_by, decided_at, ip_address, created_at`
+
+type referralRepository struct {
+	db dbExecutor
+}
+
+// NewReferralRepository creates a new referral repository
+func NewReferralRepository(db *sqlx.DB) domain.ReferralRepository {
+	return &referralRepository{db: db}
+}
+
+// GetReferralCode returns the referral code of a user
+func (r *referralRepository) GetReferralCode(userID string) (*string, error) {
+	var code *string
+	if err := r.db.Get(&code, `SELECT referral_code FROM users WHERE id = $1`, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to get referral code: %w", err)
+	}
+
+	return code, nil
+}
+
+// SetReferralCode stores the referral code of a user
+func (r *referralRepository) SetReferralCode(userID, code string) error {
+	result, err := r.db.Exec(`UPDATE users SET referral_code = $2 WHERE id = $1`, userID, code)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return fmt.Errorf("referral code already exists")
+		}
+		return fmt.Errorf("failed to set referral code: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// GetUserByReferralCode returns the user owning a referral code
+func (r *referralRepository) GetUserByReferralCode(code string) (*domain.User, error) {
+	query := `
+		SELECT id, username, email, password_hash, full_name, phone,
+			upline_id, level, is_active, is_verified, balance, held_balance, credit_limit,
+			markup_percentage, allow_debt, max_daily_transaction,
+			created_at, updated_at, last_login_at
+		FROM users WHERE referral_code = $1`
+
+	var user domain.User
+	if err := r.db.Get(&user, query, code); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("referral code not found")
+		}
+		return nil, fmt.Errorf("failed to get user by referral code: %w", err)
+	}
+
+	return &user, nil
+}
+
+// Create stores a pending downline registration
+func (r *referralRepository) Create(registration *domain.DownlineRegistration) error {
+	query := `
+		INSERT INTO downline_registrations (
+			id, upline_id, referral_code, email, full_name, phone, password_hash,
+			status, ip_address, created_at
+		) VALUES (
+			:id, :upline_id, :referral_code, :email, :full_name, :phone, :password_hash,
+			:status, :ip_address, NOW()
+		)`
+
+	if _, err := r.db.NamedExec(query, registration); err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return fmt.Errorf("registration already pending")
+		}
+		logger.Error("Failed to create downline registration",
+			logger.String("upline_id", registration.UplineID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to create downline registration: %w", err)
+	}
+
+	return nil
+}
+
+// GetByIDForUpdate returns a registration and locks its row
+func (r *referralRepository) GetByIDForUpdate(id string) (*domain.DownlineRegistration, error) {
+	query := `SELECT ` + downlineRegistrationColumns + ` FROM downline_registrations WHERE id = $1 FOR UPDATE`
+
+	var registration domain.DownlineRegistration
+	if err := r.db.Get(&registration, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("registration not found")
+		}
+		return nil, fmt.Errorf("failed to get downline registration: %w", err)
+	}
+
+	return &registration, nil
+}
+
+// ListByUpline lists the registrations made with an upline's codes, newest
+// first, optionally of one status
+func (r *referralRepository) ListByUpline(uplineID, status string, limit int) ([]*domain.DownlineRegistration, error) {
+	query := `
+		SELECT ` + downlineRegistrationColumns + `
+		FROM downline_registrations
+		WHERE upline_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+		LIMIT $3`
+
+	var registrations []*domain.DownlineRegistration
+	if err := r.db.Select(&registrations, query, uplineID, status, limit); err != nil {
+		return nil, fmt.Errorf("failed to list downline registrations: %w", err)
+	}
+
+	return registrations, nil
+}
+
+// HasPendingEmail reports whether a pending registration uses the email
+func (r *referralRepository) HasPendingEmail(email string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM downline_registrations WHERE email = $1 AND status = 'PENDING')`
+
+	var exists bool
+	if err := r.db.Get(&exists, query, email); err != nil {
+		return false, fmt.Errorf("failed to check pending registration: %w", err)
+	}
+
+	return exists, nil
+}
+
+// CountDownlines counts the direct downlines and pending registrations of a user
+func (r *referralRepository) CountDownlines(uplineID string) (int, error) {
+	query := `
+		SELECT (SELECT COUNT(*) FROM users WHERE upline_id = $1)
+			+ (SELECT COUNT(*) FROM downline_registrations WHERE upline_id = $1 AND status = 'PENDING')`
+
+	var count int
+	if err := r.db.Get(&count, query, uplineID); err != nil {
+		return 0, fmt.Errorf("failed to count downlines: %w", err)
+	}
+
+	return count, nil
+}
+
+// Decide stores the decision on a pending registration
+func (r *referralRepository) Decide(registration *domain.DownlineRegistration) error {
+	query := `
+		UPDATE downline_registrations
+		SET status = :status, user_id = :user_id, reason = :reason,
+			decided_by = :decided_by, decided_at = :decided_at
+		WHERE id = :id AND status = 'PENDING'`
+
+	result, err := r.db.NamedExec(query, registration)
+	if err != nil {
+		return fmt.Errorf("failed to decide downline registration: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("registration is not pending")
+	}
+
+	return nil
+}
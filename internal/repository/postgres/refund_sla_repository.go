@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+)
+
+type refundSLARepository struct {
+	db *sqlx.DB
+}
+
+// NewRefundSLARepository creates a new refund SLA repository
+func NewRefundSLARepository(db *sqlx.DB) domain.RefundSLARepository {
+	return &refundSLARepository{db: db}
+}
+
+// outstandingRefundFrom selects failed and timed out transactions with the
+// time of their last failure event and no balance release or refund event.
+// $1 bounds the creation time, $2 the failure time.
+const outstandingRefundFrom = `
+	FROM transactions t
+	JOIN LATERAL (
+		SELECT f.created_at AS failed_at
+		FROM transaction_events f
+		WHERE f.transaction_id = t.id AND f.status IN ('FAILED', 'TIMEOUT')
+		ORDER BY f.sequence DESC
+		LIMIT 1
+	) failure ON TRUE
+	WHERE t.status IN ('FAILED', 'TIMEOUT')
+		AND t.created_at >= $1
+		AND failure.failed_at < $2
+		AND NOT EXISTS (
+			SELECT 1 FROM transaction_events r
+			WHERE r.transaction_id = t.id AND r.event_type IN ('BALANCE_RELEASED', 'REFUNDED')
+		)`
+
+// GetRefundStats measures each refund from the last failure event before it.
+// A transaction refunded more than once counts its first refund only.
+func (r *refundSLARepository) GetRefundStats(start, end time.Time, target time.Duration) (*domain.RefundStats, error) {
+	query := `
+		WITH refunds AS (
+			SELECT DISTINCT ON (e.transaction_id)
+				e.transaction_id,
+				EXTRACT(EPOCH FROM e.created_at - (
+					SELECT f.created_at FROM transaction_events f
+					WHERE f.transaction_id = e.transaction_id
+						AND f.status IN ('FAILED', 'TIMEOUT') AND f.sequence < e.sequence
+					ORDER BY f.sequence DESC
+					LIMIT 1
+				)) AS latency
+			FROM transaction_events e
+			WHERE e.event_type = 'REFUNDED' AND e.created_at >= $1 AND e.created_at < $2
+			ORDER BY e.transaction_id, e.sequence
+		)
+		SELECT COUNT(*) AS count,
+			COALESCE(SUM(t.selling_price + t.admin_fee), 0) AS total_amount,
+			COUNT(r.latency) AS measured_count,
+			COALESCE(AVG(r.latency), 0) AS average_latency_seconds,
+			COALESCE(PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY r.latency), 0) AS p95_latency_seconds,
+			COALESCE(MAX(r.latency), 0) AS max_latency_seconds,
+			COUNT(*) FILTER (WHERE r.latency > $3) AS breached_count
+		FROM refunds r
+		JOIN transactions t ON t.id = r.transaction_id
+	`
+
+	var stats domain.RefundStats
+	if err := r.db.Get(&stats, query, start, end, target.Seconds()); err != nil {
+		return nil, fmt.Errorf("failed to get refund stats: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// ListOutstanding lists outstanding refunds oldest first
+func (r *refundSLARepository) ListOutstanding(createdSince, failedBefore time.Time, limit int) ([]*domain.OutstandingRefund, int, float64, error) {
+	var totals struct {
+		Count  int     `db:"count"`
+		Amount float64 `db:"amount"`
+	}
+	countQuery := `SELECT COUNT(*) AS count, COALESCE(SUM(t.selling_price + t.admin_fee), 0) AS amount` + outstandingRefundFrom
+	if err := r.db.Get(&totals, countQuery, createdSince, failedBefore); err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to count outstanding refunds: %w", err)
+	}
+
+	query := `
+		SELECT t.id AS transaction_id, t.trx_code, t.user_id, t.product_code, t.status,
+			t.selling_price + t.admin_fee AS amount, failure.failed_at` + outstandingRefundFrom + `
+		ORDER BY failure.failed_at ASC
+		LIMIT $3
+	`
+
+	var refunds []*domain.OutstandingRefund
+	if err := r.db.Select(&refunds, query, createdSince, failedBefore, limit); err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to list outstanding refunds: %w", err)
+	}
+
+	return refunds, totals.Count, totals.Amount, nil
+}
+
+// LastStuckAlertAt looks the alert up in the outbox, so the alert cooldown
+// holds across replicas and restarts
+func (r *refundSLARepository) LastStuckAlertAt(transactionID string) (*time.Time, error) {
+	query := `
+		SELECT MAX(created_at) FROM domain_events
+		WHERE aggregate_type = $1 AND aggregate_id = $2 AND event_type = $3
+	`
+
+	var last *time.Time
+	if err := r.db.Get(&last, query, domain.AggregateTypeTransaction, transactionID, domain.EventRefundStuck); err != nil {
+		return nil, fmt.Errorf("failed to get last stuck refund alert: %w", err)
+	}
+
+	return last, nil
+}
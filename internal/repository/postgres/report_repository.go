@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+)
+
+type reportRepository struct {
+	db *sqlx.DB
+}
+
+// NewReportRepository creates a new report repository
+func NewReportRepository(db *sqlx.DB) domain.ReportRepository {
+	return &reportRepository{db: db}
+}
+
+// GetSupplierSummary aggregates transactions per supplier and day/month. Periods
+// are truncated in the given IANA timezone and returned as wall-clock times
+// (UTC location); transactions are attributed to the final supplier when set.
+func (r *reportRepository) GetSupplierSummary(startDate, endDate time.Time, granularity, timezone string) ([]*domain.SupplierReportRow, error) {
+	if !domain.IsValidReportGranularity(granularity) {
+		return nil, fmt.Errorf("invalid report granularity")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT date_trunc('%s', t.created_at AT TIME ZONE $3) AS period,
+			s.id AS supplier_id, s.code AS supplier_code, s.name AS supplier_name,
+			COUNT(*) AS total_transactions,
+			COUNT(*) FILTER (WHERE t.status = 'SUCCESS') AS success_count,
+			COUNT(*) FILTER (WHERE t.status IN ('FAILED', 'TIMEOUT')) AS failed_count,
+			COUNT(*) FILTER (WHERE t.status = 'REFUND') AS refund_count,
+			COALESCE(SUM(t.hpp) FILTER (WHERE t.status = 'SUCCESS'), 0) AS total_hpp,
+			COALESCE(SUM(t.selling_price) FILTER (WHERE t.status = 'SUCCESS'), 0) AS total_selling,
+			COALESCE(SUM(t.admin_fee) FILTER (WHERE t.status = 'SUCCESS'), 0) AS total_admin_fee,
+			COALESCE(SUM(t.profit) FILTER (WHERE t.status = 'SUCCESS'), 0) AS gross_profit,
+			COALESCE(SUM(t.selling_price + t.admin_fee) FILTER (WHERE t.status = 'REFUND'), 0) AS refund_total
+		FROM transactions t
+		JOIN suppliers s ON s.id = COALESCE(t.final_supplier_id, t.supplier_id)
+		WHERE t.created_at >= $1 AND t.created_at < $2
+		GROUP BY 1, s.id, s.code, s.name
+		ORDER BY 1, s.code
+	`, strings.ToLower(granularity))
+
+	var rows []*domain.SupplierReportRow
+	if err := r.db.Select(&rows, query, startDate, endDate, timezone); err != nil {
+		return nil, fmt.Errorf("failed to get supplier summary: %w", err)
+	}
+
+	return rows, nil
+}
+
+// GetUserDailySummaries aggregates the transactions and deposits of every user
+// active within the range
+func (r *reportRepository) GetUserDailySummaries(startDate, endDate time.Time) ([]*domain.UserDailySummary, error) {
+	query := `
+		WITH trx AS (
+			SELECT user_id,
+				COUNT(*) AS total_transactions,
+				COUNT(*) FILTER (WHERE status = 'SUCCESS') AS success_count,
+				COUNT(*) FILTER (WHERE status IN ('FAILED', 'TIMEOUT')) AS failed_count,
+				COALESCE(SUM(selling_price + admin_fee) FILTER (WHERE status = 'SUCCESS'), 0) AS total_spent
+			FROM transactions
+			WHERE created_at >= $1 AND created_at < $2
+			GROUP BY user_id
+		), dep AS (
+			SELECT user_id, SUM(amount) AS total_deposit
+			FROM mutations
+			WHERE type = 'DEBIT' AND reference_type = 'DEPOSIT'
+			AND created_at >= $1 AND created_at < $2
+			GROUP BY user_id
+		)
+		SELECT u.id AS user_id,
+			COALESCE(trx.total_transactions, 0) AS total_transactions,
+			COALESCE(trx.success_count, 0) AS success_count,
+			COALESCE(trx.failed_count, 0) AS failed_count,
+			COALESCE(trx.total_spent, 0) AS total_spent,
+			COALESCE(dep.total_deposit, 0) AS total_deposit,
+			u.balance
+		FROM users u
+		LEFT JOIN trx ON trx.user_id = u.id
+		LEFT JOIN dep ON dep.user_id = u.id
+		WHERE trx.user_id IS NOT NULL OR dep.user_id IS NOT NULL
+		ORDER BY u.id
+	`
+
+	var rows []*domain.UserDailySummary
+	if err := r.db.Select(&rows, query, startDate, endDate); err != nil {
+		return nil, fmt.Errorf("failed to get user daily summaries: %w", err)
+	}
+
+	return rows, nil
+}
+
+// GetTagSummary aggregates the transactions created within the range by the
+// value of one tag key. Transactions without the tag are grouped under an
+// empty value.
+func (r *reportRepository) GetTagSummary(startDate, endDate time.Time, tagKey, userID string) ([]*domain.TagReportRow, error) {
+	query := `
+		SELECT COALESCE(tags ->> $3, '') AS tag_value,
+			COUNT(*) AS total_transactions,
+			COUNT(*) FILTER (WHERE status = 'SUCCESS') AS success_count,
+			COUNT(*) FILTER (WHERE status IN ('FAILED', 'TIMEOUT')) AS failed_count,
+			COUNT(*) FILTER (WHERE status = 'REFUND') AS refund_count,
+			COALESCE(SUM(selling_price) FILTER (WHERE status = 'SUCCESS'), 0) AS total_selling,
+			COALESCE(SUM(admin_fee) FILTER (WHERE status = 'SUCCESS'), 0) AS total_admin_fee,
+			COALESCE(SUM(profit) FILTER (WHERE status = 'SUCCESS'), 0) AS profit
+		FROM transactions
+		WHERE created_at >= $1 AND created_at < $2`
+	args := []interface{}{startDate, endDate, tagKey}
+	if userID != "" {
+		query += " AND user_id = $4"
+		args = append(args, userID)
+	}
+	query += " GROUP BY 1 ORDER BY 1"
+
+	var rows []*domain.TagReportRow
+	if err := r.db.Select(&rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get tag summary: %w", err)
+	}
+
+	return rows, nil
+}
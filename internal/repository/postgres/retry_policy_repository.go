@@ -0,0 +1,156 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const retryPolicyColumns = `
+	id, supplier_id, error_class, max_attempts, initial_delay_ms, max_delay_ms,
+	backoff_multiplier, enable_jitter, is_active, created_at, updated_at`
+
+type retryPolicyRepository struct {
+	db *sqlx.DB
+}
+
+// NewRetryPolicyRepository creates a new retry policy repository
+func NewRetryPolicyRepository(db *sqlx.DB) domain.RetryPolicyRepository {
+	return &retryPolicyRepository{db: db}
+}
+
+// Create creates a new retry policy
+func (r *retryPolicyRepository) Create(policy *domain.RetryPolicy) error {
+	query := `
+		INSERT INTO retry_policies (
+			id, supplier_id, error_class, max_attempts, initial_delay_ms, max_delay_ms,
+			backoff_multiplier, enable_jitter, is_active, created_at, updated_at
+		) VALUES (
+			:id, :supplier_id, :error_class, :max_attempts, :initial_delay_ms, :max_delay_ms,
+			:backoff_multiplier, :enable_jitter, :is_active, NOW(), NOW()
+		)`
+
+	if _, err := r.db.NamedExec(query, policy); err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return fmt.Errorf("retry policy already exists")
+		}
+		logger.Error("Failed to create retry policy",
+			logger.String("error_class", policy.ErrorClass),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to create retry policy: %w", err)
+	}
+
+	logger.Info("Retry policy created",
+		logger.String("policy_id", policy.ID),
+		logger.String("error_class", policy.ErrorClass),
+		logger.Int("max_attempts", policy.MaxAttempts),
+	)
+
+	return nil
+}
+
+// GetByID retrieves a retry policy by ID
+func (r *retryPolicyRepository) GetByID(id string) (*domain.RetryPolicy, error) {
+	query := `SELECT ` + retryPolicyColumns + ` FROM retry_policies WHERE id = $1`
+
+	var policy domain.RetryPolicy
+	if err := r.db.Get(&policy, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("retry policy not found")
+		}
+		return nil, fmt.Errorf("failed to get retry policy: %w", err)
+	}
+
+	return &policy, nil
+}
+
+// Update updates the limits and state of a retry policy
+func (r *retryPolicyRepository) Update(policy *domain.RetryPolicy) error {
+	query := `
+		UPDATE retry_policies SET
+			max_attempts = :max_attempts, initial_delay_ms = :initial_delay_ms,
+			max_delay_ms = :max_delay_ms, backoff_multiplier = :backoff_multiplier,
+			enable_jitter = :enable_jitter, is_active = :is_active, updated_at = NOW()
+		WHERE id = :id
+	`
+
+	result, err := r.db.NamedExec(query, policy)
+	if err != nil {
+		logger.Error("Failed to update retry policy",
+			logger.String("policy_id", policy.ID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to update retry policy: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("retry policy not found")
+	}
+
+	return nil
+}
+
+// Delete removes a retry policy
+func (r *retryPolicyRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM retry_policies WHERE id = $1`, id)
+	if err != nil {
+		logger.Error("Failed to delete retry policy",
+			logger.String("policy_id", id),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to delete retry policy: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("retry policy not found")
+	}
+
+	return nil
+}
+
+// List lists retry policies, the global ones first
+func (r *retryPolicyRepository) List(supplierID string) ([]*domain.RetryPolicy, error) {
+	query := `
+		SELECT ` + retryPolicyColumns + `
+		FROM retry_policies
+		WHERE ($1 = '' OR supplier_id::text = $1)
+		ORDER BY supplier_id NULLS FIRST, error_class
+	`
+
+	var policies []*domain.RetryPolicy
+	if err := r.db.Select(&policies, query, supplierID); err != nil {
+		logger.Error("Failed to list retry policies", logger.ErrorField(err))
+		return nil, fmt.Errorf("failed to list retry policies: %w", err)
+	}
+
+	return policies, nil
+}
+
+// ListActive returns every active retry policy
+func (r *retryPolicyRepository) ListActive() ([]*domain.RetryPolicy, error) {
+	query := `SELECT ` + retryPolicyColumns + ` FROM retry_policies WHERE is_active = TRUE`
+
+	var policies []*domain.RetryPolicy
+	if err := r.db.Select(&policies, query); err != nil {
+		logger.Error("Failed to list active retry policies", logger.ErrorField(err))
+		return nil, fmt.Errorf("failed to list active retry policies: %w", err)
+	}
+
+	return policies, nil
+}
@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+)
+
+const routingDecisionColumns = `
+	id, transaction_id, source, supplier_id, supplier_code, supplier_product_code,
+	supplier_price, score, confidence, reason, alternatives, created_at`
+
+type routingDecisionRepository struct {
+	db *sqlx.DB
+}
+
+// NewRoutingDecisionRepository creates a new routing decision repository
+func NewRoutingDecisionRepository(db *sqlx.DB) domain.RoutingDecisionRepository {
+	return &routingDecisionRepository{db: db}
+}
+
+// Create stores a routing decision
+func (r *routingDecisionRepository) Create(decision *domain.RoutingDecision) error {
+	alternatives := decision.Alternatives
+	if alternatives == nil {
+		alternatives = []domain.RoutingCandidate{}
+	}
+	data, err := json.Marshal(alternatives)
+	if err != nil {
+		return fmt.Errorf("failed to encode routing alternatives: %w", err)
+	}
+	decision.AlternativesJSON = string(data)
+
+	query := `
+		INSERT INTO routing_decisions (` + routingDecisionColumns + `
+		) VALUES (
+			:id, :transaction_id, :source, :supplier_id, :supplier_code, :supplier_product_code,
+			:supplier_price, :score, :confidence, :reason, :alternatives, :created_at
+		)`
+
+	if _, err := r.db.NamedExec(query, decision); err != nil {
+		return fmt.Errorf("failed to create routing decision: %w", err)
+	}
+
+	return nil
+}
+
+// ListByTransactionID returns the routing decisions of a transaction, oldest first
+func (r *routingDecisionRepository) ListByTransactionID(transactionID string) ([]*domain.RoutingDecision, error) {
+	query := `SELECT ` + routingDecisionColumns + `
+		FROM routing_decisions
+		WHERE transaction_id = $1
+		ORDER BY created_at ASC, id ASC`
+
+	var decisions []*domain.RoutingDecision
+	if err := r.db.Select(&decisions, query, transactionID); err != nil {
+		return nil, fmt.Errorf("failed to list routing decisions: %w", err)
+	}
+
+	for _, decision := range decisions {
+		if err := json.Unmarshal([]byte(decision.AlternativesJSON), &decision.Alternatives); err != nil {
+			return nil, fmt.Errorf("failed to decode routing alternatives: %w", err)
+		}
+	}
+
+	return decisions, nil
+}
@@ -0,0 +1,171 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const routingOverrideColumns = `
+	id, scope_type, scope_value, supplier_id, action, reason,
+	is_active, created_by, created_at, updated_at`
+
+type routingOverrideRepository struct {
+	db *sqlx.DB
+}
+
+// NewRoutingOverrideRepository creates a new routing override repository
+func NewRoutingOverrideRepository(db *sqlx.DB) domain.RoutingOverrideRepository {
+	return &routingOverrideRepository{db: db}
+}
+
+// Create creates a new routing override
+func (r *routingOverrideRepository) Create(override *domain.RoutingOverride) error {
+	query := `
+		INSERT INTO routing_overrides (
+			id, scope_type, scope_value, supplier_id, action, reason,
+			is_active, created_by, created_at, updated_at
+		) VALUES (
+			:id, :scope_type, :scope_value, :supplier_id, :action, :reason,
+			:is_active, :created_by, NOW(), NOW()
+		)`
+
+	_, err := r.db.NamedExec(query, override)
+	if err != nil {
+		logger.Error("Failed to create routing override",
+			logger.String("scope_type", override.ScopeType),
+			logger.String("scope_value", override.ScopeValue),
+			logger.String("supplier_id", override.SupplierID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to create routing override: %w", err)
+	}
+
+	logger.Info("Routing override created",
+		logger.String("override_id", override.ID),
+		logger.String("action", override.Action),
+		logger.String("scope_type", override.ScopeType),
+		logger.String("scope_value", override.ScopeValue),
+		logger.String("supplier_id", override.SupplierID),
+	)
+
+	return nil
+}
+
+// GetByID retrieves a routing override by ID
+func (r *routingOverrideRepository) GetByID(id string) (*domain.RoutingOverride, error) {
+	query := `SELECT ` + routingOverrideColumns + ` FROM routing_overrides WHERE id = $1`
+
+	var override domain.RoutingOverride
+	if err := r.db.Get(&override, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("routing override not found")
+		}
+		return nil, fmt.Errorf("failed to get routing override: %w", err)
+	}
+
+	return &override, nil
+}
+
+// Update updates mutable fields of a routing override
+func (r *routingOverrideRepository) Update(override *domain.RoutingOverride) error {
+	query := `
+		UPDATE routing_overrides SET
+			reason = $2, is_active = $3, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	result, err := r.db.Exec(query, override.ID, override.Reason, override.IsActive)
+	if err != nil {
+		logger.Error("Failed to update routing override",
+			logger.String("override_id", override.ID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to update routing override: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("routing override not found")
+	}
+
+	return nil
+}
+
+// Delete removes a routing override
+func (r *routingOverrideRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM routing_overrides WHERE id = $1`, id)
+	if err != nil {
+		logger.Error("Failed to delete routing override",
+			logger.String("override_id", id),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to delete routing override: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("routing override not found")
+	}
+
+	return nil
+}
+
+// List lists routing overrides, optionally filtered by scope
+func (r *routingOverrideRepository) List(scopeType, scopeValue string) ([]*domain.RoutingOverride, error) {
+	query := `
+		SELECT ` + routingOverrideColumns + `
+		FROM routing_overrides
+		WHERE ($1 = '' OR scope_type = $1)
+		AND ($2 = '' OR scope_value = $2)
+		ORDER BY scope_type, scope_value, action, created_at
+	`
+
+	var overrides []*domain.RoutingOverride
+	if err := r.db.Select(&overrides, query, scopeType, scopeValue); err != nil {
+		logger.Error("Failed to list routing overrides", logger.ErrorField(err))
+		return nil, fmt.Errorf("failed to list routing overrides: %w", err)
+	}
+
+	return overrides, nil
+}
+
+// GetActiveForProduct returns active overrides that apply to a product or its category
+func (r *routingOverrideRepository) GetActiveForProduct(productID, category string) ([]*domain.RoutingOverride, error) {
+	query := `
+		SELECT ` + routingOverrideColumns + `
+		FROM routing_overrides
+		WHERE is_active = TRUE
+		AND (
+			(scope_type = $1 AND scope_value = $2)
+			OR (scope_type = $3 AND scope_value = $4)
+		)
+	`
+
+	var overrides []*domain.RoutingOverride
+	err := r.db.Select(&overrides, query,
+		domain.OverrideScopeProduct, productID,
+		domain.OverrideScopeCategory, category,
+	)
+	if err != nil {
+		logger.Error("Failed to get routing overrides for product",
+			logger.String("product_id", productID),
+			logger.ErrorField(err),
+		)
+		return nil, fmt.Errorf("failed to get routing overrides: %w", err)
+	}
+
+	return overrides, nil
+}
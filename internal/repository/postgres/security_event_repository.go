@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+)
+
+const securityEventColumns = `id, event_type, reason, user_id, user_role, ip_address,
+	method, resource, requirement, user_agent, created_at`
+
+type securityEventRepository struct {
+	db *sqlx.DB
+}
+
+// NewSecurityEventRepository creates a new security event repository
+func NewSecurityEventRepository(db *sqlx.DB) domain.SecurityEventRepository {
+	return &securityEventRepository{db: db}
+}
+
+// Create stores a security event
+func (r *securityEventRepository) Create(event *domain.SecurityEvent) error {
+	query := `
+		INSERT INTO security_events (
+			id, event_type, reason, user_id, user_role, ip_address,
+			method, resource, requirement, user_agent, created_at
+		) VALUES (
+			:id, :event_type, :reason, :user_id, :user_role, :ip_address,
+			:method, :resource, :requirement, :user_agent, :created_at
+		)`
+
+	if _, err := r.db.NamedExec(query, event); err != nil {
+		return fmt.Errorf("failed to create security event: %w", err)
+	}
+
+	return nil
+}
+
+// List returns the most recent security events matching the filter
+func (r *securityEventRepository) List(filter *domain.SecurityEventFilter) ([]*domain.SecurityEvent, error) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	if filter.EventType != nil {
+		args = append(args, *filter.EventType)
+		conditions = append(conditions, fmt.Sprintf("event_type = $%d", len(args)))
+	}
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+	if filter.IPAddress != nil {
+		args = append(args, *filter.IPAddress)
+		conditions = append(conditions, fmt.Sprintf("ip_address = $%d", len(args)))
+	}
+	if filter.Since != nil {
+		args = append(args, *filter.Since)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+
+	query := "SELECT " + securityEventColumns + " FROM security_events"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d", len(args))
+
+	var events []*domain.SecurityEvent
+	if err := r.db.Select(&events, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list security events: %w", err)
+	}
+
+	return events, nil
+}
+
+// CountSince counts events of a type raised by a user (or, for anonymous
+// requests, by an IP address) since the given time
+func (r *securityEventRepository) CountSince(eventType string, userID *string, ipAddress string, since time.Time) (int, error) {
+	var (
+		count int
+		err   error
+	)
+
+	if userID != nil {
+		query := `SELECT COUNT(*) FROM security_events WHERE event_type = $1 AND user_id = $2 AND created_at >= $3`
+		err = r.db.Get(&count, query, eventType, *userID, since)
+	} else {
+		query := `SELECT COUNT(*) FROM security_events WHERE event_type = $1 AND user_id IS NULL AND ip_address = $2 AND created_at >= $3`
+		err = r.db.Get(&count, query, eventType, ipAddress, since)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to count security events: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sqlFilter collects the conditions of a dynamic filter and numbers their
+// placeholders, so the list and count queries of a filter share one WHERE
+// clause and cannot drift apart
+type sqlFilter struct {
+	conditions []string
+	args       []interface{}
+	err        error // First invalid condition, returned by Clause
+}
+
+func newSQLFilter() *sqlFilter {
+	return &sqlFilter{}
+}
+
+// Where adds a condition. Every ? in it is replaced by the placeholder of the
+// next arg, so conditions must not use the jsonb ? operators. A condition
+// whose placeholders do not match its args is left out and fails Clause.
+func (f *sqlFilter) Where(condition string, args ...interface{}) *sqlFilter {
+	if f.err != nil {
+		return f
+	}
+	if placeholders := strings.Count(condition, "?"); placeholders != len(args) {
+		f.err = fmt.Errorf("sql filter: %q expects %d args, got %d", condition, placeholders, len(args))
+		return f
+	}
+
+	var b strings.Builder
+	next := 0
+	for _, r := range condition {
+		if r == '?' {
+			b.WriteString(f.Arg(args[next]))
+			next++
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	f.conditions = append(f.conditions, b.String())
+	return f
+}
+
+// Arg adds an arg used outside the WHERE clause, such as a limit, and
+// returns its placeholder
+func (f *sqlFilter) Arg(value interface{}) string {
+	f.args = append(f.args, value)
+	return fmt.Sprintf("$%d", len(f.args))
+}
+
+// Clause joins the conditions with AND; TRUE when there are none. It fails
+// when a condition was invalid.
+func (f *sqlFilter) Clause() (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	if len(f.conditions) == 0 {
+		return "TRUE", nil
+	}
+	return strings.Join(f.conditions, " AND "), nil
+}
+
+// Args returns the args in placeholder order
+func (f *sqlFilter) Args() []interface{} {
+	return f.args
+}
@@ -0,0 +1,180 @@
+package postgres
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+)
+
+func TestSQLFilter(t *testing.T) {
+	tests := []struct {
+		name       string
+		build      func(f *sqlFilter) string // Returns the placeholder of a trailing Arg, if any
+		wantClause string
+		wantArgs   []interface{}
+		wantArg    string
+		wantErr    string
+	}{
+		{
+			name:       "empty filter matches everything",
+			build:      func(f *sqlFilter) string { return "" },
+			wantClause: "TRUE",
+			wantArgs:   nil,
+		},
+		{
+			name: "single condition",
+			build: func(f *sqlFilter) string {
+				f.Where("category = ?", "PULSA")
+				return ""
+			},
+			wantClause: "category = $1",
+			wantArgs:   []interface{}{"PULSA"},
+		},
+		{
+			name: "placeholders are renumbered across conditions",
+			build: func(f *sqlFilter) string {
+				f.Where("user_id = ?", "u1").
+					Where("created_at BETWEEN ? AND ?", 1, 2).
+					Where("(code ILIKE ? OR name ILIKE ?)", "%a%", "%a%")
+				return ""
+			},
+			wantClause: "user_id = $1 AND created_at BETWEEN $2 AND $3 AND (code ILIKE $4 OR name ILIKE $5)",
+			wantArgs:   []interface{}{"u1", 1, 2, "%a%", "%a%"},
+		},
+		{
+			name: "condition without placeholders",
+			build: func(f *sqlFilter) string {
+				f.Where("is_active").Where("type = ?", "PREPAID")
+				return ""
+			},
+			wantClause: "is_active AND type = $1",
+			wantArgs:   []interface{}{"PREPAID"},
+		},
+		{
+			name: "trailing arg continues the numbering",
+			build: func(f *sqlFilter) string {
+				f.Where("a = ?", 1).Where("b = ?", 2)
+				return f.Arg(50)
+			},
+			wantClause: "a = $1 AND b = $2",
+			wantArgs:   []interface{}{1, 2, 50},
+			wantArg:    "$3",
+		},
+		{
+			name: "too few args",
+			build: func(f *sqlFilter) string {
+				f.Where("created_at BETWEEN ? AND ?", 1)
+				return ""
+			},
+			wantErr: `"created_at BETWEEN ? AND ?" expects 2 args, got 1`,
+		},
+		{
+			name: "too many args",
+			build: func(f *sqlFilter) string {
+				f.Where("a = ?", 1, 2)
+				return ""
+			},
+			wantErr: `"a = ?" expects 1 args, got 2`,
+		},
+		{
+			name: "first invalid condition sticks",
+			build: func(f *sqlFilter) string {
+				f.Where("a = ?", 1).Where("b = ?").Where("c = ?", 3, 4)
+				return ""
+			},
+			wantErr: `"b = ?" expects 1 args, got 0`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newSQLFilter()
+			placeholder := tt.build(f)
+
+			clause, err := f.Clause()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Clause() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Clause() unexpected error: %v", err)
+			}
+			if clause != tt.wantClause {
+				t.Errorf("Clause() = %q, want %q", clause, tt.wantClause)
+			}
+			if !reflect.DeepEqual(f.Args(), tt.wantArgs) {
+				t.Errorf("Args() = %v, want %v", f.Args(), tt.wantArgs)
+			}
+			if placeholder != tt.wantArg {
+				t.Errorf("Arg() = %q, want %q", placeholder, tt.wantArg)
+			}
+		})
+	}
+}
+
+func TestRepositoryFilterSQL(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	period := domain.DateRange{From: from, To: to}
+	category := "PULSA"
+	active := true
+	query := " tsel "
+	minAmount := 1000.0
+
+	tests := []struct {
+		name       string
+		filter     *sqlFilter
+		wantClause string
+		wantArgs   []interface{}
+	}{
+		{
+			name:       "nil product filter",
+			filter:     productFilterSQL(nil),
+			wantClause: "TRUE",
+		},
+		{
+			name:       "product filter with search",
+			filter:     productFilterSQL(&domain.ProductFilter{Category: &category, IsActive: &active, Query: &query}),
+			wantClause: "category = $1 AND is_active = $2 AND (code ILIKE $3 OR name ILIKE $4)",
+			wantArgs:   []interface{}{"PULSA", true, "%tsel%", "%tsel%"},
+		},
+		{
+			name:       "mutation filter",
+			filter:     mutationFilterSQL(domain.MutationFilter{UserID: "u1", Period: period, Type: "DEBIT", MinAmount: &minAmount}),
+			wantClause: "user_id = $1 AND created_at BETWEEN $2 AND $3 AND type = $4 AND amount >= $5",
+			wantArgs:   []interface{}{"u1", from, to, "DEBIT", 1000.0},
+		},
+		{
+			name:       "transaction history without tags",
+			filter:     transactionHistorySQL("u1", period, nil),
+			wantClause: "user_id = $1 AND created_at BETWEEN $2 AND $3",
+			wantArgs:   []interface{}{"u1", from, to},
+		},
+		{
+			name:       "transaction history with tags",
+			filter:     transactionHistorySQL("u1", period, domain.TransactionTags{"branch": "jkt"}),
+			wantClause: "user_id = $1 AND created_at BETWEEN $2 AND $3 AND tags @> $4::jsonb",
+			wantArgs:   []interface{}{"u1", from, to, domain.TransactionTags{"branch": "jkt"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clause, err := tt.filter.Clause()
+			if err != nil {
+				t.Fatalf("Clause() unexpected error: %v", err)
+			}
+			if clause != tt.wantClause {
+				t.Errorf("Clause() = %q, want %q", clause, tt.wantClause)
+			}
+			if !reflect.DeepEqual(tt.filter.Args(), tt.wantArgs) {
+				t.Errorf("Args() = %v, want %v", tt.filter.Args(), tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const statementJobColumns = `
+	id, user_id, period_year, period_month, format, status, row_count, attempts,
+	file_name, error_message, created_at, updated_at, completed_at`
+
+const statementMutationColumns = `
+	id, user_id, type, amount, balance_before, balance_after, reference_type,
+	reference_id, description, created_at`
+
+const statementTransactionColumns = `
+	id, trx_code, user_id, product_id, product_code, destination_number, hpp,
+	selling_price, admin_fee, profit, status, serial_number, supplier_message,
+	created_at, updated_at, completed_at`
+
+type statementRepository struct {
+	db *sqlx.DB
+}
+
+// NewStatementRepository creates a new account statement repository
+func NewStatementRepository(db *sqlx.DB) domain.StatementRepository {
+	return &statementRepository{db: db}
+}
+
+// CountEntries counts the mutations and transactions of a user within [start, end)
+func (r *statementRepository) CountEntries(userID string, start, end time.Time) (int, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM mutations WHERE user_id = $1 AND created_at >= $2 AND created_at < $3) +
+			(SELECT COUNT(*) FROM transactions WHERE user_id = $1 AND created_at >= $2 AND created_at < $3)
+	`
+
+	var count int
+	if err := r.db.Get(&count, query, userID, start, end); err != nil {
+		return 0, fmt.Errorf("failed to count statement entries: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetOpeningBalance returns the balance of a user at start, derived from the
+// last mutation before start or the first mutation after it
+func (r *statementRepository) GetOpeningBalance(userID string, start time.Time) (float64, error) {
+	query := `
+		SELECT COALESCE(
+			(SELECT balance_after FROM mutations
+				WHERE user_id = $1 AND created_at < $2
+				ORDER BY created_at DESC LIMIT 1),
+			(SELECT balance_before FROM mutations
+				WHERE user_id = $1 AND created_at >= $2
+				ORDER BY created_at ASC LIMIT 1),
+			0
+		)
+	`
+
+	var balance float64
+	if err := r.db.Get(&balance, query, userID, start); err != nil {
+		return 0, fmt.Errorf("failed to get opening balance: %w", err)
+	}
+
+	return balance, nil
+}
+
+// ListMutations lists the mutations of a user within [start, end) in chronological order
+func (r *statementRepository) ListMutations(userID string, start, end time.Time) ([]*domain.Mutation, error) {
+	query := `
+		SELECT ` + statementMutationColumns + `
+		FROM mutations
+		WHERE user_id = $1 AND created_at >= $2 AND created_at < $3
+		ORDER BY created_at ASC, id ASC
+	`
+
+	var mutations []*domain.Mutation
+	if err := r.db.Select(&mutations, query, userID, start, end); err != nil {
+		return nil, fmt.Errorf("failed to list statement mutations: %w", err)
+	}
+
+	return mutations, nil
+}
+
+// ListTransactions lists the transactions of a user within [start, end) in chronological order
+func (r *statementRepository) ListTransactions(userID string, start, end time.Time) ([]*domain.Transaction, error) {
+	query := `
+		SELECT ` + statementTransactionColumns + `
+		FROM transactions
+		WHERE user_id = $1 AND created_at >= $2 AND created_at < $3
+		ORDER BY created_at ASC, id ASC
+	`
+
+	var transactions []*domain.Transaction
+	if err := r.db.Select(&transactions, query, userID, start, end); err != nil {
+		return nil, fmt.Errorf("failed to list statement transactions: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// CreateJob queues a statement job
+func (r *statementRepository) CreateJob(job *domain.StatementJob) error {
+	query := `
+		INSERT INTO statement_jobs (
+			id, user_id, period_year, period_month, format, status, row_count,
+			attempts, created_at, updated_at
+		) VALUES (
+			:id, :user_id, :period_year, :period_month, :format, :status, :row_count,
+			0, NOW(), NOW()
+		)`
+
+	if _, err := r.db.NamedExec(query, job); err != nil {
+		logger.Error("Failed to create statement job",
+			logger.String("user_id", job.UserID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to create statement job: %w", err)
+	}
+
+	return nil
+}
+
+// GetJob retrieves a statement job by ID
+func (r *statementRepository) GetJob(id string) (*domain.StatementJob, error) {
+	query := `SELECT ` + statementJobColumns + ` FROM statement_jobs WHERE id = $1`
+
+	var job domain.StatementJob
+	if err := r.db.Get(&job, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("statement job not found")
+		}
+		return nil, fmt.Errorf("failed to get statement job: %w", err)
+	}
+
+	return &job, nil
+}
+
+// GetLatestJob returns the most recent job for a statement
+func (r *statementRepository) GetLatestJob(userID string, year, month int, format string) (*domain.StatementJob, error) {
+	query := `
+		SELECT ` + statementJobColumns + `
+		FROM statement_jobs
+		WHERE user_id = $1 AND period_year = $2 AND period_month = $3 AND format = $4
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	var job domain.StatementJob
+	if err := r.db.Get(&job, query, userID, year, month, format); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("statement job not found")
+		}
+		return nil, fmt.Errorf("failed to get statement job: %w", err)
+	}
+
+	return &job, nil
+}
+
+// ClaimJobs claims queued jobs, and jobs whose worker stopped updating them,
+// with SKIP LOCKED so concurrent workers never take the same job
+func (r *statementRepository) ClaimJobs(limit int, staleAfter time.Duration) ([]*domain.StatementJob, error) {
+	query := `
+		UPDATE statement_jobs SET
+			status = $1, attempts = attempts + 1, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM statement_jobs
+			WHERE status = $2 OR (status = $1 AND updated_at < $3)
+			ORDER BY created_at ASC
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + statementJobColumns
+
+	var jobs []*domain.StatementJob
+	err := r.db.Select(&jobs, query,
+		domain.StatementJobProcessing, domain.StatementJobPending,
+		time.Now().Add(-staleAfter), limit,
+	)
+	if err != nil {
+		logger.Error("Failed to claim statement jobs", logger.ErrorField(err))
+		return nil, fmt.Errorf("failed to claim statement jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// CompleteJob stores the generated file and marks the job ready
+func (r *statementRepository) CompleteJob(id, fileName string, rowCount int, content []byte) error {
+	query := `
+		UPDATE statement_jobs SET
+			status = $2, file_name = $3, row_count = $4, content = $5,
+			error_message = NULL, completed_at = NOW()
+		WHERE id = $1
+	`
+	return r.exec("complete statement job", query, id, domain.StatementJobReady, fileName, rowCount, content)
+}
+
+// FailJob marks a job failed
+func (r *statementRepository) FailJob(id, message string) error {
+	query := `
+		UPDATE statement_jobs SET
+			status = $2, error_message = $3, completed_at = NOW()
+		WHERE id = $1
+	`
+	return r.exec("fail statement job", query, id, domain.StatementJobFailed, message)
+}
+
+// GetJobContent returns the generated file of a ready job
+func (r *statementRepository) GetJobContent(id string) ([]byte, error) {
+	query := `SELECT content FROM statement_jobs WHERE id = $1 AND status = $2`
+
+	var content []byte
+	if err := r.db.Get(&content, query, id, domain.StatementJobReady); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("statement job not found")
+		}
+		return nil, fmt.Errorf("failed to get statement content: %w", err)
+	}
+
+	return content, nil
+}
+
+func (r *statementRepository) exec(action, query string, args ...interface{}) error {
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		logger.Error("Failed to "+action, logger.ErrorField(err))
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("statement job not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const statusIncidentColumns = `
+	id, title, message, impact, categories, started_at, resolved_at,
+	created_by, created_at, updated_at`
+
+type statusIncidentRepository struct {
+	db *sqlx.DB
+}
+
+// NewStatusIncidentRepository creates a new status incident repository
+func NewStatusIncidentRepository(db *sqlx.DB) domain.StatusIncidentRepository {
+	return &statusIncidentRepository{db: db}
+}
+
+// Create creates a new status incident
+func (r *statusIncidentRepository) Create(incident *domain.StatusIncident) error {
+	query := `
+		INSERT INTO status_incidents (title, message, impact, categories, started_at, resolved_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at`
+
+	err := r.db.QueryRow(query,
+		incident.Title,
+		incident.Message,
+		incident.Impact,
+		pq.Array(incident.Categories),
+		incident.StartedAt,
+		incident.ResolvedAt,
+		incident.CreatedBy,
+	).Scan(&incident.ID, &incident.CreatedAt, &incident.UpdatedAt)
+	if err != nil {
+		logger.Error("Failed to create status incident", logger.ErrorField(err))
+		return fmt.Errorf("failed to create status incident: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a status incident by ID
+func (r *statusIncidentRepository) GetByID(id string) (*domain.StatusIncident, error) {
+	query := `SELECT ` + statusIncidentColumns + ` FROM status_incidents WHERE id = $1`
+
+	incident, err := scanStatusIncident(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("status incident not found")
+		}
+		return nil, fmt.Errorf("failed to get status incident: %w", err)
+	}
+
+	return incident, nil
+}
+
+// Update updates the text, impact, scope and resolution of an incident
+func (r *statusIncidentRepository) Update(incident *domain.StatusIncident) error {
+	query := `
+		UPDATE status_incidents SET
+			title = $2, message = $3, impact = $4, categories = $5,
+			resolved_at = $6, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	err := r.db.QueryRow(query,
+		incident.ID,
+		incident.Title,
+		incident.Message,
+		incident.Impact,
+		pq.Array(incident.Categories),
+		incident.ResolvedAt,
+	).Scan(&incident.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("status incident not found")
+		}
+		logger.Error("Failed to update status incident",
+			logger.String("incident_id", incident.ID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to update status incident: %w", err)
+	}
+
+	return nil
+}
+
+// List returns the incidents still open or resolved since the given time
+func (r *statusIncidentRepository) List(since time.Time, limit int) ([]*domain.StatusIncident, error) {
+	query := `
+		SELECT ` + statusIncidentColumns + `
+		FROM status_incidents
+		WHERE resolved_at IS NULL OR resolved_at >= $1
+		ORDER BY started_at DESC
+		LIMIT $2`
+
+	rows, err := r.db.Query(query, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list status incidents: %w", err)
+	}
+	defer rows.Close()
+
+	incidents := []*domain.StatusIncident{}
+	for rows.Next() {
+		incident, err := scanStatusIncident(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan status incident: %w", err)
+		}
+		incidents = append(incidents, incident)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list status incidents: %w", err)
+	}
+
+	return incidents, nil
+}
+
+// GetCategoryOutcomes counts transactions per product category by final outcome
+func (r *statusIncidentRepository) GetCategoryOutcomes(start, end time.Time) ([]*domain.CategoryOutcome, error) {
+	query := `
+		SELECT p.category,
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE t.status = 'SUCCESS') AS success_count,
+			COUNT(*) FILTER (WHERE t.status IN ('FAILED', 'TIMEOUT')) AS failed_count
+		FROM transactions t
+		JOIN products p ON p.id = t.product_id
+		WHERE t.created_at >= $1 AND t.created_at < $2
+		GROUP BY p.category
+	`
+
+	var outcomes []*domain.CategoryOutcome
+	if err := r.db.Select(&outcomes, query, start, end); err != nil {
+		return nil, fmt.Errorf("failed to get category outcomes: %w", err)
+	}
+
+	return outcomes, nil
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanStatusIncident(row rowScanner) (*domain.StatusIncident, error) {
+	var incident domain.StatusIncident
+	var createdBy sql.NullString
+	if err := row.Scan(
+		&incident.ID,
+		&incident.Title,
+		&incident.Message,
+		&incident.Impact,
+		pq.Array(&incident.Categories),
+		&incident.StartedAt,
+		&incident.ResolvedAt,
+		&createdBy,
+		&incident.CreatedAt,
+		&incident.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if createdBy.Valid {
+		incident.CreatedBy = &createdBy.String
+	}
+	if incident.Categories == nil {
+		incident.Categories = []string{}
+	}
+
+	return &incident, nil
+}
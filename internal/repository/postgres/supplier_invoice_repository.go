@@ -0,0 +1,209 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+// invoiceMatchBatch bounds the IDs looked up per transaction query
+const invoiceMatchBatch = 1000
+
+const supplierInvoiceColumns = `
+	i.id, i.supplier_id, s.code AS supplier_code, i.file_name, i.period_start, i.period_end,
+	i.line_count, i.matched_count, i.missing_count, i.amount_mismatch_count,
+	i.status_mismatch_count, i.duplicate_count, i.unbilled_count,
+	i.invoiced_total, i.expected_total, i.difference, i.uploaded_by, i.created_at`
+
+const invoiceTransactionColumns = `
+	id, trx_code, supplier_id, supplier_trx_id, product_code, destination_number, status, hpp, created_at`
+
+type supplierInvoiceRepository struct {
+	db *sqlx.DB
+}
+
+// NewSupplierInvoiceRepository creates a new supplier invoice repository
+func NewSupplierInvoiceRepository(db *sqlx.DB) domain.SupplierInvoiceRepository {
+	return &supplierInvoiceRepository{db: db}
+}
+
+// Create stores the invoice, then copies its lines in bulk
+func (r *supplierInvoiceRepository) Create(invoice *domain.SupplierInvoice, lines []*domain.SupplierInvoiceLine) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		INSERT INTO supplier_invoices (
+			supplier_id, file_name, period_start, period_end,
+			line_count, matched_count, missing_count, amount_mismatch_count,
+			status_mismatch_count, duplicate_count, unbilled_count,
+			invoiced_total, expected_total, difference, uploaded_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id, created_at`
+
+	err = tx.QueryRow(query,
+		invoice.SupplierID,
+		invoice.FileName,
+		invoice.PeriodStart,
+		invoice.PeriodEnd,
+		invoice.LineCount,
+		invoice.MatchedCount,
+		invoice.MissingCount,
+		invoice.AmountMismatchCount,
+		invoice.StatusMismatchCount,
+		invoice.DuplicateCount,
+		invoice.UnbilledCount,
+		invoice.InvoicedTotal,
+		invoice.ExpectedTotal,
+		invoice.Difference,
+		invoice.UploadedBy,
+	).Scan(&invoice.ID, &invoice.CreatedAt)
+	if err != nil {
+		logger.Error("Failed to create supplier invoice",
+			logger.String("supplier_id", invoice.SupplierID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to create supplier invoice: %w", err)
+	}
+
+	stmt, err := tx.Prepare(pq.CopyIn("supplier_invoice_lines",
+		"invoice_id", "line_number", "supplier_trx_id", "ref_id", "product_code", "destination",
+		"invoiced_status", "invoiced_amount", "transaction_id", "trx_code", "transaction_status",
+		"expected_amount", "result", "note",
+	))
+	if err != nil {
+		return fmt.Errorf("failed to prepare supplier invoice lines: %w", err)
+	}
+	for _, line := range lines {
+		line.InvoiceID = invoice.ID
+		if _, err := stmt.Exec(
+			line.InvoiceID, line.LineNumber, line.SupplierTrxID, line.RefID, line.ProductCode, line.Destination,
+			line.InvoicedStatus, line.InvoicedAmount, line.TransactionID, line.TrxCode, line.TransactionStatus,
+			line.ExpectedAmount, line.Result, line.Note,
+		); err != nil {
+			_ = stmt.Close()
+			return fmt.Errorf("failed to copy supplier invoice line %d: %w", line.LineNumber, err)
+		}
+	}
+	if _, err := stmt.Exec(); err != nil {
+		_ = stmt.Close()
+		return fmt.Errorf("failed to copy supplier invoice lines: %w", err)
+	}
+	if err := stmt.Close(); err != nil {
+		return fmt.Errorf("failed to copy supplier invoice lines: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit supplier invoice: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a supplier invoice by ID
+func (r *supplierInvoiceRepository) GetByID(id string) (*domain.SupplierInvoice, error) {
+	query := `
+		SELECT ` + supplierInvoiceColumns + `
+		FROM supplier_invoices i
+		JOIN suppliers s ON s.id = i.supplier_id
+		WHERE i.id = $1`
+
+	var invoice domain.SupplierInvoice
+	if err := r.db.Get(&invoice, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("supplier invoice not found")
+		}
+		return nil, fmt.Errorf("failed to get supplier invoice: %w", err)
+	}
+
+	return &invoice, nil
+}
+
+// List returns invoices newest first, optionally of one supplier
+func (r *supplierInvoiceRepository) List(supplierID string, limit, offset int) ([]*domain.SupplierInvoice, error) {
+	query := `
+		SELECT ` + supplierInvoiceColumns + `
+		FROM supplier_invoices i
+		JOIN suppliers s ON s.id = i.supplier_id
+		WHERE ($1 = '' OR i.supplier_id::text = $1)
+		ORDER BY i.created_at DESC
+		LIMIT $2 OFFSET $3`
+
+	invoices := []*domain.SupplierInvoice{}
+	if err := r.db.Select(&invoices, query, supplierID, limit, offset); err != nil {
+		return nil, fmt.Errorf("failed to list supplier invoices: %w", err)
+	}
+
+	return invoices, nil
+}
+
+// ListLines returns the lines of an invoice, optionally with one result
+func (r *supplierInvoiceRepository) ListLines(invoiceID, result string) ([]*domain.SupplierInvoiceLine, error) {
+	query := `
+		SELECT id, invoice_id, line_number, supplier_trx_id, ref_id, product_code, destination,
+			invoiced_status, invoiced_amount, transaction_id, trx_code, transaction_status,
+			expected_amount, result, note
+		FROM supplier_invoice_lines
+		WHERE invoice_id = $1 AND ($2 = '' OR result = $2)
+		ORDER BY line_number = 0, line_number, trx_code`
+
+	lines := []*domain.SupplierInvoiceLine{}
+	if err := r.db.Select(&lines, query, invoiceID, result); err != nil {
+		return nil, fmt.Errorf("failed to list supplier invoice lines: %w", err)
+	}
+
+	return lines, nil
+}
+
+// FindTransactions looks the IDs up in batches
+func (r *supplierInvoiceRepository) FindTransactions(supplierID string, supplierTrxIDs, refIDs []string) ([]*domain.InvoiceTransaction, error) {
+	transactions := []*domain.InvoiceTransaction{}
+
+	for start := 0; start < len(refIDs); start += invoiceMatchBatch {
+		batch := refIDs[start:min(start+invoiceMatchBatch, len(refIDs))]
+		var found []*domain.InvoiceTransaction
+		query := `SELECT ` + invoiceTransactionColumns + ` FROM transactions WHERE trx_code = ANY($1)`
+		if err := r.db.Select(&found, query, pq.Array(batch)); err != nil {
+			return nil, fmt.Errorf("failed to find invoice transactions: %w", err)
+		}
+		transactions = append(transactions, found...)
+	}
+
+	for start := 0; start < len(supplierTrxIDs); start += invoiceMatchBatch {
+		batch := supplierTrxIDs[start:min(start+invoiceMatchBatch, len(supplierTrxIDs))]
+		var found []*domain.InvoiceTransaction
+		query := `SELECT ` + invoiceTransactionColumns + ` FROM transactions WHERE supplier_id = $1 AND supplier_trx_id = ANY($2)`
+		if err := r.db.Select(&found, query, supplierID, pq.Array(batch)); err != nil {
+			return nil, fmt.Errorf("failed to find invoice transactions: %w", err)
+		}
+		transactions = append(transactions, found...)
+	}
+
+	return transactions, nil
+}
+
+// ListSuccessful returns the supplier's successful transactions of a period
+func (r *supplierInvoiceRepository) ListSuccessful(supplierID string, from, to time.Time) ([]*domain.InvoiceTransaction, error) {
+	query := `
+		SELECT ` + invoiceTransactionColumns + `
+		FROM transactions
+		WHERE status = 'SUCCESS' AND final_supplier_id = $1
+			AND created_at >= $2 AND created_at < $3
+		ORDER BY created_at`
+
+	var transactions []*domain.InvoiceTransaction
+	if err := r.db.Select(&transactions, query, supplierID, from, to); err != nil {
+		return nil, fmt.Errorf("failed to list successful supplier transactions: %w", err)
+	}
+
+	return transactions, nil
+}
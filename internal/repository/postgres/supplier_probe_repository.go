@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+type supplierProbeRepository struct {
+	db *sqlx.DB
+}
+
+// NewSupplierProbeRepository creates a new supplier latency probe repository
+func NewSupplierProbeRepository(db *sqlx.DB) domain.SupplierProbeRepository {
+	return &supplierProbeRepository{db: db}
+}
+
+// Create stores a probe result
+func (r *supplierProbeRepository) Create(probe *domain.SupplierProbe) error {
+	query := `
+		INSERT INTO supplier_latency_probes (
+			id, supplier_id, operation, latency_ms, success, error_message, probed_at
+		) VALUES (
+			:id, :supplier_id, :operation, :latency_ms, :success, :error_message, :probed_at
+		)`
+
+	if _, err := r.db.NamedExec(query, probe); err != nil {
+		logger.Error("Failed to create supplier probe",
+			logger.String("supplier_id", probe.SupplierID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to create supplier probe: %w", err)
+	}
+
+	return nil
+}
+
+// GetLatencyStats aggregates probes since the given time per supplier
+func (r *supplierProbeRepository) GetLatencyStats(since time.Time) ([]*domain.SupplierLatencyStats, error) {
+	query := `
+		SELECT
+			s.id AS supplier_id,
+			s.code AS supplier_code,
+			s.name AS supplier_name,
+			COUNT(p.id) AS samples,
+			COUNT(p.id) FILTER (WHERE p.success) AS success_count,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY p.latency_ms) FILTER (WHERE p.success) AS p50_ms,
+			percentile_cont(0.95) WITHIN GROUP (ORDER BY p.latency_ms) FILTER (WHERE p.success) AS p95_ms,
+			AVG(p.latency_ms) FILTER (WHERE p.success) AS avg_ms
+		FROM supplier_latency_probes p
+		JOIN suppliers s ON s.id = p.supplier_id
+		WHERE p.probed_at >= $1
+		GROUP BY s.id, s.code, s.name
+		ORDER BY s.code ASC
+	`
+
+	var stats []*domain.SupplierLatencyStats
+	if err := r.db.Select(&stats, query, since); err != nil {
+		logger.Error("Failed to get supplier latency stats", logger.ErrorField(err))
+		return nil, fmt.Errorf("failed to get supplier latency stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// DeleteBefore removes probes older than the given time
+func (r *supplierProbeRepository) DeleteBefore(before time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM supplier_latency_probes WHERE probed_at < $1`, before)
+	if err != nil {
+		logger.Error("Failed to delete old supplier probes", logger.ErrorField(err))
+		return 0, fmt.Errorf("failed to delete old supplier probes: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	return deleted, nil
+}
@@ -3,9 +3,11 @@ package postgres
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/alfanzaky/eraflazz/internal/domain"
 	"github.com/alfanzaky/eraflazz/pkg/logger"
 )
@@ -24,8 +26,9 @@ func (r *supplierRepository) Create(supplier *domain.Supplier) error {
 	query := `
 		INSERT INTO suppliers (id, name, code, api_url, api_key, api_secret, api_username, api_password,
 			is_active, priority, timeout_seconds, retry_attempts, balance, min_balance_threshold,
-			success_rate, avg_response_time_ms, total_transactions, failed_transactions)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+			success_rate, avg_response_time_ms, total_transactions, failed_transactions,
+			adapter_type, sign_method, webhook_secret, request_rules)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
 	`
 
 	_, err := r.db.Exec(query,
@@ -34,9 +37,13 @@ func (r *supplierRepository) Create(supplier *domain.Supplier) error {
 		supplier.Priority, supplier.TimeoutSeconds, supplier.RetryAttempts, supplier.Balance,
 		supplier.MinBalanceThreshold, supplier.SuccessRate, supplier.AvgResponseTimeMs,
 		supplier.TotalTransactions, supplier.FailedTransactions,
+		supplier.AdapterType, supplier.SignMethod, supplier.WebhookSecret, supplier.RequestRules,
 	)
 
 	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return fmt.Errorf("supplier already exists")
+		}
 		logger.Error("Failed to create supplier", 
 			logger.String("code", supplier.Code),
 			logger.ErrorField(err),
@@ -56,9 +63,11 @@ func (r *supplierRepository) Create(supplier *domain.Supplier) error {
 func (r *supplierRepository) GetByID(id string) (*domain.Supplier, error) {
 	query := `
 		SELECT id, name, code, api_url, api_key, api_secret, api_username, api_password,
+			adapter_type, sign_method, webhook_secret, request_rules,
 			is_active, priority, timeout_seconds, retry_attempts, balance, min_balance_threshold,
 			success_rate, avg_response_time_ms, total_transactions, failed_transactions,
-			created_at, updated_at, last_checked_at, last_success_at
+			created_at, updated_at, last_checked_at, last_success_at,
+			is_reachable, ping_failures, last_ping_latency_ms, last_pinged_at
 		FROM suppliers WHERE id = $1
 	`
 
@@ -78,13 +87,43 @@ func (r *supplierRepository) GetByID(id string) (*domain.Supplier, error) {
 	return &supplier, nil
 }
 
+// GetByIDs retrieves the suppliers with the given IDs in a single query
+func (r *supplierRepository) GetByIDs(ids []string) ([]*domain.Supplier, error) {
+	if len(ids) == 0 {
+		return []*domain.Supplier{}, nil
+	}
+
+	query := `
+		SELECT id, name, code, api_url, api_key, api_secret, api_username, api_password,
+			adapter_type, sign_method, webhook_secret, request_rules,
+			is_active, priority, timeout_seconds, retry_attempts, balance, min_balance_threshold,
+			success_rate, avg_response_time_ms, total_transactions, failed_transactions,
+			created_at, updated_at, last_checked_at, last_success_at,
+			is_reachable, ping_failures, last_ping_latency_ms, last_pinged_at
+		FROM suppliers WHERE id = ANY($1)
+	`
+
+	var suppliers []*domain.Supplier
+	if err := r.db.Select(&suppliers, query, pq.Array(ids)); err != nil {
+		logger.Error("Failed to get suppliers by IDs",
+			logger.Int("count", len(ids)),
+			logger.ErrorField(err),
+		)
+		return nil, fmt.Errorf("failed to get suppliers: %w", err)
+	}
+
+	return suppliers, nil
+}
+
 // GetByCode retrieves a supplier by code
 func (r *supplierRepository) GetByCode(code string) (*domain.Supplier, error) {
 	query := `
 		SELECT id, name, code, api_url, api_key, api_secret, api_username, api_password,
+			adapter_type, sign_method, webhook_secret, request_rules,
 			is_active, priority, timeout_seconds, retry_attempts, balance, min_balance_threshold,
 			success_rate, avg_response_time_ms, total_transactions, failed_transactions,
-			created_at, updated_at, last_checked_at, last_success_at
+			created_at, updated_at, last_checked_at, last_success_at,
+			is_reachable, ping_failures, last_ping_latency_ms, last_pinged_at
 		FROM suppliers WHERE code = $1
 	`
 
@@ -112,7 +151,8 @@ func (r *supplierRepository) Update(supplier *domain.Supplier) error {
 			api_username = $7, api_password = $8, is_active = $9, priority = $10,
 			timeout_seconds = $11, retry_attempts = $12, balance = $13, 
 			min_balance_threshold = $14, success_rate = $15, avg_response_time_ms = $16,
-			total_transactions = $17, failed_transactions = $18, last_checked_at = $19, last_success_at = $20
+			total_transactions = $17, failed_transactions = $18, last_checked_at = $19, last_success_at = $20,
+			adapter_type = $21, sign_method = $22, webhook_secret = $23, request_rules = $24
 		WHERE id = $1
 	`
 
@@ -122,7 +162,8 @@ func (r *supplierRepository) Update(supplier *domain.Supplier) error {
 		supplier.Priority, supplier.TimeoutSeconds, supplier.RetryAttempts, supplier.Balance,
 		supplier.MinBalanceThreshold, supplier.SuccessRate, supplier.AvgResponseTimeMs,
 		supplier.TotalTransactions, supplier.FailedTransactions, supplier.LastCheckedAt,
-		supplier.LastSuccessAt,
+		supplier.LastSuccessAt, supplier.AdapterType, supplier.SignMethod, supplier.WebhookSecret,
+		supplier.RequestRules,
 	)
 
 	if err != nil {
@@ -183,9 +224,11 @@ func (r *supplierRepository) Delete(id string) error {
 func (r *supplierRepository) GetActiveSuppliers() ([]*domain.Supplier, error) {
 	query := `
 		SELECT id, name, code, api_url, api_key, api_secret, api_username, api_password,
+			adapter_type, sign_method, webhook_secret, request_rules,
 			is_active, priority, timeout_seconds, retry_attempts, balance, min_balance_threshold,
 			success_rate, avg_response_time_ms, total_transactions, failed_transactions,
-			created_at, updated_at, last_checked_at, last_success_at
+			created_at, updated_at, last_checked_at, last_success_at,
+			is_reachable, ping_failures, last_ping_latency_ms, last_pinged_at
 		FROM suppliers WHERE is_active = true ORDER BY priority ASC, success_rate DESC
 	`
 
@@ -203,9 +246,11 @@ func (r *supplierRepository) GetActiveSuppliers() ([]*domain.Supplier, error) {
 func (r *supplierRepository) GetSuppliersByPriority() ([]*domain.Supplier, error) {
 	query := `
 		SELECT id, name, code, api_url, api_key, api_secret, api_username, api_password,
+			adapter_type, sign_method, webhook_secret, request_rules,
 			is_active, priority, timeout_seconds, retry_attempts, balance, min_balance_threshold,
 			success_rate, avg_response_time_ms, total_transactions, failed_transactions,
-			created_at, updated_at, last_checked_at, last_success_at
+			created_at, updated_at, last_checked_at, last_success_at,
+			is_reachable, ping_failures, last_ping_latency_ms, last_pinged_at
 		FROM suppliers ORDER BY priority ASC, success_rate DESC
 	`
 
@@ -316,13 +361,84 @@ func (r *supplierRepository) UpdateBalance(id string, newBalance float64) error
 	return nil
 }
 
+// RecordProbeLatency blends a probe latency into the average response time
+// the same way transaction samples are, without touching transaction counters
+func (r *supplierRepository) RecordProbeLatency(id string, responseTimeMs int) error {
+	query := `
+		UPDATE suppliers SET
+			avg_response_time_ms = CASE
+				WHEN avg_response_time_ms = 0 THEN $2
+				ELSE (avg_response_time_ms * 0.7 + $2 * 0.3)::integer
+			END,
+			last_checked_at = $3
+		WHERE id = $1
+	`
+
+	result, err := r.db.Exec(query, id, responseTimeMs, time.Now())
+	if err != nil {
+		logger.Error("Failed to record supplier probe latency",
+			logger.String("supplier_id", id),
+			logger.Int("response_time_ms", responseTimeMs),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to record supplier probe latency: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("supplier not found")
+	}
+
+	return nil
+}
+
+// RecordPing stores the result of a supplier ping. A supplier becomes
+// unreachable after unreachableAfter consecutive failures and reachable again
+// on the first success.
+func (r *supplierRepository) RecordPing(id string, success bool, latencyMs, unreachableAfter int) error {
+	query := `
+		UPDATE suppliers SET
+			ping_failures = CASE WHEN $2 THEN 0 ELSE ping_failures + 1 END,
+			is_reachable = CASE WHEN $2 THEN true ELSE ping_failures + 1 < $4 END,
+			last_ping_latency_ms = $3,
+			last_pinged_at = NOW()
+		WHERE id = $1
+	`
+
+	result, err := r.db.Exec(query, id, success, latencyMs, unreachableAfter)
+	if err != nil {
+		logger.Error("Failed to record supplier ping",
+			logger.String("supplier_id", id),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to record supplier ping: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("supplier not found")
+	}
+
+	return nil
+}
+
 // GetHealthySuppliers retrieves suppliers that are healthy (active, good success rate, sufficient balance)
 func (r *supplierRepository) GetHealthySuppliers() ([]*domain.Supplier, error) {
 	query := `
 		SELECT id, name, code, api_url, api_key, api_secret, api_username, api_password,
+			adapter_type, sign_method, webhook_secret, request_rules,
 			is_active, priority, timeout_seconds, retry_attempts, balance, min_balance_threshold,
 			success_rate, avg_response_time_ms, total_transactions, failed_transactions,
-			created_at, updated_at, last_checked_at, last_success_at
+			created_at, updated_at, last_checked_at, last_success_at,
+			is_reachable, ping_failures, last_ping_latency_ms, last_pinged_at
 		FROM suppliers 
 		WHERE is_active = true 
 		AND success_rate >= 50.0 
@@ -370,9 +486,11 @@ func (r *supplierRepository) UpdateLastChecked(id string) error {
 func (r *supplierRepository) GetSuppliersNeedingCheck(checkIntervalMinutes int) ([]*domain.Supplier, error) {
 	query := `
 		SELECT id, name, code, api_url, api_key, api_secret, api_username, api_password,
+			adapter_type, sign_method, webhook_secret, request_rules,
 			is_active, priority, timeout_seconds, retry_attempts, balance, min_balance_threshold,
 			success_rate, avg_response_time_ms, total_transactions, failed_transactions,
-			created_at, updated_at, last_checked_at, last_success_at
+			created_at, updated_at, last_checked_at, last_success_at,
+			is_reachable, ping_failures, last_ping_latency_ms, last_pinged_at
 		FROM suppliers 
 		WHERE is_active = true 
 		AND (last_checked_at IS NULL OR last_checked_at < $1)
@@ -3,6 +3,7 @@ package postgres
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -10,8 +11,24 @@ import (
 	"github.com/alfanzaky/eraflazz/pkg/logger"
 )
 
+// transactionProfitSQL is the profit stored for the transaction row t when its
+// status and HPP are the given SQL expressions: selling price plus admin fee
+// less HPP and the upline commissions still held (paid less reversed) for a
+// successful transaction, 0 otherwise. The admin fee is paid by the buyer on
+// top of the selling price, so it is revenue rather than a cost (see
+// Transaction.CalculateProfit).
+func transactionProfitSQL(status, hpp string) string {
+	return fmt.Sprintf(`CASE WHEN %s = 'SUCCESS' THEN t.selling_price + t.admin_fee - %s - COALESCE((
+			SELECT SUM(CASE WHEN m.reference_type = 'COMMISSION' THEN m.amount ELSE -m.amount END)
+			FROM mutations m
+			WHERE m.reference_id = t.id AND m.created_at >= t.created_at
+				AND ((m.reference_type = 'COMMISSION' AND m.type = 'DEBIT')
+					OR (m.reference_type = 'COMMISSION_REVERSAL' AND m.type = 'CREDIT'))
+		), 0) ELSE 0 END`, status, hpp)
+}
+
 type transactionRepository struct {
-	db *sqlx.DB
+	db dbExecutor
 }
 
 // NewTransactionRepository creates a new transaction repository
@@ -24,16 +41,17 @@ func (r *transactionRepository) Create(transaction *domain.Transaction) error {
 	query := `
 		INSERT INTO transactions (id, trx_code, user_id, product_id, supplier_id,
 			destination_number, product_code, hpp, selling_price, admin_fee,
-			status, user_ip, user_agent, api_endpoint, notes)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+			status, channel, expires_at, scheduled_at, user_ip, user_agent, api_endpoint, notes, tags)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 	`
 
 	_, err := r.db.Exec(query,
 		transaction.ID, transaction.TrxCode, transaction.UserID, transaction.ProductID,
 		transaction.SupplierID, transaction.DestinationNumber, transaction.ProductCode,
 		transaction.HPP, transaction.SellingPrice, transaction.AdminFee,
-		transaction.Status, transaction.UserIP, transaction.UserAgent,
-		transaction.APIEndpoint, transaction.Notes,
+		transaction.Status, transaction.Channel, transaction.ExpiresAt, transaction.ScheduledAt,
+		transaction.UserIP, transaction.UserAgent,
+		transaction.APIEndpoint, transaction.Notes, transaction.Tags,
 	)
 
 	if err != nil {
@@ -57,10 +75,10 @@ func (r *transactionRepository) GetByID(id string) (*domain.Transaction, error)
 	query := `
 		SELECT id, trx_code, user_id, product_id, supplier_id,
 			destination_number, product_code, hpp, selling_price, admin_fee, profit,
-			status, serial_number, supplier_message, supplier_trx_id,
+			status, channel, expires_at, scheduled_at, serial_number, supplier_message, supplier_trx_id,
 			routing_attempts, final_supplier_id,
 			created_at, updated_at, processed_at, completed_at,
-			user_ip, user_agent, api_endpoint, notes
+			user_ip, user_agent, api_endpoint, notes, tags
 		FROM transactions WHERE id = $1
 	`
 
@@ -85,15 +103,22 @@ func (r *transactionRepository) GetByTrxCode(trxCode string) (*domain.Transactio
 	query := `
 		SELECT id, trx_code, user_id, product_id, supplier_id,
 			destination_number, product_code, hpp, selling_price, admin_fee, profit,
-			status, serial_number, supplier_message, supplier_trx_id,
+			status, channel, expires_at, scheduled_at, serial_number, supplier_message, supplier_trx_id,
 			routing_attempts, final_supplier_id,
 			created_at, updated_at, processed_at, completed_at,
-			user_ip, user_agent, api_endpoint, notes
+			user_ip, user_agent, api_endpoint, notes, tags
 		FROM transactions WHERE trx_code = $1
 	`
+	args := []interface{}{trxCode}
+	if day, ok := trxCodeDay(trxCode); ok {
+		// The code carries its creation day, which limits the lookup to one
+		// or two monthly partitions. A day either side covers time zones.
+		query += " AND created_at >= $2 AND created_at < $3"
+		args = append(args, day.AddDate(0, 0, -1), day.AddDate(0, 0, 2))
+	}
 
 	var transaction domain.Transaction
-	err := r.db.Get(&transaction, query, trxCode)
+	err := r.db.Get(&transaction, query, args...)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("transaction not found")
@@ -111,37 +136,35 @@ func (r *transactionRepository) GetByTrxCode(trxCode string) (*domain.Transactio
 // Update updates a transaction
 func (r *transactionRepository) Update(transaction *domain.Transaction) error {
 	query := `
-		UPDATE transactions SET 
+		UPDATE transactions t SET 
 			supplier_id = $2, status = $3, serial_number = $4, supplier_message = $5,
 			supplier_trx_id = $6, routing_attempts = $7, final_supplier_id = $8,
-			processed_at = $9, completed_at = $10, notes = $11
-		WHERE id = $1
+			processed_at = $9, completed_at = $10, notes = $11, hpp = $12,
+			profit = ` + transactionProfitSQL("$3", "$12") + `
+		WHERE t.id = $1
+		RETURNING t.profit
 	`
 
-	result, err := r.db.Exec(query,
+	var profit float64
+	err := r.db.Get(&profit, query,
 		transaction.ID, transaction.SupplierID, transaction.Status,
 		transaction.SerialNumber, transaction.SupplierMessage,
 		transaction.SupplierTrxID, transaction.RoutingAttempts,
 		transaction.FinalSupplierID, transaction.ProcessedAt,
-		transaction.CompletedAt, transaction.Notes,
+		transaction.CompletedAt, transaction.Notes, transaction.HPP,
 	)
 
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("transaction not found")
+		}
 		logger.Error("Failed to update transaction", 
 			logger.String("trx_id", transaction.ID),
 			logger.ErrorField(err),
 		)
 		return fmt.Errorf("failed to update transaction: %w", err)
 	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to check rows affected: %w", err)
-	}
-
-	if rowsAffected == 0 {
-		return fmt.Errorf("transaction not found")
-	}
+	transaction.Profit = profit
 
 	logger.Info("Transaction updated successfully", 
 		logger.String("trx_id", transaction.ID),
@@ -151,23 +174,30 @@ func (r *transactionRepository) Update(transaction *domain.Transaction) error {
 	return nil
 }
 
-// GetByUserID retrieves transactions by user ID with pagination
-func (r *transactionRepository) GetByUserID(userID string, limit, offset int) ([]*domain.Transaction, error) {
+// GetByUserID retrieves transactions by user ID created within period with pagination
+func (r *transactionRepository) GetByUserID(userID string, period domain.DateRange, tags domain.TransactionTags, limit, offset int) ([]*domain.Transaction, error) {
+	if period.IsZero() {
+		return nil, fmt.Errorf("date range is required")
+	}
+
 	query := `
 		SELECT id, trx_code, user_id, product_id, supplier_id,
 			destination_number, product_code, hpp, selling_price, admin_fee, profit,
-			status, serial_number, supplier_message, supplier_trx_id,
+			status, channel, expires_at, scheduled_at, serial_number, supplier_message, supplier_trx_id,
 			routing_attempts, final_supplier_id,
 			created_at, updated_at, processed_at, completed_at,
-			user_ip, user_agent, api_endpoint, notes
+			user_ip, user_agent, api_endpoint, notes, tags
 		FROM transactions 
-		WHERE user_id = $1 
-		ORDER BY created_at DESC 
-		LIMIT $2 OFFSET $3
-	`
+		WHERE `
+	where := transactionHistorySQL(userID, period, tags)
+	clause, err := where.Clause()
+	if err != nil {
+		return nil, err
+	}
+	query += clause + fmt.Sprintf(" ORDER BY created_at DESC LIMIT %s OFFSET %s", where.Arg(limit), where.Arg(offset))
 
 	var transactions []*domain.Transaction
-	err := r.db.Select(&transactions, query, userID, limit, offset)
+	err = r.db.Select(&transactions, query, where.Args()...)
 	if err != nil {
 		logger.Error("Failed to get transactions by user ID", 
 			logger.String("user_id", userID),
@@ -179,12 +209,63 @@ func (r *transactionRepository) GetByUserID(userID string, limit, offset int) ([
 	return transactions, nil
 }
 
+// GetByUserIDAfter retrieves transactions by user ID created within period
+// using keyset pagination
+func (r *transactionRepository) GetByUserIDAfter(userID string, period domain.DateRange, tags domain.TransactionTags, cursor *domain.Cursor, limit int) ([]*domain.Transaction, error) {
+	if period.IsZero() {
+		return nil, fmt.Errorf("date range is required")
+	}
+
+	query := `
+		SELECT id, trx_code, user_id, product_id, supplier_id,
+			destination_number, product_code, hpp, selling_price, admin_fee, profit,
+			status, channel, expires_at, scheduled_at, serial_number, supplier_message, supplier_trx_id,
+			routing_attempts, final_supplier_id,
+			created_at, updated_at, processed_at, completed_at,
+			user_ip, user_agent, api_endpoint, notes, tags
+		FROM transactions
+		WHERE `
+	where := transactionHistorySQL(userID, period, tags)
+	if cursor != nil {
+		where.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+	clause, err := where.Clause()
+	if err != nil {
+		return nil, err
+	}
+	query += clause + fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT %s", where.Arg(limit))
+
+	var transactions []*domain.Transaction
+	err = r.db.Select(&transactions, query, where.Args()...)
+	if err != nil {
+		logger.Error("Failed to get transactions by user ID",
+			logger.String("user_id", userID),
+			logger.ErrorField(err),
+		)
+		return nil, fmt.Errorf("failed to get transactions by user ID: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// transactionHistorySQL builds the WHERE clause of a user's transactions
+// within period; with tags only the transactions carrying all of them match
+func transactionHistorySQL(userID string, period domain.DateRange, tags domain.TransactionTags) *sqlFilter {
+	where := newSQLFilter().
+		Where("user_id = ?", userID).
+		Where("created_at BETWEEN ? AND ?", period.From, period.To)
+	if len(tags) > 0 {
+		where.Where("tags @> ?::jsonb", tags)
+	}
+	return where
+}
+
 // GetByStatus retrieves transactions by status
 func (r *transactionRepository) GetByStatus(status string) ([]*domain.Transaction, error) {
 	query := `
 		SELECT id, trx_code, user_id, product_id, supplier_id,
 			destination_number, product_code, hpp, selling_price, admin_fee, profit,
-			status, serial_number, supplier_message, supplier_trx_id,
+			status, channel, expires_at, scheduled_at, serial_number, supplier_message, supplier_trx_id,
 			routing_attempts, final_supplier_id,
 			created_at, updated_at, processed_at, completed_at,
 			user_ip, user_agent, api_endpoint, notes
@@ -243,6 +324,222 @@ func (r *transactionRepository) UpdateStatus(id, status string) error {
 	return nil
 }
 
+// TransitionStatus updates the transaction status only when it still has the
+// expected status, so concurrent workers cannot both move the same transaction
+func (r *transactionRepository) TransitionStatus(id, from, to string) (bool, error) {
+	query := `UPDATE transactions SET status = $3, updated_at = $4 WHERE id = $1 AND status = $2`
+
+	result, err := r.db.Exec(query, id, from, to, time.Now())
+	if err != nil {
+		logger.Error("Failed to transition transaction status",
+			logger.String("trx_id", id),
+			logger.String("from", from),
+			logger.String("to", to),
+			logger.ErrorField(err),
+		)
+		return false, fmt.Errorf("failed to transition transaction status: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// GetExpiredPending retrieves pending transactions past their auto-cancel deadline
+func (r *transactionRepository) GetExpiredPending(limit int) ([]*domain.Transaction, error) {
+	query := `
+		SELECT id, trx_code, user_id, product_id, supplier_id,
+			destination_number, product_code, hpp, selling_price, admin_fee, profit,
+			status, channel, expires_at, scheduled_at, serial_number, supplier_message, supplier_trx_id,
+			routing_attempts, final_supplier_id,
+			created_at, updated_at, processed_at, completed_at,
+			user_ip, user_agent, api_endpoint, notes
+		FROM transactions
+		WHERE status = $1 AND expires_at IS NOT NULL AND expires_at <= $2
+		ORDER BY expires_at ASC
+		LIMIT $3
+	`
+
+	var transactions []*domain.Transaction
+	err := r.db.Select(&transactions, query, domain.StatusPending, time.Now(), limit)
+	if err != nil {
+		logger.Error("Failed to get expired pending transactions", logger.ErrorField(err))
+		return nil, fmt.Errorf("failed to get expired pending transactions: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// Schedule holds a transaction until a cutoff ends. The expiry is cleared
+// since the auto-cancel countdown restarts on release.
+func (r *transactionRepository) Schedule(id, from string, scheduledAt time.Time) (bool, error) {
+	query := `
+		UPDATE transactions SET
+			status = $3, scheduled_at = $4, expires_at = NULL, updated_at = $5
+		WHERE id = $1 AND status = $2
+	`
+
+	result, err := r.db.Exec(query, id, from, domain.StatusPendingSchedule, scheduledAt, time.Now())
+	if err != nil {
+		logger.Error("Failed to schedule transaction",
+			logger.String("trx_id", id),
+			logger.String("from", from),
+			logger.ErrorField(err),
+		)
+		return false, fmt.Errorf("failed to schedule transaction: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// GetScheduledDue retrieves scheduled transactions due for release
+func (r *transactionRepository) GetScheduledDue(before time.Time, limit int) ([]*domain.Transaction, error) {
+	query := `
+		SELECT id, trx_code, user_id, product_id, supplier_id,
+			destination_number, product_code, hpp, selling_price, admin_fee, profit,
+			status, channel, expires_at, scheduled_at, serial_number, supplier_message, supplier_trx_id,
+			routing_attempts, final_supplier_id,
+			created_at, updated_at, processed_at, completed_at,
+			user_ip, user_agent, api_endpoint, notes
+		FROM transactions
+		WHERE status = $1 AND scheduled_at <= $2
+		ORDER BY scheduled_at ASC
+		LIMIT $3
+	`
+
+	var transactions []*domain.Transaction
+	err := r.db.Select(&transactions, query, domain.StatusPendingSchedule, before, limit)
+	if err != nil {
+		logger.Error("Failed to get scheduled transactions", logger.ErrorField(err))
+		return nil, fmt.Errorf("failed to get scheduled transactions: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// ReleaseScheduled returns a scheduled transaction to PENDING
+func (r *transactionRepository) ReleaseScheduled(id string, expiresAt *time.Time) (bool, error) {
+	query := `
+		UPDATE transactions SET
+			status = $3, scheduled_at = NULL, expires_at = $4, updated_at = $5
+		WHERE id = $1 AND status = $2
+	`
+
+	result, err := r.db.Exec(query, id, domain.StatusPendingSchedule, domain.StatusPending, expiresAt, time.Now())
+	if err != nil {
+		logger.Error("Failed to release scheduled transaction",
+			logger.String("trx_id", id),
+			logger.ErrorField(err),
+		)
+		return false, fmt.Errorf("failed to release scheduled transaction: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// GetProcessingBefore retrieves processing transactions created before the given time
+func (r *transactionRepository) GetProcessingBefore(before time.Time, limit int) ([]*domain.Transaction, error) {
+	query := `
+		SELECT id, trx_code, user_id, product_id, supplier_id,
+			destination_number, product_code, hpp, selling_price, admin_fee, profit,
+			status, channel, expires_at, scheduled_at, serial_number, supplier_message, supplier_trx_id,
+			routing_attempts, final_supplier_id,
+			created_at, updated_at, processed_at, completed_at,
+			user_ip, user_agent, api_endpoint, notes
+		FROM transactions
+		WHERE status = $1 AND created_at <= $2
+		ORDER BY created_at ASC
+		LIMIT $3
+	`
+
+	var transactions []*domain.Transaction
+	err := r.db.Select(&transactions, query, domain.StatusProcessing, before, limit)
+	if err != nil {
+		logger.Error("Failed to get processing transactions", logger.ErrorField(err))
+		return nil, fmt.Errorf("failed to get processing transactions: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// GetChannelUsage counts a user's transactions on a channel by outcome
+func (r *transactionRepository) GetChannelUsage(userID, channel string, period domain.DateRange) (*domain.ChannelUsage, error) {
+	if period.IsZero() {
+		return nil, fmt.Errorf("date range is required")
+	}
+
+	query := `
+		SELECT
+			COUNT(*) AS total_transactions,
+			COUNT(*) FILTER (WHERE status = 'SUCCESS') AS success_count,
+			COUNT(*) FILTER (WHERE status IN ('FAILED', 'TIMEOUT', 'REFUND')) AS failed_count,
+			COUNT(*) FILTER (WHERE status IN ('PENDING', 'PENDING_SCHEDULE', 'PROCESSING')) AS in_progress_count,
+			COALESCE(SUM(selling_price + admin_fee) FILTER (WHERE status = 'SUCCESS'), 0) AS success_amount
+		FROM transactions
+		WHERE user_id = $1 AND channel = $2 AND created_at BETWEEN $3 AND $4
+	`
+
+	var usage domain.ChannelUsage
+	if err := r.db.Get(&usage, query, userID, channel, period.From, period.To); err != nil {
+		logger.Error("Failed to get channel usage",
+			logger.String("user_id", userID),
+			logger.String("channel", channel),
+			logger.ErrorField(err),
+		)
+		return nil, fmt.Errorf("failed to get channel usage: %w", err)
+	}
+
+	return &usage, nil
+}
+
+// FindDuplicate returns the latest in-progress or recently successful
+// transaction of the same user, product and destination
+func (r *transactionRepository) FindDuplicate(userID, productCode, destinationNumber, excludeID string, activeSince, completedSince time.Time) (*domain.Transaction, error) {
+	query := `
+		SELECT id, trx_code, user_id, product_id, supplier_id,
+			destination_number, product_code, hpp, selling_price, admin_fee, profit,
+			status, channel, expires_at, scheduled_at, serial_number, supplier_message, supplier_trx_id,
+			routing_attempts, final_supplier_id,
+			created_at, updated_at, processed_at, completed_at,
+			user_ip, user_agent, api_endpoint, notes
+		FROM transactions
+		WHERE user_id = $1 AND product_code = $2 AND destination_number = $3
+			AND id <> $4 AND created_at >= $5
+			AND (status IN ('PENDING', 'PENDING_SCHEDULE', 'PROCESSING')
+				OR (status = 'SUCCESS' AND completed_at >= $6))
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	var transaction domain.Transaction
+	if err := r.db.Get(&transaction, query, userID, productCode, destinationNumber, excludeID, activeSince, completedSince); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		logger.Error("Failed to find duplicate transaction",
+			logger.String("user_id", userID),
+			logger.String("product_code", productCode),
+			logger.ErrorField(err),
+		)
+		return nil, fmt.Errorf("failed to find duplicate transaction: %w", err)
+	}
+
+	return &transaction, nil
+}
+
 // UpdateSupplierInfo updates supplier information for a transaction
 func (r *transactionRepository) UpdateSupplierInfo(id, supplierID, supplierTrxID string) error {
 	query := `
@@ -279,10 +576,10 @@ func (r *transactionRepository) GetTransactionsByDateRange(startDate, endDate ti
 	query := `
 		SELECT id, trx_code, user_id, product_id, supplier_id,
 			destination_number, product_code, hpp, selling_price, admin_fee, profit,
-			status, serial_number, supplier_message, supplier_trx_id,
+			status, channel, expires_at, scheduled_at, serial_number, supplier_message, supplier_trx_id,
 			routing_attempts, final_supplier_id,
 			created_at, updated_at, processed_at, completed_at,
-			user_ip, user_agent, api_endpoint, notes
+			user_ip, user_agent, api_endpoint, notes, tags
 		FROM transactions 
 		WHERE created_at BETWEEN $1 AND $2 
 		ORDER BY created_at DESC
@@ -411,7 +708,7 @@ func (r *transactionRepository) GetExpiredTransactions(timeoutMinutes int) ([]*d
 	query := `
 		SELECT id, trx_code, user_id, product_id, supplier_id,
 			destination_number, product_code, hpp, selling_price, admin_fee, profit,
-			status, serial_number, supplier_message, supplier_trx_id,
+			status, channel, expires_at, scheduled_at, serial_number, supplier_message, supplier_trx_id,
 			routing_attempts, final_supplier_id,
 			created_at, updated_at, processed_at, completed_at,
 			user_ip, user_agent, api_endpoint, notes
@@ -431,3 +728,48 @@ func (r *transactionRepository) GetExpiredTransactions(timeoutMinutes int) ([]*d
 
 	return transactions, nil
 }
+
+// trxCodeDay returns the creation day carried by a TRX-YYYYMMDD-XXXX code
+func trxCodeDay(trxCode string) (time.Time, bool) {
+	parts := strings.Split(trxCode, "-")
+	if len(parts) != 3 || parts[0] != "TRX" {
+		return time.Time{}, false
+	}
+	day, err := time.ParseInLocation("20060102", parts[1], time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return day, true
+}
+
+// RecomputeProfit recomputes the stored profit of the transactions created in
+// [from, to), skipping rows that already hold the right value
+func (r *transactionRepository) RecomputeProfit(from, to time.Time) (int64, error) {
+	profit := transactionProfitSQL("t.status", "t.hpp")
+	query := `
+		UPDATE transactions t SET profit = ` + profit + `
+		WHERE t.created_at >= $1 AND t.created_at < $2
+			AND t.profit IS DISTINCT FROM ` + profit
+
+	result, err := r.db.Exec(query, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("failed to recompute transaction profit: %w", err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	return updated, nil
+}
+
+// GetFirstCreatedAt returns the creation time of the oldest transaction
+func (r *transactionRepository) GetFirstCreatedAt() (*time.Time, error) {
+	var first sql.NullTime
+	if err := r.db.Get(&first, `SELECT MIN(created_at) FROM transactions`); err != nil {
+		return nil, fmt.Errorf("failed to get first transaction time: %w", err)
+	}
+	if !first.Valid {
+		return nil, nil
+	}
+	return &first.Time, nil
+}
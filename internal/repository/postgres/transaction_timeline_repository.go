@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+)
+
+const transactionTimelineColumns = `
+	id, sequence, transaction_id, event_type, status, supplier_id, attempt, message, details, created_at`
+
+type transactionTimelineRepository struct {
+	db dbExecutor
+}
+
+// NewTransactionTimelineRepository creates a new transaction timeline repository
+func NewTransactionTimelineRepository(db *sqlx.DB) domain.TransactionTimelineRepository {
+	return &transactionTimelineRepository{db: db}
+}
+
+// Append stores a timeline entry; entries are never updated or deleted
+func (r *transactionTimelineRepository) Append(entry *domain.TransactionTimelineEntry) error {
+	if len(entry.Details) > 0 {
+		data, err := json.Marshal(entry.Details)
+		if err != nil {
+			return fmt.Errorf("failed to encode timeline details: %w", err)
+		}
+		details := string(data)
+		entry.DetailsJSON = &details
+	}
+
+	query := `
+		INSERT INTO transaction_events (
+			id, transaction_id, event_type, status, supplier_id, attempt, message, details, created_at
+		) VALUES (
+			:id, :transaction_id, :event_type, :status, :supplier_id, :attempt, :message, :details, :created_at
+		)`
+
+	if _, err := r.db.NamedExec(query, entry); err != nil {
+		return fmt.Errorf("failed to append transaction event: %w", err)
+	}
+
+	return nil
+}
+
+// ListByTransactionID returns the timeline of a transaction in the order it happened
+func (r *transactionTimelineRepository) ListByTransactionID(transactionID string) ([]*domain.TransactionTimelineEntry, error) {
+	query := `SELECT ` + transactionTimelineColumns + `
+		FROM transaction_events
+		WHERE transaction_id = $1
+		ORDER BY sequence ASC`
+
+	var entries []*domain.TransactionTimelineEntry
+	if err := r.db.Select(&entries, query, transactionID); err != nil {
+		return nil, fmt.Errorf("failed to list transaction events: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.DetailsJSON == nil {
+			continue
+		}
+		if err := json.Unmarshal([]byte(*entry.DetailsJSON), &entry.Details); err != nil {
+			return nil, fmt.Errorf("failed to decode timeline details: %w", err)
+		}
+	}
+
+	return entries, nil
+}
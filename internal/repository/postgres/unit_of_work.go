@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+// dbExecutor is satisfied by both *sqlx.DB and *sqlx.Tx so repositories can
+// run inside or outside a database transaction
+type dbExecutor interface {
+	Get(dest interface{}, query string, args ...interface{}) error
+	Select(dest interface{}, query string, args ...interface{}) error
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	NamedExec(query string, arg interface{}) (sql.Result, error)
+}
+
+type unitOfWork struct {
+	db *sqlx.DB
+}
+
+// NewUnitOfWork creates a new unit of work backed by database transactions
+func NewUnitOfWork(db *sqlx.DB) domain.UnitOfWork {
+	return &unitOfWork{db: db}
+}
+
+// Do runs fn inside a database transaction
+func (u *unitOfWork) Do(fn func(repos domain.TxRepositories) error) error {
+	tx, err := u.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(&txRepositories{tx: tx}); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			logger.Error("Failed to rollback transaction", logger.ErrorField(rbErr))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+type txRepositories struct {
+	tx *sqlx.Tx
+}
+
+func (r *txRepositories) Users() domain.UserRepository {
+	return &userRepository{db: r.tx}
+}
+
+func (r *txRepositories) Transactions() domain.TransactionRepository {
+	return &transactionRepository{db: r.tx}
+}
+
+func (r *txRepositories) Mutations() domain.MutationRepository {
+	return &mutationRepository{db: r.tx}
+}
+
+func (r *txRepositories) Events() domain.EventRepository {
+	return &eventRepository{db: r.tx}
+}
+
+func (r *txRepositories) BalanceHolds() domain.BalanceHoldRepository {
+	return &balanceHoldRepository{db: r.tx}
+}
+
+func (r *txRepositories) Timeline() domain.TransactionTimelineRepository {
+	return &transactionTimelineRepository{db: r.tx}
+}
+
+func (r *txRepositories) Transfers() domain.BalanceTransferRepository {
+	return &balanceTransferRepository{db: r.tx}
+}
+
+func (r *txRepositories) CommissionReversals() domain.CommissionReversalRepository {
+	return &commissionReversalRepository{db: r.tx}
+}
+
+func (r *txRepositories) Referrals() domain.ReferralRepository {
+	return &referralRepository{db: r.tx}
+}
+
+func (r *txRepositories) Promotions() domain.PromotionRepository {
+	return &promotionRepository{db: r.tx}
+}
+
+func (r *txRepositories) VoucherCodes() domain.VoucherCodeRepository {
+	return &voucherCodeRepository{db: r.tx}
+}
@@ -0,0 +1,223 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const userProductPriceColumns = `
+	up.id, up.user_id, up.product_id, p.code AS product_code, p.name AS product_name,
+	up.price, up.set_by, up.created_at, up.updated_at`
+
+type userProductPriceRepository struct {
+	db *sqlx.DB
+}
+
+// NewUserProductPriceRepository creates a new price override repository
+func NewUserProductPriceRepository(db *sqlx.DB) domain.UserProductPriceRepository {
+	return &userProductPriceRepository{db: db}
+}
+
+// Upsert creates or replaces the price override of a user on a product
+func (r *userProductPriceRepository) Upsert(price *domain.UserProductPrice) error {
+	query := `
+		INSERT INTO user_product_prices (id, user_id, product_id, price, set_by, created_at, updated_at)
+		VALUES (:id, :user_id, :product_id, :price, :set_by, NOW(), NOW())
+		ON CONFLICT (user_id, product_id) DO UPDATE SET
+			price = EXCLUDED.price,
+			set_by = EXCLUDED.set_by
+		RETURNING id, created_at, updated_at
+	`
+
+	rows, err := r.db.NamedQuery(query, price)
+	if err != nil {
+		logger.Error("Failed to save user product price",
+			logger.String("user_id", price.UserID),
+			logger.String("product_id", price.ProductID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to save user product price: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		if err := rows.Scan(&price.ID, &price.CreatedAt, &price.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to save user product price: %w", err)
+		}
+	}
+
+	return rows.Err()
+}
+
+// Get retrieves the price override of a user on a product
+func (r *userProductPriceRepository) Get(userID, productID string) (*domain.UserProductPrice, error) {
+	query := `
+		SELECT ` + userProductPriceColumns + `
+		FROM user_product_prices up
+		JOIN products p ON p.id = up.product_id
+		WHERE up.user_id = $1 AND up.product_id = $2
+	`
+
+	var price domain.UserProductPrice
+	if err := r.db.Get(&price, query, userID, productID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user product price not found")
+		}
+		return nil, fmt.Errorf("failed to get user product price: %w", err)
+	}
+
+	return &price, nil
+}
+
+// ListByUser lists the price overrides of a user ordered by product code
+func (r *userProductPriceRepository) ListByUser(userID string) ([]*domain.UserProductPrice, error) {
+	query := `
+		SELECT ` + userProductPriceColumns + `
+		FROM user_product_prices up
+		JOIN products p ON p.id = up.product_id
+		WHERE up.user_id = $1
+		ORDER BY p.code ASC
+	`
+
+	prices := make([]*domain.UserProductPrice, 0)
+	if err := r.db.Select(&prices, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to list user product prices: %w", err)
+	}
+
+	return prices, nil
+}
+
+// Delete removes the price override of a user on a product
+func (r *userProductPriceRepository) Delete(userID, productID string) error {
+	result, err := r.db.Exec(`DELETE FROM user_product_prices WHERE user_id = $1 AND product_id = $2`, userID, productID)
+	if err != nil {
+		logger.Error("Failed to delete user product price",
+			logger.String("user_id", userID),
+			logger.String("product_id", productID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to delete user product price: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user product price not found")
+	}
+
+	return nil
+}
+
+const productTierPriceColumns = `
+	tp.id, tp.product_id, p.code AS product_code, p.name AS product_name,
+	tp.level, tp.price, tp.set_by, tp.created_at, tp.updated_at`
+
+type productTierPriceRepository struct {
+	db *sqlx.DB
+}
+
+// NewProductTierPriceRepository creates a new tier price repository
+func NewProductTierPriceRepository(db *sqlx.DB) domain.ProductTierPriceRepository {
+	return &productTierPriceRepository{db: db}
+}
+
+// Upsert creates or replaces the tier price of a product for a level
+func (r *productTierPriceRepository) Upsert(price *domain.ProductTierPrice) error {
+	query := `
+		INSERT INTO product_tier_prices (id, product_id, level, price, set_by, created_at, updated_at)
+		VALUES (:id, :product_id, :level, :price, :set_by, NOW(), NOW())
+		ON CONFLICT (product_id, level) DO UPDATE SET
+			price = EXCLUDED.price,
+			set_by = EXCLUDED.set_by
+		RETURNING id, created_at, updated_at
+	`
+
+	rows, err := r.db.NamedQuery(query, price)
+	if err != nil {
+		logger.Error("Failed to save product tier price",
+			logger.String("product_id", price.ProductID),
+			logger.Int("level", price.Level),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to save product tier price: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		if err := rows.Scan(&price.ID, &price.CreatedAt, &price.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to save product tier price: %w", err)
+		}
+	}
+
+	return rows.Err()
+}
+
+// Get retrieves the tier price of a product for a level
+func (r *productTierPriceRepository) Get(productID string, level int) (*domain.ProductTierPrice, error) {
+	query := `
+		SELECT ` + productTierPriceColumns + `
+		FROM product_tier_prices tp
+		JOIN products p ON p.id = tp.product_id
+		WHERE tp.product_id = $1 AND tp.level = $2
+	`
+
+	var price domain.ProductTierPrice
+	if err := r.db.Get(&price, query, productID, level); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product tier price not found")
+		}
+		return nil, fmt.Errorf("failed to get product tier price: %w", err)
+	}
+
+	return &price, nil
+}
+
+// ListByProduct lists the tier prices of a product ordered by level
+func (r *productTierPriceRepository) ListByProduct(productID string) ([]*domain.ProductTierPrice, error) {
+	query := `
+		SELECT ` + productTierPriceColumns + `
+		FROM product_tier_prices tp
+		JOIN products p ON p.id = tp.product_id
+		WHERE tp.product_id = $1
+		ORDER BY tp.level ASC
+	`
+
+	prices := make([]*domain.ProductTierPrice, 0)
+	if err := r.db.Select(&prices, query, productID); err != nil {
+		return nil, fmt.Errorf("failed to list product tier prices: %w", err)
+	}
+
+	return prices, nil
+}
+
+// Delete removes the tier price of a product for a level
+func (r *productTierPriceRepository) Delete(productID string, level int) error {
+	result, err := r.db.Exec(`DELETE FROM product_tier_prices WHERE product_id = $1 AND level = $2`, productID, level)
+	if err != nil {
+		logger.Error("Failed to delete product tier price",
+			logger.String("product_id", productID),
+			logger.Int("level", level),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to delete product tier price: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("product tier price not found")
+	}
+
+	return nil
+}
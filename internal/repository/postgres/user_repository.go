@@ -11,7 +11,7 @@ import (
 )
 
 type userRepository struct {
-	db *sqlx.DB
+	db dbExecutor
 }
 
 // NewUserRepository creates a new user repository
@@ -55,7 +55,7 @@ func (r *userRepository) Create(user *domain.User) error {
 func (r *userRepository) GetByID(id string) (*domain.User, error) {
 	query := `
 		SELECT id, username, email, password_hash, full_name, phone,
-			upline_id, level, is_active, is_verified, balance, credit_limit,
+			upline_id, level, is_active, is_verified, balance, held_balance, credit_limit,
 			markup_percentage, allow_debt, max_daily_transaction,
 			created_at, updated_at, last_login_at
 		FROM users WHERE id = $1
@@ -81,7 +81,7 @@ func (r *userRepository) GetByID(id string) (*domain.User, error) {
 func (r *userRepository) GetByUsername(username string) (*domain.User, error) {
 	query := `
 		SELECT id, username, email, password_hash, full_name, phone,
-			upline_id, level, is_active, is_verified, balance, credit_limit,
+			upline_id, level, is_active, is_verified, balance, held_balance, credit_limit,
 			markup_percentage, allow_debt, max_daily_transaction,
 			created_at, updated_at, last_login_at
 		FROM users WHERE username = $1
@@ -107,7 +107,7 @@ func (r *userRepository) GetByUsername(username string) (*domain.User, error) {
 func (r *userRepository) GetByEmail(email string) (*domain.User, error) {
 	query := `
 		SELECT id, username, email, password_hash, full_name, phone,
-			upline_id, level, is_active, is_verified, balance, credit_limit,
+			upline_id, level, is_active, is_verified, balance, held_balance, credit_limit,
 			markup_percentage, allow_debt, max_daily_transaction,
 			created_at, updated_at, last_login_at
 		FROM users WHERE email = $1
@@ -133,7 +133,7 @@ func (r *userRepository) GetByEmail(email string) (*domain.User, error) {
 func (r *userRepository) GetByPhone(phone string) (*domain.User, error) {
 	query := `
 		SELECT id, username, email, password_hash, full_name, phone,
-			upline_id, level, is_active, is_verified, balance, credit_limit,
+			upline_id, level, is_active, is_verified, balance, held_balance, credit_limit,
 			markup_percentage, allow_debt, max_daily_transaction,
 			created_at, updated_at, last_login_at
 		FROM users WHERE phone = $1
@@ -232,7 +232,7 @@ func (r *userRepository) Delete(id string) error {
 func (r *userRepository) GetDownlines(uplineID string) ([]*domain.User, error) {
 	query := `
 		SELECT id, username, email, password_hash, full_name, phone,
-			upline_id, level, is_active, is_verified, balance, credit_limit,
+			upline_id, level, is_active, is_verified, balance, held_balance, credit_limit,
 			markup_percentage, allow_debt, max_daily_transaction,
 			created_at, updated_at, last_login_at
 		FROM users WHERE upline_id = $1 ORDER BY created_at DESC
@@ -302,6 +302,108 @@ func (r *userRepository) GetBalance(id string) (float64, error) {
 	return balance, nil
 }
 
+// GetPINHash retrieves the transaction PIN hash of a user
+func (r *userRepository) GetPINHash(id string) (*string, error) {
+	query := `SELECT pin_hash FROM users WHERE id = $1`
+
+	var pinHash *string
+	if err := r.db.Get(&pinHash, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to get PIN: %w", err)
+	}
+
+	return pinHash, nil
+}
+
+// UpdatePIN sets the transaction PIN hash of a user
+func (r *userRepository) UpdatePIN(id, pinHash string) error {
+	query := `UPDATE users SET pin_hash = $2, updated_at = NOW() WHERE id = $1`
+
+	result, err := r.db.Exec(query, id, pinHash)
+	if err != nil {
+		logger.Error("Failed to update PIN",
+			logger.String("user_id", id),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to update PIN: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// GetAmountConfirmThreshold retrieves the order confirmation threshold of a user
+func (r *userRepository) GetAmountConfirmThreshold(id string) (*float64, error) {
+	query := `SELECT amount_confirm_threshold FROM users WHERE id = $1`
+
+	var threshold *float64
+	if err := r.db.Get(&threshold, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to get amount confirmation threshold: %w", err)
+	}
+
+	return threshold, nil
+}
+
+// UpdateAmountConfirmThreshold sets the order confirmation threshold of a
+// user; nil restores the default
+func (r *userRepository) UpdateAmountConfirmThreshold(id string, threshold *float64) error {
+	query := `UPDATE users SET amount_confirm_threshold = $2, updated_at = NOW() WHERE id = $1`
+
+	result, err := r.db.Exec(query, id, threshold)
+	if err != nil {
+		return fmt.Errorf("failed to update amount confirmation threshold: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// UpdatePassword sets the password hash of a user
+func (r *userRepository) UpdatePassword(id, passwordHash string) error {
+	query := `UPDATE users SET password_hash = $2, updated_at = NOW() WHERE id = $1`
+
+	result, err := r.db.Exec(query, id, passwordHash)
+	if err != nil {
+		logger.Error("Failed to update password",
+			logger.String("user_id", id),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
 // UpdateLastLogin updates user's last login time
 func (r *userRepository) UpdateLastLogin(id string) error {
 	query := `UPDATE users SET last_login_at = $2 WHERE id = $1`
@@ -332,7 +434,7 @@ func (r *userRepository) UpdateLastLogin(id string) error {
 func (r *userRepository) GetActiveUsers() ([]*domain.User, error) {
 	query := `
 		SELECT id, username, email, password_hash, full_name, phone,
-			upline_id, level, is_active, is_verified, balance, credit_limit,
+			upline_id, level, is_active, is_verified, balance, held_balance, credit_limit,
 			markup_percentage, allow_debt, max_daily_transaction,
 			created_at, updated_at, last_login_at
 		FROM users WHERE is_active = true ORDER BY created_at DESC
@@ -352,7 +454,7 @@ func (r *userRepository) GetActiveUsers() ([]*domain.User, error) {
 func (r *userRepository) GetUsersByLevel(level int) ([]*domain.User, error) {
 	query := `
 		SELECT id, username, email, password_hash, full_name, phone,
-			upline_id, level, is_active, is_verified, balance, credit_limit,
+			upline_id, level, is_active, is_verified, balance, held_balance, credit_limit,
 			markup_percentage, allow_debt, max_daily_transaction,
 			created_at, updated_at, last_login_at
 		FROM users WHERE level = $1 ORDER BY created_at DESC
@@ -370,3 +472,23 @@ func (r *userRepository) GetUsersByLevel(level int) ([]*domain.User, error) {
 
 	return users, nil
 }
+
+// GetMarkupStatsByLevel summarises the markup of active users per level
+func (r *userRepository) GetMarkupStatsByLevel() ([]*domain.LevelMarkupStats, error) {
+	query := `
+		SELECT level, COUNT(*) AS users,
+			MIN(markup_percentage) AS min_markup,
+			MAX(markup_percentage) AS max_markup,
+			AVG(markup_percentage) AS avg_markup
+		FROM users WHERE is_active = true
+		GROUP BY level ORDER BY level
+	`
+
+	var stats []*domain.LevelMarkupStats
+	if err := r.db.Select(&stats, query); err != nil {
+		logger.Error("Failed to get markup stats by level", logger.ErrorField(err))
+		return nil, fmt.Errorf("failed to get markup stats by level: %w", err)
+	}
+
+	return stats, nil
+}
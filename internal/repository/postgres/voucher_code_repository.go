@@ -0,0 +1,150 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const voucherCodeColumns = `
+	id, product_id, code_encrypted, code_hash, status, transaction_id,
+	expires_at, uploaded_by, allocated_at, created_at, updated_at`
+
+type voucherCodeRepository struct {
+	db dbExecutor
+}
+
+// NewVoucherCodeRepository creates a new voucher code repository
+func NewVoucherCodeRepository(db *sqlx.DB) domain.VoucherCodeRepository {
+	return &voucherCodeRepository{db: db}
+}
+
+// Create stores a code unless a code with the same hash exists
+func (r *voucherCodeRepository) Create(code *domain.VoucherCode) (bool, error) {
+	query := `
+		INSERT INTO voucher_codes (` + voucherCodeColumns + `
+		) VALUES (
+			:id, :product_id, :code_encrypted, :code_hash, :status, :transaction_id,
+			:expires_at, :uploaded_by, :allocated_at, :created_at, :updated_at
+		)
+		ON CONFLICT (code_hash) DO NOTHING`
+
+	result, err := r.db.NamedExec(query, code)
+	if err != nil {
+		logger.Error("Failed to create voucher code",
+			logger.String("product_id", code.ProductID),
+			logger.ErrorField(err),
+		)
+		return false, fmt.Errorf("failed to create voucher code: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// Allocate assigns the available, unexpired code expiring first to a
+// transaction. Codes locked by a concurrent allocation are skipped.
+func (r *voucherCodeRepository) Allocate(productID, transactionID string) (*domain.VoucherCode, error) {
+	query := `
+		UPDATE voucher_codes SET
+			status = 'ALLOCATED', transaction_id = $2, allocated_at = NOW(), updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM voucher_codes
+			WHERE product_id = $1 AND status = 'AVAILABLE'
+				AND (expires_at IS NULL OR expires_at > NOW())
+			ORDER BY expires_at NULLS LAST, created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + voucherCodeColumns
+
+	var code domain.VoucherCode
+	if err := r.db.Get(&code, query, productID, transactionID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to allocate voucher code: %w", err)
+	}
+
+	return &code, nil
+}
+
+// SyncProductStock sets the product's stock quantity to its available,
+// unexpired codes
+func (r *voucherCodeRepository) SyncProductStock(productID string) (int, error) {
+	query := `
+		UPDATE products SET
+			stock_quantity = (
+				SELECT COUNT(*) FROM voucher_codes
+				WHERE product_id = $1 AND status = 'AVAILABLE'
+					AND (expires_at IS NULL OR expires_at > NOW())
+			),
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING stock_quantity`
+
+	var available int
+	if err := r.db.Get(&available, query, productID); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("product not found")
+		}
+		return 0, fmt.Errorf("failed to sync voucher stock: %w", err)
+	}
+
+	return available, nil
+}
+
+// SetStockMode switches a product between the code stock and its suppliers
+func (r *voucherCodeRepository) SetStockMode(productID string, enabled bool) error {
+	query := `UPDATE products SET is_voucher_stock = $2, updated_at = NOW() WHERE id = $1`
+
+	result, err := r.db.Exec(query, productID, enabled)
+	if err != nil {
+		logger.Error("Failed to set voucher stock mode",
+			logger.String("product_id", productID),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to set voucher stock mode: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("product not found")
+	}
+
+	return nil
+}
+
+// GetStock counts the codes of a product by state
+func (r *voucherCodeRepository) GetStock(productID string) (*domain.VoucherStock, error) {
+	query := `
+		SELECT p.id AS product_id, p.code AS product_code,
+			COUNT(v.id) FILTER (WHERE v.status = 'AVAILABLE' AND (v.expires_at IS NULL OR v.expires_at > NOW())) AS available,
+			COUNT(v.id) FILTER (WHERE v.status = 'ALLOCATED') AS allocated,
+			COUNT(v.id) FILTER (WHERE v.status = 'AVAILABLE' AND v.expires_at <= NOW()) AS expired
+		FROM products p
+		LEFT JOIN voucher_codes v ON v.product_id = p.id
+		WHERE p.id = $1
+		GROUP BY p.id, p.code`
+
+	var stock domain.VoucherStock
+	if err := r.db.Get(&stock, query, productID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product not found")
+		}
+		return nil, fmt.Errorf("failed to get voucher stock: %w", err)
+	}
+
+	return &stock, nil
+}
@@ -0,0 +1,73 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/go-redis/redis/v8"
+)
+
+// AmountConfirmationKeyPrefix prefixes issued order confirmation tokens
+const AmountConfirmationKeyPrefix = "trx:confirm:"
+
+type amountConfirmationRepository struct {
+	client redis.UniversalClient
+}
+
+// NewAmountConfirmationRepository creates a new Redis amount confirmation repository
+func NewAmountConfirmationRepository(client redis.UniversalClient) domain.AmountConfirmationRepository {
+	return &amountConfirmationRepository{client: client}
+}
+
+// Save stores a confirmation token until it expires
+func (r *amountConfirmationRepository) Save(confirmation *domain.AmountConfirmation) error {
+	ttl := time.Until(confirmation.ExpiresAt)
+	if ttl <= 0 {
+		return fmt.Errorf("confirmation already expired")
+	}
+
+	data, err := json.Marshal(confirmation)
+	if err != nil {
+		return fmt.Errorf("failed to marshal amount confirmation: %w", err)
+	}
+	if err := r.client.Set(context.Background(), AmountConfirmationKeyPrefix+confirmation.Token, data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save amount confirmation: %w", err)
+	}
+
+	return nil
+}
+
+// Consume reads and deletes a token in one transaction, so concurrent
+// orders on other replicas cannot redeem it twice
+func (r *amountConfirmationRepository) Consume(token string) (*domain.AmountConfirmation, error) {
+	ctx := context.Background()
+	key := AmountConfirmationKeyPrefix + token
+
+	var get *redis.StringCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to consume amount confirmation: %w", err)
+	}
+
+	data, err := get.Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to consume amount confirmation: %w", err)
+	}
+
+	var confirmation domain.AmountConfirmation
+	if err := json.Unmarshal(data, &confirmation); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal amount confirmation: %w", err)
+	}
+
+	return &confirmation, nil
+}
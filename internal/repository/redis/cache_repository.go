@@ -12,13 +12,16 @@ import (
 )
 
 type cacheRepository struct {
-	client *redis.Client
+	client redis.UniversalClient
 }
 
-var _ domain.QueueRepository = (*cacheRepository)(nil)
+var (
+	_ domain.QueueRepository        = (*cacheRepository)(nil)
+	_ domain.ProductCacheRepository = (*cacheRepository)(nil)
+)
 
 // NewCacheRepository creates a new Redis cache repository
-func NewCacheRepository(client *redis.Client) *cacheRepository {
+func NewCacheRepository(client redis.UniversalClient) *cacheRepository {
 	return &cacheRepository{client: client}
 }
 
@@ -40,6 +43,40 @@ const (
 	ProductMappingTTL   = 30 * time.Minute
 )
 
+// queueBatchSize caps the IDs of one LPUSH in a batched enqueue
+const queueBatchSize = 500
+
+// Queue failover retries: 0.2s, 0.4s, 0.8s and 1.6s between attempts covers
+// a typical sentinel promotion
+const (
+	queueRetryAttempts = 5
+	queueRetryBackoff  = 200 * time.Millisecond
+)
+
+// retryTransient runs a queue operation again while Redis is failing over.
+// A retried push may be applied twice when the reply was lost, which the
+// transaction worker tolerates because processing claims the transaction
+// with a conditional status update.
+func (r *cacheRepository) retryTransient(operation string, fn func() error) error {
+	backoff := queueRetryBackoff
+	var err error
+	for attempt := 1; attempt <= queueRetryAttempts; attempt++ {
+		err = fn()
+		if err == nil || !IsTransientError(err) || attempt == queueRetryAttempts {
+			return err
+		}
+
+		logger.Warn("Transient Redis error, retrying queue operation",
+			logger.String("operation", operation),
+			logger.Int("attempt", attempt),
+			logger.ErrorField(err),
+		)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	return err
+}
+
 // User caching
 func (r *cacheRepository) CacheUser(user *domain.User) error {
 	key := UserKeyPrefix + user.ID
@@ -213,6 +250,79 @@ func (r *cacheRepository) GetProductByCode(code string) (*domain.Product, error)
 	return &product, nil
 }
 
+// GetProducts reads cached products by ID in one pipelined round trip;
+// misses are left out of the result. A pipeline of GETs is used instead of
+// MGET, which fails when the keys span cluster slots.
+func (r *cacheRepository) GetProducts(productIDs []string) (map[string]*domain.Product, error) {
+	products := make(map[string]*domain.Product, len(productIDs))
+	if len(productIDs) == 0 {
+		return products, nil
+	}
+
+	ctx := context.Background()
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.StringCmd, len(productIDs))
+	for i, productID := range productIDs {
+		cmds[i] = pipe.Get(ctx, ProductKeyPrefix+productID)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		logger.Error("Failed to get products from cache",
+			logger.Int("count", len(productIDs)),
+			logger.ErrorField(err),
+		)
+		return nil, fmt.Errorf("failed to get products from cache: %w", err)
+	}
+
+	for i, cmd := range cmds {
+		data, err := cmd.Bytes()
+		if err != nil {
+			continue // Cache miss
+		}
+		var product domain.Product
+		if err := json.Unmarshal(data, &product); err != nil {
+			logger.Warn("Skipping malformed cached product",
+				logger.String("product_id", productIDs[i]),
+				logger.ErrorField(err),
+			)
+			continue
+		}
+		products[productIDs[i]] = &product
+	}
+
+	logger.Debug("Products retrieved from cache",
+		logger.Int("requested", len(productIDs)),
+		logger.Int("hits", len(products)),
+	)
+
+	return products, nil
+}
+
+// CacheProducts caches products by ID in one pipelined round trip
+func (r *cacheRepository) CacheProducts(products []*domain.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	pipe := r.client.Pipeline()
+	for _, product := range products {
+		data, err := json.Marshal(product)
+		if err != nil {
+			return fmt.Errorf("failed to marshal product: %w", err)
+		}
+		pipe.Set(ctx, ProductKeyPrefix+product.ID, data, ProductCacheTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Error("Failed to cache products",
+			logger.Int("count", len(products)),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to cache products: %w", err)
+	}
+
+	return nil
+}
+
 // Supplier caching
 func (r *cacheRepository) CacheSupplier(supplier *domain.Supplier) error {
 	key := SupplierKeyPrefix + supplier.ID
@@ -384,7 +494,9 @@ func (r *cacheRepository) GetProductMappings(productID string) ([]*domain.Produc
 func (r *cacheRepository) EnqueueTransaction(transactionID string) error {
 	queueKey := "transaction_queue"
 
-	err := r.client.LPush(context.Background(), queueKey, transactionID).Err()
+	err := r.retryTransient("enqueue", func() error {
+		return r.client.LPush(context.Background(), queueKey, transactionID).Err()
+	})
 	if err != nil {
 		logger.Error("Failed to enqueue transaction",
 			logger.String("transaction_id", transactionID),
@@ -400,10 +512,53 @@ func (r *cacheRepository) EnqueueTransaction(transactionID string) error {
 	return nil
 }
 
+// EnqueueTransactions pushes transaction IDs in their order with one
+// pipelined round trip, at most queueBatchSize IDs per LPUSH. A retry after a
+// lost reply may push some IDs twice, which the worker tolerates.
+func (r *cacheRepository) EnqueueTransactions(transactionIDs []string) error {
+	if len(transactionIDs) == 0 {
+		return nil
+	}
+	queueKey := "transaction_queue"
+
+	err := r.retryTransient("enqueue_batch", func() error {
+		ctx := context.Background()
+		pipe := r.client.Pipeline()
+		for start := 0; start < len(transactionIDs); start += queueBatchSize {
+			end := min(start+queueBatchSize, len(transactionIDs))
+			values := make([]interface{}, 0, end-start)
+			for _, transactionID := range transactionIDs[start:end] {
+				values = append(values, transactionID)
+			}
+			pipe.LPush(ctx, queueKey, values...)
+		}
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+	if err != nil {
+		logger.Error("Failed to enqueue transactions",
+			logger.Int("count", len(transactionIDs)),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("failed to enqueue transactions: %w", err)
+	}
+
+	logger.Debug("Transactions enqueued",
+		logger.Int("count", len(transactionIDs)),
+	)
+
+	return nil
+}
+
 func (r *cacheRepository) DequeueTransaction() (string, error) {
 	queueKey := "transaction_queue"
 
-	result, err := r.client.BRPop(context.Background(), 5*time.Second, queueKey).Result()
+	var result []string
+	err := r.retryTransient("dequeue", func() error {
+		var err error
+		result, err = r.client.BRPop(context.Background(), 5*time.Second, queueKey).Result()
+		return err
+	})
 	if err != nil {
 		if err == redis.Nil {
 			return "", nil // No items in queue
@@ -427,7 +582,12 @@ func (r *cacheRepository) DequeueTransaction() (string, error) {
 func (r *cacheRepository) GetQueueLength() (int64, error) {
 	queueKey := "transaction_queue"
 
-	length, err := r.client.LLen(context.Background(), queueKey).Result()
+	var length int64
+	err := r.retryTransient("length", func() error {
+		var err error
+		length, err = r.client.LLen(context.Background(), queueKey).Result()
+		return err
+	})
 	if err != nil {
 		logger.Error("Failed to get queue length", logger.ErrorField(err))
 		return 0, fmt.Errorf("failed to get queue length: %w", err)
@@ -443,5 +603,11 @@ func (r *cacheRepository) Ping() error {
 
 // Clear cache (for testing)
 func (r *cacheRepository) ClearAll() error {
-	return r.client.FlushDB(context.Background()).Err()
+	ctx := context.Background()
+	if cluster, ok := r.client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, master *redis.Client) error {
+			return master.FlushDB(ctx).Err()
+		})
+	}
+	return r.client.FlushDB(ctx).Err()
 }
@@ -0,0 +1,141 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/go-redis/redis/v8"
+)
+
+// benchmarkBatchSizes are the batch sizes the pipelined and per-key paths
+// are compared at
+var benchmarkBatchSizes = []int{10, 100, 1000}
+
+// newBenchmarkCache connects to the Redis named by REDIS_TEST_ADDR, using
+// REDIS_TEST_DB (default 15) since the benchmarks write to the queue and
+// product keys. Benchmarks are skipped without a server.
+func newBenchmarkCache(b *testing.B) *cacheRepository {
+	b.Helper()
+
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		b.Skip("REDIS_TEST_ADDR is not set")
+	}
+	db := 15
+	if value := os.Getenv("REDIS_TEST_DB"); value != "" {
+		var err error
+		if db, err = strconv.Atoi(value); err != nil {
+			b.Fatalf("invalid REDIS_TEST_DB %q: %v", value, err)
+		}
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: addr, DB: db})
+	b.Cleanup(func() { client.Close() })
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		b.Skipf("redis at %s is unavailable: %v", addr, err)
+	}
+
+	// Debug logs on every call would dominate the per-key timings
+	logger.Init("production")
+	return NewCacheRepository(client)
+}
+
+func benchmarkTransactionIDs(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("bench-trx-%06d", i)
+	}
+	return ids
+}
+
+func BenchmarkEnqueueTransactions(b *testing.B) {
+	cache := newBenchmarkCache(b)
+	ctx := context.Background()
+	b.Cleanup(func() { cache.client.Del(ctx, "transaction_queue") })
+
+	for _, size := range benchmarkBatchSizes {
+		ids := benchmarkTransactionIDs(size)
+
+		b.Run(fmt.Sprintf("pipelined/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := cache.EnqueueTransactions(ids); err != nil {
+					b.Fatal(err)
+				}
+				b.StopTimer()
+				cache.client.Del(ctx, "transaction_queue")
+				b.StartTimer()
+			}
+		})
+
+		b.Run(fmt.Sprintf("per_key/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, id := range ids {
+					if err := cache.EnqueueTransaction(id); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.StopTimer()
+				cache.client.Del(ctx, "transaction_queue")
+				b.StartTimer()
+			}
+		})
+	}
+}
+
+func BenchmarkGetProducts(b *testing.B) {
+	cache := newBenchmarkCache(b)
+	ctx := context.Background()
+
+	for _, size := range benchmarkBatchSizes {
+		products := make([]*domain.Product, size)
+		ids := make([]string, size)
+		keys := make([]string, size)
+		for i := range products {
+			ids[i] = fmt.Sprintf("bench-product-%06d", i)
+			keys[i] = ProductKeyPrefix + ids[i]
+			products[i] = &domain.Product{
+				ID:           ids[i],
+				Code:         fmt.Sprintf("BENCH%d", i),
+				Name:         "Benchmark product",
+				Category:     "PULSA",
+				SellingPrice: 10000,
+				IsActive:     true,
+			}
+		}
+		if err := cache.CacheProducts(products); err != nil {
+			b.Fatal(err)
+		}
+		b.Cleanup(func() { cache.client.Del(ctx, keys...) })
+
+		b.Run(fmt.Sprintf("pipelined/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				found, err := cache.GetProducts(ids)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(found) != size {
+					b.Fatalf("got %d products, want %d", len(found), size)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("per_key/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, id := range ids {
+					product, err := cache.GetProduct(id)
+					if err != nil {
+						b.Fatal(err)
+					}
+					if product == nil {
+						b.Fatalf("product %s missing from cache", id)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package redis
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Redis deployment modes
+const (
+	ModeStandalone = "standalone"
+	ModeSentinel   = "sentinel"
+	ModeCluster    = "cluster"
+)
+
+// ClientOptions describes how to connect to Redis in any deployment mode
+type ClientOptions struct {
+	Mode             string
+	Addrs            []string // Node address (standalone), sentinels or cluster seed nodes
+	MasterName       string   // Sentinel master set name
+	Username         string
+	Password         string
+	SentinelPassword string
+	DB               int
+	PoolSize         int
+	MaxRetries       int
+}
+
+// NewUniversalClient builds a standalone, sentinel (failover) or cluster
+// client. Repositories only depend on redis.UniversalClient so they work with
+// every mode.
+func NewUniversalClient(opts ClientOptions) (redis.UniversalClient, error) {
+	universal := &redis.UniversalOptions{
+		Addrs:            opts.Addrs,
+		MasterName:       opts.MasterName,
+		Username:         opts.Username,
+		Password:         opts.Password,
+		SentinelPassword: opts.SentinelPassword,
+		DB:               opts.DB,
+		PoolSize:         opts.PoolSize,
+		MaxRetries:       opts.MaxRetries,
+	}
+
+	switch strings.ToLower(strings.TrimSpace(opts.Mode)) {
+	case "", ModeStandalone:
+		return redis.NewClient(universal.Simple()), nil
+	case ModeSentinel:
+		if opts.MasterName == "" {
+			return nil, fmt.Errorf("redis sentinel mode requires a master name")
+		}
+		return redis.NewFailoverClient(universal.Failover()), nil
+	case ModeCluster:
+		if opts.DB != 0 {
+			return nil, fmt.Errorf("redis cluster mode only supports database 0")
+		}
+		return redis.NewClusterClient(universal.Cluster()), nil
+	default:
+		return nil, fmt.Errorf("unknown redis mode %q", opts.Mode)
+	}
+}
+
+// transientErrorPrefixes are server replies seen while a master fails over,
+// a replica is promoted or cluster slots are migrating
+var transientErrorPrefixes = []string{
+	"READONLY ",
+	"LOADING ",
+	"MASTERDOWN ",
+	"TRYAGAIN ",
+	"CLUSTERDOWN ",
+}
+
+// IsTransientError reports whether err is expected to clear once a failover
+// completes: dropped connections, timeouts and the replies above
+func IsTransientError(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) || errors.Is(err, redis.ErrClosed) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	msg := err.Error()
+	for _, prefix := range transientErrorPrefixes {
+		if strings.HasPrefix(msg, prefix) {
+			return true
+		}
+	}
+	return strings.Contains(msg, "connection refused") ||
+		strings.Contains(msg, "connection reset") ||
+		strings.Contains(msg, "connection pool timeout") ||
+		strings.Contains(msg, "sentinels specified in configuration are unreachable")
+}
@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/go-redis/redis/v8"
+)
+
+// FeatureFlagKeyPrefix prefixes the cached flags of an environment
+const FeatureFlagKeyPrefix = "feature_flags:"
+
+type featureFlagCacheRepository struct {
+	client redis.UniversalClient
+}
+
+// NewFeatureFlagCacheRepository creates a new Redis feature flag cache repository
+func NewFeatureFlagCacheRepository(client redis.UniversalClient) domain.FeatureFlagCacheRepository {
+	return &featureFlagCacheRepository{client: client}
+}
+
+// GetFlags returns the cached flags of an environment, or nil on a miss
+func (r *featureFlagCacheRepository) GetFlags(environment string) ([]*domain.FeatureFlag, error) {
+	data, err := r.client.Get(context.Background(), FeatureFlagKeyPrefix+environment).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get feature flags: %w", err)
+	}
+
+	flags := make([]*domain.FeatureFlag, 0)
+	if err := json.Unmarshal(data, &flags); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal feature flags: %w", err)
+	}
+
+	return flags, nil
+}
+
+// SetFlags caches the flags of an environment; an empty list is cached too
+func (r *featureFlagCacheRepository) SetFlags(environment string, flags []*domain.FeatureFlag, ttl time.Duration) error {
+	if flags == nil {
+		flags = []*domain.FeatureFlag{}
+	}
+	data, err := json.Marshal(flags)
+	if err != nil {
+		return fmt.Errorf("failed to marshal feature flags: %w", err)
+	}
+
+	if err := r.client.Set(context.Background(), FeatureFlagKeyPrefix+environment, data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to cache feature flags: %w", err)
+	}
+
+	return nil
+}
+
+// InvalidateFlags drops the cached flags of an environment
+func (r *featureFlagCacheRepository) InvalidateFlags(environment string) error {
+	if err := r.client.Del(context.Background(), FeatureFlagKeyPrefix+environment).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate feature flags: %w", err)
+	}
+
+	return nil
+}
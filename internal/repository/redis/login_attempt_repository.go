@@ -0,0 +1,119 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/go-redis/redis/v8"
+)
+
+// Login throttle keys
+const (
+	LoginFailureKeyPrefix   = "login:fail:"
+	LoginLockKeyPrefix      = "login:lock:"
+	LoginLockCountKeyPrefix = "login:lockcount:"
+)
+
+type loginAttemptRepository struct {
+	client redis.UniversalClient
+}
+
+// NewLoginAttemptRepository creates a new Redis backed login attempt repository
+func NewLoginAttemptRepository(client redis.UniversalClient) domain.LoginAttemptRepository {
+	return &loginAttemptRepository{client: client}
+}
+
+// loginKey wraps the subject in a hash tag so the failure, lock and lock count
+// keys of one subject share a cluster slot for the multi-key commands below
+func loginKey(prefix, subjectType, subject string) string {
+	return prefix + "{" + subjectType + ":" + strings.ToLower(strings.TrimSpace(subject)) + "}"
+}
+
+// IncrementFailures increments the failure counter, starting the window on the first failure
+func (r *loginAttemptRepository) IncrementFailures(subjectType, subject string, window time.Duration) (int, error) {
+	ctx := context.Background()
+	key := loginKey(LoginFailureKeyPrefix, subjectType, subject)
+
+	count, err := r.client.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment login failures: %w", err)
+	}
+	if count == 1 {
+		if err := r.client.Expire(ctx, key, window).Err(); err != nil {
+			return 0, fmt.Errorf("failed to set login failure window: %w", err)
+		}
+	}
+
+	return int(count), nil
+}
+
+// ResetFailures clears the failure counter
+func (r *loginAttemptRepository) ResetFailures(subjectType, subject string) error {
+	key := loginKey(LoginFailureKeyPrefix, subjectType, subject)
+	if err := r.client.Del(context.Background(), key).Err(); err != nil {
+		return fmt.Errorf("failed to reset login failures: %w", err)
+	}
+	return nil
+}
+
+// Lock locks the subject and bumps its lock history
+func (r *loginAttemptRepository) Lock(subjectType, subject string, duration, lockHistoryTTL time.Duration) (int, error) {
+	ctx := context.Background()
+	lockKey := loginKey(LoginLockKeyPrefix, subjectType, subject)
+	countKey := loginKey(LoginLockCountKeyPrefix, subjectType, subject)
+	failureKey := loginKey(LoginFailureKeyPrefix, subjectType, subject)
+
+	pipe := r.client.TxPipeline()
+	pipe.Set(ctx, lockKey, time.Now().Add(duration).Unix(), duration)
+	count := pipe.Incr(ctx, countKey)
+	pipe.Expire(ctx, countKey, lockHistoryTTL)
+	pipe.Del(ctx, failureKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to lock login subject: %w", err)
+	}
+
+	return int(count.Val()), nil
+}
+
+// LockCount returns how many times the subject has been locked within the history TTL
+func (r *loginAttemptRepository) LockCount(subjectType, subject string) (int, error) {
+	key := loginKey(LoginLockCountKeyPrefix, subjectType, subject)
+	count, err := r.client.Get(context.Background(), key).Int()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get login lock count: %w", err)
+	}
+	return count, nil
+}
+
+// GetLockTTL returns the remaining lock duration
+func (r *loginAttemptRepository) GetLockTTL(subjectType, subject string) (time.Duration, error) {
+	key := loginKey(LoginLockKeyPrefix, subjectType, subject)
+	ttl, err := r.client.PTTL(context.Background(), key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get login lock: %w", err)
+	}
+	// -2 means the key does not exist, -1 means no expiry (never set by Lock)
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// Unlock clears all throttle state for the subject
+func (r *loginAttemptRepository) Unlock(subjectType, subject string) error {
+	err := r.client.Del(context.Background(),
+		loginKey(LoginLockKeyPrefix, subjectType, subject),
+		loginKey(LoginLockCountKeyPrefix, subjectType, subject),
+		loginKey(LoginFailureKeyPrefix, subjectType, subject),
+	).Err()
+	if err != nil {
+		return fmt.Errorf("failed to unlock login subject: %w", err)
+	}
+	return nil
+}
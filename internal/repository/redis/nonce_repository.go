@@ -0,0 +1,33 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/go-redis/redis/v8"
+)
+
+// NonceKeyPrefix prefixes used H2H request signatures
+const NonceKeyPrefix = "h2h:nonce:"
+
+type nonceRepository struct {
+	client redis.UniversalClient
+}
+
+// NewNonceRepository creates a new Redis backed H2H nonce repository
+func NewNonceRepository(client redis.UniversalClient) domain.NonceRepository {
+	return &nonceRepository{client: client}
+}
+
+// Reserve claims a nonce with SET NX so concurrent replays on other replicas
+// are rejected as well
+func (r *nonceRepository) Reserve(nonce string, ttl time.Duration) (bool, error) {
+	ok, err := r.client.SetNX(context.Background(), NonceKeyPrefix+nonce, time.Now().Unix(), ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve nonce: %w", err)
+	}
+
+	return ok, nil
+}
@@ -0,0 +1,99 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+	"github.com/go-redis/redis/v8"
+)
+
+// Price list cache keys; an empty category is stored as "ALL"
+const (
+	PriceListKeyPrefix     = "pricelist:"
+	PriceListLockKeyPrefix = "pricelist:lock:"
+)
+
+// releasePriceListRebuild deletes the rebuild claim only while it holds the
+// caller's owner token
+var releasePriceListRebuild = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+type priceListCacheRepository struct {
+	client redis.UniversalClient
+}
+
+// NewPriceListCacheRepository creates a new Redis price list cache repository
+func NewPriceListCacheRepository(client redis.UniversalClient) domain.PriceListCacheRepository {
+	return &priceListCacheRepository{client: client}
+}
+
+func priceListCategory(category string) string {
+	if category == "" {
+		return "ALL"
+	}
+	return category
+}
+
+// Get returns the cached price list of a category
+func (r *priceListCacheRepository) Get(category string) (*domain.PriceList, bool, error) {
+	data, err := r.client.Get(context.Background(), PriceListKeyPrefix+priceListCategory(category)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to get cached price list: %w", err)
+	}
+
+	var list domain.PriceList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal cached price list: %w", err)
+	}
+
+	return &list, true, nil
+}
+
+// Set caches the price list of a category
+func (r *priceListCacheRepository) Set(category string, list *domain.PriceList, ttl time.Duration) error {
+	data, err := json.Marshal(list)
+	if err != nil {
+		return fmt.Errorf("failed to marshal price list: %w", err)
+	}
+
+	if err := r.client.Set(context.Background(), PriceListKeyPrefix+priceListCategory(category), data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to cache price list: %w", err)
+	}
+
+	return nil
+}
+
+// AcquireRebuild claims the rebuild of a category's price list under a fresh
+// owner token
+func (r *priceListCacheRepository) AcquireRebuild(category string, ttl time.Duration) (string, error) {
+	owner := utils.GenerateUUID()
+	ok, err := r.client.SetNX(context.Background(), PriceListLockKeyPrefix+priceListCategory(category), owner, ttl).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to claim price list rebuild: %w", err)
+	}
+	if !ok {
+		return "", nil
+	}
+	return owner, nil
+}
+
+// ReleaseRebuild drops the rebuild claim of a category when owner still holds
+// it, so a build outliving its TTL cannot drop another instance's claim
+func (r *priceListCacheRepository) ReleaseRebuild(category, owner string) error {
+	key := PriceListLockKeyPrefix + priceListCategory(category)
+	if err := releasePriceListRebuild.Run(context.Background(), r.client, []string{key}, owner).Err(); err != nil {
+		return fmt.Errorf("failed to release price list rebuild: %w", err)
+	}
+	return nil
+}
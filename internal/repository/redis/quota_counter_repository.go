@@ -0,0 +1,122 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/go-redis/redis/v8"
+)
+
+// QuotaKeyPrefix prefixes H2H quota counters
+const QuotaKeyPrefix = "h2h:quota:"
+
+// Counter lifetimes; they outlive their window so late requests still find them
+const (
+	quotaRequestTTL = 2 * time.Minute
+	quotaDailyTTL   = 48 * time.Hour
+)
+
+type quotaCounterRepository struct {
+	client redis.UniversalClient
+}
+
+// NewQuotaCounterRepository creates a new Redis backed H2H quota counter repository
+func NewQuotaCounterRepository(client redis.UniversalClient) domain.QuotaCounterRepository {
+	return &quotaCounterRepository{client: client}
+}
+
+// quotaKey wraps the client ID in a hash tag so the daily counters of one
+// client share a cluster slot for the pipelines below
+func quotaKey(clientID, counter string) string {
+	return QuotaKeyPrefix + "{" + clientID + "}:" + counter
+}
+
+// IncrementRequests counts a request in a fixed one minute window
+func (r *quotaCounterRepository) IncrementRequests(clientID, endpoint string, window time.Time) (int, error) {
+	ctx := context.Background()
+	key := quotaKey(clientID, "rpm:"+endpoint+":"+strconv.FormatInt(window.Unix(), 10))
+
+	pipe := r.client.TxPipeline()
+	count := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, quotaRequestTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to count quota request: %w", err)
+	}
+
+	return int(count.Val()), nil
+}
+
+// ReserveTransaction counts a transaction for the day and reads the amount used
+func (r *quotaCounterRepository) ReserveTransaction(clientID, day string) (int, float64, error) {
+	ctx := context.Background()
+	countKey := quotaKey(clientID, "trx:"+day)
+	amountKey := quotaKey(clientID, "amount:"+day)
+
+	pipe := r.client.TxPipeline()
+	count := pipe.Incr(ctx, countKey)
+	pipe.Expire(ctx, countKey, quotaDailyTTL)
+	amount := pipe.Get(ctx, amountKey)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return 0, 0, fmt.Errorf("failed to reserve quota transaction: %w", err)
+	}
+
+	used, err := amount.Float64()
+	if err != nil && err != redis.Nil {
+		return 0, 0, fmt.Errorf("failed to read quota amount: %w", err)
+	}
+
+	return int(count.Val()), used, nil
+}
+
+// ReleaseTransaction gives back a reserved transaction
+func (r *quotaCounterRepository) ReleaseTransaction(clientID, day string) error {
+	if err := r.client.Decr(context.Background(), quotaKey(clientID, "trx:"+day)).Err(); err != nil {
+		return fmt.Errorf("failed to release quota transaction: %w", err)
+	}
+	return nil
+}
+
+// AddAmount adds a transaction amount to the day's usage
+func (r *quotaCounterRepository) AddAmount(clientID, day string, amount float64) error {
+	ctx := context.Background()
+	key := quotaKey(clientID, "amount:"+day)
+
+	pipe := r.client.TxPipeline()
+	pipe.IncrByFloat(ctx, key, amount)
+	pipe.Expire(ctx, key, quotaDailyTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to add quota amount: %w", err)
+	}
+	return nil
+}
+
+// GetUsage reads the request, transaction and amount counters of a client
+func (r *quotaCounterRepository) GetUsage(clientID, endpoint string, window time.Time, day string) (int, int, float64, error) {
+	ctx := context.Background()
+
+	pipe := r.client.Pipeline()
+	requests := pipe.Get(ctx, quotaKey(clientID, "rpm:"+endpoint+":"+strconv.FormatInt(window.Unix(), 10)))
+	count := pipe.Get(ctx, quotaKey(clientID, "trx:"+day))
+	amount := pipe.Get(ctx, quotaKey(clientID, "amount:"+day))
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return 0, 0, 0, fmt.Errorf("failed to read quota usage: %w", err)
+	}
+
+	requestCount, err := requests.Int()
+	if err != nil && err != redis.Nil {
+		return 0, 0, 0, fmt.Errorf("failed to read quota requests: %w", err)
+	}
+	transactionCount, err := count.Int()
+	if err != nil && err != redis.Nil {
+		return 0, 0, 0, fmt.Errorf("failed to read quota transactions: %w", err)
+	}
+	used, err := amount.Float64()
+	if err != nil && err != redis.Nil {
+		return 0, 0, 0, fmt.Errorf("failed to read quota amount: %w", err)
+	}
+
+	return requestCount, transactionCount, used, nil
+}
@@ -0,0 +1,63 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/go-redis/redis/v8"
+)
+
+// ReportKeyPrefix prefixes cached report periods
+const ReportKeyPrefix = "report:supplier:"
+
+type reportCacheRepository struct {
+	client redis.UniversalClient
+}
+
+// NewReportCacheRepository creates a new Redis report cache repository
+func NewReportCacheRepository(client redis.UniversalClient) domain.ReportCacheRepository {
+	return &reportCacheRepository{client: client}
+}
+
+func reportPeriodKey(granularity string, period time.Time) string {
+	return ReportKeyPrefix + granularity + ":" + period.Format("2006-01-02")
+}
+
+// GetSupplierPeriod returns the cached rows of a period; ok is false on a cache miss
+func (r *reportCacheRepository) GetSupplierPeriod(granularity string, period time.Time) ([]*domain.SupplierReportRow, bool, error) {
+	data, err := r.client.Get(context.Background(), reportPeriodKey(granularity, period)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to get cached report period: %w", err)
+	}
+
+	var rows []*domain.SupplierReportRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal cached report period: %w", err)
+	}
+
+	return rows, true, nil
+}
+
+// SetSupplierPeriod caches the rows of a period (an empty period is cached too)
+func (r *reportCacheRepository) SetSupplierPeriod(granularity string, period time.Time, rows []*domain.SupplierReportRow, ttl time.Duration) error {
+	if rows == nil {
+		rows = []*domain.SupplierReportRow{}
+	}
+
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return fmt.Errorf("failed to marshal report period: %w", err)
+	}
+
+	if err := r.client.Set(context.Background(), reportPeriodKey(granularity, period), data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to cache report period: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/go-redis/redis/v8"
+)
+
+// Routing cache key prefixes. A snapshot lives under RoutingProductKeyPrefix
+// and is indexed under RoutingSupplierKeyPrefix of each of its suppliers, so a
+// supplier change drops every snapshot routing to it.
+const (
+	RoutingProductKeyPrefix  = "routing:product:"
+	RoutingSupplierKeyPrefix = "routing:supplier:"
+)
+
+type routingCacheRepository struct {
+	client redis.UniversalClient
+}
+
+// NewRoutingCacheRepository creates a new Redis routing cache repository
+func NewRoutingCacheRepository(client redis.UniversalClient) domain.RoutingCacheRepository {
+	return &routingCacheRepository{client: client}
+}
+
+// GetSnapshot returns the cached snapshot of a product, or nil on a miss
+func (r *routingCacheRepository) GetSnapshot(productID string) (*domain.RoutingSnapshot, error) {
+	data, err := r.client.Get(context.Background(), RoutingProductKeyPrefix+productID).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get routing snapshot: %w", err)
+	}
+
+	var snapshot domain.RoutingSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal routing snapshot: %w", err)
+	}
+
+	return &snapshot, nil
+}
+
+// SetSnapshot caches a snapshot and indexes it under its suppliers. The
+// supplier index outlives the snapshot by one TTL at most.
+func (r *routingCacheRepository) SetSnapshot(snapshot *domain.RoutingSnapshot, ttl time.Duration) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal routing snapshot: %w", err)
+	}
+
+	ctx := context.Background()
+	pipe := r.client.TxPipeline()
+	pipe.Set(ctx, RoutingProductKeyPrefix+snapshot.ProductID, data, ttl)
+	for _, supplier := range snapshot.Suppliers {
+		key := RoutingSupplierKeyPrefix + supplier.ID
+		pipe.SAdd(ctx, key, snapshot.ProductID)
+		pipe.Expire(ctx, key, 2*ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to cache routing snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// InvalidateProducts drops the snapshots of the products. Each key gets its
+// own DEL in one pipeline: the keys hash to different slots, so a multi-key
+// DEL would fail with CROSSSLOT on a Redis cluster.
+func (r *routingCacheRepository) InvalidateProducts(productIDs ...string) error {
+	if len(productIDs) == 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	pipe := r.client.Pipeline()
+	for _, productID := range productIDs {
+		pipe.Del(ctx, RoutingProductKeyPrefix+productID)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to invalidate routing snapshots: %w", err)
+	}
+
+	return nil
+}
+
+// InvalidateSupplier drops the snapshot of every product routed to the supplier
+func (r *routingCacheRepository) InvalidateSupplier(supplierID string) error {
+	ctx := context.Background()
+	key := RoutingSupplierKeyPrefix + supplierID
+
+	productIDs, err := r.client.SMembers(ctx, key).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get routing snapshots of supplier: %w", err)
+	}
+	if err := r.InvalidateProducts(productIDs...); err != nil {
+		return err
+	}
+	if err := r.client.Del(ctx, key).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate routing snapshots: %w", err)
+	}
+
+	return nil
+}
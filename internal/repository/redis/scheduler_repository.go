@@ -0,0 +1,72 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/go-redis/redis/v8"
+)
+
+// Scheduler keys
+const (
+	SchedulerLockPrefix = "scheduler:lock:"
+	SchedulerRunPrefix  = "scheduler:run:"
+)
+
+type schedulerRepository struct {
+	client redis.UniversalClient
+}
+
+// NewSchedulerRepository creates a new Redis scheduler repository
+func NewSchedulerRepository(client redis.UniversalClient) domain.SchedulerRepository {
+	return &schedulerRepository{client: client}
+}
+
+// AcquireJobLock claims a job activation with SET NX. The lock is not released
+// after the run, it expires with its TTL so replicas whose timers fire slightly
+// later cannot run the same activation again.
+func (r *schedulerRepository) AcquireJobLock(jobName, runKey, owner string, ttl time.Duration) (bool, error) {
+	key := SchedulerLockPrefix + jobName + ":" + runKey
+
+	ok, err := r.client.SetNX(context.Background(), key, owner, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire job lock: %w", err)
+	}
+
+	return ok, nil
+}
+
+// SaveJobRun stores the latest run of a job
+func (r *schedulerRepository) SaveJobRun(run *domain.JobRun) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job run: %w", err)
+	}
+
+	if err := r.client.Set(context.Background(), SchedulerRunPrefix+run.JobName, data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to save job run: %w", err)
+	}
+
+	return nil
+}
+
+// GetJobRun returns the latest run of a job, nil when it never ran
+func (r *schedulerRepository) GetJobRun(jobName string) (*domain.JobRun, error) {
+	data, err := r.client.Get(context.Background(), SchedulerRunPrefix+jobName).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get job run: %w", err)
+	}
+
+	var run domain.JobRun
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal job run: %w", err)
+	}
+
+	return &run, nil
+}
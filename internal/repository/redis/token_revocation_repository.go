@@ -0,0 +1,51 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/go-redis/redis/v8"
+)
+
+// TokenRevocationKeyPrefix prefixes the per-user token revocation marker
+const TokenRevocationKeyPrefix = "auth:revoked:"
+
+type tokenRevocationRepository struct {
+	client redis.UniversalClient
+}
+
+// NewTokenRevocationRepository creates a new Redis backed token revocation repository
+func NewTokenRevocationRepository(client redis.UniversalClient) domain.TokenRevocationRepository {
+	return &tokenRevocationRepository{client: client}
+}
+
+// RevokeBefore stores the revocation time as Unix seconds, matching the
+// precision of the JWT iat claim
+func (r *tokenRevocationRepository) RevokeBefore(userID string, at time.Time, ttl time.Duration) error {
+	key := TokenRevocationKeyPrefix + userID
+	if err := r.client.Set(context.Background(), key, at.Unix(), ttl).Err(); err != nil {
+		return fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+	return nil
+}
+
+// RevokedBefore returns the revocation time of the user's tokens
+func (r *tokenRevocationRepository) RevokedBefore(userID string) (time.Time, error) {
+	value, err := r.client.Get(context.Background(), TokenRevocationKeyPrefix+userID).Result()
+	if err == redis.Nil {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get token revocation: %w", err)
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid token revocation value: %w", err)
+	}
+
+	return time.Unix(seconds, 0), nil
+}
@@ -0,0 +1,102 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+	"github.com/go-redis/redis/v8"
+)
+
+// TransactionLockKeyPrefix prefixes per-transaction processing locks
+const TransactionLockKeyPrefix = "trx:lock:"
+
+// transactionFenceTTL keeps the fencing counter well past any lock TTL, so
+// tokens keep growing while a transaction can still be processed
+const transactionFenceTTL = 24 * time.Hour
+
+// acquireTransactionLock sets the lock to owner:token only when it is free,
+// bumping the fencing counter in the same step
+var acquireTransactionLock = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+local token = redis.call('INCR', KEYS[2])
+redis.call('PEXPIRE', KEYS[2], ARGV[3])
+redis.call('SET', KEYS[1], ARGV[1] .. ':' .. token, 'PX', ARGV[2])
+return token
+`)
+
+// releaseTransactionLock deletes the lock only while it holds the caller's value
+var releaseTransactionLock = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+type transactionLockRepository struct {
+	client redis.UniversalClient
+}
+
+// NewTransactionLockRepository creates a new Redis transaction lock repository
+func NewTransactionLockRepository(client redis.UniversalClient) domain.TransactionLockRepository {
+	return &transactionLockRepository{client: client}
+}
+
+// transactionLockKeys wraps the transaction ID in a hash tag so the lock and
+// its fencing counter share a cluster slot for the scripts above
+func transactionLockKeys(transactionID string) []string {
+	key := TransactionLockKeyPrefix + "{" + transactionID + "}"
+	return []string{key, key + ":fence"}
+}
+
+func transactionLockValue(lock *domain.TransactionLock) string {
+	return lock.Owner + ":" + strconv.FormatInt(lock.Token, 10)
+}
+
+// Acquire takes the lock with a fresh fencing token
+func (r *transactionLockRepository) Acquire(transactionID string, ttl time.Duration) (*domain.TransactionLock, error) {
+	owner := utils.GenerateUUID()
+	token, err := acquireTransactionLock.Run(context.Background(), r.client, transactionLockKeys(transactionID),
+		owner, ttl.Milliseconds(), transactionFenceTTL.Milliseconds(),
+	).Int64()
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire transaction lock: %w", err)
+	}
+	if token == 0 {
+		return nil, nil
+	}
+
+	return &domain.TransactionLock{
+		TransactionID: transactionID,
+		Owner:         owner,
+		Token:         token,
+		AcquiredAt:    time.Now(),
+	}, nil
+}
+
+// Validate checks the lock still holds the caller's owner and token
+func (r *transactionLockRepository) Validate(lock *domain.TransactionLock) (bool, error) {
+	value, err := r.client.Get(context.Background(), transactionLockKeys(lock.TransactionID)[0]).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to validate transaction lock: %w", err)
+	}
+
+	return value == transactionLockValue(lock), nil
+}
+
+// Release drops the lock when the caller still holds it
+func (r *transactionLockRepository) Release(lock *domain.TransactionLock) error {
+	keys := transactionLockKeys(lock.TransactionID)
+	if err := releaseTransactionLock.Run(context.Background(), r.client, keys[:1], transactionLockValue(lock)).Err(); err != nil {
+		return fmt.Errorf("failed to release transaction lock: %w", err)
+	}
+	return nil
+}
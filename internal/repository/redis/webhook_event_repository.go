@@ -0,0 +1,46 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/go-redis/redis/v8"
+)
+
+// WebhookEventKeyPrefix prefixes processed supplier webhook event IDs
+const WebhookEventKeyPrefix = "supplier:webhook:"
+
+type webhookEventRepository struct {
+	client redis.UniversalClient
+}
+
+// NewWebhookEventRepository creates a new Redis backed webhook event repository
+func NewWebhookEventRepository(client redis.UniversalClient) domain.WebhookEventRepository {
+	return &webhookEventRepository{client: client}
+}
+
+// Reserve claims an event ID with SET NX so a delivery replayed to another
+// replica is rejected as well
+func (r *webhookEventRepository) Reserve(supplierCode, eventID string, ttl time.Duration) (bool, error) {
+	ok, err := r.client.SetNX(context.Background(), webhookEventKey(supplierCode, eventID), time.Now().Unix(), ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve webhook event: %w", err)
+	}
+
+	return ok, nil
+}
+
+// Release deletes a reserved event ID
+func (r *webhookEventRepository) Release(supplierCode, eventID string) error {
+	if err := r.client.Del(context.Background(), webhookEventKey(supplierCode, eventID)).Err(); err != nil {
+		return fmt.Errorf("failed to release webhook event: %w", err)
+	}
+
+	return nil
+}
+
+func webhookEventKey(supplierCode, eventID string) string {
+	return WebhookEventKeyPrefix + supplierCode + ":" + eventID
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+type adminSigningUsecase struct {
+	keyRepo  domain.AdminSigningKeyRepository
+	userRepo domain.UserRepository
+}
+
+// NewAdminSigningUsecase creates a new admin signing use case
+func NewAdminSigningUsecase(keyRepo domain.AdminSigningKeyRepository, userRepo domain.UserRepository) domain.AdminSigningUsecase {
+	return &adminSigningUsecase{
+		keyRepo:  keyRepo,
+		userRepo: userRepo,
+	}
+}
+
+// CreateKey issues a new signing key for an admin and revokes their previous
+// one. The password is checked again so a stolen access token alone cannot
+// mint a key.
+func (uc *adminSigningUsecase) CreateKey(userID, password string) (*domain.IssuedAdminSigningKey, error) {
+	user, err := uc.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Level != domain.LevelAdmin {
+		return nil, fmt.Errorf("signing keys are only issued to admins")
+	}
+	if !utils.VerifyPassword(password, user.PasswordHash) {
+		logger.Component(logger.ComponentAuth).Warn("Admin signing key request with wrong password",
+			logger.String("user_id", userID),
+		)
+		return nil, fmt.Errorf("invalid password")
+	}
+
+	key := &domain.AdminSigningKey{
+		ID:        utils.GenerateUUID(),
+		UserID:    userID,
+		Secret:    utils.GenerateRandomString(64),
+		CreatedAt: time.Now(),
+	}
+	if err := uc.keyRepo.Rotate(key); err != nil {
+		return nil, err
+	}
+
+	return &domain.IssuedAdminSigningKey{
+		ID:        key.ID,
+		Secret:    key.Secret,
+		CreatedAt: key.CreatedAt,
+	}, nil
+}
+
+// ListKeys lists the active signing keys of every admin
+func (uc *adminSigningUsecase) ListKeys() ([]*domain.AdminSigningKey, error) {
+	return uc.keyRepo.ListActive()
+}
+
+// RevokeKey revokes a signing key, e.g. of an admin whose device was lost
+func (uc *adminSigningUsecase) RevokeKey(id, revokedBy string) error {
+	return uc.keyRepo.Revoke(id, revokedBy)
+}
+
+// Verify checks a signed admin request. Unknown, revoked and foreign keys
+// fail alike so the response does not reveal which keys exist.
+func (uc *adminSigningUsecase) Verify(userID, keyID, timestamp, signature string, payload []byte) error {
+	key, err := uc.keyRepo.GetByID(keyID)
+	if err != nil {
+		if err.Error() == "admin signing key not found" {
+			return fmt.Errorf("invalid signing key")
+		}
+		return err
+	}
+	if !key.IsActive() || key.UserID != userID {
+		return fmt.Errorf("invalid signing key")
+	}
+
+	if err := domain.ValidateSignature(key.Secret, timestamp, signature, payload); err != nil {
+		return err
+	}
+
+	if err := uc.keyRepo.UpdateLastUsed(key.ID); err != nil {
+		logger.Warn("Failed to record admin signing key usage",
+			logger.String("key_id", key.ID),
+			logger.ErrorField(err),
+		)
+	}
+	return nil
+}
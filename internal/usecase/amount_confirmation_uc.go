@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+type amountConfirmationUsecase struct {
+	userRepo         domain.UserRepository
+	confirmationRepo domain.AmountConfirmationRepository
+	pinVerifier      domain.BalanceTransferUsecase
+	policy           domain.AmountConfirmationPolicy
+}
+
+// DefaultAmountConfirmationPolicy returns the default amount confirmation policy
+func DefaultAmountConfirmationPolicy() domain.AmountConfirmationPolicy {
+	return domain.AmountConfirmationPolicy{
+		DefaultThreshold: 500000,
+		TokenTTL:         5 * time.Minute,
+		ExemptChannels:   []string{domain.ChannelH2H},
+	}
+}
+
+// NewAmountConfirmationUsecase creates a new amount confirmation use case.
+// PINs are checked by the balance transfer use case, which owns them.
+func NewAmountConfirmationUsecase(
+	userRepo domain.UserRepository,
+	confirmationRepo domain.AmountConfirmationRepository,
+	pinVerifier domain.BalanceTransferUsecase,
+	policy domain.AmountConfirmationPolicy,
+) domain.AmountConfirmationUsecase {
+	if policy.DefaultThreshold < 0 {
+		policy.DefaultThreshold = 0
+	}
+	if policy.TokenTTL <= 0 {
+		policy.TokenTTL = DefaultAmountConfirmationPolicy().TokenTTL
+	}
+	for i, channel := range policy.ExemptChannels {
+		policy.ExemptChannels[i] = strings.ToUpper(strings.TrimSpace(channel))
+	}
+
+	return &amountConfirmationUsecase{
+		userRepo:         userRepo,
+		confirmationRepo: confirmationRepo,
+		pinVerifier:      pinVerifier,
+		policy:           policy,
+	}
+}
+
+// Check lets the transaction through when it is at or below the user's
+// threshold or ctx confirms it with a matching token or the PIN. Otherwise it
+// issues a token for this exact order and returns it in the error.
+func (uc *amountConfirmationUsecase) Check(ctx context.Context, trx *domain.Transaction) error {
+	if uc.policy.IsExempt(trx.Channel) {
+		return nil
+	}
+
+	userThreshold, err := uc.userRepo.GetAmountConfirmThreshold(trx.UserID)
+	if err != nil {
+		return err
+	}
+	threshold := uc.policy.Threshold(userThreshold)
+	if threshold <= 0 || trx.TotalAmount() <= threshold {
+		return nil
+	}
+
+	log := logger.FromContext(ctx)
+	confirmation := domain.OrderConfirmationFromContext(ctx)
+
+	// A wrong PIN is reported as such rather than with a new token, and
+	// counts towards the PIN lock
+	if confirmation.PIN != "" && uc.pinVerifier != nil {
+		if err := uc.pinVerifier.VerifyPIN(trx.UserID, confirmation.PIN); err != nil {
+			return err
+		}
+		log.Info("Large order confirmed with PIN",
+			logger.Float64("total_amount", trx.TotalAmount()),
+			logger.Float64("threshold", threshold),
+		)
+		return nil
+	}
+
+	tokenRejected := false
+	if confirmation.Token != "" {
+		issued, err := uc.confirmationRepo.Consume(confirmation.Token)
+		if err != nil {
+			return err
+		}
+		if issued != nil && issued.Matches(trx) {
+			log.Info("Large order confirmed with token",
+				logger.Float64("total_amount", trx.TotalAmount()),
+				logger.Float64("threshold", threshold),
+			)
+			return nil
+		}
+		tokenRejected = true
+	}
+
+	issued := &domain.AmountConfirmation{
+		Token:             utils.GenerateRandomString(32),
+		UserID:            trx.UserID,
+		ProductCode:       trx.ProductCode,
+		DestinationNumber: trx.DestinationNumber,
+		TotalAmount:       trx.TotalAmount(),
+		ExpiresAt:         time.Now().Add(uc.policy.TokenTTL),
+	}
+	if err := uc.confirmationRepo.Save(issued); err != nil {
+		return err
+	}
+
+	pinHash, err := uc.userRepo.GetPINHash(trx.UserID)
+	if err != nil {
+		return err
+	}
+
+	log.Info("Large order awaiting confirmation",
+		logger.Float64("total_amount", trx.TotalAmount()),
+		logger.Float64("threshold", threshold),
+		logger.Bool("token_rejected", tokenRejected),
+	)
+
+	return &domain.AmountConfirmationRequiredError{
+		TotalAmount:   trx.TotalAmount(),
+		Threshold:     threshold,
+		Token:         issued.Token,
+		ExpiresAt:     issued.ExpiresAt,
+		PINAllowed:    pinHash != nil && uc.pinVerifier != nil,
+		TokenRejected: tokenRejected,
+	}
+}
+
+// GetSettings returns the confirmation rule that applies to a user
+func (uc *amountConfirmationUsecase) GetSettings(userID string) (*domain.AmountConfirmationSettings, error) {
+	userThreshold, err := uc.userRepo.GetAmountConfirmThreshold(userID)
+	if err != nil {
+		return nil, err
+	}
+	pinHash, err := uc.userRepo.GetPINHash(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AmountConfirmationSettings{
+		Threshold:       uc.policy.Threshold(userThreshold),
+		Custom:          userThreshold != nil,
+		PINSet:          pinHash != nil,
+		TokenTTLSeconds: int(uc.policy.TokenTTL.Seconds()),
+		ExemptChannels:  uc.policy.ExemptChannels,
+	}, nil
+}
+
+// SetThreshold sets the user's own threshold, 0 turning the check off for
+// them; nil restores the default
+func (uc *amountConfirmationUsecase) SetThreshold(userID string, threshold *float64) (*domain.AmountConfirmationSettings, error) {
+	if threshold != nil && *threshold < 0 {
+		return nil, fmt.Errorf("threshold must not be negative")
+	}
+
+	if err := uc.userRepo.UpdateAmountConfirmThreshold(userID, threshold); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Amount confirmation threshold updated",
+		logger.String("user_id", userID),
+		logger.Bool("custom", threshold != nil),
+	)
+
+	return uc.GetSettings(userID)
+}
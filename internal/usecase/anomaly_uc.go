@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"sync"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/metrics"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+type anomalyUsecase struct {
+	anomalyRepo domain.AnomalyRepository
+	eventRepo   domain.EventRepository
+	config      AnomalyConfig
+
+	mu     sync.Mutex
+	active map[string]*domain.Anomaly // Anomalies exported as metrics, by key
+}
+
+// AnomalyConfig defines transaction anomaly detection parameters
+type AnomalyConfig struct {
+	// Window is the rolling window of transactions evaluated
+	Window time.Duration
+	// SettleDelay skips the newest transactions, which are still in flight
+	SettleDelay time.Duration
+	// MinSamples is the minimum number of transactions needed to judge a rate
+	MinSamples int
+	// FailureRateThreshold is the failed percentage of finished transactions that alerts
+	FailureRateThreshold float64
+	// PendingRateThreshold is the percentage of routed transactions still
+	// processing that alerts
+	PendingRateThreshold float64
+	// Cooldown suppresses repeated alerts for the same anomaly
+	Cooldown time.Duration
+}
+
+// DefaultAnomalyConfig returns default anomaly detection configuration
+func DefaultAnomalyConfig() AnomalyConfig {
+	return AnomalyConfig{
+		Window:               15 * time.Minute,
+		SettleDelay:          2 * time.Minute,
+		MinSamples:           10,
+		FailureRateThreshold: 50,
+		PendingRateThreshold: 90,
+		Cooldown:             30 * time.Minute,
+	}
+}
+
+// NewAnomalyUsecase creates a new transaction anomaly use case
+func NewAnomalyUsecase(anomalyRepo domain.AnomalyRepository, eventRepo domain.EventRepository, config AnomalyConfig) domain.AnomalyUsecase {
+	defaults := DefaultAnomalyConfig()
+	if config.Window <= 0 {
+		config.Window = defaults.Window
+	}
+	if config.SettleDelay < 0 {
+		config.SettleDelay = defaults.SettleDelay
+	}
+	if config.MinSamples <= 0 {
+		config.MinSamples = defaults.MinSamples
+	}
+	if config.FailureRateThreshold <= 0 || config.FailureRateThreshold > 100 {
+		config.FailureRateThreshold = defaults.FailureRateThreshold
+	}
+	if config.PendingRateThreshold <= 0 || config.PendingRateThreshold > 100 {
+		config.PendingRateThreshold = defaults.PendingRateThreshold
+	}
+	if config.Cooldown < 0 {
+		config.Cooldown = defaults.Cooldown
+	}
+
+	return &anomalyUsecase{
+		anomalyRepo: anomalyRepo,
+		eventRepo:   eventRepo,
+		config:      config,
+		active:      make(map[string]*domain.Anomaly),
+	}
+}
+
+// DetectAnomalies checks supplier-wide and per product outcome rates. Product
+// anomalies already covered by the same supplier-wide anomaly are not raised.
+func (uc *anomalyUsecase) DetectAnomalies() ([]*domain.Anomaly, error) {
+	now := time.Now()
+	end := now.Add(-uc.config.SettleDelay)
+	start := end.Add(-uc.config.Window)
+
+	stats, err := uc.anomalyRepo.GetOutcomeStats(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	supplierStats := make(map[string]*domain.OutcomeStats)
+	supplierOrder := make([]string, 0)
+	for _, row := range stats {
+		total, ok := supplierStats[row.SupplierID]
+		if !ok {
+			total = &domain.OutcomeStats{SupplierID: row.SupplierID, SupplierCode: row.SupplierCode}
+			supplierStats[row.SupplierID] = total
+			supplierOrder = append(supplierOrder, row.SupplierID)
+		}
+		total.Total += row.Total
+		total.SuccessCount += row.SuccessCount
+		total.FailedCount += row.FailedCount
+		total.ProcessingCount += row.ProcessingCount
+	}
+
+	anomalies := make([]*domain.Anomaly, 0)
+	supplierWide := make(map[string]bool)
+	for _, supplierID := range supplierOrder {
+		for _, anomaly := range uc.evaluate(supplierStats[supplierID], domain.AnomalyComponentSupplier) {
+			anomaly.WindowStart, anomaly.WindowEnd, anomaly.DetectedAt = start, end, now
+			supplierWide[anomaly.Kind+":"+supplierID] = true
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+	for _, row := range stats {
+		for _, anomaly := range uc.evaluate(row, domain.AnomalyComponentProduct) {
+			if supplierWide[anomaly.Kind+":"+row.SupplierID] {
+				continue
+			}
+			anomaly.WindowStart, anomaly.WindowEnd, anomaly.DetectedAt = start, end, now
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+
+	for _, anomaly := range anomalies {
+		uc.alert(anomaly)
+	}
+	uc.exportMetrics(anomalies)
+
+	return anomalies, nil
+}
+
+// evaluate compares one aggregate against the failure and pending thresholds
+func (uc *anomalyUsecase) evaluate(stats *domain.OutcomeStats, component string) []*domain.Anomaly {
+	anomalies := make([]*domain.Anomaly, 0, 2)
+	newAnomaly := func(kind string, samples int, rate, threshold float64) *domain.Anomaly {
+		anomaly := &domain.Anomaly{
+			Kind:         kind,
+			Component:    component,
+			SupplierID:   stats.SupplierID,
+			SupplierCode: stats.SupplierCode,
+			Samples:      samples,
+			Rate:         utils.RoundToDecimal(rate, 2),
+			Threshold:    threshold,
+		}
+		if component == domain.AnomalyComponentProduct {
+			anomaly.ProductID = stats.ProductID
+			anomaly.ProductCode = stats.ProductCode
+		}
+		return anomaly
+	}
+
+	finished := stats.SuccessCount + stats.FailedCount
+	if finished >= uc.config.MinSamples {
+		rate := float64(stats.FailedCount) / float64(finished) * 100
+		if rate >= uc.config.FailureRateThreshold {
+			anomalies = append(anomalies, newAnomaly(domain.AnomalyFailureRate, finished, rate, uc.config.FailureRateThreshold))
+		}
+	}
+
+	routed := finished + stats.ProcessingCount
+	if routed >= uc.config.MinSamples {
+		rate := float64(stats.ProcessingCount) / float64(routed) * 100
+		if rate >= uc.config.PendingRateThreshold {
+			anomalies = append(anomalies, newAnomaly(domain.AnomalyAllPending, routed, rate, uc.config.PendingRateThreshold))
+		}
+	}
+
+	return anomalies
+}
+
+// alert writes an anomaly.detected outbox event unless the same anomaly was
+// alerted within the cooldown; the relay delivers it to the event webhooks
+func (uc *anomalyUsecase) alert(anomaly *domain.Anomaly) {
+	key := anomaly.Key()
+	last, err := uc.anomalyRepo.LastAlertAt(anomaly.SupplierID, key)
+	if err != nil {
+		logger.Error("Failed to check anomaly alert cooldown", logger.String("key", key), logger.ErrorField(err))
+		return
+	}
+	if last != nil && anomaly.DetectedAt.Sub(*last) < uc.config.Cooldown {
+		return
+	}
+
+	logger.Warn("Transaction anomaly detected",
+		logger.String("kind", anomaly.Kind),
+		logger.String("component", anomaly.Component),
+		logger.String("supplier_code", anomaly.SupplierCode),
+		logger.String("product_code", anomaly.ProductCode),
+		logger.Int("samples", anomaly.Samples),
+		logger.Float64("rate", anomaly.Rate),
+	)
+
+	event, err := domain.NewAnomalyDetectedEvent(anomaly)
+	if err != nil {
+		logger.Error("Failed to build anomaly event", logger.String("key", key), logger.ErrorField(err))
+		return
+	}
+	if err := uc.eventRepo.Create(event); err != nil {
+		logger.Error("Failed to store anomaly event", logger.String("key", key), logger.ErrorField(err))
+	}
+}
+
+// exportMetrics sets the anomaly gauge for active anomalies and removes the
+// series of anomalies that cleared
+func (uc *anomalyUsecase) exportMetrics(anomalies []*domain.Anomaly) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	current := make(map[string]*domain.Anomaly, len(anomalies))
+	for _, anomaly := range anomalies {
+		current[anomaly.Key()] = anomaly
+		metrics.SetTransactionAnomaly(anomaly.Kind, anomaly.Component, anomaly.SupplierCode, anomaly.ProductCode, true)
+	}
+	for key, anomaly := range uc.active {
+		if _, ok := current[key]; !ok {
+			metrics.SetTransactionAnomaly(anomaly.Kind, anomaly.Component, anomaly.SupplierCode, anomaly.ProductCode, false)
+		}
+	}
+	uc.active = current
+}
@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+type apiClientPortalUsecase struct {
+	clientRepo      domain.APIClientRepository
+	transactionRepo domain.TransactionRepository
+	outboxRepo      domain.OutboxRepository
+	transactionUC   domain.TransactionUsecase
+	quotaUC         domain.QuotaUsecase
+	config          APIClientPortalConfig
+}
+
+// APIClientPortalConfig defines the H2H client self-service behavior
+type APIClientPortalConfig struct {
+	// SecretGracePeriod is how long the replaced secret keeps working after a rotation
+	SecretGracePeriod time.Duration
+	// MaxDeliveries caps the delivery log returned per request
+	MaxDeliveries int
+}
+
+// DefaultAPIClientPortalConfig returns default H2H client self-service configuration
+func DefaultAPIClientPortalConfig() APIClientPortalConfig {
+	return APIClientPortalConfig{
+		SecretGracePeriod: 24 * time.Hour,
+		MaxDeliveries:     100,
+	}
+}
+
+// NewAPIClientPortalUsecase creates a new H2H client self-service use case
+func NewAPIClientPortalUsecase(
+	clientRepo domain.APIClientRepository,
+	transactionRepo domain.TransactionRepository,
+	outboxRepo domain.OutboxRepository,
+	transactionUC domain.TransactionUsecase,
+	quotaUC domain.QuotaUsecase,
+	config APIClientPortalConfig,
+) domain.APIClientPortalUsecase {
+	defaults := DefaultAPIClientPortalConfig()
+	if config.SecretGracePeriod <= 0 {
+		config.SecretGracePeriod = defaults.SecretGracePeriod
+	}
+	if config.MaxDeliveries <= 0 {
+		config.MaxDeliveries = defaults.MaxDeliveries
+	}
+
+	return &apiClientPortalUsecase{
+		clientRepo:      clientRepo,
+		transactionRepo: transactionRepo,
+		outboxRepo:      outboxRepo,
+		transactionUC:   transactionUC,
+		quotaUC:         quotaUC,
+		config:          config,
+	}
+}
+
+// GetUsage counts the transactions the client created over H2H in the period
+func (uc *apiClientPortalUsecase) GetUsage(client *domain.APIClient, period domain.DateRange) (*domain.APIClientUsage, error) {
+	userID, err := clientAccount(client)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := uc.transactionRepo.GetChannelUsage(userID, domain.ChannelH2H, period)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.APIClientUsage{
+		ClientID:     client.ClientID,
+		From:         period.From,
+		To:           period.To,
+		LastUsedAt:   client.LastUsedAt,
+		ChannelUsage: *usage,
+	}, nil
+}
+
+// GetQuota reports the client's remaining quota
+func (uc *apiClientPortalUsecase) GetQuota(client *domain.APIClient, endpoint string) (*domain.QuotaStatus, error) {
+	return uc.quotaUC.GetStatus(client, endpoint)
+}
+
+// ListTransactions lists the transactions of the client's account, newest
+// first, optionally only those carrying all the given tags
+func (uc *apiClientPortalUsecase) ListTransactions(client *domain.APIClient, period domain.DateRange, tags domain.TransactionTags, cursor string, limit int) ([]*domain.Transaction, string, error) {
+	userID, err := clientAccount(client)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return uc.transactionUC.GetUserTransactionsByCursor(userID, period, tags, cursor, limit)
+}
+
+// ListDeliveries returns the latest notifications sent to the client's
+// account with their delivery status
+func (uc *apiClientPortalUsecase) ListDeliveries(client *domain.APIClient, limit int) ([]*domain.Outbox, error) {
+	userID, err := clientAccount(client)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 || limit > uc.config.MaxDeliveries {
+		limit = uc.config.MaxDeliveries
+	}
+	return uc.outboxRepo.GetByUserID(userID, limit)
+}
+
+// RotateSecret issues a new secret for the client. The current secret keeps
+// working for the grace period so the client can roll the new one out; a
+// secret still in grace from an earlier rotation stops working immediately.
+func (uc *apiClientPortalUsecase) RotateSecret(client *domain.APIClient) (*domain.SecretRotation, error) {
+	secret := utils.GenerateRandomString(64)
+	graceUntil := time.Now().Add(uc.config.SecretGracePeriod)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := uc.clientRepo.RotateSecret(ctx, client.ClientID, secret, graceUntil); err != nil {
+		return nil, err
+	}
+
+	logger.Info("API client secret rotated",
+		logger.String("client_id", client.ClientID),
+		logger.Duration("grace_period", uc.config.SecretGracePeriod),
+	)
+
+	return &domain.SecretRotation{
+		ClientID:                client.ClientID,
+		Secret:                  secret,
+		PreviousSecretExpiresAt: graceUntil,
+	}, nil
+}
+
+// maxIPChanges caps the whitelist change log returned per request
+const maxIPChanges = 100
+
+// UpdateIPWhitelist replaces the client's IP whitelist. A client cannot clear
+// its whitelist or leave out the address it calls from: a leaked key must
+// not be able to lift the restriction, and a typo must not lock the client out.
+func (uc *apiClientPortalUsecase) UpdateIPWhitelist(client *domain.APIClient, ips []string, ipAddress string) (*domain.APIClientIPChange, error) {
+	normalized, err := domain.NormalizeIPWhitelist(ips)
+	if err != nil {
+		return nil, err
+	}
+	if len(normalized) == 0 {
+		return nil, fmt.Errorf("ip whitelist must not be empty")
+	}
+	if !(&domain.APIClient{IPWhitelist: normalized}).IsIPAllowed(ipAddress) {
+		return nil, fmt.Errorf("ip whitelist must include the requesting address")
+	}
+
+	change := &domain.APIClientIPChange{
+		ClientID:  client.ClientID,
+		NewIPs:    normalized,
+		ActorType: domain.IPChangeActorClient,
+		IPAddress: ipAddress,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := uc.clientRepo.UpdateIPWhitelist(ctx, change); err != nil {
+		return nil, err
+	}
+
+	logger.Component(logger.ComponentAuth).Info("API client IP whitelist updated by client",
+		logger.String("client_id", client.ClientID),
+		logger.Any("old_ips", change.OldIPs),
+		logger.Any("new_ips", change.NewIPs),
+		logger.String("ip", ipAddress),
+	)
+
+	return change, nil
+}
+
+// ListIPChanges returns the latest IP whitelist changes of the client
+func (uc *apiClientPortalUsecase) ListIPChanges(client *domain.APIClient, limit int) ([]*domain.APIClientIPChange, error) {
+	if limit <= 0 || limit > maxIPChanges {
+		limit = maxIPChanges
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return uc.clientRepo.ListIPChanges(ctx, client.ClientID, limit)
+}
+
+// clientAccount returns the account an API client transacts for
+func clientAccount(client *domain.APIClient) (string, error) {
+	if client.UserID == nil {
+		return "", fmt.Errorf("api client is not linked to an account")
+	}
+	return *client.UserID, nil
+}
@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"sync"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/metrics"
+)
+
+// intakeRecoveryRatio is the share of a threshold the backlog must fall under
+// before its mode is left, so a backlog hovering at a threshold does not flap
+const intakeRecoveryRatio = 0.9
+
+// Bounds of the Retry-After given to shed clients
+const (
+	minShedRetryAfter = 5 * time.Second
+	maxShedRetryAfter = 5 * time.Minute
+)
+
+type backpressureUsecase struct {
+	queueRepo domain.QueueRepository
+	config    BackpressureConfig
+	priority  map[string]bool
+
+	mu    sync.RWMutex
+	state *domain.IntakeState
+}
+
+// BackpressureConfig defines when transaction intake degrades and sheds load
+type BackpressureConfig struct {
+	// Enabled switches modes; when disabled the backlog is still sampled for
+	// metrics but intake stays NORMAL
+	Enabled bool
+	// DegradedBacklog and ShedBacklog are queued order counts entering the
+	// modes; a zero ShedBacklog never sheds
+	DegradedBacklog int64
+	ShedBacklog     int64
+	// DrainRate is the orders per second the workers are expected to process,
+	// used to estimate waits
+	DrainRate float64
+	// BaseWait is the expected wait of an order with an empty queue
+	BaseWait time.Duration
+	// PriorityClients are H2H client IDs never shed
+	PriorityClients []string
+}
+
+// DefaultBackpressureConfig returns default backpressure configuration
+func DefaultBackpressureConfig() BackpressureConfig {
+	return BackpressureConfig{
+		Enabled:         true,
+		DegradedBacklog: 500,
+		ShedBacklog:     2000,
+		DrainRate:       20,
+		BaseWait:        10 * time.Second,
+	}
+}
+
+// NewBackpressureUsecase creates a new backpressure use case
+func NewBackpressureUsecase(queueRepo domain.QueueRepository, config BackpressureConfig) domain.BackpressureUsecase {
+	defaults := DefaultBackpressureConfig()
+	if config.DegradedBacklog <= 0 {
+		config.DegradedBacklog = defaults.DegradedBacklog
+	}
+	if config.ShedBacklog < 0 {
+		config.ShedBacklog = 0
+	}
+	if config.ShedBacklog > 0 && config.ShedBacklog < config.DegradedBacklog {
+		config.ShedBacklog = config.DegradedBacklog
+	}
+	if config.DrainRate <= 0 {
+		config.DrainRate = defaults.DrainRate
+	}
+	if config.BaseWait <= 0 {
+		config.BaseWait = defaults.BaseWait
+	}
+
+	priority := make(map[string]bool, len(config.PriorityClients))
+	for _, clientID := range config.PriorityClients {
+		priority[clientID] = true
+	}
+
+	return &backpressureUsecase{
+		queueRepo: queueRepo,
+		config:    config,
+		priority:  priority,
+		state:     &domain.IntakeState{Mode: domain.IntakeNormal, EstimatedWait: config.BaseWait},
+	}
+}
+
+// State returns the last sampled state
+func (uc *backpressureUsecase) State() *domain.IntakeState {
+	uc.mu.RLock()
+	defer uc.mu.RUnlock()
+	return uc.state
+}
+
+// Refresh samples the backlog and derives the mode from it
+func (uc *backpressureUsecase) Refresh() (*domain.IntakeState, error) {
+	backlog, err := uc.queueRepo.GetQueueLength()
+	if err != nil {
+		return uc.State(), err
+	}
+	metrics.SetQueueSize("transactions", float64(backlog))
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	previous := uc.state
+	state := &domain.IntakeState{
+		Mode:          uc.nextMode(previous.Mode, backlog),
+		Backlog:       backlog,
+		EstimatedWait: uc.config.BaseWait + time.Duration(float64(backlog)/uc.config.DrainRate*float64(time.Second)),
+		SampledAt:     time.Now(),
+	}
+	if state.Mode == domain.IntakeShedding {
+		excess := float64(backlog) - float64(uc.config.ShedBacklog)*intakeRecoveryRatio
+		retryAfter := time.Duration(excess / uc.config.DrainRate * float64(time.Second))
+		state.RetryAfter = min(max(retryAfter, minShedRetryAfter), maxShedRetryAfter)
+	}
+	uc.state = state
+	metrics.SetTransactionIntakeMode(state.Mode)
+
+	if state.Mode != previous.Mode {
+		log := logger.Warn
+		if state.Mode == domain.IntakeNormal {
+			log = logger.Info
+		}
+		log("Transaction intake mode changed",
+			logger.String("from", previous.Mode),
+			logger.String("to", state.Mode),
+			logger.Int64("backlog", backlog),
+		)
+	}
+
+	return state, nil
+}
+
+// nextMode enters a mode at its threshold and leaves it only once the
+// backlog is back under intakeRecoveryRatio of the threshold
+func (uc *backpressureUsecase) nextMode(current string, backlog int64) string {
+	if !uc.config.Enabled {
+		return domain.IntakeNormal
+	}
+
+	above := func(threshold int64, inMode bool) bool {
+		if threshold <= 0 {
+			return false
+		}
+		if inMode {
+			return float64(backlog) >= float64(threshold)*intakeRecoveryRatio
+		}
+		return backlog >= threshold
+	}
+
+	switch {
+	case above(uc.config.ShedBacklog, current == domain.IntakeShedding):
+		return domain.IntakeShedding
+	case above(uc.config.DegradedBacklog, current != domain.IntakeNormal):
+		return domain.IntakeDegraded
+	default:
+		return domain.IntakeNormal
+	}
+}
+
+// IsPriorityClient reports whether the client is listed as never shed
+func (uc *backpressureUsecase) IsPriorityClient(client *domain.APIClient) bool {
+	return client != nil && uc.priority[client.ClientID]
+}
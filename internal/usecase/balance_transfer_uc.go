@@ -0,0 +1,356 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+type balanceTransferUsecase struct {
+	userRepo     domain.UserRepository
+	transferRepo domain.BalanceTransferRepository
+	attemptRepo  domain.LoginAttemptRepository
+	unitOfWork   domain.UnitOfWork
+	config       BalanceTransferConfig
+	location     *time.Location
+}
+
+// BalanceTransferConfig defines transfer limits and PIN brute-force protection
+type BalanceTransferConfig struct {
+	MinAmount float64
+	// Limits bounds transfers per sender level; levels without an entry are unlimited
+	Limits map[int]domain.TransferLimit
+	// Timezone cuts the day used by daily limits
+	Timezone string
+	// PINMaxAttempts wrong PINs within PINLockDuration lock transfers for PINLockDuration
+	PINMaxAttempts  int
+	PINLockDuration time.Duration
+}
+
+// DefaultBalanceTransferConfig returns default balance transfer configuration
+func DefaultBalanceTransferConfig() BalanceTransferConfig {
+	return BalanceTransferConfig{
+		MinAmount: 10000,
+		Limits: map[int]domain.TransferLimit{
+			domain.LevelReseller: {MaxAmount: 1000000, DailyLimit: 5000000},
+			domain.LevelAgent:    {MaxAmount: 10000000, DailyLimit: 50000000},
+			domain.LevelMaster:   {MaxAmount: 50000000, DailyLimit: 250000000},
+		},
+		Timezone:        "Asia/Jakarta",
+		PINMaxAttempts:  3,
+		PINLockDuration: 30 * time.Minute,
+	}
+}
+
+// NewBalanceTransferUsecase creates a new balance transfer use case
+func NewBalanceTransferUsecase(
+	userRepo domain.UserRepository,
+	transferRepo domain.BalanceTransferRepository,
+	attemptRepo domain.LoginAttemptRepository,
+	unitOfWork domain.UnitOfWork,
+	config BalanceTransferConfig,
+) domain.BalanceTransferUsecase {
+	defaults := DefaultBalanceTransferConfig()
+	if config.MinAmount <= 0 {
+		config.MinAmount = defaults.MinAmount
+	}
+	if config.Limits == nil {
+		config.Limits = defaults.Limits
+	}
+	if config.Timezone == "" {
+		config.Timezone = defaults.Timezone
+	}
+	if config.PINMaxAttempts <= 0 {
+		config.PINMaxAttempts = defaults.PINMaxAttempts
+	}
+	if config.PINLockDuration <= 0 {
+		config.PINLockDuration = defaults.PINLockDuration
+	}
+
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		logger.Warn("Invalid transfer timezone, falling back to UTC",
+			logger.String("timezone", config.Timezone),
+			logger.ErrorField(err),
+		)
+		config.Timezone = "UTC"
+		location = time.UTC
+	}
+
+	return &balanceTransferUsecase{
+		userRepo:     userRepo,
+		transferRepo: transferRepo,
+		attemptRepo:  attemptRepo,
+		unitOfWork:   unitOfWork,
+		config:       config,
+		location:     location,
+	}
+}
+
+// SetPIN sets or replaces the transaction PIN after checking the password
+func (uc *balanceTransferUsecase) SetPIN(userID, password, pin string) error {
+	if !domain.IsValidPIN(pin) {
+		return fmt.Errorf("PIN must be 6 digits")
+	}
+
+	user, err := uc.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if !utils.VerifyPassword(password, user.PasswordHash) {
+		return fmt.Errorf("invalid password")
+	}
+
+	pinHash, err := utils.HashPassword(pin)
+	if err != nil {
+		return err
+	}
+	if err := uc.userRepo.UpdatePIN(userID, pinHash); err != nil {
+		return err
+	}
+
+	if err := uc.attemptRepo.Unlock(domain.PINAttemptSubject, userID); err != nil {
+		logger.Warn("Failed to clear PIN lock", logger.String("user_id", userID), logger.ErrorField(err))
+	}
+
+	logger.Info("Transaction PIN updated", logger.String("user_id", userID))
+	return nil
+}
+
+// Transfer moves balance from the sender to a direct upline or downline. Both
+// balances, the transfer and the two mutations are written in one database
+// transaction with the user rows locked.
+func (uc *balanceTransferUsecase) Transfer(senderID, recipientUsername string, amount float64, pin string, note *string, actor domain.Actor) (*domain.BalanceTransfer, error) {
+	amount = utils.RoundToDecimal(amount, 2)
+	if !utils.IsValidAmount(amount) || amount < uc.config.MinAmount {
+		return nil, fmt.Errorf("minimum transfer amount is %.0f", uc.config.MinAmount)
+	}
+	if note != nil {
+		trimmed := strings.TrimSpace(*note)
+		if len(trimmed) > 255 {
+			return nil, fmt.Errorf("note must be at most 255 characters")
+		}
+		note = &trimmed
+		if trimmed == "" {
+			note = nil
+		}
+	}
+
+	sender, err := uc.userRepo.GetByID(senderID)
+	if err != nil {
+		return nil, err
+	}
+	if !sender.IsActive {
+		return nil, fmt.Errorf("user account is inactive")
+	}
+
+	recipient, err := uc.userRepo.GetByUsername(strings.TrimSpace(recipientUsername))
+	if err != nil || !recipient.IsActive {
+		return nil, fmt.Errorf("recipient not found")
+	}
+	if recipient.ID == sender.ID {
+		return nil, fmt.Errorf("cannot transfer to yourself")
+	}
+	if !sender.CanTransferTo(recipient) {
+		return nil, fmt.Errorf("transfers are only allowed between upline and downline")
+	}
+
+	limit := uc.config.Limits[sender.Level]
+	if limit.MaxAmount > 0 && amount > limit.MaxAmount {
+		return nil, fmt.Errorf("amount exceeds the transfer limit of %.0f", limit.MaxAmount)
+	}
+
+	if err := uc.verifyPIN(sender.ID, pin); err != nil {
+		return nil, err
+	}
+
+	transfer := &domain.BalanceTransfer{
+		ID:                utils.GenerateUUID(),
+		SenderID:          sender.ID,
+		SenderUsername:    sender.Username,
+		RecipientID:       recipient.ID,
+		RecipientUsername: recipient.Username,
+		Amount:            amount,
+		Note:              note,
+		Direction:         domain.TransferDirectionOut,
+		CreatedAt:         time.Now(),
+	}
+
+	refType := domain.ReferenceTypeTransfer
+	err = uc.unitOfWork.Do(func(repos domain.TxRepositories) error {
+		if err := repos.Transfers().LockUsers(sender.ID, recipient.ID); err != nil {
+			return err
+		}
+
+		// Re-read balances now that the rows are locked
+		lockedSender, err := repos.Users().GetByID(sender.ID)
+		if err != nil {
+			return err
+		}
+		lockedRecipient, err := repos.Users().GetByID(recipient.ID)
+		if err != nil {
+			return err
+		}
+
+		if lockedSender.AvailableBalance() < amount {
+			return fmt.Errorf("insufficient balance")
+		}
+
+		if limit.DailyLimit > 0 {
+			sent, err := repos.Transfers().SumSentSince(sender.ID, uc.startOfDay(transfer.CreatedAt))
+			if err != nil {
+				return err
+			}
+			if sent+amount > limit.DailyLimit {
+				return fmt.Errorf("daily transfer limit of %.0f exceeded", limit.DailyLimit)
+			}
+		}
+
+		senderBalance := lockedSender.Balance - amount
+		recipientBalance := lockedRecipient.Balance + amount
+		if err := repos.Users().UpdateBalance(sender.ID, senderBalance); err != nil {
+			return err
+		}
+		if err := repos.Users().UpdateBalance(recipient.ID, recipientBalance); err != nil {
+			return err
+		}
+		if err := repos.Transfers().Create(transfer); err != nil {
+			return err
+		}
+
+		if err := createBalanceMutation(
+			repos,
+			sender.ID,
+			domain.MutationTypeCredit, // Credit = money out
+			amount,
+			lockedSender.Balance,
+			senderBalance,
+			fmt.Sprintf("Transfer saldo ke %s", recipient.Username),
+			&refType,
+			&transfer.ID,
+			actor,
+		); err != nil {
+			return err
+		}
+		return createBalanceMutation(
+			repos,
+			recipient.ID,
+			domain.MutationTypeDebit, // Debit = money in
+			amount,
+			lockedRecipient.Balance,
+			recipientBalance,
+			fmt.Sprintf("Transfer saldo dari %s", sender.Username),
+			&refType,
+			&transfer.ID,
+			actor,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Balance transferred",
+		logger.String("transfer_id", transfer.ID),
+		logger.String("sender_id", sender.ID),
+		logger.String("recipient_id", recipient.ID),
+		logger.Float64("amount", amount),
+	)
+
+	return transfer, nil
+}
+
+// ListTransfers lists transfers sent or received by a user using keyset
+// pagination and returns the cursor of the next page
+func (uc *balanceTransferUsecase) ListTransfers(userID, cursor string, limit int) ([]*domain.BalanceTransfer, string, error) {
+	after, err := domain.DecodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	limit = domain.NormalizeCursorLimit(limit)
+
+	transfers, err := uc.transferRepo.GetByUserAfter(userID, after, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(transfers) > limit {
+		transfers = transfers[:limit]
+		last := transfers[limit-1]
+		nextCursor = domain.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	for _, transfer := range transfers {
+		transfer.Direction = domain.TransferDirectionIn
+		if transfer.SenderID == userID {
+			transfer.Direction = domain.TransferDirectionOut
+		}
+	}
+
+	return transfers, nextCursor, nil
+}
+
+// VerifyPIN checks the transaction PIN for another use case, such as
+// confirming a large order. Wrong PINs count towards the same lock.
+func (uc *balanceTransferUsecase) VerifyPIN(userID, pin string) error {
+	return uc.verifyPIN(userID, pin)
+}
+
+// verifyPIN checks the transaction PIN and locks transfers after too many
+// wrong attempts
+func (uc *balanceTransferUsecase) verifyPIN(userID, pin string) error {
+	ttl, err := uc.attemptRepo.GetLockTTL(domain.PINAttemptSubject, userID)
+	if err != nil {
+		return err
+	}
+	if ttl > 0 {
+		return &domain.PINLockedError{RetryAfter: ttl}
+	}
+
+	pinHash, err := uc.userRepo.GetPINHash(userID)
+	if err != nil {
+		return err
+	}
+	if pinHash == nil {
+		return fmt.Errorf("transaction PIN is not set")
+	}
+
+	if utils.VerifyPassword(pin, *pinHash) {
+		if err := uc.attemptRepo.ResetFailures(domain.PINAttemptSubject, userID); err != nil {
+			logger.Warn("Failed to reset PIN failures", logger.String("user_id", userID), logger.ErrorField(err))
+		}
+		return nil
+	}
+
+	failures, err := uc.attemptRepo.IncrementFailures(domain.PINAttemptSubject, userID, uc.config.PINLockDuration)
+	if err != nil {
+		logger.Error("Failed to record PIN failure", logger.String("user_id", userID), logger.ErrorField(err))
+		return fmt.Errorf("invalid PIN")
+	}
+	if failures >= uc.config.PINMaxAttempts {
+		if _, err := uc.attemptRepo.Lock(domain.PINAttemptSubject, userID, uc.config.PINLockDuration, uc.config.PINLockDuration); err != nil {
+			logger.Error("Failed to lock PIN", logger.String("user_id", userID), logger.ErrorField(err))
+		}
+		if err := uc.attemptRepo.ResetFailures(domain.PINAttemptSubject, userID); err != nil {
+			logger.Warn("Failed to reset PIN failures", logger.String("user_id", userID), logger.ErrorField(err))
+		}
+
+		logger.Warn("Transfers locked after repeated wrong PINs",
+			logger.String("user_id", userID),
+			logger.Int("failures", failures),
+		)
+		return &domain.PINLockedError{RetryAfter: uc.config.PINLockDuration}
+	}
+
+	return fmt.Errorf("invalid PIN")
+}
+
+// startOfDay returns midnight of the given time in the transfer timezone
+func (uc *balanceTransferUsecase) startOfDay(t time.Time) time.Time {
+	local := t.In(uc.location)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, uc.location)
+}
@@ -0,0 +1,272 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+)
+
+type catalogUsecase struct {
+	categoryRepo domain.ProductCategoryRepository
+	providerRepo domain.ProductProviderRepository
+	config       CatalogConfig
+
+	mu             sync.Mutex
+	categoryCodes  map[string]bool
+	providerCodes  map[string]bool
+	codesExpiresAt time.Time
+}
+
+// CatalogConfig defines how the registered category and provider codes are
+// cached for validation
+type CatalogConfig struct {
+	// CacheTTL bounds how long a replica keeps validating against codes
+	// changed elsewhere
+	CacheTTL time.Duration
+}
+
+// DefaultCatalogConfig returns default catalog configuration
+func DefaultCatalogConfig() CatalogConfig {
+	return CatalogConfig{
+		CacheTTL: 30 * time.Second,
+	}
+}
+
+// NewCatalogUsecase creates a new catalog use case
+func NewCatalogUsecase(categoryRepo domain.ProductCategoryRepository, providerRepo domain.ProductProviderRepository, config CatalogConfig) domain.CatalogUsecase {
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = DefaultCatalogConfig().CacheTTL
+	}
+
+	return &catalogUsecase{
+		categoryRepo: categoryRepo,
+		providerRepo: providerRepo,
+		config:       config,
+	}
+}
+
+// CreateCategory validates and stores a new, active product category
+func (uc *catalogUsecase) CreateCategory(category *domain.ProductCategory) error {
+	if category == nil {
+		return fmt.Errorf("product category payload is required")
+	}
+
+	category.Code = domain.NormalizeCatalogCode(category.Code)
+	category.Name = strings.TrimSpace(category.Name)
+	category.IconURL = normalizeIconURL(category.IconURL)
+	if err := category.Check(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	category.IsActive = true
+	category.CreatedAt = now
+	category.UpdatedAt = now
+
+	if err := uc.categoryRepo.Create(category); err != nil {
+		return err
+	}
+	uc.invalidate()
+	return nil
+}
+
+// UpdateCategory changes the display fields or state of a category. Its code
+// cannot change because products reference it.
+func (uc *catalogUsecase) UpdateCategory(code string, updates *domain.CatalogEntryUpdate) (*domain.ProductCategory, error) {
+	if updates == nil {
+		return nil, fmt.Errorf("product category payload is required")
+	}
+
+	category, err := uc.categoryRepo.GetByCode(domain.NormalizeCatalogCode(code))
+	if err != nil {
+		return nil, err
+	}
+
+	applyCatalogUpdate(updates, &category.Name, &category.IconURL, &category.DisplayOrder, &category.IsActive)
+	if err := category.Check(); err != nil {
+		return nil, err
+	}
+	category.UpdatedAt = time.Now()
+
+	if err := uc.categoryRepo.Update(category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// GetCategory returns a product category by code
+func (uc *catalogUsecase) GetCategory(code string) (*domain.ProductCategory, error) {
+	return uc.categoryRepo.GetByCode(domain.NormalizeCatalogCode(code))
+}
+
+// ListCategories lists product categories in display order
+func (uc *catalogUsecase) ListCategories(activeOnly bool) ([]*domain.ProductCategory, error) {
+	return uc.categoryRepo.List(activeOnly)
+}
+
+// CreateProvider validates and stores a new, active product provider
+func (uc *catalogUsecase) CreateProvider(provider *domain.ProductProvider) error {
+	if provider == nil {
+		return fmt.Errorf("product provider payload is required")
+	}
+
+	provider.Code = domain.NormalizeCatalogCode(provider.Code)
+	provider.Name = strings.TrimSpace(provider.Name)
+	provider.IconURL = normalizeIconURL(provider.IconURL)
+	if err := provider.Check(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	provider.IsActive = true
+	provider.CreatedAt = now
+	provider.UpdatedAt = now
+
+	if err := uc.providerRepo.Create(provider); err != nil {
+		return err
+	}
+	uc.invalidate()
+	return nil
+}
+
+// UpdateProvider changes the display fields or state of a provider. Its code
+// cannot change because products reference it.
+func (uc *catalogUsecase) UpdateProvider(code string, updates *domain.CatalogEntryUpdate) (*domain.ProductProvider, error) {
+	if updates == nil {
+		return nil, fmt.Errorf("product provider payload is required")
+	}
+
+	provider, err := uc.providerRepo.GetByCode(domain.NormalizeCatalogCode(code))
+	if err != nil {
+		return nil, err
+	}
+
+	applyCatalogUpdate(updates, &provider.Name, &provider.IconURL, &provider.DisplayOrder, &provider.IsActive)
+	if err := provider.Check(); err != nil {
+		return nil, err
+	}
+	provider.UpdatedAt = time.Now()
+
+	if err := uc.providerRepo.Update(provider); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+// GetProvider returns a product provider by code
+func (uc *catalogUsecase) GetProvider(code string) (*domain.ProductProvider, error) {
+	return uc.providerRepo.GetByCode(domain.NormalizeCatalogCode(code))
+}
+
+// ListProviders lists product providers in display order, optionally only
+// the ones selling in a category
+func (uc *catalogUsecase) ListProviders(activeOnly bool, category string) ([]*domain.ProductProvider, error) {
+	category = domain.NormalizeCatalogCode(category)
+	if category != "" {
+		if err := uc.ValidateCategory(category); err != nil {
+			return nil, err
+		}
+	}
+	return uc.providerRepo.List(activeOnly, category)
+}
+
+// ValidateCategory checks that a category code is registered
+func (uc *catalogUsecase) ValidateCategory(code string) error {
+	categories, _, err := uc.codes()
+	if err != nil {
+		return err
+	}
+	if !categories[code] {
+		return fmt.Errorf("invalid product category")
+	}
+	return nil
+}
+
+// ValidateProvider checks that a provider code is registered
+func (uc *catalogUsecase) ValidateProvider(code string) error {
+	_, providers, err := uc.codes()
+	if err != nil {
+		return err
+	}
+	if !providers[code] {
+		return fmt.Errorf("invalid product provider")
+	}
+	return nil
+}
+
+// codes returns the registered category and provider codes, refreshed at
+// most every CacheTTL
+func (uc *catalogUsecase) codes() (map[string]bool, map[string]bool, error) {
+	now := time.Now()
+
+	uc.mu.Lock()
+	if now.Before(uc.codesExpiresAt) {
+		categories, providers := uc.categoryCodes, uc.providerCodes
+		uc.mu.Unlock()
+		return categories, providers, nil
+	}
+	uc.mu.Unlock()
+
+	categoryList, err := uc.categoryRepo.List(false)
+	if err != nil {
+		return nil, nil, err
+	}
+	providerList, err := uc.providerRepo.List(false, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	categories := make(map[string]bool, len(categoryList))
+	for _, category := range categoryList {
+		categories[category.Code] = true
+	}
+	providers := make(map[string]bool, len(providerList))
+	for _, provider := range providerList {
+		providers[provider.Code] = true
+	}
+
+	uc.mu.Lock()
+	uc.categoryCodes = categories
+	uc.providerCodes = providers
+	uc.codesExpiresAt = now.Add(uc.config.CacheTTL)
+	uc.mu.Unlock()
+
+	return categories, providers, nil
+}
+
+func (uc *catalogUsecase) invalidate() {
+	uc.mu.Lock()
+	uc.codesExpiresAt = time.Time{}
+	uc.mu.Unlock()
+}
+
+// applyCatalogUpdate copies the set fields of updates onto a category or provider
+func applyCatalogUpdate(updates *domain.CatalogEntryUpdate, name *string, iconURL **string, displayOrder *int, isActive *bool) {
+	if updates.Name != nil {
+		*name = strings.TrimSpace(*updates.Name)
+	}
+	if updates.IconURL != nil {
+		*iconURL = normalizeIconURL(updates.IconURL)
+	}
+	if updates.DisplayOrder != nil {
+		*displayOrder = *updates.DisplayOrder
+	}
+	if updates.IsActive != nil {
+		*isActive = *updates.IsActive
+	}
+}
+
+// normalizeIconURL trims an icon URL; an empty one means no icon
+func normalizeIconURL(iconURL *string) *string {
+	if iconURL == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*iconURL)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
@@ -0,0 +1,300 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+type cutoffUsecase struct {
+	scheduleRepo domain.CutoffScheduleRepository
+	supplierRepo domain.SupplierRepository
+	catalogUC    domain.CatalogUsecase
+	config       CutoffConfig
+	location     *time.Location
+
+	mu              sync.Mutex
+	active          []*domain.CutoffSchedule
+	activeExpiresAt time.Time
+}
+
+// CutoffConfig defines how cutoff schedules are evaluated
+type CutoffConfig struct {
+	// Timezone of the HH:MM times of daily cutoffs
+	Timezone string
+	// CacheTTL bounds how long a replica keeps using schedules changed elsewhere
+	CacheTTL time.Duration
+}
+
+// DefaultCutoffConfig returns default cutoff configuration
+func DefaultCutoffConfig() CutoffConfig {
+	return CutoffConfig{
+		Timezone: "Asia/Jakarta",
+		CacheTTL: 30 * time.Second,
+	}
+}
+
+// NewCutoffUsecase creates a new cutoff schedule use case
+func NewCutoffUsecase(scheduleRepo domain.CutoffScheduleRepository, supplierRepo domain.SupplierRepository, catalogUC domain.CatalogUsecase, config CutoffConfig) domain.CutoffUsecase {
+	defaults := DefaultCutoffConfig()
+	if config.Timezone == "" {
+		config.Timezone = defaults.Timezone
+	}
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = defaults.CacheTTL
+	}
+
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		logger.Warn("Invalid cutoff timezone, falling back to UTC",
+			logger.String("timezone", config.Timezone),
+			logger.ErrorField(err),
+		)
+		config.Timezone = "UTC"
+		location = time.UTC
+	}
+
+	return &cutoffUsecase{
+		scheduleRepo: scheduleRepo,
+		supplierRepo: supplierRepo,
+		catalogUC:    catalogUC,
+		config:       config,
+		location:     location,
+	}
+}
+
+// CreateSchedule validates and stores a new cutoff schedule
+func (uc *cutoffUsecase) CreateSchedule(schedule *domain.CutoffSchedule) error {
+	if schedule == nil {
+		return fmt.Errorf("cutoff schedule payload is required")
+	}
+
+	schedule.ScopeType = strings.ToUpper(strings.TrimSpace(schedule.ScopeType))
+	schedule.ScopeValue = strings.TrimSpace(schedule.ScopeValue)
+	if !domain.IsValidCutoffScope(schedule.ScopeType) {
+		return fmt.Errorf("invalid cutoff scope")
+	}
+
+	switch schedule.ScopeType {
+	case domain.CutoffScopeCategory:
+		schedule.ScopeValue = strings.ToUpper(schedule.ScopeValue)
+		if err := uc.catalogUC.ValidateCategory(schedule.ScopeValue); err != nil {
+			return err
+		}
+	case domain.CutoffScopeSupplier:
+		if _, err := uc.supplierRepo.GetByID(schedule.ScopeValue); err != nil {
+			return fmt.Errorf("supplier not found")
+		}
+	}
+
+	if err := normalizeCutoffSchedule(schedule); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	schedule.ID = utils.GenerateUUID()
+	schedule.IsActive = true
+	schedule.CreatedAt = now
+	schedule.UpdatedAt = now
+
+	if err := uc.scheduleRepo.Create(schedule); err != nil {
+		return err
+	}
+	uc.invalidate()
+	return nil
+}
+
+// UpdateSchedule changes the window, action, reason or state of a schedule.
+// Its scope cannot change.
+func (uc *cutoffUsecase) UpdateSchedule(id string, updates *domain.CutoffScheduleUpdate) (*domain.CutoffSchedule, error) {
+	if updates == nil {
+		return nil, fmt.Errorf("cutoff schedule payload is required")
+	}
+
+	schedule, err := uc.scheduleRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if updates.Kind != nil {
+		schedule.Kind = *updates.Kind
+	}
+	if updates.StartTime != nil {
+		schedule.StartTime = updates.StartTime
+	}
+	if updates.EndTime != nil {
+		schedule.EndTime = updates.EndTime
+	}
+	if updates.StartsAt != nil {
+		schedule.StartsAt = updates.StartsAt
+	}
+	if updates.EndsAt != nil {
+		schedule.EndsAt = updates.EndsAt
+	}
+	if updates.Action != nil {
+		schedule.Action = *updates.Action
+	}
+	if updates.Reason != nil {
+		schedule.Reason = updates.Reason
+	}
+	if updates.IsActive != nil {
+		schedule.IsActive = *updates.IsActive
+	}
+
+	if err := normalizeCutoffSchedule(schedule); err != nil {
+		return nil, err
+	}
+	schedule.UpdatedAt = time.Now()
+
+	if err := uc.scheduleRepo.Update(schedule); err != nil {
+		return nil, err
+	}
+	uc.invalidate()
+	return schedule, nil
+}
+
+// DeleteSchedule removes a cutoff schedule
+func (uc *cutoffUsecase) DeleteSchedule(id string) error {
+	if err := uc.scheduleRepo.Delete(id); err != nil {
+		return err
+	}
+	uc.invalidate()
+	return nil
+}
+
+// GetSchedule returns a cutoff schedule by ID
+func (uc *cutoffUsecase) GetSchedule(id string) (*domain.CutoffSchedule, error) {
+	return uc.scheduleRepo.GetByID(id)
+}
+
+// ListSchedules lists cutoff schedules, optionally filtered by scope
+func (uc *cutoffUsecase) ListSchedules(scopeType, scopeValue string) ([]*domain.CutoffSchedule, error) {
+	scopeType = strings.ToUpper(strings.TrimSpace(scopeType))
+	if scopeType == domain.CutoffScopeCategory {
+		scopeValue = strings.ToUpper(scopeValue)
+	}
+	return uc.scheduleRepo.List(scopeType, strings.TrimSpace(scopeValue))
+}
+
+// CategoryCutoff returns the cutoff of a category in force at now. When
+// several overlap a BLOCK wins, then the one ending last.
+func (uc *cutoffUsecase) CategoryCutoff(category string, now time.Time) (*domain.ActiveCutoff, error) {
+	schedules, err := uc.activeSchedules()
+	if err != nil {
+		return nil, err
+	}
+
+	var cutoff *domain.ActiveCutoff
+	for _, schedule := range schedules {
+		if schedule.ScopeType != domain.CutoffScopeCategory || schedule.ScopeValue != category {
+			continue
+		}
+		until, ok := schedule.ActiveAt(now, uc.location)
+		if !ok {
+			continue
+		}
+		if cutoff == nil || outranksCutoff(schedule, until, cutoff) {
+			cutoff = &domain.ActiveCutoff{Schedule: schedule, Until: until}
+		}
+	}
+
+	return cutoff, nil
+}
+
+// SupplierCutoffs returns the cutoffs in force at now of the given suppliers
+func (uc *cutoffUsecase) SupplierCutoffs(supplierIDs []string, now time.Time) (map[string]*domain.ActiveCutoff, error) {
+	schedules, err := uc.activeSchedules()
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(supplierIDs))
+	for _, id := range supplierIDs {
+		wanted[id] = true
+	}
+
+	cutoffs := make(map[string]*domain.ActiveCutoff)
+	for _, schedule := range schedules {
+		if schedule.ScopeType != domain.CutoffScopeSupplier || !wanted[schedule.ScopeValue] {
+			continue
+		}
+		until, ok := schedule.ActiveAt(now, uc.location)
+		if !ok {
+			continue
+		}
+		if current, exists := cutoffs[schedule.ScopeValue]; !exists || outranksCutoff(schedule, until, current) {
+			cutoffs[schedule.ScopeValue] = &domain.ActiveCutoff{Schedule: schedule, Until: until}
+		}
+	}
+
+	return cutoffs, nil
+}
+
+// activeSchedules returns the active schedules, refreshed at most every CacheTTL
+func (uc *cutoffUsecase) activeSchedules() ([]*domain.CutoffSchedule, error) {
+	now := time.Now()
+
+	uc.mu.Lock()
+	if now.Before(uc.activeExpiresAt) {
+		schedules := uc.active
+		uc.mu.Unlock()
+		return schedules, nil
+	}
+	uc.mu.Unlock()
+
+	schedules, err := uc.scheduleRepo.ListActive()
+	if err != nil {
+		return nil, err
+	}
+
+	uc.mu.Lock()
+	uc.active = schedules
+	uc.activeExpiresAt = now.Add(uc.config.CacheTTL)
+	uc.mu.Unlock()
+
+	return schedules, nil
+}
+
+func (uc *cutoffUsecase) invalidate() {
+	uc.mu.Lock()
+	uc.activeExpiresAt = time.Time{}
+	uc.mu.Unlock()
+}
+
+// outranksCutoff reports whether a schedule active until until takes
+// precedence over the current cutoff
+func outranksCutoff(schedule *domain.CutoffSchedule, until time.Time, current *domain.ActiveCutoff) bool {
+	blocks := schedule.Action == domain.CutoffActionBlock
+	currentBlocks := current.Schedule.Action == domain.CutoffActionBlock
+	if blocks != currentBlocks {
+		return blocks
+	}
+	return until.After(current.Until)
+}
+
+// normalizeCutoffSchedule upper-cases enum fields, fills the default action
+// and clears the fields of the other window kind
+func normalizeCutoffSchedule(schedule *domain.CutoffSchedule) error {
+	schedule.Kind = strings.ToUpper(strings.TrimSpace(schedule.Kind))
+	schedule.Action = strings.ToUpper(strings.TrimSpace(schedule.Action))
+	if schedule.Action == "" {
+		schedule.Action = domain.CutoffActionQueue
+	}
+	if !domain.IsValidCutoffAction(schedule.Action) {
+		return fmt.Errorf("invalid cutoff action")
+	}
+
+	switch schedule.Kind {
+	case domain.CutoffKindDaily:
+		schedule.StartsAt, schedule.EndsAt = nil, nil
+	case domain.CutoffKindOnce:
+		schedule.StartTime, schedule.EndTime = nil, nil
+	}
+
+	return schedule.Check()
+}
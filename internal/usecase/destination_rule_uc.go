@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+type destinationRuleUsecase struct {
+	destinationRuleRepo domain.DestinationRuleRepository
+	catalogUC           domain.CatalogUsecase
+	phones              *utils.PhoneNormalizer
+}
+
+// NewDestinationRuleUsecase creates a new destination rule use case. phones
+// picks the numbering plan of each product; nil uses the Indonesian plan.
+func NewDestinationRuleUsecase(destinationRuleRepo domain.DestinationRuleRepository, catalogUC domain.CatalogUsecase, phones *utils.PhoneNormalizer) domain.DestinationRuleUsecase {
+	if phones == nil {
+		phones = utils.DefaultPhoneNormalizer()
+	}
+	return &destinationRuleUsecase{destinationRuleRepo: destinationRuleRepo, catalogUC: catalogUC, phones: phones}
+}
+
+// ListRules lists the destination rule of every configured category
+func (uc *destinationRuleUsecase) ListRules() ([]*domain.DestinationRule, error) {
+	return uc.destinationRuleRepo.List()
+}
+
+// UpdateRule validates and stores the destination rule of a category
+func (uc *destinationRuleUsecase) UpdateRule(rule *domain.DestinationRule) error {
+	if rule == nil {
+		return fmt.Errorf("destination rule payload is required")
+	}
+
+	rule.Category = strings.ToUpper(strings.TrimSpace(rule.Category))
+	if err := uc.catalogUC.ValidateCategory(rule.Category); err != nil {
+		return err
+	}
+
+	rule.Hint = strings.TrimSpace(rule.Hint)
+	if rule.Hint == "" {
+		return fmt.Errorf("destination hint is required")
+	}
+
+	if err := rule.Check(); err != nil {
+		return err
+	}
+
+	return uc.destinationRuleRepo.Upsert(rule)
+}
+
+// ValidateDestination applies the product override on top of the category
+// rule. Categories without a rule keep the phone number check of the
+// product's numbering plan.
+func (uc *destinationRuleUsecase) ValidateDestination(product *domain.Product, destination string) (string, error) {
+	destination = domain.NormalizeDestination(destination)
+
+	rule, err := uc.destinationRuleRepo.GetByCategory(product.Category)
+	if err != nil {
+		if err.Error() != "destination rule not found" {
+			logger.Error("Failed to get destination rule",
+				logger.String("category", product.Category),
+				logger.ErrorField(err),
+			)
+			return "", err
+		}
+		rule = nil
+	}
+
+	plan := uc.phones.Plan(product.Code, product.Category)
+	if rule == nil && !product.HasDestinationOverride() {
+		if !plan.Validate(destination) {
+			return "", &domain.DestinationError{Hint: plan.Hint}
+		}
+		return plan.Parse(destination), nil
+	}
+
+	merged := product.ApplyDestinationOverride(rule)
+	if err := merged.Validate(destination); err != nil {
+		return "", err
+	}
+
+	if merged.NormalizePhone {
+		return plan.Parse(destination), nil
+	}
+	return destination, nil
+}
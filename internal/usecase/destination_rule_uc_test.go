@@ -0,0 +1,274 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+type fakeDestinationRuleRepo struct {
+	rules    map[string]*domain.DestinationRule
+	err      error
+	upserted *domain.DestinationRule
+}
+
+func (r *fakeDestinationRuleRepo) GetByCategory(category string) (*domain.DestinationRule, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	rule, ok := r.rules[category]
+	if !ok {
+		return nil, fmt.Errorf("destination rule not found")
+	}
+	return rule, nil
+}
+
+func (r *fakeDestinationRuleRepo) List() ([]*domain.DestinationRule, error) {
+	rules := make([]*domain.DestinationRule, 0, len(r.rules))
+	for _, rule := range r.rules {
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (r *fakeDestinationRuleRepo) Upsert(rule *domain.DestinationRule) error {
+	r.upserted = rule
+	return nil
+}
+
+// fakeCatalogUsecase only implements category validation
+type fakeCatalogUsecase struct {
+	domain.CatalogUsecase
+	categories map[string]bool
+}
+
+func (c *fakeCatalogUsecase) ValidateCategory(code string) error {
+	if !c.categories[code] {
+		return fmt.Errorf("invalid product category")
+	}
+	return nil
+}
+
+func stringPtr(s string) *string { return &s }
+
+func intPtr(i int) *int { return &i }
+
+func TestDestinationRuleValidateDestination(t *testing.T) {
+	repo := &fakeDestinationRuleRepo{rules: map[string]*domain.DestinationRule{
+		"PLN": {Category: "PLN", Pattern: `^\d+$`, MinLength: 11, MaxLength: 12, Hint: "Nomor meter 11-12 digit"},
+		"PULSA_INTL": {Category: "PULSA_INTL", Pattern: `^\+?\d+$`, MinLength: 8, MaxLength: 16,
+			Hint: "Nomor HP internasional", NormalizePhone: true},
+	}}
+	phones, err := utils.NewPhoneNormalizer("ID",
+		map[string]string{"XLMY10": "MY"},
+		map[string]string{"PULSA_SG": "SG", "PULSA_INTL": "PH"},
+	)
+	if err != nil {
+		t.Fatalf("NewPhoneNormalizer() unexpected error: %v", err)
+	}
+	uc := NewDestinationRuleUsecase(repo, &fakeCatalogUsecase{}, phones)
+
+	tests := []struct {
+		name        string
+		product     *domain.Product
+		destination string
+		want        string
+		wantErr     string
+	}{
+		{
+			name:        "default plan leading zero",
+			product:     &domain.Product{Code: "TSEL10", Category: "PULSA"},
+			destination: "0812 3456 7890",
+			want:        "6281234567890",
+		},
+		{
+			name:        "default plan +62",
+			product:     &domain.Product{Code: "TSEL10", Category: "PULSA"},
+			destination: "+6281234567890",
+			want:        "6281234567890",
+		},
+		{
+			name:        "default plan rejects short number",
+			product:     &domain.Product{Code: "TSEL10", Category: "PULSA"},
+			destination: "0812345",
+			wantErr:     "invalid destination number: " + utils.PhonePlans["ID"].Hint,
+		},
+		{
+			name:        "default plan rejects foreign number",
+			product:     &domain.Product{Code: "TSEL10", Category: "PULSA"},
+			destination: "+60123456789",
+			wantErr:     "invalid destination number: " + utils.PhonePlans["ID"].Hint,
+		},
+		{
+			name:        "per-product plan",
+			product:     &domain.Product{Code: "XLMY10", Category: "PULSA"},
+			destination: "0123456789",
+			want:        "60123456789",
+		},
+		{
+			name:        "per-product plan rejects Indonesian number",
+			product:     &domain.Product{Code: "XLMY10", Category: "PULSA"},
+			destination: "081234567890",
+			wantErr:     "invalid destination number: " + utils.PhonePlans["MY"].Hint,
+		},
+		{
+			name:        "per-category plan",
+			product:     &domain.Product{Code: "SING10", Category: "PULSA_SG"},
+			destination: "9123 4567",
+			want:        "6591234567",
+		},
+		{
+			name:        "per-category plan rejects short number",
+			product:     &domain.Product{Code: "SING10", Category: "PULSA_SG"},
+			destination: "912345",
+			wantErr:     "invalid destination number: " + utils.PhonePlans["SG"].Hint,
+		},
+		{
+			name:        "category rule keeps the destination as typed",
+			product:     &domain.Product{Code: "PLN20", Category: "PLN"},
+			destination: "123456789012",
+			want:        "123456789012",
+		},
+		{
+			name:        "category rule rejects letters",
+			product:     &domain.Product{Code: "PLN20", Category: "PLN"},
+			destination: "12345678901a",
+			wantErr:     "invalid destination number: Nomor meter 11-12 digit",
+		},
+		{
+			name:        "category rule rejects length",
+			product:     &domain.Product{Code: "PLN20", Category: "PLN"},
+			destination: "1234567890",
+			wantErr:     "invalid destination number: Nomor meter 11-12 digit",
+		},
+		{
+			name:        "category rule normalizes with the category plan",
+			product:     &domain.Product{Code: "SMART10", Category: "PULSA_INTL"},
+			destination: "09171234567",
+			want:        "639171234567",
+		},
+		{
+			name:        "product plan wins in a normalizing category rule",
+			product:     &domain.Product{Code: "XLMY10", Category: "PULSA_INTL"},
+			destination: "0123456789",
+			want:        "60123456789",
+		},
+		{
+			name: "product override on top of the category rule",
+			product: &domain.Product{Code: "PLN20", Category: "PLN",
+				DestinationMinLength: intPtr(6), DestinationHint: stringPtr("ID pelanggan")},
+			destination: "123456",
+			want:        "123456",
+		},
+		{
+			name: "product override keeps the category pattern",
+			product: &domain.Product{Code: "PLN20", Category: "PLN",
+				DestinationMinLength: intPtr(6), DestinationHint: stringPtr("ID pelanggan")},
+			destination: "12345a",
+			wantErr:     "invalid destination number: ID pelanggan",
+		},
+		{
+			name: "product override without a category rule",
+			product: &domain.Product{Code: "GAME10", Category: "GAME",
+				DestinationPattern: stringPtr(`^\d+\(\d+\)$`), DestinationHint: stringPtr("ID(server)")},
+			destination: "12345(6789)",
+			want:        "12345(6789)",
+		},
+		{
+			name: "product override without a category rule rejects",
+			product: &domain.Product{Code: "GAME10", Category: "GAME",
+				DestinationPattern: stringPtr(`^\d+\(\d+\)$`), DestinationHint: stringPtr("ID(server)")},
+			destination: "123456789",
+			wantErr:     "invalid destination number: ID(server)",
+		},
+		{
+			name:        "empty destination",
+			product:     &domain.Product{Code: "PLN20", Category: "PLN"},
+			destination: "   ",
+			wantErr:     "invalid destination number: Nomor meter 11-12 digit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := uc.ValidateDestination(tt.product, tt.destination)
+			if tt.wantErr != "" {
+				var destinationErr *domain.DestinationError
+				if !errors.As(err, &destinationErr) || err.Error() != tt.wantErr {
+					t.Fatalf("ValidateDestination() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateDestination() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ValidateDestination() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDestinationRuleValidateDestinationDefaultsToIndonesia(t *testing.T) {
+	uc := NewDestinationRuleUsecase(&fakeDestinationRuleRepo{}, &fakeCatalogUsecase{}, nil)
+
+	got, err := uc.ValidateDestination(&domain.Product{Code: "TSEL10", Category: "PULSA"}, "081234567890")
+	if err != nil {
+		t.Fatalf("ValidateDestination() unexpected error: %v", err)
+	}
+	if got != "6281234567890" {
+		t.Errorf("ValidateDestination() = %q, want 6281234567890", got)
+	}
+}
+
+func TestDestinationRuleValidateDestinationRepositoryError(t *testing.T) {
+	repo := &fakeDestinationRuleRepo{err: fmt.Errorf("failed to get destination rule: connection refused")}
+	uc := NewDestinationRuleUsecase(repo, &fakeCatalogUsecase{}, nil)
+
+	if _, err := uc.ValidateDestination(&domain.Product{Code: "TSEL10", Category: "PULSA"}, "081234567890"); err != repo.err {
+		t.Errorf("ValidateDestination() error = %v, want %v", err, repo.err)
+	}
+}
+
+func TestDestinationRuleUpdateRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    *domain.DestinationRule
+		wantErr string
+	}{
+		{name: "nil payload", rule: nil, wantErr: "destination rule payload is required"},
+		{name: "unknown category", rule: &domain.DestinationRule{Category: "FOOD", Hint: "x"}, wantErr: "invalid product category"},
+		{name: "missing hint", rule: &domain.DestinationRule{Category: "PLN", Hint: "  "}, wantErr: "destination hint is required"},
+		{name: "negative length", rule: &domain.DestinationRule{Category: "PLN", Hint: "x", MinLength: -1}, wantErr: "destination length cannot be negative"},
+		{name: "min above max", rule: &domain.DestinationRule{Category: "PLN", Hint: "x", MinLength: 12, MaxLength: 11}, wantErr: "destination min length exceeds max length"},
+		{name: "invalid pattern", rule: &domain.DestinationRule{Category: "PLN", Hint: "x", Pattern: "[0-9"}, wantErr: "invalid destination pattern: error parsing regexp: missing closing ]: `[0-9`"},
+		{name: "valid rule", rule: &domain.DestinationRule{Category: " pln ", Hint: " Nomor meter ", Pattern: `^\d+$`, MinLength: 11, MaxLength: 12}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeDestinationRuleRepo{}
+			uc := NewDestinationRuleUsecase(repo, &fakeCatalogUsecase{categories: map[string]bool{"PLN": true}}, nil)
+
+			err := uc.UpdateRule(tt.rule)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("UpdateRule() error = %v, want %q", err, tt.wantErr)
+				}
+				if repo.upserted != nil {
+					t.Errorf("UpdateRule() stored a rejected rule")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateRule() unexpected error: %v", err)
+			}
+			if repo.upserted == nil || repo.upserted.Category != "PLN" || repo.upserted.Hint != "Nomor meter" {
+				t.Errorf("UpdateRule() stored %+v, want trimmed PLN rule", repo.upserted)
+			}
+		})
+	}
+}
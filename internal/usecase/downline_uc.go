@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+type downlineUsecase struct {
+	downlineRepo domain.DownlineRepository
+	userRepo     domain.UserRepository
+	config       DownlineConfig
+	location     *time.Location
+}
+
+// DownlineConfig defines the limits of downline tree and stats queries
+type DownlineConfig struct {
+	// DefaultDepth is used when a request sets no depth
+	DefaultDepth int
+	// MaxDepth caps the requested depth, and the walk checking that a user
+	// belongs to the caller's network
+	MaxDepth int
+	// MaxStatsDays caps the period of a stats request
+	MaxStatsDays int
+	// Timezone of the calendar dates of stats periods
+	Timezone string
+}
+
+// DefaultDownlineConfig returns default downline configuration
+func DefaultDownlineConfig() DownlineConfig {
+	return DownlineConfig{
+		DefaultDepth: 3,
+		MaxDepth:     10,
+		MaxStatsDays: 366,
+		Timezone:     "Asia/Jakarta",
+	}
+}
+
+// NewDownlineUsecase creates a new downline use case
+func NewDownlineUsecase(downlineRepo domain.DownlineRepository, userRepo domain.UserRepository, config DownlineConfig) domain.DownlineUsecase {
+	defaults := DefaultDownlineConfig()
+	if config.MaxDepth <= 0 {
+		config.MaxDepth = defaults.MaxDepth
+	}
+	if config.DefaultDepth <= 0 || config.DefaultDepth > config.MaxDepth {
+		config.DefaultDepth = min(defaults.DefaultDepth, config.MaxDepth)
+	}
+	if config.MaxStatsDays <= 0 {
+		config.MaxStatsDays = defaults.MaxStatsDays
+	}
+	if config.Timezone == "" {
+		config.Timezone = defaults.Timezone
+	}
+
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		logger.Warn("Invalid downline timezone, falling back to UTC",
+			logger.String("timezone", config.Timezone),
+			logger.ErrorField(err),
+		)
+		config.Timezone = "UTC"
+		location = time.UTC
+	}
+
+	return &downlineUsecase{
+		downlineRepo: downlineRepo,
+		userRepo:     userRepo,
+		config:       config,
+		location:     location,
+	}
+}
+
+// GetTree returns one page of the downline tree of userID
+func (uc *downlineUsecase) GetTree(actorID string, actorLevel int, userID string, depth, page, limit int) ([]*domain.DownlineNode, int, error) {
+	userID, depth, err := uc.prepare(actorID, actorLevel, userID, depth)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return uc.downlineRepo.ListTree(userID, depth, limit, (page-1)*limit)
+}
+
+// GetStats aggregates the downline network of userID over a date range
+func (uc *downlineUsecase) GetStats(actorID string, actorLevel int, userID string, depth int, from, to time.Time) (*domain.DownlineStats, error) {
+	userID, depth, err := uc.prepare(actorID, actorLevel, userID, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end, err := uc.period(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	byLevel, err := uc.downlineRepo.GetLevelStats(userID, depth, start, end)
+	if err != nil {
+		return nil, err
+	}
+	commissions, err := uc.downlineRepo.GetCommissionTotal(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &domain.DownlineStats{
+		UserID:      userID,
+		MaxDepth:    depth,
+		From:        start,
+		To:          end,
+		Commissions: commissions,
+		ByLevel:     byLevel,
+	}
+	if stats.ByLevel == nil {
+		stats.ByLevel = []*domain.DownlineLevelStats{}
+	}
+	for _, level := range stats.ByLevel {
+		level.Role = domain.MapLevelToRole(level.Level)
+		stats.TotalDownlines += level.Users
+		stats.ActiveDownlines += level.ActiveUsers
+		stats.Transactions += level.Transactions
+		stats.Volume += level.Volume
+	}
+
+	return stats, nil
+}
+
+// prepare defaults the user to the actor, checks the actor may see the user's
+// network and clamps the depth
+func (uc *downlineUsecase) prepare(actorID string, actorLevel int, userID string, depth int) (string, int, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		userID = actorID
+	}
+
+	if depth < 0 {
+		return "", 0, fmt.Errorf("depth must not be negative")
+	}
+	if depth == 0 {
+		depth = uc.config.DefaultDepth
+	}
+	if depth > uc.config.MaxDepth {
+		return "", 0, fmt.Errorf("depth must not exceed %d", uc.config.MaxDepth)
+	}
+
+	if _, err := uc.userRepo.GetByID(userID); err != nil {
+		return "", 0, fmt.Errorf("user not found")
+	}
+
+	if actorLevel == domain.LevelAdmin || userID == actorID {
+		return userID, depth, nil
+	}
+	inNetwork, err := uc.downlineRepo.IsDownline(actorID, userID, uc.config.MaxDepth)
+	if err != nil {
+		return "", 0, err
+	}
+	if !inNetwork {
+		return "", 0, fmt.Errorf("user is not in your downline")
+	}
+
+	return userID, depth, nil
+}
+
+// period turns the calendar dates from and to (inclusive) into a half-open
+// range in the configured timezone, defaulting to the current month
+func (uc *downlineUsecase) period(from, to time.Time) (time.Time, time.Time, error) {
+	now := time.Now().In(uc.location)
+	if from.IsZero() {
+		from = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, uc.location)
+	}
+	if to.IsZero() {
+		to = now
+	}
+
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, uc.location)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, uc.location).AddDate(0, 0, 1)
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end date must not be before start date")
+	}
+	if end.Sub(start) > time.Duration(uc.config.MaxStatsDays)*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("stats range too large")
+	}
+
+	return start, end, nil
+}
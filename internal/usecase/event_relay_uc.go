@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+type eventRelayUsecase struct {
+	eventRepo  domain.EventRepository
+	publishers []domain.EventPublisher
+	config     EventRelayConfig
+}
+
+// EventRelayConfig defines how the outbox relay claims and retries events
+type EventRelayConfig struct {
+	BatchSize    int
+	MaxAttempts  int
+	Lease        time.Duration // How long a claimed event is hidden from other relays
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+	PublishLimit time.Duration // Timeout for publishing a single event
+}
+
+// DefaultEventRelayConfig returns default outbox relay configuration
+func DefaultEventRelayConfig() EventRelayConfig {
+	return EventRelayConfig{
+		BatchSize:    100,
+		MaxAttempts:  10,
+		Lease:        time.Minute,
+		BaseDelay:    5 * time.Second,
+		MaxDelay:     30 * time.Minute,
+		PublishLimit: 15 * time.Second,
+	}
+}
+
+// NewEventRelayUsecase creates a new outbox relay use case
+func NewEventRelayUsecase(
+	eventRepo domain.EventRepository,
+	publishers []domain.EventPublisher,
+	config EventRelayConfig,
+) domain.EventRelayUsecase {
+	defaults := DefaultEventRelayConfig()
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.Lease <= 0 {
+		config.Lease = defaults.Lease
+	}
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = defaults.BaseDelay
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = defaults.MaxDelay
+	}
+	if config.PublishLimit <= 0 {
+		config.PublishLimit = defaults.PublishLimit
+	}
+
+	return &eventRelayUsecase{
+		eventRepo:  eventRepo,
+		publishers: publishers,
+		config:     config,
+	}
+}
+
+// RelayPendingEvents publishes a batch of pending outbox events to every
+// publisher and returns the number of events published. Delivery is
+// at-least-once: when one publisher fails the whole event is retried.
+func (uc *eventRelayUsecase) RelayPendingEvents(ctx context.Context) (int, error) {
+	if len(uc.publishers) == 0 {
+		return 0, nil
+	}
+
+	events, err := uc.eventRepo.ClaimPending(uc.config.BatchSize, uc.config.Lease)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim pending events: %w", err)
+	}
+
+	published := 0
+	for _, event := range events {
+		if ctx.Err() != nil {
+			break
+		}
+
+		if err := uc.publish(ctx, event); err != nil {
+			uc.handlePublishFailure(event, err)
+			continue
+		}
+
+		if err := uc.eventRepo.MarkPublished(event.ID); err != nil {
+			logger.Error("Failed to mark event published",
+				logger.String("event_id", event.ID),
+				logger.ErrorField(err),
+			)
+			continue
+		}
+		published++
+	}
+
+	if len(events) > 0 {
+		logger.Debug("Outbox events relayed",
+			logger.Int("claimed", len(events)),
+			logger.Int("published", published),
+		)
+	}
+
+	return published, nil
+}
+
+func (uc *eventRelayUsecase) publish(ctx context.Context, event *domain.DomainEvent) error {
+	failures := make([]string, 0)
+	for _, publisher := range uc.publishers {
+		publishCtx, cancel := context.WithTimeout(ctx, uc.config.PublishLimit)
+		err := publisher.Publish(publishCtx, event)
+		cancel()
+
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", publisher.Name(), err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+func (uc *eventRelayUsecase) handlePublishFailure(event *domain.DomainEvent, publishErr error) {
+	attempts := event.Attempts + 1
+
+	if attempts >= uc.config.MaxAttempts {
+		logger.Error("Outbox event failed permanently",
+			logger.String("event_id", event.ID),
+			logger.String("event_type", event.EventType),
+			logger.Int("attempts", attempts),
+			logger.ErrorField(publishErr),
+		)
+		if err := uc.eventRepo.MarkFailed(event.ID, publishErr.Error()); err != nil {
+			logger.Error("Failed to mark event failed", logger.String("event_id", event.ID), logger.ErrorField(err))
+		}
+		return
+	}
+
+	nextAttemptAt := time.Now().Add(uc.calculateDelay(attempts))
+	logger.Warn("Outbox event publish failed, scheduling retry",
+		logger.String("event_id", event.ID),
+		logger.String("event_type", event.EventType),
+		logger.Int("attempts", attempts),
+		logger.String("next_attempt_at", nextAttemptAt.Format(time.RFC3339)),
+		logger.ErrorField(publishErr),
+	)
+	if err := uc.eventRepo.ScheduleRetry(event.ID, publishErr.Error(), nextAttemptAt); err != nil {
+		logger.Error("Failed to schedule event retry", logger.String("event_id", event.ID), logger.ErrorField(err))
+	}
+}
+
+// calculateDelay returns exponential backoff delay for the given attempt
+func (uc *eventRelayUsecase) calculateDelay(attempt int) time.Duration {
+	delay := uc.config.BaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= uc.config.MaxDelay {
+			return uc.config.MaxDelay
+		}
+	}
+	return delay
+}
@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+type favoriteUsecase struct {
+	favoriteRepo  domain.FavoriteRepository
+	productRepo   domain.ProductRepository
+	destinationUC domain.DestinationRuleUsecase
+	transactionUC domain.TransactionUsecase
+}
+
+// NewFavoriteUsecase creates a new favorite use case
+func NewFavoriteUsecase(
+	favoriteRepo domain.FavoriteRepository,
+	productRepo domain.ProductRepository,
+	destinationUC domain.DestinationRuleUsecase,
+	transactionUC domain.TransactionUsecase,
+) domain.FavoriteUsecase {
+	return &favoriteUsecase{
+		favoriteRepo:  favoriteRepo,
+		productRepo:   productRepo,
+		destinationUC: destinationUC,
+		transactionUC: transactionUC,
+	}
+}
+
+// CreateFavorite saves a product, and optionally a destination, for a user.
+// The destination is validated against the product rules when it is saved.
+func (uc *favoriteUsecase) CreateFavorite(userID, productCode string, destination, alias *string) (*domain.Favorite, error) {
+	product, err := uc.productRepo.GetByCode(strings.TrimSpace(productCode))
+	if err != nil {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	count, err := uc.favoriteRepo.CountByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= domain.MaxFavoritesPerUser {
+		return nil, fmt.Errorf("favorite limit of %d reached", domain.MaxFavoritesPerUser)
+	}
+
+	favorite := &domain.Favorite{
+		ID:          utils.GenerateUUID(),
+		UserID:      userID,
+		ProductID:   product.ID,
+		ProductCode: product.Code,
+		ProductName: product.Name,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if err := uc.applyFields(favorite, product, destination, alias); err != nil {
+		return nil, err
+	}
+
+	if err := uc.favoriteRepo.Create(favorite); err != nil {
+		return nil, err
+	}
+
+	return favorite, nil
+}
+
+// ListFavorites lists the favorites of a user
+func (uc *favoriteUsecase) ListFavorites(userID string) ([]*domain.Favorite, error) {
+	return uc.favoriteRepo.ListByUser(userID)
+}
+
+// UpdateFavorite changes the destination or alias of a favorite; an empty
+// value clears the field
+func (uc *favoriteUsecase) UpdateFavorite(userID, id string, destination, alias *string) (*domain.Favorite, error) {
+	favorite, err := uc.getOwned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := uc.productRepo.GetByID(favorite.ProductID)
+	if err != nil {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	if err := uc.applyFields(favorite, product, destination, alias); err != nil {
+		return nil, err
+	}
+	favorite.UpdatedAt = time.Now()
+
+	if err := uc.favoriteRepo.Update(favorite); err != nil {
+		return nil, err
+	}
+
+	return favorite, nil
+}
+
+// DeleteFavorite removes a favorite of a user
+func (uc *favoriteUsecase) DeleteFavorite(userID, id string) error {
+	return uc.favoriteRepo.Delete(id, userID)
+}
+
+// QuickOrder expands a favorite into a normal transaction
+func (uc *favoriteUsecase) QuickOrder(ctx context.Context, userID, id string, destination *string, channel string) (*domain.Transaction, error) {
+	favorite, err := uc.getOwned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	target := favorite.DestinationNumber
+	if destination != nil && strings.TrimSpace(*destination) != "" {
+		target = destination
+	}
+	if target == nil {
+		return nil, fmt.Errorf("destination number is required")
+	}
+
+	return uc.transactionUC.CreateTransaction(ctx, userID, favorite.ProductCode, *target, channel)
+}
+
+// getOwned returns a favorite only when it belongs to the user
+func (uc *favoriteUsecase) getOwned(userID, id string) (*domain.Favorite, error) {
+	favorite, err := uc.favoriteRepo.GetByID(id)
+	if err != nil {
+		if err.Error() != "favorite not found" {
+			logger.Error("Failed to get favorite", logger.String("favorite_id", id), logger.ErrorField(err))
+		}
+		return nil, fmt.Errorf("favorite not found")
+	}
+	if favorite.UserID != userID {
+		return nil, fmt.Errorf("favorite not found")
+	}
+
+	return favorite, nil
+}
+
+// applyFields validates and sets the optional destination and alias
+func (uc *favoriteUsecase) applyFields(favorite *domain.Favorite, product *domain.Product, destination, alias *string) error {
+	if destination != nil {
+		favorite.DestinationNumber = nil
+		if strings.TrimSpace(*destination) != "" {
+			normalized, err := uc.destinationUC.ValidateDestination(product, *destination)
+			if err != nil {
+				return err
+			}
+			favorite.DestinationNumber = &normalized
+		}
+	}
+
+	if alias != nil {
+		favorite.Alias = nil
+		if trimmed := strings.TrimSpace(*alias); trimmed != "" {
+			if len(trimmed) > 100 {
+				return fmt.Errorf("alias must be at most 100 characters")
+			}
+			favorite.Alias = &trimmed
+		}
+	}
+
+	return nil
+}
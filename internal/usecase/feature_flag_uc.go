@@ -0,0 +1,273 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+type featureFlagUsecase struct {
+	flagRepo  domain.FeatureFlagRepository
+	flagCache domain.FeatureFlagCacheRepository
+	config    FeatureFlagConfig
+
+	mu        sync.Mutex
+	flags     map[string]*domain.FeatureFlag
+	expiresAt time.Time
+}
+
+// FeatureFlagConfig defines where flags are evaluated and how they are cached
+type FeatureFlagConfig struct {
+	// Environment is the environment whose flags this process evaluates
+	Environment string
+	// Defaults is the state of flags not stored for the environment;
+	// unlisted flags default to off
+	Defaults map[string]bool
+	// CacheTTL bounds how long a replica keeps evaluating flags changed
+	// elsewhere
+	CacheTTL time.Duration
+	// SharedCacheTTL is how long the flags stay in the shared Redis cache
+	SharedCacheTTL time.Duration
+}
+
+// DefaultFeatureFlagConfig returns default feature flag configuration
+func DefaultFeatureFlagConfig() FeatureFlagConfig {
+	return FeatureFlagConfig{
+		Environment:    "development",
+		CacheTTL:       10 * time.Second,
+		SharedCacheTTL: 5 * time.Minute,
+	}
+}
+
+// NewFeatureFlagUsecase creates a new feature flag use case. Without a cache
+// repository the flags are loaded from the database.
+func NewFeatureFlagUsecase(
+	flagRepo domain.FeatureFlagRepository,
+	flagCache domain.FeatureFlagCacheRepository,
+	config FeatureFlagConfig,
+) domain.FeatureFlagUsecase {
+	defaults := DefaultFeatureFlagConfig()
+	if strings.TrimSpace(config.Environment) == "" {
+		config.Environment = defaults.Environment
+	}
+	if config.Defaults == nil {
+		config.Defaults = map[string]bool{}
+	}
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = defaults.CacheTTL
+	}
+	if config.SharedCacheTTL <= 0 {
+		config.SharedCacheTTL = defaults.SharedCacheTTL
+	}
+
+	return &featureFlagUsecase{
+		flagRepo:  flagRepo,
+		flagCache: flagCache,
+		config:    config,
+	}
+}
+
+// IsEnabled reports whether the flag is on for userID in this environment.
+// When the flags cannot be loaded the flag keeps its default.
+func (uc *featureFlagUsecase) IsEnabled(key, userID string) bool {
+	evaluation, err := uc.Evaluate(key, userID)
+	if err != nil {
+		logger.Warn("Failed to evaluate feature flag, using its default",
+			logger.String("key", key),
+			logger.ErrorField(err),
+		)
+		return uc.config.Defaults[key]
+	}
+	return evaluation.Enabled
+}
+
+// Evaluate reports whether the flag is on for userID in this environment and why
+func (uc *featureFlagUsecase) Evaluate(key, userID string) (*domain.FeatureFlagEvaluation, error) {
+	if !domain.IsValidFeatureFlagKey(key) {
+		return nil, fmt.Errorf("unknown feature flag")
+	}
+
+	evaluation := &domain.FeatureFlagEvaluation{
+		Key:         key,
+		Environment: uc.config.Environment,
+		UserID:      userID,
+	}
+
+	flags, err := uc.activeFlags()
+	if err != nil {
+		return nil, err
+	}
+
+	flag, ok := flags[key]
+	if !ok {
+		evaluation.Enabled = uc.config.Defaults[key]
+		evaluation.Reason = domain.FlagReasonDefault
+		return evaluation, nil
+	}
+
+	evaluation.Stored = true
+	evaluation.Enabled, evaluation.Reason = flag.Evaluate(userID)
+	return evaluation, nil
+}
+
+// ListFlags returns every known flag of an environment. Flags not stored show
+// their default with a zero timestamp.
+func (uc *featureFlagUsecase) ListFlags(environment string) ([]*domain.FeatureFlag, error) {
+	environment = uc.environment(environment)
+
+	stored, err := uc.flagRepo.ListByEnvironment(environment)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]*domain.FeatureFlag, len(stored))
+	for _, flag := range stored {
+		byKey[flag.Key] = flag
+	}
+
+	flags := make([]*domain.FeatureFlag, 0, len(domain.FeatureFlagKeys))
+	for _, key := range domain.FeatureFlagKeys {
+		if flag, ok := byKey[key]; ok {
+			flags = append(flags, flag)
+			continue
+		}
+		flags = append(flags, &domain.FeatureFlag{
+			Key:            key,
+			Environment:    environment,
+			Enabled:        uc.config.Defaults[key],
+			RolloutPercent: 100,
+			UserIDs:        []string{},
+		})
+	}
+
+	return flags, nil
+}
+
+// SetFlag validates and stores the settings of a flag. Replicas pick up the
+// change within the cache TTL.
+func (uc *featureFlagUsecase) SetFlag(flag *domain.FeatureFlag) (*domain.FeatureFlag, error) {
+	if flag == nil {
+		return nil, fmt.Errorf("feature flag payload is required")
+	}
+
+	flag.Key = strings.TrimSpace(flag.Key)
+	flag.Environment = uc.environment(flag.Environment)
+	flag.Description = strings.TrimSpace(flag.Description)
+
+	userIDs := make([]string, 0, len(flag.UserIDs))
+	seen := make(map[string]bool, len(flag.UserIDs))
+	for _, userID := range flag.UserIDs {
+		userID = strings.TrimSpace(userID)
+		if userID == "" || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		userIDs = append(userIDs, userID)
+	}
+	flag.UserIDs = userIDs
+
+	if err := flag.Check(); err != nil {
+		return nil, err
+	}
+
+	if err := uc.flagRepo.Upsert(flag); err != nil {
+		return nil, err
+	}
+	uc.invalidate(flag.Environment)
+	return flag, nil
+}
+
+// DeleteFlag removes the flag of an environment, returning it to its default
+func (uc *featureFlagUsecase) DeleteFlag(key, environment string) error {
+	environment = uc.environment(environment)
+	if err := uc.flagRepo.Delete(key, environment); err != nil {
+		return err
+	}
+	uc.invalidate(environment)
+	return nil
+}
+
+// environment defaults an empty environment to the one of this process
+func (uc *featureFlagUsecase) environment(environment string) string {
+	environment = strings.TrimSpace(environment)
+	if environment == "" {
+		return uc.config.Environment
+	}
+	return environment
+}
+
+// activeFlags returns the flags of this environment by key, refreshed at most
+// every CacheTTL from the shared cache or, on a miss, from the database
+func (uc *featureFlagUsecase) activeFlags() (map[string]*domain.FeatureFlag, error) {
+	now := time.Now()
+
+	uc.mu.Lock()
+	if now.Before(uc.expiresAt) {
+		flags := uc.flags
+		uc.mu.Unlock()
+		return flags, nil
+	}
+	uc.mu.Unlock()
+
+	list, err := uc.loadFlags()
+	if err != nil {
+		return nil, err
+	}
+	flags := make(map[string]*domain.FeatureFlag, len(list))
+	for _, flag := range list {
+		flags[flag.Key] = flag
+	}
+
+	uc.mu.Lock()
+	uc.flags = flags
+	uc.expiresAt = now.Add(uc.config.CacheTTL)
+	uc.mu.Unlock()
+
+	return flags, nil
+}
+
+func (uc *featureFlagUsecase) loadFlags() ([]*domain.FeatureFlag, error) {
+	environment := uc.config.Environment
+	if uc.flagCache != nil {
+		flags, err := uc.flagCache.GetFlags(environment)
+		if err != nil {
+			logger.Warn("Failed to read cached feature flags", logger.ErrorField(err))
+		} else if flags != nil {
+			return flags, nil
+		}
+	}
+
+	flags, err := uc.flagRepo.ListByEnvironment(environment)
+	if err != nil {
+		return nil, err
+	}
+
+	if uc.flagCache != nil {
+		if err := uc.flagCache.SetFlags(environment, flags, uc.config.SharedCacheTTL); err != nil {
+			logger.Warn("Failed to cache feature flags", logger.ErrorField(err))
+		}
+	}
+	return flags, nil
+}
+
+// invalidate drops the cached flags of an environment, locally when it is
+// the environment of this process
+func (uc *featureFlagUsecase) invalidate(environment string) {
+	if uc.flagCache != nil {
+		if err := uc.flagCache.InvalidateFlags(environment); err != nil {
+			logger.Warn("Failed to invalidate cached feature flags",
+				logger.String("environment", environment),
+				logger.ErrorField(err),
+			)
+		}
+	}
+
+	if environment == uc.config.Environment {
+		uc.mu.Lock()
+		uc.expiresAt = time.Time{}
+		uc.mu.Unlock()
+	}
+}
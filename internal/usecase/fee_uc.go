@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+type feeUsecase struct {
+	feeRuleRepo domain.FeeRuleRepository
+	catalogUC   domain.CatalogUsecase
+}
+
+// NewFeeUsecase creates a new admin fee use case
+func NewFeeUsecase(feeRuleRepo domain.FeeRuleRepository, catalogUC domain.CatalogUsecase) domain.FeeUsecase {
+	return &feeUsecase{feeRuleRepo: feeRuleRepo, catalogUC: catalogUC}
+}
+
+// CreateRule validates and stores a new fee rule, active or not as requested
+func (uc *feeUsecase) CreateRule(rule *domain.FeeRule) error {
+	if rule == nil {
+		return fmt.Errorf("fee rule payload is required")
+	}
+
+	if err := uc.normalizeRule(rule); err != nil {
+		return err
+	}
+
+	rule.ID = utils.GenerateUUID()
+	rule.CreatedAt = time.Now()
+	rule.UpdatedAt = time.Now()
+
+	return uc.feeRuleRepo.Create(rule)
+}
+
+// UpdateRule validates and replaces the mutable fields of a fee rule
+func (uc *feeUsecase) UpdateRule(rule *domain.FeeRule) (*domain.FeeRule, error) {
+	if rule == nil {
+		return nil, fmt.Errorf("fee rule payload is required")
+	}
+
+	existing, err := uc.feeRuleRepo.GetByID(rule.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.normalizeRule(rule); err != nil {
+		return nil, err
+	}
+
+	rule.CreatedBy = existing.CreatedBy
+	rule.CreatedAt = existing.CreatedAt
+	rule.UpdatedAt = time.Now()
+
+	if err := uc.feeRuleRepo.Update(rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// DeleteRule removes a fee rule
+func (uc *feeUsecase) DeleteRule(id string) error {
+	return uc.feeRuleRepo.Delete(id)
+}
+
+// GetRule returns a fee rule by ID
+func (uc *feeUsecase) GetRule(id string) (*domain.FeeRule, error) {
+	return uc.feeRuleRepo.GetByID(id)
+}
+
+// ListRules lists all fee rules
+func (uc *feeUsecase) ListRules() ([]*domain.FeeRule, error) {
+	return uc.feeRuleRepo.List(false)
+}
+
+// CalculateFee resolves the admin fee for a product category and user level.
+// A zero fee is returned when no active rule matches.
+func (uc *feeUsecase) CalculateFee(category string, userLevel int, sellingPrice float64) (*domain.FeeQuote, error) {
+	rules, err := uc.feeRuleRepo.List(true)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &domain.FeeQuote{
+		SellingPrice: sellingPrice,
+		Total:        sellingPrice,
+	}
+
+	rule := domain.SelectFeeRule(rules, strings.ToUpper(category), userLevel)
+	if rule == nil {
+		return quote, nil
+	}
+
+	quote.AdminFee = rule.Calculate(sellingPrice)
+	quote.Total = sellingPrice + quote.AdminFee
+	quote.Rule = rule
+
+	return quote, nil
+}
+
+// normalizeRule upper-cases enum fields and validates a fee rule
+func (uc *feeUsecase) normalizeRule(rule *domain.FeeRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	rule.FeeType = strings.ToUpper(strings.TrimSpace(rule.FeeType))
+
+	if rule.Name == "" {
+		return fmt.Errorf("fee rule name is required")
+	}
+	if !domain.IsValidFeeType(rule.FeeType) {
+		return fmt.Errorf("invalid fee type")
+	}
+	if rule.Amount < 0 {
+		return fmt.Errorf("fee amount must not be negative")
+	}
+	if rule.FeeType == domain.FeeTypePercentage && rule.Amount > 100 {
+		return fmt.Errorf("percentage fee must not exceed 100")
+	}
+
+	if rule.Category != nil {
+		category := strings.ToUpper(strings.TrimSpace(*rule.Category))
+		if category == "" {
+			rule.Category = nil
+		} else if err := uc.catalogUC.ValidateCategory(category); err != nil {
+			return err
+		} else {
+			rule.Category = &category
+		}
+	}
+
+	if rule.UserLevel != nil && !domain.IsValidLevel(*rule.UserLevel) {
+		return fmt.Errorf("invalid user level")
+	}
+
+	if rule.MinFee != nil && *rule.MinFee < 0 {
+		return fmt.Errorf("min fee must not be negative")
+	}
+	if rule.MaxFee != nil && *rule.MaxFee < 0 {
+		return fmt.Errorf("max fee must not be negative")
+	}
+	if rule.MinFee != nil && rule.MaxFee != nil && *rule.MinFee > *rule.MaxFee {
+		return fmt.Errorf("min fee must not exceed max fee")
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+// maxImpersonationReason caps the reason recorded on a session, in characters
+const maxImpersonationReason = 500
+
+// ImpersonationConfig configures admin impersonation
+type ImpersonationConfig struct {
+	TTL time.Duration // Lifetime of an impersonation token
+}
+
+// DefaultImpersonationConfig returns the default impersonation configuration
+func DefaultImpersonationConfig() ImpersonationConfig {
+	return ImpersonationConfig{
+		TTL: 15 * time.Minute,
+	}
+}
+
+type impersonationUsecase struct {
+	impersonationRepo domain.ImpersonationRepository
+	userRepo          domain.UserRepository
+	authService       domain.AuthService
+	config            ImpersonationConfig
+}
+
+// NewImpersonationUsecase creates a new impersonation use case
+func NewImpersonationUsecase(
+	impersonationRepo domain.ImpersonationRepository,
+	userRepo domain.UserRepository,
+	authService domain.AuthService,
+	config ImpersonationConfig,
+) domain.ImpersonationUsecase {
+	if config.TTL <= 0 {
+		config.TTL = DefaultImpersonationConfig().TTL
+	}
+
+	return &impersonationUsecase{
+		impersonationRepo: impersonationRepo,
+		userRepo:          userRepo,
+		authService:       authService,
+		config:            config,
+	}
+}
+
+// Start opens an impersonation session and issues its token. Admins cannot
+// be impersonated, so a session never grants more than a reseller account.
+func (uc *impersonationUsecase) Start(adminID, userID, reason, ipAddress string) (*domain.IssuedImpersonation, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("reason is required")
+	}
+	if utf8.RuneCountInString(reason) > maxImpersonationReason {
+		return nil, fmt.Errorf("reason is too long")
+	}
+	if adminID == userID {
+		return nil, fmt.Errorf("cannot impersonate yourself")
+	}
+
+	user, err := uc.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Level == domain.LevelAdmin {
+		return nil, fmt.Errorf("admins cannot be impersonated")
+	}
+	if !user.IsActive {
+		return nil, fmt.Errorf("user is not active")
+	}
+
+	now := time.Now()
+	session := &domain.ImpersonationSession{
+		ID:        utils.GenerateUUID(),
+		AdminID:   adminID,
+		UserID:    userID,
+		Reason:    reason,
+		IPAddress: ipAddress,
+		ExpiresAt: now.Add(uc.config.TTL),
+		CreatedAt: now,
+	}
+	if err := uc.impersonationRepo.CreateSession(session); err != nil {
+		return nil, err
+	}
+
+	token, err := uc.authService.GenerateImpersonationToken(user, session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate impersonation token: %w", err)
+	}
+
+	logger.Component(logger.ComponentAuth).Warn("Admin impersonation started",
+		logger.String("session_id", session.ID),
+		logger.String("admin_id", adminID),
+		logger.String("user_id", userID),
+		logger.String("reason", reason),
+		logger.String("ip", ipAddress),
+	)
+
+	return &domain.IssuedImpersonation{Session: session, Token: token}, nil
+}
+
+// End revokes a session's token and closes the session. The token is revoked
+// first, so a failed revocation leaves the session open to end again rather
+// than recorded as ended with its token still accepted.
+func (uc *impersonationUsecase) End(sessionID, adminID string) (*domain.ImpersonationSession, error) {
+	session, err := uc.impersonationRepo.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.authService.RevokeImpersonation(session); err != nil {
+		return nil, fmt.Errorf("failed to revoke impersonation token: %w", err)
+	}
+	if err := uc.impersonationRepo.EndSession(sessionID, adminID); err != nil {
+		return nil, err
+	}
+
+	logger.Component(logger.ComponentAuth).Info("Admin impersonation ended",
+		logger.String("session_id", sessionID),
+		logger.String("ended_by", adminID),
+	)
+
+	return uc.impersonationRepo.GetSession(sessionID)
+}
+
+// GetSession retrieves an impersonation session
+func (uc *impersonationUsecase) GetSession(id string) (*domain.ImpersonationSession, error) {
+	return uc.impersonationRepo.GetSession(id)
+}
+
+// ListSessions lists impersonation sessions with the total count
+func (uc *impersonationUsecase) ListSessions(filter *domain.ImpersonationSessionFilter) ([]*domain.ImpersonationSession, int, error) {
+	return uc.impersonationRepo.ListSessions(filter)
+}
+
+// ListActions lists the requests made during a session, oldest first
+func (uc *impersonationUsecase) ListActions(sessionID string) ([]*domain.ImpersonationAction, error) {
+	if _, err := uc.impersonationRepo.GetSession(sessionID); err != nil {
+		return nil, err
+	}
+	return uc.impersonationRepo.ListActions(sessionID)
+}
+
+// RecordAction stores one request made with an impersonation token
+func (uc *impersonationUsecase) RecordAction(action *domain.ImpersonationAction) error {
+	if action.ID == "" {
+		action.ID = utils.GenerateUUID()
+	}
+	if action.CreatedAt.IsZero() {
+		action.CreatedAt = time.Now()
+	}
+	return uc.impersonationRepo.RecordAction(action)
+}
@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+type loginThrottleUsecase struct {
+	attemptRepo domain.LoginAttemptRepository
+	config      LoginThrottleConfig
+}
+
+// LoginThrottleConfig defines failed-login thresholds and lockout windows
+type LoginThrottleConfig struct {
+	MaxFailures    int           // Failures per username before lockout
+	IPMaxFailures  int           // Failures per IP before lockout
+	FailureWindow  time.Duration // Window in which failures are counted
+	BaseLockout    time.Duration // First lockout duration, doubled on every repeated lockout
+	MaxLockout     time.Duration // Upper bound of the lockout duration
+	LockHistoryTTL time.Duration // How long repeated lockouts are remembered for escalation
+}
+
+// DefaultLoginThrottleConfig returns default login throttle configuration
+func DefaultLoginThrottleConfig() LoginThrottleConfig {
+	return LoginThrottleConfig{
+		MaxFailures:    5,
+		IPMaxFailures:  20,
+		FailureWindow:  15 * time.Minute,
+		BaseLockout:    time.Minute,
+		MaxLockout:     24 * time.Hour,
+		LockHistoryTTL: 24 * time.Hour,
+	}
+}
+
+// NewLoginThrottleUsecase creates a new login throttle use case
+func NewLoginThrottleUsecase(attemptRepo domain.LoginAttemptRepository, config LoginThrottleConfig) domain.LoginThrottleUsecase {
+	defaults := DefaultLoginThrottleConfig()
+	if config.MaxFailures <= 0 {
+		config.MaxFailures = defaults.MaxFailures
+	}
+	if config.IPMaxFailures <= 0 {
+		config.IPMaxFailures = defaults.IPMaxFailures
+	}
+	if config.FailureWindow <= 0 {
+		config.FailureWindow = defaults.FailureWindow
+	}
+	if config.BaseLockout <= 0 {
+		config.BaseLockout = defaults.BaseLockout
+	}
+	if config.MaxLockout < config.BaseLockout {
+		config.MaxLockout = defaults.MaxLockout
+	}
+	if config.LockHistoryTTL <= 0 {
+		config.LockHistoryTTL = defaults.LockHistoryTTL
+	}
+
+	return &loginThrottleUsecase{
+		attemptRepo: attemptRepo,
+		config:      config,
+	}
+}
+
+// CheckLocked returns the active lock for the username or the IP
+func (uc *loginThrottleUsecase) CheckLocked(username, ip string) (*domain.LoginLock, error) {
+	for _, subject := range loginSubjects(username, ip) {
+		ttl, err := uc.attemptRepo.GetLockTTL(subject.SubjectType, subject.Subject)
+		if err != nil {
+			return nil, err
+		}
+		if ttl > 0 {
+			subject.RetryAfter = ttl
+			return subject, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// RegisterFailure counts a failed login for both the username and the IP and
+// locks whichever subject crossed its threshold
+func (uc *loginThrottleUsecase) RegisterFailure(username, ip string) (*domain.LoginLock, error) {
+	var applied *domain.LoginLock
+
+	for _, subject := range loginSubjects(username, ip) {
+		maxFailures := uc.config.MaxFailures
+		if subject.SubjectType == domain.LoginSubjectIP {
+			maxFailures = uc.config.IPMaxFailures
+		}
+
+		failures, err := uc.attemptRepo.IncrementFailures(subject.SubjectType, subject.Subject, uc.config.FailureWindow)
+		if err != nil {
+			return nil, err
+		}
+		if failures < maxFailures {
+			continue
+		}
+
+		previousLocks, err := uc.attemptRepo.LockCount(subject.SubjectType, subject.Subject)
+		if err != nil {
+			return nil, err
+		}
+
+		duration := uc.lockoutDuration(previousLocks + 1)
+		if _, err := uc.attemptRepo.Lock(subject.SubjectType, subject.Subject, duration, uc.config.LockHistoryTTL); err != nil {
+			return nil, err
+		}
+
+		logger.Warn("Login locked after repeated failures",
+			logger.String("subject_type", subject.SubjectType),
+			logger.String("subject", subject.Subject),
+			logger.Int("failures", failures),
+			logger.Int("lock_count", previousLocks+1),
+			logger.Duration("lock_duration", duration),
+		)
+
+		if applied == nil || duration > applied.RetryAfter {
+			subject.RetryAfter = duration
+			applied = subject
+		}
+	}
+
+	return applied, nil
+}
+
+// RegisterSuccess clears the username failure counter. The IP counter is kept so
+// a valid account cannot be used to reset throttling of a guessing client.
+func (uc *loginThrottleUsecase) RegisterSuccess(username, ip string) error {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return nil
+	}
+	return uc.attemptRepo.ResetFailures(domain.LoginSubjectUser, username)
+}
+
+// Unlock clears lockout state of the username and/or IP
+func (uc *loginThrottleUsecase) Unlock(username, ip string) error {
+	subjects := loginSubjects(username, ip)
+	if len(subjects) == 0 {
+		return fmt.Errorf("username or ip is required")
+	}
+
+	for _, subject := range subjects {
+		if err := uc.attemptRepo.Unlock(subject.SubjectType, subject.Subject); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lockoutDuration doubles the base lockout for every repeated lockout, capped at MaxLockout
+func (uc *loginThrottleUsecase) lockoutDuration(lockCount int) time.Duration {
+	duration := uc.config.BaseLockout
+	for i := 1; i < lockCount; i++ {
+		duration *= 2
+		if duration >= uc.config.MaxLockout {
+			return uc.config.MaxLockout
+		}
+	}
+	return duration
+}
+
+func loginSubjects(username, ip string) []*domain.LoginLock {
+	subjects := make([]*domain.LoginLock, 0, 2)
+	if username = strings.ToLower(strings.TrimSpace(username)); username != "" {
+		subjects = append(subjects, &domain.LoginLock{SubjectType: domain.LoginSubjectUser, Subject: username})
+	}
+	if ip = strings.TrimSpace(ip); ip != "" {
+		subjects = append(subjects, &domain.LoginLock{SubjectType: domain.LoginSubjectIP, Subject: ip})
+	}
+	return subjects
+}
@@ -0,0 +1,320 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+// Suggestion scoring weights and limits
+const (
+	suggestionNameWeight     = 0.5
+	suggestionNominalWeight  = 0.3
+	suggestionProviderWeight = 0.1
+	suggestionCodeWeight     = 0.1
+	suggestionMinScore       = 0.4
+	maxMappingSuggestions    = 3
+)
+
+type mappingValidationUsecase struct {
+	productRepo        domain.ProductRepository
+	productMappingRepo domain.ProductMappingRepository
+	supplierRepo       domain.SupplierRepository
+	reviewRepo         domain.MappingReviewRepository
+	adapterFactory     domain.SupplierAdapterFactory
+	routingCache       domain.RoutingCacheRepository
+}
+
+// NewMappingValidationUsecase creates a new mapping validation use case
+func NewMappingValidationUsecase(
+	productRepo domain.ProductRepository,
+	productMappingRepo domain.ProductMappingRepository,
+	supplierRepo domain.SupplierRepository,
+	reviewRepo domain.MappingReviewRepository,
+	adapterFactory domain.SupplierAdapterFactory,
+	routingCache domain.RoutingCacheRepository,
+) domain.MappingValidationUsecase {
+	return &mappingValidationUsecase{
+		productRepo:        productRepo,
+		productMappingRepo: productMappingRepo,
+		supplierRepo:       supplierRepo,
+		reviewRepo:         reviewRepo,
+		adapterFactory:     adapterFactory,
+		routingCache:       routingCache,
+	}
+}
+
+// ValidateSupplierMappings cross-references the supplier catalog with the
+// supplier's mappings, opens a review with replacement suggestions for every
+// code missing from the catalog and resolves reviews whose code reappeared
+func (uc *mappingValidationUsecase) ValidateSupplierMappings(supplierID string) (*domain.MappingValidationResult, error) {
+	supplier, err := uc.supplierRepo.GetByID(supplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	if uc.adapterFactory == nil {
+		return nil, fmt.Errorf("supplier adapter factory not configured")
+	}
+	adapter, err := uc.adapterFactory.GetSupplierAdapter(supplier)
+	if err != nil {
+		return nil, fmt.Errorf("adapter for %s not found: %w", supplier.Code, err)
+	}
+
+	catalog, err := adapter.GetProductCatalog()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s catalog: %w", supplier.Code, err)
+	}
+
+	codes := make(map[string]bool, len(catalog))
+	for _, item := range catalog {
+		codes[strings.ToUpper(item.Code)] = true
+	}
+
+	mappings, err := uc.productMappingRepo.GetBySupplierID(supplier.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get supplier mappings: %w", err)
+	}
+
+	result := &domain.MappingValidationResult{
+		SupplierID:   supplier.ID,
+		SupplierCode: supplier.Code,
+	}
+
+	for _, mapping := range mappings {
+		result.Checked++
+
+		if codes[strings.ToUpper(mapping.SupplierProductCode)] {
+			if uc.resolveOpenReview(mapping) {
+				result.Resolved++
+			}
+			continue
+		}
+
+		product, err := uc.productRepo.GetByID(mapping.ProductID)
+		if err != nil {
+			logger.Error("Failed to get product for stale mapping",
+				logger.String("mapping_id", mapping.ID),
+				logger.ErrorField(err),
+			)
+			continue
+		}
+
+		review := &domain.MappingReview{
+			ID:               utils.GenerateUUID(),
+			ProductMappingID: mapping.ID,
+			ProductID:        mapping.ProductID,
+			SupplierID:       supplier.ID,
+			StaleCode:        mapping.SupplierProductCode,
+			Suggestions:      suggestReplacements(product, mapping.SupplierProductCode, catalog),
+		}
+		if err := uc.reviewRepo.Upsert(review); err != nil {
+			continue
+		}
+		result.Stale++
+
+		logger.Warn("Stale supplier product code detected",
+			logger.String("supplier_code", supplier.Code),
+			logger.String("mapping_id", mapping.ID),
+			logger.String("stale_code", mapping.SupplierProductCode),
+			logger.Int("suggestions", len(review.Suggestions)),
+		)
+	}
+
+	logger.Info("Supplier mappings validated",
+		logger.String("supplier_code", supplier.Code),
+		logger.Int("checked", result.Checked),
+		logger.Int("stale", result.Stale),
+		logger.Int("resolved", result.Resolved),
+	)
+
+	return result, nil
+}
+
+// ValidateAllMappings validates every active supplier that has a registered adapter
+func (uc *mappingValidationUsecase) ValidateAllMappings() ([]*domain.MappingValidationResult, error) {
+	suppliers, err := uc.supplierRepo.GetActiveSuppliers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get suppliers: %w", err)
+	}
+
+	results := make([]*domain.MappingValidationResult, 0, len(suppliers))
+	for _, supplier := range suppliers {
+		if uc.adapterFactory == nil {
+			break
+		}
+		if _, err := uc.adapterFactory.GetSupplierAdapter(supplier); err != nil {
+			continue
+		}
+
+		result, err := uc.ValidateSupplierMappings(supplier.ID)
+		if err != nil {
+			logger.Error("Failed to validate supplier mappings",
+				logger.String("supplier_code", supplier.Code),
+				logger.ErrorField(err),
+			)
+			continue
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// ListReviews lists mapping reviews, optionally filtered by status
+func (uc *mappingValidationUsecase) ListReviews(status string) ([]*domain.MappingReview, error) {
+	status = strings.ToUpper(strings.TrimSpace(status))
+	if status != "" && !domain.IsValidMappingReviewStatus(status) {
+		return nil, fmt.Errorf("invalid review status")
+	}
+	return uc.reviewRepo.List(status)
+}
+
+// AcceptReview replaces the stale code of the mapping and closes the review
+func (uc *mappingValidationUsecase) AcceptReview(id, supplierProductCode string, reviewedBy *string) (*domain.MappingReview, error) {
+	supplierProductCode = strings.TrimSpace(supplierProductCode)
+	if supplierProductCode == "" {
+		return nil, fmt.Errorf("supplier_product_code is required")
+	}
+
+	review, err := uc.getOpenReview(id)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping, err := uc.productMappingRepo.GetByID(review.ProductMappingID)
+	if err != nil {
+		return nil, err
+	}
+	mapping.SupplierProductCode = supplierProductCode
+	if err := uc.productMappingRepo.Update(mapping); err != nil {
+		return nil, err
+	}
+	invalidateRoutingProducts(uc.routingCache, mapping.ProductID)
+
+	if err := uc.reviewRepo.UpdateStatus(review.ID, domain.MappingReviewAccepted, &supplierProductCode, reviewedBy); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Mapping code replaced from review",
+		logger.String("mapping_id", mapping.ID),
+		logger.String("stale_code", review.StaleCode),
+		logger.String("new_code", supplierProductCode),
+	)
+
+	return uc.reviewRepo.GetByID(review.ID)
+}
+
+// DismissReview closes a review without touching the mapping
+func (uc *mappingValidationUsecase) DismissReview(id string, reviewedBy *string) (*domain.MappingReview, error) {
+	review, err := uc.getOpenReview(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.reviewRepo.UpdateStatus(review.ID, domain.MappingReviewDismissed, nil, reviewedBy); err != nil {
+		return nil, err
+	}
+
+	return uc.reviewRepo.GetByID(review.ID)
+}
+
+func (uc *mappingValidationUsecase) getOpenReview(id string) (*domain.MappingReview, error) {
+	review, err := uc.reviewRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status != domain.MappingReviewOpen {
+		return nil, fmt.Errorf("mapping review already closed")
+	}
+	return review, nil
+}
+
+func (uc *mappingValidationUsecase) resolveOpenReview(mapping *domain.ProductMapping) bool {
+	review, err := uc.reviewRepo.GetOpenByMappingID(mapping.ID)
+	if err != nil || review == nil {
+		return false
+	}
+
+	code := mapping.SupplierProductCode
+	if err := uc.reviewRepo.UpdateStatus(review.ID, domain.MappingReviewResolved, &code, nil); err != nil {
+		logger.Error("Failed to resolve mapping review",
+			logger.String("review_id", review.ID),
+			logger.ErrorField(err),
+		)
+		return false
+	}
+	return true
+}
+
+// suggestReplacements ranks active catalog items by how closely they match the
+// mapped product (name, nominal, provider) and the stale code
+func suggestReplacements(product *domain.Product, staleCode string, catalog []*domain.Product) []domain.MappingCandidate {
+	nominal := domain.NominalFromName(product.Name)
+	if product.Nominal != nil && *product.Nominal > 0 {
+		nominal = *product.Nominal
+	}
+
+	candidates := make([]domain.MappingCandidate, 0, maxMappingSuggestions)
+	for _, item := range catalog {
+		if !item.IsActive {
+			continue
+		}
+
+		var notes []string
+		score := suggestionNameWeight * domain.NameSimilarity(product.Name, item.Name)
+
+		if nominal > 0 && domain.NominalFromName(item.Name) == nominal {
+			score += suggestionNominalWeight
+			notes = append(notes, "nominal match")
+		}
+		if product.Provider != "" && strings.EqualFold(product.Provider, item.Provider) {
+			score += suggestionProviderWeight
+			notes = append(notes, "provider match")
+		}
+		score += suggestionCodeWeight * codePrefixSimilarity(staleCode, item.Code)
+
+		if score < suggestionMinScore {
+			continue
+		}
+
+		candidates = append(candidates, domain.MappingCandidate{
+			Code:  item.Code,
+			Name:  item.Name,
+			Price: catalogCost(item),
+			Score: utils.RoundToDecimal(score, 2),
+			Notes: notes,
+		})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	if len(candidates) > maxMappingSuggestions {
+		candidates = candidates[:maxMappingSuggestions]
+	}
+
+	return candidates
+}
+
+// codePrefixSimilarity returns the shared prefix length relative to the longer code
+func codePrefixSimilarity(a, b string) float64 {
+	a, b = strings.ToUpper(a), strings.ToUpper(b)
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	if longest == 0 {
+		return 0
+	}
+
+	shared := 0
+	for shared < len(a) && shared < len(b) && a[shared] == b[shared] {
+		shared++
+	}
+	return float64(shared) / float64(longest)
+}
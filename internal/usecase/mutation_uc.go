@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+type mutationUsecase struct {
+	mutationRepo domain.MutationRepository
+	unitOfWork   domain.UnitOfWork
+	config       MutationConfig
+	location     *time.Location
+}
+
+// MutationConfig defines mutation ledger parameters
+type MutationConfig struct {
+	// Timezone is the IANA timezone used to cut days of the daily summary
+	Timezone string
+}
+
+// DefaultMutationConfig returns default mutation configuration
+func DefaultMutationConfig() MutationConfig {
+	return MutationConfig{
+		Timezone: "Asia/Jakarta",
+	}
+}
+
+// NewMutationUsecase creates a new mutation use case
+func NewMutationUsecase(mutationRepo domain.MutationRepository, unitOfWork domain.UnitOfWork, config MutationConfig) domain.MutationUsecase {
+	if config.Timezone == "" {
+		config.Timezone = DefaultMutationConfig().Timezone
+	}
+
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		logger.Warn("Invalid mutation timezone, falling back to UTC",
+			logger.String("timezone", config.Timezone),
+			logger.ErrorField(err),
+		)
+		config.Timezone = "UTC"
+		location = time.UTC
+	}
+
+	return &mutationUsecase{
+		mutationRepo: mutationRepo,
+		unitOfWork:   unitOfWork,
+		config:       config,
+		location:     location,
+	}
+}
+
+// CreateMutation records a balance mutation moved by actor together with its
+// balance event
+func (uc *mutationUsecase) CreateMutation(userID, mutationType string, amount, balanceBefore, balanceAfter float64, description string, referenceType, referenceID *string, actor domain.Actor) error {
+	if !domain.IsValidMutationType(mutationType) {
+		return fmt.Errorf("invalid mutation type")
+	}
+
+	mutation := &domain.Mutation{
+		ID:            utils.GenerateUUID(),
+		UserID:        userID,
+		Type:          mutationType,
+		Amount:        amount,
+		BalanceBefore: balanceBefore,
+		BalanceAfter:  balanceAfter,
+		Description:   description,
+		ReferenceType: referenceType,
+		ReferenceID:   referenceID,
+		CreatedAt:     time.Now(),
+	}
+	actor.Stamp(mutation)
+
+	return uc.unitOfWork.Do(func(repos domain.TxRepositories) error {
+		if err := repos.Mutations().Create(mutation); err != nil {
+			return err
+		}
+
+		event, err := domain.NewBalanceMutatedEvent(mutation)
+		if err != nil {
+			return err
+		}
+		if err := repos.Events().Create(event); err != nil {
+			return fmt.Errorf("failed to record balance event: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetUserMutations retrieves user mutations created within period with
+// page/limit pagination
+func (uc *mutationUsecase) GetUserMutations(userID string, period domain.DateRange, page, limit int) ([]*domain.Mutation, error) {
+	offset := (page - 1) * limit
+	return uc.mutationRepo.GetByUserID(userID, period, limit, offset)
+}
+
+// GetUserMutationsByCursor retrieves user mutations created within period
+// using keyset pagination. It returns the cursor of the next page, empty when
+// there are no more rows.
+func (uc *mutationUsecase) GetUserMutationsByCursor(userID string, period domain.DateRange, cursor string, limit int) ([]*domain.Mutation, string, error) {
+	after, err := domain.DecodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	limit = domain.NormalizeCursorLimit(limit)
+
+	mutations, err := uc.mutationRepo.GetByUserIDAfter(userID, period, after, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(mutations) > limit {
+		mutations = mutations[:limit]
+		last := mutations[limit-1]
+		nextCursor = domain.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return mutations, nextCursor, nil
+}
+
+// ListUserMutations returns one page of the mutations matching filter,
+// newest first, and the total number of matches
+func (uc *mutationUsecase) ListUserMutations(filter domain.MutationFilter, page, limit int) ([]*domain.Mutation, int, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, 0, err
+	}
+
+	total, err := uc.mutationRepo.Count(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []*domain.Mutation{}, 0, nil
+	}
+
+	mutations, err := uc.mutationRepo.List(filter, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return mutations, total, nil
+}
+
+// GetDailyMutationSummary aggregates the mutations matching filter per day
+// of the configured timezone, oldest first
+func (uc *mutationUsecase) GetDailyMutationSummary(filter domain.MutationFilter) ([]*domain.MutationDailySummary, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	summaries, err := uc.mutationRepo.GetDailySummary(filter, uc.config.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	for _, summary := range summaries {
+		// Days come back as wall-clock times of the configured timezone
+		summary.Day = time.Date(summary.Day.Year(), summary.Day.Month(), summary.Day.Day(), 0, 0, 0, 0, uc.location)
+	}
+	if summaries == nil {
+		summaries = []*domain.MutationDailySummary{}
+	}
+	return summaries, nil
+}
+
+// GetBalanceHistory returns the mutations of a user within a date range, newest first
+func (uc *mutationUsecase) GetBalanceHistory(userID string, startDate, endDate time.Time) ([]*domain.Mutation, error) {
+	var (
+		history []*domain.Mutation
+		after   *domain.Cursor
+	)
+	period := domain.DateRange{From: startDate, To: endDate}
+
+	for {
+		page, err := uc.mutationRepo.GetByUserIDAfter(userID, period, after, domain.MaxCursorLimit)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, page...)
+
+		if len(page) < domain.MaxCursorLimit {
+			return history, nil
+		}
+		last := page[len(page)-1]
+		after = &domain.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
+
+// GetCurrentBalance returns the balance after the latest mutation
+func (uc *mutationUsecase) GetCurrentBalance(userID string) (float64, error) {
+	return uc.mutationRepo.GetCurrentBalance(userID)
+}
+
+// ValidateBalance checks that the ledger balance covers the required amount
+func (uc *mutationUsecase) ValidateBalance(userID string, requiredAmount float64) error {
+	balance, err := uc.mutationRepo.GetCurrentBalance(userID)
+	if err != nil {
+		return err
+	}
+	if balance < requiredAmount {
+		return fmt.Errorf("insufficient balance")
+	}
+	return nil
+}
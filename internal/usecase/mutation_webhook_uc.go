@@ -0,0 +1,435 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/metrics"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+type mutationWebhookUsecase struct {
+	webhookRepo domain.MutationWebhookRepository
+	userRepo    domain.UserRepository
+	dispatcher  domain.WebhookDispatcher
+	config      MutationWebhookConfig
+}
+
+// MutationWebhookConfig defines which mutations reach a webhook and how they
+// are batched and retried
+type MutationWebhookConfig struct {
+	// MaxDepth is how many downline levels below a user reach the user's
+	// webhook when it includes downlines
+	MaxDepth int
+	// BatchSize caps the mutations of one delivery; a busier interval is
+	// sent as several deliveries
+	BatchSize int
+	// DispatchLimit is how many deliveries one pass posts
+	DispatchLimit int
+	MaxAttempts   int
+	BaseDelay     time.Duration
+	MaxDelay      time.Duration
+	Lease         time.Duration // How long a claimed delivery is hidden from other passes
+	Timeout       time.Duration // Timeout for posting a single delivery
+	// AllowHTTP accepts webhook URLs without TLS, for local development
+	AllowHTTP bool
+	// AllowPrivateNetworks accepts webhook hosts on loopback and private
+	// addresses, for local development
+	AllowPrivateNetworks bool
+	// MaxDeliveries caps the deliveries listed per request
+	MaxDeliveries int
+}
+
+// DefaultMutationWebhookConfig returns default mutation webhook configuration
+func DefaultMutationWebhookConfig() MutationWebhookConfig {
+	return MutationWebhookConfig{
+		MaxDepth:      5,
+		BatchSize:     500,
+		DispatchLimit: 100,
+		MaxAttempts:   10,
+		BaseDelay:     time.Minute,
+		MaxDelay:      time.Hour,
+		Lease:         2 * time.Minute,
+		Timeout:       10 * time.Second,
+		MaxDeliveries: 100,
+	}
+}
+
+// mutationWebhookSecretLength is the length of generated webhook secrets
+const mutationWebhookSecretLength = 64
+
+// NewMutationWebhookUsecase creates a new mutation webhook use case
+func NewMutationWebhookUsecase(
+	webhookRepo domain.MutationWebhookRepository,
+	userRepo domain.UserRepository,
+	dispatcher domain.WebhookDispatcher,
+	config MutationWebhookConfig,
+) domain.MutationWebhookUsecase {
+	defaults := DefaultMutationWebhookConfig()
+	if config.MaxDepth <= 0 {
+		config.MaxDepth = defaults.MaxDepth
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.DispatchLimit <= 0 {
+		config.DispatchLimit = defaults.DispatchLimit
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = defaults.BaseDelay
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = defaults.MaxDelay
+	}
+	if config.Lease <= 0 {
+		config.Lease = defaults.Lease
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.MaxDeliveries <= 0 {
+		config.MaxDeliveries = defaults.MaxDeliveries
+	}
+
+	return &mutationWebhookUsecase{
+		webhookRepo: webhookRepo,
+		userRepo:    userRepo,
+		dispatcher:  dispatcher,
+		config:      config,
+	}
+}
+
+// GetWebhook returns the webhook of a user
+func (uc *mutationWebhookUsecase) GetWebhook(userID string) (*domain.MutationWebhook, error) {
+	return uc.webhookRepo.GetByUserID(userID)
+}
+
+// SetWebhook creates or updates the webhook of a user. Only users who can
+// have downlines may include them. A new webhook gets a secret, returned
+// this once; updates keep the secret.
+func (uc *mutationWebhookUsecase) SetWebhook(userID, rawURL string, includeDownlines, isActive bool) (*domain.MutationWebhookSetup, error) {
+	webhookURL, err := uc.checkURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if includeDownlines {
+		user, err := uc.userRepo.GetByID(userID)
+		if err != nil {
+			return nil, err
+		}
+		if !user.CanHaveDownlines() {
+			return nil, fmt.Errorf("user cannot have downlines")
+		}
+	}
+
+	webhook, err := uc.webhookRepo.GetByUserID(userID)
+	if err != nil && err.Error() != "mutation webhook not found" {
+		return nil, err
+	}
+
+	setup := &domain.MutationWebhookSetup{}
+	if webhook == nil {
+		webhook = &domain.MutationWebhook{
+			UserID: userID,
+			Secret: utils.GenerateRandomString(mutationWebhookSecretLength),
+		}
+		setup.Secret = webhook.Secret
+	}
+	webhook.URL = webhookURL
+	webhook.IncludeDownlines = includeDownlines
+	webhook.IsActive = isActive
+
+	if err := uc.webhookRepo.Upsert(webhook); err != nil {
+		return nil, err
+	}
+	setup.MutationWebhook = webhook
+
+	logger.Info("Mutation webhook saved",
+		logger.String("user_id", userID),
+		logger.String("url", webhook.URL),
+		logger.Bool("include_downlines", includeDownlines),
+		logger.Bool("is_active", isActive),
+	)
+
+	return setup, nil
+}
+
+// RotateSecret issues a new secret for the webhook of a user. Deliveries are
+// signed when posted, so retries of earlier deliveries use the new secret too.
+func (uc *mutationWebhookUsecase) RotateSecret(userID string) (*domain.MutationWebhookSetup, error) {
+	webhook, err := uc.webhookRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.Secret = utils.GenerateRandomString(mutationWebhookSecretLength)
+	if err := uc.webhookRepo.Upsert(webhook); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Mutation webhook secret rotated", logger.String("user_id", userID))
+
+	return &domain.MutationWebhookSetup{MutationWebhook: webhook, Secret: webhook.Secret}, nil
+}
+
+// DeleteWebhook removes the webhook of a user with its queued mutations and deliveries
+func (uc *mutationWebhookUsecase) DeleteWebhook(userID string) error {
+	return uc.webhookRepo.Delete(userID)
+}
+
+// ListDeliveries returns the latest deliveries of the user's webhook
+func (uc *mutationWebhookUsecase) ListDeliveries(userID string, limit int) ([]*domain.MutationWebhookDelivery, error) {
+	webhook, err := uc.webhookRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 || limit > uc.config.MaxDeliveries {
+		limit = uc.config.MaxDeliveries
+	}
+	return uc.webhookRepo.ListDeliveries(webhook.ID, limit)
+}
+
+// ResendDelivery queues a failed delivery of the user's webhook again with
+// fresh attempts. It keeps its ID, so receivers that did get it can drop it.
+func (uc *mutationWebhookUsecase) ResendDelivery(userID, deliveryID string) (*domain.MutationWebhookDelivery, error) {
+	delivery, err := uc.webhookRepo.GetDelivery(deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.UserID != userID {
+		return nil, fmt.Errorf("mutation webhook delivery not found")
+	}
+	if delivery.Status != domain.MutationDeliveryFailed {
+		return nil, fmt.Errorf("only failed deliveries can be resent")
+	}
+
+	if err := uc.webhookRepo.Requeue(deliveryID); err != nil {
+		return nil, err
+	}
+	return uc.webhookRepo.GetDelivery(deliveryID)
+}
+
+// EnqueueMutation queues the mutation of a balance mutated event for the
+// webhook of its user and of the uplines including downlines
+func (uc *mutationWebhookUsecase) EnqueueMutation(event *domain.DomainEvent) error {
+	var payload domain.BalanceEventPayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return fmt.Errorf("failed to decode balance event: %w", err)
+	}
+
+	queued, err := uc.webhookRepo.Enqueue(&domain.MutationStatementEntry{
+		BalanceEventPayload: payload,
+		OccurredAt:          event.CreatedAt,
+	}, uc.config.MaxDepth)
+	if err != nil {
+		return err
+	}
+
+	if queued > 0 {
+		logger.Debug("Mutation queued for webhooks",
+			logger.String("mutation_id", payload.MutationID),
+			logger.Int("webhooks", queued),
+		)
+	}
+	return nil
+}
+
+// DispatchDeliveries batches the mutations queued since the last pass into
+// deliveries and posts the due ones. Delivery is at-least-once: a delivery
+// is retried with backoff until the webhook answers 2xx or attempts run out.
+func (uc *mutationWebhookUsecase) DispatchDeliveries(ctx context.Context) (int, error) {
+	batched, err := uc.webhookRepo.CreateDeliveries(uc.config.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to batch queued mutations: %w", err)
+	}
+	metrics.RecordMutationWebhookBatched(batched)
+
+	deliveries, err := uc.webhookRepo.ClaimDue(uc.config.DispatchLimit, uc.config.Lease)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim due deliveries: %w", err)
+	}
+
+	webhooks := make(map[string]*domain.MutationWebhook)
+	delivered := 0
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			break
+		}
+
+		webhook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			webhook, err = uc.webhookRepo.GetByID(delivery.WebhookID)
+			if err != nil {
+				logger.Error("Failed to get mutation webhook",
+					logger.String("delivery_id", delivery.ID),
+					logger.ErrorField(err),
+				)
+				continue
+			}
+			webhooks[delivery.WebhookID] = webhook
+		}
+
+		// A paused webhook gets nothing; the owner can resend after resuming
+		if !webhook.IsActive {
+			uc.failDelivery(delivery, delivery.Attempts+1, fmt.Errorf("mutation webhook is paused"))
+			continue
+		}
+
+		if err := uc.deliver(ctx, webhook, delivery); err != nil {
+			uc.handleDeliveryFailure(delivery, err)
+			continue
+		}
+
+		if err := uc.webhookRepo.MarkDelivered(delivery.ID); err != nil {
+			logger.Error("Failed to mark mutation webhook delivery delivered",
+				logger.String("delivery_id", delivery.ID),
+				logger.ErrorField(err),
+			)
+			continue
+		}
+		metrics.RecordMutationWebhookDelivery("delivered")
+		delivered++
+	}
+
+	if batched > 0 || len(deliveries) > 0 {
+		logger.Debug("Mutation webhook deliveries dispatched",
+			logger.Int("batched_mutations", batched),
+			logger.Int("claimed", len(deliveries)),
+			logger.Int("delivered", delivered),
+		)
+	}
+
+	return delivered, nil
+}
+
+// deliver posts a delivery as a wallet statement event whose ID is the
+// delivery ID, through the shared webhook dispatcher
+func (uc *mutationWebhookUsecase) deliver(ctx context.Context, webhook *domain.MutationWebhook, delivery *domain.MutationWebhookDelivery) error {
+	entries, err := uc.webhookRepo.ListEntries(delivery.ID)
+	if err != nil {
+		return err
+	}
+
+	event, err := domain.NewDomainEvent(domain.EventWalletStatement, domain.AggregateTypeUser, webhook.UserID, &domain.WalletStatementPayload{
+		UserID:    webhook.UserID,
+		Mutations: entries,
+	})
+	if err != nil {
+		return err
+	}
+	event.ID = delivery.ID
+	event.CreatedAt = delivery.CreatedAt
+
+	dispatchCtx, cancel := context.WithTimeout(ctx, uc.config.Timeout)
+	defer cancel()
+	return uc.dispatcher.Dispatch(dispatchCtx, webhook.URL, webhook.Secret, event)
+}
+
+func (uc *mutationWebhookUsecase) handleDeliveryFailure(delivery *domain.MutationWebhookDelivery, deliveryErr error) {
+	attempts := delivery.Attempts + 1
+	if attempts >= uc.config.MaxAttempts {
+		uc.failDelivery(delivery, attempts, deliveryErr)
+		return
+	}
+
+	nextAttemptAt := time.Now().Add(uc.calculateDelay(attempts))
+	logger.Warn("Mutation webhook delivery failed, scheduling retry",
+		logger.String("delivery_id", delivery.ID),
+		logger.String("user_id", delivery.UserID),
+		logger.Int("attempts", attempts),
+		logger.String("next_attempt_at", nextAttemptAt.Format(time.RFC3339)),
+		logger.ErrorField(deliveryErr),
+	)
+	if err := uc.webhookRepo.ScheduleRetry(delivery.ID, deliveryErr.Error(), nextAttemptAt); err != nil {
+		logger.Error("Failed to schedule mutation webhook delivery retry",
+			logger.String("delivery_id", delivery.ID),
+			logger.ErrorField(err),
+		)
+		return
+	}
+	metrics.RecordMutationWebhookDelivery("retry")
+}
+
+func (uc *mutationWebhookUsecase) failDelivery(delivery *domain.MutationWebhookDelivery, attempts int, deliveryErr error) {
+	logger.Error("Mutation webhook delivery failed permanently",
+		logger.String("delivery_id", delivery.ID),
+		logger.String("user_id", delivery.UserID),
+		logger.Int("attempts", attempts),
+		logger.ErrorField(deliveryErr),
+	)
+	if err := uc.webhookRepo.MarkFailed(delivery.ID, deliveryErr.Error()); err != nil {
+		logger.Error("Failed to mark mutation webhook delivery failed",
+			logger.String("delivery_id", delivery.ID),
+			logger.ErrorField(err),
+		)
+		return
+	}
+	metrics.RecordMutationWebhookDelivery("failed")
+}
+
+// calculateDelay returns exponential backoff delay for the given attempt
+func (uc *mutationWebhookUsecase) calculateDelay(attempt int) time.Duration {
+	delay := uc.config.BaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= uc.config.MaxDelay {
+			return uc.config.MaxDelay
+		}
+	}
+	return delay
+}
+
+// mutationWebhookResolveTimeout bounds the DNS lookup of a webhook host
+const mutationWebhookResolveTimeout = 5 * time.Second
+
+// checkURL validates a webhook URL: absolute, with a host, and https unless
+// plain http is allowed. Every address of the host must be public, so users
+// cannot make the server post to its own network. The dispatcher checks the
+// address again when it connects, as DNS can change after the check.
+func (uc *mutationWebhookUsecase) checkURL(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		return "", fmt.Errorf("invalid webhook url")
+	}
+	if parsed.Scheme == "http" && !uc.config.AllowHTTP {
+		return "", fmt.Errorf("webhook url must use https")
+	}
+	if uc.config.AllowPrivateNetworks {
+		return rawURL, nil
+	}
+
+	ips := []net.IP{net.ParseIP(parsed.Hostname())}
+	if ips[0] == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), mutationWebhookResolveTimeout)
+		defer cancel()
+
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, parsed.Hostname())
+		if err != nil || len(addrs) == 0 {
+			return "", fmt.Errorf("webhook url host cannot be resolved")
+		}
+		ips = ips[:0]
+		for _, addr := range addrs {
+			ips = append(ips, addr.IP)
+		}
+	}
+	for _, ip := range ips {
+		if !utils.IsPublicIP(ip) {
+			return "", fmt.Errorf("webhook url must not point to a private address")
+		}
+	}
+
+	return rawURL, nil
+}
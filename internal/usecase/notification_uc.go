@@ -0,0 +1,732 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+type notificationUsecase struct {
+	prefRepo     domain.NotificationPreferenceRepository
+	templateRepo domain.MessageTemplateRepository
+	outboxRepo   domain.OutboxRepository
+	userRepo     domain.UserRepository
+	reportRepo   domain.ReportRepository
+	priceRepo    domain.PriceHistoryRepository
+	config       NotificationConfig
+	location     *time.Location
+}
+
+// NotificationConfig defines thresholds and the calendar used by notifications
+type NotificationConfig struct {
+	// LowBalanceThreshold triggers a warning when a deduction (CREDIT
+	// mutation) takes the balance below it; zero disables the warning
+	LowBalanceThreshold float64
+	// Timezone cuts the day covered by daily summaries
+	Timezone string
+}
+
+// DefaultNotificationConfig returns default notification configuration
+func DefaultNotificationConfig() NotificationConfig {
+	return NotificationConfig{
+		LowBalanceThreshold: 50000,
+		Timezone:            "Asia/Jakarta",
+	}
+}
+
+// NewNotificationUsecase creates a new notification use case
+func NewNotificationUsecase(
+	prefRepo domain.NotificationPreferenceRepository,
+	templateRepo domain.MessageTemplateRepository,
+	outboxRepo domain.OutboxRepository,
+	userRepo domain.UserRepository,
+	reportRepo domain.ReportRepository,
+	priceRepo domain.PriceHistoryRepository,
+	config NotificationConfig,
+) domain.NotificationUsecase {
+	if config.LowBalanceThreshold < 0 {
+		config.LowBalanceThreshold = 0
+	}
+	if config.Timezone == "" {
+		config.Timezone = DefaultNotificationConfig().Timezone
+	}
+
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		logger.Warn("Invalid notification timezone, falling back to UTC",
+			logger.String("timezone", config.Timezone),
+			logger.ErrorField(err),
+		)
+		config.Timezone = "UTC"
+		location = time.UTC
+	}
+
+	return &notificationUsecase{
+		prefRepo:     prefRepo,
+		templateRepo: templateRepo,
+		outboxRepo:   outboxRepo,
+		userRepo:     userRepo,
+		reportRepo:   reportRepo,
+		priceRepo:    priceRepo,
+		config:       config,
+		location:     location,
+	}
+}
+
+// GetPreferences returns the full event x channel preference matrix of a user,
+// filling events without a stored preference with the channel default
+func (uc *notificationUsecase) GetPreferences(userID string) ([]*domain.NotificationPreference, error) {
+	stored, err := uc.prefRepo.GetByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]*domain.NotificationPreference, len(stored))
+	for _, pref := range stored {
+		byKey[pref.EventType+"|"+pref.Channel] = pref
+	}
+
+	prefs := make([]*domain.NotificationPreference, 0, len(domain.NotificationEventTypes)*len(domain.NotificationChannels))
+	for _, eventType := range domain.NotificationEventTypes {
+		for _, channel := range domain.NotificationChannels {
+			if pref, ok := byKey[eventType+"|"+channel]; ok {
+				prefs = append(prefs, pref)
+				continue
+			}
+			prefs = append(prefs, &domain.NotificationPreference{
+				UserID:    userID,
+				EventType: eventType,
+				Channel:   channel,
+				IsEnabled: domain.DefaultNotificationEnabled(eventType, channel),
+			})
+		}
+	}
+
+	return prefs, nil
+}
+
+// UpdatePreference enables or disables an event on a channel for the user
+func (uc *notificationUsecase) UpdatePreference(userID, eventType, channel string, enabled bool) (*domain.NotificationPreference, error) {
+	eventType = strings.ToLower(strings.TrimSpace(eventType))
+	channel = strings.ToUpper(strings.TrimSpace(channel))
+
+	if !domain.IsValidNotificationEventType(eventType) {
+		return nil, fmt.Errorf("invalid notification event type")
+	}
+	if !domain.IsValidNotificationChannel(channel) {
+		return nil, fmt.Errorf("invalid notification channel")
+	}
+
+	pref := &domain.NotificationPreference{
+		ID:        utils.GenerateUUID(),
+		UserID:    userID,
+		EventType: eventType,
+		Channel:   channel,
+		IsEnabled: enabled,
+	}
+	if err := uc.prefRepo.Upsert(pref); err != nil {
+		return nil, err
+	}
+
+	return pref, nil
+}
+
+// ListTemplates returns all message templates
+func (uc *notificationUsecase) ListTemplates() ([]*domain.MessageTemplate, error) {
+	return uc.templateRepo.List()
+}
+
+// GetTemplate returns a message template by ID
+func (uc *notificationUsecase) GetTemplate(id string) (*domain.MessageTemplate, error) {
+	return uc.templateRepo.GetByID(id)
+}
+
+// UpdateTemplate validates placeholders and updates a message template
+func (uc *notificationUsecase) UpdateTemplate(id string, subject, body *string, isActive *bool, updatedBy *string) (*domain.MessageTemplate, error) {
+	template, err := uc.templateRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if subject != nil {
+		if err := domain.ValidateTemplate(template.EventType, *subject); err != nil {
+			return nil, err
+		}
+		template.Subject = subject
+	}
+	if body != nil {
+		if strings.TrimSpace(*body) == "" {
+			return nil, fmt.Errorf("template body is required")
+		}
+		if err := domain.ValidateTemplate(template.EventType, *body); err != nil {
+			return nil, err
+		}
+		template.Body = *body
+	}
+	if isActive != nil {
+		template.IsActive = *isActive
+	}
+	template.UpdatedBy = updatedBy
+
+	if err := uc.templateRepo.Update(template); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// PreviewTemplate renders a template with sample data
+func (uc *notificationUsecase) PreviewTemplate(id string, data map[string]string) (string, string, error) {
+	template, err := uc.templateRepo.GetByID(id)
+	if err != nil {
+		return "", "", err
+	}
+
+	subject, body := template.Render(data)
+	return subject, body, nil
+}
+
+// GenerateMessages renders templates for a domain event and queues outbox messages
+// on every channel the user has enabled. Returns the number of messages queued.
+func (uc *notificationUsecase) GenerateMessages(event *domain.DomainEvent) (int, error) {
+	notifications, err := buildNotifications(event, uc.config.LowBalanceThreshold)
+	if err != nil {
+		return 0, err
+	}
+	if len(notifications) == 0 {
+		return 0, nil
+	}
+
+	user, err := uc.userRepo.GetByID(notifications[0].userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get user for notification: %w", err)
+	}
+
+	prefs, err := uc.GetPreferences(user.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	for _, notification := range notifications {
+		sourceID := event.ID
+		if notification.sourceID != "" {
+			sourceID = notification.sourceID
+		}
+		n, err := uc.queue(user, prefs, notification, sourceID)
+		queued += n
+		if err != nil {
+			return queued, err
+		}
+	}
+
+	return queued, nil
+}
+
+// QueueAccountMessage queues an account message such as a password reset.
+// These messages ignore notification preferences.
+func (uc *notificationUsecase) QueueAccountMessage(userID, eventType, channel string, data map[string]string) error {
+	if eventType != domain.NotificationEventPasswordReset {
+		return fmt.Errorf("invalid account message event type")
+	}
+	if !domain.IsValidNotificationChannel(channel) {
+		return fmt.Errorf("invalid notification channel")
+	}
+
+	user, err := uc.userRepo.GetByID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user for account message: %w", err)
+	}
+
+	recipient := notificationRecipient(user, channel)
+	if recipient == "" {
+		return fmt.Errorf("user has no recipient for channel %s", channel)
+	}
+
+	template, err := uc.templateRepo.GetActive(eventType, channel)
+	if err != nil {
+		return fmt.Errorf("no active %s template for %s", channel, eventType)
+	}
+
+	values := make(map[string]string, len(data)+2)
+	for k, v := range data {
+		values[k] = v
+	}
+	fillUserPlaceholders(values, user)
+
+	subject, body := template.Render(values)
+	outbox := &domain.Outbox{
+		ID:              utils.GenerateUUID(),
+		Destination:     channel,
+		RecipientNumber: recipient,
+		RecipientName:   user.FullName,
+		Message:         body,
+		MessageType:     domain.MessageTypeAlert,
+		UserID:          &user.ID,
+		Status:          domain.MessageStatusPending,
+		MaxRetries:      3,
+		ScheduledAt:     time.Now(),
+		Priority:        domain.PriorityHigh,
+	}
+	if subject != "" {
+		outbox.Subject = &subject
+	}
+
+	return uc.outboxRepo.Create(outbox)
+}
+
+// GenerateDailySummaries queues the activity summary of the day containing
+// the given time for every active user. Messages are keyed by user and day,
+// so running it twice for the same day queues nothing new.
+func (uc *notificationUsecase) GenerateDailySummaries(day time.Time) (int, error) {
+	if uc.reportRepo == nil {
+		return 0, fmt.Errorf("report repository is not configured")
+	}
+
+	local := day.In(uc.location)
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, uc.location)
+	end := start.AddDate(0, 0, 1)
+
+	summaries, err := uc.reportRepo.GetUserDailySummaries(start, end)
+	if err != nil {
+		return 0, err
+	}
+
+	date := utils.FormatDate(start)
+	queued := 0
+	for _, summary := range summaries {
+		user, err := uc.userRepo.GetByID(summary.UserID)
+		if err != nil {
+			logger.Warn("Skipping daily summary of unknown user",
+				logger.String("user_id", summary.UserID),
+				logger.ErrorField(err),
+			)
+			continue
+		}
+
+		prefs, err := uc.GetPreferences(user.ID)
+		if err != nil {
+			return queued, err
+		}
+
+		notification := &pendingNotification{
+			eventType:   domain.NotificationEventDailySummary,
+			userID:      user.ID,
+			messageType: domain.MessageTypeNotification,
+			priority:    domain.PriorityLow,
+			data: map[string]string{
+				"date":               date,
+				"total_transactions": fmt.Sprintf("%d", summary.TotalTransactions),
+				"success_count":      fmt.Sprintf("%d", summary.SuccessCount),
+				"failed_count":       fmt.Sprintf("%d", summary.FailedCount),
+				"total_spent":        utils.FormatCurrency(summary.TotalSpent),
+				"total_deposit":      utils.FormatCurrency(summary.TotalDeposit),
+				"balance":            utils.FormatCurrency(summary.Balance),
+			},
+		}
+
+		sourceID := utils.GenerateNameUUID(domain.NotificationEventDailySummary + ":" + user.ID + ":" + date)
+		n, err := uc.queue(user, prefs, notification, sourceID)
+		queued += n
+		if err != nil {
+			return queued, err
+		}
+	}
+
+	logger.Info("Daily summaries generated",
+		logger.String("date", date),
+		logger.Int("users", len(summaries)),
+		logger.Int("queued", queued),
+	)
+
+	return queued, nil
+}
+
+// maxPriceDigestLines caps the changes listed in one price change digest
+const maxPriceDigestLines = 30
+
+// GeneratePriceChangeDigests queues one digest per changed category to every
+// active user who opted in to price.changed. A product changed several times
+// in the window is listed once, from its first old price to its last new
+// price. Base price changes are shown as the price the user pays after their
+// markup. Messages are keyed by user, category and window end, so running it
+// twice for the same window queues nothing new.
+func (uc *notificationUsecase) GeneratePriceChangeDigests(since, until time.Time) (int, error) {
+	if uc.priceRepo == nil {
+		return 0, fmt.Errorf("price history repository is not configured")
+	}
+	if !until.After(since) {
+		return 0, fmt.Errorf("until must be after since")
+	}
+
+	changes, _, err := uc.priceRepo.ListChanges(&domain.PriceChangeFilter{
+		PriceTypes: domain.CustomerPriceTypes,
+		Since:      since,
+		Until:      until,
+	})
+	if err != nil {
+		return 0, err
+	}
+	categories := groupPriceChanges(changes)
+	if len(categories) == 0 {
+		return 0, nil
+	}
+
+	userIDs, err := uc.prefRepo.ListEnabledUsers(domain.NotificationEventPriceChanged)
+	if err != nil {
+		return 0, err
+	}
+
+	periodStart := utils.FormatTime(since.In(uc.location))
+	periodEnd := utils.FormatTime(until.In(uc.location))
+	windowKey := until.UTC().Format(time.RFC3339)
+	queued := 0
+	for _, userID := range userIDs {
+		user, err := uc.userRepo.GetByID(userID)
+		if err != nil {
+			logger.Warn("Skipping price change digest of unknown user",
+				logger.String("user_id", userID),
+				logger.ErrorField(err),
+			)
+			continue
+		}
+		if !user.IsActive {
+			continue
+		}
+
+		prefs, err := uc.GetPreferences(user.ID)
+		if err != nil {
+			return queued, err
+		}
+
+		for _, category := range categories {
+			notification := &pendingNotification{
+				eventType:   domain.NotificationEventPriceChanged,
+				userID:      user.ID,
+				messageType: domain.MessageTypeNotification,
+				priority:    domain.PriorityLow,
+				data: map[string]string{
+					"category":     category.name,
+					"change_count": fmt.Sprintf("%d", len(category.changes)),
+					"changes":      formatPriceChanges(user, category.changes),
+					"period_start": periodStart,
+					"period_end":   periodEnd,
+				},
+			}
+
+			sourceID := utils.GenerateNameUUID(domain.NotificationEventPriceChanged + ":" + user.ID + ":" + category.name + ":" + windowKey)
+			n, err := uc.queue(user, prefs, notification, sourceID)
+			queued += n
+			if err != nil {
+				return queued, err
+			}
+		}
+	}
+
+	logger.Info("Price change digests generated",
+		logger.String("period_start", periodStart),
+		logger.String("period_end", periodEnd),
+		logger.Int("categories", len(categories)),
+		logger.Int("subscribers", len(userIDs)),
+		logger.Int("queued", queued),
+	)
+
+	return queued, nil
+}
+
+type priceChangeCategory struct {
+	name    string
+	changes []*domain.PriceChange
+}
+
+// groupPriceChanges collapses the changes of each product and price type into
+// one, from the oldest old price to the newest new price, drops the ones that
+// net to zero and groups the rest by category in name order. changes must be
+// ordered newest first.
+func groupPriceChanges(changes []*domain.PriceChange) []*priceChangeCategory {
+	collapsed := make(map[string]*domain.PriceChange)
+	order := make([]string, 0, len(changes))
+	for _, change := range changes {
+		key := change.ProductID + "|" + change.PriceType
+		if existing, ok := collapsed[key]; ok {
+			existing.OldPrice = change.OldPrice
+			continue
+		}
+		copied := *change
+		collapsed[key] = &copied
+		order = append(order, key)
+	}
+
+	byCategory := make(map[string]*priceChangeCategory)
+	for _, key := range order {
+		change := collapsed[key]
+		if change.OldPrice == change.NewPrice {
+			continue
+		}
+		category, ok := byCategory[change.Category]
+		if !ok {
+			category = &priceChangeCategory{name: change.Category}
+			byCategory[change.Category] = category
+		}
+		category.changes = append(category.changes, change)
+	}
+
+	categories := make([]*priceChangeCategory, 0, len(byCategory))
+	for _, category := range byCategory {
+		sort.SliceStable(category.changes, func(i, j int) bool {
+			return category.changes[i].ProductCode < category.changes[j].ProductCode
+		})
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].name < categories[j].name
+	})
+	return categories
+}
+
+// formatPriceChanges renders one line per change for a digest
+func formatPriceChanges(user *domain.User, changes []*domain.PriceChange) string {
+	lines := make([]string, 0, len(changes)+1)
+	for i, change := range changes {
+		if i == maxPriceDigestLines {
+			lines = append(lines, fmt.Sprintf("...dan %d perubahan lainnya", len(changes)-i))
+			break
+		}
+
+		label, oldPrice, newPrice := "harga jual", change.OldPrice, change.NewPrice
+		if change.PriceType == domain.PriceTypeBase {
+			label, oldPrice, newPrice = "harga Anda", user.GetEffectivePrice(oldPrice), user.GetEffectivePrice(newPrice)
+		}
+		lines = append(lines, fmt.Sprintf("- %s %s (%s): %s -> %s",
+			change.ProductCode, change.ProductName, label,
+			utils.FormatCurrency(oldPrice), utils.FormatCurrency(newPrice),
+		))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// queue renders a notification on every channel the user enabled for it.
+// sourceID deduplicates messages when the same source is processed again.
+func (uc *notificationUsecase) queue(user *domain.User, prefs []*domain.NotificationPreference, notification *pendingNotification, sourceID string) (int, error) {
+	fillUserPlaceholders(notification.data, user)
+
+	queued := 0
+	for _, pref := range prefs {
+		if pref.EventType != notification.eventType || !pref.IsEnabled {
+			continue
+		}
+
+		recipient := notificationRecipient(user, pref.Channel)
+		if recipient == "" {
+			continue
+		}
+
+		var subject, body string
+		template, err := uc.templateRepo.GetActive(notification.eventType, pref.Channel)
+		switch {
+		case err == nil:
+			subject, body = template.Render(notification.data)
+		case notification.fallbackBody != "":
+			body = notification.fallbackBody
+			if pref.Channel == domain.NotificationChannelEmail {
+				subject = notification.fallbackSubject
+			}
+		default:
+			logger.Debug("No active message template",
+				logger.String("event_type", notification.eventType),
+				logger.String("channel", pref.Channel),
+			)
+			continue
+		}
+
+		outbox := &domain.Outbox{
+			ID:              utils.GenerateUUID(),
+			Destination:     pref.Channel,
+			RecipientNumber: recipient,
+			RecipientName:   user.FullName,
+			Message:         body,
+			MessageType:     notification.messageType,
+			UserID:          &user.ID,
+			TransactionID:   notification.transactionID,
+			SourceEventID:   &sourceID,
+			Status:          domain.MessageStatusPending,
+			MaxRetries:      3,
+			ScheduledAt:     time.Now(),
+			Priority:        notification.priority,
+		}
+		if subject != "" {
+			outbox.Subject = &subject
+		}
+
+		if err := uc.outboxRepo.Create(outbox); err != nil {
+			return queued, err
+		}
+		queued++
+	}
+
+	return queued, nil
+}
+
+func fillUserPlaceholders(data map[string]string, user *domain.User) {
+	data["username"] = user.Username
+	data["name"] = user.Username
+	if user.FullName != nil && *user.FullName != "" {
+		data["name"] = *user.FullName
+	}
+}
+
+type pendingNotification struct {
+	eventType     string
+	userID        string
+	transactionID *string
+	messageType   string
+	priority      int
+	data          map[string]string
+	// sourceID deduplicates the messages instead of the event ID
+	sourceID string
+	// fallbackBody and fallbackSubject are sent when the event has no active
+	// template on a channel; without them the channel is skipped
+	fallbackBody    string
+	fallbackSubject string
+}
+
+// buildNotifications maps a domain event to notification events and template
+// data. A completed transaction notifies its buyer once per outcome whatever
+// the channel it was ordered on: success, or failure (including timeouts and
+// refunds), keyed by transaction so repeated completions queue nothing new.
+// Deposits are announced as deposit.confirmed instead of a plain balance
+// mutation, and a deduction that takes the balance below lowBalanceThreshold also
+// raises a low balance warning. Returns nil for events that do not produce
+// notifications.
+func buildNotifications(event *domain.DomainEvent, lowBalanceThreshold float64) ([]*pendingNotification, error) {
+	switch event.EventType {
+	case domain.EventTransactionCompleted:
+		var payload domain.TransactionEventPayload
+		if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+			return nil, fmt.Errorf("failed to decode transaction event: %w", err)
+		}
+
+		var eventType string
+		switch payload.Status {
+		case domain.StatusSuccess:
+			eventType = domain.NotificationEventTransactionSuccess
+		case domain.StatusFailed, domain.StatusTimeout, domain.StatusRefund:
+			eventType = domain.NotificationEventTransactionFailed
+		default:
+			return nil, nil
+		}
+
+		transactionID := payload.TransactionID
+		return []*pendingNotification{{
+			eventType:     eventType,
+			userID:        payload.UserID,
+			transactionID: &transactionID,
+			messageType:   domain.MessageTypeTransaction,
+			priority:      domain.PriorityHigh,
+			sourceID:      utils.GenerateNameUUID(eventType + ":" + transactionID),
+			fallbackBody: domain.GenerateTransactionResponse(&domain.Transaction{
+				ProductCode:       payload.ProductCode,
+				DestinationNumber: payload.DestinationNumber,
+				Status:            payload.Status,
+				SerialNumber:      payload.SerialNumber,
+				SupplierMessage:   payload.Message,
+			}),
+			fallbackSubject: "Transaksi " + payload.TrxCode,
+			data: map[string]string{
+				"trx_code":     payload.TrxCode,
+				"product_code": payload.ProductCode,
+				"destination":  payload.DestinationNumber,
+				"sn":           stringValue(payload.SerialNumber),
+				"price":        utils.FormatCurrency(payload.SellingPrice),
+				"admin_fee":    utils.FormatCurrency(payload.AdminFee),
+				"total":        utils.FormatCurrency(payload.SellingPrice + payload.AdminFee),
+				"status":       payload.Status,
+				"message":      stringValue(payload.Message),
+				"date":         utils.FormatTime(event.CreatedAt),
+			},
+		}}, nil
+
+	case domain.EventBalanceMutated:
+		var payload domain.BalanceEventPayload
+		if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+			return nil, fmt.Errorf("failed to decode balance event: %w", err)
+		}
+
+		date := utils.FormatTime(event.CreatedAt)
+		if payload.Type == domain.MutationTypeDebit && payload.ReferenceType != nil && *payload.ReferenceType == domain.ReferenceTypeDeposit {
+			return []*pendingNotification{{
+				eventType:   domain.NotificationEventDepositConfirmed,
+				userID:      payload.UserID,
+				messageType: domain.MessageTypeNotification,
+				priority:    domain.PriorityHigh,
+				data: map[string]string{
+					"amount":         utils.FormatCurrency(payload.Amount),
+					"balance_before": utils.FormatCurrency(payload.BalanceBefore),
+					"balance":        utils.FormatCurrency(payload.BalanceAfter),
+					"description":    payload.Description,
+					"date":           date,
+				},
+			}}, nil
+		}
+
+		notifications := []*pendingNotification{{
+			eventType:   domain.NotificationEventBalanceMutated,
+			userID:      payload.UserID,
+			messageType: domain.MessageTypeNotification,
+			priority:    domain.PriorityNormal,
+			data: map[string]string{
+				"type":           payload.Type,
+				"amount":         utils.FormatCurrency(payload.Amount),
+				"balance_before": utils.FormatCurrency(payload.BalanceBefore),
+				"balance":        utils.FormatCurrency(payload.BalanceAfter),
+				"description":    payload.Description,
+				"date":           date,
+			},
+		}}
+
+		// Only the mutation crossing the threshold warns, not every deduction below it
+		if lowBalanceThreshold > 0 && payload.Type == domain.MutationTypeCredit &&
+			payload.BalanceBefore >= lowBalanceThreshold && payload.BalanceAfter < lowBalanceThreshold {
+			notifications = append(notifications, &pendingNotification{
+				eventType:   domain.NotificationEventLowBalance,
+				userID:      payload.UserID,
+				messageType: domain.MessageTypeAlert,
+				priority:    domain.PriorityHigh,
+				data: map[string]string{
+					"balance":   utils.FormatCurrency(payload.BalanceAfter),
+					"threshold": utils.FormatCurrency(lowBalanceThreshold),
+					"date":      date,
+				},
+			})
+		}
+
+		return notifications, nil
+	}
+
+	return nil, nil
+}
+
+func notificationRecipient(user *domain.User, channel string) string {
+	switch channel {
+	case domain.NotificationChannelWhatsApp:
+		if user.Phone != nil {
+			return strings.TrimSpace(*user.Phone)
+		}
+	case domain.NotificationChannelEmail:
+		return strings.TrimSpace(user.Email)
+	}
+	return ""
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
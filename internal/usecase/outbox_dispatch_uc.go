@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+type outboxDispatchUsecase struct {
+	outboxRepo domain.OutboxRepository
+	senders    []domain.MessageSender
+	config     OutboxDispatchConfig
+}
+
+// OutboxDispatchConfig defines how queued outbox messages are claimed and retried
+type OutboxDispatchConfig struct {
+	BatchSize int           // Messages claimed per channel and run
+	Lease     time.Duration // How long a claimed message is hidden from other dispatchers
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	SendLimit time.Duration // Timeout for sending a single message, including sender retries
+}
+
+// DefaultOutboxDispatchConfig returns default outbox dispatch configuration
+func DefaultOutboxDispatchConfig() OutboxDispatchConfig {
+	return OutboxDispatchConfig{
+		BatchSize: 50,
+		Lease:     5 * time.Minute,
+		BaseDelay: time.Minute,
+		MaxDelay:  time.Hour,
+		SendLimit: 2 * time.Minute,
+	}
+}
+
+// permanentError is implemented by sender errors that must not be retried
+type permanentError interface {
+	Permanent() bool
+}
+
+// NewOutboxDispatchUsecase creates a new outbox dispatch use case
+func NewOutboxDispatchUsecase(
+	outboxRepo domain.OutboxRepository,
+	senders []domain.MessageSender,
+	config OutboxDispatchConfig,
+) domain.OutboxDispatchUsecase {
+	defaults := DefaultOutboxDispatchConfig()
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.Lease <= 0 {
+		config.Lease = defaults.Lease
+	}
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = defaults.BaseDelay
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = defaults.MaxDelay
+	}
+	if config.SendLimit <= 0 {
+		config.SendLimit = defaults.SendLimit
+	}
+
+	return &outboxDispatchUsecase{
+		outboxRepo: outboxRepo,
+		senders:    senders,
+		config:     config,
+	}
+}
+
+// DispatchPending sends a batch of due messages on every channel with a
+// sender and returns the number of messages sent. Messages of channels
+// without a sender stay queued.
+func (uc *outboxDispatchUsecase) DispatchPending(ctx context.Context) (int, error) {
+	sent := 0
+	for _, sender := range uc.senders {
+		if ctx.Err() != nil {
+			break
+		}
+
+		messages, err := uc.outboxRepo.ClaimPending(sender.Channel(), uc.config.BatchSize, uc.config.Lease)
+		if err != nil {
+			return sent, err
+		}
+
+		for _, message := range messages {
+			if ctx.Err() != nil {
+				break
+			}
+			if uc.send(ctx, sender, message) {
+				sent++
+			}
+		}
+
+		if len(messages) > 0 {
+			logger.Debug("Outbox messages dispatched",
+				logger.String("channel", sender.Channel()),
+				logger.Int("claimed", len(messages)),
+			)
+		}
+	}
+
+	return sent, nil
+}
+
+func (uc *outboxDispatchUsecase) send(ctx context.Context, sender domain.MessageSender, message *domain.Outbox) bool {
+	sendCtx, cancel := context.WithTimeout(ctx, uc.config.SendLimit)
+	externalID, err := sender.Send(sendCtx, message)
+	cancel()
+
+	if err == nil {
+		if err := uc.outboxRepo.MarkAsSent(message.ID, externalID); err != nil {
+			logger.Error("Failed to mark outbox message sent",
+				logger.String("outbox_id", message.ID),
+				logger.ErrorField(err),
+			)
+		}
+		return true
+	}
+
+	attempts := message.RetryCount + 1
+	var permanent permanentError
+	if (errors.As(err, &permanent) && permanent.Permanent()) || attempts >= message.MaxRetries {
+		logger.Error("Outbox message failed permanently",
+			logger.String("outbox_id", message.ID),
+			logger.String("channel", message.Destination),
+			logger.Int("attempts", attempts),
+			logger.ErrorField(err),
+		)
+		if err := uc.outboxRepo.MarkAsUndeliverable(message.ID, err.Error()); err != nil {
+			logger.Error("Failed to mark outbox message undeliverable", logger.String("outbox_id", message.ID), logger.ErrorField(err))
+		}
+		return false
+	}
+
+	nextAttemptAt := time.Now().Add(uc.calculateDelay(attempts))
+	logger.Warn("Outbox message send failed, scheduling retry",
+		logger.String("outbox_id", message.ID),
+		logger.String("channel", message.Destination),
+		logger.Int("attempts", attempts),
+		logger.String("next_attempt_at", nextAttemptAt.Format(time.RFC3339)),
+		logger.ErrorField(err),
+	)
+	if err := uc.outboxRepo.ScheduleRetry(message.ID, err.Error(), nextAttemptAt); err != nil {
+		logger.Error("Failed to schedule outbox retry", logger.String("outbox_id", message.ID), logger.ErrorField(err))
+	}
+	return false
+}
+
+// calculateDelay returns exponential backoff delay for the given attempt
+func (uc *outboxDispatchUsecase) calculateDelay(attempt int) time.Duration {
+	delay := uc.config.BaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= uc.config.MaxDelay {
+			return uc.config.MaxDelay
+		}
+	}
+	return delay
+}
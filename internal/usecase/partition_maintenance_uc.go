@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+type partitionMaintenanceUsecase struct {
+	partitionRepo domain.PartitionRepository
+	config        PartitionMaintenanceConfig
+}
+
+// PartitionMaintenanceConfig defines how far ahead partitions are created and
+// how long they stay attached
+type PartitionMaintenanceConfig struct {
+	// PremakeMonths is the number of months created ahead of the current one
+	PremakeMonths int
+	// RetentionMonths detaches partitions older than this many months; 0 keeps all
+	RetentionMonths int
+}
+
+// DefaultPartitionMaintenanceConfig returns default partition maintenance configuration
+func DefaultPartitionMaintenanceConfig() PartitionMaintenanceConfig {
+	return PartitionMaintenanceConfig{
+		PremakeMonths:   3,
+		RetentionMonths: 0,
+	}
+}
+
+// NewPartitionMaintenanceUsecase creates a new partition maintenance use case
+func NewPartitionMaintenanceUsecase(partitionRepo domain.PartitionRepository, config PartitionMaintenanceConfig) domain.PartitionMaintenanceUsecase {
+	if config.PremakeMonths <= 0 {
+		config.PremakeMonths = DefaultPartitionMaintenanceConfig().PremakeMonths
+	}
+	if config.RetentionMonths < 0 {
+		config.RetentionMonths = 0
+	}
+
+	return &partitionMaintenanceUsecase{
+		partitionRepo: partitionRepo,
+		config:        config,
+	}
+}
+
+// MaintainPartitions makes sure every partitioned table has partitions for the
+// current month and the next PremakeMonths, then detaches partitions that are
+// past retention. Rows in a default partition are reported, since they block
+// creating the partition for their month.
+func (uc *partitionMaintenanceUsecase) MaintainPartitions() ([]*domain.PartitionMaintenanceResult, error) {
+	now := time.Now()
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+
+	results := make([]*domain.PartitionMaintenanceResult, 0, len(domain.PartitionedTables))
+	for _, table := range domain.PartitionedTables {
+		result := &domain.PartitionMaintenanceResult{
+			Table:    table,
+			Created:  []string{},
+			Detached: []string{},
+		}
+
+		for i := 0; i <= uc.config.PremakeMonths; i++ {
+			name, err := uc.partitionRepo.CreateMonthlyPartition(table, currentMonth.AddDate(0, i, 0))
+			if err != nil {
+				return results, err
+			}
+			if name != "" {
+				result.Created = append(result.Created, name)
+				logger.Info("Partition created",
+					logger.String("table", table),
+					logger.String("partition", name),
+				)
+			}
+		}
+
+		if uc.config.RetentionMonths > 0 {
+			detached, err := uc.detachExpired(table, currentMonth.AddDate(0, -uc.config.RetentionMonths, 0))
+			result.Detached = detached
+			if err != nil {
+				return append(results, result), err
+			}
+		}
+
+		defaultRows, err := uc.partitionRepo.CountDefaultRows(table)
+		if err != nil {
+			return append(results, result), err
+		}
+		result.DefaultRows = defaultRows
+		if defaultRows > 0 {
+			logger.Warn("Rows stored in default partition",
+				logger.String("table", table),
+				logger.Int64("rows", defaultRows),
+			)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// detachExpired detaches the monthly partitions of table that start before cutoff
+func (uc *partitionMaintenanceUsecase) detachExpired(table string, cutoff time.Time) ([]string, error) {
+	partitions, err := uc.partitionRepo.ListMonthlyPartitions(table)
+	if err != nil {
+		return nil, err
+	}
+
+	detached := []string{}
+	for _, partition := range partitions {
+		if !partition.MonthStart.Before(cutoff) {
+			continue
+		}
+		if err := uc.partitionRepo.DetachPartition(table, partition.Name); err != nil {
+			return detached, err
+		}
+		detached = append(detached, partition.Name)
+		logger.Info("Partition detached",
+			logger.String("table", table),
+			logger.String("partition", partition.Name),
+		)
+	}
+
+	return detached, nil
+}
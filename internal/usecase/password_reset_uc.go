@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+type passwordResetUsecase struct {
+	resetRepo      domain.PasswordResetRepository
+	userRepo       domain.UserRepository
+	notificationUC domain.NotificationUsecase
+	authService    domain.AuthService
+	config         PasswordResetConfig
+}
+
+// PasswordResetConfig defines reset token lifetime, delivery and limits
+type PasswordResetConfig struct {
+	TokenTTL time.Duration
+	// ResetURL is the page that completes the reset; the token is added as
+	// the "token" query parameter
+	ResetURL string
+	// MaxRequestsPerHour bounds the tokens issued to one account per hour
+	MaxRequestsPerHour int
+}
+
+// DefaultPasswordResetConfig returns default password reset configuration
+func DefaultPasswordResetConfig() PasswordResetConfig {
+	return PasswordResetConfig{
+		TokenTTL:           30 * time.Minute,
+		ResetURL:           "https://eraflazz.com/reset-password",
+		MaxRequestsPerHour: 3,
+	}
+}
+
+// Password length bounds; bcrypt only uses the first 72 bytes
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 72
+)
+
+// NewPasswordResetUsecase creates a new password reset use case
+func NewPasswordResetUsecase(
+	resetRepo domain.PasswordResetRepository,
+	userRepo domain.UserRepository,
+	notificationUC domain.NotificationUsecase,
+	authService domain.AuthService,
+	config PasswordResetConfig,
+) domain.PasswordResetUsecase {
+	defaults := DefaultPasswordResetConfig()
+	if config.TokenTTL <= 0 {
+		config.TokenTTL = defaults.TokenTTL
+	}
+	if config.ResetURL == "" {
+		config.ResetURL = defaults.ResetURL
+	}
+	if config.MaxRequestsPerHour <= 0 {
+		config.MaxRequestsPerHour = defaults.MaxRequestsPerHour
+	}
+
+	return &passwordResetUsecase{
+		resetRepo:      resetRepo,
+		userRepo:       userRepo,
+		notificationUC: notificationUC,
+		authService:    authService,
+		config:         config,
+	}
+}
+
+// RequestReset issues a reset token and queues it on the requested channel.
+// Requests for unknown or inactive accounts, accounts without a recipient on
+// the channel, and requests over the hourly limit succeed without sending
+// anything so callers cannot probe which accounts exist.
+func (uc *passwordResetUsecase) RequestReset(email, channel, ip string) error {
+	email = strings.ToLower(strings.TrimSpace(email))
+	channel = strings.ToUpper(strings.TrimSpace(channel))
+	if channel == "" {
+		channel = domain.NotificationChannelEmail
+	}
+	if !domain.IsValidNotificationChannel(channel) {
+		return fmt.Errorf("invalid notification channel")
+	}
+
+	user, err := uc.userRepo.GetByEmail(email)
+	if err != nil || user == nil || !user.IsActive {
+		logger.Debug("Password reset requested for unknown or inactive account", logger.String("ip", ip))
+		return nil
+	}
+
+	if channel == domain.NotificationChannelWhatsApp && (user.Phone == nil || strings.TrimSpace(*user.Phone) == "") {
+		logger.Info("Password reset requested on WhatsApp for user without phone",
+			logger.String("user_id", user.ID),
+		)
+		return nil
+	}
+
+	recent, err := uc.resetRepo.CountSince(user.ID, time.Now().Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	if recent >= uc.config.MaxRequestsPerHour {
+		logger.Warn("Password reset request limit reached",
+			logger.String("user_id", user.ID),
+			logger.String("ip", ip),
+		)
+		return nil
+	}
+
+	token, err := generateResetToken()
+	if err != nil {
+		return err
+	}
+
+	// A new request replaces any token sent before
+	if err := uc.resetRepo.InvalidateUser(user.ID); err != nil {
+		return err
+	}
+
+	record := &domain.PasswordResetToken{
+		ID:        utils.GenerateUUID(),
+		UserID:    user.ID,
+		TokenHash: hashResetToken(token),
+		Channel:   channel,
+		ExpiresAt: time.Now().Add(uc.config.TokenTTL),
+	}
+	if ip != "" {
+		record.RequestedIP = &ip
+	}
+	if err := uc.resetRepo.Create(record); err != nil {
+		return err
+	}
+
+	link, err := uc.resetLink(token)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.notificationUC.QueueAccountMessage(user.ID, domain.NotificationEventPasswordReset, channel, map[string]string{
+		"reset_link": link,
+		"expires_in": fmt.Sprintf("%d menit", int(uc.config.TokenTTL.Minutes())),
+	}); err != nil {
+		return fmt.Errorf("failed to queue password reset message: %w", err)
+	}
+
+	logger.Info("Password reset token issued",
+		logger.String("user_id", user.ID),
+		logger.String("channel", channel),
+		logger.String("ip", ip),
+	)
+
+	return nil
+}
+
+// ResetPassword consumes the token, stores the new password as a bcrypt hash
+// and revokes the user's existing tokens
+func (uc *passwordResetUsecase) ResetPassword(token, newPassword string) error {
+	if len(newPassword) < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	if len(newPassword) > maxPasswordLength {
+		return fmt.Errorf("password must be at most %d characters", maxPasswordLength)
+	}
+
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return fmt.Errorf("invalid or expired reset token")
+	}
+
+	record, err := uc.resetRepo.Consume(hashResetToken(token))
+	if err != nil {
+		return err
+	}
+
+	passwordHash, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	if err := uc.userRepo.UpdatePassword(record.UserID, passwordHash); err != nil {
+		return err
+	}
+
+	if err := uc.resetRepo.InvalidateUser(record.UserID); err != nil {
+		logger.Warn("Failed to invalidate remaining reset tokens",
+			logger.String("user_id", record.UserID),
+			logger.ErrorField(err),
+		)
+	}
+
+	if err := uc.authService.RevokeUserTokens(record.UserID); err != nil {
+		logger.Error("Failed to revoke tokens after password reset",
+			logger.String("user_id", record.UserID),
+			logger.ErrorField(err),
+		)
+	}
+
+	logger.Info("Password reset completed", logger.String("user_id", record.UserID))
+	return nil
+}
+
+func (uc *passwordResetUsecase) resetLink(token string) (string, error) {
+	link, err := url.Parse(uc.config.ResetURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid password reset URL: %w", err)
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String(), nil
+}
+
+// generateResetToken returns a 256-bit random token
+func generateResetToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashResetToken hashes a token for storage. Tokens are random and long, so
+// an unsalted SHA-256 is enough to keep a database leak from exposing them.
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
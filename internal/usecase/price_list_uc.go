@@ -0,0 +1,312 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+type priceListUsecase struct {
+	productRepo domain.ProductRepository
+	userRepo    domain.UserRepository
+	cacheRepo   domain.PriceListCacheRepository
+	catalogUC   domain.CatalogUsecase
+	healthUC    domain.ProductHealthUsecase
+	config      PriceListConfig
+
+	mu       sync.Mutex
+	inflight map[string]*priceListBuild
+}
+
+// priceListBuild is a rebuild in progress in this process; callers asking for
+// the same category wait on done instead of starting their own
+type priceListBuild struct {
+	done chan struct{}
+	list *domain.PriceList
+	err  error
+}
+
+// PriceListConfig defines how the public price list is cached
+type PriceListConfig struct {
+	// FreshTTL is how long a cached list is served as is; older lists are
+	// still served but rebuilt in the background
+	FreshTTL time.Duration
+	// StaleTTL is how long a cached list is kept in Redis at all
+	StaleTTL time.Duration
+	// RebuildLockTTL bounds how long one instance may hold a rebuild claim
+	RebuildLockTTL time.Duration
+	// WaitTimeout is how long a cache miss waits for another instance's
+	// rebuild before building the list itself
+	WaitTimeout time.Duration
+}
+
+// DefaultPriceListConfig returns default price list configuration
+func DefaultPriceListConfig() PriceListConfig {
+	return PriceListConfig{
+		FreshTTL:       30 * time.Second,
+		StaleTTL:       10 * time.Minute,
+		RebuildLockTTL: 10 * time.Second,
+		WaitTimeout:    2 * time.Second,
+	}
+}
+
+// NewPriceListUsecase creates a new price list use case. cacheRepo may be nil,
+// in which case every call is built from the database.
+func NewPriceListUsecase(productRepo domain.ProductRepository, userRepo domain.UserRepository, cacheRepo domain.PriceListCacheRepository, catalogUC domain.CatalogUsecase, healthUC domain.ProductHealthUsecase, config PriceListConfig) domain.PriceListUsecase {
+	defaults := DefaultPriceListConfig()
+	if config.FreshTTL <= 0 {
+		config.FreshTTL = defaults.FreshTTL
+	}
+	if config.StaleTTL <= 0 {
+		config.StaleTTL = defaults.StaleTTL
+	}
+	if config.StaleTTL < config.FreshTTL {
+		config.StaleTTL = config.FreshTTL
+	}
+	if config.RebuildLockTTL <= 0 {
+		config.RebuildLockTTL = defaults.RebuildLockTTL
+	}
+	if config.WaitTimeout <= 0 {
+		config.WaitTimeout = defaults.WaitTimeout
+	}
+
+	return &priceListUsecase{
+		productRepo: productRepo,
+		userRepo:    userRepo,
+		cacheRepo:   cacheRepo,
+		catalogUC:   catalogUC,
+		healthUC:    healthUC,
+		config:      config,
+		inflight:    make(map[string]*priceListBuild),
+	}
+}
+
+// GetPriceList serves the cached list when it is fresh, serves a stale list
+// while rebuilding it in the background, and only builds inline on a miss
+func (uc *priceListUsecase) GetPriceList(category string) (*domain.PriceList, error) {
+	category = strings.ToUpper(strings.TrimSpace(category))
+	if category != "" {
+		if err := uc.catalogUC.ValidateCategory(category); err != nil {
+			return nil, err
+		}
+	}
+
+	if uc.cacheRepo == nil {
+		return uc.buildPriceList(category)
+	}
+
+	cached, ok, err := uc.cacheRepo.Get(category)
+	if err != nil {
+		logger.Warn("Failed to read cached price list",
+			logger.String("category", category),
+			logger.ErrorField(err),
+		)
+	}
+	if ok {
+		if time.Since(cached.GeneratedAt) >= uc.config.FreshTTL {
+			// Only the hit that starts the rebuild spawns a goroutine; the
+			// others keep serving the stale list without waiting on it
+			if build, started := uc.startRebuild(category); started {
+				go func() {
+					if _, err := uc.runRebuild(category, build); err != nil {
+						logger.Error("Failed to refresh price list",
+							logger.String("category", category),
+							logger.ErrorField(err),
+						)
+					}
+				}()
+			}
+		}
+		return cached, nil
+	}
+
+	return uc.rebuild(category)
+}
+
+// rebuild collapses concurrent rebuilds of a category within this process
+// into one call of rebuildShared
+func (uc *priceListUsecase) rebuild(category string) (*domain.PriceList, error) {
+	build, started := uc.startRebuild(category)
+	if !started {
+		<-build.done
+		return build.list, build.err
+	}
+	return uc.runRebuild(category, build)
+}
+
+// startRebuild returns the rebuild in progress for a category, or registers a
+// new one when none is; started reports whether the caller must run it
+func (uc *priceListUsecase) startRebuild(category string) (build *priceListBuild, started bool) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if existing, ok := uc.inflight[category]; ok {
+		return existing, false
+	}
+	build = &priceListBuild{done: make(chan struct{})}
+	uc.inflight[category] = build
+	return build, true
+}
+
+// runRebuild runs a rebuild registered by startRebuild and wakes its waiters
+func (uc *priceListUsecase) runRebuild(category string, build *priceListBuild) (*domain.PriceList, error) {
+	build.list, build.err = uc.rebuildShared(category)
+
+	uc.mu.Lock()
+	delete(uc.inflight, category)
+	uc.mu.Unlock()
+	close(build.done)
+
+	return build.list, build.err
+}
+
+// rebuildShared builds and caches the list when this instance wins the rebuild
+// claim. Otherwise it waits for the winner to cache a fresh list, falling back
+// to its own uncached build when the wait runs out.
+func (uc *priceListUsecase) rebuildShared(category string) (*domain.PriceList, error) {
+	owner, err := uc.cacheRepo.AcquireRebuild(category, uc.config.RebuildLockTTL)
+	if err != nil {
+		// Redis is unhealthy, every instance builds its own
+		logger.Warn("Failed to claim price list rebuild",
+			logger.String("category", category),
+			logger.ErrorField(err),
+		)
+	} else if owner == "" {
+		if list := uc.waitForRebuild(category); list != nil {
+			return list, nil
+		}
+		return uc.buildPriceList(category)
+	}
+
+	if owner != "" {
+		defer func() {
+			if err := uc.cacheRepo.ReleaseRebuild(category, owner); err != nil {
+				logger.Warn("Failed to release price list rebuild",
+					logger.String("category", category),
+					logger.ErrorField(err),
+				)
+			}
+		}()
+	}
+
+	list, err := uc.buildPriceList(category)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.cacheRepo.Set(category, list, uc.config.StaleTTL); err != nil {
+		logger.Warn("Failed to cache price list",
+			logger.String("category", category),
+			logger.ErrorField(err),
+		)
+	}
+
+	return list, nil
+}
+
+// waitForRebuild polls the cache until another instance stores a fresh list,
+// returning nil when none appears within WaitTimeout
+func (uc *priceListUsecase) waitForRebuild(category string) *domain.PriceList {
+	const pollInterval = 100 * time.Millisecond
+
+	deadline := time.Now().Add(uc.config.WaitTimeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(pollInterval)
+
+		list, ok, err := uc.cacheRepo.Get(category)
+		if err != nil {
+			return nil
+		}
+		if ok && time.Since(list.GeneratedAt) < uc.config.FreshTTL {
+			return list
+		}
+	}
+
+	return nil
+}
+
+// buildPriceList reads the active products and prices them for every level
+func (uc *priceListUsecase) buildPriceList(category string) (*domain.PriceList, error) {
+	var (
+		products []*domain.Product
+		err      error
+	)
+	if category == "" {
+		products, err = uc.productRepo.GetActiveProducts()
+	} else {
+		products, err = uc.productRepo.GetByCategory(category)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load products: %w", err)
+	}
+
+	levelStats, err := uc.userRepo.GetMarkupStatsByLevel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load level markups: %w", err)
+	}
+
+	// Health is decoration; without it the list falls back to stock only
+	health, err := uc.healthUC.GetProductHealth()
+	if err != nil {
+		logger.Warn("Failed to load product health for the price list", logger.ErrorField(err))
+	}
+
+	list := &domain.PriceList{
+		Category:    category,
+		GeneratedAt: time.Now(),
+		Items:       make([]*domain.PriceListItem, 0, len(products)),
+	}
+	for _, product := range products {
+		// The public list is anonymous, so level-restricted products stay off it
+		if !product.IsActive || product.MinLevel != nil {
+			continue
+		}
+
+		item := &domain.PriceListItem{
+			Code:           product.Code,
+			Name:           product.Name,
+			Category:       product.Category,
+			Provider:       product.Provider,
+			Nominal:        product.Nominal,
+			ValidityPeriod: product.ValidityPeriod,
+			Price:          product.SellingPrice,
+			Available:      product.IsUnlimitedStock || product.StockQuantity > 0,
+		}
+		if health != nil {
+			productHealth, ok := health[product.ID]
+			if !ok {
+				productHealth = &domain.ProductHealth{Disrupted: true} // No active supplier
+			}
+			item.Available = item.Available && productHealth.Available
+			item.SuccessRate = productHealth.SuccessRate
+			item.Disrupted = productHealth.Disrupted
+		}
+		for _, stats := range levelStats {
+			if stats.Users == 0 || stats.Level == domain.LevelAdmin {
+				continue
+			}
+			if item.LevelPrices == nil {
+				item.LevelPrices = make(map[string]float64)
+			}
+			lowest := &domain.User{Level: stats.Level, MarkupPercentage: stats.MinMarkup}
+			item.LevelPrices[domain.MapLevelToRole(stats.Level)] = lowest.GetEffectivePrice(product.BasePrice)
+		}
+		list.Items = append(list.Items, item)
+	}
+
+	sort.SliceStable(list.Items, func(i, j int) bool {
+		if list.Items[i].Category != list.Items[j].Category {
+			return list.Items[i].Category < list.Items[j].Category
+		}
+		if list.Items[i].Provider != list.Items[j].Provider {
+			return list.Items[i].Provider < list.Items[j].Provider
+		}
+		return list.Items[i].Price < list.Items[j].Price
+	})
+
+	return list, nil
+}
@@ -0,0 +1,555 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+const defaultPriceHistoryLimit = 100
+
+type pricingUsecase struct {
+	productRepo        domain.ProductRepository
+	productMappingRepo domain.ProductMappingRepository
+	supplierRepo       domain.SupplierRepository
+	priceHistoryRepo   domain.PriceHistoryRepository
+	userRepo           domain.UserRepository
+	adapterFactory     domain.SupplierAdapterFactory
+	routingCache       domain.RoutingCacheRepository
+	eventRepo          domain.EventRepository
+	config             PricingConfig
+}
+
+// PricingConfig defines margin protection parameters
+type PricingConfig struct {
+	// MinMargin is the minimum margin (in Rupiah) between selling price and supplier cost
+	MinMargin float64
+	// MarginAction is applied when the margin is breached: FLAG or DEACTIVATE
+	MarginAction string
+}
+
+// DefaultPricingConfig returns default pricing configuration
+func DefaultPricingConfig() PricingConfig {
+	return PricingConfig{
+		MinMargin:    0,
+		MarginAction: domain.MarginActionFlag,
+	}
+}
+
+// NewPricingUsecase creates a new pricing use case
+func NewPricingUsecase(
+	productRepo domain.ProductRepository,
+	productMappingRepo domain.ProductMappingRepository,
+	supplierRepo domain.SupplierRepository,
+	priceHistoryRepo domain.PriceHistoryRepository,
+	userRepo domain.UserRepository,
+	adapterFactory domain.SupplierAdapterFactory,
+	routingCache domain.RoutingCacheRepository,
+	eventRepo domain.EventRepository,
+	config PricingConfig,
+) domain.PricingUsecase {
+	config.MarginAction = strings.ToUpper(strings.TrimSpace(config.MarginAction))
+	if !domain.IsValidMarginAction(config.MarginAction) {
+		config.MarginAction = DefaultPricingConfig().MarginAction
+	}
+	if config.MinMargin < 0 {
+		config.MinMargin = 0
+	}
+
+	return &pricingUsecase{
+		productRepo:        productRepo,
+		productMappingRepo: productMappingRepo,
+		supplierRepo:       supplierRepo,
+		priceHistoryRepo:   priceHistoryRepo,
+		userRepo:           userRepo,
+		adapterFactory:     adapterFactory,
+		routingCache:       routingCache,
+		eventRepo:          eventRepo,
+		config:             config,
+	}
+}
+
+// RecordPriceChange stores a price change, ignoring no-op changes. Base and
+// selling price changes are also written as product.price_changed events.
+func (uc *pricingUsecase) RecordPriceChange(history *domain.PriceHistory) error {
+	if history == nil || history.OldPrice == history.NewPrice {
+		return nil
+	}
+
+	history.ID = utils.GenerateUUID()
+	history.CreatedAt = time.Now()
+
+	if err := uc.priceHistoryRepo.Create(history); err != nil {
+		return err
+	}
+
+	if history.PriceType != domain.PriceTypeSupplier {
+		uc.publishPriceChange(history)
+	}
+	return nil
+}
+
+// publishPriceChange writes the product.price_changed outbox event; the relay
+// delivers it to the event webhooks
+func (uc *pricingUsecase) publishPriceChange(history *domain.PriceHistory) {
+	if uc.eventRepo == nil {
+		return
+	}
+
+	product, err := uc.productRepo.GetByID(history.ProductID)
+	if err != nil {
+		logger.Error("Failed to load product for price change event",
+			logger.String("product_id", history.ProductID),
+			logger.ErrorField(err),
+		)
+		return
+	}
+
+	event, err := domain.NewPriceChangedEvent(product, history)
+	if err != nil {
+		logger.Error("Failed to build price change event", logger.String("product_id", product.ID), logger.ErrorField(err))
+		return
+	}
+	if err := uc.eventRepo.Create(event); err != nil {
+		logger.Error("Failed to store price change event", logger.String("product_id", product.ID), logger.ErrorField(err))
+	}
+}
+
+// ListPriceChanges lists recent price changes, newest first. Without price
+// types only customer facing prices are listed.
+func (uc *pricingUsecase) ListPriceChanges(filter *domain.PriceChangeFilter) ([]*domain.PriceChange, int, error) {
+	if filter == nil {
+		filter = &domain.PriceChangeFilter{}
+	}
+	if len(filter.PriceTypes) == 0 {
+		filter.PriceTypes = domain.CustomerPriceTypes
+	}
+	for _, priceType := range filter.PriceTypes {
+		if !domain.IsValidPriceType(priceType) {
+			return nil, 0, fmt.Errorf("invalid price type")
+		}
+	}
+	if !filter.Until.IsZero() && !filter.Until.After(filter.Since) {
+		return nil, 0, fmt.Errorf("until must be after since")
+	}
+	filter.Category = domain.NormalizeCatalogCode(filter.Category)
+
+	return uc.priceHistoryRepo.ListChanges(filter)
+}
+
+// GetPriceHistory returns the latest price changes of a product
+func (uc *pricingUsecase) GetPriceHistory(productID string, limit int) ([]*domain.PriceHistory, error) {
+	if _, err := uc.productRepo.GetByID(productID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > defaultPriceHistoryLimit {
+		limit = defaultPriceHistoryLimit
+	}
+	return uc.priceHistoryRepo.ListByProduct(productID, limit)
+}
+
+// SyncSupplierPrices pulls the supplier price list, updates mapping prices that
+// changed, records the changes and runs the margin guard on affected products
+func (uc *pricingUsecase) SyncSupplierPrices(supplierID string) (*domain.PriceSyncResult, error) {
+	supplier, err := uc.supplierRepo.GetByID(supplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	if uc.adapterFactory == nil {
+		return nil, fmt.Errorf("supplier adapter factory not configured")
+	}
+	adapter, err := uc.adapterFactory.GetSupplierAdapter(supplier)
+	if err != nil {
+		return nil, fmt.Errorf("adapter for %s not found: %w", supplier.Code, err)
+	}
+
+	catalog, err := adapter.GetProductCatalog()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s price list: %w", supplier.Code, err)
+	}
+
+	prices := make(map[string]float64, len(catalog))
+	for _, item := range catalog {
+		prices[strings.ToUpper(item.Code)] = catalogCost(item)
+	}
+
+	mappings, err := uc.productMappingRepo.GetBySupplierID(supplier.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get supplier mappings: %w", err)
+	}
+
+	result := &domain.PriceSyncResult{
+		SupplierID:   supplier.ID,
+		SupplierCode: supplier.Code,
+	}
+	affected := make(map[string]bool)
+
+	for _, mapping := range mappings {
+		result.Checked++
+
+		price, ok := prices[strings.ToUpper(mapping.SupplierProductCode)]
+		if !ok || price <= 0 {
+			result.Unmatched++
+			continue
+		}
+		if price == mapping.SupplierPrice {
+			continue
+		}
+
+		oldPrice := mapping.SupplierPrice
+		mapping.SupplierPrice = price
+		mapping.UpdatedAt = time.Now()
+		if err := uc.productMappingRepo.Update(mapping); err != nil {
+			logger.Error("Failed to update synced supplier price",
+				logger.String("mapping_id", mapping.ID),
+				logger.ErrorField(err),
+			)
+			continue
+		}
+		result.Updated++
+		affected[mapping.ProductID] = true
+
+		mappingID := mapping.ID
+		err := uc.RecordPriceChange(&domain.PriceHistory{
+			ProductID:        mapping.ProductID,
+			ProductMappingID: &mappingID,
+			SupplierID:       &supplier.ID,
+			PriceType:        domain.PriceTypeSupplier,
+			OldPrice:         oldPrice,
+			NewPrice:         price,
+			Source:           domain.PriceSourceSync,
+		})
+		if err != nil {
+			logger.Error("Failed to record synced price change",
+				logger.String("mapping_id", mapping.ID),
+				logger.ErrorField(err),
+			)
+		}
+	}
+
+	// Routing compares mapping prices, so cached snapshots are now stale
+	if result.Updated > 0 {
+		invalidateRoutingSupplier(uc.routingCache, supplier.ID)
+	}
+
+	for productID := range affected {
+		check, err := uc.CheckProductMargin(productID)
+		if err != nil {
+			logger.Error("Failed to check product margin after sync",
+				logger.String("product_id", productID),
+				logger.ErrorField(err),
+			)
+			continue
+		}
+		if !check.Breached {
+			continue
+		}
+		if check.Action == domain.MarginActionDeactivate {
+			result.Deactivated++
+		} else {
+			result.Flagged++
+		}
+	}
+
+	logger.Info("Supplier price list synced",
+		logger.String("supplier_code", supplier.Code),
+		logger.Int("checked", result.Checked),
+		logger.Int("updated", result.Updated),
+		logger.Int("unmatched", result.Unmatched),
+		logger.Int("flagged", result.Flagged),
+		logger.Int("deactivated", result.Deactivated),
+	)
+
+	return result, nil
+}
+
+// SyncAllSupplierPrices syncs every active supplier that has a registered adapter
+func (uc *pricingUsecase) SyncAllSupplierPrices() ([]*domain.PriceSyncResult, error) {
+	suppliers, err := uc.supplierRepo.GetActiveSuppliers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get suppliers: %w", err)
+	}
+
+	results := make([]*domain.PriceSyncResult, 0, len(suppliers))
+	for _, supplier := range suppliers {
+		if uc.adapterFactory == nil {
+			break
+		}
+		if _, err := uc.adapterFactory.GetSupplierAdapter(supplier); err != nil {
+			continue
+		}
+
+		result, err := uc.SyncSupplierPrices(supplier.ID)
+		if err != nil {
+			logger.Error("Failed to sync supplier prices",
+				logger.String("supplier_code", supplier.Code),
+				logger.ErrorField(err),
+			)
+			continue
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// CheckProductMargin compares the cheapest active supplier cost with the product
+// selling price, applies the guard action when breached and clears a stale flag
+func (uc *pricingUsecase) CheckProductMargin(productID string) (*domain.MarginCheck, error) {
+	product, err := uc.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	mappings, err := uc.productMappingRepo.GetActiveMappings(productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product mappings: %w", err)
+	}
+
+	var best *domain.ProductMapping
+	for _, mapping := range mappings {
+		if best == nil || mapping.GetEffectivePrice() < best.GetEffectivePrice() {
+			best = mapping
+		}
+	}
+	if best == nil {
+		return &domain.MarginCheck{
+			ProductID:    product.ID,
+			ProductCode:  product.Code,
+			SellingPrice: product.SellingPrice,
+			MinMargin:    uc.config.MinMargin,
+		}, nil
+	}
+
+	check := domain.NewMarginCheck(product, product.SellingPrice, best.GetEffectivePrice(), uc.config.MinMargin)
+	check.SupplierID = best.SupplierID
+
+	if check.Breached {
+		if err := uc.applyGuard(product, check); err != nil {
+			return nil, err
+		}
+		return check, nil
+	}
+
+	if product.MarginFlagged {
+		if err := uc.productRepo.SetMarginFlag(product.ID, false, nil); err != nil {
+			return nil, err
+		}
+		logger.Info("Product margin restored, flag cleared",
+			logger.String("product_id", product.ID),
+			logger.String("product_code", product.Code),
+		)
+	}
+
+	return check, nil
+}
+
+// GuardTransactionMargin checks the selected supplier cost against the actual
+// transaction selling price and applies the guard action when breached
+func (uc *pricingUsecase) GuardTransactionMargin(productID string, mapping *domain.ProductMapping, sellingPrice float64) (*domain.MarginCheck, error) {
+	product, err := uc.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	check := domain.NewMarginCheck(product, sellingPrice, mapping.GetEffectivePrice(), uc.config.MinMargin)
+	check.SupplierID = mapping.SupplierID
+	if !check.Breached {
+		return check, nil
+	}
+
+	if err := uc.applyGuard(product, check); err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+// SimulatePricing computes the margins the hypothetical base and selling prices
+// would give per user level and per supplier mapping. Nothing is persisted and
+// the margin guard is not applied; break-even problems are listed as warnings.
+func (uc *pricingUsecase) SimulatePricing(productID string, input *domain.PriceSimulationInput) (*domain.PriceSimulation, error) {
+	product, err := uc.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	simulation := &domain.PriceSimulation{
+		ProductID:           product.ID,
+		ProductCode:         product.Code,
+		CurrentBasePrice:    product.BasePrice,
+		CurrentSellingPrice: product.SellingPrice,
+		BasePrice:           product.BasePrice,
+		SellingPrice:        product.SellingPrice,
+		MinMargin:           uc.config.MinMargin,
+		Levels:              []*domain.LevelMarginSimulation{},
+		Suppliers:           []*domain.SupplierMarginSimulation{},
+		Warnings:            []string{},
+	}
+	if input != nil && input.BasePrice != nil {
+		simulation.BasePrice = *input.BasePrice
+	}
+	if input != nil && input.SellingPrice != nil {
+		simulation.SellingPrice = *input.SellingPrice
+	}
+	if simulation.BasePrice <= 0 || simulation.SellingPrice <= 0 {
+		return nil, fmt.Errorf("prices must be greater than zero")
+	}
+
+	mappings, err := uc.productMappingRepo.GetByProductID(product.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product mappings: %w", err)
+	}
+
+	hasCost := false
+	for _, mapping := range mappings {
+		cost := mapping.GetEffectivePrice()
+		supplierMargin := &domain.SupplierMarginSimulation{
+			MappingID:           mapping.ID,
+			SupplierID:          mapping.SupplierID,
+			SupplierProductCode: mapping.SupplierProductCode,
+			IsActive:            mapping.IsActive,
+			Cost:                cost,
+			BaseMargin:          simulation.BasePrice - cost,
+			SellingMargin:       simulation.SellingPrice - cost,
+			BelowMinMargin:      cost > simulation.BasePrice-uc.config.MinMargin,
+		}
+		if supplier, err := uc.supplierRepo.GetByID(mapping.SupplierID); err == nil {
+			supplierMargin.SupplierCode = supplier.Code
+		}
+		simulation.Suppliers = append(simulation.Suppliers, supplierMargin)
+
+		if mapping.IsActive && (!hasCost || cost < simulation.SupplierCost) {
+			simulation.SupplierCost = cost
+			hasCost = true
+		}
+		if mapping.IsActive && supplierMargin.BelowMinMargin {
+			simulation.Warnings = append(simulation.Warnings, fmt.Sprintf(
+				"base price %s leaves a margin of %s against %s (cost %s), below the minimum margin %s",
+				utils.FormatAmount(simulation.BasePrice),
+				utils.FormatAmount(supplierMargin.BaseMargin),
+				supplierLabel(supplierMargin),
+				utils.FormatAmount(cost),
+				utils.FormatAmount(uc.config.MinMargin),
+			))
+		}
+	}
+
+	if !hasCost {
+		simulation.Warnings = append(simulation.Warnings, "product has no active supplier mapping, margins cannot be checked")
+	} else {
+		simulation.SellingMargin = simulation.SellingPrice - simulation.SupplierCost
+		if simulation.SupplierCost > simulation.SellingPrice-uc.config.MinMargin {
+			simulation.Warnings = append(simulation.Warnings, fmt.Sprintf(
+				"selling price %s is below break-even: cheapest supplier cost %s plus minimum margin %s",
+				utils.FormatAmount(simulation.SellingPrice),
+				utils.FormatAmount(simulation.SupplierCost),
+				utils.FormatAmount(uc.config.MinMargin),
+			))
+		}
+	}
+
+	levelStats, err := uc.userRepo.GetMarkupStatsByLevel()
+	if err != nil {
+		return nil, err
+	}
+	statsByLevel := make(map[int]*domain.LevelMarkupStats, len(levelStats))
+	for _, stats := range levelStats {
+		statsByLevel[stats.Level] = stats
+	}
+
+	for level := domain.LevelReseller; level <= domain.LevelAdmin; level++ {
+		levelMargin := &domain.LevelMarginSimulation{Level: level}
+		if stats, ok := statsByLevel[level]; ok {
+			levelMargin.Users = stats.Users
+			levelMargin.MinMarkup = stats.MinMarkup
+			levelMargin.MaxMarkup = stats.MaxMarkup
+		}
+
+		// Users pay their markup on the base price, admins the base price itself
+		lowest := &domain.User{Level: level, MarkupPercentage: levelMargin.MinMarkup}
+		highest := &domain.User{Level: level, MarkupPercentage: levelMargin.MaxMarkup}
+		levelMargin.LowestPrice = lowest.GetEffectivePrice(simulation.BasePrice)
+		levelMargin.HighestPrice = highest.GetEffectivePrice(simulation.BasePrice)
+		levelMargin.LowestMargin = levelMargin.LowestPrice - simulation.SupplierCost
+		levelMargin.HighestMargin = levelMargin.HighestPrice - simulation.SupplierCost
+		simulation.Levels = append(simulation.Levels, levelMargin)
+
+		if levelMargin.Users == 0 {
+			continue
+		}
+		if hasCost && levelMargin.LowestMargin < uc.config.MinMargin {
+			levelMargin.BelowMinMargin = true
+			simulation.Warnings = append(simulation.Warnings, fmt.Sprintf(
+				"level %d users paying %s leave a margin of %s, below the minimum margin %s",
+				level,
+				utils.FormatAmount(levelMargin.LowestPrice),
+				utils.FormatAmount(levelMargin.LowestMargin),
+				utils.FormatAmount(uc.config.MinMargin),
+			))
+		}
+		if levelMargin.LowestPrice < product.MinPrice || levelMargin.HighestPrice > product.MaxTransactionAmount {
+			simulation.Warnings = append(simulation.Warnings, fmt.Sprintf(
+				"level %d prices %s - %s fall outside the allowed range %s - %s, those transactions would be rejected",
+				level,
+				utils.FormatAmount(levelMargin.LowestPrice),
+				utils.FormatAmount(levelMargin.HighestPrice),
+				utils.FormatAmount(product.MinPrice),
+				utils.FormatAmount(product.MaxTransactionAmount),
+			))
+		}
+	}
+
+	return simulation, nil
+}
+
+func supplierLabel(simulation *domain.SupplierMarginSimulation) string {
+	if simulation.SupplierCode != "" {
+		return simulation.SupplierCode
+	}
+	return simulation.SupplierID
+}
+
+func (uc *pricingUsecase) applyGuard(product *domain.Product, check *domain.MarginCheck) error {
+	check.Action = uc.config.MarginAction
+
+	reason := fmt.Sprintf("supplier cost %s exceeds selling price %s minus minimum margin %s",
+		utils.FormatAmount(check.SupplierCost),
+		utils.FormatAmount(check.SellingPrice),
+		utils.FormatAmount(check.MinMargin),
+	)
+	if err := uc.productRepo.SetMarginFlag(product.ID, true, &reason); err != nil {
+		return err
+	}
+
+	if check.Action == domain.MarginActionDeactivate && product.IsActive {
+		if err := uc.productRepo.UpdateStatus(product.ID, false); err != nil {
+			return err
+		}
+	}
+
+	logger.Warn("Product margin below minimum",
+		logger.String("product_id", product.ID),
+		logger.String("product_code", product.Code),
+		logger.String("supplier_id", check.SupplierID),
+		logger.Float64("selling_price", check.SellingPrice),
+		logger.Float64("supplier_cost", check.SupplierCost),
+		logger.Float64("min_margin", check.MinMargin),
+		logger.String("action", check.Action),
+	)
+
+	return nil
+}
+
+// catalogCost returns what the supplier charges us for a catalog item. Supplier
+// adapters report their price to us as the product selling price.
+func catalogCost(item *domain.Product) float64 {
+	if item.SellingPrice > 0 {
+		return item.SellingPrice
+	}
+	return item.BasePrice
+}
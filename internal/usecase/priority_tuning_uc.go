@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const (
+	// latencyReferenceMs is the latency that halves the latency score
+	latencyReferenceMs = 3000.0
+
+	// Weight of each window and factor when computing the performance score
+	shortWindowWeight  = 0.6
+	longWindowWeight   = 0.4
+	successRateWeight  = 0.7
+	latencyScoreWeight = 0.3
+)
+
+type priorityTuningUsecase struct {
+	productMappingRepo domain.ProductMappingRepository
+	config             PriorityTuningConfig
+}
+
+// PriorityTuningConfig defines rolling windows used by the priority tuning job
+type PriorityTuningConfig struct {
+	ShortWindow time.Duration
+	LongWindow  time.Duration
+	MinSamples  int
+}
+
+// DefaultPriorityTuningConfig returns default priority tuning configuration
+func DefaultPriorityTuningConfig() PriorityTuningConfig {
+	return PriorityTuningConfig{
+		ShortWindow: time.Hour,
+		LongWindow:  24 * time.Hour,
+		MinSamples:  10,
+	}
+}
+
+// NewPriorityTuningUsecase creates a new priority tuning use case
+func NewPriorityTuningUsecase(
+	productMappingRepo domain.ProductMappingRepository,
+	config PriorityTuningConfig,
+) domain.PriorityTuningUsecase {
+	defaults := DefaultPriorityTuningConfig()
+	if config.ShortWindow <= 0 {
+		config.ShortWindow = defaults.ShortWindow
+	}
+	if config.LongWindow <= 0 {
+		config.LongWindow = defaults.LongWindow
+	}
+	if config.MinSamples <= 0 {
+		config.MinSamples = defaults.MinSamples
+	}
+
+	return &priorityTuningUsecase{
+		productMappingRepo: productMappingRepo,
+		config:             config,
+	}
+}
+
+// TuneMappingPriorities recomputes effective priority of every active mapping
+// from its rolling performance and returns the number of mappings updated.
+// Mappings without enough samples have their effective priority cleared, so a
+// mapping that stopped getting traffic is ranked by its admin-set priority
+// instead of a stale score.
+func (uc *priorityTuningUsecase) TuneMappingPriorities() (int, error) {
+	now := time.Now()
+
+	shortPerf, err := uc.loadPerformance(now.Add(-uc.config.ShortWindow))
+	if err != nil {
+		return 0, err
+	}
+
+	longPerf, err := uc.loadPerformance(now.Add(-uc.config.LongWindow))
+	if err != nil {
+		return 0, err
+	}
+
+	mappings, err := uc.productMappingRepo.GetAllActiveMappings()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get active mappings: %w", err)
+	}
+
+	updated, cleared := 0, 0
+	for _, mapping := range mappings {
+		key := mapping.ProductID + ":" + mapping.SupplierID
+
+		var stored *float64
+		if effectivePriority, ok := uc.calculateEffectivePriority(shortPerf[key], longPerf[key]); ok {
+			stored = &effectivePriority
+		} else if mapping.EffectivePriority == nil {
+			continue
+		}
+
+		if err := uc.productMappingRepo.UpdateEffectivePriority(mapping.ID, stored); err != nil {
+			logger.Warn("Failed to store effective priority",
+				logger.String("mapping_id", mapping.ID),
+				logger.ErrorField(err),
+			)
+			continue
+		}
+		if stored == nil {
+			cleared++
+		}
+		updated++
+	}
+
+	logger.Info("Mapping priorities tuned",
+		logger.Int("active_mappings", len(mappings)),
+		logger.Int("updated_mappings", updated),
+		logger.Int("cleared_mappings", cleared),
+	)
+
+	return updated, nil
+}
+
+// loadPerformance loads aggregated performance keyed by product and supplier
+func (uc *priorityTuningUsecase) loadPerformance(since time.Time) (map[string]*domain.MappingPerformance, error) {
+	performances, err := uc.productMappingRepo.GetPerformanceSince(since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mapping performance: %w", err)
+	}
+
+	result := make(map[string]*domain.MappingPerformance, len(performances))
+	for _, perf := range performances {
+		result[perf.ProductID+":"+perf.SupplierID] = perf
+	}
+	return result, nil
+}
+
+// calculateEffectivePriority converts rolling performance into a priority value
+// on the effective priority scale (lower is better)
+func (uc *priorityTuningUsecase) calculateEffectivePriority(short, long *domain.MappingPerformance) (float64, bool) {
+	hasShort := short != nil && short.TotalCount >= uc.config.MinSamples
+	hasLong := long != nil && long.TotalCount >= uc.config.MinSamples
+
+	var successRate, avgLatencyMs float64
+	switch {
+	case hasShort && hasLong:
+		successRate = short.GetSuccessRate()*shortWindowWeight + long.GetSuccessRate()*longWindowWeight
+		avgLatencyMs = short.AvgLatencyMs*shortWindowWeight + long.AvgLatencyMs*longWindowWeight
+	case hasShort:
+		successRate = short.GetSuccessRate()
+		avgLatencyMs = short.AvgLatencyMs
+	case hasLong:
+		successRate = long.GetSuccessRate()
+		avgLatencyMs = long.AvgLatencyMs
+	default:
+		return 0, false
+	}
+
+	latencyScore := 1.0 / (1.0 + avgLatencyMs/latencyReferenceMs)
+	performanceScore := (successRate/100.0)*successRateWeight + latencyScore*latencyScoreWeight
+
+	effectivePriority := domain.EffectivePriorityBest + (1.0-performanceScore)*(domain.EffectivePriorityWorst-domain.EffectivePriorityBest)
+	return math.Round(effectivePriority*10000) / 10000, true
+}
@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+)
+
+// fakeTuningMappingRepo implements what priority tuning needs of the
+// product mapping repository
+type fakeTuningMappingRepo struct {
+	domain.ProductMappingRepository
+	mappings     []*domain.ProductMapping
+	performances []*domain.MappingPerformance
+	stored       map[string]*float64
+}
+
+func (r *fakeTuningMappingRepo) GetAllActiveMappings() ([]*domain.ProductMapping, error) {
+	return r.mappings, nil
+}
+
+func (r *fakeTuningMappingRepo) GetPerformanceSince(since time.Time) ([]*domain.MappingPerformance, error) {
+	return r.performances, nil
+}
+
+func (r *fakeTuningMappingRepo) UpdateEffectivePriority(id string, effectivePriority *float64) error {
+	r.stored[id] = effectivePriority
+	return nil
+}
+
+func floatPtr(f float64) *float64 { return &f }
+
+func TestTuneMappingPriorities(t *testing.T) {
+	repo := &fakeTuningMappingRepo{
+		mappings: []*domain.ProductMapping{
+			{ID: "busy", ProductID: "p1", SupplierID: "s1", EffectivePriority: floatPtr(5)},
+			{ID: "stale", ProductID: "p1", SupplierID: "s2", EffectivePriority: floatPtr(2)},
+			{ID: "untuned", ProductID: "p1", SupplierID: "s3"},
+		},
+		performances: []*domain.MappingPerformance{
+			{ProductID: "p1", SupplierID: "s1", TotalCount: 20, SuccessCount: 20},
+			{ProductID: "p1", SupplierID: "s2", TotalCount: 3, SuccessCount: 3},
+		},
+		stored: map[string]*float64{},
+	}
+
+	uc := NewPriorityTuningUsecase(repo, DefaultPriorityTuningConfig())
+	updated, err := uc.TuneMappingPriorities()
+	if err != nil {
+		t.Fatalf("TuneMappingPriorities() unexpected error: %v", err)
+	}
+	if updated != 2 {
+		t.Errorf("TuneMappingPriorities() = %d, want 2", updated)
+	}
+
+	if got, ok := repo.stored["busy"]; !ok || got == nil || *got < domain.EffectivePriorityBest || *got > domain.EffectivePriorityWorst {
+		t.Errorf("busy mapping stored %v, want a priority within the effective range", got)
+	}
+	if got, ok := repo.stored["stale"]; !ok || got != nil {
+		t.Errorf("stale mapping stored %v, want it cleared", got)
+	}
+	if _, ok := repo.stored["untuned"]; ok {
+		t.Errorf("untuned mapping without samples was written")
+	}
+}
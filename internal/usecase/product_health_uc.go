@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+type productHealthUsecase struct {
+	mappingRepo domain.ProductMappingRepository
+	config      ProductHealthConfig
+
+	mu        sync.Mutex
+	cached    map[string]*domain.ProductHealth
+	expiresAt time.Time
+}
+
+// ProductHealthConfig defines how product health is derived from the
+// transactions of each product's mappings
+type ProductHealthConfig struct {
+	// Window is the rolling period whose transactions give the success rate
+	Window time.Duration
+	// MinSamples finished transactions a product needs before its success
+	// rate is reported
+	MinSamples int
+	// DisruptedBelow is the success rate percentage under which a product is
+	// marked disrupted
+	DisruptedBelow float64
+	// CacheTTL is how long computed health is served
+	CacheTTL time.Duration
+}
+
+// DefaultProductHealthConfig returns default product health configuration
+func DefaultProductHealthConfig() ProductHealthConfig {
+	return ProductHealthConfig{
+		Window:         time.Hour,
+		MinSamples:     10,
+		DisruptedBelow: 80,
+		CacheTTL:       time.Minute,
+	}
+}
+
+// NewProductHealthUsecase creates a new product health use case
+func NewProductHealthUsecase(mappingRepo domain.ProductMappingRepository, config ProductHealthConfig) domain.ProductHealthUsecase {
+	defaults := DefaultProductHealthConfig()
+	if config.Window <= 0 {
+		config.Window = defaults.Window
+	}
+	if config.MinSamples <= 0 {
+		config.MinSamples = defaults.MinSamples
+	}
+	if config.DisruptedBelow <= 0 || config.DisruptedBelow > 100 {
+		config.DisruptedBelow = defaults.DisruptedBelow
+	}
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = defaults.CacheTTL
+	}
+
+	return &productHealthUsecase{
+		mappingRepo: mappingRepo,
+		config:      config,
+	}
+}
+
+// GetProductHealth returns the cached health, recomputing it once expired.
+// When recomputing fails the previous health keeps being served.
+func (uc *productHealthUsecase) GetProductHealth() (map[string]*domain.ProductHealth, error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	now := time.Now()
+	if uc.cached != nil && now.Before(uc.expiresAt) {
+		return uc.cached, nil
+	}
+
+	health, err := uc.computeHealth(now)
+	if err != nil {
+		if uc.cached != nil {
+			logger.Warn("Failed to refresh product health, serving the previous one", logger.ErrorField(err))
+			return uc.cached, nil
+		}
+		return nil, err
+	}
+
+	uc.cached = health
+	uc.expiresAt = now.Add(uc.config.CacheTTL)
+	return health, nil
+}
+
+// computeHealth sums the window's transactions of every active mapping of a
+// product; transactions of mappings since deactivated no longer count
+func (uc *productHealthUsecase) computeHealth(now time.Time) (map[string]*domain.ProductHealth, error) {
+	mappings, err := uc.mappingRepo.GetAllActiveMappings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load product mappings: %w", err)
+	}
+	performances, err := uc.mappingRepo.GetPerformanceSince(now.Add(-uc.config.Window))
+	if err != nil {
+		return nil, err
+	}
+
+	active := make(map[string]bool, len(mappings))
+	health := make(map[string]*domain.ProductHealth)
+	for _, mapping := range mappings {
+		active[mapping.ProductID+":"+mapping.SupplierID] = true
+		entry, ok := health[mapping.ProductID]
+		if !ok {
+			entry = &domain.ProductHealth{}
+			health[mapping.ProductID] = entry
+		}
+		if mapping.StockStatus != domain.StockStatusOutOfStock {
+			entry.Available = true
+		}
+	}
+
+	successes := make(map[string]int, len(health))
+	for _, performance := range performances {
+		if !active[performance.ProductID+":"+performance.SupplierID] {
+			continue
+		}
+		health[performance.ProductID].Samples += performance.TotalCount
+		successes[performance.ProductID] += performance.SuccessCount
+	}
+
+	for productID, entry := range health {
+		if entry.Samples >= uc.config.MinSamples {
+			rate := math.Round(float64(successes[productID])/float64(entry.Samples)*10000) / 100
+			entry.SuccessRate = &rate
+		}
+		entry.Disrupted = !entry.Available || (entry.SuccessRate != nil && *entry.SuccessRate < uc.config.DisruptedBelow)
+	}
+
+	return health, nil
+}
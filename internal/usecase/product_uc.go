@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -15,6 +16,8 @@ type productUsecase struct {
 	productMappingRepo domain.ProductMappingRepository
 	supplierRepo       domain.SupplierRepository
 	smartRoutingUC     *smartRoutingUsecase
+	pricingUC          domain.PricingUsecase
+	catalogUC          domain.CatalogUsecase
 }
 
 func NewProductUsecase(
@@ -22,12 +25,16 @@ func NewProductUsecase(
 	productMappingRepo domain.ProductMappingRepository,
 	supplierRepo domain.SupplierRepository,
 	smartRoutingUC *smartRoutingUsecase,
+	pricingUC domain.PricingUsecase,
+	catalogUC domain.CatalogUsecase,
 ) domain.ProductUsecase {
 	return &productUsecase{
 		productRepo:        productRepo,
 		productMappingRepo: productMappingRepo,
 		supplierRepo:       supplierRepo,
 		smartRoutingUC:     smartRoutingUC,
+		pricingUC:          pricingUC,
+		catalogUC:          catalogUC,
 	}
 }
 
@@ -40,14 +47,24 @@ func (uc *productUsecase) CreateProduct(product *domain.Product) error {
 		return fmt.Errorf("product code and name are required")
 	}
 
-	if !domain.IsValidCategory(product.Category) {
-		return fmt.Errorf("invalid product category")
+	product.Category = domain.NormalizeCatalogCode(product.Category)
+	if err := uc.catalogUC.ValidateCategory(product.Category); err != nil {
+		return err
+	}
+
+	product.Provider = domain.NormalizeCatalogCode(product.Provider)
+	if err := uc.catalogUC.ValidateProvider(product.Provider); err != nil {
+		return err
 	}
 
 	if !domain.IsValidType(product.Type) {
 		return fmt.Errorf("invalid product type")
 	}
 
+	if err := product.ApplyDestinationOverride(nil).Check(); err != nil {
+		return err
+	}
+
 	product.ID = utils.GenerateUUID()
 	product.CreatedAt = time.Now()
 	product.UpdatedAt = time.Now()
@@ -55,7 +72,7 @@ func (uc *productUsecase) CreateProduct(product *domain.Product) error {
 	return uc.productRepo.Create(product)
 }
 
-func (uc *productUsecase) UpdateProduct(id string, updates *domain.Product) error {
+func (uc *productUsecase) UpdateProduct(id string, updates *domain.Product, changedBy *string) error {
 	if updates == nil {
 		return fmt.Errorf("update payload is required")
 	}
@@ -72,13 +89,18 @@ func (uc *productUsecase) UpdateProduct(id string, updates *domain.Product) erro
 		product.Description = updates.Description
 	}
 	if updates.Category != "" {
-		if !domain.IsValidCategory(updates.Category) {
-			return fmt.Errorf("invalid product category")
+		category := domain.NormalizeCatalogCode(updates.Category)
+		if err := uc.catalogUC.ValidateCategory(category); err != nil {
+			return err
 		}
-		product.Category = updates.Category
+		product.Category = category
 	}
 	if updates.Provider != "" {
-		product.Provider = updates.Provider
+		provider := domain.NormalizeCatalogCode(updates.Provider)
+		if err := uc.catalogUC.ValidateProvider(provider); err != nil {
+			return err
+		}
+		product.Provider = provider
 	}
 	if updates.Type != "" {
 		if !domain.IsValidType(updates.Type) {
@@ -86,6 +108,7 @@ func (uc *productUsecase) UpdateProduct(id string, updates *domain.Product) erro
 		}
 		product.Type = updates.Type
 	}
+	oldBasePrice, oldSellingPrice := product.BasePrice, product.SellingPrice
 	if updates.BasePrice > 0 {
 		product.BasePrice = updates.BasePrice
 	}
@@ -115,7 +138,31 @@ func (uc *productUsecase) UpdateProduct(id string, updates *domain.Product) erro
 	}
 
 	product.UpdatedAt = time.Now()
-	return uc.productRepo.Update(product)
+	if err := uc.productRepo.Update(product); err != nil {
+		return err
+	}
+
+	uc.recordPriceChange(&domain.PriceHistory{
+		ProductID: product.ID,
+		PriceType: domain.PriceTypeBase,
+		OldPrice:  oldBasePrice,
+		NewPrice:  product.BasePrice,
+		Source:    domain.PriceSourceAdmin,
+		ChangedBy: changedBy,
+	})
+	uc.recordPriceChange(&domain.PriceHistory{
+		ProductID: product.ID,
+		PriceType: domain.PriceTypeSelling,
+		OldPrice:  oldSellingPrice,
+		NewPrice:  product.SellingPrice,
+		Source:    domain.PriceSourceAdmin,
+		ChangedBy: changedBy,
+	})
+	if oldSellingPrice != product.SellingPrice {
+		uc.checkMargin(product.ID)
+	}
+
+	return nil
 }
 
 func (uc *productUsecase) ListProducts(filter *domain.ProductFilter) ([]*domain.Product, int, error) {
@@ -149,8 +196,44 @@ func (uc *productUsecase) GetActiveProducts() ([]*domain.Product, error) {
 	return uc.productRepo.GetActiveProducts()
 }
 
-func (uc *productUsecase) SearchProducts(query string) ([]*domain.Product, error) {
-	return uc.productRepo.Search(query)
+// SearchProducts returns active products matching query by relevance, with
+// the query terms highlighted in code and name
+func (uc *productUsecase) SearchProducts(query string, limit int) ([]*domain.ProductSearchResult, error) {
+	query = strings.TrimSpace(query)
+	if len([]rune(query)) < domain.MinProductSearchLength {
+		return nil, fmt.Errorf("search query too short")
+	}
+	terms := domain.ProductSearchTerms(query)
+	if len(terms) == 0 {
+		return []*domain.ProductSearchResult{}, nil
+	}
+
+	if limit <= 0 {
+		limit = domain.DefaultProductSearchLimit
+	}
+	if limit > domain.MaxProductSearchLimit {
+		limit = domain.MaxProductSearchLimit
+	}
+
+	results, err := uc.productRepo.Search(query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		highlights := make(map[string]string)
+		if code, ok := domain.HighlightTerms(result.Code, terms); ok {
+			highlights["code"] = code
+		}
+		if name, ok := domain.HighlightTerms(result.Name, terms); ok {
+			highlights["name"] = name
+		}
+		if len(highlights) > 0 {
+			result.Highlights = highlights
+		}
+	}
+
+	return results, nil
 }
 
 func (uc *productUsecase) ToggleProductStatus(id string, isActive bool) error {
@@ -161,9 +244,60 @@ func (uc *productUsecase) UpdateProductStock(id string, stockQuantity int, isUnl
 	if stockQuantity < 0 {
 		return fmt.Errorf("stock quantity cannot be negative")
 	}
+
+	// The stock of a voucher sold from codes is the count of its available codes
+	product, err := uc.productRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if product.IsStockedVoucher() {
+		return fmt.Errorf("stock of a voucher stock product follows its uploaded codes")
+	}
+
 	return uc.productRepo.UpdateStock(id, stockQuantity, isUnlimited)
 }
 
+func (uc *productUsecase) SetDestinationOverride(id string, pattern *string, minLength, maxLength *int, hint *string) (*domain.Product, error) {
+	product, err := uc.productRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	product.DestinationPattern = pattern
+	product.DestinationMinLength = minLength
+	product.DestinationMaxLength = maxLength
+	product.DestinationHint = hint
+	if err := product.ApplyDestinationOverride(nil).Check(); err != nil {
+		return nil, err
+	}
+
+	product.UpdatedAt = time.Now()
+	if err := uc.productRepo.Update(product); err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+func (uc *productUsecase) SetMinLevel(id string, minLevel *int) (*domain.Product, error) {
+	if minLevel != nil && (*minLevel < domain.LevelReseller || *minLevel > domain.LevelAdmin) {
+		return nil, fmt.Errorf("invalid minimum level")
+	}
+
+	product, err := uc.productRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	product.MinLevel = minLevel
+	product.UpdatedAt = time.Now()
+	if err := uc.productRepo.Update(product); err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
 func (uc *productUsecase) GetBestSupplier(productID string) (*domain.ProductMapping, error) {
 	mappings, err := uc.productMappingRepo.GetActiveMappings(productID)
 	if err != nil {
@@ -175,16 +309,40 @@ func (uc *productUsecase) GetBestSupplier(productID string) (*domain.ProductMapp
 	return mappings[0], nil
 }
 
-func (uc *productUsecase) UpdateProductMapping(mapping *domain.ProductMapping) error {
+func (uc *productUsecase) UpdateProductMapping(mapping *domain.ProductMapping, changedBy *string) error {
 	if mapping == nil || mapping.ID == "" {
 		return fmt.Errorf("mapping payload invalid")
 	}
+
+	existing, err := uc.productMappingRepo.GetByID(mapping.ID)
+	if err != nil {
+		return err
+	}
+
 	mapping.UpdatedAt = time.Now()
 	if err := uc.productMappingRepo.Update(mapping); err != nil {
 		return err
 	}
 
-	uc.refreshRoutingCache(mapping.ProductID)
+	if mapping.SupplierPrice != existing.SupplierPrice {
+		mappingID := existing.ID
+		supplierID := existing.SupplierID
+		uc.recordPriceChange(&domain.PriceHistory{
+			ProductID:        existing.ProductID,
+			ProductMappingID: &mappingID,
+			SupplierID:       &supplierID,
+			PriceType:        domain.PriceTypeSupplier,
+			OldPrice:         existing.SupplierPrice,
+			NewPrice:         mapping.SupplierPrice,
+			Source:           domain.PriceSourceAdmin,
+			ChangedBy:        changedBy,
+		})
+	}
+	if mapping.GetEffectivePrice() != existing.GetEffectivePrice() {
+		uc.checkMargin(existing.ProductID)
+	}
+
+	uc.refreshRoutingCache(existing.ProductID)
 	return nil
 }
 
@@ -240,6 +398,87 @@ func (uc *productUsecase) DeleteProductMapping(id string) error {
 	return nil
 }
 
+// ReorderProductMappings sets mapping priorities from their order in
+// mappingIDs. The list must hold every mapping of the product exactly once.
+func (uc *productUsecase) ReorderProductMappings(productID string, mappingIDs []string) ([]*domain.ProductMapping, error) {
+	if len(mappingIDs) == 0 {
+		return nil, fmt.Errorf("mapping_ids is required")
+	}
+	if _, err := uc.productRepo.GetByID(productID); err != nil {
+		return nil, err
+	}
+
+	mappings, err := uc.productMappingRepo.GetByProductID(productID)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(mappings))
+	for _, mapping := range mappings {
+		known[mapping.ID] = true
+	}
+
+	seen := make(map[string]bool, len(mappingIDs))
+	for _, id := range mappingIDs {
+		switch {
+		case seen[id]:
+			return nil, fmt.Errorf("mapping %s is listed more than once", id)
+		case !known[id]:
+			return nil, fmt.Errorf("mapping %s does not belong to this product", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) != len(known) {
+		return nil, fmt.Errorf("mapping list is missing %d mapping(s) of the product", len(known)-len(seen))
+	}
+
+	if err := uc.productMappingRepo.ReorderPriorities(productID, mappingIDs); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Product mappings reordered",
+		logger.String("product_id", productID),
+		logger.Int("count", len(mappingIDs)),
+	)
+
+	uc.refreshRoutingCache(productID)
+
+	reordered, err := uc.productMappingRepo.GetByProductID(productID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(reordered, func(i, j int) bool {
+		return reordered[i].Priority < reordered[j].Priority
+	})
+	return reordered, nil
+}
+
+func (uc *productUsecase) recordPriceChange(history *domain.PriceHistory) {
+	if uc.pricingUC == nil {
+		return
+	}
+	if err := uc.pricingUC.RecordPriceChange(history); err != nil {
+		logger.Warn("Failed to record price change",
+			logger.String("product_id", history.ProductID),
+			logger.String("price_type", history.PriceType),
+			logger.ErrorField(err),
+		)
+	}
+}
+
+func (uc *productUsecase) checkMargin(productID string) {
+	if uc.pricingUC == nil || productID == "" {
+		return
+	}
+	if _, err := uc.pricingUC.CheckProductMargin(productID); err != nil {
+		logger.Warn("Product margin check failed",
+			logger.String("product_id", productID),
+			logger.ErrorField(err),
+		)
+	}
+}
+
+// refreshRoutingCache reloads the cached routing snapshot of a product after
+// its mappings changed
 func (uc *productUsecase) refreshRoutingCache(productID string) {
 	if uc.smartRoutingUC == nil {
 		return
@@ -248,8 +487,8 @@ func (uc *productUsecase) refreshRoutingCache(productID string) {
 		return
 	}
 
-	if _, err := uc.smartRoutingUC.GetBestSupplier(productID, nil); err != nil {
-		logger.Warn("Smart routing refresh failed",
+	if err := uc.smartRoutingUC.RefreshRoutingCache(productID); err != nil {
+		logger.Warn("Routing cache refresh failed",
 			logger.String("product_id", productID),
 			logger.ErrorField(err),
 		)
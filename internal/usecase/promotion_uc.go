@@ -0,0 +1,308 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+type promotionUsecase struct {
+	promotionRepo   domain.PromotionRepository
+	transactionRepo domain.TransactionRepository
+	productRepo     domain.ProductRepository
+	userRepo        domain.UserRepository
+	catalogUC       domain.CatalogUsecase
+	unitOfWork      domain.UnitOfWork
+}
+
+// NewPromotionUsecase creates a new cashback promotion use case
+func NewPromotionUsecase(
+	promotionRepo domain.PromotionRepository,
+	transactionRepo domain.TransactionRepository,
+	productRepo domain.ProductRepository,
+	userRepo domain.UserRepository,
+	catalogUC domain.CatalogUsecase,
+	unitOfWork domain.UnitOfWork,
+) domain.PromotionUsecase {
+	return &promotionUsecase{
+		promotionRepo:   promotionRepo,
+		transactionRepo: transactionRepo,
+		productRepo:     productRepo,
+		userRepo:        userRepo,
+		catalogUC:       catalogUC,
+		unitOfWork:      unitOfWork,
+	}
+}
+
+// CreatePromotion validates and stores a new promotion
+func (uc *promotionUsecase) CreatePromotion(promotion *domain.Promotion) error {
+	if promotion == nil {
+		return fmt.Errorf("promotion payload is required")
+	}
+
+	if err := uc.normalizePromotion(promotion); err != nil {
+		return err
+	}
+
+	promotion.ID = utils.GenerateUUID()
+	promotion.Spent = 0
+	promotion.CreatedAt = time.Now()
+	promotion.UpdatedAt = time.Now()
+
+	return uc.promotionRepo.Create(promotion)
+}
+
+// UpdatePromotion validates and replaces the mutable fields of a promotion.
+// Cashback already paid is kept.
+func (uc *promotionUsecase) UpdatePromotion(promotion *domain.Promotion) (*domain.Promotion, error) {
+	if promotion == nil {
+		return nil, fmt.Errorf("promotion payload is required")
+	}
+
+	existing, err := uc.promotionRepo.GetByID(promotion.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.normalizePromotion(promotion); err != nil {
+		return nil, err
+	}
+
+	promotion.Spent = existing.Spent
+	promotion.CreatedBy = existing.CreatedBy
+	promotion.CreatedAt = existing.CreatedAt
+	promotion.UpdatedAt = time.Now()
+
+	if err := uc.promotionRepo.Update(promotion); err != nil {
+		return nil, err
+	}
+
+	return promotion, nil
+}
+
+// GetPromotion returns a promotion by ID
+func (uc *promotionUsecase) GetPromotion(id string) (*domain.Promotion, error) {
+	return uc.promotionRepo.GetByID(id)
+}
+
+// ListPromotions lists all promotions
+func (uc *promotionUsecase) ListPromotions() ([]*domain.Promotion, error) {
+	return uc.promotionRepo.List(false)
+}
+
+// ApplyCashback pays the cashback of the best promotion a successful
+// transaction matches. Promotions are matched on the order time, so an order
+// placed in the period earns cashback even if it succeeds after the end.
+func (uc *promotionUsecase) ApplyCashback(transactionID string) (*domain.PromotionCashback, error) {
+	transaction, err := uc.transactionRepo.GetByID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if transaction.Status != domain.StatusSuccess {
+		return nil, nil
+	}
+
+	existing, err := uc.promotionRepo.GetCashbackByTransactionID(transaction.ID)
+	if err != nil || existing != nil {
+		return existing, err
+	}
+
+	promotions, err := uc.promotionRepo.List(true)
+	if err != nil || len(promotions) == 0 {
+		return nil, err
+	}
+
+	product, err := uc.productRepo.GetByID(transaction.ProductID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product for cashback: %w", err)
+	}
+	user, err := uc.userRepo.GetByID(transaction.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user for cashback: %w", err)
+	}
+
+	promotion, _ := domain.SelectPromotion(promotions, product.Category, user.Level, transaction.CreatedAt, transaction.SellingPrice)
+	if promotion == nil {
+		return nil, nil
+	}
+
+	var cashback *domain.PromotionCashback
+	err = uc.unitOfWork.Do(func(repos domain.TxRepositories) error {
+		// A refund locks the buyer too, so it cannot slip in between
+		if err := repos.Transfers().LockUsers(user.ID); err != nil {
+			return err
+		}
+		current, err := repos.Transactions().GetByID(transaction.ID)
+		if err != nil {
+			return err
+		}
+		if current.Status != domain.StatusSuccess {
+			return nil
+		}
+		existing, err := repos.Promotions().GetCashbackByTransactionID(transaction.ID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			cashback = existing
+			return nil
+		}
+
+		// Recompute under the lock, the budget left may have shrunk
+		locked, err := repos.Promotions().GetByIDForUpdate(promotion.ID)
+		if err != nil {
+			return err
+		}
+		if !locked.Matches(product.Category, user.Level, transaction.CreatedAt) {
+			return nil
+		}
+		amount := locked.Calculate(transaction.SellingPrice)
+		if amount <= 0 {
+			return nil
+		}
+
+		buyer, err := repos.Users().GetByID(user.ID)
+		if err != nil {
+			return err
+		}
+
+		refType := domain.ReferenceTypeCashback
+		mutation := &domain.Mutation{
+			ID:            utils.GenerateUUID(),
+			UserID:        buyer.ID,
+			Type:          domain.MutationTypeDebit, // Debit = money in
+			Amount:        amount,
+			BalanceBefore: buyer.Balance,
+			BalanceAfter:  buyer.Balance + amount,
+			Description:   fmt.Sprintf("Cashback %s transaksi %s", locked.Name, transaction.TrxCode),
+			ReferenceType: &refType,
+			ReferenceID:   &transaction.ID,
+			CreatedAt:     time.Now(),
+		}
+		domain.SystemActor(domain.SystemActorCashback).Stamp(mutation)
+		if err := persistBalanceMutation(repos, mutation); err != nil {
+			return err
+		}
+		if err := repos.Users().UpdateBalance(buyer.ID, mutation.BalanceAfter); err != nil {
+			return err
+		}
+
+		cashback = &domain.PromotionCashback{
+			ID:                utils.GenerateUUID(),
+			PromotionID:       locked.ID,
+			TransactionID:     transaction.ID,
+			UserID:            buyer.ID,
+			TransactionAmount: transaction.SellingPrice,
+			Amount:            amount,
+			MutationID:        mutation.ID,
+			CreatedAt:         time.Now(),
+		}
+		if err := repos.Promotions().CreateCashback(cashback); err != nil {
+			return err
+		}
+		if err := repos.Promotions().AddSpent(locked.ID, amount); err != nil {
+			return err
+		}
+		return repos.Timeline().Append(domain.NewTransactionTimelineEntry(current, domain.TimelineCashbackPaid, "Cashback paid", map[string]interface{}{
+			"promotion_id":  locked.ID,
+			"amount":        amount,
+			"balance_after": mutation.BalanceAfter,
+		}))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply cashback: %w", err)
+	}
+
+	if cashback != nil {
+		logger.Info("Cashback paid",
+			logger.String("trx_id", transaction.ID),
+			logger.String("promotion_id", cashback.PromotionID),
+			logger.Float64("amount", cashback.Amount),
+		)
+	}
+
+	return cashback, nil
+}
+
+// GetPromotionReport sums up the cashback paid per promotion between the
+// calendar dates of startDate and endDate
+func (uc *promotionUsecase) GetPromotionReport(startDate, endDate time.Time) (*domain.PromotionReport, error) {
+	start := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, startDate.Location())
+	end := time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, endDate.Location()).AddDate(0, 0, 1)
+	if end.Before(start) {
+		return nil, fmt.Errorf("end date must not be before start date")
+	}
+	if end.After(start.AddDate(0, 0, maxReportDays)) {
+		return nil, fmt.Errorf("report range too large")
+	}
+
+	performance, err := uc.promotionRepo.GetPerformance(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.PromotionReport{
+		StartDate:  start,
+		EndDate:    end,
+		Promotions: performance,
+	}
+	for _, promotion := range performance {
+		report.Transactions += promotion.Transactions
+		report.CashbackAmount += promotion.CashbackAmount
+		report.NetCashback += promotion.NetCashback
+	}
+
+	return report, nil
+}
+
+// normalizePromotion upper-cases the category and validates a promotion
+func (uc *promotionUsecase) normalizePromotion(promotion *domain.Promotion) error {
+	promotion.Name = strings.TrimSpace(promotion.Name)
+	if promotion.Name == "" {
+		return fmt.Errorf("promotion name is required")
+	}
+	if promotion.Percentage <= 0 || promotion.Percentage > 100 {
+		return fmt.Errorf("percentage must be between 0 and 100")
+	}
+	if promotion.MaxCashback != nil && *promotion.MaxCashback <= 0 {
+		return fmt.Errorf("max cashback must be positive")
+	}
+	if promotion.Budget != nil && *promotion.Budget <= 0 {
+		return fmt.Errorf("budget must be positive")
+	}
+	if !promotion.StartsAt.Before(promotion.EndsAt) {
+		return fmt.Errorf("promotion must end after it starts")
+	}
+
+	if promotion.Category != nil {
+		category := strings.ToUpper(strings.TrimSpace(*promotion.Category))
+		if category == "" {
+			promotion.Category = nil
+		} else if err := uc.catalogUC.ValidateCategory(category); err != nil {
+			return err
+		} else {
+			promotion.Category = &category
+		}
+	}
+
+	levels := make([]int, 0, len(promotion.UserLevels))
+	seen := make(map[int]bool)
+	for _, level := range promotion.UserLevels {
+		if !domain.IsValidLevel(level) {
+			return fmt.Errorf("invalid user level")
+		}
+		if !seen[level] {
+			seen[level] = true
+			levels = append(levels, level)
+		}
+	}
+	sort.Ints(levels)
+	promotion.UserLevels = levels
+
+	return nil
+}
@@ -0,0 +1,373 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+type quotaUsecase struct {
+	planRepo    domain.QuotaPlanRepository
+	counterRepo domain.QuotaCounterRepository
+	config      QuotaConfig
+	location    *time.Location
+
+	mu    sync.Mutex
+	plans map[string]cachedQuotaPlan
+}
+
+type cachedQuotaPlan struct {
+	plan      *domain.QuotaPlan
+	expiresAt time.Time
+}
+
+// QuotaConfig defines how H2H quotas are evaluated
+type QuotaConfig struct {
+	// Timezone cuts the day used by daily quotas
+	Timezone string
+	// PlanCacheTTL bounds how long a replica keeps using a plan changed elsewhere
+	PlanCacheTTL time.Duration
+}
+
+// DefaultQuotaConfig returns default quota configuration
+func DefaultQuotaConfig() QuotaConfig {
+	return QuotaConfig{
+		Timezone:     "Asia/Jakarta",
+		PlanCacheTTL: 30 * time.Second,
+	}
+}
+
+// NewQuotaUsecase creates a new H2H quota use case
+func NewQuotaUsecase(planRepo domain.QuotaPlanRepository, counterRepo domain.QuotaCounterRepository, config QuotaConfig) domain.QuotaUsecase {
+	defaults := DefaultQuotaConfig()
+	if config.Timezone == "" {
+		config.Timezone = defaults.Timezone
+	}
+	if config.PlanCacheTTL <= 0 {
+		config.PlanCacheTTL = defaults.PlanCacheTTL
+	}
+
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		logger.Warn("Invalid quota timezone, falling back to UTC",
+			logger.String("timezone", config.Timezone),
+			logger.ErrorField(err),
+		)
+		config.Timezone = "UTC"
+		location = time.UTC
+	}
+
+	return &quotaUsecase{
+		planRepo:    planRepo,
+		counterRepo: counterRepo,
+		config:      config,
+		location:    location,
+		plans:       make(map[string]cachedQuotaPlan),
+	}
+}
+
+// CreatePlan validates and stores a new quota plan
+func (uc *quotaUsecase) CreatePlan(plan *domain.QuotaPlan) error {
+	if plan == nil {
+		return fmt.Errorf("quota plan payload is required")
+	}
+
+	if err := normalizeQuotaPlan(plan); err != nil {
+		return err
+	}
+
+	plan.ID = utils.GenerateUUID()
+	return uc.planRepo.Create(plan)
+}
+
+// UpdatePlan validates and replaces a quota plan
+func (uc *quotaUsecase) UpdatePlan(plan *domain.QuotaPlan) (*domain.QuotaPlan, error) {
+	if plan == nil {
+		return nil, fmt.Errorf("quota plan payload is required")
+	}
+
+	existing, err := uc.planRepo.GetByID(plan.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := normalizeQuotaPlan(plan); err != nil {
+		return nil, err
+	}
+
+	plan.CreatedAt = existing.CreatedAt
+	plan.UpdatedAt = time.Now()
+
+	if err := uc.planRepo.Update(plan); err != nil {
+		return nil, err
+	}
+	uc.forgetPlan(plan.ID)
+
+	return plan, nil
+}
+
+// DeletePlan removes a quota plan that is no longer assigned to any client
+func (uc *quotaUsecase) DeletePlan(id string) error {
+	count, err := uc.planRepo.CountClients(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("quota plan is assigned to %d api clients", count)
+	}
+
+	if err := uc.planRepo.Delete(id); err != nil {
+		return err
+	}
+	uc.forgetPlan(id)
+
+	return nil
+}
+
+// GetPlan returns a quota plan by ID
+func (uc *quotaUsecase) GetPlan(id string) (*domain.QuotaPlan, error) {
+	return uc.planRepo.GetByID(id)
+}
+
+// ListPlans lists all quota plans
+func (uc *quotaUsecase) ListPlans() ([]*domain.QuotaPlan, error) {
+	return uc.planRepo.List()
+}
+
+// AssignPlan attaches a quota plan to an API client, or detaches it when
+// planID is nil
+func (uc *quotaUsecase) AssignPlan(clientID string, planID *string) error {
+	if strings.TrimSpace(clientID) == "" {
+		return fmt.Errorf("client ID is required")
+	}
+
+	if planID != nil {
+		if _, err := uc.planRepo.GetByID(*planID); err != nil {
+			return err
+		}
+	}
+
+	if err := uc.planRepo.AssignToClient(clientID, planID); err != nil {
+		return err
+	}
+
+	logger.Info("Quota plan assigned",
+		logger.String("client_id", clientID),
+		logger.Bool("cleared", planID == nil),
+	)
+
+	return nil
+}
+
+// CheckRequest counts a request against the client's per-minute quota for the
+// endpoint. Clients without a plan are limited by MaxRequestsPerMinute.
+func (uc *quotaUsecase) CheckRequest(client *domain.APIClient, endpoint string) (*domain.QuotaStatus, error) {
+	plan, err := uc.clientPlan(client)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := client.MaxRequestsPerMinute
+	if plan != nil {
+		limit = plan.RequestLimit(endpoint)
+	}
+
+	now := time.Now()
+	window := now.Truncate(time.Minute)
+	status := &domain.QuotaStatus{
+		RequestLimit:    limit,
+		RequestsResetAt: window.Add(time.Minute),
+	}
+	if limit <= 0 {
+		return status, nil
+	}
+
+	count, err := uc.counterRepo.IncrementRequests(client.ClientID, endpoint, window)
+	if err != nil {
+		return nil, err
+	}
+
+	status.RequestsRemaining = max(limit-count, 0)
+	if count > limit {
+		status.Exceeded = domain.QuotaRequests
+	}
+
+	return status, nil
+}
+
+// ReserveTransaction claims one of the client's daily transactions. A client
+// whose amount quota is used up is rejected; the transaction that crosses the
+// amount limit is still admitted since its price is only known once created.
+func (uc *quotaUsecase) ReserveTransaction(client *domain.APIClient) (*domain.QuotaStatus, error) {
+	plan, err := uc.clientPlan(client)
+	if err != nil {
+		return nil, err
+	}
+
+	day, resetAt := uc.quotaDay(time.Now())
+	status := &domain.QuotaStatus{DailyResetAt: resetAt}
+	if plan == nil || (plan.TransactionsPerDay <= 0 && plan.MaxAmountPerDay <= 0) {
+		return status, nil
+	}
+	status.TransactionLimit = plan.TransactionsPerDay
+	status.AmountLimit = plan.MaxAmountPerDay
+
+	count, used, err := uc.counterRepo.ReserveTransaction(client.ClientID, day)
+	if err != nil {
+		return nil, err
+	}
+
+	status.TransactionsRemaining = max(plan.TransactionsPerDay-count, 0)
+	status.AmountRemaining = max(plan.MaxAmountPerDay-used, 0)
+
+	switch {
+	case plan.TransactionsPerDay > 0 && count > plan.TransactionsPerDay:
+		status.Exceeded = domain.QuotaTransactions
+	case plan.MaxAmountPerDay > 0 && used >= plan.MaxAmountPerDay:
+		status.Exceeded = domain.QuotaAmount
+	}
+
+	if !status.Allowed() {
+		if err := uc.counterRepo.ReleaseTransaction(client.ClientID, day); err != nil {
+			logger.Warn("Failed to release rejected quota transaction",
+				logger.String("client_id", client.ClientID),
+				logger.ErrorField(err),
+			)
+		}
+	}
+
+	return status, nil
+}
+
+// ReleaseTransaction gives back a transaction reserved for a request that did
+// not create one
+func (uc *quotaUsecase) ReleaseTransaction(client *domain.APIClient) error {
+	plan, err := uc.clientPlan(client)
+	if err != nil || plan == nil || (plan.TransactionsPerDay <= 0 && plan.MaxAmountPerDay <= 0) {
+		return err
+	}
+
+	day, _ := uc.quotaDay(time.Now())
+	return uc.counterRepo.ReleaseTransaction(client.ClientID, day)
+}
+
+// RecordTransactionAmount adds a created transaction to the client's daily amount
+func (uc *quotaUsecase) RecordTransactionAmount(client *domain.APIClient, amount float64) error {
+	plan, err := uc.clientPlan(client)
+	if err != nil || plan == nil || plan.MaxAmountPerDay <= 0 {
+		return err
+	}
+
+	day, _ := uc.quotaDay(time.Now())
+	return uc.counterRepo.AddAmount(client.ClientID, day, amount)
+}
+
+// GetStatus reports the client's quota on an endpoint without counting a
+// request or reserving a transaction
+func (uc *quotaUsecase) GetStatus(client *domain.APIClient, endpoint string) (*domain.QuotaStatus, error) {
+	plan, err := uc.clientPlan(client)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	window := now.Truncate(time.Minute)
+	day, resetAt := uc.quotaDay(now)
+	status := &domain.QuotaStatus{
+		RequestLimit:    client.MaxRequestsPerMinute,
+		RequestsResetAt: window.Add(time.Minute),
+		DailyResetAt:    resetAt,
+	}
+	if plan != nil {
+		status.RequestLimit = plan.RequestLimit(endpoint)
+		status.TransactionLimit = plan.TransactionsPerDay
+		status.AmountLimit = plan.MaxAmountPerDay
+	}
+
+	requests, count, used, err := uc.counterRepo.GetUsage(client.ClientID, endpoint, window, day)
+	if err != nil {
+		return nil, err
+	}
+
+	status.RequestsRemaining = max(status.RequestLimit-requests, 0)
+	status.TransactionsRemaining = max(status.TransactionLimit-count, 0)
+	status.AmountRemaining = max(status.AmountLimit-used, 0)
+
+	return status, nil
+}
+
+// clientPlan returns the client's quota plan, or nil when none is assigned
+func (uc *quotaUsecase) clientPlan(client *domain.APIClient) (*domain.QuotaPlan, error) {
+	if client == nil || client.QuotaPlanID == nil {
+		return nil, nil
+	}
+
+	id := *client.QuotaPlanID
+	now := time.Now()
+
+	uc.mu.Lock()
+	cached, ok := uc.plans[id]
+	uc.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.plan, nil
+	}
+
+	plan, err := uc.planRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.mu.Lock()
+	uc.plans[id] = cachedQuotaPlan{plan: plan, expiresAt: now.Add(uc.config.PlanCacheTTL)}
+	uc.mu.Unlock()
+
+	return plan, nil
+}
+
+func (uc *quotaUsecase) forgetPlan(id string) {
+	uc.mu.Lock()
+	delete(uc.plans, id)
+	uc.mu.Unlock()
+}
+
+// quotaDay returns the quota day key for now and when that day ends
+func (uc *quotaUsecase) quotaDay(now time.Time) (string, time.Time) {
+	local := now.In(uc.location)
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, uc.location)
+	return start.Format("20060102"), start.AddDate(0, 0, 1)
+}
+
+// normalizeQuotaPlan trims and validates a quota plan
+func normalizeQuotaPlan(plan *domain.QuotaPlan) error {
+	plan.Name = strings.TrimSpace(plan.Name)
+	if plan.Name == "" {
+		return fmt.Errorf("quota plan name is required")
+	}
+	if plan.Description != nil && strings.TrimSpace(*plan.Description) == "" {
+		plan.Description = nil
+	}
+
+	if plan.RequestsPerMinute < 0 || plan.TransactionsPerDay < 0 || plan.MaxAmountPerDay < 0 {
+		return fmt.Errorf("quota limits must not be negative")
+	}
+
+	limits := make(map[string]int, len(plan.EndpointLimits))
+	for endpoint, limit := range plan.EndpointLimits {
+		endpoint = strings.TrimSpace(endpoint)
+		if !strings.HasPrefix(endpoint, "/") {
+			return fmt.Errorf("endpoint %q must be a route path starting with /", endpoint)
+		}
+		if limit < 0 {
+			return fmt.Errorf("quota limits must not be negative")
+		}
+		limits[endpoint] = limit
+	}
+	plan.EndpointLimits = limits
+
+	return nil
+}
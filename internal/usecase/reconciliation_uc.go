@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/metrics"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+type reconciliationUsecase struct {
+	reconciliationRepo domain.ReconciliationRepository
+	config             ReconciliationConfig
+}
+
+// ReconciliationConfig defines the balance reconciliation behavior
+type ReconciliationConfig struct {
+	// BatchSize is the number of users whose ledger is recomputed per query
+	BatchSize int
+}
+
+// DefaultReconciliationConfig returns default balance reconciliation configuration
+func DefaultReconciliationConfig() ReconciliationConfig {
+	return ReconciliationConfig{
+		BatchSize: 500,
+	}
+}
+
+// NewReconciliationUsecase creates a new balance reconciliation use case
+func NewReconciliationUsecase(reconciliationRepo domain.ReconciliationRepository, config ReconciliationConfig) domain.ReconciliationUsecase {
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultReconciliationConfig().BatchSize
+	}
+
+	return &reconciliationUsecase{
+		reconciliationRepo: reconciliationRepo,
+		config:             config,
+	}
+}
+
+// ReconcileBalances walks all users in batches and compares their stored
+// balance with the sum of their mutations. Balance updates and mutation
+// inserts are not atomic, so a user caught in between is checked once more
+// before a mismatch is flagged. Open mismatches of users that balance again
+// are cleared.
+func (uc *reconciliationUsecase) ReconcileBalances() (*domain.ReconciliationResult, error) {
+	result := &domain.ReconciliationResult{StartedAt: time.Now()}
+
+	after := ""
+	for {
+		balances, err := uc.reconciliationRepo.ListLedgerBalances(after, uc.config.BatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(balances) == 0 {
+			break
+		}
+
+		matched := make([]string, 0, len(balances))
+		for _, balance := range balances {
+			result.UsersChecked++
+			after = balance.UserID
+
+			if !balance.Matches {
+				recheck, err := uc.reconciliationRepo.GetLedgerBalance(balance.UserID)
+				if err != nil {
+					logger.Warn("Failed to recheck ledger balance",
+						logger.String("user_id", balance.UserID),
+						logger.ErrorField(err),
+					)
+					continue
+				}
+				balance = recheck
+			}
+
+			if balance.Matches {
+				matched = append(matched, balance.UserID)
+				continue
+			}
+
+			if err := uc.flagMismatch(balance); err != nil {
+				return nil, err
+			}
+			result.Mismatches++
+			result.TotalDifference += balance.StoredBalance - balance.LedgerBalance
+		}
+
+		cleared, err := uc.reconciliationRepo.ClearMismatches(matched)
+		if err != nil {
+			return nil, err
+		}
+		result.Cleared += cleared
+
+		if len(balances) < uc.config.BatchSize {
+			break
+		}
+	}
+
+	result.FinishedAt = time.Now()
+	metrics.SetBalanceMismatches(result.Mismatches, result.TotalDifference)
+
+	logger.Info("Balance reconciliation completed",
+		logger.Int("users_checked", result.UsersChecked),
+		logger.Int("mismatches", result.Mismatches),
+		logger.Int("cleared", result.Cleared),
+		logger.Float64("total_difference", result.TotalDifference),
+		logger.Duration("duration", result.FinishedAt.Sub(result.StartedAt)),
+	)
+
+	return result, nil
+}
+
+// ListMismatches lists balance mismatches, optionally filtered by status
+func (uc *reconciliationUsecase) ListMismatches(status string, page, limit int) ([]*domain.BalanceMismatch, error) {
+	status = strings.ToUpper(strings.TrimSpace(status))
+	if status != "" && !domain.IsValidBalanceMismatchStatus(status) {
+		return nil, fmt.Errorf("invalid mismatch status")
+	}
+
+	offset := (page - 1) * limit
+	return uc.reconciliationRepo.ListMismatches(status, limit, offset)
+}
+
+// ResolveMismatch closes an open mismatch once an admin has corrected or
+// explained the difference. The balance itself is not touched.
+func (uc *reconciliationUsecase) ResolveMismatch(id, resolution string, resolvedBy *string) (*domain.BalanceMismatch, error) {
+	resolution = strings.TrimSpace(resolution)
+	if resolution == "" {
+		return nil, fmt.Errorf("resolution is required")
+	}
+
+	mismatch, err := uc.reconciliationRepo.GetMismatch(id)
+	if err != nil {
+		return nil, err
+	}
+	if mismatch.Status != domain.BalanceMismatchOpen {
+		return nil, fmt.Errorf("balance mismatch already closed")
+	}
+
+	if err := uc.reconciliationRepo.ResolveMismatch(mismatch.ID, resolution, resolvedBy); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Balance mismatch resolved",
+		logger.String("mismatch_id", mismatch.ID),
+		logger.String("user_id", mismatch.UserID),
+		logger.Float64("difference", mismatch.Difference),
+	)
+
+	return uc.reconciliationRepo.GetMismatch(mismatch.ID)
+}
+
+func (uc *reconciliationUsecase) flagMismatch(balance *domain.LedgerBalance) error {
+	mismatch := &domain.BalanceMismatch{
+		ID:               utils.GenerateUUID(),
+		UserID:           balance.UserID,
+		StoredBalance:    balance.StoredBalance,
+		LedgerBalance:    balance.LedgerBalance,
+		Difference:       balance.StoredBalance - balance.LedgerBalance,
+		MutationCount:    balance.MutationCount,
+		LastMutationID:   balance.LastMutationID,
+		LastBalanceAfter: balance.LastBalanceAfter,
+	}
+	if err := uc.reconciliationRepo.UpsertMismatch(mismatch); err != nil {
+		return err
+	}
+
+	logger.Error("Balance does not match mutation ledger",
+		logger.String("user_id", balance.UserID),
+		logger.Float64("stored_balance", balance.StoredBalance),
+		logger.Float64("ledger_balance", balance.LedgerBalance),
+		logger.Float64("difference", mismatch.Difference),
+		logger.Int("mutation_count", balance.MutationCount),
+	)
+	return nil
+}
+
+// RecomputeBalance reports a user's stored and ledger balance. With apply the
+// stored balance is overwritten with the ledger balance and the user's open
+// mismatch is cleared; the returned figures are from before the update.
+func (uc *reconciliationUsecase) RecomputeBalance(userID string, apply bool) (*domain.LedgerBalance, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return nil, fmt.Errorf("user id is required")
+	}
+
+	if !apply {
+		return uc.reconciliationRepo.GetLedgerBalance(userID)
+	}
+
+	balance, err := uc.reconciliationRepo.ApplyLedgerBalance(userID)
+	if err != nil {
+		return nil, err
+	}
+	if balance.Matches {
+		return balance, nil
+	}
+
+	if _, err := uc.reconciliationRepo.ClearMismatches([]string{userID}); err != nil {
+		return nil, err
+	}
+
+	logger.Warn("Balance recomputed from ledger",
+		logger.String("user_id", userID),
+		logger.Float64("stored_balance", balance.StoredBalance),
+		logger.Float64("ledger_balance", balance.LedgerBalance),
+	)
+
+	return balance, nil
+}
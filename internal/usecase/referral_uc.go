@@ -0,0 +1,365 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+// referralCodeAttempts bounds the retries when a generated code is taken
+const referralCodeAttempts = 5
+
+type referralUsecase struct {
+	userRepo     domain.UserRepository
+	referralRepo domain.ReferralRepository
+	unitOfWork   domain.UnitOfWork
+	phones       *utils.PhoneNormalizer
+	config       ReferralConfig
+}
+
+// ReferralConfig defines referral codes and the downline limits of uplines
+type ReferralConfig struct {
+	// MaxDownlines caps the direct downlines plus pending registrations per
+	// upline level; levels without an entry or with 0 are unlimited
+	MaxDownlines map[int]int
+	CodeLength   int
+	// MaxListed caps the registrations returned per request
+	MaxListed int
+}
+
+// DefaultReferralConfig returns default referral configuration
+func DefaultReferralConfig() ReferralConfig {
+	return ReferralConfig{
+		MaxDownlines: map[int]int{
+			domain.LevelAgent:  100,
+			domain.LevelMaster: 1000,
+		},
+		CodeLength: 8,
+		MaxListed:  200,
+	}
+}
+
+// NewReferralUsecase creates a new referral use case
+func NewReferralUsecase(
+	userRepo domain.UserRepository,
+	referralRepo domain.ReferralRepository,
+	unitOfWork domain.UnitOfWork,
+	phones *utils.PhoneNormalizer,
+	config ReferralConfig,
+) domain.ReferralUsecase {
+	defaults := DefaultReferralConfig()
+	if config.MaxDownlines == nil {
+		config.MaxDownlines = defaults.MaxDownlines
+	}
+	if config.CodeLength <= 0 {
+		config.CodeLength = defaults.CodeLength
+	}
+	if config.MaxListed <= 0 {
+		config.MaxListed = defaults.MaxListed
+	}
+
+	if phones == nil {
+		phones = utils.DefaultPhoneNormalizer()
+	}
+
+	return &referralUsecase{
+		userRepo:     userRepo,
+		referralRepo: referralRepo,
+		unitOfWork:   unitOfWork,
+		phones:       phones,
+		config:       config,
+	}
+}
+
+// GetCode returns the user's referral code, issuing one on first use
+func (uc *referralUsecase) GetCode(userID string) (string, error) {
+	if err := uc.checkCanRefer(userID); err != nil {
+		return "", err
+	}
+
+	code, err := uc.referralRepo.GetReferralCode(userID)
+	if err != nil {
+		return "", err
+	}
+	if code != nil {
+		return *code, nil
+	}
+
+	return uc.issueCode(userID)
+}
+
+// RotateCode issues a new referral code; registrations already made with the
+// old one stay pending
+func (uc *referralUsecase) RotateCode(userID string) (string, error) {
+	if err := uc.checkCanRefer(userID); err != nil {
+		return "", err
+	}
+
+	return uc.issueCode(userID)
+}
+
+func (uc *referralUsecase) checkCanRefer(userID string) error {
+	user, err := uc.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if !user.CanHaveDownlines() {
+		return fmt.Errorf("user level cannot have downlines")
+	}
+	return nil
+}
+
+// issueCode stores a new random code, retrying when it collides with another
+// user's
+func (uc *referralUsecase) issueCode(userID string) (string, error) {
+	for attempt := 0; attempt < referralCodeAttempts; attempt++ {
+		code := strings.ToUpper(utils.GenerateRandomString(uc.config.CodeLength))
+		err := uc.referralRepo.SetReferralCode(userID, code)
+		if err == nil {
+			logger.Info("Referral code issued", logger.String("user_id", userID))
+			return code, nil
+		}
+		if err.Error() != "referral code already exists" {
+			return "", err
+		}
+	}
+
+	return "", fmt.Errorf("failed to generate a unique referral code")
+}
+
+// Register records a registration made with a referral code. It waits for the
+// upline's approval and counts toward the upline's downline limit meanwhile.
+func (uc *referralUsecase) Register(req domain.ReferralRegistrationRequest) (*domain.DownlineRegistration, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	fullName := strings.TrimSpace(req.FullName)
+	switch {
+	case !utils.ValidateEmail(email):
+		return nil, fmt.Errorf("invalid email")
+	case fullName == "":
+		return nil, fmt.Errorf("full name is required")
+	case len(req.Password) < 8:
+		return nil, fmt.Errorf("password must be at least 8 characters")
+	case req.Phone != "" && !uc.phones.Default().Validate(req.Phone):
+		return nil, fmt.Errorf("invalid phone number")
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(req.ReferralCode))
+	if code == "" {
+		return nil, fmt.Errorf("referral code not found")
+	}
+	upline, err := uc.referralRepo.GetUserByReferralCode(code)
+	if err != nil {
+		return nil, err
+	}
+	// A deactivated or demoted upline's code no longer works
+	if !upline.IsActive || !upline.CanHaveDownlines() {
+		return nil, fmt.Errorf("referral code not found")
+	}
+
+	if existing, _ := uc.userRepo.GetByEmail(email); existing != nil {
+		return nil, fmt.Errorf("email already registered")
+	}
+	pending, err := uc.referralRepo.HasPendingEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, fmt.Errorf("registration already pending")
+	}
+
+	passwordHash, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return nil, fmt.Errorf("password must be at most 72 characters")
+	}
+
+	registration := &domain.DownlineRegistration{
+		ID:           utils.GenerateUUID(),
+		UplineID:     upline.ID,
+		ReferralCode: code,
+		Email:        email,
+		FullName:     fullName,
+		PasswordHash: passwordHash,
+		Status:       domain.RegistrationStatusPending,
+	}
+	if req.Phone != "" {
+		phone := uc.phones.Default().Parse(req.Phone)
+		registration.Phone = &phone
+	}
+	if req.IPAddress != "" {
+		registration.IPAddress = &req.IPAddress
+	}
+
+	err = uc.unitOfWork.Do(func(repos domain.TxRepositories) error {
+		// Locking the upline serializes concurrent registrations under it so
+		// the limit cannot be overrun
+		if err := repos.Transfers().LockUsers(upline.ID); err != nil {
+			return err
+		}
+		if limit := uc.config.MaxDownlines[upline.Level]; limit > 0 {
+			count, err := repos.Referrals().CountDownlines(upline.ID)
+			if err != nil {
+				return err
+			}
+			if count >= limit {
+				return fmt.Errorf("downline limit reached")
+			}
+		}
+		return repos.Referrals().Create(registration)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Downline registration received",
+		logger.String("registration_id", registration.ID),
+		logger.String("upline_id", upline.ID),
+	)
+
+	return registration, nil
+}
+
+// ListRegistrations lists the registrations made with the upline's codes
+func (uc *referralUsecase) ListRegistrations(uplineID, status string, limit int) ([]*domain.DownlineRegistration, error) {
+	status = strings.ToUpper(status)
+	switch status {
+	case "", domain.RegistrationStatusPending, domain.RegistrationStatusApproved, domain.RegistrationStatusRejected:
+	default:
+		return nil, fmt.Errorf("invalid registration status")
+	}
+	if limit <= 0 || limit > uc.config.MaxListed {
+		limit = uc.config.MaxListed
+	}
+
+	return uc.referralRepo.ListByUpline(uplineID, status, limit)
+}
+
+// Approve creates the user of a pending registration under the upline, one
+// level below it and inheriting its markup and daily transaction limit
+func (uc *referralUsecase) Approve(uplineID, registrationID string) (*domain.DownlineRegistration, error) {
+	var registration *domain.DownlineRegistration
+	var user *domain.User
+
+	err := uc.unitOfWork.Do(func(repos domain.TxRepositories) error {
+		var err error
+		registration, err = pendingRegistration(repos, uplineID, registrationID)
+		if err != nil {
+			return err
+		}
+
+		upline, err := repos.Users().GetByID(uplineID)
+		if err != nil {
+			return err
+		}
+		if existing, _ := repos.Users().GetByEmail(registration.Email); existing != nil {
+			return fmt.Errorf("email already registered")
+		}
+
+		fullName := registration.FullName
+		user = &domain.User{
+			ID:                  utils.GenerateUUID(),
+			Username:            uniqueUsername(repos.Users(), registration.Email),
+			Email:               registration.Email,
+			PasswordHash:        registration.PasswordHash,
+			FullName:            &fullName,
+			Phone:               registration.Phone,
+			UplineID:            &upline.ID,
+			Level:               domain.DownlineLevel(upline.Level),
+			IsActive:            true,
+			IsVerified:          true,
+			MarkupPercentage:    upline.MarkupPercentage,
+			MaxDailyTransaction: upline.MaxDailyTransaction,
+		}
+		if err := repos.Users().Create(user); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		registration.Status = domain.RegistrationStatusApproved
+		registration.UserID = &user.ID
+		registration.DecidedBy = &uplineID
+		registration.DecidedAt = &now
+		return repos.Referrals().Decide(registration)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Downline registration approved",
+		logger.String("registration_id", registration.ID),
+		logger.String("upline_id", uplineID),
+		logger.String("user_id", user.ID),
+		logger.Int("level", user.Level),
+	)
+
+	return registration, nil
+}
+
+// Reject declines a pending registration of the upline
+func (uc *referralUsecase) Reject(uplineID, registrationID, reason string) (*domain.DownlineRegistration, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("reason is required")
+	}
+
+	var registration *domain.DownlineRegistration
+	err := uc.unitOfWork.Do(func(repos domain.TxRepositories) error {
+		var err error
+		registration, err = pendingRegistration(repos, uplineID, registrationID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		registration.Status = domain.RegistrationStatusRejected
+		registration.Reason = &reason
+		registration.DecidedBy = &uplineID
+		registration.DecidedAt = &now
+		return repos.Referrals().Decide(registration)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Downline registration rejected",
+		logger.String("registration_id", registration.ID),
+		logger.String("upline_id", uplineID),
+	)
+
+	return registration, nil
+}
+
+// pendingRegistration locks a registration of the upline that awaits a
+// decision. Another upline's registration is reported as not found.
+func pendingRegistration(repos domain.TxRepositories, uplineID, registrationID string) (*domain.DownlineRegistration, error) {
+	registration, err := repos.Referrals().GetByIDForUpdate(registrationID)
+	if err != nil {
+		return nil, err
+	}
+	if registration.UplineID != uplineID {
+		return nil, fmt.Errorf("registration not found")
+	}
+	if registration.Status != domain.RegistrationStatusPending {
+		return nil, fmt.Errorf("registration is not pending")
+	}
+	return registration, nil
+}
+
+// uniqueUsername derives a free username from the local part of an email
+func uniqueUsername(userRepo domain.UserRepository, email string) string {
+	base := strings.TrimSpace(strings.Split(email, "@")[0])
+	if base == "" {
+		base = "user"
+	}
+
+	username := base
+	for suffix := 1; ; suffix++ {
+		if existing, _ := userRepo.GetByUsername(username); existing == nil {
+			return username
+		}
+		username = fmt.Sprintf("%s%d", base, suffix)
+	}
+}
@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/metrics"
+)
+
+type refundSLAUsecase struct {
+	refundRepo domain.RefundSLARepository
+	eventRepo  domain.EventRepository
+	config     RefundSLAConfig
+}
+
+// RefundSLAConfig defines refund SLA targets and stuck refund alerting
+type RefundSLAConfig struct {
+	// Target is how long a refund may take after the failure before it
+	// counts as breached in the report
+	Target time.Duration
+	// StuckAfter is how long a failed transaction may wait for its balance to
+	// be released or refunded before it is alerted as stuck
+	StuckAfter time.Duration
+	// Lookback bounds the creation time of transactions checked for
+	// outstanding refunds
+	Lookback time.Duration
+	// OutstandingLimit caps the outstanding refunds listed and alerted per pass
+	OutstandingLimit int
+	// Cooldown suppresses repeated alerts for the same transaction
+	Cooldown time.Duration
+}
+
+// DefaultRefundSLAConfig returns default refund SLA configuration
+func DefaultRefundSLAConfig() RefundSLAConfig {
+	return RefundSLAConfig{
+		Target:           5 * time.Minute,
+		StuckAfter:       30 * time.Minute,
+		Lookback:         7 * 24 * time.Hour,
+		OutstandingLimit: 100,
+		Cooldown:         6 * time.Hour,
+	}
+}
+
+// NewRefundSLAUsecase creates a new refund SLA use case
+func NewRefundSLAUsecase(refundRepo domain.RefundSLARepository, eventRepo domain.EventRepository, config RefundSLAConfig) domain.RefundSLAUsecase {
+	defaults := DefaultRefundSLAConfig()
+	if config.Target <= 0 {
+		config.Target = defaults.Target
+	}
+	if config.StuckAfter <= 0 {
+		config.StuckAfter = defaults.StuckAfter
+	}
+	if config.Lookback <= 0 {
+		config.Lookback = defaults.Lookback
+	}
+	if config.Lookback < config.StuckAfter {
+		config.Lookback = config.StuckAfter
+	}
+	if config.OutstandingLimit <= 0 {
+		config.OutstandingLimit = defaults.OutstandingLimit
+	}
+	if config.Cooldown < 0 {
+		config.Cooldown = defaults.Cooldown
+	}
+
+	return &refundSLAUsecase{
+		refundRepo: refundRepo,
+		eventRepo:  eventRepo,
+		config:     config,
+	}
+}
+
+// GetRefundReport builds the refund report of the calendar dates between
+// startDate and endDate
+func (uc *refundSLAUsecase) GetRefundReport(startDate, endDate time.Time, olderThan time.Duration) (*domain.RefundReport, error) {
+	start := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, startDate.Location())
+	end := time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, endDate.Location()).AddDate(0, 0, 1)
+	if end.Before(start) {
+		return nil, fmt.Errorf("end date must not be before start date")
+	}
+	if end.After(start.AddDate(0, 0, maxReportDays)) {
+		return nil, fmt.Errorf("report range too large")
+	}
+	if olderThan < 0 {
+		return nil, fmt.Errorf("older_than must not be negative")
+	}
+	if olderThan == 0 {
+		olderThan = uc.config.StuckAfter
+	}
+
+	stats, err := uc.refundRepo.GetRefundStats(start, end, uc.config.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	outstanding, count, amount, err := uc.refundRepo.ListOutstanding(now.Add(-uc.config.Lookback), now.Add(-olderThan), uc.config.OutstandingLimit)
+	if err != nil {
+		return nil, err
+	}
+	setRefundAges(outstanding, now)
+
+	return &domain.RefundReport{
+		StartDate:         start,
+		EndDate:           end,
+		TargetSeconds:     int(uc.config.Target.Seconds()),
+		Refunds:           stats,
+		OlderThanSeconds:  int(olderThan.Seconds()),
+		OutstandingCount:  count,
+		OutstandingAmount: amount,
+		Outstanding:       outstanding,
+	}, nil
+}
+
+// CheckStuckRefunds exports the stuck refund gauges and alerts each stuck
+// refund at most once per cooldown
+func (uc *refundSLAUsecase) CheckStuckRefunds() ([]*domain.OutstandingRefund, error) {
+	now := time.Now()
+	stuck, count, amount, err := uc.refundRepo.ListOutstanding(now.Add(-uc.config.Lookback), now.Add(-uc.config.StuckAfter), uc.config.OutstandingLimit)
+	if err != nil {
+		return nil, err
+	}
+	setRefundAges(stuck, now)
+
+	oldest := 0
+	if len(stuck) > 0 {
+		oldest = stuck[0].AgeSeconds
+	}
+	metrics.SetStuckRefunds(count, amount, float64(oldest))
+
+	for _, refund := range stuck {
+		uc.alert(refund, now)
+	}
+
+	return stuck, nil
+}
+
+// alert writes a refund.stuck outbox event unless the transaction was
+// alerted within the cooldown; the relay delivers it to the event webhooks
+func (uc *refundSLAUsecase) alert(refund *domain.OutstandingRefund, now time.Time) {
+	last, err := uc.refundRepo.LastStuckAlertAt(refund.TransactionID)
+	if err != nil {
+		logger.Error("Failed to check stuck refund alert cooldown", logger.String("trx_id", refund.TransactionID), logger.ErrorField(err))
+		return
+	}
+	if last != nil && now.Sub(*last) < uc.config.Cooldown {
+		return
+	}
+
+	logger.Warn("Refund stuck",
+		logger.String("trx_id", refund.TransactionID),
+		logger.String("trx_code", refund.TrxCode),
+		logger.String("status", refund.Status),
+		logger.Float64("amount", refund.Amount),
+		logger.Int("age_seconds", refund.AgeSeconds),
+	)
+
+	event, err := domain.NewRefundStuckEvent(refund, uc.config.StuckAfter)
+	if err != nil {
+		logger.Error("Failed to build stuck refund event", logger.String("trx_id", refund.TransactionID), logger.ErrorField(err))
+		return
+	}
+	if err := uc.eventRepo.Create(event); err != nil {
+		logger.Error("Failed to store stuck refund event", logger.String("trx_id", refund.TransactionID), logger.ErrorField(err))
+	}
+}
+
+func setRefundAges(refunds []*domain.OutstandingRefund, now time.Time) {
+	for _, refund := range refunds {
+		refund.AgeSeconds = int(now.Sub(refund.FailedAt).Seconds())
+	}
+}
@@ -0,0 +1,265 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+const (
+	maxReportDays   = 366
+	maxReportMonths = 36
+)
+
+type reportUsecase struct {
+	reportRepo  domain.ReportRepository
+	reportCache domain.ReportCacheRepository
+	config      ReportConfig
+	location    *time.Location
+}
+
+// ReportConfig defines finance report parameters
+type ReportConfig struct {
+	// Timezone is the IANA timezone used to cut days and months
+	Timezone string
+	// CacheTTL is how long rows of closed periods stay cached
+	CacheTTL time.Duration
+}
+
+// DefaultReportConfig returns default report configuration
+func DefaultReportConfig() ReportConfig {
+	return ReportConfig{
+		Timezone: "Asia/Jakarta",
+		CacheTTL: 24 * time.Hour,
+	}
+}
+
+// NewReportUsecase creates a new report use case. Period caching is disabled
+// when reportCache is nil.
+func NewReportUsecase(
+	reportRepo domain.ReportRepository,
+	reportCache domain.ReportCacheRepository,
+	config ReportConfig,
+) domain.ReportUsecase {
+	defaults := DefaultReportConfig()
+	if config.Timezone == "" {
+		config.Timezone = defaults.Timezone
+	}
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = defaults.CacheTTL
+	}
+
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		logger.Warn("Invalid report timezone, falling back to UTC",
+			logger.String("timezone", config.Timezone),
+			logger.ErrorField(err),
+		)
+		config.Timezone = "UTC"
+		location = time.UTC
+	}
+
+	return &reportUsecase{
+		reportRepo:  reportRepo,
+		reportCache: reportCache,
+		config:      config,
+		location:    location,
+	}
+}
+
+// GetSupplierReport builds the supplier profit report between the calendar dates
+// of startDate and endDate (inclusive), widened to whole periods. Closed periods are served from cache
+// unless refresh is set; the remaining periods are aggregated in one query.
+func (uc *reportUsecase) GetSupplierReport(startDate, endDate time.Time, granularity string, refresh bool) (*domain.SupplierReport, error) {
+	granularity = strings.ToUpper(strings.TrimSpace(granularity))
+	if !domain.IsValidReportGranularity(granularity) {
+		return nil, fmt.Errorf("invalid report granularity")
+	}
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("end date must not be before start date")
+	}
+
+	start := uc.truncate(startDate, granularity)
+	end := uc.nextPeriod(uc.truncate(endDate, granularity), granularity)
+
+	periods := make([]time.Time, 0)
+	for period := start; period.Before(end); period = uc.nextPeriod(period, granularity) {
+		periods = append(periods, period)
+	}
+	if (granularity == domain.ReportGranularityDay && len(periods) > maxReportDays) ||
+		(granularity == domain.ReportGranularityMonth && len(periods) > maxReportMonths) {
+		return nil, fmt.Errorf("report range too large")
+	}
+
+	now := time.Now()
+	rowsByPeriod := make(map[time.Time][]*domain.SupplierReportRow, len(periods))
+	queryFrom := len(periods)
+	for i, period := range periods {
+		rows, ok := uc.cachedPeriod(granularity, period, now, refresh)
+		if !ok {
+			queryFrom = i
+			break
+		}
+		rowsByPeriod[period] = rows
+	}
+
+	if queryFrom < len(periods) {
+		rows, err := uc.reportRepo.GetSupplierSummary(periods[queryFrom], end, granularity, uc.config.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			// Periods come back as wall-clock times of the report timezone
+			row.Period = time.Date(row.Period.Year(), row.Period.Month(), row.Period.Day(), 0, 0, 0, 0, uc.location)
+			rowsByPeriod[row.Period] = append(rowsByPeriod[row.Period], row)
+		}
+
+		for _, period := range periods[queryFrom:] {
+			if uc.reportCache == nil || uc.nextPeriod(period, granularity).After(now) {
+				continue
+			}
+			if err := uc.reportCache.SetSupplierPeriod(granularity, period, rowsByPeriod[period], uc.config.CacheTTL); err != nil {
+				logger.Warn("Failed to cache report period",
+					logger.String("granularity", granularity),
+					logger.String("period", period.Format("2006-01-02")),
+					logger.ErrorField(err),
+				)
+			}
+		}
+	}
+
+	report := &domain.SupplierReport{
+		Granularity: granularity,
+		StartDate:   start,
+		EndDate:     end,
+		Rows:        make([]*domain.SupplierReportRow, 0),
+		GeneratedAt: now,
+	}
+
+	totals := make(map[string]*domain.SupplierReportRow)
+	for _, period := range periods {
+		for _, row := range rowsByPeriod[period] {
+			row.SuccessRatio = successRatio(row)
+			report.Rows = append(report.Rows, row)
+
+			total, ok := totals[row.SupplierID]
+			if !ok {
+				total = &domain.SupplierReportRow{
+					Period:       start,
+					SupplierID:   row.SupplierID,
+					SupplierCode: row.SupplierCode,
+					SupplierName: row.SupplierName,
+				}
+				totals[row.SupplierID] = total
+			}
+			total.TotalTransactions += row.TotalTransactions
+			total.SuccessCount += row.SuccessCount
+			total.FailedCount += row.FailedCount
+			total.RefundCount += row.RefundCount
+			total.TotalHPP += row.TotalHPP
+			total.TotalSelling += row.TotalSelling
+			total.TotalAdminFee += row.TotalAdminFee
+			total.GrossProfit += row.GrossProfit
+			total.RefundTotal += row.RefundTotal
+		}
+	}
+
+	report.Totals = make([]*domain.SupplierReportRow, 0, len(totals))
+	for _, total := range totals {
+		total.SuccessRatio = successRatio(total)
+		report.Totals = append(report.Totals, total)
+	}
+	sort.Slice(report.Totals, func(i, j int) bool {
+		return report.Totals[i].SupplierCode < report.Totals[j].SupplierCode
+	})
+
+	return report, nil
+}
+
+// GetTagReport groups the transactions between the calendar dates of
+// startDate and endDate (inclusive) by the values of one tag key, for every
+// account or only userID
+func (uc *reportUsecase) GetTagReport(startDate, endDate time.Time, tagKey, userID string) (*domain.TagReport, error) {
+	tagKey = domain.NormalizeTransactionTagKey(tagKey)
+	if !domain.IsValidTransactionTagKey(tagKey) {
+		return nil, fmt.Errorf("invalid tag key")
+	}
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("end date must not be before start date")
+	}
+
+	start := uc.truncate(startDate, domain.ReportGranularityDay)
+	end := uc.truncate(endDate, domain.ReportGranularityDay).AddDate(0, 0, 1)
+	if end.After(start.AddDate(0, 0, maxReportDays)) {
+		return nil, fmt.Errorf("report range too large")
+	}
+
+	rows, err := uc.reportRepo.GetTagSummary(start, end, tagKey, userID)
+	if err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		rows = []*domain.TagReportRow{}
+	}
+
+	return &domain.TagReport{
+		TagKey:      tagKey,
+		UserID:      userID,
+		StartDate:   start,
+		EndDate:     end,
+		Rows:        rows,
+		GeneratedAt: time.Now(),
+	}, nil
+}
+
+// cachedPeriod returns cached rows of a closed period. Open periods are never cached.
+func (uc *reportUsecase) cachedPeriod(granularity string, period, now time.Time, refresh bool) ([]*domain.SupplierReportRow, bool) {
+	if uc.reportCache == nil || refresh || uc.nextPeriod(period, granularity).After(now) {
+		return nil, false
+	}
+
+	rows, ok, err := uc.reportCache.GetSupplierPeriod(granularity, period)
+	if err != nil {
+		logger.Warn("Failed to read cached report period",
+			logger.String("granularity", granularity),
+			logger.String("period", period.Format("2006-01-02")),
+			logger.ErrorField(err),
+		)
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+
+	for _, row := range rows {
+		row.Period = row.Period.In(uc.location)
+	}
+	return rows, true
+}
+
+// truncate returns the start of the period containing the calendar date of t
+func (uc *reportUsecase) truncate(t time.Time, granularity string) time.Time {
+	if granularity == domain.ReportGranularityMonth {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, uc.location)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, uc.location)
+}
+
+func (uc *reportUsecase) nextPeriod(period time.Time, granularity string) time.Time {
+	if granularity == domain.ReportGranularityMonth {
+		return period.AddDate(0, 1, 0)
+	}
+	return period.AddDate(0, 0, 1)
+}
+
+// successRatio is the share of successful transactions among all attempts routed to the supplier
+func successRatio(row *domain.SupplierReportRow) float64 {
+	if row.TotalTransactions == 0 {
+		return 0
+	}
+	return float64(row.SuccessCount) / float64(row.TotalTransactions)
+}
@@ -0,0 +1,256 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+type retryPolicyUsecase struct {
+	policyRepo   domain.RetryPolicyRepository
+	supplierRepo domain.SupplierRepository
+	config       RetryPolicyConfig
+
+	mu              sync.Mutex
+	active          []*domain.RetryPolicy
+	activeExpiresAt time.Time
+}
+
+// RetryPolicyConfig defines the fallback retry policy and how stored policies
+// are cached
+type RetryPolicyConfig struct {
+	// Defaults applies when no stored policy matches a failure. Zero limits
+	// take the DefaultRetryConfig values.
+	Defaults RetryConfig
+	// CacheTTL bounds how long a replica keeps using policies changed elsewhere
+	CacheTTL time.Duration
+}
+
+// DefaultRetryPolicyConfig returns default retry policy configuration
+func DefaultRetryPolicyConfig() RetryPolicyConfig {
+	return RetryPolicyConfig{
+		Defaults: *DefaultRetryConfig(),
+		CacheTTL: 30 * time.Second,
+	}
+}
+
+// NewRetryPolicyUsecase creates a new retry policy use case
+func NewRetryPolicyUsecase(policyRepo domain.RetryPolicyRepository, supplierRepo domain.SupplierRepository, config RetryPolicyConfig) domain.RetryPolicyUsecase {
+	defaults := DefaultRetryConfig()
+	if config.Defaults.MaxAttempts <= 0 {
+		config.Defaults.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.Defaults.InitialDelay <= 0 {
+		config.Defaults.InitialDelay = defaults.InitialDelay
+	}
+	if config.Defaults.MaxDelay < config.Defaults.InitialDelay {
+		config.Defaults.MaxDelay = max(defaults.MaxDelay, config.Defaults.InitialDelay)
+	}
+	if config.Defaults.BackoffMultiplier < 1 {
+		config.Defaults.BackoffMultiplier = defaults.BackoffMultiplier
+	}
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = DefaultRetryPolicyConfig().CacheTTL
+	}
+
+	return &retryPolicyUsecase{
+		policyRepo:   policyRepo,
+		supplierRepo: supplierRepo,
+		config:       config,
+	}
+}
+
+// CreatePolicy validates and stores a new retry policy
+func (uc *retryPolicyUsecase) CreatePolicy(policy *domain.RetryPolicy) error {
+	if policy == nil {
+		return fmt.Errorf("retry policy payload is required")
+	}
+
+	policy.ErrorClass = strings.ToUpper(strings.TrimSpace(policy.ErrorClass))
+	if policy.SupplierID != nil {
+		supplierID := strings.TrimSpace(*policy.SupplierID)
+		if supplierID == "" {
+			policy.SupplierID = nil
+		} else {
+			if _, err := uc.supplierRepo.GetByID(supplierID); err != nil {
+				return fmt.Errorf("supplier not found")
+			}
+			policy.SupplierID = &supplierID
+		}
+	}
+
+	if err := policy.Check(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	policy.ID = utils.GenerateUUID()
+	policy.IsActive = true
+	policy.CreatedAt = now
+	policy.UpdatedAt = now
+
+	if err := uc.policyRepo.Create(policy); err != nil {
+		return err
+	}
+	uc.invalidate()
+	return nil
+}
+
+// UpdatePolicy changes the limits or state of a policy. Its supplier and
+// error class cannot change.
+func (uc *retryPolicyUsecase) UpdatePolicy(id string, updates *domain.RetryPolicyUpdate) (*domain.RetryPolicy, error) {
+	if updates == nil {
+		return nil, fmt.Errorf("retry policy payload is required")
+	}
+
+	policy, err := uc.policyRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if updates.MaxAttempts != nil {
+		policy.MaxAttempts = *updates.MaxAttempts
+	}
+	if updates.InitialDelayMs != nil {
+		policy.InitialDelayMs = *updates.InitialDelayMs
+	}
+	if updates.MaxDelayMs != nil {
+		policy.MaxDelayMs = *updates.MaxDelayMs
+	}
+	if updates.BackoffMultiplier != nil {
+		policy.BackoffMultiplier = *updates.BackoffMultiplier
+	}
+	if updates.EnableJitter != nil {
+		policy.EnableJitter = *updates.EnableJitter
+	}
+	if updates.IsActive != nil {
+		policy.IsActive = *updates.IsActive
+	}
+
+	if err := policy.Check(); err != nil {
+		return nil, err
+	}
+	policy.UpdatedAt = time.Now()
+
+	if err := uc.policyRepo.Update(policy); err != nil {
+		return nil, err
+	}
+	uc.invalidate()
+	return policy, nil
+}
+
+// DeletePolicy removes a retry policy
+func (uc *retryPolicyUsecase) DeletePolicy(id string) error {
+	if err := uc.policyRepo.Delete(id); err != nil {
+		return err
+	}
+	uc.invalidate()
+	return nil
+}
+
+// GetPolicy returns a retry policy by ID
+func (uc *retryPolicyUsecase) GetPolicy(id string) (*domain.RetryPolicy, error) {
+	return uc.policyRepo.GetByID(id)
+}
+
+// ListPolicies lists retry policies, optionally of one supplier
+func (uc *retryPolicyUsecase) ListPolicies(supplierID string) ([]*domain.RetryPolicy, error) {
+	return uc.policyRepo.List(strings.TrimSpace(supplierID))
+}
+
+// ResolvePolicy returns the policy applying to a failure of errorClass at
+// supplierID ("" when the transaction was never routed)
+func (uc *retryPolicyUsecase) ResolvePolicy(supplierID, errorClass string) (*domain.RetryPolicy, error) {
+	errorClass = strings.ToUpper(strings.TrimSpace(errorClass))
+	if errorClass == "" {
+		errorClass = domain.RetryErrorAny
+	}
+	if !domain.IsValidRetryErrorClass(errorClass) {
+		return nil, fmt.Errorf("invalid retry error class")
+	}
+
+	policies, err := uc.activePolicies()
+	if err != nil {
+		return nil, err
+	}
+
+	var resolved *domain.RetryPolicy
+	best := 0
+	for _, policy := range policies {
+		if rank := policyRank(policy, supplierID, errorClass); rank > best {
+			resolved, best = policy, rank
+		}
+	}
+	if resolved != nil {
+		return resolved, nil
+	}
+
+	defaults := uc.config.Defaults
+	return &domain.RetryPolicy{
+		ErrorClass:        errorClass,
+		MaxAttempts:       defaults.MaxAttempts,
+		InitialDelayMs:    int(defaults.InitialDelay.Milliseconds()),
+		MaxDelayMs:        int(defaults.MaxDelay.Milliseconds()),
+		BackoffMultiplier: defaults.BackoffMultiplier,
+		EnableJitter:      defaults.EnableJitter,
+		IsActive:          true,
+	}, nil
+}
+
+// policyRank scores how specifically a policy matches a failure, 0 when it
+// does not apply. The supplier weighs more than the error class.
+func policyRank(policy *domain.RetryPolicy, supplierID, errorClass string) int {
+	rank := 1
+	switch {
+	case policy.SupplierID == nil:
+	case supplierID != "" && *policy.SupplierID == supplierID:
+		rank += 2
+	default:
+		return 0
+	}
+
+	switch policy.ErrorClass {
+	case errorClass:
+		rank++
+	case domain.RetryErrorAny:
+	default:
+		return 0
+	}
+
+	return rank
+}
+
+// activePolicies returns the active policies, refreshed at most every CacheTTL
+func (uc *retryPolicyUsecase) activePolicies() ([]*domain.RetryPolicy, error) {
+	now := time.Now()
+
+	uc.mu.Lock()
+	if now.Before(uc.activeExpiresAt) {
+		policies := uc.active
+		uc.mu.Unlock()
+		return policies, nil
+	}
+	uc.mu.Unlock()
+
+	policies, err := uc.policyRepo.ListActive()
+	if err != nil {
+		return nil, err
+	}
+
+	uc.mu.Lock()
+	uc.active = policies
+	uc.activeExpiresAt = now.Add(uc.config.CacheTTL)
+	uc.mu.Unlock()
+
+	return policies, nil
+}
+
+func (uc *retryPolicyUsecase) invalidate() {
+	uc.mu.Lock()
+	uc.activeExpiresAt = time.Time{}
+	uc.mu.Unlock()
+}
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/alfanzaky/eraflazz/internal/domain"
@@ -13,6 +14,8 @@ type retryUsecase struct {
 	transactionRepo domain.TransactionRepository
 	supplierRepo    domain.SupplierRepository
 	smartRoutingUC  *smartRoutingUsecase
+	timelineRepo    domain.TransactionTimelineRepository
+	policyUC        domain.RetryPolicyUsecase
 }
 
 // NewRetryUsecase creates a new retry use case
@@ -20,11 +23,15 @@ func NewRetryUsecase(
 	transactionRepo domain.TransactionRepository,
 	supplierRepo domain.SupplierRepository,
 	smartRoutingUC *smartRoutingUsecase,
+	timelineRepo domain.TransactionTimelineRepository,
+	policyUC domain.RetryPolicyUsecase,
 ) *retryUsecase {
 	return &retryUsecase{
 		transactionRepo: transactionRepo,
 		supplierRepo:    supplierRepo,
 		smartRoutingUC:  smartRoutingUC,
+		timelineRepo:    timelineRepo,
+		policyUC:        policyUC,
 	}
 }
 
@@ -35,6 +42,7 @@ type RetryConfig struct {
 	MaxDelay          time.Duration // Maximum delay between retries
 	BackoffMultiplier float64       // Multiplier for exponential backoff
 	TimeoutPerAttempt time.Duration // Timeout for each attempt
+	MaxAge            time.Duration // Transactions older than this are not retried
 	EnableJitter      bool          // Add random jitter to prevent thundering herd
 }
 
@@ -46,6 +54,7 @@ func DefaultRetryConfig() *RetryConfig {
 		MaxDelay:          30 * time.Second,
 		BackoffMultiplier: 2.0,
 		TimeoutPerAttempt: 30 * time.Second,
+		MaxAge:            24 * time.Hour,
 		EnableJitter:      true,
 	}
 }
@@ -73,21 +82,23 @@ type RetryAttempt struct {
 	Success        bool
 	Error          error
 	ResponseTimeMs int
+	SupplierCost   float64 // Mapping price plus additional fee, 0 when unknown
 	Reason         string
 }
 
-// RetryTransaction implements intelligent retry logic with failover
+// RetryTransaction implements intelligent retry logic with failover. A nil
+// config applies the retry policy of the transaction's supplier and failure.
 func (uc *retryUsecase) RetryTransaction(transactionID string, config *RetryConfig) (*RetryResult, error) {
-	if config == nil {
-		config = DefaultRetryConfig()
-	}
-
 	// Get transaction
 	transaction, err := uc.transactionRepo.GetByID(transactionID)
 	if err != nil {
 		return nil, fmt.Errorf("transaction not found: %w", err)
 	}
 
+	if config == nil {
+		config = uc.transactionConfig(transaction)
+	}
+
 	// Check if transaction can be retried
 	if !uc.canRetryTransaction(transaction, config) {
 		return &RetryResult{
@@ -107,11 +118,11 @@ func (uc *retryUsecase) RetryTransaction(transactionID string, config *RetryConf
 	startTime := time.Now()
 	result := &RetryResult{
 		AttemptHistory: make([]*RetryAttempt, 0),
-		RefundAmount:   transaction.SellingPrice,
+		RefundAmount:   transaction.TotalAmount(),
 	}
 
 	// Get available suppliers for failover
-	suppliers, err := uc.getFailoverSuppliers(transaction.ProductID, config.MaxAttempts)
+	suppliers, err := uc.getFailoverSuppliers(transaction.ProductID, transaction.UserID, config.MaxAttempts)
 	if err != nil {
 		logger.Error("Failed to get failover suppliers",
 			logger.String("trx_id", transactionID),
@@ -207,6 +218,48 @@ func (uc *retryUsecase) RetryTransaction(transactionID string, config *RetryConf
 	return result, nil
 }
 
+// resolveConfig returns the retry config of the policy applying to a failure
+// of errorClass at supplierID, falling back to DefaultRetryConfig
+func (uc *retryUsecase) resolveConfig(supplierID, errorClass string) *RetryConfig {
+	config := DefaultRetryConfig()
+	if uc.policyUC == nil {
+		return config
+	}
+
+	policy, err := uc.policyUC.ResolvePolicy(supplierID, errorClass)
+	if err != nil {
+		logger.Warn("Failed to resolve retry policy, using defaults",
+			logger.String("supplier_id", supplierID),
+			logger.String("error_class", errorClass),
+			logger.ErrorField(err),
+		)
+		return config
+	}
+
+	config.MaxAttempts = policy.MaxAttempts
+	config.InitialDelay = time.Duration(policy.InitialDelayMs) * time.Millisecond
+	config.MaxDelay = time.Duration(policy.MaxDelayMs) * time.Millisecond
+	config.BackoffMultiplier = policy.BackoffMultiplier
+	config.EnableJitter = policy.EnableJitter
+	return config
+}
+
+// transactionConfig resolves the retry config of a failed transaction from
+// its last supplier and how that supplier failed
+func (uc *retryUsecase) transactionConfig(transaction *domain.Transaction) *RetryConfig {
+	supplierID := ""
+	if transaction.SupplierID != nil {
+		supplierID = *transaction.SupplierID
+	}
+
+	errorClass := domain.RetryErrorFailure
+	if transaction.Status == domain.StatusTimeout {
+		errorClass = domain.RetryErrorTimeout
+	}
+
+	return uc.resolveConfig(supplierID, errorClass)
+}
+
 // canRetryTransaction checks if a transaction can be retried
 func (uc *retryUsecase) canRetryTransaction(transaction *domain.Transaction, config *RetryConfig) bool {
 	// Check if transaction is in a retryable state
@@ -219,8 +272,16 @@ func (uc *retryUsecase) canRetryTransaction(transaction *domain.Transaction, con
 		return false
 	}
 
-	// Check if transaction is not too old (optional - can be configured)
-	maxAge := 24 * time.Hour // Default: don't retry transactions older than 24 hours
+	// Request-level supplier failures (e.g. wrong destination number) fail everywhere
+	if failedPermanently(uc.timelineRepo, transaction.ID) {
+		return false
+	}
+
+	// Check if transaction is not too old
+	maxAge := config.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultRetryConfig().MaxAge
+	}
 	if time.Since(transaction.CreatedAt) > maxAge {
 		return false
 	}
@@ -228,13 +289,41 @@ func (uc *retryUsecase) canRetryTransaction(transaction *domain.Transaction, con
 	return true
 }
 
+// failedPermanently reports whether the latest supplier attempt of a transaction
+// was classified as a permanent failure
+func failedPermanently(timelineRepo domain.TransactionTimelineRepository, transactionID string) bool {
+	if timelineRepo == nil {
+		return false
+	}
+
+	entries, err := timelineRepo.ListByTransactionID(transactionID)
+	if err != nil {
+		logger.Warn("Failed to read transaction timeline",
+			logger.String("trx_id", transactionID),
+			logger.ErrorField(err),
+		)
+		return false
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].EventType != domain.TimelineSupplierAttempt {
+			continue
+		}
+		classification, _ := entries[i].Details["classification"].(string)
+		return classification == domain.SupplierResultPermanent
+	}
+
+	return false
+}
+
 // getFailoverSuppliers gets suppliers for failover, excluding previously tried ones
-func (uc *retryUsecase) getFailoverSuppliers(productID string, maxCount int) ([]*domain.Supplier, error) {
+func (uc *retryUsecase) getFailoverSuppliers(productID, userID string, maxCount int) ([]*domain.Supplier, error) {
 	// Get best suppliers using smart routing
 	result, err := uc.smartRoutingUC.GetBestSupplier(productID, &RoutingCriteria{
 		MaxSuppliers:   maxCount,
 		PreferReliable: true,
 		MinSuccessRate: 50.0,
+		UserID:         userID,
 	})
 	if err != nil {
 		return nil, err
@@ -271,8 +360,19 @@ func (uc *retryUsecase) executeRetryAttempt(
 		logger.Int("attempt", attemptNumber),
 	)
 
-	// Update transaction with current supplier
+	// Update transaction with current supplier; HPP follows it so a
+	// successful retry books the cost of the supplier that delivered
 	transaction.SupplierID = &supplier.ID
+	if cost, err := uc.supplierCost(transaction.ProductID, supplier.ID); err != nil {
+		logger.Warn("Failed to get supplier cost for retry attempt",
+			logger.String("trx_id", transaction.ID),
+			logger.String("supplier_code", supplier.Code),
+			logger.ErrorField(err),
+		)
+	} else {
+		transaction.HPP = cost
+		attempt.SupplierCost = cost
+	}
 	transaction.Status = domain.StatusProcessing
 	now := time.Now()
 	transaction.ProcessedAt = &now
@@ -294,6 +394,8 @@ func (uc *retryUsecase) executeRetryAttempt(
 	attempt.Success = success
 	attempt.Error = err
 
+	uc.appendRetryAttempt(transaction, supplier, attempt)
+
 	if success {
 		// Update transaction to success
 		serialNumber := utils.GenerateRandomString(12)
@@ -331,6 +433,36 @@ func (uc *retryUsecase) executeRetryAttempt(
 	return attempt
 }
 
+// supplierCost returns what the supplier charges for the product: its
+// mapping price plus additional fee
+func (uc *retryUsecase) supplierCost(productID, supplierID string) (float64, error) {
+	mapping, err := uc.smartRoutingUC.productMappingRepo.GetByProductAndSupplier(productID, supplierID)
+	if err != nil {
+		return 0, err
+	}
+	return mapping.GetEffectivePrice(), nil
+}
+
+// appendRetryAttempt records a retry attempt on the transaction timeline
+func (uc *retryUsecase) appendRetryAttempt(transaction *domain.Transaction, supplier *domain.Supplier, attempt *RetryAttempt) {
+	message := attempt.Reason
+	if attempt.Error != nil {
+		message = attempt.Error.Error()
+	}
+
+	entry := domain.NewTransactionTimelineEntry(transaction, domain.TimelineSupplierAttempt, message, map[string]interface{}{
+		"supplier_code":    supplier.Code,
+		"supplier_cost":    attempt.SupplierCost,
+		"response_time_ms": attempt.ResponseTimeMs,
+		"success":          attempt.Success,
+		"retry":            true,
+	})
+	entry.SupplierID = &supplier.ID
+	attemptNumber := attempt.AttemptNumber
+	entry.Attempt = &attemptNumber
+	appendTimelineEntry(uc.timelineRepo, entry)
+}
+
 // simulateSupplierCall simulates a supplier API call (replace with actual implementation)
 func (uc *retryUsecase) simulateSupplierCall(supplier *domain.Supplier, transaction *domain.Transaction, timeout time.Duration) (bool, int, error) {
 	// Simulate network delay
@@ -361,9 +493,8 @@ func (uc *retryUsecase) simulateSupplierCall(supplier *domain.Supplier, transact
 
 // calculateRetryDelay calculates delay between retry attempts with exponential backoff
 func (uc *retryUsecase) calculateRetryDelay(attempt int, config *RetryConfig) time.Duration {
-	multiplier := 1 << (attempt - 1) // This is integer bit shift
 	delay := time.Duration(float64(config.InitialDelay) *
-		float64(multiplier) * config.BackoffMultiplier)
+		math.Pow(config.BackoffMultiplier, float64(attempt-1)))
 
 	// Cap at max delay
 	if delay > config.MaxDelay {
@@ -392,6 +523,9 @@ func (uc *retryUsecase) issueRefund(transaction *domain.Transaction) error {
 	if err != nil {
 		return fmt.Errorf("failed to update transaction for refund: %w", err)
 	}
+	appendTimelineEntry(uc.timelineRepo, domain.NewTransactionTimelineEntry(transaction, domain.TimelineRefunded, msg, map[string]interface{}{
+		"amount": transaction.TotalAmount(),
+	}))
 
 	// TODO: Implement actual balance refund logic
 	// This should create a mutation and update user balance
@@ -399,7 +533,7 @@ func (uc *retryUsecase) issueRefund(transaction *domain.Transaction) error {
 	logger.Info("Refund issued for failed transaction",
 		logger.String("trx_id", transaction.ID),
 		logger.String("trx_code", transaction.TrxCode),
-		logger.Float64("amount", transaction.SellingPrice),
+		logger.Float64("amount", transaction.TotalAmount()),
 	)
 
 	return nil
@@ -434,7 +568,7 @@ func (uc *retryUsecase) GetRetryStatistics(startDate, endDate time.Time) (*Retry
 				if trx.Status == domain.StatusSuccess {
 					stats.SuccessfulRetries++
 				} else if trx.Status == domain.StatusRefund {
-					stats.TotalRefundAmount += trx.SellingPrice
+					stats.TotalRefundAmount += trx.TotalAmount()
 				}
 			}
 		}
@@ -458,7 +592,8 @@ type RetryStatistics struct {
 	RetrySuccessRate        float64
 }
 
-// ProcessFailedTransactions processes all failed transactions that are eligible for retry
+// ProcessFailedTransactions processes all failed transactions that are eligible
+// for retry. A nil config applies the retry policy of each transaction.
 func (uc *retryUsecase) ProcessFailedTransactions(config *RetryConfig) ([]*RetryResult, error) {
 	// Get all failed transactions
 	failedTransactions, err := uc.transactionRepo.GetByStatus(domain.StatusFailed)
@@ -469,8 +604,12 @@ func (uc *retryUsecase) ProcessFailedTransactions(config *RetryConfig) ([]*Retry
 	results := make([]*RetryResult, 0)
 
 	for _, transaction := range failedTransactions {
-		if uc.canRetryTransaction(transaction, config) {
-			result, err := uc.RetryTransaction(transaction.ID, config)
+		trxConfig := config
+		if trxConfig == nil {
+			trxConfig = uc.transactionConfig(transaction)
+		}
+		if uc.canRetryTransaction(transaction, trxConfig) {
+			result, err := uc.RetryTransaction(transaction.ID, trxConfig)
 			if err != nil {
 				logger.Error("Failed to retry transaction",
 					logger.String("trx_id", transaction.ID),
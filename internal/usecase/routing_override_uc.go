@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+type routingOverrideUsecase struct {
+	overrideRepo domain.RoutingOverrideRepository
+	productRepo  domain.ProductRepository
+	supplierRepo domain.SupplierRepository
+	catalogUC    domain.CatalogUsecase
+}
+
+// NewRoutingOverrideUsecase creates a new routing override use case
+func NewRoutingOverrideUsecase(
+	overrideRepo domain.RoutingOverrideRepository,
+	productRepo domain.ProductRepository,
+	supplierRepo domain.SupplierRepository,
+	catalogUC domain.CatalogUsecase,
+) domain.RoutingOverrideUsecase {
+	return &routingOverrideUsecase{
+		overrideRepo: overrideRepo,
+		productRepo:  productRepo,
+		supplierRepo: supplierRepo,
+		catalogUC:    catalogUC,
+	}
+}
+
+// CreateOverride validates and stores a new pin/exclude rule
+func (uc *routingOverrideUsecase) CreateOverride(override *domain.RoutingOverride) error {
+	if override == nil {
+		return fmt.Errorf("override payload is required")
+	}
+
+	override.ScopeType = strings.ToUpper(strings.TrimSpace(override.ScopeType))
+	override.Action = strings.ToUpper(strings.TrimSpace(override.Action))
+	override.ScopeValue = strings.TrimSpace(override.ScopeValue)
+
+	if !domain.IsValidOverrideScope(override.ScopeType) {
+		return fmt.Errorf("invalid override scope")
+	}
+	if !domain.IsValidOverrideAction(override.Action) {
+		return fmt.Errorf("invalid override action")
+	}
+
+	switch override.ScopeType {
+	case domain.OverrideScopeProduct:
+		if _, err := uc.productRepo.GetByID(override.ScopeValue); err != nil {
+			return fmt.Errorf("product not found")
+		}
+	case domain.OverrideScopeCategory:
+		override.ScopeValue = strings.ToUpper(override.ScopeValue)
+		if err := uc.catalogUC.ValidateCategory(override.ScopeValue); err != nil {
+			return err
+		}
+	}
+
+	if _, err := uc.supplierRepo.GetByID(override.SupplierID); err != nil {
+		return fmt.Errorf("supplier not found")
+	}
+
+	override.ID = utils.GenerateUUID()
+	override.IsActive = true
+	override.CreatedAt = time.Now()
+	override.UpdatedAt = time.Now()
+
+	return uc.overrideRepo.Create(override)
+}
+
+// UpdateOverride toggles an override or changes its reason
+func (uc *routingOverrideUsecase) UpdateOverride(id string, isActive *bool, reason *string) (*domain.RoutingOverride, error) {
+	override, err := uc.overrideRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if isActive != nil {
+		override.IsActive = *isActive
+	}
+	if reason != nil {
+		override.Reason = reason
+	}
+	override.UpdatedAt = time.Now()
+
+	if err := uc.overrideRepo.Update(override); err != nil {
+		return nil, err
+	}
+
+	return override, nil
+}
+
+// DeleteOverride removes an override
+func (uc *routingOverrideUsecase) DeleteOverride(id string) error {
+	return uc.overrideRepo.Delete(id)
+}
+
+// GetOverride returns an override by ID
+func (uc *routingOverrideUsecase) GetOverride(id string) (*domain.RoutingOverride, error) {
+	return uc.overrideRepo.GetByID(id)
+}
+
+// ListOverrides lists overrides, optionally filtered by scope
+func (uc *routingOverrideUsecase) ListOverrides(scopeType, scopeValue string) ([]*domain.RoutingOverride, error) {
+	return uc.overrideRepo.List(strings.ToUpper(scopeType), scopeValue)
+}
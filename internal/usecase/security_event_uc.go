@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+const (
+	defaultSecurityEventLimit = 50
+	maxSecurityEventLimit     = 500
+)
+
+type securityEventUsecase struct {
+	securityEventRepo domain.SecurityEventRepository
+	alertPublisher    domain.EventPublisher
+	config            SecurityAlertConfig
+
+	mu          sync.Mutex
+	lastAlertAt map[string]time.Time
+}
+
+// SecurityAlertConfig defines denial burst alerting parameters
+type SecurityAlertConfig struct {
+	// Threshold is the number of denials within Window that triggers an alert
+	Threshold int
+	// Window is the sliding window used to count denials per user or IP
+	Window time.Duration
+	// Cooldown suppresses repeated alerts for the same user or IP
+	Cooldown time.Duration
+	// Timeout bounds the alert delivery
+	Timeout time.Duration
+}
+
+// DefaultSecurityAlertConfig returns default denial burst alerting configuration
+func DefaultSecurityAlertConfig() SecurityAlertConfig {
+	return SecurityAlertConfig{
+		Threshold: 10,
+		Window:    5 * time.Minute,
+		Cooldown:  15 * time.Minute,
+		Timeout:   10 * time.Second,
+	}
+}
+
+// NewSecurityEventUsecase creates a new security event use case. Burst alerting
+// is disabled when alertPublisher is nil.
+func NewSecurityEventUsecase(
+	securityEventRepo domain.SecurityEventRepository,
+	alertPublisher domain.EventPublisher,
+	config SecurityAlertConfig,
+) domain.SecurityEventUsecase {
+	defaults := DefaultSecurityAlertConfig()
+	if config.Threshold <= 0 {
+		config.Threshold = defaults.Threshold
+	}
+	if config.Window <= 0 {
+		config.Window = defaults.Window
+	}
+	if config.Cooldown < 0 {
+		config.Cooldown = defaults.Cooldown
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+
+	return &securityEventUsecase{
+		securityEventRepo: securityEventRepo,
+		alertPublisher:    alertPublisher,
+		config:            config,
+		lastAlertAt:       make(map[string]time.Time),
+	}
+}
+
+// RecordDenial stores an access denial and raises an alert when the same user
+// (or anonymous IP) crosses the burst threshold
+func (uc *securityEventUsecase) RecordDenial(event *domain.SecurityEvent) error {
+	event.ID = utils.GenerateUUID()
+	event.EventType = domain.SecurityEventAccessDenied
+	event.CreatedAt = time.Now()
+
+	if err := uc.securityEventRepo.Create(event); err != nil {
+		return err
+	}
+
+	if uc.alertPublisher == nil {
+		return nil
+	}
+
+	since := event.CreatedAt.Add(-uc.config.Window)
+	count, err := uc.securityEventRepo.CountSince(domain.SecurityEventAccessDenied, event.UserID, event.IPAddress, since)
+	if err != nil {
+		return err
+	}
+	if count < uc.config.Threshold {
+		return nil
+	}
+
+	subject := "ip:" + event.IPAddress
+	if event.UserID != nil {
+		subject = "user:" + *event.UserID
+	}
+	if !uc.claimAlert(subject, event.CreatedAt) {
+		return nil
+	}
+
+	uc.publishBurstAlert(subject, event, count)
+	return nil
+}
+
+// ListEvents returns the most recent security events
+func (uc *securityEventUsecase) ListEvents(filter *domain.SecurityEventFilter) ([]*domain.SecurityEvent, error) {
+	if filter == nil {
+		filter = &domain.SecurityEventFilter{}
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultSecurityEventLimit
+	}
+	if filter.Limit > maxSecurityEventLimit {
+		filter.Limit = maxSecurityEventLimit
+	}
+	return uc.securityEventRepo.List(filter)
+}
+
+// claimAlert reports whether an alert may be sent for the subject, recording
+// the send time so the cooldown applies to subsequent bursts
+func (uc *securityEventUsecase) claimAlert(subject string, now time.Time) bool {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if last, ok := uc.lastAlertAt[subject]; ok && now.Sub(last) < uc.config.Cooldown {
+		return false
+	}
+
+	// Drop expired entries so the map does not grow unbounded
+	for key, last := range uc.lastAlertAt {
+		if now.Sub(last) >= uc.config.Cooldown {
+			delete(uc.lastAlertAt, key)
+		}
+	}
+	uc.lastAlertAt[subject] = now
+
+	return true
+}
+
+func (uc *securityEventUsecase) publishBurstAlert(subject string, event *domain.SecurityEvent, count int) {
+	alert, err := domain.NewDomainEvent(domain.EventSecurityDenialBurst, domain.AggregateTypeSecurity, subject, domain.SecurityBurstPayload{
+		UserID:     event.UserID,
+		IPAddress:  event.IPAddress,
+		Denials:    count,
+		Window:     uc.config.Window.String(),
+		LastReason: event.Reason,
+		LastPath:   event.Resource,
+		DetectedAt: event.CreatedAt,
+	})
+	if err != nil {
+		logger.Error("Failed to build security alert", logger.ErrorField(err))
+		return
+	}
+
+	logger.Warn("Access denial burst detected",
+		logger.String("subject", subject),
+		logger.Int("denials", count),
+		logger.Duration("window", uc.config.Window),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), uc.config.Timeout)
+	defer cancel()
+
+	if err := uc.alertPublisher.Publish(ctx, alert); err != nil {
+		logger.Error("Failed to deliver security alert",
+			logger.String("publisher", uc.alertPublisher.Name()),
+			logger.String("subject", subject),
+			logger.ErrorField(err),
+		)
+	}
+}
@@ -2,28 +2,77 @@ package usecase
 
 import (
 	"fmt"
+	"math/rand/v2"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/alfanzaky/eraflazz/internal/domain"
 	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
 )
 
 type smartRoutingUsecase struct {
 	productRepo        domain.ProductRepository
 	supplierRepo       domain.SupplierRepository
 	productMappingRepo domain.ProductMappingRepository
+	overrideRepo       domain.RoutingOverrideRepository
+	cutoffUC           domain.CutoffUsecase
+	routingCache       domain.RoutingCacheRepository
+	flags              domain.FeatureFlagEvaluator
+	config             SmartRoutingConfig
 }
 
-// NewSmartRoutingUsecase creates a new smart routing use case
+// SmartRoutingConfig defines tunable parameters for smart routing
+type SmartRoutingConfig struct {
+	// PriorityBlendWeight is the share (0.0 - 1.0) of the auto-tuned mapping
+	// priority blended with the supplier priority. 0 disables auto-tuning.
+	PriorityBlendWeight float64
+	// CacheTTL is how long the routing snapshot of a product is cached.
+	// Mapping and supplier changes invalidate it earlier; supplier metrics
+	// and auto-tuned priorities are only refreshed when it expires.
+	CacheTTL time.Duration
+	// SpreadAccounts spreads the traffic of a supplier over its accounts
+	// (suppliers sharing an adapter type), weighted by balance and success
+	// rate, and fails over to its other accounts before the next supplier.
+	// With feature flags the routing.spread_accounts flag decides instead.
+	SpreadAccounts bool
+}
+
+// DefaultSmartRoutingConfig returns default smart routing configuration
+func DefaultSmartRoutingConfig() SmartRoutingConfig {
+	return SmartRoutingConfig{
+		PriorityBlendWeight: 0.5,
+		CacheTTL:            30 * time.Second,
+		SpreadAccounts:      true,
+	}
+}
+
+// NewSmartRoutingUsecase creates a new smart routing use case. flags may be
+// nil.
 func NewSmartRoutingUsecase(
 	productRepo domain.ProductRepository,
 	supplierRepo domain.SupplierRepository,
 	productMappingRepo domain.ProductMappingRepository,
+	overrideRepo domain.RoutingOverrideRepository,
+	cutoffUC domain.CutoffUsecase,
+	routingCache domain.RoutingCacheRepository,
+	flags domain.FeatureFlagEvaluator,
+	config SmartRoutingConfig,
 ) *smartRoutingUsecase {
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = DefaultSmartRoutingConfig().CacheTTL
+	}
+
 	return &smartRoutingUsecase{
 		productRepo:        productRepo,
 		supplierRepo:       supplierRepo,
 		productMappingRepo: productMappingRepo,
+		overrideRepo:       overrideRepo,
+		cutoffUC:           cutoffUC,
+		routingCache:       routingCache,
+		flags:              flags,
+		config:             config,
 	}
 }
 
@@ -34,6 +83,7 @@ type RoutingResult struct {
 	Confidence       float64 // 0.0 to 1.0
 	Reason           string
 	Alternatives     []*domain.Supplier // Backup suppliers
+	Scores           []*SupplierScore   // Every supplier scored, in routing order
 }
 
 // RoutingCriteria defines criteria for routing decision
@@ -44,37 +94,72 @@ type RoutingCriteria struct {
 	PreferReliable bool    // Prefer highest success rate
 	MaxSuppliers   int     // Maximum number of suppliers to consider
 	MinSuccessRate float64 // Minimum success rate threshold
+	UserID         string  // Owner of the routed transaction, keys feature flag rollouts
+}
+
+// defaultRoutingCriteria returns the criteria routing transactions of userID
+func defaultRoutingCriteria(userID string) *RoutingCriteria {
+	return &RoutingCriteria{
+		PreferCheapest: true,
+		PreferReliable: true,
+		MaxSuppliers:   5,
+		MinSuccessRate: 50.0,
+		UserID:         userID,
+	}
 }
 
 // GetBestSupplier finds the best supplier for a product using smart routing
 func (uc *smartRoutingUsecase) GetBestSupplier(productID string, criteria *RoutingCriteria) (*RoutingResult, error) {
-	// Get product mappings for this product
-	mappings, err := uc.productMappingRepo.GetActiveMappings(productID)
+	log := logger.Component(logger.ComponentRouting)
+
+	// Mappings and suppliers come from the routing cache when it holds them
+	snapshot, err := uc.loadRoutingSnapshot(productID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get product mappings: %w", err)
+		return nil, err
 	}
+	mappings := snapshot.Mappings
 
 	if len(mappings) == 0 {
 		return nil, fmt.Errorf("no active mappings found for product")
 	}
 
-	// Get supplier information for each mapping
-	suppliers := make([]*domain.Supplier, 0, len(mappings))
-	supplierMap := make(map[string]*domain.Supplier)
+	// Admin pin/exclude overrides take precedence over scoring
+	policy, err := uc.resolveRoutingPolicy(productID)
+	if err != nil {
+		return nil, err
+	}
 
+	supplierIDs := make([]string, 0, len(mappings))
 	for _, mapping := range mappings {
-		supplier, err := uc.supplierRepo.GetByID(mapping.SupplierID)
-		if err != nil {
-			logger.Warn("Failed to get supplier for mapping",
+		if !policy.Allows(mapping.SupplierID) {
+			log.Debug("Skipping supplier due to routing override",
+				logger.String("product_id", productID),
 				logger.String("supplier_id", mapping.SupplierID),
-				logger.ErrorField(err),
+			)
+			continue
+		}
+		supplierIDs = append(supplierIDs, mapping.SupplierID)
+	}
+
+	supplierMap := make(map[string]*domain.Supplier, len(snapshot.Suppliers))
+	for _, supplier := range snapshot.Suppliers {
+		supplierMap[supplier.ID] = supplier
+	}
+
+	// Keep mapping order and drop missing or unhealthy suppliers
+	suppliers := make([]*domain.Supplier, 0, len(supplierIDs))
+	for _, supplierID := range supplierIDs {
+		supplier, ok := supplierMap[supplierID]
+		if !ok {
+			log.Warn("Supplier for mapping not found",
+				logger.String("supplier_id", supplierID),
 			)
 			continue
 		}
 
 		// Check if supplier is healthy
 		if !supplier.IsHealthy() {
-			logger.Debug("Skipping unhealthy supplier",
+			log.Debug("Skipping unhealthy supplier",
 				logger.String("supplier_id", supplier.ID),
 				logger.String("supplier_code", supplier.Code),
 			)
@@ -82,27 +167,34 @@ func (uc *smartRoutingUsecase) GetBestSupplier(productID string, criteria *Routi
 		}
 
 		suppliers = append(suppliers, supplier)
-		supplierMap[supplier.ID] = supplier
+	}
+
+	// Suppliers in a cutoff window cannot take transactions until it ends
+	suppliers, cutoff, err := uc.dropSuppliersInCutoff(suppliers)
+	if err != nil {
+		return nil, err
+	}
+	if len(suppliers) == 0 && cutoff != nil {
+		return nil, cutoff
 	}
 
 	if len(suppliers) == 0 {
+		if policy.HasPins() {
+			return nil, fmt.Errorf("pinned supplier unavailable")
+		}
 		return nil, fmt.Errorf("no healthy suppliers available")
 	}
 
 	// Apply default criteria if not provided
 	if criteria == nil {
-		criteria = &RoutingCriteria{
-			PreferCheapest: true,
-			PreferReliable: true,
-			MaxSuppliers:   5,
-			MinSuccessRate: 50.0,
-		}
+		criteria = defaultRoutingCriteria("")
 	}
 
 	// Score suppliers based on criteria
+	lowest, highest := adminPriorityRange(suppliers, mappings)
 	scores := make([]*SupplierScore, 0, len(suppliers))
 	for _, supplier := range suppliers {
-		score := uc.calculateSupplierScore(supplier, mappings, criteria)
+		score := uc.calculateSupplierScore(supplier, mappings, criteria, lowest, highest)
 		scores = append(scores, score)
 	}
 
@@ -110,6 +202,9 @@ func (uc *smartRoutingUsecase) GetBestSupplier(productID string, criteria *Routi
 	sort.Slice(scores, func(i, j int) bool {
 		return scores[i].TotalScore > scores[j].TotalScore
 	})
+	if uc.spreadsAccounts(criteria.UserID) {
+		scores = spreadAccounts(scores)
+	}
 
 	// Get the best supplier
 	bestScore := scores[0]
@@ -130,25 +225,187 @@ func (uc *smartRoutingUsecase) GetBestSupplier(productID string, criteria *Routi
 		alternatives = append(alternatives, scores[i].Supplier)
 	}
 
+	reason := bestScore.Reason
+	if policy.HasPins() {
+		reason = fmt.Sprintf("pinned by %s override, %s", strings.ToLower(policy.PinScope), reason)
+	}
+
 	result := &RoutingResult{
 		SelectedSupplier: bestSupplier,
 		SelectedMapping:  bestMapping,
 		Confidence:       bestScore.Confidence,
-		Reason:           bestScore.Reason,
+		Reason:           reason,
 		Alternatives:     alternatives,
+		Scores:           scores,
 	}
 
-	logger.Info("Smart routing decision made",
+	log.Debug("Smart routing decision made",
 		logger.String("product_id", productID),
 		logger.String("selected_supplier", bestSupplier.Code),
 		logger.Float64("confidence", bestScore.Confidence),
-		logger.String("reason", bestScore.Reason),
+		logger.String("reason", reason),
 		logger.Int("alternatives_count", len(alternatives)),
 	)
 
 	return result, nil
 }
 
+// loadRoutingSnapshot returns the active mappings of a product and their
+// suppliers, from the routing cache or else from the database. A snapshot
+// loaded from the database is cached; cache errors only cost the lookup.
+func (uc *smartRoutingUsecase) loadRoutingSnapshot(productID string) (*domain.RoutingSnapshot, error) {
+	log := logger.Component(logger.ComponentRouting)
+
+	if uc.routingCache != nil {
+		snapshot, err := uc.routingCache.GetSnapshot(productID)
+		if err != nil {
+			log.Warn("Failed to read routing cache",
+				logger.String("product_id", productID),
+				logger.ErrorField(err),
+			)
+		} else if snapshot != nil {
+			return snapshot, nil
+		}
+	}
+
+	mappings, err := uc.productMappingRepo.GetActiveMappings(productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product mappings: %w", err)
+	}
+
+	// Load the suppliers of all mappings in one query; overrides are applied
+	// per request so the snapshot holds every mapped supplier
+	supplierIDs := make([]string, 0, len(mappings))
+	for _, mapping := range mappings {
+		supplierIDs = append(supplierIDs, mapping.SupplierID)
+	}
+	suppliers, err := uc.supplierRepo.GetByIDs(supplierIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get suppliers for mappings: %w", err)
+	}
+
+	snapshot := &domain.RoutingSnapshot{
+		ProductID: productID,
+		Mappings:  mappings,
+		Suppliers: suppliers,
+		CachedAt:  time.Now(),
+	}
+
+	// An empty snapshot is not cached so a new mapping routes at once
+	if uc.routingCache != nil && len(mappings) > 0 {
+		if err := uc.routingCache.SetSnapshot(snapshot, uc.config.CacheTTL); err != nil {
+			log.Warn("Failed to write routing cache",
+				logger.String("product_id", productID),
+				logger.ErrorField(err),
+			)
+		}
+	}
+
+	return snapshot, nil
+}
+
+// RefreshRoutingCache drops the cached routing snapshot of a product and
+// loads it again from the database
+func (uc *smartRoutingUsecase) RefreshRoutingCache(productID string) error {
+	if uc.routingCache != nil {
+		if err := uc.routingCache.InvalidateProducts(productID); err != nil {
+			return err
+		}
+	}
+
+	_, err := uc.loadRoutingSnapshot(productID)
+	return err
+}
+
+// invalidateRoutingSupplier drops the routing snapshots of every product
+// mapped to a supplier after the supplier or its mappings changed
+func invalidateRoutingSupplier(routingCache domain.RoutingCacheRepository, supplierID string) {
+	if routingCache == nil {
+		return
+	}
+	if err := routingCache.InvalidateSupplier(supplierID); err != nil {
+		logger.Component(logger.ComponentRouting).Warn("Failed to invalidate routing cache of supplier",
+			logger.String("supplier_id", supplierID),
+			logger.ErrorField(err),
+		)
+	}
+}
+
+// invalidateRoutingProducts drops the routing snapshots of products after
+// their mappings changed
+func invalidateRoutingProducts(routingCache domain.RoutingCacheRepository, productIDs ...string) {
+	if routingCache == nil || len(productIDs) == 0 {
+		return
+	}
+	if err := routingCache.InvalidateProducts(productIDs...); err != nil {
+		logger.Component(logger.ComponentRouting).Warn("Failed to invalidate routing cache",
+			logger.Int("products", len(productIDs)),
+			logger.ErrorField(err),
+		)
+	}
+}
+
+// newRoutingDecision captures a routing result for the transaction; every
+// other scored supplier is kept as an alternative, best first
+func newRoutingDecision(transactionID, source string, result *RoutingResult) *domain.RoutingDecision {
+	decision := &domain.RoutingDecision{
+		ID:                  utils.GenerateUUID(),
+		TransactionID:       transactionID,
+		Source:              source,
+		SupplierID:          result.SelectedSupplier.ID,
+		SupplierCode:        result.SelectedSupplier.Code,
+		SupplierProductCode: result.SelectedMapping.SupplierProductCode,
+		SupplierPrice:       result.SelectedMapping.GetEffectivePrice(),
+		Confidence:          result.Confidence,
+		Reason:              result.Reason,
+		Alternatives:        make([]domain.RoutingCandidate, 0, len(result.Scores)),
+		CreatedAt:           time.Now(),
+	}
+
+	for _, score := range result.Scores {
+		if score.Supplier.ID == result.SelectedSupplier.ID {
+			decision.Score = score.TotalScore
+			continue
+		}
+		decision.Alternatives = append(decision.Alternatives, domain.RoutingCandidate{
+			SupplierID:   score.Supplier.ID,
+			SupplierCode: score.Supplier.Code,
+			Score:        score.TotalScore,
+			Confidence:   score.Confidence,
+			Reason:       score.Reason,
+		})
+	}
+
+	return decision
+}
+
+// resolveRoutingPolicy loads pin/exclude overrides for the product and its category
+func (uc *smartRoutingUsecase) resolveRoutingPolicy(productID string) (*domain.RoutingPolicy, error) {
+	if uc.overrideRepo == nil {
+		return domain.ResolveRoutingPolicy(nil), nil
+	}
+
+	category := ""
+	if uc.productRepo != nil {
+		product, err := uc.productRepo.GetByID(productID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get product for routing: %w", err)
+		}
+		category = product.Category
+	}
+
+	overrides, err := uc.overrideRepo.GetActiveForProduct(productID, category)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get routing overrides: %w", err)
+	}
+
+	return domain.ResolveRoutingPolicy(overrides), nil
+}
+
+// unreachablePenalty scales the score of a supplier whose last pings failed,
+// so it drops below reachable suppliers without being excluded outright
+const unreachablePenalty = 0.1
+
 // SupplierScore represents the scoring result for a supplier
 type SupplierScore struct {
 	Supplier   *domain.Supplier
@@ -158,11 +415,44 @@ type SupplierScore struct {
 	Breakdown  map[string]float64
 }
 
-// calculateSupplierScore calculates a comprehensive score for a supplier
+// adminPriority is the admin-set priority of routing a product to supplier:
+// the supplier priority plus the mapping's rank within the product, as set
+// by reordering
+func adminPriority(supplier *domain.Supplier, mapping *domain.ProductMapping) int {
+	return supplier.Priority + mapping.Priority - 1
+}
+
+// adminPriorityRange returns the lowest and highest admin-set priority among
+// the suppliers mapped to the product
+func adminPriorityRange(suppliers []*domain.Supplier, mappings []*domain.ProductMapping) (int, int) {
+	lowest, highest := 0, 0
+	found := false
+	for _, supplier := range suppliers {
+		for _, mapping := range mappings {
+			if mapping.SupplierID != supplier.ID {
+				continue
+			}
+			priority := adminPriority(supplier, mapping)
+			if !found || priority < lowest {
+				lowest = priority
+			}
+			if !found || priority > highest {
+				highest = priority
+			}
+			found = true
+			break
+		}
+	}
+	return lowest, highest
+}
+
+// calculateSupplierScore calculates a comprehensive score for a supplier.
+// lowest and highest bound the admin-set priorities of the candidates.
 func (uc *smartRoutingUsecase) calculateSupplierScore(
 	supplier *domain.Supplier,
 	mappings []*domain.ProductMapping,
 	criteria *RoutingCriteria,
+	lowest, highest int,
 ) *SupplierScore {
 	score := &SupplierScore{
 		Supplier:  supplier,
@@ -183,8 +473,12 @@ func (uc *smartRoutingUsecase) calculateSupplierScore(
 		return score
 	}
 
-	// Priority score (lower priority number = higher score)
-	priorityScore := 1.0 / float64(supplier.Priority)
+	// Priority score (lower priority number = higher score). The admin-set
+	// priority is normalized onto the 1-10 scale of the mapping's auto-tuned
+	// effective priority and blended with it. A blend weight of 0 keeps the
+	// admin-set ordering.
+	basePriority := domain.NormalizePriority(adminPriority(supplier, mapping), lowest, highest)
+	priorityScore := 1.0 / mapping.GetBlendedPriority(basePriority, uc.config.PriorityBlendWeight)
 	score.Breakdown["priority"] = priorityScore
 
 	// Success rate score
@@ -264,6 +558,13 @@ func (uc *smartRoutingUsecase) calculateSupplierScore(
 		}
 	}
 
+	// Down-rank suppliers the health worker could not reach, ahead of their
+	// stored success rate catching up
+	if !supplier.IsReachable {
+		totalScore *= unreachablePenalty
+		score.Breakdown["reachable"] = 0
+	}
+
 	score.TotalScore = totalScore
 
 	// Calculate confidence based on data availability and consistency
@@ -338,6 +639,10 @@ func (uc *smartRoutingUsecase) calculateConfidence(score *SupplierScore, supplie
 func (uc *smartRoutingUsecase) generateReason(score *SupplierScore, criteria *RoutingCriteria) string {
 	reasons := []string{}
 
+	if reachable, ok := score.Breakdown["reachable"]; ok && reachable == 0 {
+		reasons = append(reasons, "unreachable at last ping")
+	}
+
 	// Priority
 	if score.Breakdown["priority"] >= 0.8 {
 		reasons = append(reasons, "highest priority")
@@ -478,3 +783,186 @@ func (uc *smartRoutingUsecase) GetFallbackSuppliers(productID string, excludeSup
 
 	return fallbacks, nil
 }
+
+// GetFailoverRoute returns the best-scored supplier and mapping for a
+// product of userID that is not in excludeSupplierIDs
+func (uc *smartRoutingUsecase) GetFailoverRoute(productID, userID string, excludeSupplierIDs []string) (*RoutingResult, error) {
+	criteria := defaultRoutingCriteria(userID)
+	criteria.MaxSuppliers = len(excludeSupplierIDs) + 2 // Selected plus alternatives cover MaxSuppliers-1 suppliers
+	result, err := uc.GetBestSupplier(productID, criteria)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := make(map[string]bool, len(excludeSupplierIDs))
+	for _, id := range excludeSupplierIDs {
+		excluded[id] = true
+	}
+
+	candidates := append([]*domain.Supplier{result.SelectedSupplier}, result.Alternatives...)
+	for i, supplier := range candidates {
+		if excluded[supplier.ID] {
+			continue
+		}
+
+		mapping, err := uc.productMappingRepo.GetByProductAndSupplier(productID, supplier.ID)
+		if err != nil {
+			logger.Component(logger.ComponentRouting).Warn("Failed to get mapping for failover supplier",
+				logger.String("product_id", productID),
+				logger.String("supplier_id", supplier.ID),
+				logger.ErrorField(err),
+			)
+			continue
+		}
+
+		failover := &RoutingResult{
+			SelectedSupplier: supplier,
+			SelectedMapping:  mapping,
+			Alternatives:     candidates[i+1:],
+			Scores:           result.Scores,
+		}
+		if score := result.score(supplier.ID); score != nil {
+			failover.Confidence = score.Confidence
+			failover.Reason = "failover, " + score.Reason
+		}
+		return failover, nil
+	}
+
+	return nil, fmt.Errorf("no failover supplier available")
+}
+
+// dropSuppliersInCutoff removes suppliers in a cutoff window. When any was
+// removed it also returns a cutoff error ending when the first of them opens.
+func (uc *smartRoutingUsecase) dropSuppliersInCutoff(suppliers []*domain.Supplier) ([]*domain.Supplier, *domain.CutoffError, error) {
+	if uc.cutoffUC == nil || len(suppliers) == 0 {
+		return suppliers, nil, nil
+	}
+
+	ids := make([]string, 0, len(suppliers))
+	for _, supplier := range suppliers {
+		ids = append(ids, supplier.ID)
+	}
+	cutoffs, err := uc.cutoffUC.SupplierCutoffs(ids, time.Now())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check supplier cutoffs: %w", err)
+	}
+	if len(cutoffs) == 0 {
+		return suppliers, nil, nil
+	}
+
+	var earliest *domain.CutoffError
+	open := make([]*domain.Supplier, 0, len(suppliers))
+	for _, supplier := range suppliers {
+		active, inCutoff := cutoffs[supplier.ID]
+		if !inCutoff {
+			open = append(open, supplier)
+			continue
+		}
+
+		logger.Component(logger.ComponentRouting).Debug("Skipping supplier in cutoff",
+			logger.String("supplier_code", supplier.Code),
+			logger.String("until", active.Until.Format(time.RFC3339)),
+		)
+		if earliest == nil || active.Until.Before(earliest.Until) {
+			earliest = &domain.CutoffError{Until: active.Until}
+			if active.Schedule.Reason != nil {
+				earliest.Reason = *active.Schedule.Reason
+			}
+		}
+	}
+
+	return open, earliest, nil
+}
+
+// spreadsAccounts reports whether the transactions of userID are spread over
+// supplier accounts
+func (uc *smartRoutingUsecase) spreadsAccounts(userID string) bool {
+	if uc.flags == nil {
+		return uc.config.SpreadAccounts
+	}
+	return uc.flags.IsEnabled(domain.FlagRoutingSpreadAccounts, userID)
+}
+
+// spreadAccounts keeps the accounts of each supplier together at the rank of
+// the best scored one. Within a supplier the accounts are drawn at random,
+// weighted by accountWeight, so the leading account changes from one routing
+// decision to the next and failover tries the other accounts first.
+func spreadAccounts(scores []*SupplierScore) []*SupplierScore {
+	groups := make(map[string][]*SupplierScore, len(scores))
+	order := make([]string, 0, len(scores))
+	for _, score := range scores {
+		adapterType := strings.ToUpper(score.Supplier.GetAdapterType())
+		if _, exists := groups[adapterType]; !exists {
+			order = append(order, adapterType)
+		}
+		groups[adapterType] = append(groups[adapterType], score)
+	}
+	if len(order) == len(scores) {
+		return scores
+	}
+
+	spread := make([]*SupplierScore, 0, len(scores))
+	for _, adapterType := range order {
+		accounts := groups[adapterType]
+		if len(accounts) > 1 {
+			accounts = drawAccounts(accounts)
+			accounts[0].Reason = fmt.Sprintf("%s, spread over %d accounts", accounts[0].Reason, len(accounts))
+		}
+		spread = append(spread, accounts...)
+	}
+	return spread
+}
+
+// drawAccounts orders accounts by repeated weighted draws without
+// replacement. When no account has any weight the score order is kept.
+func drawAccounts(accounts []*SupplierScore) []*SupplierScore {
+	remaining := append([]*SupplierScore(nil), accounts...)
+	drawn := make([]*SupplierScore, 0, len(accounts))
+	for len(remaining) > 0 {
+		total := 0.0
+		for _, account := range remaining {
+			total += accountWeight(account.Supplier)
+		}
+
+		pick := 0
+		if total > 0 {
+			target := rand.Float64() * total
+			for ; pick < len(remaining)-1; pick++ {
+				target -= accountWeight(remaining[pick].Supplier)
+				if target < 0 {
+					break
+				}
+			}
+		}
+
+		drawn = append(drawn, remaining[pick])
+		remaining = append(remaining[:pick], remaining[pick+1:]...)
+	}
+	return drawn
+}
+
+// accountWeight is the share of traffic an account should take: its balance
+// above the minimum threshold times its success rate. An account the health
+// worker could not reach keeps a small share so it recovers its metrics.
+func accountWeight(supplier *domain.Supplier) float64 {
+	headroom := supplier.Balance - supplier.MinBalanceThreshold
+	if headroom <= 0 {
+		return 0
+	}
+
+	weight := headroom * supplier.SuccessRate / 100.0
+	if !supplier.IsReachable {
+		weight *= unreachablePenalty
+	}
+	return weight
+}
+
+// score returns the score of a supplier considered by the routing decision
+func (r *RoutingResult) score(supplierID string) *SupplierScore {
+	for _, score := range r.Scores {
+		if score.Supplier.ID == supplierID {
+			return score
+		}
+	}
+	return nil
+}
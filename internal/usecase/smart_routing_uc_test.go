@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+)
+
+// fakeRoutingCache serves a fixed routing snapshot
+type fakeRoutingCache struct {
+	snapshot *domain.RoutingSnapshot
+}
+
+func (c *fakeRoutingCache) GetSnapshot(productID string) (*domain.RoutingSnapshot, error) {
+	return c.snapshot, nil
+}
+
+func (c *fakeRoutingCache) SetSnapshot(snapshot *domain.RoutingSnapshot, ttl time.Duration) error {
+	c.snapshot = snapshot
+	return nil
+}
+
+func (c *fakeRoutingCache) InvalidateProducts(productIDs ...string) error { return nil }
+
+func (c *fakeRoutingCache) InvalidateSupplier(supplierID string) error { return nil }
+
+func newRoutingSupplier(id, code string) *domain.Supplier {
+	return &domain.Supplier{
+		ID:                id,
+		Code:              code,
+		Priority:          1,
+		IsActive:          true,
+		IsReachable:       true,
+		SuccessRate:       95,
+		AvgResponseTimeMs: 1000,
+	}
+}
+
+func TestGetBestSupplierFollowsMappingOrder(t *testing.T) {
+	tests := []struct {
+		name         string
+		priorities   map[string]int // mapping priority per supplier ID
+		wantSupplier string
+	}{
+		{name: "first mapping wins", priorities: map[string]int{"sup-a": 1, "sup-b": 2}, wantSupplier: "sup-a"},
+		{name: "reordered mapping wins", priorities: map[string]int{"sup-a": 2, "sup-b": 1}, wantSupplier: "sup-b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := &domain.RoutingSnapshot{
+				ProductID: "product-1",
+				Suppliers: []*domain.Supplier{newRoutingSupplier("sup-a", "SUPA"), newRoutingSupplier("sup-b", "SUPB")},
+			}
+			for _, supplierID := range []string{"sup-a", "sup-b"} {
+				snapshot.Mappings = append(snapshot.Mappings, &domain.ProductMapping{
+					ID:            "mapping-" + supplierID,
+					ProductID:     "product-1",
+					SupplierID:    supplierID,
+					SupplierPrice: 9000,
+					Priority:      tt.priorities[supplierID],
+					IsActive:      true,
+					StockStatus:   domain.StockStatusAvailable,
+				})
+			}
+
+			uc := NewSmartRoutingUsecase(nil, nil, nil, nil, nil, &fakeRoutingCache{snapshot: snapshot}, nil, DefaultSmartRoutingConfig())
+			result, err := uc.GetBestSupplier("product-1", nil)
+			if err != nil {
+				t.Fatalf("GetBestSupplier() unexpected error: %v", err)
+			}
+			if result.SelectedSupplier.ID != tt.wantSupplier {
+				t.Errorf("GetBestSupplier() selected %s, want %s", result.SelectedSupplier.ID, tt.wantSupplier)
+			}
+		})
+	}
+}
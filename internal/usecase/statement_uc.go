@@ -0,0 +1,484 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/pdf"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+// maxStatementJobAttempts is how many times a job is claimed before it is
+// marked failed (covers workers that crash mid-generation)
+const maxStatementJobAttempts = 3
+
+var statementMonthNames = []string{
+	"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember",
+}
+
+type statementUsecase struct {
+	statementRepo domain.StatementRepository
+	userRepo      domain.UserRepository
+	config        StatementConfig
+	location      *time.Location
+}
+
+// StatementConfig defines account statement parameters
+type StatementConfig struct {
+	// Timezone is the IANA timezone used to cut months
+	Timezone string
+	// SyncMaxRows is the largest statement (mutations + transactions) rendered
+	// within the request; bigger statements are generated by a background job
+	SyncMaxRows int
+	// BatchSize is how many queued jobs one worker tick claims
+	BatchSize int
+	// StaleAfter is when a processing job is considered abandoned and reclaimed
+	StaleAfter time.Duration
+}
+
+// DefaultStatementConfig returns default statement configuration
+func DefaultStatementConfig() StatementConfig {
+	return StatementConfig{
+		Timezone:    "Asia/Jakarta",
+		SyncMaxRows: 2000,
+		BatchSize:   5,
+		StaleAfter:  15 * time.Minute,
+	}
+}
+
+// NewStatementUsecase creates a new account statement use case
+func NewStatementUsecase(
+	statementRepo domain.StatementRepository,
+	userRepo domain.UserRepository,
+	config StatementConfig,
+) domain.StatementUsecase {
+	defaults := DefaultStatementConfig()
+	if config.Timezone == "" {
+		config.Timezone = defaults.Timezone
+	}
+	if config.SyncMaxRows <= 0 {
+		config.SyncMaxRows = defaults.SyncMaxRows
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.StaleAfter <= 0 {
+		config.StaleAfter = defaults.StaleAfter
+	}
+
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		logger.Warn("Invalid statement timezone, falling back to UTC",
+			logger.String("timezone", config.Timezone),
+			logger.ErrorField(err),
+		)
+		config.Timezone = "UTC"
+		location = time.UTC
+	}
+
+	return &statementUsecase{
+		statementRepo: statementRepo,
+		userRepo:      userRepo,
+		config:        config,
+		location:      location,
+	}
+}
+
+// RequestStatement renders a monthly statement directly when it is small, or
+// queues (or returns the existing) background job for it otherwise
+func (uc *statementUsecase) RequestStatement(userID string, year, month int, format string) (*domain.StatementResult, error) {
+	format = strings.ToUpper(strings.TrimSpace(format))
+	if !domain.IsValidStatementFormat(format) {
+		return nil, fmt.Errorf("invalid statement format")
+	}
+
+	start, end, err := uc.period(year, month)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := uc.statementRepo.CountEntries(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	if count <= uc.config.SyncMaxRows {
+		file, _, err := uc.generate(userID, year, month, format)
+		if err != nil {
+			return nil, err
+		}
+		return &domain.StatementResult{File: file}, nil
+	}
+
+	job, err := uc.statementRepo.GetLatestJob(userID, year, month, format)
+	if err != nil && err.Error() != "statement job not found" {
+		return nil, err
+	}
+	if job != nil && uc.reusable(job, end) {
+		return &domain.StatementResult{Job: job}, nil
+	}
+
+	job = &domain.StatementJob{
+		ID:       utils.GenerateUUID(),
+		UserID:   userID,
+		Year:     year,
+		Month:    month,
+		Format:   format,
+		Status:   domain.StatementJobPending,
+		RowCount: count,
+	}
+	if err := uc.statementRepo.CreateJob(job); err != nil {
+		// A concurrent request queued the same statement first
+		if strings.Contains(err.Error(), "duplicate key") {
+			existing, getErr := uc.statementRepo.GetLatestJob(userID, year, month, format)
+			if getErr != nil {
+				return nil, getErr
+			}
+			return &domain.StatementResult{Job: existing}, nil
+		}
+		return nil, err
+	}
+
+	logger.Info("Statement job queued",
+		logger.String("job_id", job.ID),
+		logger.String("user_id", userID),
+		logger.Int("year", year),
+		logger.Int("month", month),
+		logger.String("format", format),
+		logger.Int("row_count", count),
+	)
+
+	return &domain.StatementResult{Job: job}, nil
+}
+
+// GetJob returns a statement job owned by the user
+func (uc *statementUsecase) GetJob(userID, jobID string) (*domain.StatementJob, error) {
+	job, err := uc.statementRepo.GetJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.UserID != userID {
+		return nil, fmt.Errorf("statement job not found")
+	}
+	return job, nil
+}
+
+// DownloadJob returns the generated file of a ready statement job owned by the user
+func (uc *statementUsecase) DownloadJob(userID, jobID string) (*domain.StatementFile, error) {
+	job, err := uc.GetJob(userID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != domain.StatementJobReady {
+		return nil, fmt.Errorf("statement is not ready")
+	}
+
+	content, err := uc.statementRepo.GetJobContent(job.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	fileName := statementFileName(job.Year, job.Month, job.Format)
+	if job.FileName != nil {
+		fileName = *job.FileName
+	}
+
+	return &domain.StatementFile{
+		FileName:    fileName,
+		ContentType: statementContentType(job.Format),
+		Content:     content,
+	}, nil
+}
+
+// ProcessPendingJobs generates the files of queued statement jobs
+func (uc *statementUsecase) ProcessPendingJobs(ctx context.Context) (int, error) {
+	jobs, err := uc.statementRepo.ClaimJobs(uc.config.BatchSize, uc.config.StaleAfter)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			return processed, ctx.Err()
+		}
+
+		if job.Attempts > maxStatementJobAttempts {
+			if err := uc.statementRepo.FailJob(job.ID, "statement generation abandoned too many times"); err != nil {
+				logger.Error("Failed to mark statement job failed", logger.String("job_id", job.ID), logger.ErrorField(err))
+			}
+			continue
+		}
+
+		started := time.Now()
+		file, rowCount, err := uc.generate(job.UserID, job.Year, job.Month, job.Format)
+		if err != nil {
+			logger.Error("Failed to generate statement",
+				logger.String("job_id", job.ID),
+				logger.ErrorField(err),
+			)
+			if failErr := uc.statementRepo.FailJob(job.ID, err.Error()); failErr != nil {
+				logger.Error("Failed to mark statement job failed", logger.String("job_id", job.ID), logger.ErrorField(failErr))
+			}
+			continue
+		}
+
+		if err := uc.statementRepo.CompleteJob(job.ID, file.FileName, rowCount, file.Content); err != nil {
+			logger.Error("Failed to store statement", logger.String("job_id", job.ID), logger.ErrorField(err))
+			continue
+		}
+
+		processed++
+		logger.Info("Statement generated",
+			logger.String("job_id", job.ID),
+			logger.String("user_id", job.UserID),
+			logger.Int("row_count", rowCount),
+			logger.Int("size_bytes", len(file.Content)),
+			logger.Duration("duration", time.Since(started)),
+		)
+	}
+
+	return processed, nil
+}
+
+// reusable reports whether an existing job can answer a new request: queued
+// and running jobs always can, ready jobs only when generated after the month closed
+func (uc *statementUsecase) reusable(job *domain.StatementJob, end time.Time) bool {
+	switch job.Status {
+	case domain.StatementJobPending, domain.StatementJobProcessing:
+		return true
+	case domain.StatementJobReady:
+		return !job.CreatedAt.Before(end)
+	default:
+		return false
+	}
+}
+
+// period returns the [start, end) bounds of a month in the statement timezone
+func (uc *statementUsecase) period(year, month int) (time.Time, time.Time, error) {
+	if month < 1 || month > 12 || year < 2000 {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid statement period")
+	}
+
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, uc.location)
+	if start.After(time.Now()) {
+		return time.Time{}, time.Time{}, fmt.Errorf("statement period is in the future")
+	}
+
+	return start, start.AddDate(0, 1, 0), nil
+}
+
+// generate builds and renders a statement, returning the file and its row count
+func (uc *statementUsecase) generate(userID string, year, month int, format string) (*domain.StatementFile, int, error) {
+	statement, err := uc.buildStatement(userID, year, month)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var content []byte
+	switch format {
+	case domain.StatementFormatCSV:
+		content, err = uc.renderCSV(statement)
+	default:
+		content = uc.renderPDF(statement)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return &domain.StatementFile{
+		FileName:    statementFileName(year, month, format),
+		ContentType: statementContentType(format),
+		Content:     content,
+	}, len(statement.Mutations) + len(statement.Transactions), nil
+}
+
+func (uc *statementUsecase) buildStatement(userID string, year, month int) (*domain.Statement, error) {
+	start, end, err := uc.period(year, month)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := uc.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	opening, err := uc.statementRepo.GetOpeningBalance(userID, start)
+	if err != nil {
+		return nil, err
+	}
+
+	mutations, err := uc.statementRepo.ListMutations(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := uc.statementRepo.ListTransactions(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &domain.Statement{
+		User:           user,
+		Year:           year,
+		Month:          month,
+		PeriodStart:    start,
+		PeriodEnd:      end,
+		OpeningBalance: opening,
+		ClosingBalance: opening,
+		Mutations:      mutations,
+		Transactions:   transactions,
+		GeneratedAt:    time.Now().In(uc.location),
+	}
+
+	for _, mutation := range mutations {
+		switch mutation.Type {
+		case domain.MutationTypeDebit:
+			statement.TotalDebit += mutation.Amount
+		case domain.MutationTypeCredit:
+			statement.TotalCredit += mutation.Amount
+		}
+		statement.ClosingBalance = mutation.BalanceAfter
+	}
+
+	return statement, nil
+}
+
+func (uc *statementUsecase) renderCSV(statement *domain.Statement) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	rows := [][]string{{
+		"record_type", "created_at", "reference", "description", "type",
+		"amount", "admin_fee", "balance_after", "status",
+	}}
+	rows = append(rows, []string{
+		"OPENING_BALANCE", statement.PeriodStart.Format(time.RFC3339), "", "", "",
+		"", "", utils.FormatAmount(statement.OpeningBalance), "",
+	})
+	for _, mutation := range statement.Mutations {
+		rows = append(rows, []string{
+			"MUTATION", mutation.CreatedAt.In(uc.location).Format(time.RFC3339), mutation.ID,
+			mutation.Description, mutation.Type, utils.FormatAmount(mutation.Amount), "",
+			utils.FormatAmount(mutation.BalanceAfter), "",
+		})
+	}
+	for _, trx := range statement.Transactions {
+		rows = append(rows, []string{
+			"TRANSACTION", trx.CreatedAt.In(uc.location).Format(time.RFC3339), trx.TrxCode,
+			trx.ProductCode + " " + trx.DestinationNumber, "", utils.FormatAmount(trx.SellingPrice),
+			utils.FormatAmount(trx.AdminFee), "", trx.Status,
+		})
+	}
+	rows = append(rows, []string{
+		"CLOSING_BALANCE", statement.PeriodEnd.Format(time.RFC3339), "", "", "",
+		"", "", utils.FormatAmount(statement.ClosingBalance), "",
+	})
+
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, fmt.Errorf("failed to write statement csv: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (uc *statementUsecase) renderPDF(statement *domain.Statement) []byte {
+	const rowSize = 7.5
+	period := fmt.Sprintf("%s %d", statementMonthNames[statement.Month-1], statement.Year)
+	doc := pdf.NewDocument("Rekening Koran " + period)
+	width := pdf.MonoColumns(rowSize)
+
+	name := statement.User.Username
+	if statement.User.FullName != nil && *statement.User.FullName != "" {
+		name = *statement.User.FullName + " (" + statement.User.Username + ")"
+	}
+
+	doc.Line(pdf.FontBold, 16, "Rekening Koran Saldo")
+	doc.Line(pdf.FontRegular, 10, "Periode: "+period+" ("+uc.config.Timezone+")")
+	doc.Line(pdf.FontRegular, 10, "Akun: "+name+" - "+statement.User.Email)
+	doc.Line(pdf.FontRegular, 10, "Dibuat: "+utils.FormatTime(statement.GeneratedAt))
+	doc.Space(8)
+
+	success, failed := 0, 0
+	for _, trx := range statement.Transactions {
+		switch trx.Status {
+		case domain.StatusSuccess:
+			success++
+		case domain.StatusFailed, domain.StatusRefund:
+			failed++
+		}
+	}
+
+	doc.Line(pdf.FontBold, 11, "Ringkasan")
+	for _, line := range [][2]string{
+		{"Saldo awal", utils.FormatCurrency(statement.OpeningBalance)},
+		{"Total masuk (debit)", utils.FormatCurrency(statement.TotalDebit)},
+		{"Total keluar (kredit)", utils.FormatCurrency(statement.TotalCredit)},
+		{"Saldo akhir", utils.FormatCurrency(statement.ClosingBalance)},
+		{"Transaksi", fmt.Sprintf("%d (sukses %d, gagal/refund %d)", len(statement.Transactions), success, failed)},
+	} {
+		doc.Line(pdf.FontMono, 9, fmt.Sprintf("%-24s %s", line[0], line[1]))
+	}
+	doc.Space(8)
+
+	doc.Line(pdf.FontBold, 11, "Mutasi Saldo")
+	doc.Line(pdf.FontMono, rowSize, fitColumns(width, fmt.Sprintf("%-16s %-6s %15s %15s  %s", "Tanggal", "Tipe", "Jumlah", "Saldo", "Keterangan")))
+	if len(statement.Mutations) == 0 {
+		doc.Line(pdf.FontRegular, 9, "Tidak ada mutasi pada periode ini.")
+	}
+	for _, mutation := range statement.Mutations {
+		doc.Line(pdf.FontMono, rowSize, fitColumns(width, fmt.Sprintf("%-16s %-6s %15s %15s  %s",
+			mutation.CreatedAt.In(uc.location).Format("2006-01-02 15:04"),
+			mutation.Type,
+			utils.FormatAmount(mutation.Amount),
+			utils.FormatAmount(mutation.BalanceAfter),
+			mutation.Description,
+		)))
+	}
+	doc.Space(8)
+
+	doc.Line(pdf.FontBold, 11, "Transaksi")
+	doc.Line(pdf.FontMono, rowSize, fitColumns(width, fmt.Sprintf("%-16s %-22s %-12s %-15s %12s %-10s", "Tanggal", "Kode", "Produk", "Tujuan", "Total", "Status")))
+	if len(statement.Transactions) == 0 {
+		doc.Line(pdf.FontRegular, 9, "Tidak ada transaksi pada periode ini.")
+	}
+	for _, trx := range statement.Transactions {
+		doc.Line(pdf.FontMono, rowSize, fitColumns(width, fmt.Sprintf("%-16s %-22s %-12s %-15s %12s %-10s",
+			trx.CreatedAt.In(uc.location).Format("2006-01-02 15:04"),
+			trx.TrxCode,
+			trx.ProductCode,
+			trx.DestinationNumber,
+			utils.FormatAmount(trx.TotalAmount()),
+			trx.Status,
+		)))
+	}
+
+	return doc.Bytes()
+}
+
+// fitColumns truncates a monospace row to the page width
+func fitColumns(width int, row string) string {
+	if len(row) <= width {
+		return row
+	}
+	return row[:width-3] + "..."
+}
+
+func statementFileName(year, month int, format string) string {
+	return fmt.Sprintf("statement-%d-%02d.%s", year, month, strings.ToLower(format))
+}
+
+func statementContentType(format string) string {
+	if format == domain.StatementFormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/pdf"
+}
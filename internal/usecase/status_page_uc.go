@@ -0,0 +1,352 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+type statusPageUsecase struct {
+	incidentRepo domain.StatusIncidentRepository
+	categoryRepo domain.ProductCategoryRepository
+	queueRepo    domain.QueueRepository
+	config       StatusPageConfig
+
+	mu        sync.Mutex
+	cached    *domain.PublicStatus
+	expiresAt time.Time
+}
+
+// StatusPageConfig defines how the public status is derived from recent
+// transactions and the queue backlog
+type StatusPageConfig struct {
+	// Window is the rolling period whose transactions give the success rates
+	Window time.Duration
+	// MinSamples finished transactions a category needs before its success
+	// rate counts; quieter categories are reported operational
+	MinSamples int
+	// DegradedBelow and DownBelow are success rate percentages
+	DegradedBelow float64
+	DownBelow     float64
+	// QueueDegradedBacklog and QueueDownBacklog are queued order counts that
+	// degrade or take down every category
+	QueueDegradedBacklog int64
+	QueueDownBacklog     int64
+	// IncidentHistory is how long resolved incidents stay listed
+	IncidentHistory time.Duration
+	MaxIncidents    int
+	// CacheTTL is how long a computed status is served
+	CacheTTL time.Duration
+}
+
+// DefaultStatusPageConfig returns default status page configuration
+func DefaultStatusPageConfig() StatusPageConfig {
+	return StatusPageConfig{
+		Window:               15 * time.Minute,
+		MinSamples:           10,
+		DegradedBelow:        90,
+		DownBelow:            50,
+		QueueDegradedBacklog: 500,
+		QueueDownBacklog:     5000,
+		IncidentHistory:      7 * 24 * time.Hour,
+		MaxIncidents:         20,
+		CacheTTL:             30 * time.Second,
+	}
+}
+
+// NewStatusPageUsecase creates a new status page use case
+func NewStatusPageUsecase(
+	incidentRepo domain.StatusIncidentRepository,
+	categoryRepo domain.ProductCategoryRepository,
+	queueRepo domain.QueueRepository,
+	config StatusPageConfig,
+) domain.StatusPageUsecase {
+	defaults := DefaultStatusPageConfig()
+	if config.Window <= 0 {
+		config.Window = defaults.Window
+	}
+	if config.MinSamples <= 0 {
+		config.MinSamples = defaults.MinSamples
+	}
+	if config.DegradedBelow <= 0 {
+		config.DegradedBelow = defaults.DegradedBelow
+	}
+	if config.DownBelow <= 0 || config.DownBelow > config.DegradedBelow {
+		config.DownBelow = min(defaults.DownBelow, config.DegradedBelow)
+	}
+	if config.QueueDegradedBacklog <= 0 {
+		config.QueueDegradedBacklog = defaults.QueueDegradedBacklog
+	}
+	if config.QueueDownBacklog < config.QueueDegradedBacklog {
+		config.QueueDownBacklog = max(defaults.QueueDownBacklog, config.QueueDegradedBacklog)
+	}
+	if config.IncidentHistory <= 0 {
+		config.IncidentHistory = defaults.IncidentHistory
+	}
+	if config.MaxIncidents <= 0 {
+		config.MaxIncidents = defaults.MaxIncidents
+	}
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = defaults.CacheTTL
+	}
+
+	return &statusPageUsecase{
+		incidentRepo: incidentRepo,
+		categoryRepo: categoryRepo,
+		queueRepo:    queueRepo,
+		config:       config,
+	}
+}
+
+// GetPublicStatus returns the cached status, recomputing it once expired.
+// When recomputing fails the previous status keeps being served.
+func (uc *statusPageUsecase) GetPublicStatus() (*domain.PublicStatus, error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	now := time.Now()
+	if uc.cached != nil && now.Before(uc.expiresAt) {
+		return uc.cached, nil
+	}
+
+	status, err := uc.computeStatus(now)
+	if err != nil {
+		if uc.cached != nil {
+			logger.Warn("Failed to refresh public status, serving the previous one", logger.ErrorField(err))
+			return uc.cached, nil
+		}
+		return nil, err
+	}
+
+	uc.cached = status
+	uc.expiresAt = now.Add(uc.config.CacheTTL)
+	return status, nil
+}
+
+// computeStatus rates every active category by its success rate over the
+// window, the queue backlog and the open incidents affecting it; the worst
+// of the three wins
+func (uc *statusPageUsecase) computeStatus(now time.Time) (*domain.PublicStatus, error) {
+	categories, err := uc.categoryRepo.List(true)
+	if err != nil {
+		return nil, err
+	}
+	outcomes, err := uc.incidentRepo.GetCategoryOutcomes(now.Add(-uc.config.Window), now)
+	if err != nil {
+		return nil, err
+	}
+	incidents, err := uc.incidentRepo.List(now.Add(-uc.config.IncidentHistory), uc.config.MaxIncidents)
+	if err != nil {
+		return nil, err
+	}
+
+	byCategory := make(map[string]*domain.CategoryOutcome, len(outcomes))
+	for _, outcome := range outcomes {
+		byCategory[outcome.Category] = outcome
+	}
+
+	status := &domain.PublicStatus{
+		Status:      domain.ServiceOperational,
+		QueueStatus: uc.queueStatus(),
+		Categories:  make([]*domain.CategoryStatus, 0, len(categories)),
+		Incidents:   incidents,
+		Window:      uc.config.Window.String(),
+		UpdatedAt:   now,
+	}
+
+	for _, category := range categories {
+		entry := &domain.CategoryStatus{
+			Code:   category.Code,
+			Name:   category.Name,
+			Status: status.QueueStatus,
+		}
+
+		if outcome := byCategory[category.Code]; outcome != nil {
+			finished := outcome.SuccessCount + outcome.FailedCount
+			entry.Samples = finished
+			if finished >= uc.config.MinSamples {
+				rate := float64(outcome.SuccessCount) / float64(finished) * 100
+				entry.SuccessRate = &rate
+				entry.Status = domain.WorseStatus(entry.Status, uc.rateStatus(rate))
+			}
+		}
+
+		for _, incident := range incidents {
+			if incident.ResolvedAt == nil && incident.Affects(category.Code) {
+				entry.Status = domain.WorseStatus(entry.Status, incident.Impact)
+			}
+		}
+
+		status.Status = domain.WorseStatus(status.Status, entry.Status)
+		status.Categories = append(status.Categories, entry)
+	}
+
+	return status, nil
+}
+
+func (uc *statusPageUsecase) rateStatus(rate float64) string {
+	switch {
+	case rate < uc.config.DownBelow:
+		return domain.ServiceDown
+	case rate < uc.config.DegradedBelow:
+		return domain.ServiceDegraded
+	default:
+		return domain.ServiceOperational
+	}
+}
+
+// queueStatus rates the backlog of orders waiting for a worker. An unreadable
+// queue means orders cannot be processed either.
+func (uc *statusPageUsecase) queueStatus() string {
+	backlog, err := uc.queueRepo.GetQueueLength()
+	if err != nil {
+		logger.Error("Failed to read queue backlog for the status page", logger.ErrorField(err))
+		return domain.ServiceDown
+	}
+
+	switch {
+	case backlog >= uc.config.QueueDownBacklog:
+		return domain.ServiceDown
+	case backlog >= uc.config.QueueDegradedBacklog:
+		return domain.ServiceDegraded
+	default:
+		return domain.ServiceOperational
+	}
+}
+
+// CreateIncident validates and stores a new incident
+func (uc *statusPageUsecase) CreateIncident(incident *domain.StatusIncident) error {
+	incident.Title = strings.TrimSpace(incident.Title)
+	incident.Message = strings.TrimSpace(incident.Message)
+	if incident.Title == "" || incident.Message == "" {
+		return fmt.Errorf("title and message are required")
+	}
+	if !domain.IsValidIncidentImpact(incident.Impact) {
+		return fmt.Errorf("impact must be degraded or down")
+	}
+
+	categories, err := uc.normalizeCategories(incident.Categories)
+	if err != nil {
+		return err
+	}
+	incident.Categories = categories
+	if incident.StartedAt.IsZero() {
+		incident.StartedAt = time.Now()
+	}
+
+	if err := uc.incidentRepo.Create(incident); err != nil {
+		return err
+	}
+
+	uc.invalidate()
+	logger.Info("Status incident created",
+		logger.String("incident_id", incident.ID),
+		logger.String("impact", incident.Impact),
+		logger.Any("categories", incident.Categories),
+	)
+
+	return nil
+}
+
+// UpdateIncident changes an incident; resolving it stamps the resolution time
+func (uc *statusPageUsecase) UpdateIncident(id string, updates *domain.StatusIncidentUpdate) (*domain.StatusIncident, error) {
+	incident, err := uc.incidentRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if updates.Title != nil {
+		incident.Title = strings.TrimSpace(*updates.Title)
+	}
+	if updates.Message != nil {
+		incident.Message = strings.TrimSpace(*updates.Message)
+	}
+	if incident.Title == "" || incident.Message == "" {
+		return nil, fmt.Errorf("title and message are required")
+	}
+	if updates.Impact != nil {
+		if !domain.IsValidIncidentImpact(*updates.Impact) {
+			return nil, fmt.Errorf("impact must be degraded or down")
+		}
+		incident.Impact = *updates.Impact
+	}
+	if updates.Categories != nil {
+		categories, err := uc.normalizeCategories(*updates.Categories)
+		if err != nil {
+			return nil, err
+		}
+		incident.Categories = categories
+	}
+	if updates.Resolved != nil {
+		switch {
+		case *updates.Resolved && incident.ResolvedAt == nil:
+			now := time.Now()
+			incident.ResolvedAt = &now
+		case !*updates.Resolved:
+			incident.ResolvedAt = nil
+		}
+	}
+
+	if err := uc.incidentRepo.Update(incident); err != nil {
+		return nil, err
+	}
+
+	uc.invalidate()
+	logger.Info("Status incident updated",
+		logger.String("incident_id", incident.ID),
+		logger.String("impact", incident.Impact),
+		logger.Bool("resolved", incident.ResolvedAt != nil),
+	)
+
+	return incident, nil
+}
+
+// ListIncidents lists every incident, newest first
+func (uc *statusPageUsecase) ListIncidents(limit int) ([]*domain.StatusIncident, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	return uc.incidentRepo.List(time.Time{}, limit)
+}
+
+// normalizeCategories uppercases the category codes and checks they exist
+func (uc *statusPageUsecase) normalizeCategories(codes []string) ([]string, error) {
+	if len(codes) == 0 {
+		return []string{}, nil
+	}
+
+	categories, err := uc.categoryRepo.List(false)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		known[category.Code] = true
+	}
+
+	normalized := make([]string, 0, len(codes))
+	seen := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if !known[code] {
+			return nil, fmt.Errorf("unknown category %q", code)
+		}
+		if !seen[code] {
+			seen[code] = true
+			normalized = append(normalized, code)
+		}
+	}
+
+	return normalized, nil
+}
+
+// invalidate drops the cached status so incident changes show immediately
+func (uc *statusPageUsecase) invalidate() {
+	uc.mu.Lock()
+	uc.cached = nil
+	uc.mu.Unlock()
+}
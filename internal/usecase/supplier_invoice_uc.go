@@ -0,0 +1,503 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+type supplierInvoiceUsecase struct {
+	invoiceRepo  domain.SupplierInvoiceRepository
+	supplierRepo domain.SupplierRepository
+	config       SupplierInvoiceConfig
+	location     *time.Location
+}
+
+// SupplierInvoiceConfig defines limits and tolerances of invoice matching
+type SupplierInvoiceConfig struct {
+	MaxFileSize int64 // Bytes
+	MaxLines    int
+	// AmountTolerance is the largest difference between a billed amount and
+	// the transaction's HPP still counted as a match
+	AmountTolerance float64
+	// Timezone is the IANA timezone cutting the invoice period days
+	Timezone string
+}
+
+// DefaultSupplierInvoiceConfig returns default supplier invoice configuration
+func DefaultSupplierInvoiceConfig() SupplierInvoiceConfig {
+	return SupplierInvoiceConfig{
+		MaxFileSize:     10 << 20,
+		MaxLines:        100000,
+		AmountTolerance: 0.01,
+		Timezone:        "Asia/Jakarta",
+	}
+}
+
+// invoiceColumnAliases maps the header names suppliers use to our columns
+var invoiceColumnAliases = map[string][]string{
+	"supplier_trx_id": {"supplier_trx_id", "trx_id", "transaction_id", "id_transaksi"},
+	"ref_id":          {"ref_id", "refid", "reference", "ref"},
+	"amount":          {"amount", "price", "harga", "nominal", "total"},
+	"status":          {"status"},
+	"product_code":    {"product_code", "buyer_sku_code", "sku", "kode_produk"},
+	"destination":     {"destination", "customer_no", "tujuan", "nomor"},
+}
+
+// invoiceFailedStatuses mark lines the supplier lists but does not bill
+var invoiceFailedStatuses = []string{"fail", "gagal", "refund", "cancel", "batal"}
+
+// NewSupplierInvoiceUsecase creates a new supplier invoice use case
+func NewSupplierInvoiceUsecase(invoiceRepo domain.SupplierInvoiceRepository, supplierRepo domain.SupplierRepository, config SupplierInvoiceConfig) domain.SupplierInvoiceUsecase {
+	defaults := DefaultSupplierInvoiceConfig()
+	if config.MaxFileSize <= 0 {
+		config.MaxFileSize = defaults.MaxFileSize
+	}
+	if config.MaxLines <= 0 {
+		config.MaxLines = defaults.MaxLines
+	}
+	if config.AmountTolerance < 0 {
+		config.AmountTolerance = defaults.AmountTolerance
+	}
+	if config.Timezone == "" {
+		config.Timezone = defaults.Timezone
+	}
+
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		logger.Warn("Invalid supplier invoice timezone, falling back to UTC",
+			logger.String("timezone", config.Timezone),
+			logger.ErrorField(err),
+		)
+		location = time.UTC
+	}
+
+	return &supplierInvoiceUsecase{
+		invoiceRepo:  invoiceRepo,
+		supplierRepo: supplierRepo,
+		config:       config,
+		location:     location,
+	}
+}
+
+// invoiceEntry is one parsed line of a recap file
+type invoiceEntry struct {
+	lineNumber    int
+	supplierTrxID string
+	refID         string
+	productCode   string
+	destination   string
+	status        string
+	amount        float64
+	billed        bool // False when the status says the supplier did not charge it
+}
+
+// IngestInvoice parses a recap file, matches it against our transactions and
+// stores the invoice with every line's result
+func (uc *supplierInvoiceUsecase) IngestInvoice(upload *domain.SupplierInvoiceUpload) (*domain.SupplierInvoice, error) {
+	supplier, err := uc.supplierRepo.GetByID(upload.SupplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	var from, to time.Time
+	if (upload.PeriodStart == nil) != (upload.PeriodEnd == nil) {
+		return nil, fmt.Errorf("period_start and period_end go together")
+	}
+	if upload.PeriodStart != nil {
+		from = time.Date(upload.PeriodStart.Year(), upload.PeriodStart.Month(), upload.PeriodStart.Day(), 0, 0, 0, 0, uc.location)
+		to = time.Date(upload.PeriodEnd.Year(), upload.PeriodEnd.Month(), upload.PeriodEnd.Day(), 0, 0, 0, 0, uc.location).AddDate(0, 0, 1)
+		if !to.After(from) {
+			return nil, fmt.Errorf("period_end must not be before period_start")
+		}
+		if to.Sub(from) > domain.MaxListingRange {
+			return nil, fmt.Errorf("invoice period too long")
+		}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(upload.File, uc.config.MaxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read invoice file: %w", err)
+	}
+	if int64(len(data)) > uc.config.MaxFileSize {
+		return nil, fmt.Errorf("invoice file too large")
+	}
+
+	entries, err := uc.parseInvoice(data)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice := &domain.SupplierInvoice{
+		SupplierID:   supplier.ID,
+		SupplierCode: supplier.Code,
+		FileName:     upload.FileName,
+		PeriodStart:  upload.PeriodStart,
+		PeriodEnd:    upload.PeriodEnd,
+		LineCount:    len(entries),
+		UploadedBy:   upload.UploadedBy,
+	}
+
+	lines, err := uc.matchInvoice(invoice, entries, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.invoiceRepo.Create(invoice, lines); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Supplier invoice ingested",
+		logger.String("invoice_id", invoice.ID),
+		logger.String("supplier_code", supplier.Code),
+		logger.Int("lines", invoice.LineCount),
+		logger.Int("matched", invoice.MatchedCount),
+		logger.Int("missing", invoice.MissingCount),
+		logger.Int("amount_mismatches", invoice.AmountMismatchCount),
+		logger.Int("status_mismatches", invoice.StatusMismatchCount),
+		logger.Int("unbilled", invoice.UnbilledCount),
+		logger.Float64("difference", invoice.Difference),
+	)
+
+	return invoice, nil
+}
+
+// parseInvoice reads a CSV recap with a header row, separated by commas or
+// semicolons. Columns are found by name, see invoiceColumnAliases.
+func (uc *supplierInvoiceUsecase) parseInvoice(data []byte) ([]*invoiceEntry, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	columns, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("invoice file is empty")
+		}
+		return nil, fmt.Errorf("invalid invoice file: %v", err)
+	}
+	index := invoiceColumnIndex(columns)
+	if _, ok := index["amount"]; !ok {
+		return nil, fmt.Errorf("invoice file needs an amount column")
+	}
+	_, hasTrxID := index["supplier_trx_id"]
+	_, hasRefID := index["ref_id"]
+	if !hasTrxID && !hasRefID {
+		return nil, fmt.Errorf("invoice file needs a supplier_trx_id or ref_id column")
+	}
+
+	var entries []*invoiceEntry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid invoice file: %v", err)
+		}
+		if len(entries) >= uc.config.MaxLines {
+			return nil, fmt.Errorf("invoice file has more than %d lines", uc.config.MaxLines)
+		}
+
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := index[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		entry := &invoiceEntry{
+			lineNumber:    line,
+			supplierTrxID: field("supplier_trx_id"),
+			refID:         field("ref_id"),
+			productCode:   field("product_code"),
+			destination:   field("destination"),
+			status:        field("status"),
+		}
+		if entry.supplierTrxID == "" && entry.refID == "" {
+			return nil, fmt.Errorf("line %d: supplier_trx_id or ref_id is required", line)
+		}
+		entry.amount, err = parseInvoiceAmount(field("amount"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		entry.billed = !invoiceStatusFailed(entry.status)
+
+		entries = append(entries, entry)
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("invoice file has no lines")
+	}
+	return entries, nil
+}
+
+// matchInvoice gives every entry its result, adds the UNBILLED lines of the
+// period when there is one and fills in the invoice summary
+func (uc *supplierInvoiceUsecase) matchInvoice(invoice *domain.SupplierInvoice, entries []*invoiceEntry, from, to time.Time) ([]*domain.SupplierInvoiceLine, error) {
+	var supplierTrxIDs, refIDs []string
+	for _, entry := range entries {
+		if entry.supplierTrxID != "" {
+			supplierTrxIDs = append(supplierTrxIDs, entry.supplierTrxID)
+		}
+		if entry.refID != "" {
+			refIDs = append(refIDs, entry.refID)
+		}
+	}
+
+	found, err := uc.invoiceRepo.FindTransactions(invoice.SupplierID, supplierTrxIDs, refIDs)
+	if err != nil {
+		return nil, err
+	}
+	bySupplierTrxID := make(map[string]*domain.InvoiceTransaction, len(found))
+	byTrxCode := make(map[string]*domain.InvoiceTransaction, len(found))
+	for _, transaction := range found {
+		if transaction.SupplierTrxID != nil && transaction.SupplierID != nil && *transaction.SupplierID == invoice.SupplierID {
+			bySupplierTrxID[*transaction.SupplierTrxID] = transaction
+		}
+		byTrxCode[transaction.TrxCode] = transaction
+	}
+
+	lines := make([]*domain.SupplierInvoiceLine, 0, len(entries))
+	billed := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		transaction := bySupplierTrxID[entry.supplierTrxID]
+		if transaction == nil {
+			transaction = byTrxCode[entry.refID]
+		}
+
+		line := newInvoiceLine(entry, transaction)
+		switch {
+		case transaction == nil:
+			line.Result = domain.InvoiceLineMissing
+			line.Note = invoiceNote("no transaction with this ID")
+		case billed[transaction.ID]:
+			line.Result = domain.InvoiceLineDuplicate
+			line.Note = invoiceNote("transaction already on an earlier line")
+		case transaction.SupplierID == nil || *transaction.SupplierID != invoice.SupplierID:
+			line.Result = domain.InvoiceLineStatusMismatch
+			line.Note = invoiceNote("transaction was completed at another supplier")
+		case entry.billed && transaction.Status != domain.StatusSuccess:
+			line.Result = domain.InvoiceLineStatusMismatch
+			line.Note = invoiceNote(fmt.Sprintf("billed but transaction is %s", transaction.Status))
+		case !entry.billed && transaction.Status == domain.StatusSuccess:
+			line.Result = domain.InvoiceLineStatusMismatch
+			line.Note = invoiceNote("supplier reports failure but transaction succeeded")
+		case entry.billed && math.Abs(entry.amount-transaction.HPP) > uc.config.AmountTolerance:
+			line.Result = domain.InvoiceLineAmountMismatch
+			line.Note = invoiceNote(fmt.Sprintf("billed %.2f, expected %.2f", entry.amount, transaction.HPP))
+		default:
+			line.Result = domain.InvoiceLineMatched
+		}
+
+		if transaction != nil {
+			if !billed[transaction.ID] && transaction.Status == domain.StatusSuccess &&
+				transaction.SupplierID != nil && *transaction.SupplierID == invoice.SupplierID {
+				invoice.ExpectedTotal += transaction.HPP
+			}
+			billed[transaction.ID] = true
+		}
+		if entry.billed {
+			invoice.InvoicedTotal += entry.amount
+		}
+		countInvoiceLine(invoice, line.Result)
+		lines = append(lines, line)
+	}
+
+	if !from.IsZero() {
+		successful, err := uc.invoiceRepo.ListSuccessful(invoice.SupplierID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, transaction := range successful {
+			if billed[transaction.ID] {
+				continue
+			}
+			line := newInvoiceLine(nil, transaction)
+			line.Result = domain.InvoiceLineUnbilled
+			line.Note = invoiceNote("successful transaction missing from the invoice")
+			invoice.ExpectedTotal += transaction.HPP
+			countInvoiceLine(invoice, line.Result)
+			lines = append(lines, line)
+		}
+	}
+
+	invoice.InvoicedTotal = math.Round(invoice.InvoicedTotal*100) / 100
+	invoice.ExpectedTotal = math.Round(invoice.ExpectedTotal*100) / 100
+	invoice.Difference = math.Round((invoice.InvoicedTotal-invoice.ExpectedTotal)*100) / 100
+	return lines, nil
+}
+
+// GetInvoice retrieves an invoice with its match summary
+func (uc *supplierInvoiceUsecase) GetInvoice(id string) (*domain.SupplierInvoice, error) {
+	return uc.invoiceRepo.GetByID(id)
+}
+
+// ListInvoices lists invoices newest first, optionally of one supplier
+func (uc *supplierInvoiceUsecase) ListInvoices(supplierID string, page, limit int) ([]*domain.SupplierInvoice, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	return uc.invoiceRepo.List(supplierID, limit, (page-1)*limit)
+}
+
+// ListInvoiceLines lists the lines of an invoice, optionally with one result
+func (uc *supplierInvoiceUsecase) ListInvoiceLines(invoiceID, result string) ([]*domain.SupplierInvoiceLine, error) {
+	result = strings.ToUpper(strings.TrimSpace(result))
+	if result != "" && !domain.IsValidInvoiceLineResult(result) {
+		return nil, fmt.Errorf("invalid invoice line result")
+	}
+	if _, err := uc.invoiceRepo.GetByID(invoiceID); err != nil {
+		return nil, err
+	}
+	return uc.invoiceRepo.ListLines(invoiceID, result)
+}
+
+// newInvoiceLine builds the line of an entry (nil for UNBILLED) and the
+// transaction it matched, if any
+func newInvoiceLine(entry *invoiceEntry, transaction *domain.InvoiceTransaction) *domain.SupplierInvoiceLine {
+	line := &domain.SupplierInvoiceLine{}
+	if entry != nil {
+		amount := entry.amount
+		line.LineNumber = entry.lineNumber
+		line.SupplierTrxID = optionalInvoiceField(entry.supplierTrxID)
+		line.RefID = optionalInvoiceField(entry.refID)
+		line.ProductCode = optionalInvoiceField(entry.productCode)
+		line.Destination = optionalInvoiceField(entry.destination)
+		line.InvoicedStatus = optionalInvoiceField(entry.status)
+		line.InvoicedAmount = &amount
+	}
+	if transaction != nil {
+		id, trxCode, status, hpp := transaction.ID, transaction.TrxCode, transaction.Status, transaction.HPP
+		line.TransactionID = &id
+		line.TrxCode = &trxCode
+		line.TransactionStatus = &status
+		line.ExpectedAmount = &hpp
+		if entry == nil {
+			line.SupplierTrxID = transaction.SupplierTrxID
+			line.ProductCode = optionalInvoiceField(transaction.ProductCode)
+			line.Destination = optionalInvoiceField(transaction.DestinationNumber)
+		}
+	}
+	return line
+}
+
+func countInvoiceLine(invoice *domain.SupplierInvoice, result string) {
+	switch result {
+	case domain.InvoiceLineMatched:
+		invoice.MatchedCount++
+	case domain.InvoiceLineMissing:
+		invoice.MissingCount++
+	case domain.InvoiceLineAmountMismatch:
+		invoice.AmountMismatchCount++
+	case domain.InvoiceLineStatusMismatch:
+		invoice.StatusMismatchCount++
+	case domain.InvoiceLineDuplicate:
+		invoice.DuplicateCount++
+	case domain.InvoiceLineUnbilled:
+		invoice.UnbilledCount++
+	}
+}
+
+// invoiceColumnIndex maps our column names to their position in the header
+func invoiceColumnIndex(header []string) map[string]int {
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+		if _, seen := positions[name]; !seen {
+			positions[name] = i
+		}
+	}
+
+	index := make(map[string]int, len(invoiceColumnAliases))
+	for column, aliases := range invoiceColumnAliases {
+		for _, alias := range aliases {
+			if i, ok := positions[alias]; ok {
+				index[column] = i
+				break
+			}
+		}
+	}
+	return index
+}
+
+// parseInvoiceAmount reads amounts as suppliers write them: "10500",
+// "10.500", "10,500.00", "Rp 10.500,00". A single separator followed by
+// exactly three digits groups thousands, otherwise the last separator
+// starts the decimals.
+func parseInvoiceAmount(value string) (float64, error) {
+	cleaned := strings.TrimSpace(value)
+	cleaned = strings.TrimPrefix(strings.TrimPrefix(cleaned, "Rp"), "IDR")
+	cleaned = strings.ReplaceAll(strings.TrimSpace(cleaned), " ", "")
+	if cleaned == "" {
+		return 0, fmt.Errorf("amount is required")
+	}
+
+	lastDot, lastComma := strings.LastIndex(cleaned, "."), strings.LastIndex(cleaned, ",")
+	decimal := max(lastDot, lastComma)
+	if decimal >= 0 && (lastDot < 0 || lastComma < 0) {
+		separator := cleaned[decimal : decimal+1]
+		if strings.Count(cleaned, separator) > 1 || len(cleaned)-decimal-1 == 3 {
+			decimal = -1 // Thousands only
+		}
+	}
+
+	var digits strings.Builder
+	for i, r := range cleaned {
+		switch {
+		case r >= '0' && r <= '9', r == '-' && i == 0:
+			digits.WriteRune(r)
+		case (r == '.' || r == ',') && i == decimal:
+			digits.WriteRune('.')
+		case r == '.' || r == ',':
+		default:
+			return 0, fmt.Errorf("invalid amount %q", value)
+		}
+	}
+
+	amount, err := strconv.ParseFloat(digits.String(), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	return amount, nil
+}
+
+func invoiceStatusFailed(status string) bool {
+	status = strings.ToLower(status)
+	for _, failed := range invoiceFailedStatuses {
+		if strings.Contains(status, failed) {
+			return true
+		}
+	}
+	return false
+}
+
+func optionalInvoiceField(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func invoiceNote(note string) *string {
+	return &note
+}
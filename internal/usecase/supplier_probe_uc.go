@@ -0,0 +1,240 @@
+package usecase
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/metrics"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+type supplierProbeUsecase struct {
+	supplierRepo   domain.SupplierRepository
+	probeRepo      domain.SupplierProbeRepository
+	adapterFactory domain.SupplierAdapterFactory
+	routingCache   domain.RoutingCacheRepository
+	eventRepo      domain.EventRepository
+	config         SupplierProbeConfig
+}
+
+// SupplierProbeConfig defines supplier latency probing and SLA parameters
+type SupplierProbeConfig struct {
+	// Retention is how long probe results are kept
+	Retention time.Duration
+	// DefaultWindow is the SLA window used when none is requested
+	DefaultWindow time.Duration
+	// TargetP95Ms is the p95 latency a supplier must stay under
+	TargetP95Ms int
+	// TargetAvailability is the minimum percentage of successful probes
+	TargetAvailability float64
+	// UnreachableAfter is how many pings in a row must fail before the
+	// supplier is marked unreachable
+	UnreachableAfter int
+}
+
+// DefaultSupplierProbeConfig returns default supplier probe configuration
+func DefaultSupplierProbeConfig() SupplierProbeConfig {
+	return SupplierProbeConfig{
+		Retention:          7 * 24 * time.Hour,
+		DefaultWindow:      24 * time.Hour,
+		TargetP95Ms:        3000,
+		TargetAvailability: 99.0,
+		UnreachableAfter:   2,
+	}
+}
+
+// NewSupplierProbeUsecase creates a new supplier probe use case
+func NewSupplierProbeUsecase(
+	supplierRepo domain.SupplierRepository,
+	probeRepo domain.SupplierProbeRepository,
+	adapterFactory domain.SupplierAdapterFactory,
+	routingCache domain.RoutingCacheRepository,
+	eventRepo domain.EventRepository,
+	config SupplierProbeConfig,
+) domain.SupplierProbeUsecase {
+	defaults := DefaultSupplierProbeConfig()
+	if config.Retention <= 0 {
+		config.Retention = defaults.Retention
+	}
+	if config.DefaultWindow <= 0 {
+		config.DefaultWindow = defaults.DefaultWindow
+	}
+	if config.TargetP95Ms <= 0 {
+		config.TargetP95Ms = defaults.TargetP95Ms
+	}
+	if config.TargetAvailability <= 0 || config.TargetAvailability > 100 {
+		config.TargetAvailability = defaults.TargetAvailability
+	}
+	if config.UnreachableAfter <= 0 {
+		config.UnreachableAfter = defaults.UnreachableAfter
+	}
+
+	return &supplierProbeUsecase{
+		supplierRepo:   supplierRepo,
+		probeRepo:      probeRepo,
+		adapterFactory: adapterFactory,
+		routingCache:   routingCache,
+		eventRepo:      eventRepo,
+		config:         config,
+	}
+}
+
+// ProbeSuppliers pings every active supplier that has an adapter,
+// concurrently, stores its reachability and prunes probes past retention
+func (uc *supplierProbeUsecase) ProbeSuppliers() (int, error) {
+	if uc.adapterFactory == nil {
+		return 0, fmt.Errorf("supplier adapter factory not configured")
+	}
+
+	suppliers, err := uc.supplierRepo.GetActiveSuppliers()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get suppliers: %w", err)
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		probed int
+	)
+	for _, supplier := range suppliers {
+		adapter, err := uc.adapterFactory.GetSupplierAdapter(supplier)
+		if err != nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func(supplier *domain.Supplier, adapter domain.SupplierAdapter) {
+			defer wg.Done()
+			if uc.probeSupplier(supplier, adapter) {
+				mu.Lock()
+				probed++
+				mu.Unlock()
+			}
+		}(supplier, adapter)
+	}
+	wg.Wait()
+
+	if _, err := uc.probeRepo.DeleteBefore(time.Now().Add(-uc.config.Retention)); err != nil {
+		logger.Warn("Failed to prune supplier probes", logger.ErrorField(err))
+	}
+
+	return probed, nil
+}
+
+// probeSupplier runs and stores one probe, reporting whether it was stored
+func (uc *supplierProbeUsecase) probeSupplier(supplier *domain.Supplier, adapter domain.SupplierAdapter) bool {
+	start := time.Now()
+	callErr := adapter.Ping()
+	latency := time.Since(start)
+
+	probe := &domain.SupplierProbe{
+		ID:         utils.GenerateUUID(),
+		SupplierID: supplier.ID,
+		Operation:  domain.SupplierProbePing,
+		LatencyMs:  int(latency.Milliseconds()),
+		Success:    callErr == nil,
+		ProbedAt:   start,
+	}
+
+	status := "success"
+	if callErr != nil {
+		status = "failed"
+		msg := callErr.Error()
+		probe.ErrorMessage = &msg
+		logger.Warn("Supplier probe failed",
+			logger.String("supplier_code", supplier.Code),
+			logger.Duration("latency", latency),
+			logger.ErrorField(callErr),
+		)
+	}
+	metrics.RecordSupplierRequest(supplier.Code, "probe", status, latency.Seconds())
+
+	if err := uc.supplierRepo.RecordPing(supplier.ID, probe.Success, probe.LatencyMs, uc.config.UnreachableAfter); err != nil {
+		logger.Warn("Failed to record supplier reachability",
+			logger.String("supplier_code", supplier.Code),
+			logger.ErrorField(err),
+		)
+	} else if !probe.Success && supplier.IsReachable && supplier.PingFailures+1 >= uc.config.UnreachableAfter {
+		logger.Component(logger.ComponentRouting).Warn("Supplier unreachable, routing down-ranks it",
+			logger.String("supplier_code", supplier.Code),
+			logger.Int("failed_pings", supplier.PingFailures+1),
+		)
+		invalidateRoutingSupplier(uc.routingCache, supplier.ID)
+		uc.recordUnhealthy(supplier, supplier.PingFailures+1, *probe.ErrorMessage)
+	} else if probe.Success && !supplier.IsReachable {
+		logger.Component(logger.ComponentRouting).Info("Supplier reachable again",
+			logger.String("supplier_code", supplier.Code),
+		)
+		invalidateRoutingSupplier(uc.routingCache, supplier.ID)
+	}
+
+	if err := uc.probeRepo.Create(probe); err != nil {
+		return false
+	}
+
+	// Failed calls often return fast or time out; only successful latencies
+	// describe how the supplier performs
+	if probe.Success {
+		if err := uc.supplierRepo.RecordProbeLatency(supplier.ID, probe.LatencyMs); err != nil {
+			logger.Warn("Failed to update supplier response time",
+				logger.String("supplier_code", supplier.Code),
+				logger.ErrorField(err),
+			)
+		}
+	}
+
+	return true
+}
+
+// recordUnhealthy stores the outbox event alerting that a supplier became
+// unreachable
+func (uc *supplierProbeUsecase) recordUnhealthy(supplier *domain.Supplier, failedPings int, lastError string) {
+	event, err := domain.NewSupplierUnhealthyEvent(supplier, failedPings, lastError)
+	if err != nil {
+		logger.Error("Failed to build supplier unhealthy event", logger.String("supplier_code", supplier.Code), logger.ErrorField(err))
+		return
+	}
+	if err := uc.eventRepo.Create(event); err != nil {
+		logger.Error("Failed to store supplier unhealthy event", logger.String("supplier_code", supplier.Code), logger.ErrorField(err))
+	}
+}
+
+// GetSLAReport evaluates every supplier probed within the window against the
+// latency and availability targets
+func (uc *supplierProbeUsecase) GetSLAReport(window time.Duration) (*domain.SupplierSLAReport, error) {
+	if window <= 0 {
+		window = uc.config.DefaultWindow
+	}
+
+	end := time.Now()
+	start := end.Add(-window)
+	stats, err := uc.probeRepo.GetLatencyStats(start)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.SupplierSLAReport{
+		WindowStart: start,
+		WindowEnd:   end,
+		Suppliers:   make([]*domain.SupplierSLA, 0, len(stats)),
+	}
+	for _, stat := range stats {
+		sla := &domain.SupplierSLA{
+			SupplierLatencyStats: stat,
+			TargetP95Ms:          uc.config.TargetP95Ms,
+			TargetAvailability:   uc.config.TargetAvailability,
+		}
+		if stat.Samples > 0 {
+			sla.Availability = utils.RoundToDecimal(float64(stat.SuccessCount)/float64(stat.Samples)*100, 2)
+		}
+		sla.LatencyCompliant = stat.P95Ms != nil && *stat.P95Ms <= float64(uc.config.TargetP95Ms)
+		sla.AvailabilityCompliant = sla.Availability >= uc.config.TargetAvailability
+		sla.Compliant = sla.LatencyCompliant && sla.AvailabilityCompliant
+		report.Suppliers = append(report.Suppliers, sla)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,352 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/suppliersign"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+type supplierRegistryUsecase struct {
+	supplierRepo       domain.SupplierRepository
+	productMappingRepo domain.ProductMappingRepository
+	adapterFactory     domain.SupplierAdapterFactory
+	routingCache       domain.RoutingCacheRepository
+}
+
+// NewSupplierRegistryUsecase creates a new supplier registry use case
+func NewSupplierRegistryUsecase(
+	supplierRepo domain.SupplierRepository,
+	productMappingRepo domain.ProductMappingRepository,
+	adapterFactory domain.SupplierAdapterFactory,
+	routingCache domain.RoutingCacheRepository,
+) domain.SupplierRegistryUsecase {
+	return &supplierRegistryUsecase{
+		supplierRepo:       supplierRepo,
+		productMappingRepo: productMappingRepo,
+		adapterFactory:     adapterFactory,
+		routingCache:       routingCache,
+	}
+}
+
+// LoadAdapters builds the adapter of every active supplier so a broken
+// account shows up in the startup log rather than on its first transaction
+func (uc *supplierRegistryUsecase) LoadAdapters() (int, error) {
+	suppliers, err := uc.supplierRepo.GetActiveSuppliers()
+	if err != nil {
+		return 0, err
+	}
+
+	loaded := 0
+	for _, supplier := range suppliers {
+		if _, err := uc.adapterFactory.Load(supplier); err != nil {
+			logger.Warn("Supplier adapter not loaded",
+				logger.String("supplier_id", supplier.ID),
+				logger.String("supplier_code", supplier.Code),
+				logger.String("adapter_type", supplier.GetAdapterType()),
+				logger.ErrorField(err),
+			)
+			continue
+		}
+		loaded++
+	}
+
+	return loaded, nil
+}
+
+// CreateSupplier validates a new supplier account and brings its adapter
+// live. An active account whose adapter cannot be built is rejected.
+func (uc *supplierRegistryUsecase) CreateSupplier(supplier *domain.Supplier) error {
+	if supplier == nil {
+		return fmt.Errorf("supplier payload is required")
+	}
+
+	supplier.Code = strings.ToUpper(strings.TrimSpace(supplier.Code))
+	supplier.Name = strings.TrimSpace(supplier.Name)
+	supplier.APIURL = strings.TrimSpace(supplier.APIURL)
+	supplier.AdapterType = optionalUpper(supplier.AdapterType)
+	supplier.SignMethod = optionalUpper(supplier.SignMethod)
+	supplier.APIKey = optionalString(supplier.APIKey)
+	supplier.APISecret = optionalString(supplier.APISecret)
+	supplier.APIUsername = optionalString(supplier.APIUsername)
+	supplier.APIPassword = optionalString(supplier.APIPassword)
+	supplier.WebhookSecret = optionalString(supplier.WebhookSecret)
+	if supplier.Priority == 0 {
+		supplier.Priority = domain.DefaultPriority
+	}
+	if supplier.TimeoutSeconds == 0 {
+		supplier.TimeoutSeconds = domain.DefaultTimeoutSeconds
+	}
+
+	if err := supplier.Check(); err != nil {
+		return err
+	}
+	if err := uc.checkAdapter(supplier); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	supplier.ID = utils.GenerateUUID()
+	supplier.SuccessRate = 100
+	supplier.IsReachable = true
+	supplier.AvgResponseTimeMs = 1000
+	supplier.CreatedAt = now
+	supplier.UpdatedAt = now
+
+	if supplier.IsActive {
+		if _, err := uc.adapterFactory.Load(supplier); err != nil {
+			return fmt.Errorf("supplier adapter cannot be loaded: %v", err)
+		}
+	}
+
+	if err := uc.supplierRepo.Create(supplier); err != nil {
+		uc.adapterFactory.Unload(supplier.ID)
+		return err
+	}
+	return nil
+}
+
+// UpdateSupplier changes the settings of a supplier account. The adapter is
+// rebuilt from the new settings before they are stored, so a bad credential
+// change is rejected while the account keeps serving with the old ones.
+func (uc *supplierRegistryUsecase) UpdateSupplier(id string, updates *domain.SupplierUpdate) (*domain.Supplier, error) {
+	if updates == nil {
+		return nil, fmt.Errorf("supplier payload is required")
+	}
+
+	supplier, err := uc.supplierRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	previous := *supplier
+
+	applySupplierUpdate(supplier, updates)
+	if err := supplier.Check(); err != nil {
+		return nil, err
+	}
+	if err := uc.checkAdapter(supplier); err != nil {
+		return nil, err
+	}
+
+	if supplier.IsActive {
+		if _, err := uc.adapterFactory.Load(supplier); err != nil {
+			return nil, fmt.Errorf("supplier adapter cannot be loaded: %v", err)
+		}
+	}
+	supplier.UpdatedAt = time.Now()
+
+	if err := uc.supplierRepo.Update(supplier); err != nil {
+		uc.restoreAdapter(&previous)
+		return nil, err
+	}
+
+	if !supplier.IsActive {
+		uc.adapterFactory.Unload(supplier.ID)
+	}
+	invalidateRoutingSupplier(uc.routingCache, supplier.ID)
+
+	logger.Info("Supplier settings updated",
+		logger.String("supplier_id", supplier.ID),
+		logger.String("supplier_code", supplier.Code),
+		logger.String("adapter_type", supplier.GetAdapterType()),
+		logger.Bool("is_active", supplier.IsActive),
+	)
+
+	return supplier, nil
+}
+
+// GetSupplier returns a supplier by ID
+func (uc *supplierRegistryUsecase) GetSupplier(id string) (*domain.Supplier, error) {
+	return uc.supplierRepo.GetByID(id)
+}
+
+// ListSuppliers lists every supplier, active or not, by priority
+func (uc *supplierRegistryUsecase) ListSuppliers() ([]*domain.Supplier, error) {
+	return uc.supplierRepo.GetSuppliersByPriority()
+}
+
+// PreviewRequestRules rewrites a destination like the supplier's adapter
+// would, so rules can be tried out before they are saved
+func (uc *supplierRegistryUsecase) PreviewRequestRules(id string, rules *domain.SupplierRequestRules, productCode, destination string) (string, error) {
+	if rules == nil {
+		supplier, err := uc.supplierRepo.GetByID(id)
+		if err != nil {
+			return "", err
+		}
+		rules = &supplier.RequestRules
+	}
+
+	rewriter, err := rules.Compile()
+	if err != nil {
+		return "", err
+	}
+	return rewriter.Rewrite(productCode, domain.NormalizeDestination(destination)), nil
+}
+
+// CopyMappings copies the product mappings of an account to another account
+// of the same supplier, so a new account takes traffic without mapping every
+// product again. Copies start with no performance history.
+func (uc *supplierRegistryUsecase) CopyMappings(targetID, sourceID string) (int, error) {
+	if targetID == sourceID {
+		return 0, fmt.Errorf("source and target supplier must differ")
+	}
+
+	target, err := uc.supplierRepo.GetByID(targetID)
+	if err != nil {
+		return 0, err
+	}
+	source, err := uc.supplierRepo.GetByID(sourceID)
+	if err != nil {
+		return 0, err
+	}
+	if !target.IsAccountOf(source) {
+		return 0, fmt.Errorf("source supplier has a different adapter type")
+	}
+
+	sourceMappings, err := uc.productMappingRepo.GetBySupplierID(source.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get source supplier mappings: %w", err)
+	}
+	targetMappings, err := uc.productMappingRepo.GetBySupplierID(target.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get target supplier mappings: %w", err)
+	}
+	mapped := make(map[string]bool, len(targetMappings))
+	for _, mapping := range targetMappings {
+		mapped[mapping.ProductID] = true
+	}
+
+	now := time.Now()
+	productIDs := make([]string, 0, len(sourceMappings))
+	for _, mapping := range sourceMappings {
+		if mapped[mapping.ProductID] {
+			continue
+		}
+
+		err := uc.productMappingRepo.Create(&domain.ProductMapping{
+			ID:                  utils.GenerateUUID(),
+			ProductID:           mapping.ProductID,
+			SupplierID:          target.ID,
+			SupplierProductCode: mapping.SupplierProductCode,
+			SupplierPrice:       mapping.SupplierPrice,
+			AdditionalFee:       mapping.AdditionalFee,
+			Priority:            mapping.Priority,
+			IsActive:            mapping.IsActive,
+			StockStatus:         mapping.StockStatus,
+			CreatedAt:           now,
+			UpdatedAt:           now,
+		})
+		if err != nil {
+			invalidateRoutingProducts(uc.routingCache, productIDs...)
+			return len(productIDs), err
+		}
+		productIDs = append(productIDs, mapping.ProductID)
+	}
+	invalidateRoutingProducts(uc.routingCache, productIDs...)
+
+	logger.Info("Supplier mappings copied",
+		logger.String("supplier_code", target.Code),
+		logger.String("source_supplier_code", source.Code),
+		logger.Int("copied", len(productIDs)),
+	)
+
+	return len(productIDs), nil
+}
+
+// checkAdapter rejects adapter types without a builder and unknown sign methods
+func (uc *supplierRegistryUsecase) checkAdapter(supplier *domain.Supplier) error {
+	if !uc.adapterFactory.HasBuilder(supplier.GetAdapterType()) {
+		return fmt.Errorf("unsupported adapter type %s", strings.ToUpper(supplier.GetAdapterType()))
+	}
+	if supplier.SignMethod != nil && !suppliersign.IsValidMethod(*supplier.SignMethod) {
+		return fmt.Errorf("unsupported sign method %s", *supplier.SignMethod)
+	}
+	return nil
+}
+
+// restoreAdapter puts back the adapter of settings that failed to be replaced
+func (uc *supplierRegistryUsecase) restoreAdapter(previous *domain.Supplier) {
+	if !previous.IsActive {
+		uc.adapterFactory.Unload(previous.ID)
+		return
+	}
+	if _, err := uc.adapterFactory.Load(previous); err != nil {
+		uc.adapterFactory.Unload(previous.ID)
+	}
+}
+
+// applySupplierUpdate copies the set fields of updates onto a supplier
+func applySupplierUpdate(supplier *domain.Supplier, updates *domain.SupplierUpdate) {
+	if updates.Name != nil {
+		supplier.Name = strings.TrimSpace(*updates.Name)
+	}
+	if updates.APIURL != nil {
+		supplier.APIURL = strings.TrimSpace(*updates.APIURL)
+	}
+	if updates.APIKey != nil {
+		supplier.APIKey = optionalString(updates.APIKey)
+	}
+	if updates.APISecret != nil {
+		supplier.APISecret = optionalString(updates.APISecret)
+	}
+	if updates.APIUsername != nil {
+		supplier.APIUsername = optionalString(updates.APIUsername)
+	}
+	if updates.APIPassword != nil {
+		supplier.APIPassword = optionalString(updates.APIPassword)
+	}
+	if updates.AdapterType != nil {
+		supplier.AdapterType = optionalUpper(updates.AdapterType)
+	}
+	if updates.SignMethod != nil {
+		supplier.SignMethod = optionalUpper(updates.SignMethod)
+	}
+	if updates.WebhookSecret != nil {
+		supplier.WebhookSecret = optionalString(updates.WebhookSecret)
+	}
+	if updates.IsActive != nil {
+		supplier.IsActive = *updates.IsActive
+	}
+	if updates.Priority != nil {
+		supplier.Priority = *updates.Priority
+	}
+	if updates.TimeoutSeconds != nil {
+		supplier.TimeoutSeconds = *updates.TimeoutSeconds
+	}
+	if updates.RetryAttempts != nil {
+		supplier.RetryAttempts = *updates.RetryAttempts
+	}
+	if updates.MinBalanceThreshold != nil {
+		supplier.MinBalanceThreshold = *updates.MinBalanceThreshold
+	}
+	if updates.RequestRules != nil {
+		supplier.RequestRules = *updates.RequestRules
+	}
+}
+
+// optionalUpper upper-cases an adapter type or sign method; an empty one is
+// stored as NULL (the supplier code or the adapter default)
+func optionalUpper(value *string) *string {
+	value = optionalString(value)
+	if value == nil {
+		return nil
+	}
+	normalized := strings.ToUpper(*value)
+	return &normalized
+}
+
+// optionalString trims a value; an empty one is stored as NULL
+func optionalString(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
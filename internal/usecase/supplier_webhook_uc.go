@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/suppliersign"
+)
+
+type supplierWebhookUsecase struct {
+	supplierRepo   domain.SupplierRepository
+	eventRepo      domain.WebhookEventRepository
+	adapterFactory domain.SupplierAdapterFactory
+	transactionUC  domain.TransactionUsecase
+	config         SupplierWebhookConfig
+}
+
+// SupplierWebhookConfig defines how inbound supplier webhooks are accepted
+type SupplierWebhookConfig struct {
+	// ReplayWindow is how long a processed event ID is remembered and rejected
+	ReplayWindow time.Duration
+}
+
+// DefaultSupplierWebhookConfig returns default supplier webhook configuration
+func DefaultSupplierWebhookConfig() SupplierWebhookConfig {
+	return SupplierWebhookConfig{
+		ReplayWindow: 24 * time.Hour,
+	}
+}
+
+// NewSupplierWebhookUsecase creates a new supplier webhook use case
+func NewSupplierWebhookUsecase(
+	supplierRepo domain.SupplierRepository,
+	eventRepo domain.WebhookEventRepository,
+	adapterFactory domain.SupplierAdapterFactory,
+	transactionUC domain.TransactionUsecase,
+	config SupplierWebhookConfig,
+) domain.SupplierWebhookUsecase {
+	if config.ReplayWindow <= 0 {
+		config.ReplayWindow = DefaultSupplierWebhookConfig().ReplayWindow
+	}
+
+	return &supplierWebhookUsecase{
+		supplierRepo:   supplierRepo,
+		eventRepo:      eventRepo,
+		adapterFactory: adapterFactory,
+		transactionUC:  transactionUC,
+		config:         config,
+	}
+}
+
+// HandleWebhook verifies the signature with the supplier's webhook secret,
+// rejects payloads seen within the replay window and applies the transaction
+// result in the payload. Refusals are returned as *domain.WebhookRejection.
+func (uc *supplierWebhookUsecase) HandleWebhook(ctx context.Context, webhook *domain.SupplierWebhook) error {
+	supplier, err := uc.supplierRepo.GetByCode(strings.ToUpper(strings.TrimSpace(webhook.SupplierCode)))
+	if err != nil {
+		if err.Error() == "supplier not found" {
+			return &domain.WebhookRejection{Reason: domain.WebhookRejectUnknownSupplier}
+		}
+		return err
+	}
+
+	secret := ""
+	if supplier.WebhookSecret != nil {
+		secret = strings.TrimSpace(*supplier.WebhookSecret)
+	}
+	if secret == "" {
+		return &domain.WebhookRejection{Reason: domain.WebhookRejectNoSecret}
+	}
+	if !suppliersign.VerifyWebhook(secret, webhook.Payload, webhook.Signature) {
+		return &domain.WebhookRejection{Reason: domain.WebhookRejectInvalidSignature}
+	}
+
+	// The event is identified by a hash of the signed body, not by a delivery
+	// header the signature does not cover, so a captured notification cannot
+	// be replayed under a new ID. Only verified deliveries claim it, so forged
+	// requests cannot block a genuine notification.
+	eventID := webhookEventID(webhook.Payload)
+	reserved, err := uc.eventRepo.Reserve(supplier.Code, eventID, uc.config.ReplayWindow)
+	if err != nil {
+		return err
+	}
+	if !reserved {
+		return &domain.WebhookRejection{Reason: domain.WebhookRejectReplay}
+	}
+
+	adapter, err := uc.adapterFactory.GetSupplierAdapter(supplier)
+	if err != nil {
+		return fmt.Errorf("adapter for %s not found: %v", supplier.Code, err)
+	}
+	response, err := adapter.ParseResponse(webhook.Payload)
+	if err != nil {
+		return fmt.Errorf("invalid webhook payload: %w", err)
+	}
+
+	ctx = logger.WithContext(ctx,
+		logger.String("supplier_code", supplier.Code),
+		logger.String("event_id", eventID),
+	)
+	log := logger.FromContext(ctx)
+	log.Info("Supplier webhook received",
+		logger.String("trx_code", response.TrxID),
+		logger.String("classification", response.Classification),
+	)
+
+	if err := uc.transactionUC.ApplySupplierResult(ctx, supplier, response); err != nil {
+		switch err.Error() {
+		case "transaction not found", "transaction was not routed to this supplier":
+		default:
+			// Let the supplier's redelivery through after a failure on our side
+			if releaseErr := uc.eventRepo.Release(supplier.Code, eventID); releaseErr != nil {
+				log.Warn("Failed to release webhook event", logger.ErrorField(releaseErr))
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// webhookEventID identifies a webhook by the SHA-256 of its signed payload
+func webhookEventID(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
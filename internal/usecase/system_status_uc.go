@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+)
+
+type systemStatusUsecase struct {
+	monitor   domain.WorkerMonitor
+	queueRepo domain.QueueRepository
+	eventRepo domain.EventRepository
+}
+
+// NewSystemStatusUsecase creates a new system status use case
+func NewSystemStatusUsecase(monitor domain.WorkerMonitor, queueRepo domain.QueueRepository, eventRepo domain.EventRepository) domain.SystemStatusUsecase {
+	return &systemStatusUsecase{
+		monitor:   monitor,
+		queueRepo: queueRepo,
+		eventRepo: eventRepo,
+	}
+}
+
+// GetWorkersStatus combines the worker counters of this replica with the
+// depths of the shared queues
+func (uc *systemStatusUsecase) GetWorkersStatus() (*domain.SystemWorkersStatus, error) {
+	transactions, err := uc.queueRepo.GetQueueLength()
+	if err != nil {
+		return nil, err
+	}
+	events, err := uc.eventRepo.CountByStatus(domain.EventStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	dlqSize, err := uc.eventRepo.CountByStatus(domain.EventStatusFailed)
+	if err != nil {
+		return nil, err
+	}
+
+	workers := uc.monitor.Workers()
+	inFlight := make([]string, 0)
+	for _, worker := range workers {
+		inFlight = append(inFlight, worker.InFlight...)
+	}
+
+	startedAt := uc.monitor.StartedAt()
+	return &domain.SystemWorkersStatus{
+		StartedAt:     startedAt,
+		UptimeSeconds: int64(time.Since(startedAt).Seconds()),
+		Goroutines:    runtime.NumGoroutine(),
+		Workers:       workers,
+		QueueDepths: map[string]int64{
+			domain.QueueTransactions: transactions,
+			domain.QueueEvents:       int64(events),
+		},
+		DLQSize:  dlqSize,
+		InFlight: inFlight,
+	}, nil
+}
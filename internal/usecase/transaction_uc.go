@@ -1,11 +1,17 @@
 package usecase
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/alfanzaky/eraflazz/internal/domain"
 	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/metrics"
 	"github.com/alfanzaky/eraflazz/pkg/utils"
 )
 
@@ -17,9 +23,67 @@ type transactionUsecase struct {
 	mutationRepo    domain.MutationRepository
 	cacheRepo       interface{} // Will be implemented as Redis cache
 	queueRepo       domain.QueueRepository
+	productCache    domain.ProductCacheRepository
 	smartRoutingUC  *smartRoutingUsecase
 	adapterFactory  domain.SupplierAdapterFactory
 	retryUC         *retryUsecase
+	unitOfWork      domain.UnitOfWork
+	pricingUC       domain.PricingUsecase
+	holdRepo        domain.BalanceHoldRepository
+	timelineRepo    domain.TransactionTimelineRepository
+	decisionRepo    domain.RoutingDecisionRepository
+	feeUC           domain.FeeUsecase
+	destinationUC   domain.DestinationRuleUsecase
+	priceUC         domain.UserPriceUsecase
+	cutoffUC        domain.CutoffUsecase
+	confirmationUC  domain.AmountConfirmationUsecase
+	lockRepo        domain.TransactionLockRepository
+	voucherUC       domain.VoucherUsecase
+	config          TransactionConfig
+}
+
+// TransactionConfig defines transaction lifecycle parameters
+type TransactionConfig struct {
+	// AutoCancel sets how long a transaction may stay pending per channel and product
+	AutoCancel domain.AutoCancelPolicy
+	// ExpiryBatchSize is how many expired transactions one expiry pass cancels
+	ExpiryBatchSize int
+	// ProcessingSLA sets per category and product how long a transaction may
+	// stay processing at the supplier
+	ProcessingSLA domain.ProcessingSLAPolicy
+	// DuplicateGuard rejects orders repeating a recent one of the same user
+	DuplicateGuard domain.DuplicateGuardPolicy
+	// LockTTL bounds how long one worker may hold a transaction's processing
+	// lock; it must outlast the supplier calls of one processing run
+	LockTTL time.Duration
+	// MinSupplierBudget is the least time left before a request's deadline
+	// for a synchronous supplier call to start; with less the worker takes over
+	MinSupplierBudget time.Duration
+	// SlowProcessing is how long a processing run may take before its stage
+	// timings are recorded on the transaction timeline
+	SlowProcessing time.Duration
+}
+
+// duplicateActiveLookback bounds how far back in-progress transactions are
+// searched for duplicates, so only recent partitions are scanned
+const duplicateActiveLookback = 24 * time.Hour
+
+// DefaultTransactionConfig returns default transaction configuration
+func DefaultTransactionConfig() TransactionConfig {
+	return TransactionConfig{
+		ExpiryBatchSize: 100,
+		ProcessingSLA: domain.ProcessingSLAPolicy{
+			Expected: 30 * time.Second,
+			Timeout:  10 * time.Minute,
+		},
+		DuplicateGuard: domain.DuplicateGuardPolicy{
+			Mode:   domain.DuplicateGuardConfirm,
+			Window: 5 * time.Minute,
+		},
+		LockTTL:           2 * time.Minute,
+		MinSupplierBudget: 3 * time.Second,
+		SlowProcessing:    10 * time.Second,
+	}
 }
 
 // NewTransactionUsecase creates a new transaction use case
@@ -33,7 +97,53 @@ func NewTransactionUsecase(
 	adapterFactory domain.SupplierAdapterFactory,
 	retryUC *retryUsecase,
 	queueRepo domain.QueueRepository,
+	productCache domain.ProductCacheRepository,
+	unitOfWork domain.UnitOfWork,
+	pricingUC domain.PricingUsecase,
+	holdRepo domain.BalanceHoldRepository,
+	timelineRepo domain.TransactionTimelineRepository,
+	decisionRepo domain.RoutingDecisionRepository,
+	feeUC domain.FeeUsecase,
+	destinationUC domain.DestinationRuleUsecase,
+	priceUC domain.UserPriceUsecase,
+	cutoffUC domain.CutoffUsecase,
+	confirmationUC domain.AmountConfirmationUsecase,
+	lockRepo domain.TransactionLockRepository,
+	voucherUC domain.VoucherUsecase,
+	config TransactionConfig,
 ) domain.TransactionUsecase {
+	if config.ExpiryBatchSize <= 0 {
+		config.ExpiryBatchSize = DefaultTransactionConfig().ExpiryBatchSize
+	}
+	if config.ProcessingSLA.Expected <= 0 {
+		config.ProcessingSLA.Expected = DefaultTransactionConfig().ProcessingSLA.Expected
+	}
+	if config.ProcessingSLA.Timeout <= 0 {
+		config.ProcessingSLA.Timeout = DefaultTransactionConfig().ProcessingSLA.Timeout
+	}
+	config.DuplicateGuard.Mode = strings.ToUpper(strings.TrimSpace(config.DuplicateGuard.Mode))
+	if config.DuplicateGuard.Mode == "" {
+		config.DuplicateGuard.Mode = DefaultTransactionConfig().DuplicateGuard.Mode
+	}
+	if !domain.IsValidDuplicateGuardMode(config.DuplicateGuard.Mode) {
+		logger.Warn("Invalid duplicate guard mode, falling back to default",
+			logger.String("mode", config.DuplicateGuard.Mode),
+		)
+		config.DuplicateGuard.Mode = DefaultTransactionConfig().DuplicateGuard.Mode
+	}
+	if config.DuplicateGuard.Window <= 0 {
+		config.DuplicateGuard.Window = DefaultTransactionConfig().DuplicateGuard.Window
+	}
+	if config.LockTTL <= 0 {
+		config.LockTTL = DefaultTransactionConfig().LockTTL
+	}
+	if config.MinSupplierBudget <= 0 {
+		config.MinSupplierBudget = DefaultTransactionConfig().MinSupplierBudget
+	}
+	if config.SlowProcessing <= 0 {
+		config.SlowProcessing = DefaultTransactionConfig().SlowProcessing
+	}
+
 	return &transactionUsecase{
 		userRepo:        userRepo,
 		productRepo:     productRepo,
@@ -41,66 +151,359 @@ func NewTransactionUsecase(
 		transactionRepo: transactionRepo,
 		mutationRepo:    mutationRepo,
 		queueRepo:       queueRepo,
+		productCache:    productCache,
 		smartRoutingUC:  smartRoutingUC,
 		adapterFactory:  adapterFactory,
 		retryUC:         retryUC,
+		unitOfWork:      unitOfWork,
+		pricingUC:       pricingUC,
+		holdRepo:        holdRepo,
+		timelineRepo:    timelineRepo,
+		decisionRepo:    decisionRepo,
+		feeUC:           feeUC,
+		destinationUC:   destinationUC,
+		priceUC:         priceUC,
+		cutoffUC:        cutoffUC,
+		confirmationUC:  confirmationUC,
+		lockRepo:        lockRepo,
+		voucherUC:       voucherUC,
+		config:          config,
+	}
+}
+
+// CreateTransaction creates a new transaction and queues it for processing
+func (uc *transactionUsecase) CreateTransaction(ctx context.Context, userID, productCode, destinationNumber, channel string) (*domain.Transaction, error) {
+	transaction, err := uc.createTransaction(ctx, userID, productCode, destinationNumber, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	// Transactions held by a cutoff are queued when the window opens
+	if transaction.Status == domain.StatusPendingSchedule {
+		return transaction, nil
+	}
+
+	uc.enqueueTransaction(ctx, transaction)
+	return transaction, nil
+}
+
+// enqueueTransaction queues a pending transaction for the worker
+func (uc *transactionUsecase) enqueueTransaction(ctx context.Context, transaction *domain.Transaction) {
+	log := logger.FromContext(transactionContext(ctx, transaction))
+	if uc.queueRepo != nil {
+		if err := uc.queueRepo.EnqueueTransaction(transaction.ID); err != nil {
+			log.Error("Failed to enqueue transaction", logger.ErrorField(err))
+		} else {
+			log.Debug("Transaction queued for processing")
+		}
+	} else {
+		log.Warn("Queue repository is not configured; transaction will not be auto-processed")
+	}
+}
+
+// CreateTransactionSync creates a transaction and processes it within the
+// request, failing over to alternative suppliers according to policy. The
+// returned transaction carries the final (or still pending) status; supplier
+// failures are reflected in the status rather than returned as errors. When
+// the request deadline leaves no time for a supplier call the transaction is
+// queued instead.
+func (uc *transactionUsecase) CreateTransactionSync(ctx context.Context, userID, productCode, destinationNumber, channel string, policy *domain.SyncFailoverPolicy) (*domain.Transaction, error) {
+	transaction, err := uc.createTransaction(ctx, userID, productCode, destinationNumber, channel)
+	if err != nil {
+		return nil, err
+	}
+	if transaction.Status == domain.StatusPendingSchedule {
+		return transaction, nil
+	}
+
+	if uc.supplierBudgetExhausted(ctx) {
+		logger.FromContext(transactionContext(ctx, transaction)).Info("Request budget exhausted, transaction handed to the worker")
+		uc.enqueueTransaction(ctx, transaction)
+		return transaction, nil
+	}
+
+	if err := uc.processTransaction(ctx, transaction.ID, policy); err != nil {
+		logger.FromContext(transactionContext(ctx, transaction)).Warn("Synchronous transaction processing failed",
+			logger.ErrorField(err),
+		)
+	}
+
+	processed, err := uc.transactionRepo.GetByID(transaction.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload transaction: %w", err)
+	}
+
+	return processed, nil
+}
+
+func (uc *transactionUsecase) createTransaction(ctx context.Context, userID, productCode, destinationNumber, channel string) (*domain.Transaction, error) {
+	transaction, user, err := uc.buildTransaction(ctx, userID, productCode, destinationNumber, channel)
+	if err != nil {
+		return nil, err
+	}
+	log := logger.FromContext(transactionContext(ctx, transaction))
+
+	// Check user balance
+	if !user.HasSufficientBalance(transaction.TotalAmount()) {
+		return nil, fmt.Errorf("insufficient balance")
+	}
+
+	// Orders above the user's threshold need a confirmation token or the PIN
+	if uc.confirmationUC != nil {
+		if err := uc.confirmationUC.Check(transactionContext(ctx, transaction), transaction); err != nil {
+			return nil, err
+		}
+	}
+
+	// Save transaction, balance hold and outbox event atomically
+	err = uc.unitOfWork.Do(func(repos domain.TxRepositories) error {
+		if err := repos.Transactions().Create(transaction); err != nil {
+			return err
+		}
+		if err := repos.BalanceHolds().Hold(newBalanceHold(transaction)); err != nil {
+			return err
+		}
+		// The hold locked the user row, so concurrent orders of the same
+		// user are checked for duplicates one after the other
+		if err := uc.checkDuplicate(transactionContext(ctx, transaction), repos, transaction); err != nil {
+			return err
+		}
+		if err := repos.Timeline().Append(domain.NewTransactionTimelineEntry(transaction, domain.TimelineCreated, "Transaction created, balance held", map[string]interface{}{
+			"product_code":       transaction.ProductCode,
+			"destination_number": transaction.DestinationNumber,
+			"selling_price":      transaction.SellingPrice,
+			"admin_fee":          transaction.AdminFee,
+			"channel":            transaction.Channel,
+		})); err != nil {
+			return err
+		}
+		if transaction.ScheduledAt != nil {
+			if err := repos.Timeline().Append(newScheduledTimelineEntry(transaction, "Held until the product cutoff ends")); err != nil {
+				return err
+			}
+		}
+		return uc.recordTransactionEvent(repos, domain.EventTransactionCreated, transaction)
+	})
+	var duplicateErr *domain.DuplicateTransactionError
+	if errors.As(err, &duplicateErr) {
+		log.Warn("Duplicate transaction rejected",
+			logger.String("duplicate_of", duplicateErr.Existing.TrxCode),
+			logger.String("mode", uc.config.DuplicateGuard.Mode),
+		)
+		return nil, err
+	}
+	if err != nil {
+		if err.Error() == "insufficient balance" {
+			return nil, err
+		}
+		log.Error("Failed to create transaction", logger.ErrorField(err))
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	log.Info("Transaction created successfully",
+		logger.String("product_code", productCode),
+		logger.Float64("amount", transaction.TotalAmount()),
+	)
+
+	return transaction, nil
+}
+
+// checkDuplicate rejects a new transaction repeating an in-progress or
+// recently successful one of the same user, product and destination. In
+// CONFIRM mode the duplicate override lets the order through.
+func (uc *transactionUsecase) checkDuplicate(ctx context.Context, repos domain.TxRepositories, transaction *domain.Transaction) error {
+	guard := uc.config.DuplicateGuard
+	if !guard.Enabled() {
+		return nil
+	}
+
+	now := time.Now()
+	existing, err := repos.Transactions().FindDuplicate(
+		transaction.UserID, transaction.ProductCode, transaction.DestinationNumber, transaction.ID,
+		now.Add(-max(guard.Window, duplicateActiveLookback)), now.Add(-guard.Window),
+	)
+	if err != nil || existing == nil {
+		return err
+	}
+
+	if guard.Mode == domain.DuplicateGuardConfirm && domain.HasDuplicateOverride(ctx) {
+		logger.FromContext(ctx).Info("Duplicate transaction confirmed",
+			logger.String("duplicate_of", existing.TrxCode),
+		)
+		return nil
+	}
+
+	return &domain.DuplicateTransactionError{
+		Existing:    existing,
+		Confirmable: guard.Mode == domain.DuplicateGuardConfirm,
+	}
+}
+
+// SimulateTransaction runs the validation, pricing and routing pipeline of an
+// order and reports the outcome without holding balance or calling a supplier
+func (uc *transactionUsecase) SimulateTransaction(ctx context.Context, userID, productCode, destinationNumber, channel string) (*domain.TransactionSimulation, error) {
+	transaction, user, err := uc.buildTransaction(ctx, userID, productCode, destinationNumber, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	simulation := &domain.TransactionSimulation{
+		Simulated:         true,
+		Accepted:          true,
+		ProductCode:       transaction.ProductCode,
+		DestinationNumber: transaction.DestinationNumber,
+		Channel:           transaction.Channel,
+		HPP:               transaction.HPP,
+		SellingPrice:      transaction.SellingPrice,
+		AdminFee:          transaction.AdminFee,
+		TotalAmount:       transaction.TotalAmount(),
+		AvailableBalance:  user.AvailableBalance(),
+		SufficientBalance: user.HasSufficientBalance(transaction.TotalAmount()),
+		ExpiresAt:         transaction.ExpiresAt,
+		ScheduledAt:       transaction.ScheduledAt,
 	}
+	if !simulation.SufficientBalance {
+		simulation.Accepted = false
+		simulation.RejectReason = "insufficient balance"
+	}
+
+	if uc.smartRoutingUC == nil {
+		simulation.Accepted = false
+		simulation.RejectReason = "smart routing is not configured"
+		return simulation, nil
+	}
+
+	result, err := uc.smartRoutingUC.GetBestSupplier(transaction.ProductID, defaultRoutingCriteria(transaction.UserID))
+	var cutoffErr *domain.CutoffError
+	if errors.As(err, &cutoffErr) {
+		// Every supplier is in cutoff; the order would wait for the first to open
+		simulation.ScheduledAt = &cutoffErr.Until
+		return simulation, nil
+	}
+	if err != nil || result == nil || result.SelectedSupplier == nil || result.SelectedMapping == nil {
+		if simulation.Accepted {
+			simulation.Accepted = false
+			simulation.RejectReason = "no supplier available"
+		}
+		return simulation, nil
+	}
+
+	simulation.SupplierCode = result.SelectedSupplier.Code
+	simulation.SupplierName = result.SelectedSupplier.Name
+	simulation.SupplierProductCode = result.SelectedMapping.SupplierProductCode
+	simulation.SupplierPrice = result.SelectedMapping.GetEffectivePrice()
+	simulation.EstimatedProfit = transaction.TotalAmount() - simulation.SupplierPrice
+	simulation.RoutingReason = result.Reason
+	simulation.RoutingConfidence = result.Confidence
+
+	logger.FromContext(ctx).Info("Transaction simulated",
+		logger.String("product_code", transaction.ProductCode),
+		logger.String("supplier_code", simulation.SupplierCode),
+		logger.Bool("accepted", simulation.Accepted),
+	)
+
+	return simulation, nil
 }
 
-// CreateTransaction creates a new transaction
-func (uc *transactionUsecase) CreateTransaction(userID, productCode, destinationNumber string) (*domain.Transaction, error) {
+// buildTransaction runs the validation and pricing pipeline shared by real
+// and simulated orders and returns the unsaved transaction with its owner.
+func (uc *transactionUsecase) buildTransaction(ctx context.Context, userID, productCode, destinationNumber, channel string) (*domain.Transaction, *domain.User, error) {
+	log := logger.FromContext(ctx)
+
 	// Validate input
 	if userID == "" || productCode == "" || destinationNumber == "" {
-		return nil, fmt.Errorf("missing required fields")
+		return nil, nil, fmt.Errorf("missing required fields")
 	}
 
-	// Validate phone number
-	if !utils.ValidatePhoneNumber(destinationNumber) {
-		return nil, fmt.Errorf("invalid phone number format")
+	if channel == "" {
+		channel = domain.ChannelAPI
+	}
+	if !domain.IsValidChannel(channel) {
+		return nil, nil, fmt.Errorf("invalid channel")
 	}
 
 	// Get user
 	user, err := uc.userRepo.GetByID(userID)
 	if err != nil {
-		logger.Error("Failed to get user for transaction",
-			logger.String("user_id", userID),
-			logger.ErrorField(err),
-		)
-		return nil, fmt.Errorf("user not found: %w", err)
+		log.Error("Failed to get user for transaction", logger.ErrorField(err))
+		return nil, nil, fmt.Errorf("user not found: %w", err)
 	}
 
 	// Check if user is active
 	if !user.IsActive {
-		return nil, fmt.Errorf("user account is not active")
+		return nil, nil, fmt.Errorf("user account is not active")
 	}
 
 	// Get product
 	product, err := uc.productRepo.GetByCode(productCode)
 	if err != nil {
-		logger.Error("Failed to get product for transaction",
+		log.Error("Failed to get product for transaction",
 			logger.String("product_code", productCode),
 			logger.ErrorField(err),
 		)
-		return nil, fmt.Errorf("product not found: %w", err)
+		return nil, nil, fmt.Errorf("product not found: %w", err)
 	}
 
 	// Check if product is active
 	if !product.IsActive {
-		return nil, fmt.Errorf("product is not available")
+		return nil, nil, fmt.Errorf("product is not available")
+	}
+
+	// Restricted products are only sold to users of their minimum level and above
+	if !product.VisibleTo(user.Level) {
+		return nil, nil, &domain.ProductRestrictedError{MinLevel: *product.MinLevel}
+	}
+
+	// Stocked vouchers can only be sold while codes are left
+	if uc.sellsFromStock(product) && product.StockQuantity <= 0 {
+		return nil, nil, fmt.Errorf("product is out of stock")
+	}
+
+	// A category cutoff rejects the order (BLOCK) or holds it until the window opens (QUEUE)
+	now := time.Now()
+	cutoff := uc.categoryCutoff(ctx, product.Category, now)
+	if cutoff != nil && cutoff.Schedule.Action == domain.CutoffActionBlock {
+		return nil, nil, newCutoffError(cutoff)
+	}
+
+	// Validate destination against the product and category format
+	destinationNumber, err = uc.destinationUC.ValidateDestination(product, destinationNumber)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Calculate pricing
+	// Calculate pricing: a price override for the user wins over the markup
 	basePrice := product.BasePrice
 	sellingPrice := user.GetEffectivePrice(basePrice)
+	if uc.priceUC != nil {
+		resolved, err := uc.priceUC.ResolvePrice(user, product)
+		if err != nil {
+			log.Error("Failed to resolve selling price",
+				logger.String("product_code", productCode),
+				logger.ErrorField(err),
+			)
+			return nil, nil, fmt.Errorf("failed to resolve selling price: %w", err)
+		}
+		sellingPrice = resolved.Price
+	}
 
 	// Check transaction limits
 	if sellingPrice < product.MinPrice || sellingPrice > product.MaxTransactionAmount {
-		return nil, fmt.Errorf("price out of allowed range")
+		return nil, nil, fmt.Errorf("price out of allowed range")
 	}
 
-	// Check user balance
-	if !user.HasSufficientBalance(sellingPrice) {
-		return nil, fmt.Errorf("insufficient balance")
+	// Calculate admin fee charged on top of the selling price
+	adminFee := 0.0
+	if uc.feeUC != nil {
+		quote, err := uc.feeUC.CalculateFee(product.Category, user.Level, sellingPrice)
+		if err != nil {
+			log.Error("Failed to calculate admin fee",
+				logger.String("product_code", productCode),
+				logger.ErrorField(err),
+			)
+			return nil, nil, fmt.Errorf("failed to calculate admin fee: %w", err)
+		}
+		adminFee = quote.AdminFee
 	}
 
 	// Create transaction
@@ -109,152 +512,176 @@ func (uc *transactionUsecase) CreateTransaction(userID, productCode, destination
 		TrxCode:           utils.GenerateTrxCode(),
 		UserID:            userID,
 		ProductID:         product.ID,
-		DestinationNumber: utils.ParsePhoneNumber(destinationNumber),
+		DestinationNumber: destinationNumber,
 		ProductCode:       productCode,
 		HPP:               basePrice,
 		SellingPrice:      sellingPrice,
-		AdminFee:          0, // Can be calculated based on business rules
+		AdminFee:          adminFee,
 		Status:            domain.StatusPending,
+		Channel:           channel,
+		ExpiresAt:         uc.config.AutoCancel.ExpiresAt(channel, productCode, now),
 		RoutingAttempts:   0,
-		CreatedAt:         time.Now(),
-		UpdatedAt:         time.Now(),
-	}
-
-	// Save transaction
-	err = uc.transactionRepo.Create(transaction)
-	if err != nil {
-		logger.Error("Failed to create transaction",
-			logger.String("trx_code", transaction.TrxCode),
-			logger.ErrorField(err),
-		)
-		return nil, fmt.Errorf("failed to create transaction: %w", err)
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		Tags:              domain.TransactionTagsFromContext(ctx),
 	}
-
-	// Enqueue transaction for processing
-	if uc.queueRepo != nil {
-		err = uc.queueRepo.EnqueueTransaction(transaction.ID)
-		if err != nil {
-			logger.Error("Failed to enqueue transaction",
-				logger.String("trx_id", transaction.ID),
-				logger.String("trace_id", transaction.TrxCode),
-				logger.ErrorField(err),
-			)
-		} else {
-			logger.Debug("Transaction queued for processing",
-				logger.String("trx_id", transaction.ID),
-				logger.String("trace_id", transaction.TrxCode),
-			)
+	if actor, ok := domain.ActorFromContext(ctx); ok {
+		// Kept so the mutations settling the order show where it came from
+		if actor.IPAddress != "" {
+			transaction.UserIP = &actor.IPAddress
+		}
+		if actor.UserAgent != "" {
+			transaction.UserAgent = &actor.UserAgent
 		}
-	} else {
-		logger.Warn("Queue repository is not configured; transaction will not be auto-processed",
-			logger.String("trx_id", transaction.ID),
-			logger.String("trace_id", transaction.TrxCode),
-		)
+	}
+	if cutoff != nil {
+		// The auto-cancel countdown starts when the transaction is released
+		transaction.Status = domain.StatusPendingSchedule
+		transaction.ScheduledAt = &cutoff.Until
+		transaction.ExpiresAt = nil
 	}
 
-	logger.Info("Transaction created successfully",
-		logger.String("trace_id", transaction.TrxCode),
-		logger.String("trx_id", transaction.ID),
-		logger.String("user_id", userID),
-		logger.String("product_code", productCode),
-		logger.Float64("amount", sellingPrice),
-	)
-
-	return transaction, nil
+	return transaction, user, nil
 }
 
 // ProcessTransaction processes a pending transaction
-func (uc *transactionUsecase) ProcessTransaction(transactionID string) error {
+func (uc *transactionUsecase) ProcessTransaction(ctx context.Context, transactionID string) error {
+	return uc.processTransaction(ctx, transactionID, nil)
+}
+
+// processTransaction routes and executes a pending transaction. A non-nil
+// policy enables synchronous failover to alternative suppliers.
+func (uc *transactionUsecase) processTransaction(ctx context.Context, transactionID string, policy *domain.SyncFailoverPolicy) error {
+	stages := newStageTimer(stageValidation)
+
+	ctx, unlock, err := uc.lockTransaction(ctx, transactionID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	// Get transaction
 	transaction, err := uc.transactionRepo.GetByID(transactionID)
 	if err != nil {
 		return fmt.Errorf("transaction not found: %w", err)
 	}
+	ctx = transactionContext(ctx, transaction)
+	log := logger.FromContext(ctx)
 
 	// Check if transaction is in pending status
 	if transaction.Status != domain.StatusPending {
 		return fmt.Errorf("transaction is not in pending status")
 	}
 
-	// Update status to processing
+	// Expired transactions are left for the expiry job to cancel
 	now := time.Now()
+	if transaction.IsPastExpiry(now) {
+		return fmt.Errorf("transaction has expired")
+	}
+
+	// A cutoff that started after the order was accepted holds it rather than
+	// rejecting it, whatever the cutoff action
+	product, productErr := uc.productRepo.GetByID(transaction.ProductID)
+	if productErr == nil {
+		if cutoff := uc.categoryCutoff(ctx, product.Category, now); cutoff != nil {
+			return uc.scheduleTransaction(ctx, transaction, domain.StatusPending, newCutoffError(cutoff))
+		}
+	}
+
+	// Update status to processing unless another worker or the expiry job got there first
 	transaction.ProcessedAt = &now
-	err = uc.transactionRepo.UpdateStatus(transactionID, domain.StatusProcessing)
+	updated, err := uc.transactionRepo.TransitionStatus(transactionID, domain.StatusPending, domain.StatusProcessing)
 	if err != nil {
 		return fmt.Errorf("failed to update processing status: %w", err)
 	}
+	if !updated {
+		return fmt.Errorf("transaction is not in pending status")
+	}
+	transaction.Status = domain.StatusProcessing
+	uc.appendTimeline(transaction, domain.TimelineProcessing, "Transaction picked up for processing", nil)
+	// Only runs that got the transaction are timed, the others did no work
+	defer uc.recordStages(ctx, transaction, stages)
 
-	logger.Info("Processing transaction",
-		logger.String("trace_id", transaction.TrxCode),
-		logger.String("trx_id", transaction.ID),
-		logger.Float64("amount", transaction.SellingPrice),
+	log.Info("Processing transaction",
+		logger.Float64("amount", transaction.TotalAmount()),
 	)
 
-	// Get user for balance check
-	user, err := uc.userRepo.GetByID(transaction.UserID)
-	if err != nil {
-		return fmt.Errorf("failed to get user: %w", err)
-	}
+	// Make sure the amount is still reserved (holds are released when a
+	// transaction fails and is retried later)
+	stages.begin(stageDebit)
+	if err := uc.ensureBalanceHold(transaction); err != nil {
+		if err.Error() != "insufficient balance" {
+			return fmt.Errorf("failed to reserve balance: %w", err)
+		}
 
-	// Check balance again (in case it changed)
-	if !user.HasSufficientBalance(transaction.SellingPrice) {
 		// Update transaction to failed due to insufficient balance
+		stages.begin(stagePersistence)
 		msg := "Insufficient balance"
 		transaction.Status = domain.StatusFailed
 		transaction.SupplierMessage = &msg
-		err = uc.transactionRepo.Update(transaction)
+		err = uc.completeTransaction(transaction)
 		if err != nil {
-			logger.Error("Failed to update transaction status", logger.ErrorField(err))
+			log.Error("Failed to update transaction status", logger.ErrorField(err))
 		}
 		return fmt.Errorf("insufficient balance")
 	}
 
+	// Stocked vouchers are fulfilled with a code from the stock, no supplier involved
+	if productErr == nil && uc.sellsFromStock(product) {
+		stages.begin(stagePersistence)
+		return uc.fulfillFromStock(ctx, transaction, product)
+	}
+
+	stages.begin(stageRouting)
 	selectedSupplier, selectedMapping, err := uc.selectSupplier(transaction)
+	var cutoffErr *domain.CutoffError
+	if errors.As(err, &cutoffErr) {
+		stages.begin(stagePersistence)
+		return uc.scheduleTransaction(ctx, transaction, domain.StatusProcessing, cutoffErr)
+	}
 	if err != nil {
-		logger.Error("Failed to select supplier",
-			logger.String("trx_id", transaction.ID),
-			logger.String("trace_id", transaction.TrxCode),
-			logger.ErrorField(err),
-		)
-		return uc.handleSupplierFailure(transaction, fmt.Sprintf("routing error: %v", err))
+		log.Error("Failed to select supplier", logger.ErrorField(err))
+		stages.begin(stagePersistence)
+		uc.appendTimeline(transaction, domain.TimelineRoutingFailed, err.Error(), nil)
+		return uc.handleSupplierFailure(ctx, transaction, fmt.Sprintf("routing error: %v", err), domain.RetryErrorFailure, true)
 	}
 
-	logger.Info("Supplier selected",
-		logger.String("trace_id", transaction.TrxCode),
-		logger.String("trx_id", transaction.ID),
+	log.Info("Supplier selected",
 		logger.String("supplier_code", selectedSupplier.Code),
 		logger.String("mapping_code", selectedMapping.SupplierProductCode),
 	)
 
 	supplierID := selectedSupplier.ID
 	transaction.SupplierID = &supplierID
-
-	// Deduct balance (create mutation)
-	refType := domain.ReferenceTypeTransaction
-	err = uc.createBalanceMutation(
-		user.ID,
-		domain.MutationTypeCredit, // Credit = money out
-		transaction.SellingPrice,
-		user.Balance,
-		user.Balance-transaction.SellingPrice,
-		fmt.Sprintf("Pembelian %s %s", transaction.ProductCode, transaction.DestinationNumber),
-		&refType,
-		&transaction.ID,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create balance mutation: %w", err)
-	}
-
-	// Update user balance
-	newBalance := user.Balance - transaction.SellingPrice
-	err = uc.userRepo.UpdateBalance(user.ID, newBalance)
-	if err != nil {
-		logger.Error("Failed to update user balance", logger.ErrorField(err))
-		// Continue processing even if balance update fails
-		// Will be handled by reconciliation
+	// HPP follows the routed supplier, so profit reflects what it actually costs
+	transaction.HPP = selectedMapping.GetEffectivePrice()
+	uc.appendTimeline(transaction, domain.TimelineRouted, fmt.Sprintf("Routed to %s", selectedSupplier.Code), map[string]interface{}{
+		"supplier_code":         selectedSupplier.Code,
+		"supplier_product_code": selectedMapping.SupplierProductCode,
+		"supplier_price":        selectedMapping.GetEffectivePrice(),
+		"priority":              selectedMapping.Priority,
+	})
+
+	// Margin guard: never sell below supplier cost plus the configured minimum margin
+	if uc.pricingUC != nil {
+		check, err := uc.pricingUC.GuardTransactionMargin(transaction.ProductID, selectedMapping, transaction.SellingPrice)
+		if err != nil {
+			log.Warn("Margin guard check failed", logger.ErrorField(err))
+		} else if check.Breached && check.Action == domain.MarginActionDeactivate {
+			stages.begin(stagePersistence)
+			msg := "Harga supplier melebihi harga jual"
+			transaction.Status = domain.StatusFailed
+			transaction.SupplierMessage = &msg
+			if err := uc.completeTransaction(transaction); err != nil {
+				log.Error("Failed to update transaction status", logger.ErrorField(err))
+			}
+			return fmt.Errorf("margin below minimum for product %s", transaction.ProductCode)
+		}
 	}
 
-	return uc.executeSupplierTransaction(transaction, selectedSupplier, selectedMapping)
+	// Balance stays on hold while the supplier processes the transaction; the
+	// hold is captured on success and released on failure
+	return uc.executeSupplierTransaction(ctx, transaction, selectedSupplier, selectedMapping, policy, stages)
 }
 
 // ProcessPendingTransactions processes all pending transactions
@@ -271,7 +698,7 @@ func (uc *transactionUsecase) ProcessPendingTransactions() error {
 
 	// Process each transaction
 	for _, transaction := range pendingTransactions {
-		err := uc.ProcessTransaction(transaction.ID)
+		err := uc.ProcessTransaction(context.Background(), transaction.ID)
 		if err != nil {
 			logger.Error("Failed to process transaction",
 				logger.String("trx_id", transaction.ID),
@@ -289,7 +716,7 @@ func (uc *transactionUsecase) selectSupplier(transaction *domain.Transaction) (*
 		return nil, nil, fmt.Errorf("smart routing is not configured")
 	}
 
-	result, err := uc.smartRoutingUC.GetBestSupplier(transaction.ProductID, nil)
+	result, err := uc.smartRoutingUC.GetBestSupplier(transaction.ProductID, defaultRoutingCriteria(transaction.UserID))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -298,57 +725,46 @@ func (uc *transactionUsecase) selectSupplier(transaction *domain.Transaction) (*
 		return nil, nil, fmt.Errorf("no supplier available for product %s", transaction.ProductID)
 	}
 
+	uc.recordRoutingDecision(transaction, domain.RoutingSourceRouting, result)
 	return result.SelectedSupplier, result.SelectedMapping, nil
 }
 
 func (uc *transactionUsecase) executeSupplierTransaction(
+	ctx context.Context,
 	transaction *domain.Transaction,
 	supplier *domain.Supplier,
 	mapping *domain.ProductMapping,
+	policy *domain.SyncFailoverPolicy,
+	stages *stageTimer,
 ) error {
-	if uc.adapterFactory == nil {
-		return uc.handleSupplierFailure(transaction, "supplier adapter factory not configured")
-	}
-
-	adapter, err := uc.adapterFactory.GetAdapter(supplier.Code)
-	if err != nil {
-		return uc.handleSupplierFailure(transaction, fmt.Sprintf("adapter for %s not found: %v", supplier.Code, err))
-	}
-
-	request := &domain.SupplierRequest{
-		ProductCode:       mapping.SupplierProductCode,
-		DestinationNumber: transaction.DestinationNumber,
-		RefID:             transaction.TrxCode,
-	}
-
-	logger.Info("Calling supplier",
-		logger.String("trace_id", transaction.TrxCode),
-		logger.String("trx_id", transaction.ID),
-		logger.String("supplier_code", supplier.Code),
-		logger.String("product_code", mapping.SupplierProductCode),
-	)
-
+	stages.begin(stageSupplierCall)
 	start := time.Now()
-	response, err := adapter.TopUp(request)
+	response, err := uc.callSupplier(ctx, transaction, supplier, mapping, 1)
+	if policy != nil {
+		supplier, mapping, response, err = uc.failoverSupplierTransaction(ctx, transaction, supplier, mapping, response, err, policy, start)
+	}
 	duration := time.Since(start)
+	stages.begin(stagePersistence)
 
-	success := err == nil && response != nil && response.Success
-	responseTime := int(duration.Milliseconds())
-	if response != nil && response.ResponseTime > 0 {
-		responseTime = response.ResponseTime
-	}
+	// Synchronous clients already had their failover within the budget, so a
+	// failure is refunded right away instead of entering the retry flow,
+	// unless the request ran out of time to fail over
+	retryable := policy == nil || uc.supplierBudgetExhausted(ctx)
 
-	if uc.smartRoutingUC != nil {
-		if updateErr := uc.smartRoutingUC.UpdateSupplierMetrics(supplier.ID, success, responseTime); updateErr != nil {
-			logger.Warn("Failed to update supplier metrics",
-				logger.String("supplier_id", supplier.ID),
-				logger.ErrorField(updateErr),
-			)
+	if err != nil {
+		// Cut off by the request deadline, the supplier may still deliver; the
+		// processing poll asks it for the result
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return uc.handleSupplierPending(ctx, transaction, supplier, &domain.SupplierResponse{
+				Message: "Supplier did not answer before the request deadline",
+			})
 		}
+		// No answer from the supplier (timeout, network error)
+		return uc.handleSupplierFailure(ctx, transaction, fmt.Sprintf("supplier error: %v", err), domain.RetryErrorTimeout, retryable)
 	}
 
-	if err != nil {
-		return uc.handleSupplierFailure(transaction, fmt.Sprintf("supplier error: %v", err))
+	if response.IsPending() {
+		return uc.handleSupplierPending(ctx, transaction, supplier, response)
 	}
 
 	if !response.Success {
@@ -356,9 +772,44 @@ func (uc *transactionUsecase) executeSupplierTransaction(
 		if msg == "" {
 			msg = "supplier returned failure"
 		}
-		return uc.handleSupplierFailure(transaction, msg)
+		// Permanent failures (wrong destination, invalid nominal, ...) would fail
+		// at every supplier, so they are refunded without retry or failover
+		return uc.handleSupplierFailure(ctx, transaction, msg, domain.RetryErrorFailure, retryable && !response.IsPermanentFailure())
+	}
+
+	responseTime := int(duration.Milliseconds())
+	if response.ResponseTime > 0 {
+		responseTime = response.ResponseTime
+	}
+
+	completed, err := uc.completeSupplierSuccess(transaction, supplier, response)
+	if err != nil {
+		return err
+	}
+	if !completed {
+		logger.FromContext(ctx).Info("Supplier result already applied",
+			logger.String("supplier_code", supplier.Code),
+		)
+		return nil
 	}
 
+	logger.FromContext(ctx).Info("Transaction completed via supplier",
+		logger.String("supplier_code", supplier.Code),
+		logger.Float64("hpp", transaction.HPP),
+		logger.Float64("profit", transaction.Profit),
+		logger.Duration("duration", duration),
+		logger.Int("response_time_ms", responseTime),
+	)
+
+	return nil
+}
+
+// completeSupplierSuccess stores the supplier result of a successful
+// transaction and settles its balance hold. The status moves from PROCESSING
+// in the same database transaction, so of a worker, the status poll and a
+// webhook delivering the same result only one completes it; the others get
+// false.
+func (uc *transactionUsecase) completeSupplierSuccess(transaction *domain.Transaction, supplier *domain.Supplier, response *domain.SupplierResponse) (bool, error) {
 	serial := response.SerialNumber
 	if serial == "" {
 		serial = response.TrxID
@@ -382,273 +833,1944 @@ func (uc *transactionUsecase) executeSupplierTransaction(
 	now := time.Now()
 	transaction.CompletedAt = &now
 
-	if err := uc.transactionRepo.Update(transaction); err != nil {
-		return fmt.Errorf("failed to update successful transaction: %w", err)
+	completed, err := uc.completeTransactionFrom(transaction, domain.StatusProcessing)
+	if err != nil {
+		return false, fmt.Errorf("failed to update successful transaction: %w", err)
 	}
 
-	logger.Info("Transaction completed via supplier",
-		logger.String("trace_id", transaction.TrxCode),
-		logger.String("trx_id", transaction.ID),
-		logger.String("supplier_code", supplier.Code),
-		logger.Duration("duration", duration),
-		logger.Int("response_time_ms", responseTime),
-	)
+	return completed, nil
+}
 
-	return nil
+// sellsFromStock reports whether the product's orders take a code from the
+// voucher stock instead of going to a supplier
+func (uc *transactionUsecase) sellsFromStock(product *domain.Product) bool {
+	return uc.voucherUC != nil && uc.voucherUC.SellsFromStock(product)
 }
 
-func (uc *transactionUsecase) handleSupplierFailure(transaction *domain.Transaction, reason string) error {
-	msg := reason
-	transaction.Status = domain.StatusFailed
+// fulfillFromStock completes a stocked voucher transaction with a code from
+// the stock as its SN. The code is allocated in the same database transaction
+// that settles the balance hold; without a code the order is refunded.
+func (uc *transactionUsecase) fulfillFromStock(ctx context.Context, transaction *domain.Transaction, product *domain.Product) error {
+	now := time.Now()
+	transaction.Status = domain.StatusSuccess
+	transaction.CompletedAt = &now
+
+	err := uc.unitOfWork.Do(func(repos domain.TxRepositories) error {
+		code, err := uc.voucherUC.AllocateCode(repos, transaction, product)
+		if err != nil {
+			return err
+		}
+		transaction.SerialNumber = &code
+		return uc.persistCompletion(repos, transaction)
+	})
+	if err != nil {
+		transaction.Status = domain.StatusProcessing
+		transaction.CompletedAt = nil
+		transaction.SerialNumber = nil
+
+		reason := fmt.Sprintf("voucher stock error: %v", err)
+		if err.Error() == "voucher out of stock" {
+			reason = "Stok voucher habis"
+		}
+		return uc.handleSupplierFailure(ctx, transaction, reason, domain.RetryErrorFailure, false)
+	}
+
+	logger.FromContext(ctx).Info("Transaction completed from voucher stock",
+		logger.String("product_code", product.Code),
+	)
+
+	return nil
+}
+
+// callSupplier sends one top-up attempt to a supplier, updating supplier
+// metrics and the timeline. A nil error means response is non-nil.
+func (uc *transactionUsecase) callSupplier(
+	ctx context.Context,
+	transaction *domain.Transaction,
+	supplier *domain.Supplier,
+	mapping *domain.ProductMapping,
+	attempt int,
+) (*domain.SupplierResponse, error) {
+	if uc.adapterFactory == nil {
+		return nil, fmt.Errorf("supplier adapter factory not configured")
+	}
+	if err := uc.checkTransactionLock(ctx); err != nil {
+		return nil, err
+	}
+
+	adapter, err := uc.adapterFactory.GetSupplierAdapter(supplier)
+	if err != nil {
+		return nil, fmt.Errorf("adapter for %s not found: %v", supplier.Code, err)
+	}
+
+	request := &domain.SupplierRequest{
+		ProductCode:       mapping.SupplierProductCode,
+		DestinationNumber: transaction.DestinationNumber,
+		RefID:             transaction.TrxCode,
+	}
+	// Within a request the supplier must answer before the response is due
+	if deadline, ok := ctx.Deadline(); ok {
+		request.Deadline = deadline
+	}
+
+	log := logger.FromContext(ctx)
+	log.Info("Calling supplier",
+		logger.String("supplier_code", supplier.Code),
+		logger.String("product_code", mapping.SupplierProductCode),
+		logger.Int("attempt", attempt),
+	)
+
+	start := time.Now()
+	response, err := adapter.TopUp(request)
+	duration := time.Since(start)
+
+	success := err == nil && response != nil && response.Success
+	responseTime := int(duration.Milliseconds())
+	if response != nil && response.ResponseTime > 0 {
+		responseTime = response.ResponseTime
+	}
+
+	// Pending results and request-level failures say nothing about supplier health
+	countable := response == nil || (!response.IsPending() && !response.IsPermanentFailure())
+	if uc.smartRoutingUC != nil && countable {
+		if updateErr := uc.smartRoutingUC.UpdateSupplierMetrics(supplier.ID, success, responseTime); updateErr != nil {
+			log.Warn("Failed to update supplier metrics",
+				logger.String("supplier_id", supplier.ID),
+				logger.ErrorField(updateErr),
+			)
+		}
+	}
+
+	uc.appendSupplierAttempt(transaction, supplier, mapping, response, err, responseTime, attempt)
+
+	if err == nil && response == nil {
+		return nil, fmt.Errorf("empty response from %s", supplier.Code)
+	}
+	return response, err
+}
+
+// failoverSupplierTransaction retries retryable supplier failures on the next
+// best suppliers until one succeeds, reports pending or fails permanently, or
+// the policy's attempts or time budget run out. It returns the supplier and
+// result of the last attempt.
+func (uc *transactionUsecase) failoverSupplierTransaction(
+	ctx context.Context,
+	transaction *domain.Transaction,
+	supplier *domain.Supplier,
+	mapping *domain.ProductMapping,
+	response *domain.SupplierResponse,
+	callErr error,
+	policy *domain.SyncFailoverPolicy,
+	start time.Time,
+) (*domain.Supplier, *domain.ProductMapping, *domain.SupplierResponse, error) {
+	if uc.smartRoutingUC == nil {
+		return supplier, mapping, response, callErr
+	}
+
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts > domain.MaxSyncFailoverAttempts {
+		maxAttempts = domain.MaxSyncFailoverAttempts
+	}
+	deadline := start.Add(policy.Budget)
+	// A failover call must also fit in what is left of the request
+	if requestDeadline, ok := ctx.Deadline(); ok {
+		if latest := requestDeadline.Add(-uc.config.MinSupplierBudget); latest.Before(deadline) {
+			deadline = latest
+		}
+	}
+	tried := []string{supplier.ID}
+
+	for failovers := 0; failovers < maxAttempts; failovers++ {
+		if !supplierFailoverAllowed(response, callErr) {
+			break
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			logger.FromContext(ctx).Info("Synchronous failover budget exhausted",
+				logger.Int("failovers", failovers),
+			)
+			break
+		}
+
+		next, nextMapping, err := uc.nextFailoverRoute(ctx, transaction, &tried)
+		if err != nil {
+			logger.FromContext(ctx).Info("No supplier left for synchronous failover",
+				logger.ErrorField(err),
+			)
+			break
+		}
+
+		uc.appendTimeline(transaction, domain.TimelineFailover, fmt.Sprintf("Failing over from %s to %s", supplier.Code, next.Code), map[string]interface{}{
+			"from_supplier_code":    supplier.Code,
+			"supplier_code":         next.Code,
+			"supplier_product_code": nextMapping.SupplierProductCode,
+			"remaining_budget_ms":   remaining.Milliseconds(),
+		})
+
+		supplier, mapping = next, nextMapping
+		supplierID := supplier.ID
+		transaction.SupplierID = &supplierID
+		transaction.HPP = mapping.GetEffectivePrice()
+		transaction.RoutingAttempts++
+		response, callErr = uc.callSupplier(ctx, transaction, supplier, mapping, failovers+2)
+	}
+
+	return supplier, mapping, response, callErr
+}
+
+// nextFailoverRoute picks the best untried supplier whose cost stays within the
+// transaction's selling price, adding every considered supplier to tried
+func (uc *transactionUsecase) nextFailoverRoute(ctx context.Context, transaction *domain.Transaction, tried *[]string) (*domain.Supplier, *domain.ProductMapping, error) {
+	for {
+		result, err := uc.smartRoutingUC.GetFailoverRoute(transaction.ProductID, transaction.UserID, *tried)
+		if err != nil {
+			return nil, nil, err
+		}
+		supplier, mapping := result.SelectedSupplier, result.SelectedMapping
+		*tried = append(*tried, supplier.ID)
+
+		if mapping.GetEffectivePrice() > transaction.SellingPrice {
+			logger.FromContext(ctx).Debug("Skipping failover supplier priced above selling price",
+				logger.String("supplier_code", supplier.Code),
+			)
+			continue
+		}
+
+		uc.recordRoutingDecision(transaction, domain.RoutingSourceFailover, result)
+		return supplier, mapping, nil
+	}
+}
+
+// supplierFailoverAllowed reports whether a supplier result may be retried on
+// another supplier right away. Pending results may still be delivered and
+// permanent failures would fail everywhere.
+func supplierFailoverAllowed(response *domain.SupplierResponse, callErr error) bool {
+	if callErr != nil {
+		return true
+	}
+	return !response.Success && !response.IsPending() && !response.IsPermanentFailure()
+}
+
+// handleSupplierPending keeps the transaction in processing with its balance on
+// hold; the final result arrives later through a status check or callback
+func (uc *transactionUsecase) handleSupplierPending(ctx context.Context, transaction *domain.Transaction, supplier *domain.Supplier, response *domain.SupplierResponse) error {
+	if response.TrxID != "" {
+		supplierTrxID := response.TrxID
+		transaction.SupplierTrxID = &supplierTrxID
+	}
+	if response.Message != "" {
+		msg := response.Message
+		transaction.SupplierMessage = &msg
+	}
+	transaction.Status = domain.StatusProcessing
+
+	if err := uc.transactionRepo.Update(transaction); err != nil {
+		return fmt.Errorf("failed to update pending transaction: %w", err)
+	}
+
+	logger.FromContext(ctx).Info("Supplier reported transaction pending",
+		logger.String("supplier_code", supplier.Code),
+		logger.String("rc", response.ResponseCode),
+	)
+
+	return nil
+}
+
+func (uc *transactionUsecase) handleSupplierFailure(ctx context.Context, transaction *domain.Transaction, reason, errorClass string, retryable bool) error {
+	log := logger.FromContext(ctx)
+	uc.markSupplierFailure(ctx, transaction, reason)
+
+	var config *RetryConfig
+	if uc.retryUC != nil && retryable {
+		config = uc.retryConfig(transaction, errorClass)
+	}
+	// A policy with no attempts refunds right away
+	if config != nil && config.MaxAttempts > 0 {
+		result, err := uc.retryUC.RetryTransaction(transaction.ID, config)
+		if err == nil {
+			if result != nil && (result.Success || result.RefundIssued) {
+				// Retry finished the transaction, settle its balance hold accordingly
+				if err := uc.settleRetriedTransaction(transaction.ID); err != nil {
+					log.Error("Failed to settle balance hold after retry", logger.ErrorField(err))
+				}
+				return nil
+			}
+		} else {
+			log.Error("Retry transaction failed", logger.ErrorField(err))
+		}
+	}
+
+	if err := uc.refundTransaction(transaction, domain.SystemActor(domain.SystemActorRefund)); err != nil {
+		return fmt.Errorf("failed to refund transaction after supplier failure: %w", err)
+	}
+
+	return fmt.Errorf("supplier failure: %s", reason)
+}
+
+// markSupplierFailure stores a transaction as failed with the supplier's reason
+func (uc *transactionUsecase) markSupplierFailure(ctx context.Context, transaction *domain.Transaction, reason string) {
+	log := logger.FromContext(ctx)
+
+	msg := reason
+	transaction.Status = domain.StatusFailed
+	transaction.SupplierMessage = &msg
+	now := time.Now()
+	transaction.CompletedAt = &now
+
+	if err := uc.transactionRepo.Update(transaction); err != nil {
+		log.Error("Failed to update failed transaction", logger.ErrorField(err))
+	} else {
+		uc.appendTimeline(transaction, domain.TimelineStatusChanged, reason, nil)
+	}
+
+	log.Warn("Supplier failure", logger.String("reason", reason))
+}
+
+// ApplySupplierResult completes a transaction the supplier reported pending
+// with the final result it sent later, e.g. through a webhook. Results for
+// transactions that are no longer processing are ignored, so repeated
+// notifications are harmless.
+func (uc *transactionUsecase) ApplySupplierResult(ctx context.Context, supplier *domain.Supplier, response *domain.SupplierResponse) error {
+	transaction, err := uc.transactionRepo.GetByTrxCode(response.TrxID)
+	if err != nil {
+		return fmt.Errorf("transaction not found")
+	}
+
+	if transaction.SupplierID == nil || *transaction.SupplierID != supplier.ID {
+		return fmt.Errorf("transaction was not routed to this supplier")
+	}
+
+	_, err = uc.applySupplierResult(transactionContext(ctx, transaction), transaction, supplier, response)
+	return err
+}
+
+// applySupplierResult finalizes a processing transaction with a supplier
+// result. The status moves from PROCESSING inside the completion's database
+// transaction, so when the webhook and the status poll race with the same
+// result only one applies it; it reports false for the other.
+func (uc *transactionUsecase) applySupplierResult(ctx context.Context, transaction *domain.Transaction, supplier *domain.Supplier, response *domain.SupplierResponse) (bool, error) {
+	log := logger.FromContext(ctx)
+	if transaction.Status != domain.StatusProcessing || response.IsPending() {
+		log.Info("Supplier result ignored",
+			logger.String("status", transaction.Status),
+			logger.String("classification", response.Classification),
+		)
+		return false, nil
+	}
+
+	if response.Success {
+		completed, err := uc.completeSupplierSuccess(transaction, supplier, response)
+		if err != nil {
+			return false, err
+		}
+		if !completed {
+			log.Info("Supplier result already applied")
+			return false, nil
+		}
+		log.Info("Transaction completed via supplier result",
+			logger.String("supplier_code", supplier.Code),
+		)
+		return true, nil
+	}
+
+	// The supplier already gave up on the request, so it is refunded without
+	// retry or failover
+	msg := response.Message
+	if msg == "" {
+		msg = "supplier returned failure"
+	}
+	transaction.SupplierMessage = &msg
+	err := uc.refundTransactionFrom(transaction, domain.SystemActor(domain.SystemActorRefund), domain.StatusProcessing)
+	if err != nil && err.Error() == "transaction already refunded" {
+		log.Info("Supplier result already applied")
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to refund transaction after supplier failure: %w", err)
+	}
+
+	log.Warn("Supplier failure", logger.String("reason", msg))
+	return true, nil
+}
+
+// RetryFailedTransaction retries a failed transaction
+func (uc *transactionUsecase) RetryFailedTransaction(transactionID string) error {
+	// Get transaction
+	transaction, err := uc.transactionRepo.GetByID(transactionID)
+	if err != nil {
+		return fmt.Errorf("transaction not found: %w", err)
+	}
+
+	// Check if transaction can be retried
+	if !transaction.CanRetry() {
+		return fmt.Errorf("transaction cannot be retried")
+	}
+	if failedPermanently(uc.timelineRepo, transaction.ID) {
+		return fmt.Errorf("transaction failed permanently and cannot be retried")
+	}
+
+	// Increment routing attempts
+	transaction.RoutingAttempts++
+	err = uc.transactionRepo.Update(transaction)
+	if err != nil {
+		return fmt.Errorf("failed to increment routing attempts: %w", err)
+	}
+
+	// Reset status to pending
+	err = uc.transactionRepo.UpdateStatus(transactionID, domain.StatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to reset transaction status: %w", err)
+	}
+	transaction.Status = domain.StatusPending
+	uc.appendTimeline(transaction, domain.TimelineRetryRequested, "Manual retry requested", map[string]interface{}{
+		"routing_attempts": transaction.RoutingAttempts,
+	})
+
+	// Process transaction again
+	return uc.ProcessTransaction(context.Background(), transactionID)
+}
+
+// GetTransaction retrieves a transaction by ID
+func (uc *transactionUsecase) GetTransaction(id string) (*domain.Transaction, error) {
+	return uc.transactionRepo.GetByID(id)
+}
+
+// GetUserTransactions retrieves user transactions created within period with
+// pagination, optionally only those carrying all the given tags
+func (uc *transactionUsecase) GetUserTransactions(userID string, period domain.DateRange, tags domain.TransactionTags, page, limit int) ([]*domain.Transaction, error) {
+	offset := (page - 1) * limit
+	return uc.transactionRepo.GetByUserID(userID, period, tags, limit, offset)
+}
+
+// GetUserTransactionsByCursor retrieves user transactions created within period
+// using keyset pagination. It returns the cursor of the next page, empty when
+// there are no more rows.
+func (uc *transactionUsecase) GetUserTransactionsByCursor(userID string, period domain.DateRange, tags domain.TransactionTags, cursor string, limit int) ([]*domain.Transaction, string, error) {
+	after, err := domain.DecodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	limit = domain.NormalizeCursorLimit(limit)
+
+	transactions, err := uc.transactionRepo.GetByUserIDAfter(userID, period, tags, after, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(transactions) > limit {
+		transactions = transactions[:limit]
+		last := transactions[limit-1]
+		nextCursor = domain.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return transactions, nextCursor, nil
+}
+
+// GetTransactionTimeline returns the ordered event history of a transaction
+func (uc *transactionUsecase) GetTransactionTimeline(transactionID string) ([]*domain.TransactionTimelineEntry, error) {
+	if _, err := uc.transactionRepo.GetByID(transactionID); err != nil {
+		return nil, err
+	}
+	return uc.timelineRepo.ListByTransactionID(transactionID)
+}
+
+// GetRoutingDecisions returns the supplier routing decisions of a transaction, oldest first
+func (uc *transactionUsecase) GetRoutingDecisions(transactionID string) ([]*domain.RoutingDecision, error) {
+	if _, err := uc.transactionRepo.GetByID(transactionID); err != nil {
+		return nil, err
+	}
+	if uc.decisionRepo == nil {
+		return []*domain.RoutingDecision{}, nil
+	}
+	return uc.decisionRepo.ListByTransactionID(transactionID)
+}
+
+// GetTransactionByTrxCode retrieves a transaction by transaction code
+func (uc *transactionUsecase) GetTransactionByTrxCode(trxCode string) (*domain.Transaction, error) {
+	return uc.transactionRepo.GetByTrxCode(trxCode)
+}
+
+// CancelTransaction cancels a transaction
+func (uc *transactionUsecase) CancelTransaction(ctx context.Context, transactionID string) error {
+	// Get transaction
+	transaction, err := uc.transactionRepo.GetByID(transactionID)
+	if err != nil {
+		return fmt.Errorf("transaction not found: %w", err)
+	}
+
+	// Can only cancel pending transactions, including those held by a cutoff
+	if transaction.Status != domain.StatusPending && transaction.Status != domain.StatusPendingSchedule {
+		return fmt.Errorf("cannot cancel transaction in %s status", transaction.Status)
+	}
+
+	// The status is compared and set with the hold release, so a worker
+	// picking the transaction up meanwhile makes the cancellation fail
+	// instead of both going ahead
+	previousStatus := transaction.Status
+	msg := "Transaction cancelled by user"
+	now := time.Now()
+	transaction.Status = domain.StatusFailed
+	transaction.SupplierMessage = &msg
+	transaction.CompletedAt = &now
+	cancelled, err := uc.completeTransactionFrom(transaction, previousStatus)
+	if err != nil {
+		return fmt.Errorf("failed to cancel transaction: %w", err)
+	}
+	if !cancelled {
+		return fmt.Errorf("transaction is no longer pending")
+	}
+
+	logger.FromContext(transactionContext(ctx, transaction)).Info("Transaction cancelled",
+		logger.String("previous_status", previousStatus),
+	)
+
+	return nil
+}
+
+// ExpireTransactions cancels pending transactions past their auto-cancel
+// deadline, releasing their balance hold and notifying the user. It returns
+// how many transactions were cancelled.
+func (uc *transactionUsecase) ExpireTransactions() (int, error) {
+	transactions, err := uc.transactionRepo.GetExpiredPending(uc.config.ExpiryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, transaction := range transactions {
+		cancelled, err := uc.expireTransaction(transaction)
+		if err != nil {
+			logger.Error("Failed to cancel expired transaction",
+				logger.String("trace_id", transaction.TrxCode),
+				logger.String("trx_id", transaction.ID),
+				logger.ErrorField(err),
+			)
+			continue
+		}
+		if cancelled {
+			expired++
+		}
+	}
+
+	if expired > 0 {
+		logger.Info("Expired transactions cancelled", logger.Int("count", expired))
+	}
+
+	return expired, nil
+}
+
+// expireTransaction fails one expired transaction. It reports false when the
+// transaction left the pending status before it could be cancelled.
+func (uc *transactionUsecase) expireTransaction(transaction *domain.Transaction) (bool, error) {
+	cancelled := false
+	err := uc.unitOfWork.Do(func(repos domain.TxRepositories) error {
+		updated, err := repos.Transactions().TransitionStatus(transaction.ID, domain.StatusPending, domain.StatusFailed)
+		if err != nil || !updated {
+			return err
+		}
+
+		now := time.Now()
+		msg := "Transaction cancelled: not confirmed before expiry"
+		transaction.Status = domain.StatusFailed
+		transaction.SupplierMessage = &msg
+		transaction.CompletedAt = &now
+		if err := repos.Transactions().Update(transaction); err != nil {
+			return err
+		}
+
+		if err := repos.Timeline().Append(domain.NewTransactionTimelineEntry(transaction, domain.TimelineExpired, msg, map[string]interface{}{
+			"channel":    transaction.Channel,
+			"expires_at": transaction.ExpiresAt,
+		})); err != nil {
+			return err
+		}
+		if err := uc.settleBalanceHold(repos, transaction); err != nil {
+			return err
+		}
+
+		cancelled = true
+		return uc.recordTransactionEvent(repos, domain.EventTransactionCompleted, transaction)
+	})
+
+	return cancelled, err
+}
+
+// PollProcessingTransactions asks suppliers for the status of processing
+// transactions past the expected SLA of their product and applies final
+// results. Transactions still unresolved past their processing timeout are
+// marked TIMEOUT and their balance hold is released.
+func (uc *transactionUsecase) PollProcessingTransactions() (*domain.ProcessingPollResult, error) {
+	now := time.Now()
+	transactions, err := uc.transactionRepo.GetProcessingBefore(now.Add(-uc.config.ProcessingSLA.ShortestExpected()), uc.config.ExpiryBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.ProcessingPollResult{}
+	categories := uc.productCategories(transactions)
+	for _, transaction := range transactions {
+		sla := uc.config.ProcessingSLA.For(categories[transaction.ProductID], transaction.ProductCode)
+		if err := uc.pollProcessingTransaction(transaction, sla, now, result); err != nil {
+			logger.Error("Failed to poll processing transaction",
+				logger.String("trace_id", transaction.TrxCode),
+				logger.String("trx_id", transaction.ID),
+				logger.ErrorField(err),
+			)
+		}
+	}
+
+	if result.Completed+result.Failed+result.TimedOut > 0 {
+		logger.Info("Processing transactions resolved",
+			logger.Int("completed", result.Completed),
+			logger.Int("failed", result.Failed),
+			logger.Int("timed_out", result.TimedOut),
+		)
+	}
+
+	return result, nil
+}
+
+// pollProcessingTransaction checks one processing transaction against its SLA
+func (uc *transactionUsecase) pollProcessingTransaction(transaction *domain.Transaction, sla domain.ProcessingSLA, now time.Time, result *domain.ProcessingPollResult) error {
+	startedAt := transaction.CreatedAt
+	if transaction.ProcessedAt != nil {
+		startedAt = *transaction.ProcessedAt
+	}
+	elapsed := now.Sub(startedAt)
+	if elapsed < sla.Expected {
+		return nil
+	}
+
+	if transaction.SupplierID != nil && uc.adapterFactory != nil {
+		result.Checked++
+		response, supplier, err := uc.checkSupplierStatus(transaction)
+		if err != nil {
+			logger.Warn("Supplier status check failed",
+				logger.String("trace_id", transaction.TrxCode),
+				logger.String("trx_id", transaction.ID),
+				logger.ErrorField(err),
+			)
+		} else if !response.IsPending() {
+			applied, err := uc.applySupplierResult(transactionContext(context.Background(), transaction), transaction, supplier, response)
+			if err != nil || !applied {
+				return err
+			}
+			if response.Success {
+				result.Completed++
+			} else {
+				result.Failed++
+			}
+			return nil
+		}
+	}
+
+	if sla.Timeout <= 0 || elapsed < sla.Timeout {
+		return nil
+	}
+
+	timedOut, err := uc.timeoutTransaction(transaction, sla, elapsed)
+	if err != nil {
+		return err
+	}
+	if timedOut {
+		result.TimedOut++
+	}
+	return nil
+}
+
+// checkSupplierStatus asks the transaction's supplier for its current status
+func (uc *transactionUsecase) checkSupplierStatus(transaction *domain.Transaction) (*domain.SupplierResponse, *domain.Supplier, error) {
+	supplier, err := uc.supplierRepo.GetByID(*transaction.SupplierID)
+	if err != nil {
+		return nil, nil, err
+	}
+	adapter, err := uc.adapterFactory.GetSupplierAdapter(supplier)
+	if err != nil {
+		return nil, nil, fmt.Errorf("adapter for %s not found: %v", supplier.Code, err)
+	}
+
+	start := time.Now()
+	response, err := adapter.CheckStatus(transaction.TrxCode)
+	status := "success"
+	if err != nil {
+		status = "failed"
+	} else if response == nil {
+		status = "failed"
+		err = fmt.Errorf("empty response from %s", supplier.Code)
+	}
+	metrics.RecordSupplierRequest(supplier.Code, "check_status", status, time.Since(start).Seconds())
+
+	return response, supplier, err
+}
+
+// timeoutTransaction gives up a transaction still processing past its
+// timeout. It reports false when the transaction was resolved meanwhile.
+func (uc *transactionUsecase) timeoutTransaction(transaction *domain.Transaction, sla domain.ProcessingSLA, elapsed time.Duration) (bool, error) {
+	timedOut := false
+	err := uc.unitOfWork.Do(func(repos domain.TxRepositories) error {
+		updated, err := repos.Transactions().TransitionStatus(transaction.ID, domain.StatusProcessing, domain.StatusTimeout)
+		if err != nil || !updated {
+			return err
+		}
+
+		now := time.Now()
+		msg := "Transaction timed out: no final result from supplier"
+		transaction.Status = domain.StatusTimeout
+		transaction.SupplierMessage = &msg
+		transaction.CompletedAt = &now
+		if err := repos.Transactions().Update(transaction); err != nil {
+			return err
+		}
+
+		if err := repos.Timeline().Append(domain.NewTransactionTimelineEntry(transaction, domain.TimelineTimedOut, msg, map[string]interface{}{
+			"elapsed_seconds":  int(elapsed.Seconds()),
+			"expected_seconds": int(sla.Expected.Seconds()),
+			"timeout_seconds":  int(sla.Timeout.Seconds()),
+		})); err != nil {
+			return err
+		}
+		if err := uc.settleBalanceHold(repos, transaction); err != nil {
+			return err
+		}
+
+		timedOut = true
+		return uc.recordTransactionEvent(repos, domain.EventTransactionCompleted, transaction)
+	})
+
+	if timedOut {
+		logger.Warn("Processing transaction timed out",
+			logger.String("trace_id", transaction.TrxCode),
+			logger.String("trx_id", transaction.ID),
+			logger.Duration("elapsed", elapsed),
+			logger.Duration("timeout", sla.Timeout),
+		)
+	}
+
+	return timedOut, err
+}
+
+// RefundTransaction refunds a failed transaction
+func (uc *transactionUsecase) RefundTransaction(transactionID string) error {
+	// Get transaction
+	transaction, err := uc.transactionRepo.GetByID(transactionID)
+	if err != nil {
+		return fmt.Errorf("transaction not found: %w", err)
+	}
+
+	return uc.refundTransaction(transaction, domain.SystemActor(domain.SystemActorRefund))
+}
+
+// ForceRefundTransaction refunds a finished transaction on an admin's
+// request, e.g. a SUCCESS the customer never received. Transactions still in
+// progress are left to the supplier result.
+func (uc *transactionUsecase) ForceRefundTransaction(transactionID string, admin domain.Actor, reason string) (*domain.Transaction, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("reason is required")
+	}
+
+	transaction, err := uc.transactionRepo.GetByID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch transaction.Status {
+	case domain.StatusSuccess, domain.StatusFailed, domain.StatusTimeout:
+	case domain.StatusRefund:
+		return nil, fmt.Errorf("transaction already refunded")
+	default:
+		return nil, fmt.Errorf("transaction is still in progress")
+	}
+
+	// Without an active hold the amount is credited back, which is only owed
+	// when the purchase was actually debited
+	hold, err := uc.holdRepo.GetByTransactionID(transaction.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance hold: %w", err)
+	}
+	if hold == nil || !hold.IsActive() {
+		mutations, err := uc.mutationRepo.GetByReference(domain.ReferenceTypeTransaction, transaction.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check transaction mutations: %w", err)
+		}
+		if !hasPurchaseDebit(mutations) {
+			return nil, fmt.Errorf("transaction balance was never charged")
+		}
+	}
+
+	// The status is compared and set with the refund, so of two concurrent
+	// requests only one credits the balance
+	previousStatus := transaction.Status
+	if err := uc.refundTransactionFrom(transaction, admin, previousStatus); err != nil {
+		return nil, err
+	}
+
+	uc.appendTimeline(transaction, domain.TimelineForceRefunded, "Refund forced by admin", map[string]interface{}{
+		"admin_id":        admin.UserID,
+		"reason":          reason,
+		"previous_status": previousStatus,
+	})
+	logger.Warn("Transaction refund forced by admin",
+		logger.String("trx_id", transaction.ID),
+		logger.String("trx_code", transaction.TrxCode),
+		logger.String("admin_id", admin.UserID),
+		logger.String("previous_status", previousStatus),
+		logger.String("reason", reason),
+	)
+
+	return transaction, nil
+}
+
+// ReprocessTransaction sends a FAILED or TIMEOUT transaction to a supplier
+// again on an admin's request. Only transactions whose balance was never
+// charged qualify: a new hold is placed as for a fresh order, so the amount
+// is captured at most once. The result is awaited without failover or retry,
+// a failure releases the hold again.
+func (uc *transactionUsecase) ReprocessTransaction(ctx context.Context, transactionID, supplierID string, admin domain.Actor, reason string) (*domain.Transaction, error) {
+	ctx, unlock, err := uc.lockTransaction(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	transaction, err := uc.transactionRepo.GetByID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	ctx = transactionContext(ctx, transaction)
+
+	switch transaction.Status {
+	case domain.StatusFailed, domain.StatusTimeout:
+	case domain.StatusSuccess:
+		return nil, fmt.Errorf("transaction already succeeded")
+	case domain.StatusRefund:
+		return nil, fmt.Errorf("transaction already refunded")
+	default:
+		return nil, fmt.Errorf("transaction is still in progress")
+	}
+
+	// Captured holds, legacy direct debits and refunds all leave a mutation
+	charged, err := uc.mutationRepo.GetByReference(domain.ReferenceTypeTransaction, transaction.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check transaction mutations: %w", err)
+	}
+	if len(charged) > 0 {
+		return nil, fmt.Errorf("transaction balance was already charged")
+	}
+
+	supplier, mapping, err := uc.reprocessRoute(transaction, supplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	previousStatus := transaction.Status
+	reason = strings.TrimSpace(reason)
+	err = uc.unitOfWork.Do(func(repos domain.TxRepositories) error {
+		// Fails when the transaction was reprocessed or refunded meanwhile
+		updated, err := repos.Transactions().TransitionStatus(transaction.ID, previousStatus, domain.StatusProcessing)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return fmt.Errorf("transaction status changed, reload and try again")
+		}
+
+		hold, err := repos.BalanceHolds().GetByTransactionID(transaction.ID)
+		if err != nil {
+			return err
+		}
+		if hold == nil || !hold.IsActive() {
+			if err := repos.BalanceHolds().Hold(newBalanceHold(transaction)); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		routedID := supplier.ID
+		transaction.Status = domain.StatusProcessing
+		transaction.SupplierID = &routedID
+		transaction.HPP = mapping.GetEffectivePrice()
+		transaction.RoutingAttempts++
+		transaction.ProcessedAt = &now
+		transaction.CompletedAt = nil
+		transaction.ExpiresAt = nil
+		if err := repos.Transactions().Update(transaction); err != nil {
+			return err
+		}
+
+		return repos.Timeline().Append(domain.NewTransactionTimelineEntry(transaction, domain.TimelineReprocessed, fmt.Sprintf("Reprocessed by admin via %s", supplier.Code), map[string]interface{}{
+			"admin_id":              admin.UserID,
+			"reason":                reason,
+			"previous_status":       previousStatus,
+			"supplier_code":         supplier.Code,
+			"supplier_product_code": mapping.SupplierProductCode,
+			"supplier_price":        mapping.GetEffectivePrice(),
+			"supplier_override":     supplierID != "",
+			"routing_attempts":      transaction.RoutingAttempts,
+		}))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log := logger.FromContext(ctx)
+	log.Warn("Transaction reprocessed by admin",
+		logger.String("admin_id", admin.UserID),
+		logger.String("previous_status", previousStatus),
+		logger.String("supplier_code", supplier.Code),
+		logger.Bool("supplier_override", supplierID != ""),
+	)
+
+	// An empty policy keeps the admin waiting for this one supplier's answer:
+	// no failover, and a failure is released instead of retried
+	if err := uc.executeSupplierTransaction(ctx, transaction, supplier, mapping, &domain.SyncFailoverPolicy{}, nil); err != nil {
+		log.Warn("Reprocessed transaction did not succeed", logger.ErrorField(err))
+	}
+
+	return uc.transactionRepo.GetByID(transaction.ID)
+}
+
+// reprocessRoute resolves the supplier of a reprocessed transaction: the one
+// the admin picked, which must be active and sell the product, or smart
+// routing's choice
+func (uc *transactionUsecase) reprocessRoute(transaction *domain.Transaction, supplierID string) (*domain.Supplier, *domain.ProductMapping, error) {
+	if supplierID == "" {
+		return uc.selectSupplier(transaction)
+	}
+	if uc.smartRoutingUC == nil {
+		return nil, nil, fmt.Errorf("smart routing is not configured")
+	}
+
+	supplier, err := uc.supplierRepo.GetByID(supplierID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !supplier.IsActive {
+		return nil, nil, fmt.Errorf("supplier is not active")
+	}
+
+	mappings, err := uc.smartRoutingUC.productMappingRepo.GetActiveMappings(transaction.ProductID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, mapping := range mappings {
+		if mapping.SupplierID != supplier.ID {
+			continue
+		}
+		uc.recordRoutingDecision(transaction, domain.RoutingSourceAdmin, &RoutingResult{
+			SelectedSupplier: supplier,
+			SelectedMapping:  mapping,
+			Confidence:       1,
+			Reason:           "Supplier picked by admin",
+		})
+		return supplier, mapping, nil
+	}
+
+	return nil, nil, fmt.Errorf("supplier has no active mapping for this product")
+}
+
+// GetTransactionStats gets transaction statistics for a user
+func (uc *transactionUsecase) GetTransactionStats(userID string, startDate, endDate time.Time) (*domain.TransactionStats, error) {
+	// Get transactions in date range
+	transactions, err := uc.transactionRepo.GetTransactionsByDateRange(startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	// Filter by user and calculate stats
+	stats := &domain.TransactionStats{}
+	var totalAmount float64
+
+	for _, trx := range transactions {
+		if trx.UserID == userID {
+			stats.TotalTransactions++
+			totalAmount += trx.TotalAmount()
+
+			switch trx.Status {
+			case domain.StatusSuccess:
+				stats.SuccessCount++
+				stats.TotalRevenue += trx.TotalAmount()
+				stats.TotalProfit += trx.Profit
+			case domain.StatusFailed:
+				stats.FailedCount++
+			case domain.StatusPending:
+				stats.PendingCount++
+			}
+		}
+	}
+
+	// Calculate averages
+	if stats.TotalTransactions > 0 {
+		stats.AverageAmount = totalAmount / float64(stats.TotalTransactions)
+	}
+
+	return stats, nil
+}
+
+// GetTransactionStatsByTag gets a user's transaction statistics per value of
+// one tag key
+func (uc *transactionUsecase) GetTransactionStatsByTag(userID, tagKey string, startDate, endDate time.Time) ([]*domain.TaggedTransactionStats, error) {
+	tagKey = domain.NormalizeTransactionTagKey(tagKey)
+	if !domain.IsValidTransactionTagKey(tagKey) {
+		return nil, fmt.Errorf("invalid tag key")
+	}
+
+	transactions, err := uc.transactionRepo.GetTransactionsByDateRange(startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	groups := make(map[string]*domain.TaggedTransactionStats)
+	totals := make(map[string]float64)
+	for _, trx := range transactions {
+		if trx.UserID != userID {
+			continue
+		}
+
+		value := trx.Tags[tagKey]
+		group, ok := groups[value]
+		if !ok {
+			group = &domain.TaggedTransactionStats{TagValue: value}
+			groups[value] = group
+		}
+		group.TotalTransactions++
+		totals[value] += trx.TotalAmount()
+
+		switch trx.Status {
+		case domain.StatusSuccess:
+			group.SuccessCount++
+			group.TotalRevenue += trx.TotalAmount()
+			group.TotalProfit += trx.Profit
+		case domain.StatusFailed:
+			group.FailedCount++
+		case domain.StatusPending:
+			group.PendingCount++
+		}
+	}
+
+	stats := make([]*domain.TaggedTransactionStats, 0, len(groups))
+	for value, group := range groups {
+		group.AverageAmount = totals[value] / float64(group.TotalTransactions)
+		stats = append(stats, group)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].TagValue < stats[j].TagValue
+	})
+
+	return stats, nil
+}
+
+// Helper functions
+
+// completeTransaction persists a final transaction state together with its
+// balance hold settlement and outbox event
+func (uc *transactionUsecase) completeTransaction(transaction *domain.Transaction) error {
+	return uc.unitOfWork.Do(func(repos domain.TxRepositories) error {
+		return uc.persistCompletion(repos, transaction)
+	})
+}
+
+// completeTransactionFrom completes like completeTransaction after moving
+// the stored status from the given one in the same database transaction. It
+// reports false, writing nothing, when the transaction left that status
+// meanwhile.
+func (uc *transactionUsecase) completeTransactionFrom(transaction *domain.Transaction, from string) (bool, error) {
+	completed := false
+	err := uc.unitOfWork.Do(func(repos domain.TxRepositories) error {
+		updated, err := repos.Transactions().TransitionStatus(transaction.ID, from, transaction.Status)
+		if err != nil || !updated {
+			return err
+		}
+		if err := uc.persistCompletion(repos, transaction); err != nil {
+			return err
+		}
+		completed = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return completed, nil
+}
+
+// persistCompletion writes a final transaction state inside a unit of work
+func (uc *transactionUsecase) persistCompletion(repos domain.TxRepositories, transaction *domain.Transaction) error {
+	if err := repos.Transactions().Update(transaction); err != nil {
+		return err
+	}
+	if err := repos.Timeline().Append(newStatusTimelineEntry(transaction)); err != nil {
+		return err
+	}
+	if err := uc.settleBalanceHold(repos, transaction); err != nil {
+		return err
+	}
+	return uc.recordTransactionEvent(repos, domain.EventTransactionCompleted, transaction)
+}
+
+func newBalanceHold(transaction *domain.Transaction) *domain.BalanceHold {
+	return &domain.BalanceHold{
+		ID:            utils.GenerateUUID(),
+		UserID:        transaction.UserID,
+		TransactionID: transaction.ID,
+		Amount:        transaction.TotalAmount(),
+	}
+}
+
+// ensureBalanceHold places a hold for the transaction unless one is still active
+func (uc *transactionUsecase) ensureBalanceHold(transaction *domain.Transaction) error {
+	hold, err := uc.holdRepo.GetByTransactionID(transaction.ID)
+	if err != nil {
+		return err
+	}
+	if hold != nil && hold.IsActive() {
+		return nil
+	}
+
+	return uc.unitOfWork.Do(func(repos domain.TxRepositories) error {
+		return repos.BalanceHolds().Hold(newBalanceHold(transaction))
+	})
+}
+
+// settleBalanceHold captures the hold of a successful transaction as a debit
+// mutation and releases it for failed ones. A failed transaction without an
+// active hold is left untouched; a successful one must then have been
+// debited already (before holds existed), otherwise it would be delivered
+// without being paid for.
+func (uc *transactionUsecase) settleBalanceHold(repos domain.TxRepositories, transaction *domain.Transaction) error {
+	switch transaction.Status {
+	case domain.StatusSuccess:
+		hold, balanceAfter, err := repos.BalanceHolds().Capture(transaction.ID)
+		if err != nil {
+			if err.Error() == "active balance hold not found" {
+				return requirePurchaseDebit(repos, transaction)
+			}
+			return err
+		}
+
+		if err := repos.Timeline().Append(domain.NewTransactionTimelineEntry(transaction, domain.TimelineBalanceCaptured, "Balance hold captured", map[string]interface{}{
+			"amount":        hold.Amount,
+			"balance_after": balanceAfter,
+		})); err != nil {
+			return err
+		}
+
+		refType := domain.ReferenceTypeTransaction
+		return createBalanceMutation(
+			repos,
+			hold.UserID,
+			domain.MutationTypeCredit, // Credit = money out
+			hold.Amount,
+			balanceAfter+hold.Amount,
+			balanceAfter,
+			fmt.Sprintf("Pembelian %s %s", transaction.ProductCode, transaction.DestinationNumber),
+			&refType,
+			&transaction.ID,
+			domain.TransactionActor(transaction),
+		)
+	case domain.StatusFailed, domain.StatusTimeout, domain.StatusRefund:
+		hold, err := repos.BalanceHolds().Release(transaction.ID)
+		if err != nil {
+			if err.Error() == "active balance hold not found" {
+				return nil
+			}
+			return err
+		}
+		return repos.Timeline().Append(domain.NewTransactionTimelineEntry(transaction, domain.TimelineBalanceReleased, "Balance hold released", map[string]interface{}{
+			"amount": hold.Amount,
+		}))
+	}
+
+	return nil
+}
+
+// requirePurchaseDebit fails unless the transaction's balance was already
+// debited by a purchase mutation
+func requirePurchaseDebit(repos domain.TxRepositories, transaction *domain.Transaction) error {
+	mutations, err := repos.Mutations().GetByReference(domain.ReferenceTypeTransaction, transaction.ID)
+	if err != nil {
+		return err
+	}
+	if !hasPurchaseDebit(mutations) {
+		return fmt.Errorf("transaction balance was never charged")
+	}
+	return nil
+}
+
+// hasPurchaseDebit reports whether a transaction's mutations include the
+// purchase taking its amount from the balance
+func hasPurchaseDebit(mutations []*domain.Mutation) bool {
+	for _, mutation := range mutations {
+		// Credit = money out
+		if mutation.Type == domain.MutationTypeCredit {
+			return true
+		}
+	}
+	return false
+}
+
+// ReleaseScheduledTransactions moves transactions whose cutoff ended back to
+// PENDING with a fresh auto-cancel deadline and queues them for processing
+func (uc *transactionUsecase) ReleaseScheduledTransactions() (int, error) {
+	now := time.Now()
+	transactions, err := uc.transactionRepo.GetScheduledDue(now, uc.config.ExpiryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	releasedIDs := make([]string, 0, len(transactions))
+	for _, transaction := range transactions {
+		expiresAt := uc.config.AutoCancel.ExpiresAt(transaction.Channel, transaction.ProductCode, now)
+		updated, err := uc.transactionRepo.ReleaseScheduled(transaction.ID, expiresAt)
+		if err != nil {
+			logger.Error("Failed to release scheduled transaction",
+				logger.String("trace_id", transaction.TrxCode),
+				logger.String("trx_id", transaction.ID),
+				logger.ErrorField(err),
+			)
+			continue
+		}
+		if !updated {
+			continue
+		}
+
+		transaction.Status = domain.StatusPending
+		transaction.ScheduledAt = nil
+		transaction.ExpiresAt = expiresAt
+		uc.appendTimeline(transaction, domain.TimelineReleased, "Cutoff ended, transaction released for processing", nil)
+		releasedIDs = append(releasedIDs, transaction.ID)
+	}
+
+	if len(releasedIDs) == 0 {
+		return 0, nil
+	}
+
+	// A window opening releases many transactions at once, so they are
+	// enqueued in one round trip
+	if uc.queueRepo != nil {
+		if err := uc.queueRepo.EnqueueTransactions(releasedIDs); err != nil {
+			logger.Error("Failed to enqueue released transactions",
+				logger.Int("count", len(releasedIDs)),
+				logger.ErrorField(err),
+			)
+		}
+	}
+
+	logger.Info("Scheduled transactions released", logger.Int("count", len(releasedIDs)))
+
+	return len(releasedIDs), nil
+}
+
+// categoryCutoff returns the cutoff in force for a category. A failing
+// lookup lets the order through so cutoff storage problems do not stop sales.
+func (uc *transactionUsecase) categoryCutoff(ctx context.Context, category string, now time.Time) *domain.ActiveCutoff {
+	if uc.cutoffUC == nil {
+		return nil
+	}
+	cutoff, err := uc.cutoffUC.CategoryCutoff(category, now)
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to check category cutoff",
+			logger.String("category", category),
+			logger.ErrorField(err),
+		)
+		return nil
+	}
+	return cutoff
+}
+
+// scheduleTransaction holds a transaction in the from status until its cutoff
+// ends. The balance hold stays in place.
+func (uc *transactionUsecase) scheduleTransaction(ctx context.Context, transaction *domain.Transaction, from string, cutoff *domain.CutoffError) error {
+	updated, err := uc.transactionRepo.Schedule(transaction.ID, from, cutoff.Until)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return fmt.Errorf("transaction is not in %s status", strings.ToLower(from))
+	}
+
+	transaction.Status = domain.StatusPendingSchedule
+	transaction.ScheduledAt = &cutoff.Until
+	transaction.ExpiresAt = nil
+	appendTimelineEntry(uc.timelineRepo, newScheduledTimelineEntry(transaction, cutoff.Error()))
+
+	logger.FromContext(ctx).Info("Transaction held until cutoff ends",
+		logger.String("scheduled_at", cutoff.Until.Format(time.RFC3339)),
+	)
+
+	return nil
+}
+
+func newCutoffError(cutoff *domain.ActiveCutoff) *domain.CutoffError {
+	err := &domain.CutoffError{Until: cutoff.Until}
+	if cutoff.Schedule.Reason != nil {
+		err.Reason = *cutoff.Schedule.Reason
+	}
+	return err
+}
+
+func newScheduledTimelineEntry(transaction *domain.Transaction, message string) *domain.TransactionTimelineEntry {
+	return domain.NewTransactionTimelineEntry(transaction, domain.TimelineScheduled, message, map[string]interface{}{
+		"scheduled_at": transaction.ScheduledAt,
+	})
+}
+
+// productCategories returns the category of the products of a batch of
+// transactions keyed by product ID. Cached products are read in one round
+// trip; the rest come from the database and are cached in one round trip.
+// Products that cannot be read are left out.
+func (uc *transactionUsecase) productCategories(transactions []*domain.Transaction) map[string]string {
+	categories := make(map[string]string)
+	productIDs := make([]string, 0, len(transactions))
+	for _, transaction := range transactions {
+		if _, seen := categories[transaction.ProductID]; !seen {
+			categories[transaction.ProductID] = ""
+			productIDs = append(productIDs, transaction.ProductID)
+		}
+	}
+
+	cached := map[string]*domain.Product{}
+	if uc.productCache != nil {
+		products, err := uc.productCache.GetProducts(productIDs)
+		if err != nil {
+			logger.Warn("Failed to read cached products", logger.ErrorField(err))
+		} else {
+			cached = products
+		}
+	}
+
+	var loaded []*domain.Product
+	for _, productID := range productIDs {
+		product, ok := cached[productID]
+		if !ok {
+			var err error
+			if product, err = uc.productRepo.GetByID(productID); err != nil {
+				continue
+			}
+			loaded = append(loaded, product)
+		}
+		categories[productID] = product.Category
+	}
+
+	if uc.productCache != nil && len(loaded) > 0 {
+		if err := uc.productCache.CacheProducts(loaded); err != nil {
+			logger.Warn("Failed to cache products", logger.ErrorField(err))
+		}
+	}
+
+	return categories
+}
+
+// processingSLA resolves the processing SLA of a transaction's product
+func (uc *transactionUsecase) processingSLA(transaction *domain.Transaction) domain.ProcessingSLA {
+	category := ""
+	if product, err := uc.productRepo.GetByID(transaction.ProductID); err == nil {
+		category = product.Category
+	}
+	return uc.config.ProcessingSLA.For(category, transaction.ProductCode)
+}
+
+// retryConfig applies the retry policy of the supplier and error class,
+// bounded by the product's processing SLA: each attempt may take the expected
+// duration, and a transaction past its processing timeout is not retried anymore
+func (uc *transactionUsecase) retryConfig(transaction *domain.Transaction, errorClass string) *RetryConfig {
+	sla := uc.processingSLA(transaction)
+	supplierID := ""
+	if transaction.SupplierID != nil {
+		supplierID = *transaction.SupplierID
+	}
+	config := uc.retryUC.resolveConfig(supplierID, errorClass)
+	if sla.Expected > 0 {
+		config.TimeoutPerAttempt = sla.Expected
+	}
+	if sla.Timeout > 0 {
+		config.MaxAge = sla.Timeout
+	}
+	return config
+}
+
+// settleRetriedTransaction settles the hold of a transaction finished by the retry flow
+func (uc *transactionUsecase) settleRetriedTransaction(transactionID string) error {
+	transaction, err := uc.transactionRepo.GetByID(transactionID)
+	if err != nil {
+		return err
+	}
+
+	return uc.unitOfWork.Do(func(repos domain.TxRepositories) error {
+		return uc.settleBalanceHold(repos, transaction)
+	})
+}
+
+func (uc *transactionUsecase) recordTransactionEvent(repos domain.TxRepositories, eventType string, transaction *domain.Transaction) error {
+	event, err := domain.NewTransactionEvent(eventType, transaction)
+	if err != nil {
+		return err
+	}
+	return repos.Events().Create(event)
+}
+
+// createBalanceMutation stores a mutation moved by actor and its outbox event
+// using the given transactional repositories
+func createBalanceMutation(
+	repos domain.TxRepositories,
+	userID, mutationType string, amount, balanceBefore, balanceAfter float64,
+	description string, referenceType *string, referenceID *string,
+	actor domain.Actor,
+) error {
+	mutation := &domain.Mutation{
+		ID:            utils.GenerateUUID(),
+		UserID:        userID,
+		Type:          mutationType,
+		Amount:        amount,
+		BalanceBefore: balanceBefore,
+		BalanceAfter:  balanceAfter,
+		Description:   description,
+		ReferenceType: referenceType,
+		ReferenceID:   referenceID,
+		CreatedAt:     time.Now(),
+	}
+	actor.Stamp(mutation)
+
+	return persistBalanceMutation(repos, mutation)
+}
+
+// persistBalanceMutation stores a built mutation and its outbox event
+func persistBalanceMutation(repos domain.TxRepositories, mutation *domain.Mutation) error {
+	if err := repos.Mutations().Create(mutation); err != nil {
+		return fmt.Errorf("failed to create mutation: %w", err)
+	}
+
+	event, err := domain.NewBalanceMutatedEvent(mutation)
+	if err != nil {
+		return err
+	}
+	if err := repos.Events().Create(event); err != nil {
+		return fmt.Errorf("failed to record balance event: %w", err)
+	}
+
+	logger.Debug("Balance mutation persisted",
+		logger.String("user_id", mutation.UserID),
+		logger.String("type", mutation.Type),
+		logger.Float64("amount", mutation.Amount),
+	)
+
+	return nil
+}
+
+// refundTransaction releases the balance hold of a transaction or, once the
+// balance was captured, credits it back as a mutation moved by actor
+func (uc *transactionUsecase) refundTransaction(transaction *domain.Transaction, actor domain.Actor) error {
+	return uc.refundTransactionFrom(transaction, actor, "")
+}
+
+// refundTransactionFrom refunds like refundTransaction. A non-empty from
+// status is compared and set inside the refund's database transaction, so of
+// concurrent refunds only the one still seeing from moves money; the others
+// fail with "transaction already refunded".
+func (uc *transactionUsecase) refundTransactionFrom(transaction *domain.Transaction, actor domain.Actor, from string) error {
+	errAlreadyRefunded := fmt.Errorf("transaction already refunded")
+	transition := func(repos domain.TxRepositories, to string) error {
+		if from == "" {
+			return nil
+		}
+		updated, err := repos.Transactions().TransitionStatus(transaction.ID, from, to)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return errAlreadyRefunded
+		}
+		return nil
+	}
+
+	hold, err := uc.holdRepo.GetByTransactionID(transaction.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get balance hold: %w", err)
+	}
+	if hold != nil && hold.IsActive() {
+		// Balance was only reserved, releasing the hold is enough
+		transaction.Status = domain.StatusFailed
+		if transaction.CompletedAt == nil {
+			now := time.Now()
+			transaction.CompletedAt = &now
+		}
+		err := uc.unitOfWork.Do(func(repos domain.TxRepositories) error {
+			if err := transition(repos, domain.StatusFailed); err != nil {
+				return err
+			}
+			return uc.persistCompletion(repos, transaction)
+		})
+		if err == errAlreadyRefunded {
+			return err
+		}
+		if err != nil {
+			return fmt.Errorf("failed to release balance hold: %w", err)
+		}
+
+		logger.Info("Transaction balance hold released",
+			logger.String("trx_id", transaction.ID),
+			logger.String("trx_code", transaction.TrxCode),
+			logger.Float64("amount", hold.Amount),
+		)
+		return nil
+	}
+	if hold != nil && hold.Status == domain.HoldStatusReleased {
+		return fmt.Errorf("transaction balance was never charged")
+	}
+
+	msg := "Transaction refunded due to failure"
+	transaction.Status = domain.StatusRefund
 	transaction.SupplierMessage = &msg
 	now := time.Now()
 	transaction.CompletedAt = &now
 
-	if err := uc.transactionRepo.Update(transaction); err != nil {
-		logger.Error("Failed to update failed transaction", logger.ErrorField(err))
-	}
+	// Refund mutation, balance, transaction status and outbox events are atomic
+	refType := domain.ReferenceTypeTransaction
+	err = uc.unitOfWork.Do(func(repos domain.TxRepositories) error {
+		if err := transition(repos, domain.StatusRefund); err != nil {
+			return err
+		}
 
-	logger.Warn("Supplier failure",
-		logger.String("trace_id", transaction.TrxCode),
-		logger.String("trx_id", transaction.ID),
-		logger.String("reason", reason),
-	)
+		commissions, err := pendingCommissions(repos, transaction)
+		if err != nil {
+			return err
+		}
+		cashback, err := repos.Promotions().GetCashbackByTransactionID(transaction.ID)
+		if err != nil {
+			return err
+		}
+		if cashback != nil && cashback.ReversedAt != nil {
+			cashback = nil
+		}
 
-	if uc.retryUC != nil {
-		result, err := uc.retryUC.RetryTransaction(transaction.ID, nil)
-		if err == nil {
-			if result != nil {
-				if result.Success {
-					return nil
-				}
-				if result.RefundIssued {
-					return nil
-				}
+		// Lock the buyer, who also gives back any cashback, and every upline
+		// losing money in one statement ordered by ID, so refunds sharing
+		// users cannot deadlock and the balances read below cannot change
+		// before they are written
+		userIDs := []string{transaction.UserID}
+		seenUsers := map[string]bool{transaction.UserID: true}
+		for _, commission := range commissions {
+			if !seenUsers[commission.UserID] {
+				seenUsers[commission.UserID] = true
+				userIDs = append(userIDs, commission.UserID)
 			}
-		} else {
-			logger.Error("Retry transaction failed", logger.ErrorField(err))
 		}
-	}
+		if err := repos.Transfers().LockUsers(userIDs...); err != nil {
+			return err
+		}
 
-	if err := uc.refundTransaction(transaction); err != nil {
-		return fmt.Errorf("failed to refund transaction after supplier failure: %w", err)
+		user, err := repos.Users().GetByID(transaction.UserID)
+		if err != nil {
+			return err
+		}
+		newBalance := user.Balance + transaction.TotalAmount()
+		err = createBalanceMutation(
+			repos,
+			user.ID,
+			domain.MutationTypeDebit, // Debit = money in (refund)
+			transaction.TotalAmount(),
+			user.Balance,
+			newBalance,
+			fmt.Sprintf("Refund transaksi gagal %s", transaction.TrxCode),
+			&refType,
+			&transaction.ID,
+			actor,
+		)
+		if err != nil {
+			return err
+		}
+		if err := repos.Users().UpdateBalance(user.ID, newBalance); err != nil {
+			return err
+		}
+		if err := repos.Transactions().Update(transaction); err != nil {
+			return err
+		}
+		if err := repos.Timeline().Append(domain.NewTransactionTimelineEntry(transaction, domain.TimelineRefunded, msg, map[string]interface{}{
+			"amount":        transaction.TotalAmount(),
+			"balance_after": newBalance,
+		})); err != nil {
+			return err
+		}
+		if err := reverseCommissions(repos, transaction, commissions, actor); err != nil {
+			return err
+		}
+		if err := reverseCashback(repos, transaction, cashback, actor); err != nil {
+			return err
+		}
+		if err := uc.recordTransactionEvent(repos, domain.EventRefundIssued, transaction); err != nil {
+			return err
+		}
+		return uc.recordTransactionEvent(repos, domain.EventTransactionCompleted, transaction)
+	})
+	if err == errAlreadyRefunded {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create refund mutation: %w", err)
 	}
 
-	return fmt.Errorf("supplier failure: %s", reason)
+	logger.Info("Transaction refunded successfully",
+		logger.String("trx_id", transaction.ID),
+		logger.String("trx_code", transaction.TrxCode),
+		logger.Float64("amount", transaction.TotalAmount()),
+	)
+
+	return nil
 }
 
-// RetryFailedTransaction retries a failed transaction
-func (uc *transactionUsecase) RetryFailedTransaction(transactionID string) error {
-	// Get transaction
-	transaction, err := uc.transactionRepo.GetByID(transactionID)
+// pendingCommissions returns the upline commissions paid for a transaction
+// that were not reversed yet
+func pendingCommissions(repos domain.TxRepositories, transaction *domain.Transaction) ([]*domain.Mutation, error) {
+	paid, err := repos.Mutations().GetByReference(domain.ReferenceTypeCommission, transaction.ID)
 	if err != nil {
-		return fmt.Errorf("transaction not found: %w", err)
+		return nil, err
 	}
 
-	// Check if transaction can be retried
-	if !transaction.CanRetry() {
-		return fmt.Errorf("transaction cannot be retried")
+	reversals, err := repos.CommissionReversals().ListByTransactionID(transaction.ID)
+	if err != nil {
+		return nil, err
+	}
+	reversed := make(map[string]bool, len(reversals))
+	for _, reversal := range reversals {
+		reversed[reversal.CommissionMutationID] = true
 	}
 
-	// Increment routing attempts
-	transaction.RoutingAttempts++
-	err = uc.transactionRepo.Update(transaction)
-	if err != nil {
-		return fmt.Errorf("failed to increment routing attempts: %w", err)
+	var commissions []*domain.Mutation
+	for _, mutation := range paid {
+		if mutation.Type != domain.MutationTypeDebit || reversed[mutation.ID] {
+			continue
+		}
+		commissions = append(commissions, mutation)
 	}
+	return commissions, nil
+}
 
-	// Reset status to pending
-	err = uc.transactionRepo.UpdateStatus(transactionID, domain.StatusPending)
-	if err != nil {
-		return fmt.Errorf("failed to reset transaction status: %w", err)
+// reverseCommissions takes back the pending upline commissions of a refunded
+// transaction, inside the refund's database transaction, whose caller already
+// locked the uplines. A commission is reversed at most once, and never by
+// more than the upline's available balance; what cannot be taken back is
+// audited as shortfall.
+func reverseCommissions(repos domain.TxRepositories, transaction *domain.Transaction, commissions []*domain.Mutation, actor domain.Actor) error {
+	uplines := make(map[string]*domain.User)
+	for _, commission := range commissions {
+		if uplines[commission.UserID] != nil {
+			continue
+		}
+		upline, err := repos.Users().GetByID(commission.UserID)
+		if err != nil {
+			return err
+		}
+		uplines[commission.UserID] = upline
 	}
 
-	// Process transaction again
-	return uc.ProcessTransaction(transactionID)
-}
+	refType := domain.ReferenceTypeCommissionReversal
+	for _, commission := range commissions {
+		upline := uplines[commission.UserID]
+		amount := math.Min(commission.Amount, math.Max(upline.AvailableBalance(), 0))
+
+		reversal := &domain.CommissionReversal{
+			ID:                   utils.GenerateUUID(),
+			TransactionID:        transaction.ID,
+			UserID:               upline.ID,
+			CommissionMutationID: commission.ID,
+			CommissionAmount:     commission.Amount,
+			ReversedAmount:       amount,
+			Shortfall:            commission.Amount - amount,
+			CreatedAt:            time.Now(),
+		}
 
-// GetTransaction retrieves a transaction by ID
-func (uc *transactionUsecase) GetTransaction(id string) (*domain.Transaction, error) {
-	return uc.transactionRepo.GetByID(id)
-}
+		if amount > 0 {
+			mutation := &domain.Mutation{
+				ID:            utils.GenerateUUID(),
+				UserID:        upline.ID,
+				Type:          domain.MutationTypeCredit, // Credit = money out
+				Amount:        amount,
+				BalanceBefore: upline.Balance,
+				BalanceAfter:  upline.Balance - amount,
+				Description:   fmt.Sprintf("Pembatalan komisi transaksi %s", transaction.TrxCode),
+				ReferenceType: &refType,
+				ReferenceID:   &transaction.ID,
+				CreatedAt:     time.Now(),
+			}
+			actor.Stamp(mutation)
+			if err := persistBalanceMutation(repos, mutation); err != nil {
+				return err
+			}
+			if err := repos.Users().UpdateBalance(upline.ID, mutation.BalanceAfter); err != nil {
+				return err
+			}
+			upline.Balance = mutation.BalanceAfter
+			reversal.ReversalMutationID = &mutation.ID
+		}
 
-// GetUserTransactions retrieves user transactions with pagination
-func (uc *transactionUsecase) GetUserTransactions(userID string, page, limit int) ([]*domain.Transaction, error) {
-	offset := (page - 1) * limit
-	return uc.transactionRepo.GetByUserID(userID, limit, offset)
-}
+		if err := repos.CommissionReversals().Create(reversal); err != nil {
+			return err
+		}
+		if err := repos.Timeline().Append(domain.NewTransactionTimelineEntry(transaction, domain.TimelineCommissionReversed, "Commission reversed", map[string]interface{}{
+			"user_id":           upline.ID,
+			"commission_amount": reversal.CommissionAmount,
+			"reversed_amount":   reversal.ReversedAmount,
+			"shortfall":         reversal.Shortfall,
+		})); err != nil {
+			return err
+		}
 
-// GetTransactionByTrxCode retrieves a transaction by transaction code
-func (uc *transactionUsecase) GetTransactionByTrxCode(trxCode string) (*domain.Transaction, error) {
-	return uc.transactionRepo.GetByTrxCode(trxCode)
+		if reversal.Shortfall > 0 {
+			logger.Warn("Commission reversal short of balance",
+				logger.String("trx_id", transaction.ID),
+				logger.String("user_id", upline.ID),
+				logger.Float64("commission_amount", reversal.CommissionAmount),
+				logger.Float64("shortfall", reversal.Shortfall),
+			)
+		}
+	}
+
+	return nil
 }
 
-// CancelTransaction cancels a transaction
-func (uc *transactionUsecase) CancelTransaction(transactionID string) error {
-	// Get transaction
-	transaction, err := uc.transactionRepo.GetByID(transactionID)
+// reverseCashback takes back the unreversed promotion cashback of a refunded
+// transaction, if any, inside the refund's database transaction whose caller
+// already locked the buyer. It is capped like commission reversals at the
+// buyer's available balance. The promotion's budget gets back what was taken.
+func reverseCashback(repos domain.TxRepositories, transaction *domain.Transaction, cashback *domain.PromotionCashback, actor domain.Actor) error {
+	if cashback == nil {
+		return nil
+	}
+
+	buyer, err := repos.Users().GetByID(cashback.UserID)
 	if err != nil {
-		return fmt.Errorf("transaction not found: %w", err)
+		return err
 	}
 
-	// Can only cancel pending transactions
-	if transaction.Status != domain.StatusPending {
-		return fmt.Errorf("cannot cancel transaction in %s status", transaction.Status)
+	amount := math.Min(cashback.Amount, math.Max(buyer.AvailableBalance(), 0))
+	if amount > 0 {
+		refType := domain.ReferenceTypeCashbackReversal
+		mutation := &domain.Mutation{
+			ID:            utils.GenerateUUID(),
+			UserID:        buyer.ID,
+			Type:          domain.MutationTypeCredit, // Credit = money out
+			Amount:        amount,
+			BalanceBefore: buyer.Balance,
+			BalanceAfter:  buyer.Balance - amount,
+			Description:   fmt.Sprintf("Pembatalan cashback transaksi %s", transaction.TrxCode),
+			ReferenceType: &refType,
+			ReferenceID:   &transaction.ID,
+			CreatedAt:     time.Now(),
+		}
+		actor.Stamp(mutation)
+		if err := persistBalanceMutation(repos, mutation); err != nil {
+			return err
+		}
+		if err := repos.Users().UpdateBalance(buyer.ID, mutation.BalanceAfter); err != nil {
+			return err
+		}
+		if err := repos.Promotions().AddSpent(cashback.PromotionID, -amount); err != nil {
+			return err
+		}
 	}
 
-	// Update status to failed
-	msg := "Transaction cancelled by user"
-	transaction.Status = domain.StatusFailed
-	transaction.SupplierMessage = &msg
-	err = uc.transactionRepo.Update(transaction)
-	if err != nil {
-		return fmt.Errorf("failed to cancel transaction: %w", err)
+	if err := repos.Promotions().MarkCashbackReversed(cashback.ID, amount); err != nil {
+		return err
+	}
+	if err := repos.Timeline().Append(domain.NewTransactionTimelineEntry(transaction, domain.TimelineCashbackReversed, "Cashback reversed", map[string]interface{}{
+		"promotion_id":    cashback.PromotionID,
+		"cashback_amount": cashback.Amount,
+		"reversed_amount": amount,
+		"shortfall":       cashback.Amount - amount,
+	})); err != nil {
+		return err
 	}
 
-	// Refund balance if already deducted
-	if transaction.Status == domain.StatusProcessing {
-		err = uc.refundTransaction(transaction)
-		if err != nil {
-			logger.Error("Failed to refund cancelled transaction", logger.ErrorField(err))
-		}
+	if amount < cashback.Amount {
+		logger.Warn("Cashback reversal short of balance",
+			logger.String("trx_id", transaction.ID),
+			logger.String("user_id", buyer.ID),
+			logger.Float64("cashback_amount", cashback.Amount),
+			logger.Float64("shortfall", cashback.Amount-amount),
+		)
 	}
 
 	return nil
 }
 
-// RefundTransaction refunds a failed transaction
-func (uc *transactionUsecase) RefundTransaction(transactionID string) error {
-	// Get transaction
-	transaction, err := uc.transactionRepo.GetByID(transactionID)
-	if err != nil {
-		return fmt.Errorf("transaction not found: %w", err)
+// recordRoutingDecision stores why routing chose the supplier. Failures are
+// logged only, like the timeline it must never break transaction processing.
+func (uc *transactionUsecase) recordRoutingDecision(transaction *domain.Transaction, source string, result *RoutingResult) {
+	if uc.decisionRepo == nil {
+		return
 	}
 
-	return uc.refundTransaction(transaction)
+	decision := newRoutingDecision(transaction.ID, source, result)
+	if err := uc.decisionRepo.Create(decision); err != nil {
+		logger.Error("Failed to record routing decision",
+			logger.String("trx_id", transaction.ID),
+			logger.String("supplier_code", decision.SupplierCode),
+			logger.ErrorField(err),
+		)
+	}
 }
 
-// GetTransactionStats gets transaction statistics for a user
-func (uc *transactionUsecase) GetTransactionStats(userID string, startDate, endDate time.Time) (*domain.TransactionStats, error) {
-	// Get transactions in date range
-	transactions, err := uc.transactionRepo.GetTransactionsByDateRange(startDate, endDate)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get transactions: %w", err)
-	}
+// appendTimeline records a timeline entry outside a unit of work. Failures are
+// logged only, the timeline must never break transaction processing.
+func (uc *transactionUsecase) appendTimeline(transaction *domain.Transaction, eventType, message string, details map[string]interface{}) {
+	appendTimelineEntry(uc.timelineRepo, domain.NewTransactionTimelineEntry(transaction, eventType, message, details))
+}
 
-	// Filter by user and calculate stats
-	stats := &domain.TransactionStats{}
-	var totalAmount float64
+// Transaction processing stages, the stage label of the stage histogram
+const (
+	stageValidation   = "validation"
+	stageDebit        = "debit"
+	stageRouting      = "routing"
+	stageSupplierCall = "supplier_call"
+	stagePersistence  = "persistence"
+)
 
-	for _, trx := range transactions {
-		if trx.UserID == userID {
-			stats.TotalTransactions++
-			totalAmount += trx.SellingPrice
+// stageTiming is how long one stage of a processing run took
+type stageTiming struct {
+	stage    string
+	duration time.Duration
+}
 
-			switch trx.Status {
-			case domain.StatusSuccess:
-				stats.SuccessCount++
-				stats.TotalRevenue += trx.SellingPrice
-				stats.TotalProfit += trx.Profit
-			case domain.StatusFailed:
-				stats.FailedCount++
-			case domain.StatusPending:
-				stats.PendingCount++
-			}
-		}
-	}
+// stageTimer times the consecutive stages of one processing run. A nil timer
+// ignores every call, for runs that are not timed.
+type stageTimer struct {
+	start      time.Time
+	stage      string
+	stageStart time.Time
+	timings    []stageTiming
+}
 
-	// Calculate averages
-	if stats.TotalTransactions > 0 {
-		stats.AverageAmount = totalAmount / float64(stats.TotalTransactions)
-	}
+func newStageTimer(stage string) *stageTimer {
+	now := time.Now()
+	return &stageTimer{start: now, stage: stage, stageStart: now}
+}
 
-	return stats, nil
+// begin ends the current stage and starts the next one
+func (t *stageTimer) begin(stage string) {
+	if t == nil {
+		return
+	}
+	now := time.Now()
+	t.timings = append(t.timings, stageTiming{stage: t.stage, duration: now.Sub(t.stageStart)})
+	t.stage = stage
+	t.stageStart = now
 }
 
-// Helper functions
+// finish ends the current stage and returns the timings of the run
+func (t *stageTimer) finish() []stageTiming {
+	t.begin("")
+	return t.timings
+}
 
-func (uc *transactionUsecase) createBalanceMutation(
-	userID, mutationType string, amount, balanceBefore, balanceAfter float64,
-	description string, referenceType *string, referenceID *string,
-) error {
-	if uc.mutationRepo == nil {
-		return fmt.Errorf("mutation repository is not configured")
+// recordStages observes the stage durations of a processing run. A run slower
+// than the SlowProcessing threshold also gets its timings on the timeline, so
+// the outlier can be explained from the transaction itself.
+func (uc *transactionUsecase) recordStages(ctx context.Context, transaction *domain.Transaction, stages *stageTimer) {
+	timings := stages.finish()
+	for _, timing := range timings {
+		metrics.RecordTransactionStage(timing.stage, timing.duration.Seconds())
 	}
 
-	mutation := &domain.Mutation{
-		ID:            utils.GenerateUUID(),
-		UserID:        userID,
-		Type:          mutationType,
-		Amount:        amount,
-		BalanceBefore: balanceBefore,
-		BalanceAfter:  balanceAfter,
-		Description:   description,
-		ReferenceType: referenceType,
-		ReferenceID:   referenceID,
-		CreatedAt:     time.Now(),
+	total := time.Since(stages.start)
+	if total < uc.config.SlowProcessing {
+		return
 	}
 
-	if err := uc.mutationRepo.Create(mutation); err != nil {
-		return fmt.Errorf("failed to create mutation: %w", err)
+	details := map[string]interface{}{
+		"total_ms":     total.Milliseconds(),
+		"threshold_ms": uc.config.SlowProcessing.Milliseconds(),
 	}
+	slowest := timings[0]
+	for _, timing := range timings {
+		details[timing.stage+"_ms"] = timing.duration.Milliseconds()
+		if timing.duration > slowest.duration {
+			slowest = timing
+		}
+	}
+	details["slowest_stage"] = slowest.stage
 
-	logger.Debug("Balance mutation persisted",
-		logger.String("user_id", userID),
-		logger.String("type", mutationType),
-		logger.Float64("amount", amount),
+	logger.FromContext(ctx).Warn("Slow transaction processing",
+		logger.Duration("duration", total),
+		logger.String("slowest_stage", slowest.stage),
+		logger.Duration("slowest_duration", slowest.duration),
 	)
-
-	return nil
+	uc.appendTimeline(transaction, domain.TimelineSlowProcessing,
+		fmt.Sprintf("Processing took %s, mostly %s", total.Round(time.Millisecond), slowest.stage), details)
 }
 
-func (uc *transactionUsecase) refundTransaction(transaction *domain.Transaction) error {
-	// Get user
-	user, err := uc.userRepo.GetByID(transaction.UserID)
-	if err != nil {
-		return fmt.Errorf("failed to get user for refund: %w", err)
+func (uc *transactionUsecase) appendSupplierAttempt(
+	transaction *domain.Transaction,
+	supplier *domain.Supplier,
+	mapping *domain.ProductMapping,
+	response *domain.SupplierResponse,
+	callErr error,
+	responseTime int,
+	attempt int,
+) {
+	details := map[string]interface{}{
+		"supplier_code":         supplier.Code,
+		"supplier_product_code": mapping.SupplierProductCode,
+		"supplier_cost":         mapping.GetEffectivePrice(),
+		"response_time_ms":      responseTime,
+		"success":               callErr == nil && response != nil && response.Success,
 	}
 
-	// Create refund mutation
-	refType := domain.ReferenceTypeTransaction
-	err = uc.createBalanceMutation(
-		user.ID,
-		domain.MutationTypeDebit, // Debit = money in (refund)
-		transaction.SellingPrice,
-		user.Balance,
-		user.Balance+transaction.SellingPrice,
-		fmt.Sprintf("Refund transaksi gagal %s", transaction.TrxCode),
-		&refType,
-		&transaction.ID,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create refund mutation: %w", err)
+	message := ""
+	if callErr != nil {
+		message = callErr.Error()
+	} else if response != nil {
+		message = response.Message
+		if response.TrxID != "" {
+			details["supplier_trx_id"] = response.TrxID
+		}
+		if response.StatusCode != 0 {
+			details["supplier_status_code"] = response.StatusCode
+		}
+		if response.ResponseCode != "" {
+			details["rc"] = response.ResponseCode
+		}
+		if response.Classification != "" {
+			details["classification"] = response.Classification
+		}
 	}
 
-	// Update user balance
-	newBalance := user.Balance + transaction.SellingPrice
-	err = uc.userRepo.UpdateBalance(user.ID, newBalance)
-	if err != nil {
-		logger.Error("Failed to update user balance for refund", logger.ErrorField(err))
+	entry := domain.NewTransactionTimelineEntry(transaction, domain.TimelineSupplierAttempt, message, details)
+	entry.SupplierID = &supplier.ID
+	entry.Attempt = &attempt
+	appendTimelineEntry(uc.timelineRepo, entry)
+}
+
+// newStatusTimelineEntry records the transaction status together with the supplier message
+func newStatusTimelineEntry(transaction *domain.Transaction) *domain.TransactionTimelineEntry {
+	message := ""
+	if transaction.SupplierMessage != nil {
+		message = *transaction.SupplierMessage
 	}
 
-	// Update transaction status
-	msg := "Transaction refunded due to failure"
-	transaction.Status = domain.StatusRefund
-	transaction.SupplierMessage = &msg
-	now := time.Now()
-	transaction.CompletedAt = &now
-	err = uc.transactionRepo.Update(transaction)
-	if err != nil {
-		logger.Error("Failed to update transaction status for refund", logger.ErrorField(err))
+	var details map[string]interface{}
+	if transaction.SerialNumber != nil {
+		details = map[string]interface{}{"serial_number": *transaction.SerialNumber}
 	}
 
-	logger.Info("Transaction refunded successfully",
-		logger.String("trx_id", transaction.ID),
-		logger.String("trx_code", transaction.TrxCode),
-		logger.Float64("amount", transaction.SellingPrice),
-	)
+	entry := domain.NewTransactionTimelineEntry(transaction, domain.TimelineStatusChanged, message, details)
+	if transaction.FinalSupplierID != nil {
+		entry.SupplierID = transaction.FinalSupplierID
+	}
+	return entry
+}
 
-	return nil
+func appendTimelineEntry(repo domain.TransactionTimelineRepository, entry *domain.TransactionTimelineEntry) {
+	if repo == nil {
+		return
+	}
+	if err := repo.Append(entry); err != nil {
+		logger.Warn("Failed to append transaction timeline",
+			logger.String("trx_id", entry.TransactionID),
+			logger.String("event_type", entry.EventType),
+			logger.ErrorField(err),
+		)
+	}
 }
 
 func (uc *transactionUsecase) simulateSupplierCall(transaction *domain.Transaction) error {
@@ -695,3 +2817,98 @@ func (uc *transactionUsecase) simulateSupplierCall(transaction *domain.Transacti
 		return fmt.Errorf("supplier call failed")
 	}
 }
+
+type transactionLockKey struct{}
+
+// lockTransaction takes the processing lock of a transaction, so two workers
+// (or a worker and an admin reprocess) never call suppliers for it at the
+// same time. The returned context carries the lock for checkTransactionLock.
+// When Redis fails processing goes on unlocked: the status transitions still
+// admit a single worker, the lock only narrows the window further.
+func (uc *transactionUsecase) lockTransaction(ctx context.Context, transactionID string) (context.Context, func(), error) {
+	if uc.lockRepo == nil {
+		return ctx, func() {}, nil
+	}
+
+	log := logger.FromContext(ctx)
+	lock, err := uc.lockRepo.Acquire(transactionID, uc.config.LockTTL)
+	if err != nil {
+		metrics.RecordTransactionLock("error")
+		log.Error("Failed to acquire transaction lock, processing unlocked",
+			logger.String("trx_id", transactionID),
+			logger.ErrorField(err),
+		)
+		return ctx, func() {}, nil
+	}
+	if lock == nil {
+		metrics.RecordTransactionLock("contended")
+		log.Warn("Transaction lock contended",
+			logger.String("trx_id", transactionID),
+		)
+		return ctx, nil, fmt.Errorf("transaction is being processed by another worker")
+	}
+	metrics.RecordTransactionLock("acquired")
+
+	unlock := func() {
+		held := time.Since(lock.AcquiredAt)
+		metrics.RecordTransactionLockHold(held.Seconds())
+		if held > uc.config.LockTTL {
+			log.Warn("Transaction lock held past its TTL",
+				logger.String("trx_id", transactionID),
+				logger.Duration("held", held),
+				logger.Duration("ttl", uc.config.LockTTL),
+			)
+		}
+		if err := uc.lockRepo.Release(lock); err != nil {
+			log.Warn("Failed to release transaction lock, it expires with its TTL",
+				logger.String("trx_id", transactionID),
+				logger.ErrorField(err),
+			)
+		}
+	}
+
+	return context.WithValue(ctx, transactionLockKey{}, lock), unlock, nil
+}
+
+// checkTransactionLock is the fence before each supplier call: a worker whose
+// lock expired and went to another holder (higher token) must not call the
+// supplier anymore. Unlocked contexts and Redis failures pass.
+func (uc *transactionUsecase) checkTransactionLock(ctx context.Context) error {
+	lock, ok := ctx.Value(transactionLockKey{}).(*domain.TransactionLock)
+	if !ok || uc.lockRepo == nil {
+		return nil
+	}
+
+	held, err := uc.lockRepo.Validate(lock)
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to validate transaction lock", logger.ErrorField(err))
+		return nil
+	}
+	if !held {
+		metrics.RecordTransactionLock("lost")
+		logger.FromContext(ctx).Error("Transaction lock lost before supplier call",
+			logger.Int64("fencing_token", lock.Token),
+			logger.Duration("held", time.Since(lock.AcquiredAt)),
+		)
+		return fmt.Errorf("transaction lock lost, another worker took over")
+	}
+
+	return nil
+}
+
+// supplierBudgetExhausted reports whether too little of the request is left
+// to start a supplier call. Without a request deadline there is no limit.
+func (uc *transactionUsecase) supplierBudgetExhausted(ctx context.Context) bool {
+	deadline, ok := ctx.Deadline()
+	return ok && time.Until(deadline) < uc.config.MinSupplierBudget
+}
+
+// transactionContext tags the context logger with the transaction component
+// and identifiers
+func transactionContext(ctx context.Context, transaction *domain.Transaction) context.Context {
+	ctx = logger.WithComponent(ctx, logger.ComponentTransaction)
+	return logger.WithContext(ctx,
+		logger.String("trx_id", transaction.ID),
+		logger.String("trx_code", transaction.TrxCode),
+	)
+}
@@ -0,0 +1,312 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+)
+
+// fakeLedger holds the users and ledger rows a refund reads and writes
+type fakeLedger struct {
+	users     map[string]*domain.User
+	mutations []*domain.Mutation
+	reversals []*domain.CommissionReversal
+	cashback  *domain.PromotionCashback
+	locked    [][]string
+}
+
+type fakeLedgerUsers struct {
+	domain.UserRepository
+	ledger *fakeLedger
+}
+
+func (r *fakeLedgerUsers) GetByID(id string) (*domain.User, error) {
+	user, ok := r.ledger.users[id]
+	if !ok {
+		return nil, fmt.Errorf("user not found")
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *fakeLedgerUsers) UpdateBalance(id string, newBalance float64) error {
+	r.ledger.users[id].Balance = newBalance
+	return nil
+}
+
+type fakeLedgerMutations struct {
+	domain.MutationRepository
+	ledger *fakeLedger
+}
+
+func (r *fakeLedgerMutations) Create(mutation *domain.Mutation) error {
+	r.ledger.mutations = append(r.ledger.mutations, mutation)
+	return nil
+}
+
+func (r *fakeLedgerMutations) GetByReference(referenceType, referenceID string) ([]*domain.Mutation, error) {
+	var found []*domain.Mutation
+	for _, mutation := range r.ledger.mutations {
+		if mutation.ReferenceType != nil && *mutation.ReferenceType == referenceType &&
+			mutation.ReferenceID != nil && *mutation.ReferenceID == referenceID {
+			found = append(found, mutation)
+		}
+	}
+	return found, nil
+}
+
+type fakeLedgerTransfers struct {
+	domain.BalanceTransferRepository
+	ledger *fakeLedger
+}
+
+// LockUsers fails on repeated IDs like the FOR UPDATE query, which returns
+// fewer rows than IDs asked for
+func (r *fakeLedgerTransfers) LockUsers(userIDs ...string) error {
+	seen := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		if seen[id] || r.ledger.users[id] == nil {
+			return fmt.Errorf("user not found")
+		}
+		seen[id] = true
+	}
+	r.ledger.locked = append(r.ledger.locked, userIDs)
+	return nil
+}
+
+type fakeLedgerReversals struct {
+	domain.CommissionReversalRepository
+	ledger *fakeLedger
+}
+
+func (r *fakeLedgerReversals) Create(reversal *domain.CommissionReversal) error {
+	r.ledger.reversals = append(r.ledger.reversals, reversal)
+	return nil
+}
+
+func (r *fakeLedgerReversals) ListByTransactionID(transactionID string) ([]*domain.CommissionReversal, error) {
+	return r.ledger.reversals, nil
+}
+
+type fakeLedgerPromotions struct {
+	domain.PromotionRepository
+	ledger *fakeLedger
+}
+
+func (r *fakeLedgerPromotions) GetCashbackByTransactionID(transactionID string) (*domain.PromotionCashback, error) {
+	return r.ledger.cashback, nil
+}
+
+func (r *fakeLedgerPromotions) AddSpent(id string, amount float64) error { return nil }
+
+func (r *fakeLedgerPromotions) MarkCashbackReversed(id string, amount float64) error { return nil }
+
+type fakeLedgerTransactions struct{ domain.TransactionRepository }
+
+func (r *fakeLedgerTransactions) Update(transaction *domain.Transaction) error { return nil }
+
+type fakeLedgerEvents struct{ domain.EventRepository }
+
+func (r *fakeLedgerEvents) Create(event *domain.DomainEvent) error { return nil }
+
+type fakeLedgerTimeline struct {
+	domain.TransactionTimelineRepository
+}
+
+func (r *fakeLedgerTimeline) Append(entry *domain.TransactionTimelineEntry) error { return nil }
+
+type fakeLedgerHolds struct{ domain.BalanceHoldRepository }
+
+func (r *fakeLedgerHolds) GetByTransactionID(transactionID string) (*domain.BalanceHold, error) {
+	return &domain.BalanceHold{TransactionID: transactionID, Status: domain.HoldStatusCaptured}, nil
+}
+
+// fakeLedgerUnitOfWork runs the refund against the ledger without rollback
+type fakeLedgerUnitOfWork struct {
+	domain.TxRepositories
+	ledger *fakeLedger
+}
+
+func (u *fakeLedgerUnitOfWork) Do(fn func(repos domain.TxRepositories) error) error {
+	return fn(u)
+}
+
+func (u *fakeLedgerUnitOfWork) Users() domain.UserRepository {
+	return &fakeLedgerUsers{ledger: u.ledger}
+}
+
+func (u *fakeLedgerUnitOfWork) Transactions() domain.TransactionRepository {
+	return &fakeLedgerTransactions{}
+}
+
+func (u *fakeLedgerUnitOfWork) Mutations() domain.MutationRepository {
+	return &fakeLedgerMutations{ledger: u.ledger}
+}
+
+func (u *fakeLedgerUnitOfWork) Events() domain.EventRepository { return &fakeLedgerEvents{} }
+
+func (u *fakeLedgerUnitOfWork) Timeline() domain.TransactionTimelineRepository {
+	return &fakeLedgerTimeline{}
+}
+
+func (u *fakeLedgerUnitOfWork) Transfers() domain.BalanceTransferRepository {
+	return &fakeLedgerTransfers{ledger: u.ledger}
+}
+
+func (u *fakeLedgerUnitOfWork) CommissionReversals() domain.CommissionReversalRepository {
+	return &fakeLedgerReversals{ledger: u.ledger}
+}
+
+func (u *fakeLedgerUnitOfWork) Promotions() domain.PromotionRepository {
+	return &fakeLedgerPromotions{ledger: u.ledger}
+}
+
+// commissionMutation is a COMMISSION row as a commission payout would write it
+func commissionMutation(id, userID, transactionID string, amount float64) *domain.Mutation {
+	refType := domain.ReferenceTypeCommission
+	return &domain.Mutation{
+		ID:            id,
+		UserID:        userID,
+		Type:          domain.MutationTypeDebit, // Debit = money in
+		Amount:        amount,
+		ReferenceType: &refType,
+		ReferenceID:   &transactionID,
+	}
+}
+
+func TestRefundReversesCommissions(t *testing.T) {
+	const trxID = "trx-1"
+
+	tests := []struct {
+		name          string
+		uplines       map[string]*domain.User
+		commissions   []*domain.Mutation
+		reversed      []*domain.CommissionReversal
+		cashback      *domain.PromotionCashback
+		wantBalances  map[string]float64
+		wantReversals int
+		wantShortfall float64
+		wantLocked    []string
+	}{
+		{
+			name: "commissions of two uplines",
+			uplines: map[string]*domain.User{
+				"upline-1": {ID: "upline-1", Balance: 5000},
+				"upline-2": {ID: "upline-2", Balance: 5000},
+			},
+			commissions: []*domain.Mutation{
+				commissionMutation("c1", "upline-1", trxID, 300),
+				commissionMutation("c2", "upline-2", trxID, 200),
+			},
+			wantBalances:  map[string]float64{"buyer": 11000, "upline-1": 4700, "upline-2": 4800},
+			wantReversals: 2,
+			wantLocked:    []string{"buyer", "upline-1", "upline-2"},
+		},
+		{
+			name: "upline short of available balance",
+			uplines: map[string]*domain.User{
+				"upline-1": {ID: "upline-1", Balance: 500, HeldBalance: 400},
+			},
+			commissions:   []*domain.Mutation{commissionMutation("c1", "upline-1", trxID, 300)},
+			wantBalances:  map[string]float64{"buyer": 11000, "upline-1": 400},
+			wantReversals: 1,
+			wantShortfall: 200,
+			wantLocked:    []string{"buyer", "upline-1"},
+		},
+		{
+			name: "upline with two commissions is locked once",
+			uplines: map[string]*domain.User{
+				"upline-1": {ID: "upline-1", Balance: 5000},
+			},
+			commissions: []*domain.Mutation{
+				commissionMutation("c1", "upline-1", trxID, 300),
+				commissionMutation("c2", "upline-1", trxID, 100),
+			},
+			wantBalances:  map[string]float64{"buyer": 11000, "upline-1": 4600},
+			wantReversals: 2,
+			wantLocked:    []string{"buyer", "upline-1"},
+		},
+		{
+			name: "reversed commission is skipped",
+			uplines: map[string]*domain.User{
+				"upline-1": {ID: "upline-1", Balance: 5000},
+			},
+			commissions: []*domain.Mutation{
+				commissionMutation("c1", "upline-1", trxID, 300),
+				commissionMutation("c2", "upline-1", trxID, 100),
+			},
+			reversed:      []*domain.CommissionReversal{{ID: "r1", TransactionID: trxID, CommissionMutationID: "c1"}},
+			wantBalances:  map[string]float64{"buyer": 11000, "upline-1": 4900},
+			wantReversals: 2,
+			wantLocked:    []string{"buyer", "upline-1"},
+		},
+		{
+			name: "buyer cashback and commission",
+			uplines: map[string]*domain.User{
+				"upline-1": {ID: "upline-1", Balance: 5000},
+			},
+			commissions:   []*domain.Mutation{commissionMutation("c1", "upline-1", trxID, 300)},
+			cashback:      &domain.PromotionCashback{ID: "cb-1", PromotionID: "promo-1", UserID: "buyer", Amount: 250},
+			wantBalances:  map[string]float64{"buyer": 10750, "upline-1": 4700},
+			wantReversals: 1,
+			wantLocked:    []string{"buyer", "upline-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ledger := &fakeLedger{
+				users:     map[string]*domain.User{"buyer": {ID: "buyer", Balance: 10000}},
+				mutations: tt.commissions,
+				reversals: tt.reversed,
+				cashback:  tt.cashback,
+			}
+			for id, upline := range tt.uplines {
+				ledger.users[id] = upline
+			}
+
+			uc := &transactionUsecase{
+				holdRepo:   &fakeLedgerHolds{},
+				unitOfWork: &fakeLedgerUnitOfWork{ledger: ledger},
+			}
+			transaction := &domain.Transaction{
+				ID:           trxID,
+				TrxCode:      "TRX-20261016-0001",
+				UserID:       "buyer",
+				SellingPrice: 950,
+				AdminFee:     50,
+				Status:       domain.StatusSuccess,
+			}
+
+			if err := uc.refundTransaction(transaction, domain.SystemActor(domain.SystemActorRefund)); err != nil {
+				t.Fatalf("refundTransaction() unexpected error: %v", err)
+			}
+
+			for id, want := range tt.wantBalances {
+				if got := ledger.users[id].Balance; math.Abs(got-want) > 1e-9 {
+					t.Errorf("%s balance = %v, want %v", id, got, want)
+				}
+			}
+
+			if len(ledger.reversals) != tt.wantReversals {
+				t.Errorf("reversals = %d, want %d", len(ledger.reversals), tt.wantReversals)
+			}
+			shortfall := 0.0
+			for _, reversal := range ledger.reversals {
+				shortfall += reversal.Shortfall
+			}
+			if math.Abs(shortfall-tt.wantShortfall) > 1e-9 {
+				t.Errorf("shortfall = %v, want %v", shortfall, tt.wantShortfall)
+			}
+
+			if len(ledger.locked) != 1 {
+				t.Fatalf("LockUsers called %d times, want once", len(ledger.locked))
+			}
+			if fmt.Sprint(ledger.locked[0]) != fmt.Sprint(tt.wantLocked) {
+				t.Errorf("locked users = %v, want %v", ledger.locked[0], tt.wantLocked)
+			}
+		})
+	}
+}
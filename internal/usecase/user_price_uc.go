@@ -0,0 +1,348 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+type userPriceUsecase struct {
+	priceRepo   domain.UserProductPriceRepository
+	tierRepo    domain.ProductTierPriceRepository
+	userRepo    domain.UserRepository
+	productRepo domain.ProductRepository
+	config      UserPriceConfig
+
+	mu     sync.Mutex
+	prices map[string]cachedUserPrice
+}
+
+// userPriceCacheSweepSize is the cache size from which expired entries are
+// dropped before adding another one
+const userPriceCacheSweepSize = 10000
+
+// cachedUserPrice holds the price of an override or tier, or nil when none is
+// set for the product, so lookups without one are cached too
+type cachedUserPrice struct {
+	price     *float64
+	expiresAt time.Time
+}
+
+// UserPriceConfig defines how price overrides and tier prices are resolved
+type UserPriceConfig struct {
+	// CacheTTL bounds how long a replica keeps using a price changed elsewhere
+	CacheTTL time.Duration
+}
+
+// DefaultUserPriceConfig returns default price override configuration
+func DefaultUserPriceConfig() UserPriceConfig {
+	return UserPriceConfig{
+		CacheTTL: 30 * time.Second,
+	}
+}
+
+// NewUserPriceUsecase creates a new price override use case
+func NewUserPriceUsecase(
+	priceRepo domain.UserProductPriceRepository,
+	tierRepo domain.ProductTierPriceRepository,
+	userRepo domain.UserRepository,
+	productRepo domain.ProductRepository,
+	config UserPriceConfig,
+) domain.UserPriceUsecase {
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = DefaultUserPriceConfig().CacheTTL
+	}
+
+	return &userPriceUsecase{
+		priceRepo:   priceRepo,
+		tierRepo:    tierRepo,
+		userRepo:    userRepo,
+		productRepo: productRepo,
+		config:      config,
+		prices:      make(map[string]cachedUserPrice),
+	}
+}
+
+// SetPrice creates or replaces the price override of a user on a product. The
+// price may not be below the product base price, and an upline may not price
+// a downline below the upline's own price for the product.
+func (uc *userPriceUsecase) SetPrice(actorID string, actorLevel int, userID, productCode string, price float64) (*domain.UserProductPrice, error) {
+	if price <= 0 {
+		return nil, fmt.Errorf("price must be greater than zero")
+	}
+
+	if err := uc.checkManaged(actorID, actorLevel, userID); err != nil {
+		return nil, err
+	}
+
+	product, err := uc.productRepo.GetByCode(strings.TrimSpace(productCode))
+	if err != nil {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	if price < product.BasePrice {
+		return nil, fmt.Errorf("price is below the product base price")
+	}
+	if price < product.MinPrice || price > product.MaxTransactionAmount {
+		return nil, fmt.Errorf("price out of allowed range")
+	}
+
+	if actorLevel != domain.LevelAdmin {
+		actor, err := uc.userRepo.GetByID(actorID)
+		if err != nil {
+			return nil, fmt.Errorf("user not found")
+		}
+		own, err := uc.ResolvePrice(actor, product)
+		if err != nil {
+			return nil, err
+		}
+		if price < own.Price {
+			return nil, fmt.Errorf("price is below your own price")
+		}
+	}
+
+	override := &domain.UserProductPrice{
+		ID:          utils.GenerateUUID(),
+		UserID:      userID,
+		ProductID:   product.ID,
+		ProductCode: product.Code,
+		ProductName: product.Name,
+		Price:       price,
+		SetBy:       actorID,
+	}
+	if err := uc.priceRepo.Upsert(override); err != nil {
+		return nil, err
+	}
+	uc.forget(overrideKey(userID, product.ID))
+
+	logger.Info("User product price set",
+		logger.String("user_id", userID),
+		logger.String("product_code", product.Code),
+		logger.Float64("price", price),
+		logger.String("set_by", actorID),
+	)
+
+	return override, nil
+}
+
+// DeletePrice removes the price override of a user on a product, the user
+// falls back to the tier price of their level or their markup
+func (uc *userPriceUsecase) DeletePrice(actorID string, actorLevel int, userID, productCode string) error {
+	if err := uc.checkManaged(actorID, actorLevel, userID); err != nil {
+		return err
+	}
+
+	product, err := uc.productRepo.GetByCode(strings.TrimSpace(productCode))
+	if err != nil {
+		return fmt.Errorf("product not found")
+	}
+
+	if err := uc.priceRepo.Delete(userID, product.ID); err != nil {
+		return err
+	}
+	uc.forget(overrideKey(userID, product.ID))
+
+	logger.Info("User product price removed",
+		logger.String("user_id", userID),
+		logger.String("product_code", product.Code),
+		logger.String("removed_by", actorID),
+	)
+
+	return nil
+}
+
+// ListPrices lists the price overrides of a user
+func (uc *userPriceUsecase) ListPrices(actorID string, actorLevel int, userID string) ([]*domain.UserProductPrice, error) {
+	if err := uc.checkManaged(actorID, actorLevel, userID); err != nil {
+		return nil, err
+	}
+
+	return uc.priceRepo.ListByUser(userID)
+}
+
+// SetTierPrice creates or replaces the price of a product for every user of a
+// level. The price is bounded like an override.
+func (uc *userPriceUsecase) SetTierPrice(adminID, productCode string, level int, price float64) (*domain.ProductTierPrice, error) {
+	if price <= 0 {
+		return nil, fmt.Errorf("price must be greater than zero")
+	}
+	if level < domain.LevelReseller || level > domain.LevelAdmin {
+		return nil, fmt.Errorf("invalid level")
+	}
+
+	product, err := uc.productRepo.GetByCode(strings.TrimSpace(productCode))
+	if err != nil {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	if price < product.BasePrice {
+		return nil, fmt.Errorf("price is below the product base price")
+	}
+	if price < product.MinPrice || price > product.MaxTransactionAmount {
+		return nil, fmt.Errorf("price out of allowed range")
+	}
+
+	tier := &domain.ProductTierPrice{
+		ID:          utils.GenerateUUID(),
+		ProductID:   product.ID,
+		ProductCode: product.Code,
+		ProductName: product.Name,
+		Level:       level,
+		Price:       price,
+		SetBy:       adminID,
+	}
+	if err := uc.tierRepo.Upsert(tier); err != nil {
+		return nil, err
+	}
+	uc.forget(tierKey(level, product.ID))
+
+	logger.Info("Product tier price set",
+		logger.String("product_code", product.Code),
+		logger.Int("level", level),
+		logger.Float64("price", price),
+		logger.String("set_by", adminID),
+	)
+
+	return tier, nil
+}
+
+// DeleteTierPrice removes the price of a product for a level, its users fall
+// back to their markup
+func (uc *userPriceUsecase) DeleteTierPrice(adminID, productCode string, level int) error {
+	product, err := uc.productRepo.GetByCode(strings.TrimSpace(productCode))
+	if err != nil {
+		return fmt.Errorf("product not found")
+	}
+
+	if err := uc.tierRepo.Delete(product.ID, level); err != nil {
+		return err
+	}
+	uc.forget(tierKey(level, product.ID))
+
+	logger.Info("Product tier price removed",
+		logger.String("product_code", product.Code),
+		logger.Int("level", level),
+		logger.String("removed_by", adminID),
+	)
+
+	return nil
+}
+
+// ListTierPrices lists the tier prices of a product
+func (uc *userPriceUsecase) ListTierPrices(productCode string) ([]*domain.ProductTierPrice, error) {
+	product, err := uc.productRepo.GetByCode(strings.TrimSpace(productCode))
+	if err != nil {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	return uc.tierRepo.ListByProduct(product.ID)
+}
+
+// ResolvePrice applies the pricing precedence: an override set for the user
+// wins, then the tier price of the user's level, otherwise the user's markup
+// on the base price is used. The result tells which layer won.
+func (uc *userPriceUsecase) ResolvePrice(user *domain.User, product *domain.Product) (*domain.ResolvedPrice, error) {
+	override, err := uc.cachedPrice(overrideKey(user.ID, product.ID), func() (*float64, error) {
+		price, err := uc.priceRepo.Get(user.ID, product.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &price.Price, nil
+	}, "user product price not found")
+	if err != nil {
+		return nil, err
+	}
+	if override != nil {
+		return &domain.ResolvedPrice{Price: *override, Source: domain.PriceSourceOverride}, nil
+	}
+
+	tier, err := uc.cachedPrice(tierKey(user.Level, product.ID), func() (*float64, error) {
+		price, err := uc.tierRepo.Get(product.ID, user.Level)
+		if err != nil {
+			return nil, err
+		}
+		return &price.Price, nil
+	}, "product tier price not found")
+	if err != nil {
+		return nil, err
+	}
+	if tier != nil {
+		return &domain.ResolvedPrice{Price: *tier, Source: domain.PriceSourceTier}, nil
+	}
+
+	return &domain.ResolvedPrice{
+		Price:  user.GetEffectivePrice(product.BasePrice),
+		Source: domain.PriceSourceMarkup,
+	}, nil
+}
+
+// checkManaged verifies the actor may manage the prices of a user. Admins
+// manage every user, other users only their direct downlines.
+func (uc *userPriceUsecase) checkManaged(actorID string, actorLevel int, userID string) error {
+	user, err := uc.userRepo.GetByID(userID)
+	if err != nil {
+		return fmt.Errorf("user not found")
+	}
+
+	if actorLevel == domain.LevelAdmin {
+		return nil
+	}
+	if user.UplineID == nil || *user.UplineID != actorID {
+		return fmt.Errorf("prices can only be managed for your own downlines")
+	}
+
+	return nil
+}
+
+// cachedPrice returns the cached price under key, loading it on a miss. A
+// load failing with notFound caches that no price is set.
+func (uc *userPriceUsecase) cachedPrice(key string, load func() (*float64, error), notFound string) (*float64, error) {
+	now := time.Now()
+
+	uc.mu.Lock()
+	cached, ok := uc.prices[key]
+	uc.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.price, nil
+	}
+
+	price, err := load()
+	if err != nil {
+		if err.Error() != notFound {
+			return nil, err
+		}
+		price = nil
+	}
+
+	uc.mu.Lock()
+	if len(uc.prices) >= userPriceCacheSweepSize {
+		for k, entry := range uc.prices {
+			if !now.Before(entry.expiresAt) {
+				delete(uc.prices, k)
+			}
+		}
+	}
+	uc.prices[key] = cachedUserPrice{price: price, expiresAt: now.Add(uc.config.CacheTTL)}
+	uc.mu.Unlock()
+
+	return price, nil
+}
+
+func (uc *userPriceUsecase) forget(key string) {
+	uc.mu.Lock()
+	delete(uc.prices, key)
+	uc.mu.Unlock()
+}
+
+func overrideKey(userID, productID string) string {
+	return "user:" + userID + ":" + productID
+}
+
+func tierKey(level int, productID string) string {
+	return fmt.Sprintf("tier:%d:%s", level, productID)
+}
@@ -0,0 +1,290 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/secretbox"
+	"github.com/alfanzaky/eraflazz/pkg/utils"
+)
+
+type voucherUsecase struct {
+	voucherRepo domain.VoucherCodeRepository
+	productRepo domain.ProductRepository
+	unitOfWork  domain.UnitOfWork
+	box         *secretbox.Box // Nil when no code key is configured
+	config      VoucherConfig
+}
+
+// VoucherConfig defines voucher code stock limits and alerting
+type VoucherConfig struct {
+	// LowStockThreshold alerts when a product's available codes drop to it
+	LowStockThreshold int
+	MaxFileSize       int64 // Bytes
+	MaxCodes          int   // Per upload
+	MaxCodeLength     int
+}
+
+// DefaultVoucherConfig returns default voucher configuration
+func DefaultVoucherConfig() VoucherConfig {
+	return VoucherConfig{
+		LowStockThreshold: 10,
+		MaxFileSize:       5 << 20,
+		MaxCodes:          50000,
+		MaxCodeLength:     255,
+	}
+}
+
+// NewVoucherUsecase creates a new voucher stock use case. Without a box codes
+// can neither be uploaded nor allocated.
+func NewVoucherUsecase(
+	voucherRepo domain.VoucherCodeRepository,
+	productRepo domain.ProductRepository,
+	unitOfWork domain.UnitOfWork,
+	box *secretbox.Box,
+	config VoucherConfig,
+) domain.VoucherUsecase {
+	defaults := DefaultVoucherConfig()
+	if config.LowStockThreshold < 0 {
+		config.LowStockThreshold = defaults.LowStockThreshold
+	}
+	if config.MaxFileSize <= 0 {
+		config.MaxFileSize = defaults.MaxFileSize
+	}
+	if config.MaxCodes <= 0 {
+		config.MaxCodes = defaults.MaxCodes
+	}
+	if config.MaxCodeLength <= 0 {
+		config.MaxCodeLength = defaults.MaxCodeLength
+	}
+
+	return &voucherUsecase{
+		voucherRepo: voucherRepo,
+		productRepo: productRepo,
+		unitOfWork:  unitOfWork,
+		box:         box,
+		config:      config,
+	}
+}
+
+// UploadCodes encrypts and stores the codes of a file. Codes uploaded before
+// are skipped, so a file can be uploaded again safely.
+func (uc *voucherUsecase) UploadCodes(upload *domain.VoucherCodeUpload) (*domain.VoucherUploadResult, error) {
+	if uc.box == nil {
+		return nil, fmt.Errorf("voucher code key is not configured")
+	}
+
+	product, err := uc.productRepo.GetByID(upload.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if !product.IsStockedVoucher() {
+		return nil, fmt.Errorf("product is not a stocked voucher")
+	}
+	if upload.ExpiresAt != nil && !upload.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("expires_at must be in the future")
+	}
+
+	data, err := io.ReadAll(io.LimitReader(upload.File, uc.config.MaxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read voucher file: %w", err)
+	}
+	if int64(len(data)) > uc.config.MaxFileSize {
+		return nil, fmt.Errorf("voucher file too large")
+	}
+
+	codes, err := uc.parseCodes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.VoucherUploadResult{ProductID: product.ID, Lines: len(codes)}
+	err = uc.unitOfWork.Do(func(repos domain.TxRepositories) error {
+		for _, code := range codes {
+			sealed, err := uc.box.Seal(code)
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			inserted, err := repos.VoucherCodes().Create(&domain.VoucherCode{
+				ID:            utils.GenerateUUID(),
+				ProductID:     product.ID,
+				CodeEncrypted: sealed,
+				CodeHash:      uc.box.Hash(code),
+				Status:        domain.VoucherCodeAvailable,
+				ExpiresAt:     upload.ExpiresAt,
+				UploadedBy:    upload.UploadedBy,
+				CreatedAt:     now,
+				UpdatedAt:     now,
+			})
+			if err != nil {
+				return err
+			}
+			if inserted {
+				result.Inserted++
+			} else {
+				result.Duplicates++
+			}
+		}
+
+		available, err := repos.VoucherCodes().SyncProductStock(product.ID)
+		result.Available = available
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Voucher codes uploaded",
+		logger.String("product_code", product.Code),
+		logger.Int("lines", result.Lines),
+		logger.Int("inserted", result.Inserted),
+		logger.Int("duplicates", result.Duplicates),
+		logger.Int("available", result.Available),
+	)
+
+	return result, nil
+}
+
+// parseCodes reads one code per line. In CSV files the first column holds the
+// code; a leading "code" header is skipped.
+func (uc *voucherUsecase) parseCodes(data []byte) ([]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	codes := make([]string, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		code, _, _ := strings.Cut(scanner.Text(), ",")
+		code, _, _ = strings.Cut(code, ";")
+		code = strings.Trim(strings.TrimSpace(code), `"`)
+		if code == "" {
+			continue
+		}
+		if lineNumber == 1 && (strings.EqualFold(code, "code") || strings.EqualFold(code, "kode")) {
+			continue
+		}
+		if len(code) > uc.config.MaxCodeLength {
+			return nil, fmt.Errorf("line %d: code longer than %d characters", lineNumber, uc.config.MaxCodeLength)
+		}
+		if len(codes) >= uc.config.MaxCodes {
+			return nil, fmt.Errorf("voucher file has more than %d codes", uc.config.MaxCodes)
+		}
+		codes = append(codes, code)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("invalid voucher file: %v", err)
+	}
+	if len(codes) == 0 {
+		return nil, fmt.Errorf("voucher file has no codes")
+	}
+
+	return codes, nil
+}
+
+// GetStock counts the codes of a product
+func (uc *voucherUsecase) GetStock(productID string) (*domain.VoucherStock, error) {
+	stock, err := uc.voucherRepo.GetStock(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	stock.Threshold = uc.config.LowStockThreshold
+	stock.LowStock = stock.Available <= stock.Threshold
+	return stock, nil
+}
+
+// SetStockMode opts a voucher product in to or out of the code stock. Opting
+// in sets its stock quantity to the available codes; until codes are uploaded
+// the product is out of stock.
+func (uc *voucherUsecase) SetStockMode(productID string, enabled bool) (*domain.VoucherStock, error) {
+	product, err := uc.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.Category != domain.CategoryVoucher {
+		return nil, fmt.Errorf("product is not a voucher")
+	}
+	if enabled && uc.box == nil {
+		return nil, fmt.Errorf("voucher code key is not configured")
+	}
+
+	err = uc.unitOfWork.Do(func(repos domain.TxRepositories) error {
+		if err := repos.VoucherCodes().SetStockMode(product.ID, enabled); err != nil {
+			return err
+		}
+		if !enabled {
+			return nil
+		}
+		_, err := repos.VoucherCodes().SyncProductStock(product.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Voucher stock mode changed",
+		logger.String("product_code", product.Code),
+		logger.Bool("enabled", enabled),
+	)
+
+	return uc.GetStock(product.ID)
+}
+
+// SellsFromStock reports whether the product opted in to the code stock and
+// codes can be decrypted. Otherwise its orders go to its suppliers.
+func (uc *voucherUsecase) SellsFromStock(product *domain.Product) bool {
+	return uc.box != nil && product.IsStockedVoucher()
+}
+
+// AllocateCode takes a code of the product for a transaction inside its
+// completion and returns the code in clear. The stock alert goes out when the
+// available codes reach the threshold and again when they run out.
+func (uc *voucherUsecase) AllocateCode(repos domain.TxRepositories, transaction *domain.Transaction, product *domain.Product) (string, error) {
+	if uc.box == nil {
+		return "", fmt.Errorf("voucher code key is not configured")
+	}
+
+	code, err := repos.VoucherCodes().Allocate(product.ID, transaction.ID)
+	if err != nil {
+		return "", err
+	}
+	if code == nil {
+		return "", fmt.Errorf("voucher out of stock")
+	}
+
+	plaintext, err := uc.box.Open(code.CodeEncrypted)
+	if err != nil {
+		return "", err
+	}
+
+	available, err := repos.VoucherCodes().SyncProductStock(product.ID)
+	if err != nil {
+		return "", err
+	}
+	if available == uc.config.LowStockThreshold || available == 0 {
+		logger.Warn("Voucher stock low",
+			logger.String("product_code", product.Code),
+			logger.Int("available", available),
+			logger.Int("threshold", uc.config.LowStockThreshold),
+		)
+
+		event, err := domain.NewVoucherStockLowEvent(product, available, uc.config.LowStockThreshold)
+		if err != nil {
+			return "", err
+		}
+		if err := repos.Events().Create(event); err != nil {
+			return "", fmt.Errorf("failed to record voucher stock event: %w", err)
+		}
+	}
+
+	return plaintext, nil
+}
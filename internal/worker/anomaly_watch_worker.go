@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+// AnomalyWatchWorker periodically checks rolling transaction outcomes per
+// supplier and product and alerts failure spikes and all-pending suppliers.
+type AnomalyWatchWorker struct {
+	anomalyUC domain.AnomalyUsecase
+	interval  time.Duration
+}
+
+// AnomalyWatchWorkerConfig defines runtime options for the worker.
+type AnomalyWatchWorkerConfig struct {
+	Interval time.Duration
+}
+
+// NewAnomalyWatchWorker builds a new anomaly watch worker instance.
+func NewAnomalyWatchWorker(anomalyUC domain.AnomalyUsecase, cfg AnomalyWatchWorkerConfig) *AnomalyWatchWorker {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	return &AnomalyWatchWorker{
+		anomalyUC: anomalyUC,
+		interval:  interval,
+	}
+}
+
+// Start runs a check immediately and then on every interval.
+// It blocks until context cancellation.
+func (w *AnomalyWatchWorker) Start(ctx context.Context) {
+	logger.Component(logger.ComponentWorker).Info("Anomaly watch worker started", logger.Duration("interval", w.interval))
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	_ = w.watch()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Component(logger.ComponentWorker).Info("Anomaly watch worker stopping", logger.ErrorField(ctx.Err()))
+			return
+		case <-ticker.C:
+			_ = w.watch()
+		}
+	}
+}
+
+// Job runs the anomaly watch pass through the scheduler.
+func (w *AnomalyWatchWorker) Job() Job {
+	return IntervalJob("anomaly-watch", w.interval, w.watch)
+}
+
+func (w *AnomalyWatchWorker) watch() error {
+	if w.anomalyUC == nil {
+		logger.Component(logger.ComponentWorker).Warn("Anomaly watch worker missing dependencies")
+		return nil
+	}
+
+	start := time.Now()
+	anomalies, err := w.anomalyUC.DetectAnomalies()
+	if err != nil {
+		logger.Component(logger.ComponentWorker).Error("Failed to detect transaction anomalies",
+			logger.Duration("duration", time.Since(start)),
+			logger.ErrorField(err),
+		)
+		return err
+	}
+
+	logger.Component(logger.ComponentWorker).Debug("Anomaly watch pass finished",
+		logger.Int("anomalies", len(anomalies)),
+		logger.Duration("duration", time.Since(start)),
+	)
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+// BackpressureWorker samples the transaction queue backlog so order intake
+// can degrade or shed load without reading the queue on every request
+type BackpressureWorker struct {
+	backpressureUC domain.BackpressureUsecase
+	interval       time.Duration
+	stats          *workerStats
+}
+
+// BackpressureWorkerConfig defines runtime options for the worker.
+type BackpressureWorkerConfig struct {
+	Interval time.Duration
+}
+
+// NewBackpressureWorker builds a new backlog sampler instance.
+func NewBackpressureWorker(backpressureUC domain.BackpressureUsecase, cfg BackpressureWorkerConfig) *BackpressureWorker {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	return &BackpressureWorker{
+		backpressureUC: backpressureUC,
+		interval:       interval,
+		stats:          trackWorker("backpressure"),
+	}
+}
+
+// Start launches the sampling loop. It blocks until context cancellation.
+func (w *BackpressureWorker) Start(ctx context.Context) {
+	logger.Component(logger.ComponentWorker).Info("Backpressure worker started", logger.Duration("interval", w.interval))
+	defer w.stats.running()()
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.sample()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Component(logger.ComponentWorker).Info("Backpressure worker stopping", logger.ErrorField(ctx.Err()))
+			return
+		case <-ticker.C:
+			w.sample()
+		}
+	}
+}
+
+func (w *BackpressureWorker) sample() {
+	_, err := w.backpressureUC.Refresh()
+	w.stats.record(0, err)
+	if err != nil {
+		logger.Component(logger.ComponentWorker).Warn("Failed to sample queue backlog, keeping the previous intake mode", logger.ErrorField(err))
+	}
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+// BalanceReconciliationWorker compares every user's balance with the
+// mutation ledger and flags the mismatches.
+type BalanceReconciliationWorker struct {
+	reconciliationUC domain.ReconciliationUsecase
+	schedule         string
+}
+
+// BalanceReconciliationWorkerConfig defines runtime options for the worker.
+type BalanceReconciliationWorkerConfig struct {
+	// Schedule is a cron expression in server local time, see ParseSchedule.
+	Schedule string
+}
+
+// NewBalanceReconciliationWorker builds a new balance reconciliation worker instance.
+func NewBalanceReconciliationWorker(reconciliationUC domain.ReconciliationUsecase, cfg BalanceReconciliationWorkerConfig) *BalanceReconciliationWorker {
+	schedule := cfg.Schedule
+	if schedule == "" {
+		schedule = "30 2 * * *"
+	}
+
+	return &BalanceReconciliationWorker{
+		reconciliationUC: reconciliationUC,
+		schedule:         schedule,
+	}
+}
+
+// Job exposes the worker as a scheduler job. Mismatches are kept open per
+// user, so a repeated run refreshes them instead of opening new ones.
+func (w *BalanceReconciliationWorker) Job() Job {
+	return Job{
+		Name:     "balance-reconciliation",
+		Schedule: w.schedule,
+		Run: func(ctx context.Context) error {
+			return w.reconcile()
+		},
+	}
+}
+
+func (w *BalanceReconciliationWorker) reconcile() error {
+	if w.reconciliationUC == nil {
+		logger.Component(logger.ComponentWorker).Warn("Balance reconciliation worker missing dependencies")
+		return nil
+	}
+
+	start := time.Now()
+	if _, err := w.reconciliationUC.ReconcileBalances(); err != nil {
+		logger.Component(logger.ComponentWorker).Error("Failed to reconcile balances",
+			logger.Duration("duration", time.Since(start)),
+			logger.ErrorField(err),
+		)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+// DailySummaryWorker queues each user's summary of the previous day.
+type DailySummaryWorker struct {
+	notificationUC domain.NotificationUsecase
+	schedule       string
+}
+
+// DailySummaryWorkerConfig defines runtime options for the worker.
+type DailySummaryWorkerConfig struct {
+	// Schedule is a cron expression in server local time, see ParseSchedule.
+	Schedule string
+}
+
+// NewDailySummaryWorker builds a new daily summary worker instance.
+func NewDailySummaryWorker(notificationUC domain.NotificationUsecase, cfg DailySummaryWorkerConfig) *DailySummaryWorker {
+	schedule := cfg.Schedule
+	if schedule == "" {
+		schedule = "0 7 * * *"
+	}
+
+	return &DailySummaryWorker{
+		notificationUC: notificationUC,
+		schedule:       schedule,
+	}
+}
+
+// Job exposes the worker as a scheduler job. Summaries are keyed per user and
+// day, so a repeated run for the same day queues nothing new.
+func (w *DailySummaryWorker) Job() Job {
+	return Job{
+		Name:     "daily-summary",
+		Schedule: w.schedule,
+		Run: func(ctx context.Context) error {
+			return w.generate()
+		},
+	}
+}
+
+func (w *DailySummaryWorker) generate() error {
+	if w.notificationUC == nil {
+		logger.Component(logger.ComponentWorker).Warn("Daily summary worker missing dependencies")
+		return nil
+	}
+
+	start := time.Now()
+	if _, err := w.notificationUC.GenerateDailySummaries(start.AddDate(0, 0, -1)); err != nil {
+		logger.Component(logger.ComponentWorker).Error("Failed to generate daily summaries",
+			logger.Duration("duration", time.Since(start)),
+			logger.ErrorField(err),
+		)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+// EventRelayWorker periodically relays pending outbox events to publishers.
+type EventRelayWorker struct {
+	relayUC  domain.EventRelayUsecase
+	interval time.Duration
+	stats    *workerStats
+}
+
+// EventRelayWorkerConfig defines runtime options for the worker.
+type EventRelayWorkerConfig struct {
+	PollingInterval time.Duration
+}
+
+// NewEventRelayWorker builds a new outbox relay worker instance.
+func NewEventRelayWorker(relayUC domain.EventRelayUsecase, cfg EventRelayWorkerConfig) *EventRelayWorker {
+	interval := cfg.PollingInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	return &EventRelayWorker{
+		relayUC:  relayUC,
+		interval: interval,
+		stats:    trackWorker("event-relay"),
+	}
+}
+
+// Start launches the relay loop. It blocks until context cancellation.
+func (w *EventRelayWorker) Start(ctx context.Context) {
+	logger.Component(logger.ComponentWorker).Info("Event relay worker started", logger.Duration("interval", w.interval))
+	defer w.stats.running()()
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Component(logger.ComponentWorker).Info("Event relay worker stopping", logger.ErrorField(ctx.Err()))
+			return
+		case <-ticker.C:
+			w.relay(ctx)
+		}
+	}
+}
+
+func (w *EventRelayWorker) relay(ctx context.Context) {
+	if w.relayUC == nil {
+		logger.Component(logger.ComponentWorker).Warn("Event relay worker missing dependencies")
+		return
+	}
+
+	relayed, err := w.relayUC.RelayPendingEvents(ctx)
+	w.stats.record(relayed, err)
+	if err != nil {
+		logger.Component(logger.ComponentWorker).Error("Failed to relay outbox events", logger.ErrorField(err))
+	}
+}
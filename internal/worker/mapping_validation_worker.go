@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+// MappingValidationWorker periodically cross-references supplier catalogs with
+// product mappings and opens reviews for stale supplier product codes.
+type MappingValidationWorker struct {
+	validationUC domain.MappingValidationUsecase
+	interval     time.Duration
+}
+
+// MappingValidationWorkerConfig defines runtime options for the worker.
+type MappingValidationWorkerConfig struct {
+	Interval time.Duration
+}
+
+// NewMappingValidationWorker builds a new mapping validation worker instance.
+func NewMappingValidationWorker(validationUC domain.MappingValidationUsecase, cfg MappingValidationWorkerConfig) *MappingValidationWorker {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = 6 * time.Hour
+	}
+
+	return &MappingValidationWorker{
+		validationUC: validationUC,
+		interval:     interval,
+	}
+}
+
+// Start runs a validation pass immediately and then on every interval.
+// It blocks until context cancellation.
+func (w *MappingValidationWorker) Start(ctx context.Context) {
+	logger.Component(logger.ComponentWorker).Info("Mapping validation worker started", logger.Duration("interval", w.interval))
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	_ = w.validate()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Component(logger.ComponentWorker).Info("Mapping validation worker stopping", logger.ErrorField(ctx.Err()))
+			return
+		case <-ticker.C:
+			_ = w.validate()
+		}
+	}
+}
+
+// Job runs the mapping validation pass through the scheduler.
+func (w *MappingValidationWorker) Job() Job {
+	return IntervalJob("mapping-validation", w.interval, w.validate)
+}
+
+func (w *MappingValidationWorker) validate() error {
+	if w.validationUC == nil {
+		logger.Component(logger.ComponentWorker).Warn("Mapping validation worker missing dependencies")
+		return nil
+	}
+
+	start := time.Now()
+	results, err := w.validationUC.ValidateAllMappings()
+	if err != nil {
+		logger.Component(logger.ComponentWorker).Error("Failed to validate supplier mappings",
+			logger.Duration("duration", time.Since(start)),
+			logger.ErrorField(err),
+		)
+		return err
+	}
+
+	stale := 0
+	for _, result := range results {
+		stale += result.Stale
+	}
+
+	logger.Component(logger.ComponentWorker).Debug("Mapping validation pass finished",
+		logger.Int("suppliers", len(results)),
+		logger.Int("stale_mappings", stale),
+		logger.Duration("duration", time.Since(start)),
+	)
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+// MutationWebhookWorker periodically batches the mutations queued for user
+// mutation webhooks into deliveries and posts the due ones.
+type MutationWebhookWorker struct {
+	webhookUC domain.MutationWebhookUsecase
+	interval  time.Duration
+}
+
+// MutationWebhookWorkerConfig defines runtime options for the worker.
+type MutationWebhookWorkerConfig struct {
+	Interval time.Duration // Batch interval, one delivery per webhook per run
+}
+
+// NewMutationWebhookWorker builds a new mutation webhook worker instance.
+func NewMutationWebhookWorker(webhookUC domain.MutationWebhookUsecase, cfg MutationWebhookWorkerConfig) *MutationWebhookWorker {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	return &MutationWebhookWorker{
+		webhookUC: webhookUC,
+		interval:  interval,
+	}
+}
+
+// Job exposes the worker as a scheduler job running on the batch interval.
+func (w *MutationWebhookWorker) Job() Job {
+	return Job{
+		Name:     "mutation-webhooks",
+		Schedule: EverySchedule(w.interval),
+		Run: func(ctx context.Context) error {
+			return w.dispatch(ctx)
+		},
+	}
+}
+
+func (w *MutationWebhookWorker) dispatch(ctx context.Context) error {
+	if w.webhookUC == nil {
+		logger.Component(logger.ComponentWorker).Warn("Mutation webhook worker missing dependencies")
+		return nil
+	}
+
+	start := time.Now()
+	delivered, err := w.webhookUC.DispatchDeliveries(ctx)
+	if err != nil {
+		logger.Component(logger.ComponentWorker).Error("Failed to dispatch mutation webhooks",
+			logger.Duration("duration", time.Since(start)),
+			logger.ErrorField(err),
+		)
+		return err
+	}
+
+	logger.Component(logger.ComponentWorker).Debug("Mutation webhook pass finished",
+		logger.Int("delivered", delivered),
+		logger.Duration("duration", time.Since(start)),
+	)
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+// OutboxDispatchWorker periodically delivers queued outbox messages.
+type OutboxDispatchWorker struct {
+	dispatchUC domain.OutboxDispatchUsecase
+	interval   time.Duration
+	stats      *workerStats
+}
+
+// OutboxDispatchWorkerConfig defines runtime options for the worker.
+type OutboxDispatchWorkerConfig struct {
+	PollingInterval time.Duration
+}
+
+// NewOutboxDispatchWorker builds a new outbox dispatch worker instance.
+func NewOutboxDispatchWorker(dispatchUC domain.OutboxDispatchUsecase, cfg OutboxDispatchWorkerConfig) *OutboxDispatchWorker {
+	interval := cfg.PollingInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	return &OutboxDispatchWorker{
+		dispatchUC: dispatchUC,
+		interval:   interval,
+		stats:      trackWorker("outbox-dispatch"),
+	}
+}
+
+// Start launches the dispatch loop. It blocks until context cancellation.
+func (w *OutboxDispatchWorker) Start(ctx context.Context) {
+	logger.Component(logger.ComponentWorker).Info("Outbox dispatch worker started", logger.Duration("interval", w.interval))
+	defer w.stats.running()()
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Component(logger.ComponentWorker).Info("Outbox dispatch worker stopping", logger.ErrorField(ctx.Err()))
+			return
+		case <-ticker.C:
+			w.dispatch(ctx)
+		}
+	}
+}
+
+func (w *OutboxDispatchWorker) dispatch(ctx context.Context) {
+	if w.dispatchUC == nil {
+		logger.Component(logger.ComponentWorker).Warn("Outbox dispatch worker missing dependencies")
+		return
+	}
+
+	dispatched, err := w.dispatchUC.DispatchPending(ctx)
+	w.stats.record(dispatched, err)
+	if err != nil {
+		logger.Component(logger.ComponentWorker).Error("Failed to dispatch outbox messages", logger.ErrorField(err))
+	}
+}
@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+// PartitionMaintenanceWorker periodically creates upcoming monthly partitions
+// of transactions and mutations and detaches the ones past retention.
+type PartitionMaintenanceWorker struct {
+	maintenanceUC domain.PartitionMaintenanceUsecase
+	interval      time.Duration
+}
+
+// PartitionMaintenanceWorkerConfig defines runtime options for the worker.
+type PartitionMaintenanceWorkerConfig struct {
+	Interval time.Duration
+}
+
+// NewPartitionMaintenanceWorker builds a new partition maintenance worker instance.
+func NewPartitionMaintenanceWorker(maintenanceUC domain.PartitionMaintenanceUsecase, cfg PartitionMaintenanceWorkerConfig) *PartitionMaintenanceWorker {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = 6 * time.Hour
+	}
+
+	return &PartitionMaintenanceWorker{
+		maintenanceUC: maintenanceUC,
+		interval:      interval,
+	}
+}
+
+// Start runs a maintenance pass immediately and then on every interval.
+// It blocks until context cancellation.
+func (w *PartitionMaintenanceWorker) Start(ctx context.Context) {
+	logger.Component(logger.ComponentWorker).Info("Partition maintenance worker started", logger.Duration("interval", w.interval))
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	_ = w.maintain()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Component(logger.ComponentWorker).Info("Partition maintenance worker stopping", logger.ErrorField(ctx.Err()))
+			return
+		case <-ticker.C:
+			_ = w.maintain()
+		}
+	}
+}
+
+// Job runs the partition maintenance pass through the scheduler.
+func (w *PartitionMaintenanceWorker) Job() Job {
+	return IntervalJob("partition-maintenance", w.interval, w.maintain)
+}
+
+func (w *PartitionMaintenanceWorker) maintain() error {
+	if w.maintenanceUC == nil {
+		logger.Component(logger.ComponentWorker).Warn("Partition maintenance worker missing dependencies")
+		return nil
+	}
+
+	start := time.Now()
+	results, err := w.maintenanceUC.MaintainPartitions()
+	if err != nil {
+		logger.Component(logger.ComponentWorker).Error("Failed to maintain partitions",
+			logger.Duration("duration", time.Since(start)),
+			logger.ErrorField(err),
+		)
+		return err
+	}
+
+	created, detached := 0, 0
+	for _, result := range results {
+		created += len(result.Created)
+		detached += len(result.Detached)
+	}
+
+	logger.Component(logger.ComponentWorker).Debug("Partition maintenance pass finished",
+		logger.Int("created", created),
+		logger.Int("detached", detached),
+		logger.Duration("duration", time.Since(start)),
+	)
+
+	return nil
+}
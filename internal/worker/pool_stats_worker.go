@@ -0,0 +1,147 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/metrics"
+	"github.com/go-redis/redis/v8"
+)
+
+// DBStatser exposes database/sql connection pool statistics.
+type DBStatser interface {
+	Stats() sql.DBStats
+}
+
+// RedisPoolStatser exposes go-redis connection pool statistics.
+type RedisPoolStatser interface {
+	PoolStats() *redis.PoolStats
+}
+
+// PoolStatsWorker samples the database and Redis connection pools of this
+// instance, exports them as metrics and warns when a pool runs saturated.
+type PoolStatsWorker struct {
+	db        DBStatser
+	rdb       RedisPoolStatser
+	interval  time.Duration
+	threshold float64
+	redisSize int
+	stats     *workerStats
+
+	lastDBWaitCount   int64
+	lastRedisTimeouts uint32
+	sampled           bool // Cumulative counters have a baseline
+}
+
+// PoolStatsWorkerConfig defines runtime options for the worker.
+type PoolStatsWorkerConfig struct {
+	Interval time.Duration
+	// SaturationThreshold is the in-use share of a pool (0-1) that logs a warning.
+	SaturationThreshold float64
+	// RedisPoolSize is the Redis pool capacity; zero skips the in-use check,
+	// e.g. in cluster mode where the size applies per node.
+	RedisPoolSize int
+}
+
+// NewPoolStatsWorker builds a new connection pool collector instance.
+func NewPoolStatsWorker(db DBStatser, rdb RedisPoolStatser, cfg PoolStatsWorkerConfig) *PoolStatsWorker {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	threshold := cfg.SaturationThreshold
+	if threshold <= 0 || threshold > 1 {
+		threshold = 0.8
+	}
+
+	return &PoolStatsWorker{
+		db:        db,
+		rdb:       rdb,
+		interval:  interval,
+		threshold: threshold,
+		redisSize: cfg.RedisPoolSize,
+		stats:     trackWorker("pool-stats"),
+	}
+}
+
+// Start launches the sampling loop. It blocks until context cancellation.
+func (w *PoolStatsWorker) Start(ctx context.Context) {
+	logger.Component(logger.ComponentWorker).Info("Pool stats worker started", logger.Duration("interval", w.interval))
+	defer w.stats.running()()
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.collect()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Component(logger.ComponentWorker).Info("Pool stats worker stopping", logger.ErrorField(ctx.Err()))
+			return
+		case <-ticker.C:
+			w.collect()
+		}
+	}
+}
+
+func (w *PoolStatsWorker) collect() {
+	if w.db != nil {
+		w.collectDB(w.db.Stats())
+	}
+	if w.rdb != nil {
+		if stats := w.rdb.PoolStats(); stats != nil {
+			w.collectRedis(stats)
+		}
+	}
+	w.sampled = true
+	w.stats.record(0, nil)
+}
+
+func (w *PoolStatsWorker) collectDB(stats sql.DBStats) {
+	metrics.SetDBConnectionsActive(float64(stats.InUse))
+
+	// WaitCount is cumulative; only waits since the previous sample matter
+	waits := stats.WaitCount - w.lastDBWaitCount
+	w.lastDBWaitCount = stats.WaitCount
+	if !w.sampled {
+		waits = 0
+	}
+
+	saturated := stats.MaxOpenConnections > 0 &&
+		float64(stats.InUse) >= w.threshold*float64(stats.MaxOpenConnections)
+	if saturated || waits > 0 {
+		logger.Component(logger.ComponentWorker).Warn("Database connection pool saturated",
+			logger.Int("in_use", stats.InUse),
+			logger.Int("idle", stats.Idle),
+			logger.Int("max_open", stats.MaxOpenConnections),
+			logger.Int64("waits", waits),
+			logger.Duration("wait_duration_total", stats.WaitDuration),
+		)
+	}
+}
+
+func (w *PoolStatsWorker) collectRedis(stats *redis.PoolStats) {
+	active := int(stats.TotalConns) - int(stats.IdleConns)
+	if active < 0 {
+		active = 0
+	}
+	metrics.SetRedisConnectionsActive(float64(active))
+
+	// Timeouts counts callers that gave up waiting for a free connection
+	timeouts := stats.Timeouts - w.lastRedisTimeouts
+	w.lastRedisTimeouts = stats.Timeouts
+	if !w.sampled {
+		timeouts = 0
+	}
+
+	saturated := w.redisSize > 0 && float64(active) >= w.threshold*float64(w.redisSize)
+	if saturated || timeouts > 0 {
+		logger.Component(logger.ComponentWorker).Warn("Redis connection pool saturated",
+			logger.Int("active", active),
+			logger.Int("idle", int(stats.IdleConns)),
+			logger.Int("pool_size", w.redisSize),
+			logger.Int64("timeouts", int64(timeouts)),
+		)
+	}
+}
@@ -0,0 +1,76 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+// PriceDigestWorker queues the price change digests of the last window to
+// the users who opted in.
+type PriceDigestWorker struct {
+	notificationUC domain.NotificationUsecase
+	schedule       string
+	window         time.Duration
+}
+
+// PriceDigestWorkerConfig defines runtime options for the worker.
+type PriceDigestWorkerConfig struct {
+	// Schedule is a cron expression in server local time, see ParseSchedule.
+	Schedule string
+	// Window is the period covered by one digest. Windows are aligned to
+	// multiples of it since the Unix epoch (UTC), so the schedule should run
+	// once per window, shortly after it closes.
+	Window time.Duration
+}
+
+// NewPriceDigestWorker builds a new price digest worker instance.
+func NewPriceDigestWorker(notificationUC domain.NotificationUsecase, cfg PriceDigestWorkerConfig) *PriceDigestWorker {
+	schedule := cfg.Schedule
+	if schedule == "" {
+		schedule = "5 * * * *"
+	}
+	window := cfg.Window
+	if window <= 0 {
+		window = time.Hour
+	}
+
+	return &PriceDigestWorker{
+		notificationUC: notificationUC,
+		schedule:       schedule,
+		window:         window,
+	}
+}
+
+// Job exposes the worker as a scheduler job. Digests are keyed per user,
+// category and window, so a repeated run in the same window queues nothing new.
+func (w *PriceDigestWorker) Job() Job {
+	return Job{
+		Name:     "price-digest",
+		Schedule: w.schedule,
+		Run: func(ctx context.Context) error {
+			return w.generate()
+		},
+	}
+}
+
+func (w *PriceDigestWorker) generate() error {
+	if w.notificationUC == nil {
+		logger.Component(logger.ComponentWorker).Warn("Price digest worker missing dependencies")
+		return nil
+	}
+
+	start := time.Now()
+	until := start.Truncate(w.window)
+	if _, err := w.notificationUC.GeneratePriceChangeDigests(until.Add(-w.window), until); err != nil {
+		logger.Component(logger.ComponentWorker).Error("Failed to generate price change digests",
+			logger.Duration("duration", time.Since(start)),
+			logger.ErrorField(err),
+		)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+// PriceSyncWorker periodically pulls supplier price lists and runs the margin
+// guard on products whose supplier cost changed.
+type PriceSyncWorker struct {
+	pricingUC domain.PricingUsecase
+	interval  time.Duration
+}
+
+// PriceSyncWorkerConfig defines runtime options for the worker.
+type PriceSyncWorkerConfig struct {
+	Interval time.Duration
+}
+
+// NewPriceSyncWorker builds a new price sync worker instance.
+func NewPriceSyncWorker(pricingUC domain.PricingUsecase, cfg PriceSyncWorkerConfig) *PriceSyncWorker {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	return &PriceSyncWorker{
+		pricingUC: pricingUC,
+		interval:  interval,
+	}
+}
+
+// Start runs a sync pass immediately and then on every interval.
+// It blocks until context cancellation.
+func (w *PriceSyncWorker) Start(ctx context.Context) {
+	logger.Component(logger.ComponentWorker).Info("Price sync worker started", logger.Duration("interval", w.interval))
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	_ = w.sync()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Component(logger.ComponentWorker).Info("Price sync worker stopping", logger.ErrorField(ctx.Err()))
+			return
+		case <-ticker.C:
+			_ = w.sync()
+		}
+	}
+}
+
+// Job runs the price sync pass through the scheduler.
+func (w *PriceSyncWorker) Job() Job {
+	return IntervalJob("price-sync", w.interval, w.sync)
+}
+
+func (w *PriceSyncWorker) sync() error {
+	if w.pricingUC == nil {
+		logger.Component(logger.ComponentWorker).Warn("Price sync worker missing dependencies")
+		return nil
+	}
+
+	start := time.Now()
+	results, err := w.pricingUC.SyncAllSupplierPrices()
+	if err != nil {
+		logger.Component(logger.ComponentWorker).Error("Failed to sync supplier prices",
+			logger.Duration("duration", time.Since(start)),
+			logger.ErrorField(err),
+		)
+		return err
+	}
+
+	logger.Component(logger.ComponentWorker).Debug("Price sync pass finished",
+		logger.Int("suppliers", len(results)),
+		logger.Duration("duration", time.Since(start)),
+	)
+
+	return nil
+}
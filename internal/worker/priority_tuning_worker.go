@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+// PriorityTuningWorker periodically recomputes effective priority of product
+// mappings from rolling supplier performance.
+type PriorityTuningWorker struct {
+	tuningUC domain.PriorityTuningUsecase
+	interval time.Duration
+}
+
+// PriorityTuningWorkerConfig defines runtime options for the worker.
+type PriorityTuningWorkerConfig struct {
+	Interval time.Duration
+}
+
+// NewPriorityTuningWorker builds a new priority tuning worker instance.
+func NewPriorityTuningWorker(tuningUC domain.PriorityTuningUsecase, cfg PriorityTuningWorkerConfig) *PriorityTuningWorker {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	return &PriorityTuningWorker{
+		tuningUC: tuningUC,
+		interval: interval,
+	}
+}
+
+// Start runs a tuning pass immediately and then on every interval.
+// It blocks until context cancellation.
+func (w *PriorityTuningWorker) Start(ctx context.Context) {
+	logger.Component(logger.ComponentWorker).Info("Priority tuning worker started", logger.Duration("interval", w.interval))
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	_ = w.tune()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Component(logger.ComponentWorker).Info("Priority tuning worker stopping", logger.ErrorField(ctx.Err()))
+			return
+		case <-ticker.C:
+			_ = w.tune()
+		}
+	}
+}
+
+// Job runs the priority tuning pass through the scheduler.
+func (w *PriorityTuningWorker) Job() Job {
+	return IntervalJob("priority-tuning", w.interval, w.tune)
+}
+
+func (w *PriorityTuningWorker) tune() error {
+	if w.tuningUC == nil {
+		logger.Component(logger.ComponentWorker).Warn("Priority tuning worker missing dependencies")
+		return nil
+	}
+
+	start := time.Now()
+	updated, err := w.tuningUC.TuneMappingPriorities()
+	if err != nil {
+		logger.Component(logger.ComponentWorker).Error("Failed to tune mapping priorities",
+			logger.Duration("duration", time.Since(start)),
+			logger.ErrorField(err),
+		)
+		return err
+	}
+
+	logger.Component(logger.ComponentWorker).Debug("Priority tuning pass finished",
+		logger.Int("updated_mappings", updated),
+		logger.Duration("duration", time.Since(start)),
+	)
+
+	return nil
+}
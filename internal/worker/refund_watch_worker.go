@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+// RefundWatchWorker periodically looks for failed transactions whose balance
+// was neither released nor refunded in time and alerts them as stuck.
+type RefundWatchWorker struct {
+	refundUC domain.RefundSLAUsecase
+	interval time.Duration
+}
+
+// RefundWatchWorkerConfig defines runtime options for the worker.
+type RefundWatchWorkerConfig struct {
+	Interval time.Duration
+}
+
+// NewRefundWatchWorker builds a new refund watch worker instance.
+func NewRefundWatchWorker(refundUC domain.RefundSLAUsecase, cfg RefundWatchWorkerConfig) *RefundWatchWorker {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	return &RefundWatchWorker{
+		refundUC: refundUC,
+		interval: interval,
+	}
+}
+
+// Job runs the refund watch pass through the scheduler.
+func (w *RefundWatchWorker) Job() Job {
+	return IntervalJob("refund-watch", w.interval, w.watch)
+}
+
+func (w *RefundWatchWorker) watch() error {
+	if w.refundUC == nil {
+		logger.Component(logger.ComponentWorker).Warn("Refund watch worker missing dependencies")
+		return nil
+	}
+
+	start := time.Now()
+	stuck, err := w.refundUC.CheckStuckRefunds()
+	if err != nil {
+		logger.Component(logger.ComponentWorker).Error("Failed to check stuck refunds",
+			logger.Duration("duration", time.Since(start)),
+			logger.ErrorField(err),
+		)
+		return err
+	}
+
+	logger.Component(logger.ComponentWorker).Debug("Refund watch pass finished",
+		logger.Int("stuck", len(stuck)),
+		logger.Duration("duration", time.Since(start)),
+	)
+
+	return nil
+}
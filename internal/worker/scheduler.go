@@ -0,0 +1,307 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/metrics"
+)
+
+// Job is a periodic task run by the Scheduler.
+type Job struct {
+	Name string
+	// Schedule is a cron expression or descriptor, see ParseSchedule.
+	Schedule string
+	// Timeout bounds a single run; it also keeps the activation lock alive.
+	Timeout time.Duration
+	// RunOnStart runs the job once when the scheduler starts.
+	RunOnStart bool
+	Run        func(ctx context.Context) error
+}
+
+// IntervalJob builds a job that runs every interval and once when the
+// scheduler starts.
+func IntervalJob(name string, interval time.Duration, run func() error) Job {
+	return Job{
+		Name:       name,
+		Schedule:   EverySchedule(interval),
+		RunOnStart: true,
+		Run: func(ctx context.Context) error {
+			return run()
+		},
+	}
+}
+
+// SchedulerConfig defines runtime options for the scheduler.
+type SchedulerConfig struct {
+	// InstanceID identifies this replica in locks and run status.
+	InstanceID string
+	// DefaultTimeout applies to jobs without a timeout.
+	DefaultTimeout time.Duration
+}
+
+type scheduledJob struct {
+	job      Job
+	schedule Schedule
+	stats    *workerStats
+
+	mu        sync.Mutex
+	nextRunAt time.Time
+	running   bool
+	lastRun   *domain.JobRun
+}
+
+// Scheduler runs registered jobs on their cron schedules. Every activation is
+// claimed through a distributed lock so only one replica runs it.
+type Scheduler struct {
+	repo       domain.SchedulerRepository
+	instanceID string
+	timeout    time.Duration
+
+	mu      sync.RWMutex
+	jobs    map[string]*scheduledJob
+	started bool
+}
+
+var _ domain.JobScheduler = (*Scheduler)(nil)
+
+// NewScheduler builds a new scheduler instance. Without a repository every
+// replica runs every activation.
+func NewScheduler(repo domain.SchedulerRepository, cfg SchedulerConfig) *Scheduler {
+	instanceID := cfg.InstanceID
+	if instanceID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			hostname = "unknown"
+		}
+		instanceID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	timeout := cfg.DefaultTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Minute
+	}
+
+	return &Scheduler{
+		repo:       repo,
+		instanceID: instanceID,
+		timeout:    timeout,
+		jobs:       make(map[string]*scheduledJob),
+	}
+}
+
+// Register adds a job. Jobs must be registered before Start.
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return fmt.Errorf("job name and run function are required")
+	}
+
+	schedule, err := ParseSchedule(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("invalid schedule for job %s: %w", job.Name, err)
+	}
+	if job.Timeout <= 0 {
+		job.Timeout = s.timeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return fmt.Errorf("scheduler already started")
+	}
+	if _, exists := s.jobs[job.Name]; exists {
+		return fmt.Errorf("job %s already registered", job.Name)
+	}
+
+	s.jobs[job.Name] = &scheduledJob{job: job, schedule: schedule, stats: trackWorker(job.Name)}
+	return nil
+}
+
+// Start runs every registered job on its schedule.
+// It blocks until context cancellation and waits for running jobs to finish.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	s.started = true
+	jobs := make([]*scheduledJob, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	s.mu.Unlock()
+
+	logger.Component(logger.ComponentWorker).Info("Scheduler started",
+		logger.String("instance", s.instanceID),
+		logger.Int("jobs", len(jobs)),
+	)
+
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job *scheduledJob) {
+			defer wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+
+	wg.Wait()
+	logger.Component(logger.ComponentWorker).Info("Scheduler stopped", logger.ErrorField(ctx.Err()))
+}
+
+// ListJobs returns registered jobs with their cluster-wide last run.
+func (s *Scheduler) ListJobs() ([]*domain.JobStatus, error) {
+	s.mu.RLock()
+	jobs := make([]*scheduledJob, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	s.mu.RUnlock()
+
+	statuses := make([]*domain.JobStatus, 0, len(jobs))
+	for _, job := range jobs {
+		job.mu.Lock()
+		status := &domain.JobStatus{
+			Name:      job.job.Name,
+			Schedule:  job.job.Schedule,
+			NextRunAt: job.nextRunAt,
+			Running:   job.running,
+			LastRun:   job.lastRun,
+		}
+		job.mu.Unlock()
+
+		if s.repo != nil {
+			run, err := s.repo.GetJobRun(job.job.Name)
+			if err != nil {
+				return nil, err
+			}
+			if run != nil {
+				status.LastRun = run
+			}
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses, nil
+}
+
+func (s *Scheduler) loop(ctx context.Context, job *scheduledJob) {
+	if job.job.RunOnStart {
+		// Replicas starting within the same minute share the activation
+		s.activate(ctx, job, "start:"+time.Now().Truncate(time.Minute).Format(time.RFC3339))
+	}
+
+	for {
+		next := job.schedule.Next(time.Now())
+		if next.IsZero() {
+			logger.Component(logger.ComponentWorker).Warn("Scheduled job has no future activation", logger.String("job", job.job.Name))
+			return
+		}
+
+		job.mu.Lock()
+		job.nextRunAt = next
+		job.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.activate(ctx, job, next.UTC().Format(time.RFC3339))
+		}
+	}
+}
+
+// activate claims and runs one activation of a job
+func (s *Scheduler) activate(ctx context.Context, job *scheduledJob, runKey string) {
+	name := job.job.Name
+
+	if s.repo != nil {
+		acquired, err := s.repo.AcquireJobLock(name, runKey, s.instanceID, job.job.Timeout)
+		if err != nil {
+			logger.Component(logger.ComponentWorker).Error("Failed to acquire scheduled job lock",
+				logger.String("job", name),
+				logger.ErrorField(err),
+			)
+			return
+		}
+		if !acquired {
+			metrics.RecordScheduledJob(name, domain.JobRunSkipped, 0)
+			logger.Component(logger.ComponentWorker).Debug("Scheduled job claimed by another replica", logger.String("job", name))
+			return
+		}
+	}
+
+	defer job.stats.running()()
+
+	job.mu.Lock()
+	job.running = true
+	job.mu.Unlock()
+
+	runCtx, cancel := context.WithTimeout(ctx, job.job.Timeout)
+	defer cancel()
+
+	run := &domain.JobRun{
+		JobName:   name,
+		Instance:  s.instanceID,
+		Status:    domain.JobRunSuccess,
+		StartedAt: time.Now(),
+	}
+	err := s.runJob(runCtx, job.job)
+	run.FinishedAt = time.Now()
+	run.DurationMs = run.FinishedAt.Sub(run.StartedAt).Milliseconds()
+
+	if err != nil {
+		msg := err.Error()
+		run.Status = domain.JobRunFailed
+		run.Error = &msg
+		logger.Component(logger.ComponentWorker).Error("Scheduled job failed",
+			logger.String("job", name),
+			logger.Duration("duration", run.FinishedAt.Sub(run.StartedAt)),
+			logger.ErrorField(err),
+		)
+	} else {
+		logger.Component(logger.ComponentWorker).Debug("Scheduled job finished",
+			logger.String("job", name),
+			logger.Duration("duration", run.FinishedAt.Sub(run.StartedAt)),
+		)
+	}
+	metrics.RecordScheduledJob(name, run.Status, run.FinishedAt.Sub(run.StartedAt).Seconds())
+	job.stats.record(0, err)
+
+	job.mu.Lock()
+	job.running = false
+	job.lastRun = run
+	job.mu.Unlock()
+
+	if s.repo != nil {
+		if err := s.repo.SaveJobRun(run); err != nil {
+			logger.Component(logger.ComponentWorker).Warn("Failed to save scheduled job run",
+				logger.String("job", name),
+				logger.ErrorField(err),
+			)
+		}
+	}
+}
+
+// runJob runs the job and turns a panic into an error so one job cannot take
+// down the scheduler
+func (s *Scheduler) runJob(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return job.Run(ctx)
+}
@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+// StatementWorker periodically generates queued account statements.
+type StatementWorker struct {
+	statementUC domain.StatementUsecase
+	interval    time.Duration
+	stats       *workerStats
+}
+
+// StatementWorkerConfig defines runtime options for the worker.
+type StatementWorkerConfig struct {
+	PollingInterval time.Duration
+}
+
+// NewStatementWorker builds a new statement worker instance.
+func NewStatementWorker(statementUC domain.StatementUsecase, cfg StatementWorkerConfig) *StatementWorker {
+	interval := cfg.PollingInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	return &StatementWorker{
+		statementUC: statementUC,
+		interval:    interval,
+		stats:       trackWorker("statement"),
+	}
+}
+
+// Start launches the generation loop. It blocks until context cancellation.
+func (w *StatementWorker) Start(ctx context.Context) {
+	logger.Component(logger.ComponentWorker).Info("Statement worker started", logger.Duration("interval", w.interval))
+	defer w.stats.running()()
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Component(logger.ComponentWorker).Info("Statement worker stopping", logger.ErrorField(ctx.Err()))
+			return
+		case <-ticker.C:
+			w.process(ctx)
+		}
+	}
+}
+
+func (w *StatementWorker) process(ctx context.Context) {
+	if w.statementUC == nil {
+		logger.Component(logger.ComponentWorker).Warn("Statement worker missing dependencies")
+		return
+	}
+
+	processed, err := w.statementUC.ProcessPendingJobs(ctx)
+	w.stats.record(processed, err)
+	if err != nil {
+		logger.Component(logger.ComponentWorker).Error("Failed to process statement jobs", logger.ErrorField(err))
+	}
+}
@@ -0,0 +1,142 @@
+package worker
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+)
+
+// startedAt approximates the process start; the package loads with main.
+var startedAt = time.Now()
+
+// workerStats counts the activity of the workers of one type. Counters are
+// kept in memory, so they are per replica and reset on restart.
+type workerStats struct {
+	name string
+
+	mu          sync.Mutex
+	goroutines  int
+	processed   int64
+	failed      int64
+	lastRunAt   *time.Time
+	lastError   *string
+	lastErrorAt *time.Time
+	inFlight    map[string]struct{}
+}
+
+var (
+	statsMu sync.Mutex
+	stats   = make(map[string]*workerStats)
+)
+
+// trackWorker returns the shared stats of a worker type.
+func trackWorker(name string) *workerStats {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	s, exists := stats[name]
+	if !exists {
+		s = &workerStats{name: name, inFlight: make(map[string]struct{})}
+		stats[name] = s
+	}
+	return s
+}
+
+// running counts a worker goroutine until the returned function is called.
+func (s *workerStats) running() func() {
+	s.mu.Lock()
+	s.goroutines++
+	s.mu.Unlock()
+
+	return func() {
+		s.mu.Lock()
+		s.goroutines--
+		s.mu.Unlock()
+	}
+}
+
+// record counts the outcome of one pass that handled processed items.
+func (s *workerStats) record(processed int, err error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastRunAt = &now
+	s.processed += int64(processed)
+	if err != nil {
+		message := err.Error()
+		s.failed++
+		s.lastError = &message
+		s.lastErrorAt = &now
+	}
+}
+
+// begin marks a transaction in flight until the returned function is called.
+func (s *workerStats) begin(trxID string) func() {
+	s.mu.Lock()
+	s.inFlight[trxID] = struct{}{}
+	s.mu.Unlock()
+
+	return func() {
+		s.mu.Lock()
+		delete(s.inFlight, trxID)
+		s.mu.Unlock()
+	}
+}
+
+func (s *workerStats) snapshot() *domain.WorkerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inFlight := make([]string, 0, len(s.inFlight))
+	for trxID := range s.inFlight {
+		inFlight = append(inFlight, trxID)
+	}
+	sort.Strings(inFlight)
+
+	return &domain.WorkerStatus{
+		Name:        s.name,
+		Goroutines:  s.goroutines,
+		Processed:   s.processed,
+		Failed:      s.failed,
+		LastRunAt:   s.lastRunAt,
+		LastError:   s.lastError,
+		LastErrorAt: s.lastErrorAt,
+		InFlight:    inFlight,
+	}
+}
+
+// Monitor reports the counters of the workers running in this process.
+type Monitor struct{}
+
+var _ domain.WorkerMonitor = (*Monitor)(nil)
+
+// NewMonitor builds a new worker monitor instance.
+func NewMonitor() *Monitor {
+	return &Monitor{}
+}
+
+// StartedAt returns when the process started.
+func (m *Monitor) StartedAt() time.Time {
+	return startedAt
+}
+
+// Workers returns the status of every worker type started so far, by name.
+func (m *Monitor) Workers() []*domain.WorkerStatus {
+	statsMu.Lock()
+	all := make([]*workerStats, 0, len(stats))
+	for _, s := range stats {
+		all = append(all, s)
+	}
+	statsMu.Unlock()
+
+	workers := make([]*domain.WorkerStatus, 0, len(all))
+	for _, s := range all {
+		workers = append(workers, s.snapshot())
+	}
+	sort.Slice(workers, func(i, j int) bool { return workers[i].Name < workers[j].Name })
+	return workers
+}
@@ -0,0 +1,84 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+// SupplierProbeWorker periodically measures supplier API latency with a
+// lightweight ping so SLA tracking and supplier reachability do not depend
+// on traffic.
+type SupplierProbeWorker struct {
+	probeUC  domain.SupplierProbeUsecase
+	interval time.Duration
+}
+
+// SupplierProbeWorkerConfig defines runtime options for the worker.
+type SupplierProbeWorkerConfig struct {
+	Interval time.Duration
+}
+
+// NewSupplierProbeWorker builds a new supplier probe worker instance.
+func NewSupplierProbeWorker(probeUC domain.SupplierProbeUsecase, cfg SupplierProbeWorkerConfig) *SupplierProbeWorker {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	return &SupplierProbeWorker{
+		probeUC:  probeUC,
+		interval: interval,
+	}
+}
+
+// Start runs a probe immediately and then on every interval.
+// It blocks until context cancellation.
+func (w *SupplierProbeWorker) Start(ctx context.Context) {
+	logger.Component(logger.ComponentWorker).Info("Supplier probe worker started", logger.Duration("interval", w.interval))
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	_ = w.probe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Component(logger.ComponentWorker).Info("Supplier probe worker stopping", logger.ErrorField(ctx.Err()))
+			return
+		case <-ticker.C:
+			_ = w.probe()
+		}
+	}
+}
+
+// Job runs the supplier probe pass through the scheduler.
+func (w *SupplierProbeWorker) Job() Job {
+	return IntervalJob("supplier-probe", w.interval, w.probe)
+}
+
+func (w *SupplierProbeWorker) probe() error {
+	if w.probeUC == nil {
+		logger.Component(logger.ComponentWorker).Warn("Supplier probe worker missing dependencies")
+		return nil
+	}
+
+	start := time.Now()
+	probed, err := w.probeUC.ProbeSuppliers()
+	if err != nil {
+		logger.Component(logger.ComponentWorker).Error("Failed to probe suppliers",
+			logger.Duration("duration", time.Since(start)),
+			logger.ErrorField(err),
+		)
+		return err
+	}
+
+	logger.Component(logger.ComponentWorker).Debug("Supplier probe pass finished",
+		logger.Int("probed", probed),
+		logger.Duration("duration", time.Since(start)),
+	)
+
+	return nil
+}
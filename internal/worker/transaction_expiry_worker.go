@@ -0,0 +1,106 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+// TransactionExpiryWorker periodically cancels pending transactions that
+// passed their auto-cancel deadline and releases their balance holds. It also
+// polls suppliers for processing transactions past their processing SLA and
+// releases transactions held by a cutoff that ended.
+type TransactionExpiryWorker struct {
+	transactionUC domain.TransactionUsecase
+	interval      time.Duration
+}
+
+// TransactionExpiryWorkerConfig defines runtime options for the worker.
+type TransactionExpiryWorkerConfig struct {
+	Interval time.Duration
+}
+
+// NewTransactionExpiryWorker builds a new transaction expiry worker instance.
+func NewTransactionExpiryWorker(transactionUC domain.TransactionUsecase, cfg TransactionExpiryWorkerConfig) *TransactionExpiryWorker {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	return &TransactionExpiryWorker{
+		transactionUC: transactionUC,
+		interval:      interval,
+	}
+}
+
+// Start runs an expiry pass immediately and then on every interval.
+// It blocks until context cancellation.
+func (w *TransactionExpiryWorker) Start(ctx context.Context) {
+	logger.Component(logger.ComponentWorker).Info("Transaction expiry worker started", logger.Duration("interval", w.interval))
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	_ = w.expire()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Component(logger.ComponentWorker).Info("Transaction expiry worker stopping", logger.ErrorField(ctx.Err()))
+			return
+		case <-ticker.C:
+			_ = w.expire()
+		}
+	}
+}
+
+// Job runs the expiry pass through the scheduler.
+func (w *TransactionExpiryWorker) Job() Job {
+	return IntervalJob("transaction-expiry", w.interval, w.expire)
+}
+
+func (w *TransactionExpiryWorker) expire() error {
+	if w.transactionUC == nil {
+		logger.Component(logger.ComponentWorker).Warn("Transaction expiry worker missing dependencies")
+		return nil
+	}
+
+	start := time.Now()
+	expired, err := w.transactionUC.ExpireTransactions()
+	if err != nil {
+		logger.Component(logger.ComponentWorker).Error("Failed to expire pending transactions",
+			logger.Duration("duration", time.Since(start)),
+			logger.ErrorField(err),
+		)
+		return err
+	}
+
+	polled, err := w.transactionUC.PollProcessingTransactions()
+	if err != nil {
+		logger.Component(logger.ComponentWorker).Error("Failed to poll processing transactions",
+			logger.Duration("duration", time.Since(start)),
+			logger.ErrorField(err),
+		)
+		return err
+	}
+
+	released, err := w.transactionUC.ReleaseScheduledTransactions()
+	if err != nil {
+		logger.Component(logger.ComponentWorker).Error("Failed to release scheduled transactions",
+			logger.Duration("duration", time.Since(start)),
+			logger.ErrorField(err),
+		)
+		return err
+	}
+
+	logger.Component(logger.ComponentWorker).Debug("Transaction expiry pass finished",
+		logger.Int("expired", expired),
+		logger.Int("released", released),
+		logger.Int("status_checked", polled.Checked),
+		logger.Int("timed_out", polled.TimedOut),
+		logger.Duration("duration", time.Since(start)),
+	)
+
+	return nil
+}
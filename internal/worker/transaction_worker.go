@@ -15,6 +15,7 @@ type TransactionWorker struct {
     queueRepo domain.QueueRepository
     trxUC     domain.TransactionUsecase
     interval  time.Duration
+    stats     *workerStats
 }
 
 // TransactionWorkerConfig defines runtime options for the worker.
@@ -33,19 +34,21 @@ func NewTransactionWorker(queueRepo domain.QueueRepository, trxUC domain.Transac
         queueRepo: queueRepo,
         trxUC:     trxUC,
         interval:  interval,
+        stats:     trackWorker("transaction"),
     }
 }
 
 // Start launches the worker loop. It blocks until context cancellation.
 func (w *TransactionWorker) Start(ctx context.Context) {
-    logger.Info("Transaction worker started")
+    logger.Component(logger.ComponentWorker).Info("Transaction worker started")
+    defer w.stats.running()()
     ticker := time.NewTicker(w.interval)
     defer ticker.Stop()
 
     for {
         select {
         case <-ctx.Done():
-            logger.Info("Transaction worker stopping", logger.ErrorField(ctx.Err()))
+            logger.Component(logger.ComponentWorker).Info("Transaction worker stopping", logger.ErrorField(ctx.Err()))
             return
         case <-ticker.C:
             w.processNext(ctx)
@@ -55,13 +58,14 @@ func (w *TransactionWorker) Start(ctx context.Context) {
 
 func (w *TransactionWorker) processNext(ctx context.Context) {
     if w.queueRepo == nil || w.trxUC == nil {
-        logger.Warn("Transaction worker missing dependencies")
+        logger.Component(logger.ComponentWorker).Warn("Transaction worker missing dependencies")
         return
     }
 
     trxID, err := w.queueRepo.DequeueTransaction()
     if err != nil {
-        logger.Error("Failed to dequeue transaction", logger.ErrorField(err))
+        logger.Component(logger.ComponentWorker).Error("Failed to dequeue transaction", logger.ErrorField(err))
+        w.stats.record(0, err)
         return
     }
 
@@ -70,12 +74,15 @@ func (w *TransactionWorker) processNext(ctx context.Context) {
         return
     }
 
+    done := w.stats.begin(trxID)
     start := time.Now()
-    err = w.trxUC.ProcessTransaction(trxID)
+    err = w.trxUC.ProcessTransaction(ctx, trxID)
     duration := time.Since(start)
+    done()
 
     if err != nil {
-        logger.Error("Failed to process queued transaction",
+        w.stats.record(0, err)
+        logger.Component(logger.ComponentWorker).Error("Failed to process queued transaction",
             logger.String("trx_id", trxID),
             logger.Duration("duration", duration),
             logger.ErrorField(err),
@@ -83,7 +90,8 @@ func (w *TransactionWorker) processNext(ctx context.Context) {
         return
     }
 
-    logger.Info("Queued transaction processed",
+    w.stats.record(1, nil)
+    logger.Component(logger.ComponentWorker).Debug("Queued transaction processed",
         logger.String("trx_id", trxID),
         logger.Duration("duration", duration),
     )
@@ -0,0 +1,25 @@
+-- Load test fixtures: a reseller with enough balance, the XLD10 product and a
+-- Digiflazz supplier served by the sandbox replay fixtures in
+-- testdata/suppliers/digiflazz. Safe to run repeatedly; the balance is reset.
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+INSERT INTO users (id, username, email, password_hash, full_name, level, is_active, is_verified, balance)
+VALUES ('00000000-0000-4000-8000-00000000a001', 'loadtest', 'loadtest@eraflazz.local',
+        crypt('LoadTest123', gen_salt('bf')), 'Load Test', 1, true, true, 1000000000)
+ON CONFLICT (id) DO UPDATE SET balance = EXCLUDED.balance, held_balance = 0, is_active = true;
+
+INSERT INTO suppliers (id, name, code, api_url, api_username, api_key, is_active, priority, balance)
+VALUES ('00000000-0000-4000-8000-00000000a002', 'Digiflazz (sandbox)', 'DIGIFLAZZ',
+        'https://api.digiflazz.com/v1', 'loadtest', 'loadtest', true, 1, 1000000000)
+ON CONFLICT (code) DO UPDATE SET is_active = true;
+
+INSERT INTO products (id, code, name, category, provider, type, base_price, selling_price, min_price, nominal, is_active, is_unlimited_stock)
+VALUES ('00000000-0000-4000-8000-00000000a003', 'XLD10', 'XL Data 10K', 'PULSA', 'XL', 'PREPAID',
+        10150, 10500, 0, 10000, true, true)
+ON CONFLICT (code) DO UPDATE SET is_active = true;
+
+INSERT INTO product_mappings (product_id, supplier_id, supplier_product_code, supplier_price, priority, is_active, stock_status)
+SELECT p.id, s.id, 'xld10', 10150, 1, true, 'AVAILABLE'
+FROM products p, suppliers s
+WHERE p.code = 'XLD10' AND s.code = 'DIGIFLAZZ'
+ON CONFLICT (product_id, supplier_id) DO UPDATE SET is_active = true, supplier_product_code = 'xld10';
@@ -0,0 +1,5 @@
+-- Drop auto-tuned priority columns from product_mappings
+DROP INDEX IF EXISTS idx_transactions_product_supplier_created_at;
+ALTER TABLE product_mappings
+    DROP COLUMN IF EXISTS priority_tuned_at,
+    DROP COLUMN IF EXISTS effective_priority;
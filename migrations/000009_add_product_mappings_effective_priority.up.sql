@@ -0,0 +1,7 @@
+-- Add auto-tuned priority columns to product_mappings
+ALTER TABLE product_mappings
+    ADD COLUMN effective_priority DECIMAL(10, 4), -- Priority computed from rolling performance (NULL = not tuned yet)
+    ADD COLUMN priority_tuned_at TIMESTAMP WITH TIME ZONE;
+
+-- Index used by the tuning job to aggregate per mapping performance
+CREATE INDEX idx_transactions_product_supplier_created_at ON transactions(product_id, supplier_id, created_at);
@@ -0,0 +1,3 @@
+-- Drop domain_events table and related objects
+DROP TRIGGER IF EXISTS update_domain_events_updated_at ON domain_events;
+DROP TABLE IF EXISTS domain_events;
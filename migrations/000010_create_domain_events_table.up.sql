@@ -0,0 +1,34 @@
+-- Create domain_events table (transactional outbox)
+CREATE TABLE domain_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_type VARCHAR(50) NOT NULL, -- transaction.created, transaction.completed, balance.mutated
+    aggregate_type VARCHAR(30) NOT NULL, -- TRANSACTION, USER
+    aggregate_id UUID NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+
+    -- Relay state
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING' CHECK (
+        status IN ('PENDING', 'PUBLISHED', 'FAILED')
+    ),
+    attempts INTEGER DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(), -- Also used as claim lease by the relay
+    published_at TIMESTAMP WITH TIME ZONE,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Indexes
+CREATE INDEX idx_domain_events_event_type ON domain_events(event_type);
+CREATE INDEX idx_domain_events_aggregate ON domain_events(aggregate_type, aggregate_id);
+CREATE INDEX idx_domain_events_created_at ON domain_events(created_at);
+
+-- Partial index for the relay worker
+CREATE INDEX idx_domain_events_pending ON domain_events(next_attempt_at) WHERE status = 'PENDING';
+
+-- Trigger for updated_at
+CREATE TRIGGER update_domain_events_updated_at 
+    BEFORE UPDATE ON domain_events 
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,3 @@
+-- Drop schema version from domain_events
+ALTER TABLE domain_events
+    DROP COLUMN IF EXISTS schema_version;
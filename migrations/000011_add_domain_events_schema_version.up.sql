@@ -0,0 +1,3 @@
+-- Add schema version to domain_events so consumers can evolve payload parsing
+ALTER TABLE domain_events
+    ADD COLUMN schema_version INTEGER NOT NULL DEFAULT 1;
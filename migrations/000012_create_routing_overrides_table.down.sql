@@ -0,0 +1,3 @@
+-- Drop routing_overrides table and related objects
+DROP TRIGGER IF EXISTS update_routing_overrides_updated_at ON routing_overrides;
+DROP TABLE IF EXISTS routing_overrides;
@@ -0,0 +1,28 @@
+-- Create routing_overrides table (supplier pinning and exclusion)
+CREATE TABLE routing_overrides (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    scope_type VARCHAR(20) NOT NULL CHECK (scope_type IN ('PRODUCT', 'CATEGORY')),
+    scope_value VARCHAR(50) NOT NULL, -- Product ID or category code
+    supplier_id UUID NOT NULL REFERENCES suppliers(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('PIN', 'EXCLUDE')),
+    reason TEXT, -- e.g. contract reference
+    is_active BOOLEAN DEFAULT true,
+    created_by UUID REFERENCES users(id),
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    -- One rule per supplier per scope
+    UNIQUE(scope_type, scope_value, supplier_id)
+);
+
+-- Indexes
+CREATE INDEX idx_routing_overrides_scope ON routing_overrides(scope_type, scope_value);
+CREATE INDEX idx_routing_overrides_supplier_id ON routing_overrides(supplier_id);
+CREATE INDEX idx_routing_overrides_is_active ON routing_overrides(is_active);
+
+-- Trigger for updated_at
+CREATE TRIGGER update_routing_overrides_updated_at 
+    BEFORE UPDATE ON routing_overrides 
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
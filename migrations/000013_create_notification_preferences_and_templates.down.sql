@@ -0,0 +1,10 @@
+-- Drop notification_preferences and message_templates tables and related objects
+DROP TRIGGER IF EXISTS update_message_templates_updated_at ON message_templates;
+DROP TRIGGER IF EXISTS update_notification_preferences_updated_at ON notification_preferences;
+DROP TABLE IF EXISTS message_templates;
+DROP TABLE IF EXISTS notification_preferences;
+
+DROP INDEX IF EXISTS idx_outbox_source_event_id;
+ALTER TABLE outbox DROP COLUMN IF EXISTS source_event_id;
+ALTER TABLE outbox DROP COLUMN IF EXISTS subject;
+ALTER TABLE outbox ALTER COLUMN recipient_number TYPE VARCHAR(50);
@@ -0,0 +1,65 @@
+-- Create notification_preferences table (per user, per event, per channel opt-in/out)
+CREATE TABLE notification_preferences (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL, -- transaction.success, transaction.failed, balance.mutated
+    channel VARCHAR(20) NOT NULL CHECK (channel IN ('WHATSAPP', 'EMAIL')),
+    is_enabled BOOLEAN NOT NULL DEFAULT true,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    UNIQUE(user_id, event_type, channel)
+);
+
+-- Create message_templates table (admin editable notification templates)
+CREATE TABLE message_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_type VARCHAR(50) NOT NULL,
+    channel VARCHAR(20) NOT NULL CHECK (channel IN ('WHATSAPP', 'EMAIL')),
+    subject VARCHAR(255), -- Email only
+    body TEXT NOT NULL, -- Supports {{placeholder}} variables
+    is_active BOOLEAN DEFAULT true,
+    updated_by UUID REFERENCES users(id),
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    UNIQUE(event_type, channel)
+);
+
+-- Outbox needs room for email recipients and subjects, and a link to the
+-- domain event it was generated from so relay retries do not duplicate messages
+ALTER TABLE outbox ALTER COLUMN recipient_number TYPE VARCHAR(255);
+ALTER TABLE outbox ADD COLUMN subject VARCHAR(255);
+ALTER TABLE outbox ADD COLUMN source_event_id UUID;
+
+-- Indexes
+CREATE INDEX idx_notification_preferences_user_id ON notification_preferences(user_id);
+CREATE UNIQUE INDEX idx_outbox_source_event_id ON outbox(source_event_id, destination) WHERE source_event_id IS NOT NULL;
+
+-- Triggers for updated_at
+CREATE TRIGGER update_notification_preferences_updated_at 
+    BEFORE UPDATE ON notification_preferences 
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_message_templates_updated_at 
+    BEFORE UPDATE ON message_templates 
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Default templates
+INSERT INTO message_templates (event_type, channel, subject, body) VALUES
+    ('transaction.success', 'WHATSAPP', NULL,
+     'Transaksi BERHASIL! {{product_code}} -> {{destination}}. SN: {{sn}}. Harga: {{price}}. Ref: {{trx_code}}'),
+    ('transaction.failed', 'WHATSAPP', NULL,
+     'Transaksi GAGAL! {{product_code}} -> {{destination}}. {{message}}. Ref: {{trx_code}}'),
+    ('balance.mutated', 'WHATSAPP', NULL,
+     'Mutasi saldo {{type}} {{amount}}. Saldo: {{balance}}. {{description}}'),
+    ('transaction.success', 'EMAIL', 'Transaksi {{trx_code}} berhasil',
+     E'Halo {{name}},\n\nTransaksi {{product_code}} ke {{destination}} berhasil pada {{date}}.\nSN: {{sn}}\nHarga: {{price}}\nRef: {{trx_code}}'),
+    ('transaction.failed', 'EMAIL', 'Transaksi {{trx_code}} gagal',
+     E'Halo {{name}},\n\nTransaksi {{product_code}} ke {{destination}} gagal pada {{date}}.\nKeterangan: {{message}}\nRef: {{trx_code}}'),
+    ('balance.mutated', 'EMAIL', 'Mutasi saldo {{type}}',
+     E'Halo {{name}},\n\nTerjadi mutasi saldo {{type}} sebesar {{amount}} pada {{date}}.\nSaldo awal: {{balance_before}}\nSaldo akhir: {{balance}}\nKeterangan: {{description}}');
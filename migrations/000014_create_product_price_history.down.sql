@@ -0,0 +1,6 @@
+-- Drop product_price_history table and margin guard columns
+DROP INDEX IF EXISTS idx_products_margin_flagged;
+ALTER TABLE products DROP COLUMN IF EXISTS margin_flag_reason;
+ALTER TABLE products DROP COLUMN IF EXISTS margin_flagged_at;
+ALTER TABLE products DROP COLUMN IF EXISTS margin_flagged;
+DROP TABLE IF EXISTS product_price_history;
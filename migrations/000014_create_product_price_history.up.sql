@@ -0,0 +1,24 @@
+-- Create product_price_history table (audit of selling, base and supplier price changes)
+CREATE TABLE product_price_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    product_mapping_id UUID REFERENCES product_mappings(id) ON DELETE SET NULL,
+    supplier_id UUID REFERENCES suppliers(id) ON DELETE SET NULL,
+    price_type VARCHAR(20) NOT NULL CHECK (price_type IN ('SELLING', 'BASE', 'SUPPLIER')),
+    old_price DECIMAL(19, 4) NOT NULL,
+    new_price DECIMAL(19, 4) NOT NULL,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('ADMIN', 'SYNC')),
+    changed_by UUID REFERENCES users(id), -- NULL for supplier sync
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Margin guard flag on products
+ALTER TABLE products ADD COLUMN margin_flagged BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE products ADD COLUMN margin_flagged_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE products ADD COLUMN margin_flag_reason TEXT;
+
+-- Indexes
+CREATE INDEX idx_product_price_history_product_id ON product_price_history(product_id, created_at DESC);
+CREATE INDEX idx_products_margin_flagged ON products(margin_flagged) WHERE margin_flagged = true;
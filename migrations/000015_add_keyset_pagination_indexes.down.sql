@@ -0,0 +1,3 @@
+-- Drop keyset pagination indexes
+DROP INDEX IF EXISTS idx_mutations_user_created_id;
+DROP INDEX IF EXISTS idx_transactions_user_created_id;
@@ -0,0 +1,3 @@
+-- Add keyset pagination indexes for transaction and mutation listings
+CREATE INDEX IF NOT EXISTS idx_transactions_user_created_id ON transactions(user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_mutations_user_created_id ON mutations(user_id, created_at DESC, id DESC);
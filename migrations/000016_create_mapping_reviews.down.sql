@@ -0,0 +1,3 @@
+-- Drop mapping_reviews table and related objects
+DROP TRIGGER IF EXISTS update_mapping_reviews_updated_at ON mapping_reviews;
+DROP TABLE IF EXISTS mapping_reviews;
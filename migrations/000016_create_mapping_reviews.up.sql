@@ -0,0 +1,27 @@
+-- Create mapping_reviews table (stale supplier product codes and replacement suggestions)
+CREATE TABLE mapping_reviews (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_mapping_id UUID NOT NULL REFERENCES product_mappings(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    supplier_id UUID NOT NULL REFERENCES suppliers(id) ON DELETE CASCADE,
+    stale_code VARCHAR(100) NOT NULL, -- Supplier product code missing from the catalog
+    suggestions JSONB NOT NULL DEFAULT '[]', -- Candidate replacement codes, best first
+    status VARCHAR(20) NOT NULL DEFAULT 'OPEN' CHECK (status IN ('OPEN', 'ACCEPTED', 'DISMISSED', 'RESOLVED')),
+    resolved_code VARCHAR(100),
+    reviewed_by UUID REFERENCES users(id),
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+
+    -- Timestamps
+    detected_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Indexes
+CREATE UNIQUE INDEX idx_mapping_reviews_open_mapping ON mapping_reviews(product_mapping_id) WHERE status = 'OPEN';
+CREATE INDEX idx_mapping_reviews_status ON mapping_reviews(status);
+CREATE INDEX idx_mapping_reviews_supplier_id ON mapping_reviews(supplier_id);
+
+-- Trigger for updated_at
+CREATE TRIGGER update_mapping_reviews_updated_at 
+    BEFORE UPDATE ON mapping_reviews 
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,4 @@
+-- Drop balance_holds table and held_balance column
+DROP TRIGGER IF EXISTS update_balance_holds_updated_at ON balance_holds;
+DROP TABLE IF EXISTS balance_holds;
+ALTER TABLE users DROP COLUMN IF EXISTS held_balance;
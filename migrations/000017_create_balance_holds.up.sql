@@ -0,0 +1,27 @@
+-- Create balance_holds table (balance reserved for in-flight transactions)
+CREATE TABLE balance_holds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    amount DECIMAL(19, 4) NOT NULL CHECK (amount > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'HELD' CHECK (status IN ('HELD', 'CAPTURED', 'RELEASED')),
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    settled_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Sum of active holds, kept in sync with balance_holds
+ALTER TABLE users ADD COLUMN held_balance DECIMAL(19, 4) NOT NULL DEFAULT 0;
+
+-- Indexes
+CREATE UNIQUE INDEX idx_balance_holds_active_transaction ON balance_holds(transaction_id) WHERE status = 'HELD';
+CREATE INDEX idx_balance_holds_transaction_id ON balance_holds(transaction_id);
+CREATE INDEX idx_balance_holds_user_id ON balance_holds(user_id);
+CREATE INDEX idx_balance_holds_active ON balance_holds(user_id) WHERE status = 'HELD';
+
+-- Trigger for updated_at
+CREATE TRIGGER update_balance_holds_updated_at 
+    BEFORE UPDATE ON balance_holds 
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,22 @@
+-- Create security_events table (role-guard denials and other security audit records)
+CREATE TABLE security_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_type VARCHAR(50) NOT NULL,
+    reason VARCHAR(50) NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    user_role VARCHAR(20),
+    ip_address VARCHAR(45) NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    resource VARCHAR(255) NOT NULL,
+    requirement VARCHAR(50),
+    user_agent TEXT,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Indexes
+CREATE INDEX idx_security_events_created_at ON security_events(created_at DESC);
+CREATE INDEX idx_security_events_user_id ON security_events(user_id, created_at DESC);
+CREATE INDEX idx_security_events_ip_address ON security_events(ip_address, created_at DESC);
+CREATE INDEX idx_security_events_event_type ON security_events(event_type);
@@ -0,0 +1,4 @@
+-- Drop transaction_events table
+DROP TRIGGER IF EXISTS prevent_transaction_events_change ON transaction_events;
+DROP FUNCTION IF EXISTS prevent_transaction_events_change();
+DROP TABLE IF EXISTS transaction_events;
@@ -0,0 +1,31 @@
+-- Create transaction_events table (append-only transaction timeline)
+CREATE TABLE transaction_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    sequence BIGSERIAL NOT NULL, -- Stable ordering for events written in the same instant
+    transaction_id UUID NOT NULL REFERENCES transactions(id),
+    event_type VARCHAR(30) NOT NULL,
+    status VARCHAR(20) NOT NULL, -- Transaction status after the event
+    supplier_id UUID REFERENCES suppliers(id),
+    attempt INTEGER,
+    message TEXT,
+    details JSONB,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Indexes
+CREATE INDEX idx_transaction_events_transaction_id ON transaction_events(transaction_id, sequence);
+CREATE INDEX idx_transaction_events_event_type ON transaction_events(event_type);
+
+-- Timeline entries are append-only
+CREATE OR REPLACE FUNCTION prevent_transaction_events_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'transaction_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER prevent_transaction_events_change
+    BEFORE UPDATE OR DELETE ON transaction_events
+    FOR EACH ROW EXECUTE FUNCTION prevent_transaction_events_change();
@@ -0,0 +1,6 @@
+-- Drop owning account and synchronous failover settings from api_clients
+DROP INDEX IF EXISTS idx_api_clients_user_id;
+ALTER TABLE api_clients
+    DROP COLUMN IF EXISTS sync_failover_budget_ms,
+    DROP COLUMN IF EXISTS sync_failover_attempts,
+    DROP COLUMN IF EXISTS user_id;
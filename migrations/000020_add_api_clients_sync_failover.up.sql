@@ -0,0 +1,7 @@
+-- Add owning account and synchronous failover settings to api_clients
+ALTER TABLE api_clients
+    ADD COLUMN user_id UUID REFERENCES users(id), -- Account charged for the client's H2H transactions
+    ADD COLUMN sync_failover_attempts INTEGER NOT NULL DEFAULT 0, -- Alternative suppliers tried inline (0 = async retry only)
+    ADD COLUMN sync_failover_budget_ms INTEGER NOT NULL DEFAULT 0; -- Total time budget for inline failover
+
+CREATE INDEX idx_api_clients_user_id ON api_clients(user_id);
@@ -0,0 +1,16 @@
+-- Drop fee_rules table and restore the previous profit formula
+UPDATE message_templates
+SET body = 'Transaksi BERHASIL! {{product_code}} -> {{destination}}. SN: {{sn}}. Harga: {{price}}. Ref: {{trx_code}}'
+WHERE event_type = 'transaction.success' AND channel = 'WHATSAPP'
+AND body = 'Transaksi BERHASIL! {{product_code}} -> {{destination}}. SN: {{sn}}. Harga: {{price}}. Biaya admin: {{admin_fee}}. Total: {{total}}. Ref: {{trx_code}}';
+
+UPDATE message_templates
+SET body = E'Halo {{name}},\n\nTransaksi {{product_code}} ke {{destination}} berhasil pada {{date}}.\nSN: {{sn}}\nHarga: {{price}}\nRef: {{trx_code}}'
+WHERE event_type = 'transaction.success' AND channel = 'EMAIL'
+AND body = E'Halo {{name}},\n\nTransaksi {{product_code}} ke {{destination}} berhasil pada {{date}}.\nSN: {{sn}}\nHarga: {{price}}\nBiaya admin: {{admin_fee}}\nTotal: {{total}}\nRef: {{trx_code}}';
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS profit;
+ALTER TABLE transactions
+    ADD COLUMN profit DECIMAL(19, 4) GENERATED ALWAYS AS (selling_price - hpp - admin_fee) STORED;
+
+DROP TABLE IF EXISTS fee_rules;
@@ -0,0 +1,44 @@
+-- Create fee_rules table (admin fee charged on top of the selling price)
+CREATE TABLE fee_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    category VARCHAR(20), -- Product category (NULL = all categories)
+    user_level INTEGER CHECK (user_level BETWEEN 1 AND 4), -- User level (NULL = all levels)
+    fee_type VARCHAR(20) NOT NULL CHECK (fee_type IN ('FLAT', 'PERCENTAGE')),
+    amount DECIMAL(19, 4) NOT NULL CHECK (amount >= 0), -- Rupiah for FLAT, percent of selling price for PERCENTAGE
+    min_fee DECIMAL(19, 4) CHECK (min_fee >= 0),
+    max_fee DECIMAL(19, 4) CHECK (max_fee >= 0),
+    priority INTEGER NOT NULL DEFAULT 0, -- Tie-breaker between equally specific rules (higher wins)
+    is_active BOOLEAN DEFAULT true,
+    created_by UUID REFERENCES users(id),
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    CHECK (min_fee IS NULL OR max_fee IS NULL OR min_fee <= max_fee)
+);
+
+-- Indexes
+CREATE INDEX idx_fee_rules_active ON fee_rules(category, user_level) WHERE is_active = true;
+
+-- Trigger for updated_at
+CREATE TRIGGER update_fee_rules_updated_at
+    BEFORE UPDATE ON fee_rules
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Admin fee is paid by the buyer on top of the selling price, so it adds to profit
+ALTER TABLE transactions DROP COLUMN profit;
+ALTER TABLE transactions
+    ADD COLUMN profit DECIMAL(19, 4) GENERATED ALWAYS AS (selling_price + admin_fee - hpp) STORED;
+
+-- Show the admin fee on receipts (only templates still using the default text)
+UPDATE message_templates
+SET body = 'Transaksi BERHASIL! {{product_code}} -> {{destination}}. SN: {{sn}}. Harga: {{price}}. Biaya admin: {{admin_fee}}. Total: {{total}}. Ref: {{trx_code}}'
+WHERE event_type = 'transaction.success' AND channel = 'WHATSAPP'
+AND body = 'Transaksi BERHASIL! {{product_code}} -> {{destination}}. SN: {{sn}}. Harga: {{price}}. Ref: {{trx_code}}';
+
+UPDATE message_templates
+SET body = E'Halo {{name}},\n\nTransaksi {{product_code}} ke {{destination}} berhasil pada {{date}}.\nSN: {{sn}}\nHarga: {{price}}\nBiaya admin: {{admin_fee}}\nTotal: {{total}}\nRef: {{trx_code}}'
+WHERE event_type = 'transaction.success' AND channel = 'EMAIL'
+AND body = E'Halo {{name}},\n\nTransaksi {{product_code}} ke {{destination}} berhasil pada {{date}}.\nSN: {{sn}}\nHarga: {{price}}\nRef: {{trx_code}}';
@@ -0,0 +1,3 @@
+-- Drop statement_jobs table
+DROP TRIGGER IF EXISTS update_statement_jobs_updated_at ON statement_jobs;
+DROP TABLE IF EXISTS statement_jobs;
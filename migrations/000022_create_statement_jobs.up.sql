@@ -0,0 +1,32 @@
+-- Create statement_jobs table (asynchronous monthly account statements)
+CREATE TABLE statement_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    period_year INTEGER NOT NULL CHECK (period_year >= 2000),
+    period_month INTEGER NOT NULL CHECK (period_month BETWEEN 1 AND 12),
+    format VARCHAR(10) NOT NULL CHECK (format IN ('PDF', 'CSV')),
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'PROCESSING', 'READY', 'FAILED')),
+    row_count INTEGER NOT NULL DEFAULT 0, -- Mutations + transactions in the statement
+    attempts INTEGER NOT NULL DEFAULT 0,
+    file_name VARCHAR(255),
+    content BYTEA, -- Generated file, stored in the database so any replica can serve it
+    error_message TEXT,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Indexes
+CREATE INDEX idx_statement_jobs_user_period ON statement_jobs(user_id, period_year, period_month, format, created_at DESC);
+CREATE INDEX idx_statement_jobs_queue ON statement_jobs(status, updated_at) WHERE status IN ('PENDING', 'PROCESSING');
+
+-- Only one job per statement may be queued or running at a time
+CREATE UNIQUE INDEX idx_statement_jobs_active ON statement_jobs(user_id, period_year, period_month, format)
+    WHERE status IN ('PENDING', 'PROCESSING');
+
+-- Trigger for updated_at
+CREATE TRIGGER update_statement_jobs_updated_at
+    BEFORE UPDATE ON statement_jobs
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
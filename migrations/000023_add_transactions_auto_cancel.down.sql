@@ -0,0 +1,5 @@
+-- Drop channel and auto-cancel expiry columns from transactions
+DROP INDEX IF EXISTS idx_transactions_pending_expires_at;
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS expires_at,
+    DROP COLUMN IF EXISTS channel;
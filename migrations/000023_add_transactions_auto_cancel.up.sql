@@ -0,0 +1,10 @@
+-- Add channel and auto-cancel expiry columns to transactions
+ALTER TABLE transactions
+    ADD COLUMN channel VARCHAR(20) NOT NULL DEFAULT 'API' CHECK (
+        channel IN ('API', 'H2H', 'WHATSAPP', 'TELEGRAM', 'SMS')
+    ), -- Where the order was placed
+    ADD COLUMN expires_at TIMESTAMP WITH TIME ZONE; -- Pending transactions are cancelled after this (NULL = never)
+
+-- Index used by the expiry job to find pending transactions past their expiry
+CREATE INDEX idx_transactions_pending_expires_at ON transactions(expires_at)
+    WHERE status = 'PENDING' AND expires_at IS NOT NULL;
@@ -0,0 +1,4 @@
+-- Drop adapter type and signing method from suppliers
+ALTER TABLE suppliers
+    DROP COLUMN IF EXISTS sign_method,
+    DROP COLUMN IF EXISTS adapter_type;
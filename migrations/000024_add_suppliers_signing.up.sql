@@ -0,0 +1,5 @@
+-- Add adapter type and signing method to suppliers so several supplier
+-- accounts can share one adapter implementation
+ALTER TABLE suppliers
+    ADD COLUMN adapter_type VARCHAR(20), -- Adapter implementation, e.g. DIGIFLAZZ (NULL = same as code)
+    ADD COLUMN sign_method VARCHAR(20); -- MD5, HMAC_SHA256 or JWT (NULL = adapter default)
@@ -0,0 +1,7 @@
+-- Drop full-text and trigram search from products
+DROP INDEX IF EXISTS idx_products_code_trgm;
+DROP INDEX IF EXISTS idx_products_name_trgm;
+DROP INDEX IF EXISTS idx_products_search_vector;
+DROP TRIGGER IF EXISTS update_products_search_vector ON products;
+DROP FUNCTION IF EXISTS products_search_vector_update();
+ALTER TABLE products DROP COLUMN IF EXISTS search_vector;
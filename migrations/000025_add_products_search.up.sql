@@ -0,0 +1,35 @@
+-- Add full-text and trigram search to products
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE products ADD COLUMN search_vector tsvector;
+
+-- Code and name weigh most; 'simple' keeps brand names and codes unstemmed
+CREATE OR REPLACE FUNCTION products_search_vector_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', coalesce(NEW.code, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(NEW.name, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(NEW.provider, '')), 'B') ||
+        setweight(to_tsvector('simple', coalesce(NEW.category, '')), 'C') ||
+        setweight(to_tsvector('simple', coalesce(NEW.description, '')), 'D');
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER update_products_search_vector
+    BEFORE INSERT OR UPDATE OF code, name, provider, category, description ON products
+    FOR EACH ROW EXECUTE FUNCTION products_search_vector_update();
+
+-- Backfill existing products
+UPDATE products SET search_vector =
+    setweight(to_tsvector('simple', coalesce(code, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(provider, '')), 'B') ||
+    setweight(to_tsvector('simple', coalesce(category, '')), 'C') ||
+    setweight(to_tsvector('simple', coalesce(description, '')), 'D');
+
+-- Indexes
+CREATE INDEX idx_products_search_vector ON products USING GIN(search_vector);
+CREATE INDEX idx_products_name_trgm ON products USING GIN(lower(name) gin_trgm_ops);
+CREATE INDEX idx_products_code_trgm ON products USING GIN(lower(code) gin_trgm_ops);
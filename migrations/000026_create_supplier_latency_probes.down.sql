@@ -0,0 +1,2 @@
+-- Drop supplier_latency_probes table
+DROP TABLE IF EXISTS supplier_latency_probes;
@@ -0,0 +1,16 @@
+-- Create supplier_latency_probes table (active supplier API latency probing)
+CREATE TABLE supplier_latency_probes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    supplier_id UUID NOT NULL REFERENCES suppliers(id) ON DELETE CASCADE,
+    operation VARCHAR(20) NOT NULL, -- Supplier call used as probe, e.g. BALANCE
+    latency_ms INTEGER NOT NULL CHECK (latency_ms >= 0),
+    success BOOLEAN NOT NULL,
+    error_message TEXT,
+
+    -- Timestamps
+    probed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Indexes
+CREATE INDEX idx_supplier_latency_probes_supplier_probed_at ON supplier_latency_probes(supplier_id, probed_at DESC);
+CREATE INDEX idx_supplier_latency_probes_probed_at ON supplier_latency_probes(probed_at);
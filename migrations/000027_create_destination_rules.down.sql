@@ -0,0 +1,8 @@
+-- Drop destination_rules table and product destination overrides
+ALTER TABLE products
+    DROP COLUMN IF EXISTS destination_hint,
+    DROP COLUMN IF EXISTS destination_max_length,
+    DROP COLUMN IF EXISTS destination_min_length,
+    DROP COLUMN IF EXISTS destination_pattern;
+
+DROP TABLE IF EXISTS destination_rules;
@@ -0,0 +1,33 @@
+-- Create destination_rules table (accepted destination format per product category)
+CREATE TABLE destination_rules (
+    category VARCHAR(50) PRIMARY KEY,
+    pattern TEXT NOT NULL DEFAULT '', -- Regular expression the whole destination must match, empty = any
+    min_length INTEGER NOT NULL DEFAULT 0 CHECK (min_length >= 0),
+    max_length INTEGER NOT NULL DEFAULT 0 CHECK (max_length >= 0), -- 0 = unlimited
+    hint VARCHAR(255) NOT NULL, -- Expected format shown to users when validation fails
+    normalize_phone BOOLEAN NOT NULL DEFAULT false, -- Store Indonesian phone numbers as 62xxx
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TRIGGER update_destination_rules_updated_at
+    BEFORE UPDATE ON destination_rules
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO destination_rules (category, pattern, min_length, max_length, hint, normalize_phone) VALUES
+    ('PULSA', '^(\+?62|0)8[0-9]{8,12}$', 10, 15, 'Nomor HP Indonesia, contoh 081234567890', true),
+    ('DATA', '^(\+?62|0)8[0-9]{8,12}$', 10, 15, 'Nomor HP Indonesia, contoh 081234567890', true),
+    ('PLN', '^[0-9]+$', 11, 12, 'Nomor meter atau ID pelanggan PLN, 11-12 digit angka', false),
+    ('PDAM', '^[0-9]+$', 4, 20, 'Nomor pelanggan PDAM, 4-20 digit angka', false),
+    ('BPJS', '^[0-9]+$', 11, 16, 'Nomor VA atau kartu BPJS, 11-16 digit angka', false),
+    ('GAME', '^[A-Za-z0-9]+(\([A-Za-z0-9]+\))?$', 3, 40, 'ID pemain; untuk game dengan server gunakan ID(SERVER), contoh 12345678(1234)', false),
+    ('VOUCHER', '^([0-9+]+|[^@\s]+@[^@\s]+\.[^@\s]+)$', 5, 100, 'Nomor HP atau email penerima voucher', false);
+
+-- Per-product overrides; NULL falls back to the category rule
+ALTER TABLE products
+    ADD COLUMN destination_pattern TEXT,
+    ADD COLUMN destination_min_length INTEGER CHECK (destination_min_length >= 0),
+    ADD COLUMN destination_max_length INTEGER CHECK (destination_max_length >= 0),
+    ADD COLUMN destination_hint VARCHAR(255);
@@ -0,0 +1,2 @@
+-- Drop user_favorites table
+DROP TABLE IF EXISTS user_favorites;
@@ -0,0 +1,22 @@
+-- Create user_favorites table (saved product + destination shortcuts for quick orders)
+CREATE TABLE user_favorites (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    destination_number VARCHAR(50), -- Optional, can be supplied at order time
+    alias VARCHAR(100), -- e.g. "Pulsa Ibu"
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- One favorite per product and destination per user
+CREATE UNIQUE INDEX idx_user_favorites_unique
+    ON user_favorites(user_id, product_id, COALESCE(destination_number, ''));
+CREATE INDEX idx_user_favorites_user_id ON user_favorites(user_id, created_at DESC);
+
+-- Trigger for updated_at
+CREATE TRIGGER update_user_favorites_updated_at
+    BEFORE UPDATE ON user_favorites
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
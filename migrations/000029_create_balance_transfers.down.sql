@@ -0,0 +1,4 @@
+-- Drop balance_transfers table and transaction PIN
+DROP TABLE IF EXISTS balance_transfers;
+
+ALTER TABLE users DROP COLUMN IF EXISTS pin_hash;
@@ -0,0 +1,19 @@
+-- Create balance_transfers table (balance moved between upline and downline)
+ALTER TABLE users ADD COLUMN pin_hash VARCHAR(255); -- Transaction PIN, NULL until set
+
+CREATE TABLE balance_transfers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    sender_id UUID NOT NULL REFERENCES users(id),
+    recipient_id UUID NOT NULL REFERENCES users(id),
+    amount DECIMAL(19, 4) NOT NULL CHECK (amount > 0),
+    note TEXT,
+
+    -- Timestamp
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    CONSTRAINT check_transfer_parties CHECK (sender_id <> recipient_id)
+);
+
+-- Indexes (history per user and daily totals per sender)
+CREATE INDEX idx_balance_transfers_sender ON balance_transfers(sender_id, created_at DESC, id DESC);
+CREATE INDEX idx_balance_transfers_recipient ON balance_transfers(recipient_id, created_at DESC, id DESC);
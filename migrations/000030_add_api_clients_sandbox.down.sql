@@ -0,0 +1,3 @@
+-- Drop sandbox mode from api_clients
+ALTER TABLE api_clients
+    DROP COLUMN IF EXISTS sandbox;
@@ -0,0 +1,3 @@
+-- Add sandbox mode to api_clients
+ALTER TABLE api_clients
+    ADD COLUMN sandbox BOOLEAN NOT NULL DEFAULT false; -- Every payment is simulated: no balance hold, no supplier call
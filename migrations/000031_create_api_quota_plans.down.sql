@@ -0,0 +1,5 @@
+-- Drop api_quota_plans table and the client assignment
+DROP INDEX IF EXISTS idx_api_clients_quota_plan_id;
+ALTER TABLE api_clients DROP COLUMN IF EXISTS quota_plan_id;
+
+DROP TABLE IF EXISTS api_quota_plans;
@@ -0,0 +1,25 @@
+-- Create api_quota_plans table (usage quotas assigned to H2H API clients)
+CREATE TABLE api_quota_plans (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    requests_per_minute INTEGER NOT NULL DEFAULT 0 CHECK (requests_per_minute >= 0), -- Per endpoint (0 = unlimited)
+    endpoint_limits JSONB NOT NULL DEFAULT '{}', -- Requests per minute keyed by route path, overriding requests_per_minute
+    transactions_per_day INTEGER NOT NULL DEFAULT 0 CHECK (transactions_per_day >= 0), -- 0 = unlimited
+    max_amount_per_day DECIMAL(19, 4) NOT NULL DEFAULT 0 CHECK (max_amount_per_day >= 0), -- 0 = unlimited
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Trigger for updated_at
+CREATE TRIGGER update_api_quota_plans_updated_at
+    BEFORE UPDATE ON api_quota_plans
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Clients without a plan keep max_requests_per_minute and no daily limits
+ALTER TABLE api_clients
+    ADD COLUMN quota_plan_id UUID REFERENCES api_quota_plans(id);
+
+CREATE INDEX idx_api_clients_quota_plan_id ON api_clients(quota_plan_id);
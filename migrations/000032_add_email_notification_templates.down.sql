@@ -0,0 +1,7 @@
+-- Drop email notification templates and restore the outbox event index
+DELETE FROM message_templates
+WHERE event_type IN ('deposit.confirmed', 'balance.low', 'daily.summary', 'password.reset');
+
+DROP INDEX IF EXISTS idx_outbox_dispatch;
+DROP INDEX IF EXISTS idx_outbox_source_event_id;
+CREATE UNIQUE INDEX idx_outbox_source_event_id ON outbox(source_event_id, destination) WHERE source_event_id IS NOT NULL;
@@ -0,0 +1,23 @@
+-- Add email notification templates and allow one event to produce several
+-- messages per destination (a deduction can notify the mutation and warn of
+-- a low balance)
+DROP INDEX IF EXISTS idx_outbox_source_event_id;
+CREATE UNIQUE INDEX idx_outbox_source_event_id ON outbox(source_event_id, destination, message_type) WHERE source_event_id IS NOT NULL;
+
+-- Dispatcher claims due messages per destination
+CREATE INDEX idx_outbox_dispatch ON outbox(destination, status, scheduled_at);
+
+INSERT INTO message_templates (event_type, channel, subject, body) VALUES
+    ('deposit.confirmed', 'WHATSAPP', NULL,
+     'Deposit {{amount}} berhasil masuk. Saldo: {{balance}}. {{description}}'),
+    ('deposit.confirmed', 'EMAIL', 'Deposit {{amount}} berhasil',
+     E'Halo {{name}},\n\nDeposit sebesar {{amount}} telah kami terima pada {{date}}.\nSaldo awal: {{balance_before}}\nSaldo akhir: {{balance}}\nKeterangan: {{description}}'),
+    ('balance.low', 'WHATSAPP', NULL,
+     'Saldo Anda tinggal {{balance}} (di bawah {{threshold}}). Segera lakukan deposit agar transaksi tidak gagal.'),
+    ('balance.low', 'EMAIL', 'Saldo Anda hampir habis',
+     E'Halo {{name}},\n\nSaldo Anda tinggal {{balance}} per {{date}}, di bawah batas {{threshold}}.\nSegera lakukan deposit agar transaksi tidak gagal.'),
+    ('daily.summary', 'EMAIL', 'Ringkasan aktivitas {{date}}',
+     E'Halo {{name}},\n\nRingkasan aktivitas akun Anda pada {{date}}:\nTotal transaksi: {{total_transactions}}\nBerhasil: {{success_count}}\nGagal: {{failed_count}}\nTotal pembelian: {{total_spent}}\nTotal deposit: {{total_deposit}}\nSaldo saat ini: {{balance}}'),
+    ('password.reset', 'EMAIL', 'Reset password akun {{username}}',
+     E'Halo {{name}},\n\nKami menerima permintaan reset password untuk akun {{username}}.\nBuka tautan berikut dalam {{expires_in}} untuk membuat password baru:\n{{reset_link}}\n\nAbaikan email ini jika Anda tidak meminta reset password.')
+ON CONFLICT (event_type, channel) DO NOTHING;
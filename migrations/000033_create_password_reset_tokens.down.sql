@@ -0,0 +1,4 @@
+-- Drop password_reset_tokens table and the WhatsApp reset template
+DELETE FROM message_templates WHERE event_type = 'password.reset' AND channel = 'WHATSAPP';
+
+DROP TABLE IF EXISTS password_reset_tokens;
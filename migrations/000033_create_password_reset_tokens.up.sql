@@ -0,0 +1,22 @@
+-- Create password_reset_tokens table (single-use, time-limited reset tokens)
+CREATE TABLE password_reset_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE, -- SHA-256 of the token, the token itself is never stored
+    channel VARCHAR(20) NOT NULL CHECK (channel IN ('WHATSAPP', 'EMAIL')),
+    requested_ip VARCHAR(45),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Indexes
+CREATE INDEX idx_password_reset_tokens_user_id ON password_reset_tokens(user_id, created_at);
+
+-- WhatsApp delivery of reset links
+INSERT INTO message_templates (event_type, channel, subject, body) VALUES
+    ('password.reset', 'WHATSAPP', NULL,
+     'Reset password akun {{username}}: {{reset_link}} (berlaku {{expires_in}}). Abaikan pesan ini jika Anda tidak memintanya.')
+ON CONFLICT (event_type, channel) DO NOTHING;
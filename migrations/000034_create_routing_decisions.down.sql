@@ -0,0 +1,2 @@
+-- Drop routing_decisions table
+DROP TABLE IF EXISTS routing_decisions;
@@ -0,0 +1,21 @@
+-- Create routing_decisions table (why smart routing chose a transaction's supplier)
+CREATE TABLE routing_decisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    transaction_id UUID NOT NULL REFERENCES transactions(id),
+    source VARCHAR(20) NOT NULL, -- ROUTING or FAILOVER
+    supplier_id UUID NOT NULL REFERENCES suppliers(id),
+    supplier_code VARCHAR(20) NOT NULL,
+    supplier_product_code VARCHAR(50) NOT NULL,
+    supplier_price DECIMAL(19, 4) NOT NULL DEFAULT 0.0000,
+    score DECIMAL(10, 4) NOT NULL DEFAULT 0,
+    confidence DECIMAL(5, 4) NOT NULL DEFAULT 0, -- 0.0 to 1.0
+    reason TEXT NOT NULL,
+    alternatives JSONB NOT NULL DEFAULT '[]', -- Suppliers scored but not chosen, best first
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Indexes
+CREATE INDEX idx_routing_decisions_transaction_id ON routing_decisions(transaction_id, created_at);
+CREATE INDEX idx_routing_decisions_supplier_id ON routing_decisions(supplier_id);
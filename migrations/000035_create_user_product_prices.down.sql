@@ -0,0 +1,2 @@
+-- Drop user_product_prices table
+DROP TABLE IF EXISTS user_product_prices;
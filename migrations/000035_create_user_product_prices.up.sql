@@ -0,0 +1,19 @@
+-- Create user_product_prices table (selling price overrides per user and product)
+CREATE TABLE user_product_prices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    price DECIMAL(19, 4) NOT NULL CHECK (price > 0),
+    set_by UUID NOT NULL REFERENCES users(id), -- Admin or upline who set the price
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    UNIQUE (user_id, product_id)
+);
+
+-- Trigger for updated_at
+CREATE TRIGGER update_user_product_prices_updated_at
+    BEFORE UPDATE ON user_product_prices
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,2 @@
+-- Drop commission_reversals table
+DROP TABLE IF EXISTS commission_reversals;
@@ -0,0 +1,18 @@
+-- Create commission_reversals table (audit of commissions taken back after a refund)
+CREATE TABLE commission_reversals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    transaction_id UUID NOT NULL REFERENCES transactions(id),
+    user_id UUID NOT NULL REFERENCES users(id), -- Upline who received the commission
+    commission_mutation_id UUID NOT NULL UNIQUE REFERENCES mutations(id), -- Reversed at most once
+    reversal_mutation_id UUID REFERENCES mutations(id), -- NULL when nothing could be taken back
+    commission_amount DECIMAL(19, 4) NOT NULL,
+    reversed_amount DECIMAL(19, 4) NOT NULL DEFAULT 0.0000,
+    shortfall DECIMAL(19, 4) NOT NULL DEFAULT 0.0000, -- Left to collect manually
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Indexes
+CREATE INDEX idx_commission_reversals_transaction_id ON commission_reversals(transaction_id);
+CREATE INDEX idx_commission_reversals_shortfall ON commission_reversals(user_id) WHERE shortfall > 0;
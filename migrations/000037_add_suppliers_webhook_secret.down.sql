@@ -0,0 +1,3 @@
+-- Drop suppliers webhook secret
+ALTER TABLE suppliers
+    DROP COLUMN IF EXISTS webhook_secret;
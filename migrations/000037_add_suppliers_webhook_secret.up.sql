@@ -0,0 +1,3 @@
+-- Add the secret suppliers sign their webhooks with
+ALTER TABLE suppliers
+    ADD COLUMN webhook_secret VARCHAR(255); -- HMAC key of inbound webhooks (NULL = webhooks rejected)
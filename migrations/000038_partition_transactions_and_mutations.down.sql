@@ -0,0 +1,68 @@
+-- Merge the partitions back into plain transactions and mutations tables
+ALTER TABLE transactions DETACH PARTITION transactions_legacy;
+ALTER TABLE mutations DETACH PARTITION mutations_legacy;
+
+INSERT INTO transactions_legacy (
+    id, trx_code, user_id, product_id, supplier_id, destination_number, product_code,
+    hpp, selling_price, admin_fee, status, serial_number, supplier_message, supplier_trx_id,
+    routing_attempts, final_supplier_id, created_at, updated_at, processed_at, completed_at,
+    user_ip, user_agent, api_endpoint, notes, channel, expires_at
+)
+SELECT
+    id, trx_code, user_id, product_id, supplier_id, destination_number, product_code,
+    hpp, selling_price, admin_fee, status, serial_number, supplier_message, supplier_trx_id,
+    routing_attempts, final_supplier_id, created_at, updated_at, processed_at, completed_at,
+    user_ip, user_agent, api_endpoint, notes, channel, expires_at
+FROM transactions;
+
+INSERT INTO mutations_legacy (
+    id, user_id, type, amount, balance_before, balance_after, reference_type, reference_id,
+    description, notes, created_by, ip_address, user_agent, created_at
+)
+SELECT
+    id, user_id, type, amount, balance_before, balance_after, reference_type, reference_id,
+    description, notes, created_by, ip_address, user_agent, created_at
+FROM mutations;
+
+DROP TABLE transactions;
+DROP TABLE mutations;
+DROP FUNCTION IF EXISTS create_monthly_partition(TEXT, DATE);
+
+ALTER TABLE transactions_legacy RENAME TO transactions;
+ALTER TABLE mutations_legacy RENAME TO mutations;
+
+ALTER TABLE transactions DROP CONSTRAINT transactions_legacy_pkey;
+ALTER TABLE mutations DROP CONSTRAINT mutations_legacy_pkey;
+
+DO $$
+DECLARE
+    idx RECORD;
+BEGIN
+    FOR idx IN
+        SELECT indexname FROM pg_indexes
+        WHERE schemaname = current_schema()
+        AND tablename IN ('transactions', 'mutations')
+        AND indexname LIKE '%\_legacy'
+    LOOP
+        EXECUTE format('ALTER INDEX %I RENAME TO %I', idx.indexname, left(idx.indexname, -length('_legacy')));
+    END LOOP;
+END $$;
+
+ALTER TABLE transactions ADD CONSTRAINT transactions_pkey PRIMARY KEY (id);
+ALTER TABLE transactions ALTER COLUMN created_at DROP NOT NULL;
+ALTER TABLE mutations ADD CONSTRAINT mutations_pkey PRIMARY KEY (id);
+ALTER TABLE mutations ALTER COLUMN created_at DROP NOT NULL;
+
+CREATE TRIGGER update_transactions_updated_at
+    BEFORE UPDATE ON transactions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Restore the foreign keys to transactions and mutations
+ALTER TABLE inbox ADD CONSTRAINT inbox_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES transactions(id);
+ALTER TABLE outbox ADD CONSTRAINT outbox_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES transactions(id);
+ALTER TABLE balance_holds ADD CONSTRAINT balance_holds_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE;
+ALTER TABLE transaction_events ADD CONSTRAINT transaction_events_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES transactions(id);
+ALTER TABLE routing_decisions ADD CONSTRAINT routing_decisions_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES transactions(id);
+ALTER TABLE commission_reversals ADD CONSTRAINT commission_reversals_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES transactions(id);
+ALTER TABLE commission_reversals ADD CONSTRAINT commission_reversals_commission_mutation_id_fkey FOREIGN KEY (commission_mutation_id) REFERENCES mutations(id);
+ALTER TABLE commission_reversals ADD CONSTRAINT commission_reversals_reversal_mutation_id_fkey FOREIGN KEY (reversal_mutation_id) REFERENCES mutations(id);
@@ -0,0 +1,146 @@
+-- Partition transactions and mutations by created_at month.
+--
+-- The existing tables are renamed to <table>_legacy and attached as the
+-- partition holding everything before next month, so no rows are copied.
+-- Monthly partitions from next month on are created by
+-- create_monthly_partition(), here and by the partition maintenance job.
+-- Attaching rebuilds the primary keys as (id, created_at) and scans the
+-- legacy tables once; run this migration in a quiet period.
+
+-- A foreign key needs a unique constraint on the referenced columns, which on
+-- a partitioned table must include created_at. The referencing rows are
+-- written in the same database transaction as the row they point to.
+ALTER TABLE inbox DROP CONSTRAINT IF EXISTS inbox_transaction_id_fkey;
+ALTER TABLE outbox DROP CONSTRAINT IF EXISTS outbox_transaction_id_fkey;
+ALTER TABLE balance_holds DROP CONSTRAINT IF EXISTS balance_holds_transaction_id_fkey;
+ALTER TABLE transaction_events DROP CONSTRAINT IF EXISTS transaction_events_transaction_id_fkey;
+ALTER TABLE routing_decisions DROP CONSTRAINT IF EXISTS routing_decisions_transaction_id_fkey;
+ALTER TABLE commission_reversals DROP CONSTRAINT IF EXISTS commission_reversals_transaction_id_fkey;
+ALTER TABLE commission_reversals DROP CONSTRAINT IF EXISTS commission_reversals_commission_mutation_id_fkey;
+ALTER TABLE commission_reversals DROP CONSTRAINT IF EXISTS commission_reversals_reversal_mutation_id_fkey;
+
+-- Move the current tables (and their index names) out of the way
+ALTER TABLE transactions RENAME TO transactions_legacy;
+ALTER TABLE mutations RENAME TO mutations_legacy;
+
+DO $$
+DECLARE
+    idx RECORD;
+BEGIN
+    FOR idx IN
+        SELECT indexname FROM pg_indexes
+        WHERE schemaname = current_schema()
+        AND tablename IN ('transactions_legacy', 'mutations_legacy')
+    LOOP
+        EXECUTE format('ALTER INDEX %I RENAME TO %I', idx.indexname, idx.indexname || '_legacy');
+    END LOOP;
+END $$;
+
+-- The partition key must be part of the primary key and never NULL
+ALTER TABLE transactions_legacy ALTER COLUMN created_at SET NOT NULL;
+ALTER TABLE transactions_legacy DROP CONSTRAINT transactions_pkey_legacy;
+ALTER TABLE transactions_legacy ADD CONSTRAINT transactions_legacy_pkey PRIMARY KEY (id, created_at);
+DROP TRIGGER IF EXISTS update_transactions_updated_at ON transactions_legacy;
+
+ALTER TABLE mutations_legacy ALTER COLUMN created_at SET NOT NULL;
+ALTER TABLE mutations_legacy DROP CONSTRAINT mutations_pkey_legacy;
+ALTER TABLE mutations_legacy ADD CONSTRAINT mutations_legacy_pkey PRIMARY KEY (id, created_at);
+
+-- Partitioned tables with the same columns, defaults and checks
+CREATE TABLE transactions (
+    LIKE transactions_legacy INCLUDING DEFAULTS INCLUDING GENERATED INCLUDING CONSTRAINTS,
+    PRIMARY KEY (id, created_at),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (product_id) REFERENCES products(id),
+    FOREIGN KEY (supplier_id) REFERENCES suppliers(id),
+    FOREIGN KEY (final_supplier_id) REFERENCES suppliers(id)
+) PARTITION BY RANGE (created_at);
+
+CREATE TABLE mutations (
+    LIKE mutations_legacy INCLUDING DEFAULTS INCLUDING GENERATED INCLUDING CONSTRAINTS,
+    PRIMARY KEY (id, created_at),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (created_by) REFERENCES users(id)
+) PARTITION BY RANGE (created_at);
+
+-- Creates the partition of parent for the month of month_start and returns its
+-- name (<parent>_YYYY_MM), or NULL when the month is already covered
+CREATE OR REPLACE FUNCTION create_monthly_partition(parent TEXT, month_start DATE)
+RETURNS TEXT AS $$
+DECLARE
+    start_date DATE := date_trunc('month', month_start)::DATE;
+    partition_name TEXT := parent || '_' || to_char(start_date, 'YYYY_MM');
+BEGIN
+    IF to_regclass(partition_name) IS NOT NULL THEN
+        RETURN NULL;
+    END IF;
+
+    BEGIN
+        EXECUTE format('CREATE TABLE %I PARTITION OF %I FOR VALUES FROM (%L) TO (%L)',
+            partition_name, parent, start_date, (start_date + INTERVAL '1 month')::DATE);
+    EXCEPTION WHEN invalid_object_definition THEN
+        -- The month overlaps the legacy partition
+        RETURN NULL;
+    END;
+
+    IF parent = 'transactions' THEN
+        -- trx_code carries its creation date, so unique per month is unique
+        EXECUTE format('CREATE UNIQUE INDEX %I ON %I (trx_code)', partition_name || '_trx_code_key', partition_name);
+    END IF;
+
+    RETURN partition_name;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Attach the legacy tables as the partition of everything before next month
+DO $$
+DECLARE
+    next_month DATE := (date_trunc('month', NOW()) + INTERVAL '1 month')::DATE;
+BEGIN
+    EXECUTE format('ALTER TABLE transactions ATTACH PARTITION transactions_legacy FOR VALUES FROM (MINVALUE) TO (%L)', next_month);
+    EXECUTE format('ALTER TABLE mutations ATTACH PARTITION mutations_legacy FOR VALUES FROM (MINVALUE) TO (%L)', next_month);
+END $$;
+
+-- Rows outside every monthly partition land here instead of failing; the
+-- maintenance job warns when it is not empty
+CREATE TABLE transactions_default PARTITION OF transactions DEFAULT;
+CREATE TABLE mutations_default PARTITION OF mutations DEFAULT;
+
+-- Next months; the maintenance job keeps creating them ahead
+SELECT create_monthly_partition('transactions', (date_trunc('month', NOW()) + make_interval(months => m))::DATE)
+FROM generate_series(1, 3) AS m;
+SELECT create_monthly_partition('mutations', (date_trunc('month', NOW()) + make_interval(months => m))::DATE)
+FROM generate_series(1, 3) AS m;
+
+-- Indexes on the partitioned tables. Matching legacy indexes are attached
+-- instead of rebuilt; new partitions get them automatically.
+CREATE INDEX idx_transactions_trx_code ON transactions(trx_code);
+CREATE INDEX idx_transactions_user_id ON transactions(user_id);
+CREATE INDEX idx_transactions_product_id ON transactions(product_id);
+CREATE INDEX idx_transactions_supplier_id ON transactions(supplier_id);
+CREATE INDEX idx_transactions_status ON transactions(status);
+CREATE INDEX idx_transactions_created_at ON transactions(created_at);
+CREATE INDEX idx_transactions_destination_number ON transactions(destination_number);
+CREATE INDEX idx_transactions_completed_at ON transactions(completed_at);
+CREATE INDEX idx_transactions_pending ON transactions(created_at) WHERE status = 'PENDING';
+CREATE INDEX idx_transactions_processing ON transactions(created_at) WHERE status = 'PROCESSING';
+CREATE INDEX idx_transactions_success ON transactions(created_at) WHERE status = 'SUCCESS';
+CREATE INDEX idx_transactions_failed ON transactions(created_at) WHERE status = 'FAILED';
+CREATE INDEX idx_transactions_product_supplier_created_at ON transactions(product_id, supplier_id, created_at);
+CREATE INDEX idx_transactions_user_created_id ON transactions(user_id, created_at DESC, id DESC);
+CREATE INDEX idx_transactions_pending_expires_at ON transactions(expires_at)
+    WHERE status = 'PENDING' AND expires_at IS NOT NULL;
+
+CREATE INDEX idx_mutations_user_id ON mutations(user_id);
+CREATE INDEX idx_mutations_type ON mutations(type);
+CREATE INDEX idx_mutations_reference_id ON mutations(reference_id);
+CREATE INDEX idx_mutations_created_at ON mutations(created_at);
+CREATE INDEX idx_mutations_reference_type ON mutations(reference_type);
+CREATE INDEX idx_mutations_user_created ON mutations(user_id, created_at DESC);
+CREATE INDEX idx_mutations_reference ON mutations(reference_type, reference_id);
+CREATE INDEX idx_mutations_user_created_id ON mutations(user_id, created_at DESC, id DESC);
+
+-- Trigger for updated_at
+CREATE TRIGGER update_transactions_updated_at
+    BEFORE UPDATE ON transactions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,4 @@
+-- Drop api_clients previous secret
+ALTER TABLE api_clients
+    DROP COLUMN IF EXISTS previous_secret_expires_at,
+    DROP COLUMN IF EXISTS previous_secret;
@@ -0,0 +1,4 @@
+-- Keep the replaced secret of an API client valid for a grace period after a rotation
+ALTER TABLE api_clients
+    ADD COLUMN previous_secret VARCHAR(255), -- Secret before the last rotation
+    ADD COLUMN previous_secret_expires_at TIMESTAMP WITH TIME ZONE; -- End of the previous secret's grace period
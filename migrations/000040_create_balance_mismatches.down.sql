@@ -0,0 +1,3 @@
+-- Drop balance_mismatches table and related objects
+DROP TRIGGER IF EXISTS update_balance_mismatches_updated_at ON balance_mismatches;
+DROP TABLE IF EXISTS balance_mismatches;
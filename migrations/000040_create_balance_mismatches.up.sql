@@ -0,0 +1,29 @@
+-- Create balance_mismatches table (users whose stored balance disagrees with the mutation ledger)
+CREATE TABLE balance_mismatches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    stored_balance DECIMAL(19, 4) NOT NULL, -- users.balance when last checked
+    ledger_balance DECIMAL(19, 4) NOT NULL, -- DEBIT (money in) minus CREDIT (money out) mutations
+    difference DECIMAL(19, 4) NOT NULL, -- stored_balance - ledger_balance
+    mutation_count INTEGER NOT NULL DEFAULT 0,
+    last_mutation_id UUID, -- Latest mutation of the user (no FK, mutations are partitioned)
+    last_balance_after DECIMAL(19, 4), -- balance_after of the latest mutation
+    status VARCHAR(20) NOT NULL DEFAULT 'OPEN' CHECK (status IN ('OPEN', 'RESOLVED', 'CLEARED')),
+    resolution TEXT,
+    resolved_by UUID REFERENCES users(id),
+    resolved_at TIMESTAMP WITH TIME ZONE,
+
+    -- Timestamps
+    detected_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    last_checked_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Indexes
+CREATE UNIQUE INDEX idx_balance_mismatches_open_user ON balance_mismatches(user_id) WHERE status = 'OPEN';
+CREATE INDEX idx_balance_mismatches_status ON balance_mismatches(status);
+
+-- Trigger for updated_at
+CREATE TRIGGER update_balance_mismatches_updated_at 
+    BEFORE UPDATE ON balance_mismatches 
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,11 @@
+-- Drop cutoff_schedules table and transaction scheduling
+DROP INDEX IF EXISTS idx_transactions_scheduled;
+UPDATE transactions SET status = 'PENDING' WHERE status = 'PENDING_SCHEDULE';
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_status_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_status_check CHECK (
+    status IN ('PENDING', 'PROCESSING', 'SUCCESS', 'FAILED', 'REFUND', 'TIMEOUT')
+);
+ALTER TABLE transactions DROP COLUMN IF EXISTS scheduled_at;
+
+DROP TRIGGER IF EXISTS update_cutoff_schedules_updated_at ON cutoff_schedules;
+DROP TABLE IF EXISTS cutoff_schedules;
@@ -0,0 +1,47 @@
+-- Create cutoff_schedules table (biller cutoff hours and ops calendar per category or supplier)
+CREATE TABLE cutoff_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    scope_type VARCHAR(20) NOT NULL CHECK (scope_type IN ('CATEGORY', 'SUPPLIER')),
+    scope_value VARCHAR(50) NOT NULL, -- Category code or supplier ID
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('DAILY', 'ONCE')),
+    start_time VARCHAR(5), -- HH:MM in the ops timezone, DAILY only
+    end_time VARCHAR(5), -- HH:MM, before start_time wraps past midnight
+    starts_at TIMESTAMP WITH TIME ZONE, -- ONCE only
+    ends_at TIMESTAMP WITH TIME ZONE,
+    action VARCHAR(10) NOT NULL DEFAULT 'QUEUE' CHECK (action IN ('QUEUE', 'BLOCK')),
+    reason TEXT, -- Shown to users whose order is held or rejected
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID REFERENCES users(id),
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    CHECK (
+        (kind = 'DAILY' AND start_time IS NOT NULL AND end_time IS NOT NULL)
+        OR (kind = 'ONCE' AND starts_at IS NOT NULL AND ends_at > starts_at)
+    )
+);
+
+-- Indexes
+CREATE INDEX idx_cutoff_schedules_scope ON cutoff_schedules(scope_type, scope_value);
+CREATE INDEX idx_cutoff_schedules_is_active ON cutoff_schedules(is_active);
+
+-- Trigger for updated_at
+CREATE TRIGGER update_cutoff_schedules_updated_at 
+    BEFORE UPDATE ON cutoff_schedules 
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Nightly biller cutoffs
+INSERT INTO cutoff_schedules (scope_type, scope_value, kind, start_time, end_time, action, reason) VALUES
+    ('CATEGORY', 'PDAM', 'DAILY', '23:00', '01:00', 'QUEUE', 'Cutoff biller PDAM 23:00-01:00'),
+    ('CATEGORY', 'BPJS', 'DAILY', '23:30', '00:30', 'QUEUE', 'Cutoff biller BPJS 23:30-00:30');
+
+-- Transactions held by a cutoff are PENDING_SCHEDULE until scheduled_at
+ALTER TABLE transactions ADD COLUMN scheduled_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_status_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_status_check CHECK (
+    status IN ('PENDING', 'PENDING_SCHEDULE', 'PROCESSING', 'SUCCESS', 'FAILED', 'REFUND', 'TIMEOUT')
+);
+
+CREATE INDEX idx_transactions_scheduled ON transactions(scheduled_at) WHERE status = 'PENDING_SCHEDULE';
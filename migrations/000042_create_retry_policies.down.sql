@@ -0,0 +1,3 @@
+-- Drop retry_policies table
+DROP TRIGGER IF EXISTS update_retry_policies_updated_at ON retry_policies;
+DROP TABLE IF EXISTS retry_policies;
@@ -0,0 +1,26 @@
+-- Create retry_policies table (auto-retry tuning per supplier and error class)
+CREATE TABLE retry_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    supplier_id UUID REFERENCES suppliers(id) ON DELETE CASCADE, -- NULL applies to every supplier
+    error_class VARCHAR(10) NOT NULL CHECK (error_class IN ('TIMEOUT', 'FAILURE', 'ANY')),
+    max_attempts INTEGER NOT NULL CHECK (max_attempts BETWEEN 0 AND 10), -- 0 disables the retry
+    initial_delay_ms INTEGER NOT NULL DEFAULT 2000 CHECK (initial_delay_ms >= 0),
+    max_delay_ms INTEGER NOT NULL DEFAULT 30000 CHECK (max_delay_ms >= initial_delay_ms),
+    backoff_multiplier DECIMAL(5,2) NOT NULL DEFAULT 2.00 CHECK (backoff_multiplier >= 1),
+    enable_jitter BOOLEAN NOT NULL DEFAULT true,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- One policy per supplier (or the global scope) and error class
+CREATE UNIQUE INDEX idx_retry_policies_scope ON retry_policies(
+    COALESCE(supplier_id, '00000000-0000-0000-0000-000000000000'::uuid), error_class
+);
+
+-- Trigger for updated_at
+CREATE TRIGGER update_retry_policies_updated_at 
+    BEFORE UPDATE ON retry_policies 
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
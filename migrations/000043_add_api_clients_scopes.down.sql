@@ -0,0 +1,4 @@
+-- Drop access scopes from api_clients
+ALTER TABLE api_clients
+    DROP CONSTRAINT IF EXISTS chk_api_clients_scopes,
+    DROP COLUMN IF EXISTS scopes;
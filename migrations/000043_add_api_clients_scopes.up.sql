@@ -0,0 +1,4 @@
+-- Add access scopes to api_clients
+ALTER TABLE api_clients
+    ADD COLUMN scopes TEXT[] NOT NULL DEFAULT '{read,transact}', -- read: account and history endpoints, transact: payments
+    ADD CONSTRAINT chk_api_clients_scopes CHECK (scopes <@ ARRAY['read', 'transact']::TEXT[]);
@@ -0,0 +1,4 @@
+-- Restore the generated profit column
+ALTER TABLE transactions DROP COLUMN profit;
+ALTER TABLE transactions
+    ADD COLUMN profit DECIMAL(19, 4) GENERATED ALWAYS AS (selling_price + admin_fee - hpp) STORED;
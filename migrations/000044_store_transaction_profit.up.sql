@@ -0,0 +1,8 @@
+-- Store the profit of a transaction when it completes instead of generating it.
+-- Dropping the expression keeps the values already computed, so no row is
+-- rewritten here. The stored profit also nets the upline commissions and is 0
+-- for transactions that did not succeed; existing rows are brought in line by
+-- `eraflazzctl profit backfill`.
+ALTER TABLE transactions ALTER COLUMN profit DROP EXPRESSION;
+ALTER TABLE transactions ALTER COLUMN profit SET DEFAULT 0;
+ALTER TABLE transactions ALTER COLUMN profit SET NOT NULL;
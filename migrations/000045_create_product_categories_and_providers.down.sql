@@ -0,0 +1,4 @@
+-- Drop product_categories and product_providers tables
+DROP INDEX IF EXISTS idx_products_provider_category;
+DROP TABLE IF EXISTS product_providers;
+DROP TABLE IF EXISTS product_categories;
@@ -0,0 +1,55 @@
+-- Create product_categories and product_providers tables (admin-managed
+-- storefront menus; products reference them by code)
+CREATE TABLE product_categories (
+    code VARCHAR(50) PRIMARY KEY, -- PULSA, DATA, PLN, ...
+    name VARCHAR(100) NOT NULL,
+    icon_url VARCHAR(500),
+    display_order INTEGER NOT NULL DEFAULT 0 CHECK (display_order >= 0),
+    is_active BOOLEAN NOT NULL DEFAULT true, -- Inactive categories are hidden from public menus
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE product_providers (
+    code VARCHAR(50) PRIMARY KEY, -- TELKOMSEL, INDOSAT, PLN, ...
+    name VARCHAR(100) NOT NULL,
+    icon_url VARCHAR(500),
+    display_order INTEGER NOT NULL DEFAULT 0 CHECK (display_order >= 0),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_product_categories_display_order ON product_categories(display_order, code);
+CREATE INDEX idx_product_providers_display_order ON product_providers(display_order, code);
+CREATE INDEX idx_products_provider_category ON products(provider, category) WHERE is_active = true;
+
+-- Triggers for updated_at
+CREATE TRIGGER update_product_categories_updated_at
+    BEFORE UPDATE ON product_categories
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_product_providers_updated_at
+    BEFORE UPDATE ON product_providers
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- The categories that were hardcoded so far
+INSERT INTO product_categories (code, name, display_order) VALUES
+    ('PULSA', 'Pulsa', 10),
+    ('DATA', 'Paket Data', 20),
+    ('PLN', 'PLN', 30),
+    ('PDAM', 'PDAM', 40),
+    ('BPJS', 'BPJS', 50),
+    ('GAME', 'Voucher Game', 60),
+    ('VOUCHER', 'Voucher', 70);
+
+-- Provider codes are upper case; register the providers already used by products
+UPDATE products SET provider = UPPER(TRIM(provider)) WHERE provider <> UPPER(TRIM(provider));
+
+INSERT INTO product_providers (code, name)
+SELECT DISTINCT provider, provider FROM products
+ON CONFLICT (code) DO NOTHING;
@@ -0,0 +1,2 @@
+-- Drop admin_signing_keys table
+DROP TABLE IF EXISTS admin_signing_keys;
@@ -0,0 +1,13 @@
+-- Per-admin HMAC secrets signing destructive admin requests (force refund,
+-- balance corrections) on top of the JWT. One active key per admin.
+CREATE TABLE admin_signing_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    secret VARCHAR(128) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    revoked_by UUID REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX idx_admin_signing_keys_active_user ON admin_signing_keys(user_id) WHERE revoked_at IS NULL;
@@ -0,0 +1,6 @@
+-- Drop supplier reachability columns
+ALTER TABLE suppliers
+    DROP COLUMN IF EXISTS last_pinged_at,
+    DROP COLUMN IF EXISTS last_ping_latency_ms,
+    DROP COLUMN IF EXISTS ping_failures,
+    DROP COLUMN IF EXISTS is_reachable;
@@ -0,0 +1,7 @@
+-- Store the result of the periodic supplier ping so routing can down-rank a
+-- supplier that stopped answering without waiting for failed transactions
+ALTER TABLE suppliers
+    ADD COLUMN is_reachable BOOLEAN NOT NULL DEFAULT true,
+    ADD COLUMN ping_failures INTEGER NOT NULL DEFAULT 0, -- Consecutive failed pings
+    ADD COLUMN last_ping_latency_ms INTEGER,
+    ADD COLUMN last_pinged_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,3 @@
+-- Drop transaction tags
+DROP INDEX IF EXISTS idx_transactions_tags;
+ALTER TABLE transactions DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,6 @@
+-- Partner-defined transaction tags, e.g. {"campaign": "RAMADAN24", "branch": "JKT-01"}.
+-- A constant default adds the column without rewriting the partitions.
+ALTER TABLE transactions ADD COLUMN tags JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+-- Listing filters use containment (tags @> '{"branch": "JKT-01"}')
+CREATE INDEX idx_transactions_tags ON transactions USING GIN (tags jsonb_path_ops);
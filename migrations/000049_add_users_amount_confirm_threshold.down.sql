@@ -0,0 +1,2 @@
+-- Drop the per-user amount confirmation threshold
+ALTER TABLE users DROP COLUMN IF EXISTS amount_confirm_threshold;
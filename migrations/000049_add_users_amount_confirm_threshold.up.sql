@@ -0,0 +1,4 @@
+-- Orders totalling more than this need a confirmation token or the PIN.
+-- NULL uses TRANSACTION_CONFIRM_THRESHOLD; 0 turns the check off.
+ALTER TABLE users ADD COLUMN amount_confirm_threshold DECIMAL(19, 4)
+    CHECK (amount_confirm_threshold IS NULL OR amount_confirm_threshold >= 0);
@@ -0,0 +1,3 @@
+-- Drop admin impersonation audit tables
+DROP TABLE IF EXISTS impersonation_actions;
+DROP TABLE IF EXISTS impersonation_sessions;
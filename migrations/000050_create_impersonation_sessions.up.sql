@@ -0,0 +1,31 @@
+-- Admin impersonation sessions: a short-lived, read-only token acting as a
+-- user so support sees what the user sees, with every request audited
+CREATE TABLE impersonation_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    admin_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    ip_address VARCHAR(45) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ended_at TIMESTAMP WITH TIME ZONE,
+    ended_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_impersonation_sessions_admin ON impersonation_sessions(admin_id, created_at DESC);
+CREATE INDEX idx_impersonation_sessions_user ON impersonation_sessions(user_id, created_at DESC);
+
+-- Every request made with an impersonation token, including rejected ones
+CREATE TABLE impersonation_actions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID NOT NULL REFERENCES impersonation_sessions(id) ON DELETE CASCADE,
+    method VARCHAR(10) NOT NULL,
+    path VARCHAR(500) NOT NULL, -- Request URI including the query string
+    route VARCHAR(255), -- Matched route pattern, NULL when no route matched
+    status_code INTEGER NOT NULL,
+    ip_address VARCHAR(45) NOT NULL,
+    user_agent TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_impersonation_actions_session ON impersonation_actions(session_id, created_at);
@@ -0,0 +1,4 @@
+-- Drop price change digest templates and the history index
+DELETE FROM message_templates WHERE event_type = 'price.changed';
+
+DROP INDEX IF EXISTS idx_product_price_history_type_created;
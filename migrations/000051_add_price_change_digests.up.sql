@@ -0,0 +1,9 @@
+-- Price change listings and digests scan the history across products by time
+CREATE INDEX IF NOT EXISTS idx_product_price_history_type_created ON product_price_history(price_type, created_at DESC);
+
+INSERT INTO message_templates (event_type, channel, subject, body) VALUES
+    ('price.changed', 'WHATSAPP', NULL,
+     E'Perubahan harga {{category}} ({{change_count}} produk) periode {{period_start}} s/d {{period_end}}:\n{{changes}}'),
+    ('price.changed', 'EMAIL', 'Perubahan harga {{category}}',
+     E'Halo {{name}},\n\nBerikut {{change_count}} perubahan harga produk {{category}} periode {{period_start}} s/d {{period_end}}:\n\n{{changes}}\n\nHarga baru sudah berlaku sejak perubahan dicatat.')
+ON CONFLICT (event_type, channel) DO NOTHING;
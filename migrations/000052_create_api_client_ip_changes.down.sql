@@ -0,0 +1,2 @@
+-- Drop the IP whitelist change audit
+DROP TABLE IF EXISTS api_client_ip_changes;
@@ -0,0 +1,17 @@
+-- Audit of per-client H2H IP whitelist changes, made by admins or by the
+-- client itself through the self-service API
+CREATE TABLE api_client_ip_changes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id VARCHAR(100) NOT NULL REFERENCES api_clients(client_id) ON DELETE CASCADE,
+    old_ips TEXT[] NOT NULL DEFAULT '{}',
+    new_ips TEXT[] NOT NULL DEFAULT '{}',
+    actor_type VARCHAR(10) NOT NULL CHECK (actor_type IN ('ADMIN', 'CLIENT')),
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL, -- Admin; NULL for the client
+    ip_address VARCHAR(45) NOT NULL,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Indexes
+CREATE INDEX idx_api_client_ip_changes_client ON api_client_ip_changes(client_id, created_at DESC);
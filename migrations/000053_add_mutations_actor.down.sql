@@ -0,0 +1,3 @@
+-- Drop the mutation actor columns
+ALTER TABLE mutations DROP COLUMN IF EXISTS actor_name;
+ALTER TABLE mutations DROP COLUMN IF EXISTS actor_type;
@@ -0,0 +1,6 @@
+-- Who or what moved the money: USER, ADMIN or SYSTEM, with the H2H client
+-- or system component in actor_name. created_by, ip_address and user_agent
+-- hold the acting user and the request it came from.
+ALTER TABLE mutations ADD COLUMN actor_type VARCHAR(20)
+    CHECK (actor_type IS NULL OR actor_type IN ('USER', 'ADMIN', 'SYSTEM'));
+ALTER TABLE mutations ADD COLUMN actor_name VARCHAR(100);
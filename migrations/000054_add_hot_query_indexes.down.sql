@@ -0,0 +1,6 @@
+-- Drop the hot query composite indexes
+DROP INDEX IF EXISTS idx_product_mappings_product_active;
+DROP INDEX IF EXISTS idx_mutations_user_reference_created;
+DROP INDEX IF EXISTS idx_transactions_user_channel_created;
+DROP INDEX IF EXISTS idx_transactions_user_product_destination;
+DROP INDEX IF EXISTS idx_transactions_status_created;
@@ -0,0 +1,22 @@
+-- Composite indexes for the hot queries checked at startup by the index
+-- advisor (internal/repository/postgres/index_advisor.go). Indexes on the
+-- partitioned tables are created on every partition, new ones included.
+
+-- Status lists and jobs filtered by period: timeouts, expiry, admin reports
+CREATE INDEX IF NOT EXISTS idx_transactions_status_created ON transactions(status, created_at);
+
+-- Duplicate order guard: same user, product and destination within a window
+CREATE INDEX IF NOT EXISTS idx_transactions_user_product_destination
+    ON transactions(user_id, product_code, destination_number, created_at DESC);
+
+-- Per-channel usage of an account (H2H client usage)
+CREATE INDEX IF NOT EXISTS idx_transactions_user_channel_created
+    ON transactions(user_id, channel, created_at);
+
+-- Commission totals per user and period
+CREATE INDEX IF NOT EXISTS idx_mutations_user_reference_created
+    ON mutations(user_id, reference_type, created_at);
+
+-- Routing reads the active mappings of a product in priority order
+CREATE INDEX IF NOT EXISTS idx_product_mappings_product_active
+    ON product_mappings(product_id, is_active, priority);
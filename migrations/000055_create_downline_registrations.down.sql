@@ -0,0 +1,4 @@
+-- Drop downline registrations and referral codes
+DROP TABLE IF EXISTS downline_registrations;
+DROP INDEX IF EXISTS idx_users_referral_code;
+ALTER TABLE users DROP COLUMN IF EXISTS referral_code;
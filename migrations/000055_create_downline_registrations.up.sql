@@ -0,0 +1,27 @@
+-- Referral code an agent shares so new users register under them
+ALTER TABLE users ADD COLUMN referral_code VARCHAR(16);
+CREATE UNIQUE INDEX idx_users_referral_code ON users(referral_code) WHERE referral_code IS NOT NULL;
+
+-- Registrations made with a referral code, waiting for the upline's
+-- approval. The user is created under the upline when approved.
+CREATE TABLE downline_registrations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    upline_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    referral_code VARCHAR(16) NOT NULL, -- Code used, kept after a rotation
+    email VARCHAR(100) NOT NULL,
+    full_name VARCHAR(100) NOT NULL,
+    phone VARCHAR(20),
+    password_hash VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'APPROVED', 'REJECTED')),
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL, -- Created user once approved
+    reason TEXT, -- Rejection reason
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMP WITH TIME ZONE,
+    ip_address VARCHAR(45),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_downline_registrations_upline ON downline_registrations(upline_id, status, created_at DESC);
+-- One pending registration per email
+CREATE UNIQUE INDEX idx_downline_registrations_pending_email
+    ON downline_registrations(email) WHERE status = 'PENDING';
@@ -0,0 +1,2 @@
+-- Drop status page incidents
+DROP TABLE IF EXISTS status_incidents;
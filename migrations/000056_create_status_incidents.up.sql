@@ -0,0 +1,18 @@
+-- Incident notes shown on the public status page. An incident affects the
+-- listed product categories, or every category when the list is empty, and
+-- holds their status at or below its impact until it is resolved.
+CREATE TABLE status_incidents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    title VARCHAR(200) NOT NULL,
+    message TEXT NOT NULL,
+    impact VARCHAR(20) NOT NULL CHECK (impact IN ('degraded', 'down')),
+    categories TEXT[] NOT NULL DEFAULT '{}',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_status_incidents_started ON status_incidents(started_at DESC);
+CREATE INDEX idx_status_incidents_open ON status_incidents(started_at) WHERE resolved_at IS NULL;
@@ -0,0 +1,2 @@
+-- Drop supplier request rules
+ALTER TABLE suppliers DROP COLUMN IF EXISTS request_rules;
@@ -0,0 +1,4 @@
+-- Per-supplier rewrite rules for request destinations (phone prefix, meter
+-- ID padding, ...) applied by the adapter layer, with the examples they are
+-- checked against when saved. See domain.SupplierRequestRules.
+ALTER TABLE suppliers ADD COLUMN request_rules JSONB NOT NULL DEFAULT '{"rules": []}';
@@ -0,0 +1,4 @@
+-- Drop supplier invoices
+DROP INDEX IF EXISTS idx_transactions_supplier_trx_id;
+DROP TABLE IF EXISTS supplier_invoice_lines;
+DROP TABLE IF EXISTS supplier_invoices;
@@ -0,0 +1,51 @@
+-- Supplier recap files (invoices) uploaded by admins and matched against our
+-- transactions. Every line keeps its match result; transactions we completed
+-- at the supplier within the invoice period but missing from the file are
+-- stored as UNBILLED lines with line_number 0.
+CREATE TABLE supplier_invoices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    supplier_id UUID NOT NULL REFERENCES suppliers(id),
+    file_name VARCHAR(255) NOT NULL,
+    period_start DATE,
+    period_end DATE,
+    line_count INTEGER NOT NULL DEFAULT 0,
+    matched_count INTEGER NOT NULL DEFAULT 0,
+    missing_count INTEGER NOT NULL DEFAULT 0,
+    amount_mismatch_count INTEGER NOT NULL DEFAULT 0,
+    status_mismatch_count INTEGER NOT NULL DEFAULT 0,
+    duplicate_count INTEGER NOT NULL DEFAULT 0,
+    unbilled_count INTEGER NOT NULL DEFAULT 0,
+    invoiced_total DECIMAL(15,2) NOT NULL DEFAULT 0,
+    expected_total DECIMAL(15,2) NOT NULL DEFAULT 0,
+    difference DECIMAL(15,2) NOT NULL DEFAULT 0,
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_supplier_invoices_supplier ON supplier_invoices(supplier_id, created_at DESC);
+
+CREATE TABLE supplier_invoice_lines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    invoice_id UUID NOT NULL REFERENCES supplier_invoices(id) ON DELETE CASCADE,
+    line_number INTEGER NOT NULL,
+    supplier_trx_id VARCHAR(100),
+    ref_id VARCHAR(100),
+    product_code VARCHAR(100),
+    destination VARCHAR(100),
+    invoiced_status VARCHAR(50),
+    invoiced_amount DECIMAL(15,2),
+    transaction_id UUID,
+    trx_code VARCHAR(50),
+    transaction_status VARCHAR(20),
+    expected_amount DECIMAL(15,2),
+    result VARCHAR(20) NOT NULL CHECK (result IN (
+        'MATCHED', 'MISSING', 'AMOUNT_MISMATCH', 'STATUS_MISMATCH', 'DUPLICATE', 'UNBILLED'
+    )),
+    note TEXT
+);
+
+CREATE INDEX idx_supplier_invoice_lines_invoice ON supplier_invoice_lines(invoice_id, result, line_number);
+
+-- Invoice lines are matched on the supplier's transaction ID
+CREATE INDEX IF NOT EXISTS idx_transactions_supplier_trx_id
+    ON transactions(supplier_id, supplier_trx_id) WHERE supplier_trx_id IS NOT NULL;
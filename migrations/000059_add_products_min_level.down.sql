@@ -0,0 +1,4 @@
+-- Drop product level restrictions
+DROP INDEX IF EXISTS idx_products_min_level;
+ALTER TABLE products
+    DROP COLUMN IF EXISTS min_level;
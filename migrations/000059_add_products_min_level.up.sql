@@ -0,0 +1,5 @@
+-- Minimum user level allowed to see and buy a product; NULL means every level
+ALTER TABLE products
+    ADD COLUMN min_level SMALLINT CHECK (min_level BETWEEN 1 AND 4);
+
+CREATE INDEX idx_products_min_level ON products(min_level) WHERE min_level IS NOT NULL;
@@ -0,0 +1,4 @@
+-- Drop response projection from api_clients
+ALTER TABLE api_clients
+    DROP COLUMN IF EXISTS response_exclude_fields,
+    DROP COLUMN IF EXISTS response_include_fields;
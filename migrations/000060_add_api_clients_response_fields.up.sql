@@ -0,0 +1,4 @@
+-- Add per-client response projection of transaction endpoints
+ALTER TABLE api_clients
+    ADD COLUMN response_include_fields TEXT[] NOT NULL DEFAULT '{}', -- Whitelist; empty keeps every field
+    ADD COLUMN response_exclude_fields TEXT[] NOT NULL DEFAULT '{}'; -- Removed after the whitelist
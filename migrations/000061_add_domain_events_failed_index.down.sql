@@ -0,0 +1,2 @@
+-- Drop the dead letter queue index
+DROP INDEX IF EXISTS idx_domain_events_failed;
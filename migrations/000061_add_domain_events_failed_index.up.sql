@@ -0,0 +1,2 @@
+-- Dead letter queue lookups (listing and sizing failed outbox events)
+CREATE INDEX idx_domain_events_failed ON domain_events(created_at) WHERE status = 'FAILED';
@@ -0,0 +1,4 @@
+-- Drop cashback promotions
+DROP TABLE IF EXISTS promotion_cashbacks;
+DROP TRIGGER IF EXISTS update_promotions_updated_at ON promotions;
+DROP TABLE IF EXISTS promotions;
@@ -0,0 +1,50 @@
+-- Create promotions table (cashback campaigns paid back on successful transactions)
+CREATE TABLE promotions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    category VARCHAR(20), -- Product category (NULL = all categories)
+    user_levels SMALLINT[] NOT NULL DEFAULT '{}', -- Eligible user levels (empty = all levels)
+    percentage DECIMAL(7, 4) NOT NULL CHECK (percentage > 0 AND percentage <= 100), -- Percent of the selling price paid back
+    max_cashback DECIMAL(19, 4) CHECK (max_cashback > 0), -- Cap per transaction (NULL = uncapped)
+    budget DECIMAL(19, 4) CHECK (budget > 0), -- Cap on all cashback of the promotion (NULL = uncapped)
+    spent DECIMAL(19, 4) NOT NULL DEFAULT 0.0000, -- Cashback paid, net of reversals
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    is_active BOOLEAN DEFAULT true,
+    created_by UUID REFERENCES users(id),
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    CHECK (starts_at < ends_at)
+);
+
+-- Indexes
+CREATE INDEX idx_promotions_active ON promotions(ends_at) WHERE is_active = true;
+
+-- Trigger for updated_at
+CREATE TRIGGER update_promotions_updated_at
+    BEFORE UPDATE ON promotions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Cashback paid per transaction. transactions and mutations are partitioned,
+-- so they are referenced without foreign keys.
+CREATE TABLE promotion_cashbacks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    promotion_id UUID NOT NULL REFERENCES promotions(id),
+    transaction_id UUID NOT NULL UNIQUE, -- At most one cashback per transaction
+    user_id UUID NOT NULL REFERENCES users(id),
+    transaction_amount DECIMAL(19, 4) NOT NULL, -- Selling price the cashback was computed from
+    amount DECIMAL(19, 4) NOT NULL CHECK (amount > 0),
+    mutation_id UUID NOT NULL,
+    reversed_amount DECIMAL(19, 4) NOT NULL DEFAULT 0.0000, -- Taken back after a refund
+    reversed_at TIMESTAMP WITH TIME ZONE,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Indexes
+CREATE INDEX idx_promotion_cashbacks_created_at ON promotion_cashbacks(created_at, promotion_id);
@@ -0,0 +1,3 @@
+-- Drop voucher_codes table
+DROP TRIGGER IF EXISTS update_voucher_codes_updated_at ON voucher_codes;
+DROP TABLE IF EXISTS voucher_codes;
@@ -0,0 +1,25 @@
+-- Create voucher_codes table (code stock of voucher products without unlimited stock)
+CREATE TABLE voucher_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES products(id),
+    code_encrypted TEXT NOT NULL, -- AES-GCM sealed code
+    code_hash VARCHAR(64) NOT NULL UNIQUE, -- HMAC-SHA256 of the code, rejects duplicates
+    status VARCHAR(20) NOT NULL DEFAULT 'AVAILABLE' CHECK (status IN ('AVAILABLE', 'ALLOCATED')),
+    transaction_id UUID, -- transactions is partitioned, so no foreign key
+    expires_at TIMESTAMP WITH TIME ZONE,
+    uploaded_by UUID REFERENCES users(id),
+    allocated_at TIMESTAMP WITH TIME ZONE,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Indexes
+CREATE INDEX idx_voucher_codes_available ON voucher_codes(product_id, expires_at, created_at) WHERE status = 'AVAILABLE';
+CREATE UNIQUE INDEX idx_voucher_codes_transaction ON voucher_codes(transaction_id) WHERE transaction_id IS NOT NULL;
+
+-- Trigger for updated_at
+CREATE TRIGGER update_voucher_codes_updated_at
+    BEFORE UPDATE ON voucher_codes
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,21 @@
+-- Create feature_flags table (gradual rollout of new behaviors per environment)
+CREATE TABLE feature_flags (
+    key VARCHAR(100) NOT NULL,
+    environment VARCHAR(50) NOT NULL, -- APP_ENV of the processes evaluating the flag
+    description TEXT NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    rollout_percent INTEGER NOT NULL DEFAULT 100 CHECK (rollout_percent BETWEEN 0 AND 100),
+    user_ids TEXT[] NOT NULL DEFAULT '{}', -- on for these users regardless of the rollout
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    PRIMARY KEY (key, environment)
+);
+
+-- Trigger for updated_at
+CREATE TRIGGER update_feature_flags_updated_at
+    BEFORE UPDATE ON feature_flags
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
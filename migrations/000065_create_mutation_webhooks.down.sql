@@ -0,0 +1,6 @@
+-- Drop mutation webhook tables
+DROP TABLE IF EXISTS mutation_webhook_items;
+DROP TRIGGER IF EXISTS update_mutation_webhook_deliveries_updated_at ON mutation_webhook_deliveries;
+DROP TABLE IF EXISTS mutation_webhook_deliveries;
+DROP TRIGGER IF EXISTS update_mutation_webhooks_updated_at ON mutation_webhooks;
+DROP TABLE IF EXISTS mutation_webhooks;
@@ -0,0 +1,57 @@
+-- Create mutation_webhooks table (balance mutations pushed to the accounting system of a user)
+CREATE TABLE mutation_webhooks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret VARCHAR(128) NOT NULL, -- HMAC-SHA256 key of the X-Eraflazz-Signature header
+    include_downlines BOOLEAN NOT NULL DEFAULT false,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create mutation_webhook_deliveries table (one batch of mutations posted to a webhook)
+CREATE TABLE mutation_webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(), -- event ID of the request
+    webhook_id UUID NOT NULL REFERENCES mutation_webhooks(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'DELIVERED', 'FAILED')),
+    mutation_count INTEGER NOT NULL DEFAULT 0,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMP WITH TIME ZONE,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create mutation_webhook_items table (mutations queued for a webhook, batched into deliveries)
+CREATE TABLE mutation_webhook_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    webhook_id UUID NOT NULL REFERENCES mutation_webhooks(id) ON DELETE CASCADE,
+    mutation_id UUID NOT NULL, -- mutations is partitioned, so no foreign key
+    payload JSONB NOT NULL,
+    delivery_id UUID REFERENCES mutation_webhook_deliveries(id) ON DELETE CASCADE, -- NULL while queued
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    UNIQUE (webhook_id, mutation_id)
+);
+
+-- Indexes
+CREATE INDEX idx_mutation_webhook_deliveries_due ON mutation_webhook_deliveries(next_attempt_at) WHERE status = 'PENDING';
+CREATE INDEX idx_mutation_webhook_deliveries_webhook ON mutation_webhook_deliveries(webhook_id, created_at DESC);
+CREATE INDEX idx_mutation_webhook_items_queued ON mutation_webhook_items(webhook_id, created_at) WHERE delivery_id IS NULL;
+CREATE INDEX idx_mutation_webhook_items_delivery ON mutation_webhook_items(delivery_id, created_at);
+
+-- Triggers for updated_at
+CREATE TRIGGER update_mutation_webhooks_updated_at
+    BEFORE UPDATE ON mutation_webhooks
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_mutation_webhook_deliveries_updated_at
+    BEFORE UPDATE ON mutation_webhook_deliveries
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,2 @@
+-- Drop the voucher code stock opt-in of products
+ALTER TABLE products DROP COLUMN IF EXISTS is_voucher_stock;
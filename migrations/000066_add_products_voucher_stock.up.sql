@@ -0,0 +1,3 @@
+-- Opt-in for voucher products sold from the uploaded code stock. Existing
+-- vouchers keep going to their suppliers until an admin switches them over.
+ALTER TABLE products ADD COLUMN is_voucher_stock BOOLEAN NOT NULL DEFAULT false;
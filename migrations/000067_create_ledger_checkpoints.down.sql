@@ -0,0 +1,3 @@
+-- Drop ledger_checkpoints tables
+DROP TABLE IF EXISTS ledger_checkpoint_partitions;
+DROP TABLE IF EXISTS ledger_checkpoints;
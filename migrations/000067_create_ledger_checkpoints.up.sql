@@ -0,0 +1,23 @@
+-- Create ledger_checkpoints table (per user sum of the mutations in detached
+-- mutation partitions, the opening balance of the attached ledger)
+CREATE TABLE ledger_checkpoints (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    opening_balance DECIMAL(19, 4) NOT NULL DEFAULT 0, -- DEBIT minus CREDIT of the detached mutations
+    mutation_count BIGINT NOT NULL DEFAULT 0,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Detached mutation partitions, each folded into the checkpoints exactly once
+CREATE TABLE ledger_checkpoint_partitions (
+    partition_name VARCHAR(63) PRIMARY KEY,
+    users INTEGER NOT NULL,
+    mutation_count BIGINT NOT NULL,
+    folded_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TRIGGER update_ledger_checkpoints_updated_at
+    BEFORE UPDATE ON ledger_checkpoints
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,2 @@
+-- Drop product_tier_prices table
+DROP TABLE IF EXISTS product_tier_prices;
@@ -0,0 +1,20 @@
+-- Create product_tier_prices table (selling prices per product and user level,
+-- between per-user overrides and markup in the pricing precedence)
+CREATE TABLE product_tier_prices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    level INTEGER NOT NULL CHECK (level BETWEEN 1 AND 4), -- 1=RESELLER ... 4=ADMIN
+    price DECIMAL(19, 4) NOT NULL CHECK (price > 0),
+    set_by UUID NOT NULL REFERENCES users(id), -- Admin who set the price
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    UNIQUE (product_id, level)
+);
+
+-- Trigger for updated_at
+CREATE TRIGGER update_product_tier_prices_updated_at
+    BEFORE UPDATE ON product_tier_prices
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
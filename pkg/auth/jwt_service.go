@@ -13,27 +13,38 @@ import (
 
 	"github.com/alfanzaky/eraflazz/config"
 	"github.com/alfanzaky/eraflazz/internal/domain"
+	"github.com/alfanzaky/eraflazz/pkg/logger"
 )
 
 var (
 	ErrInvalidToken     = errors.New("invalid token")
 	ErrExpiredToken     = errors.New("token expired")
 	ErrSignatureInvalid = errors.New("invalid signature")
+	ErrRevokedToken     = errors.New("token revoked")
 )
 
 type customClaims struct {
 	Role string `json:"role"`
+	// Impersonator and ImpersonationSession are set on impersonation tokens
+	Impersonator         string `json:"imp,omitempty"`
+	ImpersonationSession string `json:"imp_sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// impersonationRevocationPrefix keys impersonation sessions in the token
+// revocation store, apart from user IDs
+const impersonationRevocationPrefix = "impersonation:"
+
 // JWTAuthService implements domain.AuthService using JWT + HMAC signature for H2H
 type JWTAuthService struct {
-	cfg config.AuthConfig
+	cfg         config.AuthConfig
+	revocations domain.TokenRevocationRepository
 }
 
-// NewJWTAuthService creates a new auth service instance
-func NewJWTAuthService(cfg config.AuthConfig) *JWTAuthService {
-	return &JWTAuthService{cfg: cfg}
+// NewJWTAuthService creates a new auth service instance. Without a revocation
+// repository tokens stay valid until they expire.
+func NewJWTAuthService(cfg config.AuthConfig, revocations domain.TokenRevocationRepository) *JWTAuthService {
+	return &JWTAuthService{cfg: cfg, revocations: revocations}
 }
 
 func (s *JWTAuthService) accessTTL() time.Duration {
@@ -73,6 +84,40 @@ func (s *JWTAuthService) GenerateAccessToken(user *domain.User) (string, error)
 	return signed, nil
 }
 
+// GenerateImpersonationToken creates a JWT acting as user for the admin of
+// the session. It expires with the session and carries the impersonation
+// claims, which the API uses to restrict and audit it.
+func (s *JWTAuthService) GenerateImpersonationToken(user *domain.User, session *domain.ImpersonationSession) (string, error) {
+	if user == nil || user.ID == "" || session == nil || session.ID == "" {
+		return "", fmt.Errorf("invalid impersonation payload")
+	}
+
+	now := time.Now()
+	claims := &customClaims{
+		Role:                 domain.MapLevelToRole(user.Level),
+		Impersonator:         session.AdminID,
+		ImpersonationSession: session.ID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.ID,
+			Issuer:    s.cfg.Issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(session.ExpiresAt),
+			ID:        session.ID,
+		},
+	}
+	if audience := strings.TrimSpace(s.cfg.Audience); audience != "" {
+		claims.Audience = jwt.ClaimStrings{audience}
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString([]byte(s.cfg.AccessSecret))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return signed, nil
+}
+
 // ValidateToken parses and validates JWT token and returns AuthClaims
 func (s *JWTAuthService) ValidateToken(token string) (*domain.AuthClaims, error) {
 	if token == "" {
@@ -102,6 +147,31 @@ func (s *JWTAuthService) ValidateToken(token string) (*domain.AuthClaims, error)
 		return nil, ErrInvalidToken
 	}
 
+	if s.revocations != nil && claims.IssuedAt != nil {
+		revokedBefore, err := s.revocations.RevokedBefore(claims.Subject)
+		if err != nil {
+			// Fail open so a Redis outage does not log every user out
+			logger.Warn("Failed to check token revocation",
+				logger.String("user_id", claims.Subject),
+				logger.ErrorField(err),
+			)
+		} else if claims.IssuedAt.Time.Before(revokedBefore) {
+			return nil, ErrRevokedToken
+		}
+	}
+
+	// Ended impersonation sessions fail closed: the token grants access to
+	// someone else's account
+	if claims.ImpersonationSession != "" {
+		if s.revocations == nil {
+			return nil, ErrRevokedToken
+		}
+		endedAt, err := s.revocations.RevokedBefore(impersonationRevocationPrefix + claims.ImpersonationSession)
+		if err != nil || !endedAt.IsZero() {
+			return nil, ErrRevokedToken
+		}
+	}
+
 	role := strings.ToUpper(claims.Role)
 	if role == "" {
 		role = domain.RoleReseller
@@ -112,9 +182,34 @@ func (s *JWTAuthService) ValidateToken(token string) (*domain.AuthClaims, error)
 		Role:      role,
 		IssuedAt:  claims.IssuedAt.Time,
 		ExpiresAt: claims.ExpiresAt.Time,
+
+		ImpersonatorID:         claims.Impersonator,
+		ImpersonationSessionID: claims.ImpersonationSession,
 	}, nil
 }
 
+// RevokeUserTokens rejects every token issued to the user before now, e.g.
+// after a password change
+func (s *JWTAuthService) RevokeUserTokens(userID string) error {
+	if s.revocations == nil {
+		return fmt.Errorf("token revocation not configured")
+	}
+	return s.revocations.RevokeBefore(userID, time.Now(), s.accessTTL())
+}
+
+// RevokeImpersonation rejects the token of an impersonation session until it
+// would have expired anyway
+func (s *JWTAuthService) RevokeImpersonation(session *domain.ImpersonationSession) error {
+	if s.revocations == nil {
+		return fmt.Errorf("token revocation not configured")
+	}
+	ttl := time.Until(session.ExpiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.revocations.RevokeBefore(impersonationRevocationPrefix+session.ID, time.Now(), ttl)
+}
+
 // ValidateH2HSignature validates H2H signature using configured secret
 func (s *JWTAuthService) ValidateH2HSignature(apiKey, signature, timestamp string, payload []byte) error {
 	if s.cfg.H2HAPIKey == "" || s.cfg.H2HAPISecret == "" {
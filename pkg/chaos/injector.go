@@ -0,0 +1,177 @@
+// Package chaos injects latency and errors into supplier, Redis and database
+// calls so retry, refund and failover paths can be exercised outside
+// production.
+package chaos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/pkg/metrics"
+)
+
+// Fault targets
+const (
+	TargetSupplier = "supplier"
+	TargetRedis    = "redis"
+	TargetDatabase = "database"
+)
+
+// ErrInjected is returned by calls failed on purpose
+var ErrInjected = errors.New("chaos: injected fault")
+
+// Fault describes what to inject into calls to a target
+type Fault struct {
+	Target    string        `json:"target"`
+	Latency   time.Duration `json:"latency"`    // Added before every matching call
+	ErrorRate float64       `json:"error_rate"` // Share of matching calls failed, 0.0 - 1.0
+	// Scope limits a supplier fault to one supplier code; empty matches all
+	Scope     string     `json:"scope,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// MarshalJSON renders latency as a duration string such as 500ms
+func (f Fault) MarshalJSON() ([]byte, error) {
+	type alias Fault
+	return json.Marshal(struct {
+		alias
+		Latency string `json:"latency"`
+	}{alias: alias(f), Latency: f.Latency.String()})
+}
+
+// IsValidTarget checks if the fault target is valid
+func IsValidTarget(target string) bool {
+	return target == TargetSupplier || target == TargetRedis || target == TargetDatabase
+}
+
+// Validate checks the fault definition
+func (f Fault) Validate() error {
+	if !IsValidTarget(f.Target) {
+		return fmt.Errorf("invalid chaos target")
+	}
+	if f.Latency < 0 {
+		return fmt.Errorf("chaos latency cannot be negative")
+	}
+	if f.ErrorRate < 0 || f.ErrorRate > 1 {
+		return fmt.Errorf("chaos error rate must be between 0 and 1")
+	}
+	return nil
+}
+
+func (f Fault) active(now time.Time) bool {
+	if f.ExpiresAt != nil && !now.Before(*f.ExpiresAt) {
+		return false
+	}
+	return f.Latency > 0 || f.ErrorRate > 0
+}
+
+// Injector holds one fault per target. A nil Injector injects nothing, so
+// callers can keep it unset when chaos is disabled.
+type Injector struct {
+	mu     sync.RWMutex
+	faults map[string]Fault
+}
+
+// NewInjector creates an injector with initial faults; inactive faults are skipped
+func NewInjector(faults ...Fault) (*Injector, error) {
+	injector := &Injector{faults: make(map[string]Fault)}
+	for _, fault := range faults {
+		if !fault.active(time.Now()) {
+			continue
+		}
+		if err := injector.Set(fault); err != nil {
+			return nil, err
+		}
+	}
+	return injector, nil
+}
+
+// Set replaces the fault of its target
+func (i *Injector) Set(fault Fault) error {
+	if i == nil {
+		return fmt.Errorf("chaos injector is disabled")
+	}
+	if err := fault.Validate(); err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.faults[fault.Target] = fault
+	return nil
+}
+
+// Clear removes the fault of a target
+func (i *Injector) Clear(target string) {
+	if i == nil {
+		return
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.faults, target)
+}
+
+// List returns the active faults ordered by target
+func (i *Injector) List() []Fault {
+	if i == nil {
+		return []Fault{}
+	}
+
+	now := time.Now()
+	i.mu.RLock()
+	faults := make([]Fault, 0, len(i.faults))
+	for _, fault := range i.faults {
+		if fault.active(now) {
+			faults = append(faults, fault)
+		}
+	}
+	i.mu.RUnlock()
+
+	sort.Slice(faults, func(a, b int) bool { return faults[a].Target < faults[b].Target })
+	return faults
+}
+
+// Inject applies the fault of a target to one call: it waits the configured
+// latency (or until ctx is done) and then fails the call at the error rate
+func (i *Injector) Inject(ctx context.Context, target, scope string) error {
+	if i == nil {
+		return nil
+	}
+
+	i.mu.RLock()
+	fault, ok := i.faults[target]
+	i.mu.RUnlock()
+
+	if !ok || !fault.active(time.Now()) {
+		return nil
+	}
+	if fault.Scope != "" && !strings.EqualFold(fault.Scope, scope) {
+		return nil
+	}
+
+	if fault.Latency > 0 {
+		metrics.RecordChaosFault(target, "latency")
+		timer := time.NewTimer(fault.Latency)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if fault.ErrorRate > 0 && rand.Float64() < fault.ErrorRate {
+		metrics.RecordChaosFault(target, "error")
+		return fmt.Errorf("%w: %s", ErrInjected, target)
+	}
+
+	return nil
+}
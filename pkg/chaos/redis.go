@@ -0,0 +1,37 @@
+package chaos
+
+import (
+	"context"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisHook injects the redis fault into every command and pipeline
+type RedisHook struct {
+	injector *Injector
+}
+
+// NewRedisHook creates a go-redis hook backed by the injector
+func NewRedisHook(injector *Injector) *RedisHook {
+	return &RedisHook{injector: injector}
+}
+
+// BeforeProcess delays or fails a single command
+func (h *RedisHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return ctx, h.injector.Inject(ctx, TargetRedis, cmd.Name())
+}
+
+// AfterProcess implements redis.Hook
+func (h *RedisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	return nil
+}
+
+// BeforeProcessPipeline delays or fails a whole pipeline once
+func (h *RedisHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return ctx, h.injector.Inject(ctx, TargetRedis, "pipeline")
+}
+
+// AfterProcessPipeline implements redis.Hook
+func (h *RedisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	return nil
+}
@@ -0,0 +1,219 @@
+// Package httpclient builds the HTTP client shared by supplier adapters: a
+// pooled transport with per-attempt timeouts, retries of transient failures
+// and per-host metrics.
+//
+// Only requests marked with WithIdempotent are retried after they may have
+// reached the server. Other requests (purchases) are retried only when the
+// connection could not be established, since a retried purchase could be
+// charged twice.
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+	"github.com/alfanzaky/eraflazz/pkg/metrics"
+)
+
+// Config controls retries, attempt timeouts and connection pooling
+type Config struct {
+	MaxRetries          int           // Extra attempts after the first one
+	AttemptTimeout      time.Duration // Bound of a single attempt; 0 leaves only the request deadline
+	RetryBackoff        time.Duration // Wait before the first retry, doubled on every further retry
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int // 0 means unlimited
+	IdleConnTimeout     time.Duration
+}
+
+// DefaultConfig returns the default client configuration
+func DefaultConfig() Config {
+	return Config{
+		MaxRetries:          2,
+		AttemptTimeout:      10 * time.Second,
+		RetryBackoff:        200 * time.Millisecond,
+		MaxIdleConnsPerHost: 20,
+		MaxConnsPerHost:     100,
+		IdleConnTimeout:     90 * time.Second,
+	}
+}
+
+type idempotentKey struct{}
+
+// WithIdempotent marks requests made with ctx as safe to retry after they
+// may have reached the server, e.g. balance, status and inquiry calls
+func WithIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	marked, _ := req.Context().Value(idempotentKey{}).(bool)
+	return marked
+}
+
+// NewTransport returns a pooled transport. Create one per process and share
+// it between clients so connections to a supplier are reused.
+func NewTransport(cfg Config) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+		if transport.MaxIdleConns < cfg.MaxIdleConnsPerHost {
+			transport.MaxIdleConns = cfg.MaxIdleConnsPerHost
+		}
+	}
+	transport.MaxConnsPerHost = cfg.MaxConnsPerHost
+	if cfg.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = cfg.IdleConnTimeout
+	}
+	return transport
+}
+
+// New returns a client retrying through next, or through a new pooled
+// transport when next is nil. timeout bounds the whole call including retries.
+func New(cfg Config, next http.RoundTripper, timeout time.Duration) *http.Client {
+	if next == nil {
+		next = NewTransport(cfg)
+	}
+	return &http.Client{
+		Transport: &Transport{next: next, config: cfg},
+		Timeout:   timeout,
+	}
+}
+
+// Transport is an http.RoundTripper retrying transient failures of next
+type Transport struct {
+	next   http.RoundTripper
+	config Config
+}
+
+// RoundTrip sends the request, retrying it per the package rules
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	idempotent := isIdempotent(req)
+	// A consumed body can only be sent again when it can be recreated
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	backoff := t.config.RetryBackoff
+
+	for attempt := 0; ; attempt++ {
+		attemptReq, cancel, err := t.attemptRequest(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		resp, err := t.next.RoundTrip(attemptReq)
+		metrics.RecordOutboundHTTPRequest(host, req.Method, attemptStatus(resp, err), time.Since(start).Seconds())
+
+		reason := retryReason(resp, err, idempotent)
+		if reason == "" || !replayable || attempt >= t.config.MaxRetries || req.Context().Err() != nil {
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			// The attempt context must outlive RoundTrip until the body is read
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+		cancel()
+
+		metrics.RecordOutboundHTTPRetry(host, reason)
+		logger.Warn("Retrying outbound HTTP request",
+			logger.String("host", host),
+			logger.String("path", req.URL.Path),
+			logger.Int("attempt", attempt+1),
+			logger.String("reason", reason),
+		)
+
+		select {
+		case <-time.After(backoff):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		backoff *= 2
+	}
+}
+
+// attemptRequest clones req with the attempt timeout and, for retries, a
+// fresh copy of the body
+func (t *Transport) attemptRequest(req *http.Request, attempt int) (*http.Request, context.CancelFunc, error) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if t.config.AttemptTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.config.AttemptTimeout)
+	}
+
+	attemptReq := req.Clone(ctx)
+	if attempt > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, nil, err
+		}
+		attemptReq.Body = body
+	}
+
+	return attemptReq, cancel, nil
+}
+
+// retryReason returns why an attempt should be retried, or "" when it
+// should not. Dial failures never reached the server and are always retried.
+func retryReason(resp *http.Response, err error, idempotent bool) string {
+	if err != nil {
+		var opErr *net.OpError
+		var dnsErr *net.DNSError
+		if (errors.As(err, &opErr) && opErr.Op == "dial") || errors.As(err, &dnsErr) {
+			return "connect"
+		}
+		if !idempotent {
+			return ""
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return "timeout"
+		}
+		return "network"
+	}
+
+	if idempotent {
+		switch resp.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return "status_" + strconv.Itoa(resp.StatusCode)
+		}
+	}
+	return ""
+}
+
+func attemptStatus(resp *http.Response, err error) string {
+	switch {
+	case err == nil:
+		return strconv.Itoa(resp.StatusCode)
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	default:
+		return "error"
+	}
+}
+
+// cancelOnClose releases the attempt context once the body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,298 @@
+// Package lifecycle starts the components of a process in dependency order
+// and stops them in reverse, so a component never outlives what it relies on.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/pkg/logger"
+)
+
+// Component is one part of the process. Every hook is optional.
+type Component struct {
+	Name string
+	// DependsOn names the components started before and stopped after this one
+	DependsOn []string
+	// Start returns once the component is ready; a failure aborts startup
+	Start func(ctx context.Context) error
+	// Run runs in its own goroutine until its context is cancelled
+	Run func(ctx context.Context)
+	// Stop releases the component after Run returned
+	Stop func(ctx context.Context) error
+	// StopTimeout bounds stopping the component; zero uses the app default
+	StopTimeout time.Duration
+}
+
+// Config defines lifecycle defaults
+type Config struct {
+	StopTimeout time.Duration
+}
+
+// DefaultConfig returns default lifecycle configuration
+func DefaultConfig() Config {
+	return Config{StopTimeout: 10 * time.Second}
+}
+
+// App runs registered components. Components on the same level, those whose
+// dependencies are all on lower levels, stop concurrently.
+type App struct {
+	mu         sync.Mutex
+	config     Config
+	components []*entry
+	levels     [][]*entry
+	started    bool
+	stopOnce   sync.Once
+}
+
+type entry struct {
+	Component
+	level   int
+	started bool
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// New creates an app without components
+func New(config Config) *App {
+	if config.StopTimeout <= 0 {
+		config.StopTimeout = DefaultConfig().StopTimeout
+	}
+
+	return &App{config: config}
+}
+
+// Register adds a component. Names and dependencies are checked by Start, so
+// components can be registered in any order.
+func (a *App) Register(component Component) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.components = append(a.components, &entry{Component: component})
+}
+
+// Start starts the components level by level. When one fails, the components
+// already started are stopped again.
+func (a *App) Start(ctx context.Context) error {
+	a.mu.Lock()
+	if a.started {
+		a.mu.Unlock()
+		return fmt.Errorf("lifecycle already started")
+	}
+	levels, err := a.resolve()
+	if err != nil {
+		a.mu.Unlock()
+		return err
+	}
+	a.levels = levels
+	a.started = true
+	a.mu.Unlock()
+
+	for _, level := range levels {
+		for _, e := range level {
+			if err := a.start(ctx, e); err != nil {
+				logger.Error("Component failed to start",
+					logger.String("component", e.Name),
+					logger.ErrorField(err),
+				)
+				a.Shutdown()
+				return fmt.Errorf("failed to start %s: %w", e.Name, err)
+			}
+		}
+	}
+
+	logger.Info("All components started", logger.Int("components", len(a.components)))
+	return nil
+}
+
+func (a *App) start(ctx context.Context, e *entry) error {
+	if e.Start != nil {
+		if err := e.Start(ctx); err != nil {
+			return err
+		}
+	}
+
+	if e.Run != nil {
+		runCtx, cancel := context.WithCancel(context.Background())
+		e.cancel = cancel
+		e.done = make(chan struct{})
+		go func() {
+			defer close(e.done)
+			e.Run(runCtx)
+		}()
+	}
+
+	e.started = true
+	logger.Debug("Component started",
+		logger.String("component", e.Name),
+		logger.Int("level", e.level),
+	)
+	return nil
+}
+
+// Shutdown stops the started components from the highest level down and
+// returns the failures. Only the first call does anything.
+func (a *App) Shutdown() error {
+	var errs []error
+	a.stopOnce.Do(func() {
+		a.mu.Lock()
+		levels := a.levels
+		a.mu.Unlock()
+
+		begin := time.Now()
+		logger.Info("Shutting down components", logger.Int("levels", len(levels)))
+
+		for i := len(levels) - 1; i >= 0; i-- {
+			var (
+				wg sync.WaitGroup
+				mu sync.Mutex
+			)
+			for _, e := range levels[i] {
+				if !e.started {
+					continue
+				}
+				wg.Add(1)
+				go func(e *entry) {
+					defer wg.Done()
+					if err := a.stop(e); err != nil {
+						mu.Lock()
+						errs = append(errs, err)
+						mu.Unlock()
+					}
+				}(e)
+			}
+			wg.Wait()
+		}
+
+		logger.Info("Shutdown complete",
+			logger.Duration("duration", time.Since(begin)),
+			logger.Int("failed", len(errs)),
+		)
+	})
+
+	return errors.Join(errs...)
+}
+
+// stop cancels the component's run, waits for it and calls its Stop hook,
+// giving up once the component's timeout passes
+func (a *App) stop(e *entry) error {
+	timeout := e.StopTimeout
+	if timeout <= 0 {
+		timeout = a.config.StopTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	begin := time.Now()
+	logger.Info("Stopping component", logger.String("component", e.Name))
+
+	stopped := make(chan error, 1)
+	go func() {
+		if e.cancel != nil {
+			e.cancel()
+			<-e.done
+		}
+		if e.Stop != nil {
+			stopped <- e.Stop(ctx)
+			return
+		}
+		stopped <- nil
+	}()
+
+	var err error
+	select {
+	case err = <-stopped:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+
+	if err != nil {
+		logger.Error("Component failed to stop",
+			logger.String("component", e.Name),
+			logger.Duration("duration", time.Since(begin)),
+			logger.ErrorField(err),
+		)
+		return fmt.Errorf("%s: %w", e.Name, err)
+	}
+
+	logger.Info("Component stopped",
+		logger.String("component", e.Name),
+		logger.Duration("duration", time.Since(begin)),
+	)
+	return nil
+}
+
+// resolve checks the components and groups them by level. A component sits
+// one level above its highest dependency; within a level registration order
+// is kept.
+func (a *App) resolve() ([][]*entry, error) {
+	byName := make(map[string]*entry, len(a.components))
+	for _, e := range a.components {
+		if strings.TrimSpace(e.Name) == "" {
+			return nil, fmt.Errorf("component name is required")
+		}
+		if _, exists := byName[e.Name]; exists {
+			return nil, fmt.Errorf("component %s registered twice", e.Name)
+		}
+		byName[e.Name] = e
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(a.components))
+
+	var visit func(e *entry, path []string) error
+	visit = func(e *entry, path []string) error {
+		switch state[e.Name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("component dependency cycle: %s", strings.Join(append(path, e.Name), " -> "))
+		}
+
+		state[e.Name] = visiting
+		e.level = 0
+		for _, name := range e.DependsOn {
+			dependency, ok := byName[name]
+			if !ok {
+				return fmt.Errorf("component %s depends on unknown component %s", e.Name, name)
+			}
+			if err := visit(dependency, append(path, e.Name)); err != nil {
+				return err
+			}
+			if dependency.level+1 > e.level {
+				e.level = dependency.level + 1
+			}
+		}
+		state[e.Name] = visited
+		return nil
+	}
+
+	for _, e := range a.components {
+		if err := visit(e, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	ordered := make([]*entry, len(a.components))
+	copy(ordered, a.components)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].level < ordered[j].level })
+
+	levels := make([][]*entry, 0)
+	for _, e := range ordered {
+		if len(levels) <= e.level {
+			levels = append(levels, nil)
+		}
+		levels[e.level] = append(levels[e.level], e)
+	}
+
+	return levels, nil
+}
@@ -0,0 +1,30 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type contextKey struct{}
+
+// WithContext returns a copy of ctx whose logger also carries fields, so every
+// line logged through FromContext for the same request or transaction can be
+// correlated
+func WithContext(ctx context.Context, fields ...zap.Field) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, contextKey{}, FromContext(ctx).With(fields...))
+}
+
+// FromContext returns the logger stored in ctx by WithContext, or the global
+// logger when there is none
+func FromContext(ctx context.Context) *zap.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKey{}).(*zap.Logger); ok {
+			return l
+		}
+	}
+	return GetLogger()
+}
@@ -34,8 +34,15 @@ func Init(env string) {
 		config.OutputPaths = []string{"stdout"}
 		config.ErrorOutputPaths = []string{"stderr"}
 		
+		// The core writes every level; tieredCore applies the default and
+		// per component levels, which can change at runtime
+		tiers.Store(&tierSettings{level: config.Level.Level(), components: tiers.Load().components})
+		config.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
+		
 		var err error
-		instance, err = config.Build()
+		instance, err = config.Build(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return &tieredCore{Core: core}
+		}))
 		if err != nil {
 			panic(err)
 		}
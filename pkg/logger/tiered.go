@@ -0,0 +1,275 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Components with their own level and sampling
+const (
+	ComponentRouting     = "routing"
+	ComponentWorker      = "worker"
+	ComponentAuth        = "auth"
+	ComponentTransaction = "transaction"
+)
+
+// ComponentSettings tunes the logs of one component. An empty Level follows
+// the default level. SampleRate (0-1) keeps that share of Debug and Info
+// entries; Warn and above are always kept, and 0 or 1 disables sampling.
+type ComponentSettings struct {
+	Level      string  `json:"level,omitempty"`
+	SampleRate float64 `json:"sample_rate"`
+}
+
+// LevelSettings is the default level and the per component settings in force
+type LevelSettings struct {
+	Level      string                       `json:"level"`
+	Components map[string]ComponentSettings `json:"components"`
+}
+
+type componentTier struct {
+	level      zapcore.Level
+	hasLevel   bool
+	sampleRate float64
+}
+
+type tierSettings struct {
+	level      zapcore.Level
+	components map[string]componentTier
+}
+
+var (
+	tiers      atomic.Pointer[tierSettings]
+	tiersMu    sync.Mutex
+	components sync.Map // Component name to its *zap.Logger
+)
+
+func init() {
+	tiers.Store(&tierSettings{level: zapcore.InfoLevel, components: map[string]componentTier{}})
+}
+
+// tieredCore filters entries by the level and sample rate of its component
+// before handing them to the wrapped core
+type tieredCore struct {
+	zapcore.Core
+	component string
+}
+
+func (c *tieredCore) tier() (zapcore.Level, float64) {
+	settings := tiers.Load()
+	if tier, ok := settings.components[c.component]; ok {
+		if tier.hasLevel {
+			return tier.level, tier.sampleRate
+		}
+		return settings.level, tier.sampleRate
+	}
+	return settings.level, 0
+}
+
+func (c *tieredCore) Enabled(level zapcore.Level) bool {
+	minLevel, _ := c.tier()
+	return level >= minLevel
+}
+
+func (c *tieredCore) With(fields []zapcore.Field) zapcore.Core {
+	return &tieredCore{Core: c.Core.With(fields), component: c.component}
+}
+
+func (c *tieredCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	minLevel, sampleRate := c.tier()
+	if entry.Level < minLevel {
+		return checked
+	}
+	if entry.Level < zapcore.WarnLevel && sampleRate > 0 && sampleRate < 1 && rand.Float64() >= sampleRate {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
+
+// withComponent tags a logger with a component; loggers not built by Init
+// are returned unchanged
+func withComponent(l *zap.Logger, name string) *zap.Logger {
+	return l.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		tiered, ok := core.(*tieredCore)
+		if !ok || tiered.component == name {
+			return core
+		}
+		return &tieredCore{Core: tiered.Core.With([]zapcore.Field{zap.String("component", name)}), component: name}
+	}))
+}
+
+// Component returns the logger of a component, filtered by its level and
+// sample rate. Call it after Init.
+func Component(name string) *zap.Logger {
+	if l, ok := components.Load(name); ok {
+		return l.(*zap.Logger)
+	}
+	l, _ := components.LoadOrStore(name, withComponent(GetLogger(), name))
+	return l.(*zap.Logger)
+}
+
+// WithComponent returns a copy of ctx whose logger belongs to a component, so
+// FromContext applies the component's level and sample rate
+func WithComponent(ctx context.Context, name string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, contextKey{}, withComponent(FromContext(ctx), name))
+}
+
+// Configure sets the default level ("" keeps it) and replaces the per
+// component settings
+func Configure(level string, settings map[string]ComponentSettings) error {
+	tiersMu.Lock()
+	defer tiersMu.Unlock()
+
+	next := &tierSettings{level: tiers.Load().level, components: make(map[string]componentTier, len(settings))}
+	if level != "" {
+		parsed, err := parseLevel(level)
+		if err != nil {
+			return err
+		}
+		next.level = parsed
+	}
+	for name, setting := range settings {
+		tier, err := newComponentTier(setting)
+		if err != nil {
+			return fmt.Errorf("component %s: %w", name, err)
+		}
+		next.components[name] = tier
+	}
+
+	tiers.Store(next)
+	return nil
+}
+
+// SetLevel changes the default level at runtime
+func SetLevel(level string) error {
+	parsed, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	tiersMu.Lock()
+	defer tiersMu.Unlock()
+	current := tiers.Load()
+	tiers.Store(&tierSettings{level: parsed, components: current.components})
+	return nil
+}
+
+// SetComponent changes the settings of a component at runtime
+func SetComponent(name string, settings ComponentSettings) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("component is required")
+	}
+	tier, err := newComponentTier(settings)
+	if err != nil {
+		return err
+	}
+
+	tiersMu.Lock()
+	defer tiersMu.Unlock()
+	current := tiers.Load()
+	next := &tierSettings{level: current.level, components: make(map[string]componentTier, len(current.components)+1)}
+	for key, value := range current.components {
+		next.components[key] = value
+	}
+	next.components[name] = tier
+	tiers.Store(next)
+	return nil
+}
+
+// ResetComponent makes a component follow the default level again, unsampled
+func ResetComponent(name string) {
+	tiersMu.Lock()
+	defer tiersMu.Unlock()
+	current := tiers.Load()
+	next := &tierSettings{level: current.level, components: make(map[string]componentTier, len(current.components))}
+	for key, value := range current.components {
+		if key != name {
+			next.components[key] = value
+		}
+	}
+	tiers.Store(next)
+}
+
+// Levels returns the settings in force
+func Levels() LevelSettings {
+	current := tiers.Load()
+	settings := LevelSettings{
+		Level:      current.level.String(),
+		Components: make(map[string]ComponentSettings, len(current.components)),
+	}
+	for name, tier := range current.components {
+		setting := ComponentSettings{SampleRate: tier.sampleRate}
+		if tier.hasLevel {
+			setting.Level = tier.level.String()
+		}
+		settings.Components[name] = setting
+	}
+	return settings
+}
+
+// ParseComponents parses name=level[:sample_rate] pairs separated by commas,
+// e.g. "routing=debug:0.01,worker=info,auth=warn"
+func ParseComponents(spec string) (map[string]ComponentSettings, error) {
+	settings := make(map[string]ComponentSettings)
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, found := strings.Cut(pair, "=")
+		if !found || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid component setting %q", pair)
+		}
+
+		level, rate, hasRate := strings.Cut(value, ":")
+		setting := ComponentSettings{Level: strings.TrimSpace(level)}
+		if hasRate {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(rate), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid sample rate in %q", pair)
+			}
+			setting.SampleRate = parsed
+		}
+		if _, err := newComponentTier(setting); err != nil {
+			return nil, fmt.Errorf("component %s: %w", strings.TrimSpace(name), err)
+		}
+		settings[strings.TrimSpace(name)] = setting
+	}
+	return settings, nil
+}
+
+func newComponentTier(settings ComponentSettings) (componentTier, error) {
+	if settings.SampleRate < 0 || settings.SampleRate > 1 {
+		return componentTier{}, fmt.Errorf("sample rate must be between 0 and 1")
+	}
+	tier := componentTier{sampleRate: settings.SampleRate}
+	if settings.Level != "" {
+		level, err := parseLevel(settings.Level)
+		if err != nil {
+			return componentTier{}, err
+		}
+		tier.level = level
+		tier.hasLevel = true
+	}
+	return tier, nil
+}
+
+func parseLevel(level string) (zapcore.Level, error) {
+	parsed, err := zapcore.ParseLevel(strings.ToLower(strings.TrimSpace(level)))
+	if err != nil || parsed > zapcore.ErrorLevel {
+		return zapcore.InfoLevel, fmt.Errorf("invalid log level %q", level)
+	}
+	return parsed, nil
+}
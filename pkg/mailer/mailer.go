@@ -0,0 +1,273 @@
+// Package mailer sends plain text email over SMTP with STARTTLS or implicit
+// TLS, authentication and retries of transient failures.
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Connection security modes
+const (
+	EncryptionSTARTTLS = "starttls" // Plain connection upgraded with STARTTLS (port 587)
+	EncryptionTLS      = "tls"      // Implicit TLS (port 465)
+	EncryptionNone     = "none"     // Unencrypted, for local relays only
+)
+
+// Config defines the SMTP server and delivery behaviour
+type Config struct {
+	Host       string
+	Port       int
+	Username   string // Empty disables authentication
+	Password   string
+	From       string
+	FromName   string
+	Encryption string        // starttls (default), tls or none
+	Timeout    time.Duration // Per attempt, covering dial and the whole SMTP exchange
+	MaxRetries int           // Additional attempts after a transient failure
+	RetryDelay time.Duration // Doubles after every failed attempt
+}
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	ToName  string
+	Subject string
+	Body    string
+}
+
+// PermanentError is a delivery failure that will not succeed on retry, such
+// as a rejected recipient or an invalid address
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string { return e.Err.Error() }
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+// Permanent reports that retrying the delivery is pointless
+func (e *PermanentError) Permanent() bool { return true }
+
+// Mailer delivers messages through one SMTP server
+type Mailer struct {
+	config Config
+	from   mail.Address
+}
+
+// New validates the configuration and builds a mailer
+func New(config Config) (*Mailer, error) {
+	if config.Host == "" || config.Port <= 0 {
+		return nil, fmt.Errorf("smtp host and port are required")
+	}
+
+	from, err := mail.ParseAddress(config.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid smtp from address: %w", err)
+	}
+	from.Name = config.FromName
+
+	config.Encryption = strings.ToLower(strings.TrimSpace(config.Encryption))
+	switch config.Encryption {
+	case "":
+		config.Encryption = EncryptionSTARTTLS
+	case EncryptionSTARTTLS, EncryptionTLS, EncryptionNone:
+	default:
+		return nil, fmt.Errorf("unknown smtp encryption %q", config.Encryption)
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = time.Second
+	}
+
+	return &Mailer{config: config, from: *from}, nil
+}
+
+// Send delivers a message and returns its Message-ID. Transient failures are
+// retried; a *PermanentError is returned when the server rejects the message.
+func (m *Mailer) Send(ctx context.Context, msg *Message) (string, error) {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return "", &PermanentError{Err: fmt.Errorf("invalid recipient address: %w", err)}
+	}
+	to.Name = msg.ToName
+
+	messageID := m.messageID()
+	data, err := m.compose(to, msg, messageID)
+	if err != nil {
+		return "", &PermanentError{Err: err}
+	}
+
+	delay := m.config.RetryDelay
+	for attempt := 0; ; attempt++ {
+		err = m.deliver(ctx, to.Address, data)
+		if err == nil {
+			return messageID, nil
+		}
+		if isPermanent(err) || attempt >= m.config.MaxRetries {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+
+	if isPermanent(err) {
+		return "", &PermanentError{Err: err}
+	}
+	return "", err
+}
+
+// deliver runs one SMTP session
+func (m *Mailer) deliver(ctx context.Context, to string, data []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, m.config.Timeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(m.config.Host, strconv.Itoa(m.config.Port))
+	dialer := &net.Dialer{}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if m.config.Encryption == EncryptionTLS {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: m.tlsConfig()}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	defer conn.Close()
+
+	// The deadline bounds every read and write of the session
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		return fmt.Errorf("failed to start smtp session: %w", err)
+	}
+	defer client.Close()
+
+	if m.config.Encryption == EncryptionSTARTTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return &PermanentError{Err: fmt.Errorf("smtp server does not support STARTTLS")}
+		}
+		if err := client.StartTLS(m.tlsConfig()); err != nil {
+			return fmt.Errorf("failed to start tls: %w", err)
+		}
+	}
+
+	if m.config.Username != "" {
+		auth := smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("smtp authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(m.from.Address); err != nil {
+		return fmt.Errorf("smtp MAIL FROM rejected: %w", err)
+	}
+	if err := client.Rcpt(to); err != nil {
+		return fmt.Errorf("smtp RCPT TO rejected: %w", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA rejected: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp message rejected: %w", err)
+	}
+
+	return client.Quit()
+}
+
+func (m *Mailer) tlsConfig() *tls.Config {
+	return &tls.Config{ServerName: m.config.Host, MinVersion: tls.VersionTLS12}
+}
+
+// compose renders headers and a quoted-printable UTF-8 body
+func (m *Mailer) compose(to *mail.Address, msg *Message, messageID string) ([]byte, error) {
+	var buf bytes.Buffer
+	headers := []struct{ key, value string }{
+		{"From", m.from.String()},
+		{"To", to.String()},
+		{"Subject", mime.QEncoding.Encode("utf-8", msg.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", messageID},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "text/plain; charset=UTF-8"},
+		{"Content-Transfer-Encoding", "quoted-printable"},
+	}
+	for _, h := range headers {
+		buf.WriteString(h.key + ": " + h.value + "\r\n")
+	}
+	buf.WriteString("\r\n")
+
+	body := strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n")
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return nil, fmt.Errorf("failed to encode message body: %w", err)
+	}
+	if err := qp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode message body: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (m *Mailer) messageID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	domain := "localhost"
+	if at := strings.LastIndex(m.from.Address, "@"); at >= 0 {
+		domain = m.from.Address[at+1:]
+	}
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
+
+// isPermanent reports whether the error is a 5xx SMTP reply or was already
+// classified as permanent
+func isPermanent(err error) bool {
+	var permanent *PermanentError
+	if errors.As(err, &permanent) {
+		return true
+	}
+
+	var reply *textproto.Error
+	if errors.As(err, &reply) {
+		return reply.Code >= 500
+	}
+	return false
+}
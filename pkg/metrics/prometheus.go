@@ -84,6 +84,14 @@ var (
 		[]string{"queue_name"},
 	)
 
+	transactionIntakeMode = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "transaction_intake_mode",
+			Help: "Current transaction intake mode driven by the queue backlog (1 for the active mode)",
+		},
+		[]string{"mode"},
+	)
+
 	queueProcessingDuration = promauto.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    "queue_processing_duration_seconds",
@@ -93,6 +101,48 @@ var (
 		[]string{"queue_name", "status"},
 	)
 
+	transactionStageDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "transaction_stage_duration_seconds",
+			Help:    "Duration of the stages of transaction processing (validation, debit, routing, supplier_call, persistence) in seconds",
+			Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+		},
+		[]string{"stage"},
+	)
+
+	// Mutation webhook metrics
+	mutationWebhookDeliveriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mutation_webhook_deliveries_total",
+			Help: "Total number of mutation webhook delivery attempts by outcome (delivered, retry, failed)",
+		},
+		[]string{"status"},
+	)
+
+	mutationWebhookMutationsTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "mutation_webhook_mutations_total",
+			Help: "Total number of queued mutations batched into mutation webhook deliveries",
+		},
+	)
+
+	// Transaction lock metrics
+	transactionLocksTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "transaction_locks_total",
+			Help: "Total number of transaction processing lock attempts by result (acquired, contended, lost, error)",
+		},
+		[]string{"result"},
+	)
+
+	transactionLockHoldDuration = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "transaction_lock_hold_duration_seconds",
+			Help:    "How long transaction processing locks are held in seconds",
+			Buckets: []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120},
+		},
+	)
+
 	// Supplier adapter metrics
 	supplierRequestsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -111,6 +161,40 @@ var (
 		[]string{"supplier", "operation"},
 	)
 
+	supplierWebhookRejectionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "supplier_webhook_rejections_total",
+			Help: "Total number of supplier webhooks rejected before processing",
+		},
+		[]string{"supplier", "reason"},
+	)
+
+	// Outbound HTTP metrics (supplier adapter calls, per host and attempt)
+	outboundHTTPRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbound_http_requests_total",
+			Help: "Total number of outbound HTTP attempts",
+		},
+		[]string{"host", "method", "status"},
+	)
+
+	outboundHTTPRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "outbound_http_request_duration_seconds",
+			Help:    "Outbound HTTP attempt duration in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"host"},
+	)
+
+	outboundHTTPRetriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbound_http_retries_total",
+			Help: "Total number of outbound HTTP attempts retried",
+		},
+		[]string{"host", "reason"},
+	)
+
 	// Authentication metrics
 	authAttemptsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -120,6 +204,113 @@ var (
 		[]string{"method", "status"},
 	)
 
+	accessDenialsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "access_denials_total",
+			Help: "Total number of requests denied by role guards",
+		},
+		[]string{"reason", "requirement"},
+	)
+
+	h2hReplayRejectionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "h2h_replay_rejections_total",
+			Help: "Total number of H2H requests rejected because their signature was already used",
+		},
+		[]string{"client_id"},
+	)
+
+	// Scheduled job metrics
+	scheduledJobRunsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "scheduled_job_runs_total",
+			Help: "Total number of scheduled job activations",
+		},
+		[]string{"job", "status"},
+	)
+
+	scheduledJobDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "scheduled_job_duration_seconds",
+			Help:    "Scheduled job run duration in seconds",
+			Buckets: []float64{0.1, 0.5, 1, 5, 15, 30, 60, 300, 900},
+		},
+		[]string{"job"},
+	)
+
+	// Anomaly metrics (1 while an anomaly is active, removed when it clears)
+	transactionAnomaly = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "transaction_anomaly",
+			Help: "Active transaction anomalies per supplier and product (1 = active)",
+		},
+		[]string{"kind", "component", "supplier", "product"},
+	)
+
+	// Refund SLA metrics
+	refundsStuck = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "refunds_stuck",
+			Help: "Failed transactions waiting longer than the stuck threshold for their balance to be released or refunded",
+		},
+	)
+
+	refundsStuckAmount = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "refunds_stuck_amount",
+			Help: "Sum of the amounts of stuck refunds",
+		},
+	)
+
+	refundOldestStuckSeconds = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "refund_oldest_stuck_seconds",
+			Help: "Seconds since the failure of the oldest stuck refund (0 = none)",
+		},
+	)
+
+	// Reconciliation metrics
+	balanceMismatchesOpen = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "balance_mismatches_open",
+			Help: "Users whose stored balance disagreed with the mutation ledger in the last reconciliation",
+		},
+	)
+
+	balanceMismatchDifference = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "balance_mismatch_difference",
+			Help: "Sum of stored minus ledger balance over the mismatches of the last reconciliation",
+		},
+	)
+
+	// Fault injection metrics (non-production resilience testing)
+	chaosFaultsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "chaos_faults_injected_total",
+			Help: "Total number of latency and error faults injected on purpose",
+		},
+		[]string{"target", "kind"},
+	)
+
+	// Event hook metrics
+	eventHookCallsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "event_hook_calls_total",
+			Help: "Total number of plugin event hook calls by outcome",
+		},
+		[]string{"hook", "event_type", "status"},
+	)
+
+	eventHookDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "event_hook_duration_seconds",
+			Help:    "Plugin event hook call duration in seconds",
+			Buckets: []float64{0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"hook"},
+	)
+
 	// Application metrics
 	activeUsers = promauto.NewGauge(
 		prometheus.GaugeOpts{
@@ -149,6 +340,11 @@ func RecordTransaction(status, productCategory, userRole string, amount float64)
 	transactionAmount.WithLabelValues(productCategory, userRole).Observe(amount)
 }
 
+// RecordTransactionStage observes the duration of one processing stage of a transaction
+func RecordTransactionStage(stage string, duration float64) {
+	transactionStageDuration.WithLabelValues(stage).Observe(duration)
+}
+
 // Database Metrics
 func SetDBConnectionsActive(count float64) {
 	dbConnectionsActive.Set(count)
@@ -172,21 +368,113 @@ func SetQueueSize(queueName string, size float64) {
 	queueSize.WithLabelValues(queueName).Set(size)
 }
 
+// SetTransactionIntakeMode marks mode as the active intake mode
+func SetTransactionIntakeMode(mode string) {
+	for _, m := range []string{"NORMAL", "DEGRADED", "SHEDDING"} {
+		value := 0.0
+		if m == mode {
+			value = 1
+		}
+		transactionIntakeMode.WithLabelValues(m).Set(value)
+	}
+}
+
 func RecordQueueProcessing(queueName, status string, duration float64) {
 	queueProcessingDuration.WithLabelValues(queueName, status).Observe(duration)
 }
 
+// Mutation Webhook Metrics
+func RecordMutationWebhookDelivery(status string) {
+	mutationWebhookDeliveriesTotal.WithLabelValues(status).Inc()
+}
+
+func RecordMutationWebhookBatched(count int) {
+	mutationWebhookMutationsTotal.Add(float64(count))
+}
+
+// Transaction Lock Metrics
+func RecordTransactionLock(result string) {
+	transactionLocksTotal.WithLabelValues(result).Inc()
+}
+
+func RecordTransactionLockHold(duration float64) {
+	transactionLockHoldDuration.Observe(duration)
+}
+
 // Supplier Metrics
 func RecordSupplierRequest(supplier, operation, status string, duration float64) {
 	supplierRequestsTotal.WithLabelValues(supplier, operation, status).Inc()
 	supplierRequestDuration.WithLabelValues(supplier, operation).Observe(duration)
 }
 
+func RecordSupplierWebhookRejection(supplier, reason string) {
+	supplierWebhookRejectionsTotal.WithLabelValues(supplier, reason).Inc()
+}
+
+// Outbound HTTP Metrics
+func RecordOutboundHTTPRequest(host, method, status string, duration float64) {
+	outboundHTTPRequestsTotal.WithLabelValues(host, method, status).Inc()
+	outboundHTTPRequestDuration.WithLabelValues(host).Observe(duration)
+}
+
+func RecordOutboundHTTPRetry(host, reason string) {
+	outboundHTTPRetriesTotal.WithLabelValues(host, reason).Inc()
+}
+
 // Authentication Metrics
 func RecordAuthAttempt(method, status string) {
 	authAttemptsTotal.WithLabelValues(method, status).Inc()
 }
 
+func RecordAccessDenial(reason, requirement string) {
+	accessDenialsTotal.WithLabelValues(reason, requirement).Inc()
+}
+
+func RecordH2HReplayRejection(clientID string) {
+	h2hReplayRejectionsTotal.WithLabelValues(clientID).Inc()
+}
+
+// Scheduled Job Metrics
+func RecordScheduledJob(job, status string, duration float64) {
+	scheduledJobRunsTotal.WithLabelValues(job, status).Inc()
+	if status != "SKIPPED" {
+		scheduledJobDuration.WithLabelValues(job).Observe(duration)
+	}
+}
+
+// Anomaly Metrics
+func SetTransactionAnomaly(kind, component, supplier, product string, active bool) {
+	if active {
+		transactionAnomaly.WithLabelValues(kind, component, supplier, product).Set(1)
+		return
+	}
+	transactionAnomaly.DeleteLabelValues(kind, component, supplier, product)
+}
+
+// Refund SLA Metrics
+func SetStuckRefunds(count int, amount, oldestSeconds float64) {
+	refundsStuck.Set(float64(count))
+	refundsStuckAmount.Set(amount)
+	refundOldestStuckSeconds.Set(oldestSeconds)
+}
+
+// Reconciliation Metrics
+func SetBalanceMismatches(count int, difference float64) {
+	balanceMismatchesOpen.Set(float64(count))
+	balanceMismatchDifference.Set(difference)
+}
+
+// Fault Injection Metrics
+func RecordChaosFault(target, kind string) {
+	chaosFaultsTotal.WithLabelValues(target, kind).Inc()
+}
+
+// Event Hook Metrics
+func RecordEventHook(hook, eventType, status string, duration float64) {
+	eventHookCallsTotal.WithLabelValues(hook, eventType, status).Inc()
+	eventHookDuration.WithLabelValues(hook).Observe(duration)
+}
+
 // Application Metrics
 func SetActiveUsers(count float64) {
 	activeUsers.Set(count)
@@ -37,8 +37,10 @@ func ObservabilityMiddleware() gin.HandlerFunc {
 		c.Header(TraceIDHeader, traceID)
 		c.Set(string(TraceIDContextKey), traceID)
 
-		// Add trace ID to logger context
-		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), TraceIDContextKey, traceID))
+		// Add trace ID to the request context and its logger, so every line
+		// logged through logger.FromContext carries it
+		ctx := context.WithValue(c.Request.Context(), TraceIDContextKey, traceID)
+		c.Request = c.Request.WithContext(logger.WithContext(ctx, logger.String("trace_id", traceID)))
 
 		// Get user role from context if available
 		userRole := "anonymous"
@@ -67,9 +69,9 @@ func ObservabilityMiddleware() gin.HandlerFunc {
 			duration,
 		)
 
-		// Log request completion with trace ID
-		logger.Info("Request completed",
-			logger.String("trace_id", traceID),
+		// Log request completion; auth middleware may have added the user to
+		// the request logger by now
+		logger.FromContext(c.Request.Context()).Info("Request completed",
 			logger.String("method", c.Request.Method),
 			logger.String("path", c.Request.URL.Path),
 			logger.String("status", statusCode),
@@ -110,37 +112,31 @@ func WithTraceID(ctx context.Context, traceID string) context.Context {
 	return context.WithValue(ctx, TraceIDContextKey, traceID)
 }
 
-// LogWithError logs error with trace ID
+// LogWithError logs error with the request logger
 func LogWithError(c *gin.Context, err error, message string) {
-	traceID := GetTraceID(c)
-	logger.Error(message,
-		logger.String("trace_id", traceID),
+	logger.FromContext(c.Request.Context()).Error(message,
+		logger.ErrorField(err),
 	)
 }
 
-// LogWithFields logs with trace ID and custom fields
+// LogWithFields logs with the request logger and custom fields
 func LogWithFields(c *gin.Context, message string, fields ...zap.Field) {
-	traceID := GetTraceID(c)
 	allFields := append([]zap.Field{
-		zap.String("trace_id", traceID),
 		zap.String("method", c.Request.Method),
 		zap.String("path", c.Request.URL.Path),
 		zap.String("client_ip", c.ClientIP()),
 	}, fields...)
 
-	logger.Info(message, allFields...)
+	logger.FromContext(c.Request.Context()).Info(message, allFields...)
 }
 
 // RecordSystemError records system error with metrics and logging
 func RecordSystemError(c *gin.Context, errorType, component string, err error) {
-	traceID := GetTraceID(c)
-
 	// Record metrics
 	metrics.RecordSystemError(errorType, component)
 
-	// Log with trace ID
-	logger.Error("System error occurred",
-		logger.String("trace_id", traceID),
+	// Log with the request logger
+	logger.FromContext(c.Request.Context()).Error("System error occurred",
 		logger.String("error_type", errorType),
 		logger.String("component", component),
 		logger.ErrorField(err),
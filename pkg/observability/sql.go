@@ -0,0 +1,153 @@
+package observability
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"time"
+
+	"github.com/alfanzaky/eraflazz/pkg/metrics"
+)
+
+// WrapConnector returns a connector whose connections record every query and
+// exec in db_query_duration_seconds, labelled with the SQL operation and the
+// table it targets. Statements prepared explicitly are not timed.
+func WrapConnector(connector driver.Connector) driver.Connector {
+	return &metricsConnector{Connector: connector}
+}
+
+type metricsConnector struct {
+	driver.Connector
+}
+
+func (c *metricsConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &metricsConn{conn: conn}, nil
+}
+
+// metricsConn forwards to the wrapped connection. Optional interfaces it does
+// not implement report driver.ErrSkip so database/sql falls back correctly.
+type metricsConn struct {
+	conn driver.Conn
+}
+
+func (c *metricsConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *metricsConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.conn.Prepare(query)
+}
+
+func (c *metricsConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *metricsConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *metricsConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.conn.Begin() //nolint:staticcheck // Fallback for drivers without BeginTx
+}
+
+func (c *metricsConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	defer recordQuery(query, time.Now())
+	return queryer.QueryContext(ctx, query, args)
+}
+
+func (c *metricsConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	defer recordQuery(query, time.Now())
+	return execer.ExecContext(ctx, query, args)
+}
+
+func (c *metricsConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *metricsConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func (c *metricsConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *metricsConn) IsValid() bool {
+	if validator, ok := c.conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func recordQuery(query string, start time.Time) {
+	operation, table := queryLabels(query)
+	metrics.RecordDBQuery(operation, table, time.Since(start).Seconds())
+}
+
+// tableKeywords maps each operation to the keyword preceding its target table
+var tableKeywords = map[string]string{
+	"SELECT": "FROM",
+	"WITH":   "FROM",
+	"INSERT": "INTO",
+	"UPDATE": "UPDATE",
+	"DELETE": "FROM",
+}
+
+// queryLabels derives the operation and target table of a statement. The
+// table of a SELECT or CTE is the first one read; statements without a plain
+// table are labelled "none" to keep the label set small.
+func queryLabels(query string) (operation, table string) {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "OTHER", "none"
+	}
+
+	operation = strings.ToUpper(strings.TrimLeft(fields[0], "("))
+	keyword, ok := tableKeywords[operation]
+	if !ok {
+		return "OTHER", "none"
+	}
+
+	for i, field := range fields {
+		if !strings.EqualFold(field, keyword) || i+1 >= len(fields) {
+			continue
+		}
+		name := strings.ToLower(strings.Trim(fields[i+1], `",;)`))
+		if name == "" || strings.HasPrefix(name, "(") {
+			break
+		}
+		if paren := strings.IndexByte(name, '('); paren > 0 {
+			name = name[:paren] // INSERT INTO table(columns)
+		}
+		return operation, strings.TrimPrefix(name, "public.")
+	}
+
+	return operation, "none"
+}
@@ -0,0 +1,164 @@
+// Package pdf writes simple text-only PDF documents using the standard
+// Helvetica and Courier fonts, so no font files or external libraries are needed.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 portrait page size and layout in points
+const (
+	PageWidth  = 595.0
+	PageHeight = 842.0
+	Margin     = 40.0
+	footerSize = 8.0
+)
+
+// Font identifies one of the built-in fonts registered in every document
+type Font string
+
+// Built-in fonts
+const (
+	FontRegular Font = "F1" // Helvetica
+	FontBold    Font = "F2" // Helvetica-Bold
+	FontMono    Font = "F3" // Courier, for aligned table rows
+)
+
+var fontNames = []struct {
+	key  Font
+	name string
+}{
+	{FontRegular, "Helvetica"},
+	{FontBold, "Helvetica-Bold"},
+	{FontMono, "Courier"},
+}
+
+// Document accumulates lines of text and breaks pages automatically
+type Document struct {
+	pages  []*bytes.Buffer
+	y      float64
+	footer string
+}
+
+// NewDocument creates an empty document. When footer is not empty it is
+// printed on every page followed by the page number.
+func NewDocument(footer string) *Document {
+	d := &Document{footer: footer}
+	d.AddPage()
+	return d
+}
+
+// AddPage starts a new page
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = PageHeight - Margin
+}
+
+// Line writes one line of text, starting a new page when the current one is full
+func (d *Document) Line(font Font, size float64, text string) {
+	lineHeight := size * 1.4
+	if d.y-lineHeight < Margin+footerSize*2 {
+		d.AddPage()
+	}
+	d.y -= lineHeight
+	d.pages[len(d.pages)-1].WriteString(textOp(font, size, Margin, d.y, text))
+}
+
+// Space adds vertical space
+func (d *Document) Space(height float64) {
+	d.y -= height
+}
+
+// MonoColumns is how many Courier characters fit on one line at size
+func MonoColumns(size float64) int {
+	// Courier glyphs are 600/1000 em wide
+	return int((PageWidth - 2*Margin) / (size * 0.6))
+}
+
+// Bytes renders the document
+func (d *Document) Bytes() []byte {
+	total := len(d.pages)
+
+	// Object layout: 1 catalog, 2 page tree, 3..5 fonts, then a page and a
+	// content stream object per page
+	fontObj := 3
+	firstPageObj := fontObj + len(fontNames)
+	objects := make([]string, 0, firstPageObj+2*total)
+
+	kids := make([]string, total)
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPageObj+2*i)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), total),
+	)
+
+	fontRefs := make([]string, len(fontNames))
+	for i, f := range fontNames {
+		objects = append(objects, fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", f.name))
+		fontRefs[i] = fmt.Sprintf("/%s %d 0 R", f.key, fontObj+i)
+	}
+
+	for i, page := range d.pages {
+		content := page.String()
+		if d.footer != "" || total > 1 {
+			footer := fmt.Sprintf("Halaman %d / %d", i+1, total)
+			if d.footer != "" {
+				footer = d.footer + " - " + footer
+			}
+			content += textOp(FontRegular, footerSize, Margin, Margin, footer)
+		}
+
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << %s >> >> /Contents %d 0 R >>",
+				PageWidth, PageHeight, strings.Join(fontRefs, " "), firstPageObj+2*i+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content),
+		)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return out.Bytes()
+}
+
+func textOp(font Font, size, x, y float64, text string) string {
+	return fmt.Sprintf("BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, escape(text))
+}
+
+// escape encodes text as a WinAnsi PDF string literal; characters outside
+// Latin-1 are replaced with '?'
+func escape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 32 && r < 127:
+			b.WriteRune(r)
+		case r >= 160 && r <= 255:
+			fmt.Fprintf(&b, "\\%03o", r)
+		case r == '\t':
+			b.WriteString("    ")
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,91 @@
+// Package secretbox encrypts short secrets stored in the database, such as
+// voucher codes, with AES-256-GCM. It also derives a keyed hash of each
+// secret so duplicates can be found without decrypting anything.
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// KeySize is the size of the master key in bytes
+const KeySize = 32
+
+// Box seals and opens secrets with one master key
+type Box struct {
+	aead    cipher.AEAD
+	hashKey []byte
+}
+
+// New builds a box from a base64 encoded 32 byte master key. Separate keys
+// for encryption and hashing are derived from it.
+func New(key string) (*Box, error) {
+	master, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("secret key must be base64 encoded: %w", err)
+	}
+	if len(master) != KeySize {
+		return nil, fmt.Errorf("secret key must be %d bytes, got %d", KeySize, len(master))
+	}
+
+	block, err := aes.NewCipher(derive(master, "encryption"))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Box{aead: aead, hashKey: derive(master, "lookup")}, nil
+}
+
+func derive(master []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, master)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// Seal encrypts a secret with a random nonce and returns it base64 encoded
+func (b *Box) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a secret produced by Seal
+func (b *Box) Open(sealed string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", fmt.Errorf("invalid sealed secret: %w", err)
+	}
+	if len(data) < b.aead.NonceSize() {
+		return "", fmt.Errorf("invalid sealed secret: too short")
+	}
+
+	nonce, ciphertext := data[:b.aead.NonceSize()], data[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+
+	return string(plaintext), nil
+}
+
+// Hash returns the hex HMAC-SHA256 of a secret. Equal secrets hash equally,
+// so the hash can back a unique index.
+func (b *Box) Hash(plaintext string) string {
+	mac := hmac.New(sha256.New, b.hashKey)
+	mac.Write([]byte(plaintext))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,197 @@
+// Package suppliersign signs supplier API requests and verifies supplier
+// webhooks. Adapters pick a signer by method name and pass the credentials of
+// the supplier account being called, so signing is not tied to global
+// configuration.
+package suppliersign
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Signing methods
+const (
+	MethodMD5        = "MD5"
+	MethodHMACSHA256 = "HMAC_SHA256"
+	MethodJWT        = "JWT"
+)
+
+// Credentials are the secrets of one supplier account
+type Credentials struct {
+	Username  string
+	APIKey    string
+	APISecret string
+}
+
+// secret returns the HMAC/JWT key: the API secret, or the API key for
+// suppliers that issue a single key
+func (c Credentials) secret() string {
+	if c.APISecret != "" {
+		return c.APISecret
+	}
+	return c.APIKey
+}
+
+// Signer produces the signature a supplier expects for a message. What the
+// message is (a ref ID, a command or the request body) is defined by the adapter.
+type Signer interface {
+	Sign(creds Credentials, message string) (string, error)
+}
+
+// SignerFunc adapts a function to the Signer interface
+type SignerFunc func(creds Credentials, message string) (string, error)
+
+// Sign calls f
+func (f SignerFunc) Sign(creds Credentials, message string) (string, error) {
+	return f(creds, message)
+}
+
+var (
+	mu      sync.RWMutex
+	signers = map[string]Signer{
+		MethodMD5:        MD5Signer{},
+		MethodHMACSHA256: HMACSHA256Signer{},
+		MethodJWT:        JWTSigner{},
+	}
+)
+
+// Register adds or replaces the signer for a method
+func Register(method string, signer Signer) {
+	method = normalize(method)
+	if method == "" || signer == nil {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	signers[method] = signer
+}
+
+// Get returns the signer registered for a method
+func Get(method string) (Signer, error) {
+	method = normalize(method)
+
+	mu.RLock()
+	signer, ok := signers[method]
+	mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown signing method %q", method)
+	}
+	return signer, nil
+}
+
+// IsValidMethod checks if a signer is registered for the method
+func IsValidMethod(method string) bool {
+	_, err := Get(method)
+	return err == nil
+}
+
+func normalize(method string) string {
+	return strings.ToUpper(strings.TrimSpace(method))
+}
+
+// MD5Signer signs with md5(username + api key + message), as used by Digiflazz
+type MD5Signer struct{}
+
+// Sign implements Signer
+func (MD5Signer) Sign(creds Credentials, message string) (string, error) {
+	if creds.Username == "" || creds.APIKey == "" {
+		return "", fmt.Errorf("md5 signing requires username and api key")
+	}
+
+	sum := md5.Sum([]byte(creds.Username + creds.APIKey + message))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// HMACSHA256Signer signs with the hex encoded HMAC-SHA256 of the message
+type HMACSHA256Signer struct{}
+
+// Sign implements Signer
+func (HMACSHA256Signer) Sign(creds Credentials, message string) (string, error) {
+	secret := creds.secret()
+	if secret == "" {
+		return "", fmt.Errorf("hmac signing requires an api secret")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// JWTSigner issues a short-lived HS256 token whose body_sha256 claim binds it
+// to the message
+type JWTSigner struct {
+	TTL time.Duration // Token lifetime, 5 minutes when zero
+}
+
+// Sign implements Signer
+func (s JWTSigner) Sign(creds Credentials, message string) (string, error) {
+	secret := creds.secret()
+	if secret == "" {
+		return "", fmt.Errorf("jwt signing requires an api secret")
+	}
+
+	ttl := s.TTL
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+
+	now := time.Now()
+	sum := sha256.Sum256([]byte(message))
+	claims := jwt.MapClaims{
+		"iat":         now.Unix(),
+		"exp":         now.Add(ttl).Unix(),
+		"body_sha256": hex.EncodeToString(sum[:]),
+	}
+	if creds.Username != "" {
+		claims["iss"] = creds.Username
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign jwt: %w", err)
+	}
+	return token, nil
+}
+
+// VerifyWebhook checks the HMAC signature a supplier sent with a webhook
+// payload. The signature is hex encoded and may carry a "sha1=" or
+// "sha256=" prefix naming the hash, as in Digiflazz's X-Hub-Signature
+// header; without a prefix SHA-256 is assumed. Comparison is constant-time.
+func VerifyWebhook(secret string, payload []byte, signature string) bool {
+	if secret == "" {
+		return false
+	}
+
+	newHash := sha256.New
+	signature = strings.TrimSpace(signature)
+	if algorithm, value, ok := strings.Cut(signature, "="); ok {
+		switch strings.ToLower(algorithm) {
+		case "sha1":
+			newHash = sha1.New
+		case "sha256":
+		default:
+			return false
+		}
+		signature = value
+	}
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PhonePlan is the numbering plan of one country. Numbers are stored in
+// international form without the plus sign: CountryCode followed by the
+// national significant number.
+type PhonePlan struct {
+	Country     string // ISO 3166-1 alpha-2 code
+	CountryCode string // Calling code, e.g. "62"
+	// TrunkPrefix is dialed before national numbers and dropped in
+	// international form ("0" in Indonesia). Plans without one read a bare
+	// national number of valid length as local.
+	TrunkPrefix string
+	// MinDigits and MaxDigits bound the national significant number
+	MinDigits int
+	MaxDigits int
+	Hint      string // Expected format shown to users
+}
+
+// PhonePlans is the table of supported numbering plans keyed by country
+var PhonePlans = map[string]*PhonePlan{
+	"ID": {Country: "ID", CountryCode: "62", TrunkPrefix: "0", MinDigits: 9, MaxDigits: 13, Hint: "Nomor HP Indonesia, contoh 081234567890"},
+	"MY": {Country: "MY", CountryCode: "60", TrunkPrefix: "0", MinDigits: 9, MaxDigits: 10, Hint: "Malaysian mobile number, e.g. 0123456789"},
+	"SG": {Country: "SG", CountryCode: "65", MinDigits: 8, MaxDigits: 8, Hint: "Singapore mobile number, e.g. 91234567"},
+	"PH": {Country: "PH", CountryCode: "63", TrunkPrefix: "0", MinDigits: 10, MaxDigits: 10, Hint: "Philippine mobile number, e.g. 09171234567"},
+	"TH": {Country: "TH", CountryCode: "66", TrunkPrefix: "0", MinDigits: 8, MaxDigits: 9, Hint: "Thai mobile number, e.g. 0812345678"},
+	"VN": {Country: "VN", CountryCode: "84", TrunkPrefix: "0", MinDigits: 9, MaxDigits: 10, Hint: "Vietnamese mobile number, e.g. 0912345678"},
+	"TL": {Country: "TL", CountryCode: "670", MinDigits: 8, MaxDigits: 8, Hint: "Timor-Leste mobile number, e.g. 77123456"},
+}
+
+// Parse normalizes a number to the plan's international form. Numbers
+// written with "+" or "00" are taken as international and only lose the
+// prefix; digits the plan cannot place are returned as they are.
+func (p *PhonePlan) Parse(phone string) string {
+	phone = strings.TrimSpace(phone)
+	international := strings.HasPrefix(phone, "+")
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+
+	switch {
+	case international:
+		return digits
+	case strings.HasPrefix(digits, "00"):
+		return digits[2:]
+	case p.TrunkPrefix != "" && strings.HasPrefix(digits, p.TrunkPrefix):
+		return p.CountryCode + digits[len(p.TrunkPrefix):]
+	case p.TrunkPrefix == "" && !strings.HasPrefix(digits, p.CountryCode) &&
+		len(digits) >= p.MinDigits && len(digits) <= p.MaxDigits:
+		return p.CountryCode + digits
+	default:
+		return digits
+	}
+}
+
+// Validate reports whether the number parses to a number of this plan
+func (p *PhonePlan) Validate(phone string) bool {
+	normalized := p.Parse(phone)
+	if !strings.HasPrefix(normalized, p.CountryCode) {
+		return false
+	}
+	digits := len(normalized) - len(p.CountryCode)
+	return digits >= p.MinDigits && digits <= p.MaxDigits
+}
+
+// PhoneNormalizer picks the numbering plan of a destination: the plan of its
+// product code, else of its category, else the default plan
+type PhoneNormalizer struct {
+	defaultPlan *PhonePlan
+	products    map[string]*PhonePlan
+	categories  map[string]*PhonePlan
+}
+
+// NewPhoneNormalizer builds a normalizer from country codes; the maps key
+// product codes and category codes to the country their numbers belong to
+func NewPhoneNormalizer(defaultCountry string, productCountries, categoryCountries map[string]string) (*PhoneNormalizer, error) {
+	defaultPlan, err := lookupPhonePlan(defaultCountry)
+	if err != nil {
+		return nil, err
+	}
+
+	normalizer := &PhoneNormalizer{
+		defaultPlan: defaultPlan,
+		products:    make(map[string]*PhonePlan, len(productCountries)),
+		categories:  make(map[string]*PhonePlan, len(categoryCountries)),
+	}
+	for code, country := range productCountries {
+		if normalizer.products[strings.ToUpper(code)], err = lookupPhonePlan(country); err != nil {
+			return nil, fmt.Errorf("product %s: %w", code, err)
+		}
+	}
+	for code, country := range categoryCountries {
+		if normalizer.categories[strings.ToUpper(code)], err = lookupPhonePlan(country); err != nil {
+			return nil, fmt.Errorf("category %s: %w", code, err)
+		}
+	}
+	return normalizer, nil
+}
+
+// DefaultPhoneNormalizer normalizes every number with the Indonesian plan
+func DefaultPhoneNormalizer() *PhoneNormalizer {
+	return &PhoneNormalizer{defaultPlan: PhonePlans["ID"]}
+}
+
+// Plan returns the plan of a product; empty codes skip that level
+func (n *PhoneNormalizer) Plan(productCode, category string) *PhonePlan {
+	if plan, ok := n.products[strings.ToUpper(productCode)]; ok {
+		return plan
+	}
+	if plan, ok := n.categories[strings.ToUpper(category)]; ok {
+		return plan
+	}
+	return n.defaultPlan
+}
+
+// Default returns the plan used for numbers without a product
+func (n *PhoneNormalizer) Default() *PhonePlan {
+	return n.defaultPlan
+}
+
+func lookupPhonePlan(country string) (*PhonePlan, error) {
+	plan, ok := PhonePlans[strings.ToUpper(strings.TrimSpace(country))]
+	if !ok {
+		return nil, fmt.Errorf("unsupported phone country %q", country)
+	}
+	return plan, nil
+}
+
+// ParsePhoneNumber normalizes an Indonesian phone number to 62xxx form
+func ParsePhoneNumber(phone string) string {
+	return PhonePlans["ID"].Parse(phone)
+}
+
+// ValidatePhoneNumber validates Indonesian phone number format
+func ValidatePhoneNumber(phone string) bool {
+	return PhonePlans["ID"].Validate(phone)
+}
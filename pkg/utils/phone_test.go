@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPhonePlanParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		country string
+		phone   string
+		want    string
+	}{
+		{name: "ID leading zero", country: "ID", phone: "081234567890", want: "6281234567890"},
+		{name: "ID plus country code", country: "ID", phone: "+6281234567890", want: "6281234567890"},
+		{name: "ID bare country code", country: "ID", phone: "6281234567890", want: "6281234567890"},
+		{name: "ID double zero prefix", country: "ID", phone: "006281234567890", want: "6281234567890"},
+		{name: "ID separators are dropped", country: "ID", phone: " 0812-3456 7890 ", want: "6281234567890"},
+		{name: "ID plus keeps a foreign country code", country: "ID", phone: "+60123456789", want: "60123456789"},
+		{name: "MY leading zero", country: "MY", phone: "0123456789", want: "60123456789"},
+		{name: "PH leading zero", country: "PH", phone: "09171234567", want: "639171234567"},
+		{name: "TH leading zero", country: "TH", phone: "0812345678", want: "66812345678"},
+		{name: "VN leading zero", country: "VN", phone: "0912345678", want: "84912345678"},
+		{name: "SG bare national number", country: "SG", phone: "91234567", want: "6591234567"},
+		{name: "SG plus country code", country: "SG", phone: "+65 9123 4567", want: "6591234567"},
+		{name: "SG short number kept as is", country: "SG", phone: "1234", want: "1234"},
+		{name: "TL three digit country code", country: "TL", phone: "77123456", want: "67077123456"},
+		{name: "TL with country code", country: "TL", phone: "67077123456", want: "67077123456"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PhonePlans[tt.country].Parse(tt.phone); got != tt.want {
+				t.Errorf("Parse(%q) = %q, want %q", tt.phone, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPhonePlanValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		country string
+		phone   string
+		want    bool
+	}{
+		{name: "ID leading zero", country: "ID", phone: "081234567890", want: true},
+		{name: "ID plus country code", country: "ID", phone: "+6281234567890", want: true},
+		{name: "ID shortest number", country: "ID", phone: "0812345678", want: true},
+		{name: "ID too short", country: "ID", phone: "081234567", want: false},
+		{name: "ID too long", country: "ID", phone: "081234567890123", want: false},
+		{name: "ID foreign number", country: "ID", phone: "+60123456789", want: false},
+		{name: "ID missing trunk prefix", country: "ID", phone: "81234567890", want: false},
+		{name: "ID empty", country: "ID", phone: "", want: false},
+		{name: "ID letters only", country: "ID", phone: "abc", want: false},
+		{name: "MY leading zero", country: "MY", phone: "0123456789", want: true},
+		{name: "MY too long", country: "MY", phone: "012345678901", want: false},
+		{name: "PH exact length", country: "PH", phone: "+639171234567", want: true},
+		{name: "PH too short", country: "PH", phone: "0917123456", want: false},
+		{name: "SG bare national number", country: "SG", phone: "91234567", want: true},
+		{name: "SG seven digits", country: "SG", phone: "9123456", want: false},
+		{name: "TL bare national number", country: "TL", phone: "77123456", want: true},
+		{name: "TL Indonesian number", country: "TL", phone: "081234567890", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PhonePlans[tt.country].Validate(tt.phone); got != tt.want {
+				t.Errorf("Validate(%q) = %v, want %v", tt.phone, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPhoneNormalizerPlan(t *testing.T) {
+	normalizer, err := NewPhoneNormalizer("id",
+		map[string]string{"tlmy10": "MY"},
+		map[string]string{"pulsa_sg": "sg", "PULSA_MY": "TH"},
+	)
+	if err != nil {
+		t.Fatalf("NewPhoneNormalizer() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		productCode string
+		category    string
+		want        string
+	}{
+		{name: "no override uses default", productCode: "TSEL10", category: "PULSA", want: "ID"},
+		{name: "product override", productCode: "TLMY10", category: "PULSA", want: "MY"},
+		{name: "product override wins over category", productCode: "tlmy10", category: "PULSA_MY", want: "MY"},
+		{name: "category override", productCode: "SING10", category: "pulsa_sg", want: "SG"},
+		{name: "category override of another product", productCode: "TSEL10", category: "PULSA_MY", want: "TH"},
+		{name: "empty codes use default", want: "ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizer.Plan(tt.productCode, tt.category).Country; got != tt.want {
+				t.Errorf("Plan(%q, %q) = %s, want %s", tt.productCode, tt.category, got, tt.want)
+			}
+		})
+	}
+
+	if got := normalizer.Default().Country; got != "ID" {
+		t.Errorf("Default() = %s, want ID", got)
+	}
+}
+
+func TestNewPhoneNormalizerRejectsUnknownCountries(t *testing.T) {
+	tests := []struct {
+		name       string
+		country    string
+		products   map[string]string
+		categories map[string]string
+		wantErr    string
+	}{
+		{name: "unknown default", country: "XX", wantErr: `unsupported phone country "XX"`},
+		{name: "empty default", country: "", wantErr: `unsupported phone country ""`},
+		{name: "unknown product country", country: "ID", products: map[string]string{"TSEL10": "US"}, wantErr: `product TSEL10: unsupported phone country "US"`},
+		{name: "unknown category country", country: "ID", categories: map[string]string{"PULSA": "JP"}, wantErr: `category PULSA: unsupported phone country "JP"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalizer, err := NewPhoneNormalizer(tt.country, tt.products, tt.categories)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("NewPhoneNormalizer() error = %v, want %q", err, tt.wantErr)
+			}
+			if normalizer != nil {
+				t.Errorf("NewPhoneNormalizer() = %v, want nil on error", normalizer)
+			}
+		})
+	}
+}
+
+func TestIndonesianPhoneHelpers(t *testing.T) {
+	if got := ParsePhoneNumber("0812 3456 7890"); got != "6281234567890" {
+		t.Errorf("ParsePhoneNumber() = %q, want 6281234567890", got)
+	}
+	if !ValidatePhoneNumber("+6281234567890") {
+		t.Error("ValidatePhoneNumber(+6281234567890) = false, want true")
+	}
+	if ValidatePhoneNumber("12345") {
+		t.Error("ValidatePhoneNumber(12345) = true, want false")
+	}
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"crypto/rand"
+	"crypto/subtle"
 	"fmt"
 	"math"
 	"math/big"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // GenerateUUID generates a new UUID
@@ -18,6 +20,12 @@ func GenerateUUID() string {
 	return uuid.New().String()
 }
 
+// GenerateNameUUID generates a deterministic UUID from a name, so the same
+// name always yields the same ID
+func GenerateNameUUID(name string) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(name)).String()
+}
+
 // GenerateTrxCode generates a unique transaction code
 func GenerateTrxCode() string {
 	now := time.Now()
@@ -35,35 +43,6 @@ func FormatCurrency(amount float64) string {
 	return fmt.Sprintf("Rp %.2f", amount)
 }
 
-// ParsePhoneNumber parses and normalizes phone number
-func ParsePhoneNumber(phone string) string {
-	// Remove all non-digit characters
-	re := regexp.MustCompile(`[^\d]`)
-	phone = re.ReplaceAllString(phone, "")
-
-	// Remove leading 0 if present and add 62 for Indonesia
-	if strings.HasPrefix(phone, "0") {
-		phone = "62" + phone[1:]
-	}
-
-	return phone
-}
-
-// ValidatePhoneNumber validates Indonesian phone number format
-func ValidatePhoneNumber(phone string) bool {
-	// Parse and normalize first
-	normalized := ParsePhoneNumber(phone)
-
-	// Check if it starts with 62 and has correct length (10-13 digits after 62)
-	if !strings.HasPrefix(normalized, "62") {
-		return false
-	}
-
-	// Remove 62 prefix and check remaining digits
-	digits := normalized[2:]
-	return len(digits) >= 9 && len(digits) <= 13
-}
-
 // ValidateEmail validates email format
 func ValidateEmail(email string) bool {
 	re := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
@@ -84,18 +63,26 @@ func ValidatePassword(password string) bool {
 	return hasUpper && hasLower && hasDigit
 }
 
-// HashPassword creates a hash for the password (placeholder - use bcrypt in production)
-func HashPassword(password string) string {
-	// In production, use bcrypt: bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	// For now, return a simple hash (NOT SECURE FOR PRODUCTION)
-	return fmt.Sprintf("hashed_%s", password)
+// legacyPasswordPrefix marks hashes written before passwords were hashed with bcrypt
+const legacyPasswordPrefix = "hashed_"
+
+// HashPassword hashes the password with bcrypt. Passwords longer than 72
+// bytes are rejected.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
 }
 
-// VerifyPassword verifies password against hash (placeholder - use bcrypt in production)
+// VerifyPassword verifies password against a bcrypt hash, or a legacy hash
+// until the password is changed
 func VerifyPassword(password, hash string) bool {
-	// In production, use bcrypt: bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	// For now, simple verification (NOT SECURE FOR PRODUCTION)
-	return hash == fmt.Sprintf("hashed_%s", password)
+	if strings.HasPrefix(hash, legacyPasswordPrefix) {
+		return subtle.ConstantTimeCompare([]byte(hash), []byte(legacyPasswordPrefix+password)) == 1
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
 }
 
 // GenerateRandomString generates a random string of specified length
@@ -44,6 +44,23 @@ type PaginatedResponse struct {
 	Timestamp  int64         `json:"timestamp"`
 }
 
+// CursorMeta represents cursor (keyset) pagination metadata
+type CursorMeta struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// CursorPaginatedResponse represents cursor paginated response
+type CursorPaginatedResponse struct {
+	Code       int         `json:"code"`
+	Status     string      `json:"status"`
+	Message    string      `json:"message"`
+	Data       interface{} `json:"data"`
+	Pagination CursorMeta  `json:"pagination"`
+	Timestamp  int64       `json:"timestamp"`
+}
+
 // Common error codes
 const (
 	ErrCodeValidationFailed = "VALIDATION_FAILED"
@@ -60,6 +77,10 @@ const (
 	ErrCodeInvalidCredentials = "INVALID_CREDENTIALS"
 	ErrCodeAccountLocked    = "ACCOUNT_LOCKED"
 	ErrCodeRateLimitExceeded = "RATE_LIMIT_EXCEEDED"
+	ErrCodeDuplicateTransaction = "DUPLICATE_TRANSACTION"
+	ErrCodeConfirmationRequired = "CONFIRMATION_REQUIRED"
+	ErrCodeServiceOverloaded = "SERVICE_OVERLOADED"
+	ErrCodeProductRestricted = "PRODUCT_RESTRICTED"
 )
 
 // Success sends success response
@@ -218,6 +239,23 @@ func Paginated(c *gin.Context, message string, data interface{}, page, limit, to
 	c.JSON(http.StatusOK, response)
 }
 
+// CursorPaginated sends cursor paginated response
+func CursorPaginated(c *gin.Context, message string, data interface{}, limit int, nextCursor string) {
+	response := CursorPaginatedResponse{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: message,
+		Data:    data,
+		Pagination: CursorMeta{
+			Limit:      limit,
+			NextCursor: nextCursor,
+			HasMore:    nextCursor != "",
+		},
+		Timestamp: time.Now().Unix(),
+	}
+	c.JSON(http.StatusOK, response)
+}
+
 // ValidationError sends validation error response with field details
 func ValidationError(c *gin.Context, details interface{}) {
 	ErrorWithDetails(c, http.StatusBadRequest, ErrCodeValidationFailed, "Validation failed", details)